@@ -0,0 +1,218 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+)
+
+// ProposalStatus 配置提案状态
+type ProposalStatus string
+
+const (
+	ProposalPending  ProposalStatus = "pending"
+	ProposalApproved ProposalStatus = "approved"
+	ProposalRejected ProposalStatus = "rejected"
+)
+
+// ConfigProposal AI 生成配置的待审批提案
+// 审核模式下 AI 生成的配置不直接写入 config.yaml，而是连同说明与相对当前配置的差异保存为提案，
+// 人工批准后才应用
+type ConfigProposal struct {
+	ID          string                  `json:"id"`
+	Status      ProposalStatus          `json:"status"`
+	Source      string                  `json:"source"`            // generate-config / apply-config
+	TaskID      string                  `json:"task_id,omitempty"` // 生成该配置的 AI 任务
+	Explanation string                  `json:"explanation"`
+	Config      *GenerateConfigResponse `json:"config"`
+	Diff        []config.ConfigChange   `json:"diff"`                   // 创建时相对当前配置的差异
+	AppliedDiff []config.ConfigChange   `json:"applied_diff,omitempty"` // 批准时实际写入的差异（当前配置可能已变化）
+	CreatedAt   time.Time               `json:"created_at"`
+	ReviewedAt  *time.Time              `json:"reviewed_at,omitempty"`
+	Reviewer    string                  `json:"reviewer,omitempty"`
+	Comment     string                  `json:"comment,omitempty"`
+}
+
+// ProposalStore 配置提案存储（JSON 文件持久化）
+type ProposalStore struct {
+	mu        sync.Mutex
+	path      string
+	proposals map[string]*ConfigProposal
+}
+
+// NewProposalStore 创建提案存储，path 为空时仅保存在内存
+func NewProposalStore(path string) *ProposalStore {
+	s := &ProposalStore{path: path, proposals: make(map[string]*ConfigProposal)}
+	s.load()
+	return s
+}
+
+// Create 保存一条待审批提案
+func (s *ProposalStore) Create(source, taskID string, aiConfig *GenerateConfigResponse, diff *config.ConfigDiff) (*ConfigProposal, error) {
+	if aiConfig == nil {
+		return nil, fmt.Errorf("提案配置为空")
+	}
+	p := &ConfigProposal{
+		ID:          fmt.Sprintf("proposal_%d", time.Now().UnixNano()),
+		Status:      ProposalPending,
+		Source:      source,
+		TaskID:      taskID,
+		Explanation: aiConfig.Explanation,
+		Config:      aiConfig,
+		CreatedAt:   time.Now(),
+	}
+	if diff != nil {
+		p.Diff = diff.Changes
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if taskID != "" {
+		for _, existing := range s.proposals {
+			if existing.TaskID == taskID && existing.Status == ProposalPending {
+				return existing, nil
+			}
+		}
+	}
+	s.proposals[p.ID] = p
+	return p, s.saveLocked()
+}
+
+// Get 获取提案
+func (s *ProposalStore) Get(id string) (*ConfigProposal, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.proposals[id]
+	return p, ok
+}
+
+// List 按创建时间倒序列出提案，status 为空时返回全部
+func (s *ProposalStore) List(status ProposalStatus) []*ConfigProposal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]*ConfigProposal, 0, len(s.proposals))
+	for _, p := range s.proposals {
+		if status == "" || p.Status == status {
+			result = append(result, p)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result
+}
+
+// review 将待审批提案标记为已批准/已拒绝
+func (s *ProposalStore) review(id string, status ProposalStatus, reviewer, comment string, applied *config.ConfigDiff) (*ConfigProposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.proposals[id]
+	if !ok {
+		return nil, fmt.Errorf("提案不存在: %s", id)
+	}
+	if p.Status != ProposalPending {
+		return nil, fmt.Errorf("提案 %s 已处理（%s）", id, p.Status)
+	}
+	now := time.Now()
+	p.Status = status
+	p.ReviewedAt = &now
+	p.Reviewer = reviewer
+	p.Comment = comment
+	if applied != nil {
+		p.AppliedDiff = applied.Changes
+	}
+	return p, s.saveLocked()
+}
+
+// Reject 拒绝提案，不修改配置
+func (s *ProposalStore) Reject(id, reviewer, comment string) (*ConfigProposal, error) {
+	return s.review(id, ProposalRejected, reviewer, comment, nil)
+}
+
+func (s *ProposalStore) load() {
+	if s.path == "" {
+		return
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("⚠️ [AI提案] 读取提案文件失败: %v", err)
+		}
+		return
+	}
+	var list []*ConfigProposal
+	if err := json.Unmarshal(data, &list); err != nil {
+		logger.Warn("⚠️ [AI提案] 解析提案文件失败: %v", err)
+		return
+	}
+	for _, p := range list {
+		if p != nil && p.ID != "" {
+			s.proposals[p.ID] = p
+		}
+	}
+}
+
+// saveLocked 写入提案文件（先写临时文件再重命名），调用方持有 s.mu
+func (s *ProposalStore) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	list := make([]*ConfigProposal, 0, len(s.proposals))
+	for _, p := range s.proposals {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(s.path), 0755)
+	}
+	if err == nil {
+		tmp := s.path + ".tmp"
+		if err = os.WriteFile(tmp, data, 0644); err == nil {
+			err = os.Rename(tmp, s.path)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("保存提案失败: %w", err)
+	}
+	return nil
+}
+
+// ProposeAIConfig 将 AI 生成的配置保存为待审批提案，附带相对当前 config.yaml 的差异
+func (cs *ConfigService) ProposeAIConfig(store *ProposalStore, source, taskID string, aiConfig *GenerateConfigResponse) (*ConfigProposal, error) {
+	diff, err := cs.PreviewAIConfig(aiConfig)
+	if err != nil {
+		return nil, err
+	}
+	return store.Create(source, taskID, aiConfig, diff)
+}
+
+// ApproveProposal 批准提案：按当前 config.yaml 重新合并后写入，记录实际应用的差异
+func (cs *ConfigService) ApproveProposal(store *ProposalStore, id, reviewer, comment string) (*ConfigProposal, error) {
+	p, ok := store.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("提案不存在: %s", id)
+	}
+	if p.Status != ProposalPending {
+		return nil, fmt.Errorf("提案 %s 已处理（%s）", id, p.Status)
+	}
+
+	diff, err := cs.PreviewAIConfig(p.Config)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.LoadConfig(cs.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("加载当前配置失败: %w", err)
+	}
+	if err := cs.ApplyAIConfig(p.Config, cfg); err != nil {
+		return nil, err
+	}
+	return store.review(id, ProposalApproved, reviewer, comment, diff)
+}
@@ -0,0 +1,103 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"quantmesh/config"
+)
+
+const proposalTestConfig = `
+app:
+  current_exchange: "binance"
+trading:
+  symbol: "BTCUSDT"
+  price_interval: 100
+  order_quantity: 100
+  buy_window_size: 10
+  sell_window_size: 10
+  symbols:
+    - exchange: "binance"
+      symbol: "BTCUSDT"
+      price_interval: 100
+      order_quantity: 100
+      buy_window_size: 10
+      sell_window_size: 10
+exchanges:
+  binance:
+    api_key: "test_key"
+    secret_key: "test_secret"
+    fee_rate: 0.0002
+`
+
+func TestConfigProposalReviewFlow(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte(proposalTestConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	storePath := filepath.Join(dir, "proposals.json")
+	store := NewProposalStore(storePath)
+	cs := NewConfigService(configPath)
+
+	aiConfig := &GenerateConfigResponse{
+		Explanation: "缩小网格间距",
+		GridConfig: []SymbolGridConfig{{
+			Exchange: "binance", Symbol: "BTCUSDT",
+			PriceInterval: 50, OrderQuantity: 100, BuyWindowSize: 10, SellWindowSize: 10,
+		}},
+	}
+	proposal, err := cs.ProposeAIConfig(store, "generate-config", "task_1", aiConfig)
+	if err != nil {
+		t.Fatalf("propose: %v", err)
+	}
+	if proposal.Status != ProposalPending || len(proposal.Diff) == 0 {
+		t.Fatalf("unexpected proposal: %+v", proposal)
+	}
+
+	// 提案不会修改配置文件
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Trading.Symbols[0].PriceInterval; got != 100 {
+		t.Fatalf("config changed before approval: price_interval=%v", got)
+	}
+
+	// 同一任务重复提交返回同一提案
+	if again, _ := cs.ProposeAIConfig(store, "generate-config", "task_1", aiConfig); again.ID != proposal.ID {
+		t.Errorf("duplicate proposal for the same task: %s vs %s", again.ID, proposal.ID)
+	}
+
+	approved, err := cs.ApproveProposal(store, proposal.ID, "admin", "ok")
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if approved.Status != ProposalApproved || approved.ReviewedAt == nil || len(approved.AppliedDiff) == 0 {
+		t.Errorf("unexpected approved proposal: %+v", approved)
+	}
+	cfg, err = config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Trading.Symbols[0].PriceInterval; got != 50 {
+		t.Errorf("price_interval after approval = %v, want 50", got)
+	}
+
+	if _, err := cs.ApproveProposal(store, proposal.ID, "admin", ""); err == nil {
+		t.Error("approving twice should fail")
+	}
+	if _, err := store.Reject(proposal.ID, "admin", ""); err == nil {
+		t.Error("rejecting an approved proposal should fail")
+	}
+
+	// 重新加载后提案仍在
+	reloaded := NewProposalStore(storePath)
+	if p, ok := reloaded.Get(proposal.ID); !ok || p.Status != ProposalApproved {
+		t.Errorf("proposal not persisted: %+v", p)
+	}
+	if n := len(reloaded.List(ProposalPending)); n != 0 {
+		t.Errorf("pending proposals = %d, want 0", n)
+	}
+}
@@ -3,6 +3,7 @@ package ai
 import (
 	"fmt"
 	"quantmesh/config"
+	"quantmesh/logger"
 )
 
 // ConfigService AI 配置服务
@@ -17,8 +18,50 @@ func NewConfigService(configPath string) *ConfigService {
 	}
 }
 
+// aiDisabledByProfile 交易对的风控档位禁止 AI 修改参数时返回 true
+func aiDisabledByProfile(cfg *config.Config, symCfg config.SymbolConfig) bool {
+	profile, ok := cfg.LookupRiskProfile(symCfg.RiskProfile)
+	if !ok || !profile.DisableAI {
+		return false
+	}
+	logger.Info("ℹ️ [%s] 风控档位 %s 禁止 AI 修改参数，保留原配置", symCfg.Symbol, symCfg.RiskProfile)
+	return true
+}
+
 // ApplyAIConfig 应用 AI 生成的配置
 func (cs *ConfigService) ApplyAIConfig(aiConfig *GenerateConfigResponse, cfg *config.Config) error {
+	// 护栏对所有写入路径生效（AI 任务结果、手工提交、审批提案）
+	if err := cs.ValidateAIConfig(aiConfig, 0); err != nil {
+		return err
+	}
+	mergeAIConfig(aiConfig, cfg)
+
+	if err := config.SaveConfig(cfg, cs.configPath); err != nil {
+		return fmt.Errorf("保存配置失败: %w", err)
+	}
+
+	return nil
+}
+
+// PreviewAIConfig 计算应用 AI 配置后相对当前 config.yaml 的差异，不写入文件
+func (cs *ConfigService) PreviewAIConfig(aiConfig *GenerateConfigResponse) (*config.ConfigDiff, error) {
+	if err := cs.ValidateAIConfig(aiConfig, 0); err != nil {
+		return nil, err
+	}
+	current, err := config.LoadConfig(cs.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("加载当前配置失败: %w", err)
+	}
+	proposed, err := config.LoadConfig(cs.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("加载当前配置失败: %w", err)
+	}
+	mergeAIConfig(aiConfig, proposed)
+	return config.DiffConfig(current, proposed), nil
+}
+
+// mergeAIConfig 将 AI 生成的交易对参数与资金分配合并到 cfg
+func mergeAIConfig(aiConfig *GenerateConfigResponse, cfg *config.Config) {
 	// 1. 优先处理分级的 SymbolsConfig (资产优先重构)
 	if len(aiConfig.SymbolsConfig) > 0 {
 		for _, newSymCfg := range aiConfig.SymbolsConfig {
@@ -36,6 +79,14 @@ func (cs *ConfigService) ApplyAIConfig(aiConfig *GenerateConfigResponse, cfg *co
 				}
 
 				if oldExchange == newSymCfg.Exchange && oldSymCfg.Symbol == newSymCfg.Symbol {
+					if aiDisabledByProfile(cfg, oldSymCfg) {
+						found = true
+						break
+					}
+					if newSymCfg.RiskProfile == "" {
+						newSymCfg.RiskProfile = oldSymCfg.RiskProfile
+					}
+
 					// 保留一些基础字段，防止 AI 覆盖掉（如订单清理等，除非 AI 显式指定）
 					if newSymCfg.MinOrderValue == 0 {
 						newSymCfg.MinOrderValue = oldSymCfg.MinOrderValue
@@ -91,6 +142,10 @@ func (cs *ConfigService) ApplyAIConfig(aiConfig *GenerateConfigResponse, cfg *co
 			found := false
 			for i, symCfg := range cfg.Trading.Symbols {
 				if symCfg.Exchange == gridCfg.Exchange && symCfg.Symbol == gridCfg.Symbol {
+					if aiDisabledByProfile(cfg, symCfg) {
+						found = true
+						break
+					}
 					cfg.Trading.Symbols[i].PriceInterval = gridCfg.PriceInterval
 					cfg.Trading.Symbols[i].OrderQuantity = gridCfg.OrderQuantity
 					cfg.Trading.Symbols[i].BuyWindowSize = gridCfg.BuyWindowSize
@@ -172,56 +227,14 @@ func (cs *ConfigService) ApplyAIConfig(aiConfig *GenerateConfigResponse, cfg *co
 			})
 		}
 	}
-
-	// 3. 保存配置
-	if err := config.SaveConfig(cfg, cs.configPath); err != nil {
-		return fmt.Errorf("保存配置失败: %w", err)
-	}
-
-	return nil
 }
 
-// ValidateAIConfig 验证 AI 配置
+// ValidateAIConfig 验证 AI 配置：参数范围、资金总额（totalCapital <= 0 时不检查）与护栏上限
 func (cs *ConfigService) ValidateAIConfig(aiConfig *GenerateConfigResponse, totalCapital float64) error {
-	// 验证资金分配总和不超过可用资金
-	var totalAllocated float64
-	for _, alloc := range aiConfig.Allocation {
-		totalAllocated += alloc.MaxAmountUSDT
+	if aiConfig == nil {
+		return fmt.Errorf("AI 配置为空")
 	}
-
-	if totalAllocated > totalCapital {
-		return fmt.Errorf("资金分配总和 (%.2f USDT) 超过可用资金 (%.2f USDT)", totalAllocated, totalCapital)
-	}
-
-	// 验证网格参数合理性
-	for _, grid := range aiConfig.GridConfig {
-		if grid.PriceInterval <= 0 {
-			return fmt.Errorf("%s 价格间隔必须大于0", grid.Symbol)
-		}
-		if grid.OrderQuantity <= 0 {
-			return fmt.Errorf("%s 每单金额必须大于0", grid.Symbol)
-		}
-		if grid.BuyWindowSize <= 0 || grid.SellWindowSize <= 0 {
-			return fmt.Errorf("%s 窗口大小必须大于0", grid.Symbol)
-		}
-		
-		// 验证风控参数
-		if grid.GridRiskControl != nil && grid.GridRiskControl.Enabled {
-			if grid.GridRiskControl.StopLossRatio < 0 || grid.GridRiskControl.StopLossRatio > 1 {
-				return fmt.Errorf("%s 止损比例必须在0-1之间", grid.Symbol)
-			}
-			if grid.GridRiskControl.TakeProfitTriggerRatio < 0 || grid.GridRiskControl.TakeProfitTriggerRatio > 1 {
-				return fmt.Errorf("%s 盈利触发比例必须在0-1之间", grid.Symbol)
-			}
-			if grid.GridRiskControl.TrailingTakeProfitRatio < 0 || grid.GridRiskControl.TrailingTakeProfitRatio > 1 {
-				return fmt.Errorf("%s 回撤止盈比例必须在0-1之间", grid.Symbol)
-			}
-			if grid.GridRiskControl.MaxGridLayers < 0 {
-				return fmt.Errorf("%s 最大层数不能为负数", grid.Symbol)
-			}
-		}
-	}
-
-	return nil
+	v := &validator{}
+	checkConfigResponse(v, aiConfig, totalCapital, currentGuardrails())
+	return v.err()
 }
-
@@ -0,0 +1,461 @@
+package ai
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 默认数据源地址
+const (
+	DefaultFearGreedAPIURL  = "https://api.alternative.me/fng/"
+	DefaultPolymarketAPIURL = "https://gamma-api.polymarket.com"
+	DefaultTwitterAPIURL    = "https://api.twitter.com/2"
+	DefaultTelegramWebURL   = "https://t.me/s/"
+)
+
+// ErrDataSourceDisabled 数据源未启用或未配置
+var ErrDataSourceDisabled = errors.New("数据源未启用")
+
+// 未配置时使用的默认新闻源和 Reddit 子版块
+var (
+	DefaultRSSFeeds = []string{
+		"https://www.coindesk.com/arc/outboundfeeds/rss/",
+		"https://cointelegraph.com/rss",
+		"https://cryptonews.com/news/feed/",
+	}
+	DefaultSubreddits = []string{"Bitcoin", "ethereum", "CryptoCurrency", "CryptoMarkets"}
+)
+
+// NewsItem 新闻条目
+type NewsItem struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	URL         string    `json:"url"`
+	Source      string    `json:"source"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// FearGreedIndex 恐慌贪婪指数
+type FearGreedIndex struct {
+	Value          int       `json:"value"`
+	Classification string    `json:"classification"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// RedditPost Reddit 帖子
+type RedditPost struct {
+	Title       string    `json:"title"`
+	Content     string    `json:"content"`
+	URL         string    `json:"url"`
+	Subreddit   string    `json:"subreddit"`
+	Score       int       `json:"score"`
+	UpvoteRatio float64   `json:"upvote_ratio"`
+	CreatedAt   time.Time `json:"created_at"`
+	Author      string    `json:"author"`
+}
+
+// PolymarketMarket Polymarket 预测市场
+type PolymarketMarket struct {
+	ID          string    `json:"id"`
+	Question    string    `json:"question"`
+	Description string    `json:"description"`
+	EndDate     time.Time `json:"end_date"`
+	Outcomes    []string  `json:"outcomes"`
+	Volume      float64   `json:"volume"`
+	Liquidity   float64   `json:"liquidity"`
+}
+
+// SocialPost 社交媒体消息（X/Twitter 推文、Telegram 频道消息）
+type SocialPost struct {
+	Source    string    `json:"source"`  // twitter, telegram
+	Channel   string    `json:"channel"` // 推文作者 ID 或 Telegram 频道名
+	Text      string    `json:"text"`
+	URL       string    `json:"url"`
+	Likes     int       `json:"likes"`
+	Reposts   int       `json:"reposts"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DataSourceAPI 市场情报数据源接口
+// DataSourceManager 直接请求外部 API，CachedDataSource 在其之上增加缓存，Web 层只依赖该接口
+type DataSourceAPI interface {
+	FetchRSSFeed(url string) ([]NewsItem, error)
+	FetchFearGreedIndex(apiURL string) (*FearGreedIndex, error)
+	FetchRedditPosts(subreddits []string, limit int) ([]RedditPost, error)
+	FetchPolymarketMarkets(apiURL string, keywords []string) ([]PolymarketMarket, error)
+	FetchTweets(query string, limit int) ([]SocialPost, error)
+	FetchTelegramPosts(channel string, limit int) ([]SocialPost, error)
+	FetchOnChain() (*OnChainSnapshot, error)
+}
+
+var _ DataSourceAPI = (*DataSourceManager)(nil)
+
+// DataSourceManager 外部市场情报数据源（新闻 RSS、恐慌贪婪指数、Reddit、Polymarket）
+// 每次调用都会直接请求外部 API，面向 Web 和分析模块时应通过 CachedDataSource 使用
+type DataSourceManager struct {
+	client    *http.Client
+	userAgent string
+
+	twitterAPIURL      string
+	twitterBearerToken string
+
+	onChain       OnChainProvider
+	onChainAssets []string
+}
+
+// NewDataSourceManager 创建数据源管理器
+func NewDataSourceManager() *DataSourceManager {
+	return &DataSourceManager{
+		client:    &http.Client{Timeout: 15 * time.Second},
+		userAgent: "QuantMesh/1.0 (market intelligence)",
+	}
+}
+
+// SetTwitterCredentials 设置 X API 地址和 Bearer Token（apiURL 为空时使用默认地址）
+func (m *DataSourceManager) SetTwitterCredentials(apiURL, bearerToken string) {
+	if apiURL == "" {
+		apiURL = DefaultTwitterAPIURL
+	}
+	m.twitterAPIURL = strings.TrimRight(apiURL, "/")
+	m.twitterBearerToken = bearerToken
+}
+
+// SetOnChainProvider 设置链上数据提供方和关注的币种（如 BTC、ETH）
+func (m *DataSourceManager) SetOnChainProvider(provider OnChainProvider, assets []string) {
+	m.onChain = provider
+	m.onChainAssets = assets
+}
+
+// FetchOnChain 获取链上数据快照
+func (m *DataSourceManager) FetchOnChain() (*OnChainSnapshot, error) {
+	if m.onChain == nil {
+		return nil, fmt.Errorf("链上数据: %w", ErrDataSourceDisabled)
+	}
+	return m.onChain.FetchOnChain(m.onChainAssets)
+}
+
+// getBody 发送 GET 请求并读取响应体（限制 5MB）
+func (m *DataSourceManager) getBody(rawURL string) ([]byte, error) {
+	return m.getBodyWithHeaders(rawURL, nil)
+}
+
+// getBodyWithHeaders 发送带额外请求头的 GET 请求
+func (m *DataSourceManager) getBodyWithHeaders(rawURL string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", m.userAgent)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s 返回 HTTP %d", rawURL, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 5<<20))
+}
+
+// rssDocument RSS 2.0 文档
+type rssDocument struct {
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			Title       string `xml:"title"`
+			Description string `xml:"description"`
+			Link        string `xml:"link"`
+			PubDate     string `xml:"pubDate"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+// FetchRSSFeed 获取 RSS 新闻
+func (m *DataSourceManager) FetchRSSFeed(feedURL string) ([]NewsItem, error) {
+	body, err := m.getBody(feedURL)
+	if err != nil {
+		return nil, fmt.Errorf("获取 RSS 失败: %w", err)
+	}
+	var doc rssDocument
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("解析 RSS 失败: %w", err)
+	}
+
+	source := doc.Channel.Title
+	if source == "" {
+		if u, err := url.Parse(feedURL); err == nil {
+			source = u.Host
+		}
+	}
+	items := make([]NewsItem, 0, len(doc.Channel.Items))
+	for _, it := range doc.Channel.Items {
+		items = append(items, NewsItem{
+			Title:       strings.TrimSpace(it.Title),
+			Description: strings.TrimSpace(it.Description),
+			URL:         strings.TrimSpace(it.Link),
+			Source:      source,
+			PublishedAt: parseRSSTime(it.PubDate),
+		})
+	}
+	return items, nil
+}
+
+// parseRSSTime 解析 RSS 发布时间（格式不规范时返回零值）
+func parseRSSTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// FetchFearGreedIndex 获取恐慌贪婪指数（alternative.me 接口格式）
+func (m *DataSourceManager) FetchFearGreedIndex(apiURL string) (*FearGreedIndex, error) {
+	if apiURL == "" {
+		apiURL = DefaultFearGreedAPIURL
+	}
+	body, err := m.getBody(apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("获取恐慌贪婪指数失败: %w", err)
+	}
+	var resp struct {
+		Data []struct {
+			Value          string `json:"value"`
+			Classification string `json:"value_classification"`
+			Timestamp      string `json:"timestamp"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("解析恐慌贪婪指数失败: %w", err)
+	}
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("恐慌贪婪指数无数据")
+	}
+	d := resp.Data[0]
+	value, err := strconv.Atoi(d.Value)
+	if err != nil {
+		return nil, fmt.Errorf("恐慌贪婪指数格式错误: %s", d.Value)
+	}
+	index := &FearGreedIndex{Value: value, Classification: d.Classification, Timestamp: time.Now()}
+	if ts, err := strconv.ParseInt(d.Timestamp, 10, 64); err == nil {
+		index.Timestamp = time.Unix(ts, 0)
+	}
+	return index, nil
+}
+
+// FetchRedditPosts 获取多个子版块的热门帖子（单个子版块失败时跳过）
+func (m *DataSourceManager) FetchRedditPosts(subreddits []string, limit int) ([]RedditPost, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	posts := make([]RedditPost, 0)
+	var lastErr error
+	for _, sub := range subreddits {
+		body, err := m.getBody(fmt.Sprintf("https://www.reddit.com/r/%s/hot.json?limit=%d", url.PathEscape(sub), limit))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		var listing struct {
+			Data struct {
+				Children []struct {
+					Data struct {
+						Title       string  `json:"title"`
+						Selftext    string  `json:"selftext"`
+						Permalink   string  `json:"permalink"`
+						Subreddit   string  `json:"subreddit"`
+						Score       int     `json:"score"`
+						UpvoteRatio float64 `json:"upvote_ratio"`
+						CreatedUTC  float64 `json:"created_utc"`
+						Author      string  `json:"author"`
+					} `json:"data"`
+				} `json:"children"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &listing); err != nil {
+			lastErr = err
+			continue
+		}
+		for _, child := range listing.Data.Children {
+			d := child.Data
+			posts = append(posts, RedditPost{
+				Title:       d.Title,
+				Content:     d.Selftext,
+				URL:         "https://www.reddit.com" + d.Permalink,
+				Subreddit:   d.Subreddit,
+				Score:       d.Score,
+				UpvoteRatio: d.UpvoteRatio,
+				CreatedAt:   time.Unix(int64(d.CreatedUTC), 0),
+				Author:      d.Author,
+			})
+		}
+	}
+	if len(posts) == 0 && lastErr != nil {
+		return nil, fmt.Errorf("获取 Reddit 帖子失败: %w", lastErr)
+	}
+	return posts, nil
+}
+
+// FetchPolymarketMarkets 获取活跃的 Polymarket 市场（按 24 小时成交量排序），keywords 不为空时按问题筛选
+// 旧配置中的 GraphQL 地址已停用，自动改用 Gamma API
+func (m *DataSourceManager) FetchPolymarketMarkets(apiURL string, keywords []string) ([]PolymarketMarket, error) {
+	if apiURL == "" || strings.HasSuffix(apiURL, "/graphql") {
+		apiURL = DefaultPolymarketAPIURL
+	}
+	body, err := m.getBody(strings.TrimRight(apiURL, "/") + "/markets?active=true&closed=false&limit=100&order=volume24hr&ascending=false")
+	if err != nil {
+		return nil, fmt.Errorf("获取 Polymarket 市场失败: %w", err)
+	}
+	var raw []struct {
+		ID          string          `json:"id"`
+		Question    string          `json:"question"`
+		Description string          `json:"description"`
+		EndDate     string          `json:"endDate"`
+		Outcomes    string          `json:"outcomes"`
+		Volume      json.RawMessage `json:"volume"`
+		Liquidity   json.RawMessage `json:"liquidity"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("解析 Polymarket 市场失败: %w", err)
+	}
+
+	markets := make([]PolymarketMarket, 0, len(raw))
+	for _, r := range raw {
+		if len(keywords) > 0 && !containsAnyFold(r.Question, keywords) {
+			continue
+		}
+		market := PolymarketMarket{
+			ID:          r.ID,
+			Question:    r.Question,
+			Description: r.Description,
+			Volume:      parseLooseFloat(r.Volume),
+			Liquidity:   parseLooseFloat(r.Liquidity),
+			Outcomes:    []string{},
+		}
+		if t, err := time.Parse(time.RFC3339, r.EndDate); err == nil {
+			market.EndDate = t
+		}
+		json.Unmarshal([]byte(r.Outcomes), &market.Outcomes)
+		markets = append(markets, market)
+	}
+	return markets, nil
+}
+
+// FetchTweets 通过 X API v2 最近搜索接口获取推文（排除转推）
+func (m *DataSourceManager) FetchTweets(query string, limit int) ([]SocialPost, error) {
+	if m.twitterBearerToken == "" {
+		return nil, fmt.Errorf("X/Twitter: %w", ErrDataSourceDisabled)
+	}
+	if limit < 10 {
+		limit = 10
+	} else if limit > 100 {
+		limit = 100
+	}
+	params := url.Values{}
+	params.Set("query", query+" -is:retweet")
+	params.Set("max_results", strconv.Itoa(limit))
+	params.Set("tweet.fields", "created_at,public_metrics,author_id")
+	body, err := m.getBodyWithHeaders(m.twitterAPIURL+"/tweets/search/recent?"+params.Encode(),
+		map[string]string{"Authorization": "Bearer " + m.twitterBearerToken})
+	if err != nil {
+		return nil, fmt.Errorf("搜索推文失败: %w", err)
+	}
+	var resp struct {
+		Data []struct {
+			ID            string    `json:"id"`
+			Text          string    `json:"text"`
+			AuthorID      string    `json:"author_id"`
+			CreatedAt     time.Time `json:"created_at"`
+			PublicMetrics struct {
+				LikeCount    int `json:"like_count"`
+				RetweetCount int `json:"retweet_count"`
+			} `json:"public_metrics"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("解析推文失败: %w", err)
+	}
+	posts := make([]SocialPost, 0, len(resp.Data))
+	for _, t := range resp.Data {
+		posts = append(posts, SocialPost{
+			Source:    "twitter",
+			Channel:   t.AuthorID,
+			Text:      t.Text,
+			URL:       "https://x.com/i/web/status/" + t.ID,
+			Likes:     t.PublicMetrics.LikeCount,
+			Reposts:   t.PublicMetrics.RetweetCount,
+			CreatedAt: t.CreatedAt,
+		})
+	}
+	return posts, nil
+}
+
+// Telegram 公开频道网页预览中的消息块
+var (
+	telegramMessagePattern = regexp.MustCompile(`(?s)data-post="([^"]+)".*?class="tgme_widget_message_text[^"]*"[^>]*>(.*?)</div>.*?<time[^>]*datetime="([^"]+)"`)
+	htmlTagPattern         = regexp.MustCompile(`<[^>]+>`)
+)
+
+// FetchTelegramPosts 抓取 Telegram 公开频道网页预览（t.me/s/频道名）中的最新消息
+func (m *DataSourceManager) FetchTelegramPosts(channel string, limit int) ([]SocialPost, error) {
+	channel = strings.TrimPrefix(strings.TrimSpace(channel), "@")
+	body, err := m.getBody(DefaultTelegramWebURL + url.PathEscape(channel))
+	if err != nil {
+		return nil, fmt.Errorf("获取 Telegram 频道 %s 失败: %w", channel, err)
+	}
+	matches := telegramMessagePattern.FindAllSubmatch(body, -1)
+	posts := make([]SocialPost, 0, len(matches))
+	for _, match := range matches {
+		text := htmlTagPattern.ReplaceAllString(strings.ReplaceAll(string(match[2]), "<br/>", "\n"), "")
+		post := SocialPost{
+			Source:  "telegram",
+			Channel: channel,
+			Text:    strings.TrimSpace(html.UnescapeString(text)),
+			URL:     "https://t.me/" + string(match[1]),
+		}
+		if t, err := time.Parse(time.RFC3339, string(match[3])); err == nil {
+			post.CreatedAt = t
+		}
+		if post.Text != "" {
+			posts = append(posts, post)
+		}
+	}
+	// 页面按时间正序排列，保留最新的 limit 条
+	if limit > 0 && len(posts) > limit {
+		posts = posts[len(posts)-limit:]
+	}
+	return posts, nil
+}
+
+// containsAnyFold 是否包含任一关键词（忽略大小写）
+func containsAnyFold(s string, keywords []string) bool {
+	s = strings.ToLower(s)
+	for _, k := range keywords {
+		if strings.Contains(s, strings.ToLower(k)) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLooseFloat 解析字符串或数字形式的浮点数
+func parseLooseFloat(raw json.RawMessage) float64 {
+	s := strings.Trim(string(raw), `"`)
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
@@ -0,0 +1,405 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"quantmesh/logger"
+	"quantmesh/utils"
+)
+
+// 缓存默认参数
+const (
+	defaultNewsCacheTTL       = 5 * time.Minute
+	defaultFearGreedCacheTTL  = time.Hour
+	defaultRedditCacheTTL     = 10 * time.Minute
+	defaultPolymarketCacheTTL = 5 * time.Minute
+	defaultTwitterCacheTTL    = 15 * time.Minute
+	defaultTelegramCacheTTL   = 5 * time.Minute
+	defaultOnChainCacheTTL    = time.Hour
+	defaultRedditPostLimit    = 25
+
+	// 刷新失败后的重试间隔（不超过 TTL）
+	dataSourceRetryInterval = time.Minute
+	// 超过该时间无人读取的数据不再后台刷新
+	dataSourceIdleTimeout = time.Hour
+	// 后台检查间隔
+	dataSourceCheckInterval = 30 * time.Second
+)
+
+// DataSourceCacheConfig 数据源缓存配置
+type DataSourceCacheConfig struct {
+	NewsTTL         time.Duration
+	FearGreedTTL    time.Duration
+	RedditTTL       time.Duration
+	PolymarketTTL   time.Duration
+	TwitterTTL      time.Duration
+	TelegramTTL     time.Duration
+	OnChainTTL      time.Duration
+	RedditPostLimit int    // 每个子版块拉取的帖子数
+	SnapshotPath    string // 快照文件路径，为空则不持久化
+}
+
+// dataSourceEntry 单个数据源的缓存条目
+type dataSourceEntry struct {
+	Data      json.RawMessage `json:"data"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	LastError string          `json:"last_error,omitempty"`
+
+	ttl         time.Duration
+	fetch       func() (interface{}, error)
+	lastAttempt time.Time
+	lastAccess  time.Time
+	inflight    chan struct{}
+}
+
+// CachedDataSource 带缓存的数据源
+// 采用 stale-while-revalidate：有缓存时立即返回（即使已过期），过期数据在后台刷新；
+// 只有从未获取过的数据才会同步请求外部 API，且同一数据源同时只有一个请求。
+// 最新快照保存到磁盘，重启后仪表盘可立即显示上次的数据
+type CachedDataSource struct {
+	source DataSourceAPI
+	cfg    DataSourceCacheConfig
+
+	mu      sync.Mutex
+	entries map[string]*dataSourceEntry
+	dirty   bool
+}
+
+var _ DataSourceAPI = (*CachedDataSource)(nil)
+
+// NewCachedDataSource 创建带缓存的数据源，并加载上次保存的快照
+func NewCachedDataSource(source DataSourceAPI, cfg DataSourceCacheConfig) *CachedDataSource {
+	if cfg.NewsTTL <= 0 {
+		cfg.NewsTTL = defaultNewsCacheTTL
+	}
+	if cfg.FearGreedTTL <= 0 {
+		cfg.FearGreedTTL = defaultFearGreedCacheTTL
+	}
+	if cfg.RedditTTL <= 0 {
+		cfg.RedditTTL = defaultRedditCacheTTL
+	}
+	if cfg.PolymarketTTL <= 0 {
+		cfg.PolymarketTTL = defaultPolymarketCacheTTL
+	}
+	if cfg.TwitterTTL <= 0 {
+		cfg.TwitterTTL = defaultTwitterCacheTTL
+	}
+	if cfg.TelegramTTL <= 0 {
+		cfg.TelegramTTL = defaultTelegramCacheTTL
+	}
+	if cfg.OnChainTTL <= 0 {
+		cfg.OnChainTTL = defaultOnChainCacheTTL
+	}
+	if cfg.RedditPostLimit <= 0 {
+		cfg.RedditPostLimit = defaultRedditPostLimit
+	}
+	c := &CachedDataSource{
+		source:  source,
+		cfg:     cfg,
+		entries: make(map[string]*dataSourceEntry),
+	}
+	c.loadSnapshot()
+	return c
+}
+
+// Start 启动后台刷新，ctx 取消时保存快照并退出
+func (c *CachedDataSource) Start(ctx context.Context) {
+	utils.GoSupervised(ctx, "data-source-cache", func(ctx context.Context) {
+		ticker := time.NewTicker(dataSourceCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				c.saveSnapshot()
+				return
+			case <-ticker.C:
+				c.refreshExpired()
+				c.saveSnapshot()
+			}
+		}
+	})
+}
+
+// FetchRSSFeed 获取 RSS 新闻（缓存）
+func (c *CachedDataSource) FetchRSSFeed(url string) ([]NewsItem, error) {
+	var items []NewsItem
+	err := c.get("rss|"+url, c.cfg.NewsTTL, func() (interface{}, error) {
+		return c.source.FetchRSSFeed(url)
+	}, &items)
+	return items, err
+}
+
+// FetchFearGreedIndex 获取恐慌贪婪指数（缓存）
+func (c *CachedDataSource) FetchFearGreedIndex(apiURL string) (*FearGreedIndex, error) {
+	var index *FearGreedIndex
+	err := c.get("fear_greed|"+apiURL, c.cfg.FearGreedTTL, func() (interface{}, error) {
+		return c.source.FetchFearGreedIndex(apiURL)
+	}, &index)
+	if err == nil && index == nil {
+		return nil, fmt.Errorf("恐慌贪婪指数无数据")
+	}
+	return index, err
+}
+
+// FetchRedditPosts 获取 Reddit 帖子（缓存）
+// 按子版块组合缓存，固定拉取 RedditPostLimit 条，再按 limit 截取每个子版块的帖子
+func (c *CachedDataSource) FetchRedditPosts(subreddits []string, limit int) ([]RedditPost, error) {
+	subs := append([]string(nil), subreddits...)
+	sort.Strings(subs)
+	var posts []RedditPost
+	err := c.get("reddit|"+strings.Join(subs, ","), c.cfg.RedditTTL, func() (interface{}, error) {
+		return c.source.FetchRedditPosts(subs, c.cfg.RedditPostLimit)
+	}, &posts)
+	if err != nil || limit <= 0 {
+		return posts, err
+	}
+	counts := make(map[string]int)
+	result := make([]RedditPost, 0, len(posts))
+	for _, p := range posts {
+		if counts[p.Subreddit] >= limit {
+			continue
+		}
+		counts[p.Subreddit]++
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// FetchPolymarketMarkets 获取 Polymarket 市场（缓存）
+// 缓存完整的市场列表，关键词筛选在本地进行，避免不同搜索词各自请求外部 API
+func (c *CachedDataSource) FetchPolymarketMarkets(apiURL string, keywords []string) ([]PolymarketMarket, error) {
+	var markets []PolymarketMarket
+	err := c.get("polymarket|"+apiURL, c.cfg.PolymarketTTL, func() (interface{}, error) {
+		return c.source.FetchPolymarketMarkets(apiURL, nil)
+	}, &markets)
+	if err != nil || len(keywords) == 0 {
+		return markets, err
+	}
+	filtered := make([]PolymarketMarket, 0, len(markets))
+	for _, m := range markets {
+		if containsAnyFold(m.Question, keywords) {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered, nil
+}
+
+// FetchTweets 搜索推文（缓存，按查询语句和数量区分）
+func (c *CachedDataSource) FetchTweets(query string, limit int) ([]SocialPost, error) {
+	var posts []SocialPost
+	err := c.get(fmt.Sprintf("twitter|%d|%s", limit, query), c.cfg.TwitterTTL, func() (interface{}, error) {
+		return c.source.FetchTweets(query, limit)
+	}, &posts)
+	return posts, err
+}
+
+// FetchTelegramPosts 获取 Telegram 频道消息（缓存，网页预览每次最多返回约 20 条，按 limit 截取）
+func (c *CachedDataSource) FetchTelegramPosts(channel string, limit int) ([]SocialPost, error) {
+	var posts []SocialPost
+	err := c.get("telegram|"+channel, c.cfg.TelegramTTL, func() (interface{}, error) {
+		return c.source.FetchTelegramPosts(channel, 0)
+	}, &posts)
+	if err == nil && limit > 0 && len(posts) > limit {
+		posts = posts[len(posts)-limit:]
+	}
+	return posts, err
+}
+
+// FetchOnChain 获取链上数据快照（缓存）
+func (c *CachedDataSource) FetchOnChain() (*OnChainSnapshot, error) {
+	var snapshot *OnChainSnapshot
+	err := c.get("onchain", c.cfg.OnChainTTL, func() (interface{}, error) {
+		return c.source.FetchOnChain()
+	}, &snapshot)
+	if err == nil && snapshot == nil {
+		return nil, fmt.Errorf("链上数据为空")
+	}
+	return snapshot, err
+}
+
+// get 读取缓存：有数据立即返回，过期则触发后台刷新；无数据时同步获取
+func (c *CachedDataSource) get(key string, ttl time.Duration, fetch func() (interface{}, error), out interface{}) error {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		e = &dataSourceEntry{}
+		c.entries[key] = e
+	}
+	e.ttl = ttl
+	e.fetch = fetch
+	e.lastAccess = time.Now()
+
+	if e.Data != nil {
+		if c.needsRefresh(e, time.Now()) {
+			c.startRefresh(key, e)
+		}
+		data := e.Data
+		c.mu.Unlock()
+		return json.Unmarshal(data, out)
+	}
+
+	// 首次获取：已有请求在进行时等待其完成；最近刚失败过则直接返回错误，避免每次请求都打到外部 API
+	done := e.inflight
+	if done == nil {
+		if e.LastError != "" && !c.needsRefresh(e, time.Now()) {
+			lastErr := e.LastError
+			c.mu.Unlock()
+			return fmt.Errorf("%s", lastErr)
+		}
+		done = c.startRefresh(key, e)
+	}
+	c.mu.Unlock()
+	<-done
+
+	c.mu.Lock()
+	data, lastErr := e.Data, e.LastError
+	c.mu.Unlock()
+	if data == nil {
+		return fmt.Errorf("%s", lastErr)
+	}
+	return json.Unmarshal(data, out)
+}
+
+// needsRefresh 数据已过期、当前未在刷新，且距上次失败已超过重试间隔（调用方持有锁）
+func (c *CachedDataSource) needsRefresh(e *dataSourceEntry, now time.Time) bool {
+	if e.inflight != nil || e.fetch == nil {
+		return false
+	}
+	if now.Sub(e.FetchedAt) < e.ttl {
+		return false
+	}
+	retry := dataSourceRetryInterval
+	if e.ttl < retry {
+		retry = e.ttl
+	}
+	return now.Sub(e.lastAttempt) >= retry
+}
+
+// startRefresh 在后台刷新条目，返回刷新完成时关闭的通道（调用方持有锁）
+func (c *CachedDataSource) startRefresh(key string, e *dataSourceEntry) chan struct{} {
+	done := make(chan struct{})
+	e.inflight = done
+	e.lastAttempt = time.Now()
+	fetch := e.fetch
+
+	go func() {
+		value, err := fetchRecovered(key, fetch)
+		var data []byte
+		if err == nil {
+			data, err = json.Marshal(value)
+		}
+
+		c.mu.Lock()
+		if err != nil {
+			e.LastError = err.Error()
+			if !errors.Is(err, ErrDataSourceDisabled) {
+				logger.Warn("⚠️ [市场情报] 刷新 %s 失败: %v", key, err)
+			}
+		} else {
+			e.Data = data
+			e.FetchedAt = time.Now()
+			e.LastError = ""
+			c.dirty = true
+		}
+		e.inflight = nil
+		c.mu.Unlock()
+		close(done)
+	}()
+	return done
+}
+
+// fetchRecovered 调用数据源，panic 转为错误，保证刷新协程总能清除 inflight 并唤醒等待者
+func fetchRecovered(key string, fetch func() (interface{}, error)) (value interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("❌ [市场情报] 刷新 %s panic: %v\n%s", key, r, debug.Stack())
+			value, err = nil, fmt.Errorf("刷新 %s panic: %v", key, r)
+		}
+	}()
+	return fetch()
+}
+
+// refreshExpired 刷新最近有人读取且已过期的条目
+func (c *CachedDataSource) refreshExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for key, e := range c.entries {
+		if now.Sub(e.lastAccess) > dataSourceIdleTimeout {
+			continue
+		}
+		if c.needsRefresh(e, now) {
+			c.startRefresh(key, e)
+		}
+	}
+}
+
+// loadSnapshot 加载上次保存的快照（加载的条目视为已过期前的旧数据，首次读取后按 TTL 刷新）
+func (c *CachedDataSource) loadSnapshot() {
+	if c.cfg.SnapshotPath == "" {
+		return
+	}
+	data, err := os.ReadFile(c.cfg.SnapshotPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("⚠️ [市场情报] 读取缓存快照失败: %v", err)
+		}
+		return
+	}
+	entries := make(map[string]*dataSourceEntry)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		logger.Warn("⚠️ [市场情报] 解析缓存快照失败: %v", err)
+		return
+	}
+	for key, e := range entries {
+		if e == nil || e.Data == nil {
+			continue
+		}
+		c.entries[key] = e
+	}
+	logger.Info("✅ [市场情报] 已加载 %d 条缓存快照", len(c.entries))
+}
+
+// saveSnapshot 有新数据时将快照写入磁盘（先写临时文件再重命名）
+func (c *CachedDataSource) saveSnapshot() {
+	if c.cfg.SnapshotPath == "" {
+		return
+	}
+	c.mu.Lock()
+	if !c.dirty {
+		c.mu.Unlock()
+		return
+	}
+	snapshot := make(map[string]*dataSourceEntry, len(c.entries))
+	for key, e := range c.entries {
+		if e.Data != nil {
+			snapshot[key] = &dataSourceEntry{Data: e.Data, FetchedAt: e.FetchedAt, LastError: e.LastError}
+		}
+	}
+	c.dirty = false
+	c.mu.Unlock()
+
+	data, err := json.Marshal(snapshot)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(c.cfg.SnapshotPath), 0755)
+	}
+	if err == nil {
+		tmp := c.cfg.SnapshotPath + ".tmp"
+		if err = os.WriteFile(tmp, data, 0644); err == nil {
+			err = os.Rename(tmp, c.cfg.SnapshotPath)
+		}
+	}
+	if err != nil {
+		logger.Warn("⚠️ [市场情报] 保存缓存快照失败: %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package ai
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubDataSource 可控的数据源：只实现恐慌贪婪指数，其余返回未启用
+type stubDataSource struct {
+	calls int32
+	fetch func(call int32) (*FearGreedIndex, error)
+}
+
+func (s *stubDataSource) FetchFearGreedIndex(apiURL string) (*FearGreedIndex, error) {
+	return s.fetch(atomic.AddInt32(&s.calls, 1))
+}
+
+func (s *stubDataSource) FetchRSSFeed(url string) ([]NewsItem, error) {
+	return nil, ErrDataSourceDisabled
+}
+
+func (s *stubDataSource) FetchRedditPosts(subreddits []string, limit int) ([]RedditPost, error) {
+	return nil, ErrDataSourceDisabled
+}
+
+func (s *stubDataSource) FetchPolymarketMarkets(apiURL string, keywords []string) ([]PolymarketMarket, error) {
+	return nil, ErrDataSourceDisabled
+}
+
+func (s *stubDataSource) FetchTweets(query string, limit int) ([]SocialPost, error) {
+	return nil, ErrDataSourceDisabled
+}
+
+func (s *stubDataSource) FetchTelegramPosts(channel string, limit int) ([]SocialPost, error) {
+	return nil, ErrDataSourceDisabled
+}
+
+func (s *stubDataSource) FetchOnChain() (*OnChainSnapshot, error) {
+	return nil, ErrDataSourceDisabled
+}
+
+func (s *stubDataSource) callCount() int32 {
+	return atomic.LoadInt32(&s.calls)
+}
+
+const fearGreedKey = "fear_greed|api"
+
+// age 把条目的获取和尝试时间往前推，模拟时间流逝
+func (c *CachedDataSource) age(key string, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.entries[key]
+	e.FetchedAt = e.FetchedAt.Add(-d)
+	e.lastAttempt = e.lastAttempt.Add(-d)
+}
+
+// waitIdle 等待后台刷新结束
+func (c *CachedDataSource) waitIdle(t *testing.T, key string) {
+	t.Helper()
+	c.mu.Lock()
+	done := c.entries[key].inflight
+	c.mu.Unlock()
+	if done == nil {
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("refresh did not finish")
+	}
+}
+
+func TestCachedDataSourceStaleWhileRevalidate(t *testing.T) {
+	release := make(chan struct{})
+	src := &stubDataSource{fetch: func(call int32) (*FearGreedIndex, error) {
+		if call > 1 {
+			<-release
+		}
+		return &FearGreedIndex{Value: int(call)}, nil
+	}}
+	c := NewCachedDataSource(src, DataSourceCacheConfig{FearGreedTTL: time.Minute})
+
+	index, err := c.FetchFearGreedIndex("api")
+	if err != nil || index.Value != 1 {
+		t.Fatalf("first fetch = %+v, %v", index, err)
+	}
+
+	// 未过期：直接读缓存
+	if index, _ = c.FetchFearGreedIndex("api"); index.Value != 1 || src.callCount() != 1 {
+		t.Fatalf("fresh read = %+v after %d calls", index, src.callCount())
+	}
+
+	// 已过期：立即返回旧数据，后台刷新
+	c.age(fearGreedKey, 2*time.Minute)
+	returned := make(chan *FearGreedIndex, 1)
+	go func() {
+		index, _ := c.FetchFearGreedIndex("api")
+		returned <- index
+	}()
+	select {
+	case index = <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("stale read blocked on refresh")
+	}
+	if index.Value != 1 {
+		t.Fatalf("stale read = %+v, want cached value", index)
+	}
+
+	close(release)
+	c.waitIdle(t, fearGreedKey)
+	if index, _ = c.FetchFearGreedIndex("api"); index.Value != 2 || src.callCount() != 2 {
+		t.Fatalf("after refresh = %+v, calls = %d", index, src.callCount())
+	}
+}
+
+func TestCachedDataSourceDeduplicatesInflight(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 8)
+	src := &stubDataSource{fetch: func(call int32) (*FearGreedIndex, error) {
+		started <- struct{}{}
+		<-release
+		return &FearGreedIndex{Value: 42}, nil
+	}}
+	c := NewCachedDataSource(src, DataSourceCacheConfig{})
+
+	const readers = 8
+	var wg sync.WaitGroup
+	results := make(chan int, readers)
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if index, err := c.FetchFearGreedIndex("api"); err == nil {
+				results <- index.Value
+			}
+		}()
+	}
+	<-started
+	time.Sleep(20 * time.Millisecond) // 让其余读者进入等待
+	close(release)
+	wg.Wait()
+	close(results)
+
+	n := 0
+	for v := range results {
+		if v != 42 {
+			t.Fatalf("reader got %d", v)
+		}
+		n++
+	}
+	if n != readers || src.callCount() != 1 {
+		t.Fatalf("%d readers served with %d fetches, want %d with 1", n, src.callCount(), readers)
+	}
+}
+
+func TestCachedDataSourceRetryBackoff(t *testing.T) {
+	fail := true
+	var mu sync.Mutex
+	src := &stubDataSource{fetch: func(call int32) (*FearGreedIndex, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			return nil, errors.New("upstream down")
+		}
+		return &FearGreedIndex{Value: int(call)}, nil
+	}}
+	c := NewCachedDataSource(src, DataSourceCacheConfig{FearGreedTTL: time.Hour})
+
+	// 首次失败：返回错误，重试间隔内不再请求外部 API
+	if _, err := c.FetchFearGreedIndex("api"); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := c.FetchFearGreedIndex("api"); err == nil || err.Error() != "upstream down" {
+		t.Fatalf("cached error = %v", err)
+	}
+	if src.callCount() != 1 {
+		t.Fatalf("calls = %d within retry interval, want 1", src.callCount())
+	}
+
+	// 超过重试间隔后重试
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	c.age(fearGreedKey, dataSourceRetryInterval)
+	index, err := c.FetchFearGreedIndex("api")
+	if err != nil || index.Value != 2 {
+		t.Fatalf("retry = %+v, %v", index, err)
+	}
+
+	// 有数据时刷新失败：继续返回旧数据，同样按重试间隔退避
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+	c.age(fearGreedKey, 2*time.Hour)
+	if index, _ = c.FetchFearGreedIndex("api"); index.Value != 2 {
+		t.Fatalf("stale read = %+v", index)
+	}
+	c.waitIdle(t, fearGreedKey)
+	if index, _ = c.FetchFearGreedIndex("api"); index.Value != 2 {
+		t.Fatalf("read after failed refresh = %+v", index)
+	}
+	if src.callCount() != 3 {
+		t.Fatalf("calls = %d, want no retry before the interval", src.callCount())
+	}
+	c.age(fearGreedKey, dataSourceRetryInterval)
+	c.FetchFearGreedIndex("api")
+	c.waitIdle(t, fearGreedKey)
+	if src.callCount() != 4 {
+		t.Fatalf("calls = %d, want retry after the interval", src.callCount())
+	}
+}
+
+func TestCachedDataSourceRecoversFetchPanic(t *testing.T) {
+	src := &stubDataSource{fetch: func(call int32) (*FearGreedIndex, error) {
+		if call == 1 {
+			panic("decoder bug")
+		}
+		return &FearGreedIndex{Value: 7}, nil
+	}}
+	c := NewCachedDataSource(src, DataSourceCacheConfig{})
+
+	if _, err := c.FetchFearGreedIndex("api"); err == nil {
+		t.Fatal("panicking fetch returned no error")
+	}
+	c.mu.Lock()
+	inflight := c.entries[fearGreedKey].inflight
+	c.mu.Unlock()
+	if inflight != nil {
+		t.Fatal("panicking fetch left the entry in flight")
+	}
+
+	c.age(fearGreedKey, dataSourceRetryInterval)
+	if index, err := c.FetchFearGreedIndex("api"); err != nil || index.Value != 7 {
+		t.Fatalf("retry after panic = %+v, %v", index, err)
+	}
+}
+
+func TestCachedDataSourceSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "snapshot.json")
+	src := &stubDataSource{fetch: func(call int32) (*FearGreedIndex, error) {
+		return &FearGreedIndex{Value: 55, Classification: "Greed"}, nil
+	}}
+	c := NewCachedDataSource(src, DataSourceCacheConfig{SnapshotPath: path})
+	if _, err := c.FetchFearGreedIndex("api"); err != nil {
+		t.Fatal(err)
+	}
+	c.saveSnapshot()
+
+	// 重启后从快照读取，未过期时不请求外部 API
+	restartSrc := &stubDataSource{fetch: func(call int32) (*FearGreedIndex, error) {
+		return nil, errors.New("offline")
+	}}
+	restarted := NewCachedDataSource(restartSrc, DataSourceCacheConfig{SnapshotPath: path})
+	index, err := restarted.FetchFearGreedIndex("api")
+	if err != nil || index.Value != 55 || index.Classification != "Greed" {
+		t.Fatalf("restored = %+v, %v", index, err)
+	}
+	if restartSrc.callCount() != 0 {
+		t.Fatalf("fresh snapshot triggered %d fetches", restartSrc.callCount())
+	}
+
+	// 没有新数据时不重写快照
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	restarted.saveSnapshot()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("snapshot rewritten without new data: %v", err)
+	}
+}
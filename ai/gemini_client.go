@@ -34,20 +34,30 @@ func NewGeminiClient(apiKey string) GeminiClient {
 
 // GenerateConfig 生成配置建议
 func (c *AsyncGeminiClient) GenerateConfig(ctx context.Context, req *GenerateConfigRequest) (*GenerateConfigResponse, error) {
+	return generateConfig(ctx, c, req)
+}
+
+// generateConfig 生成并校验配置，输出不合法（无法解析、缺字段、超出护栏）时拒绝并重新提示
+func generateConfig(ctx context.Context, client GeminiClient, req *GenerateConfigRequest) (*GenerateConfigResponse, error) {
 	prompt := buildPrompt(req)
 	schema := buildConfigSchema()
 
-	aiText, err := c.GenerateContent(ctx, prompt, schema)
+	var result *GenerateConfigResponse
+	err := generateValidated(ctx, client, "配置生成", prompt, schema, func(aiText string) error {
+		var parsed GenerateConfigResponse
+		if err := json.Unmarshal([]byte(aiText), &parsed); err != nil {
+			return fmt.Errorf("解析 AI 配置失败: %w", err)
+		}
+		if err := ValidateGeneratedConfig(&parsed, req); err != nil {
+			return err
+		}
+		result = &parsed
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	var result GenerateConfigResponse
-	if err := json.Unmarshal([]byte(aiText), &result); err != nil {
-		return nil, fmt.Errorf("解析 AI 配置失败: %w (响应: %s)", err, aiText)
-	}
-
-	return &result, nil
+	return result, nil
 }
 
 // GenerateContent 生成内容（通过内置异步系统）
@@ -235,6 +245,16 @@ func buildPrompt(req *GenerateConfigRequest) string {
 		prompt += fmt.Sprintf("- %s: $%.2f\n", symbol, price)
 	}
 
+	guardrails := CurrentGuardrails()
+	prompt += fmt.Sprintf("\n硬性限制（超出将被拒绝）：\n- 买/卖单窗口不超过 %d\n", guardrails.MaxWindowSize)
+	if guardrails.MaxLeverage > 0 {
+		prompt += fmt.Sprintf("- 杠杆不超过 %d 倍\n", guardrails.MaxLeverage)
+	}
+	if guardrails.MaxOrderSize > 0 {
+		prompt += fmt.Sprintf("- 每单金额不超过 %.2f USDT\n", guardrails.MaxOrderSize)
+	}
+	prompt += fmt.Sprintf("- 策略类型只能是 %v\n", AllowedStrategyTypes)
+
 	prompt += `
 请提供一个详细的配置方案，要求：
 1. **资产分配层**：为每个币种设定 symbol_config，包括其分配的总资金 (total_allocated_capital)。
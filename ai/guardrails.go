@@ -0,0 +1,289 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+)
+
+// AllowedStrategyTypes AI 可以配置的策略类型
+var AllowedStrategyTypes = []string{"grid", "dca", "martingale", "dca_enhanced", "combo"}
+
+var (
+	riskLevels         = []string{"low", "medium", "high", "extreme"}
+	riskSeverities     = []string{"low", "medium", "high", "critical"}
+	suggestionPriority = []string{"high", "medium", "low"}
+)
+
+// guardrailSettings 当前生效的护栏（硬性上限 + 可选的风控档位）
+type guardrailSettings struct {
+	limits   config.AIGuardrails
+	profiles map[string]config.RiskProfile
+}
+
+var (
+	guardrailsMu     sync.RWMutex
+	activeGuardrails = guardrailSettings{
+		limits:   config.AIGuardrails{MaxLeverage: 10, MaxWindowSize: 100, MaxRetries: 2},
+		profiles: config.BuiltinRiskProfiles(),
+	}
+)
+
+// SetGuardrails 按配置更新 AI 输出护栏（启动和配置热更新时调用）
+func SetGuardrails(cfg *config.Config) {
+	g := guardrailSettings{limits: cfg.ResolvedAIGuardrails(), profiles: cfg.AllRiskProfiles()}
+	guardrailsMu.Lock()
+	activeGuardrails = g
+	guardrailsMu.Unlock()
+}
+
+// CurrentGuardrails 当前生效的护栏上限
+func CurrentGuardrails() config.AIGuardrails {
+	return currentGuardrails().limits
+}
+
+func currentGuardrails() guardrailSettings {
+	guardrailsMu.RLock()
+	defer guardrailsMu.RUnlock()
+	return activeGuardrails
+}
+
+// ValidationError AI 输出校验失败，Problems 会作为反馈附在重新提示的提示词中
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "AI 输出校验失败: " + strings.Join(e.Problems, "; ")
+}
+
+// validator 收集全部校验问题，一次反馈给 AI
+type validator struct {
+	problems []string
+}
+
+func (v *validator) check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		v.problems = append(v.problems, fmt.Sprintf(format, args...))
+	}
+}
+
+func (v *validator) oneOf(field, value string, allowed []string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.check(false, "%s 取值 %q 不在允许范围 %v 内", field, value, allowed)
+}
+
+func (v *validator) ratio(field string, value float64) {
+	v.check(value >= 0 && value <= 1, "%s 必须在 0~1 之间（当前 %v）", field, value)
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+// ValidateGeneratedConfig 校验 AI 生成的配置：必填字段、币种/交易所白名单、参数范围、资金总额与护栏上限
+func ValidateGeneratedConfig(resp *GenerateConfigResponse, req *GenerateConfigRequest) error {
+	if resp == nil {
+		return &ValidationError{Problems: []string{"响应为空"}}
+	}
+	v := &validator{}
+	v.check(strings.TrimSpace(resp.Explanation) != "", "缺少 explanation")
+	v.check(len(resp.SymbolsConfig) > 0 || len(resp.GridConfig) > 0, "缺少 symbols_config")
+
+	var totalCapital float64
+	if req != nil {
+		totalCapital = req.TotalCapital
+		if req.CapitalMode == "per_symbol" && len(req.SymbolCapitals) > 0 {
+			totalCapital = 0
+			for _, sc := range req.SymbolCapitals {
+				totalCapital += sc.Capital
+			}
+		}
+		requested := make(map[string]bool, len(req.Symbols))
+		for _, s := range req.Symbols {
+			requested[s] = true
+		}
+		checkSymbol := func(exchange, symbol string) {
+			if len(requested) > 0 {
+				v.check(requested[symbol], "币种 %s 不在请求的币种 %v 中", symbol, req.Symbols)
+			}
+			v.check(exchange == "" || req.Exchange == "" || exchange == req.Exchange, "%s 的交易所 %s 与请求的 %s 不一致", symbol, exchange, req.Exchange)
+		}
+		for _, s := range resp.SymbolsConfig {
+			checkSymbol(s.Exchange, s.Symbol)
+		}
+		for _, g := range resp.GridConfig {
+			checkSymbol(g.Exchange, g.Symbol)
+		}
+		for _, a := range resp.Allocation {
+			checkSymbol(a.Exchange, a.Symbol)
+		}
+	}
+	checkConfigResponse(v, resp, totalCapital, currentGuardrails())
+	return v.err()
+}
+
+// checkConfigResponse 参数范围、资金与护栏检查（totalCapital <= 0 时不检查资金总额）
+func checkConfigResponse(v *validator, resp *GenerateConfigResponse, totalCapital float64, g guardrailSettings) {
+	limits := g.limits
+	checkOrderSize := func(symbol string, quantity, capital float64) {
+		v.check(quantity > 0, "%s 每单金额必须大于0", symbol)
+		if limits.MaxOrderSize > 0 {
+			v.check(quantity <= limits.MaxOrderSize, "%s 每单金额 %.2f 超过护栏上限 %.2f USDT", symbol, quantity, limits.MaxOrderSize)
+		}
+		if capital > 0 {
+			v.check(quantity <= capital, "%s 每单金额 %.2f 超过该币种分配资金 %.2f", symbol, quantity, capital)
+		}
+	}
+	checkWindows := func(symbol string, buy, sell int) {
+		v.check(buy > 0 && sell > 0, "%s 窗口大小必须大于0", symbol)
+		if limits.MaxWindowSize > 0 {
+			v.check(buy <= limits.MaxWindowSize && sell <= limits.MaxWindowSize, "%s 窗口大小超过护栏上限 %d", symbol, limits.MaxWindowSize)
+		}
+	}
+	checkLeverage := func(field string, leverage float64) {
+		if limits.MaxLeverage > 0 {
+			v.check(leverage <= float64(limits.MaxLeverage), "%s 杠杆 %v 超过护栏上限 %d", field, leverage, limits.MaxLeverage)
+		}
+	}
+
+	allocated := make(map[string]float64)
+	var totalAllocated float64
+	for _, a := range resp.Allocation {
+		v.check(a.MaxAmountUSDT >= 0, "%s 分配资金不能为负数", a.Symbol)
+		allocated[a.Symbol] = a.MaxAmountUSDT
+		totalAllocated += a.MaxAmountUSDT
+	}
+	var symbolsAllocated float64
+	for _, s := range resp.SymbolsConfig {
+		symbolsAllocated += s.TotalAllocatedCapital
+	}
+	if totalCapital > 0 {
+		v.check(totalAllocated <= totalCapital, "资金分配总和 (%.2f USDT) 超过可用资金 (%.2f USDT)", totalAllocated, totalCapital)
+		v.check(symbolsAllocated <= totalCapital, "币种分配资金总和 (%.2f USDT) 超过可用资金 (%.2f USDT)", symbolsAllocated, totalCapital)
+	}
+
+	for _, grid := range resp.GridConfig {
+		v.check(grid.Symbol != "", "grid_config 缺少 symbol")
+		v.check(grid.PriceInterval > 0, "%s 价格间隔必须大于0", grid.Symbol)
+		checkOrderSize(grid.Symbol, grid.OrderQuantity, allocated[grid.Symbol])
+		checkWindows(grid.Symbol, grid.BuyWindowSize, grid.SellWindowSize)
+		if rc := grid.GridRiskControl; rc != nil && rc.Enabled {
+			v.ratio(grid.Symbol+" 止损比例", rc.StopLossRatio)
+			v.ratio(grid.Symbol+" 盈利触发比例", rc.TakeProfitTriggerRatio)
+			v.ratio(grid.Symbol+" 回撤止盈比例", rc.TrailingTakeProfitRatio)
+			v.check(rc.MaxGridLayers >= 0, "%s 最大层数不能为负数", grid.Symbol)
+		}
+	}
+
+	for _, s := range resp.SymbolsConfig {
+		v.check(s.Symbol != "", "symbols_config 缺少 symbol")
+		v.check(s.TotalAllocatedCapital >= 0, "%s 分配资金不能为负数", s.Symbol)
+		v.check(s.PriceInterval > 0, "%s 价格间隔必须大于0", s.Symbol)
+		checkOrderSize(s.Symbol, s.OrderQuantity, s.TotalAllocatedCapital)
+		checkWindows(s.Symbol, s.BuyWindowSize, s.SellWindowSize)
+		if rc := s.GridRiskControl; rc.Enabled {
+			v.ratio(s.Symbol+" 止损比例", rc.StopLossRatio)
+			v.ratio(s.Symbol+" 盈利触发比例", rc.TakeProfitTriggerRatio)
+			v.ratio(s.Symbol+" 回撤止盈比例", rc.TrailingTakeProfitRatio)
+			v.check(rc.MaxGridLayers >= 0, "%s 最大层数不能为负数", s.Symbol)
+		}
+		if s.WithdrawalPolicy.Enabled {
+			v.ratio(s.Symbol+" 提现阈值", s.WithdrawalPolicy.Threshold)
+		}
+		if s.RiskProfile != "" {
+			profile, ok := g.profiles[s.RiskProfile]
+			v.check(ok, "%s 风控档位 %q 不存在", s.Symbol, s.RiskProfile)
+			if ok {
+				checkLeverage(s.Symbol+" 风控档位 "+s.RiskProfile, float64(profile.MaxLeverage))
+			}
+		}
+
+		var weights float64
+		for _, st := range s.Strategies {
+			v.oneOf(s.Symbol+" 策略类型", st.Type, AllowedStrategyTypes)
+			v.ratio(s.Symbol+" 策略权重", st.Weight)
+			weights += st.Weight
+			if leverage, ok := numberParam(st.Config, "leverage"); ok {
+				checkLeverage(s.Symbol+" "+st.Type+" 策略", leverage)
+			}
+			if quantity, ok := numberParam(st.Config, "order_quantity"); ok && limits.MaxOrderSize > 0 {
+				v.check(quantity <= limits.MaxOrderSize, "%s %s 策略每单金额 %.2f 超过护栏上限 %.2f USDT", s.Symbol, st.Type, quantity, limits.MaxOrderSize)
+			}
+		}
+		v.check(weights <= 1+1e-6, "%s 策略权重之和 %.2f 超过 1", s.Symbol, weights)
+	}
+}
+
+// numberParam 读取策略参数中的数值
+func numberParam(params map[string]interface{}, key string) (float64, bool) {
+	switch n := params[key].(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// ValidateRiskAssessment 校验 AI 风险评估结果的评分范围与枚举字段
+func ValidateRiskAssessment(resp *RiskAssessmentResponse) error {
+	if resp == nil {
+		return &ValidationError{Problems: []string{"响应为空"}}
+	}
+	v := &validator{}
+	v.check(resp.OverallScore >= 0 && resp.OverallScore <= 100, "overall_score 必须在 0~100 之间（当前 %d）", resp.OverallScore)
+	v.oneOf("risk_level", resp.RiskLevel, riskLevels)
+	b := resp.ScoreBreakdown
+	for field, score := range map[string]int{
+		"capital_management": b.CapitalManagement,
+		"risk_control":       b.RiskControl,
+		"strategy_fit":       b.StrategyFit,
+		"market_condition":   b.MarketCondition,
+	} {
+		v.check(score >= 0 && score <= 25, "score_breakdown.%s 必须在 0~25 之间（当前 %d）", field, score)
+	}
+	for _, f := range resp.RiskFactors {
+		v.oneOf("risk_factors.severity", f.Severity, riskSeverities)
+	}
+	for _, s := range resp.Suggestions {
+		v.oneOf("suggestions.priority", s.Priority, suggestionPriority)
+	}
+	v.check(strings.TrimSpace(resp.Summary) != "", "缺少 summary")
+	return v.err()
+}
+
+// generateValidated 调用 AI 并用 decode 解析、校验输出；不合法时把问题附在提示词后重新请求，
+// 最多重试 guardrails.max_retries 次。请求本身失败（网络、任务超时）直接返回，不重新提示
+func generateValidated(ctx context.Context, client GeminiClient, task, prompt string, schema map[string]interface{}, decode func(text string) error) error {
+	maxRetries := CurrentGuardrails().MaxRetries
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		p := prompt
+		if lastErr != nil {
+			p = prompt + fmt.Sprintf("\n\n注意：你上一次的输出被拒绝，原因：%v\n请修正上述问题后重新输出完整的 JSON，所有参数不得超过给定的限制。\n", lastErr)
+		}
+		text, err := client.GenerateContent(ctx, p, schema)
+		if err != nil {
+			return err
+		}
+		if lastErr = decode(text); lastErr == nil {
+			return nil
+		}
+		logger.Warn("⚠️ [AI校验] %s 第 %d 次输出被拒绝: %v", task, attempt+1, lastErr)
+	}
+	return fmt.Errorf("%s 连续 %d 次输出未通过校验: %w", task, maxRetries+1, lastErr)
+}
@@ -0,0 +1,130 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"quantmesh/config"
+)
+
+// scriptedClient 按顺序返回预设输出，并记录收到的提示词
+type scriptedClient struct {
+	outputs []string
+	prompts []string
+}
+
+func (c *scriptedClient) GenerateConfig(ctx context.Context, req *GenerateConfigRequest) (*GenerateConfigResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *scriptedClient) GenerateContent(ctx context.Context, prompt string, schema map[string]interface{}) (string, error) {
+	c.prompts = append(c.prompts, prompt)
+	out := c.outputs[0]
+	c.outputs = c.outputs[1:]
+	return out, nil
+}
+
+func withGuardrails(t *testing.T, g config.AIGuardrails) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.AI.Guardrails = g
+	SetGuardrails(cfg)
+	t.Cleanup(func() {
+		reset := &config.Config{}
+		reset.RiskControl.MaxLeverage = 10
+		SetGuardrails(reset)
+	})
+}
+
+func TestValidateGeneratedConfigGuardrails(t *testing.T) {
+	withGuardrails(t, config.AIGuardrails{MaxLeverage: 5, MaxOrderSize: 50, MaxWindowSize: 30})
+	req := &GenerateConfigRequest{Exchange: "binance", Symbols: []string{"BTCUSDT"}, TotalCapital: 1000}
+	valid := func() *GenerateConfigResponse {
+		return &GenerateConfigResponse{
+			Explanation: "ok",
+			SymbolsConfig: []config.SymbolConfig{{
+				Symbol: "BTCUSDT", TotalAllocatedCapital: 800, PriceInterval: 10, OrderQuantity: 20,
+				BuyWindowSize: 10, SellWindowSize: 10, RiskProfile: config.RiskProfileBalanced,
+				Strategies: []config.StrategyInstance{{Type: "grid", Weight: 0.7}, {Type: "dca", Weight: 0.3}},
+			}},
+		}
+	}
+	if err := ValidateGeneratedConfig(valid(), req); err != nil {
+		t.Fatalf("valid config rejected: %v", err)
+	}
+
+	cases := map[string]func(r *GenerateConfigResponse){
+		"missing explanation": func(r *GenerateConfigResponse) { r.Explanation = "" },
+		"unknown symbol":      func(r *GenerateConfigResponse) { r.SymbolsConfig[0].Symbol = "DOGEUSDT" },
+		"order size":          func(r *GenerateConfigResponse) { r.SymbolsConfig[0].OrderQuantity = 80 },
+		"window size":         func(r *GenerateConfigResponse) { r.SymbolsConfig[0].BuyWindowSize = 50 },
+		"over capital":        func(r *GenerateConfigResponse) { r.SymbolsConfig[0].TotalAllocatedCapital = 1200 },
+		"strategy enum":       func(r *GenerateConfigResponse) { r.SymbolsConfig[0].Strategies[1].Type = "yolo" },
+		"profile leverage":    func(r *GenerateConfigResponse) { r.SymbolsConfig[0].RiskProfile = config.RiskProfileAggressive },
+		"strategy leverage": func(r *GenerateConfigResponse) {
+			r.SymbolsConfig[0].Strategies[0].Config = map[string]interface{}{"leverage": 20.0}
+		},
+	}
+	for name, mutate := range cases {
+		r := valid()
+		mutate(r)
+		var validationErr *ValidationError
+		if err := ValidateGeneratedConfig(r, req); !errors.As(err, &validationErr) {
+			t.Errorf("%s: expected validation error, got %v", name, err)
+		}
+	}
+}
+
+func TestGenerateConfigRepromptsOnInvalidOutput(t *testing.T) {
+	withGuardrails(t, config.AIGuardrails{MaxOrderSize: 50, MaxRetries: 1})
+	req := &GenerateConfigRequest{Exchange: "binance", Symbols: []string{"BTCUSDT"}, TotalCapital: 1000}
+	invalid := `{"explanation":"x","symbols_config":[{"symbol":"BTCUSDT","total_allocated_capital":500,"price_interval":10,"order_quantity":100,"buy_window_size":10,"sell_window_size":10}]}`
+	valid := strings.Replace(invalid, `"order_quantity":100`, `"order_quantity":20`, 1)
+
+	// 重试次数用完仍不合法：任务失败
+	client := &scriptedClient{outputs: []string{"not json", invalid, valid}}
+	if _, err := generateConfig(context.Background(), client, req); err == nil {
+		t.Fatal("expected failure after exhausting retries")
+	}
+	if len(client.prompts) != 2 || !strings.Contains(client.prompts[1], "解析 AI 配置失败") {
+		t.Fatalf("retry prompt should carry the previous error: %q", client.prompts)
+	}
+
+	client = &scriptedClient{outputs: []string{invalid, valid}}
+	result, err := generateConfig(context.Background(), client, req)
+	if err != nil {
+		t.Fatalf("expected success on retry: %v", err)
+	}
+	if result.SymbolsConfig[0].OrderQuantity != 20 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if !strings.Contains(client.prompts[0], "每单金额不超过 50.00 USDT") {
+		t.Errorf("prompt should state the guardrail limits")
+	}
+	if !strings.Contains(client.prompts[1], "超过护栏上限") {
+		t.Errorf("retry prompt should explain the guardrail violation: %q", client.prompts[1])
+	}
+}
+
+func TestValidateRiskAssessment(t *testing.T) {
+	resp := &RiskAssessmentResponse{
+		OverallScore:   70,
+		RiskLevel:      "medium",
+		ScoreBreakdown: ScoreBreakdown{CapitalManagement: 20, RiskControl: 15, StrategyFit: 20, MarketCondition: 15},
+		RiskFactors:    []RiskFactor{{Severity: "high"}},
+		Suggestions:    []Suggestion{{Priority: "low"}},
+		Summary:        "ok",
+	}
+	if err := ValidateRiskAssessment(resp); err != nil {
+		t.Fatalf("valid assessment rejected: %v", err)
+	}
+	resp.OverallScore = 130
+	resp.RiskLevel = "apocalyptic"
+	err := ValidateRiskAssessment(resp)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Problems) != 2 {
+		t.Errorf("expected 2 problems, got %v", err)
+	}
+}
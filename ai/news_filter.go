@@ -0,0 +1,180 @@
+package ai
+
+import (
+	"hash/fnv"
+	"strings"
+)
+
+// 新闻过滤默认参数
+const (
+	defaultDedupThreshold = 0.8 // MinHash 估计的 Jaccard 相似度达到该值视为重复
+	defaultMinRelevance   = 0.3 // 正文只提到一次关键词的相关度约为 0.33
+	minHashSize           = 64
+	shingleSize           = 3
+)
+
+// NewsText 待过滤的文本条目（新闻、帖子、推文等），标题为空时只有正文
+type NewsText struct {
+	Title string
+	Body  string
+}
+
+// Text 标题与正文拼接后的文本
+func (t NewsText) Text() string {
+	if t.Title == "" {
+		return t.Body
+	}
+	return t.Title + " " + t.Body
+}
+
+// NewsFilter 新闻去重与相关度过滤
+// 去重：按词 3-gram 计算 MinHash 签名，估计的 Jaccard 相似度超过阈值的条目只保留最先出现的一条，
+// 可识别多个 RSS 源转载、标题略有改动的同一新闻以及转推；
+// 相关度：标题命中关键词记为 1，仅正文命中按次数递增（1 次约 0.33，2 次 0.5），低于阈值的条目丢弃
+type NewsFilter struct {
+	threshold    float64
+	minRelevance float64
+}
+
+// NewNewsFilter 创建新闻过滤器，参数 <= 0 时使用默认值
+func NewNewsFilter(dedupThreshold, minRelevance float64) *NewsFilter {
+	if dedupThreshold <= 0 {
+		dedupThreshold = defaultDedupThreshold
+	}
+	if minRelevance <= 0 {
+		minRelevance = defaultMinRelevance
+	}
+	return &NewsFilter{threshold: dedupThreshold, minRelevance: minRelevance}
+}
+
+// Dedup 去除近似重复的条目，返回保留的条目和被去除的数量
+func (f *NewsFilter) Dedup(items []NewsText) ([]NewsText, int) {
+	if f.threshold > 1 {
+		return items, 0
+	}
+	kept := make([]NewsText, 0, len(items))
+	signatures := make([][]uint64, 0, len(items))
+	for _, item := range items {
+		sig := minHashSignature(item.Text())
+		if sig == nil {
+			continue
+		}
+		duplicate := false
+		for _, other := range signatures {
+			if minHashSimilarity(sig, other) >= f.threshold {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		kept = append(kept, item)
+		signatures = append(signatures, sig)
+	}
+	return kept, len(items) - len(kept)
+}
+
+// Relevant 返回与关键词相关度不低于阈值的条目（keywords 为空时全部保留）
+func (f *NewsFilter) Relevant(items []NewsText, keywords []string) []NewsText {
+	if len(keywords) == 0 {
+		return items
+	}
+	result := make([]NewsText, 0, len(items))
+	for _, item := range items {
+		if Relevance(item, keywords) >= f.minRelevance {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Relevance 条目与关键词的相关度（0~1）
+func Relevance(item NewsText, keywords []string) float64 {
+	title := strings.ToLower(item.Title)
+	if title != "" && matchesKeywords(title, wordPattern.FindAllString(title, -1), keywords) {
+		return 1
+	}
+	body := strings.ToLower(item.Body)
+	hits := keywordHits(body, wordPattern.FindAllString(body, -1), keywords)
+	return float64(hits) / float64(hits+2)
+}
+
+// keywordHits 统计关键词出现次数，匹配规则与 matchesKeywords 相同
+func keywordHits(lower string, words []string, keywords []string) int {
+	var hits int
+	for _, k := range keywords {
+		k = strings.ToLower(k)
+		if strings.Contains(k, " ") {
+			hits += strings.Count(lower, k)
+			continue
+		}
+		for _, w := range words {
+			if w == k {
+				hits++
+			}
+		}
+	}
+	return hits
+}
+
+// minHashSignature 计算文本的 MinHash 签名，文本不含单词时返回 nil
+func minHashSignature(text string) []uint64 {
+	words := strings.Fields(normalizeForShingles(text))
+	if len(words) == 0 {
+		return nil
+	}
+	n := shingleSize
+	if len(words) < n {
+		n = len(words)
+	}
+
+	sig := make([]uint64, minHashSize)
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for i := 0; i+n <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+n], " ")))
+		base := h.Sum64()
+		for j := range sig {
+			if v := mixHash(base, uint64(j)); v < sig[j] {
+				sig[j] = v
+			}
+		}
+	}
+	return sig
+}
+
+// minHashSimilarity 两个签名相同位置取值相等的比例，即 Jaccard 相似度的估计
+func minHashSimilarity(a, b []uint64) float64 {
+	var same int
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(a))
+}
+
+// mixHash 由基础哈希和序号派生第 seed 个哈希函数（splitmix64）
+func mixHash(base, seed uint64) uint64 {
+	z := base + (seed+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// normalizeForShingles 转小写并去掉标点，使转载时的格式差异不影响相似度
+func normalizeForShingles(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r > 127:
+			return r
+		default:
+			return ' '
+		}
+	}, text)
+}
@@ -0,0 +1,54 @@
+package ai
+
+import "testing"
+
+func TestNewsFilterDedup(t *testing.T) {
+	f := NewNewsFilter(0, 0)
+	items := []NewsText{
+		{Title: "Bitcoin ETF sees record inflow as BTC rallies above $70,000", Body: "Spot bitcoin ETFs recorded their largest daily inflow this month."},
+		// 其他源转载：大小写、标点不同
+		{Title: "Bitcoin ETF Sees Record Inflow as BTC Rallies Above $70,000!", Body: "Spot Bitcoin ETFs recorded their largest daily inflow this month"},
+		{Title: "Ethereum developers schedule the next network upgrade", Body: "The upgrade is expected to reduce fees for layer-2 rollups."},
+		{Body: "   "},
+	}
+	kept, dup := f.Dedup(items)
+	if len(kept) != 2 || dup != 2 {
+		t.Fatalf("kept %d items (%d duplicates), want 2 kept: %+v", len(kept), dup, kept)
+	}
+	if kept[0].Title != items[0].Title || kept[1].Title != items[2].Title {
+		t.Errorf("unexpected kept items: %+v", kept)
+	}
+
+	// 阈值大于 1 关闭去重
+	if kept, dup := NewNewsFilter(1.1, 0).Dedup(items); len(kept) != len(items) || dup != 0 {
+		t.Errorf("dedup disabled: kept %d, duplicates %d", len(kept), dup)
+	}
+}
+
+func TestNewsFilterRelevance(t *testing.T) {
+	keywords := []string{"btc", "bitcoin"}
+	cases := []struct {
+		item NewsText
+		want bool
+	}{
+		{NewsText{Title: "Bitcoin hits new high", Body: "Markets rally."}, true},
+		{NewsText{Title: "Crypto weekly recap", Body: "Also, BTC moved a bit."}, true},
+		{NewsText{Title: "Ethereum upgrade scheduled", Body: "Developers agreed on a date."}, false},
+		// 整词匹配：btcfoo 不匹配 btc
+		{NewsText{Body: "btcfoo token launches"}, false},
+	}
+	f := NewNewsFilter(0, 0)
+	for _, c := range cases {
+		got := len(f.Relevant([]NewsText{c.item}, keywords)) == 1
+		if got != c.want {
+			t.Errorf("Relevant(%q) = %v, want %v (relevance %.2f)", c.item.Text(), got, c.want, Relevance(c.item, keywords))
+		}
+	}
+
+	// 提高阈值后只保留标题命中的条目
+	strict := NewNewsFilter(0, 0.9)
+	items := []NewsText{cases[0].item, cases[1].item}
+	if got := strict.Relevant(items, keywords); len(got) != 1 || got[0].Title != cases[0].item.Title {
+		t.Errorf("strict relevance kept %+v", got)
+	}
+}
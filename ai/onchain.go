@@ -0,0 +1,331 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+)
+
+// 链上数据服务默认地址
+const (
+	DefaultGlassnodeAPIURL = "https://api.glassnode.com"
+	DefaultDuneAPIURL      = "https://api.dune.com/api/v1"
+)
+
+// 默认 Glassnode 指标路径（/v1/metrics/ 之后的部分）
+var DefaultGlassnodeMetrics = map[string]string{
+	"exchange_netflow":  "transactions/transfers_volume_exchanges_net",
+	"large_transfers":   "transactions/transfers_volume_more_than_1m_count",
+	"stablecoin_supply": "supply/current",
+}
+
+// 默认统计的稳定币
+var DefaultStablecoins = []string{"USDT", "USDC"}
+
+// OnChainMetrics 单个币种的链上指标（最近 24 小时）
+type OnChainMetrics struct {
+	Asset           string  `json:"asset"`
+	ExchangeNetflow float64 `json:"exchange_netflow"` // 交易所净流入（币本位，正数表示流入交易所，通常偏空）
+	LargeTransfers  float64 `json:"large_transfers"`  // 大额转账（>100 万美元）笔数
+}
+
+// OnChainSnapshot 链上数据快照
+type OnChainSnapshot struct {
+	Provider               string                     `json:"provider"`
+	Assets                 map[string]*OnChainMetrics `json:"assets"`
+	StablecoinSupply       float64                    `json:"stablecoin_supply"`        // 稳定币总供应量
+	StablecoinSupplyChange float64                    `json:"stablecoin_supply_change"` // 24 小时变化率（正数表示场外资金流入）
+	UpdatedAt              time.Time                  `json:"updated_at"`
+	Errors                 []string                   `json:"errors,omitempty"` // 部分指标获取失败的原因
+}
+
+// Features 供 AI 分析模块使用的结构化特征
+// 币种没有链上数据时只返回全市场特征
+func (s *OnChainSnapshot) Features(asset string) map[string]float64 {
+	features := map[string]float64{
+		"onchain_stablecoin_supply":        s.StablecoinSupply,
+		"onchain_stablecoin_supply_change": s.StablecoinSupplyChange,
+	}
+	if m, ok := s.Assets[strings.ToUpper(asset)]; ok {
+		features["onchain_exchange_netflow"] = m.ExchangeNetflow
+		features["onchain_large_transfers"] = m.LargeTransfers
+	}
+	return features
+}
+
+// OnChainProvider 链上数据提供方
+type OnChainProvider interface {
+	Name() string
+	FetchOnChain(assets []string) (*OnChainSnapshot, error)
+}
+
+// GlassnodeProvider 通过 Glassnode 指标接口获取链上数据
+type GlassnodeProvider struct {
+	manager     *DataSourceManager
+	apiURL      string
+	apiKey      string
+	metrics     map[string]string
+	stablecoins []string
+}
+
+// NewGlassnodeProvider 创建 Glassnode 数据提供方，metrics 中未配置的指标使用默认路径
+func NewGlassnodeProvider(manager *DataSourceManager, apiURL, apiKey string, metrics map[string]string) *GlassnodeProvider {
+	if apiURL == "" {
+		apiURL = DefaultGlassnodeAPIURL
+	}
+	merged := make(map[string]string, len(DefaultGlassnodeMetrics))
+	for k, v := range DefaultGlassnodeMetrics {
+		merged[k] = v
+	}
+	for k, v := range metrics {
+		if v != "" {
+			merged[k] = v
+		}
+	}
+	return &GlassnodeProvider{
+		manager:     manager,
+		apiURL:      strings.TrimRight(apiURL, "/"),
+		apiKey:      apiKey,
+		metrics:     merged,
+		stablecoins: DefaultStablecoins,
+	}
+}
+
+// Name 数据提供方名称
+func (p *GlassnodeProvider) Name() string {
+	return "glassnode"
+}
+
+// FetchOnChain 获取各币种的交易所净流入、大额转账和稳定币供应量
+// 单个指标失败时记录到 Errors，全部失败才返回错误
+func (p *GlassnodeProvider) FetchOnChain(assets []string) (*OnChainSnapshot, error) {
+	snapshot := &OnChainSnapshot{Provider: p.Name(), Assets: make(map[string]*OnChainMetrics), UpdatedAt: time.Now()}
+	succeeded := 0
+
+	for _, asset := range assets {
+		asset = strings.ToUpper(asset)
+		m := &OnChainMetrics{Asset: asset}
+		if points, err := p.series(p.metrics["exchange_netflow"], asset); err != nil {
+			snapshot.Errors = append(snapshot.Errors, fmt.Sprintf("%s exchange_netflow: %v", asset, err))
+		} else if len(points) > 0 {
+			m.ExchangeNetflow = points[len(points)-1]
+			succeeded++
+		}
+		if points, err := p.series(p.metrics["large_transfers"], asset); err != nil {
+			snapshot.Errors = append(snapshot.Errors, fmt.Sprintf("%s large_transfers: %v", asset, err))
+		} else if len(points) > 0 {
+			m.LargeTransfers = points[len(points)-1]
+			succeeded++
+		}
+		snapshot.Assets[asset] = m
+	}
+
+	var supply, previous float64
+	for _, coin := range p.stablecoins {
+		points, err := p.series(p.metrics["stablecoin_supply"], coin)
+		if err != nil {
+			snapshot.Errors = append(snapshot.Errors, fmt.Sprintf("%s stablecoin_supply: %v", coin, err))
+			continue
+		}
+		if len(points) == 0 {
+			continue
+		}
+		supply += points[len(points)-1]
+		if len(points) >= 2 {
+			previous += points[len(points)-2]
+		} else {
+			previous += points[len(points)-1]
+		}
+		succeeded++
+	}
+	snapshot.StablecoinSupply = supply
+	if previous > 0 {
+		snapshot.StablecoinSupplyChange = (supply - previous) / previous
+	}
+
+	if succeeded == 0 && len(snapshot.Errors) > 0 {
+		return nil, fmt.Errorf("获取链上数据失败: %s", snapshot.Errors[0])
+	}
+	return snapshot, nil
+}
+
+// series 获取指标最近 3 天的日线数据
+func (p *GlassnodeProvider) series(metric, asset string) ([]float64, error) {
+	params := url.Values{}
+	params.Set("a", asset)
+	params.Set("i", "24h")
+	params.Set("s", strconv.FormatInt(time.Now().Add(-72*time.Hour).Unix(), 10))
+	body, err := p.manager.getBodyWithHeaders(p.apiURL+"/v1/metrics/"+metric+"?"+params.Encode(),
+		map[string]string{"X-Api-Key": p.apiKey})
+	if err != nil {
+		return nil, err
+	}
+	var points []struct {
+		T int64   `json:"t"`
+		V float64 `json:"v"`
+	}
+	if err := json.Unmarshal(body, &points); err != nil {
+		return nil, fmt.Errorf("解析 Glassnode 数据失败: %w", err)
+	}
+	values := make([]float64, 0, len(points))
+	for _, pt := range points {
+		values = append(values, pt.V)
+	}
+	return values, nil
+}
+
+// DuneQueries Dune 已保存查询的 ID（0 表示不查询）
+// 查询结果需包含以下列：
+//   - netflow：asset, netflow
+//   - large_transfers：asset, count
+//   - stablecoin_supply：supply, supply_change
+type DuneQueries struct {
+	Netflow          int
+	LargeTransfers   int
+	StablecoinSupply int
+}
+
+// DuneProvider 通过 Dune 已保存查询的最新结果获取链上数据
+type DuneProvider struct {
+	manager *DataSourceManager
+	apiURL  string
+	apiKey  string
+	queries DuneQueries
+}
+
+// NewDuneProvider 创建 Dune 数据提供方
+func NewDuneProvider(manager *DataSourceManager, apiURL, apiKey string, queries DuneQueries) *DuneProvider {
+	if apiURL == "" {
+		apiURL = DefaultDuneAPIURL
+	}
+	return &DuneProvider{manager: manager, apiURL: strings.TrimRight(apiURL, "/"), apiKey: apiKey, queries: queries}
+}
+
+// Name 数据提供方名称
+func (p *DuneProvider) Name() string {
+	return "dune"
+}
+
+// FetchOnChain 读取各查询的最新结果（不触发重新执行，执行频率由 Dune 上的定时刷新决定）
+func (p *DuneProvider) FetchOnChain(assets []string) (*OnChainSnapshot, error) {
+	snapshot := &OnChainSnapshot{Provider: p.Name(), Assets: make(map[string]*OnChainMetrics), UpdatedAt: time.Now()}
+	wanted := make(map[string]bool, len(assets))
+	for _, asset := range assets {
+		asset = strings.ToUpper(asset)
+		wanted[asset] = true
+		snapshot.Assets[asset] = &OnChainMetrics{Asset: asset}
+	}
+	succeeded := 0
+
+	if p.queries.Netflow > 0 {
+		rows, err := p.rows(p.queries.Netflow)
+		if err != nil {
+			snapshot.Errors = append(snapshot.Errors, fmt.Sprintf("netflow: %v", err))
+		}
+		for _, row := range rows {
+			asset := strings.ToUpper(fmt.Sprint(row["asset"]))
+			if wanted[asset] {
+				snapshot.Assets[asset].ExchangeNetflow = toFloat(row["netflow"])
+				succeeded++
+			}
+		}
+	}
+	if p.queries.LargeTransfers > 0 {
+		rows, err := p.rows(p.queries.LargeTransfers)
+		if err != nil {
+			snapshot.Errors = append(snapshot.Errors, fmt.Sprintf("large_transfers: %v", err))
+		}
+		for _, row := range rows {
+			asset := strings.ToUpper(fmt.Sprint(row["asset"]))
+			if wanted[asset] {
+				snapshot.Assets[asset].LargeTransfers = toFloat(row["count"])
+				succeeded++
+			}
+		}
+	}
+	if p.queries.StablecoinSupply > 0 {
+		rows, err := p.rows(p.queries.StablecoinSupply)
+		if err != nil {
+			snapshot.Errors = append(snapshot.Errors, fmt.Sprintf("stablecoin_supply: %v", err))
+		} else if len(rows) > 0 {
+			snapshot.StablecoinSupply = toFloat(rows[0]["supply"])
+			snapshot.StablecoinSupplyChange = toFloat(rows[0]["supply_change"])
+			succeeded++
+		}
+	}
+
+	if succeeded == 0 && len(snapshot.Errors) > 0 {
+		return nil, fmt.Errorf("获取链上数据失败: %s", snapshot.Errors[0])
+	}
+	return snapshot, nil
+}
+
+// rows 获取查询最新结果的数据行
+func (p *DuneProvider) rows(queryID int) ([]map[string]interface{}, error) {
+	body, err := p.manager.getBodyWithHeaders(fmt.Sprintf("%s/query/%d/results?limit=1000", p.apiURL, queryID),
+		map[string]string{"X-Dune-API-Key": p.apiKey})
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Result struct {
+			Rows []map[string]interface{} `json:"rows"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("解析 Dune 查询结果失败: %w", err)
+	}
+	return resp.Result.Rows, nil
+}
+
+// toFloat 将查询结果中的数字或数字字符串转换为 float64
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case string:
+		f, _ := strconv.ParseFloat(n, 64)
+		return f
+	}
+	return 0
+}
+
+// ConfigureOnChain 根据配置为数据源管理器设置链上数据提供方（未启用时不做任何事）
+// 未配置 assets 时使用所有交易对的基础币种
+func ConfigureOnChain(manager *DataSourceManager, cfg *config.Config) {
+	onChain := cfg.AI.Modules.SentimentAnalysis.DataSources.OnChain
+	if !onChain.Enabled {
+		return
+	}
+	assets := onChain.Assets
+	if len(assets) == 0 {
+		seen := make(map[string]bool)
+		for _, sym := range cfg.Trading.Symbols {
+			base := baseAssetOf(sym.Symbol)
+			if !seen[base] {
+				seen[base] = true
+				assets = append(assets, base)
+			}
+		}
+	}
+
+	var provider OnChainProvider
+	switch onChain.Provider {
+	case "dune":
+		provider = NewDuneProvider(manager, onChain.APIURL, onChain.APIKey, DuneQueries{
+			Netflow:          onChain.DuneQueries.Netflow,
+			LargeTransfers:   onChain.DuneQueries.LargeTransfers,
+			StablecoinSupply: onChain.DuneQueries.StablecoinSupply,
+		})
+	default:
+		provider = NewGlassnodeProvider(manager, onChain.APIURL, onChain.APIKey, onChain.Metrics)
+	}
+	manager.SetOnChainProvider(provider, assets)
+	logger.Info("✅ [链上数据] 已启用 %s，币种: %v", provider.Name(), assets)
+}
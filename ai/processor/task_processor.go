@@ -44,6 +44,13 @@ func (p *TaskProcessor) Start() {
 	p.isRunning = true
 	p.mu.Unlock()
 
+	// 退出（包括 panic）时重置运行状态，允许守护协程重新启动
+	defer func() {
+		p.mu.Lock()
+		p.isRunning = false
+		p.mu.Unlock()
+	}()
+
 	logger.Info("AI 任务处理器已启动")
 	ticker := time.NewTicker(p.checkInterval)
 	defer ticker.Stop()
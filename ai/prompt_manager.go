@@ -0,0 +1,263 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"quantmesh/storage"
+)
+
+// PromptInfo 模块提示词的当前状态
+type PromptInfo struct {
+	Module        string    `json:"module"`
+	Template      string    `json:"template"`
+	SystemPrompt  string    `json:"system_prompt"`
+	Version       int       `json:"version"`        // 当前版本
+	PinnedVersion int       `json:"pinned_version"` // 固定使用的版本，0 表示跟随当前版本
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// DiffLine 差异中的一行，Op 为 "+"（新增）、"-"（删除）或 " "（未变）
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// PromptDiff 两个提示词版本的逐行差异
+type PromptDiff struct {
+	Module       string     `json:"module"`
+	From         int        `json:"from"`
+	To           int        `json:"to"`
+	Template     []DiffLine `json:"template"`
+	SystemPrompt []DiffLine `json:"system_prompt"`
+	Changed      bool       `json:"changed"`
+}
+
+// PromptManager 提示词管理（带版本历史）
+// 每次修改都追加一个不可变版本并记录作者，回滚即以历史版本内容追加新版本，历史不会被覆盖；
+// 模块可固定（pin）到某个版本，固定期间的修改只进入历史，分析器仍使用固定的版本
+type PromptManager struct {
+	store storage.Storage
+}
+
+// NewPromptManager 创建提示词管理器
+func NewPromptManager(store storage.Storage) *PromptManager {
+	return &PromptManager{store: store}
+}
+
+// GetAllPrompts 获取所有模块的提示词
+func (m *PromptManager) GetAllPrompts() (map[string]*PromptInfo, error) {
+	templates, err := m.store.GetAllAIPromptTemplates()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]*PromptInfo, len(templates))
+	for _, t := range templates {
+		result[t.Module] = &PromptInfo{
+			Module:        t.Module,
+			Template:      t.Template,
+			SystemPrompt:  t.SystemPrompt,
+			Version:       t.Version,
+			PinnedVersion: t.PinnedVersion,
+			UpdatedAt:     t.UpdatedAt,
+		}
+	}
+	return result, nil
+}
+
+// GetActivePrompt 获取分析器应使用的提示词：固定了版本时返回该版本，否则返回当前版本
+// 模块没有提示词时返回 nil（由分析器使用内置提示词）
+func (m *PromptManager) GetActivePrompt(module string) (*storage.AIPromptVersion, error) {
+	current, err := m.store.GetAIPromptTemplate(module)
+	if err != nil || current == nil {
+		return nil, err
+	}
+	if current.PinnedVersion > 0 {
+		pinned, err := m.store.GetAIPromptVersion(module, current.PinnedVersion)
+		if err != nil {
+			return nil, err
+		}
+		if pinned != nil {
+			return pinned, nil
+		}
+	}
+	return &storage.AIPromptVersion{
+		Module:       current.Module,
+		Version:      current.Version,
+		Template:     current.Template,
+		SystemPrompt: current.SystemPrompt,
+		CreatedAt:    current.UpdatedAt,
+	}, nil
+}
+
+// UpdatePrompt 修改提示词，追加一个新版本
+func (m *PromptManager) UpdatePrompt(module, template, systemPrompt, author, comment string) (*storage.AIPromptVersion, error) {
+	if module == "" {
+		return nil, fmt.Errorf("模块名不能为空")
+	}
+	if strings.TrimSpace(template) == "" {
+		return nil, fmt.Errorf("提示词模板不能为空")
+	}
+	if err := m.ensureBaseline(module, author); err != nil {
+		return nil, err
+	}
+	v := &storage.AIPromptVersion{Module: module, Template: template, SystemPrompt: systemPrompt, Author: author, Comment: comment}
+	if err := m.store.SaveAIPromptVersion(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// History 获取提示词版本历史（新版本在前）
+func (m *PromptManager) History(module string, limit int) ([]*storage.AIPromptVersion, error) {
+	return m.store.QueryAIPromptVersions(module, limit)
+}
+
+// Diff 比较两个版本，to 为 0 表示当前版本
+func (m *PromptManager) Diff(module string, from, to int) (*PromptDiff, error) {
+	if to == 0 {
+		current, err := m.store.GetAIPromptTemplate(module)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			return nil, fmt.Errorf("提示词模块不存在: %s", module)
+		}
+		to = current.Version
+	}
+	a, err := m.version(module, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := m.version(module, to)
+	if err != nil {
+		return nil, err
+	}
+	diff := &PromptDiff{
+		Module:       module,
+		From:         from,
+		To:           to,
+		Template:     diffLines(a.Template, b.Template),
+		SystemPrompt: diffLines(a.SystemPrompt, b.SystemPrompt),
+	}
+	for _, lines := range [][]DiffLine{diff.Template, diff.SystemPrompt} {
+		for _, l := range lines {
+			if l.Op != " " {
+				diff.Changed = true
+			}
+		}
+	}
+	return diff, nil
+}
+
+// Rollback 回滚到历史版本：以该版本内容追加一个新版本
+func (m *PromptManager) Rollback(module string, version int, author string) (*storage.AIPromptVersion, error) {
+	target, err := m.version(module, version)
+	if err != nil {
+		return nil, err
+	}
+	v := &storage.AIPromptVersion{
+		Module:       module,
+		Template:     target.Template,
+		SystemPrompt: target.SystemPrompt,
+		Author:       author,
+		Comment:      fmt.Sprintf("回滚到 v%d", version),
+	}
+	if err := m.store.SaveAIPromptVersion(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Pin 将模块固定到指定版本，version 为 0 时取消固定
+func (m *PromptManager) Pin(module string, version int) error {
+	if version < 0 {
+		return fmt.Errorf("无效的版本号: %d", version)
+	}
+	if version > 0 {
+		if _, err := m.version(module, version); err != nil {
+			return err
+		}
+	}
+	return m.store.SetAIPromptPin(module, version)
+}
+
+func (m *PromptManager) version(module string, version int) (*storage.AIPromptVersion, error) {
+	v, err := m.store.GetAIPromptVersion(module, version)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("提示词 %s 不存在版本 v%d", module, version)
+	}
+	return v, nil
+}
+
+// ensureBaseline 启用版本历史前通过 SetAIPromptTemplate 写入的模板没有版本记录，修改前先记为一个版本，保证可以回滚
+func (m *PromptManager) ensureBaseline(module, author string) error {
+	current, err := m.store.GetAIPromptTemplate(module)
+	if err != nil || current == nil || current.Version > 0 {
+		return err
+	}
+	return m.store.SaveAIPromptVersion(&storage.AIPromptVersion{
+		Module:       module,
+		Template:     current.Template,
+		SystemPrompt: current.SystemPrompt,
+		Author:       author,
+		Comment:      "启用版本历史前的模板",
+		CreatedAt:    current.UpdatedAt,
+	})
+}
+
+// diffLines 基于最长公共子序列的逐行差异
+func diffLines(a, b string) []DiffLine {
+	x, y := splitLines(a), splitLines(b)
+	// lcs[i][j] 为 x[i:] 与 y[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]DiffLine, 0, len(x)+len(y))
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			lines = append(lines, DiffLine{Op: " ", Text: x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, DiffLine{Op: "-", Text: x[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{Op: "+", Text: y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		lines = append(lines, DiffLine{Op: "-", Text: x[i]})
+	}
+	for ; j < len(y); j++ {
+		lines = append(lines, DiffLine{Op: "+", Text: y[j]})
+	}
+	return lines
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"testing"
+
+	"quantmesh/storage"
+)
+
+func TestPromptManagerVersioning(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	// 启用版本历史前写入的模板
+	if err := store.SetAIPromptTemplate(&storage.AIPromptTemplate{Module: "market_analysis", Template: "line1\nline2"}); err != nil {
+		t.Fatal(err)
+	}
+	m := NewPromptManager(store)
+
+	v, err := m.UpdatePrompt("market_analysis", "line1\nline2 changed\nline3", "sys", "alice", "更详细")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Version != 2 {
+		t.Fatalf("version = %d, want 2 (legacy template recorded as v1)", v.Version)
+	}
+
+	history, err := m.History("market_analysis", 0)
+	if err != nil || len(history) != 2 || history[0].Version != 2 || history[0].Author != "alice" {
+		t.Fatalf("unexpected history: %+v (%v)", history, err)
+	}
+
+	diff, err := m.Diff("market_analysis", 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffLine{{" ", "line1"}, {"-", "line2"}, {"+", "line2 changed"}, {"+", "line3"}}
+	if !diff.Changed || diff.To != 2 || len(diff.Template) != len(want) {
+		t.Fatalf("unexpected diff: %+v", diff)
+	}
+	for i := range want {
+		if diff.Template[i] != want[i] {
+			t.Errorf("diff line %d = %+v, want %+v", i, diff.Template[i], want[i])
+		}
+	}
+
+	// 固定到 v1 后，修改只进入历史，分析器仍使用 v1
+	if err := m.Pin("market_analysis", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.UpdatePrompt("market_analysis", "broken", "", "bob", ""); err != nil {
+		t.Fatal(err)
+	}
+	active, err := m.GetActivePrompt("market_analysis")
+	if err != nil || active.Version != 1 || active.Template != "line1\nline2" {
+		t.Fatalf("pinned prompt = %+v (%v)", active, err)
+	}
+	if err := m.Pin("market_analysis", 9); err == nil {
+		t.Error("pinning a missing version should fail")
+	}
+
+	// 取消固定后回滚到 v2
+	if err := m.Pin("market_analysis", 0); err != nil {
+		t.Fatal(err)
+	}
+	rolled, err := m.Rollback("market_analysis", 2, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	active, _ = m.GetActivePrompt("market_analysis")
+	if rolled.Version != 4 || active.Version != 4 || active.Template != v.Template || active.SystemPrompt != "sys" {
+		t.Errorf("after rollback: rolled=%+v active=%+v", rolled, active)
+	}
+}
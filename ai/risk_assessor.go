@@ -102,18 +102,23 @@ func (r *RiskAssessor) AssessRisk(ctx context.Context, req *RiskAssessmentReques
 	// 定义 JSON Schema
 	schema := r.buildSchema()
 
-	// 使用接口方法生成内容
-	aiText, err := r.client.GenerateContent(ctx, prompt, schema)
+	// 使用接口方法生成内容，评分越界或枚举值非法时重新提示
+	var result *RiskAssessmentResponse
+	err := generateValidated(ctx, r.client, "风险评估", prompt, schema, func(aiText string) error {
+		var parsed RiskAssessmentResponse
+		if err := json.Unmarshal([]byte(aiText), &parsed); err != nil {
+			return fmt.Errorf("解析 AI 评估结果失败: %w", err)
+		}
+		if err := ValidateRiskAssessment(&parsed); err != nil {
+			return err
+		}
+		result = &parsed
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	var result RiskAssessmentResponse
-	if err := json.Unmarshal([]byte(aiText), &result); err != nil {
-		return nil, fmt.Errorf("解析 AI 评估结果失败: %w (响应: %s)", err, aiText)
-	}
-
-	return &result, nil
+	return result, nil
 }
 
 // buildPrompt 构建提示词
@@ -0,0 +1,304 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+	"quantmesh/utils"
+)
+
+// ModuleStatus AI 模块调度状态
+type ModuleStatus struct {
+	Module     string                  `json:"module"`
+	Schedule   config.AIModuleSchedule `json:"schedule"`
+	Running    int                     `json:"running"`  // 正在执行的次数
+	Runs       int64                   `json:"runs"`     // 累计执行次数
+	Skipped    int64                   `json:"skipped"`  // 因并发达到上限跳过的触发次数
+	Failures   int64                   `json:"failures"` // 累计失败次数
+	LastRun    time.Time               `json:"last_run,omitempty"`
+	LastError  string                  `json:"last_error,omitempty"`
+	LastTook   string                  `json:"last_took,omitempty"`
+	NextRun    time.Time               `json:"next_run,omitempty"`
+	UpdatedAt  time.Time               `json:"updated_at"`
+	Registered bool                    `json:"registered"` // 是否有可执行的分析器（商业插件模块未安装时为 false）
+}
+
+// ModuleScheduler AI 模块调度器
+// 各分析模块注册执行函数后由调度器按间隔或 cron 触发；调度设置可在运行中更新（UpdateSchedule），
+// 新设置在下一次计算触发时间时生效，正在执行的分析不受影响，无需重启。
+type ModuleScheduler struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	modules map[string]*scheduledModule
+}
+
+type scheduledModule struct {
+	name     string
+	run      func() error
+	schedule config.AIModuleSchedule
+	reload   chan struct{}
+	started  bool
+
+	running   int
+	runs      int64
+	skipped   int64
+	failures  int64
+	lastRun   time.Time
+	lastError string
+	lastTook  time.Duration
+	nextRun   time.Time
+	updatedAt time.Time
+}
+
+// NewModuleScheduler 创建 AI 模块调度器，cfg 中的调度设置作为各模块的初始设置
+func NewModuleScheduler(cfg *config.Config) *ModuleScheduler {
+	s := &ModuleScheduler{modules: make(map[string]*scheduledModule)}
+	for _, name := range config.AIModules {
+		schedule, _ := cfg.AIModuleSchedule(name)
+		if schedule.MaxConcurrency <= 0 {
+			schedule.MaxConcurrency = 1
+		}
+		s.modules[name] = &scheduledModule{
+			name:      name,
+			schedule:  schedule,
+			reload:    make(chan struct{}, 1),
+			updatedAt: time.Now(),
+		}
+	}
+	return s
+}
+
+// Register 注册模块的执行函数；调度器已启动时立即开始调度该模块
+func (s *ModuleScheduler) Register(module string, run func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.modules[module]
+	if !ok {
+		return fmt.Errorf("未知的 AI 模块: %s", module)
+	}
+	m.run = run
+	if s.ctx != nil && !m.started {
+		m.started = true
+		go s.loop(s.ctx, m)
+	}
+	return nil
+}
+
+// Start 启动已注册模块的调度
+func (s *ModuleScheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = ctx
+	for _, m := range s.modules {
+		if m.run != nil && !m.started {
+			m.started = true
+			go s.loop(ctx, m)
+		}
+	}
+}
+
+// UpdateSchedule 热更新模块的调度设置
+func (s *ModuleScheduler) UpdateSchedule(module string, schedule config.AIModuleSchedule) (ModuleStatus, error) {
+	if err := schedule.Validate(); err != nil {
+		return ModuleStatus{}, err
+	}
+	s.mu.Lock()
+	m, ok := s.modules[module]
+	if !ok {
+		s.mu.Unlock()
+		return ModuleStatus{}, fmt.Errorf("未知的 AI 模块: %s", module)
+	}
+	changed := m.schedule != schedule
+	m.schedule = schedule
+	m.updatedAt = time.Now()
+	s.mu.Unlock()
+
+	if changed {
+		logger.Info("🔧 [AI调度] %s 调度已更新: enabled=%v interval=%ds cron=%q max_concurrency=%d",
+			module, schedule.Enabled, schedule.Interval, schedule.Cron, schedule.MaxConcurrency)
+		select {
+		case m.reload <- struct{}{}:
+		default:
+		}
+	}
+	return s.status(module), nil
+}
+
+// ApplyConfig 按新配置更新所有模块的调度（配置热更新回调使用）
+func (s *ModuleScheduler) ApplyConfig(cfg *config.Config) error {
+	for _, name := range config.AIModules {
+		schedule, _ := cfg.AIModuleSchedule(name)
+		if _, err := s.UpdateSchedule(name, schedule); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Trigger 立即执行一次（受并发上限约束）
+func (s *ModuleScheduler) Trigger(module string) error {
+	s.mu.Lock()
+	m, ok := s.modules[module]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("未知的 AI 模块: %s", module)
+	}
+	if !s.fire(m) {
+		return fmt.Errorf("%s 未注册或已达到并发上限", module)
+	}
+	return nil
+}
+
+// Status 所有模块的调度状态
+func (s *ModuleScheduler) Status() []ModuleStatus {
+	s.mu.Lock()
+	names := make([]string, 0, len(s.modules))
+	for name := range s.modules {
+		names = append(names, name)
+	}
+	s.mu.Unlock()
+	sort.Strings(names)
+
+	result := make([]ModuleStatus, 0, len(names))
+	for _, name := range names {
+		result = append(result, s.status(name))
+	}
+	return result
+}
+
+func (s *ModuleScheduler) status(module string) ModuleStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.modules[module]
+	st := ModuleStatus{
+		Module:     m.name,
+		Schedule:   m.schedule,
+		Running:    m.running,
+		Runs:       m.runs,
+		Skipped:    m.skipped,
+		Failures:   m.failures,
+		LastRun:    m.lastRun,
+		LastError:  m.lastError,
+		NextRun:    m.nextRun,
+		UpdatedAt:  m.updatedAt,
+		Registered: m.run != nil,
+	}
+	if m.lastTook > 0 {
+		st.LastTook = m.lastTook.Round(time.Millisecond).String()
+	}
+	return st
+}
+
+// loop 模块调度循环：计算下一次触发时间并等待，设置更新时重新计算
+func (s *ModuleScheduler) loop(ctx context.Context, m *scheduledModule) {
+	for {
+		s.mu.Lock()
+		schedule := m.schedule
+		last := m.lastRun
+		s.mu.Unlock()
+
+		next := nextRunTime(schedule, last, time.Now())
+		s.mu.Lock()
+		m.nextRun = next
+		s.mu.Unlock()
+
+		var timer *time.Timer
+		var timerC <-chan time.Time
+		if !next.IsZero() {
+			timer = time.NewTimer(time.Until(next))
+			timerC = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-m.reload:
+		case <-timerC:
+			if !s.fire(m) {
+				// 未执行时也推进基准时间，避免按间隔调度时立即再次触发
+				s.mu.Lock()
+				m.lastRun = time.Now()
+				s.mu.Unlock()
+			}
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// fire 在并发上限内异步执行一次，返回是否已开始执行
+func (s *ModuleScheduler) fire(m *scheduledModule) bool {
+	s.mu.Lock()
+	if m.run == nil {
+		s.mu.Unlock()
+		return false
+	}
+	if m.running >= m.schedule.MaxConcurrency {
+		m.skipped++
+		s.mu.Unlock()
+		logger.Warn("⚠️ [AI调度] %s 上一次分析仍在执行（并发上限 %d），跳过本次触发", m.name, m.schedule.MaxConcurrency)
+		return false
+	}
+	m.running++
+	m.lastRun = time.Now()
+	run := m.run
+	s.mu.Unlock()
+
+	go func() {
+		start := time.Now()
+		err := run()
+		s.mu.Lock()
+		m.running--
+		m.runs++
+		m.lastTook = time.Since(start)
+		m.lastError = ""
+		if err != nil {
+			m.failures++
+			m.lastError = err.Error()
+		}
+		s.mu.Unlock()
+		if err != nil {
+			logger.Warn("⚠️ [AI调度] %s 分析失败: %v", m.name, err)
+		}
+	}()
+	return true
+}
+
+// nextRunTime 计算下一次触发时间：未启用返回零值；cron 按系统时区计算；
+// 按间隔调度时从上一次执行起算，从未执行过则立即执行
+func nextRunTime(schedule config.AIModuleSchedule, lastRun, now time.Time) time.Time {
+	if !schedule.Enabled {
+		return time.Time{}
+	}
+	if schedule.Cron != "" {
+		cron, err := utils.ParseCron(schedule.Cron)
+		if err != nil {
+			return time.Time{}
+		}
+		loc := utils.GlobalLocation
+		if loc == nil {
+			loc = time.Local
+		}
+		return cron.Next(now.In(loc))
+	}
+	if schedule.Interval <= 0 {
+		return time.Time{}
+	}
+	if lastRun.IsZero() {
+		return now
+	}
+	next := lastRun.Add(time.Duration(schedule.Interval) * time.Second)
+	if next.Before(now) {
+		return now
+	}
+	return next
+}
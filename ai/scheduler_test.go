@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"quantmesh/config"
+)
+
+func TestModuleSchedulerHotUpdate(t *testing.T) {
+	cfg := &config.Config{}
+	s := NewModuleScheduler(cfg)
+
+	var runs int32
+	release := make(chan struct{})
+	if err := s.Register(config.AIModuleSentimentAnalysis, func() error {
+		atomic.AddInt32(&runs, 1)
+		<-release
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+
+	// 未启用时不执行
+	time.Sleep(20 * time.Millisecond)
+	if n := atomic.LoadInt32(&runs); n != 0 {
+		t.Fatalf("disabled module ran %d times", n)
+	}
+
+	// 运行中启用：立即执行一次
+	if _, err := s.UpdateSchedule(config.AIModuleSentimentAnalysis, config.AIModuleSchedule{Enabled: true, Interval: 1}); err != nil {
+		t.Fatal(err)
+	}
+	waitUntil(t, func() bool { return atomic.LoadInt32(&runs) == 1 })
+
+	// 上一次仍在执行时，超过并发上限的触发被跳过
+	if err := s.Trigger(config.AIModuleSentimentAnalysis); err == nil {
+		t.Error("trigger should be rejected at max concurrency 1")
+	}
+	if _, err := s.UpdateSchedule(config.AIModuleSentimentAnalysis, config.AIModuleSchedule{Enabled: true, Interval: 3600, MaxConcurrency: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Trigger(config.AIModuleSentimentAnalysis); err != nil {
+		t.Errorf("trigger within raised concurrency: %v", err)
+	}
+	waitUntil(t, func() bool { return atomic.LoadInt32(&runs) == 2 })
+	close(release)
+
+	var st ModuleStatus
+	for _, m := range s.Status() {
+		if m.Module == config.AIModuleSentimentAnalysis {
+			st = m
+		}
+	}
+	waitUntil(t, func() bool {
+		for _, m := range s.Status() {
+			if m.Module == config.AIModuleSentimentAnalysis {
+				st = m
+			}
+		}
+		return st.Runs == 2
+	})
+	if st.Skipped != 1 || !st.Registered || st.Schedule.MaxConcurrency != 2 {
+		t.Errorf("unexpected status: %+v", st)
+	}
+	if st.NextRun.Before(time.Now().Add(50 * time.Minute)) {
+		t.Errorf("next run should follow the updated 1h interval, got %v", st.NextRun)
+	}
+
+	if _, err := s.UpdateSchedule(config.AIModuleSentimentAnalysis, config.AIModuleSchedule{Enabled: true, Cron: "61 * * * *"}); err == nil {
+		t.Error("invalid cron should be rejected")
+	}
+}
+
+func waitUntil(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,380 @@
+package ai
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+)
+
+// DefaultSentimentWeights 各数据源默认权重
+// 社交媒体对加密货币价格的反应最快，新闻次之；恐慌贪婪指数是全市场指标，权重最低
+var DefaultSentimentWeights = map[string]float64{
+	"news":       1.0,
+	"reddit":     0.8,
+	"twitter":    1.0,
+	"telegram":   0.7,
+	"fear_greed": 0.5,
+}
+
+// 常见币种的全称，用于自动生成关键词
+var coinNames = map[string]string{
+	"BTC":  "bitcoin",
+	"ETH":  "ethereum",
+	"SOL":  "solana",
+	"XRP":  "ripple",
+	"DOGE": "dogecoin",
+	"BNB":  "binance coin",
+	"ADA":  "cardano",
+	"AVAX": "avalanche",
+	"DOT":  "polkadot",
+	"LINK": "chainlink",
+	"LTC":  "litecoin",
+	"TON":  "toncoin",
+}
+
+// 情绪词典（小写）
+var (
+	bullishWords = []string{"bull", "bullish", "moon", "pump", "rally", "surge", "soar", "breakout", "ath",
+		"buy", "long", "adoption", "approval", "approved", "inflow", "accumulate", "upgrade", "partnership", "gain", "green"}
+	bearishWords = []string{"bear", "bearish", "dump", "crash", "plunge", "sell", "selloff", "sell-off", "short", "hack", "hacked",
+		"exploit", "ban", "lawsuit", "sue", "liquidation", "liquidated", "outflow", "fear", "rekt", "scam", "delist", "red"}
+	wordPattern = regexp.MustCompile(`[a-z][a-z\-]*`)
+)
+
+// SourceSentiment 单个数据源的情绪
+type SourceSentiment struct {
+	Score      float64 `json:"score"`                // -1（极度看空）~ 1（极度看多）
+	Weight     float64 `json:"weight"`               // 参与加权的权重
+	Samples    int     `json:"samples"`              // 命中关键词且含情绪词的条目数
+	Duplicates int     `json:"duplicates,omitempty"` // 去重时丢弃的近似重复条目数
+	Error      string  `json:"error,omitempty"`
+}
+
+// SymbolSentiment 单个交易对的情绪
+type SymbolSentiment struct {
+	Symbol   string                      `json:"symbol"`
+	Score    float64                     `json:"score"`
+	Label    string                      `json:"label"` // bullish, bearish, neutral
+	Keywords []string                    `json:"keywords"`
+	Sources  map[string]*SourceSentiment `json:"sources"`
+	Features map[string]float64          `json:"features,omitempty"` // 链上等结构化特征
+}
+
+// SentimentAnalysis 一次情绪分析的结果
+type SentimentAnalysis struct {
+	Symbols    map[string]*SymbolSentiment `json:"symbols"`
+	FearGreed  *FearGreedIndex             `json:"fear_greed,omitempty"`
+	OnChain    *OnChainSnapshot            `json:"on_chain,omitempty"`
+	AnalyzedAt time.Time                   `json:"analyzed_at"`
+}
+
+// SentimentAnalyzer 多数据源情绪分析
+// 汇总新闻、Reddit、X/Twitter、Telegram 中与交易对关键词相关的内容，按词典打分后按数据源权重加权，
+// 恐慌贪婪指数作为全市场情绪参与每个交易对的加权
+type SentimentAnalyzer struct {
+	source DataSourceAPI
+	cfg    *config.Config
+
+	mu           sync.RWMutex
+	lastAnalysis *SentimentAnalysis
+	lastTime     time.Time
+}
+
+// NewSentimentAnalyzer 创建情绪分析器（执行周期由 ModuleScheduler 调度）
+func NewSentimentAnalyzer(source DataSourceAPI, cfg *config.Config) *SentimentAnalyzer {
+	return &SentimentAnalyzer{source: source, cfg: cfg}
+}
+
+// GetLastAnalysis 获取最近一次分析结果
+func (a *SentimentAnalyzer) GetLastAnalysis() interface{} {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.lastAnalysis == nil {
+		return nil
+	}
+	return a.lastAnalysis
+}
+
+// GetLastAnalysisTime 获取最近一次分析时间
+func (a *SentimentAnalyzer) GetLastAnalysisTime() time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.lastTime
+}
+
+// PerformAnalysis 执行一次情绪分析
+func (a *SentimentAnalyzer) PerformAnalysis() error {
+	sa := a.cfg.AI.Modules.SentimentAnalysis
+	ds := sa.DataSources
+
+	// 全市场数据只获取一次，去重后再按交易对关键词筛选
+	texts := make(map[string][]NewsText)
+	errs := make(map[string]string)
+	if ds.News.Enabled {
+		feeds := ds.News.RSSFeeds
+		if len(feeds) == 0 {
+			feeds = DefaultRSSFeeds
+		}
+		for _, feed := range feeds {
+			items, err := a.source.FetchRSSFeed(feed)
+			if err != nil {
+				errs["news"] = err.Error()
+				continue
+			}
+			for _, item := range items {
+				texts["news"] = append(texts["news"], NewsText{Title: item.Title, Body: item.Description})
+			}
+		}
+	}
+	if ds.SocialMedia.Enabled {
+		subreddits := ds.SocialMedia.Subreddits
+		if len(subreddits) == 0 {
+			subreddits = DefaultSubreddits
+		}
+		posts, err := a.source.FetchRedditPosts(subreddits, ds.SocialMedia.PostLimit)
+		if err != nil {
+			errs["reddit"] = err.Error()
+		}
+		for _, p := range posts {
+			texts["reddit"] = append(texts["reddit"], NewsText{Title: p.Title, Body: p.Content})
+		}
+	}
+	if ds.Telegram.Enabled {
+		for _, channel := range ds.Telegram.Channels {
+			posts, err := a.source.FetchTelegramPosts(channel, ds.Telegram.PostLimit)
+			if err != nil {
+				errs["telegram"] = err.Error()
+				continue
+			}
+			for _, p := range posts {
+				texts["telegram"] = append(texts["telegram"], NewsText{Body: p.Text})
+			}
+		}
+	}
+
+	filter := NewNewsFilter(ds.Filter.DedupThreshold, ds.Filter.MinRelevance)
+	duplicates := make(map[string]int)
+	for source, list := range texts {
+		texts[source], duplicates[source] = filter.Dedup(list)
+	}
+
+	result := &SentimentAnalysis{Symbols: make(map[string]*SymbolSentiment), AnalyzedAt: time.Now()}
+	if ds.FearGreedIndex.Enabled {
+		index, err := a.source.FetchFearGreedIndex(ds.FearGreedIndex.APIURL)
+		if err != nil {
+			errs["fear_greed"] = err.Error()
+		} else {
+			result.FearGreed = index
+		}
+	}
+
+	if ds.OnChain.Enabled {
+		snapshot, err := a.source.FetchOnChain()
+		if err != nil {
+			logger.Warn("⚠️ [情绪分析] 获取链上数据失败: %v", err)
+		} else {
+			result.OnChain = snapshot
+		}
+	}
+
+	for _, symCfg := range a.cfg.Trading.Symbols {
+		symbol := symCfg.Symbol
+		if _, done := result.Symbols[symbol]; done {
+			continue
+		}
+		keywords := a.keywordsFor(symbol)
+		s := &SymbolSentiment{Symbol: symbol, Keywords: keywords, Sources: make(map[string]*SourceSentiment)}
+
+		for source, list := range texts {
+			s.Sources[source] = scoreTexts(filter.Relevant(list, keywords))
+			s.Sources[source].Duplicates = duplicates[source]
+		}
+		if ds.Twitter.Enabled {
+			maxResults := ds.Twitter.MaxResults
+			if maxResults == 0 {
+				maxResults = 50
+			}
+			tweets, err := a.source.FetchTweets(twitterQuery(keywords), maxResults)
+			list := make([]NewsText, 0, len(tweets))
+			for _, t := range tweets {
+				list = append(list, NewsText{Body: t.Text})
+			}
+			// 搜索结果已按关键词筛选，只去重（转推、机器人刷屏）
+			list, dup := filter.Dedup(list)
+			s.Sources["twitter"] = scoreTexts(list)
+			s.Sources["twitter"].Duplicates = dup
+			if err != nil {
+				s.Sources["twitter"].Error = err.Error()
+			}
+		}
+		if result.FearGreed != nil {
+			s.Sources["fear_greed"] = &SourceSentiment{Score: float64(result.FearGreed.Value-50) / 50, Samples: 1}
+		}
+		for source, msg := range errs {
+			if s.Sources[source] == nil {
+				s.Sources[source] = &SourceSentiment{}
+			}
+			s.Sources[source].Error = msg
+		}
+
+		if result.OnChain != nil {
+			s.Features = result.OnChain.Features(baseAssetOf(symbol))
+		}
+
+		a.combine(s, sa.SourceWeights)
+		result.Symbols[symbol] = s
+	}
+
+	if len(result.Symbols) == 0 {
+		return fmt.Errorf("未配置交易对")
+	}
+
+	a.mu.Lock()
+	a.lastAnalysis = result
+	a.lastTime = result.AnalyzedAt
+	a.mu.Unlock()
+	for symbol, s := range result.Symbols {
+		logger.Info("📰 [情绪分析] %s 情绪 %.2f (%s)", symbol, s.Score, s.Label)
+	}
+	return nil
+}
+
+// keywordsFor 交易对关键词：优先使用配置，否则使用币种代码和全称
+func (a *SentimentAnalyzer) keywordsFor(symbol string) []string {
+	if keywords, ok := a.cfg.AI.Modules.SentimentAnalysis.SymbolKeywords[symbol]; ok && len(keywords) > 0 {
+		return keywords
+	}
+	base := baseAssetOf(symbol)
+	keywords := []string{strings.ToLower(base)}
+	if name, ok := coinNames[base]; ok {
+		keywords = append(keywords, name)
+	}
+	return keywords
+}
+
+// combine 按权重合成交易对情绪，没有样本的数据源不参与加权
+func (a *SentimentAnalyzer) combine(s *SymbolSentiment, weights map[string]float64) {
+	var sum, total float64
+	for source, src := range s.Sources {
+		weight, ok := weights[source]
+		if !ok {
+			weight = DefaultSentimentWeights[source]
+		}
+		if src.Samples == 0 {
+			continue
+		}
+		src.Weight = weight
+		sum += src.Score * weight
+		total += weight
+	}
+	if total > 0 {
+		s.Score = math.Round(sum/total*1000) / 1000
+	}
+	switch {
+	case s.Score >= 0.2:
+		s.Label = "bullish"
+	case s.Score <= -0.2:
+		s.Label = "bearish"
+	default:
+		s.Label = "neutral"
+	}
+}
+
+// scoreTexts 对已筛选的文本打分，返回平均情绪
+func scoreTexts(texts []NewsText) *SourceSentiment {
+	result := &SourceSentiment{}
+	var sum float64
+	for _, text := range texts {
+		lower := strings.ToLower(text.Text())
+		words := wordPattern.FindAllString(lower, -1)
+		score, ok := scoreWords(lower, words)
+		if !ok {
+			continue
+		}
+		sum += score
+		result.Samples++
+	}
+	if result.Samples > 0 {
+		result.Score = math.Round(sum/float64(result.Samples)*1000) / 1000
+	}
+	return result
+}
+
+// matchesKeywords 单词关键词按整词匹配（避免 eth 匹配 whether），词组按子串匹配
+func matchesKeywords(lower string, words []string, keywords []string) bool {
+	for _, k := range keywords {
+		k = strings.ToLower(k)
+		if strings.Contains(k, " ") {
+			if strings.Contains(lower, k) {
+				return true
+			}
+			continue
+		}
+		for _, w := range words {
+			if w == k {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scoreWords 统计看多/看空词数量，返回 (多-空)/(多+空)；不含情绪词时返回 false
+func scoreWords(lower string, words []string) (float64, bool) {
+	bull := strings.Count(lower, "all-time high")
+	var bear int
+	for _, w := range words {
+		if sentimentWordIn(w, bullishWords) {
+			bull++
+		} else if sentimentWordIn(w, bearishWords) {
+			bear++
+		}
+	}
+	if bull+bear == 0 {
+		return 0, false
+	}
+	return float64(bull-bear) / float64(bull+bear), true
+}
+
+func sentimentWordIn(w string, list []string) bool {
+	for _, b := range list {
+		if w == b {
+			return true
+		}
+	}
+	return false
+}
+
+// twitterQuery 生成 X 搜索语句：(kw1 OR kw2) lang:en
+func twitterQuery(keywords []string) string {
+	quoted := make([]string, 0, len(keywords))
+	for _, k := range keywords {
+		if strings.Contains(k, " ") {
+			k = `"` + k + `"`
+		}
+		quoted = append(quoted, k)
+	}
+	return "(" + strings.Join(quoted, " OR ") + ") lang:en"
+}
+
+// baseAssetOf 从交易对中提取基础币种（BTCUSDT、BTC-USDT、BTC/USDT -> BTC）
+func baseAssetOf(symbol string) string {
+	s := strings.ToUpper(symbol)
+	for _, sep := range []string{"-", "/", "_"} {
+		if idx := strings.Index(s, sep); idx > 0 {
+			return s[:idx]
+		}
+	}
+	for _, quote := range []string{"FDUSD", "USDT", "USDC", "BUSD", "USD"} {
+		if strings.HasSuffix(s, quote) && len(s) > len(quote) {
+			return strings.TrimSuffix(s, quote)
+		}
+	}
+	return s
+}
@@ -1,9 +1,11 @@
 package backtest
 
 import (
+	"math"
 	"testing"
 	"time"
 
+	"quantmesh/config"
 	"quantmesh/exchange"
 )
 
@@ -231,3 +233,51 @@ func generateTrendingCandles(symbol string, count int, basePrice float64, trendR
 
 	return candles
 }
+
+// scriptedAdapter 按K线序号发出预设信号
+type scriptedAdapter struct {
+	signals map[int]string
+	n       int
+}
+
+func (a *scriptedAdapter) OnCandle(candle *exchange.Candle) Signal {
+	action := a.signals[a.n]
+	a.n++
+	if action == "" {
+		action = "hold"
+	}
+	return Signal{Action: action, Price: candle.Close}
+}
+
+func (a *scriptedAdapter) GetName() string { return "scripted" }
+
+// TestExecutionSimulatorDelaysFills 模拟执行延迟把成交推迟到后续K线的开盘价
+func TestExecutionSimulatorDelaysFills(t *testing.T) {
+	candles := make([]*exchange.Candle, 5)
+	for i := range candles {
+		price := 100 + float64(i)*10
+		candles[i] = &exchange.Candle{Symbol: "BTCUSDT", Open: price, High: price + 5, Low: price - 5, Close: price + 5, Timestamp: int64(i) * 60000}
+	}
+
+	run := func(ackMs float64) *BacktestResult {
+		bt := NewBacktester("BTCUSDT", candles, &scriptedAdapter{signals: map[int]string{0: "buy"}}, 10000)
+		bt.SetExecutionSimulator(exchange.NewExecutionSimulator(config.ExecutionSimConfig{
+			AckLatency: config.LatencyConfig{MeanMs: ackMs},
+			Slippage:   config.SlippageConfig{Model: config.SlippageFixed, Bps: 10},
+		}, 1))
+		result, err := bt.Run()
+		if err != nil {
+			t.Fatalf("回测失败: %v", err)
+		}
+		return result
+	}
+
+	// 延迟小于一根K线：按下一根K线开盘价成交
+	if price := run(50).Trades[0].Price; math.Abs(price-110*1.001) > 1e-9 {
+		t.Errorf("buy price = %v, want next open 110 + 10bps", price)
+	}
+	// 延迟超过一根K线：按延迟结束时所在K线的开盘价成交
+	if price := run(90000).Trades[0].Price; math.Abs(price-120*1.001) > 1e-9 {
+		t.Errorf("buy price = %v, want open 120 + 10bps", price)
+	}
+}
@@ -31,6 +31,9 @@ type Backtester struct {
 	makerFee float64 // 0.0002 (0.02%)
 	slippage float64 // 0.0003 (0.03%)
 
+	// 模拟执行（设置后按延迟推迟成交并使用滑点模型代替固定滑点）
+	sim *exchange.ExecutionSimulator
+
 	// 账户状态
 	cash       float64
 	position   float64
@@ -113,6 +116,34 @@ func (bt *Backtester) SetFees(takerFee, makerFee, slippage float64) {
 	bt.slippage = slippage
 }
 
+// SetExecutionSimulator 设置模拟执行模型（下单回报延迟、成交延迟和滑点）
+func (bt *Backtester) SetExecutionSimulator(sim *exchange.ExecutionSimulator) {
+	bt.sim = sim
+}
+
+// marketPrice 第 i 根K线收盘时发出市价单的模拟成交价
+// 未设置模拟执行模型时按收盘价加固定滑点；否则成交时间推迟到收盘后经过回报+成交延迟，
+// 按该时间所在K线的开盘价成交（K线内没有更细的价格），再叠加滑点模型
+func (bt *Backtester) marketPrice(i int, side exchange.Side) float64 {
+	candle := bt.candles[i]
+	if bt.sim == nil {
+		if side == exchange.SideBuy {
+			return candle.Close * (1 + bt.slippage)
+		}
+		return candle.Close * (1 - bt.slippage)
+	}
+	price := candle.Close
+	if i+1 < len(bt.candles) {
+		fillTime := bt.candles[i+1].Timestamp + bt.sim.OrderDelay().Milliseconds()
+		j := i + 1
+		for j+1 < len(bt.candles) && bt.candles[j+1].Timestamp <= fillTime {
+			j++
+		}
+		price = bt.candles[j].Open
+	}
+	return bt.sim.Slip(side, price)
+}
+
 // Run 运行回测
 func (bt *Backtester) Run() (*BacktestResult, error) {
 	// Bug Fix 1: 检查 candles 是否为空
@@ -139,9 +170,9 @@ func (bt *Backtester) Run() (*BacktestResult, error) {
 
 		// 3. 执行交易
 		if signal.Action == "buy" && bt.position == 0 {
-			bt.executeBuy(candle)
+			bt.executeBuy(candle, bt.marketPrice(i, exchange.SideBuy))
 		} else if signal.Action == "sell" && bt.position > 0 {
-			bt.executeSell(candle)
+			bt.executeSell(candle, bt.marketPrice(i, exchange.SideSell))
 		}
 
 		// 4. 进度显示
@@ -154,7 +185,7 @@ func (bt *Backtester) Run() (*BacktestResult, error) {
 	// 如果还有持仓，按最后价格平仓
 	if bt.position > 0 && len(bt.candles) > 0 {
 		lastCandle := bt.candles[len(bt.candles)-1]
-		bt.executeSell(lastCandle)
+		bt.executeSell(lastCandle, bt.marketPrice(len(bt.candles)-1, exchange.SideSell))
 		logger.Info("📊 回测结束，强制平仓")
 	}
 
@@ -186,9 +217,8 @@ func (bt *Backtester) Run() (*BacktestResult, error) {
 	}, nil
 }
 
-// executeBuy 执行买入
-func (bt *Backtester) executeBuy(candle *exchange.Candle) {
-	price := candle.Close * (1 + bt.slippage)
+// executeBuy 按成交价 price 执行买入
+func (bt *Backtester) executeBuy(candle *exchange.Candle, price float64) {
 	quantity := (bt.cash * 0.95) / price // 使用 95% 资金
 	fee := quantity * price * bt.takerFee
 
@@ -208,9 +238,8 @@ func (bt *Backtester) executeBuy(candle *exchange.Candle) {
 	logger.Info("📈 买入: 价格=%.2f, 数量=%.4f, 手续费=%.2f", price, quantity, fee)
 }
 
-// executeSell 执行卖出
-func (bt *Backtester) executeSell(candle *exchange.Candle) {
-	price := candle.Close * (1 - bt.slippage)
+// executeSell 按成交价 price 执行卖出
+func (bt *Backtester) executeSell(candle *exchange.Candle, price float64) {
 	quantity := bt.position
 	fee := quantity * price * bt.takerFee
 
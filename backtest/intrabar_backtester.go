@@ -129,7 +129,15 @@ type IntrabarTick struct {
 	Timestamp int64
 }
 
+// pendingOrder 已发出、等待模拟延迟后成交的市价单
+type pendingOrder struct {
+	side     exchange.Side
+	fillTime int64 // 毫秒时间戳，到达后按当时的 tick 价格成交
+}
+
 // Run 运行K线内模拟回测
+// 设置模拟执行模型时，信号发出的市价单在回报+成交延迟之后按第一个到达的 tick 价格加滑点成交，
+// 等待成交期间忽略新的信号
 func (ibt *IntrabarBacktester) Run() (*BacktestResult, error) {
 	ibt.cash = ibt.initialCapital
 	ibt.position = 0
@@ -139,6 +147,7 @@ func (ibt *IntrabarBacktester) Run() (*BacktestResult, error) {
 	logger.Info("📊 总计模拟: %d 次价格变动", len(ibt.candles)*ibt.ticksPerBar)
 
 	totalTicks := 0
+	var pending *pendingOrder
 
 	for i, candle := range ibt.candles {
 		// 模拟K线内部的价格变动
@@ -169,10 +178,34 @@ func (ibt *IntrabarBacktester) Run() (*BacktestResult, error) {
 			// 调用策略
 			signal := ibt.strategy.OnCandle(simulatedCandle)
 
+			// 模拟延迟到达后成交挂起的订单
+			if pending != nil && tick.Timestamp >= pending.fillTime {
+				price := ibt.sim.Slip(pending.side, tick.Price)
+				if pending.side == exchange.SideBuy {
+					ibt.executeBuyAtPrice(price, tick.Timestamp)
+				} else {
+					ibt.executeSellAtPrice(price, tick.Timestamp)
+				}
+				pending = nil
+			}
+			if pending != nil {
+				continue
+			}
+
 			// 执行交易
+			var side exchange.Side
 			if signal.Action == "buy" && ibt.position == 0 {
-				ibt.executeBuyAtPrice(tick.Price, tick.Timestamp)
+				side = exchange.SideBuy
 			} else if signal.Action == "sell" && ibt.position > 0 {
+				side = exchange.SideSell
+			}
+			switch {
+			case side == "":
+			case ibt.sim != nil:
+				pending = &pendingOrder{side: side, fillTime: tick.Timestamp + ibt.sim.OrderDelay().Milliseconds()}
+			case side == exchange.SideBuy:
+				ibt.executeBuyAtPrice(tick.Price, tick.Timestamp)
+			default:
 				ibt.executeSellAtPrice(tick.Price, tick.Timestamp)
 			}
 		}
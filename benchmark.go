@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+	"quantmesh/storage"
+	"quantmesh/utils"
+	"quantmesh/web"
+)
+
+// benchmarkStart 单个交易对的 HODL 对比起算点
+type benchmarkStart struct {
+	StartedAt      time.Time `json:"started_at"`
+	StartPrice     float64   `json:"start_price"`
+	InitialCapital float64   `json:"initial_capital"`
+	RealizedBase   float64   `json:"realized_base"` // 起算时存储中已有的累计已实现盈亏
+}
+
+// benchmarkTracker HODL 基准对比：记录各交易对的起算点（持久化，重启后沿用），定期采样价格与机器人权益
+type benchmarkTracker struct {
+	cfg      *config.Config
+	manager  *SymbolManager
+	store    storage.Storage // 未启用存储时为 nil，权益中不含已实现盈亏
+	interval time.Duration
+
+	mu      sync.Mutex
+	starts  map[string]*benchmarkStart
+	samples map[string][]web.BenchmarkSample
+}
+
+func newBenchmarkTracker(cfg *config.Config, manager *SymbolManager, store storage.Storage) *benchmarkTracker {
+	t := &benchmarkTracker{
+		cfg:      cfg,
+		manager:  manager,
+		store:    store,
+		interval: time.Duration(cfg.Benchmark.SampleInterval) * time.Second,
+		starts:   make(map[string]*benchmarkStart),
+		samples:  make(map[string][]web.BenchmarkSample),
+	}
+	data, err := os.ReadFile(cfg.Benchmark.StateFile)
+	if err == nil {
+		err = json.Unmarshal(data, &t.starts)
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Warn("⚠️ [HODL对比] 读取起算点 %s 失败，将重新起算: %v", cfg.Benchmark.StateFile, err)
+		t.starts = make(map[string]*benchmarkStart)
+	}
+	return t
+}
+
+// Start 按 sample_interval 定期采样
+func (t *benchmarkTracker) Start(ctx context.Context) {
+	logger.Info("✅ [HODL对比] 已启用，采样间隔 %v，起算点文件: %s", t.interval, t.cfg.Benchmark.StateFile)
+	utils.GoSupervised(ctx, "benchmark-tracker", func(ctx context.Context) {
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.sample(time.Now())
+			}
+		}
+	})
+}
+
+// sample 采样各交易对的价格与权益；首次采样到价格的交易对以当前价格起算
+func (t *benchmarkTracker) sample(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	added := false
+	for _, rt := range t.manager.List() {
+		if rt == nil || rt.SuperPositionManager == nil || rt.PriceMonitor == nil {
+			continue
+		}
+		price := rt.PriceMonitor.GetLastPrice()
+		if price <= 0 {
+			continue
+		}
+		key := runtimeKey(rt.Config.Exchange, rt.Config.Symbol)
+		start, ok := t.starts[key]
+		if !ok {
+			capital := t.initialCapital(rt)
+			if capital <= 0 {
+				continue
+			}
+			start = &benchmarkStart{StartedAt: now, StartPrice: price, InitialCapital: capital, RealizedBase: t.realizedPnL(rt)}
+			t.starts[key] = start
+			added = true
+			logger.Info("📐 [HODL对比] %s 起算: 价格 %.8g, 初始资金 %.2f", key, price, capital)
+		}
+
+		sym := t.compute(rt, start, price, nil)
+		samples := append(t.samples[key], web.BenchmarkSample{Time: now, Price: price, Equity: sym.BotEquity})
+		if len(samples) > t.cfg.Benchmark.MaxSamples {
+			samples = samples[len(samples)-t.cfg.Benchmark.MaxSamples:]
+		}
+		t.samples[key] = samples
+	}
+
+	if added {
+		err := os.MkdirAll(filepath.Dir(t.cfg.Benchmark.StateFile), 0755)
+		if err == nil {
+			err = writeJSONAtomic(t.cfg.Benchmark.StateFile, t.starts)
+		}
+		if err != nil {
+			logger.Warn("⚠️ [HODL对比] 保存起算点失败: %v", err)
+		}
+	}
+}
+
+// BenchmarkReport 各交易对当前的 HODL 对比（尚未起算的交易对不包含在内）
+func (t *benchmarkTracker) BenchmarkReport() web.BenchmarkReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	runtimes := t.manager.List()
+	sort.Slice(runtimes, func(i, j int) bool {
+		return runtimeKey(runtimes[i].Config.Exchange, runtimes[i].Config.Symbol) <
+			runtimeKey(runtimes[j].Config.Exchange, runtimes[j].Config.Symbol)
+	})
+	symbols := make([]web.BenchmarkSymbol, 0, len(runtimes))
+	for _, rt := range runtimes {
+		if rt == nil || rt.SuperPositionManager == nil || rt.PriceMonitor == nil {
+			continue
+		}
+		key := runtimeKey(rt.Config.Exchange, rt.Config.Symbol)
+		start, ok := t.starts[key]
+		price := rt.PriceMonitor.GetLastPrice()
+		if !ok || price <= 0 {
+			continue
+		}
+		symbols = append(symbols, t.compute(rt, start, price, t.samples[key]))
+	}
+	return web.NewBenchmarkReport(symbols, t.interval)
+}
+
+// compute 以最新价格计算单个交易对的权益与 HODL 对比
+func (t *benchmarkTracker) compute(rt *SymbolRuntime, start *benchmarkStart, price float64, samples []web.BenchmarkSample) web.BenchmarkSymbol {
+	sym := web.BenchmarkSymbol{
+		Exchange:       strings.ToLower(rt.Config.Exchange),
+		Symbol:         strings.ToUpper(rt.Config.Symbol),
+		StartedAt:      start.StartedAt,
+		StartPrice:     start.StartPrice,
+		InitialCapital: start.InitialCapital,
+		CurrentPrice:   price,
+		RealizedPnL:    t.realizedPnL(rt) - start.RealizedBase,
+	}
+	if qty, _, avgEntry := rt.SuperPositionManager.GetCostBasis(); qty != 0 {
+		sym.UnrealizedPnL = (price - avgEntry) * qty
+	}
+	return web.ComputeBenchmarkSymbol(sym, samples, t.interval)
+}
+
+// initialCapital 交易对的初始资金：total_allocated_capital > benchmark.initial_capital > order_quantity × buy_window_size
+func (t *benchmarkTracker) initialCapital(rt *SymbolRuntime) float64 {
+	if rt.Config.TotalAllocatedCapital > 0 {
+		return rt.Config.TotalAllocatedCapital
+	}
+	if t.cfg.Benchmark.InitialCapital > 0 {
+		return t.cfg.Benchmark.InitialCapital
+	}
+	return rt.Config.OrderQuantity * float64(rt.Config.BuyWindowSize)
+}
+
+// realizedPnL 存储中该交易对的累计已实现盈亏
+func (t *benchmarkTracker) realizedPnL(rt *SymbolRuntime) float64 {
+	if t.store == nil {
+		return 0
+	}
+	// 存储中交易所为小写、交易对为大写
+	summary, err := t.store.GetStatisticsSummaryBySymbol(strings.ToLower(rt.Config.Exchange), strings.ToUpper(rt.Config.Symbol))
+	if err != nil || summary == nil {
+		return 0
+	}
+	return summary.TotalPnL
+}
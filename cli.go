@@ -0,0 +1,183 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"quantmesh/config"
+	"quantmesh/web"
+)
+
+// cliCommand 子命令
+// 每个子命令使用独立的 FlagSet 解析参数，共用 --config 和同一个配置加载函数
+type cliCommand struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+// cliCommands 所有子命令（第一个为默认命令）
+func cliCommands() []*cliCommand {
+	return []*cliCommand{
+		{name: "run", summary: "运行交易系统（默认）", run: cmdRun},
+		{name: "backtest", summary: "使用历史K线回测策略", run: cmdBacktest},
+		{name: "export", summary: "导出交易/订单/统计数据（CSV 或 JSON）", run: cmdExport},
+		{name: "migrate-storage", summary: "把 SQLite 存储中的数据迁移到 database 配置的数据库", run: cmdMigrateStorage},
+		{name: "schema", summary: "查看或执行存储表结构迁移（status / up / down）", run: cmdSchema},
+		{name: "keys", summary: "查看或更新交易所 API 密钥", run: cmdKeys},
+		{name: "status", summary: "查看运行中实例的交易对状态（headless 控制通道）", run: cmdStatus},
+		{name: "pause", summary: "暂停交易，保留挂单（headless 控制通道）", run: cmdPause},
+		{name: "resume", summary: "恢复交易（headless 控制通道）", run: cmdResume},
+		{name: "cancel", summary: "暂停交易并撤销挂单（headless 控制通道）", run: cmdCancel},
+		{name: "stats", summary: "查询本地数据库中的每日统计", run: cmdStats},
+		{name: "api", summary: "通过本地管理 socket 调用运行中实例的 API", run: cmdAPI},
+		{name: "doctor", summary: "检查配置与运行环境", run: cmdDoctor},
+		{name: "version", summary: "显示版本号", run: cmdVersion},
+	}
+}
+
+// findCommand 按名称查找子命令
+func findCommand(name string) *cliCommand {
+	for _, c := range cliCommands() {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func main() {
+	args := os.Args[1:]
+	cmd := cliCommands()[0]
+	if len(args) > 0 {
+		switch args[0] {
+		case "-version", "--version":
+			cmd, args = findCommand("version"), args[1:]
+		case "-h", "--help", "help":
+			printUsage()
+			return
+		default:
+			// 兼容旧用法：quantmesh [config.yaml] [--debug] 等同于 quantmesh run
+			if c := findCommand(args[0]); c != nil {
+				cmd, args = c, args[1:]
+			}
+		}
+	}
+
+	if err := cmd.run(args); err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "❌ %s: %v\n", cmd.name, err)
+		os.Exit(1)
+	}
+}
+
+// printUsage 打印子命令列表
+func printUsage() {
+	fmt.Printf("QuantMesh Market Maker %s\n\n", Version)
+	fmt.Println("用法: quantmesh <command> [flags]")
+	fmt.Println()
+	fmt.Println("命令:")
+	for _, c := range cliCommands() {
+		fmt.Printf("  %-16s %s\n", c.name, c.summary)
+	}
+	fmt.Println()
+	fmt.Println("使用 quantmesh <command> -h 查看命令参数")
+}
+
+// newCommandFlags 创建子命令的 FlagSet，并注册公共参数 --config
+func newCommandFlags(name string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configPath := fs.String("config", "config.yaml", "配置文件路径")
+	return fs, configPath
+}
+
+// parseCommandFlags 解析参数，允许参数和位置参数交错（quantmesh run config.yaml --debug）
+// 返回所有位置参数
+func parseCommandFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// loadCommandConfig 子命令共用的配置加载
+func loadCommandConfig(path string) (*config.Config, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("配置文件不可用: %w", err)
+	}
+	return config.LoadConfig(path)
+}
+
+// runOptions run 子命令参数
+type runOptions struct {
+	configPath  string
+	logDB       string
+	debug       bool
+	maintenance bool
+}
+
+// cmdRun 运行交易系统
+func cmdRun(args []string) error {
+	fs, configPath := newCommandFlags("run")
+	opts := runOptions{}
+	fs.StringVar(&opts.logDB, "log-db", "./logs.db", "日志数据库路径")
+	fs.BoolVar(&opts.debug, "debug", false, "输出全量请求日志")
+	fs.BoolVar(&opts.maintenance, "maintenance", false, "维护模式：只启动 Web 服务，不启动交易")
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	opts.configPath = *configPath
+	// 兼容旧用法：配置文件路径作为第一个位置参数
+	if len(positional) > 0 {
+		opts.configPath = positional[0]
+	}
+
+	runTrading(opts)
+	if restartExitCode != 0 {
+		// 协调重启：以特定退出码退出，由进程管理器拉起新进程
+		os.Exit(restartExitCode)
+	}
+	return nil
+}
+
+// cmdVersion 显示版本号
+func cmdVersion(args []string) error {
+	web.SetVersion(Version)
+	web.SetBuildInfo(GitCommit, BuildTime)
+	info := web.GetBuildInfo()
+	fmt.Printf("QuantMesh Market Maker\n")
+	fmt.Printf("Version: %s\n", info.Version)
+	fmt.Printf("Commit:  %s\n", info.GitCommit)
+	if info.BuildTime != "" {
+		fmt.Printf("Built:   %s\n", info.BuildTime)
+	}
+	fmt.Printf("Go:      %s\n", info.GoVersion)
+	if info.UIEmbedded {
+		fmt.Printf("UI:      %s\n", info.UIHash)
+	}
+	return nil
+}
+
+// maskSecret 脱敏显示密钥
+func maskSecret(secret string) string {
+	if secret == "" {
+		return "(未设置)"
+	}
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", len(secret)-8) + secret[len(secret)-4:]
+}
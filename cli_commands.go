@@ -0,0 +1,509 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"quantmesh/backtest"
+	"quantmesh/config"
+	"quantmesh/database"
+	"quantmesh/storage"
+)
+
+// parseDateFlag 解析 YYYY-MM-DD 日期参数
+func parseDateFlag(name, value string) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--%s 格式应为 YYYY-MM-DD: %w", name, err)
+	}
+	return t, nil
+}
+
+// cmdBacktest 使用历史K线回测策略
+func cmdBacktest(args []string) error {
+	fs, configPath := newCommandFlags("backtest")
+	symbol := fs.String("symbol", "", "交易对（默认使用配置中的第一个交易对）")
+	strategyName := fs.String("strategy", "momentum", "策略: momentum / mean_reversion / trend_following")
+	interval := fs.String("interval", "1h", "K线周期")
+	start := fs.String("start", time.Now().AddDate(0, 0, -30).Format("2006-01-02"), "开始日期 (YYYY-MM-DD)")
+	end := fs.String("end", time.Now().Format("2006-01-02"), "结束日期 (YYYY-MM-DD)")
+	capital := fs.Float64("capital", 10000, "初始资金 (USDT)")
+	if _, err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	binanceConfig := map[string]string{"api_key": "", "secret_key": "", "testnet": "false"}
+	if cfg, err := loadCommandConfig(*configPath); err == nil {
+		if *symbol == "" && len(cfg.Trading.Symbols) > 0 {
+			*symbol = cfg.Trading.Symbols[0].Symbol
+		}
+		if ex, ok := cfg.Exchanges["binance"]; ok {
+			binanceConfig["api_key"] = ex.APIKey
+			binanceConfig["secret_key"] = ex.SecretKey
+			binanceConfig["testnet"] = strconv.FormatBool(ex.Testnet)
+		}
+	}
+	if *symbol == "" {
+		return fmt.Errorf("需要 --symbol")
+	}
+
+	var strategy backtest.StrategyAdapter
+	switch *strategyName {
+	case "momentum":
+		strategy = backtest.NewMomentumAdapter()
+	case "mean_reversion":
+		strategy = backtest.NewMeanReversionAdapter()
+	case "trend_following":
+		strategy = backtest.NewTrendFollowingAdapter()
+	default:
+		return fmt.Errorf("不支持的策略: %s", *strategyName)
+	}
+
+	startTime, err := parseDateFlag("start", *start)
+	if err != nil {
+		return err
+	}
+	endTime, err := parseDateFlag("end", *end)
+	if err != nil {
+		return err
+	}
+	if !endTime.After(startTime) {
+		return fmt.Errorf("结束日期必须晚于开始日期")
+	}
+
+	candles, err := backtest.GetHistoricalData(*symbol, *interval, startTime, endTime, binanceConfig)
+	if err != nil {
+		return fmt.Errorf("获取历史数据失败: %w", err)
+	}
+	result, err := backtest.NewBacktester(*symbol, candles, strategy, *capital).Run()
+	if err != nil {
+		return err
+	}
+	reportPath, err := backtest.GenerateReport(result)
+	if err != nil {
+		return fmt.Errorf("生成报告失败: %w", err)
+	}
+
+	m := result.Metrics
+	fmt.Printf("📊 %s %s (%s ~ %s, %d 根K线)\n", *symbol, strategy.GetName(), *start, *end, len(candles))
+	fmt.Printf("   总收益率: %.2f%%  年化: %.2f%%  最大回撤: %.2f%%\n", m.TotalReturn, m.AnnualizedReturn, m.MaxDrawdown)
+	fmt.Printf("   夏普: %.2f  交易次数: %d  胜率: %.2f%%\n", m.SharpeRatio, m.TotalTrades, m.WinRate)
+	fmt.Printf("   报告: %s\n", reportPath)
+	return nil
+}
+
+// cmdExport 导出存储中的交易/订单/统计数据
+func cmdExport(args []string) error {
+	fs, configPath := newCommandFlags("export")
+	dataType := fs.String("type", "trades", "数据类型: trades / orders / statistics")
+	from := fs.String("from", time.Now().AddDate(0, -1, 0).Format("2006-01-02"), "开始日期 (YYYY-MM-DD，orders 不适用)")
+	to := fs.String("to", time.Now().Format("2006-01-02"), "结束日期 (YYYY-MM-DD，包含当天)")
+	format := fs.String("format", "csv", "输出格式: csv / json")
+	out := fs.String("out", "", "输出文件（默认标准输出）")
+	exchangeName := fs.String("exchange", "", "只导出指定交易所（默认全部）")
+	symbol := fs.String("symbol", "", "只导出指定交易对（默认全部）")
+	if _, err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("不支持的格式: %s", *format)
+	}
+
+	cfg, err := loadCommandConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	startTime, err := parseDateFlag("from", *from)
+	if err != nil {
+		return err
+	}
+	endTime, err := parseDateFlag("to", *to)
+	if err != nil {
+		return err
+	}
+	endTime = endTime.Add(24*time.Hour - time.Nanosecond)
+
+	if err := requireSQLiteStorage(cfg); err != nil {
+		return err
+	}
+	st, err := storage.NewSQLiteStorage(cfg.Storage.Path)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	scopeExchange, scopeSymbol := strings.ToLower(*exchangeName), strings.ToUpper(*symbol)
+	var header []string
+	var rows [][]string
+	var records interface{}
+	switch *dataType {
+	case "trades":
+		trades, err := queryAllTrades(st, scopeExchange, scopeSymbol, startTime, endTime)
+		if err != nil {
+			return err
+		}
+		records = trades
+		header = []string{"created_at", "exchange", "symbol", "buy_order_id", "sell_order_id", "buy_price", "sell_price", "quantity", "pnl"}
+		for _, t := range trades {
+			rows = append(rows, []string{t.CreatedAt.Format(time.RFC3339), t.Exchange, t.Symbol,
+				strconv.FormatInt(t.BuyOrderID, 10), strconv.FormatInt(t.SellOrderID, 10),
+				formatFloat(t.BuyPrice), formatFloat(t.SellPrice), formatFloat(t.Quantity), formatFloat(t.PnL)})
+		}
+	case "orders":
+		orders, err := queryAllOrders(st, scopeExchange, scopeSymbol)
+		if err != nil {
+			return err
+		}
+		records = orders
+		header = []string{"created_at", "updated_at", "order_id", "client_order_id", "exchange", "symbol", "side", "price", "quantity", "status"}
+		for _, o := range orders {
+			rows = append(rows, []string{o.CreatedAt.Format(time.RFC3339), o.UpdatedAt.Format(time.RFC3339),
+				strconv.FormatInt(o.OrderID, 10), o.ClientOrderID, o.Exchange, o.Symbol, o.Side,
+				formatFloat(o.Price), formatFloat(o.Quantity), o.Status})
+		}
+	case "statistics":
+		stats, err := st.QueryStatistics(scopeExchange, scopeSymbol, startTime, endTime)
+		if err != nil {
+			return err
+		}
+		records = stats
+		header = []string{"date", "exchange", "symbol", "total_trades", "total_volume", "total_pnl", "win_rate"}
+		for _, s := range stats {
+			rows = append(rows, []string{s.Date.Format("2006-01-02"), s.Exchange, s.Symbol, strconv.Itoa(s.TotalTrades),
+				formatFloat(s.TotalVolume), formatFloat(s.TotalPnL), formatFloat(s.WinRate)})
+		}
+	default:
+		return fmt.Errorf("不支持的数据类型: %s", *dataType)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(records)
+	} else {
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		cw.WriteAll(rows)
+		err = cw.Error()
+	}
+	if err != nil {
+		return err
+	}
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, "✅ 已导出 %d 条 %s 到 %s\n", len(rows), *dataType, *out)
+	}
+	return nil
+}
+
+// exportPageSize 分页查询大小（存储层单次最多返回 10000 条）
+const exportPageSize = 5000
+
+// queryAllTrades 分页读取时间范围内的全部交易（exchange/symbol 为空表示不过滤）
+func queryAllTrades(st storage.Storage, exchange, symbol string, startTime, endTime time.Time) ([]*storage.Trade, error) {
+	var all []*storage.Trade
+	for offset := 0; ; offset += exportPageSize {
+		page, err := st.QueryTrades(exchange, symbol, startTime, endTime, exportPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < exportPageSize {
+			break
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].CreatedAt.Before(all[j].CreatedAt) })
+	return all, nil
+}
+
+// queryAllOrders 分页读取全部订单（exchange/symbol 为空表示不过滤）
+func queryAllOrders(st storage.Storage, exchange, symbol string) ([]*storage.Order, error) {
+	var all []*storage.Order
+	for offset := 0; ; offset += exportPageSize {
+		page, err := st.QueryOrders(exchange, symbol, exportPageSize, offset, "")
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < exportPageSize {
+			break
+		}
+	}
+	return all, nil
+}
+
+// formatFloat 导出时的浮点数格式
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// cmdMigrateStorage 把 SQLite 存储（storage.path）中的交易、订单和统计迁移到 database 配置的数据库
+// 往返交易拆分为买入和卖出两条成交记录，盈亏记在卖出记录上
+func cmdMigrateStorage(args []string) error {
+	fs, configPath := newCommandFlags("migrate-storage")
+	dryRun := fs.Bool("dry-run", false, "只统计待迁移的记录数，不写入")
+	if _, err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadCommandConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if cfg.Database.Type == "" || cfg.Database.DSN == "" {
+		return fmt.Errorf("未配置目标数据库（database.type / database.dsn）")
+	}
+
+	if err := requireSQLiteStorage(cfg); err != nil {
+		return err
+	}
+	st, err := storage.NewSQLiteStorage(cfg.Storage.Path)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	trades, err := queryAllTrades(st, "", "", time.Unix(0, 0), time.Now())
+	if err != nil {
+		return fmt.Errorf("读取交易失败: %w", err)
+	}
+	orders, err := queryAllOrders(st, "", "")
+	if err != nil {
+		return fmt.Errorf("读取订单失败: %w", err)
+	}
+	stats, err := st.QueryStatistics("", "", time.Unix(0, 0), time.Now())
+	if err != nil {
+		return fmt.Errorf("读取统计失败: %w", err)
+	}
+	fmt.Printf("📦 源存储 %s: 交易 %d 条, 订单 %d 条, 统计 %d 条\n", cfg.Storage.Path, len(trades), len(orders), len(stats))
+	if *dryRun {
+		return nil
+	}
+
+	db, err := database.NewDatabase(&database.Config{
+		Type:            cfg.Database.Type,
+		DSN:             cfg.Database.DSN,
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: time.Duration(cfg.Database.ConnMaxLifetime) * time.Second,
+		LogLevel:        cfg.Database.LogLevel,
+	})
+	if err != nil {
+		return fmt.Errorf("连接目标数据库失败: %w", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	dbTrades := make([]*database.Trade, 0, len(trades)*2)
+	for _, t := range trades {
+		exchangeName := t.Exchange
+		if exchangeName == "" {
+			exchangeName = cfg.App.CurrentExchange
+		}
+		dbTrades = append(dbTrades,
+			&database.Trade{Exchange: exchangeName, Symbol: t.Symbol, OrderID: t.BuyOrderID, Side: "BUY",
+				Price: t.BuyPrice, Quantity: t.Quantity, Amount: t.BuyPrice * t.Quantity, CreatedAt: t.CreatedAt},
+			&database.Trade{Exchange: exchangeName, Symbol: t.Symbol, OrderID: t.SellOrderID, Side: "SELL",
+				Price: t.SellPrice, Quantity: t.Quantity, Amount: t.SellPrice * t.Quantity, PnL: t.PnL, CreatedAt: t.CreatedAt})
+	}
+	for i := 0; i < len(dbTrades); i += exportPageSize {
+		j := i + exportPageSize
+		if j > len(dbTrades) {
+			j = len(dbTrades)
+		}
+		if err := db.BatchSaveTrades(ctx, dbTrades[i:j]); err != nil {
+			return fmt.Errorf("写入交易失败: %w", err)
+		}
+	}
+
+	for _, o := range orders {
+		exchangeName := o.Exchange
+		if exchangeName == "" {
+			exchangeName = cfg.App.CurrentExchange
+		}
+		if err := db.SaveOrder(ctx, &database.Order{Exchange: exchangeName, Symbol: o.Symbol, OrderID: o.OrderID,
+			ClientOrderID: o.ClientOrderID, Side: o.Side, Type: "LIMIT", Price: o.Price, Quantity: o.Quantity,
+			Status: o.Status, CreatedAt: o.CreatedAt, UpdatedAt: o.UpdatedAt}); err != nil {
+			return fmt.Errorf("写入订单 %d 失败: %w", o.OrderID, err)
+		}
+	}
+
+	for _, s := range stats {
+		exchangeName := s.Exchange
+		if exchangeName == "" {
+			exchangeName = cfg.App.CurrentExchange
+		}
+		if err := db.SaveStatistics(ctx, &database.Statistics{Exchange: exchangeName, Symbol: s.Symbol, Date: s.Date,
+			TotalPnL: s.TotalPnL, WinRate: s.WinRate, Volume: s.TotalVolume, TradeCount: s.TotalTrades,
+			CreatedAt: s.CreatedAt}); err != nil {
+			return fmt.Errorf("写入统计失败: %w", err)
+		}
+	}
+
+	fmt.Printf("✅ 已迁移到 %s: 成交 %d 条, 订单 %d 条, 统计 %d 条\n", cfg.Database.Type, len(dbTrades), len(orders), len(stats))
+	return nil
+}
+
+// cmdSchema 查看或执行 SQLite 存储的表结构迁移
+// quantmesh schema status
+// quantmesh schema up
+// quantmesh schema down --to 1
+func cmdSchema(args []string) error {
+	action := "status"
+	if len(args) > 0 && (args[0] == "status" || args[0] == "up" || args[0] == "down") {
+		action, args = args[0], args[1:]
+	}
+
+	fs, configPath := newCommandFlags("schema " + action)
+	target := fs.Int("to", -1, "回滚到的目标版本（down 必填）")
+	if _, err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadCommandConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if err := requireSQLiteStorage(cfg); err != nil {
+		return err
+	}
+	db, err := storage.OpenSQLite(cfg.Storage.Path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch action {
+	case "up":
+		if err := storage.MigrateSQLite(db, cfg.Storage.Path); err != nil {
+			return err
+		}
+	case "down":
+		if *target < 0 {
+			return fmt.Errorf("down 需要 --to 指定目标版本")
+		}
+		m, err := storage.NewSQLiteMigrator(db, cfg.Storage.Path)
+		if err != nil {
+			return err
+		}
+		if _, err := m.Down(*target); err != nil {
+			return err
+		}
+	}
+
+	m, err := storage.NewSQLiteMigrator(db, cfg.Storage.Path)
+	if err != nil {
+		return err
+	}
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	current, err := m.Current()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("📦 %s: 当前版本 %d, 最新版本 %d\n", cfg.Storage.Path, current, m.Latest())
+	for _, st := range status {
+		state := "待执行"
+		if st.Applied {
+			state = "已执行 " + st.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		reversible := ""
+		if !st.Reversible {
+			reversible = "（不可回滚）"
+		}
+		fmt.Printf("  %04d_%-24s %s%s\n", st.Version, st.Name, state, reversible)
+	}
+	return nil
+}
+
+// cmdKeys 查看或更新交易所 API 密钥
+// quantmesh keys list
+// quantmesh keys set --exchange binance --api-key xxx --secret-key yyy
+func cmdKeys(args []string) error {
+	action := "list"
+	if len(args) > 0 && (args[0] == "list" || args[0] == "set") {
+		action, args = args[0], args[1:]
+	}
+
+	fs, configPath := newCommandFlags("keys " + action)
+	exchangeName := fs.String("exchange", "", "交易所名称（set 必填）")
+	apiKey := fs.String("api-key", "", "API Key")
+	secretKey := fs.String("secret-key", "", "Secret Key")
+	passphrase := fs.String("passphrase", "", "Passphrase（部分交易所需要）")
+	if _, err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadCommandConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	if action == "list" {
+		names := make([]string, 0, len(cfg.Exchanges))
+		for name := range cfg.Exchanges {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			ex := cfg.Exchanges[name]
+			current := ""
+			if name == cfg.App.CurrentExchange {
+				current = " (当前)"
+			}
+			fmt.Printf("%s%s\n  api_key:    %s\n  secret_key: %s\n  testnet:    %v\n",
+				name, current, maskSecret(ex.APIKey), maskSecret(ex.SecretKey), ex.Testnet)
+		}
+		return nil
+	}
+
+	if *exchangeName == "" || (*apiKey == "" && *secretKey == "" && *passphrase == "") {
+		return fmt.Errorf("需要 --exchange 以及 --api-key / --secret-key / --passphrase 中的至少一个")
+	}
+	if cfg.Exchanges == nil {
+		cfg.Exchanges = make(map[string]config.ExchangeConfig)
+	}
+	ex := cfg.Exchanges[*exchangeName]
+	if *apiKey != "" {
+		ex.APIKey = *apiKey
+	}
+	if *secretKey != "" {
+		ex.SecretKey = *secretKey
+	}
+	if *passphrase != "" {
+		ex.Passphrase = *passphrase
+	}
+	cfg.Exchanges[*exchangeName] = ex
+	if err := config.SaveConfig(cfg, *configPath); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 已更新 %s 的密钥 (api_key: %s)\n", *exchangeName, maskSecret(ex.APIKey))
+	return nil
+}
+
+// requireSQLiteStorage 离线数据命令只能操作 SQLite 数据库文件
+func requireSQLiteStorage(cfg *config.Config) error {
+	if cfg.Storage.Type != "sqlite" {
+		return fmt.Errorf("存储类型 %s 不支持该命令（仅支持 sqlite）", cfg.Storage.Type)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"quantmesh/storage"
+)
+
+// cmdStatus 查看运行中实例的各交易对状态
+func cmdStatus(args []string) error {
+	return runControlCommand(controlActionStatus, args)
+}
+
+// cmdPause 暂停交易（保留挂单）
+func cmdPause(args []string) error {
+	return runControlCommand(controlActionPause, args)
+}
+
+// cmdResume 恢复交易
+func cmdResume(args []string) error {
+	return runControlCommand(controlActionResume, args)
+}
+
+// cmdCancel 暂停交易并撤销挂单
+func cmdCancel(args []string) error {
+	return runControlCommand(controlActionCancel, args)
+}
+
+// runControlCommand 通过控制目录把命令发给运行中的实例并打印结果
+func runControlCommand(action string, args []string) error {
+	fs, configPath := newCommandFlags(action)
+	exchangeName := fs.String("exchange", "", "只作用于指定交易所（默认全部）")
+	symbol := fs.String("symbol", "", "只作用于指定交易对（默认全部）")
+	timeout := fs.Duration("timeout", 15*time.Second, "等待实例响应的时间")
+	asJSON := fs.Bool("json", false, "以 JSON 输出结果")
+	if _, err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadCommandConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	req := controlRequest{Action: action, Exchange: strings.ToLower(*exchangeName), Symbol: strings.ToUpper(*symbol)}
+	resp, err := sendControlRequest(cfg.Headless.ControlDir, req, *timeout)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("QuantMesh %s（模式: %s）\n", resp.Version, resp.Mode)
+		if len(resp.Symbols) == 0 {
+			fmt.Println("  没有运行中的交易对")
+		}
+		for _, s := range resp.Symbols {
+			state := "运行中"
+			if s.Paused {
+				state = "已暂停"
+			}
+			if len(s.BuyHalts) > 0 {
+				state += "（禁止买入: " + strings.Join(s.BuyHalts, ",") + "）"
+			}
+			fmt.Printf("  %-10s %-12s %s  价格 %s  持仓 %s  均价 %s  层数 %d\n", s.Exchange, s.Symbol, state,
+				formatFloat(s.LastPrice), formatFloat(s.Position), formatFloat(s.AvgEntryPrice), s.ActiveLayers)
+			if s.Error != "" {
+				fmt.Printf("    ❌ %s\n", s.Error)
+			}
+		}
+	}
+	if !resp.OK {
+		return fmt.Errorf("%s", resp.Error)
+	}
+	return nil
+}
+
+// cmdStats 查询本地数据库中的每日统计（不需要实例在运行）
+func cmdStats(args []string) error {
+	fs, configPath := newCommandFlags("stats")
+	from := fs.String("from", time.Now().AddDate(0, 0, -30).Format("2006-01-02"), "开始日期 (YYYY-MM-DD)")
+	to := fs.String("to", time.Now().Format("2006-01-02"), "结束日期 (YYYY-MM-DD，包含当天)")
+	exchangeName := fs.String("exchange", "", "只统计指定交易所（默认全部）")
+	symbol := fs.String("symbol", "", "只统计指定交易对（默认全部）")
+	asJSON := fs.Bool("json", false, "以 JSON 输出结果")
+	if _, err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadCommandConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	startTime, err := parseDateFlag("from", *from)
+	if err != nil {
+		return err
+	}
+	endTime, err := parseDateFlag("to", *to)
+	if err != nil {
+		return err
+	}
+	endTime = endTime.Add(24*time.Hour - time.Nanosecond)
+
+	if err := requireSQLiteStorage(cfg); err != nil {
+		return err
+	}
+	st, err := storage.NewSQLiteStorage(cfg.Storage.Path)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	stats, err := st.QueryStatistics(strings.ToLower(*exchangeName), strings.ToUpper(*symbol), startTime, endTime)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+
+	var trades int
+	var volume, pnl, wins float64
+	fmt.Printf("%-10s  %-10s %-12s %8s %14s %12s %8s\n", "日期", "交易所", "交易对", "成交数", "成交量", "盈亏", "胜率")
+	for _, s := range stats {
+		fmt.Printf("%-10s  %-10s %-12s %8d %14.2f %12.4f %7.1f%%\n", s.Date.Format("2006-01-02"), s.Exchange, s.Symbol,
+			s.TotalTrades, s.TotalVolume, s.TotalPnL, s.WinRate*100)
+		trades += s.TotalTrades
+		volume += s.TotalVolume
+		pnl += s.TotalPnL
+		wins += s.WinRate * float64(s.TotalTrades)
+	}
+	winRate := 0.0
+	if trades > 0 {
+		winRate = wins / float64(trades)
+	}
+	fmt.Printf("%-10s  %-23s %8d %14.2f %12.4f %7.1f%%\n", "合计", "", trades, volume, pnl, winRate*100)
+	return nil
+}
+
+// cmdAPI 通过本地管理 socket 调用运行中实例的 Web API
+// quantmesh api GET /api/status
+// quantmesh api POST /api/trading/stop?exchange=binance&symbol=ETHUSDT
+// quantmesh api PUT /api/xxx --data '{"key":"value"}'
+func cmdAPI(args []string) error {
+	fs, configPath := newCommandFlags("api")
+	data := fs.String("data", "", "请求体（JSON）")
+	timeout := fs.Duration("timeout", 60*time.Second, "请求超时")
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return fmt.Errorf("用法: quantmesh api <METHOD> <PATH> [--data JSON]")
+	}
+	method, path := strings.ToUpper(positional[0]), positional[1]
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	cfg, err := loadCommandConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if !cfg.AdminSocket.Enabled {
+		return fmt.Errorf("管理 socket 未启用（admin_socket.enabled=false）")
+	}
+
+	socketPath := cfg.AdminSocket.Path
+	client := &http.Client{
+		Timeout: *timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+	var body io.Reader
+	if *data != "" {
+		body = strings.NewReader(*data)
+	}
+	// 主机名只用于构造 URL，实际连接的是管理 socket
+	req, err := http.NewRequest(method, "http://localhost"+path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("连接管理 socket %s 失败（实例是否在运行？）: %w", socketPath, err)
+	}
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, payload, "", "  ") == nil {
+		payload = pretty.Bytes()
+	}
+	os.Stdout.Write(payload)
+	fmt.Println()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -2,7 +2,13 @@ package config
 
 import (
 	"fmt"
+	"math"
+	"net"
 	"os"
+	"strings"
+	"time"
+
+	"quantmesh/utils"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,6 +23,372 @@ type GridRiskControl struct {
 	TrendFilterEnabled      bool    `yaml:"trend_filter_enabled" json:"trend_filter_enabled"`             // 是否开启趋势过滤
 }
 
+// RegimeWindowProfile 某一市场状态下使用的挂单窗口（0 表示沿用交易对配置）
+type RegimeWindowProfile struct {
+	BuyWindowSize  int `yaml:"buy_window_size" json:"buy_window_size"`
+	SellWindowSize int `yaml:"sell_window_size" json:"sell_window_size"`
+}
+
+// RegimeConfig 市场状态分类配置（趋势/震荡/高波动），分类结果用于切换挂单窗口
+type RegimeConfig struct {
+	Enabled           bool    `yaml:"enabled" json:"enabled"`
+	Interval          string  `yaml:"interval" json:"interval"`                       // K线周期（默认 15m）
+	Lookback          int     `yaml:"lookback" json:"lookback"`                       // 参与计算的K线数量（默认 100）
+	ADXPeriod         int     `yaml:"adx_period" json:"adx_period"`                   // ADX 周期（默认 14）
+	ADXTrendThreshold float64 `yaml:"adx_trend_threshold" json:"adx_trend_threshold"` // ADX 高于此值视为趋势（默认 25）
+	HighVolThreshold  float64 `yaml:"high_vol_threshold" json:"high_vol_threshold"`   // 单根K线收益率标准差（%）高于此值视为高波动（默认 1.0）
+	CheckInterval     int     `yaml:"check_interval" json:"check_interval"`           // 检查间隔（秒，默认 300）
+
+	Profiles struct {
+		TrendUp   RegimeWindowProfile `yaml:"trend_up" json:"trend_up"`
+		TrendDown RegimeWindowProfile `yaml:"trend_down" json:"trend_down"`
+		Range     RegimeWindowProfile `yaml:"range" json:"range"`
+		HighVol   RegimeWindowProfile `yaml:"high_vol" json:"high_vol"`
+	} `yaml:"profiles" json:"profiles"`
+}
+
+// TrendServiceConfig 多周期趋势服务配置（EMA/MACD），趋势检测器与各策略共享同一结果
+type TrendServiceConfig struct {
+	Enabled       bool     `yaml:"enabled" json:"enabled"`
+	Timeframes    []string `yaml:"timeframes" json:"timeframes"`         // K线周期（默认 1m/15m/1h）
+	FastPeriod    int      `yaml:"fast_period" json:"fast_period"`       // 快线 EMA 周期（默认 12）
+	SlowPeriod    int      `yaml:"slow_period" json:"slow_period"`       // 慢线 EMA 周期（默认 26）
+	SignalPeriod  int      `yaml:"signal_period" json:"signal_period"`   // MACD 信号线周期（默认 9）
+	Lookback      int      `yaml:"lookback" json:"lookback"`             // 每个周期拉取的K线数量（默认 100）
+	CheckInterval int      `yaml:"check_interval" json:"check_interval"` // 最短刷新间隔（秒，默认 60），长周期按K线时长的 1/4 刷新
+}
+
+// LevelsConfig 支撑/阻力位与K线形态检测配置
+type LevelsConfig struct {
+	Enabled       bool    `yaml:"enabled" json:"enabled"`
+	Interval      string  `yaml:"interval" json:"interval"`             // K线周期（默认 1h）
+	Lookback      int     `yaml:"lookback" json:"lookback"`             // 参与计算的K线数量（默认 200）
+	PivotWindow   int     `yaml:"pivot_window" json:"pivot_window"`     // 摆动高低点左右确认的K线数量（默认 3）
+	Tolerance     float64 `yaml:"tolerance" json:"tolerance"`           // 摆动点合并为同一价位的容差（%，默认 0.3）
+	MaxLevels     int     `yaml:"max_levels" json:"max_levels"`         // 支撑/阻力每侧最多保留的价位数量（默认 5）
+	PatternBars   int     `yaml:"pattern_bars" json:"pattern_bars"`     // 检测K线形态的最近K线数量（默认 10）
+	CheckInterval int     `yaml:"check_interval" json:"check_interval"` // 刷新间隔（秒，默认 300）
+
+	// 网格偏置：在检测到的支撑位附近加密买单槽位（在原网格价格之间插入半格价格）
+	GridBias struct {
+		Enabled     bool    `yaml:"enabled" json:"enabled"`
+		ZonePercent float64 `yaml:"zone_percent" json:"zone_percent"` // 支撑位上下加密范围（%，默认 0.5）
+		MinTouches  int     `yaml:"min_touches" json:"min_touches"`   // 触及次数达到该值的支撑位才加密（默认 2）
+	} `yaml:"grid_bias" json:"grid_bias"`
+}
+
+// VolatilityGuardConfig 短时高波动保护：短窗口已实现波动率超过阈值时，新挂单强制 PostOnly 并拉开与现价的距离，回落后自动恢复
+type VolatilityGuardConfig struct {
+	Enabled          bool    `yaml:"enabled" json:"enabled"`
+	WindowSeconds    int     `yaml:"window_seconds" json:"window_seconds"`         // 已实现波动率的统计窗口（秒，默认 60）
+	ThresholdPercent float64 `yaml:"threshold_percent" json:"threshold_percent"`   // 窗口内已实现波动率（%）超过此值时启用保护（默认 1.0）
+	RecoverPercent   float64 `yaml:"recover_percent" json:"recover_percent"`       // 波动率回落到此值以下才解除（%，默认为阈值的 60%）
+	MinActiveSeconds int     `yaml:"min_active_seconds" json:"min_active_seconds"` // 启用后至少保持的时间（秒，默认 120）
+	OffsetIntervals  float64 `yaml:"offset_intervals" json:"offset_intervals"`     // 保护期间新挂单距现价至少的价格间隔数（默认 1）
+}
+
+// DepthGuardConfig 买盘深度骤降保护：盘口前几档买盘在几秒内大幅消失时暂停新开仓并撤销买单，深度恢复后自动恢复
+// 价格/成交量偏离检查依赖K线，对撤单式砸盘反应太慢；盘口深度的变化通常先于价格
+type DepthGuardConfig struct {
+	Enabled          bool    `yaml:"enabled" json:"enabled"`
+	Levels           int     `yaml:"levels" json:"levels"`                         // 统计的买盘档位数（默认 5）
+	WindowSeconds    int     `yaml:"window_seconds" json:"window_seconds"`         // 比较窗口（秒，默认 5）：当前深度与窗口内最大深度比较
+	DropPercent      float64 `yaml:"drop_percent" json:"drop_percent"`             // 买盘深度下降超过此比例时触发（%，默认 70）
+	RecoverPercent   float64 `yaml:"recover_percent" json:"recover_percent"`       // 深度恢复到触发前基准的此比例后解除（%，默认 60）
+	MinActiveSeconds int     `yaml:"min_active_seconds" json:"min_active_seconds"` // 触发后至少保持的时间（秒，默认 30）
+	MaxActiveSeconds int     `yaml:"max_active_seconds" json:"max_active_seconds"` // 超过此时间仍未恢复也解除，视为盘口进入新常态（秒，默认 300）
+}
+
+// 交易所上调最小名义价值后的处理方式
+const (
+	MinNotionalActionAlert  = "alert"  // 只告警
+	MinNotionalActionAdjust = "adjust" // 自动调大每单金额
+	MinNotionalActionPause  = "pause"  // 暂停该交易对
+)
+
+// MinNotionalChangeConfig 交易所上调最小名义价值（定期刷新交易对规则时检测）后的处理：
+// 每单金额低于新的最小名义价值时告警，并可自动调大每单金额或暂停该交易对
+type MinNotionalChangeConfig struct {
+	Action        string  `yaml:"action" json:"action"`                 // alert / adjust / pause（默认 alert）
+	BufferPercent float64 `yaml:"buffer_percent" json:"buffer_percent"` // adjust 时每单金额 = 新最小名义价值 × (1 + buffer_percent/100)，默认 10
+}
+
+// 退出时按策略的处理方式
+const (
+	ShutdownPolicyCancelAll  = "cancel_all"  // 撤销该策略的全部挂单，保留持仓
+	ShutdownPolicyCancelBuys = "cancel_buys" // 只撤买单，卖单（止盈单）继续挂在交易所
+	ShutdownPolicyFlatten    = "flatten"     // 撤销全部挂单并平掉该策略的持仓
+	ShutdownPolicyLeave      = "leave"       // 不撤单也不平仓
+)
+
+// ShutdownPolicyConfig 退出时按策略的处理方式，替代全局的 cancel_on_exit / close_positions_on_exit：
+// 例如网格撤单后干净重启，而长期持有的 DCA 库存及其止盈卖单保留在交易所
+type ShutdownPolicyConfig struct {
+	Default    string            `yaml:"default" json:"default"`       // 未单独配置的策略使用的方式，为空时按 cancel_on_exit / close_positions_on_exit 推导
+	Strategies map[string]string `yaml:"strategies" json:"strategies"` // 策略名称 -> 方式（grid 为网格主策略，其余为 strategies.configs 中的名称）
+}
+
+// RestartConfig 协调重启：按各策略的退出方式处理挂单后保存恢复令牌，以特定退出码退出，由进程管理器（systemd / Docker restart 策略）拉起新版本
+// 新进程校验恢复令牌后沿用原网格锚点并接管保留的挂单，不重新定位网格、不重复挂单
+type RestartConfig struct {
+	ExitCode        int    `yaml:"exit_code" json:"exit_code"`                 // 协调重启的退出码（默认 75，进程管理器据此判断是否立即拉起）
+	TokenFile       string `yaml:"token_file" json:"token_file"`               // 恢复令牌文件（默认 ./data/resume_token.json）
+	TokenTTLSeconds int    `yaml:"token_ttl_seconds" json:"token_ttl_seconds"` // 恢复令牌有效期（秒，默认 600），过期后按正常流程启动
+}
+
+// NotificationChannels 通知渠道标识（与 notifications 下的配置项名称一致），用于"渠道.事件类型"形式的模板键
+var NotificationChannels = []string{"telegram", "webhook", "email", "feishu", "dingtalk", "wechat_work", "slack", "ntfy", "gotify"}
+
+// NotificationTemplateConfig 通知消息模板（Go text/template），可引用 .Title .Type .Severity .Time .Exchange .Symbol .PnL .Data 等字段
+type NotificationTemplateConfig struct {
+	Title string `yaml:"title" json:"title"` // 标题模板，为空时使用内置标题
+	Body  string `yaml:"body" json:"body"`   // 正文模板，为空时按"字段: 值"逐行列出事件数据
+}
+
+func validShutdownPolicy(policy string) bool {
+	switch policy {
+	case ShutdownPolicyCancelAll, ShutdownPolicyCancelBuys, ShutdownPolicyFlatten, ShutdownPolicyLeave:
+		return true
+	}
+	return false
+}
+
+// ReduceOnlyGuardConfig 只减仓统一检查：下单前按当前持仓自动为减仓方向的订单加上 ReduceOnly，并拦截超过持仓数量的只减仓单（避免 -2022 拒单）
+type ReduceOnlyGuardConfig struct {
+	Enabled       bool `yaml:"enabled" json:"enabled"`
+	RefreshMillis int  `yaml:"refresh_millis" json:"refresh_millis"` // 持仓缓存有效期（毫秒，默认 2000；订单成交后立即失效）
+}
+
+// TurnoverLimits 交易额度上限（按已提交订单的名义价值和数量计，滚动窗口，0 表示不限制）
+type TurnoverLimits struct {
+	MaxNotionalPerHour float64 `yaml:"max_notional_per_hour" json:"max_notional_per_hour"` // 1 小时内下单金额上限（USDT）
+	MaxNotionalPerDay  float64 `yaml:"max_notional_per_day" json:"max_notional_per_day"`   // 24 小时内下单金额上限（USDT）
+	MaxOrdersPerHour   int     `yaml:"max_orders_per_hour" json:"max_orders_per_hour"`     // 1 小时内订单数上限
+	MaxOrdersPerDay    int     `yaml:"max_orders_per_day" json:"max_orders_per_day"`       // 24 小时内订单数上限
+}
+
+// TurnoverBudgetConfig 按策略的交易额度：下单执行器拦截超过额度的订单并发出告警，防止异常循环高频下单消耗手续费
+// 额度按交易对分别计算；网格主策略的名称为 grid，多策略模式下按策略名称或类型（grid/dca/martingale/...）匹配
+type TurnoverBudgetConfig struct {
+	Enabled    bool                      `yaml:"enabled" json:"enabled"`
+	Default    TurnoverLimits            `yaml:"default" json:"default"`       // 未单独配置的策略使用的额度
+	Strategies map[string]TurnoverLimits `yaml:"strategies" json:"strategies"` // 按策略名称或类型覆盖
+}
+
+// validate 检查额度配置
+func (l TurnoverLimits) validate(name string) error {
+	if l.MaxNotionalPerHour < 0 || l.MaxNotionalPerDay < 0 || l.MaxOrdersPerHour < 0 || l.MaxOrdersPerDay < 0 {
+		return fmt.Errorf("trading.turnover_budget.%s 的额度不能为负数", name)
+	}
+	return nil
+}
+
+// MarginTierConfig 维持保证金档位（按持仓名义价值分档，与交易所的杠杆分层/风险限额表对应）
+type MarginTierConfig struct {
+	NotionalCap     float64 `yaml:"notional_cap" json:"notional_cap"`         // 该档位的名义价值上限（USDT，0 表示不限，只能用于最后一档）
+	MaxLeverage     int     `yaml:"max_leverage" json:"max_leverage"`         // 该档位允许的最大杠杆（0 表示不限）
+	MaintenanceRate float64 `yaml:"maintenance_rate" json:"maintenance_rate"` // 维持保证金率（如 0.004 表示 0.4%）
+}
+
+// MarginCheckConfig 下单前保证金检查：每批下单前估算所需保证金，超过可用保证金时先向交易所核实，仍不足则裁剪批次（避免连续 -2019 拒单）
+type MarginCheckConfig struct {
+	Enabled        bool               `yaml:"enabled" json:"enabled"`
+	Leverage       int                `yaml:"leverage" json:"leverage"`               // 杠杆倍数（0 表示按交易所持仓/账户查询，查询不到时按 1 倍计算）
+	SafetyBuffer   float64            `yaml:"safety_buffer" json:"safety_buffer"`     // 可用保证金中保留不用的比例（默认 0.05）
+	RefreshSeconds int                `yaml:"refresh_seconds" json:"refresh_seconds"` // 向交易所核实可用保证金的最长间隔（秒，默认 30；估算不足时立即核实）
+	Tiers          []MarginTierConfig `yaml:"tiers" json:"tiers"`                     // 维持保证金档位（按名义价值上限升序，默认单档 0.5%）
+}
+
+// validate 检查保证金检查配置
+func (m MarginCheckConfig) validate() error {
+	if m.Leverage < 0 || m.RefreshSeconds < 0 {
+		return fmt.Errorf("trading.margin_check 的 leverage 和 refresh_seconds 不能为负数")
+	}
+	if m.SafetyBuffer < 0 || m.SafetyBuffer >= 1 {
+		return fmt.Errorf("trading.margin_check.safety_buffer 必须在 0-1 之间")
+	}
+	for i, tier := range m.Tiers {
+		if tier.MaintenanceRate < 0 || tier.MaintenanceRate >= 1 || tier.MaxLeverage < 0 || tier.NotionalCap < 0 {
+			return fmt.Errorf("trading.margin_check.tiers[%d] 的维持保证金率必须在 0-1 之间，杠杆和名义价值上限不能为负数", i)
+		}
+		if tier.NotionalCap == 0 && i != len(m.Tiers)-1 {
+			return fmt.Errorf("trading.margin_check.tiers[%d] 未设置 notional_cap，只有最后一档可以不限上限", i)
+		}
+		if i > 0 && tier.NotionalCap != 0 && tier.NotionalCap <= m.Tiers[i-1].NotionalCap {
+			return fmt.Errorf("trading.margin_check.tiers 必须按 notional_cap 升序排列")
+		}
+	}
+	return nil
+}
+
+// PriceBandConfig 交易对价格带：价格带之外无论策略信号如何都不挂单，由下单执行器统一拦截并发出告警
+// 绝对上下限与相对基准价的百分比上下限同时配置时取较严者，0 表示不限制
+type PriceBandConfig struct {
+	Enabled          bool    `yaml:"enabled" json:"enabled"`
+	MinPrice         float64 `yaml:"min_price" json:"min_price"`                   // 绝对下限（低于该价格不挂单）
+	MaxPrice         float64 `yaml:"max_price" json:"max_price"`                   // 绝对上限（高于该价格不挂单）
+	ReferencePrice   float64 `yaml:"reference_price" json:"reference_price"`       // 百分比价格带的基准价，0 表示使用启动时的网格锚点
+	MaxBelowPercent  float64 `yaml:"max_below_percent" json:"max_below_percent"`   // 低于基准价超过该百分比不挂单
+	MaxAbovePercent  float64 `yaml:"max_above_percent" json:"max_above_percent"`   // 高于基准价超过该百分比不挂单
+	ExemptReduceOnly bool    `yaml:"exempt_reduce_only" json:"exempt_reduce_only"` // 只减仓（平仓）订单不受价格带限制
+}
+
+// validate 检查价格带配置
+func (b PriceBandConfig) validate(symbol string) error {
+	if !b.Enabled {
+		return nil
+	}
+	if b.MinPrice < 0 || b.MaxPrice < 0 || b.ReferencePrice < 0 || b.MaxAbovePercent < 0 {
+		return fmt.Errorf("交易对 %s 的 price_band 不能为负数", symbol)
+	}
+	if b.MaxBelowPercent < 0 || b.MaxBelowPercent >= 100 {
+		return fmt.Errorf("交易对 %s 的 price_band.max_below_percent 必须在 0-100 之间", symbol)
+	}
+	if b.MinPrice > 0 && b.MaxPrice > 0 && b.MinPrice >= b.MaxPrice {
+		return fmt.Errorf("交易对 %s 的 price_band.min_price (%.8g) 必须小于 max_price (%.8g)", symbol, b.MinPrice, b.MaxPrice)
+	}
+	if b.MinPrice == 0 && b.MaxPrice == 0 && b.MaxBelowPercent == 0 && b.MaxAbovePercent == 0 {
+		return fmt.Errorf("交易对 %s 启用了 price_band 但未配置任何上下限", symbol)
+	}
+	return nil
+}
+
+// StrategyLossBreakerConfig 单策略连续亏损熔断：某个策略连续亏损或滚动窗口内亏损过多时只暂停该策略，冷却后自动恢复或通过 API 手动恢复
+type StrategyLossBreakerConfig struct {
+	Enabled              bool    `yaml:"enabled" json:"enabled"`
+	MaxConsecutiveLosses int     `yaml:"max_consecutive_losses" json:"max_consecutive_losses"` // 连续亏损的完整交易（开仓到平仓）次数达到此值时暂停（默认 3）
+	MaxLossPercent       float64 `yaml:"max_loss_percent" json:"max_loss_percent"`             // 滚动窗口内累计亏损占策略分配资金的百分比上限（0 表示不检查）
+	WindowMinutes        int     `yaml:"window_minutes" json:"window_minutes"`                 // 亏损统计的滚动窗口（分钟，默认 1440）
+	CooldownMinutes      int     `yaml:"cooldown_minutes" json:"cooldown_minutes"`             // 暂停后的冷却时间（分钟，默认 60）
+	ManualResume         bool    `yaml:"manual_resume" json:"manual_resume"`                   // 为 true 时冷却结束也不自动恢复，只能通过 API 恢复
+	CheckInterval        int     `yaml:"check_interval" json:"check_interval"`                 // 检查策略统计的间隔（秒，默认 10）
+}
+
+// AnchorConfig 冷启动网格锚点配置
+type AnchorConfig struct {
+	Mode         string  `yaml:"mode" json:"mode"`                   // first_tick（默认，首个推送价格）/ vwap / candle_close / manual
+	VWAPSeconds  int     `yaml:"vwap_seconds" json:"vwap_seconds"`   // vwap 模式的统计时长（秒，默认 30）
+	Price        float64 `yaml:"price" json:"price"`                 // manual 模式的锚点价格（通过 API 设置的锚点优先）
+	Persist      bool    `yaml:"persist" json:"persist"`             // 持久化锚点，重启后沿用上次的锚点而不是重新定位网格
+	MaxDeviation float64 `yaml:"max_deviation" json:"max_deviation"` // 沿用已保存锚点时允许偏离当前价格的最大比例（默认 0.1），超过则重新定位
+}
+
+// RecenterConfig 网格重新居中策略配置
+type RecenterConfig struct {
+	Policy          string `yaml:"policy" json:"policy"`                     // never（默认）/ band（价格偏离锚点超过 band_intervals 个网格时）/ time（每 interval_minutes 分钟）
+	BandIntervals   int    `yaml:"band_intervals" json:"band_intervals"`     // band 策略：价格偏离锚点的网格数阈值（默认 buy_window_size + sell_window_size）
+	IntervalMinutes int    `yaml:"interval_minutes" json:"interval_minutes"` // time 策略：检查间隔（分钟，默认 240）
+	MinShift        int    `yaml:"min_shift" json:"min_shift"`               // 锚点至少移动多少个网格才执行（默认 1），避免空转
+}
+
+// HedgeConfig 库存对冲配置
+type HedgeConfig struct {
+	Enabled           bool              `yaml:"enabled" json:"enabled"`
+	Exchange          string            `yaml:"exchange" json:"exchange"`                     // 对冲所在交易所（默认与网格相同）
+	Account           ExchangeConfig    `yaml:"account" json:"account"`                       // 对冲账户密钥（为空时使用 exchanges 中该交易所的密钥）
+	Symbols           map[string]string `yaml:"symbols" json:"symbols"`                       // 网格交易对 -> 对冲合约（未配置时对冲同一交易对）
+	Ratio             float64           `yaml:"ratio" json:"ratio"`                           // 对冲比例（0~1，默认 0.5），按名义价值对冲网格库存
+	RebalanceInterval int               `yaml:"rebalance_interval" json:"rebalance_interval"` // 再平衡间隔（秒，默认 300）
+	MinNotional       float64           `yaml:"min_notional" json:"min_notional"`             // 偏差低于该名义价值时不调整（默认 20）
+	MaxNotional       float64           `yaml:"max_notional" json:"max_notional"`             // 对冲仓位名义价值上限（0 表示不限制）
+}
+
+// InstanceLockConfig 重复实例检测配置
+// 同一交易所+交易对只允许一个实例交易：本机使用带心跳的锁文件，启用分布式锁时额外持有租约（跨主机）
+type InstanceLockConfig struct {
+	Mode         string `yaml:"mode" json:"mode"`                   // refuse（默认，检测到其它实例时拒绝启动该交易对）/ observer（等待其它实例退出后接管）/ off
+	Dir          string `yaml:"dir" json:"dir"`                     // 锁文件目录，默认 ./data/locks
+	StaleSeconds int    `yaml:"stale_seconds" json:"stale_seconds"` // 心跳超过该时间未更新视为实例已退出（秒，默认 30）
+}
+
+// StandbyConfig 热备配置
+// primary 通过复制通道推送槽位快照和事件；standby 只读订阅行情并镜像主实例状态，
+// 复制通道中断超过 takeover_after 秒且实例锁可获取时接管订单管理（跨主机部署需启用 distributed_lock）
+type StandbyConfig struct {
+	Role             string `yaml:"role" json:"role"`                           // 空（默认，不启用）/ primary / standby
+	Listen           string `yaml:"listen" json:"listen"`                       // primary：复制通道监听地址，默认 :28890
+	PrimaryURL       string `yaml:"primary_url" json:"primary_url"`             // standby：主实例复制通道地址，如 ws://10.0.0.1:28890/replication
+	Token            string `yaml:"token" json:"-"`                             // 复制通道共享密钥（两端一致）
+	SnapshotInterval int    `yaml:"snapshot_interval" json:"snapshot_interval"` // primary：快照推送间隔（秒，默认 2，同时作为心跳）
+	TakeoverAfter    int    `yaml:"takeover_after" json:"takeover_after"`       // standby：复制通道静默多久后尝试接管（秒，默认 10）
+}
+
+// FleetConfig 多实例总览（轻量联邦）
+// 每个进程独立运行（如每个交易所一个进程），成员定期把状态和盈亏推送给 hub，hub 汇总后提供 /api/fleet
+// 实例之间使用共享的只读令牌认证，令牌只能上报和读取总览，不能调用任何交易接口
+type FleetConfig struct {
+	Role           string `yaml:"role" json:"role"`                       // 空（默认，不启用）/ hub / member
+	Name           string `yaml:"name" json:"name"`                       // 在总览中显示的名称，默认 instance.id，未设置时使用主机名
+	HubURL         string `yaml:"hub_url" json:"hub_url"`                 // member：hub 的 Web 地址，如 https://hub.example.com:28888
+	Token          string `yaml:"token" json:"-"`                         // 实例间共享的只读令牌（hub 与成员一致）
+	ReportInterval int    `yaml:"report_interval" json:"report_interval"` // member：上报间隔（秒，默认 30）
+	StaleAfter     int    `yaml:"stale_after" json:"stale_after"`         // hub：超过该时间未上报视为离线（秒，默认 90）
+}
+
+// OrderExpiryConfig 挂单过期配置（模拟 GTD）
+type OrderExpiryConfig struct {
+	Enabled       bool           `yaml:"enabled" json:"enabled"`
+	MaxAgeMinutes int            `yaml:"max_age_minutes" json:"max_age_minutes"` // 未成交挂单的最长存活时间（分钟，默认 60）
+	Strategies    map[string]int `yaml:"strategies" json:"strategies"`           // 按策略类型覆盖最长存活时间（分钟，0 表示该策略不过期）
+	Replace       bool           `yaml:"replace" json:"replace"`                 // 撤单后立即按当前价格重新挂单（否则等下一次价格变动时按窗口规则补单）
+}
+
+// MaxAge 指定策略类型的最长挂单时间，0 表示不过期
+func (c OrderExpiryConfig) MaxAge(strategyType string) time.Duration {
+	if !c.Enabled {
+		return 0
+	}
+	minutes := c.MaxAgeMinutes
+	if override, ok := c.Strategies[strategyType]; ok {
+		minutes = override
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// OrderCleanerConfig 订单清理策略配置
+type OrderCleanerConfig struct {
+	DryRun              bool   `yaml:"dry_run" json:"dry_run"`                               // 只记录将被撤销的订单，不实际撤单
+	OrphanPolicy        string `yaml:"orphan_policy" json:"orphan_policy"`                   // 孤儿挂单（交易所有、本地槽位没有）的处理：ignore（默认）/ bot（只撤本程序生成的订单）/ all（含手动下的订单）
+	OrphanMinAgeMinutes int    `yaml:"orphan_min_age_minutes" json:"orphan_min_age_minutes"` // 孤儿挂单至少存在多久才撤销（分钟，默认 10），避免误撤刚提交、尚未回报的订单
+}
+
+// ReconcileTolerance 对账容差：持仓差异不超过 max(absolute, relative×持仓) 时视为粉尘，只累计不告警、不同步
+type ReconcileTolerance struct {
+	Absolute float64 `yaml:"absolute" json:"absolute"` // 绝对容差（基础币数量）
+	Relative float64 `yaml:"relative" json:"relative"` // 相对容差（占本地与交易所持仓较大者的比例）
+}
+
+// Band 给定持仓规模下的容差
+func (t ReconcileTolerance) Band(position float64) float64 {
+	return math.Max(t.Absolute, t.Relative*math.Abs(position))
+}
+
+// ReconcileToleranceConfig 对账容差配置（可按交易对覆盖）
+type ReconcileToleranceConfig struct {
+	ReconcileTolerance `yaml:",inline"`
+	Symbols            map[string]ReconcileTolerance `yaml:"symbols" json:"symbols"` // 按交易对覆盖，如 BTCUSDT
+}
+
+// For 指定交易对的对账容差
+func (c ReconcileToleranceConfig) For(symbol string) ReconcileTolerance {
+	if t, ok := c.Symbols[strings.ToUpper(symbol)]; ok {
+		return t
+	}
+	return c.ReconcileTolerance
+}
+
+// QueuePositionConfig 挂单排队位置估算配置
+type QueuePositionConfig struct {
+	Enabled          bool    `yaml:"enabled" json:"enabled"`                       // 订阅盘口与逐笔成交，估算挂单的排队位置
+	Requeue          bool    `yaml:"requeue" json:"requeue"`                       // 允许撤单重挂排队过深的订单
+	MinRestSeconds   int     `yaml:"min_rest_seconds" json:"min_rest_seconds"`     // 挂单至少存在多久才考虑重挂（秒，默认 120）
+	MaxAheadRatio    float64 `yaml:"max_ahead_ratio" json:"max_ahead_ratio"`       // 前方排队量占该价位总量的比例超过此值视为排队过深（默认 0.8）
+	MinAheadMultiple float64 `yaml:"min_ahead_multiple" json:"min_ahead_multiple"` // 前方排队量至少为自身数量的倍数才重挂（默认 20）
+	RoundNumberStep  float64 `yaml:"round_number_step" json:"round_number_step"`   // 只重挂整数关口附近的订单（如 100 表示 xx00 价位），0 表示不限制
+	RoundNumberTicks int     `yaml:"round_number_ticks" json:"round_number_ticks"` // 距整数关口多少个最小价位以内视为附近（默认 0，即正好在关口上）
+	ImproveTicks     int     `yaml:"improve_ticks" json:"improve_ticks"`           // 重挂时向盘口内侧改善的最小价位数（默认 1）
+}
+
 // Config 做市商系统配置
 type Config struct {
 	// 应用配置
@@ -40,6 +412,8 @@ type Config struct {
 		CleanupBatchSize      int     `yaml:"cleanup_batch_size"`           // 清理批次大小（默认10）
 		MarginLockDurationSec int     `yaml:"margin_lock_duration_seconds"` // 保证金锁定时间（秒，默认10）
 		PositionSafetyCheck   int     `yaml:"position_safety_check"`        // 持仓安全性检查（默认100，最少能向下持有多少仓）
+		ContractType          string  `yaml:"contract_type"`                // 合约类型：linear（U 本位，默认）/ inverse（币本位 COIN-M）
+		ContractSize          float64 `yaml:"contract_size"`                // 币本位合约每张面值（计价货币，如 BTCUSD 为 100）
 		// 多交易对配置
 		Symbols []SymbolConfig `yaml:"symbols"`
 		// 注意：price_decimals 和 quantity_decimals 已废弃，现在从交易所自动获取
@@ -105,17 +479,84 @@ type Config struct {
 		} `yaml:"smart_position"`
 
 		GridRiskControl GridRiskControl `yaml:"grid_risk_control"`
+
+		// 市场状态分类（根据 ADX 与已实现波动率切换挂单窗口，启用后取代 smart_position 的窗口调整）
+		Regime RegimeConfig `yaml:"regime"`
+
+		// 冷启动网格锚点（默认使用首个推送价格，可能是异常成交价）
+		Anchor AnchorConfig `yaml:"anchor"`
+
+		// 网格重新居中（价格单边远离锚点后撤掉窗口外的闲置买单，锚点移到当前价格附近）
+		Recenter RecenterConfig `yaml:"recenter"`
+
+		// 库存对冲（在另一账户或相关合约上做空，抵消网格累积的多头库存）
+		Hedge HedgeConfig `yaml:"hedge"`
+
+		// 挂单过期：超过最长存活时间且未成交的挂单由订单清理器撤销
+		OrderExpiry OrderExpiryConfig `yaml:"order_expiry"`
+
+		// 订单清理策略（演练模式、孤儿挂单处理）
+		OrderCleaner OrderCleanerConfig `yaml:"order_cleaner"`
+
+		// 挂单排队位置估算（可选：撤单重挂整数关口附近排队过深的订单）
+		QueuePosition QueuePositionConfig `yaml:"queue_position"`
+
+		// 多周期趋势服务（EMA/MACD，启用后趋势检测器与 DCA/趋势跟踪策略共用其结果）
+		TrendService TrendServiceConfig `yaml:"trend_service"`
+
+		// 支撑/阻力位与K线形态检测（可选：支撑位附近加密买单槽位）
+		Levels LevelsConfig `yaml:"levels"`
+
+		// 短时高波动保护（强制 PostOnly 并拉开挂单距离，避免吃单或在急跌中接单）
+		VolatilityGuard VolatilityGuardConfig `yaml:"volatility_guard"`
+
+		// 买盘深度骤降保护（撤单式砸盘时暂停买入并撤销买单，需要交易所支持盘口推送）
+		DepthGuard DepthGuardConfig `yaml:"depth_guard"`
+
+		// 只减仓统一检查（按交易所持仓自动标记/拦截 ReduceOnly 订单）
+		ReduceOnlyGuard ReduceOnlyGuardConfig `yaml:"reduce_only_guard"`
+
+		// 按策略的交易额度（每小时/每天下单金额与订单数上限）
+		TurnoverBudget TurnoverBudgetConfig `yaml:"turnover_budget"`
+
+		// 价格带默认值（交易对未配置 price_band 时继承）
+		PriceBand PriceBandConfig `yaml:"price_band"`
+
+		// 下单前保证金检查（按杠杆和维持保证金档位估算批次所需保证金，不足时裁剪批次）
+		MarginCheck MarginCheckConfig `yaml:"margin_check"`
+
+		// 交易所上调最小名义价值后的处理
+		MinNotionalChange MinNotionalChangeConfig `yaml:"min_notional_change"`
 	} `yaml:"trading"`
 
 	System struct {
-		LogLevel             string `yaml:"log_level"`
-		Timezone             string `yaml:"timezone"`     // 时区，如 "Asia/Shanghai"
-		LogLanguage          string `yaml:"log_language"` // 日志语言，如 "zh-CN" 或 "en-US"
-		CancelOnExit         bool   `yaml:"cancel_on_exit"`
-		ClosePositionsOnExit bool   `yaml:"close_positions_on_exit"` // 退出时是否平仓（默认false）
-		LogRetentionDays     int    `yaml:"log_retention_days"`      // 日志保留天数（默认30天，0表示不清理）
+		LogLevel             string   `yaml:"log_level"`
+		Timezone             string   `yaml:"timezone"`     // 时区，如 "Asia/Shanghai"
+		LogLanguage          string   `yaml:"log_language"` // 日志语言，如 "zh-CN" 或 "en-US"
+		CancelOnExit         bool     `yaml:"cancel_on_exit"`
+		ClosePositionsOnExit bool     `yaml:"close_positions_on_exit"` // 退出时是否平仓（默认false）
+		LogRetentionDays     int      `yaml:"log_retention_days"`      // 日志保留天数（默认30天，0表示不清理）
+		FastCancelSeconds    int      `yaml:"fast_cancel_seconds"`     // 收到 SIGTERM/PreStop 后撤单的时间预算（秒，默认5，需小于容器停止宽限期）
+		PreStopToken         string   `yaml:"prestop_token"`           // PreStop 钩子令牌（为空时只允许通过本地管理 socket 调用）
+		LogProfile           string   `yaml:"log_profile"`             // 日志配置档: normal / quiet（不输出周期性状态和 emoji 日志，状态只写指标）/ verbose（全部 DEBUG），默认normal，可通过 /api/system/logging 运行时切换
+		DebugModules         []string `yaml:"debug_modules"`           // 启动时按 DEBUG 输出的模块（包路径，如 position、exchange/binance、main）
+
+		// 退出时按策略的处理方式（优先于 cancel_on_exit / close_positions_on_exit）
+		Shutdown ShutdownPolicyConfig `yaml:"shutdown"`
+
+		// 协调重启（POST /api/system/restart）
+		Restart RestartConfig `yaml:"restart"`
 	} `yaml:"system"`
 
+	// 混沌测试配置（仅用于测试网/模拟环境验证对账与风控的恢复能力，切勿在实盘开启）
+	Chaos ChaosConfig `yaml:"chaos"`
+
+	// 行情录制与回放配置（用于按原始输入复现问题）
+	MarketData MarketDataConfig `yaml:"market_data"`
+
+	// 模拟执行配置（回放与回测模式下的下单回报延迟、成交延迟和滑点）
+	Simulation SimulationConfig `yaml:"simulation"`
+
 	// 实例配置（多实例部署）
 	Instance struct {
 		ID    string `yaml:"id"`    // 实例唯一标识，默认为空（单实例模式）
@@ -123,6 +564,15 @@ type Config struct {
 		Total int    `yaml:"total"` // 总实例数，默认1
 	} `yaml:"instance"`
 
+	// 重复实例检测（防止两个实例同时操作同一账户的同一交易对）
+	InstanceLock InstanceLockConfig `yaml:"instance_lock"`
+
+	// 热备（主备切换）
+	Standby StandbyConfig `yaml:"standby"`
+
+	// 多实例总览（成员向 hub 上报状态与盈亏，hub 通过 /api/fleet 汇总展示）
+	Fleet FleetConfig `yaml:"fleet"`
+
 	// 数据库配置（支持 SQLite、PostgreSQL、MySQL）
 	Database struct {
 		Type            string `yaml:"type"`              // 数据库类型: sqlite, postgres, mysql，默认 sqlite
@@ -157,6 +607,38 @@ type Config struct {
 		AverageWindow     int      `yaml:"average_window"`     // 移动平均窗口大小，默认20
 		RecoveryThreshold int      `yaml:"recovery_threshold"` // 恢复交易所需的正常币种数量，默认3
 		MaxLeverage       int      `yaml:"max_leverage"`       // 最大允许杠杆倍数，默认10（设置为0表示不限制）
+
+		// 监控币种自动发现：按交易所24小时成交额选出前 top_n 个币种 + 正在交易的币种，定期刷新（需交易所支持全市场行情，目前为币安合约）
+		// 启用后 monitor_symbols 仅在首次发现失败时使用
+		AutoDiscovery struct {
+			Enabled      bool   `yaml:"enabled"`       // 是否启用，默认false
+			TopN         int    `yaml:"top_n"`         // 按成交额选取的币种数量，默认5
+			QuoteAsset   string `yaml:"quote_asset"`   // 计价货币，默认USDT
+			RefreshHours int    `yaml:"refresh_hours"` // 刷新间隔（小时），默认24
+		} `yaml:"auto_discovery"`
+
+		LiquidationWarningRatio  float64 `yaml:"liquidation_warning_ratio"`  // 标记价格距强平价的比例低于该值时发出严重告警，默认0.05（5%）
+		ReconcileDivergenceRatio float64 `yaml:"reconcile_divergence_ratio"` // 对账持仓偏差占持仓比例超过该值时发出严重告警，默认0.1（10%）
+
+		// 对账容差：差异在容差内视为粉尘，超过容差为 warning，超过 reconcile_divergence_ratio 为 critical
+		ReconcileTolerance ReconcileToleranceConfig `yaml:"reconcile_tolerance"`
+
+		// 压力测试（GET /api/risk/stress）：价格冲击情景与波动率放大情景
+		StressTest struct {
+			PriceShocks    []float64 `yaml:"price_shocks"`    // 价格冲击比例，默认 [-0.05, -0.10]
+			VolMultipliers []float64 `yaml:"vol_multipliers"` // 日波动率倍数（按 99% 单日不利波动），默认 [2]
+			VolLookback    int       `yaml:"vol_lookback"`    // 计算日波动率使用的日K数量，默认30
+		} `yaml:"stress_test"`
+
+		// 外部价格源交叉校验：主行情与第二价格源偏离过大时暂停交易，防止错误/停滞的行情打穿网格
+		PriceOracle struct {
+			Enabled       bool              `yaml:"enabled"`
+			Source        string            `yaml:"source"`         // binance / okx / bybit（公开行情接口，无需密钥）
+			MaxDivergence float64           `yaml:"max_divergence"` // 最大允许偏离比例，默认0.01（1%）
+			Interval      int               `yaml:"interval"`       // 校验间隔（秒，默认10）
+			ConfirmCount  int               `yaml:"confirm_count"`  // 连续超限次数达到该值才暂停，默认2
+			SymbolMap     map[string]string `yaml:"symbol_map"`     // 交易对映射（本地交易对 -> 价格源交易对），未配置时自动转换
+		} `yaml:"price_oracle"`
 	} `yaml:"risk_control"`
 
 	// 时间间隔配置（单位：秒，除非特别说明）
@@ -168,15 +650,20 @@ type Config struct {
 		WebSocketPingInterval      int `yaml:"websocket_ping_interval"`       // WebSocket PING间隔（秒，默认20）
 		ListenKeyKeepAliveInterval int `yaml:"listen_key_keepalive_interval"` // listenKey保活间隔（分钟，默认30）
 
+		// 行情 WebSocket 协商 permessage-deflate 压缩（交易所不支持时自动回退），交易对较多时可显著降低带宽
+		WebSocketCompression bool `yaml:"websocket_compression"`
+
 		// 价格监控相关
-		PriceSendInterval int `yaml:"price_send_interval"` // 定期发送价格的间隔（毫秒，默认50）
+		PriceSendInterval     int `yaml:"price_send_interval"`      // 定期发送价格的间隔（毫秒，默认50），间隔内的价格只保留最新一个
+		PriceConflateMinTicks int `yaml:"price_conflate_min_ticks"` // 价格相对上次发送变动达到该 tick 数时立即发送，不等待间隔（0 表示关闭）
 
 		// 订单执行相关
-		RateLimitRetryDelay  int `yaml:"rate_limit_retry_delay"` // 速率限制重试等待时间（秒，默认1）
-		OrderRetryDelay      int `yaml:"order_retry_delay"`      // 其他错误重试等待时间（毫秒，默认500）
-		PricePollInterval    int `yaml:"price_poll_interval"`    // 等待获取价格的轮询间隔（毫秒，默认500）
-		StatusPrintInterval  int `yaml:"status_print_interval"`  // 定期打印状态的间隔（分钟，默认1）
-		OrderCleanupInterval int `yaml:"order_cleanup_interval"` // 订单清理检查间隔（秒，默认60）
+		RateLimitRetryDelay   int `yaml:"rate_limit_retry_delay"`   // 速率限制重试等待时间（秒，默认1）
+		OrderRetryDelay       int `yaml:"order_retry_delay"`        // 其他错误重试等待时间（毫秒，默认500）
+		PricePollInterval     int `yaml:"price_poll_interval"`      // 等待获取价格的轮询间隔（毫秒，默认500）
+		StatusPrintInterval   int `yaml:"status_print_interval"`    // 定期打印状态的间隔（分钟，默认1）
+		StatusPrintIntervalMs int `yaml:"status_print_interval_ms"` // 定期状态的间隔（毫秒，最小100），大于0时优先于 status_print_interval，可低于1秒（建议配合 quiet 配置档只写指标）
+		OrderCleanupInterval  int `yaml:"order_cleanup_interval"`   // 订单清理检查间隔（秒，默认60）
 	} `yaml:"timing"`
 
 	// 通知配置
@@ -248,6 +735,57 @@ type Config struct {
 			Webhook string `yaml:"webhook"` // Slack Incoming Webhook URL
 		} `yaml:"slack"`
 
+		// ntfy 推送配置（ntfy.sh 或自建服务器）
+		Ntfy struct {
+			Enabled  bool              `yaml:"enabled"`
+			Server   string            `yaml:"server"`   // 服务器地址，默认 https://ntfy.sh
+			Topic    string            `yaml:"topic"`    // 默认主题
+			Token    string            `yaml:"token"`    // 访问令牌（可选）
+			Username string            `yaml:"username"` // 基本认证用户名（可选，与 token 二选一）
+			Password string            `yaml:"password"` // 基本认证密码（可选）
+			Topics   map[string]string `yaml:"topics"`   // 按事件类型或级别（critical/warning/info）映射主题，未匹配时使用默认主题
+		} `yaml:"ntfy"`
+
+		// Gotify 推送配置
+		Gotify struct {
+			Enabled bool              `yaml:"enabled"`
+			URL     string            `yaml:"url"`    // Gotify 服务器地址
+			Token   string            `yaml:"token"`  // 默认应用令牌
+			Tokens  map[string]string `yaml:"tokens"` // 按事件类型或级别映射应用令牌，未匹配时使用默认令牌
+		} `yaml:"gotify"`
+
+		// 严重告警升级通道（电话/短信/PagerDuty），仅用于强平风险等严重事件，独立限流
+		Escalation struct {
+			Enabled     bool     `yaml:"enabled"`
+			Provider    string   `yaml:"provider"`     // twilio / pagerduty
+			Events      []string `yaml:"events"`       // 升级的事件类型，默认 liquidation_risk, reconciliation_divergence, watchdog_failure
+			MinInterval int      `yaml:"min_interval"` // 同一事件（交易所+交易对+类型）两次升级的最小间隔（秒，默认900）
+			MaxPerHour  int      `yaml:"max_per_hour"` // 每小时最多升级次数（默认4）
+
+			// 生效时段（为空表示全天生效），使用配置的时区
+			Schedule struct {
+				Start    string   `yaml:"start"`    // 开始时间，如 "22:00"
+				End      string   `yaml:"end"`      // 结束时间，如 "08:00"（可跨午夜）
+				Weekdays []string `yaml:"weekdays"` // 生效的星期，如 ["sat", "sun"]，为空表示每天
+			} `yaml:"schedule"`
+
+			Twilio struct {
+				AccountSID string `yaml:"account_sid"`
+				AuthToken  string `yaml:"auth_token"`
+				From       string `yaml:"from"` // Twilio 号码
+				To         string `yaml:"to"`   // 接收号码
+				Mode       string `yaml:"mode"` // call（语音电话）/ sms，默认 call
+			} `yaml:"twilio"`
+
+			PagerDuty struct {
+				RoutingKey string `yaml:"routing_key"` // Events API v2 集成密钥
+			} `yaml:"pagerduty"`
+		} `yaml:"escalation"`
+
+		// 自定义消息模板：键为事件类型、事件级别（critical/warning/info）或 default，
+		// "渠道.键"（如 telegram.order_filled、email.default）只对该渠道生效；未匹配的事件使用各渠道内置格式
+		Templates map[string]NotificationTemplateConfig `yaml:"templates"`
+
 		// 通知规则：哪些事件需要通知
 		Rules struct {
 			OrderPlaced        bool `yaml:"order_placed"`
@@ -263,7 +801,7 @@ type Config struct {
 	// 存储配置
 	Storage struct {
 		Enabled       bool   `yaml:"enabled"`
-		Type          string `yaml:"type"`           // sqlite
+		Type          string `yaml:"type"`           // sqlite / memory（内存存储，退出后数据丢失）
 		Path          string `yaml:"path"`           // 数据库文件路径
 		BufferSize    int    `yaml:"buffer_size"`    // 缓冲区大小（默认1000）
 		BatchSize     int    `yaml:"batch_size"`     // 批量写入大小（默认100）
@@ -276,6 +814,31 @@ type Config struct {
 		Host    string `yaml:"host"`    // 监听地址（默认 0.0.0.0）
 		Port    int    `yaml:"port"`    // 监听端口（默认 8080）
 		APIKey  string `yaml:"api_key"` // API 密钥（可选，用于认证）
+
+		// 反向代理部署（如 nginx 把 /quantmesh/ 转发到本服务）
+		BasePath        string   `yaml:"base_path"`         // 对外访问的子路径，如 /quantmesh，默认为空（根路径）
+		TrustedProxies  []string `yaml:"trusted_proxies"`   // 可信代理 IP/CIDR，仅信任来自这些地址的 X-Forwarded-For，默认仅本机
+		RemoteIPHeaders []string `yaml:"remote_ip_headers"` // 携带客户端真实 IP 的请求头，默认 X-Forwarded-For、X-Real-IP
+
+		// 高开销接口的限流与响应缓存，键为接口路径（如 /api/klines）
+		// 配置了某个接口时整体替换该接口的默认值，rate_per_minute 和 cache_ttl 都为 0 表示不限制
+		EndpointLimits map[string]EndpointLimitConfig `yaml:"endpoint_limits"`
+
+		// WebAuthn 依赖方配置（通过域名/反向代理访问时必须与浏览器地址栏一致）
+		WebAuthn struct {
+			RPID     string `yaml:"rp_id"`     // 依赖方 ID（域名，如 dash.example.com），默认 localhost
+			RPOrigin string `yaml:"rp_origin"` // 依赖方来源（如 https://dash.example.com），默认根据监听地址生成
+		} `yaml:"webauthn"`
+
+		// CORS 跨域配置（默认仅允许同源访问）
+		CORS struct {
+			AllowedOrigins []string `yaml:"allowed_origins"` // 允许跨域访问的来源，如 https://dash.example.com，为空时仅允许同源
+		} `yaml:"cors"`
+
+		// CSRF 防护配置（默认开启，修改状态的请求需携带 X-CSRF-Token 请求头）
+		CSRF struct {
+			Disabled bool `yaml:"disabled"` // 关闭 CSRF 校验（仅用于调试），默认 false
+		} `yaml:"csrf"`
 		
 		// pprof 性能分析配置
 		Pprof struct {
@@ -283,8 +846,35 @@ type Config struct {
 			RequireAuth bool     `yaml:"require_auth"` // 是否需要认证，默认 true
 			AllowedIPs  []string `yaml:"allowed_ips"` // IP 白名单（可选，为空则允许所有 IP）
 		} `yaml:"pprof"`
+
+		// 公开状态页配置（无需登录，可分享或嵌入，不包含余额和密钥等敏感信息）
+		PublicStatus struct {
+			Enabled    bool     `yaml:"enabled"`     // 是否启用公开状态页，默认 false
+			Title      string   `yaml:"title"`       // 页面标题，默认 "QuantMesh Status"
+			Metrics    []string `yaml:"metrics"`     // 公开的指标：equity_curve, uptime, last_trade_time, running, total_pnl, win_rate, trade_count，默认前三项
+			EquityDays int      `yaml:"equity_days"` // 收益曲线天数，默认 30
+			RateLimit  int      `yaml:"rate_limit"`  // 每个 IP 每分钟请求上限，默认 30
+		} `yaml:"public_status"`
 	} `yaml:"web"`
 
+	// 无 Web 服务运行（headless）：Web 服务完全关闭，不监听任何端口，
+	// 通过 CLI（status / pause / resume / cancel / stats）控制运行中的实例，通知照常发送
+	Headless struct {
+		Enabled      bool   `yaml:"enabled"`       // 是否启用，启用后忽略 web.enabled，默认 false
+		ControlDir   string `yaml:"control_dir"`   // 控制目录（CLI 写入命令、实例写回结果），默认 ./data/control
+		PollInterval int    `yaml:"poll_interval"` // 实例检查新命令的间隔（毫秒），默认 500
+	} `yaml:"headless"`
+
+	// 本地管理 socket（Unix domain socket）：提供与 Web 服务相同的 API，只能在本机访问，
+	// 不依赖 web.enabled，HTTP 端口被防火墙屏蔽或 headless 模式下脚本和 CLI 仍可驱动实例
+	AdminSocket struct {
+		Enabled bool   `yaml:"enabled"` // 是否启用，默认 false
+		Path    string `yaml:"path"`    // socket 文件路径（权限 0600，能连接即视为管理员），默认 ./data/admin.sock
+	} `yaml:"admin_socket"`
+
+	// 远程日志/指标转发（Loki 或通用 HTTPS 端点，TLS 加密，故障期间缓存到磁盘）
+	LogShipping LogShippingConfig `yaml:"log_shipping"`
+
 	// 插件配置
 	Plugins struct {
 		Enabled   bool                              `yaml:"enabled"`   // 是否启用插件系统，默认false
@@ -293,6 +883,12 @@ type Config struct {
 		Config    map[string]map[string]interface{} `yaml:"config"`    // 插件配置
 	} `yaml:"plugins"`
 
+	// 资金费率监控配置（可通过 /api/funding/symbols 运行时修改监控列表）
+	FundingMonitor struct {
+		IntervalHours int      `yaml:"interval_hours"`    // 采集间隔（小时），默认8
+		Symbols       []string `yaml:"symbols,omitempty"` // 监控的交易对，为空时使用默认主流交易对；正在交易的交易对始终自动纳入
+	} `yaml:"funding_monitor"`
+
 	// 价差监控配置
 	BasisMonitor struct {
 		Enabled         bool     `yaml:"enabled"`          // 是否启用价差监控，默认false
@@ -300,6 +896,129 @@ type Config struct {
 		Symbols         []string `yaml:"symbols"`          // 监控的交易对列表
 	} `yaml:"basis_monitor"`
 
+	// 持仓量与大户多空比监控（需交易所支持持仓量接口，目前为币安合约）
+	OpenInterestMonitor struct {
+		Enabled         bool     `yaml:"enabled"`          // 是否启用，默认false
+		IntervalMinutes int      `yaml:"interval_minutes"` // 采集间隔（分钟），默认5
+		Symbols         []string `yaml:"symbols"`          // 监控的交易对，为空时使用正在交易的交易对
+
+		// 持仓量突增风控：窗口内持仓量变化超过阈值时触发
+		SpikeRule struct {
+			Enabled         bool    `yaml:"enabled"`          // 是否启用，默认false
+			ChangePercent   float64 `yaml:"change_percent"`   // 持仓量变化百分比阈值（绝对值），默认20
+			WindowMinutes   int     `yaml:"window_minutes"`   // 比较窗口（分钟），默认60
+			Action          string  `yaml:"action"`           // 触发动作：notify（仅告警）/ shrink_window（缩小买单窗口）/ pause（暂停交易），默认notify
+			BuyWindowSize   int     `yaml:"buy_window_size"`  // shrink_window 时的买单窗口大小，默认2
+			CooldownMinutes int     `yaml:"cooldown_minutes"` // 触发后的冷却时间（分钟），到期自动恢复，默认60
+		} `yaml:"spike_rule"`
+	} `yaml:"open_interest_monitor"`
+
+	// 自定义风控档位（与内置 conservative/balanced/aggressive 同名时覆盖内置档位）
+	RiskProfiles map[string]RiskProfile `yaml:"risk_profiles"`
+
+	// 成交异常检测（检查自身成交中的异常模式，发现烧手续费的配置错误）
+	FillAnomaly struct {
+		Enabled           bool `yaml:"enabled"`             // 是否启用，默认false
+		IntervalMinutes   int  `yaml:"interval_minutes"`    // 扫描间隔（分钟），默认5
+		LoopWindowSeconds int  `yaml:"loop_window_seconds"` // 同一槽位重复成交的时间窗口（秒），默认60
+		LoopCount         int  `yaml:"loop_count"`          // 时间窗口内同一槽位成交达到该次数视为循环，默认3
+	} `yaml:"fill_anomaly"`
+
+	// 交易所维护窗口感知（维护期间暂停下单，并静默预期内的断线告警）
+	Maintenance struct {
+		Enabled          bool                      `yaml:"enabled"`            // 是否启用，默认false
+		CheckInterval    int                       `yaml:"check_interval"`     // 状态检查间隔（秒），默认60
+		PreWindowMinutes int                       `yaml:"pre_window_minutes"` // 计划维护开始前提前暂停的分钟数，默认1
+		Windows          []MaintenanceWindowConfig `yaml:"windows"`            // 手动配置的维护窗口（交易所未提供维护公告接口时使用）
+	} `yaml:"maintenance"`
+
+	// 利润金库：周期内已实现盈亏超过阈值时，将超出部分从合约账户划转到现货/资金账户（需 API 密钥开启划转权限）
+	ProfitVault struct {
+		Enabled       bool    `yaml:"enabled"`        // 是否启用，默认false
+		Period        string  `yaml:"period"`         // 统计周期：daily / weekly / monthly，默认daily（UTC）
+		Threshold     float64 `yaml:"threshold"`      // 周期内已实现盈亏超过该金额的部分才划转，默认0
+		SweepRatio    float64 `yaml:"sweep_ratio"`    // 超出部分的划转比例（0-1），默认1
+		MinTransfer   float64 `yaml:"min_transfer"`   // 单次最小划转金额，默认10
+		Asset         string  `yaml:"asset"`          // 划转资产，默认USDT
+		ToWallet      string  `yaml:"to_wallet"`      // 目标账户：SPOT / FUNDING，默认SPOT
+		CheckInterval int     `yaml:"check_interval"` // 检查间隔（秒），默认3600
+	} `yaml:"profit_vault"`
+
+	// 交易日志：重要事件自动记录，并可通过 /api/journal 添加手动笔记（需启用存储）
+	Journal struct {
+		Enabled        bool     `yaml:"enabled"`         // 是否启用，默认false
+		AutoEvents     []string `yaml:"auto_events"`     // 自动记录的事件类型，默认风控触发、止损止盈、强平风险等
+		DrawdownAmount float64  `yaml:"drawdown_amount"` // 累计已实现盈亏自高点回撤超过该金额时记录，0 表示不记录
+		CheckInterval  int      `yaml:"check_interval"`  // 回撤检查间隔（秒），默认300
+	} `yaml:"journal"`
+
+	// 盈利目标：按 UTC 日/周统计已实现盈亏（含资金费），达成目标或当日亏损达到上限时通知，并可暂停当日新开仓（需启用存储）
+	Goals struct {
+		Enabled       bool    `yaml:"enabled"`        // 是否启用，默认false
+		DailyTarget   float64 `yaml:"daily_target"`   // 日盈利目标，0 表示不设置
+		WeeklyTarget  float64 `yaml:"weekly_target"`  // 周盈利目标（周一起算），0 表示不设置
+		MaxDailyLoss  float64 `yaml:"max_daily_loss"` // 当日最大亏损（填正数），0 表示不设置
+		OnTarget      string  `yaml:"on_target"`      // 达成盈利目标后的动作：notify / stop_buying（当日剩余时间不再开新仓），默认notify
+		OnMaxLoss     string  `yaml:"on_max_loss"`    // 达到亏损上限后的动作：notify / stop_buying，默认notify
+		CheckInterval int     `yaml:"check_interval"` // 检查间隔（秒），默认60
+	} `yaml:"goals"`
+
+	// 每日收盘快照：每天定时汇总前一自然日（配置时区）的统计、持仓与槽位状态，写入不可覆盖的 JSON 文件，可选发送通知
+	DailySnapshot struct {
+		Enabled       bool   `yaml:"enabled"`        // 是否启用，默认false
+		Time          string `yaml:"time"`           // 生成时间（配置时区 HH:MM），统计前一自然日，默认00:05
+		Dir           string `yaml:"dir"`            // 快照文件目录，默认./data/snapshots
+		Notify        bool   `yaml:"notify"`         // 生成后发送通知（当日摘要和文件路径），默认false
+		RetentionDays int    `yaml:"retention_days"` // 快照文件保留天数，0 表示永久保留
+	} `yaml:"daily_snapshot"`
+
+	// HODL 基准对比：定期采样机器人权益（分配资金 + 起算以来的已实现盈亏 + 未实现盈亏），与起算时用同样资金买入并持有的价值比较
+	// 结果见 GET /api/statistics/benchmark，启用每日快照时一并写入快照
+	Benchmark struct {
+		Enabled        bool    `yaml:"enabled"`         // 是否启用，默认false
+		InitialCapital float64 `yaml:"initial_capital"` // 交易对未配置 total_allocated_capital 时的初始资金，0 表示按 order_quantity × buy_window_size
+		SampleInterval int     `yaml:"sample_interval"` // 采样间隔（秒），默认300
+		MaxSamples     int     `yaml:"max_samples"`     // 每个交易对保留的采样数（用于计算波动率），默认2016（5分钟间隔约7天）
+		StateFile      string  `yaml:"state_file"`      // 起算点（时间、价格、资金）保存文件，重启后沿用；删除后重新起算，默认./data/benchmark.json
+	} `yaml:"benchmark"`
+
+	// 定时任务调度（按时段触发的任务共用，如低流动性时段降杠杆）
+	Scheduler struct {
+		CheckInterval int `yaml:"check_interval"` // 时段检查间隔（秒），默认30
+	} `yaml:"scheduler"`
+
+	// 低流动性时段（周末、代币化股票休市日等）前自动降杠杆：收紧挂单窗口、限制持仓层数，时段结束后恢复
+	Deleverage struct {
+		Enabled        bool                   `yaml:"enabled"`          // 是否启用，默认false
+		Timezone       string                 `yaml:"timezone"`         // 按周循环时段使用的时区，默认UTC
+		LeadMinutes    int                    `yaml:"lead_minutes"`     // 提前进入降杠杆的分钟数，默认60
+		Windows        []ScheduleWindowConfig `yaml:"windows"`          // 低流动性时段
+		Symbols        []string               `yaml:"symbols"`          // 生效的交易对，为空表示全部
+		BuyWindowSize  int                    `yaml:"buy_window_size"`  // 期间买单窗口上限，0 表示不限制
+		SellWindowSize int                    `yaml:"sell_window_size"` // 期间卖单窗口上限，0 表示不限制
+		MaxGridLayers  int                    `yaml:"max_grid_layers"`  // 期间最大持仓层数（达到后不再买入），0 表示不限制
+		StopBuying     bool                   `yaml:"stop_buying"`      // 期间完全暂停新开仓并撤销买单
+	} `yaml:"deleverage"`
+
+	// 账户流水同步与盈亏对账：定期拉取交易所已实现盈亏、手续费、资金费流水入库，并与本地计算的盈亏比对（需启用存储）
+	IncomeSync struct {
+		Enabled          bool    `yaml:"enabled"`           // 是否启用，默认false
+		IntervalMinutes  int     `yaml:"interval_minutes"`  // 同步间隔（分钟），默认60
+		LookbackDays     int     `yaml:"lookback_days"`     // 首次同步回溯天数，默认30
+		ReportHours      int     `yaml:"report_hours"`      // 每次同步后对账的统计区间（小时），默认24
+		ToleranceAbs     float64 `yaml:"tolerance_abs"`     // 允许的绝对偏差（计价资产），默认1
+		TolerancePercent float64 `yaml:"tolerance_percent"` // 允许的相对偏差（百分比，相对交易所已实现盈亏），默认5
+	} `yaml:"income_sync"`
+
+	// K 线缺口补齐：本地保存 K 线，启动时检测回溯区间内的缺口（如停机期间）并从交易所补齐，之后定期增量同步（需启用存储）
+	KlineBackfill struct {
+		Enabled       bool     `yaml:"enabled"`        // 是否启用，默认false
+		Intervals     []string `yaml:"intervals"`      // 保存的 K 线周期，默认 ["1m", "1h"]
+		LookbackHours int      `yaml:"lookback_hours"` // 启动时检测缺口的回溯小时数，默认24，最多720
+		PollMinutes   int      `yaml:"poll_minutes"`   // 定期检测并补齐的间隔（分钟），默认5
+		MaxFetch      int      `yaml:"max_fetch"`      // 单次从交易所拉取的最大 K 线根数，默认1000
+	} `yaml:"kline_backfill"`
+
 	// 事件中心配置
 	EventCenter struct {
 		Enabled                  bool     `yaml:"enabled"`                     // 是否启用事件中心，默认true
@@ -326,7 +1045,7 @@ type Config struct {
 
 		// 资金分配配置
 		CapitalAllocation struct {
-			Mode         string  `yaml:"mode"`          // fixed/dynamic/both
+			Mode         string  `yaml:"mode"`          // fixed/dynamic/both/risk_parity/kelly
 			TotalCapital float64 `yaml:"total_capital"` // 总资金（USDT）
 
 			// 固定分配
@@ -345,9 +1064,30 @@ type Config struct {
 
 				// 评估指标权重
 				PerformanceWeights map[string]float64 `yaml:"performance_weights"`
+
+				// risk_parity/kelly 模式：每次再平衡记录一次各策略的区间收益率（区间盈亏 / 分配资金）
+				ReturnLookback   int `yaml:"return_lookback"`    // 参与计算的最近区间数（默认30）
+				MinReturnSamples int `yaml:"min_return_samples"` // 样本不足时保持当前权重（默认5）
 			} `yaml:"dynamic"`
+
+			// kelly 模式：目标权重 = fraction × 区间收益均值 / 区间收益方差，并限制在 max_fraction 以内
+			Kelly struct {
+				Fraction    float64 `yaml:"fraction"`     // 分数凯利系数（默认0.5）
+				MaxFraction float64 `yaml:"max_fraction"` // 单策略凯利比例上限（默认0.25）
+			} `yaml:"kelly"`
+
+			// 组合优化：记录各策略每日收益，比较当前分配/等权/波动率倒数加权方案，供再平衡建议（mode=optimal）使用，需启用存储
+			Optimizer struct {
+				Enabled       bool `yaml:"enabled"`
+				CheckInterval int  `yaml:"check_interval"` // 记录与分析间隔（秒，默认3600）
+				LookbackDays  int  `yaml:"lookback_days"`  // 回看天数（默认30）
+				MinDays       int  `yaml:"min_days"`       // 有收益记录的天数少于该值时不给出建议（默认7）
+			} `yaml:"optimizer"`
 		} `yaml:"capital_allocation"`
 
+		// 单策略连续亏损熔断
+		LossBreaker StrategyLossBreakerConfig `yaml:"loss_breaker"`
+
 		// 策略配置
 		Configs map[string]StrategyConfig `yaml:"configs"`
 	} `yaml:"strategies"`
@@ -464,7 +1204,48 @@ type Config struct {
 						Subreddits []string `yaml:"subreddits"` // Reddit子版块列表
 						PostLimit  int      `yaml:"post_limit"` // 每个子版块获取的帖子数量
 					} `yaml:"social_media"`
+
+					Twitter struct {
+						Enabled       bool   `yaml:"enabled"`
+						BearerToken   string `yaml:"bearer_token"`   // X API v2 Bearer Token
+						APIURL        string `yaml:"api_url"`        // 默认 https://api.twitter.com/2
+						MaxResults    int    `yaml:"max_results"`    // 每个交易对每次搜索的推文数（10~100，默认 50）
+						FetchInterval int    `yaml:"fetch_interval"` // 秒，默认 900（X API 免费额度很低）
+					} `yaml:"twitter"`
+
+					Telegram struct {
+						Enabled       bool     `yaml:"enabled"`
+						Channels      []string `yaml:"channels"`       // 公开频道用户名（不含 @）
+						PostLimit     int      `yaml:"post_limit"`     // 每个频道获取的消息数量（默认 20）
+						FetchInterval int      `yaml:"fetch_interval"` // 秒，默认 300
+					} `yaml:"telegram"`
+
+					OnChain struct {
+						Enabled       bool              `yaml:"enabled"`
+						Provider      string            `yaml:"provider"`       // glassnode, dune
+						APIKey        string            `yaml:"api_key"`
+						APIURL        string            `yaml:"api_url"`        // 为空使用服务商默认地址
+						Assets        []string          `yaml:"assets"`         // 关注的币种，默认取交易对的基础币种
+						FetchInterval int               `yaml:"fetch_interval"` // 秒，默认 3600（链上数据多为日线）
+						Metrics       map[string]string `yaml:"metrics"`        // Glassnode 指标路径覆盖：exchange_netflow、large_transfers、stablecoin_supply
+						DuneQueries   struct {
+							Netflow          int `yaml:"netflow"`           // 列：asset, netflow
+							LargeTransfers   int `yaml:"large_transfers"`   // 列：asset, count
+							StablecoinSupply int `yaml:"stablecoin_supply"` // 列：supply, supply_change
+						} `yaml:"dune_queries"`
+					} `yaml:"on_chain"`
+
+					// 情绪打分前的去重与相关度过滤
+					Filter struct {
+						DedupThreshold float64 `yaml:"dedup_threshold"` // 近似重复阈值（MinHash 估计的 Jaccard 相似度，默认 0.8，>1 关闭去重）
+						MinRelevance   float64 `yaml:"min_relevance"`   // 最低相关度（标题命中关键词为 1，仅正文提到一次约 0.33，默认 0.3）
+					} `yaml:"filter"`
 				} `yaml:"data_sources"`
+
+				// 每个交易对的关键词（如 BTCUSDT: [bitcoin, btc]），未配置时根据币种自动生成
+				SymbolKeywords map[string][]string `yaml:"symbol_keywords"`
+				// 各数据源权重（news、reddit、twitter、telegram、fear_greed），未配置的数据源使用默认权重
+				SourceWeights map[string]float64 `yaml:"source_weights"`
 			} `yaml:"sentiment_analysis"`
 
 			StrategyGeneration struct {
@@ -491,6 +1272,16 @@ type Config struct {
 			} `yaml:"polymarket_signal"`
 		} `yaml:"modules"`
 
+		// 各模块调度（执行周期/cron、启停、并发），可通过 /api/ai/modules 热更新；
+		// 未配置的模块沿用 modules 中的 enabled 与间隔设置
+		Scheduling map[string]AIModuleSchedule `yaml:"scheduling"`
+
+		// 审核模式：AI 生成的配置先保存为待审批提案（/api/ai/proposals），人工批准后才写入 config.yaml
+		ConfigReview bool `yaml:"config_review"`
+
+		// 护栏：AI 输出任何情况下都不能超过的硬性上限，校验失败的输出被拒绝并重新提示
+		Guardrails AIGuardrails `yaml:"guardrails"`
+
 		// 决策模式
 		DecisionMode string `yaml:"decision_mode"` // advisor, executor, hybrid
 
@@ -503,6 +1294,140 @@ type Config struct {
 	} `yaml:"ai"`
 }
 
+// AI 模块名称（ai.scheduling 的键）
+const (
+	AIModuleMarketAnalysis        = "market_analysis"
+	AIModuleParameterOptimization = "parameter_optimization"
+	AIModuleRiskAnalysis          = "risk_analysis"
+	AIModuleSentimentAnalysis     = "sentiment_analysis"
+	AIModulePolymarketSignal      = "polymarket_signal"
+)
+
+// AIModules 支持调度的 AI 模块
+var AIModules = []string{
+	AIModuleMarketAnalysis,
+	AIModuleParameterOptimization,
+	AIModuleRiskAnalysis,
+	AIModuleSentimentAnalysis,
+	AIModulePolymarketSignal,
+}
+
+// IsAIModule 是否为支持调度的 AI 模块
+func IsAIModule(module string) bool {
+	for _, m := range AIModules {
+		if m == module {
+			return true
+		}
+	}
+	return false
+}
+
+// AIModuleSchedule AI 模块调度设置
+type AIModuleSchedule struct {
+	Enabled        bool   `yaml:"enabled" json:"enabled"`
+	Interval       int    `yaml:"interval" json:"interval"`               // 执行间隔（秒），配置 cron 时忽略
+	Cron           string `yaml:"cron" json:"cron"`                       // 5 段 cron 表达式（分 时 日 月 周，按系统时区），优先于 interval
+	MaxConcurrency int    `yaml:"max_concurrency" json:"max_concurrency"` // 同时运行的最大次数（默认 1），达到上限时跳过本次触发
+}
+
+// Validate 校验并补全调度设置
+func (s *AIModuleSchedule) Validate() error {
+	if s.Interval < 0 {
+		return fmt.Errorf("interval 不能为负数")
+	}
+	if s.Cron != "" {
+		if _, err := utils.ParseCron(s.Cron); err != nil {
+			return err
+		}
+	} else if s.Enabled && s.Interval == 0 {
+		return fmt.Errorf("启用时必须配置 interval 或 cron")
+	}
+	if s.MaxConcurrency < 0 {
+		return fmt.Errorf("max_concurrency 不能为负数")
+	}
+	if s.MaxConcurrency == 0 {
+		s.MaxConcurrency = 1
+	}
+	return nil
+}
+
+// ShutdownPolicy 策略退出时的处理方式（cancel_all / cancel_buys / flatten / leave）
+func (c *Config) ShutdownPolicy(strategy string) string {
+	if policy, ok := c.System.Shutdown.Strategies[strategy]; ok {
+		return policy
+	}
+	if c.System.Shutdown.Default != "" {
+		return c.System.Shutdown.Default
+	}
+	switch {
+	case c.System.ClosePositionsOnExit:
+		return ShutdownPolicyFlatten
+	case c.System.CancelOnExit:
+		return ShutdownPolicyCancelAll
+	}
+	return ShutdownPolicyLeave
+}
+
+// StatusPrintPeriod 定期状态（持仓打印/状态指标）的间隔：status_print_interval_ms 优先，否则按分钟
+func (c *Config) StatusPrintPeriod() time.Duration {
+	if c.Timing.StatusPrintIntervalMs > 0 {
+		return time.Duration(c.Timing.StatusPrintIntervalMs) * time.Millisecond
+	}
+	return time.Duration(c.Timing.StatusPrintInterval) * time.Minute
+}
+
+// AIModuleSchedule 返回模块的调度设置：优先使用 ai.scheduling，未配置时按 modules 中的开关与间隔生成
+func (c *Config) AIModuleSchedule(module string) (AIModuleSchedule, bool) {
+	if schedule, ok := c.AI.Scheduling[module]; ok {
+		return schedule, true
+	}
+	m := &c.AI.Modules
+	schedule := AIModuleSchedule{MaxConcurrency: 1}
+	switch module {
+	case AIModuleMarketAnalysis:
+		schedule.Enabled, schedule.Interval = m.MarketAnalysis.Enabled, m.MarketAnalysis.UpdateInterval
+	case AIModuleParameterOptimization:
+		schedule.Enabled, schedule.Interval = m.ParameterOptimization.Enabled, m.ParameterOptimization.OptimizationInterval
+	case AIModuleRiskAnalysis:
+		schedule.Enabled, schedule.Interval = m.RiskAnalysis.Enabled, m.RiskAnalysis.AnalysisInterval
+	case AIModuleSentimentAnalysis:
+		schedule.Enabled, schedule.Interval = m.SentimentAnalysis.Enabled, m.SentimentAnalysis.AnalysisInterval
+		if schedule.Interval <= 0 {
+			schedule.Interval = 600
+		}
+	case AIModulePolymarketSignal:
+		schedule.Enabled, schedule.Interval = m.PolymarketSignal.Enabled, m.PolymarketSignal.AnalysisInterval
+	default:
+		return AIModuleSchedule{}, false
+	}
+	return schedule, true
+}
+
+// AIGuardrails AI 输出护栏
+type AIGuardrails struct {
+	MaxLeverage   int     `yaml:"max_leverage" json:"max_leverage"`       // 最大杠杆（风控档位与策略参数），0 表示使用 risk_control.max_leverage
+	MaxOrderSize  float64 `yaml:"max_order_size" json:"max_order_size"`   // 单笔订单金额上限（USDT），0 表示只限制不超过该币种分配的资金
+	MaxWindowSize int     `yaml:"max_window_size" json:"max_window_size"` // 买/卖单窗口上限，默认 100
+	MaxRetries    int     `yaml:"max_retries" json:"max_retries"`         // 输出校验失败后重新提示的次数，默认 2，-1 表示不重试
+}
+
+// ResolvedAIGuardrails 返回补齐默认值后的 AI 护栏
+func (c *Config) ResolvedAIGuardrails() AIGuardrails {
+	g := c.AI.Guardrails
+	if g.MaxLeverage <= 0 {
+		g.MaxLeverage = c.RiskControl.MaxLeverage
+	}
+	if g.MaxWindowSize <= 0 {
+		g.MaxWindowSize = 100
+	}
+	if g.MaxRetries == 0 {
+		g.MaxRetries = 2
+	} else if g.MaxRetries < 0 {
+		g.MaxRetries = 0
+	}
+	return g
+}
+
 // WithdrawalPolicy 提现策略（利润保护）
 type WithdrawalPolicy struct {
 	Enabled   bool    `yaml:"enabled" json:"enabled"`
@@ -564,6 +1489,98 @@ type StrategyInstance struct {
 	Config map[string]interface{} `yaml:"config" json:"config"` // 策略专属配置
 }
 
+// MaintenanceWindowConfig 手动配置的交易所维护窗口
+type MaintenanceWindowConfig struct {
+	Exchange string `yaml:"exchange" json:"exchange"` // 交易所名称
+	Title    string `yaml:"title" json:"title"`       // 说明（可选）
+	Start    string `yaml:"start" json:"start"`       // 开始时间（RFC3339）
+	End      string `yaml:"end" json:"end"`           // 结束时间（RFC3339）
+}
+
+// Parse 解析维护窗口起止时间
+func (w MaintenanceWindowConfig) Parse() (time.Time, time.Time, error) {
+	start, err := time.Parse(time.RFC3339, w.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := time.Parse(time.RFC3339, w.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+// ScheduleWindowConfig 计划时段：按周循环（start_day/start_time 至 end_day/end_time，可跨周），
+// 或固定起止时间（start/end，RFC3339，用于节假日等一次性时段）
+type ScheduleWindowConfig struct {
+	Name      string `yaml:"name" json:"name"`             // 说明（可选）
+	StartDay  string `yaml:"start_day" json:"start_day"`   // 周循环开始日：mon/tue/wed/thu/fri/sat/sun
+	StartTime string `yaml:"start_time" json:"start_time"` // 周循环开始时间（HH:MM）
+	EndDay    string `yaml:"end_day" json:"end_day"`       // 周循环结束日
+	EndTime   string `yaml:"end_time" json:"end_time"`     // 周循环结束时间（HH:MM）
+	Start     string `yaml:"start" json:"start"`           // 固定时段开始时间（RFC3339）
+	End       string `yaml:"end" json:"end"`               // 固定时段结束时间（RFC3339）
+}
+
+// IsWeekly 是否为按周循环的时段
+func (w ScheduleWindowConfig) IsWeekly() bool {
+	return w.StartDay != "" || w.EndDay != ""
+}
+
+// ParseWeekly 解析周循环时段，返回开始/结束的星期及当日分钟数
+func (w ScheduleWindowConfig) ParseWeekly() (startDay time.Weekday, startMinute int, endDay time.Weekday, endMinute int, err error) {
+	if startDay, err = parseWeekday(w.StartDay); err != nil {
+		return
+	}
+	if endDay, err = parseWeekday(w.EndDay); err != nil {
+		return
+	}
+	if startMinute, err = parseClock(w.StartTime); err != nil {
+		return
+	}
+	endMinute, err = parseClock(w.EndTime)
+	return
+}
+
+// ParseFixed 解析固定起止时间
+func (w ScheduleWindowConfig) ParseFixed() (time.Time, time.Time, error) {
+	start, err := time.Parse(time.RFC3339, w.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := time.Parse(time.RFC3339, w.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	days := map[string]time.Weekday{
+		"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+		"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+	}
+	key := strings.ToLower(strings.TrimSpace(s))
+	if len(key) > 3 {
+		key = key[:3]
+	}
+	if d, ok := days[key]; ok {
+		return d, nil
+	}
+	return 0, fmt.Errorf("无效的星期: %q", s)
+}
+
+func parseClock(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("无效的时间 %q（需 HH:MM）", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
 // SymbolConfig 单个交易对配置（可指定所属交易所及交易参数）
 type SymbolConfig struct {
 	Exchange              string           `yaml:"exchange" json:"exchange"`                                 // 所属交易所，默认为 app.current_exchange
@@ -582,6 +1599,10 @@ type SymbolConfig struct {
 	MarginLockDurationSec int              `yaml:"margin_lock_duration_seconds" json:"margin_lock_duration"` // 保证金锁定时间（秒）
 	PositionSafetyCheck   int              `yaml:"position_safety_check" json:"position_safety_check"`       // 持仓安全性检查
 	GridRiskControl       GridRiskControl  `yaml:"grid_risk_control" json:"grid_risk_control"`               // 网格策略风控
+	RiskProfile           string           `yaml:"risk_profile" json:"risk_profile,omitempty"`               // 风控档位（conservative/balanced/aggressive 或自定义），覆盖窗口、杠杆和网格风控
+	ContractType          string           `yaml:"contract_type" json:"contract_type,omitempty"`             // 合约类型：linear（U 本位）/ inverse（币本位），默认继承 trading.contract_type
+	ContractSize          float64          `yaml:"contract_size" json:"contract_size,omitempty"`             // 币本位合约每张面值（计价货币）；币本位时 order_quantity/min_order_value 为计价货币名义价值
+	PriceBand             PriceBandConfig  `yaml:"price_band" json:"price_band"`                             // 价格带，未启用时继承 trading.price_band
 }
 
 // StrategyConfig 策略配置
@@ -602,6 +1623,211 @@ type ExchangeConfig struct {
 	Leverage   int     `yaml:"leverage" json:"leverage"`     // 杠杆倍数（仅 Gate.io 支持，0 表示不设置）
 }
 
+// ChaosConfig 混沌测试配置
+// 在交易所适配层随机注入 WebSocket 断线、REST 超时、成交推送延迟和乱序，
+// 用于验证对账器和风控系统能否正确恢复。概率均为 0~1，0 表示不注入该类故障
+type ChaosConfig struct {
+	Enabled            bool    `yaml:"enabled" json:"enabled"`                             // 是否启用（也可通过环境变量 QUANTMESH_CHAOS=1 开启）
+	Seed               int64   `yaml:"seed" json:"seed"`                                   // 随机种子（0 表示使用当前时间，固定种子便于复现）
+	RESTTimeoutRate    float64 `yaml:"rest_timeout_rate" json:"rest_timeout_rate"`         // REST 请求超时概率
+	RESTTimeoutDelayMs int     `yaml:"rest_timeout_delay_ms" json:"rest_timeout_delay_ms"` // 超时前的等待时间（毫秒，默认 1000）
+	WSDisconnectRate   float64 `yaml:"ws_disconnect_rate" json:"ws_disconnect_rate"`       // 每条 WebSocket 推送触发断线的概率
+	WSDisconnectSec    int     `yaml:"ws_disconnect_seconds" json:"ws_disconnect_seconds"` // 断线持续时间（秒，默认 10），期间推送全部丢弃
+	FillDelayRate      float64 `yaml:"fill_delay_rate" json:"fill_delay_rate"`             // 订单推送延迟概率
+	FillDelayMaxMs     int     `yaml:"fill_delay_max_ms" json:"fill_delay_max_ms"`         // 订单推送最大延迟（毫秒，默认 3000）
+	ReorderRate        float64 `yaml:"reorder_rate" json:"reorder_rate"`                   // 订单推送乱序概率（与下一条推送交换顺序）
+}
+
+// ReplayExchangeName 回放交易所名称
+// app.current_exchange 设为该值时，使用录制的行情文件代替真实交易所
+const ReplayExchangeName = "replay"
+
+// MarketDataConfig 行情录制与回放配置
+type MarketDataConfig struct {
+	// 录制：将 WebSocket 价格和订单推送原样写入 JSONL 文件
+	Record struct {
+		Enabled bool   `yaml:"enabled" json:"enabled"`
+		Dir     string `yaml:"dir" json:"dir"` // 录制目录（默认 ./data/recordings）
+	} `yaml:"record" json:"record"`
+
+	// 回放：current_exchange 为 replay 时生效
+	Replay struct {
+		File      string  `yaml:"file" json:"file"`             // 录制文件路径
+		Speed     float64 `yaml:"speed" json:"speed"`           // 回放倍速（默认 1，负数表示不等待、尽快回放）
+		WarmupSec int     `yaml:"warmup_sec" json:"warmup_sec"` // 首个价格推送后的预热等待时间（秒，默认 5），供系统完成初始化
+		Balance   float64 `yaml:"balance" json:"balance"`       // 模拟账户可用余额（默认 10000）
+		Venue     string  `yaml:"venue" json:"venue"`           // 模拟执行参数使用的交易所（对应 simulation.exchanges），为空时使用 simulation.default
+	} `yaml:"replay" json:"replay"`
+}
+
+// 模拟延迟分布
+const (
+	LatencyFixed     = "fixed"     // 固定为 mean_ms
+	LatencyUniform   = "uniform"   // 在 mean_ms ± stddev_ms 内均匀分布
+	LatencyNormal    = "normal"    // 正态分布
+	LatencyLognormal = "lognormal" // 对数正态分布（长尾，更接近真实网络延迟）
+)
+
+// 模拟滑点模型
+const (
+	SlippageNone   = "none"   // 不计滑点
+	SlippageFixed  = "fixed"  // 固定 bps
+	SlippageRandom = "random" // 在 [0, 2×bps] 内均匀分布（平均 bps），不超过 max_bps
+)
+
+// LatencyConfig 模拟延迟分布（毫秒）
+type LatencyConfig struct {
+	Distribution string  `yaml:"distribution" json:"distribution"` // fixed / uniform / normal / lognormal，默认 fixed
+	MeanMs       float64 `yaml:"mean_ms" json:"mean_ms"`           // 平均延迟
+	StdDevMs     float64 `yaml:"stddev_ms" json:"stddev_ms"`       // 标准差（uniform 时为半宽）
+	MaxMs        float64 `yaml:"max_ms" json:"max_ms"`             // 上限，0 表示不限制
+}
+
+// SlippageConfig 模拟滑点
+type SlippageConfig struct {
+	Model  string  `yaml:"model" json:"model"`     // none / fixed / random，默认 none
+	Bps    float64 `yaml:"bps" json:"bps"`         // 滑点（万分之一）
+	MaxBps float64 `yaml:"max_bps" json:"max_bps"` // random 模型的上限，0 表示不限制
+}
+
+// ExecutionSimConfig 单个交易所的模拟执行参数
+type ExecutionSimConfig struct {
+	AckLatency  LatencyConfig  `yaml:"ack_latency" json:"ack_latency"`   // 下单/撤单到收到交易所回报的延迟
+	FillLatency LatencyConfig  `yaml:"fill_latency" json:"fill_latency"` // 回报之后到成交的延迟
+	Slippage    SlippageConfig `yaml:"slippage" json:"slippage"`
+}
+
+// 远程日志转发目标类型
+const (
+	LogShippingLoki  = "loki"  // Loki push API（/loki/api/v1/push）
+	LogShippingHTTPS = "https" // 通用 HTTPS 端点（POST JSON 批次）
+)
+
+// LogShippingConfig 远程日志/指标转发
+type LogShippingConfig struct {
+	Enabled  bool              `yaml:"enabled"`   // 是否启用，默认 false
+	Type     string            `yaml:"type"`      // loki / https，默认 loki
+	URL      string            `yaml:"url"`       // 目标地址，必须为 https://
+	Username string            `yaml:"username"`  // Basic 认证用户名（可选，如 Grafana Cloud 的实例 ID）
+	Password string            `yaml:"password"`  // Basic 认证密码或 API Token（可选）
+	Headers  map[string]string `yaml:"headers"`   // 额外请求头（如 Authorization、X-Scope-OrgID）
+	Labels   map[string]string `yaml:"labels"`    // 附加标签（默认已包含 app、instance、host）
+	MinLevel string            `yaml:"min_level"` // 转发的最低日志级别，默认 info
+
+	Metrics         []string `yaml:"metrics"`          // 转发的指标名称，默认盈亏、持仓、胜率、风控与连接状态
+	MetricsInterval int      `yaml:"metrics_interval"` // 指标采样间隔（秒），默认 60，0 以下使用默认值
+
+	BatchSize     int    `yaml:"batch_size"`     // 单批最多条数，默认 500
+	FlushInterval int    `yaml:"flush_interval"` // 发送间隔（秒），默认 5
+	BufferDir     string `yaml:"buffer_dir"`     // 发送失败时的磁盘缓存目录，默认 ./data/log_shipping
+	MaxBufferMB   int    `yaml:"max_buffer_mb"`  // 磁盘缓存上限（MB），超出后丢弃最旧的批次，默认 100
+
+	TLS struct {
+		CAFile   string `yaml:"ca_file"`   // 自定义 CA 证书（自签名端点）
+		CertFile string `yaml:"cert_file"` // 客户端证书（双向 TLS，可选）
+		KeyFile  string `yaml:"key_file"`  // 客户端私钥
+	} `yaml:"tls"`
+}
+
+// validate 校验并补全默认值
+func (l *LogShippingConfig) validate() error {
+	if !l.Enabled {
+		return nil
+	}
+	l.Type = strings.ToLower(l.Type)
+	if l.Type == "" {
+		l.Type = LogShippingLoki
+	}
+	if l.Type != LogShippingLoki && l.Type != LogShippingHTTPS {
+		return fmt.Errorf("log_shipping.type 必须是 loki 或 https")
+	}
+	// 日志中可能包含账户和交易信息，只允许加密传输
+	if !strings.HasPrefix(strings.ToLower(l.URL), "https://") {
+		return fmt.Errorf("log_shipping.url 必须是 https:// 地址")
+	}
+	if (l.TLS.CertFile == "") != (l.TLS.KeyFile == "") {
+		return fmt.Errorf("log_shipping.tls.cert_file 和 key_file 必须同时配置")
+	}
+	l.MinLevel = strings.ToLower(l.MinLevel)
+	if l.MinLevel == "" {
+		l.MinLevel = "info"
+	}
+	if len(l.Metrics) == 0 {
+		l.Metrics = []string{
+			"quantmesh_pnl_total",
+			"quantmesh_pnl_realized_total",
+			"quantmesh_position_size",
+			"quantmesh_win_rate",
+			"quantmesh_risk_control_triggered",
+			"quantmesh_websocket_connected",
+		}
+	}
+	if l.MetricsInterval <= 0 {
+		l.MetricsInterval = 60
+	}
+	if l.BatchSize <= 0 {
+		l.BatchSize = 500
+	}
+	if l.FlushInterval <= 0 {
+		l.FlushInterval = 5
+	}
+	if l.BufferDir == "" {
+		l.BufferDir = "./data/log_shipping"
+	}
+	if l.MaxBufferMB <= 0 {
+		l.MaxBufferMB = 100
+	}
+	return nil
+}
+
+// SimulationConfig 模拟执行配置，exchanges 中的条目整体覆盖 default
+// 回放交易所始终按此配置模拟回报延迟；回测仅在 enabled 时使用（否则沿用固定滑点、收盘价即时成交）
+type SimulationConfig struct {
+	Enabled   bool                          `yaml:"enabled" json:"enabled"` // 回测是否使用模拟执行模型
+	Seed      int64                         `yaml:"seed" json:"seed"`       // 随机种子（0 表示使用当前时间）
+	Default   ExecutionSimConfig            `yaml:"default" json:"default"`
+	Exchanges map[string]ExecutionSimConfig `yaml:"exchanges" json:"exchanges"`
+}
+
+// For 返回指定交易所的模拟执行参数，未单独配置时使用 default
+func (s SimulationConfig) For(exchange string) ExecutionSimConfig {
+	if cfg, ok := s.Exchanges[exchange]; ok {
+		return cfg
+	}
+	return s.Default
+}
+
+// validate 校验并补全默认值
+func (e *ExecutionSimConfig) validate(path string) error {
+	for name, l := range map[string]*LatencyConfig{"ack_latency": &e.AckLatency, "fill_latency": &e.FillLatency} {
+		l.Distribution = strings.ToLower(l.Distribution)
+		if l.Distribution == "" {
+			l.Distribution = LatencyFixed
+		}
+		switch l.Distribution {
+		case LatencyFixed, LatencyUniform, LatencyNormal, LatencyLognormal:
+		default:
+			return fmt.Errorf("%s.%s.distribution 必须为 fixed/uniform/normal/lognormal", path, name)
+		}
+		if l.MeanMs < 0 || l.StdDevMs < 0 || l.MaxMs < 0 {
+			return fmt.Errorf("%s.%s 的延迟参数不能为负数", path, name)
+		}
+	}
+	e.Slippage.Model = strings.ToLower(e.Slippage.Model)
+	if e.Slippage.Model == "" {
+		e.Slippage.Model = SlippageNone
+	}
+	switch e.Slippage.Model {
+	case SlippageNone, SlippageFixed, SlippageRandom:
+	default:
+		return fmt.Errorf("%s.slippage.model 必须为 none/fixed/random", path)
+	}
+	if e.Slippage.Bps < 0 || e.Slippage.MaxBps < 0 {
+		return fmt.Errorf("%s.slippage 的参数不能为负数", path)
+	}
+	return nil
+}
+
 // SymbolAllocation 单个币种的资金分配配置
 type SymbolAllocation struct {
 	Exchange      string  `yaml:"exchange"`
@@ -735,6 +1961,7 @@ func CreateMinimalConfig() *Config {
 	cfg.Notifications.Enabled = false
 	cfg.Notifications.Webhook.Timeout = 3
 	cfg.Notifications.Email.Provider = "smtp"
+	cfg.Notifications.Ntfy.Server = "https://ntfy.sh"
 
 	cfg.Metrics.Enabled = true
 	cfg.Metrics.CollectInterval = 60
@@ -879,7 +2106,7 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("交易所 %s 的配置不存在", c.App.CurrentExchange)
 	}
 
-	if exchangeCfg.APIKey == "" || exchangeCfg.SecretKey == "" {
+	if c.App.CurrentExchange != ReplayExchangeName && (exchangeCfg.APIKey == "" || exchangeCfg.SecretKey == "") {
 		return fmt.Errorf("交易所 %s 的 API 配置不完整", c.App.CurrentExchange)
 	}
 
@@ -898,7 +2125,7 @@ func (c *Config) Validate() error {
 		if !ok {
 			return sc, fmt.Errorf("交易所 %s 的配置不存在", sc.Exchange)
 		}
-		if exCfg.APIKey == "" || exCfg.SecretKey == "" {
+		if sc.Exchange != ReplayExchangeName && (exCfg.APIKey == "" || exCfg.SecretKey == "") {
 			return sc, fmt.Errorf("交易所 %s 的 API 配置不完整", sc.Exchange)
 		}
 		if exCfg.FeeRate < 0 {
@@ -988,9 +2215,30 @@ func (c *Config) Validate() error {
 			}
 		}
 
-		// 风控配置继承
-		if !sc.GridRiskControl.Enabled && c.Trading.GridRiskControl.Enabled {
-			sc.GridRiskControl = c.Trading.GridRiskControl
+		// 合约类型（币本位的数量为合约张数，盈亏以基础币结算）
+		if sc.ContractType == "" {
+			sc.ContractType = c.Trading.ContractType
+		}
+		sc.ContractType = strings.ToLower(sc.ContractType)
+		if sc.ContractType == "" {
+			sc.ContractType = "linear"
+		}
+		switch sc.ContractType {
+		case "linear":
+		case "inverse":
+			if sc.ContractSize <= 0 {
+				sc.ContractSize = c.Trading.ContractSize
+			}
+			if sc.ContractSize <= 0 {
+				return sc, fmt.Errorf("币本位交易对 %s 需配置 contract_size（每张合约面值）", sc.Symbol)
+			}
+		default:
+			return sc, fmt.Errorf("交易对 %s 的 contract_type 无效: %s（可选 linear / inverse）", sc.Symbol, sc.ContractType)
+		}
+
+		// 风控配置继承
+		if !sc.GridRiskControl.Enabled && c.Trading.GridRiskControl.Enabled {
+			sc.GridRiskControl = c.Trading.GridRiskControl
 		} else if sc.GridRiskControl.Enabled {
 			// 如果启用了但某些字段没填，可以考虑从全局继承，但通常启用表示要自定义
 			if sc.GridRiskControl.MaxGridLayers == 0 {
@@ -1007,6 +2255,14 @@ func (c *Config) Validate() error {
 			}
 		}
 
+		// 价格带继承
+		if !sc.PriceBand.Enabled {
+			sc.PriceBand = c.Trading.PriceBand
+		}
+		if err := sc.PriceBand.validate(sc.Symbol); err != nil {
+			return sc, err
+		}
+
 		// 验证策略占比
 		if len(sc.Strategies) > 0 {
 			var totalWeight float64
@@ -1040,6 +2296,8 @@ func (c *Config) Validate() error {
 			MarginLockDurationSec: c.Trading.MarginLockDurationSec,
 			PositionSafetyCheck:   c.Trading.PositionSafetyCheck,
 			GridRiskControl:       c.Trading.GridRiskControl,
+			ContractType:          c.Trading.ContractType,
+			ContractSize:          c.Trading.ContractSize,
 		}}
 	}
 
@@ -1052,6 +2310,9 @@ func (c *Config) Validate() error {
 		normalized = append(normalized, norm)
 	}
 	c.Trading.Symbols = normalized
+	if err := c.validateRiskProfiles(); err != nil {
+		return err
+	}
 
 	// 兼容旧字段：保持首个交易对到旧字段，供未改造代码使用
 	if len(c.Trading.Symbols) > 0 {
@@ -1068,6 +2329,8 @@ func (c *Config) Validate() error {
 		c.Trading.MarginLockDurationSec = primary.MarginLockDurationSec
 		c.Trading.PositionSafetyCheck = primary.PositionSafetyCheck
 		c.Trading.GridRiskControl = primary.GridRiskControl
+		c.Trading.ContractType = primary.ContractType
+		c.Trading.ContractSize = primary.ContractSize
 	}
 
 	// 设置默认时间间隔
@@ -1077,6 +2340,13 @@ func (c *Config) Validate() error {
 	if c.System.LogRetentionDays <= 0 {
 		c.System.LogRetentionDays = 30 // 默认保留30天
 	}
+	switch c.System.LogProfile {
+	case "":
+		c.System.LogProfile = "normal"
+	case "normal", "quiet", "verbose":
+	default:
+		return fmt.Errorf("system.log_profile 必须为 normal、quiet 或 verbose: %s", c.System.LogProfile)
+	}
 
 	if c.Timing.WebSocketReconnectDelay <= 0 {
 		c.Timing.WebSocketReconnectDelay = 5 // 默认5秒
@@ -1108,6 +2378,9 @@ func (c *Config) Validate() error {
 	if c.Timing.StatusPrintInterval <= 0 {
 		c.Timing.StatusPrintInterval = 1 // 默认1分钟
 	}
+	if c.Timing.StatusPrintIntervalMs < 0 || (c.Timing.StatusPrintIntervalMs > 0 && c.Timing.StatusPrintIntervalMs < 100) {
+		return fmt.Errorf("timing.status_print_interval_ms 必须为0或不小于100")
+	}
 	if c.Timing.OrderCleanupInterval <= 0 {
 		c.Timing.OrderCleanupInterval = 60 // 默认60秒
 	}
@@ -1125,6 +2398,68 @@ func (c *Config) Validate() error {
 	if len(c.RiskControl.MonitorSymbols) == 0 {
 		c.RiskControl.MonitorSymbols = []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "XRPUSDT", "DOGEUSDT"}
 	}
+	if c.RiskControl.AutoDiscovery.TopN <= 0 {
+		c.RiskControl.AutoDiscovery.TopN = 5
+	}
+	if c.RiskControl.AutoDiscovery.QuoteAsset == "" {
+		c.RiskControl.AutoDiscovery.QuoteAsset = "USDT"
+	}
+	c.RiskControl.AutoDiscovery.QuoteAsset = strings.ToUpper(c.RiskControl.AutoDiscovery.QuoteAsset)
+	if c.RiskControl.AutoDiscovery.RefreshHours <= 0 {
+		c.RiskControl.AutoDiscovery.RefreshHours = 24
+	}
+	if len(c.RiskControl.StressTest.PriceShocks) == 0 {
+		c.RiskControl.StressTest.PriceShocks = []float64{-0.05, -0.10}
+	}
+	for _, shock := range c.RiskControl.StressTest.PriceShocks {
+		if shock <= -1 || shock == 0 {
+			return fmt.Errorf("risk_control.stress_test.price_shocks 必须大于 -1 且不为 0")
+		}
+	}
+	if len(c.RiskControl.StressTest.VolMultipliers) == 0 {
+		c.RiskControl.StressTest.VolMultipliers = []float64{2}
+	}
+	for _, mult := range c.RiskControl.StressTest.VolMultipliers {
+		if mult <= 0 {
+			return fmt.Errorf("risk_control.stress_test.vol_multipliers 必须大于 0")
+		}
+	}
+	if c.RiskControl.StressTest.VolLookback <= 1 {
+		c.RiskControl.StressTest.VolLookback = 30
+	}
+	if c.RiskControl.LiquidationWarningRatio <= 0 {
+		c.RiskControl.LiquidationWarningRatio = 0.05 // 默认距强平价5%
+	}
+	if c.RiskControl.ReconcileDivergenceRatio <= 0 {
+		c.RiskControl.ReconcileDivergenceRatio = 0.1 // 默认偏差10%
+	}
+	tolerance := &c.RiskControl.ReconcileTolerance
+	if tolerance.Absolute <= 0 {
+		tolerance.Absolute = 0.00000001 // 默认只忽略浮点误差
+	}
+	if tolerance.Relative < 0 || tolerance.Relative >= c.RiskControl.ReconcileDivergenceRatio {
+		return fmt.Errorf("risk_control.reconcile_tolerance.relative 必须在 0 到 reconcile_divergence_ratio 之间")
+	}
+	symbolTolerances := make(map[string]ReconcileTolerance, len(tolerance.Symbols))
+	for sym, t := range tolerance.Symbols {
+		if t.Absolute < 0 || t.Relative < 0 || t.Relative >= c.RiskControl.ReconcileDivergenceRatio {
+			return fmt.Errorf("risk_control.reconcile_tolerance.symbols.%s 容差无效", sym)
+		}
+		symbolTolerances[strings.ToUpper(sym)] = t
+	}
+	tolerance.Symbols = symbolTolerances
+	if c.RiskControl.PriceOracle.Source == "" {
+		c.RiskControl.PriceOracle.Source = "binance"
+	}
+	if c.RiskControl.PriceOracle.MaxDivergence <= 0 {
+		c.RiskControl.PriceOracle.MaxDivergence = 0.01 // 默认1%
+	}
+	if c.RiskControl.PriceOracle.Interval <= 0 {
+		c.RiskControl.PriceOracle.Interval = 10 // 默认10秒
+	}
+	if c.RiskControl.PriceOracle.ConfirmCount <= 0 {
+		c.RiskControl.PriceOracle.ConfirmCount = 2
+	}
 
 	// 验证恢复阈值配置
 	monitorCount := len(c.RiskControl.MonitorSymbols)
@@ -1140,9 +2475,38 @@ func (c *Config) Validate() error {
 	if c.Notifications.Webhook.Timeout <= 0 {
 		c.Notifications.Webhook.Timeout = 3 // 默认3秒
 	}
+	if c.Notifications.Ntfy.Server == "" {
+		c.Notifications.Ntfy.Server = "https://ntfy.sh" // 默认使用公共服务器
+	}
+	if len(c.Notifications.Escalation.Events) == 0 {
+		c.Notifications.Escalation.Events = []string{"liquidation_risk", "reconciliation_divergence", "watchdog_failure"}
+	}
+	if c.Notifications.Escalation.MinInterval <= 0 {
+		c.Notifications.Escalation.MinInterval = 900 // 默认15分钟
+	}
+	if c.Notifications.Escalation.MaxPerHour <= 0 {
+		c.Notifications.Escalation.MaxPerHour = 4
+	}
+	if c.Notifications.Escalation.Twilio.Mode == "" {
+		c.Notifications.Escalation.Twilio.Mode = "call"
+	}
 	if c.Notifications.Email.Provider == "" {
 		c.Notifications.Email.Provider = "smtp" // 默认SMTP
 	}
+	for key, tmpl := range c.Notifications.Templates {
+		if tmpl.Title == "" && tmpl.Body == "" {
+			return fmt.Errorf("notifications.templates.%s 至少需要配置 title 或 body", key)
+		}
+		if channel, name, ok := strings.Cut(key, "."); ok {
+			known := false
+			for _, ch := range NotificationChannels {
+				known = known || ch == channel
+			}
+			if !known || name == "" {
+				return fmt.Errorf("notifications.templates.%s 渠道无效（可选: %s）", key, strings.Join(NotificationChannels, ", "))
+			}
+		}
+	}
 
 	// 设置存储配置默认值
 	if c.Storage.Type == "" {
@@ -1169,6 +2533,25 @@ func (c *Config) Validate() error {
 		c.Web.Port = 28888 // 默认端口（使用10000以上端口，避免常见端口冲突）
 	}
 	
+	// headless 模式：强制关闭 Web 服务
+	if c.Headless.Enabled {
+		c.Web.Enabled = false
+	}
+	if c.Headless.ControlDir == "" {
+		c.Headless.ControlDir = "./data/control"
+	}
+	if c.Headless.PollInterval <= 0 {
+		c.Headless.PollInterval = 500
+	}
+	if c.AdminSocket.Path == "" {
+		c.AdminSocket.Path = "./data/admin.sock"
+	}
+
+	// 远程日志/指标转发
+	if err := c.LogShipping.validate(); err != nil {
+		return err
+	}
+	
 	// 设置 pprof 配置默认值
 	if len(c.Web.Pprof.AllowedIPs) == 0 {
 		// 默认允许本地访问
@@ -1177,6 +2560,65 @@ func (c *Config) Validate() error {
 	// pprof.Enabled 默认为 false（生产环境安全）
 	// pprof.RequireAuth 默认为 true（需要认证）
 
+	// 设置公开状态页默认值
+	if c.Web.PublicStatus.Title == "" {
+		c.Web.PublicStatus.Title = "QuantMesh Status"
+	}
+	if len(c.Web.PublicStatus.Metrics) == 0 {
+		c.Web.PublicStatus.Metrics = []string{"equity_curve", "uptime", "last_trade_time"}
+	}
+	if c.Web.PublicStatus.EquityDays <= 0 {
+		c.Web.PublicStatus.EquityDays = 30
+	}
+	if c.Web.PublicStatus.RateLimit <= 0 {
+		c.Web.PublicStatus.RateLimit = 30
+	}
+
+	// 设置反向代理默认值
+	basePath, err := normalizeBasePath(c.Web.BasePath)
+	if err != nil {
+		return err
+	}
+	c.Web.BasePath = basePath
+	if c.Web.TrustedProxies == nil {
+		// 默认只信任本机（同机部署的 nginx），避免任意客户端伪造 X-Forwarded-For 绕过限流或污染审计日志
+		c.Web.TrustedProxies = []string{"127.0.0.1", "::1"}
+	}
+	for _, proxy := range c.Web.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("web.trusted_proxies 中的 %q 不是有效的 IP 或 CIDR", proxy)
+			}
+		}
+	}
+	limits := DefaultEndpointLimits()
+	for path, limit := range c.Web.EndpointLimits {
+		if !strings.HasPrefix(path, "/api/") {
+			return fmt.Errorf("web.endpoint_limits 的键必须是 /api/ 开头的接口路径: %s", path)
+		}
+		if limit.RatePerMinute < 0 || limit.Burst < 0 || limit.CacheTTL < 0 {
+			return fmt.Errorf("web.endpoint_limits.%s 的参数不能为负数", path)
+		}
+		limits[path] = limit
+	}
+	for path, limit := range limits {
+		if limit.RatePerMinute > 0 && limit.Burst == 0 {
+			limit.Burst = limit.RatePerMinute / 6
+			if limit.Burst < 3 {
+				limit.Burst = 3
+			}
+			limits[path] = limit
+		}
+	}
+	c.Web.EndpointLimits = limits
+	if len(c.Web.RemoteIPHeaders) == 0 {
+		c.Web.RemoteIPHeaders = []string{"X-Forwarded-For", "X-Real-IP"}
+	}
+	if c.Web.WebAuthn.RPOrigin != "" && !strings.HasPrefix(c.Web.WebAuthn.RPOrigin, "http://") &&
+		!strings.HasPrefix(c.Web.WebAuthn.RPOrigin, "https://") {
+		return fmt.Errorf("web.webauthn.rp_origin 必须以 http:// 或 https:// 开头")
+	}
+
 	// 设置实例配置默认值
 	if c.Instance.ID == "" {
 		c.Instance.ID = "default-instance" // 默认实例ID
@@ -1225,6 +2667,101 @@ func (c *Config) Validate() error {
 		c.DistributedLock.Redis.PoolSize = 10 // 默认连接池大小
 	}
 
+	if c.System.FastCancelSeconds <= 0 {
+		c.System.FastCancelSeconds = 5
+	}
+	if p := c.System.Shutdown.Default; p != "" && !validShutdownPolicy(p) {
+		return fmt.Errorf("system.shutdown.default 无效: %s（可选 cancel_all / cancel_buys / flatten / leave）", p)
+	}
+	for name, p := range c.System.Shutdown.Strategies {
+		if !validShutdownPolicy(p) {
+			return fmt.Errorf("system.shutdown.strategies.%s 无效: %s（可选 cancel_all / cancel_buys / flatten / leave）", name, p)
+		}
+	}
+	if c.System.Restart.ExitCode == 0 {
+		c.System.Restart.ExitCode = 75
+	}
+	if c.System.Restart.ExitCode < 2 || c.System.Restart.ExitCode > 255 {
+		return fmt.Errorf("system.restart.exit_code 必须在 2-255 之间（0 和 1 表示正常退出和异常退出）")
+	}
+	if c.System.Restart.TokenFile == "" {
+		c.System.Restart.TokenFile = "./data/resume_token.json"
+	}
+	if c.System.Restart.TokenTTLSeconds <= 0 {
+		c.System.Restart.TokenTTLSeconds = 600
+	}
+
+	// 设置重复实例检测默认值（默认开启）
+	switch c.InstanceLock.Mode {
+	case "":
+		c.InstanceLock.Mode = "refuse"
+	case "refuse", "observer", "off":
+	default:
+		return fmt.Errorf("instance_lock.mode 必须是 refuse、observer 或 off")
+	}
+	if c.InstanceLock.Dir == "" {
+		c.InstanceLock.Dir = "./data/locks"
+	}
+	if c.InstanceLock.StaleSeconds <= 0 {
+		c.InstanceLock.StaleSeconds = 30
+	}
+
+	// 设置热备配置默认值
+	switch c.Standby.Role {
+	case "":
+	case "primary", "standby":
+		if c.Standby.Token == "" {
+			return fmt.Errorf("standby.token 不能为空")
+		}
+		// 复制通道静默可能只是网络分区，主实例仍在交易；只有跨主机的实例租约能保证同一时刻只有一方下单
+		if !c.DistributedLock.Enabled {
+			return fmt.Errorf("热备模式依赖实例租约防止双主，需启用 distributed_lock")
+		}
+		if c.Standby.Role == "standby" {
+			if c.Standby.PrimaryURL == "" {
+				return fmt.Errorf("standby.primary_url 不能为空")
+			}
+			if c.InstanceLock.Mode == "off" {
+				return fmt.Errorf("热备模式依赖实例锁，instance_lock.mode 不能为 off")
+			}
+		}
+	default:
+		return fmt.Errorf("standby.role 必须是 primary 或 standby")
+	}
+	if c.Standby.Listen == "" {
+		c.Standby.Listen = ":28890"
+	}
+	if c.Standby.SnapshotInterval <= 0 {
+		c.Standby.SnapshotInterval = 2
+	}
+	if c.Standby.TakeoverAfter <= 0 {
+		c.Standby.TakeoverAfter = 10
+	}
+
+	// 设置多实例总览默认值
+	switch c.Fleet.Role {
+	case "":
+	case "hub", "member":
+		if c.Fleet.Token == "" {
+			return fmt.Errorf("fleet.token 不能为空")
+		}
+		if c.Fleet.Role == "hub" && !c.Web.Enabled {
+			return fmt.Errorf("fleet hub 需要启用 Web 服务接收成员上报")
+		}
+		if c.Fleet.Role == "member" && c.Fleet.HubURL == "" {
+			return fmt.Errorf("fleet.hub_url 不能为空")
+		}
+	default:
+		return fmt.Errorf("fleet.role 必须是 hub 或 member")
+	}
+	c.Fleet.HubURL = strings.TrimRight(c.Fleet.HubURL, "/")
+	if c.Fleet.ReportInterval <= 0 {
+		c.Fleet.ReportInterval = 30
+	}
+	if c.Fleet.StaleAfter <= 0 {
+		c.Fleet.StaleAfter = 90
+	}
+
 	// 设置监控配置默认值
 	if c.Metrics.CollectInterval <= 0 {
 		c.Metrics.CollectInterval = 60 // 默认60秒
@@ -1257,6 +2794,58 @@ func (c *Config) Validate() error {
 			"max_drawdown": 0.1,
 		}
 	}
+	switch c.Strategies.CapitalAllocation.Mode {
+	case "fixed", "dynamic", "both", "risk_parity", "kelly":
+	default:
+		return fmt.Errorf("strategies.capital_allocation.mode 必须是 fixed/dynamic/both/risk_parity/kelly，当前为 %q", c.Strategies.CapitalAllocation.Mode)
+	}
+	if c.Strategies.CapitalAllocation.DynamicAllocation.ReturnLookback <= 0 {
+		c.Strategies.CapitalAllocation.DynamicAllocation.ReturnLookback = 30
+	}
+	if c.Strategies.CapitalAllocation.DynamicAllocation.MinReturnSamples <= 0 {
+		c.Strategies.CapitalAllocation.DynamicAllocation.MinReturnSamples = 5
+	}
+	if c.Strategies.CapitalAllocation.DynamicAllocation.MinReturnSamples < 2 {
+		c.Strategies.CapitalAllocation.DynamicAllocation.MinReturnSamples = 2 // 波动率至少需要2个样本
+	}
+	if c.Strategies.CapitalAllocation.Kelly.Fraction <= 0 {
+		c.Strategies.CapitalAllocation.Kelly.Fraction = 0.5
+	}
+	if c.Strategies.CapitalAllocation.Kelly.MaxFraction <= 0 {
+		c.Strategies.CapitalAllocation.Kelly.MaxFraction = 0.25
+	}
+	optimizer := &c.Strategies.CapitalAllocation.Optimizer
+	if optimizer.CheckInterval <= 0 {
+		optimizer.CheckInterval = 3600
+	}
+	if optimizer.LookbackDays <= 0 {
+		optimizer.LookbackDays = 30
+	}
+	if optimizer.MinDays <= 0 {
+		optimizer.MinDays = 7
+	}
+	if optimizer.MinDays < 2 {
+		optimizer.MinDays = 2 // 波动率至少需要2个样本
+	}
+	if optimizer.MinDays > optimizer.LookbackDays {
+		return fmt.Errorf("strategies.capital_allocation.optimizer.min_days 不能大于 lookback_days")
+	}
+	lossBreaker := &c.Strategies.LossBreaker
+	if lossBreaker.MaxConsecutiveLosses <= 0 {
+		lossBreaker.MaxConsecutiveLosses = 3
+	}
+	if lossBreaker.WindowMinutes <= 0 {
+		lossBreaker.WindowMinutes = 1440 // 默认24小时
+	}
+	if lossBreaker.CooldownMinutes <= 0 {
+		lossBreaker.CooldownMinutes = 60
+	}
+	if lossBreaker.CheckInterval <= 0 {
+		lossBreaker.CheckInterval = 10
+	}
+	if lossBreaker.MaxLossPercent < 0 || lossBreaker.MaxLossPercent > 100 {
+		return fmt.Errorf("strategies.loss_breaker.max_loss_percent 必须在 0-100 之间")
+	}
 
 	// 设置事件中心配置默认值
 	// 默认启用事件中心
@@ -1285,5 +2874,706 @@ func (c *Config) Validate() error {
 		c.EventCenter.CleanupInterval = 24 // 默认每24小时清理一次
 	}
 
+	// 设置成交异常检测默认值
+	if c.FillAnomaly.IntervalMinutes <= 0 {
+		c.FillAnomaly.IntervalMinutes = 5
+	}
+	if c.FillAnomaly.LoopWindowSeconds <= 0 {
+		c.FillAnomaly.LoopWindowSeconds = 60
+	}
+	if c.FillAnomaly.LoopCount <= 1 {
+		c.FillAnomaly.LoopCount = 3
+	}
+
+	// 设置市场状态分类默认值
+	if c.Trading.Regime.Interval == "" {
+		c.Trading.Regime.Interval = "15m"
+	}
+	if c.Trading.Regime.Lookback <= 0 {
+		c.Trading.Regime.Lookback = 100
+	}
+	if c.Trading.Regime.ADXPeriod <= 0 {
+		c.Trading.Regime.ADXPeriod = 14
+	}
+	if c.Trading.Regime.ADXTrendThreshold <= 0 {
+		c.Trading.Regime.ADXTrendThreshold = 25
+	}
+	if c.Trading.Regime.HighVolThreshold <= 0 {
+		c.Trading.Regime.HighVolThreshold = 1.0
+	}
+	if c.Trading.Regime.CheckInterval <= 0 {
+		c.Trading.Regime.CheckInterval = 300
+	}
+	if c.Trading.Regime.Enabled && c.Trading.Regime.Lookback < c.Trading.Regime.ADXPeriod*2+1 {
+		return fmt.Errorf("trading.regime.lookback 至少需要 adx_period*2+1 根K线")
+	}
+
+	// 设置多周期趋势服务默认值
+	if len(c.Trading.TrendService.Timeframes) == 0 {
+		c.Trading.TrendService.Timeframes = []string{"1m", "15m", "1h"}
+	}
+	if c.Trading.TrendService.FastPeriod <= 0 {
+		c.Trading.TrendService.FastPeriod = 12
+	}
+	if c.Trading.TrendService.SlowPeriod <= 0 {
+		c.Trading.TrendService.SlowPeriod = 26
+	}
+	if c.Trading.TrendService.SignalPeriod <= 0 {
+		c.Trading.TrendService.SignalPeriod = 9
+	}
+	if c.Trading.TrendService.Lookback <= 0 {
+		c.Trading.TrendService.Lookback = 100
+	}
+	if c.Trading.TrendService.CheckInterval <= 0 {
+		c.Trading.TrendService.CheckInterval = 60
+	}
+	if c.Trading.TrendService.Enabled {
+		if c.Trading.TrendService.FastPeriod >= c.Trading.TrendService.SlowPeriod {
+			return fmt.Errorf("trading.trend_service.fast_period 必须小于 slow_period")
+		}
+		if c.Trading.TrendService.Lookback < c.Trading.TrendService.SlowPeriod+c.Trading.TrendService.SignalPeriod {
+			return fmt.Errorf("trading.trend_service.lookback 至少需要 slow_period+signal_period 根K线")
+		}
+	}
+
+	// 设置支撑/阻力位检测默认值
+	levels := &c.Trading.Levels
+	if levels.Interval == "" {
+		levels.Interval = "1h"
+	}
+	if levels.Lookback <= 0 {
+		levels.Lookback = 200
+	}
+	if levels.PivotWindow <= 0 {
+		levels.PivotWindow = 3
+	}
+	if levels.Tolerance <= 0 {
+		levels.Tolerance = 0.3
+	}
+	if levels.MaxLevels <= 0 {
+		levels.MaxLevels = 5
+	}
+	if levels.PatternBars <= 0 {
+		levels.PatternBars = 10
+	}
+	if levels.CheckInterval <= 0 {
+		levels.CheckInterval = 300
+	}
+	if levels.GridBias.ZonePercent <= 0 {
+		levels.GridBias.ZonePercent = 0.5
+	}
+	if levels.GridBias.MinTouches <= 0 {
+		levels.GridBias.MinTouches = 2
+	}
+	if levels.Enabled && levels.Lookback < levels.PivotWindow*2+1 {
+		return fmt.Errorf("trading.levels.lookback 至少需要 pivot_window*2+1 根K线")
+	}
+
+	// 设置高波动保护默认值
+	volGuard := &c.Trading.VolatilityGuard
+	if volGuard.WindowSeconds <= 0 {
+		volGuard.WindowSeconds = 60
+	}
+	if volGuard.ThresholdPercent <= 0 {
+		volGuard.ThresholdPercent = 1.0
+	}
+	if volGuard.RecoverPercent <= 0 {
+		volGuard.RecoverPercent = volGuard.ThresholdPercent * 0.6
+	}
+	if volGuard.MinActiveSeconds <= 0 {
+		volGuard.MinActiveSeconds = 120
+	}
+	if volGuard.OffsetIntervals <= 0 {
+		volGuard.OffsetIntervals = 1
+	}
+	if volGuard.Enabled && volGuard.RecoverPercent > volGuard.ThresholdPercent {
+		return fmt.Errorf("trading.volatility_guard.recover_percent 不能大于 threshold_percent")
+	}
+
+	// 设置买盘深度保护默认值
+	depthGuard := &c.Trading.DepthGuard
+	if depthGuard.Levels <= 0 {
+		depthGuard.Levels = 5
+	}
+	if depthGuard.WindowSeconds <= 0 {
+		depthGuard.WindowSeconds = 5
+	}
+	if depthGuard.DropPercent <= 0 {
+		depthGuard.DropPercent = 70
+	}
+	if depthGuard.RecoverPercent <= 0 {
+		depthGuard.RecoverPercent = 60
+	}
+	if depthGuard.MinActiveSeconds <= 0 {
+		depthGuard.MinActiveSeconds = 30
+	}
+	if depthGuard.MaxActiveSeconds <= 0 {
+		depthGuard.MaxActiveSeconds = 300
+	}
+	if depthGuard.Enabled {
+		if depthGuard.DropPercent >= 100 {
+			return fmt.Errorf("trading.depth_guard.drop_percent 必须小于 100")
+		}
+		if depthGuard.RecoverPercent > 100 {
+			return fmt.Errorf("trading.depth_guard.recover_percent 不能大于 100")
+		}
+		if depthGuard.MaxActiveSeconds < depthGuard.MinActiveSeconds {
+			return fmt.Errorf("trading.depth_guard.max_active_seconds 不能小于 min_active_seconds")
+		}
+	}
+
+	// 设置只减仓检查默认值
+	if c.Trading.ReduceOnlyGuard.RefreshMillis <= 0 {
+		c.Trading.ReduceOnlyGuard.RefreshMillis = 2000
+	}
+
+	// 最小名义价值变更处理
+	if c.Trading.MinNotionalChange.Action == "" {
+		c.Trading.MinNotionalChange.Action = MinNotionalActionAlert
+	}
+	switch c.Trading.MinNotionalChange.Action {
+	case MinNotionalActionAlert, MinNotionalActionAdjust, MinNotionalActionPause:
+	default:
+		return fmt.Errorf("trading.min_notional_change.action 无效: %s（可选 alert / adjust / pause）", c.Trading.MinNotionalChange.Action)
+	}
+	if c.Trading.MinNotionalChange.BufferPercent < 0 {
+		return fmt.Errorf("trading.min_notional_change.buffer_percent 不能为负数")
+	}
+	if c.Trading.MinNotionalChange.BufferPercent == 0 {
+		c.Trading.MinNotionalChange.BufferPercent = 10
+	}
+
+	// 校验交易额度
+	if budget := c.Trading.TurnoverBudget; budget.Enabled {
+		if err := budget.Default.validate("default"); err != nil {
+			return err
+		}
+		for name, limits := range budget.Strategies {
+			if err := limits.validate("strategies." + name); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 保证金检查默认值
+	if c.Trading.MarginCheck.SafetyBuffer == 0 {
+		c.Trading.MarginCheck.SafetyBuffer = 0.05
+	}
+	if c.Trading.MarginCheck.RefreshSeconds == 0 {
+		c.Trading.MarginCheck.RefreshSeconds = 30
+	}
+	if len(c.Trading.MarginCheck.Tiers) == 0 {
+		c.Trading.MarginCheck.Tiers = []MarginTierConfig{{MaintenanceRate: 0.005}}
+	}
+	if err := c.Trading.MarginCheck.validate(); err != nil {
+		return err
+	}
+
+	// 设置网格锚点默认值
+	if c.Trading.Anchor.Mode == "" {
+		c.Trading.Anchor.Mode = "first_tick"
+	}
+	switch c.Trading.Anchor.Mode {
+	case "first_tick", "vwap", "candle_close", "manual":
+	default:
+		return fmt.Errorf("trading.anchor.mode 必须为 first_tick、vwap、candle_close 或 manual")
+	}
+	if c.Trading.Anchor.VWAPSeconds <= 0 {
+		c.Trading.Anchor.VWAPSeconds = 30
+	}
+	if c.Trading.Anchor.MaxDeviation <= 0 {
+		c.Trading.Anchor.MaxDeviation = 0.1
+	}
+	if c.Trading.Anchor.Price < 0 {
+		return fmt.Errorf("trading.anchor.price 不能为负数")
+	}
+
+	// 设置网格重新居中默认值
+	if c.Trading.Recenter.Policy == "" {
+		c.Trading.Recenter.Policy = "never"
+	}
+	switch c.Trading.Recenter.Policy {
+	case "never", "band", "time":
+	default:
+		return fmt.Errorf("trading.recenter.policy 必须为 never、band 或 time")
+	}
+	if c.Trading.Recenter.BandIntervals <= 0 {
+		c.Trading.Recenter.BandIntervals = c.Trading.BuyWindowSize + c.Trading.SellWindowSize
+		if c.Trading.Recenter.BandIntervals <= 0 {
+			c.Trading.Recenter.BandIntervals = 20
+		}
+	}
+	if c.Trading.Recenter.IntervalMinutes <= 0 {
+		c.Trading.Recenter.IntervalMinutes = 240
+	}
+	if c.Trading.Recenter.MinShift <= 0 {
+		c.Trading.Recenter.MinShift = 1
+	}
+
+	// 设置挂单过期默认值
+	if c.Trading.OrderExpiry.MaxAgeMinutes <= 0 {
+		c.Trading.OrderExpiry.MaxAgeMinutes = 60
+	}
+	for strategyType, minutes := range c.Trading.OrderExpiry.Strategies {
+		if minutes < 0 {
+			return fmt.Errorf("trading.order_expiry.strategies.%s 不能为负数", strategyType)
+		}
+	}
+
+	// 设置订单清理策略默认值
+	switch c.Trading.OrderCleaner.OrphanPolicy {
+	case "":
+		c.Trading.OrderCleaner.OrphanPolicy = "ignore"
+	case "ignore", "bot", "all":
+	default:
+		return fmt.Errorf("trading.order_cleaner.orphan_policy 必须为 ignore、bot 或 all")
+	}
+	if c.Trading.OrderCleaner.OrphanMinAgeMinutes <= 0 {
+		c.Trading.OrderCleaner.OrphanMinAgeMinutes = 10
+	}
+
+	// 设置库存对冲默认值
+	if c.Trading.Hedge.Enabled {
+		if c.Trading.Hedge.Ratio == 0 {
+			c.Trading.Hedge.Ratio = 0.5
+		}
+		if c.Trading.Hedge.Ratio < 0 || c.Trading.Hedge.Ratio > 1 {
+			return fmt.Errorf("trading.hedge.ratio 必须在 0~1 之间")
+		}
+		if c.Trading.Hedge.RebalanceInterval <= 0 {
+			c.Trading.Hedge.RebalanceInterval = 300
+		}
+		if c.Trading.Hedge.MinNotional <= 0 {
+			c.Trading.Hedge.MinNotional = 20
+		}
+		if c.Trading.Hedge.MaxNotional < 0 {
+			return fmt.Errorf("trading.hedge.max_notional 不能为负数")
+		}
+		if c.Trading.Hedge.Exchange != "" && c.Trading.Hedge.Account.APIKey == "" {
+			if _, ok := c.Exchanges[c.Trading.Hedge.Exchange]; !ok {
+				return fmt.Errorf("trading.hedge.exchange %s 未在 exchanges 中配置", c.Trading.Hedge.Exchange)
+			}
+		}
+	}
+
+	// 设置排队位置估算默认值
+	if c.Trading.QueuePosition.MinRestSeconds <= 0 {
+		c.Trading.QueuePosition.MinRestSeconds = 120
+	}
+	if c.Trading.QueuePosition.MaxAheadRatio <= 0 {
+		c.Trading.QueuePosition.MaxAheadRatio = 0.8
+	}
+	if c.Trading.QueuePosition.MinAheadMultiple <= 0 {
+		c.Trading.QueuePosition.MinAheadMultiple = 20
+	}
+	if c.Trading.QueuePosition.ImproveTicks <= 0 {
+		c.Trading.QueuePosition.ImproveTicks = 1
+	}
+
+	// 设置利润金库默认值
+	if c.ProfitVault.Period == "" {
+		c.ProfitVault.Period = "daily"
+	}
+	if c.ProfitVault.SweepRatio <= 0 || c.ProfitVault.SweepRatio > 1 {
+		c.ProfitVault.SweepRatio = 1
+	}
+	if c.ProfitVault.MinTransfer <= 0 {
+		c.ProfitVault.MinTransfer = 10
+	}
+	if c.ProfitVault.Asset == "" {
+		c.ProfitVault.Asset = "USDT"
+	}
+	c.ProfitVault.ToWallet = strings.ToUpper(c.ProfitVault.ToWallet)
+	if c.ProfitVault.ToWallet == "" {
+		c.ProfitVault.ToWallet = "SPOT"
+	}
+	if c.ProfitVault.CheckInterval <= 0 {
+		c.ProfitVault.CheckInterval = 3600
+	}
+	if c.ProfitVault.Enabled {
+		switch c.ProfitVault.Period {
+		case "daily", "weekly", "monthly":
+		default:
+			return fmt.Errorf("profit_vault.period 必须为 daily、weekly 或 monthly")
+		}
+		if c.ProfitVault.ToWallet != "SPOT" && c.ProfitVault.ToWallet != "FUNDING" {
+			return fmt.Errorf("profit_vault.to_wallet 必须为 SPOT 或 FUNDING")
+		}
+		if c.ProfitVault.Threshold < 0 {
+			return fmt.Errorf("profit_vault.threshold 不能为负数")
+		}
+	}
+
+	// 设置资金费率监控默认值
+	if c.FundingMonitor.IntervalHours <= 0 {
+		c.FundingMonitor.IntervalHours = 8
+	}
+
+	// 设置持仓量监控默认值
+	if c.OpenInterestMonitor.IntervalMinutes <= 0 {
+		c.OpenInterestMonitor.IntervalMinutes = 5
+	}
+	spike := &c.OpenInterestMonitor.SpikeRule
+	if spike.ChangePercent <= 0 {
+		spike.ChangePercent = 20
+	}
+	if spike.WindowMinutes <= 0 {
+		spike.WindowMinutes = 60
+	}
+	spike.Action = strings.ToLower(spike.Action)
+	if spike.Action == "" {
+		spike.Action = "notify"
+	}
+	if spike.BuyWindowSize <= 0 {
+		spike.BuyWindowSize = 2
+	}
+	if spike.CooldownMinutes <= 0 {
+		spike.CooldownMinutes = 60
+	}
+	if c.OpenInterestMonitor.Enabled && spike.Enabled {
+		switch spike.Action {
+		case "notify", "shrink_window", "pause":
+		default:
+			return fmt.Errorf("open_interest_monitor.spike_rule.action 必须为 notify、shrink_window 或 pause")
+		}
+		if spike.WindowMinutes < c.OpenInterestMonitor.IntervalMinutes {
+			return fmt.Errorf("open_interest_monitor.spike_rule.window_minutes 不能小于采集间隔 interval_minutes")
+		}
+	}
+
+	// 设置交易日志默认值
+	if len(c.Journal.AutoEvents) == 0 {
+		c.Journal.AutoEvents = []string{
+			"risk_triggered", "stop_loss", "take_profit", "liquidation_risk",
+			"margin_insufficient", "reconciliation_divergence", "grid_recentered", "open_interest_spike",
+			"profit_goal_reached", "daily_loss_limit", "strategy_loss_breaker", "pnl_divergence",
+			"turnover_budget_exhausted", "price_band_violation",
+		}
+	}
+	if c.Journal.CheckInterval <= 0 {
+		c.Journal.CheckInterval = 300
+	}
+	if c.Journal.DrawdownAmount < 0 {
+		return fmt.Errorf("journal.drawdown_amount 不能为负数")
+	}
+
+	// 设置盈利目标默认值
+	c.Goals.OnTarget = strings.ToLower(c.Goals.OnTarget)
+	if c.Goals.OnTarget == "" {
+		c.Goals.OnTarget = "notify"
+	}
+	c.Goals.OnMaxLoss = strings.ToLower(c.Goals.OnMaxLoss)
+	if c.Goals.OnMaxLoss == "" {
+		c.Goals.OnMaxLoss = "notify"
+	}
+	if c.Goals.CheckInterval <= 0 {
+		c.Goals.CheckInterval = 60
+	}
+	if c.Goals.Enabled {
+		for name, action := range map[string]string{"on_target": c.Goals.OnTarget, "on_max_loss": c.Goals.OnMaxLoss} {
+			if action != "notify" && action != "stop_buying" {
+				return fmt.Errorf("goals.%s 必须为 notify 或 stop_buying", name)
+			}
+		}
+		if c.Goals.DailyTarget < 0 || c.Goals.WeeklyTarget < 0 || c.Goals.MaxDailyLoss < 0 {
+			return fmt.Errorf("goals.daily_target、weekly_target、max_daily_loss 不能为负数")
+		}
+	}
+
+	// 设置每日快照默认值
+	if c.DailySnapshot.Time == "" {
+		c.DailySnapshot.Time = "00:05"
+	}
+	if c.DailySnapshot.Dir == "" {
+		c.DailySnapshot.Dir = "./data/snapshots"
+	}
+	if _, err := time.Parse("15:04", c.DailySnapshot.Time); err != nil {
+		return fmt.Errorf("daily_snapshot.time 格式错误（应为 HH:MM）: %s", c.DailySnapshot.Time)
+	}
+	if c.DailySnapshot.RetentionDays < 0 {
+		return fmt.Errorf("daily_snapshot.retention_days 不能为负数")
+	}
+
+	// 设置 HODL 基准对比默认值
+	if c.Benchmark.SampleInterval <= 0 {
+		c.Benchmark.SampleInterval = 300
+	}
+	if c.Benchmark.MaxSamples <= 0 {
+		c.Benchmark.MaxSamples = 2016
+	}
+	if c.Benchmark.StateFile == "" {
+		c.Benchmark.StateFile = "./data/benchmark.json"
+	}
+	if c.Benchmark.InitialCapital < 0 {
+		return fmt.Errorf("benchmark.initial_capital 不能为负数")
+	}
+
+	// 设置定时调度与降杠杆默认值
+	if c.Scheduler.CheckInterval <= 0 {
+		c.Scheduler.CheckInterval = 30
+	}
+	if c.Deleverage.Timezone == "" {
+		c.Deleverage.Timezone = "UTC"
+	}
+	if c.Deleverage.LeadMinutes <= 0 {
+		c.Deleverage.LeadMinutes = 60
+	}
+	if c.Deleverage.Enabled {
+		if _, err := time.LoadLocation(c.Deleverage.Timezone); err != nil {
+			return fmt.Errorf("deleverage.timezone 无效: %w", err)
+		}
+		if len(c.Deleverage.Windows) == 0 {
+			return fmt.Errorf("deleverage.windows 至少需要一个时段")
+		}
+		if c.Deleverage.BuyWindowSize < 0 || c.Deleverage.SellWindowSize < 0 || c.Deleverage.MaxGridLayers < 0 {
+			return fmt.Errorf("deleverage 的窗口和层数上限不能为负数")
+		}
+		for i, w := range c.Deleverage.Windows {
+			if w.IsWeekly() {
+				startDay, startMinute, endDay, endMinute, err := w.ParseWeekly()
+				if err != nil {
+					return fmt.Errorf("deleverage.windows[%d]: %w", i, err)
+				}
+				if startDay == endDay && startMinute == endMinute {
+					return fmt.Errorf("deleverage.windows[%d] 开始和结束时间不能相同", i)
+				}
+				continue
+			}
+			start, end, err := w.ParseFixed()
+			if err != nil {
+				return fmt.Errorf("deleverage.windows[%d] 需配置 start_day/end_day 或 RFC3339 格式的 start/end: %w", i, err)
+			}
+			if !end.After(start) {
+				return fmt.Errorf("deleverage.windows[%d] 结束时间必须晚于开始时间", i)
+			}
+		}
+	}
+
+	// 设置账户流水同步默认值
+	if c.IncomeSync.IntervalMinutes <= 0 {
+		c.IncomeSync.IntervalMinutes = 60
+	}
+	if c.IncomeSync.LookbackDays <= 0 {
+		c.IncomeSync.LookbackDays = 30
+	}
+	if c.IncomeSync.ReportHours <= 0 {
+		c.IncomeSync.ReportHours = 24
+	}
+	if c.IncomeSync.ToleranceAbs == 0 {
+		c.IncomeSync.ToleranceAbs = 1
+	}
+	if c.IncomeSync.TolerancePercent == 0 {
+		c.IncomeSync.TolerancePercent = 5
+	}
+	if c.IncomeSync.Enabled {
+		if c.IncomeSync.ToleranceAbs < 0 || c.IncomeSync.TolerancePercent < 0 {
+			return fmt.Errorf("income_sync 的对账容差不能为负数")
+		}
+		if c.IncomeSync.LookbackDays > 90 {
+			return fmt.Errorf("income_sync.lookback_days 不能超过 90（交易所仅保留近 3 个月流水）")
+		}
+	}
+
+	// 设置 K 线缺口补齐默认值
+	if len(c.KlineBackfill.Intervals) == 0 {
+		c.KlineBackfill.Intervals = []string{"1m", "1h"}
+	}
+	if c.KlineBackfill.LookbackHours <= 0 {
+		c.KlineBackfill.LookbackHours = 24
+	}
+	if c.KlineBackfill.PollMinutes <= 0 {
+		c.KlineBackfill.PollMinutes = 5
+	}
+	if c.KlineBackfill.MaxFetch <= 0 {
+		c.KlineBackfill.MaxFetch = 1000
+	}
+	if c.KlineBackfill.Enabled {
+		for _, interval := range c.KlineBackfill.Intervals {
+			if _, ok := utils.KlineIntervalDuration(interval); !ok {
+				return fmt.Errorf("kline_backfill.intervals 不支持周期 %q（支持 1m~12h 和 1d）", interval)
+			}
+		}
+		if c.KlineBackfill.LookbackHours > 720 {
+			return fmt.Errorf("kline_backfill.lookback_hours 不能超过 720")
+		}
+	}
+
+	// 设置维护窗口默认值
+	if c.Maintenance.CheckInterval <= 0 {
+		c.Maintenance.CheckInterval = 60
+	}
+	if c.Maintenance.PreWindowMinutes <= 0 {
+		c.Maintenance.PreWindowMinutes = 1
+	}
+	for i, w := range c.Maintenance.Windows {
+		if w.Exchange == "" {
+			return fmt.Errorf("maintenance.windows[%d] 必须指定 exchange", i)
+		}
+		start, end, err := w.Parse()
+		if err != nil {
+			return fmt.Errorf("maintenance.windows[%d] 时间格式错误（需 RFC3339，如 2026-01-01T08:00:00Z）: %w", i, err)
+		}
+		if !end.After(start) {
+			return fmt.Errorf("maintenance.windows[%d] 结束时间必须晚于开始时间", i)
+		}
+	}
+
+	// 混沌测试：环境变量优先于配置文件
+	if v := os.Getenv("QUANTMESH_CHAOS"); v != "" {
+		c.Chaos.Enabled = v == "1" || strings.EqualFold(v, "true")
+	}
+	if c.Chaos.RESTTimeoutDelayMs <= 0 {
+		c.Chaos.RESTTimeoutDelayMs = 1000 // 默认1秒
+	}
+	if c.Chaos.WSDisconnectSec <= 0 {
+		c.Chaos.WSDisconnectSec = 10 // 默认断线10秒
+	}
+	if c.Chaos.FillDelayMaxMs <= 0 {
+		c.Chaos.FillDelayMaxMs = 3000 // 默认最多延迟3秒
+	}
+	for name, rate := range map[string]float64{
+		"rest_timeout_rate":  c.Chaos.RESTTimeoutRate,
+		"ws_disconnect_rate": c.Chaos.WSDisconnectRate,
+		"fill_delay_rate":    c.Chaos.FillDelayRate,
+		"reorder_rate":       c.Chaos.ReorderRate,
+	} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("chaos.%s 必须在 0~1 之间", name)
+		}
+	}
+
+	// 行情录制与回放
+	if c.MarketData.Record.Dir == "" {
+		c.MarketData.Record.Dir = "./data/recordings"
+	}
+	if c.MarketData.Replay.Speed == 0 {
+		c.MarketData.Replay.Speed = 1
+	}
+	if c.MarketData.Replay.WarmupSec <= 0 {
+		c.MarketData.Replay.WarmupSec = 5
+	}
+	if c.MarketData.Replay.Balance <= 0 {
+		c.MarketData.Replay.Balance = 10000
+	}
+	if c.App.CurrentExchange == ReplayExchangeName && c.MarketData.Replay.File == "" {
+		return fmt.Errorf("使用回放交易所时必须指定 market_data.replay.file")
+	}
+
+	// 模拟执行
+	if err := c.Simulation.Default.validate("simulation.default"); err != nil {
+		return err
+	}
+	for name, sim := range c.Simulation.Exchanges {
+		if err := sim.validate("simulation.exchanges." + name); err != nil {
+			return err
+		}
+		c.Simulation.Exchanges[name] = sim
+	}
+	if venue := c.MarketData.Replay.Venue; venue != "" {
+		if _, ok := c.Simulation.Exchanges[venue]; !ok {
+			return fmt.Errorf("market_data.replay.venue %s 未在 simulation.exchanges 中配置", venue)
+		}
+	}
+
+	// AI 模块调度
+	for module, schedule := range c.AI.Scheduling {
+		if !IsAIModule(module) {
+			return fmt.Errorf("ai.scheduling 包含未知模块 %s（可选: %s）", module, strings.Join(AIModules, ", "))
+		}
+		if err := schedule.Validate(); err != nil {
+			return fmt.Errorf("ai.scheduling.%s: %w", module, err)
+		}
+		c.AI.Scheduling[module] = schedule
+	}
+
+	// 情绪分析数据源
+	sentiment := &c.AI.Modules.SentimentAnalysis
+	if sentiment.DataSources.Twitter.Enabled && sentiment.DataSources.Twitter.BearerToken == "" {
+		return fmt.Errorf("启用 ai.modules.sentiment_analysis.data_sources.twitter 时必须配置 bearer_token")
+	}
+	if n := sentiment.DataSources.Twitter.MaxResults; n != 0 && (n < 10 || n > 100) {
+		return fmt.Errorf("ai.modules.sentiment_analysis.data_sources.twitter.max_results 必须在 10~100 之间")
+	}
+	if sentiment.DataSources.Telegram.Enabled && len(sentiment.DataSources.Telegram.Channels) == 0 {
+		return fmt.Errorf("启用 ai.modules.sentiment_analysis.data_sources.telegram 时必须配置 channels")
+	}
+	if g := c.AI.Guardrails; g.MaxLeverage < 0 || g.MaxOrderSize < 0 || g.MaxWindowSize < 0 {
+		return fmt.Errorf("ai.guardrails 的上限不能为负数")
+	}
+	if r := sentiment.DataSources.Filter.MinRelevance; r < 0 || r > 1 {
+		return fmt.Errorf("ai.modules.sentiment_analysis.data_sources.filter.min_relevance 必须在 0~1 之间")
+	}
+	if onChain := &sentiment.DataSources.OnChain; onChain.Enabled {
+		onChain.Provider = strings.ToLower(onChain.Provider)
+		if onChain.Provider == "" {
+			onChain.Provider = "glassnode"
+		}
+		switch onChain.Provider {
+		case "glassnode":
+		case "dune":
+			q := onChain.DuneQueries
+			if q.Netflow == 0 && q.LargeTransfers == 0 && q.StablecoinSupply == 0 {
+				return fmt.Errorf("ai.modules.sentiment_analysis.data_sources.on_chain 使用 dune 时至少需要配置一个 dune_queries")
+			}
+		default:
+			return fmt.Errorf("ai.modules.sentiment_analysis.data_sources.on_chain.provider 必须为 glassnode 或 dune")
+		}
+		if onChain.APIKey == "" {
+			return fmt.Errorf("启用 ai.modules.sentiment_analysis.data_sources.on_chain 时必须配置 api_key")
+		}
+		for key := range onChain.Metrics {
+			switch key {
+			case "exchange_netflow", "large_transfers", "stablecoin_supply":
+			default:
+				return fmt.Errorf("ai.modules.sentiment_analysis.data_sources.on_chain.metrics 不支持指标 %s", key)
+			}
+		}
+	}
+	for source, weight := range sentiment.SourceWeights {
+		switch source {
+		case "news", "reddit", "twitter", "telegram", "fear_greed":
+		default:
+			return fmt.Errorf("ai.modules.sentiment_analysis.source_weights 不支持数据源 %s", source)
+		}
+		if weight < 0 {
+			return fmt.Errorf("ai.modules.sentiment_analysis.source_weights.%s 不能为负数", source)
+		}
+	}
+
 	return nil
 }
+
+// EndpointLimitConfig 单个接口的限流与响应缓存配置
+type EndpointLimitConfig struct {
+	RatePerMinute int `yaml:"rate_per_minute"` // 每个客户端每分钟请求上限，0 表示不限流
+	Burst         int `yaml:"burst"`           // 突发请求数，默认 rate_per_minute/6（至少 3）
+	CacheTTL      int `yaml:"cache_ttl"`       // 响应缓存时间（秒），0 表示不缓存
+}
+
+// DefaultEndpointLimits 默认受限的接口：K线和相关性分析会请求交易所，市场情报会聚合多个外部数据源，日志查询会扫描日志库，盈亏归因会扫描交易记录，数据库导出会复制整个数据库
+func DefaultEndpointLimits() map[string]EndpointLimitConfig {
+	return map[string]EndpointLimitConfig{
+		"/api/klines":                {RatePerMinute: 60, CacheTTL: 5},
+		"/api/market-intelligence":   {RatePerMinute: 20, CacheTTL: 30},
+		"/api/logs":                  {RatePerMinute: 60, CacheTTL: 2},
+		"/api/analytics/correlation": {RatePerMinute: 20, CacheTTL: 60},
+		"/api/analytics/attribution": {RatePerMinute: 20, CacheTTL: 60},
+		"/api/export/database":       {RatePerMinute: 2, Burst: 1},
+	}
+}
+
+// normalizeBasePath 规范化 Web 子路径：以 / 开头、不以 / 结尾，根路径返回空字符串
+func normalizeBasePath(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	p = strings.TrimRight(p, "/")
+	if p == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	if strings.ContainsAny(p, "?#\\ ") || strings.Contains(p, "..") || strings.Contains(p, "//") {
+		return "", fmt.Errorf("web.base_path 无效: %q", p)
+	}
+	return p, nil
+}
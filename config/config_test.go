@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func createValidConfig() *Config {
@@ -161,3 +162,423 @@ func TestConfigBackup(t *testing.T) {
 		}
 	}
 }
+
+func TestRiskProfiles(t *testing.T) {
+	// 引用不存在的档位应报错
+	cfg := createValidConfig()
+	cfg.Trading.Symbols = []SymbolConfig{{Exchange: "binance", Symbol: "BTCUSDT", PriceInterval: 10, OrderQuantity: 30, RiskProfile: "unknown"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("不存在的风控档位应该报错")
+	}
+
+	// 自定义档位覆盖内置档位
+	cfg = createValidConfig()
+	cfg.RiskProfiles = map[string]RiskProfile{
+		RiskProfileConservative: {MaxLeverage: 2, StopLossRatio: 0.03},
+	}
+	cfg.Trading.Symbols = []SymbolConfig{{Exchange: "binance", Symbol: "BTCUSDT", PriceInterval: 10, OrderQuantity: 30, RiskProfile: RiskProfileConservative}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("有效的风控档位验证失败: %v", err)
+	}
+	profile, ok := cfg.LookupRiskProfile(RiskProfileConservative)
+	if !ok || profile.MaxLeverage != 2 {
+		t.Fatalf("自定义档位未覆盖内置档位: %+v", profile)
+	}
+
+	// 应用档位：数值为 0 的字段保留原配置
+	ApplyRiskProfile(cfg, profile)
+	if cfg.RiskControl.MaxLeverage != 2 || cfg.Trading.BuyWindowSize != 10 {
+		t.Errorf("档位应用结果错误: 杠杆 %d, 买单窗口 %d", cfg.RiskControl.MaxLeverage, cfg.Trading.BuyWindowSize)
+	}
+	if !cfg.Trading.GridRiskControl.Enabled || cfg.Trading.GridRiskControl.StopLossRatio != 0.03 {
+		t.Errorf("档位应启用网格风控: %+v", cfg.Trading.GridRiskControl)
+	}
+
+	balanced, _ := cfg.LookupRiskProfile(RiskProfileBalanced)
+	ApplyRiskProfile(cfg, balanced)
+	if cfg.Trading.BuyWindowSize != balanced.BuyWindowSize || cfg.RiskControl.MaxLeverage != balanced.MaxLeverage {
+		t.Errorf("切换档位未生效: 杠杆 %d, 买单窗口 %d", cfg.RiskControl.MaxLeverage, cfg.Trading.BuyWindowSize)
+	}
+}
+
+// createValidWebConfig 可通过校验的最小配置
+func createValidWebConfig() *Config {
+	cfg := createValidConfig()
+	cfg.Trading.PriceInterval = 10
+	return cfg
+}
+
+func TestWebReverseProxyConfig(t *testing.T) {
+	cases := map[string]string{
+		"":            "",
+		"/":           "",
+		"quantmesh":   "/quantmesh",
+		"/quantmesh/": "/quantmesh",
+		"/a/b":        "/a/b",
+	}
+	for in, want := range cases {
+		cfg := createValidWebConfig()
+		cfg.Web.BasePath = in
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("base_path %q 验证失败: %v", in, err)
+		}
+		if cfg.Web.BasePath != want {
+			t.Errorf("base_path %q 规范化为 %q, 期望 %q", in, cfg.Web.BasePath, want)
+		}
+	}
+
+	cfg := createValidWebConfig()
+	cfg.Web.BasePath = "/../etc"
+	if err := cfg.Validate(); err == nil {
+		t.Error("包含 .. 的 base_path 应该报错")
+	}
+
+	// 默认只信任本机代理
+	cfg = createValidWebConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Web.TrustedProxies) != 2 || cfg.Web.TrustedProxies[0] != "127.0.0.1" {
+		t.Errorf("默认可信代理错误: %v", cfg.Web.TrustedProxies)
+	}
+
+	// 显式配置为空列表时不信任任何代理
+	cfg = createValidWebConfig()
+	cfg.Web.TrustedProxies = []string{}
+	if err := cfg.Validate(); err != nil || len(cfg.Web.TrustedProxies) != 0 {
+		t.Errorf("空列表应保留: %v, %v", cfg.Web.TrustedProxies, err)
+	}
+
+	cfg = createValidWebConfig()
+	cfg.Web.TrustedProxies = []string{"10.0.0.0/8", "nginx"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("无效的可信代理应该报错")
+	}
+}
+
+func TestSentimentDataSourceConfig(t *testing.T) {
+	cfg := createValidWebConfig()
+	cfg.AI.Modules.SentimentAnalysis.DataSources.Twitter.Enabled = true
+	if err := cfg.Validate(); err == nil {
+		t.Error("启用 twitter 但未配置 bearer_token 应该报错")
+	}
+	cfg.AI.Modules.SentimentAnalysis.DataSources.Twitter.BearerToken = "token"
+	cfg.AI.Modules.SentimentAnalysis.DataSources.Twitter.MaxResults = 5
+	if err := cfg.Validate(); err == nil {
+		t.Error("max_results 小于 10 应该报错")
+	}
+	cfg.AI.Modules.SentimentAnalysis.DataSources.Twitter.MaxResults = 50
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("有效的 twitter 配置验证失败: %v", err)
+	}
+
+	cfg = createValidWebConfig()
+	cfg.AI.Modules.SentimentAnalysis.DataSources.Telegram.Enabled = true
+	if err := cfg.Validate(); err == nil {
+		t.Error("启用 telegram 但未配置 channels 应该报错")
+	}
+
+	cfg = createValidWebConfig()
+	cfg.AI.Modules.SentimentAnalysis.SourceWeights = map[string]float64{"news": 1, "discord": 1}
+	if err := cfg.Validate(); err == nil {
+		t.Error("未知数据源权重应该报错")
+	}
+	cfg.AI.Modules.SentimentAnalysis.SourceWeights = map[string]float64{"news": -1}
+	if err := cfg.Validate(); err == nil {
+		t.Error("负权重应该报错")
+	}
+}
+
+func TestOnChainDataSourceConfig(t *testing.T) {
+	cfg := createValidWebConfig()
+	onChain := &cfg.AI.Modules.SentimentAnalysis.DataSources.OnChain
+	onChain.Enabled = true
+	if err := cfg.Validate(); err == nil {
+		t.Error("启用链上数据但未配置 api_key 应该报错")
+	}
+	onChain.APIKey = "key"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("glassnode 配置验证失败: %v", err)
+	}
+	if onChain.Provider != "glassnode" {
+		t.Errorf("默认 provider 应为 glassnode, 实际 %s", onChain.Provider)
+	}
+
+	onChain.Provider = "Dune"
+	if err := cfg.Validate(); err == nil {
+		t.Error("dune 未配置查询 ID 应该报错")
+	}
+	onChain.DuneQueries.Netflow = 123
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("dune 配置验证失败: %v", err)
+	}
+
+	onChain.Provider = "nansen"
+	if err := cfg.Validate(); err == nil {
+		t.Error("不支持的 provider 应该报错")
+	}
+
+	onChain.Provider = "glassnode"
+	onChain.Metrics = map[string]string{"mvrv": "market/mvrv"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("不支持的指标应该报错")
+	}
+}
+
+func TestOpenInterestMonitorConfig(t *testing.T) {
+	cfg := createValidWebConfig()
+	oi := &cfg.OpenInterestMonitor
+	oi.Enabled = true
+	oi.SpikeRule.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("默认持仓量监控配置验证失败: %v", err)
+	}
+	if oi.IntervalMinutes != 5 || oi.SpikeRule.ChangePercent != 20 || oi.SpikeRule.WindowMinutes != 60 || oi.SpikeRule.Action != "notify" {
+		t.Errorf("默认值设置错误: %+v", *oi)
+	}
+
+	oi.SpikeRule.Action = "Shrink_Window"
+	if err := cfg.Validate(); err != nil || oi.SpikeRule.Action != "shrink_window" {
+		t.Errorf("action 应忽略大小写: action=%s, err=%v", oi.SpikeRule.Action, err)
+	}
+
+	oi.SpikeRule.Action = "close_all"
+	if err := cfg.Validate(); err == nil {
+		t.Error("不支持的 action 应该报错")
+	}
+
+	oi.SpikeRule.Action = "pause"
+	oi.IntervalMinutes = 30
+	oi.SpikeRule.WindowMinutes = 15
+	if err := cfg.Validate(); err == nil {
+		t.Error("比较窗口小于采集间隔应该报错")
+	}
+}
+
+func TestLevelsConfig(t *testing.T) {
+	cfg := createValidWebConfig()
+	cfg.Trading.Levels.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("默认支撑/阻力位配置验证失败: %v", err)
+	}
+	levels := cfg.Trading.Levels
+	if levels.Interval != "1h" || levels.PivotWindow != 3 || levels.GridBias.ZonePercent != 0.5 || levels.GridBias.MinTouches != 2 {
+		t.Errorf("默认值设置错误: %+v", levels)
+	}
+
+	cfg.Trading.Levels.Lookback = 5
+	if err := cfg.Validate(); err == nil {
+		t.Error("lookback 小于 pivot_window*2+1 应该报错")
+	}
+}
+
+func TestGoalsConfig(t *testing.T) {
+	cfg := createValidWebConfig()
+	cfg.Goals.Enabled = true
+	cfg.Goals.DailyTarget = 50
+	cfg.Goals.OnMaxLoss = "STOP_BUYING"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("盈利目标配置验证失败: %v", err)
+	}
+	if cfg.Goals.OnTarget != "notify" || cfg.Goals.OnMaxLoss != "stop_buying" || cfg.Goals.CheckInterval != 60 {
+		t.Errorf("默认值设置错误: %+v", cfg.Goals)
+	}
+
+	cfg.Goals.OnTarget = "close_all"
+	if err := cfg.Validate(); err == nil {
+		t.Error("未知的 on_target 动作应该报错")
+	}
+	cfg.Goals.OnTarget = "notify"
+	cfg.Goals.MaxDailyLoss = -10
+	if err := cfg.Validate(); err == nil {
+		t.Error("max_daily_loss 为负数应该报错")
+	}
+}
+
+func TestVolatilityGuardConfig(t *testing.T) {
+	cfg := createValidWebConfig()
+	cfg.Trading.VolatilityGuard.Enabled = true
+	cfg.Trading.VolatilityGuard.ThresholdPercent = 2
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("高波动保护配置验证失败: %v", err)
+	}
+	guard := cfg.Trading.VolatilityGuard
+	if guard.WindowSeconds != 60 || guard.RecoverPercent != 1.2 || guard.MinActiveSeconds != 120 || guard.OffsetIntervals != 1 {
+		t.Errorf("默认值设置错误: %+v", guard)
+	}
+
+	cfg.Trading.VolatilityGuard.RecoverPercent = 3
+	if err := cfg.Validate(); err == nil {
+		t.Error("recover_percent 大于 threshold_percent 应该报错")
+	}
+}
+
+func TestReduceOnlyGuardConfig(t *testing.T) {
+	cfg := createValidWebConfig()
+	cfg.Trading.ReduceOnlyGuard.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("只减仓检查配置验证失败: %v", err)
+	}
+	if cfg.Trading.ReduceOnlyGuard.RefreshMillis != 2000 {
+		t.Errorf("refresh_millis 默认值错误: %d", cfg.Trading.ReduceOnlyGuard.RefreshMillis)
+	}
+}
+
+func TestStrategyLossBreakerConfig(t *testing.T) {
+	cfg := createValidWebConfig()
+	cfg.Strategies.LossBreaker.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("策略亏损熔断配置验证失败: %v", err)
+	}
+	breaker := cfg.Strategies.LossBreaker
+	if breaker.MaxConsecutiveLosses != 3 || breaker.WindowMinutes != 1440 || breaker.CooldownMinutes != 60 || breaker.CheckInterval != 10 {
+		t.Errorf("默认值设置错误: %+v", breaker)
+	}
+
+	cfg.Strategies.LossBreaker.MaxLossPercent = 120
+	if err := cfg.Validate(); err == nil {
+		t.Error("max_loss_percent 超过 100 应该报错")
+	}
+}
+
+func TestDeleverageConfig(t *testing.T) {
+	cfg := createValidWebConfig()
+	cfg.Deleverage.Enabled = true
+	cfg.Deleverage.Windows = []ScheduleWindowConfig{
+		{StartDay: "fri", StartTime: "20:00", EndDay: "Monday", EndTime: "00:30"},
+		{Start: "2026-12-24T00:00:00Z", End: "2026-12-26T00:00:00Z"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("降杠杆配置验证失败: %v", err)
+	}
+	if cfg.Deleverage.Timezone != "UTC" || cfg.Deleverage.LeadMinutes != 60 || cfg.Scheduler.CheckInterval != 30 {
+		t.Errorf("默认值设置错误: timezone=%s lead=%d interval=%d",
+			cfg.Deleverage.Timezone, cfg.Deleverage.LeadMinutes, cfg.Scheduler.CheckInterval)
+	}
+	startDay, startMinute, endDay, endMinute, err := cfg.Deleverage.Windows[0].ParseWeekly()
+	if err != nil || startDay != time.Friday || startMinute != 20*60 || endDay != time.Monday || endMinute != 30 {
+		t.Errorf("周循环时段解析错误: %v %d %v %d %v", startDay, startMinute, endDay, endMinute, err)
+	}
+
+	cfg.Deleverage.Windows = []ScheduleWindowConfig{{StartDay: "fri", StartTime: "25:00", EndDay: "mon"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("无效的时间应该报错")
+	}
+	cfg.Deleverage.Windows = []ScheduleWindowConfig{{Start: "2026-12-26T00:00:00Z", End: "2026-12-24T00:00:00Z"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("结束早于开始应该报错")
+	}
+}
+
+func TestIncomeSyncConfig(t *testing.T) {
+	cfg := createValidWebConfig()
+	cfg.IncomeSync.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("账户流水同步配置验证失败: %v", err)
+	}
+	sync := cfg.IncomeSync
+	if sync.IntervalMinutes != 60 || sync.LookbackDays != 30 || sync.ReportHours != 24 || sync.ToleranceAbs != 1 || sync.TolerancePercent != 5 {
+		t.Errorf("默认值设置错误: %+v", sync)
+	}
+
+	cfg.IncomeSync.TolerancePercent = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("负数容差应该报错")
+	}
+	cfg.IncomeSync.TolerancePercent = 5
+	cfg.IncomeSync.LookbackDays = 120
+	if err := cfg.Validate(); err == nil {
+		t.Error("回溯天数超过 90 应该报错")
+	}
+}
+
+func TestContractTypeConfig(t *testing.T) {
+	cfg := createValidWebConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("配置验证失败: %v", err)
+	}
+	if cfg.Trading.Symbols[0].ContractType != "linear" || cfg.Trading.ContractType != "linear" {
+		t.Errorf("默认应为 U 本位: %q", cfg.Trading.Symbols[0].ContractType)
+	}
+
+	cfg = createValidWebConfig()
+	cfg.Trading.ContractType = "Inverse"
+	if err := cfg.Validate(); err == nil {
+		t.Error("币本位未配置合约面值应该报错")
+	}
+	cfg = createValidWebConfig()
+	cfg.Trading.ContractType = "Inverse"
+	cfg.Trading.ContractSize = 100
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("币本位配置验证失败: %v", err)
+	}
+	if sc := cfg.Trading.Symbols[0]; sc.ContractType != "inverse" || sc.ContractSize != 100 {
+		t.Errorf("交易对应继承全局合约类型与面值: %+v", sc)
+	}
+
+	cfg.Trading.Symbols[0].ContractType = "quanto"
+	if err := cfg.Validate(); err == nil {
+		t.Error("未知的合约类型应该报错")
+	}
+}
+
+func TestShutdownPolicyConfig(t *testing.T) {
+	cfg := createValidWebConfig()
+	cfg.System.CancelOnExit = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("配置验证失败: %v", err)
+	}
+	if p := cfg.ShutdownPolicy("grid"); p != ShutdownPolicyCancelAll {
+		t.Errorf("未配置时应按 cancel_on_exit 推导: %s", p)
+	}
+	cfg.System.ClosePositionsOnExit = true
+	if p := cfg.ShutdownPolicy("grid"); p != ShutdownPolicyFlatten {
+		t.Errorf("close_positions_on_exit 应推导为 flatten: %s", p)
+	}
+
+	cfg.System.Shutdown = ShutdownPolicyConfig{
+		Default:    ShutdownPolicyLeave,
+		Strategies: map[string]string{"dca_enhanced": ShutdownPolicyCancelBuys},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("配置验证失败: %v", err)
+	}
+	if cfg.ShutdownPolicy("grid") != ShutdownPolicyLeave || cfg.ShutdownPolicy("dca_enhanced") != ShutdownPolicyCancelBuys {
+		t.Errorf("按策略的退出方式未生效: grid=%s dca_enhanced=%s", cfg.ShutdownPolicy("grid"), cfg.ShutdownPolicy("dca_enhanced"))
+	}
+
+	cfg.System.Shutdown.Strategies["grid"] = "sell_all"
+	if err := cfg.Validate(); err == nil {
+		t.Error("无效的退出方式应该报错")
+	}
+}
+
+func TestStandbyConfig(t *testing.T) {
+	cfg := createValidWebConfig()
+	cfg.Standby.Role = "standby"
+	cfg.Standby.Token = "secret"
+	cfg.Standby.PrimaryURL = "ws://10.0.0.1:28890/replication"
+	if err := cfg.Validate(); err == nil {
+		t.Error("standby 未启用 distributed_lock 应该报错")
+	}
+
+	cfg.DistributedLock.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("热备配置验证失败: %v", err)
+	}
+	if cfg.Standby.SnapshotInterval != 2 || cfg.Standby.TakeoverAfter != 10 {
+		t.Errorf("默认值设置错误: %+v", cfg.Standby)
+	}
+
+	cfg.InstanceLock.Mode = "off"
+	if err := cfg.Validate(); err == nil {
+		t.Error("standby 关闭实例锁应该报错")
+	}
+
+	primary := createValidWebConfig()
+	primary.Standby.Role = "primary"
+	primary.Standby.Token = "secret"
+	if err := primary.Validate(); err == nil {
+		t.Error("primary 未启用 distributed_lock 应该报错")
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// 内置风控档位
+const (
+	RiskProfileConservative = "conservative"
+	RiskProfileBalanced     = "balanced"
+	RiskProfileAggressive   = "aggressive"
+)
+
+// RiskProfile 风控档位（杠杆上限、挂单窗口、回撤限制、AI 影响范围的组合预设）
+// 数值字段为 0 时保留交易对自身配置
+type RiskProfile struct {
+	Description    string  `yaml:"description" json:"description"`
+	MaxLeverage    int     `yaml:"max_leverage" json:"max_leverage"`         // 最大允许杠杆倍数
+	BuyWindowSize  int     `yaml:"buy_window_size" json:"buy_window_size"`   // 买单窗口
+	SellWindowSize int     `yaml:"sell_window_size" json:"sell_window_size"` // 卖单窗口
+	MaxGridLayers  int     `yaml:"max_grid_layers" json:"max_grid_layers"`   // 最大买入层数
+	StopLossRatio  float64 `yaml:"stop_loss_ratio" json:"stop_loss_ratio"`   // 单币种最大浮亏比例
+	TrendFilter    bool    `yaml:"trend_filter" json:"trend_filter"`         // 是否开启趋势过滤
+	DisableAI      bool    `yaml:"disable_ai" json:"disable_ai"`             // 禁止 AI 生成的参数覆盖该交易对
+}
+
+// BuiltinRiskProfiles 内置风控档位，可在 risk_profiles 中用同名配置覆盖
+func BuiltinRiskProfiles() map[string]RiskProfile {
+	return map[string]RiskProfile{
+		RiskProfileConservative: {
+			Description:    "保守：低杠杆、小窗口、严格止损，AI 仅提供建议",
+			MaxLeverage:    3,
+			BuyWindowSize:  5,
+			SellWindowSize: 5,
+			MaxGridLayers:  10,
+			StopLossRatio:  0.05,
+			TrendFilter:    true,
+			DisableAI:      true,
+		},
+		RiskProfileBalanced: {
+			Description:    "均衡：中等杠杆和窗口，开启趋势过滤",
+			MaxLeverage:    5,
+			BuyWindowSize:  10,
+			SellWindowSize: 10,
+			MaxGridLayers:  20,
+			StopLossRatio:  0.1,
+			TrendFilter:    true,
+		},
+		RiskProfileAggressive: {
+			Description:    "激进：高杠杆、大窗口，回撤容忍度高",
+			MaxLeverage:    10,
+			BuyWindowSize:  20,
+			SellWindowSize: 20,
+			MaxGridLayers:  40,
+			StopLossRatio:  0.2,
+		},
+	}
+}
+
+// AllRiskProfiles 返回全部可用档位（内置 + 自定义，自定义同名覆盖内置）
+func (c *Config) AllRiskProfiles() map[string]RiskProfile {
+	profiles := BuiltinRiskProfiles()
+	for name, p := range c.RiskProfiles {
+		profiles[name] = p
+	}
+	return profiles
+}
+
+// LookupRiskProfile 按名称查找档位
+func (c *Config) LookupRiskProfile(name string) (RiskProfile, bool) {
+	if name == "" {
+		return RiskProfile{}, false
+	}
+	if p, ok := c.RiskProfiles[name]; ok {
+		return p, true
+	}
+	p, ok := BuiltinRiskProfiles()[name]
+	return p, ok
+}
+
+// RiskProfileNames 返回排序后的档位名称
+func (c *Config) RiskProfileNames() []string {
+	profiles := c.AllRiskProfiles()
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyRiskProfile 将档位应用到单交易对的运行时配置
+func ApplyRiskProfile(cfg *Config, p RiskProfile) {
+	if p.MaxLeverage > 0 {
+		cfg.RiskControl.MaxLeverage = p.MaxLeverage
+	}
+	if p.BuyWindowSize > 0 {
+		cfg.Trading.BuyWindowSize = p.BuyWindowSize
+	}
+	if p.SellWindowSize > 0 {
+		cfg.Trading.SellWindowSize = p.SellWindowSize
+	}
+
+	grc := &cfg.Trading.GridRiskControl
+	if p.MaxGridLayers > 0 {
+		grc.MaxGridLayers = p.MaxGridLayers
+	}
+	if p.StopLossRatio > 0 {
+		grc.StopLossRatio = p.StopLossRatio
+	}
+	grc.TrendFilterEnabled = p.TrendFilter
+	if p.MaxGridLayers > 0 || p.StopLossRatio > 0 {
+		grc.Enabled = true
+	}
+}
+
+// validateRiskProfiles 校验自定义档位及交易对引用
+func (c *Config) validateRiskProfiles() error {
+	for name, p := range c.RiskProfiles {
+		if p.MaxLeverage < 0 || p.BuyWindowSize < 0 || p.SellWindowSize < 0 || p.MaxGridLayers < 0 {
+			return fmt.Errorf("风控档位 %s 的数值不能为负数", name)
+		}
+		if p.StopLossRatio < 0 || p.StopLossRatio >= 1 {
+			return fmt.Errorf("风控档位 %s 的 stop_loss_ratio 必须在 0-1 之间", name)
+		}
+	}
+	for _, sc := range c.Trading.Symbols {
+		if sc.RiskProfile == "" {
+			continue
+		}
+		if _, ok := c.LookupRiskProfile(sc.RiskProfile); !ok {
+			return fmt.Errorf("交易对 %s 的风控档位 %s 不存在（可选: %v）", sc.Symbol, sc.RiskProfile, c.RiskProfileNames())
+		}
+	}
+	return nil
+}
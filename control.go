@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+	"quantmesh/utils"
+)
+
+// 本地控制通道：headless 模式下 CLI 通过控制目录驱动运行中的实例
+// CLI 把命令写入 control_dir/commands/<id>.json，实例执行后把结果写入 control_dir/results/<id>.json
+// 只依赖本地文件系统，不监听任何端口；目录权限 0700，只有运行实例的用户可以读写
+
+// 控制命令
+const (
+	controlActionStatus = "status" // 查看各交易对状态
+	controlActionPause  = "pause"  // 暂停交易（保留挂单）
+	controlActionResume = "resume" // 恢复交易
+	controlActionCancel = "cancel" // 暂停交易并撤销挂单
+)
+
+// controlResultTTL 结果文件保留时间（CLI 超时后未读取的结果由实例清理）
+const controlResultTTL = 10 * time.Minute
+
+// controlRequest 控制命令（exchange/symbol 为空表示全部交易对）
+type controlRequest struct {
+	ID        string    `json:"id"`
+	Action    string    `json:"action"`
+	Exchange  string    `json:"exchange,omitempty"`
+	Symbol    string    `json:"symbol,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// controlSymbolStatus 单个交易对的状态
+type controlSymbolStatus struct {
+	Exchange      string   `json:"exchange"`
+	Symbol        string   `json:"symbol"`
+	Paused        bool     `json:"paused"`
+	BuyHalts      []string `json:"buy_halts,omitempty"`
+	LastPrice     float64  `json:"last_price"`
+	Position      float64  `json:"position"`
+	AvgEntryPrice float64  `json:"avg_entry_price"`
+	ActiveLayers  int      `json:"active_layers"`
+	Error         string   `json:"error,omitempty"` // 本次命令在该交易对上的错误（如撤单失败）
+}
+
+// controlResponse 命令执行结果
+type controlResponse struct {
+	ID         string                `json:"id"`
+	OK         bool                  `json:"ok"`
+	Error      string                `json:"error,omitempty"`
+	Mode       string                `json:"mode"`
+	Version    string                `json:"version"`
+	Symbols    []controlSymbolStatus `json:"symbols"`
+	FinishedAt time.Time             `json:"finished_at"`
+}
+
+// controlExecutor 执行控制命令（与命令的传输方式无关）
+type controlExecutor struct {
+	cfg     *config.Config
+	manager *SymbolManager
+	mode    string
+}
+
+// Execute 执行命令，命令执行后返回受影响交易对的最新状态
+func (e *controlExecutor) Execute(ctx context.Context, req controlRequest) controlResponse {
+	resp := controlResponse{ID: req.ID, Mode: e.mode, Version: Version, Symbols: []controlSymbolStatus{}}
+	runtimes, err := e.selectRuntimes(req.Exchange, req.Symbol)
+	if err == nil {
+		switch req.Action {
+		case controlActionStatus, controlActionPause, controlActionResume, controlActionCancel:
+		default:
+			err = fmt.Errorf("不支持的命令: %s", req.Action)
+		}
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		resp.FinishedAt = time.Now()
+		return resp
+	}
+
+	failed := 0
+	for _, rt := range runtimes {
+		var rtErr error
+		switch req.Action {
+		case controlActionPause:
+			rt.SuperPositionManager.Pause()
+		case controlActionResume:
+			rt.SuperPositionManager.Resume()
+		case controlActionCancel:
+			// 先暂停再撤单，避免仓位管理器立即补挂
+			rt.SuperPositionManager.Pause()
+			cancelCtx, cancel := context.WithTimeout(ctx, time.Duration(e.cfg.System.FastCancelSeconds)*time.Second)
+			rtErr = rt.Exchange.CancelAllOrders(cancelCtx, rt.Config.Symbol)
+			cancel()
+		}
+		if req.Action != controlActionStatus {
+			logger.Info("🖥️ [控制通道] [%s:%s] 执行 %s", rt.Config.Exchange, rt.Config.Symbol, req.Action)
+		}
+		status := runtimeControlStatus(rt)
+		if rtErr != nil {
+			failed++
+			status.Error = rtErr.Error()
+			logger.Error("❌ [控制通道] [%s:%s] %s 失败: %v", rt.Config.Exchange, rt.Config.Symbol, req.Action, rtErr)
+		}
+		resp.Symbols = append(resp.Symbols, status)
+	}
+	if failed > 0 {
+		resp.Error = fmt.Sprintf("%d 个交易对执行失败", failed)
+	}
+	resp.OK = failed == 0
+	resp.FinishedAt = time.Now()
+	return resp
+}
+
+// selectRuntimes 按交易所/交易对筛选运行时（为空表示不筛选），按名称排序
+func (e *controlExecutor) selectRuntimes(exchangeName, symbol string) ([]*SymbolRuntime, error) {
+	var selected []*SymbolRuntime
+	for _, rt := range e.manager.List() {
+		if rt == nil || rt.SuperPositionManager == nil {
+			continue
+		}
+		if exchangeName != "" && !strings.EqualFold(rt.Config.Exchange, exchangeName) {
+			continue
+		}
+		if symbol != "" && !strings.EqualFold(rt.Config.Symbol, symbol) {
+			continue
+		}
+		selected = append(selected, rt)
+	}
+	if len(selected) == 0 && (exchangeName != "" || symbol != "") {
+		return nil, fmt.Errorf("未找到运行中的交易对: %s:%s", exchangeName, symbol)
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return runtimeKey(selected[i].Config.Exchange, selected[i].Config.Symbol) <
+			runtimeKey(selected[j].Config.Exchange, selected[j].Config.Symbol)
+	})
+	return selected, nil
+}
+
+// runtimeControlStatus 读取交易对的当前状态
+func runtimeControlStatus(rt *SymbolRuntime) controlSymbolStatus {
+	spm := rt.SuperPositionManager
+	qty, _, avgEntry := spm.GetCostBasis()
+	status := controlSymbolStatus{
+		Exchange:      rt.Config.Exchange,
+		Symbol:        rt.Config.Symbol,
+		Paused:        spm.IsPaused(),
+		BuyHalts:      spm.BuyHaltSources(),
+		Position:      qty,
+		AvgEntryPrice: avgEntry,
+		ActiveLayers:  spm.GetActiveLayers(),
+	}
+	if rt.PriceMonitor != nil {
+		status.LastPrice = rt.PriceMonitor.GetLastPrice()
+	}
+	return status
+}
+
+// controlSpool 基于控制目录的命令队列（实例端）
+type controlSpool struct {
+	dir      string
+	interval time.Duration
+	executor *controlExecutor
+}
+
+// newControlSpool 创建控制目录（commands/ 和 results/）
+func newControlSpool(dir string, interval time.Duration, executor *controlExecutor) (*controlSpool, error) {
+	for _, sub := range []string{"", "commands", "results"} {
+		path := filepath.Join(dir, sub)
+		if err := os.MkdirAll(path, 0700); err != nil {
+			return nil, fmt.Errorf("创建控制目录失败: %w", err)
+		}
+		// 目录已存在时 MkdirAll 不会修改权限
+		if err := os.Chmod(path, 0700); err != nil {
+			return nil, fmt.Errorf("设置控制目录权限失败: %w", err)
+		}
+	}
+	return &controlSpool{dir: dir, interval: interval, executor: executor}, nil
+}
+
+// Start 后台轮询命令目录
+func (s *controlSpool) Start(ctx context.Context) {
+	utils.GoSupervised(ctx, "control-spool", func(ctx context.Context) {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.poll(ctx)
+			}
+		}
+	})
+}
+
+// poll 按文件名顺序执行所有待处理命令，并清理过期结果
+func (s *controlSpool) poll(ctx context.Context) {
+	commandsDir := filepath.Join(s.dir, "commands")
+	entries, err := os.ReadDir(commandsDir)
+	if err != nil {
+		logger.Warn("⚠️ [控制通道] 读取命令目录失败: %v", err)
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(commandsDir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		// 先删除再执行，避免执行失败时反复执行同一条命令
+		if err := os.Remove(path); err != nil {
+			logger.Warn("⚠️ [控制通道] 删除命令文件失败: %v", err)
+			continue
+		}
+
+		var req controlRequest
+		var resp controlResponse
+		if err := json.Unmarshal(data, &req); err != nil {
+			resp = controlResponse{Error: fmt.Sprintf("解析命令失败: %v", err), FinishedAt: time.Now()}
+		} else {
+			resp = s.executor.Execute(ctx, req)
+		}
+		resp.ID = strings.TrimSuffix(entry.Name(), ".json")
+		if err := writeJSONAtomic(filepath.Join(s.dir, "results", entry.Name()), resp); err != nil {
+			logger.Warn("⚠️ [控制通道] 写入结果失败: %v", err)
+		}
+	}
+
+	resultsDir := filepath.Join(s.dir, "results")
+	results, _ := os.ReadDir(resultsDir)
+	for _, entry := range results {
+		if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) > controlResultTTL {
+			os.Remove(filepath.Join(resultsDir, entry.Name()))
+		}
+	}
+}
+
+// writeJSONAtomic 先写临时文件再重命名，读取方不会读到写了一半的文件
+func writeJSONAtomic(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// sendControlRequest 把命令写入控制目录并等待实例返回结果（CLI 端）
+func sendControlRequest(dir string, req controlRequest, timeout time.Duration) (*controlResponse, error) {
+	if _, err := os.Stat(filepath.Join(dir, "commands")); err != nil {
+		return nil, fmt.Errorf("控制目录 %s 不可用（实例是否以 headless 模式运行？）: %w", dir, err)
+	}
+	req.ID = fmt.Sprintf("%d-%d", time.Now().UnixNano(), os.Getpid())
+	req.CreatedAt = time.Now()
+	commandPath := filepath.Join(dir, "commands", req.ID+".json")
+	if err := writeJSONAtomic(commandPath, req); err != nil {
+		return nil, fmt.Errorf("写入命令失败: %w", err)
+	}
+
+	resultPath := filepath.Join(dir, "results", req.ID+".json")
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		data, err := os.ReadFile(resultPath)
+		if err == nil {
+			os.Remove(resultPath)
+			var resp controlResponse
+			if err := json.Unmarshal(data, &resp); err != nil {
+				return nil, fmt.Errorf("解析结果失败: %w", err)
+			}
+			return &resp, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	// 超时后撤回尚未被执行的命令，避免实例之后才执行
+	if os.Remove(commandPath) == nil {
+		return nil, fmt.Errorf("实例在 %s 内未响应（实例是否在运行并启用了 headless 模式？）", timeout)
+	}
+	return nil, fmt.Errorf("实例已取走命令但在 %s 内未返回结果，请稍后用 status 确认", timeout)
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/position"
+	"quantmesh/testutil"
+)
+
+// newControlTestExecutor 创建带假仓位管理器的控制执行器，交易对由 exchanges 指定
+func newControlTestExecutor(exchanges map[string]*cancelRecorder) *controlExecutor {
+	cfg := &config.Config{}
+	cfg.System.FastCancelSeconds = 1
+	manager := NewSymbolManager(cfg)
+	for symbol, ex := range exchanges {
+		symCfg := &config.Config{}
+		symCfg.Trading.Symbol = symbol
+		spm := position.NewSuperPositionManager(symCfg, testutil.NewFakeExecutor(), testutil.NewFakeExchange("fake", 2, 3), 2, 3)
+		manager.runtimes[runtimeKey("fake", symbol)] = &SymbolRuntime{
+			Config:               config.SymbolConfig{Exchange: "fake", Symbol: symbol},
+			Exchange:             ex,
+			SuperPositionManager: spm,
+		}
+	}
+	return &controlExecutor{cfg: cfg, manager: manager, mode: "headless"}
+}
+
+func TestControlExecutorCommands(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         controlRequest
+		cancelErr   error
+		wantOK      bool
+		wantError   string
+		wantSymbols []string
+		wantPaused  bool
+		wantCancels int32
+	}{
+		{name: "status", req: controlRequest{Action: controlActionStatus}, wantOK: true, wantSymbols: []string{"BTCUSDT", "ETHUSDT"}},
+		{name: "pause one symbol", req: controlRequest{Action: controlActionPause, Symbol: "btcusdt"}, wantOK: true, wantSymbols: []string{"BTCUSDT"}, wantPaused: true},
+		{name: "resume", req: controlRequest{Action: controlActionResume, Exchange: "FAKE"}, wantOK: true, wantSymbols: []string{"BTCUSDT", "ETHUSDT"}},
+		{name: "cancel", req: controlRequest{Action: controlActionCancel, Symbol: "BTCUSDT"}, wantOK: true, wantSymbols: []string{"BTCUSDT"}, wantPaused: true, wantCancels: 1},
+		{name: "cancel failure", req: controlRequest{Action: controlActionCancel, Symbol: "BTCUSDT"}, cancelErr: errors.New("rate limited"),
+			wantError: "1 个交易对执行失败", wantSymbols: []string{"BTCUSDT"}, wantPaused: true, wantCancels: 1},
+		{name: "unknown action", req: controlRequest{Action: "liquidate"}, wantError: "不支持的命令"},
+		{name: "unknown symbol", req: controlRequest{Action: controlActionStatus, Symbol: "DOGEUSDT"}, wantError: "未找到运行中的交易对"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			btc := &cancelRecorder{cancelAllErr: tt.cancelErr}
+			e := newControlTestExecutor(map[string]*cancelRecorder{"BTCUSDT": btc, "ETHUSDT": {}})
+			tt.req.ID = "cmd"
+
+			resp := e.Execute(context.Background(), tt.req)
+
+			if resp.ID != "cmd" || resp.Mode != "headless" || resp.FinishedAt.IsZero() {
+				t.Errorf("response metadata = %+v", resp)
+			}
+			if resp.OK != tt.wantOK || !strings.Contains(resp.Error, tt.wantError) || (tt.wantError == "") != (resp.Error == "") {
+				t.Fatalf("OK = %v error = %q, want %v %q", resp.OK, resp.Error, tt.wantOK, tt.wantError)
+			}
+			var symbols []string
+			for _, s := range resp.Symbols {
+				symbols = append(symbols, s.Symbol)
+				if s.Symbol == "BTCUSDT" && s.Paused != tt.wantPaused {
+					t.Errorf("BTCUSDT paused = %v, want %v", s.Paused, tt.wantPaused)
+				}
+				if s.Symbol == "BTCUSDT" && tt.cancelErr != nil && s.Error != tt.cancelErr.Error() {
+					t.Errorf("BTCUSDT error = %q, want %q", s.Error, tt.cancelErr)
+				}
+			}
+			if strings.Join(symbols, ",") != strings.Join(tt.wantSymbols, ",") {
+				t.Errorf("symbols = %v, want %v", symbols, tt.wantSymbols)
+			}
+			if got := btc.cancelAlls.Load(); got != tt.wantCancels {
+				t.Errorf("CancelAllOrders calls = %d, want %d", got, tt.wantCancels)
+			}
+		})
+	}
+}
+
+func TestControlSpoolPoll(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "control")
+	// 已存在的宽松权限目录会被收紧
+	if err := os.MkdirAll(filepath.Join(dir, "commands"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	btc := &cancelRecorder{}
+	spool, err := newControlSpool(dir, time.Second, newControlTestExecutor(map[string]*cancelRecorder{"BTCUSDT": btc}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range []string{"", "commands", "results"} {
+		info, err := os.Stat(filepath.Join(dir, sub))
+		if err != nil || info.Mode().Perm() != 0700 {
+			t.Fatalf("%q mode = %v (%v), want 0700", sub, info.Mode().Perm(), err)
+		}
+	}
+
+	commands := filepath.Join(dir, "commands")
+	results := filepath.Join(dir, "results")
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(filepath.Join(commands, "1-pause.json"), `{"action":"pause","symbol":"BTCUSDT"}`)
+	writeFile(filepath.Join(commands, "2-bad.json"), `{not json`)
+	writeFile(filepath.Join(commands, "notes.txt"), `ignored`)
+	// 过期未读取的结果被清理，新结果保留
+	writeFile(filepath.Join(results, "stale.json"), `{}`)
+	writeFile(filepath.Join(results, "fresh.json"), `{}`)
+	old := time.Now().Add(-controlResultTTL - time.Minute)
+	if err := os.Chtimes(filepath.Join(results, "stale.json"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	spool.poll(context.Background())
+
+	readResult := func(id string) controlResponse {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(results, id+".json"))
+		if err != nil {
+			t.Fatalf("result %s: %v", id, err)
+		}
+		var resp controlResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	if resp := readResult("1-pause"); !resp.OK || resp.ID != "1-pause" || len(resp.Symbols) != 1 || !resp.Symbols[0].Paused {
+		t.Errorf("pause result = %+v", resp)
+	}
+	if resp := readResult("2-bad"); resp.OK || resp.ID != "2-bad" || !strings.Contains(resp.Error, "解析命令失败") {
+		t.Errorf("bad command result = %+v", resp)
+	}
+
+	remaining, _ := os.ReadDir(commands)
+	if len(remaining) != 1 || remaining[0].Name() != "notes.txt" {
+		t.Errorf("commands left = %v, want only notes.txt", remaining)
+	}
+	if _, err := os.Stat(filepath.Join(results, "stale.json")); !os.IsNotExist(err) {
+		t.Errorf("stale result not cleaned up: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(results, "fresh.json")); err != nil {
+		t.Errorf("fresh result removed: %v", err)
+	}
+
+	// 命令执行一次后即删除，再次轮询不会重复执行
+	spool.poll(context.Background())
+	if _, err := os.Stat(filepath.Join(results, "1-pause.json")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSendControlRequest(t *testing.T) {
+	if _, err := sendControlRequest(filepath.Join(t.TempDir(), "missing"), controlRequest{Action: controlActionStatus}, time.Second); err == nil {
+		t.Fatal("missing control dir accepted")
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		dir := t.TempDir()
+		spool, err := newControlSpool(dir, time.Second, newControlTestExecutor(map[string]*cancelRecorder{"BTCUSDT": {}}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			ticker := time.NewTicker(20 * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					spool.poll(ctx)
+				}
+			}
+		}()
+
+		resp, err := sendControlRequest(dir, controlRequest{Action: controlActionStatus}, 2*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !resp.OK || len(resp.Symbols) != 1 || resp.Symbols[0].Symbol != "BTCUSDT" {
+			t.Fatalf("response = %+v", resp)
+		}
+		// CLI 读取后删除结果文件
+		if left, _ := os.ReadDir(filepath.Join(dir, "results")); len(left) != 0 {
+			t.Errorf("result files left: %v", left)
+		}
+	})
+
+	t.Run("timeout withdraws command", func(t *testing.T) {
+		dir := t.TempDir()
+		if _, err := newControlSpool(dir, time.Second, nil); err != nil {
+			t.Fatal(err)
+		}
+		_, err := sendControlRequest(dir, controlRequest{Action: controlActionCancel}, 150*time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), "未响应") {
+			t.Fatalf("err = %v, want no-response error", err)
+		}
+		// 撤回后实例启动也不会再执行该命令
+		if left, _ := os.ReadDir(filepath.Join(dir, "commands")); len(left) != 0 {
+			t.Fatalf("command not withdrawn: %v", left)
+		}
+	})
+
+	t.Run("timeout after pickup", func(t *testing.T) {
+		dir := t.TempDir()
+		if _, err := newControlSpool(dir, time.Second, nil); err != nil {
+			t.Fatal(err)
+		}
+		// 实例取走命令但没有写结果
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			commands := filepath.Join(dir, "commands")
+			for ctx.Err() == nil {
+				entries, _ := os.ReadDir(commands)
+				for _, entry := range entries {
+					if filepath.Ext(entry.Name()) == ".json" {
+						os.Remove(filepath.Join(commands, entry.Name()))
+						return
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		}()
+		_, err := sendControlRequest(dir, controlRequest{Action: controlActionCancel}, 300*time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), "已取走命令") {
+			t.Fatalf("err = %v, want picked-up error", err)
+		}
+	})
+}
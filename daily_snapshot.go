@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/event"
+	"quantmesh/logger"
+	"quantmesh/storage"
+	"quantmesh/utils"
+	"quantmesh/web"
+)
+
+// dailySnapshotDateLayout 快照日期与文件名格式
+const dailySnapshotDateLayout = "2006-01-02"
+
+// dailySnapshotTotals 当日合计
+type dailySnapshotTotals struct {
+	Trades        int     `json:"trades"`
+	Volume        float64 `json:"volume"`
+	PnL           float64 `json:"pnl"`
+	FundingFee    float64 `json:"funding_fee"`
+	NetPnL        float64 `json:"net_pnl"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+}
+
+// dailySnapshotSlot 有持仓或挂单的槽位
+type dailySnapshotSlot struct {
+	Price         float64 `json:"price"`
+	PositionQty   float64 `json:"position_qty,omitempty"`
+	AvgEntryPrice float64 `json:"avg_entry_price,omitempty"`
+	OrderSide     string  `json:"order_side,omitempty"`
+	OrderPrice    float64 `json:"order_price,omitempty"`
+	OrderStatus   string  `json:"order_status,omitempty"`
+}
+
+// dailySnapshotSymbol 单个交易对的当日统计与收盘时的持仓
+type dailySnapshotSymbol struct {
+	Exchange      string              `json:"exchange"`
+	Symbol        string              `json:"symbol"`
+	Trades        int                 `json:"trades"`
+	Wins          int                 `json:"wins"`
+	Losses        int                 `json:"losses"`
+	Volume        float64             `json:"volume"`
+	PnL           float64             `json:"pnl"`
+	WinRate       float64             `json:"win_rate"`
+	FundingFee    float64             `json:"funding_fee"`
+	NetPnL        float64             `json:"net_pnl"`
+	Paused        bool                `json:"paused"`
+	Price         float64             `json:"price"`
+	Position      float64             `json:"position"`
+	AvgEntryPrice float64             `json:"avg_entry_price"`
+	CostBasis     float64             `json:"cost_basis"`
+	UnrealizedPnL float64             `json:"unrealized_pnl"`
+	Slots         []dailySnapshotSlot `json:"slots"`
+}
+
+// dailySnapshot 每日收盘快照文件内容
+type dailySnapshot struct {
+	Date        string                `json:"date"`
+	Timezone    string                `json:"timezone"`
+	GeneratedAt time.Time             `json:"generated_at"`
+	Version     string                `json:"version"`
+	Totals      dailySnapshotTotals   `json:"totals"`
+	Symbols     []dailySnapshotSymbol `json:"symbols"`
+	Benchmark   *web.BenchmarkReport  `json:"benchmark,omitempty"` // 启用 HODL 对比时为生成时刻的对比结果
+}
+
+// dailySnapshotter 每日收盘快照：定时汇总前一自然日的统计（同时写入 statistics 表）、持仓与槽位状态，
+// 写入不可覆盖的 JSON 文件，文件哈希记录到存储事件中，可选发送通知
+type dailySnapshotter struct {
+	cfg       *config.Config
+	manager   *SymbolManager
+	store     storage.Storage // 未启用存储时为 nil，快照中不含当日统计
+	eventBus  *event.EventBus
+	benchmark *benchmarkTracker // 未启用 HODL 对比时为 nil
+}
+
+func newDailySnapshotter(cfg *config.Config, manager *SymbolManager, store storage.Storage, eventBus *event.EventBus) *dailySnapshotter {
+	return &dailySnapshotter{cfg: cfg, manager: manager, store: store, eventBus: eventBus}
+}
+
+// Start 每天在配置的时间生成前一自然日的快照
+func (s *dailySnapshotter) Start(ctx context.Context) {
+	logger.Info("✅ [每日快照] 已启用，每天 %s 生成前一日快照，目录: %s", s.cfg.DailySnapshot.Time, s.cfg.DailySnapshot.Dir)
+	utils.GoSupervised(ctx, "daily-snapshot", func(ctx context.Context) {
+		for {
+			next := s.nextRun(utils.NowConfiguredTimezone())
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			day := next.AddDate(0, 0, -1)
+			if err := s.run(day); err != nil {
+				logger.Error("❌ [每日快照] 生成 %s 快照失败: %v", day.Format(dailySnapshotDateLayout), err)
+			}
+			s.prune(next)
+		}
+	})
+}
+
+// nextRun 下一次生成时间（配置时区）
+func (s *dailySnapshotter) nextRun(now time.Time) time.Time {
+	at, _ := time.Parse("15:04", s.cfg.DailySnapshot.Time)
+	next := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// run 生成指定自然日的快照；文件已存在时不覆盖
+func (s *dailySnapshotter) run(day time.Time) error {
+	dayStart := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	date := dayStart.Format(dailySnapshotDateLayout)
+	path := filepath.Join(s.cfg.DailySnapshot.Dir, date+".json")
+
+	snap := s.build(date, dayStart, dayEnd)
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.cfg.DailySnapshot.Dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		logger.Warn("⚠️ [每日快照] %s 已存在，不覆盖", path)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	if s.store != nil {
+		if err := s.store.SaveEvent(string(event.EventTypeDailySnapshot), map[string]interface{}{
+			"date":    date,
+			"path":    path,
+			"sha256":  digest,
+			"trades":  snap.Totals.Trades,
+			"pnl":     snap.Totals.PnL,
+			"net_pnl": snap.Totals.NetPnL,
+		}); err != nil {
+			logger.Warn("⚠️ [每日快照] 记录快照哈希失败: %v", err)
+		}
+	}
+	logger.Info("📸 [每日快照] %s 已生成: %s (交易 %d 笔, 盈亏 %.4f, 未实现 %.4f)",
+		date, path, snap.Totals.Trades, snap.Totals.NetPnL, snap.Totals.UnrealizedPnL)
+
+	if s.cfg.DailySnapshot.Notify && s.eventBus != nil {
+		s.eventBus.Publish(&event.Event{
+			Type:      event.EventTypeDailySnapshot,
+			Timestamp: time.Now(),
+			Data: map[string]interface{}{
+				"date":           date,
+				"trades":         snap.Totals.Trades,
+				"pnl":            snap.Totals.PnL,
+				"funding_fee":    snap.Totals.FundingFee,
+				"net_pnl":        snap.Totals.NetPnL,
+				"unrealized_pnl": snap.Totals.UnrealizedPnL,
+				"symbols":        len(snap.Symbols),
+				"file":           path,
+				"sha256":         digest,
+			},
+		})
+	}
+	return nil
+}
+
+// build 汇总各交易对的当日统计与当前持仓，并将当日统计写入 statistics 表
+func (s *dailySnapshotter) build(date string, dayStart, dayEnd time.Time) dailySnapshot {
+	snap := dailySnapshot{
+		Date:        date,
+		Timezone:    dayStart.Location().String(),
+		GeneratedAt: time.Now(),
+		Version:     Version,
+		Symbols:     []dailySnapshotSymbol{},
+	}
+
+	runtimes := s.manager.List()
+	sort.Slice(runtimes, func(i, j int) bool {
+		return runtimeKey(runtimes[i].Config.Exchange, runtimes[i].Config.Symbol) <
+			runtimeKey(runtimes[j].Config.Exchange, runtimes[j].Config.Symbol)
+	})
+	for _, rt := range runtimes {
+		if rt == nil || rt.SuperPositionManager == nil {
+			continue
+		}
+		spm := rt.SuperPositionManager
+		// 存储中交易所为小写、交易对为大写
+		exchangeName, symbol := strings.ToLower(rt.Config.Exchange), strings.ToUpper(rt.Config.Symbol)
+		sym := dailySnapshotSymbol{Exchange: exchangeName, Symbol: symbol, Paused: spm.IsPaused(), Slots: []dailySnapshotSlot{}}
+
+		if s.store != nil {
+			daily, err := s.store.QueryDailyStatisticsBySymbol(exchangeName, symbol, dayStart, dayEnd)
+			if err != nil {
+				logger.Warn("⚠️ [每日快照] 查询 %s:%s 当日统计失败: %v", exchangeName, symbol, err)
+			}
+			for _, d := range daily {
+				sym.Trades += d.TotalTrades
+				sym.Wins += d.WinningTrades
+				sym.Losses += d.LosingTrades
+				sym.Volume += d.TotalVolume
+				sym.PnL += d.TotalPnL
+				sym.FundingFee += d.FundingFee
+				sym.NetPnL += d.NetPnL
+			}
+			if sym.Trades > 0 {
+				sym.WinRate = float64(sym.Wins) / float64(sym.Trades)
+			}
+			if err := s.store.SaveStatistics(&storage.Statistics{
+				Exchange:    exchangeName,
+				Symbol:      symbol,
+				Date:        dayStart,
+				TotalTrades: sym.Trades,
+				TotalVolume: sym.Volume,
+				TotalPnL:    sym.PnL,
+				WinRate:     sym.WinRate,
+				CreatedAt:   time.Now(),
+			}); err != nil {
+				logger.Warn("⚠️ [每日快照] 写入 %s:%s 当日统计失败: %v", exchangeName, symbol, err)
+			}
+		}
+
+		sym.Position, sym.CostBasis, sym.AvgEntryPrice = spm.GetCostBasis()
+		if rt.PriceMonitor != nil {
+			sym.Price = rt.PriceMonitor.GetLastPrice()
+		}
+		if sym.Price > 0 && sym.Position != 0 {
+			sym.UnrealizedPnL = (sym.Price - sym.AvgEntryPrice) * sym.Position
+		}
+		for _, slot := range spm.GetAllSlotsDetailed() {
+			if slot.PositionQty <= 0 && slot.OrderID == 0 {
+				continue
+			}
+			entry := dailySnapshotSlot{Price: slot.Price, PositionQty: slot.PositionQty}
+			if slot.PositionQty > 0 {
+				entry.AvgEntryPrice = slot.AvgEntryPrice
+			}
+			if slot.OrderID != 0 {
+				entry.OrderSide, entry.OrderPrice, entry.OrderStatus = slot.OrderSide, slot.OrderPrice, slot.OrderStatus
+			}
+			sym.Slots = append(sym.Slots, entry)
+		}
+		sort.Slice(sym.Slots, func(i, j int) bool { return sym.Slots[i].Price < sym.Slots[j].Price })
+
+		snap.Totals.Trades += sym.Trades
+		snap.Totals.Volume += sym.Volume
+		snap.Totals.PnL += sym.PnL
+		snap.Totals.FundingFee += sym.FundingFee
+		snap.Totals.NetPnL += sym.NetPnL
+		snap.Totals.UnrealizedPnL += sym.UnrealizedPnL
+		snap.Symbols = append(snap.Symbols, sym)
+	}
+	if s.benchmark != nil {
+		report := s.benchmark.BenchmarkReport()
+		snap.Benchmark = &report
+	}
+	return snap
+}
+
+// prune 删除超过保留天数的快照文件（retention_days 为 0 时永久保留）
+func (s *dailySnapshotter) prune(now time.Time) {
+	days := s.cfg.DailySnapshot.RetentionDays
+	if days <= 0 {
+		return
+	}
+	cutoff := now.AddDate(0, 0, -days).Format(dailySnapshotDateLayout)
+	entries, err := os.ReadDir(s.cfg.DailySnapshot.Dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		date := strings.TrimSuffix(e.Name(), ".json")
+		if e.IsDir() || date == e.Name() {
+			continue
+		}
+		if _, err := time.Parse(dailySnapshotDateLayout, date); err != nil || date >= cutoff {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.cfg.DailySnapshot.Dir, e.Name())); err != nil {
+			logger.Warn("⚠️ [每日快照] 删除过期快照 %s 失败: %v", e.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+	"quantmesh/position"
+	"quantmesh/scheduler"
+)
+
+// deleverageSource 低流动性时段设置挂单上限/暂停新开仓时使用的来源名称
+const deleverageSource = "deleverage"
+
+// buildScheduleWindows 将配置的时段转换为调度器时段（周循环时段按 loc 解释）
+func buildScheduleWindows(windows []config.ScheduleWindowConfig, loc *time.Location) ([]scheduler.Window, error) {
+	result := make([]scheduler.Window, 0, len(windows))
+	for i, w := range windows {
+		if w.IsWeekly() {
+			startDay, startMinute, endDay, endMinute, err := w.ParseWeekly()
+			if err != nil {
+				return nil, fmt.Errorf("时段 %d: %w", i, err)
+			}
+			result = append(result, scheduler.WeeklyWindow{
+				StartDay:    startDay,
+				StartMinute: startMinute,
+				EndDay:      endDay,
+				EndMinute:   endMinute,
+				Location:    loc,
+			})
+			continue
+		}
+		start, end, err := w.ParseFixed()
+		if err != nil {
+			return nil, fmt.Errorf("时段 %d: %w", i, err)
+		}
+		result = append(result, scheduler.FixedWindow{Start: start, End: end})
+	}
+	return result, nil
+}
+
+// deleverageJob 低流动性时段降杠杆任务
+// 进入时段（提前 lead_minutes）时收紧挂单窗口、限制持仓层数，stop_buying 时暂停新开仓并撤销买单；离开时段后恢复
+func deleverageJob(cfg *config.Config, symbolManager *SymbolManager) (scheduler.Job, error) {
+	dl := cfg.Deleverage
+	loc, err := time.LoadLocation(dl.Timezone)
+	if err != nil {
+		return scheduler.Job{}, err
+	}
+	windows, err := buildScheduleWindows(dl.Windows, loc)
+	if err != nil {
+		return scheduler.Job{}, err
+	}
+
+	targets := func() []*SymbolRuntime {
+		var result []*SymbolRuntime
+		for _, rt := range symbolManager.List() {
+			if rt.SuperPositionManager == nil {
+				continue
+			}
+			if len(dl.Symbols) > 0 && !containsFold(dl.Symbols, rt.Config.Symbol) {
+				continue
+			}
+			result = append(result, rt)
+		}
+		return result
+	}
+	windowCap := position.WindowCap{
+		BuyWindow:  dl.BuyWindowSize,
+		SellWindow: dl.SellWindowSize,
+		MaxLayers:  dl.MaxGridLayers,
+	}
+
+	onEnter := func() {
+		for _, rt := range targets() {
+			spm := rt.SuperPositionManager
+			spm.SetWindowCap(deleverageSource, windowCap)
+			if dl.StopBuying {
+				spm.HaltBuying(deleverageSource)
+				spm.CancelAllBuyOrders()
+			}
+			logger.Warn("📉 [%s:%s] 进入低流动性时段，降杠杆 (买单窗口≤%d, 卖单窗口≤%d, 层数≤%d, 暂停新开仓: %v)",
+				rt.Config.Exchange, rt.Config.Symbol, dl.BuyWindowSize, dl.SellWindowSize, dl.MaxGridLayers, dl.StopBuying)
+		}
+	}
+	onExit := func() {
+		for _, rt := range targets() {
+			rt.SuperPositionManager.ClearWindowCap(deleverageSource)
+			rt.SuperPositionManager.ResumeBuying(deleverageSource)
+			logger.Info("📈 [%s:%s] 低流动性时段结束，恢复正常参数", rt.Config.Exchange, rt.Config.Symbol)
+		}
+	}
+
+	return scheduler.Job{
+		Name:    deleverageSource,
+		Windows: windows,
+		Lead:    time.Duration(dl.LeadMinutes) * time.Minute,
+		OnEnter: onEnter,
+		OnExit:  onExit,
+	}, nil
+}
+
+// containsFold 忽略大小写判断列表是否包含 s
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
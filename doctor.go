@@ -0,0 +1,390 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/exchange"
+)
+
+// 诊断结果状态
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+	doctorSkip = "skip"
+)
+
+// 时钟偏差阈值：超过 warn 给出警告，超过 fail 时签名请求大概率被拒绝（币安默认 recvWindow 为 5s）
+const (
+	clockDriftWarn = 500 * time.Millisecond
+	clockDriftFail = 3 * time.Second
+)
+
+// doctorCheck 单项诊断结果
+type doctorCheck struct {
+	Name      string `json:"name"`
+	Target    string `json:"target,omitempty"`
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	Hint      string `json:"hint,omitempty"`
+	ElapsedMs int64  `json:"elapsed_ms"`
+}
+
+// doctorReport 诊断报告
+type doctorReport struct {
+	Version   string         `json:"version"`
+	Config    string         `json:"config"`
+	CheckedAt time.Time      `json:"checked_at"`
+	Passed    bool           `json:"passed"`
+	Checks    []*doctorCheck `json:"checks"`
+}
+
+// add 记录一项诊断结果
+func (r *doctorReport) add(c *doctorCheck) {
+	r.Checks = append(r.Checks, c)
+	if c.Status == doctorFail {
+		r.Passed = false
+	}
+}
+
+// doctorRunner 执行各项诊断
+type doctorRunner struct {
+	cfg     *config.Config
+	timeout time.Duration
+	report  *doctorReport
+}
+
+// cmdDoctor 检查配置、交易所连通性、API 权限、时钟偏差、WebSocket、存储可写和时区数据
+// 默认输出彩色报告，--json 输出机器可读的 JSON；有检查失败时返回非零退出码
+func cmdDoctor(args []string) error {
+	fs, configPath := newCommandFlags("doctor")
+	jsonOutput := fs.Bool("json", false, "输出 JSON 格式的诊断结果")
+	noColor := fs.Bool("no-color", false, "不使用颜色")
+	offline := fs.Bool("offline", false, "跳过需要访问交易所的检查")
+	timeout := fs.Duration("timeout", 10*time.Second, "单项网络检查超时")
+	logDB := fs.String("log-db", "./logs.db", "日志数据库路径（检查可写）")
+	if _, err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	report := &doctorReport{Version: Version, Config: *configPath, CheckedAt: time.Now(), Passed: true}
+	runner := &doctorRunner{timeout: *timeout, report: report}
+
+	start := time.Now()
+	cfg, err := loadCommandConfig(*configPath)
+	check := &doctorCheck{Name: "config", Target: *configPath, ElapsedMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		check.Status, check.Message = doctorFail, err.Error()
+		check.Hint = "参考 config.example.yaml 修正配置"
+		report.add(check)
+	} else {
+		check.Status = doctorPass
+		check.Message = fmt.Sprintf("配置有效，%d 个交易对", len(cfg.Trading.Symbols))
+		report.add(check)
+		runner.cfg = cfg
+	}
+
+	runner.checkTimezone()
+	runner.checkStorage(*logDB)
+	if runner.cfg != nil {
+		if *offline {
+			report.add(&doctorCheck{Name: "exchange", Status: doctorSkip, Message: "--offline 已跳过交易所检查"})
+		} else {
+			runner.checkExchanges()
+		}
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printDoctorReport(report, !*noColor && isTerminal(os.Stdout))
+	}
+	if !report.Passed {
+		return fmt.Errorf("存在未通过的检查")
+	}
+	return nil
+}
+
+// checkTimezone 检查时区数据（精简镜像缺少 tzdata 时 LoadLocation 会失败，统计日期会按错误时区切分）
+func (d *doctorRunner) checkTimezone() {
+	name := "Asia/Shanghai"
+	if d.cfg != nil && d.cfg.System.Timezone != "" {
+		name = d.cfg.System.Timezone
+	}
+	check := &doctorCheck{Name: "timezone", Target: name}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		check.Status, check.Message = doctorFail, err.Error()
+		check.Hint = "安装 tzdata（如 apk add tzdata）或使用 -tags timetzdata 编译"
+	} else {
+		_, offset := time.Now().In(loc).Zone()
+		check.Status = doctorPass
+		check.Message = fmt.Sprintf("时区数据可用 (UTC%+.1f)", float64(offset)/3600)
+	}
+	d.report.add(check)
+}
+
+// checkStorage 检查数据目录可写
+func (d *doctorRunner) checkStorage(logDB string) {
+	dirs := []string{filepath.Dir(logDB)}
+	if d.cfg != nil {
+		if d.cfg.Storage.Enabled && d.cfg.Storage.Type != "memory" && d.cfg.Storage.Path != "" {
+			dirs = append(dirs, filepath.Dir(d.cfg.Storage.Path))
+		}
+		if d.cfg.InstanceLock.Mode != "off" && d.cfg.InstanceLock.Dir != "" {
+			dirs = append(dirs, d.cfg.InstanceLock.Dir)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		start := time.Now()
+		check := &doctorCheck{Name: "storage", Target: dir}
+		if err := checkDirWritable(dir); err != nil {
+			check.Status, check.Message = doctorFail, err.Error()
+			check.Hint = "检查目录权限，容器中确认数据卷已挂载且属主正确"
+		} else {
+			check.Status, check.Message = doctorPass, "目录可写"
+		}
+		check.ElapsedMs = time.Since(start).Milliseconds()
+		d.report.add(check)
+	}
+}
+
+// checkDirWritable 创建（不存在时）并写入临时文件
+func checkDirWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	_, err = f.WriteString("ok")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	os.Remove(name)
+	return err
+}
+
+// checkExchanges 对每个交易所取第一个交易对做网络检查
+func (d *doctorRunner) checkExchanges() {
+	seen := make(map[string]bool)
+	for _, symCfg := range d.cfg.Trading.Symbols {
+		if seen[symCfg.Exchange] {
+			continue
+		}
+		seen[symCfg.Exchange] = true
+		d.checkExchange(symCfg)
+	}
+}
+
+// checkExchange 连通性、API 权限、时钟偏差和 WebSocket 行情
+func (d *doctorRunner) checkExchange(symCfg config.SymbolConfig) {
+	target := symCfg.Exchange + ":" + symCfg.Symbol
+	exCfg := *d.cfg
+	exCfg.App.CurrentExchange = symCfg.Exchange
+	exCfg.Trading.Symbol = symCfg.Symbol
+	exCfg.MarketData.Record.Enabled = false
+	exCfg.Chaos.Enabled = false
+
+	start := time.Now()
+	ex, err := exchange.NewExchange(&exCfg, symCfg.Exchange, symCfg.Symbol)
+	if err != nil {
+		d.report.add(&doctorCheck{Name: "exchange", Target: target, Status: doctorFail, Message: err.Error(),
+			Hint: "检查 exchanges 配置中的 api_key / secret_key", ElapsedMs: time.Since(start).Milliseconds()})
+		return
+	}
+
+	// REST 连通性
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	start = time.Now()
+	price, err := ex.GetLatestPrice(ctx, symCfg.Symbol)
+	cancel()
+	check := &doctorCheck{Name: "exchange", Target: target, ElapsedMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		check.Status, check.Message = doctorFail, err.Error()
+		check.Hint = "检查网络/代理，以及交易对名称是否正确"
+	} else {
+		check.Status = doctorPass
+		check.Message = fmt.Sprintf("REST 可达，最新价 %.8g", price)
+	}
+	d.report.add(check)
+
+	d.checkPermissions(ex, target)
+	d.checkClock(ex, target)
+	d.checkWebSocket(ex, symCfg.Symbol, target)
+}
+
+// checkPermissions 检查 API 密钥权限（交易所不支持权限检测时退化为查询账户）
+func (d *doctorRunner) checkPermissions(ex exchange.IExchange, target string) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	start := time.Now()
+	check := &doctorCheck{Name: "api_key", Target: target}
+	defer func() {
+		check.ElapsedMs = time.Since(start).Milliseconds()
+		d.report.add(check)
+	}()
+
+	if checker, ok := exchange.As[exchange.PermissionChecker](ex); ok {
+		perms, err := checker.CheckAPIPermissions(ctx)
+		if err == nil {
+			perms.CalculateSecurityScore()
+			switch {
+			case !perms.CanTrade:
+				check.Status, check.Message = doctorFail, "API 密钥没有交易权限"
+				check.Hint = "在交易所后台为该密钥开启合约交易权限，并确认 IP 白名单包含本机"
+			case !perms.IsSecure() || len(perms.GetWarnings()) > 0:
+				check.Status = doctorWarn
+				check.Message = strings.Join(perms.GetWarnings(), "; ")
+				if check.Message == "" {
+					check.Message = fmt.Sprintf("安全评分 %d", perms.SecurityScore)
+				}
+			default:
+				check.Status = doctorPass
+				check.Message = fmt.Sprintf("可交易，安全评分 %d", perms.SecurityScore)
+			}
+			return
+		}
+		if !errors.Is(err, exchange.ErrNotImplemented) {
+			check.Status, check.Message = doctorFail, err.Error()
+			check.Hint = "检查密钥是否正确、是否过期以及 IP 白名单"
+			return
+		}
+	}
+
+	if _, err := ex.GetAccount(ctx); err != nil {
+		check.Status, check.Message = doctorFail, err.Error()
+		check.Hint = "检查密钥是否正确、是否过期以及 IP 白名单"
+		return
+	}
+	check.Status, check.Message = doctorPass, "账户可查询（交易所不支持权限明细检测）"
+}
+
+// checkClock 检查本机与交易所服务器的时钟偏差（取请求往返的中点作为本地时间）
+func (d *doctorRunner) checkClock(ex exchange.IExchange, target string) {
+	check := &doctorCheck{Name: "clock", Target: target}
+	provider, ok := exchange.As[exchange.ServerTimeProvider](ex)
+	if !ok {
+		check.Status, check.Message = doctorSkip, "交易所不支持服务器时间查询"
+		d.report.add(check)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	start := time.Now()
+	serverTime, err := provider.GetServerTime(ctx)
+	rtt := time.Since(start)
+	check.ElapsedMs = rtt.Milliseconds()
+	switch {
+	case errors.Is(err, exchange.ErrNotImplemented):
+		check.Status, check.Message = doctorSkip, "交易所不支持服务器时间查询"
+	case err != nil:
+		check.Status, check.Message = doctorFail, err.Error()
+	default:
+		drift := serverTime.Sub(start.Add(rtt / 2))
+		check.Message = fmt.Sprintf("时钟偏差 %dms (往返 %dms)", drift.Milliseconds(), rtt.Milliseconds())
+		abs := drift
+		if abs < 0 {
+			abs = -abs
+		}
+		switch {
+		case abs >= clockDriftFail:
+			check.Status = doctorFail
+			check.Hint = "启用 NTP 同步（如 timedatectl set-ntp true 或 chronyd）"
+		case abs >= clockDriftWarn:
+			check.Status = doctorWarn
+			check.Hint = "建议启用 NTP 同步"
+		default:
+			check.Status = doctorPass
+		}
+	}
+	d.report.add(check)
+}
+
+// checkWebSocket 订阅价格流，超时内收到第一条推送即通过
+func (d *doctorRunner) checkWebSocket(ex exchange.IExchange, symbol, target string) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	start := time.Now()
+	check := &doctorCheck{Name: "websocket", Target: target}
+
+	received := make(chan float64, 1)
+	err := ex.StartPriceStream(ctx, symbol, func(price float64) {
+		select {
+		case received <- price:
+		default:
+		}
+	})
+	if err != nil {
+		check.Status, check.Message = doctorFail, err.Error()
+		check.Hint = "检查防火墙/代理是否放行 WebSocket (wss) 连接"
+	} else {
+		select {
+		case price := <-received:
+			check.Status = doctorPass
+			check.Message = fmt.Sprintf("收到价格推送 %.8g", price)
+		case <-ctx.Done():
+			check.Status = doctorFail
+			check.Message = fmt.Sprintf("%s 内未收到价格推送", d.timeout)
+			check.Hint = "检查防火墙/代理是否放行 WebSocket (wss) 连接"
+		}
+	}
+	check.ElapsedMs = time.Since(start).Milliseconds()
+	d.report.add(check)
+}
+
+// printDoctorReport 打印彩色诊断报告
+func printDoctorReport(report *doctorReport, color bool) {
+	labels := map[string]string{doctorPass: "PASS", doctorWarn: "WARN", doctorFail: "FAIL", doctorSkip: "SKIP"}
+	colors := map[string]string{doctorPass: "\033[32m", doctorWarn: "\033[33m", doctorFail: "\033[31m", doctorSkip: "\033[90m"}
+
+	fmt.Printf("QuantMesh doctor %s (%s)\n\n", report.Version, report.Config)
+	counts := make(map[string]int)
+	for _, c := range report.Checks {
+		counts[c.Status]++
+		label := labels[c.Status]
+		if color {
+			label = colors[c.Status] + label + "\033[0m"
+		}
+		name := c.Name
+		if c.Target != "" {
+			name += " [" + c.Target + "]"
+		}
+		fmt.Printf("  %s  %-40s %s\n", label, name, c.Message)
+		if c.Hint != "" && (c.Status == doctorFail || c.Status == doctorWarn) {
+			fmt.Printf("        ↳ %s\n", c.Hint)
+		}
+	}
+	fmt.Printf("\n通过 %d, 警告 %d, 失败 %d, 跳过 %d\n",
+		counts[doctorPass], counts[doctorWarn], counts[doctorFail], counts[doctorSkip])
+}
+
+// isTerminal 输出是否为终端（重定向到文件或管道时不输出颜色）
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
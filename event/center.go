@@ -166,7 +166,11 @@ func (ec *EventCenter) handleEvent(event *Event) {
 		return
 	}
 	
-	// 触发通知（如果需要）
+	// 触发通知（如果需要），交易所维护期间的断线/请求失败属于预期内，不再通知
+	if IsExchangeInMaintenance(exchange) && isExpectedDuringMaintenance(event.Type) {
+		logger.Debug("🔕 [%s] 维护期间静默事件通知: %s", exchange, event.Type)
+		return
+	}
 	if ec.shouldNotify(event.Type, severity) {
 		ec.notifier.Send(event)
 	}
@@ -288,11 +292,21 @@ func (ec *EventCenter) shouldNotify(eventType EventType, severity EventSeverity)
 	// Warning 级别的某些重要事件需要通知
 	if severity == SeverityWarning {
 		switch eventType {
-		case EventTypeAPIRateLimited, EventTypePriceVolatility, EventTypeAPIRequestFailed, EventTypePrecisionAdjustment:
+		case EventTypeAPIRateLimited, EventTypePriceVolatility, EventTypeAPIRequestFailed, EventTypePrecisionAdjustment,
+			EventTypeAPIKeyChanged, EventTypeAPIKeyExpiring, EventTypeExecutionAnomaly, EventTypeManualPositionAdjust,
+			EventTypeExchangeMaintenance, EventTypeExchangeMaintenanceEnd, EventTypeGridRecentered, EventTypeOpenInterestSpike,
+			EventTypeProfitGoalReached, EventTypeDailyLossLimit, EventTypeStrategyLossBreaker, EventTypeOrderStreamResync,
+			EventTypeListenKeyRenewed, EventTypeTurnoverBudgetExhausted, EventTypePriceBandViolation,
+			EventTypeMinNotionalChanged:
 			return true
 		}
 	}
 	
+	// 每日收盘快照只在 daily_snapshot.notify 开启时发布，发布即通知
+	if eventType == EventTypeDailySnapshot {
+		return true
+	}
+
 	// Info 级别的事件通常不通知（除非在通知配置中明确启用）
 	return false
 }
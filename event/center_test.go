@@ -134,3 +134,26 @@ func TestEventTitle(t *testing.T) {
 	}
 }
 
+
+func TestExchangeMaintenanceSilencesExpectedEvents(t *testing.T) {
+	SetExchangeMaintenance("Binance", true)
+	defer SetExchangeMaintenance("binance", false)
+
+	if !IsExchangeInMaintenance("binance") {
+		t.Fatal("交易所应处于维护中")
+	}
+	if IsExchangeInMaintenance("okx") {
+		t.Error("其他交易所不应受影响")
+	}
+	if !isExpectedDuringMaintenance(EventTypeWebSocketDisconnected) {
+		t.Error("维护期间 WebSocket 断线应静默")
+	}
+	if isExpectedDuringMaintenance(EventTypeRiskTriggered) {
+		t.Error("维护期间风控事件仍需通知")
+	}
+
+	SetExchangeMaintenance("binance", false)
+	if IsExchangeInMaintenance("binance") {
+		t.Error("维护结束后应清除标记")
+	}
+}
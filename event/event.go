@@ -1,6 +1,7 @@
 package event
 
 import (
+	"sync"
 	"time"
 
 	"quantmesh/logger"
@@ -15,10 +16,14 @@ const (
 	EventTypeOrderFilled        EventType = "order_filled"
 	EventTypeOrderCanceled      EventType = "order_canceled"
 	EventTypeOrderFailed        EventType = "order_failed" // 订单失败
+	EventTypeOrderStreamResync  EventType = "order_stream_resync" // 订单流断线恢复后通过 REST 补查订单
 	
 	// 持仓相关事件
 	EventTypePositionOpened     EventType = "position_opened"
 	EventTypePositionClosed     EventType = "position_closed"
+	EventTypeSymbolListed       EventType = "symbol_listed" // 交易所新上线交易对
+	EventTypeSymbolDelisting    EventType = "symbol_delisting" // 交易对下架/改名/状态变更（已暂停交易）
+	EventTypeMinNotionalChanged EventType = "min_notional_changed" // 交易所上调最小名义价值，每单金额不再满足要求
 	
 	// 风控相关事件
 	EventTypeRiskTriggered      EventType = "risk_triggered"
@@ -27,30 +32,57 @@ const (
 	EventTypeTakeProfit         EventType = "take_profit"
 	EventTypeMarginInsufficient EventType = "margin_insufficient" // 保证金不足
 	EventTypeAllocationExceeded EventType = "allocation_exceeded" // 超出资金分配限制
+	EventTypeLiquidationRisk    EventType = "liquidation_risk"    // 标记价格接近强平价
+	EventTypeReconcileDivergence EventType = "reconciliation_divergence" // 对账持仓偏差超出阈值
+	EventTypeManualPositionAdjust EventType = "manual_position_adjustment" // 人工修正槽位库存
+	EventTypeProfitGoalReached    EventType = "profit_goal_reached"        // 达成日/周盈利目标
+	EventTypeDailyLossLimit       EventType = "daily_loss_limit"           // 当日已实现亏损达到上限
+	EventTypeStrategyLossBreaker  EventType = "strategy_loss_breaker"      // 单个策略连续亏损触发熔断暂停
+	EventTypeTurnoverBudgetExhausted EventType = "turnover_budget_exhausted" // 策略交易额度（下单金额/订单数）用尽，已暂停该策略下单
+	EventTypePriceBandViolation   EventType = "price_band_violation"       // 订单价格超出交易对价格带，已在本地拦截
+	EventTypePnLDivergence        EventType = "pnl_divergence"             // 本地计算盈亏与交易所账户流水偏差超出容差
 	
 	// 网络相关事件
 	EventTypeWebSocketDisconnected EventType = "websocket_disconnected" // WebSocket 断连
 	EventTypeWebSocketReconnected  EventType = "websocket_reconnected"  // WebSocket 重连
+	EventTypeUserStreamUnhealthy   EventType = "user_stream_unhealthy"  // 用户数据流异常（listenKey 保活失败/过期），可能丢失成交推送
+	EventTypeListenKeyRenewed      EventType = "listen_key_renewed"     // 已更换 listenKey 并重新订阅用户数据流
 	EventTypeAPIRequestFailed      EventType = "api_request_failed"     // API 请求失败
 	EventTypeConnectionTimeout     EventType = "connection_timeout"     // 连接超时
+	EventTypeExchangeMaintenance    EventType = "exchange_maintenance"     // 交易所进入维护（已暂停下单）
+	EventTypeExchangeMaintenanceEnd EventType = "exchange_maintenance_end" // 交易所维护结束（健康检查通过后恢复下单）
 	
 	// API 错误事件
 	EventTypeAPIRateLimited   EventType = "api_rate_limited"   // API 限流 (429)
 	EventTypeAPIServerError   EventType = "api_server_error"   // 服务器错误 (5xx)
 	EventTypeAPIAuthFailed    EventType = "api_auth_failed"    // 认证失败
 	EventTypeAPIBadRequest    EventType = "api_bad_request"    // 请求错误 (4xx)
+
+	// API 密钥状态
+	EventTypeAPIKeyPermissionLost EventType = "api_key_permission_lost" // 密钥失去交易权限（已暂停交易）
+	EventTypeAPIKeyChanged        EventType = "api_key_changed"         // 密钥限制变更（如 IP 白名单）
+	EventTypeAPIKeyExpiring       EventType = "api_key_expiring"        // 密钥即将过期
 	
 	// 价格波动事件
 	EventTypePriceVolatility EventType = "price_volatility" // 价格大幅波动
 	EventTypePriceAnomaly    EventType = "price_anomaly"    // 价格异常
+	EventTypeOpenInterestSpike EventType = "open_interest_spike" // 持仓量短时间内大幅变化
 	
 	// 下单校验事件
 	EventTypePrecisionAdjustment EventType = "precision_adjustment" // 精度调整告警
+	EventTypeExecutionAnomaly    EventType = "execution_anomaly"    // 自身成交异常（低于手续费的往返、同一槽位循环成交）
+	EventTypeGridRecentered      EventType = "grid_recentered"      // 网格重新居中（锚点移动，撤销窗口外闲置买单）
+	EventTypeCapitalRebalanced   EventType = "capital_rebalanced"   // 多策略资金权重再平衡（记录每个策略的权重变化）
 	
 	// 系统资源事件
 	EventTypeSystemCPUHigh    EventType = "system_cpu_high"    // CPU 使用率过高
 	EventTypeSystemMemoryHigh EventType = "system_memory_high" // 内存使用率过高
 	EventTypeSystemDiskFull   EventType = "system_disk_full"   // 磁盘空间不足
+	EventTypeGoroutinePanic   EventType = "goroutine_panic"    // 协程 panic（已自动重启）
+	EventTypeWatchdogFailure  EventType = "watchdog_failure"   // 守护协程多次崩溃后停止重启
+	EventTypeInstanceConflict EventType = "instance_conflict"  // 检测到另一个实例在操作同一交易对
+	EventTypeDailySnapshot    EventType = "daily_snapshot"     // 每日收盘快照已生成
+	EventTypeStandbyTakeover  EventType = "standby_takeover"   // 主实例失联，备用实例接管交易
 	
 	// 系统状态事件
 	EventTypeError       EventType = "error"
@@ -75,12 +107,21 @@ func GetEventSeverity(eventType EventType) EventSeverity {
 		EventTypeStopLoss,
 		EventTypeMarginInsufficient,
 		EventTypeAllocationExceeded,
+		EventTypeLiquidationRisk,
+		EventTypeReconcileDivergence,
+		EventTypeSymbolDelisting,
 		EventTypeWebSocketDisconnected,
+		EventTypeUserStreamUnhealthy,
 		EventTypeAPIServerError,
 		EventTypeAPIAuthFailed,
+		EventTypeAPIKeyPermissionLost,
 		EventTypeSystemCPUHigh,
 		EventTypeSystemMemoryHigh,
 		EventTypeSystemDiskFull,
+		EventTypeGoroutinePanic,
+		EventTypeWatchdogFailure,
+		EventTypeInstanceConflict,
+		EventTypeStandbyTakeover,
 		EventTypeSystemStop,
 		EventTypeOrderFailed:
 		return SeverityCritical
@@ -91,9 +132,26 @@ func GetEventSeverity(eventType EventType) EventSeverity {
 		EventTypeConnectionTimeout,
 		EventTypePriceVolatility,
 		EventTypePriceAnomaly,
+		EventTypeOpenInterestSpike,
 		EventTypeRiskRecovered,
 		EventTypeAPIBadRequest,
 		EventTypePrecisionAdjustment,
+		EventTypeExecutionAnomaly,
+		EventTypeGridRecentered,
+		EventTypeManualPositionAdjust,
+		EventTypeProfitGoalReached,
+		EventTypeDailyLossLimit,
+		EventTypeStrategyLossBreaker,
+		EventTypeTurnoverBudgetExhausted,
+		EventTypePriceBandViolation,
+		EventTypeMinNotionalChanged,
+		EventTypePnLDivergence,
+		EventTypeExchangeMaintenance,
+		EventTypeExchangeMaintenanceEnd,
+		EventTypeAPIKeyChanged,
+		EventTypeAPIKeyExpiring,
+		EventTypeOrderStreamResync,
+		EventTypeListenKeyRenewed,
 		EventTypeError:
 		return SeverityWarning
 		
@@ -104,7 +162,10 @@ func GetEventSeverity(eventType EventType) EventSeverity {
 		EventTypePositionOpened,
 		EventTypePositionClosed,
 		EventTypeTakeProfit,
+		EventTypeSymbolListed,
 		EventTypeWebSocketReconnected,
+		EventTypeCapitalRebalanced,
+		EventTypeDailySnapshot,
 		EventTypeSystemStart:
 		return SeverityInfo
 		
@@ -135,28 +196,34 @@ const (
 // GetEventSource 根据事件类型获取事件源
 func GetEventSource(eventType EventType) EventSource {
 	switch eventType {
-	case EventTypeOrderPlaced, EventTypeOrderFilled, EventTypeOrderCanceled, EventTypeOrderFailed:
+	case EventTypeOrderPlaced, EventTypeOrderFilled, EventTypeOrderCanceled, EventTypeOrderFailed, EventTypeOrderStreamResync:
 		return SourceExchange
 		
-	case EventTypePositionOpened, EventTypePositionClosed:
+	case EventTypePositionOpened, EventTypePositionClosed, EventTypeSymbolListed, EventTypeSymbolDelisting,
+		EventTypeMinNotionalChanged, EventTypeExchangeMaintenance, EventTypeExchangeMaintenanceEnd:
 		return SourceExchange
 		
 	case EventTypeRiskTriggered, EventTypeRiskRecovered, EventTypeStopLoss, EventTypeTakeProfit,
-		EventTypeMarginInsufficient, EventTypeAllocationExceeded:
+		EventTypeMarginInsufficient, EventTypeAllocationExceeded, EventTypeLiquidationRisk, EventTypeReconcileDivergence,
+		EventTypeManualPositionAdjust, EventTypeProfitGoalReached, EventTypeDailyLossLimit, EventTypeStrategyLossBreaker,
+		EventTypeTurnoverBudgetExhausted, EventTypePriceBandViolation, EventTypePnLDivergence:
 		return SourceRisk
 		
-	case EventTypeWebSocketDisconnected, EventTypeWebSocketReconnected,
+	case EventTypeWebSocketDisconnected, EventTypeWebSocketReconnected, EventTypeUserStreamUnhealthy, EventTypeListenKeyRenewed,
 		EventTypeAPIRequestFailed, EventTypeConnectionTimeout:
 		return SourceNetwork
 		
-	case EventTypeAPIRateLimited, EventTypeAPIServerError, EventTypeAPIAuthFailed, EventTypeAPIBadRequest:
+	case EventTypeAPIRateLimited, EventTypeAPIServerError, EventTypeAPIAuthFailed, EventTypeAPIBadRequest,
+		EventTypeAPIKeyPermissionLost, EventTypeAPIKeyChanged, EventTypeAPIKeyExpiring:
 		return SourceAPI
 		
-	case EventTypePriceVolatility, EventTypePriceAnomaly, EventTypePrecisionAdjustment:
+	case EventTypePriceVolatility, EventTypePriceAnomaly, EventTypePrecisionAdjustment, EventTypeExecutionAnomaly,
+		EventTypeGridRecentered, EventTypeOpenInterestSpike, EventTypeCapitalRebalanced:
 		return SourceStrategy
 		
-	case EventTypeSystemCPUHigh, EventTypeSystemMemoryHigh, EventTypeSystemDiskFull,
-		EventTypeSystemStart, EventTypeSystemStop, EventTypeError:
+	case EventTypeSystemCPUHigh, EventTypeSystemMemoryHigh, EventTypeSystemDiskFull, EventTypeGoroutinePanic,
+		EventTypeWatchdogFailure, EventTypeInstanceConflict, EventTypeStandbyTakeover, EventTypeSystemStart, EventTypeSystemStop, EventTypeError,
+		EventTypeDailySnapshot:
 		return SourceSystem
 		
 	default:
@@ -172,10 +239,14 @@ func GetEventTitle(eventType EventType) string {
 		EventTypeOrderFilled:   "订单已成交",
 		EventTypeOrderCanceled: "订单已取消",
 		EventTypeOrderFailed:   "订单失败",
+		EventTypeOrderStreamResync: "订单流断线补查",
 		
 		// 持仓相关
 		EventTypePositionOpened: "持仓已开仓",
 		EventTypePositionClosed: "持仓已平仓",
+		EventTypeSymbolListed:   "新交易对上线",
+		EventTypeSymbolDelisting: "交易对下架/变更",
+		EventTypeMinNotionalChanged: "最小下单金额变更",
 		
 		// 风控相关
 		EventTypeRiskTriggered:      "风控触发",
@@ -184,28 +255,55 @@ func GetEventTitle(eventType EventType) string {
 		EventTypeTakeProfit:         "止盈触发",
 		EventTypeMarginInsufficient: "保证金不足",
 		EventTypeAllocationExceeded: "资金分配超限",
+		EventTypeLiquidationRisk:     "接近强平价",
+		EventTypeReconcileDivergence: "对账持仓严重偏差",
+		EventTypeManualPositionAdjust: "人工修正持仓",
+		EventTypeProfitGoalReached:    "达成盈利目标",
+		EventTypeDailyLossLimit:       "当日亏损达到上限",
+		EventTypeStrategyLossBreaker:  "策略亏损熔断",
+		EventTypeTurnoverBudgetExhausted: "策略交易额度用尽",
+		EventTypePriceBandViolation:   "订单超出价格带",
+		EventTypePnLDivergence:        "盈亏对账偏差",
 		
 		// 网络相关
 		EventTypeWebSocketDisconnected: "WebSocket 断开连接",
 		EventTypeWebSocketReconnected:  "WebSocket 重新连接",
+		EventTypeUserStreamUnhealthy:   "用户数据流异常",
+		EventTypeListenKeyRenewed:      "listenKey 已更换",
 		EventTypeAPIRequestFailed:      "API 请求失败",
 		EventTypeConnectionTimeout:     "连接超时",
+		EventTypeExchangeMaintenance:    "交易所维护中",
+		EventTypeExchangeMaintenanceEnd: "交易所维护结束",
 		
 		// API 错误
 		EventTypeAPIRateLimited: "API 限流",
 		EventTypeAPIServerError: "API 服务器错误",
 		EventTypeAPIAuthFailed:  "API 认证失败",
 		EventTypeAPIBadRequest:  "API 请求错误",
+
+		// API 密钥状态
+		EventTypeAPIKeyPermissionLost: "API 密钥失去交易权限",
+		EventTypeAPIKeyChanged:        "API 密钥限制变更",
+		EventTypeAPIKeyExpiring:       "API 密钥即将过期",
 		
 		// 价格波动
 		EventTypePriceVolatility: "价格大幅波动",
 		EventTypePriceAnomaly:    "价格异常",
+		EventTypeOpenInterestSpike: "持仓量突变",
 		EventTypePrecisionAdjustment: "下单精度异常",
+		EventTypeExecutionAnomaly:    "成交异常",
+		EventTypeGridRecentered:      "网格重新居中",
+		EventTypeCapitalRebalanced:   "策略资金再平衡",
 		
 		// 系统资源
 		EventTypeSystemCPUHigh:    "CPU 使用率过高",
 		EventTypeSystemMemoryHigh: "内存使用率过高",
 		EventTypeSystemDiskFull:   "磁盘空间不足",
+		EventTypeGoroutinePanic:   "协程崩溃",
+		EventTypeWatchdogFailure:  "守护协程停止重启",
+		EventTypeInstanceConflict: "检测到重复实例",
+		EventTypeStandbyTakeover:  "备用实例接管",
+		EventTypeDailySnapshot:    "每日收盘快照",
 		
 		// 系统状态
 		EventTypeError:       "系统错误",
@@ -223,6 +321,9 @@ func GetEventTitle(eventType EventType) string {
 type EventBus struct {
 	eventCh    chan *Event
 	bufferSize int
+
+	listenerMu sync.RWMutex
+	listeners  []func(*Event)
 }
 
 // NewEventBus 创建事件总线
@@ -247,6 +348,12 @@ func (eb *EventBus) Publish(event *Event) {
 		event.Timestamp = time.Now()
 	}
 
+	eb.listenerMu.RLock()
+	for _, listener := range eb.listeners {
+		listener(event)
+	}
+	eb.listenerMu.RUnlock()
+
 	select {
 	case eb.eventCh <- event:
 		// 成功发布
@@ -256,6 +363,14 @@ func (eb *EventBus) Publish(event *Event) {
 	}
 }
 
+// AddListener 注册事件监听器（如热备复制通道），Publish 时同步调用，监听器必须非阻塞
+// Subscribe 返回的 channel 只能由事件中心消费，其它模块需要旁路获取事件时使用监听器
+func (eb *EventBus) AddListener(listener func(*Event)) {
+	eb.listenerMu.Lock()
+	eb.listeners = append(eb.listeners, listener)
+	eb.listenerMu.Unlock()
+}
+
 // Subscribe 订阅事件（返回 channel）
 func (eb *EventBus) Subscribe() <-chan *Event {
 	return eb.eventCh
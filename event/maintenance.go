@@ -0,0 +1,44 @@
+package event
+
+import (
+	"strings"
+	"sync"
+)
+
+// 处于维护窗口中的交易所（由维护窗口监控设置）
+var (
+	maintenanceMu        sync.RWMutex
+	maintenanceExchanges = make(map[string]bool)
+)
+
+// SetExchangeMaintenance 标记交易所是否处于维护中，维护期间预期内的网络类事件不再通知
+func SetExchangeMaintenance(exchange string, active bool) {
+	key := strings.ToLower(exchange)
+	maintenanceMu.Lock()
+	defer maintenanceMu.Unlock()
+	if active {
+		maintenanceExchanges[key] = true
+	} else {
+		delete(maintenanceExchanges, key)
+	}
+}
+
+// IsExchangeInMaintenance 交易所是否处于维护中
+func IsExchangeInMaintenance(exchange string) bool {
+	if exchange == "" {
+		return false
+	}
+	maintenanceMu.RLock()
+	defer maintenanceMu.RUnlock()
+	return maintenanceExchanges[strings.ToLower(exchange)]
+}
+
+// isExpectedDuringMaintenance 维护期间预期会出现的事件（断线、请求失败等）
+func isExpectedDuringMaintenance(eventType EventType) bool {
+	switch eventType {
+	case EventTypeWebSocketDisconnected, EventTypeWebSocketReconnected,
+		EventTypeAPIRequestFailed, EventTypeConnectionTimeout, EventTypeAPIServerError:
+		return true
+	}
+	return false
+}
@@ -16,6 +16,8 @@ import (
 	"quantmesh/logger"
 	"quantmesh/utils"
 
+	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/common"
 	"github.com/adshao/go-binance/v2/futures"
 )
 
@@ -80,14 +82,15 @@ type Order struct {
 }
 
 type Position struct {
-	Symbol         string
-	Size           float64
-	EntryPrice     float64
-	MarkPrice      float64
-	UnrealizedPNL  float64
-	Leverage       int
-	MarginType     string
-	IsolatedMargin float64
+	Symbol           string
+	Size             float64
+	EntryPrice       float64
+	MarkPrice        float64
+	UnrealizedPNL    float64
+	Leverage         int
+	MarginType       string
+	IsolatedMargin   float64
+	LiquidationPrice float64
 }
 
 type Account struct {
@@ -141,6 +144,7 @@ type APIPermissions struct {
 	AllowedIPs    []string
 	APIKeyName    string
 	CreateTime    int64
+	ExpireTime    int64 // 交易权限过期时间（Unix 时间戳，0 表示不过期）
 	SecurityScore int
 	RiskLevel     string
 }
@@ -697,17 +701,19 @@ func (b *BinanceAdapter) GetPositions(ctx context.Context, symbol string) ([]*Po
 				unrealizedPNL, _ := strconv.ParseFloat(pos.UnRealizedProfit, 64)
 				markPrice, _ := strconv.ParseFloat(pos.MarkPrice, 64)
 				isolatedMargin, _ := strconv.ParseFloat(pos.IsolatedMargin, 64)
+				liquidationPrice, _ := strconv.ParseFloat(pos.LiquidationPrice, 64)
 				leverage, _ := strconv.Atoi(pos.Leverage)
 
 				result = append(result, &Position{
-					Symbol:         pos.Symbol,
-					Size:           posAmt,
-					EntryPrice:     entryPrice,
-					MarkPrice:      markPrice,
-					UnrealizedPNL:  unrealizedPNL,
-					Leverage:       leverage,
-					MarginType:     pos.MarginType,
-					IsolatedMargin: isolatedMargin,
+					Symbol:           pos.Symbol,
+					Size:             posAmt,
+					EntryPrice:       entryPrice,
+					MarkPrice:        markPrice,
+					UnrealizedPNL:    unrealizedPNL,
+					Leverage:         leverage,
+					MarginType:       pos.MarginType,
+					IsolatedMargin:   isolatedMargin,
+					LiquidationPrice: liquidationPrice,
 				})
 			}
 			return result, nil
@@ -788,6 +794,13 @@ func (b *BinanceAdapter) StartOrderStream(ctx context.Context, callback func(int
 		}
 		callback(genericUpdate)
 	}
+	// 断线重连通知和用户数据流告警原样传递，由 exchange 包装层转换为通用格式
+	b.wsManager.SetReconnectHandler(func(r StreamReconnect) {
+		callback(r)
+	})
+	b.wsManager.SetAlertHandler(func(a StreamAlert) {
+		callback(a)
+	})
 	return b.wsManager.Start(ctx, localCallback)
 }
 
@@ -835,6 +848,8 @@ func (b *BinanceAdapter) StartKlineStream(ctx context.Context, symbols []string,
 func (b *BinanceAdapter) StopKlineStream() error {
 	if b.klineWSManager != nil {
 		b.klineWSManager.Stop()
+		// 停止后丢弃管理器，再次启动时使用新的订阅列表重建（风控篮子刷新）
+		b.klineWSManager = nil
 	}
 	return nil
 }
@@ -919,6 +934,214 @@ func (b *BinanceAdapter) GetFundingRate(ctx context.Context, symbol string) (flo
 	return fundingRate, nil
 }
 
+// OpenInterestStat 持仓量与大户多空比（临时定义，避免循环导入）
+type OpenInterestStat struct {
+	Symbol            string
+	OpenInterest      float64
+	OpenInterestValue float64
+	LongShortRatio    float64
+	LongPercent       float64
+	ShortPercent      float64
+	Time              time.Time
+}
+
+// GetOpenInterestStat 获取持仓量与大户持仓多空比
+// API: GET /fapi/v1/openInterest, GET /fapi/v1/premiumIndex, GET /futures/data/topLongShortPositionRatio
+func (b *BinanceAdapter) GetOpenInterestStat(ctx context.Context, symbol string) (*OpenInterestStat, error) {
+	oi, err := b.client.NewGetOpenInterestService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取持仓量失败: %w", err)
+	}
+	openInterest, err := strconv.ParseFloat(oi.OpenInterest, 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析持仓量失败: %w", err)
+	}
+
+	stat := &OpenInterestStat{
+		Symbol:       symbol,
+		OpenInterest: openInterest,
+		Time:         time.UnixMilli(oi.Time),
+	}
+	if oi.Time == 0 {
+		stat.Time = time.Now()
+	}
+
+	// 标记价格和多空比获取失败不影响持仓量本身
+	if indexes, err := b.client.NewPremiumIndexService().Symbol(symbol).Do(ctx); err != nil {
+		logger.Warn("⚠️ [Binance] 获取 %s 标记价格失败: %v", symbol, err)
+	} else if len(indexes) > 0 {
+		if markPrice, err := strconv.ParseFloat(indexes[0].MarkPrice, 64); err == nil {
+			stat.OpenInterestValue = openInterest * markPrice
+		}
+	}
+
+	ratios, err := b.client.NewTopLongShortPositionRatioService().Symbol(symbol).Period("5m").Limit(1).Do(ctx)
+	if err != nil {
+		logger.Warn("⚠️ [Binance] 获取 %s 大户多空比失败: %v", symbol, err)
+	} else if len(ratios) > 0 {
+		stat.LongShortRatio, _ = strconv.ParseFloat(ratios[0].LongShortRatio, 64)
+		stat.LongPercent, _ = strconv.ParseFloat(ratios[0].LongAccount, 64)
+		stat.ShortPercent, _ = strconv.ParseFloat(ratios[0].ShortAccount, 64)
+	}
+	return stat, nil
+}
+
+// IncomeRecord 账户流水（临时定义，避免循环导入）
+type IncomeRecord struct {
+	Symbol     string
+	IncomeType string
+	Asset      string
+	Amount     float64
+	TranID     string
+	Time       time.Time
+}
+
+// GetIncomeHistory 查询账户流水（资金费、手续费、已实现盈亏等）
+// API: GET /fapi/v1/income，单次最多返回 1000 条
+func (b *BinanceAdapter) GetIncomeHistory(ctx context.Context, symbol, incomeType string, startTime, endTime time.Time) ([]*IncomeRecord, error) {
+	svc := b.client.NewGetIncomeHistoryService().Limit(1000)
+	if symbol != "" {
+		svc = svc.Symbol(symbol)
+	}
+	if incomeType != "" {
+		svc = svc.IncomeType(incomeType)
+	}
+	if !startTime.IsZero() {
+		svc = svc.StartTime(startTime.UnixMilli())
+	}
+	if !endTime.IsZero() {
+		svc = svc.EndTime(endTime.UnixMilli())
+	}
+
+	incomes, err := svc.Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取账户流水失败: %w", err)
+	}
+
+	records := make([]*IncomeRecord, 0, len(incomes))
+	for _, income := range incomes {
+		amount, err := strconv.ParseFloat(income.Income, 64)
+		if err != nil {
+			logger.Warn("⚠️ [Binance] 解析流水金额失败: %s, %v", income.Income, err)
+			continue
+		}
+		records = append(records, &IncomeRecord{
+			Symbol:     income.Symbol,
+			IncomeType: income.IncomeType,
+			Asset:      income.Asset,
+			Amount:     amount,
+			TranID:     strconv.FormatInt(income.TranID, 10),
+			Time:       time.UnixMilli(income.Time),
+		})
+	}
+	return records, nil
+}
+
+// Ticker24h 24 小时行情统计（临时定义，避免循环导入）
+type Ticker24h struct {
+	Symbol      string
+	LastPrice   float64
+	Volume      float64
+	QuoteVolume float64
+}
+
+// GetTickers24h 查询全部合约的 24 小时行情统计
+// API: GET /fapi/v1/ticker/24hr（不带 symbol，权重 40）
+func (b *BinanceAdapter) GetTickers24h(ctx context.Context) ([]*Ticker24h, error) {
+	stats, err := b.client.NewListPriceChangeStatsService().Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取24小时行情失败: %w", err)
+	}
+
+	tickers := make([]*Ticker24h, 0, len(stats))
+	for _, s := range stats {
+		lastPrice, _ := strconv.ParseFloat(s.LastPrice, 64)
+		volume, _ := strconv.ParseFloat(s.Volume, 64)
+		quoteVolume, err := strconv.ParseFloat(s.QuoteVolume, 64)
+		if err != nil {
+			continue
+		}
+		tickers = append(tickers, &Ticker24h{
+			Symbol:      s.Symbol,
+			LastPrice:   lastPrice,
+			Volume:      volume,
+			QuoteVolume: quoteVolume,
+		})
+	}
+	return tickers, nil
+}
+
+// TransferFromFutures 将 U 本位合约账户的资产划转到现货或资金账户
+// API: POST /sapi/v1/asset/transfer（万向划转，需开启万向划转权限，测试网不支持）
+func (b *BinanceAdapter) TransferFromFutures(ctx context.Context, asset string, amount float64, toWallet string) (string, error) {
+	if b.useTestnet {
+		return "", fmt.Errorf("测试网不支持账户划转")
+	}
+	transferType := binance.UserUniversalTransferTypeUmFuturesToMain
+	switch toWallet {
+	case "SPOT":
+	case "FUNDING":
+		transferType = binance.UserUniversalTransferTypeUmFuturesToFunding
+	default:
+		return "", fmt.Errorf("不支持的目标账户: %s", toWallet)
+	}
+
+	resp, err := binance.NewClient(b.client.APIKey, b.client.SecretKey).NewUserUniversalTransferService().
+		Type(transferType).
+		Asset(asset).
+		Amount(strconv.FormatFloat(amount, 'f', 8, 64)).
+		Do(ctx)
+	if err != nil {
+		return "", fmt.Errorf("账户划转失败: %w", err)
+	}
+	return strconv.FormatInt(resp.ID, 10), nil
+}
+
+// bnbFeeDiscount 币安 U 本位合约开启 BNB 抵扣后的手续费折扣（9 折）
+const bnbFeeDiscount = 0.1
+
+// CommissionRate 账户手续费率（临时定义，避免循环导入）
+type CommissionRate struct {
+	Symbol       string
+	MakerRate    float64
+	TakerRate    float64
+	FeeDiscount  bool
+	DiscountRate float64
+	UpdatedAt    time.Time
+}
+
+// GetCommissionRate 查询账户实际手续费率和 BNB 抵扣状态
+// API: GET /fapi/v1/commissionRate, GET /fapi/v1/feeBurn
+func (b *BinanceAdapter) GetCommissionRate(ctx context.Context, symbol string) (*CommissionRate, error) {
+	rate, err := b.client.NewCommissionRateService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取手续费率失败: %w", err)
+	}
+	maker, err := strconv.ParseFloat(rate.MakerCommissionRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析 Maker 费率失败: %w", err)
+	}
+	taker, err := strconv.ParseFloat(rate.TakerCommissionRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析 Taker 费率失败: %w", err)
+	}
+
+	result := &CommissionRate{
+		Symbol:       symbol,
+		MakerRate:    maker,
+		TakerRate:    taker,
+		DiscountRate: bnbFeeDiscount,
+		UpdatedAt:    time.Now(),
+	}
+	// BNB 抵扣状态查询失败不影响费率本身，按未开启处理
+	if feeBurn, err := b.client.NewGetFeeBurnService().Do(ctx); err != nil {
+		logger.Warn("⚠️ [Binance] 查询 BNB 抵扣状态失败: %v", err)
+	} else {
+		result.FeeDiscount = feeBurn.FeeBurn
+	}
+	return result, nil
+}
+
 // GetSpotPrice 获取现货市场价格
 func (b *BinanceAdapter) GetSpotPrice(ctx context.Context, symbol string) (float64, error) {
 	// 使用币安现货API获取价格
@@ -961,6 +1184,15 @@ func (b *BinanceAdapter) GetSpotPrice(ctx context.Context, symbol string) (float
 	return price, nil
 }
 
+// GetServerTime 查询服务器时间
+func (b *BinanceAdapter) GetServerTime(ctx context.Context) (time.Time, error) {
+	ms, err := b.client.NewServerTimeService().Do(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("获取服务器时间失败: %w", err)
+	}
+	return time.UnixMilli(ms), nil
+}
+
 // CheckAPIPermissions 检查 API 密钥权限
 func (b *BinanceAdapter) CheckAPIPermissions(ctx context.Context) (*APIPermissions, error) {
 	permissions := &APIPermissions{
@@ -973,7 +1205,11 @@ func (b *BinanceAdapter) CheckAPIPermissions(ctx context.Context) (*APIPermissio
 	_, err := b.client.NewGetAccountService().Do(ctx)
 	if err == nil {
 		permissions.CanTrade = true
-		logger.Info("✅ [Binance] API 具有交易权限")
+		logger.Debug("✅ [Binance] API 具有交易权限")
+	} else if apiErr, ok := err.(*common.APIError); ok && isPermissionErrorCode(apiErr.Code) {
+		// -2015: 密钥无效、IP 不在白名单或未开启合约权限；-2014/-1022: 密钥格式或签名错误
+		permissions.CanRead = false
+		logger.Error("🚨 [Binance] API 密钥被拒绝 (code=%d): %s", apiErr.Code, apiErr.Message)
 	} else {
 		logger.Warn("⚠️ [Binance] API 可能没有交易权限或调用失败: %v", err)
 		// 即使失败也继续，可能是网络问题
@@ -987,10 +1223,22 @@ func (b *BinanceAdapter) CheckAPIPermissions(ctx context.Context) (*APIPermissio
 	permissions.CanTransfer = false
 
 	// 检查 IP 限制
-	// 币安 API 没有直接查询 IP 限制的接口
-	// 如果设置了 IP 白名单，从非白名单 IP 调用会返回 -2015 错误
-	// 这里我们假设能成功调用说明 IP 是允许的或没有限制
-	permissions.IPRestricted = false // 无法直接判断，需要用户在交易所后台确认
+	// 主网可通过现货接口 /sapi/v1/account/apiRestrictions 查询密钥限制（测试网不支持）
+	// 查询失败时无法判断，需要用户在交易所后台确认
+	permissions.IPRestricted = false
+	if !b.useTestnet && permissions.CanRead {
+		restrictions, err := binance.NewClient(b.client.APIKey, b.client.SecretKey).NewGetAPIKeyPermission().Do(ctx)
+		if err != nil {
+			logger.Warn("⚠️ [Binance] 查询 API 密钥限制失败: %v", err)
+		} else {
+			permissions.CanTrade = permissions.CanTrade && restrictions.EnableFutures
+			permissions.CanWithdraw = restrictions.EnableWithdrawals
+			permissions.CanTransfer = restrictions.EnableInternalTransfer || restrictions.PermitsUniversalTransfer
+			permissions.IPRestricted = restrictions.IPRestrict
+			permissions.CreateTime = int64(restrictions.CreateTime / 1000)
+			permissions.ExpireTime = int64(restrictions.TradingAuthorityExpirationTime / 1000)
+		}
+	}
 
 	// 计算安全评分
 	permissions.SecurityScore = 100
@@ -1012,8 +1260,17 @@ func (b *BinanceAdapter) CheckAPIPermissions(ctx context.Context) (*APIPermissio
 		permissions.RiskLevel = "high"
 	}
 
-	logger.Info("🔐 [Binance] API 权限检测完成: 交易=%v, 提现=%v, 安全评分=%d, 风险等级=%s",
+	logger.Debug("🔐 [Binance] API 权限检测完成: 交易=%v, 提现=%v, 安全评分=%d, 风险等级=%s",
 		permissions.CanTrade, permissions.CanWithdraw, permissions.SecurityScore, permissions.RiskLevel)
 
 	return permissions, nil
 }
+
+// isPermissionErrorCode 判断币安错误码是否表示密钥被拒绝（而非网络或限流问题）
+func isPermissionErrorCode(code int64) bool {
+	switch code {
+	case -2015, -2014, -1022:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,454 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"quantmesh/exchange/wsutil"
+	"quantmesh/logger"
+	"quantmesh/metrics"
+
+	"github.com/gorilla/websocket"
+)
+
+// 组合流（/stream?streams=a/b/c）限制，参见币安合约 WebSocket 文档
+const (
+	maxStreamsPerConnection = 200                    // 单连接最多订阅的流数量
+	maxStreamsPerRequest    = 50                     // 单条 SUBSCRIBE 消息携带的流数量（控制消息长度）
+	controlMessageInterval  = 150 * time.Millisecond // 控制消息最小间隔（币安限制每秒 10 条）
+)
+
+// streamHandler 处理组合流中某个流的 data 负载
+type streamHandler func(data json.RawMessage)
+
+// CombinedStreamManager 币安公共行情组合流管理器
+// 多个交易对、多种行情流共用少量连接：新增订阅通过 SUBSCRIBE 消息追加到已有连接，
+// 单连接超过交易所上限时自动分片到新连接，断线重连后按当前订阅重新订阅。
+type CombinedStreamManager struct {
+	baseURL        string // 组合流地址，如 wss://fstream.binance.com/stream
+	maxStreams     int
+	reconnectDelay time.Duration
+	pingInterval   time.Duration
+	pongWait       time.Duration
+
+	mu     sync.Mutex
+	shards []*streamShard
+	nextID int64
+}
+
+// streamShard 组合流的一条连接
+type streamShard struct {
+	manager *CombinedStreamManager
+	index   int
+
+	mu       sync.Mutex
+	conn     *websocket.Conn
+	handlers map[string]map[int64]streamHandler // stream -> 订阅ID -> 处理函数
+	running  bool
+	requests int64 // SUBSCRIBE/UNSUBSCRIBE 请求 ID
+
+	writeMu  sync.Mutex
+	lastSend time.Time
+}
+
+var (
+	sharedStreamsMu sync.Mutex
+	sharedStreams   = make(map[bool]*CombinedStreamManager)
+)
+
+// sharedCombinedStreams 返回进程内共享的组合流管理器（主网、测试网各一个），
+// 使各交易对的适配器复用同一组连接
+func sharedCombinedStreams(useTestnet bool) *CombinedStreamManager {
+	sharedStreamsMu.Lock()
+	defer sharedStreamsMu.Unlock()
+
+	m, ok := sharedStreams[useTestnet]
+	if !ok {
+		baseURL := "wss://fstream.binance.com/stream"
+		if useTestnet {
+			baseURL = "wss://stream.binancefuture.com/stream"
+		}
+		m = NewCombinedStreamManager(baseURL)
+		sharedStreams[useTestnet] = m
+	}
+	return m
+}
+
+// NewCombinedStreamManager 创建组合流管理器
+func NewCombinedStreamManager(baseURL string) *CombinedStreamManager {
+	return &CombinedStreamManager{
+		baseURL:        baseURL,
+		maxStreams:     maxStreamsPerConnection,
+		reconnectDelay: 5 * time.Second,
+		pingInterval:   30 * time.Second,
+		pongWait:       60 * time.Second,
+	}
+}
+
+// Subscribe 订阅一个流（如 btcusdt@aggTrade，交易对需小写），ctx 取消时自动退订。
+// 同一个流可被多次订阅，消息会分发给所有订阅者；最后一个订阅者退订后才向交易所发送 UNSUBSCRIBE。
+func (m *CombinedStreamManager) Subscribe(ctx context.Context, stream string, handler func(data json.RawMessage)) {
+	m.mu.Lock()
+	m.nextID++
+	id := m.nextID
+	shard := m.shardFor(stream)
+	m.mu.Unlock()
+
+	shard.add(stream, id, handler)
+
+	go func() {
+		<-ctx.Done()
+		shard.remove(stream, id)
+	}()
+}
+
+// shardFor 选择流所在的连接：已订阅该流的连接优先，其次是未满的连接，都没有则新建分片（调用方持有 m.mu）
+func (m *CombinedStreamManager) shardFor(stream string) *streamShard {
+	for _, s := range m.shards {
+		if s.has(stream) {
+			return s
+		}
+	}
+	for _, s := range m.shards {
+		if s.size() < m.maxStreams {
+			return s
+		}
+	}
+	s := &streamShard{
+		manager:  m,
+		index:    len(m.shards),
+		handlers: make(map[string]map[int64]streamHandler),
+	}
+	m.shards = append(m.shards, s)
+	logger.Info("🔀 [Binance] 组合流新建分片 #%d（单连接上限 %d 个流）", s.index, m.maxStreams)
+	return s
+}
+
+// ConnectionCount 当前已建立的组合流连接数
+func (m *CombinedStreamManager) ConnectionCount() int {
+	m.mu.Lock()
+	shards := append([]*streamShard(nil), m.shards...)
+	m.mu.Unlock()
+
+	count := 0
+	for _, s := range shards {
+		s.mu.Lock()
+		if s.conn != nil {
+			count++
+		}
+		s.mu.Unlock()
+	}
+	return count
+}
+
+// StreamCount 当前订阅的流数量
+func (m *CombinedStreamManager) StreamCount() int {
+	m.mu.Lock()
+	shards := append([]*streamShard(nil), m.shards...)
+	m.mu.Unlock()
+
+	count := 0
+	for _, s := range shards {
+		count += s.size()
+	}
+	return count
+}
+
+func (s *streamShard) has(stream string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.handlers[stream]
+	return ok
+}
+
+func (s *streamShard) size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.handlers)
+}
+
+// add 登记订阅；新流在连接已建立时立即发送 SUBSCRIBE，连接未建立时由连接循环统一订阅
+func (s *streamShard) add(stream string, id int64, handler streamHandler) {
+	s.mu.Lock()
+	subs, exists := s.handlers[stream]
+	if !exists {
+		subs = make(map[int64]streamHandler)
+		s.handlers[stream] = subs
+	}
+	subs[id] = handler
+	conn := s.conn
+	start := !s.running
+	s.running = true
+	s.mu.Unlock()
+
+	if start {
+		go s.run()
+		return
+	}
+	if !exists && conn != nil {
+		if err := s.send(conn, "SUBSCRIBE", []string{stream}); err != nil {
+			logger.Warn("⚠️ [Binance] 组合流 #%d 订阅 %s 失败: %v，重连后重试", s.index, stream, err)
+			conn.Close()
+		}
+	}
+}
+
+// remove 移除订阅；流的最后一个订阅者退订时发送 UNSUBSCRIBE，分片无订阅时关闭连接
+func (s *streamShard) remove(stream string, id int64) {
+	s.mu.Lock()
+	subs, ok := s.handlers[stream]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	delete(subs, id)
+	if len(subs) > 0 {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.handlers, stream)
+	empty := len(s.handlers) == 0
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn == nil {
+		return
+	}
+	if empty {
+		conn.Close()
+		return
+	}
+	if err := s.send(conn, "UNSUBSCRIBE", []string{stream}); err != nil {
+		logger.Debug("[Binance] 组合流 #%d 退订 %s 失败: %v", s.index, stream, err)
+	}
+}
+
+// streams 当前订阅的流列表
+func (s *streamShard) streams() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]string, 0, len(s.handlers))
+	for stream := range s.handlers {
+		list = append(list, stream)
+	}
+	return list
+}
+
+// run 连接循环：按当前订阅建立连接，断线后重连并重新订阅；分片无订阅时退出
+func (s *streamShard) run() {
+	m := s.manager
+	streamType := fmt.Sprintf("combined_%d", s.index)
+	connected := false
+
+	for {
+		streams := s.streams()
+		if len(streams) == 0 {
+			s.mu.Lock()
+			// 退出前再检查一次，避免与并发的 add 竞争
+			if len(s.handlers) == 0 {
+				s.running = false
+				s.mu.Unlock()
+				logger.Debug("[Binance] 组合流 #%d 已无订阅，连接关闭", s.index)
+				return
+			}
+			s.mu.Unlock()
+			continue
+		}
+
+		// 订阅列表放进 URL，超出单条 URL 的部分通过 SUBSCRIBE 补齐
+		initial := streams
+		if len(initial) > maxStreamsPerRequest {
+			initial = initial[:maxStreamsPerRequest]
+		}
+		url := m.baseURL + "?streams=" + strings.Join(initial, "/")
+		conn, _, err := wsutil.Dialer().Dial(url, nil)
+		if err != nil {
+			logger.Warn("⚠️ [Binance] 组合流 #%d 连接失败: %v，%v后重试", s.index, err, m.reconnectDelay)
+			time.Sleep(m.reconnectDelay)
+			continue
+		}
+
+		if connected {
+			if pm := metrics.GetPrometheusMetrics(); pm != nil {
+				pm.RecordWebSocketReconnect("binance", streamType)
+			}
+		}
+		connected = true
+
+		// 登记连接，补订阅 URL 之外以及拨号期间新增的流
+		s.mu.Lock()
+		s.conn = conn
+		pending := make([]string, 0)
+		inURL := make(map[string]bool, len(initial))
+		for _, stream := range initial {
+			inURL[stream] = true
+		}
+		for stream := range s.handlers {
+			if !inURL[stream] {
+				pending = append(pending, stream)
+			}
+		}
+		s.mu.Unlock()
+
+		logger.Info("✅ [Binance] 组合流 #%d 已连接（%d 个流）", s.index, len(initial)+len(pending))
+		if pm := metrics.GetPrometheusMetrics(); pm != nil {
+			pm.SetWebSocketStatus("binance", streamType, true)
+		}
+
+		if err := s.subscribeAll(conn, pending); err != nil {
+			logger.Warn("⚠️ [Binance] 组合流 #%d 重新订阅失败: %v", s.index, err)
+			conn.Close()
+		}
+
+		done := make(chan struct{})
+		go s.pingLoop(conn, done)
+		s.readLoop(conn)
+		close(done)
+
+		s.mu.Lock()
+		if s.conn == conn {
+			s.conn = nil
+		}
+		remaining := len(s.handlers)
+		s.mu.Unlock()
+		conn.Close()
+
+		if pm := metrics.GetPrometheusMetrics(); pm != nil {
+			pm.SetWebSocketStatus("binance", streamType, false)
+		}
+		if remaining > 0 {
+			logger.Warn("⚠️ [Binance] 组合流 #%d 连接断开，%v后重连并重新订阅 %d 个流", s.index, m.reconnectDelay, remaining)
+			time.Sleep(m.reconnectDelay)
+		}
+	}
+}
+
+// subscribeAll 分批发送 SUBSCRIBE
+func (s *streamShard) subscribeAll(conn *websocket.Conn, streams []string) error {
+	for start := 0; start < len(streams); start += maxStreamsPerRequest {
+		end := start + maxStreamsPerRequest
+		if end > len(streams) {
+			end = len(streams)
+		}
+		if err := s.send(conn, "SUBSCRIBE", streams[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// send 发送 SUBSCRIBE/UNSUBSCRIBE 控制消息，按交易所限制控制发送频率
+func (s *streamShard) send(conn *websocket.Conn, method string, streams []string) error {
+	s.mu.Lock()
+	s.requests++
+	id := s.requests
+	s.mu.Unlock()
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if wait := controlMessageInterval - time.Since(s.lastSend); wait > 0 {
+		time.Sleep(wait)
+	}
+	s.lastSend = time.Now()
+
+	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return conn.WriteJSON(map[string]interface{}{
+		"method": method,
+		"params": streams,
+		"id":     id,
+	})
+}
+
+// pingLoop 心跳保活
+func (s *streamShard) pingLoop(conn *websocket.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(s.manager.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			s.writeMu.Lock()
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			err := conn.WriteMessage(websocket.PingMessage, nil)
+			s.writeMu.Unlock()
+			if err != nil {
+				logger.Warn("⚠️ [Binance] 组合流 #%d 发送Ping失败: %v", s.index, err)
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// readLoop 读取消息并按 stream 字段分发，连接断开时返回
+func (s *streamShard) readLoop(conn *websocket.Conn) {
+	pongWait := s.manager.pongWait
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	for {
+		messageType, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				logger.Warn("⚠️ [Binance] 组合流 #%d 异常关闭: %v", s.index, err)
+			} else {
+				logger.Debug("[Binance] 组合流 #%d 读取结束: %v", s.index, err)
+			}
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		// permessage-deflate 由连接层透明解压，这里只处理二进制压缩帧
+		message, err = wsutil.Decode(messageType, message)
+		if err != nil {
+			logger.Debug("[Binance] 组合流 #%d 解压消息失败: %v", s.index, err)
+			continue
+		}
+		s.dispatch(message)
+	}
+}
+
+// dispatch 将组合流消息 {"stream": "...", "data": {...}} 分发给订阅者
+func (s *streamShard) dispatch(message []byte) {
+	var msg struct {
+		Stream string          `json:"stream"`
+		Data   json.RawMessage `json:"data"`
+		ID     int64           `json:"id"`
+		Error  *struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil {
+		logger.Debug("[Binance] 解析组合流消息失败: %v", err)
+		return
+	}
+	if msg.Stream == "" {
+		// SUBSCRIBE/UNSUBSCRIBE 的应答
+		if msg.Error != nil {
+			logger.Warn("⚠️ [Binance] 组合流 #%d 请求 %d 失败: %d %s", s.index, msg.ID, msg.Error.Code, msg.Error.Msg)
+		}
+		return
+	}
+
+	s.mu.Lock()
+	subs := s.handlers[msg.Stream]
+	handlers := make([]streamHandler, 0, len(subs))
+	for _, h := range subs {
+		handlers = append(handlers, h)
+	}
+	s.mu.Unlock()
+
+	for _, h := range handlers {
+		h(msg.Data)
+	}
+}
@@ -0,0 +1,146 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeCombinedServer 模拟币安组合流：流一经订阅（URL 或 SUBSCRIBE）即推送一条 {"stream", "data": {"conn": 连接序号}}
+type fakeCombinedServer struct {
+	mu    sync.Mutex
+	conns []*websocket.Conn
+}
+
+func (f *fakeCombinedServer) handler(t *testing.T) http.HandlerFunc {
+	upgrader := websocket.Upgrader{}
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		f.mu.Lock()
+		f.conns = append(f.conns, conn)
+		seq := len(f.conns)
+		f.mu.Unlock()
+
+		var writeMu sync.Mutex
+		push := func(streams []string) {
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			for _, s := range streams {
+				conn.WriteJSON(map[string]interface{}{"stream": s, "data": map[string]int{"conn": seq}})
+			}
+		}
+		if q := r.URL.Query().Get("streams"); q != "" {
+			push(strings.Split(q, "/"))
+		}
+		for {
+			var req struct {
+				Method string   `json:"method"`
+				Params []string `json:"params"`
+				ID     int64    `json:"id"`
+			}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			if req.Method == "SUBSCRIBE" {
+				push(req.Params)
+			}
+		}
+	}
+}
+
+func (f *fakeCombinedServer) dropAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, c := range f.conns {
+		c.Close()
+	}
+}
+
+func TestCombinedStreamShardingAndResubscribe(t *testing.T) {
+	fake := &fakeCombinedServer{}
+	srv := httptest.NewServer(fake.handler(t))
+	defer srv.Close()
+
+	m := NewCombinedStreamManager("ws" + strings.TrimPrefix(srv.URL, "http") + "/stream")
+	m.maxStreams = 2
+	m.reconnectDelay = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	received := make(map[string][]int)
+	subscribe := func(stream string) {
+		m.Subscribe(ctx, stream, func(data json.RawMessage) {
+			var msg struct {
+				Conn int `json:"conn"`
+			}
+			json.Unmarshal(data, &msg)
+			mu.Lock()
+			received[stream] = append(received[stream], msg.Conn)
+			mu.Unlock()
+		})
+	}
+	streams := []string{"btcusdt@aggTrade", "ethusdt@aggTrade", "solusdt@aggTrade"}
+	for _, s := range streams {
+		subscribe(s)
+	}
+	// 同一个流重复订阅不占用新的名额
+	subscribe("btcusdt@aggTrade")
+
+	waitFor := func(desc string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(3 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timeout waiting for %s", desc)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	// fromConnAfter 每个流都收到了来自序号大于 seq 的连接的消息
+	fromConnAfter := func(seq int) func() bool {
+		return func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, s := range streams {
+				ok := false
+				for _, c := range received[s] {
+					if c > seq {
+						ok = true
+					}
+				}
+				if !ok {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	waitFor("initial messages", fromConnAfter(0))
+	if got := m.StreamCount(); got != 3 {
+		t.Errorf("StreamCount = %d, want 3", got)
+	}
+	if got := m.ConnectionCount(); got != 2 {
+		t.Errorf("ConnectionCount = %d, want 2 (3 streams, 2 per connection)", got)
+	}
+
+	// 断线后自动重连并重新订阅所有流
+	fake.dropAll()
+	waitFor("messages after reconnect", fromConnAfter(2))
+
+	cancel()
+	waitFor("unsubscribe on cancel", func() bool { return m.StreamCount() == 0 })
+}
@@ -0,0 +1,214 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"quantmesh/logger"
+	"quantmesh/metrics"
+
+	"github.com/adshao/go-binance/v2/common"
+)
+
+// userDataStreamType 用户数据流在 WebSocket 指标中的 stream_type 标签
+const userDataStreamType = "user_data"
+
+const (
+	// listenKeyValidity 币安 listenKey 有效期，每次保活后重新计时
+	listenKeyValidity = 60 * time.Minute
+	// keepAliveRetryDelay 保活失败后的重试间隔
+	keepAliveRetryDelay = time.Minute
+	// maxKeepAliveFailures 连续保活失败达到该次数后直接更换 listenKey
+	maxKeepAliveFailures = 3
+	// errCodeListenKeyNotExist 币安 listenKey 不存在（已过期）的错误码
+	errCodeListenKeyNotExist = -1125
+)
+
+// listenKey 更换原因
+const (
+	renewReasonExpired         = "expired"          // 收到 listenKeyExpired 事件或保活时提示 listenKey 不存在
+	renewReasonKeepAliveFailed = "keepalive_failed" // 连续保活失败
+	renewReasonStale           = "stale"            // 距上次成功保活即将超过有效期
+)
+
+// 用户数据流告警类型
+const (
+	StreamAlertKeepAliveFailed  = "keepalive_failed"
+	StreamAlertListenKeyExpired = "listen_key_expired"
+	StreamAlertListenKeyRenewed = "listen_key_renewed"
+	StreamAlertRenewFailed      = "listen_key_renew_failed"
+)
+
+// StreamAlert 用户数据流健康告警
+// listenKey 过期后连接不会断开，只是不再推送订单，属于静默丢失成交的故障，需要告警
+type StreamAlert struct {
+	Kind                string
+	Message             string
+	ConsecutiveFailures int
+}
+
+// SetAlertHandler 设置用户数据流告警回调（需在 Start 之前设置）
+func (w *WebSocketManager) SetAlertHandler(handler func(StreamAlert)) {
+	w.mu.Lock()
+	w.onAlert = handler
+	w.mu.Unlock()
+}
+
+// createListenKey 创建新的 listenKey
+func (w *WebSocketManager) createListenKey(ctx context.Context) error {
+	listenKey, err := w.client.NewStartUserStreamService().Do(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	w.mu.Lock()
+	w.listenKey = listenKey
+	w.listenKeyCreatedAt = now
+	w.lastKeepAliveAt = now
+	w.keepAliveFailures = 0
+	w.mu.Unlock()
+	logger.Debug("✅ [Binance] 已获取订单流listenKey: %s", listenKey)
+	return nil
+}
+
+// keepAliveListenKey 保持listenKey有效：定时保活，失败后缩短重试间隔，过期或多次失败时更换 listenKey 并重新订阅
+func (w *WebSocketManager) keepAliveListenKey(ctx context.Context) {
+	timer := time.NewTimer(w.keepAliveInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.stopC:
+			return
+		case reason := <-w.renewC:
+			w.renewListenKey(ctx, reason)
+		case <-timer.C:
+			w.keepAlive(ctx)
+		}
+
+		w.mu.RLock()
+		next := w.keepAliveInterval
+		if w.keepAliveFailures > 0 {
+			next = keepAliveRetryDelay
+		}
+		w.mu.RUnlock()
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(next)
+	}
+}
+
+// keepAlive 执行一次保活
+func (w *WebSocketManager) keepAlive(ctx context.Context) {
+	w.mu.RLock()
+	listenKey := w.listenKey
+	w.mu.RUnlock()
+
+	err := w.client.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(ctx)
+	w.mu.Lock()
+	if err == nil {
+		w.lastKeepAliveAt = time.Now()
+		w.keepAliveFailures = 0
+	} else {
+		w.keepAliveFailures++
+	}
+	failures := w.keepAliveFailures
+	sinceKeepAlive := time.Since(w.lastKeepAliveAt)
+	w.mu.Unlock()
+
+	metrics.GetPrometheusMetrics().RecordListenKeyKeepAlive("binance", err == nil, failures)
+	if err == nil {
+		logger.Debug("✅ [Binance] listenKey保活成功")
+		return
+	}
+
+	logger.Error("❌ [Binance] listenKey保活失败（连续 %d 次）: %v", failures, err)
+	w.alert(StreamAlert{
+		Kind:                StreamAlertKeepAliveFailed,
+		Message:             fmt.Sprintf("listenKey 保活失败（连续 %d 次）: %v", failures, err),
+		ConsecutiveFailures: failures,
+	})
+
+	switch {
+	case isListenKeyNotExist(err):
+		w.renewListenKey(ctx, renewReasonExpired)
+	case failures >= maxKeepAliveFailures:
+		w.renewListenKey(ctx, renewReasonKeepAliveFailed)
+	case sinceKeepAlive >= listenKeyValidity-keepAliveRetryDelay:
+		w.renewListenKey(ctx, renewReasonStale)
+	}
+}
+
+// requestRenew 请求保活协程更换 listenKey（已有待处理请求时忽略）
+func (w *WebSocketManager) requestRenew(reason string) {
+	select {
+	case w.renewC <- reason:
+	default:
+	}
+}
+
+// renewListenKey 创建新的 listenKey 并用新 key 重新订阅用户数据流
+// 重新订阅会经过一次断线重连，订单流会收到重连通知并补查期间可能丢失的成交
+func (w *WebSocketManager) renewListenKey(ctx context.Context, reason string) {
+	if err := w.createListenKey(ctx); err != nil {
+		w.mu.Lock()
+		w.keepAliveFailures++
+		failures := w.keepAliveFailures
+		w.mu.Unlock()
+		logger.Error("❌ [Binance] 更换listenKey失败（%s）: %v", reason, err)
+		w.alert(StreamAlert{
+			Kind:                StreamAlertRenewFailed,
+			Message:             fmt.Sprintf("更换 listenKey 失败（%s）: %v，稍后重试", reason, err),
+			ConsecutiveFailures: failures,
+		})
+		return
+	}
+
+	w.mu.Lock()
+	w.renewals++
+	stopC := w.wsStopC
+	w.mu.Unlock()
+	metrics.GetPrometheusMetrics().RecordListenKeyRenewal("binance", reason)
+	logger.Warn("🔑 [Binance] 已更换listenKey（%s），重新订阅用户数据流", reason)
+	w.alert(StreamAlert{Kind: StreamAlertListenKeyRenewed, Message: fmt.Sprintf("已更换 listenKey（%s）并重新订阅用户数据流", reason)})
+
+	// 关闭旧连接，listenUserDataStream 会使用新的 listenKey 重连
+	if stopC != nil {
+		select {
+		case stopC <- struct{}{}:
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// setConnected 记录用户数据流连接状态，stopC 为当前连接的停止通道（断开时为 nil）
+func (w *WebSocketManager) setConnected(stopC chan struct{}) {
+	w.mu.Lock()
+	w.wsStopC = stopC
+	w.mu.Unlock()
+	metrics.GetPrometheusMetrics().SetWebSocketStatus("binance", userDataStreamType, stopC != nil)
+}
+
+// alert 发送用户数据流告警
+func (w *WebSocketManager) alert(a StreamAlert) {
+	w.mu.RLock()
+	handler := w.onAlert
+	w.mu.RUnlock()
+	if handler != nil {
+		handler(a)
+	}
+}
+
+// isListenKeyNotExist 判断是否为 listenKey 不存在（已过期）错误
+func isListenKeyNotExist(err error) bool {
+	var apiErr *common.APIError
+	return errors.As(err, &apiErr) && apiErr.Code == errCodeListenKeyNotExist
+}
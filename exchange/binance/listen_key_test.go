@@ -0,0 +1,42 @@
+package binance
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/adshao/go-binance/v2/common"
+)
+
+func TestIsListenKeyNotExist(t *testing.T) {
+	if !isListenKeyNotExist(&common.APIError{Code: -1125, Message: "This listenKey does not exist."}) {
+		t.Error("-1125 应识别为 listenKey 不存在")
+	}
+	if !isListenKeyNotExist(fmt.Errorf("保活失败: %w", &common.APIError{Code: -1125})) {
+		t.Error("包装后的 -1125 错误也应识别")
+	}
+	if isListenKeyNotExist(&common.APIError{Code: -1001}) || isListenKeyNotExist(fmt.Errorf("timeout")) {
+		t.Error("其他错误不应识别为 listenKey 不存在")
+	}
+}
+
+func TestRequestRenewCoalesces(t *testing.T) {
+	w := NewWebSocketManager("", "", false)
+	var alerts []StreamAlert
+	w.SetAlertHandler(func(a StreamAlert) { alerts = append(alerts, a) })
+
+	w.requestRenew(renewReasonExpired)
+	w.requestRenew(renewReasonStale) // 已有待处理请求，忽略
+	if got := <-w.renewC; got != renewReasonExpired {
+		t.Errorf("应保留第一个更换原因, 得到 %s", got)
+	}
+	select {
+	case reason := <-w.renewC:
+		t.Errorf("重复的更换请求应被合并, 得到 %s", reason)
+	default:
+	}
+
+	w.alert(StreamAlert{Kind: StreamAlertKeepAliveFailed, ConsecutiveFailures: 1})
+	if len(alerts) != 1 || alerts[0].Kind != StreamAlertKeepAliveFailed {
+		t.Errorf("告警回调未收到: %+v", alerts)
+	}
+}
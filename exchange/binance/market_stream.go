@@ -0,0 +1,107 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"quantmesh/logger"
+)
+
+// BookLevel 盘口价位
+type BookLevel struct {
+	Price float64
+	Qty   float64
+}
+
+// DepthSnapshot 有限档位的盘口快照
+type DepthSnapshot struct {
+	Symbol    string
+	Bids      []BookLevel
+	Asks      []BookLevel
+	Timestamp int64
+}
+
+// AggTrade 归集逐笔成交
+type AggTrade struct {
+	Symbol     string
+	Price      float64
+	Qty        float64
+	BuyerMaker bool // true 表示卖方主动成交
+	Timestamp  int64
+}
+
+// StartDepthStream 启动有限档位盘口流（20 档，250ms 推送），经组合流与其他交易对共用连接
+// API: <symbol>@depth20@250ms
+func (b *BinanceAdapter) StartDepthStream(ctx context.Context, symbol string, callback func(*DepthSnapshot)) error {
+	stream := strings.ToLower(symbol) + "@depth20@250ms"
+	sharedCombinedStreams(b.useTestnet).Subscribe(ctx, stream, func(data json.RawMessage) {
+		var msg struct {
+			Symbol string     `json:"s"`
+			Time   int64      `json:"T"`
+			Bids   [][]string `json:"b"`
+			Asks   [][]string `json:"a"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			logger.Debug("解析盘口消息失败: %v", err)
+			return
+		}
+		callback(&DepthSnapshot{
+			Symbol:    msg.Symbol,
+			Bids:      parseBookLevels(msg.Bids),
+			Asks:      parseBookLevels(msg.Asks),
+			Timestamp: msg.Time,
+		})
+	})
+	return nil
+}
+
+// StartTradeStream 启动归集逐笔成交流，经组合流与其他交易对共用连接
+// API: <symbol>@aggTrade
+func (b *BinanceAdapter) StartTradeStream(ctx context.Context, symbol string, callback func(*AggTrade)) error {
+	stream := strings.ToLower(symbol) + "@aggTrade"
+	sharedCombinedStreams(b.useTestnet).Subscribe(ctx, stream, func(data json.RawMessage) {
+		var msg struct {
+			Symbol     string `json:"s"`
+			Price      string `json:"p"`
+			Qty        string `json:"q"`
+			Time       int64  `json:"T"`
+			BuyerMaker bool   `json:"m"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			logger.Debug("解析成交消息失败: %v", err)
+			return
+		}
+		price, err1 := strconv.ParseFloat(msg.Price, 64)
+		qty, err2 := strconv.ParseFloat(msg.Qty, 64)
+		if err1 != nil || err2 != nil {
+			return
+		}
+		callback(&AggTrade{
+			Symbol:     msg.Symbol,
+			Price:      price,
+			Qty:        qty,
+			BuyerMaker: msg.BuyerMaker,
+			Timestamp:  msg.Time,
+		})
+	})
+	return nil
+}
+
+// parseBookLevels 解析 [["价格","数量"], ...] 格式的盘口档位
+func parseBookLevels(raw [][]string) []BookLevel {
+	levels := make([]BookLevel, 0, len(raw))
+	for _, l := range raw {
+		if len(l) < 2 {
+			continue
+		}
+		price, err1 := strconv.ParseFloat(l[0], 64)
+		qty, err2 := strconv.ParseFloat(l[1], 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		levels = append(levels, BookLevel{Price: price, Qty: qty})
+	}
+	return levels
+}
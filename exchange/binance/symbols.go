@@ -0,0 +1,66 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// SymbolInfo 合约元数据（精度、币种、合约类型、下单过滤条件）
+type SymbolInfo struct {
+	Symbol           string
+	BaseAsset        string
+	QuoteAsset       string
+	ContractType     string
+	Status           string
+	PriceDecimals    int
+	QuantityDecimals int
+	TickSize         float64
+	StepSize         float64
+	MinQty           float64
+	MinNotional      float64
+	OnboardDate      time.Time
+	DeliveryDate     time.Time // 永续合约正常为 2100 年，宣布下架后改为实际下架时间
+}
+
+// ListSymbols 获取全部 U 本位合约的元数据
+// API: GET /fapi/v1/exchangeInfo
+func (b *BinanceAdapter) ListSymbols(ctx context.Context) ([]*SymbolInfo, error) {
+	exchangeInfo, err := b.client.NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易所信息失败: %w", err)
+	}
+
+	result := make([]*SymbolInfo, 0, len(exchangeInfo.Symbols))
+	for i := range exchangeInfo.Symbols {
+		s := &exchangeInfo.Symbols[i]
+		info := &SymbolInfo{
+			Symbol:           s.Symbol,
+			BaseAsset:        s.BaseAsset,
+			QuoteAsset:       s.QuoteAsset,
+			ContractType:     string(s.ContractType),
+			Status:           s.Status,
+			PriceDecimals:    s.PricePrecision,
+			QuantityDecimals: s.QuantityPrecision,
+		}
+		if s.OnboardDate > 0 {
+			info.OnboardDate = time.UnixMilli(s.OnboardDate)
+		}
+		if s.DeliveryDate > 0 {
+			info.DeliveryDate = time.UnixMilli(s.DeliveryDate)
+		}
+		if f := s.PriceFilter(); f != nil {
+			info.TickSize, _ = strconv.ParseFloat(f.TickSize, 64)
+		}
+		if f := s.LotSizeFilter(); f != nil {
+			info.StepSize, _ = strconv.ParseFloat(f.StepSize, 64)
+			info.MinQty, _ = strconv.ParseFloat(f.MinQuantity, 64)
+		}
+		if f := s.MinNotionalFilter(); f != nil {
+			info.MinNotional, _ = strconv.ParseFloat(f.Notional, 64)
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
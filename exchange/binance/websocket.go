@@ -10,9 +10,9 @@ import (
 	"time"
 
 	"quantmesh/logger"
+	"quantmesh/metrics"
 
 	"github.com/adshao/go-binance/v2/futures"
-	"github.com/gorilla/websocket"
 )
 
 // WebSocketManager 币安 WebSocket 订单流管理器
@@ -32,12 +32,32 @@ type WebSocketManager struct {
 	latestPrice float64
 	priceMu     sync.RWMutex
 
+	// 断线重连通知（用于补查断线期间丢失的订单推送）
+	onReconnect   func(StreamReconnect)
+	lastEventTime time.Time
+
+	// listenKey 生命周期（见 listen_key.go）
+	listenKeyCreatedAt time.Time
+	lastKeepAliveAt    time.Time
+	keepAliveFailures  int               // 连续保活失败次数
+	renewals           int               // 更换 listenKey 的次数
+	renewC             chan string       // 请求更换 listenKey（原因），由保活协程处理
+	wsStopC            chan struct{}     // 当前用户数据流连接的停止通道，更换 listenKey 后用于重新订阅
+	onAlert            func(StreamAlert) // 保活失败、listenKey 过期/更换时的告警回调
+
 	// 时间配置
 	reconnectDelay    time.Duration
 	keepAliveInterval time.Duration
 	closeTimeout      time.Duration
 }
 
+// StreamReconnect 订单流断线后重新连上的通知
+type StreamReconnect struct {
+	DisconnectedAt time.Time
+	ReconnectedAt  time.Time
+	LastEventTime  time.Time // 断线前最后一次收到用户数据事件的时间
+}
+
 // NewWebSocketManager 创建 WebSocket 管理器
 func NewWebSocketManager(apiKey, secretKey string, useTestnet bool) *WebSocketManager {
 	return &WebSocketManager{
@@ -51,6 +71,7 @@ func NewWebSocketManager(apiKey, secretKey string, useTestnet bool) *WebSocketMa
 		reconnectDelay:    5 * time.Second,
 		keepAliveInterval: 30 * time.Minute,
 		closeTimeout:      10 * time.Second,
+		renewC:            make(chan string, 1),
 	}
 }
 
@@ -66,12 +87,12 @@ func (w *WebSocketManager) Start(ctx context.Context, callback OrderUpdateCallba
 	w.mu.Unlock()
 
 	// 获取listenKey
-	listenKey, err := w.client.NewStartUserStreamService().Do(ctx)
-	if err != nil {
+	if err := w.createListenKey(ctx); err != nil {
+		w.mu.Lock()
+		w.isRunning = false
+		w.mu.Unlock()
 		return fmt.Errorf("获取listenKey失败: %v", err)
 	}
-	w.listenKey = listenKey
-	logger.Debug("✅ [Binance] 已获取订单流listenKey: %s", listenKey)
 
 	// 启动listenKey保活协程
 	go w.keepAliveListenKey(ctx)
@@ -82,99 +103,59 @@ func (w *WebSocketManager) Start(ctx context.Context, callback OrderUpdateCallba
 	return nil
 }
 
+// SetReconnectHandler 设置订单流断线重连回调（需在 Start 之前设置）
+func (w *WebSocketManager) SetReconnectHandler(handler func(StreamReconnect)) {
+	w.mu.Lock()
+	w.onReconnect = handler
+	w.mu.Unlock()
+}
+
 // StartPriceStream 启动价格流
 func (w *WebSocketManager) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
-	// 使用原生 WebSocket 连接（go-binance 的 WsAggTradeServe 有 Bug）
-	// 格式: wss://fstream.binance.com/ws/<symbol>@aggTrade (主网)
-	//       wss://stream.binancefuture.com/ws/<symbol>@aggTrade (测试网)
-
-	symbolLower := strings.ToLower(symbol)
-	var url string
-	if w.useTestnet {
-		url = fmt.Sprintf("wss://stream.binancefuture.com/ws/%s@aggTrade", symbolLower)
-		logger.Info("🌐 [Binance WS] 使用测试网 WebSocket: %s", url)
-	} else {
-		url = fmt.Sprintf("wss://fstream.binance.com/ws/%s@aggTrade", symbolLower)
-	}
+	// 使用原生 WebSocket 组合流（go-binance 的 WsAggTradeServe 有 Bug），与其他交易对共用连接
+	// 流名称: <symbol>@aggTrade
+	stream := strings.ToLower(symbol) + "@aggTrade"
 
 	// 使用通道等待首个价格
 	firstPriceCh := make(chan struct{})
-	firstPriceReceived := false
-
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				logger.Info("✅ [Binance] 价格流已停止")
-				return
-			default:
-			}
-
-			logger.Debug("🔗 [Binance] 正在连接 WebSocket: %s", url)
-
-			// 导入 gorilla/websocket
-			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
-			if err != nil {
-				logger.Error("❌ [Binance] WebSocket 连接失败: %v，5秒后重试", err)
-				time.Sleep(5 * time.Second)
-				continue
-			}
-
-			logger.Info("✅ [Binance] WebSocket 已连接: %s", url) // 读取消息循环
-			for {
-				select {
-				case <-ctx.Done():
-					conn.Close()
-					logger.Info("✅ [Binance] 价格流已停止")
-					return
-				default:
-				}
-
-				_, message, err := conn.ReadMessage()
-				if err != nil {
-					logger.Warn("⚠️ [Binance] WebSocket 读取错误: %v，正在重连", err)
-					conn.Close()
-					time.Sleep(2 * time.Second)
-					break // 跳出内层循环，重新连接
-				}
-
-				// 解析消息（只提取必要字段）
-				var event struct {
-					Symbol string `json:"s"`
-					Price  string `json:"p"`
-				}
-
-				if err := json.Unmarshal(message, &event); err != nil {
-					logger.Debug("解析消息失败: %v", err)
-					continue
-				}
-
-				price, err := strconv.ParseFloat(event.Price, 64)
-				if err != nil {
-					logger.Debug("解析价格失败: %v", err)
-					continue
-				} // 更新价格缓存
-				w.priceMu.Lock()
-				w.latestPrice = price
-				w.priceMu.Unlock()
-
-				// 通知首个价格已接收
-				if !firstPriceReceived {
-					firstPriceReceived = true
-					logger.Debug("✅ [Binance] 收到首个价格: %.2f", price)
-					close(firstPriceCh)
-				}
-
-				// 调用回调
-				callback(price)
-			}
+	var firstPriceOnce sync.Once
+
+	sharedCombinedStreams(w.useTestnet).Subscribe(ctx, stream, func(data json.RawMessage) {
+		// 解析消息（只提取必要字段）
+		var event struct {
+			Symbol string `json:"s"`
+			Price  string `json:"p"`
 		}
-	}()
+
+		if err := json.Unmarshal(data, &event); err != nil {
+			logger.Debug("解析消息失败: %v", err)
+			return
+		}
+
+		price, err := strconv.ParseFloat(event.Price, 64)
+		if err != nil {
+			logger.Debug("解析价格失败: %v", err)
+			return
+		}
+		// 更新价格缓存
+		w.priceMu.Lock()
+		w.latestPrice = price
+		w.priceMu.Unlock()
+
+		// 通知首个价格已接收
+		firstPriceOnce.Do(func() {
+			logger.Debug("✅ [Binance] 收到首个价格: %.2f", price)
+			close(firstPriceCh)
+		})
+
+		// 调用回调
+		callback(price)
+	})
 
 	// 等待接收首个价格（最多10秒）
 	select {
 	case <-firstPriceCh:
-		logger.Debug("✅ [Binance] 价格流已启动: %s@aggTrade", symbolLower)
+		logger.Debug("✅ [Binance] 价格流已启动: %s", stream)
 		return nil
 	case <-time.After(10 * time.Second):
 		return fmt.Errorf("等待首个价格超时（10秒）")
@@ -203,31 +184,12 @@ func (w *WebSocketManager) Stop() {
 	w.isRunning = false
 }
 
-// keepAliveListenKey 保持listenKey有效
-func (w *WebSocketManager) keepAliveListenKey(ctx context.Context) {
-	ticker := time.NewTicker(w.keepAliveInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-w.stopC:
-			return
-		case <-ticker.C:
-			if err := w.client.NewKeepaliveUserStreamService().ListenKey(w.listenKey).Do(ctx); err != nil {
-				logger.Error("❌ [Binance] listenKey保活失败: %v", err)
-			} else {
-				logger.Debug("✅ [Binance] listenKey保活成功")
-			}
-		}
-	}
-}
-
 // listenUserDataStream 监听用户数据流
 func (w *WebSocketManager) listenUserDataStream(ctx context.Context) {
 	defer close(w.doneC)
 
+	// 断线时间，首次连接为零值（启动时由持仓恢复流程同步订单状态）
+	var disconnectedAt time.Time
 	for {
 		select {
 		case <-ctx.Done():
@@ -239,7 +201,10 @@ func (w *WebSocketManager) listenUserDataStream(ctx context.Context) {
 
 		logger.Info("🔗 [Binance] 连接WebSocket订单流...")
 
-		doneC, stopC, err := futures.WsUserDataServe(w.listenKey, w.handleUserDataEvent, w.handleError)
+		w.mu.RLock()
+		listenKey := w.listenKey
+		w.mu.RUnlock()
+		doneC, stopC, err := futures.WsUserDataServe(listenKey, w.handleUserDataEvent, w.handleError)
 		if err != nil {
 			logger.Error("❌ [Binance] WebSocket连接失败: %v", err)
 			time.Sleep(w.reconnectDelay)
@@ -247,6 +212,12 @@ func (w *WebSocketManager) listenUserDataStream(ctx context.Context) {
 		}
 
 		logger.Info("✅ [Binance] WebSocket订单流已连接")
+		w.setConnected(stopC)
+		if !disconnectedAt.IsZero() {
+			metrics.GetPrometheusMetrics().RecordWebSocketReconnect("binance", userDataStreamType)
+			w.notifyReconnect(disconnectedAt)
+			disconnectedAt = time.Time{}
+		}
 
 		// 等待断开或停止信号
 		select {
@@ -258,13 +229,38 @@ func (w *WebSocketManager) listenUserDataStream(ctx context.Context) {
 			return
 		case <-doneC:
 			logger.Warn("⚠️ [Binance] WebSocket连接断开，等待重连...")
+			w.setConnected(nil)
+			disconnectedAt = time.Now()
 			time.Sleep(w.reconnectDelay)
 		}
 	}
 }
 
+// notifyReconnect 通知订单流已从断线中恢复
+func (w *WebSocketManager) notifyReconnect(disconnectedAt time.Time) {
+	w.mu.RLock()
+	handler := w.onReconnect
+	lastEventTime := w.lastEventTime
+	w.mu.RUnlock()
+
+	now := time.Now()
+	logger.Warn("⚠️ [Binance] 订单流断线 %v 后已恢复，断线期间的订单推送可能丢失", now.Sub(disconnectedAt).Round(time.Second))
+	if handler != nil {
+		handler(StreamReconnect{DisconnectedAt: disconnectedAt, ReconnectedAt: now, LastEventTime: lastEventTime})
+	}
+}
+
 // handleUserDataEvent 处理用户数据事件
 func (w *WebSocketManager) handleUserDataEvent(event *futures.WsUserDataEvent) {
+	w.mu.Lock()
+	w.lastEventTime = time.Now()
+	w.mu.Unlock()
+
+	if event.Event == futures.UserDataEventTypeListenKeyExpired {
+		w.alert(StreamAlert{Kind: StreamAlertListenKeyExpired, Message: "listenKey 已过期，用户数据流将不再推送"})
+		w.requestRenew(renewReasonExpired)
+		return
+	}
 	if event.Event != futures.UserDataEventTypeOrderTradeUpdate {
 		return
 	}
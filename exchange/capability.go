@@ -0,0 +1,28 @@
+package exchange
+
+// Unwrapper 包装器（健康统计、故障注入、行情录制）实现，返回被包装的内部交易所
+type Unwrapper interface {
+	Unwrap() IExchange
+}
+
+// As 查询交易所是否支持可选接口 T（如 PermissionChecker、CommissionRateProvider）
+// 包装器为了透传实现了全部可选接口，内部交易所不支持时调用只会得到 ErrNotImplemented，
+// 直接做类型断言总会成功；这里逐层剥开包装器，只有每一层都实现了 T 才视为支持，
+// 返回最外层的实现以保留健康统计和故障注入
+func As[T any](ex IExchange) (T, bool) {
+	var zero T
+	outer, ok := ex.(T)
+	if !ok {
+		return zero, false
+	}
+	for {
+		wrapper, wrapped := ex.(Unwrapper)
+		if !wrapped {
+			return outer, true
+		}
+		ex = wrapper.Unwrap()
+		if _, ok := ex.(T); !ok {
+			return zero, false
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+
+	"quantmesh/config"
+)
+
+// plainExchange 不实现任何可选接口的测试交易所
+type plainExchange struct {
+	IExchange
+}
+
+func (p *plainExchange) GetName() string { return "plain" }
+
+// commissionExchange 仅实现手续费率查询的测试交易所
+type commissionExchange struct {
+	plainExchange
+}
+
+func (c *commissionExchange) GetCommissionRate(ctx context.Context, symbol string) (*CommissionRate, error) {
+	return &CommissionRate{Symbol: symbol, MakerRate: 0.0002, TakerRate: 0.0005}, nil
+}
+
+func TestAsSeesThroughWrappers(t *testing.T) {
+	chaosCfg := config.ChaosConfig{Seed: 1}
+	wrap := func(inner IExchange) IExchange {
+		return newHealthExchange(newChaosExchange(inner, chaosCfg))
+	}
+
+	// 包装器实现了全部可选接口，但内部交易所不支持时 As 应返回 false
+	plain := wrap(&plainExchange{})
+	if _, ok := plain.(CommissionRateProvider); !ok {
+		t.Fatal("wrapper should satisfy CommissionRateProvider by type assertion")
+	}
+	if _, ok := As[CommissionRateProvider](plain); ok {
+		t.Error("As[CommissionRateProvider] should be false when the inner exchange lacks it")
+	}
+	if _, ok := As[PermissionChecker](plain); ok {
+		t.Error("As[PermissionChecker] should be false when the inner exchange lacks it")
+	}
+
+	// 内部交易所支持时返回最外层包装器，调用仍经过健康统计
+	wrapped := wrap(&commissionExchange{})
+	provider, ok := As[CommissionRateProvider](wrapped)
+	if !ok {
+		t.Fatal("As[CommissionRateProvider] should be true when the inner exchange implements it")
+	}
+	if _, isHealth := provider.(*healthExchange); !isHealth {
+		t.Errorf("As should return the outermost wrapper, got %T", provider)
+	}
+	rate, err := provider.GetCommissionRate(context.Background(), "BTCUSDT")
+	if err != nil || rate.TakerRate != 0.0005 {
+		t.Errorf("GetCommissionRate via wrapper = %+v, %v", rate, err)
+	}
+	if _, ok := As[IncomeHistoryProvider](wrapped); ok {
+		t.Error("As[IncomeHistoryProvider] should be false for an exchange implementing only commission rates")
+	}
+
+	// 未包装的交易所直接按类型断言
+	if _, ok := As[CommissionRateProvider](&commissionExchange{}); !ok {
+		t.Error("As on an unwrapped exchange should match its own methods")
+	}
+}
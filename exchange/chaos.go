@@ -0,0 +1,383 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+)
+
+// ErrChaosTimeout 混沌测试注入的 REST 超时错误
+var ErrChaosTimeout = fmt.Errorf("chaos: 请求超时: %w", context.DeadlineExceeded)
+
+// reorderFlushDelay 乱序暂存的推送在没有后续推送时的最长等待时间
+const reorderFlushDelay = 2 * time.Second
+
+// chaosExchange 混沌测试包装器
+// 在真实交易所适配器外层随机注入故障，未覆盖的方法直接透传给内部交易所
+type chaosExchange struct {
+	IExchange
+	cfg config.ChaosConfig
+
+	mu                sync.Mutex
+	rng               *rand.Rand
+	disconnectedUntil time.Time
+	orderStreamDown   time.Time   // 订单流模拟断线开始时间，恢复后发送重连通知
+	heldUpdate        interface{} // 等待与下一条推送交换顺序的订单更新
+	heldTimer         *time.Timer
+}
+
+// newChaosExchange 创建混沌测试包装器
+func newChaosExchange(inner IExchange, cfg config.ChaosConfig) *chaosExchange {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	logger.Warn("⚠️ [混沌测试] 已启用 %s 故障注入 (seed=%d, REST超时=%.2f, WS断线=%.2f, 成交延迟=%.2f, 乱序=%.2f)",
+		inner.GetName(), seed, cfg.RESTTimeoutRate, cfg.WSDisconnectRate, cfg.FillDelayRate, cfg.ReorderRate)
+	return &chaosExchange{
+		IExchange: inner,
+		cfg:       cfg,
+		rng:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Unwrap 返回被包装的交易所（见 As）
+func (c *chaosExchange) Unwrap() IExchange {
+	return c.IExchange
+}
+
+// roll 按概率掷骰
+func (c *chaosExchange) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rng.Float64() < rate
+}
+
+// randDuration 返回 [0, max) 的随机时长
+func (c *chaosExchange) randDuration(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Duration(c.rng.Int63n(int64(max)))
+}
+
+// injectTimeout 按概率模拟 REST 超时：等待一段时间后返回超时错误
+func (c *chaosExchange) injectTimeout(ctx context.Context, op string) error {
+	if !c.roll(c.cfg.RESTTimeoutRate) {
+		return nil
+	}
+	logger.Warn("🧪 [混沌测试] 注入 REST 超时: %s", op)
+	select {
+	case <-time.After(time.Duration(c.cfg.RESTTimeoutDelayMs) * time.Millisecond):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return ErrChaosTimeout
+}
+
+// wsDropped 判断当前推送是否因模拟断线而丢弃
+func (c *chaosExchange) wsDropped(stream string) bool {
+	now := time.Now()
+	c.mu.Lock()
+	if now.Before(c.disconnectedUntil) {
+		c.mu.Unlock()
+		return true
+	}
+	c.mu.Unlock()
+
+	if !c.roll(c.cfg.WSDisconnectRate) {
+		return false
+	}
+	until := now.Add(time.Duration(c.cfg.WSDisconnectSec) * time.Second)
+	c.mu.Lock()
+	c.disconnectedUntil = until
+	c.mu.Unlock()
+	logger.Warn("🧪 [混沌测试] 模拟 WebSocket 断线 (%s)，%d 秒内推送将被丢弃", stream, c.cfg.WSDisconnectSec)
+	return true
+}
+
+// === REST 故障注入 ===
+
+func (c *chaosExchange) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	if err := c.injectTimeout(ctx, "PlaceOrder"); err != nil {
+		return nil, err
+	}
+	order, err := c.IExchange.PlaceOrder(ctx, req)
+	// 请求已到达交易所但响应丢失，是对账器最需要覆盖的场景
+	if err == nil && c.roll(c.cfg.RESTTimeoutRate) {
+		logger.Warn("🧪 [混沌测试] 订单已提交但丢弃响应: %s", req.ClientOrderID)
+		return nil, ErrChaosTimeout
+	}
+	return order, err
+}
+
+func (c *chaosExchange) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	if err := c.injectTimeout(ctx, "BatchPlaceOrders"); err != nil {
+		return nil, false
+	}
+	placed, marginErr := c.IExchange.BatchPlaceOrders(ctx, orders)
+	if c.roll(c.cfg.RESTTimeoutRate) {
+		logger.Warn("🧪 [混沌测试] 批量订单已提交但丢弃响应 (%d 笔)", len(placed))
+		return nil, marginErr
+	}
+	return placed, marginErr
+}
+
+func (c *chaosExchange) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	if err := c.injectTimeout(ctx, "CancelOrder"); err != nil {
+		return err
+	}
+	return c.IExchange.CancelOrder(ctx, symbol, orderID)
+}
+
+func (c *chaosExchange) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	if err := c.injectTimeout(ctx, "BatchCancelOrders"); err != nil {
+		return err
+	}
+	return c.IExchange.BatchCancelOrders(ctx, symbol, orderIDs)
+}
+
+func (c *chaosExchange) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	if err := c.injectTimeout(ctx, "GetOrder"); err != nil {
+		return nil, err
+	}
+	return c.IExchange.GetOrder(ctx, symbol, orderID)
+}
+
+func (c *chaosExchange) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	if err := c.injectTimeout(ctx, "GetOpenOrders"); err != nil {
+		return nil, err
+	}
+	return c.IExchange.GetOpenOrders(ctx, symbol)
+}
+
+func (c *chaosExchange) GetAccount(ctx context.Context) (*Account, error) {
+	if err := c.injectTimeout(ctx, "GetAccount"); err != nil {
+		return nil, err
+	}
+	return c.IExchange.GetAccount(ctx)
+}
+
+func (c *chaosExchange) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	if err := c.injectTimeout(ctx, "GetPositions"); err != nil {
+		return nil, err
+	}
+	return c.IExchange.GetPositions(ctx, symbol)
+}
+
+func (c *chaosExchange) GetBalance(ctx context.Context, asset string) (float64, error) {
+	if err := c.injectTimeout(ctx, "GetBalance"); err != nil {
+		return 0, err
+	}
+	return c.IExchange.GetBalance(ctx, asset)
+}
+
+func (c *chaosExchange) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	if err := c.injectTimeout(ctx, "GetLatestPrice"); err != nil {
+		return 0, err
+	}
+	return c.IExchange.GetLatestPrice(ctx, symbol)
+}
+
+// === WebSocket 故障注入 ===
+
+func (c *chaosExchange) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	return c.IExchange.StartOrderStream(ctx, func(update interface{}) {
+		switch update.(type) {
+		case OrderStreamReconnect, UserStreamAlert:
+			callback(update)
+			return
+		}
+		if c.wsDropped("订单流") {
+			c.mu.Lock()
+			if c.orderStreamDown.IsZero() {
+				c.orderStreamDown = time.Now()
+			}
+			c.mu.Unlock()
+			return
+		}
+		// 模拟断线结束：与真实交易所一样先发送重连通知，触发补查丢失的推送
+		c.mu.Lock()
+		downSince := c.orderStreamDown
+		c.orderStreamDown = time.Time{}
+		c.mu.Unlock()
+		if !downSince.IsZero() {
+			callback(OrderStreamReconnect{Exchange: c.GetName(), DisconnectedAt: downSince, ReconnectedAt: time.Now()})
+		}
+
+		if c.roll(c.cfg.FillDelayRate) {
+			delay := c.randDuration(time.Duration(c.cfg.FillDelayMaxMs) * time.Millisecond)
+			logger.Warn("🧪 [混沌测试] 订单推送延迟 %v", delay)
+			time.AfterFunc(delay, func() { callback(update) })
+			return
+		}
+
+		c.mu.Lock()
+		held := c.heldUpdate
+		if held != nil {
+			// 先投递当前推送，再投递被暂存的旧推送，形成乱序
+			c.heldUpdate = nil
+			c.heldTimer.Stop()
+			c.mu.Unlock()
+			callback(update)
+			callback(held)
+			return
+		}
+		c.mu.Unlock()
+
+		if c.roll(c.cfg.ReorderRate) {
+			logger.Warn("🧪 [混沌测试] 暂存订单推送以制造乱序")
+			c.mu.Lock()
+			c.heldUpdate = update
+			c.heldTimer = time.AfterFunc(reorderFlushDelay, func() {
+				c.mu.Lock()
+				pending := c.heldUpdate
+				c.heldUpdate = nil
+				c.mu.Unlock()
+				if pending != nil {
+					callback(pending)
+				}
+			})
+			c.mu.Unlock()
+			return
+		}
+
+		callback(update)
+	})
+}
+
+func (c *chaosExchange) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	return c.IExchange.StartPriceStream(ctx, symbol, func(price float64) {
+		if c.wsDropped("价格流") {
+			return
+		}
+		callback(price)
+	})
+}
+
+// CheckAPIPermissions 透传权限检测（内部交易所支持时）
+func (c *chaosExchange) CheckAPIPermissions(ctx context.Context) (*APIPermissions, error) {
+	checker, ok := c.IExchange.(PermissionChecker)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return checker.CheckAPIPermissions(ctx)
+}
+
+// GetServerTime 透传服务器时间查询（内部交易所支持时）
+func (c *chaosExchange) GetServerTime(ctx context.Context) (time.Time, error) {
+	provider, ok := c.IExchange.(ServerTimeProvider)
+	if !ok {
+		return time.Time{}, ErrNotImplemented
+	}
+	return provider.GetServerTime(ctx)
+}
+
+// GetIncomeHistory 透传账户流水查询（内部交易所支持时）
+func (c *chaosExchange) GetIncomeHistory(ctx context.Context, symbol, incomeType string, startTime, endTime time.Time) ([]*IncomeRecord, error) {
+	provider, ok := c.IExchange.(IncomeHistoryProvider)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	if err := c.injectTimeout(ctx, "GetIncomeHistory"); err != nil {
+		return nil, err
+	}
+	return provider.GetIncomeHistory(ctx, symbol, incomeType, startTime, endTime)
+}
+
+// GetTickers24h 透传 24 小时行情查询（内部交易所支持时）
+func (c *chaosExchange) GetTickers24h(ctx context.Context) ([]*Ticker24h, error) {
+	provider, ok := c.IExchange.(TickerStatsProvider)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	if err := c.injectTimeout(ctx, "GetTickers24h"); err != nil {
+		return nil, err
+	}
+	return provider.GetTickers24h(ctx)
+}
+
+// TransferFromFutures 透传账户划转（内部交易所支持时）
+func (c *chaosExchange) TransferFromFutures(ctx context.Context, asset string, amount float64, toWallet string) (string, error) {
+	transferer, ok := c.IExchange.(WalletTransferer)
+	if !ok {
+		return "", ErrNotImplemented
+	}
+	if err := c.injectTimeout(ctx, "TransferFromFutures"); err != nil {
+		return "", err
+	}
+	return transferer.TransferFromFutures(ctx, asset, amount, toWallet)
+}
+
+// GetOpenInterestStat 透传持仓量查询（内部交易所支持时）
+func (c *chaosExchange) GetOpenInterestStat(ctx context.Context, symbol string) (*OpenInterestStat, error) {
+	provider, ok := c.IExchange.(OpenInterestProvider)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	if err := c.injectTimeout(ctx, "GetOpenInterestStat"); err != nil {
+		return nil, err
+	}
+	return provider.GetOpenInterestStat(ctx, symbol)
+}
+
+// GetCommissionRate 透传手续费率查询（内部交易所支持时）
+func (c *chaosExchange) GetCommissionRate(ctx context.Context, symbol string) (*CommissionRate, error) {
+	provider, ok := c.IExchange.(CommissionRateProvider)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	if err := c.injectTimeout(ctx, "GetCommissionRate"); err != nil {
+		return nil, err
+	}
+	return provider.GetCommissionRate(ctx, symbol)
+}
+
+// GetSymbolsMetadata 透传交易对元数据查询（内部交易所支持时）
+func (c *chaosExchange) GetSymbolsMetadata(ctx context.Context) ([]*SymbolMetadata, error) {
+	provider, ok := c.IExchange.(SymbolMetadataProvider)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return provider.GetSymbolsMetadata(ctx)
+}
+
+// PlaceOCO 透传原生 OCO 下单（内部交易所支持时）
+func (c *chaosExchange) PlaceOCO(ctx context.Context, req *OCORequest) (*OCOResult, error) {
+	placer, ok := c.IExchange.(OCOPlacer)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	if err := c.injectTimeout(ctx, "PlaceOCO"); err != nil {
+		return nil, err
+	}
+	return placer.PlaceOCO(ctx, req)
+}
+
+// StartDepthStream 透传盘口快照流（内部交易所支持时）
+func (c *chaosExchange) StartDepthStream(ctx context.Context, symbol string, callback func(*DepthUpdate)) error {
+	streamer, ok := c.IExchange.(MarketDepthStreamer)
+	if !ok {
+		return ErrNotImplemented
+	}
+	return streamer.StartDepthStream(ctx, symbol, callback)
+}
+
+// StartTradeStream 透传逐笔成交流（内部交易所支持时）
+func (c *chaosExchange) StartTradeStream(ctx context.Context, symbol string, callback func(*TradePrint)) error {
+	streamer, ok := c.IExchange.(MarketDepthStreamer)
+	if !ok {
+		return ErrNotImplemented
+	}
+	return streamer.StartTradeStream(ctx, symbol, callback)
+}
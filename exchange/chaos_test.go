@@ -0,0 +1,318 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"quantmesh/config"
+)
+
+// streamExchange 记录下单并保存推送回调的测试交易所，由测试直接驱动推送
+type streamExchange struct {
+	plainExchange
+	mu      sync.Mutex
+	placed  int
+	onOrder func(interface{})
+	onPrice func(float64)
+}
+
+func (s *streamExchange) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.placed++
+	return &Order{OrderID: int64(s.placed), ClientOrderID: req.ClientOrderID, Status: OrderStatusNew}, nil
+}
+
+func (s *streamExchange) GetBalance(ctx context.Context, asset string) (float64, error) {
+	return 100, nil
+}
+
+func (s *streamExchange) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	s.onOrder = callback
+	return nil
+}
+
+func (s *streamExchange) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	s.onPrice = callback
+	return nil
+}
+
+// updateRecorder 并发安全地记录投递的订单推送
+type updateRecorder struct {
+	mu         sync.Mutex
+	updates    []int64
+	reconnects int
+}
+
+func (r *updateRecorder) callback(update interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch u := update.(type) {
+	case OrderUpdate:
+		r.updates = append(r.updates, u.OrderID)
+	case OrderStreamReconnect:
+		r.reconnects++
+	}
+}
+
+func (r *updateRecorder) snapshot() ([]int64, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.updates...), r.reconnects
+}
+
+// assertRate 断言实际发生比例接近配置概率（固定种子，结果确定）
+func assertRate(t *testing.T, what string, hits, total int, rate float64) {
+	t.Helper()
+	if got := float64(hits) / float64(total); math.Abs(got-rate) > 0.03 {
+		t.Errorf("%s rate = %.3f (%d/%d), want %.2f±0.03", what, got, hits, total, rate)
+	}
+}
+
+func TestChaosRESTTimeoutRate(t *testing.T) {
+	const n = 4000
+	ctx := context.Background()
+	run := func() []bool {
+		c := newChaosExchange(&streamExchange{}, config.ChaosConfig{Seed: 7, RESTTimeoutRate: 0.2})
+		results := make([]bool, n)
+		for i := range results {
+			_, err := c.GetBalance(ctx, "USDT")
+			if err != nil && !errors.Is(err, ErrChaosTimeout) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			results[i] = err != nil
+		}
+		return results
+	}
+
+	first := run()
+	timeouts := 0
+	for _, timedOut := range first {
+		if timedOut {
+			timeouts++
+		}
+	}
+	assertRate(t, "REST timeout", timeouts, n, 0.2)
+	if !errors.Is(ErrChaosTimeout, context.DeadlineExceeded) {
+		t.Error("injected timeouts should look like deadline errors to callers")
+	}
+
+	// 同一种子的故障序列可复现
+	second := run()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("seeded run diverged at call %d", i)
+		}
+	}
+}
+
+func TestChaosPlaceOrderDropsResponse(t *testing.T) {
+	const n = 4000
+	inner := &streamExchange{}
+	c := newChaosExchange(inner, config.ChaosConfig{Seed: 11, RESTTimeoutRate: 0.2})
+
+	timeouts := 0
+	for i := 0; i < n; i++ {
+		if _, err := c.PlaceOrder(context.Background(), &OrderRequest{ClientOrderID: "c"}); err != nil {
+			timeouts++
+		}
+	}
+	// 请求前超时和响应丢失各自按概率发生：总失败率 1-(0.8×0.8)，其中响应丢失的订单已到达交易所
+	assertRate(t, "PlaceOrder failure", timeouts, n, 0.36)
+	assertRate(t, "orders reaching the exchange", inner.placed, n, 0.8)
+	if dropped := inner.placed - (n - timeouts); dropped <= 0 {
+		t.Fatal("no placed order had its response dropped")
+	} else {
+		assertRate(t, "dropped responses", dropped, inner.placed, 0.2)
+	}
+}
+
+func TestChaosPriceStreamDropRate(t *testing.T) {
+	const n = 4000
+	inner := &streamExchange{}
+	// 断线持续 0 秒：每条推送独立按概率丢弃
+	c := newChaosExchange(inner, config.ChaosConfig{Seed: 3, WSDisconnectRate: 0.1})
+	delivered := 0
+	if err := c.StartPriceStream(context.Background(), "BTCUSDT", func(float64) { delivered++ }); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		inner.onPrice(float64(i))
+	}
+	assertRate(t, "price drop", n-delivered, n, 0.1)
+
+	// 断线持续期间全部丢弃
+	c = newChaosExchange(inner, config.ChaosConfig{Seed: 3, WSDisconnectRate: 1, WSDisconnectSec: 60})
+	delivered = 0
+	c.StartPriceStream(context.Background(), "BTCUSDT", func(float64) { delivered++ })
+	inner.onPrice(1) // 触发断线
+	c.cfg.WSDisconnectRate = 0
+	for i := 0; i < 100; i++ {
+		inner.onPrice(float64(i))
+	}
+	if delivered != 0 {
+		t.Fatalf("%d prices delivered during a simulated disconnect", delivered)
+	}
+}
+
+func TestChaosOrderStreamDropSendsReconnect(t *testing.T) {
+	inner := &streamExchange{}
+	c := newChaosExchange(inner, config.ChaosConfig{Seed: 5, WSDisconnectRate: 1})
+	rec := &updateRecorder{}
+	c.StartOrderStream(context.Background(), rec.callback)
+
+	// 第一条推送触发断线被丢弃；之后停止注入，下一条推送前先收到重连通知
+	inner.onOrder(OrderUpdate{OrderID: 1})
+	c.cfg.WSDisconnectRate = 0
+	inner.onOrder(OrderUpdate{OrderID: 2})
+	inner.onOrder(OrderUpdate{OrderID: 3})
+	// 重连通知本身不受故障注入影响
+	inner.onOrder(OrderStreamReconnect{Exchange: "plain"})
+
+	updates, reconnects := rec.snapshot()
+	if len(updates) != 2 || updates[0] != 2 || updates[1] != 3 || reconnects != 2 {
+		t.Fatalf("updates = %v, reconnects = %d", updates, reconnects)
+	}
+}
+
+func TestChaosOrderStreamDelayRate(t *testing.T) {
+	const n = 2000
+	inner := &streamExchange{}
+	c := newChaosExchange(inner, config.ChaosConfig{Seed: 9, FillDelayRate: 0.25, FillDelayMaxMs: 20})
+	rec := &updateRecorder{}
+	// 推送期间同步送达的是未延迟的推送（延迟的推送在定时器协程中送达，订单号必然不同）
+	var current int64
+	immediate := 0
+	c.StartOrderStream(context.Background(), func(update interface{}) {
+		rec.mu.Lock()
+		if u, ok := update.(OrderUpdate); ok && u.OrderID == current {
+			immediate++
+		}
+		rec.mu.Unlock()
+		rec.callback(update)
+	})
+
+	for i := 1; i <= n; i++ {
+		rec.mu.Lock()
+		current = int64(i)
+		rec.mu.Unlock()
+		inner.onOrder(OrderUpdate{OrderID: int64(i)})
+	}
+	rec.mu.Lock()
+	current = 0
+	delayed := n - immediate
+	rec.mu.Unlock()
+	assertRate(t, "fill delay", delayed, n, 0.25)
+
+	// 延迟的推送最终全部送达
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		updates, _ := rec.snapshot()
+		if len(updates) == n {
+			assertDeliveredOnce(t, updates, n)
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("only %d/%d delayed updates delivered", len(updates), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestChaosOrderStreamReorder(t *testing.T) {
+	inner := &streamExchange{}
+	c := newChaosExchange(inner, config.ChaosConfig{Seed: 1, ReorderRate: 1})
+	rec := &updateRecorder{}
+	c.StartOrderStream(context.Background(), rec.callback)
+
+	// 每条推送都与下一条交换顺序
+	for i := 1; i <= 4; i++ {
+		inner.onOrder(OrderUpdate{OrderID: int64(i)})
+	}
+	updates, _ := rec.snapshot()
+	if want := []int64{2, 1, 4, 3}; !equalIDs(updates, want) {
+		t.Fatalf("updates = %v, want %v", updates, want)
+	}
+
+	// 没有后续推送时，暂存的推送在超时后仍会送达
+	inner.onOrder(OrderUpdate{OrderID: 5})
+	if updates, _ := rec.snapshot(); len(updates) != 4 {
+		t.Fatalf("held update delivered early: %v", updates)
+	}
+	time.Sleep(reorderFlushDelay + 200*time.Millisecond)
+	if updates, _ := rec.snapshot(); !equalIDs(updates, []int64{2, 1, 4, 3, 5}) {
+		t.Fatalf("held update not flushed: %v", updates)
+	}
+}
+
+func TestChaosOrderStreamReorderRate(t *testing.T) {
+	const n = 4000
+	inner := &streamExchange{}
+	c := newChaosExchange(inner, config.ChaosConfig{Seed: 13, ReorderRate: 0.2})
+	rec := &updateRecorder{}
+	c.StartOrderStream(context.Background(), rec.callback)
+
+	for i := 1; i <= n; i++ {
+		inner.onOrder(OrderUpdate{OrderID: int64(i)})
+	}
+	// 最后一条若被暂存，用一条额外推送冲出
+	inner.onOrder(OrderUpdate{OrderID: n + 1})
+	updates, _ := rec.snapshot()
+	if updates[len(updates)-1] == n+1 {
+		updates = updates[:len(updates)-1]
+	} else {
+		c.mu.Lock()
+		held := c.heldUpdate
+		c.mu.Unlock()
+		if held == nil {
+			t.Fatal("extra update neither delivered nor held")
+		}
+	}
+	swaps := 0
+	kept := updates[:0:0]
+	for _, id := range updates {
+		if id != n+1 {
+			kept = append(kept, id)
+		}
+	}
+	for i := 1; i < len(kept); i++ {
+		if kept[i] < kept[i-1] {
+			swaps++
+		}
+	}
+	assertDeliveredOnce(t, kept, n)
+	// 被暂存的推送不参与下一次掷骰：期望交换比例为 p/(1+p)
+	assertRate(t, "reorder", swaps, n, 0.2/1.2)
+}
+
+func equalIDs(got, want []int64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// assertDeliveredOnce 断言 1..n 每条推送恰好送达一次
+func assertDeliveredOnce(t *testing.T, updates []int64, n int) {
+	t.Helper()
+	sorted := append([]int64(nil), updates...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	if len(sorted) != n {
+		t.Fatalf("delivered %d updates, want %d", len(sorted), n)
+	}
+	for i, id := range sorted {
+		if id != int64(i+1) {
+			t.Fatalf("update %d missing or duplicated", i+1)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package exchange
+
+import (
+	"context"
+	"time"
+)
+
+// CommissionRate 账户实际手续费率（取决于 VIP 等级和抵扣设置）
+type CommissionRate struct {
+	Symbol       string
+	MakerRate    float64 // 交易所返回的 Maker 费率（未计抵扣）
+	TakerRate    float64 // 交易所返回的 Taker 费率（未计抵扣）
+	FeeDiscount  bool    // 是否开启手续费抵扣（如币安 BNB 抵扣）
+	DiscountRate float64 // 抵扣时的折扣比例，如 0.1 表示按 9 折收取
+	UpdatedAt    time.Time
+}
+
+// EffectiveMakerRate 计入抵扣后的 Maker 费率
+func (c *CommissionRate) EffectiveMakerRate() float64 {
+	return c.MakerRate * c.discountFactor()
+}
+
+// EffectiveTakerRate 计入抵扣后的 Taker 费率
+func (c *CommissionRate) EffectiveTakerRate() float64 {
+	return c.TakerRate * c.discountFactor()
+}
+
+func (c *CommissionRate) discountFactor() float64 {
+	if !c.FeeDiscount || c.DiscountRate <= 0 || c.DiscountRate >= 1 {
+		return 1
+	}
+	return 1 - c.DiscountRate
+}
+
+// CommissionRateProvider 账户手续费率查询接口（可选能力，通过类型断言检测）
+type CommissionRateProvider interface {
+	// GetCommissionRate 查询指定交易对的实际手续费率
+	GetCommissionRate(ctx context.Context, symbol string) (*CommissionRate, error)
+}
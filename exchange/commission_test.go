@@ -0,0 +1,21 @@
+package exchange
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCommissionRateEffective(t *testing.T) {
+	rate := &CommissionRate{MakerRate: 0.0002, TakerRate: 0.0005, DiscountRate: 0.1}
+	if rate.EffectiveMakerRate() != 0.0002 || rate.EffectiveTakerRate() != 0.0005 {
+		t.Errorf("未开启抵扣时不应打折: maker=%v taker=%v", rate.EffectiveMakerRate(), rate.EffectiveTakerRate())
+	}
+
+	rate.FeeDiscount = true
+	if got := rate.EffectiveMakerRate(); math.Abs(got-0.00018) > 1e-12 {
+		t.Errorf("开启抵扣后 Maker 费率 = %v, 期望 0.00018", got)
+	}
+	if got := rate.EffectiveTakerRate(); math.Abs(got-0.00045) > 1e-12 {
+		t.Errorf("开启抵扣后 Taker 费率 = %v, 期望 0.00045", got)
+	}
+}
@@ -0,0 +1,138 @@
+package exchange
+
+import (
+	"errors"
+	"strings"
+)
+
+// 交易所错误分类（通过 errors.Is 判断，原始错误信息保持不变）
+var (
+	ErrRateLimited        = errors.New("请求频率超限")
+	ErrInsufficientMargin = errors.New("保证金不足")
+	ErrOrderNotFound      = errors.New("订单不存在")
+	ErrInvalidFilter      = errors.New("订单不符合交易规则")
+	ErrRestrictedRegion   = errors.New("所在地区受限")
+)
+
+// ExchangeError 已分类的交易所错误
+// Error() 返回原始错误信息，便于日志与旧的字符串判断保持一致
+type ExchangeError struct {
+	Exchange string
+	Category error // 上面定义的分类之一
+	Err      error
+}
+
+func (e *ExchangeError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap 同时暴露分类与原始错误
+func (e *ExchangeError) Unwrap() []error {
+	return []error{e.Category, e.Err}
+}
+
+// errorRule 错误分类规则：codes 仅在 exchange 匹配时使用（交易所名称前缀，空表示全部），phrases 对全部交易所生效
+type errorRule struct {
+	category error
+	exchange string
+	codes    []string
+	phrases  []string
+}
+
+var errorRules = []errorRule{
+	{category: ErrRateLimited, exchange: "binance", codes: []string{"-1003", "-1015"}},
+	{category: ErrRateLimited, exchange: "okx", codes: []string{"50011", "50061"}},
+	{category: ErrRateLimited, exchange: "bybit", codes: []string{"10006", "10018"}},
+	{category: ErrRateLimited, phrases: []string{"way too many requests", "too many requests", "rate limit", "banned until", "too_many_requests"}},
+
+	{category: ErrInsufficientMargin, exchange: "binance", codes: []string{"-2019", "-2018"}},
+	{category: ErrInsufficientMargin, exchange: "okx", codes: []string{"51008"}},
+	{category: ErrInsufficientMargin, exchange: "bybit", codes: []string{"110007", "110004"}},
+	{category: ErrInsufficientMargin, exchange: "bitget", codes: []string{"40007", "40762"}},
+	{category: ErrInsufficientMargin, exchange: "huobi", codes: []string{"1030", "1047"}},
+	{category: ErrInsufficientMargin, phrases: []string{"insufficient", "margin is insufficient", "保证金不足", "balance_not_enough"}},
+
+	{category: ErrOrderNotFound, exchange: "binance", codes: []string{"-2011", "-2013"}},
+	{category: ErrOrderNotFound, exchange: "okx", codes: []string{"51400", "51603"}},
+	{category: ErrOrderNotFound, exchange: "bybit", codes: []string{"110001"}},
+	{category: ErrOrderNotFound, exchange: "bitget", codes: []string{"40029"}},
+	{category: ErrOrderNotFound, exchange: "huobi", codes: []string{"1061"}},
+	{category: ErrOrderNotFound, phrases: []string{"unknown order", "order does not exist", "order not found", "order_not_found"}},
+
+	{category: ErrInvalidFilter, exchange: "binance", codes: []string{"-1013", "-1111", "-4164", "-4014", "-4003"}},
+	{category: ErrInvalidFilter, exchange: "okx", codes: []string{"51020", "51121"}},
+	{category: ErrInvalidFilter, exchange: "bybit", codes: []string{"110017", "10001"}},
+	{category: ErrInvalidFilter, phrases: []string{"filter failure", "min_notional", "lot_size", "price_filter", "precision is over", "invalid quantity", "invalid price"}},
+
+	{category: ErrRestrictedRegion, exchange: "okx", codes: []string{"51155"}},
+	{category: ErrRestrictedRegion, exchange: "bybit", codes: []string{"10024"}},
+	{category: ErrRestrictedRegion, phrases: []string{"restricted location", "restricted region", "not available in your region", "限制服务区域"}},
+}
+
+// ClassifyError 按交易所错误码与错误信息为错误打上分类；无法识别或已分类时原样返回
+// 规则按顺序匹配：限流优先于保证金（部分交易所的限流信息也包含 "insufficient"）
+func ClassifyError(exchangeName string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var classified *ExchangeError
+	if errors.As(err, &classified) {
+		return err
+	}
+	if category := ErrorCategory(exchangeName, err); category != nil {
+		return &ExchangeError{Exchange: exchangeName, Category: category, Err: err}
+	}
+	return err
+}
+
+// ErrorCategory 返回错误所属的分类，无法识别时返回 nil
+func ErrorCategory(exchangeName string, err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, c := range categoryNames {
+		if errors.Is(err, c.category) {
+			return c.category
+		}
+	}
+
+	name := strings.ToLower(exchangeName)
+	msg := strings.ToLower(err.Error())
+	for _, rule := range errorRules {
+		if rule.exchange != "" && strings.HasPrefix(name, rule.exchange) {
+			for _, code := range rule.codes {
+				if strings.Contains(msg, code) {
+					return rule.category
+				}
+			}
+		}
+		for _, phrase := range rule.phrases {
+			if strings.Contains(msg, phrase) {
+				return rule.category
+			}
+		}
+	}
+	return nil
+}
+
+// categoryNames 错误分类的名称（用于持久化与统计）
+var categoryNames = []struct {
+	category error
+	name     string
+}{
+	{ErrRateLimited, "rate_limited"},
+	{ErrInsufficientMargin, "insufficient_margin"},
+	{ErrOrderNotFound, "order_not_found"},
+	{ErrInvalidFilter, "invalid_filter"},
+	{ErrRestrictedRegion, "restricted_region"},
+}
+
+// ErrorCategoryName 返回已分类错误的分类名称，未分类时返回空字符串
+func ErrorCategoryName(err error) string {
+	for _, c := range categoryNames {
+		if errors.Is(err, c.category) {
+			return c.name
+		}
+	}
+	return ""
+}
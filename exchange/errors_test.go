@@ -0,0 +1,54 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		exchange string
+		msg      string
+		want     error
+	}{
+		{"Binance", "<APIError> code=-1003, msg=Way too many requests; IP banned until 1700000000000", ErrRateLimited},
+		{"Binance", "<APIError> code=-2019, msg=Margin is insufficient.", ErrInsufficientMargin},
+		{"Binance", "<APIError> code=-2011, msg=Unknown order sent.", ErrOrderNotFound},
+		{"Binance", "<APIError> code=-4164, msg=Order's notional must be no smaller than 5 (unless you choose reduce only).", ErrInvalidFilter},
+		{"Binance", "你的网络连接在限制服务区域，请检查网络或使用代理", ErrRestrictedRegion},
+		{"OKX", "API 错误 51400: Order cancellation failed as the order has been filled, canceled or does not exist", ErrOrderNotFound},
+		{"Gate.io", "保证金不足: BALANCE_NOT_ENOUGH", ErrInsufficientMargin},
+		{"Bybit", "retCode=10006 too many visits", ErrRateLimited},
+	}
+	for _, tc := range cases {
+		err := ClassifyError(tc.exchange, errors.New(tc.msg))
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s %q: 期望分类 %v, 实际 %v", tc.exchange, tc.msg, tc.want, ErrorCategory(tc.exchange, err))
+		}
+		if ErrorCategoryName(err) == "" {
+			t.Errorf("%s %q: 分类名称不应为空", tc.exchange, tc.msg)
+		}
+		if err.Error() != tc.msg {
+			t.Errorf("分类后应保留原始错误信息: %q", err.Error())
+		}
+	}
+
+	// 其他交易所的错误码不应误判
+	if err := ClassifyError("OKX", errors.New("API 错误 -2019: something")); ErrorCategory("OKX", err) != nil {
+		t.Errorf("错误码只应匹配对应交易所: %v", err)
+	}
+
+	// 外层包装后仍可识别，重复分类不会重复包装
+	raw := errors.New("code=-2011, msg=Unknown order sent.")
+	wrapped := fmt.Errorf("取消订单失败: %w", ClassifyError("Binance", raw))
+	if !errors.Is(wrapped, ErrOrderNotFound) || !errors.Is(wrapped, raw) {
+		t.Error("包装后的错误应同时匹配分类和原始错误")
+	}
+	if again := ClassifyError("Binance", wrapped); again != wrapped {
+		t.Error("已分类的错误应原样返回")
+	}
+	if err := ClassifyError("Binance", errors.New("connection reset by peer")); ErrorCategory("Binance", err) != nil {
+		t.Errorf("未知错误不应被分类: %v", err)
+	}
+}
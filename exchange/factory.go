@@ -24,12 +24,37 @@ import (
 	"quantmesh/exchange/phemex"
 	"quantmesh/exchange/poloniex"
 	"quantmesh/exchange/woox"
+	"quantmesh/exchange/wsutil"
 	"quantmesh/exchange/xtcom"
 )
 
 // NewExchange 创建交易所实例
 // exchangeName/symbol 允许覆盖配置中的当前交易所和交易对，便于多交易对场景
+// 启用行情录制或混沌测试时，返回的实例会在适配层外依次包装录制器和故障注入器，
+// 最外层统一包装健康统计（见 AllVenueHealth），故障注入产生的错误也会计入评分
 func NewExchange(cfg *config.Config, exchangeName, symbol string) (IExchange, error) {
+	wsutil.SetCompression(cfg.Timing.WebSocketCompression)
+	ex, err := newExchange(cfg, exchangeName, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MarketData.Record.Enabled && ex.GetName() != config.ReplayExchangeName {
+		if symbol == "" {
+			symbol = cfg.Trading.Symbol
+		}
+		recorder, err := newRecordingExchange(ex, symbol, cfg.MarketData.Record.Dir)
+		if err != nil {
+			return nil, err
+		}
+		ex = recorder
+	}
+	if cfg.Chaos.Enabled {
+		ex = newChaosExchange(ex, cfg.Chaos)
+	}
+	return newHealthExchange(ex), nil
+}
+
+func newExchange(cfg *config.Config, exchangeName, symbol string) (IExchange, error) {
 	if exchangeName == "" {
 		exchangeName = cfg.App.CurrentExchange
 	}
@@ -395,6 +420,9 @@ func NewExchange(cfg *config.Config, exchangeName, symbol string) (IExchange, er
 		}
 		return &cryptocomWrapper{adapter: adapter}, nil
 
+	case config.ReplayExchangeName:
+		return newReplayExchange(cfg, symbol)
+
 	case "edgex":
 		return nil, fmt.Errorf("edgeX 尚未实现")
 
@@ -0,0 +1,407 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// healthWindow 健康评分的滚动统计窗口
+	healthWindow = 5 * time.Minute
+	// healthMaxSamples 每个交易所保留的最大请求样本数
+	healthMaxSamples = 2000
+
+	// 评分阈值：平均延迟、错误率、行情推送停滞时间超过上限时对应分项扣满
+	healthLatencyGood  = 200 * time.Millisecond
+	healthLatencyBad   = 2 * time.Second
+	healthErrorRateBad = 0.2
+	healthStaleGood    = 5 * time.Second
+	healthStaleBad     = 60 * time.Second
+)
+
+// 交易所健康状态
+const (
+	VenueHealthy   = "healthy"
+	VenueDegraded  = "degraded"
+	VenueUnhealthy = "unhealthy"
+)
+
+// VenueHealth 交易所健康评分
+type VenueHealth struct {
+	Exchange     string    `json:"exchange"`
+	Score        float64   `json:"score"`  // 0-100，越高越健康
+	Status       string    `json:"status"` // healthy / degraded / unhealthy
+	Requests     int       `json:"requests"`
+	Errors       int       `json:"errors"`
+	ErrorRate    float64   `json:"error_rate"`
+	AvgLatencyMs float64   `json:"avg_latency_ms"`
+	MaxLatencyMs float64   `json:"max_latency_ms"`
+	WSStaleSec   float64   `json:"ws_stale_seconds"` // 距最近一次行情推送的秒数，-1 表示尚无推送
+	LastWSUpdate time.Time `json:"last_ws_update"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type healthSample struct {
+	at      time.Time
+	latency time.Duration
+	failed  bool
+}
+
+// venueHealthTracker 单个交易所的请求统计（同一交易所的多个交易对共享）
+type venueHealthTracker struct {
+	mu           sync.Mutex
+	name         string
+	samples      []healthSample
+	lastWSUpdate time.Time
+}
+
+var (
+	healthRegistryMu sync.Mutex
+	healthRegistry   = make(map[string]*venueHealthTracker)
+)
+
+// venueTracker 获取（或创建）交易所的统计器
+func venueTracker(name string) *venueHealthTracker {
+	key := strings.ToLower(name)
+	healthRegistryMu.Lock()
+	defer healthRegistryMu.Unlock()
+	t, ok := healthRegistry[key]
+	if !ok {
+		t = &venueHealthTracker{name: key}
+		healthRegistry[key] = t
+	}
+	return t
+}
+
+// record 记录一次 REST 请求，调用方主动取消的请求不计入
+func (t *venueHealthTracker) record(start time.Time, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.samples = append(t.samples, healthSample{at: now, latency: now.Sub(start), failed: err != nil})
+	t.trim(now)
+}
+
+// markWS 记录一次行情推送
+func (t *venueHealthTracker) markWS() {
+	t.mu.Lock()
+	t.lastWSUpdate = time.Now()
+	t.mu.Unlock()
+}
+
+// trim 丢弃窗口外和超出上限的样本（调用方需持有锁）
+func (t *venueHealthTracker) trim(now time.Time) {
+	cutoff := now.Add(-healthWindow)
+	i := 0
+	for i < len(t.samples) && t.samples[i].at.Before(cutoff) {
+		i++
+	}
+	if over := len(t.samples) - i - healthMaxSamples; over > 0 {
+		i += over
+	}
+	if i > 0 {
+		t.samples = append(t.samples[:0], t.samples[i:]...)
+	}
+}
+
+// snapshot 计算当前健康评分
+func (t *venueHealthTracker) snapshot(now time.Time) VenueHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.trim(now)
+
+	h := VenueHealth{Exchange: t.name, LastWSUpdate: t.lastWSUpdate, UpdatedAt: now, WSStaleSec: -1}
+	var total time.Duration
+	var max time.Duration
+	for _, s := range t.samples {
+		h.Requests++
+		if s.failed {
+			h.Errors++
+		}
+		total += s.latency
+		if s.latency > max {
+			max = s.latency
+		}
+	}
+	var avg time.Duration
+	if h.Requests > 0 {
+		avg = total / time.Duration(h.Requests)
+		h.ErrorRate = float64(h.Errors) / float64(h.Requests)
+	}
+	h.AvgLatencyMs = float64(avg.Microseconds()) / 1000
+	h.MaxLatencyMs = float64(max.Microseconds()) / 1000
+
+	var stale time.Duration
+	if !t.lastWSUpdate.IsZero() {
+		stale = now.Sub(t.lastWSUpdate)
+		h.WSStaleSec = math.Round(stale.Seconds()*10) / 10
+	}
+
+	// 延迟 40 分、错误率 30 分、行情推送新鲜度 30 分；尚无数据的分项不扣分
+	h.Score = 40*linearScore(float64(avg), float64(healthLatencyGood), float64(healthLatencyBad)) +
+		30*linearScore(h.ErrorRate, 0, healthErrorRateBad) +
+		30*linearScore(float64(stale), float64(healthStaleGood), float64(healthStaleBad))
+	h.Score = math.Round(h.Score*10) / 10
+
+	switch {
+	case h.Score >= 80:
+		h.Status = VenueHealthy
+	case h.Score >= 50:
+		h.Status = VenueDegraded
+	default:
+		h.Status = VenueUnhealthy
+	}
+	return h
+}
+
+// linearScore 在 [good, bad] 区间线性映射到 [1, 0]
+func linearScore(v, good, bad float64) float64 {
+	if v <= good {
+		return 1
+	}
+	if v >= bad {
+		return 0
+	}
+	return 1 - (v-good)/(bad-good)
+}
+
+// GetVenueHealth 获取交易所健康评分（交易所未创建过实例时返回 false）
+func GetVenueHealth(name string) (VenueHealth, bool) {
+	healthRegistryMu.Lock()
+	t, ok := healthRegistry[strings.ToLower(name)]
+	healthRegistryMu.Unlock()
+	if !ok {
+		return VenueHealth{}, false
+	}
+	return t.snapshot(time.Now()), true
+}
+
+// AllVenueHealth 获取所有交易所的健康评分（按交易所名称排序）
+func AllVenueHealth() []VenueHealth {
+	healthRegistryMu.Lock()
+	trackers := make([]*venueHealthTracker, 0, len(healthRegistry))
+	for _, t := range healthRegistry {
+		trackers = append(trackers, t)
+	}
+	healthRegistryMu.Unlock()
+
+	now := time.Now()
+	result := make([]VenueHealth, 0, len(trackers))
+	for _, t := range trackers {
+		result = append(result, t.snapshot(now))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Exchange < result[j].Exchange })
+	return result
+}
+
+// IsVenueDegraded 交易所评分是否低于阈值（供套利等多交易所策略避开劣化的交易所报价）
+// 没有统计数据的交易所视为正常
+func IsVenueDegraded(name string, minScore float64) bool {
+	h, ok := GetVenueHealth(name)
+	return ok && h.Score < minScore
+}
+
+// healthExchange 健康统计包装器
+// 统计 REST 请求的延迟与错误、行情推送的时间，未覆盖的方法直接透传给内部交易所
+type healthExchange struct {
+	IExchange
+	tracker *venueHealthTracker
+}
+
+// newHealthExchange 创建健康统计包装器
+func newHealthExchange(inner IExchange) *healthExchange {
+	return &healthExchange{IExchange: inner, tracker: venueTracker(inner.GetName())}
+}
+
+// Unwrap 返回被包装的交易所（见 As）
+func (h *healthExchange) Unwrap() IExchange {
+	return h.IExchange
+}
+
+// classify 为交易所错误打上统一分类（见 ClassifyError），各适配器的 REST 错误经此返回
+func (h *healthExchange) classify(err error) error {
+	return ClassifyError(h.IExchange.GetName(), err)
+}
+
+func (h *healthExchange) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	start := time.Now()
+	order, err := h.IExchange.PlaceOrder(ctx, req)
+	h.tracker.record(start, err)
+	return order, h.classify(err)
+}
+
+func (h *healthExchange) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	start := time.Now()
+	err := h.IExchange.CancelOrder(ctx, symbol, orderID)
+	h.tracker.record(start, err)
+	return h.classify(err)
+}
+
+func (h *healthExchange) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	start := time.Now()
+	err := h.IExchange.BatchCancelOrders(ctx, symbol, orderIDs)
+	h.tracker.record(start, err)
+	return h.classify(err)
+}
+
+func (h *healthExchange) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	start := time.Now()
+	order, err := h.IExchange.GetOrder(ctx, symbol, orderID)
+	h.tracker.record(start, err)
+	return order, h.classify(err)
+}
+
+func (h *healthExchange) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	start := time.Now()
+	orders, err := h.IExchange.GetOpenOrders(ctx, symbol)
+	h.tracker.record(start, err)
+	return orders, h.classify(err)
+}
+
+func (h *healthExchange) GetAccount(ctx context.Context) (*Account, error) {
+	start := time.Now()
+	account, err := h.IExchange.GetAccount(ctx)
+	h.tracker.record(start, err)
+	return account, h.classify(err)
+}
+
+func (h *healthExchange) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	start := time.Now()
+	positions, err := h.IExchange.GetPositions(ctx, symbol)
+	h.tracker.record(start, err)
+	return positions, h.classify(err)
+}
+
+func (h *healthExchange) GetBalance(ctx context.Context, asset string) (float64, error) {
+	start := time.Now()
+	balance, err := h.IExchange.GetBalance(ctx, asset)
+	h.tracker.record(start, err)
+	return balance, h.classify(err)
+}
+
+func (h *healthExchange) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	start := time.Now()
+	price, err := h.IExchange.GetLatestPrice(ctx, symbol)
+	h.tracker.record(start, err)
+	return price, h.classify(err)
+}
+
+func (h *healthExchange) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	return h.IExchange.StartPriceStream(ctx, symbol, func(price float64) {
+		h.tracker.markWS()
+		callback(price)
+	})
+}
+
+// CheckAPIPermissions 透传权限检测（内部交易所支持时）
+func (h *healthExchange) CheckAPIPermissions(ctx context.Context) (*APIPermissions, error) {
+	checker, ok := h.IExchange.(PermissionChecker)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return checker.CheckAPIPermissions(ctx)
+}
+
+// GetServerTime 透传服务器时间查询（内部交易所支持时）
+func (h *healthExchange) GetServerTime(ctx context.Context) (time.Time, error) {
+	provider, ok := h.IExchange.(ServerTimeProvider)
+	if !ok {
+		return time.Time{}, ErrNotImplemented
+	}
+	return provider.GetServerTime(ctx)
+}
+
+// GetIncomeHistory 透传账户流水查询（内部交易所支持时）
+func (h *healthExchange) GetIncomeHistory(ctx context.Context, symbol, incomeType string, startTime, endTime time.Time) ([]*IncomeRecord, error) {
+	provider, ok := h.IExchange.(IncomeHistoryProvider)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return provider.GetIncomeHistory(ctx, symbol, incomeType, startTime, endTime)
+}
+
+// GetTickers24h 透传 24 小时行情查询（内部交易所支持时）
+func (h *healthExchange) GetTickers24h(ctx context.Context) ([]*Ticker24h, error) {
+	provider, ok := h.IExchange.(TickerStatsProvider)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return provider.GetTickers24h(ctx)
+}
+
+// TransferFromFutures 透传账户划转（内部交易所支持时）
+func (h *healthExchange) TransferFromFutures(ctx context.Context, asset string, amount float64, toWallet string) (string, error) {
+	transferer, ok := h.IExchange.(WalletTransferer)
+	if !ok {
+		return "", ErrNotImplemented
+	}
+	start := time.Now()
+	tranID, err := transferer.TransferFromFutures(ctx, asset, amount, toWallet)
+	h.tracker.record(start, err)
+	return tranID, h.classify(err)
+}
+
+// GetOpenInterestStat 透传持仓量查询（内部交易所支持时）
+func (h *healthExchange) GetOpenInterestStat(ctx context.Context, symbol string) (*OpenInterestStat, error) {
+	provider, ok := h.IExchange.(OpenInterestProvider)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return provider.GetOpenInterestStat(ctx, symbol)
+}
+
+// GetCommissionRate 透传手续费率查询（内部交易所支持时）
+func (h *healthExchange) GetCommissionRate(ctx context.Context, symbol string) (*CommissionRate, error) {
+	provider, ok := h.IExchange.(CommissionRateProvider)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return provider.GetCommissionRate(ctx, symbol)
+}
+
+// GetSymbolsMetadata 透传交易对元数据查询（内部交易所支持时）
+func (h *healthExchange) GetSymbolsMetadata(ctx context.Context) ([]*SymbolMetadata, error) {
+	provider, ok := h.IExchange.(SymbolMetadataProvider)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return provider.GetSymbolsMetadata(ctx)
+}
+
+// PlaceOCO 透传原生 OCO 下单（内部交易所支持时）
+func (h *healthExchange) PlaceOCO(ctx context.Context, req *OCORequest) (*OCOResult, error) {
+	placer, ok := h.IExchange.(OCOPlacer)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	start := time.Now()
+	result, err := placer.PlaceOCO(ctx, req)
+	h.tracker.record(start, err)
+	return result, h.classify(err)
+}
+
+// StartDepthStream 透传盘口快照流（内部交易所支持时）
+func (h *healthExchange) StartDepthStream(ctx context.Context, symbol string, callback func(*DepthUpdate)) error {
+	streamer, ok := h.IExchange.(MarketDepthStreamer)
+	if !ok {
+		return ErrNotImplemented
+	}
+	return streamer.StartDepthStream(ctx, symbol, callback)
+}
+
+// StartTradeStream 透传逐笔成交流（内部交易所支持时）
+func (h *healthExchange) StartTradeStream(ctx context.Context, symbol string, callback func(*TradePrint)) error {
+	streamer, ok := h.IExchange.(MarketDepthStreamer)
+	if !ok {
+		return ErrNotImplemented
+	}
+	return streamer.StartTradeStream(ctx, symbol, callback)
+}
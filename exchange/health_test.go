@@ -0,0 +1,58 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestVenueHealthScore(t *testing.T) {
+	tracker := &venueHealthTracker{name: "test"}
+	now := time.Now()
+
+	h := tracker.snapshot(now)
+	if h.Score != 100 || h.Status != VenueHealthy {
+		t.Fatalf("无数据时应视为健康, 得到 %.1f %s", h.Score, h.Status)
+	}
+
+	// 低延迟、无错误、行情新鲜
+	for i := 0; i < 10; i++ {
+		tracker.samples = append(tracker.samples, healthSample{at: now, latency: 50 * time.Millisecond})
+	}
+	tracker.lastWSUpdate = now.Add(-time.Second)
+	if h = tracker.snapshot(now); h.Score != 100 {
+		t.Errorf("健康交易所评分应为 100, 得到 %.1f", h.Score)
+	}
+
+	// 错误率 20%、行情停滞 2 分钟：错误率和推送分项扣满
+	tracker.samples = tracker.samples[:8]
+	tracker.samples = append(tracker.samples,
+		healthSample{at: now, latency: 50 * time.Millisecond, failed: true},
+		healthSample{at: now, latency: 50 * time.Millisecond, failed: true})
+	tracker.lastWSUpdate = now.Add(-2 * time.Minute)
+	h = tracker.snapshot(now)
+	if h.Score != 40 || h.Status != VenueUnhealthy {
+		t.Errorf("劣化交易所评分应为 40 (unhealthy), 得到 %.1f %s", h.Score, h.Status)
+	}
+	if h.Errors != 2 || h.ErrorRate != 0.2 {
+		t.Errorf("错误统计错误: %d %.2f", h.Errors, h.ErrorRate)
+	}
+
+	// 窗口外的样本会被丢弃
+	tracker.samples = []healthSample{{at: now.Add(-2 * healthWindow), latency: 5 * time.Second, failed: true}}
+	tracker.lastWSUpdate = now
+	if h = tracker.snapshot(now); h.Requests != 0 || h.Score != 100 {
+		t.Errorf("窗口外样本应被丢弃, 得到 requests=%d score=%.1f", h.Requests, h.Score)
+	}
+}
+
+func TestVenueHealthRecordSkipsCanceled(t *testing.T) {
+	tracker := &venueHealthTracker{name: "test"}
+	tracker.record(time.Now(), errors.New("boom"))
+	tracker.record(time.Now(), fmt.Errorf("请求中止: %w", context.Canceled))
+	if len(tracker.samples) != 1 {
+		t.Errorf("调用方取消的请求不应计入, 得到 %d 个样本", len(tracker.samples))
+	}
+}
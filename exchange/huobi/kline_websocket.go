@@ -9,6 +9,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"quantmesh/exchange/wsutil"
 	"quantmesh/logger"
 
 	"github.com/gorilla/websocket"
@@ -42,7 +43,7 @@ func (k *KlineWebSocketManager) Start(ctx context.Context, contractCodes []strin
 
 	k.callback = callback
 
-	conn, _, err := websocket.DefaultDialer.Dial(PublicWsURL, nil)
+	conn, _, err := wsutil.Dialer().Dial(PublicWsURL, nil)
 	if err != nil {
 		return fmt.Errorf("连接 K线 WebSocket 失败: %w", err)
 	}
@@ -106,7 +107,7 @@ func (k *KlineWebSocketManager) readMessages() {
 			break
 		}
 
-		_, message, err := conn.ReadMessage()
+		messageType, message, err := conn.ReadMessage()
 		if err != nil {
 			if k.isRunning.Load() {
 				logger.Warn("⚠️ [Huobi K线 WebSocket] 读取消息失败: %v", err)
@@ -114,8 +115,8 @@ func (k *KlineWebSocketManager) readMessages() {
 			break
 		}
 
-		// 解压 gzip
-		decompressed, err := decompressGzip(message)
+		// 解压 gzip 二进制帧
+		decompressed, err := wsutil.Decode(messageType, message)
 		if err != nil {
 			logger.Warn("⚠️ [Huobi K线 WebSocket] 解压消息失败: %v", err)
 			continue
@@ -1,33 +1,20 @@
 package huobi
 
 import (
-	"bytes"
-	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"quantmesh/exchange/wsutil"
 	"quantmesh/logger"
 
 	"github.com/gorilla/websocket"
 )
 
-// decompressGzip 解压 gzip 数据
-func decompressGzip(data []byte) ([]byte, error) {
-	reader, err := gzip.NewReader(bytes.NewReader(data))
-	if err != nil {
-		return nil, err
-	}
-	defer reader.Close()
-
-	return io.ReadAll(reader)
-}
-
 // WebSocketManager WebSocket 管理器
 type WebSocketManager struct {
 	apiKey        string
@@ -58,7 +45,7 @@ func (w *WebSocketManager) Start(ctx context.Context, contractCode string, callb
 
 	w.orderCallback = callback
 
-	conn, _, err := websocket.DefaultDialer.Dial(MainnetWsURL, nil)
+	conn, _, err := wsutil.Dialer().Dial(MainnetWsURL, nil)
 	if err != nil {
 		return fmt.Errorf("连接 WebSocket 失败: %w", err)
 	}
@@ -139,7 +126,7 @@ func (w *WebSocketManager) readMessages() {
 			break
 		}
 
-		_, message, err := conn.ReadMessage()
+		messageType, message, err := conn.ReadMessage()
 		if err != nil {
 			if w.isRunning.Load() {
 				logger.Warn("⚠️ [Huobi WebSocket] 读取消息失败: %v", err)
@@ -147,8 +134,8 @@ func (w *WebSocketManager) readMessages() {
 			break
 		}
 
-		// 解压 gzip
-		decompressed, err := decompressGzip(message)
+		// 解压 gzip 二进制帧
+		decompressed, err := wsutil.Decode(messageType, message)
 		if err != nil {
 			logger.Warn("⚠️ [Huobi WebSocket] 解压消息失败: %v", err)
 			continue
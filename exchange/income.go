@@ -0,0 +1,29 @@
+package exchange
+
+import (
+	"context"
+	"time"
+)
+
+// 账户流水类型（与币安 income history 的 incomeType 一致）
+const (
+	IncomeTypeFundingFee  = "FUNDING_FEE"
+	IncomeTypeRealizedPnL = "REALIZED_PNL"
+	IncomeTypeCommission  = "COMMISSION"
+)
+
+// IncomeRecord 账户收支流水（资金费、手续费、已实现盈亏等）
+type IncomeRecord struct {
+	Symbol     string
+	IncomeType string
+	Asset      string
+	Amount     float64 // 正数为收入，负数为支出
+	TranID     string  // 交易所流水号，用于去重
+	Time       time.Time
+}
+
+// IncomeHistoryProvider 账户流水查询接口（可选能力，通过类型断言检测）
+type IncomeHistoryProvider interface {
+	// GetIncomeHistory 查询 [startTime, endTime] 内的流水，incomeType 为空表示全部类型
+	GetIncomeHistory(ctx context.Context, symbol, incomeType string, startTime, endTime time.Time) ([]*IncomeRecord, error)
+}
@@ -0,0 +1,34 @@
+package exchange
+
+import "context"
+
+// BookLevel 盘口价位
+type BookLevel struct {
+	Price float64
+	Qty   float64
+}
+
+// DepthUpdate 有限档位的盘口快照
+type DepthUpdate struct {
+	Symbol    string
+	Bids      []BookLevel // 买盘，价格从高到低
+	Asks      []BookLevel // 卖盘，价格从低到高
+	Timestamp int64
+}
+
+// TradePrint 逐笔成交
+type TradePrint struct {
+	Symbol     string
+	Price      float64
+	Qty        float64
+	BuyerMaker bool // true 表示卖方主动成交（吃掉买盘）
+	Timestamp  int64
+}
+
+// MarketDepthStreamer 盘口与逐笔成交推送（可选能力，通过类型断言检测）
+type MarketDepthStreamer interface {
+	// StartDepthStream 启动盘口快照流，ctx 取消时停止
+	StartDepthStream(ctx context.Context, symbol string, callback func(*DepthUpdate)) error
+	// StartTradeStream 启动逐笔成交流，ctx 取消时停止
+	StartTradeStream(ctx context.Context, symbol string, callback func(*TradePrint)) error
+}
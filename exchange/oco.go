@@ -0,0 +1,26 @@
+package exchange
+
+import "context"
+
+// OCORequest OCO（一单成交撤销另一单）下单请求：同一数量的止盈限价单与止损单，均为只减仓
+type OCORequest struct {
+	Symbol          string
+	Side            Side // 平仓方向（多仓止盈止损为 SELL）
+	Quantity        float64
+	TakeProfitPrice float64
+	StopPrice       float64
+	PriceDecimals   int
+	ClientOrderID   string // 关联ID，交易所据此派生两张子订单的自定义ID
+}
+
+// OCOResult 原生 OCO 下单结果
+type OCOResult struct {
+	TakeProfit *Order
+	StopLoss   *Order
+}
+
+// OCOPlacer 原生 OCO 下单接口（可选能力，通过类型断言检测）
+// 不支持时由订单执行器在本地模拟止损触发
+type OCOPlacer interface {
+	PlaceOCO(ctx context.Context, req *OCORequest) (*OCOResult, error)
+}
@@ -0,0 +1,23 @@
+package exchange
+
+import (
+	"context"
+	"time"
+)
+
+// OpenInterestStat 合约持仓量与大户多空比快照
+type OpenInterestStat struct {
+	Symbol            string
+	OpenInterest      float64 // 持仓量（币）
+	OpenInterestValue float64 // 持仓价值（按标记价格折算，计价币）
+	LongShortRatio    float64 // 大户持仓多空比（交易所不支持时为 0）
+	LongPercent       float64 // 大户多头持仓占比（0-1）
+	ShortPercent      float64 // 大户空头持仓占比（0-1）
+	Time              time.Time
+}
+
+// OpenInterestProvider 持仓量查询接口（可选能力，通过类型断言检测）
+type OpenInterestProvider interface {
+	// GetOpenInterestStat 查询指定交易对的当前持仓量与大户多空比
+	GetOpenInterestStat(ctx context.Context, symbol string) (*OpenInterestStat, error)
+}
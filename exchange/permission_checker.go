@@ -23,6 +23,7 @@ type APIPermissions struct {
 	// 其他信息
 	APIKeyName string `json:"api_key_name"` // API Key 名称/标签
 	CreateTime int64  `json:"create_time"`  // 创建时间（Unix 时间戳）
+	ExpireTime int64  `json:"expire_time"`  // 交易权限过期时间（Unix 时间戳，0 表示不过期或交易所不提供）
 
 	// 安全评分（0-100，越高越安全）
 	SecurityScore int    `json:"security_score"`
@@ -0,0 +1,256 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"quantmesh/logger"
+)
+
+// 录制记录类型
+const (
+	RecordTypePrice = "price"
+	RecordTypeOrder = "order"
+)
+
+// MarketDataRecord 录制的一条 WebSocket 推送（JSONL 格式，每行一条）
+type MarketDataRecord struct {
+	Time   int64        `json:"t"`    // 接收时间（Unix 毫秒）
+	Type   string       `json:"type"` // price / order
+	Symbol string       `json:"symbol"`
+	Price  float64      `json:"price,omitempty"`
+	Order  *OrderUpdate `json:"order,omitempty"`
+}
+
+// recordingExchange 行情录制包装器
+// 将价格流和订单流推送在交给上层之前写入磁盘，其余方法透传
+type recordingExchange struct {
+	IExchange
+	symbol string
+
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// newRecordingExchange 创建录制包装器，文件名为 <交易所>_<交易对>_<启动时间>.jsonl
+func newRecordingExchange(inner IExchange, symbol, dir string) (*recordingExchange, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建录制目录失败: %w", err)
+	}
+	name := fmt.Sprintf("%s_%s_%s.jsonl", inner.GetName(), symbol, time.Now().Format("20060102_150405"))
+	path := filepath.Join(dir, name)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("创建录制文件失败: %w", err)
+	}
+	logger.Info("📼 [行情录制] %s 推送将写入 %s", symbol, path)
+	return &recordingExchange{
+		IExchange: inner,
+		symbol:    symbol,
+		file:      file,
+		enc:       json.NewEncoder(file),
+	}, nil
+}
+
+// Unwrap 返回被包装的交易所（见 As）
+func (r *recordingExchange) Unwrap() IExchange {
+	return r.IExchange
+}
+
+func (r *recordingExchange) write(rec *MarketDataRecord) {
+	rec.Time = time.Now().UnixMilli()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.enc == nil {
+		return
+	}
+	if err := r.enc.Encode(rec); err != nil {
+		logger.Warn("⚠️ [行情录制] 写入失败: %v", err)
+	}
+}
+
+func (r *recordingExchange) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	return r.IExchange.StartPriceStream(ctx, symbol, func(price float64) {
+		r.write(&MarketDataRecord{Type: RecordTypePrice, Symbol: symbol, Price: price})
+		callback(price)
+	})
+}
+
+func (r *recordingExchange) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	return r.IExchange.StartOrderStream(ctx, func(update interface{}) {
+		if u, ok := normalizeOrderUpdate(update); ok {
+			r.write(&MarketDataRecord{Type: RecordTypeOrder, Symbol: u.Symbol, Order: &u})
+		}
+		callback(update)
+	})
+}
+
+func (r *recordingExchange) StopOrderStream() error {
+	err := r.IExchange.StopOrderStream()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+		r.enc = nil
+	}
+	return err
+}
+
+// CheckAPIPermissions 透传权限检测（内部交易所支持时）
+func (r *recordingExchange) CheckAPIPermissions(ctx context.Context) (*APIPermissions, error) {
+	checker, ok := r.IExchange.(PermissionChecker)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return checker.CheckAPIPermissions(ctx)
+}
+
+// GetServerTime 透传服务器时间查询（内部交易所支持时）
+func (r *recordingExchange) GetServerTime(ctx context.Context) (time.Time, error) {
+	provider, ok := r.IExchange.(ServerTimeProvider)
+	if !ok {
+		return time.Time{}, ErrNotImplemented
+	}
+	return provider.GetServerTime(ctx)
+}
+
+// GetIncomeHistory 透传账户流水查询（内部交易所支持时）
+func (r *recordingExchange) GetIncomeHistory(ctx context.Context, symbol, incomeType string, startTime, endTime time.Time) ([]*IncomeRecord, error) {
+	provider, ok := r.IExchange.(IncomeHistoryProvider)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return provider.GetIncomeHistory(ctx, symbol, incomeType, startTime, endTime)
+}
+
+// GetTickers24h 透传 24 小时行情查询（内部交易所支持时）
+func (r *recordingExchange) GetTickers24h(ctx context.Context) ([]*Ticker24h, error) {
+	provider, ok := r.IExchange.(TickerStatsProvider)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return provider.GetTickers24h(ctx)
+}
+
+// TransferFromFutures 透传账户划转（内部交易所支持时）
+func (r *recordingExchange) TransferFromFutures(ctx context.Context, asset string, amount float64, toWallet string) (string, error) {
+	transferer, ok := r.IExchange.(WalletTransferer)
+	if !ok {
+		return "", ErrNotImplemented
+	}
+	return transferer.TransferFromFutures(ctx, asset, amount, toWallet)
+}
+
+// GetOpenInterestStat 透传持仓量查询（内部交易所支持时）
+func (r *recordingExchange) GetOpenInterestStat(ctx context.Context, symbol string) (*OpenInterestStat, error) {
+	provider, ok := r.IExchange.(OpenInterestProvider)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return provider.GetOpenInterestStat(ctx, symbol)
+}
+
+// GetCommissionRate 透传手续费率查询（内部交易所支持时）
+func (r *recordingExchange) GetCommissionRate(ctx context.Context, symbol string) (*CommissionRate, error) {
+	provider, ok := r.IExchange.(CommissionRateProvider)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return provider.GetCommissionRate(ctx, symbol)
+}
+
+// GetSymbolsMetadata 透传交易对元数据查询（内部交易所支持时）
+func (r *recordingExchange) GetSymbolsMetadata(ctx context.Context) ([]*SymbolMetadata, error) {
+	provider, ok := r.IExchange.(SymbolMetadataProvider)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return provider.GetSymbolsMetadata(ctx)
+}
+
+// normalizeOrderUpdate 将各适配器自有的订单更新结构转换为通用 OrderUpdate
+// 各子包的结构字段名一致，按字段名反射读取
+func normalizeOrderUpdate(update interface{}) (OrderUpdate, bool) {
+	if u, ok := update.(OrderUpdate); ok {
+		return u, true
+	}
+	switch update.(type) {
+	case OrderStreamReconnect, UserStreamAlert:
+		return OrderUpdate{}, false
+	}
+	v := reflect.ValueOf(update)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return OrderUpdate{}, false
+	}
+
+	str := func(name string) string {
+		if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.String {
+			return f.String()
+		}
+		return ""
+	}
+	num := func(name string) float64 {
+		if f := v.FieldByName(name); f.IsValid() && f.CanFloat() {
+			return f.Float()
+		}
+		return 0
+	}
+	integer := func(name string) int64 {
+		if f := v.FieldByName(name); f.IsValid() && f.CanInt() {
+			return f.Int()
+		}
+		return 0
+	}
+
+	return OrderUpdate{
+		OrderID:       integer("OrderID"),
+		ClientOrderID: str("ClientOrderID"),
+		Symbol:        str("Symbol"),
+		Side:          Side(str("Side")),
+		Type:          OrderType(str("Type")),
+		Status:        OrderStatus(str("Status")),
+		Price:         num("Price"),
+		Quantity:      num("Quantity"),
+		ExecutedQty:   num("ExecutedQty"),
+		AvgPrice:      num("AvgPrice"),
+		UpdateTime:    integer("UpdateTime"),
+	}, true
+}
+
+// PlaceOCO 透传原生 OCO 下单（内部交易所支持时）
+func (r *recordingExchange) PlaceOCO(ctx context.Context, req *OCORequest) (*OCOResult, error) {
+	placer, ok := r.IExchange.(OCOPlacer)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return placer.PlaceOCO(ctx, req)
+}
+
+// StartDepthStream 透传盘口快照流（内部交易所支持时）
+func (r *recordingExchange) StartDepthStream(ctx context.Context, symbol string, callback func(*DepthUpdate)) error {
+	streamer, ok := r.IExchange.(MarketDepthStreamer)
+	if !ok {
+		return ErrNotImplemented
+	}
+	return streamer.StartDepthStream(ctx, symbol, callback)
+}
+
+// StartTradeStream 透传逐笔成交流（内部交易所支持时）
+func (r *recordingExchange) StartTradeStream(ctx context.Context, symbol string, callback func(*TradePrint)) error {
+	streamer, ok := r.IExchange.(MarketDepthStreamer)
+	if !ok {
+		return ErrNotImplemented
+	}
+	return streamer.StartTradeStream(ctx, symbol, callback)
+}
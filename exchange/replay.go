@@ -0,0 +1,453 @@
+package exchange
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+)
+
+// replayExchange 回放交易所
+// 按录制时的时间间隔（可倍速）重新推送价格和订单更新，用于精确复现线上问题。
+// 下单、撤单只在内存中记录，成交只来自录制文件中的订单推送：回放时的订单 ID 与录制时不同，
+// 录制的成交推送按方向和价格映射到回放中同价位的挂单，改写为该挂单的 OrderID/ClientOrderID 后推送，
+// 同一录制订单的后续推送沿用同一映射；找不到对应挂单的成交和非成交推送（新单、撤单）不再推送。
+// 下单、撤单按 simulation 配置的回报延迟（随倍速缩放）返回，模拟实际网络往返
+type replayExchange struct {
+	symbol   string
+	records  []MarketDataRecord
+	speed    float64
+	warmup   time.Duration
+	balance  float64
+	priceDec int
+	qtyDec   int
+	sim      *ExecutionSimulator
+
+	mu            sync.RWMutex
+	priceCbs      map[string]func(price float64)
+	orderCb       func(interface{})
+	lastPrice     float64
+	openOrders    map[int64]*Order
+	remapped      map[int64]int64 // 录制订单 ID -> 回放挂单 ID
+	nextOrderID   int64
+	startPlayback sync.Once
+	fills         int // 已映射推送的成交
+	dropped       int // 无法映射而丢弃的订单推送
+}
+
+// LoadMarketDataRecords 读取录制文件
+func LoadMarketDataRecords(path string) ([]MarketDataRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开录制文件失败: %w", err)
+	}
+	defer file.Close()
+
+	var records []MarketDataRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var rec MarketDataRecord
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			return nil, fmt.Errorf("解析录制文件第 %d 行失败: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取录制文件失败: %w", err)
+	}
+	return records, nil
+}
+
+// newReplayExchange 创建回放交易所
+func newReplayExchange(cfg *config.Config, symbol string) (*replayExchange, error) {
+	replayCfg := cfg.MarketData.Replay
+	records, err := LoadMarketDataRecords(replayCfg.File)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("录制文件 %s 为空", replayCfg.File)
+	}
+
+	r := &replayExchange{
+		symbol:      symbol,
+		records:     records,
+		speed:       replayCfg.Speed,
+		warmup:      time.Duration(replayCfg.WarmupSec) * time.Second,
+		balance:     replayCfg.Balance,
+		priceDec:    2,
+		qtyDec:      3,
+		priceCbs:    make(map[string]func(price float64)),
+		openOrders:  make(map[int64]*Order),
+		remapped:    make(map[int64]int64),
+		nextOrderID: 1,
+		sim:         NewExecutionSimulator(cfg.Simulation.For(replayCfg.Venue), cfg.Simulation.Seed),
+	}
+	// 精度按录制数据中出现的最大小数位推断
+	for _, rec := range records {
+		if rec.Type == RecordTypePrice && rec.Symbol == symbol {
+			r.priceDec = max(r.priceDec, decimalPlaces(rec.Price))
+		}
+		if rec.Order != nil && rec.Order.Symbol == symbol {
+			r.qtyDec = max(r.qtyDec, decimalPlaces(rec.Order.Quantity))
+		}
+	}
+
+	logger.Info("📼 [行情回放] 已加载 %d 条记录 (文件=%s, 倍速=%.2f)", len(records), replayCfg.File, r.speed)
+	return r, nil
+}
+
+// decimalPlaces 计算浮点数的小数位数（最多 8 位）
+func decimalPlaces(v float64) int {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	idx := strings.IndexByte(s, '.')
+	if idx < 0 {
+		return 0
+	}
+	return min(len(s)-idx-1, 8)
+}
+
+// play 按录制时间线推送数据
+func (r *replayExchange) play(ctx context.Context) {
+	var prevTime int64
+	warmedUp := false
+	for i, rec := range r.records {
+		if i > 0 && r.speed > 0 {
+			wait := time.Duration(float64(time.Duration(rec.Time-prevTime)*time.Millisecond) / r.speed)
+			if wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+		prevTime = rec.Time
+
+		switch rec.Type {
+		case RecordTypePrice:
+			r.mu.Lock()
+			cb := r.priceCbs[rec.Symbol]
+			if cb != nil {
+				r.lastPrice = rec.Price
+			}
+			r.mu.Unlock()
+			if cb == nil {
+				continue
+			}
+			cb(rec.Price)
+			// 首个价格推送后等待系统完成初始化（订单流、仓位管理器等）
+			if !warmedUp && r.warmup > 0 {
+				warmedUp = true
+				select {
+				case <-time.After(r.warmup):
+				case <-ctx.Done():
+					return
+				}
+			}
+		case RecordTypeOrder:
+			if rec.Order == nil {
+				continue
+			}
+			r.mu.RLock()
+			cb := r.orderCb
+			r.mu.RUnlock()
+			if cb == nil {
+				continue
+			}
+			if update, ok := r.remapFill(rec.Order); ok {
+				cb(update)
+			}
+		}
+	}
+	r.mu.RLock()
+	fills, dropped := r.fills, r.dropped
+	r.mu.RUnlock()
+	logger.Info("📼 [行情回放] 回放结束，共 %d 条记录，映射成交推送 %d 条，丢弃无对应挂单的订单推送 %d 条",
+		len(r.records), fills, dropped)
+}
+
+// remapFill 将录制的成交推送映射到回放中的挂单
+// 首次出现的录制订单按交易对、方向、价格（半个最小价格单位内）匹配尚未映射的挂单，取最早下的一笔；
+// 成交数量按回放挂单数量封顶，完全成交后挂单移出并清除映射
+func (r *replayExchange) remapFill(rec *OrderUpdate) (OrderUpdate, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if rec.Status != OrderStatusFilled && rec.Status != OrderStatusPartiallyFilled {
+		r.dropped++
+		return OrderUpdate{}, false
+	}
+
+	order, ok := r.openOrders[r.remapped[rec.OrderID]]
+	if !ok {
+		mapped := make(map[int64]bool, len(r.remapped))
+		for _, id := range r.remapped {
+			mapped[id] = true
+		}
+		tolerance := math.Pow10(-r.priceDec) / 2
+		for id, o := range r.openOrders {
+			if mapped[id] || o.Symbol != rec.Symbol || o.Side != rec.Side || math.Abs(o.Price-rec.Price) > tolerance {
+				continue
+			}
+			if order == nil || id < order.OrderID {
+				order = o
+			}
+		}
+		if order == nil {
+			r.dropped++
+			logger.Debug("📼 [行情回放] 录制成交无对应挂单，跳过: %s %s %.8f @ %.8f (录制订单 %d)",
+				rec.Symbol, rec.Side, rec.ExecutedQty, rec.Price, rec.OrderID)
+			return OrderUpdate{}, false
+		}
+		r.remapped[rec.OrderID] = order.OrderID
+	}
+
+	executed := math.Min(rec.ExecutedQty, order.Quantity)
+	status := rec.Status
+	if status == OrderStatusFilled || executed >= order.Quantity {
+		executed, status = order.Quantity, OrderStatusFilled
+	}
+	order.ExecutedQty = executed
+	order.Status = status
+	order.UpdateTime = time.Now().UnixMilli()
+	if rec.AvgPrice > 0 {
+		order.AvgPrice = rec.AvgPrice
+	}
+	if status == OrderStatusFilled {
+		delete(r.openOrders, order.OrderID)
+		delete(r.remapped, rec.OrderID)
+	}
+	r.fills++
+
+	return OrderUpdate{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		Type:          order.Type,
+		Status:        status,
+		Price:         order.Price,
+		Quantity:      order.Quantity,
+		ExecutedQty:   executed,
+		AvgPrice:      order.AvgPrice,
+		UpdateTime:    order.UpdateTime,
+	}, true
+}
+
+func (r *replayExchange) GetName() string {
+	return config.ReplayExchangeName
+}
+
+func (r *replayExchange) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	if err := r.sim.Wait(ctx, r.sim.AckDelay(), r.speed); err != nil {
+		return nil, err
+	}
+	return r.placeOrder(req), nil
+}
+
+// placeOrder 在内存中记录挂单
+func (r *replayExchange) placeOrder(req *OrderRequest) *Order {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	order := &Order{
+		OrderID:       r.nextOrderID,
+		ClientOrderID: req.ClientOrderID,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		Status:        OrderStatusNew,
+		CreatedAt:     time.Now(),
+		UpdateTime:    time.Now().UnixMilli(),
+	}
+	r.nextOrderID++
+	r.openOrders[order.OrderID] = order
+	copied := *order
+	return &copied
+}
+
+func (r *replayExchange) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	if err := r.sim.Wait(ctx, r.sim.AckDelay(), r.speed); err != nil {
+		return nil, false
+	}
+	placed := make([]*Order, 0, len(orders))
+	for _, req := range orders {
+		placed = append(placed, r.placeOrder(req))
+	}
+	return placed, false
+}
+
+func (r *replayExchange) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	if err := r.sim.Wait(ctx, r.sim.AckDelay(), r.speed); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.openOrders, orderID)
+	return nil
+}
+
+func (r *replayExchange) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	if err := r.sim.Wait(ctx, r.sim.AckDelay(), r.speed); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range orderIDs {
+		delete(r.openOrders, id)
+	}
+	return nil
+}
+
+func (r *replayExchange) CancelAllOrders(ctx context.Context, symbol string) error {
+	if err := r.sim.Wait(ctx, r.sim.AckDelay(), r.speed); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, order := range r.openOrders {
+		if order.Symbol == symbol {
+			delete(r.openOrders, id)
+		}
+	}
+	return nil
+}
+
+func (r *replayExchange) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	order, ok := r.openOrders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("订单不存在: %d", orderID)
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (r *replayExchange) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	orders := make([]*Order, 0, len(r.openOrders))
+	for _, order := range r.openOrders {
+		if order.Symbol == symbol {
+			copied := *order
+			orders = append(orders, &copied)
+		}
+	}
+	return orders, nil
+}
+
+func (r *replayExchange) GetAccount(ctx context.Context) (*Account, error) {
+	return &Account{
+		TotalWalletBalance: r.balance,
+		TotalMarginBalance: r.balance,
+		AvailableBalance:   r.balance,
+	}, nil
+}
+
+func (r *replayExchange) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	return []*Position{}, nil
+}
+
+func (r *replayExchange) GetBalance(ctx context.Context, asset string) (float64, error) {
+	return r.balance, nil
+}
+
+func (r *replayExchange) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.orderCb = callback
+	return nil
+}
+
+func (r *replayExchange) StopOrderStream() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.orderCb = nil
+	return nil
+}
+
+func (r *replayExchange) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.lastPrice <= 0 {
+		return 0, fmt.Errorf("回放尚未推送价格")
+	}
+	return r.lastPrice, nil
+}
+
+// StartPriceStream 注册价格回调并启动回放（价格流是回放的驱动源）
+func (r *replayExchange) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	r.mu.Lock()
+	r.priceCbs[symbol] = callback
+	r.mu.Unlock()
+	r.startPlayback.Do(func() {
+		go r.play(ctx)
+	})
+	return nil
+}
+
+func (r *replayExchange) StartKlineStream(ctx context.Context, symbols []string, interval string, callback CandleUpdateCallback) error {
+	logger.Warn("⚠️ [行情回放] 录制文件不包含K线数据，K线流不会推送")
+	return nil
+}
+
+func (r *replayExchange) StopKlineStream() error {
+	return nil
+}
+
+func (r *replayExchange) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	return []*Candle{}, nil
+}
+
+func (r *replayExchange) GetPriceDecimals() int {
+	return r.priceDec
+}
+
+func (r *replayExchange) GetQuantityDecimals() int {
+	return r.qtyDec
+}
+
+func (r *replayExchange) GetBaseAsset() string {
+	for _, quote := range []string{"USDT", "USDC", "BUSD", "USD"} {
+		if strings.HasSuffix(r.symbol, quote) {
+			return strings.TrimSuffix(r.symbol, quote)
+		}
+	}
+	return r.symbol
+}
+
+func (r *replayExchange) GetQuoteAsset() string {
+	for _, quote := range []string{"USDT", "USDC", "BUSD", "USD"} {
+		if strings.HasSuffix(r.symbol, quote) {
+			return quote
+		}
+	}
+	return "USDT"
+}
+
+func (r *replayExchange) GetFundingRate(ctx context.Context, symbol string) (float64, error) {
+	return 0, nil
+}
+
+func (r *replayExchange) GetSpotPrice(ctx context.Context, symbol string) (float64, error) {
+	return r.GetLatestPrice(ctx, symbol)
+}
@@ -0,0 +1,142 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"quantmesh/config"
+)
+
+type adapterOrderUpdate struct {
+	OrderID       int64
+	ClientOrderID string
+	Symbol        string
+	Side          string
+	Status        string
+	Price         float64
+	Quantity      float64
+	ExecutedQty   float64
+}
+
+func TestNormalizeOrderUpdate(t *testing.T) {
+	u, ok := normalizeOrderUpdate(adapterOrderUpdate{
+		OrderID:       42,
+		ClientOrderID: "abc",
+		Symbol:        "BTCUSDT",
+		Side:          "BUY",
+		Status:        "FILLED",
+		Price:         65000.5,
+		Quantity:      0.01,
+		ExecutedQty:   0.01,
+	})
+	if !ok {
+		t.Fatal("结构体订单更新应可转换")
+	}
+	if u.OrderID != 42 || u.Status != OrderStatusFilled || u.Side != SideBuy || u.ExecutedQty != 0.01 {
+		t.Errorf("转换结果不正确: %+v", u)
+	}
+
+	if _, ok := normalizeOrderUpdate("not a struct"); ok {
+		t.Error("非结构体不应转换成功")
+	}
+}
+
+func TestLoadMarketDataRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rec.jsonl")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc := json.NewEncoder(file)
+	enc.Encode(&MarketDataRecord{Time: 1000, Type: RecordTypePrice, Symbol: "BTCUSDT", Price: 65000.25})
+	enc.Encode(&MarketDataRecord{Time: 1500, Type: RecordTypeOrder, Symbol: "BTCUSDT", Order: &OrderUpdate{OrderID: 7, Symbol: "BTCUSDT", Quantity: 0.0015}})
+	file.Close()
+
+	records, err := LoadMarketDataRecords(path)
+	if err != nil {
+		t.Fatalf("读取录制文件失败: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("期望 2 条记录，实际 %d", len(records))
+	}
+	if records[1].Order == nil || records[1].Order.OrderID != 7 {
+		t.Errorf("订单记录解析错误: %+v", records[1])
+	}
+
+	if got := decimalPlaces(65000.25); got != 2 {
+		t.Errorf("decimalPlaces(65000.25) = %d, 期望 2", got)
+	}
+	if got := decimalPlaces(0.0015); got != 4 {
+		t.Errorf("decimalPlaces(0.0015) = %d, 期望 4", got)
+	}
+}
+
+// writeReplayFile 写入录制文件并返回路径
+func writeReplayFile(t *testing.T, records ...*MarketDataRecord) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rec.jsonl")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	enc := json.NewEncoder(file)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestReplayRemapsRecordedFills(t *testing.T) {
+	path := writeReplayFile(t,
+		&MarketDataRecord{Type: RecordTypePrice, Symbol: "BTCUSDT", Price: 1000},
+		// 录制时的新单推送：回放中的挂单由回放自己确认，不再推送
+		&MarketDataRecord{Type: RecordTypeOrder, Order: &OrderUpdate{OrderID: 9001, ClientOrderID: "rec-1", Symbol: "BTCUSDT", Side: SideBuy, Status: OrderStatusNew, Price: 990, Quantity: 0.1}},
+		&MarketDataRecord{Type: RecordTypeOrder, Order: &OrderUpdate{OrderID: 9001, ClientOrderID: "rec-1", Symbol: "BTCUSDT", Side: SideBuy, Status: OrderStatusPartiallyFilled, Price: 990, Quantity: 0.1, ExecutedQty: 0.04, AvgPrice: 990}},
+		// 另一个录制订单在同一价位成交，映射到下一笔未映射的挂单
+		&MarketDataRecord{Type: RecordTypeOrder, Order: &OrderUpdate{OrderID: 9002, ClientOrderID: "rec-2", Symbol: "BTCUSDT", Side: SideBuy, Status: OrderStatusFilled, Price: 990, Quantity: 0.1, ExecutedQty: 0.1, AvgPrice: 990}},
+		// 录制数量大于回放挂单数量时按挂单数量封顶
+		&MarketDataRecord{Type: RecordTypeOrder, Order: &OrderUpdate{OrderID: 9001, ClientOrderID: "rec-1", Symbol: "BTCUSDT", Side: SideBuy, Status: OrderStatusPartiallyFilled, Price: 990, Quantity: 0.1, ExecutedQty: 0.2, AvgPrice: 990}},
+		// 回放中没有对应方向的挂单
+		&MarketDataRecord{Type: RecordTypeOrder, Order: &OrderUpdate{OrderID: 9003, ClientOrderID: "rec-3", Symbol: "BTCUSDT", Side: SideSell, Status: OrderStatusFilled, Price: 1010, Quantity: 0.1, ExecutedQty: 0.1}},
+	)
+	cfg := &config.Config{}
+	cfg.MarketData.Replay.File = path
+	r, err := newReplayExchange(cfg, "BTCUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	first, _ := r.PlaceOrder(ctx, &OrderRequest{ClientOrderID: "live-1", Symbol: "BTCUSDT", Side: SideBuy, Type: OrderTypeLimit, Price: 990, Quantity: 0.15})
+	second, _ := r.PlaceOrder(ctx, &OrderRequest{ClientOrderID: "live-2", Symbol: "BTCUSDT", Side: SideBuy, Type: OrderTypeLimit, Price: 990, Quantity: 0.05})
+
+	var updates []OrderUpdate
+	r.StartOrderStream(ctx, func(u interface{}) { updates = append(updates, u.(OrderUpdate)) })
+	r.priceCbs["BTCUSDT"] = func(float64) {}
+	r.play(ctx)
+
+	if len(updates) != 3 {
+		t.Fatalf("got %d updates, want 3: %+v", len(updates), updates)
+	}
+	if u := updates[0]; u.OrderID != first.OrderID || u.ClientOrderID != "live-1" || u.Status != OrderStatusPartiallyFilled || u.ExecutedQty != 0.04 {
+		t.Errorf("first fill not remapped: %+v", u)
+	}
+	if u := updates[1]; u.OrderID != second.OrderID || u.ClientOrderID != "live-2" || u.Status != OrderStatusFilled || u.ExecutedQty != 0.05 {
+		t.Errorf("second fill not remapped: %+v", u)
+	}
+	if u := updates[2]; u.OrderID != first.OrderID || u.Status != OrderStatusFilled || u.ExecutedQty != 0.15 {
+		t.Errorf("fill above order quantity not capped: %+v", u)
+	}
+	if open, _ := r.GetOpenOrders(ctx, "BTCUSDT"); len(open) != 0 {
+		t.Errorf("filled orders should leave the book: %+v", open)
+	}
+	if r.fills != 3 || r.dropped != 2 {
+		t.Errorf("fills=%d dropped=%d, want 3 and 2", r.fills, r.dropped)
+	}
+}
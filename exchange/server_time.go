@@ -0,0 +1,13 @@
+package exchange
+
+import (
+	"context"
+	"time"
+)
+
+// ServerTimeProvider 交易所服务器时间查询接口（可选能力，通过类型断言检测）
+// 用于检测本机时钟偏差：偏差过大时签名请求会被交易所拒绝（如币安 -1021）
+type ServerTimeProvider interface {
+	// GetServerTime 查询交易所服务器当前时间
+	GetServerTime(ctx context.Context) (time.Time, error)
+}
@@ -0,0 +1,111 @@
+package exchange
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+)
+
+// ExecutionSimulator 模拟执行模型
+// 按配置的分布抽样下单回报延迟、成交延迟，并按滑点模型调整成交价，
+// 供回放交易所和回测器使用，使结果接近实际 VPS 到交易所的执行条件
+type ExecutionSimulator struct {
+	cfg config.ExecutionSimConfig
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewExecutionSimulator 创建模拟执行模型，seed 为 0 时使用当前时间
+func NewExecutionSimulator(cfg config.ExecutionSimConfig, seed int64) *ExecutionSimulator {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &ExecutionSimulator{cfg: cfg, rng: rand.New(rand.NewSource(seed))}
+}
+
+// AckDelay 抽样一次下单/撤单回报延迟
+func (s *ExecutionSimulator) AckDelay() time.Duration {
+	return s.sample(s.cfg.AckLatency)
+}
+
+// FillDelay 抽样一次回报之后到成交的延迟
+func (s *ExecutionSimulator) FillDelay() time.Duration {
+	return s.sample(s.cfg.FillLatency)
+}
+
+// OrderDelay 抽样一次从发出订单到成交的总延迟（回报延迟 + 成交延迟）
+func (s *ExecutionSimulator) OrderDelay() time.Duration {
+	return s.AckDelay() + s.FillDelay()
+}
+
+// Slip 按滑点模型调整成交价：买入向上、卖出向下
+func (s *ExecutionSimulator) Slip(side Side, price float64) float64 {
+	bps := 0.0
+	switch s.cfg.Slippage.Model {
+	case config.SlippageFixed:
+		bps = s.cfg.Slippage.Bps
+	case config.SlippageRandom:
+		s.mu.Lock()
+		bps = s.rng.Float64() * 2 * s.cfg.Slippage.Bps
+		s.mu.Unlock()
+		if s.cfg.Slippage.MaxBps > 0 {
+			bps = math.Min(bps, s.cfg.Slippage.MaxBps)
+		}
+	}
+	if strings.EqualFold(string(side), string(SideSell)) {
+		return price * (1 - bps/10000)
+	}
+	return price * (1 + bps/10000)
+}
+
+// Wait 等待 d（按倍速缩短），ctx 取消时提前返回
+func (s *ExecutionSimulator) Wait(ctx context.Context, d time.Duration, speed float64) error {
+	if speed < 0 {
+		return nil
+	}
+	if speed > 0 {
+		d = time.Duration(float64(d) / speed)
+	}
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sample 按分布抽样延迟，结果不小于 0、不超过 max_ms
+func (s *ExecutionSimulator) sample(l config.LatencyConfig) time.Duration {
+	ms := l.MeanMs
+	if l.StdDevMs > 0 {
+		s.mu.Lock()
+		switch l.Distribution {
+		case config.LatencyUniform:
+			ms = l.MeanMs + (s.rng.Float64()*2-1)*l.StdDevMs
+		case config.LatencyNormal:
+			ms = l.MeanMs + s.rng.NormFloat64()*l.StdDevMs
+		case config.LatencyLognormal:
+			if l.MeanMs > 0 {
+				// 由均值和标准差换算对数正态分布参数
+				sigma2 := math.Log(1 + (l.StdDevMs*l.StdDevMs)/(l.MeanMs*l.MeanMs))
+				mu := math.Log(l.MeanMs) - sigma2/2
+				ms = math.Exp(mu + s.rng.NormFloat64()*math.Sqrt(sigma2))
+			}
+		}
+		s.mu.Unlock()
+	}
+	ms = math.Max(0, ms)
+	if l.MaxMs > 0 {
+		ms = math.Min(ms, l.MaxMs)
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
@@ -0,0 +1,54 @@
+package exchange
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"quantmesh/config"
+)
+
+func TestExecutionSimulatorLatency(t *testing.T) {
+	sim := NewExecutionSimulator(config.ExecutionSimConfig{
+		AckLatency:  config.LatencyConfig{Distribution: config.LatencyLognormal, MeanMs: 50, StdDevMs: 30, MaxMs: 200},
+		FillLatency: config.LatencyConfig{Distribution: config.LatencyFixed, MeanMs: 20},
+	}, 1)
+
+	var total time.Duration
+	const n = 5000
+	for i := 0; i < n; i++ {
+		d := sim.AckDelay()
+		if d < 0 || d > 200*time.Millisecond {
+			t.Fatalf("ack delay %v outside [0, max_ms]", d)
+		}
+		total += d
+	}
+	if mean := float64(total/n) / float64(time.Millisecond); math.Abs(mean-50) > 5 {
+		t.Errorf("lognormal mean = %.1fms, want about 50ms", mean)
+	}
+	if d := sim.FillDelay(); d != 20*time.Millisecond {
+		t.Errorf("fixed fill delay = %v", d)
+	}
+}
+
+func TestExecutionSimulatorSlippage(t *testing.T) {
+	fixed := NewExecutionSimulator(config.ExecutionSimConfig{Slippage: config.SlippageConfig{Model: config.SlippageFixed, Bps: 10}}, 1)
+	if got := fixed.Slip(SideBuy, 100); math.Abs(got-100.1) > 1e-9 {
+		t.Errorf("buy slip = %v, want 100.1", got)
+	}
+	if got := fixed.Slip(SideSell, 100); math.Abs(got-99.9) > 1e-9 {
+		t.Errorf("sell slip = %v, want 99.9", got)
+	}
+
+	random := NewExecutionSimulator(config.ExecutionSimConfig{Slippage: config.SlippageConfig{Model: config.SlippageRandom, Bps: 10, MaxBps: 15}}, 1)
+	for i := 0; i < 1000; i++ {
+		if got := random.Slip(SideBuy, 100); got < 100 || got > 100.15+1e-9 {
+			t.Fatalf("random slip %v outside [0, max_bps]", got)
+		}
+	}
+
+	none := NewExecutionSimulator(config.ExecutionSimConfig{}, 1)
+	if got := none.Slip(SideBuy, 100); got != 100 {
+		t.Errorf("zero config should not slip: %v", got)
+	}
+}
@@ -0,0 +1,194 @@
+package exchange
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"quantmesh/logger"
+)
+
+// SymbolStatusTrading 交易对正常交易状态（币安 exchangeInfo 的 status 字段）
+const SymbolStatusTrading = "TRADING"
+
+// SymbolStatusDelisted 交易对已从交易所列表中移除（注册表内部标记）
+const SymbolStatusDelisted = "DELISTED"
+
+// 交易对状态变更类型
+const (
+	SymbolChangeStatus          = "status_changed"   // 状态变化（如 TRADING -> SETTLING）
+	SymbolChangeDelistScheduled = "delist_scheduled" // 永续合约被设置了近期的交割（下架）时间
+	SymbolChangeRemoved         = "removed"          // 交易对从列表中消失
+)
+
+// delistHorizon 永续合约交割时间在该期限内视为已公告下架（正常永续合约的交割时间为 2100 年）
+const delistHorizon = 365 * 24 * time.Hour
+
+// SymbolStatusChange 交易对状态变更（下架公告、停止交易、改名等）
+type SymbolStatusChange struct {
+	Exchange     string    `json:"exchange"`
+	Symbol       string    `json:"symbol"`
+	Kind         string    `json:"kind"`
+	OldStatus    string    `json:"old_status"`
+	NewStatus    string    `json:"new_status"`
+	DeliveryDate time.Time `json:"delivery_date,omitempty"`
+	RenamedTo    string    `json:"renamed_to,omitempty"` // 疑似改名后的新交易对（如 SHIBUSDT -> 1000SHIBUSDT）
+	DetectedAt   time.Time `json:"detected_at"`
+}
+
+// Delisting 该变更是否意味着交易对将无法继续交易（需要暂停并迁移）
+func (c *SymbolStatusChange) Delisting() bool {
+	return c.Kind != SymbolChangeStatus || c.NewStatus != SymbolStatusTrading
+}
+
+// detectStatusChange 比较同一交易对前后两次全量加载的元数据
+// prev 只来自 Register 登记（没有状态信息）时不做判断
+func detectStatusChange(prev, cur *SymbolMetadata, now time.Time) *SymbolStatusChange {
+	if prev.Status == "" {
+		return nil
+	}
+	change := &SymbolStatusChange{
+		Exchange:     cur.Exchange,
+		Symbol:       cur.Symbol,
+		OldStatus:    prev.Status,
+		NewStatus:    cur.Status,
+		DeliveryDate: cur.DeliveryDate,
+		DetectedAt:   now,
+	}
+	switch {
+	case cur.Status != prev.Status:
+		change.Kind = SymbolChangeStatus
+	case cur.ContractType == "PERPETUAL" && !cur.DeliveryDate.IsZero() &&
+		!cur.DeliveryDate.Equal(prev.DeliveryDate) && cur.DeliveryDate.Before(now.Add(delistHorizon)):
+		change.Kind = SymbolChangeDelistScheduled
+	default:
+		return nil
+	}
+	return change
+}
+
+// findRename 在新上线的交易对中查找疑似改名后的交易对
+// 同一计价币种下，新旧基础币种互为后缀（如 SHIB -> 1000SHIB 的面值调整）时视为改名
+func findRename(old *SymbolMetadata, listings []*SymbolMetadata) string {
+	if old.BaseAsset == "" {
+		return ""
+	}
+	for _, meta := range listings {
+		if meta.Symbol == old.Symbol || meta.BaseAsset == "" || meta.QuoteAsset != old.QuoteAsset {
+			continue
+		}
+		if strings.HasSuffix(meta.BaseAsset, old.BaseAsset) || strings.HasSuffix(old.BaseAsset, meta.BaseAsset) {
+			return meta.Symbol
+		}
+	}
+	return ""
+}
+
+// markRemoved 将上次全量加载存在、本次消失的交易对标记为已下架
+func (r *SymbolRegistry) markRemoved(key string, seen map[string]bool, listings []*SymbolMetadata) []*SymbolStatusChange {
+	now := r.now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var changes []*SymbolStatusChange
+	for symbol, meta := range r.symbols[key] {
+		if seen[symbol] || meta.Status == "" || meta.Status == SymbolStatusDelisted {
+			continue
+		}
+		changes = append(changes, &SymbolStatusChange{
+			Exchange:     meta.Exchange,
+			Symbol:       symbol,
+			Kind:         SymbolChangeRemoved,
+			OldStatus:    meta.Status,
+			NewStatus:    SymbolStatusDelisted,
+			DeliveryDate: meta.DeliveryDate,
+			RenamedTo:    findRename(meta, listings),
+			DetectedAt:   now,
+		})
+		meta.Status = SymbolStatusDelisted
+		meta.UpdatedAt = now
+	}
+	return changes
+}
+
+// recordChanges 更新待处理的变更列表并通知回调
+// 交易对恢复正常交易时从待处理列表中移除
+func (r *SymbolRegistry) recordChanges(changes []*SymbolStatusChange) {
+	if len(changes) == 0 {
+		return
+	}
+	r.mu.Lock()
+	for _, change := range changes {
+		key := registryKey(change.Exchange) + ":" + change.Symbol
+		if change.Delisting() {
+			r.pending[key] = change
+		} else {
+			delete(r.pending, key)
+		}
+	}
+	listeners := append([]func(*SymbolStatusChange){}, r.changeListeners...)
+	r.mu.Unlock()
+
+	for _, change := range changes {
+		if change.Delisting() {
+			logger.Warn("⚠️ [%s] 交易对 %s 状态变更: %s (%s -> %s) 改名: %s",
+				change.Exchange, change.Symbol, change.Kind, change.OldStatus, change.NewStatus, change.RenamedTo)
+		} else {
+			logger.Info("ℹ️ [%s] 交易对 %s 恢复交易: %s -> %s",
+				change.Exchange, change.Symbol, change.OldStatus, change.NewStatus)
+		}
+		for _, fn := range listeners {
+			fn(change)
+		}
+	}
+}
+
+// OnStatusChange 注册交易对状态变更回调
+func (r *SymbolRegistry) OnStatusChange(fn func(*SymbolStatusChange)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changeListeners = append(r.changeListeners, fn)
+}
+
+// PendingChanges 返回尚未处理（迁移）的下架/改名变更，按交易所和交易对排序
+func (r *SymbolRegistry) PendingChanges() []*SymbolStatusChange {
+	r.mu.RLock()
+	result := make([]*SymbolStatusChange, 0, len(r.pending))
+	for _, change := range r.pending {
+		cp := *change
+		result = append(result, &cp)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Exchange != result[j].Exchange {
+			return result[i].Exchange < result[j].Exchange
+		}
+		return result[i].Symbol < result[j].Symbol
+	})
+	return result
+}
+
+// PendingChange 查询交易对尚未处理的下架/改名变更
+func (r *SymbolRegistry) PendingChange(exchangeName, symbol string) (*SymbolStatusChange, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	change, ok := r.pending[registryKey(exchangeName)+":"+symbol]
+	if !ok {
+		return nil, false
+	}
+	cp := *change
+	return &cp, true
+}
+
+// ResolveChange 迁移完成后将变更移出待处理列表
+func (r *SymbolRegistry) ResolveChange(exchangeName, symbol string) bool {
+	key := registryKey(exchangeName) + ":" + symbol
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.pending[key]; !ok {
+		return false
+	}
+	delete(r.pending, key)
+	return true
+}
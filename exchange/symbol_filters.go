@@ -0,0 +1,80 @@
+package exchange
+
+import (
+	"time"
+
+	"quantmesh/logger"
+)
+
+// SymbolFilterChange 交易对下单规则变更（最小名义价值、最小数量、步长、价格精度）
+// 交易所会不定期上调最小名义价值（如 5 -> 20 USDT），导致按原配置下单的小额网格全部被拒
+type SymbolFilterChange struct {
+	Exchange       string    `json:"exchange"`
+	Symbol         string    `json:"symbol"`
+	OldMinNotional float64   `json:"old_min_notional"`
+	NewMinNotional float64   `json:"new_min_notional"`
+	OldMinQty      float64   `json:"old_min_qty"`
+	NewMinQty      float64   `json:"new_min_qty"`
+	OldStepSize    float64   `json:"old_step_size"`
+	NewStepSize    float64   `json:"new_step_size"`
+	OldTickSize    float64   `json:"old_tick_size"`
+	NewTickSize    float64   `json:"new_tick_size"`
+	DetectedAt     time.Time `json:"detected_at"`
+}
+
+// MinNotionalRaised 最小名义价值是否被上调
+func (c *SymbolFilterChange) MinNotionalRaised() bool {
+	return c.NewMinNotional > c.OldMinNotional
+}
+
+// detectFilterChange 比较同一交易对前后两次全量加载的下单规则
+// prev 只来自 Register 登记（没有状态信息）时不做判断；交易所未返回的字段（为 0）不参与比较
+func detectFilterChange(prev, cur *SymbolMetadata, now time.Time) *SymbolFilterChange {
+	if prev.Status == "" {
+		return nil
+	}
+	changed := func(old, new float64) bool { return old > 0 && new > 0 && old != new }
+	if !changed(prev.MinNotional, cur.MinNotional) && !changed(prev.MinQty, cur.MinQty) &&
+		!changed(prev.StepSize, cur.StepSize) && !changed(prev.TickSize, cur.TickSize) {
+		return nil
+	}
+	return &SymbolFilterChange{
+		Exchange:       cur.Exchange,
+		Symbol:         cur.Symbol,
+		OldMinNotional: prev.MinNotional,
+		NewMinNotional: cur.MinNotional,
+		OldMinQty:      prev.MinQty,
+		NewMinQty:      cur.MinQty,
+		OldStepSize:    prev.StepSize,
+		NewStepSize:    cur.StepSize,
+		OldTickSize:    prev.TickSize,
+		NewTickSize:    cur.TickSize,
+		DetectedAt:     now,
+	}
+}
+
+// OnFilterChange 注册交易对下单规则变更回调
+func (r *SymbolRegistry) OnFilterChange(fn func(*SymbolFilterChange)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.filterListeners = append(r.filterListeners, fn)
+}
+
+// notifyFilterChanges 记录日志并通知回调
+func (r *SymbolRegistry) notifyFilterChanges(changes []*SymbolFilterChange) {
+	if len(changes) == 0 {
+		return
+	}
+	r.mu.RLock()
+	listeners := append([]func(*SymbolFilterChange){}, r.filterListeners...)
+	r.mu.RUnlock()
+
+	for _, change := range changes {
+		logger.Warn("⚠️ [%s] 交易对 %s 下单规则变更: 最小名义价值 %g -> %g, 最小数量 %g -> %g, 步长 %g -> %g, 价格精度 %g -> %g",
+			change.Exchange, change.Symbol, change.OldMinNotional, change.NewMinNotional, change.OldMinQty, change.NewMinQty,
+			change.OldStepSize, change.NewStepSize, change.OldTickSize, change.NewTickSize)
+		for _, fn := range listeners {
+			fn(change)
+		}
+	}
+}
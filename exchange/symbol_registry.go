@@ -0,0 +1,320 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"quantmesh/logger"
+	"quantmesh/utils"
+)
+
+// SymbolMetadata 交易对元数据（精度、币种、合约类型、下单过滤条件）
+// 数值为 0 / 字符串为空表示该交易所未提供
+type SymbolMetadata struct {
+	Exchange         string    `json:"exchange"`
+	Symbol           string    `json:"symbol"`
+	BaseAsset        string    `json:"base_asset"`
+	QuoteAsset       string    `json:"quote_asset"`
+	ContractType     string    `json:"contract_type"` // PERPETUAL / CURRENT_QUARTER 等
+	Status           string    `json:"status"`        // TRADING / SETTLING 等
+	PriceDecimals    int       `json:"price_decimals"`
+	QuantityDecimals int       `json:"quantity_decimals"`
+	TickSize         float64   `json:"tick_size"`
+	StepSize         float64   `json:"step_size"`
+	MinQty           float64   `json:"min_qty"`
+	MinNotional      float64   `json:"min_notional"`
+	OnboardDate      time.Time `json:"onboard_date"`
+	DeliveryDate     time.Time `json:"delivery_date"`
+	FirstSeen        time.Time `json:"first_seen"`  // 注册表首次发现该交易对的时间
+	NewListing       bool      `json:"new_listing"` // 是否在首次全量加载之后才出现（新上线）
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// SymbolMetadataProvider 全量交易对元数据查询接口（可选能力，通过类型断言检测）
+type SymbolMetadataProvider interface {
+	// GetSymbolsMetadata 查询交易所全部交易对的元数据
+	GetSymbolsMetadata(ctx context.Context) ([]*SymbolMetadata, error)
+}
+
+// SymbolRegistry 统一的交易对元数据注册表
+// 启动时由各交易对运行时登记，支持全量查询的交易所定期刷新并检测新上线的交易对
+type SymbolRegistry struct {
+	mu        sync.RWMutex
+	symbols   map[string]map[string]*SymbolMetadata // 交易所（小写） -> 交易对 -> 元数据
+	loaded    map[string]bool                       // 已完成过全量加载的交易所（之后新出现的交易对视为上新）
+	listeners []func(*SymbolMetadata)
+	now       func() time.Time
+
+	pending         map[string]*SymbolStatusChange // 交易所（小写）:交易对 -> 待迁移的下架/改名变更
+	changeListeners []func(*SymbolStatusChange)
+	filterListeners []func(*SymbolFilterChange)
+}
+
+// NewSymbolRegistry 创建交易对元数据注册表
+func NewSymbolRegistry() *SymbolRegistry {
+	return &SymbolRegistry{
+		symbols: make(map[string]map[string]*SymbolMetadata),
+		loaded:  make(map[string]bool),
+		now:     time.Now,
+		pending: make(map[string]*SymbolStatusChange),
+	}
+}
+
+var defaultSymbolRegistry = NewSymbolRegistry()
+
+// GetSymbolRegistry 获取全局交易对元数据注册表
+func GetSymbolRegistry() *SymbolRegistry {
+	return defaultSymbolRegistry
+}
+
+func registryKey(exchangeName string) string {
+	return strings.ToLower(exchangeName)
+}
+
+// Register 从交易所实例登记单个交易对（所有适配器都支持的精度和币种信息）
+// 已有更完整的元数据时只补充缺失字段
+func (r *SymbolRegistry) Register(ex IExchange, symbol string) {
+	r.upsert(&SymbolMetadata{
+		Exchange:         ex.GetName(),
+		Symbol:           symbol,
+		BaseAsset:        ex.GetBaseAsset(),
+		QuoteAsset:       ex.GetQuoteAsset(),
+		PriceDecimals:    ex.GetPriceDecimals(),
+		QuantityDecimals: ex.GetQuantityDecimals(),
+	}, false)
+}
+
+// Refresh 全量刷新交易所的元数据，返回本次新上线的交易对
+// 首次加载之后的刷新还会检测状态变更（下架公告、停止交易、从列表移除）并通知 OnStatusChange 回调，
+// 下单规则（最小名义价值等）变化时通知 OnFilterChange 回调
+// 交易所不支持全量查询时返回 ErrNotImplemented
+func (r *SymbolRegistry) Refresh(ctx context.Context, ex IExchange) ([]*SymbolMetadata, error) {
+	provider, ok := As[SymbolMetadataProvider](ex)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	list, err := provider.GetSymbolsMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := registryKey(ex.GetName())
+	r.mu.RLock()
+	detect := r.loaded[key]
+	r.mu.RUnlock()
+
+	now := r.now()
+	var listings []*SymbolMetadata
+	var changes []*SymbolStatusChange
+	var changedFrom []*SymbolMetadata
+	var filterChanges []*SymbolFilterChange
+	seen := make(map[string]bool, len(list))
+	for _, meta := range list {
+		meta.Exchange = ex.GetName()
+		meta.NewListing = detect
+		seen[meta.Symbol] = true
+		prev, had := r.Lookup(meta.Exchange, meta.Symbol)
+		if r.upsert(meta, true) && detect {
+			cp := *meta
+			listings = append(listings, &cp)
+		} else if had && detect {
+			if change := detectStatusChange(prev, meta, now); change != nil {
+				changes = append(changes, change)
+				changedFrom = append(changedFrom, prev)
+			}
+			if change := detectFilterChange(prev, meta, now); change != nil {
+				filterChanges = append(filterChanges, change)
+			}
+		}
+	}
+	if detect {
+		for i, change := range changes {
+			if change.Delisting() {
+				change.RenamedTo = findRename(changedFrom[i], listings)
+			}
+		}
+		changes = append(changes, r.markRemoved(key, seen, listings)...)
+	}
+
+	r.mu.Lock()
+	r.loaded[key] = true
+	listeners := append([]func(*SymbolMetadata){}, r.listeners...)
+	r.mu.Unlock()
+
+	for _, meta := range listings {
+		logger.Info("🆕 [%s] 检测到新上线交易对: %s (%s/%s, %s)",
+			meta.Exchange, meta.Symbol, meta.BaseAsset, meta.QuoteAsset, meta.ContractType)
+		for _, fn := range listeners {
+			fn(meta)
+		}
+	}
+	r.recordChanges(changes)
+	r.notifyFilterChanges(filterChanges)
+	return listings, nil
+}
+
+// upsert 写入元数据，返回是否为新出现的交易对
+// overwrite=false 时只补充已有记录中缺失的字段
+func (r *SymbolRegistry) upsert(meta *SymbolMetadata, overwrite bool) bool {
+	key := registryKey(meta.Exchange)
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	bySymbol, ok := r.symbols[key]
+	if !ok {
+		bySymbol = make(map[string]*SymbolMetadata)
+		r.symbols[key] = bySymbol
+	}
+	existing, ok := bySymbol[meta.Symbol]
+	if !ok {
+		cp := *meta
+		cp.FirstSeen = now
+		cp.UpdatedAt = now
+		bySymbol[meta.Symbol] = &cp
+		return true
+	}
+	if overwrite {
+		firstSeen, listing := existing.FirstSeen, existing.NewListing
+		*existing = *meta
+		existing.FirstSeen = firstSeen
+		existing.NewListing = listing
+	} else {
+		mergeMetadata(existing, meta)
+	}
+	existing.UpdatedAt = now
+	return false
+}
+
+// mergeMetadata 用 src 补充 dst 中缺失的字段
+func mergeMetadata(dst, src *SymbolMetadata) {
+	if dst.BaseAsset == "" {
+		dst.BaseAsset = src.BaseAsset
+	}
+	if dst.QuoteAsset == "" {
+		dst.QuoteAsset = src.QuoteAsset
+	}
+	if dst.ContractType == "" {
+		dst.ContractType = src.ContractType
+	}
+	if dst.Status == "" {
+		dst.Status = src.Status
+	}
+	if dst.PriceDecimals == 0 {
+		dst.PriceDecimals = src.PriceDecimals
+	}
+	if dst.QuantityDecimals == 0 {
+		dst.QuantityDecimals = src.QuantityDecimals
+	}
+	if dst.TickSize == 0 {
+		dst.TickSize = src.TickSize
+	}
+	if dst.StepSize == 0 {
+		dst.StepSize = src.StepSize
+	}
+	if dst.MinQty == 0 {
+		dst.MinQty = src.MinQty
+	}
+	if dst.MinNotional == 0 {
+		dst.MinNotional = src.MinNotional
+	}
+	if dst.OnboardDate.IsZero() {
+		dst.OnboardDate = src.OnboardDate
+	}
+	if dst.DeliveryDate.IsZero() {
+		dst.DeliveryDate = src.DeliveryDate
+	}
+}
+
+// Lookup 查询交易对元数据（返回副本）
+func (r *SymbolRegistry) Lookup(exchangeName, symbol string) (*SymbolMetadata, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	meta, ok := r.symbols[registryKey(exchangeName)][symbol]
+	if !ok {
+		return nil, false
+	}
+	cp := *meta
+	return &cp, true
+}
+
+// List 列出交易所的全部交易对元数据（exchangeName 为空时列出所有交易所），按交易所和交易对排序
+func (r *SymbolRegistry) List(exchangeName string) []*SymbolMetadata {
+	r.mu.RLock()
+	var result []*SymbolMetadata
+	for key, bySymbol := range r.symbols {
+		if exchangeName != "" && key != registryKey(exchangeName) {
+			continue
+		}
+		for _, meta := range bySymbol {
+			cp := *meta
+			result = append(result, &cp)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Exchange != result[j].Exchange {
+			return result[i].Exchange < result[j].Exchange
+		}
+		return result[i].Symbol < result[j].Symbol
+	})
+	return result
+}
+
+// RecentListings 返回 since 之后新上线的交易对
+func (r *SymbolRegistry) RecentListings(since time.Time) []*SymbolMetadata {
+	var result []*SymbolMetadata
+	for _, meta := range r.List("") {
+		if meta.NewListing && meta.FirstSeen.After(since) {
+			result = append(result, meta)
+		}
+	}
+	return result
+}
+
+// OnNewListing 注册新上线交易对回调
+func (r *SymbolRegistry) OnNewListing(fn func(*SymbolMetadata)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// StartAutoRefresh 立即全量加载一次，之后定期刷新
+// 交易所不支持全量查询时直接返回，注册表中只保留 Register 登记的信息
+func (r *SymbolRegistry) StartAutoRefresh(ctx context.Context, ex IExchange, interval time.Duration) {
+	refresh := func() error {
+		reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		_, err := r.Refresh(reqCtx, ex)
+		return err
+	}
+	if err := refresh(); errors.Is(err, ErrNotImplemented) {
+		return
+	} else if err != nil {
+		logger.Warn("⚠️ [%s] 加载交易对元数据失败: %v", ex.GetName(), err)
+	}
+
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	utils.GoSupervised(ctx, fmt.Sprintf("symbol-registry:%s", ex.GetName()), func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := refresh(); err != nil {
+					logger.Warn("⚠️ [%s] 刷新交易对元数据失败: %v", ex.GetName(), err)
+				}
+			}
+		}
+	})
+}
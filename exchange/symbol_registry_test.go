@@ -0,0 +1,150 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// metadataExchange 仅实现元数据查询的测试交易所
+type metadataExchange struct {
+	IExchange
+	symbols []*SymbolMetadata
+}
+
+func (m *metadataExchange) GetName() string { return "TestEx" }
+
+func (m *metadataExchange) GetSymbolsMetadata(ctx context.Context) ([]*SymbolMetadata, error) {
+	result := make([]*SymbolMetadata, len(m.symbols))
+	for i, s := range m.symbols {
+		cp := *s
+		result[i] = &cp
+	}
+	return result, nil
+}
+
+func TestSymbolRegistryRefreshDetectsNewListings(t *testing.T) {
+	r := NewSymbolRegistry()
+	ex := &metadataExchange{symbols: []*SymbolMetadata{
+		{Symbol: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT", PriceDecimals: 1, MinNotional: 100},
+	}}
+
+	var notified []string
+	r.OnNewListing(func(meta *SymbolMetadata) { notified = append(notified, meta.Symbol) })
+
+	// 首次全量加载不视为上新
+	listings, err := r.Refresh(context.Background(), ex)
+	if err != nil {
+		t.Fatalf("刷新失败: %v", err)
+	}
+	if len(listings) != 0 || len(notified) != 0 {
+		t.Fatalf("首次加载不应产生上新, 得到 %d", len(listings))
+	}
+
+	ex.symbols = append(ex.symbols, &SymbolMetadata{Symbol: "NEWUSDT", BaseAsset: "NEW", QuoteAsset: "USDT"})
+	listings, _ = r.Refresh(context.Background(), ex)
+	if len(listings) != 1 || listings[0].Symbol != "NEWUSDT" || len(notified) != 1 {
+		t.Fatalf("应检测到 NEWUSDT 上新, 得到 %v", notified)
+	}
+
+	meta, ok := r.Lookup("testex", "BTCUSDT")
+	if !ok || meta.MinNotional != 100 || meta.Exchange != "TestEx" {
+		t.Errorf("查询元数据错误: %+v", meta)
+	}
+	if meta.NewListing {
+		t.Error("BTCUSDT 不应标记为上新")
+	}
+	if got := r.RecentListings(meta.FirstSeen.Add(-1)); len(got) != 1 || got[0].Symbol != "NEWUSDT" {
+		t.Errorf("RecentListings 错误: %v", got)
+	}
+}
+
+func TestSymbolRegistryMergeKeepsRicherData(t *testing.T) {
+	r := NewSymbolRegistry()
+	r.upsert(&SymbolMetadata{Exchange: "TestEx", Symbol: "BTCUSDT", PriceDecimals: 1, TickSize: 0.1}, true)
+	r.upsert(&SymbolMetadata{Exchange: "TestEx", Symbol: "BTCUSDT", PriceDecimals: 2, QuantityDecimals: 3}, false)
+
+	meta, _ := r.Lookup("TestEx", "BTCUSDT")
+	if meta.PriceDecimals != 1 || meta.TickSize != 0.1 || meta.QuantityDecimals != 3 {
+		t.Errorf("合并应只补充缺失字段: %+v", meta)
+	}
+}
+
+func TestSymbolRegistryDetectsDelistingAndRename(t *testing.T) {
+	r := NewSymbolRegistry()
+	farFuture := time.UnixMilli(4133404800000)
+	ex := &metadataExchange{symbols: []*SymbolMetadata{
+		{Symbol: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT", ContractType: "PERPETUAL", Status: "TRADING", DeliveryDate: farFuture},
+		{Symbol: "ETHUSDT", BaseAsset: "ETH", QuoteAsset: "USDT", ContractType: "PERPETUAL", Status: "TRADING", DeliveryDate: farFuture},
+		{Symbol: "SHIBUSDT", BaseAsset: "SHIB", QuoteAsset: "USDT", ContractType: "PERPETUAL", Status: "TRADING", DeliveryDate: farFuture},
+	}}
+
+	var notified []*SymbolStatusChange
+	r.OnStatusChange(func(change *SymbolStatusChange) { notified = append(notified, change) })
+	if _, err := r.Refresh(context.Background(), ex); err != nil {
+		t.Fatalf("刷新失败: %v", err)
+	}
+
+	// BTCUSDT 公告下架（交割时间改为一周后），SHIBUSDT 改名为 1000SHIBUSDT
+	delivery := time.Now().Add(7 * 24 * time.Hour).Truncate(time.Millisecond)
+	ex.symbols = []*SymbolMetadata{
+		{Symbol: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT", ContractType: "PERPETUAL", Status: "TRADING", DeliveryDate: delivery},
+		{Symbol: "ETHUSDT", BaseAsset: "ETH", QuoteAsset: "USDT", ContractType: "PERPETUAL", Status: "TRADING", DeliveryDate: farFuture},
+		{Symbol: "1000SHIBUSDT", BaseAsset: "1000SHIB", QuoteAsset: "USDT", ContractType: "PERPETUAL", Status: "TRADING", DeliveryDate: farFuture},
+	}
+	if _, err := r.Refresh(context.Background(), ex); err != nil {
+		t.Fatalf("刷新失败: %v", err)
+	}
+	if len(notified) != 2 {
+		t.Fatalf("应检测到 2 个变更, 得到 %d", len(notified))
+	}
+
+	btc, ok := r.PendingChange("testex", "BTCUSDT")
+	if !ok || btc.Kind != SymbolChangeDelistScheduled || !btc.DeliveryDate.Equal(delivery) {
+		t.Errorf("BTCUSDT 应为下架公告: %+v", btc)
+	}
+	shib, ok := r.PendingChange("TestEx", "SHIBUSDT")
+	if !ok || shib.Kind != SymbolChangeRemoved || shib.RenamedTo != "1000SHIBUSDT" {
+		t.Errorf("SHIBUSDT 应识别为改名: %+v", shib)
+	}
+	if meta, _ := r.Lookup("TestEx", "SHIBUSDT"); meta.Status != SymbolStatusDelisted {
+		t.Errorf("移除的交易对应标记为已下架: %s", meta.Status)
+	}
+
+	// 同样的数据再次刷新不应重复通知
+	r.Refresh(context.Background(), ex)
+	if len(notified) != 2 {
+		t.Errorf("重复刷新不应产生新变更, 得到 %d", len(notified))
+	}
+
+	if !r.ResolveChange("TestEx", "SHIBUSDT") || len(r.PendingChanges()) != 1 {
+		t.Errorf("处理后应从待处理列表移除: %v", r.PendingChanges())
+	}
+}
+
+func TestSymbolRegistryDetectsFilterChange(t *testing.T) {
+	r := NewSymbolRegistry()
+	ex := &metadataExchange{symbols: []*SymbolMetadata{
+		{Symbol: "BTCUSDT", Status: SymbolStatusTrading, MinNotional: 5, StepSize: 0.001},
+		{Symbol: "ETHUSDT", Status: SymbolStatusTrading, MinNotional: 5},
+	}}
+	var changes []*SymbolFilterChange
+	r.OnFilterChange(func(c *SymbolFilterChange) { changes = append(changes, c) })
+
+	if _, err := r.Refresh(context.Background(), ex); err != nil {
+		t.Fatalf("刷新失败: %v", err)
+	}
+	// 未变化时不通知；交易所未返回的字段（为 0）不视为变化
+	ex.symbols[0].StepSize = 0
+	r.Refresh(context.Background(), ex)
+	if len(changes) != 0 {
+		t.Fatalf("规则未变化时不应通知: %+v", changes)
+	}
+
+	ex.symbols[1].MinNotional = 20
+	r.Refresh(context.Background(), ex)
+	if len(changes) != 1 || changes[0].Symbol != "ETHUSDT" || !changes[0].MinNotionalRaised() ||
+		changes[0].OldMinNotional != 5 || changes[0].NewMinNotional != 20 {
+		t.Fatalf("应检测到 ETHUSDT 最小名义价值上调: %+v", changes)
+	}
+}
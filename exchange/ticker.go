@@ -0,0 +1,16 @@
+package exchange
+
+import "context"
+
+// Ticker24h 24 小时行情统计
+type Ticker24h struct {
+	Symbol      string
+	LastPrice   float64
+	Volume      float64 // 基础币成交量
+	QuoteVolume float64 // 计价货币成交额
+}
+
+// TickerStatsProvider 全市场 24 小时行情查询接口（可选能力，通过类型断言检测）
+type TickerStatsProvider interface {
+	GetTickers24h(ctx context.Context) ([]*Ticker24h, error)
+}
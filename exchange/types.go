@@ -74,14 +74,15 @@ type Order struct {
 
 // Position 持仓信息（通用）
 type Position struct {
-	Symbol         string
-	Size           float64 // 正数表示多仓，负数表示空仓
-	EntryPrice     float64
-	MarkPrice      float64
-	UnrealizedPNL  float64
-	Leverage       int
-	MarginType     string
-	IsolatedMargin float64
+	Symbol           string
+	Size             float64 // 正数表示多仓，负数表示空仓
+	EntryPrice       float64
+	MarkPrice        float64
+	UnrealizedPNL    float64
+	Leverage         int
+	MarginType       string
+	IsolatedMargin   float64
+	LiquidationPrice float64 // 强平价（0 表示交易所未提供或无持仓）
 }
 
 // Account 账户信息（通用）
@@ -108,6 +109,31 @@ type OrderUpdate struct {
 	UpdateTime    int64
 }
 
+// OrderStreamReconnect 订单流断线后重新连上的通知，通过 StartOrderStream 的回调与 OrderUpdate 一起传递
+// 断线期间的推送已经丢失，收到后应通过 REST 补查本地仍认为有效的订单
+type OrderStreamReconnect struct {
+	Exchange       string
+	DisconnectedAt time.Time
+	ReconnectedAt  time.Time
+	LastEventTime  time.Time // 断线前最后一次收到推送的时间（未知时为零值）
+}
+
+// 用户数据流告警类型
+const (
+	UserStreamAlertKeepAliveFailed  = "keepalive_failed"
+	UserStreamAlertListenKeyExpired = "listen_key_expired"
+	UserStreamAlertListenKeyRenewed = "listen_key_renewed"
+	UserStreamAlertRenewFailed      = "listen_key_renew_failed"
+)
+
+// UserStreamAlert 用户数据流健康告警（listenKey 保活失败、过期、更换等），通过 StartOrderStream 的回调传递
+type UserStreamAlert struct {
+	Exchange            string
+	Kind                string // 见 UserStreamAlert* 常量
+	Message             string
+	ConsecutiveFailures int
+}
+
 // OrderUpdateCallback 订单更新回调函数
 type OrderUpdateCallback func(update OrderUpdate)
 
@@ -0,0 +1,16 @@
+package exchange
+
+import "context"
+
+// 合约账户划出的目标账户
+const (
+	WalletSpot    = "SPOT"    // 现货账户
+	WalletFunding = "FUNDING" // 资金账户
+)
+
+// WalletTransferer 账户间划转接口（可选能力，通过类型断言检测）
+// 需要 API 密钥开启划转权限（见 APIPermissions.CanTransfer）
+type WalletTransferer interface {
+	// TransferFromFutures 将合约账户的资产划转到 toWallet，返回交易所划转流水号
+	TransferFromFutures(ctx context.Context, asset string, amount float64, toWallet string) (string, error)
+}
@@ -2,6 +2,8 @@ package exchange
 
 import (
 	"context"
+	"time"
+
 	"quantmesh/exchange/binance"
 )
 
@@ -206,14 +208,15 @@ func (w *binanceWrapper) GetPositions(ctx context.Context, symbol string) ([]*Po
 	positions := make([]*Position, len(binancePositions))
 	for i, pos := range binancePositions {
 		positions[i] = &Position{
-			Symbol:         pos.Symbol,
-			Size:           pos.Size,
-			EntryPrice:     pos.EntryPrice,
-			MarkPrice:      pos.MarkPrice,
-			UnrealizedPNL:  pos.UnrealizedPNL,
-			Leverage:       pos.Leverage,
-			MarginType:     pos.MarginType,
-			IsolatedMargin: pos.IsolatedMargin,
+			Symbol:           pos.Symbol,
+			Size:             pos.Size,
+			EntryPrice:       pos.EntryPrice,
+			MarkPrice:        pos.MarkPrice,
+			UnrealizedPNL:    pos.UnrealizedPNL,
+			Leverage:         pos.Leverage,
+			MarginType:       pos.MarginType,
+			IsolatedMargin:   pos.IsolatedMargin,
+			LiquidationPrice: pos.LiquidationPrice,
 		}
 	}
 
@@ -225,7 +228,27 @@ func (w *binanceWrapper) GetBalance(ctx context.Context, asset string) (float64,
 }
 
 func (w *binanceWrapper) StartOrderStream(ctx context.Context, callback func(interface{})) error {
-	return w.adapter.StartOrderStream(ctx, callback)
+	return w.adapter.StartOrderStream(ctx, func(data interface{}) {
+		if r, ok := data.(binance.StreamReconnect); ok {
+			callback(OrderStreamReconnect{
+				Exchange:       w.GetName(),
+				DisconnectedAt: r.DisconnectedAt,
+				ReconnectedAt:  r.ReconnectedAt,
+				LastEventTime:  r.LastEventTime,
+			})
+			return
+		}
+		if a, ok := data.(binance.StreamAlert); ok {
+			callback(UserStreamAlert{
+				Exchange:            w.GetName(),
+				Kind:                a.Kind,
+				Message:             a.Message,
+				ConsecutiveFailures: a.ConsecutiveFailures,
+			})
+			return
+		}
+		callback(data)
+	})
 }
 
 func (w *binanceWrapper) StopOrderStream() error {
@@ -303,6 +326,168 @@ func (w *binanceWrapper) GetFundingRate(ctx context.Context, symbol string) (flo
 	return w.adapter.GetFundingRate(ctx, symbol)
 }
 
+// GetIncomeHistory 查询账户流水
+func (w *binanceWrapper) GetIncomeHistory(ctx context.Context, symbol, incomeType string, startTime, endTime time.Time) ([]*IncomeRecord, error) {
+	binanceRecords, err := w.adapter.GetIncomeHistory(ctx, symbol, incomeType, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*IncomeRecord, len(binanceRecords))
+	for i, r := range binanceRecords {
+		records[i] = &IncomeRecord{
+			Symbol:     r.Symbol,
+			IncomeType: r.IncomeType,
+			Asset:      r.Asset,
+			Amount:     r.Amount,
+			TranID:     r.TranID,
+			Time:       r.Time,
+		}
+	}
+	return records, nil
+}
+
+// GetTickers24h 查询全部合约的 24 小时行情统计
+func (w *binanceWrapper) GetTickers24h(ctx context.Context) ([]*Ticker24h, error) {
+	binanceTickers, err := w.adapter.GetTickers24h(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tickers := make([]*Ticker24h, len(binanceTickers))
+	for i, t := range binanceTickers {
+		tickers[i] = &Ticker24h{
+			Symbol:      t.Symbol,
+			LastPrice:   t.LastPrice,
+			Volume:      t.Volume,
+			QuoteVolume: t.QuoteVolume,
+		}
+	}
+	return tickers, nil
+}
+
+// TransferFromFutures 合约账户划转到现货/资金账户
+func (w *binanceWrapper) TransferFromFutures(ctx context.Context, asset string, amount float64, toWallet string) (string, error) {
+	return w.adapter.TransferFromFutures(ctx, asset, amount, toWallet)
+}
+
+// GetCommissionRate 查询账户实际手续费率
+func (w *binanceWrapper) GetCommissionRate(ctx context.Context, symbol string) (*CommissionRate, error) {
+	rate, err := w.adapter.GetCommissionRate(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &CommissionRate{
+		Symbol:       rate.Symbol,
+		MakerRate:    rate.MakerRate,
+		TakerRate:    rate.TakerRate,
+		FeeDiscount:  rate.FeeDiscount,
+		DiscountRate: rate.DiscountRate,
+		UpdatedAt:    rate.UpdatedAt,
+	}, nil
+}
+
+// GetOpenInterestStat 查询持仓量与大户多空比
+func (w *binanceWrapper) GetOpenInterestStat(ctx context.Context, symbol string) (*OpenInterestStat, error) {
+	stat, err := w.adapter.GetOpenInterestStat(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &OpenInterestStat{
+		Symbol:            stat.Symbol,
+		OpenInterest:      stat.OpenInterest,
+		OpenInterestValue: stat.OpenInterestValue,
+		LongShortRatio:    stat.LongShortRatio,
+		LongPercent:       stat.LongPercent,
+		ShortPercent:      stat.ShortPercent,
+		Time:              stat.Time,
+	}, nil
+}
+
+// GetServerTime 查询服务器时间
+func (w *binanceWrapper) GetServerTime(ctx context.Context) (time.Time, error) {
+	return w.adapter.GetServerTime(ctx)
+}
+
+// CheckAPIPermissions 检查 API 密钥权限
+func (w *binanceWrapper) CheckAPIPermissions(ctx context.Context) (*APIPermissions, error) {
+	perms, err := w.adapter.CheckAPIPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &APIPermissions{
+		CanTrade:      perms.CanTrade,
+		CanWithdraw:   perms.CanWithdraw,
+		CanTransfer:   perms.CanTransfer,
+		CanRead:       perms.CanRead,
+		IPRestricted:  perms.IPRestricted,
+		AllowedIPs:    perms.AllowedIPs,
+		APIKeyName:    perms.APIKeyName,
+		CreateTime:    perms.CreateTime,
+		ExpireTime:    perms.ExpireTime,
+		SecurityScore: perms.SecurityScore,
+		RiskLevel:     perms.RiskLevel,
+	}, nil
+}
+
+// GetSymbolsMetadata 查询全部合约的元数据
+func (w *binanceWrapper) GetSymbolsMetadata(ctx context.Context) ([]*SymbolMetadata, error) {
+	symbols, err := w.adapter.ListSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*SymbolMetadata, 0, len(symbols))
+	for _, s := range symbols {
+		result = append(result, &SymbolMetadata{
+			Exchange:         w.GetName(),
+			Symbol:           s.Symbol,
+			BaseAsset:        s.BaseAsset,
+			QuoteAsset:       s.QuoteAsset,
+			ContractType:     s.ContractType,
+			Status:           s.Status,
+			PriceDecimals:    s.PriceDecimals,
+			QuantityDecimals: s.QuantityDecimals,
+			TickSize:         s.TickSize,
+			StepSize:         s.StepSize,
+			MinQty:           s.MinQty,
+			MinNotional:      s.MinNotional,
+			OnboardDate:      s.OnboardDate,
+			DeliveryDate:     s.DeliveryDate,
+		})
+	}
+	return result, nil
+}
+
+// StartDepthStream 启动盘口快照流
+func (w *binanceWrapper) StartDepthStream(ctx context.Context, symbol string, callback func(*DepthUpdate)) error {
+	return w.adapter.StartDepthStream(ctx, symbol, func(d *binance.DepthSnapshot) {
+		update := &DepthUpdate{
+			Symbol:    d.Symbol,
+			Bids:      make([]BookLevel, len(d.Bids)),
+			Asks:      make([]BookLevel, len(d.Asks)),
+			Timestamp: d.Timestamp,
+		}
+		for i, l := range d.Bids {
+			update.Bids[i] = BookLevel{Price: l.Price, Qty: l.Qty}
+		}
+		for i, l := range d.Asks {
+			update.Asks[i] = BookLevel{Price: l.Price, Qty: l.Qty}
+		}
+		callback(update)
+	})
+}
+
+// StartTradeStream 启动逐笔成交流
+func (w *binanceWrapper) StartTradeStream(ctx context.Context, symbol string, callback func(*TradePrint)) error {
+	return w.adapter.StartTradeStream(ctx, symbol, func(t *binance.AggTrade) {
+		callback(&TradePrint{
+			Symbol:     t.Symbol,
+			Price:      t.Price,
+			Qty:        t.Qty,
+			BuyerMaker: t.BuyerMaker,
+			Timestamp:  t.Timestamp,
+		})
+	})
+}
+
 // GetSpotPrice 获取现货市场价格
 func (w *binanceWrapper) GetSpotPrice(ctx context.Context, symbol string) (float64, error) {
 	return w.adapter.GetSpotPrice(ctx, symbol)
@@ -0,0 +1,109 @@
+// Package wsutil 交易所 WebSocket 行情连接的公共工具：压缩协商与二进制帧解码。
+// 交易对数量较多时带宽和 JSON 解析是小内存 VPS 上的主要瓶颈，
+// 开启 permessage-deflate 可显著降低行情流量；部分交易所（如 Huobi）原生推送 gzip 压缩的二进制帧。
+package wsutil
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var compression atomic.Bool
+
+// SetCompression 设置新建连接是否协商 permessage-deflate 压缩（已建立的连接不受影响）
+func SetCompression(enabled bool) {
+	compression.Store(enabled)
+}
+
+// CompressionEnabled 是否协商 permessage-deflate 压缩
+func CompressionEnabled() bool {
+	return compression.Load()
+}
+
+// Dialer 返回行情连接使用的拨号器；开启压缩时向服务端请求 permessage-deflate，
+// 服务端不支持时自动回退为不压缩
+func Dialer() *websocket.Dialer {
+	return &websocket.Dialer{
+		Proxy:             websocket.DefaultDialer.Proxy,
+		HandshakeTimeout:  45 * time.Second,
+		EnableCompression: compression.Load(),
+	}
+}
+
+var (
+	gzipReaders  sync.Pool
+	flateReaders sync.Pool
+	buffers      = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+)
+
+// Decode 解码一帧消息：文本帧原样返回；二进制帧按 gzip（魔数 1f 8b）或 raw deflate 解压。
+// 解压使用池化的 reader，避免高频行情下每条消息分配新的解压器。
+func Decode(messageType int, data []byte) ([]byte, error) {
+	if messageType != websocket.BinaryMessage {
+		return data, nil
+	}
+	if IsGzip(data) {
+		return Gunzip(data)
+	}
+	return Inflate(data)
+}
+
+// IsGzip 判断数据是否为 gzip 格式
+func IsGzip(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+// Gunzip 解压 gzip 数据
+func Gunzip(data []byte) ([]byte, error) {
+	var zr *gzip.Reader
+	if v := gzipReaders.Get(); v != nil {
+		zr = v.(*gzip.Reader)
+		if err := zr.Reset(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("gzip 解压失败: %w", err)
+		}
+	} else {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("gzip 解压失败: %w", err)
+		}
+		zr = r
+	}
+	defer gzipReaders.Put(zr)
+	return readAll(zr, len(data))
+}
+
+// Inflate 解压 raw deflate 数据（如 OKX 旧版接口）
+func Inflate(data []byte) ([]byte, error) {
+	var fr io.ReadCloser
+	if v := flateReaders.Get(); v != nil {
+		fr = v.(io.ReadCloser)
+		if err := fr.(flate.Resetter).Reset(bytes.NewReader(data), nil); err != nil {
+			return nil, fmt.Errorf("deflate 解压失败: %w", err)
+		}
+	} else {
+		fr = flate.NewReader(bytes.NewReader(data))
+	}
+	defer flateReaders.Put(fr)
+	return readAll(fr, len(data))
+}
+
+// readAll 读取全部解压数据，返回的切片由调用方持有
+func readAll(r io.Reader, compressedLen int) ([]byte, error) {
+	buf := buffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer buffers.Put(buf)
+
+	buf.Grow(compressedLen * 4)
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, fmt.Errorf("解压失败: %w", err)
+	}
+	return bytes.Clone(buf.Bytes()), nil
+}
@@ -0,0 +1,162 @@
+package wsutil
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+const aggTradeJSON = `{"stream":"btcusdt@aggTrade","data":{"e":"aggTrade","E":1700000000123,"s":"BTCUSDT","a":2034567890,"p":"43210.50","q":"0.012","f":4012345678,"l":4012345680,"T":1700000000120,"m":true}}`
+
+func gzipBytes(t testing.TB, data []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+func deflateBytes(t testing.TB, data []byte) []byte {
+	var buf bytes.Buffer
+	fw, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+	if _, err := fw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	fw.Close()
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	payload := []byte(aggTradeJSON)
+	cases := []struct {
+		name        string
+		messageType int
+		data        []byte
+	}{
+		{"text", websocket.TextMessage, payload},
+		{"gzip", websocket.BinaryMessage, gzipBytes(t, payload)},
+		{"deflate", websocket.BinaryMessage, deflateBytes(t, payload)},
+	}
+	for _, tc := range cases {
+		for i := 0; i < 2; i++ { // 第二次走池化的 reader
+			got, err := Decode(tc.messageType, tc.data)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			if !bytes.Equal(got, payload) {
+				t.Errorf("%s: got %q", tc.name, got)
+			}
+		}
+	}
+	if _, err := Decode(websocket.BinaryMessage, []byte{0x1f, 0x8b, 0x00}); err == nil {
+		t.Error("损坏的 gzip 数据应返回错误")
+	}
+}
+
+func TestDialerNegotiatesCompression(t *testing.T) {
+	upgrader := websocket.Upgrader{EnableCompression: true}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, []byte(aggTradeJSON))
+	}))
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	defer SetCompression(false)
+	for _, enabled := range []bool{false, true} {
+		SetCompression(enabled)
+		conn, resp, err := Dialer().Dial(url, nil)
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		negotiated := strings.Contains(resp.Header.Get("Sec-Websocket-Extensions"), "permessage-deflate")
+		if negotiated != enabled {
+			t.Errorf("compression=%v: negotiated=%v", enabled, negotiated)
+		}
+		_, msg, err := conn.ReadMessage()
+		if err != nil || string(msg) != aggTradeJSON {
+			t.Errorf("compression=%v: read %q, %v", enabled, msg, err)
+		}
+		conn.Close()
+	}
+}
+
+// 以下基准用于评估行情流在高交易对数量下的解压与解析开销：
+//   go test ./exchange/wsutil -bench . -benchmem
+
+func BenchmarkDecodeText(b *testing.B) {
+	payload := []byte(aggTradeJSON)
+	b.SetBytes(int64(len(payload)))
+	for i := 0; i < b.N; i++ {
+		Decode(websocket.TextMessage, payload)
+	}
+}
+
+func BenchmarkDecodeGzip(b *testing.B) {
+	data := gzipBytes(b, []byte(aggTradeJSON))
+	b.SetBytes(int64(len(aggTradeJSON)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Decode(websocket.BinaryMessage, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeDeflate(b *testing.B) {
+	data := deflateBytes(b, []byte(aggTradeJSON))
+	b.SetBytes(int64(len(aggTradeJSON)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Decode(websocket.BinaryMessage, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkJSONDecodeMap 通用 map 解析（多数交易所适配器的写法）
+func BenchmarkJSONDecodeMap(b *testing.B) {
+	payload := []byte(aggTradeJSON)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var msg map[string]interface{}
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkJSONDecodeEnvelope 先按 stream 路由、只解析所需字段（组合流的写法）
+func BenchmarkJSONDecodeEnvelope(b *testing.B) {
+	payload := []byte(aggTradeJSON)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var env struct {
+			Stream string          `json:"stream"`
+			Data   json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(payload, &env); err != nil {
+			b.Fatal(err)
+		}
+		var trade struct {
+			Price string `json:"p"`
+			Qty   string `json:"q"`
+		}
+		if err := json.Unmarshal(env.Data, &trade); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
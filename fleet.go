@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+	"quantmesh/storage"
+	"quantmesh/utils"
+	"quantmesh/web"
+)
+
+// fleetReporter 多实例总览：生成本实例的状态报告，member 角色定期推送给 hub
+type fleetReporter struct {
+	cfg       *config.Config
+	name      string
+	mode      string
+	manager   *SymbolManager
+	store     storage.Storage // 未启用存储时为 nil，报告中不含盈亏
+	startedAt time.Time
+	client    *http.Client
+	failing   bool
+}
+
+func newFleetReporter(cfg *config.Config, manager *SymbolManager, store storage.Storage, mode string) *fleetReporter {
+	name := cfg.Fleet.Name
+	if name == "" {
+		name = cfg.Instance.ID
+	}
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	return &fleetReporter{
+		cfg:       cfg,
+		name:      name,
+		mode:      mode,
+		manager:   manager,
+		store:     store,
+		startedAt: time.Now(),
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// LocalReport 本实例各交易对的状态与盈亏
+func (r *fleetReporter) LocalReport() web.FleetReport {
+	report := web.FleetReport{
+		Name:       r.name,
+		Version:    Version,
+		Mode:       r.mode,
+		StartedAt:  r.startedAt,
+		ReportedAt: time.Now(),
+		Symbols:    []web.FleetSymbolStatus{},
+	}
+	now := utils.NowConfiguredTimezone()
+	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	runtimes := r.manager.List()
+	sort.Slice(runtimes, func(i, j int) bool {
+		return runtimeKey(runtimes[i].Config.Exchange, runtimes[i].Config.Symbol) <
+			runtimeKey(runtimes[j].Config.Exchange, runtimes[j].Config.Symbol)
+	})
+	for _, rt := range runtimes {
+		if rt == nil || rt.SuperPositionManager == nil {
+			continue
+		}
+		qty, _, _ := rt.SuperPositionManager.GetCostBasis()
+		status := web.FleetSymbolStatus{
+			Exchange: rt.Config.Exchange,
+			Symbol:   rt.Config.Symbol,
+			Paused:   rt.SuperPositionManager.IsPaused(),
+			Position: qty,
+		}
+		if rt.PriceMonitor != nil {
+			status.CurrentPrice = rt.PriceMonitor.GetLastPrice()
+		}
+		if r.store != nil {
+			// 存储中交易所为小写、交易对为大写
+			exchangeName, symbol := strings.ToLower(rt.Config.Exchange), strings.ToUpper(rt.Config.Symbol)
+			if summary, err := r.store.GetStatisticsSummaryBySymbol(exchangeName, symbol); err == nil && summary != nil {
+				status.TotalPnL = summary.TotalPnL
+				status.TotalTrades = summary.TotalTrades
+			}
+			if daily, err := r.store.QueryDailyStatisticsBySymbol(exchangeName, symbol, todayStart, now); err == nil {
+				for _, d := range daily {
+					report.TodayPnL += d.TotalPnL
+				}
+			}
+		}
+		report.TotalPnL += status.TotalPnL
+		report.TotalTrades += status.TotalTrades
+		report.Symbols = append(report.Symbols, status)
+	}
+	return report
+}
+
+// Start member 角色：立即上报一次，之后按 report_interval 定期上报
+func (r *fleetReporter) Start(ctx context.Context) {
+	utils.GoSupervised(ctx, "fleet-reporter", func(ctx context.Context) {
+		ticker := time.NewTicker(time.Duration(r.cfg.Fleet.ReportInterval) * time.Second)
+		defer ticker.Stop()
+		for {
+			r.report(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// report 推送一次报告，只在失败/恢复时记录日志
+func (r *fleetReporter) report(ctx context.Context) {
+	err := r.push(ctx, r.LocalReport())
+	if err != nil && !r.failing {
+		logger.Warn("⚠️ [实例总览] 上报到 %s 失败: %v", r.cfg.Fleet.HubURL, err)
+	} else if err == nil && r.failing {
+		logger.Info("✅ [实例总览] 已恢复上报到 %s", r.cfg.Fleet.HubURL)
+	}
+	r.failing = err != nil
+}
+
+func (r *fleetReporter) push(ctx context.Context, report web.FleetReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.cfg.Fleet.HubURL+"/api/fleet/report", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+r.cfg.Fleet.Token)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/exchange"
+	"quantmesh/logger"
+	"quantmesh/monitor"
+	"quantmesh/storage"
+)
+
+// resolveGridAnchor 确定冷启动时的网格锚点
+// 1. 优先沿用已保存的锚点（启用持久化时的任意锚点，或通过 API 手动设置的锚点），偏离当前价格超过 max_deviation 时重新定位
+// 2. 否则按配置的模式计算：vwap / candle_close / manual，失败时回退到首个推送价格
+// 启用持久化时保存最终使用的锚点
+func resolveGridAnchor(ctx context.Context, cfg config.AnchorConfig, ex exchange.IExchange, priceMonitor *monitor.PriceMonitor,
+	st storage.Storage, symbol string, firstTick float64) float64 {
+	exName := ex.GetName()
+
+	if st != nil {
+		saved, err := st.GetGridAnchor(exName, symbol)
+		if err != nil {
+			logger.Warn("⚠️ [%s] 读取已保存的网格锚点失败: %v", symbol, err)
+		} else if saved != nil && (cfg.Persist || saved.Source == "manual") {
+			deviation := math.Abs(saved.Price-firstTick) / firstTick
+			if deviation <= cfg.MaxDeviation {
+				logger.Info("⚓ [%s] 沿用已保存的网格锚点: %.8g (来源: %s, 保存于 %s, 偏离当前价格 %.2f%%)",
+					symbol, saved.Price, saved.Source, saved.UpdatedAt.Format(time.RFC3339), deviation*100)
+				return saved.Price
+			}
+			logger.Warn("⚠️ [%s] 已保存的网格锚点 %.8g 偏离当前价格 %.2f%%（上限 %.0f%%），重新定位网格",
+				symbol, saved.Price, deviation*100, cfg.MaxDeviation*100)
+		}
+	}
+
+	anchor, source := firstTick, "first_tick"
+	var err error
+	switch cfg.Mode {
+	case "vwap":
+		anchor, err = anchorVWAP(ctx, ex, priceMonitor, symbol, time.Duration(cfg.VWAPSeconds)*time.Second)
+		source = "vwap"
+	case "candle_close":
+		anchor, err = anchorCandleClose(ctx, ex, symbol)
+		source = "candle_close"
+	case "manual":
+		if cfg.Price <= 0 {
+			err = fmt.Errorf("未通过 API 或 trading.anchor.price 设置锚点价格")
+		}
+		anchor, source = cfg.Price, "manual"
+	}
+	if err != nil || anchor <= 0 {
+		logger.Warn("⚠️ [%s] %s 锚点计算失败，使用首个推送价格 %.8g: %v", symbol, cfg.Mode, firstTick, err)
+		anchor, source = firstTick, "first_tick"
+	} else {
+		logger.Info("⚓ [%s] 网格锚点: %.8g (模式: %s, 首个推送价格: %.8g)", symbol, anchor, source, firstTick)
+	}
+
+	if st != nil && cfg.Persist {
+		if err := st.SaveGridAnchor(&storage.GridAnchor{Exchange: exName, Symbol: symbol, Price: anchor, Source: source}); err != nil {
+			logger.Warn("⚠️ [%s] 保存网格锚点失败: %v", symbol, err)
+		}
+	}
+	return anchor
+}
+
+// anchorVWAP 统计一段时间内逐笔成交的成交量加权均价
+// 交易所不支持逐笔成交流时，改为对同一时段的推送价格做时间加权平均
+func anchorVWAP(ctx context.Context, ex exchange.IExchange, priceMonitor *monitor.PriceMonitor, symbol string, window time.Duration) (float64, error) {
+	logger.Info("⏳ [%s] 统计 %v 内的成交均价作为网格锚点...", symbol, window)
+	streamCtx, cancel := context.WithTimeout(ctx, window)
+	defer cancel()
+
+	var mu sync.Mutex
+	var notional, volume float64
+	tradeStream := false
+	if streamer, ok := exchange.As[exchange.MarketDepthStreamer](ex); ok {
+		err := streamer.StartTradeStream(streamCtx, symbol, func(t *exchange.TradePrint) {
+			mu.Lock()
+			notional += t.Price * t.Qty
+			volume += t.Qty
+			mu.Unlock()
+		})
+		tradeStream = err == nil
+	}
+
+	var priceSum float64
+	var samples int
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+collect:
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-streamCtx.Done():
+			break collect
+		case <-ticker.C:
+			if price := priceMonitor.GetLastPrice(); price > 0 {
+				priceSum += price
+				samples++
+			}
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if tradeStream && volume > 0 {
+		return notional / volume, nil
+	}
+	if samples == 0 {
+		return 0, fmt.Errorf("%v 内没有收到价格", window)
+	}
+	logger.Info("ℹ️ [%s] 没有逐笔成交数据，使用 %d 个价格样本的均价", symbol, samples)
+	return priceSum / float64(samples), nil
+}
+
+// anchorCandleClose 最近一根已收盘 1m K线的收盘价
+func anchorCandleClose(ctx context.Context, ex exchange.IExchange, symbol string) (float64, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	klines, err := ex.GetHistoricalKlines(reqCtx, symbol, "1m", 2)
+	if err != nil {
+		return 0, err
+	}
+	for i := len(klines) - 1; i >= 0; i-- {
+		if klines[i].IsClosed || i < len(klines)-1 {
+			return klines[i].Close, nil
+		}
+	}
+	return 0, fmt.Errorf("没有已收盘的K线")
+}
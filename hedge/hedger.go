@@ -0,0 +1,226 @@
+package hedge
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/exchange"
+	"quantmesh/logger"
+	"quantmesh/utils"
+)
+
+// InventorySource 网格库存来源（避免循环导入，由 SuperPositionManager 实现）
+type InventorySource interface {
+	GetCostBasis() (quantity, totalCost, avgEntryPrice float64)
+}
+
+// Status 对冲状态快照
+type Status struct {
+	Exchange      string    `json:"exchange"` // 网格所在交易所
+	GridSymbol    string    `json:"grid_symbol"`
+	HedgeExchange string    `json:"hedge_exchange"`
+	HedgeSymbol   string    `json:"hedge_symbol"`
+	Ratio         float64   `json:"ratio"`
+	GridQty       float64   `json:"grid_qty"`    // 网格多头库存数量
+	GridPrice     float64   `json:"grid_price"`  // 网格交易对最新价格
+	HedgePrice    float64   `json:"hedge_price"` // 对冲合约最新价格
+	TargetQty     float64   `json:"target_qty"`  // 目标对冲仓位（负数为空仓）
+	HedgeQty      float64   `json:"hedge_qty"`   // 当前对冲仓位
+	LastRebalance time.Time `json:"last_rebalance"`
+	LastError     string    `json:"last_error,omitempty"`
+}
+
+// Hedger 库存对冲器
+// 网格在单边下跌时持续累积多头库存，对冲器按固定周期在另一账户（或另一交易所）上
+// 做空同一交易对或相关合约，使对冲仓位的名义价值保持在库存的 ratio 倍，
+// 网格本身照常挂单赚取价差，只是方向性敞口被限制住
+type Hedger struct {
+	cfg        config.HedgeConfig
+	gridSymbol string
+	gridEx     exchange.IExchange
+	hedgeEx    exchange.IExchange
+	inventory  InventorySource
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// NewHedger 创建库存对冲器，按配置创建对冲账户的交易所实例
+// 对冲与网格在同一账户的同一交易对上会直接抵消网格持仓，这种配置会被拒绝
+func NewHedger(cfg *config.Config, gridEx exchange.IExchange, gridSymbol string, inventory InventorySource) (*Hedger, error) {
+	hedgeCfg := cfg.Trading.Hedge
+	exchangeName := hedgeCfg.Exchange
+	if exchangeName == "" {
+		exchangeName = cfg.App.CurrentExchange
+	}
+	hedgeSymbol := hedgeCfg.Symbols[gridSymbol]
+	if hedgeSymbol == "" {
+		hedgeSymbol = gridSymbol
+	}
+	hedgeSymbol = strings.ToUpper(hedgeSymbol)
+
+	separateAccount := hedgeCfg.Account.APIKey != ""
+	if exchangeName == cfg.App.CurrentExchange && hedgeSymbol == gridSymbol && !separateAccount {
+		return nil, fmt.Errorf("在网格账户的同一交易对上对冲会直接抵消网格持仓，请配置 trading.hedge.account 或其它对冲合约")
+	}
+
+	// 使用对冲账户的密钥创建交易所实例（不修改全局配置）
+	exCfg := *cfg
+	exCfg.Exchanges = make(map[string]config.ExchangeConfig, len(cfg.Exchanges)+1)
+	for name, ec := range cfg.Exchanges {
+		exCfg.Exchanges[name] = ec
+	}
+	if separateAccount {
+		exCfg.Exchanges[exchangeName] = hedgeCfg.Account
+	}
+	hedgeEx, err := exchange.NewExchange(&exCfg, exchangeName, hedgeSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("创建对冲交易所实例失败: %w", err)
+	}
+
+	return &Hedger{
+		cfg:        hedgeCfg,
+		gridSymbol: gridSymbol,
+		gridEx:     gridEx,
+		hedgeEx:    hedgeEx,
+		inventory:  inventory,
+		status: Status{
+			Exchange:      cfg.App.CurrentExchange,
+			GridSymbol:    gridSymbol,
+			HedgeExchange: exchangeName,
+			HedgeSymbol:   hedgeSymbol,
+			Ratio:         hedgeCfg.Ratio,
+		},
+	}, nil
+}
+
+// Start 启动定期再平衡（启动后立即执行一次）
+func (h *Hedger) Start(ctx context.Context) {
+	interval := time.Duration(h.cfg.RebalanceInterval) * time.Second
+	logger.Info("🛡️ [%s] 库存对冲已启动: %s %s, 对冲比例 %.0f%%, 再平衡间隔 %v",
+		h.gridSymbol, h.status.HedgeExchange, h.status.HedgeSymbol, h.cfg.Ratio*100, interval)
+
+	utils.GoSupervised(ctx, "hedger:"+h.gridSymbol, func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			h.rebalance(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Status 获取对冲状态
+func (h *Hedger) Status() Status {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.status
+}
+
+// rebalance 将对冲仓位调整到目标数量
+func (h *Hedger) rebalance(ctx context.Context) {
+	err := h.doRebalance(ctx)
+	h.mu.Lock()
+	h.status.LastRebalance = time.Now()
+	h.status.LastError = ""
+	if err != nil {
+		h.status.LastError = err.Error()
+	}
+	h.mu.Unlock()
+	if err != nil {
+		logger.Warn("⚠️ [%s] 库存对冲再平衡失败: %v", h.gridSymbol, err)
+	}
+}
+
+func (h *Hedger) doRebalance(ctx context.Context) error {
+	reqCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	gridQty, _, _ := h.inventory.GetCostBasis()
+	gridPrice, err := h.gridEx.GetLatestPrice(reqCtx, h.gridSymbol)
+	if err != nil {
+		return fmt.Errorf("获取 %s 价格失败: %w", h.gridSymbol, err)
+	}
+	hedgePrice := gridPrice
+	if h.status.HedgeSymbol != h.gridSymbol || h.status.HedgeExchange != h.gridEx.GetName() {
+		if hedgePrice, err = h.hedgeEx.GetLatestPrice(reqCtx, h.status.HedgeSymbol); err != nil {
+			return fmt.Errorf("获取对冲合约 %s 价格失败: %w", h.status.HedgeSymbol, err)
+		}
+	}
+	positions, err := h.hedgeEx.GetPositions(reqCtx, h.status.HedgeSymbol)
+	if err != nil {
+		return fmt.Errorf("获取对冲仓位失败: %w", err)
+	}
+	var hedgeQty float64
+	for _, p := range positions {
+		if p.Symbol == h.status.HedgeSymbol {
+			hedgeQty += p.Size
+		}
+	}
+
+	target := TargetHedgeQty(gridQty, gridPrice, hedgePrice, h.cfg.Ratio, h.cfg.MaxNotional)
+	h.mu.Lock()
+	h.status.GridQty = gridQty
+	h.status.GridPrice = gridPrice
+	h.status.HedgePrice = hedgePrice
+	h.status.TargetQty = target
+	h.status.HedgeQty = hedgeQty
+	h.mu.Unlock()
+
+	delta := truncateQty(target-hedgeQty, h.hedgeEx.GetQuantityDecimals())
+	if delta == 0 || math.Abs(delta)*hedgePrice < h.cfg.MinNotional {
+		return nil
+	}
+
+	req := &exchange.OrderRequest{
+		Symbol:       h.status.HedgeSymbol,
+		Side:         exchange.SideSell,
+		Type:         exchange.OrderTypeMarket,
+		Quantity:     math.Abs(delta),
+		StrategyName: "hedger",
+		StrategyType: "hedge",
+	}
+	if delta > 0 {
+		// 库存减少时回补空仓，只减仓避免反向开多
+		req.Side = exchange.SideBuy
+		req.ReduceOnly = hedgeQty < 0 && delta <= -hedgeQty
+	}
+	order, err := h.hedgeEx.PlaceOrder(reqCtx, req)
+	if err != nil {
+		return fmt.Errorf("对冲下单失败 (%s %.8f): %w", req.Side, req.Quantity, err)
+	}
+	logger.Info("🛡️ [%s] 库存对冲再平衡: 网格库存 %.6f @ %.4f, 对冲仓位 %.6f -> 目标 %.6f, %s %.6f %s (订单 %d)",
+		h.gridSymbol, gridQty, gridPrice, hedgeQty, target, req.Side, req.Quantity, h.status.HedgeSymbol, order.OrderID)
+	return nil
+}
+
+// TargetHedgeQty 计算目标对冲仓位（负数为空仓）
+// 按名义价值对冲：库存价值 × ratio ÷ 对冲合约价格，并受 maxNotional 限制（0 表示不限制）
+func TargetHedgeQty(gridQty, gridPrice, hedgePrice, ratio, maxNotional float64) float64 {
+	if gridQty <= 0 || gridPrice <= 0 || hedgePrice <= 0 || ratio <= 0 {
+		return 0
+	}
+	notional := gridQty * gridPrice * ratio
+	if maxNotional > 0 && notional > maxNotional {
+		notional = maxNotional
+	}
+	return -notional / hedgePrice
+}
+
+// truncateQty 按数量精度向零截断
+func truncateQty(qty float64, decimals int) float64 {
+	if decimals < 0 {
+		return qty
+	}
+	factor := math.Pow10(decimals)
+	return math.Trunc(qty*factor) / factor
+}
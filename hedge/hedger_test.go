@@ -0,0 +1,184 @@
+package hedge
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"quantmesh/config"
+	"quantmesh/exchange"
+	"quantmesh/testutil"
+)
+
+// stubInventory 固定数量的网格库存
+type stubInventory struct {
+	qty float64
+}
+
+func (s *stubInventory) GetCostBasis() (quantity, totalCost, avgEntryPrice float64) {
+	return s.qty, 0, 0
+}
+
+func newTestHedger(cfg config.HedgeConfig, inventory InventorySource) (*Hedger, *testutil.FakeVenue, *testutil.FakeVenue) {
+	gridEx := testutil.NewFakeVenue("grid", 2, 3)
+	hedgeEx := testutil.NewFakeVenue("hedge", 2, 3)
+	gridEx.SetPrice("BTCUSDT", 100)
+	hedgeEx.SetPrice("BTCUSDT", 101)
+	h := &Hedger{
+		cfg:        cfg,
+		gridSymbol: "BTCUSDT",
+		gridEx:     gridEx,
+		hedgeEx:    hedgeEx,
+		inventory:  inventory,
+		status: Status{
+			Exchange:      "grid",
+			GridSymbol:    "BTCUSDT",
+			HedgeExchange: hedgeEx.GetName(),
+			HedgeSymbol:   "BTCUSDT",
+			Ratio:         cfg.Ratio,
+		},
+	}
+	return h, gridEx, hedgeEx
+}
+
+func TestTargetHedgeQty(t *testing.T) {
+	tests := []struct {
+		name                                  string
+		gridQty, gridPrice, hedgePrice, ratio float64
+		maxNotional                           float64
+		want                                  float64
+	}{
+		{"no inventory", 0, 100, 100, 0.5, 0, 0},
+		{"short inventory is not hedged", -1, 100, 100, 0.5, 0, 0},
+		{"missing grid price", 1, 0, 100, 0.5, 0, 0},
+		{"missing hedge price", 1, 100, 0, 0.5, 0, 0},
+		{"zero ratio", 1, 100, 100, 0, 0, 0},
+		{"half hedge", 2, 100, 100, 0.5, 0, -1},
+		{"notional across prices", 2, 100, 50, 1, 0, -4},
+		{"capped by max notional", 10, 100, 100, 1, 250, -2.5},
+		{"below max notional", 1, 100, 100, 1, 250, -1},
+	}
+	for _, tt := range tests {
+		got := TargetHedgeQty(tt.gridQty, tt.gridPrice, tt.hedgePrice, tt.ratio, tt.maxNotional)
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: TargetHedgeQty = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateQty(t *testing.T) {
+	tests := []struct {
+		qty      float64
+		decimals int
+		want     float64
+	}{
+		{1.23456, 3, 1.234},
+		{-1.23456, 3, -1.234}, // 向零截断，不会放大回补数量
+		{0.0009, 3, 0},
+		{1.9, 0, 1},
+		{1.23456, -1, 1.23456},
+	}
+	for _, tt := range tests {
+		if got := truncateQty(tt.qty, tt.decimals); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("truncateQty(%v, %d) = %v, want %v", tt.qty, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestDoRebalance(t *testing.T) {
+	ctx := context.Background()
+	inventory := &stubInventory{qty: 2}
+	h, _, hedgeEx := newTestHedger(config.HedgeConfig{Ratio: 0.5, MinNotional: 20}, inventory)
+
+	// 库存 2 @ 100，对冲一半名义价值：100 / 101 = 0.990099，按 3 位精度截断后做空 0.99
+	if err := h.doRebalance(ctx); err != nil {
+		t.Fatal(err)
+	}
+	orders := hedgeEx.PlacedOrders()
+	if len(orders) != 1 || orders[0].Side != exchange.SideSell || orders[0].Type != exchange.OrderTypeMarket ||
+		orders[0].Quantity != 0.99 || orders[0].ReduceOnly {
+		t.Fatalf("opening hedge orders = %+v", orders)
+	}
+	if got := hedgeEx.Position("BTCUSDT"); got != -0.99 {
+		t.Fatalf("hedge position = %v, want -0.99", got)
+	}
+	st := h.Status()
+	if st.GridQty != 2 || st.GridPrice != 100 || st.HedgePrice != 101 || st.HedgeQty != 0 || math.Abs(st.TargetQty+0.990099) > 1e-6 {
+		t.Fatalf("status after opening = %+v", st)
+	}
+
+	// 已达到目标，不再下单
+	if err := h.doRebalance(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(hedgeEx.PlacedOrders()); n != 1 {
+		t.Fatalf("rebalance at target placed %d orders", n)
+	}
+
+	// 库存清空后只减仓回补空仓
+	inventory.qty = 0
+	if err := h.doRebalance(ctx); err != nil {
+		t.Fatal(err)
+	}
+	orders = hedgeEx.PlacedOrders()
+	if len(orders) != 2 || orders[1].Side != exchange.SideBuy || orders[1].Quantity != 0.99 || !orders[1].ReduceOnly {
+		t.Fatalf("closing hedge orders = %+v", orders)
+	}
+	if got := hedgeEx.Position("BTCUSDT"); math.Abs(got) > 1e-9 {
+		t.Fatalf("hedge position after close = %v", got)
+	}
+}
+
+func TestDoRebalanceSkipsBelowMinNotional(t *testing.T) {
+	h, _, hedgeEx := newTestHedger(config.HedgeConfig{Ratio: 0.5, MinNotional: 150}, &stubInventory{qty: 2})
+	if err := h.doRebalance(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(hedgeEx.PlacedOrders()); n != 0 {
+		t.Fatalf("placed %d orders for a 99.99 USDT delta below min_notional 150", n)
+	}
+	if st := h.Status(); st.TargetQty == 0 {
+		t.Fatal("status should be refreshed even when no order is placed")
+	}
+}
+
+func TestDoRebalanceSameVenueUsesGridPrice(t *testing.T) {
+	h, gridEx, hedgeEx := newTestHedger(config.HedgeConfig{Ratio: 1}, &stubInventory{qty: 1})
+	// 对冲与网格是同一交易所的同一合约（不同账户）时直接使用网格价格
+	h.status.HedgeExchange = gridEx.GetName()
+	if err := h.doRebalance(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if hedgeEx.CallCount("GetLatestPrice") != 0 {
+		t.Fatal("hedge price should not be queried separately for the same contract")
+	}
+	if st := h.Status(); st.HedgePrice != 100 {
+		t.Fatalf("hedge price = %v, want grid price 100", st.HedgePrice)
+	}
+}
+
+func TestRebalanceRecordsError(t *testing.T) {
+	h, _, hedgeEx := newTestHedger(config.HedgeConfig{Ratio: 0.5}, &stubInventory{qty: 2})
+	hedgeEx.FailOn("GetLatestPrice", errors.New("timeout"))
+	h.rebalance(context.Background())
+
+	st := h.Status()
+	if !strings.Contains(st.LastError, "timeout") || st.LastRebalance.IsZero() {
+		t.Fatalf("status = %+v, want last error recorded", st)
+	}
+	if hedgeEx.CallCount("PlaceOrder") != 0 {
+		t.Fatal("no order should be placed without a hedge price")
+	}
+
+	// 恢复后清除错误
+	hedgeEx.FailOn("GetLatestPrice", nil)
+	h.rebalance(context.Background())
+	if st := h.Status(); st.LastError != "" || st.HedgeQty != 0 {
+		t.Fatalf("status after recovery = %+v", st)
+	}
+	if hedgeEx.CallCount("PlaceOrder") != 1 {
+		t.Fatal("hedge should be opened after recovery")
+	}
+}
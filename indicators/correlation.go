@@ -0,0 +1,187 @@
+package indicators
+
+import (
+	"math"
+	"sort"
+)
+
+// ========== 波动率与相关性 ==========
+
+// CorrelationReport 多个交易对的历史波动率与两两相关系数
+type CorrelationReport struct {
+	Symbols          []string             `json:"symbols"`            // 参与计算的交易对（与矩阵行列顺序一致）
+	Samples          int                  `json:"samples"`            // 对齐后的收益率样本数
+	Volatility       map[string]float64   `json:"volatility"`         // 整个样本的收益率标准差（单周期）
+	RollingVol       map[string][]float64 `json:"rolling_vol"`        // 滚动窗口收益率标准差序列
+	RollingTimes     []int64              `json:"rolling_times"`      // 滚动序列对应的K线时间
+	Matrix           [][]float64          `json:"matrix"`             // 皮尔逊相关系数矩阵
+	RiskParityWeight map[string]float64   `json:"risk_parity_weight"` // 按波动率倒数归一化的权重
+}
+
+// LogReturns 收盘价序列的对数收益率（跳过非正价格）
+func LogReturns(closes []float64) []float64 {
+	if len(closes) < 2 {
+		return nil
+	}
+	returns := make([]float64, 0, len(closes)-1)
+	for i := 1; i < len(closes); i++ {
+		if closes[i-1] > 0 && closes[i] > 0 {
+			returns = append(returns, math.Log(closes[i]/closes[i-1]))
+		} else {
+			returns = append(returns, 0)
+		}
+	}
+	return returns
+}
+
+// SampleStdDev 样本标准差，样本不足 2 个时返回 0
+func SampleStdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(values)-1))
+}
+
+// RollingStdDev 滚动窗口样本标准差，结果长度为 len(values)-window+1
+func RollingStdDev(values []float64, window int) []float64 {
+	if window < 2 || len(values) < window {
+		return nil
+	}
+	result := make([]float64, 0, len(values)-window+1)
+	for i := window; i <= len(values); i++ {
+		result = append(result, SampleStdDev(values[i-window:i]))
+	}
+	return result
+}
+
+// Correlation 两个等长序列的皮尔逊相关系数，任一序列无波动时返回 0
+func Correlation(a, b []float64) float64 {
+	n := len(a)
+	if n != len(b) || n < 2 {
+		return 0
+	}
+	meanA, meanB := 0.0, 0.0
+	for i := 0; i < n; i++ {
+		meanA += a[i]
+		meanB += b[i]
+	}
+	meanA /= float64(n)
+	meanB /= float64(n)
+
+	var cov, varA, varB float64
+	for i := 0; i < n; i++ {
+		da, db := a[i]-meanA, b[i]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
+
+// InverseVolWeights 风险平价权重：按波动率倒数归一化，波动率为 0 的项不参与分配
+func InverseVolWeights(vols map[string]float64) map[string]float64 {
+	weights := make(map[string]float64, len(vols))
+	total := 0.0
+	for _, vol := range vols {
+		if vol > 0 {
+			total += 1 / vol
+		}
+	}
+	for name, vol := range vols {
+		if vol > 0 && total > 0 {
+			weights[name] = (1 / vol) / total
+		} else {
+			weights[name] = 0
+		}
+	}
+	return weights
+}
+
+// ComputeCorrelation 计算多个交易对的波动率与相关性
+// 各交易对的K线按时间对齐（只取所有交易对都有的时间点），window 为滚动波动率窗口（收益率个数）
+func ComputeCorrelation(series map[string][]Candle, window int) *CorrelationReport {
+	symbols := make([]string, 0, len(series))
+	for symbol := range series {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	report := &CorrelationReport{
+		Symbols:          symbols,
+		Volatility:       make(map[string]float64, len(symbols)),
+		RollingVol:       make(map[string][]float64, len(symbols)),
+		Matrix:           make([][]float64, len(symbols)),
+		RiskParityWeight: map[string]float64{},
+	}
+	if len(symbols) == 0 {
+		return report
+	}
+
+	// 按时间对齐：只保留所有交易对都有收盘价的时间点
+	closesByTime := make(map[string]map[int64]float64, len(symbols))
+	counts := make(map[int64]int)
+	for _, symbol := range symbols {
+		closes := make(map[int64]float64, len(series[symbol]))
+		for _, c := range series[symbol] {
+			if _, dup := closes[c.Time]; !dup {
+				counts[c.Time]++
+			}
+			closes[c.Time] = c.Close
+		}
+		closesByTime[symbol] = closes
+	}
+	times := make([]int64, 0, len(counts))
+	for t, n := range counts {
+		if n == len(symbols) {
+			times = append(times, t)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	returns := make(map[string][]float64, len(symbols))
+	for _, symbol := range symbols {
+		closes := make([]float64, len(times))
+		for i, t := range times {
+			closes[i] = closesByTime[symbol][t]
+		}
+		returns[symbol] = LogReturns(closes)
+		report.Volatility[symbol] = SampleStdDev(returns[symbol])
+		if rolling := RollingStdDev(returns[symbol], window); rolling != nil {
+			report.RollingVol[symbol] = rolling
+		}
+	}
+	if len(times) > 1 {
+		report.Samples = len(times) - 1
+	}
+	// 第 k 个滚动值对应收益率 [k, k+window)，结束于 times[k+window]
+	if window >= 2 && report.Samples >= window {
+		report.RollingTimes = append([]int64(nil), times[window:]...)
+	}
+
+	for i, a := range symbols {
+		report.Matrix[i] = make([]float64, len(symbols))
+		for j, b := range symbols {
+			if i == j {
+				report.Matrix[i][j] = 1
+			} else if j < i {
+				report.Matrix[i][j] = report.Matrix[j][i]
+			} else {
+				report.Matrix[i][j] = Correlation(returns[a], returns[b])
+			}
+		}
+	}
+	report.RiskParityWeight = InverseVolWeights(report.Volatility)
+	return report
+}
@@ -0,0 +1,121 @@
+package indicators
+
+import (
+	"math"
+	"sort"
+)
+
+// 关键价位类型
+const (
+	LevelSupport    = "support"
+	LevelResistance = "resistance"
+)
+
+// Level 支撑/阻力位（由多个相近的摆动高低点聚类得到）
+type Level struct {
+	Price     float64 `json:"price"`      // 聚类后的价位（各摆动点的均值）
+	Kind      string  `json:"kind"`       // support / resistance（相对最新收盘价）
+	Touches   int     `json:"touches"`    // 触及次数（参与聚类的摆动点数量）
+	Low       float64 `json:"low"`        // 聚类中最低的摆动点
+	High      float64 `json:"high"`       // 聚类中最高的摆动点
+	LastTouch int64   `json:"last_touch"` // 最近一次触及的K线时间
+}
+
+type pivot struct {
+	price float64
+	time  int64
+}
+
+// DetectLevels 检测支撑/阻力位
+// 以左右各 window 根K线确认摆动高低点，价差在 tolerance（比例，如 0.003 表示 0.3%）以内的摆动点合并为同一价位，
+// 按触及次数排序，收盘价下方为支撑、上方为阻力，每侧最多返回 maxPerSide 个（按离收盘价由近到远）
+func DetectLevels(candles []Candle, window int, tolerance float64, maxPerSide int) []Level {
+	if window <= 0 {
+		window = 3
+	}
+	if len(candles) < window*2+1 {
+		return nil
+	}
+
+	var pivots []pivot
+	for i := window; i < len(candles)-window; i++ {
+		isHigh, isLow := true, true
+		for j := i - window; j <= i+window; j++ {
+			if j == i {
+				continue
+			}
+			if candles[j].High > candles[i].High {
+				isHigh = false
+			}
+			if candles[j].Low < candles[i].Low {
+				isLow = false
+			}
+		}
+		if isHigh {
+			pivots = append(pivots, pivot{price: candles[i].High, time: candles[i].Time})
+		}
+		if isLow {
+			pivots = append(pivots, pivot{price: candles[i].Low, time: candles[i].Time})
+		}
+	}
+	if len(pivots) == 0 {
+		return nil
+	}
+
+	// 按价格排序后顺序聚类：与当前聚类均值的偏离不超过容差则并入
+	sort.Slice(pivots, func(i, j int) bool { return pivots[i].price < pivots[j].price })
+	var clusters [][]pivot
+	var sum float64
+	for _, p := range pivots {
+		n := len(clusters)
+		if n > 0 {
+			mean := sum / float64(len(clusters[n-1]))
+			if math.Abs(p.price-mean)/mean <= tolerance {
+				clusters[n-1] = append(clusters[n-1], p)
+				sum += p.price
+				continue
+			}
+		}
+		clusters = append(clusters, []pivot{p})
+		sum = p.price
+	}
+
+	lastClose := candles[len(candles)-1].Close
+	var supports, resistances []Level
+	for _, cluster := range clusters {
+		level := Level{Touches: len(cluster), Low: cluster[0].price, High: cluster[len(cluster)-1].price}
+		for _, p := range cluster {
+			level.Price += p.price
+			if p.time > level.LastTouch {
+				level.LastTouch = p.time
+			}
+		}
+		level.Price /= float64(len(cluster))
+		if level.Price <= lastClose {
+			level.Kind = LevelSupport
+			supports = append(supports, level)
+		} else {
+			level.Kind = LevelResistance
+			resistances = append(resistances, level)
+		}
+	}
+
+	return append(strongestNearest(supports, lastClose, maxPerSide), strongestNearest(resistances, lastClose, maxPerSide)...)
+}
+
+// strongestNearest 按触及次数取前 n 个价位，再按离参考价由近到远排序
+func strongestNearest(levels []Level, ref float64, n int) []Level {
+	sort.SliceStable(levels, func(i, j int) bool {
+		if levels[i].Touches != levels[j].Touches {
+			return levels[i].Touches > levels[j].Touches
+		}
+		return math.Abs(levels[i].Price-ref) < math.Abs(levels[j].Price-ref)
+	})
+	if n > 0 && len(levels) > n {
+		levels = levels[:n]
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return math.Abs(levels[i].Price-ref) < math.Abs(levels[j].Price-ref)
+	})
+	return levels
+}
@@ -0,0 +1,83 @@
+package indicators
+
+import "math"
+
+// K线形态名称
+const (
+	PatternDoji             = "doji"
+	PatternHammer           = "hammer"
+	PatternShootingStar     = "shooting_star"
+	PatternBullishEngulfing = "bullish_engulfing"
+	PatternBearishEngulfing = "bearish_engulfing"
+	PatternMorningStar      = "morning_star"
+	PatternEveningStar      = "evening_star"
+)
+
+// CandlePattern 检测到的K线形态
+type CandlePattern struct {
+	Name      string `json:"name"`
+	Direction int    `json:"direction"` // 1=看涨，-1=看跌，0=中性
+	Time      int64  `json:"time"`      // 形态最后一根K线的时间
+}
+
+// DetectPatterns 检测最近 lookback 根K线中出现的常见形态（按时间正序）
+func DetectPatterns(candles []Candle, lookback int) []CandlePattern {
+	if lookback <= 0 || lookback > len(candles) {
+		lookback = len(candles)
+	}
+	var patterns []CandlePattern
+	for i := len(candles) - lookback; i < len(candles); i++ {
+		c := candles[i]
+		add := func(name string, direction int) {
+			patterns = append(patterns, CandlePattern{Name: name, Direction: direction, Time: c.Time})
+		}
+
+		body, upper, lower, rng := candleParts(c)
+		if rng <= 0 {
+			continue
+		}
+		switch {
+		case body <= rng*0.1:
+			add(PatternDoji, 0)
+		case lower >= body*2 && upper <= body*0.5:
+			add(PatternHammer, 1)
+		case upper >= body*2 && lower <= body*0.5:
+			add(PatternShootingStar, -1)
+		}
+
+		if i >= 1 {
+			prev := candles[i-1]
+			if prev.Close < prev.Open && c.Close > c.Open && c.Open <= prev.Close && c.Close >= prev.Open {
+				add(PatternBullishEngulfing, 1)
+			}
+			if prev.Close > prev.Open && c.Close < c.Open && c.Open >= prev.Close && c.Close <= prev.Open {
+				add(PatternBearishEngulfing, -1)
+			}
+		}
+
+		if i >= 2 {
+			first, mid := candles[i-2], candles[i-1]
+			firstBody, _, _, firstRange := candleParts(first)
+			midBody, _, _, _ := candleParts(mid)
+			if firstRange > 0 && firstBody >= firstRange*0.5 && midBody <= firstBody*0.3 {
+				firstMid := (first.Open + first.Close) / 2
+				if first.Close < first.Open && c.Close > c.Open && c.Close >= firstMid {
+					add(PatternMorningStar, 1)
+				}
+				if first.Close > first.Open && c.Close < c.Open && c.Close <= firstMid {
+					add(PatternEveningStar, -1)
+				}
+			}
+		}
+	}
+	return patterns
+}
+
+// candleParts 实体、上影线、下影线长度与振幅
+func candleParts(c Candle) (body, upper, lower, rng float64) {
+	body = math.Abs(c.Close - c.Open)
+	upper = c.High - math.Max(c.Open, c.Close)
+	lower = math.Min(c.Open, c.Close) - c.Low
+	rng = c.High - c.Low
+	return body, upper, lower, rng
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+	"quantmesh/web"
+)
+
+// lifecycleManager 容器生命周期管理：健康/就绪检查、PreStop 钩子和退出时的快速撤单
+// 容器停止时编排器先调用 PreStop（或直接发送 SIGTERM），在宽限期的前几秒内暂停所有交易对并并发撤单，
+// 之后再执行平仓、停止组件等较慢的清理
+type lifecycleManager struct {
+	cfg         *config.Config
+	manager     *SymbolManager
+	mode        string // trading / maintenance / setup / standby
+	startedAt   time.Time
+	draining    atomic.Bool
+	cancelOnce  sync.Once
+	cancelStats web.FastCancelResult
+}
+
+func newLifecycleManager(cfg *config.Config, manager *SymbolManager, mode string) *lifecycleManager {
+	return &lifecycleManager{
+		cfg:       cfg,
+		manager:   manager,
+		mode:      mode,
+		startedAt: time.Now(),
+	}
+}
+
+// Health 存活检查（进程在运行即可）
+func (l *lifecycleManager) Health() web.LifecycleHealth {
+	return web.LifecycleHealth{
+		Status:        "ok",
+		Mode:          l.mode,
+		Draining:      l.draining.Load(),
+		Symbols:       len(l.manager.List()),
+		UptimeSeconds: int64(time.Since(l.startedAt).Seconds()),
+	}
+}
+
+// Ready 就绪检查：排空中不就绪；交易模式下至少有一个交易对在运行
+func (l *lifecycleManager) Ready() (bool, string) {
+	if l.draining.Load() {
+		return false, "draining"
+	}
+	if l.mode == "trading" && len(l.manager.List()) == 0 {
+		return false, "no symbol running"
+	}
+	return true, l.mode
+}
+
+// PreStop PreStop 钩子：进入排空状态并快速撤单（重复调用只执行一次）
+func (l *lifecycleManager) PreStop() web.FastCancelResult {
+	logger.Warn("🛑 [生命周期] 收到 PreStop 请求，开始快速撤单")
+	return l.FastCancel()
+}
+
+// FastCancel 暂停所有交易对后并发撤单，整体不超过 fast_cancel_seconds
+// 先暂停再撤单，避免价格变动时仓位管理器立即补挂；按各策略的退出方式（system.shutdown）决定撤哪些单，全部为 leave 时只暂停不撤单
+func (l *lifecycleManager) FastCancel() web.FastCancelResult {
+	l.cancelOnce.Do(func() {
+		l.draining.Store(true)
+		start := time.Now()
+		runtimes := l.manager.List()
+		for _, rt := range runtimes {
+			if rt.SuperPositionManager != nil {
+				rt.SuperPositionManager.Pause()
+			}
+		}
+
+		result := web.FastCancelResult{Symbols: len(runtimes), Failed: []string{}}
+		if !shutdownCancelsAny(l.cfg, runtimes) {
+			result.Skipped = true
+			result.ElapsedMs = time.Since(start).Milliseconds()
+			l.cancelStats = result
+			return
+		}
+
+		budget := time.Duration(l.cfg.System.FastCancelSeconds) * time.Second
+		ctx, cancel := context.WithTimeout(context.Background(), budget)
+		defer cancel()
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, rt := range runtimes {
+			wg.Add(1)
+			go func(rt *SymbolRuntime) {
+				defer wg.Done()
+				kept, err := cancelForShutdown(ctx, l.cfg, rt)
+				mu.Lock()
+				defer mu.Unlock()
+				result.Kept += kept
+				if err != nil {
+					logger.Error("❌ [%s:%s] 快速撤单失败: %v", rt.Config.Exchange, rt.Config.Symbol, err)
+					result.Failed = append(result.Failed, rt.Config.Exchange+":"+rt.Config.Symbol)
+					return
+				}
+				result.Canceled++
+				logger.Info("✅ [%s:%s] 已按退出策略撤单", rt.Config.Exchange, rt.Config.Symbol)
+			}(rt)
+		}
+		wg.Wait()
+
+		result.ElapsedMs = time.Since(start).Milliseconds()
+		logger.Info("🛑 [生命周期] 快速撤单完成: %d/%d 个交易对, 耗时 %dms", result.Canceled, result.Symbols, result.ElapsedMs)
+		l.cancelStats = result
+	})
+	return l.cancelStats
+}
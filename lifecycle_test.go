@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/exchange"
+)
+
+// cancelRecorder 只实现快速撤单用到的方法，其余方法调用时 panic
+type cancelRecorder struct {
+	exchange.IExchange
+	cancelAllErr error
+	block        bool // CancelAllOrders 一直阻塞到 ctx 超时
+	openOrders   []*exchange.Order
+	cancelAlls   atomic.Int32
+	mu           sync.Mutex
+	canceledIDs  []int64
+}
+
+func (r *cancelRecorder) CancelAllOrders(ctx context.Context, symbol string) error {
+	r.cancelAlls.Add(1)
+	if r.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return r.cancelAllErr
+}
+
+func (r *cancelRecorder) GetOpenOrders(ctx context.Context, symbol string) ([]*exchange.Order, error) {
+	return r.openOrders, nil
+}
+
+func (r *cancelRecorder) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.canceledIDs = append(r.canceledIDs, orderIDs...)
+	return nil
+}
+
+func newLifecycleTestManager(cfg *config.Config, exchanges map[string]*cancelRecorder) *lifecycleManager {
+	manager := NewSymbolManager(cfg)
+	for symbol, ex := range exchanges {
+		manager.runtimes[runtimeKey("fake", symbol)] = &SymbolRuntime{
+			Config:   config.SymbolConfig{Exchange: "fake", Symbol: symbol},
+			Exchange: ex,
+		}
+	}
+	return newLifecycleManager(cfg, manager, "trading")
+}
+
+func TestFastCancelLeaveOnlyPauses(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.System.FastCancelSeconds = 1
+	cfg.System.Shutdown.Default = config.ShutdownPolicyLeave
+	ex := &cancelRecorder{}
+	l := newLifecycleTestManager(cfg, map[string]*cancelRecorder{"BTCUSDT": ex})
+
+	result := l.FastCancel()
+	if !result.Skipped || result.Symbols != 1 || result.Canceled != 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if ex.cancelAlls.Load() != 0 {
+		t.Fatalf("leave policy must not cancel orders")
+	}
+	if ready, reason := l.Ready(); ready || reason != "draining" {
+		t.Fatalf("Ready() = %v %q after FastCancel, want draining", ready, reason)
+	}
+}
+
+func TestFastCancelBudgetAndOnce(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.System.FastCancelSeconds = 1
+	cfg.System.Shutdown.Default = config.ShutdownPolicyCancelAll
+	ok := &cancelRecorder{}
+	failing := &cancelRecorder{cancelAllErr: errors.New("rejected")}
+	hanging := &cancelRecorder{block: true}
+	l := newLifecycleTestManager(cfg, map[string]*cancelRecorder{
+		"BTCUSDT": ok,
+		"ETHUSDT": failing,
+		"SOLUSDT": hanging,
+	})
+
+	start := time.Now()
+	result := l.PreStop()
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("FastCancel took %v, budget is 1s", elapsed)
+	}
+	if result.Symbols != 3 || result.Canceled != 1 || result.Skipped {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	sort.Strings(result.Failed)
+	if len(result.Failed) != 2 || result.Failed[0] != "fake:ETHUSDT" || result.Failed[1] != "fake:SOLUSDT" {
+		t.Fatalf("failed symbols = %v", result.Failed)
+	}
+
+	// SIGTERM 随后到达时不重复撤单，返回第一次的结果
+	again := l.FastCancel()
+	if again.Canceled != result.Canceled || ok.cancelAlls.Load() != 1 || hanging.cancelAlls.Load() != 1 {
+		t.Fatalf("second FastCancel re-ran cancellation: %+v", again)
+	}
+	if !l.Health().Draining {
+		t.Fatalf("Health().Draining = false after PreStop")
+	}
+}
+
+func TestFastCancelCancelBuysKeepsSells(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.System.FastCancelSeconds = 1
+	cfg.System.Shutdown.Default = config.ShutdownPolicyCancelBuys
+	ex := &cancelRecorder{openOrders: []*exchange.Order{
+		{OrderID: 1, Side: exchange.SideBuy},
+		{OrderID: 2, Side: exchange.SideSell},
+		{OrderID: 3, Side: exchange.SideBuy},
+	}}
+	l := newLifecycleTestManager(cfg, map[string]*cancelRecorder{"BTCUSDT": ex})
+
+	result := l.FastCancel()
+	if result.Canceled != 1 || result.Kept != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if ex.cancelAlls.Load() != 0 || len(ex.canceledIDs) != 2 || ex.canceledIDs[0] != 1 || ex.canceledIDs[1] != 3 {
+		t.Fatalf("canceled ids = %v, cancelAll calls = %d", ex.canceledIDs, ex.cancelAlls.Load())
+	}
+}
@@ -0,0 +1,267 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InstanceInfo 实例锁持有者信息（写入锁文件）
+type InstanceInfo struct {
+	InstanceID string    `json:"instance_id"`
+	Hostname   string    `json:"hostname"`
+	PID        int       `json:"pid"`
+	Exchange   string    `json:"exchange"`
+	Symbol     string    `json:"symbol"`
+	StartedAt  time.Time `json:"started_at"`
+	Heartbeat  time.Time `json:"heartbeat"`
+}
+
+// InstanceLockedError 检测到另一个存活实例
+type InstanceLockedError struct {
+	Exchange string
+	Symbol   string
+	Source   string        // file（本机锁文件）/ lease（分布式租约）
+	Holder   *InstanceInfo // 租约冲突时为空
+}
+
+func (e *InstanceLockedError) Error() string {
+	if e.Holder != nil {
+		return fmt.Sprintf("%s:%s 已被实例 %s (host=%s, pid=%d) 占用，最近心跳 %s",
+			e.Exchange, e.Symbol, e.Holder.InstanceID, e.Holder.Hostname, e.Holder.PID,
+			e.Holder.Heartbeat.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s:%s 的实例租约已被其它实例持有 (%s)", e.Exchange, e.Symbol, e.Source)
+}
+
+// IsInstanceLocked 判断错误是否为实例锁冲突
+func IsInstanceLocked(err error) bool {
+	var lockedErr *InstanceLockedError
+	return errors.As(err, &lockedErr)
+}
+
+// InstanceLockConfig 实例锁配置
+type InstanceLockConfig struct {
+	InstanceID string        // 实例标识，为空时使用 hostname-pid
+	Dir        string        // 锁文件目录
+	StaleAfter time.Duration // 心跳超过该时间未更新视为持有者已退出
+}
+
+// InstanceLock 单个交易所+交易对的实例锁
+// 由本机锁文件（带心跳，进程崩溃后超时失效）和可选的分布式租约（跨主机）组成
+type InstanceLock struct {
+	cfg      InstanceLockConfig
+	dl       DistributedLock
+	path     string
+	leaseKey string
+	info     InstanceInfo
+
+	mu       sync.Mutex
+	released bool
+	onLost   func(reason string)
+	cancel   context.CancelFunc
+}
+
+// AcquireInstanceLock 获取实例锁，另一个存活实例持有时返回 *InstanceLockedError
+// dl 为 nil 或 NopLock 时只使用本机锁文件
+func AcquireInstanceLock(cfg InstanceLockConfig, dl DistributedLock, exchange, symbol string) (*InstanceLock, error) {
+	hostname, _ := os.Hostname()
+	if cfg.InstanceID == "" {
+		cfg.InstanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = 30 * time.Second
+	}
+
+	key := strings.ToLower(exchange) + "_" + strings.ToUpper(symbol)
+	now := time.Now()
+	l := &InstanceLock{
+		cfg:      cfg,
+		dl:       dl,
+		path:     filepath.Join(cfg.Dir, key+".lock"),
+		leaseKey: "instance:" + key,
+		info: InstanceInfo{
+			InstanceID: cfg.InstanceID,
+			Hostname:   hostname,
+			PID:        os.Getpid(),
+			Exchange:   exchange,
+			Symbol:     symbol,
+			StartedAt:  now,
+			Heartbeat:  now,
+		},
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建锁目录失败: %w", err)
+	}
+	if err := l.acquireFile(); err != nil {
+		return nil, err
+	}
+
+	if dl != nil {
+		ok, err := dl.TryLock(context.Background(), l.leaseKey, l.leaseTTL())
+		if err != nil {
+			os.Remove(l.path)
+			return nil, fmt.Errorf("获取实例租约失败: %w", err)
+		}
+		if !ok {
+			os.Remove(l.path)
+			return nil, &InstanceLockedError{Exchange: exchange, Symbol: symbol, Source: "lease"}
+		}
+	}
+	return l, nil
+}
+
+// acquireFile 以独占方式创建锁文件，已存在的锁文件心跳过期时接管
+func (l *InstanceLock) acquireFile() error {
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			err = json.NewEncoder(f).Encode(l.info)
+			f.Close()
+			if err != nil {
+				os.Remove(l.path)
+				return fmt.Errorf("写入锁文件失败: %w", err)
+			}
+			return nil
+		}
+		if !os.IsExist(err) {
+			return fmt.Errorf("创建锁文件失败: %w", err)
+		}
+
+		holder, readErr := readInstanceInfo(l.path)
+		if readErr == nil && time.Since(holder.Heartbeat) < l.cfg.StaleAfter {
+			return &InstanceLockedError{Exchange: l.info.Exchange, Symbol: l.info.Symbol, Source: "file", Holder: holder}
+		}
+		// 心跳过期或文件损坏：持有者已退出，删除后重试（并发接管时只有一个实例能创建成功）
+		if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("清理过期锁文件失败: %w", err)
+		}
+	}
+	holder, _ := readInstanceInfo(l.path)
+	return &InstanceLockedError{Exchange: l.info.Exchange, Symbol: l.info.Symbol, Source: "file", Holder: holder}
+}
+
+// readInstanceInfo 读取锁文件
+func readInstanceInfo(path string) (*InstanceInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var info InstanceInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// leaseTTL 分布式租约有效期（与锁文件心跳失效时间一致）
+func (l *InstanceLock) leaseTTL() time.Duration {
+	return l.cfg.StaleAfter
+}
+
+// Info 当前实例信息
+func (l *InstanceLock) Info() InstanceInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.info
+}
+
+// SetOnLost 设置锁丢失回调（心跳时发现锁文件被其它实例接管或租约续期失败）
+func (l *InstanceLock) SetOnLost(fn func(reason string)) {
+	l.mu.Lock()
+	l.onLost = fn
+	l.mu.Unlock()
+}
+
+// Start 启动心跳（间隔为失效时间的三分之一）
+func (l *InstanceLock) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	l.mu.Lock()
+	l.cancel = cancel
+	l.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(l.cfg.StaleAfter / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := l.heartbeat(ctx); err != nil {
+					l.mu.Lock()
+					onLost := l.onLost
+					l.mu.Unlock()
+					if onLost != nil {
+						onLost(err.Error())
+					}
+					return
+				}
+			}
+		}
+	}()
+}
+
+// heartbeat 刷新锁文件心跳并续期租约
+func (l *InstanceLock) heartbeat(ctx context.Context) error {
+	holder, err := readInstanceInfo(l.path)
+	if err != nil {
+		return fmt.Errorf("读取锁文件失败: %w", err)
+	}
+	if holder.InstanceID != l.info.InstanceID || holder.PID != l.info.PID || holder.Hostname != l.info.Hostname {
+		return fmt.Errorf("锁文件已被实例 %s (host=%s, pid=%d) 接管", holder.InstanceID, holder.Hostname, holder.PID)
+	}
+
+	l.mu.Lock()
+	l.info.Heartbeat = time.Now()
+	info := l.info
+	l.mu.Unlock()
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入锁文件失败: %w", err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return fmt.Errorf("写入锁文件失败: %w", err)
+	}
+
+	if l.dl != nil {
+		if err := l.dl.Extend(ctx, l.leaseKey, l.leaseTTL()); err != nil {
+			return fmt.Errorf("实例租约续期失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// Release 停止心跳并释放锁（仅删除自己持有的锁文件）
+func (l *InstanceLock) Release() {
+	l.mu.Lock()
+	if l.released {
+		l.mu.Unlock()
+		return
+	}
+	l.released = true
+	cancel := l.cancel
+	l.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	if holder, err := readInstanceInfo(l.path); err == nil && holder.InstanceID == l.info.InstanceID && holder.PID == l.info.PID {
+		os.Remove(l.path)
+	}
+	if l.dl != nil {
+		l.dl.Unlock(context.Background(), l.leaseKey)
+	}
+}
@@ -0,0 +1,326 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testLockConfig(dir, id string, staleAfter time.Duration) InstanceLockConfig {
+	return InstanceLockConfig{InstanceID: id, Dir: dir, StaleAfter: staleAfter}
+}
+
+func writeLockFile(t *testing.T, path string, info InstanceInfo) {
+	t.Helper()
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitFor 轮询等待条件成立
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return cond()
+}
+
+func TestAcquireInstanceLockRefusesLiveHolder(t *testing.T) {
+	dir := t.TempDir()
+	first, err := AcquireInstanceLock(testLockConfig(dir, "a", time.Minute), nil, "Binance", "btcusdt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Release()
+
+	// 锁文件按 交易所小写_交易对大写 命名，记录持有者信息
+	path := filepath.Join(dir, "binance_BTCUSDT.lock")
+	holder, err := readInstanceInfo(path)
+	if err != nil || holder.InstanceID != "a" || holder.PID != os.Getpid() {
+		t.Fatalf("lock file = %+v, err = %v", holder, err)
+	}
+
+	_, err = AcquireInstanceLock(testLockConfig(dir, "b", time.Minute), nil, "binance", "BTCUSDT")
+	var lockedErr *InstanceLockedError
+	if !errors.As(err, &lockedErr) || !IsInstanceLocked(err) {
+		t.Fatalf("second acquire err = %v, want *InstanceLockedError", err)
+	}
+	if lockedErr.Source != "file" || lockedErr.Holder == nil || lockedErr.Holder.InstanceID != "a" {
+		t.Fatalf("locked error = %+v", lockedErr)
+	}
+
+	// 其它交易对不受影响
+	other, err := AcquireInstanceLock(testLockConfig(dir, "b", time.Minute), nil, "binance", "ETHUSDT")
+	if err != nil {
+		t.Fatalf("different symbol should not conflict: %v", err)
+	}
+	other.Release()
+
+	// 释放后可立即重新获取
+	first.Release()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("lock file should be removed on release: %v", err)
+	}
+	second, err := AcquireInstanceLock(testLockConfig(dir, "b", time.Minute), nil, "binance", "BTCUSDT")
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	second.Release()
+}
+
+func TestAcquireInstanceLockTakesOverStaleFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "binance_BTCUSDT.lock")
+
+	// 持有者心跳已过期（进程崩溃后残留的锁文件）
+	writeLockFile(t, path, InstanceInfo{InstanceID: "crashed", Hostname: "old-host", PID: 1, Heartbeat: time.Now().Add(-time.Minute)})
+	l, err := AcquireInstanceLock(testLockConfig(dir, "new", 30*time.Second), nil, "binance", "BTCUSDT")
+	if err != nil {
+		t.Fatalf("stale lock should be taken over: %v", err)
+	}
+	if holder, _ := readInstanceInfo(path); holder == nil || holder.InstanceID != "new" {
+		t.Fatalf("lock file holder = %+v", holder)
+	}
+	l.Release()
+
+	// 损坏的锁文件同样视为无主
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l, err = AcquireInstanceLock(testLockConfig(dir, "new", 30*time.Second), nil, "binance", "BTCUSDT")
+	if err != nil {
+		t.Fatalf("corrupt lock should be taken over: %v", err)
+	}
+	l.Release()
+}
+
+func TestInstanceLockHeartbeat(t *testing.T) {
+	dir := t.TempDir()
+	l, err := AcquireInstanceLock(testLockConfig(dir, "a", 90*time.Millisecond), nil, "binance", "BTCUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Release()
+	path := filepath.Join(dir, "binance_BTCUSDT.lock")
+	started := l.Info().Heartbeat
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l.Start(ctx)
+
+	// 心跳每 30ms 刷新一次，锁文件一直不会过期
+	if !waitFor(t, time.Second, func() bool {
+		holder, err := readInstanceInfo(path)
+		return err == nil && holder.Heartbeat.After(started)
+	}) {
+		t.Fatal("heartbeat did not refresh the lock file")
+	}
+	time.Sleep(200 * time.Millisecond)
+	if _, err := AcquireInstanceLock(testLockConfig(dir, "b", 90*time.Millisecond), nil, "binance", "BTCUSDT"); !IsInstanceLocked(err) {
+		t.Fatalf("lock with a live heartbeat was taken over: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatal("heartbeat should replace the lock file atomically")
+	}
+}
+
+func TestInstanceLockHeartbeatDetectsTakeover(t *testing.T) {
+	dir := t.TempDir()
+	l, err := AcquireInstanceLock(testLockConfig(dir, "a", 60*time.Millisecond), nil, "binance", "BTCUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Release()
+	lost := make(chan string, 1)
+	l.SetOnLost(func(reason string) { lost <- reason })
+
+	// 其它实例在本实例心跳之前接管了锁文件
+	path := filepath.Join(dir, "binance_BTCUSDT.lock")
+	writeLockFile(t, path, InstanceInfo{InstanceID: "b", Hostname: "other", PID: 42, Heartbeat: time.Now()})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l.Start(ctx)
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatal("onLost was not called after the lock file was taken over")
+	}
+
+	// 释放时不删除别人的锁文件
+	l.Release()
+	if holder, _ := readInstanceInfo(path); holder == nil || holder.InstanceID != "b" {
+		t.Fatalf("release removed the new holder's lock: %+v", holder)
+	}
+}
+
+// TestInstanceLockObserverTakeover 观察模式：持有者存活时重试一直被拒绝，持有者停止心跳后超时接管
+func TestInstanceLockObserverTakeover(t *testing.T) {
+	dir := t.TempDir()
+	staleAfter := 90 * time.Millisecond
+	holder, err := AcquireInstanceLock(testLockConfig(dir, "primary", staleAfter), nil, "binance", "BTCUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer holder.Release()
+	holderCtx, stopHolder := context.WithCancel(context.Background())
+	holder.Start(holderCtx)
+	holderLost := make(chan string, 1)
+	holder.SetOnLost(func(reason string) { holderLost <- reason })
+
+	observe := func() (*InstanceLock, error) {
+		return AcquireInstanceLock(testLockConfig(dir, "observer", staleAfter), nil, "binance", "BTCUSDT")
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := observe(); !IsInstanceLocked(err) {
+			t.Fatalf("observer acquired a live lock on attempt %d: %v", i, err)
+		}
+		time.Sleep(staleAfter / 3)
+	}
+
+	// 持有者卡死（停止心跳但不释放），超过失效时间后观察者接管
+	stopHolder()
+	var observer *InstanceLock
+	if !waitFor(t, 2*time.Second, func() bool {
+		observer, err = observe()
+		return err == nil
+	}) {
+		t.Fatalf("observer did not take over a stale lock: %v", err)
+	}
+	defer observer.Release()
+	if info, _ := readInstanceInfo(filepath.Join(dir, "binance_BTCUSDT.lock")); info == nil || info.InstanceID != "observer" {
+		t.Fatalf("lock file holder = %+v", info)
+	}
+
+	// 原持有者恢复心跳时发现锁已被接管
+	holder.Start(context.Background())
+	select {
+	case <-holderLost:
+	case <-time.After(time.Second):
+		t.Fatal("previous holder did not notice the takeover")
+	}
+}
+
+// fakeLease 内存分布式租约
+type fakeLease struct {
+	mu        sync.Mutex
+	held      map[string]bool
+	tryErr    error
+	extendErr error
+	extends   int
+}
+
+func newFakeLease() *fakeLease {
+	return &fakeLease{held: make(map[string]bool)}
+}
+
+func (f *fakeLease) Lock(ctx context.Context, key string, ttl time.Duration) error { return nil }
+
+func (f *fakeLease) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.tryErr != nil {
+		return false, f.tryErr
+	}
+	if f.held[key] {
+		return false, nil
+	}
+	f.held[key] = true
+	return true, nil
+}
+
+func (f *fakeLease) Unlock(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.held, key)
+	return nil
+}
+
+func (f *fakeLease) Extend(ctx context.Context, key string, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.extends++
+	return f.extendErr
+}
+
+func (f *fakeLease) Close() error { return nil }
+
+func TestAcquireInstanceLockRefusesHeldLease(t *testing.T) {
+	lease := newFakeLease()
+	first, err := AcquireInstanceLock(testLockConfig(t.TempDir(), "a", time.Minute), lease, "binance", "BTCUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Release()
+
+	// 另一台主机（不同锁目录）上的实例被租约拒绝，且不残留本机锁文件
+	dir := t.TempDir()
+	_, err = AcquireInstanceLock(testLockConfig(dir, "b", time.Minute), lease, "binance", "BTCUSDT")
+	var lockedErr *InstanceLockedError
+	if !errors.As(err, &lockedErr) || lockedErr.Source != "lease" || lockedErr.Holder != nil {
+		t.Fatalf("err = %v, want lease conflict", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "binance_BTCUSDT.lock")); !os.IsNotExist(err) {
+		t.Fatal("lock file should be removed when the lease is refused")
+	}
+
+	// 租约服务出错时不是实例冲突
+	lease.tryErr = errors.New("redis down")
+	_, err = AcquireInstanceLock(testLockConfig(t.TempDir(), "c", time.Minute), lease, "binance", "ETHUSDT")
+	if err == nil || IsInstanceLocked(err) {
+		t.Fatalf("err = %v, want lease error", err)
+	}
+
+	// 释放后租约可被其它实例获取
+	lease.tryErr = nil
+	first.Release()
+	second, err := AcquireInstanceLock(testLockConfig(dir, "b", time.Minute), lease, "binance", "BTCUSDT")
+	if err != nil {
+		t.Fatalf("acquire after lease release: %v", err)
+	}
+	second.Release()
+}
+
+func TestInstanceLockHeartbeatExtendsLease(t *testing.T) {
+	lease := newFakeLease()
+	l, err := AcquireInstanceLock(testLockConfig(t.TempDir(), "a", 60*time.Millisecond), lease, "binance", "BTCUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Release()
+	lost := make(chan string, 1)
+	l.SetOnLost(func(reason string) { lost <- reason })
+	l.Start(context.Background())
+
+	if !waitFor(t, time.Second, func() bool {
+		lease.mu.Lock()
+		defer lease.mu.Unlock()
+		return lease.extends >= 2
+	}) {
+		t.Fatal("heartbeat did not extend the lease")
+	}
+
+	// 续期失败视为锁丢失
+	lease.mu.Lock()
+	lease.extendErr = errors.New("lease expired")
+	lease.mu.Unlock()
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatal("onLost was not called after the lease extension failed")
+	}
+}
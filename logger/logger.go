@@ -46,6 +46,10 @@ var (
 	logStorageWriter func(level, message string)
 	logStorageMu     sync.RWMutex
 
+	// 远程日志转发（通过函数指针避免循环依赖，实现方必须非阻塞）
+	logShipWriter func(level, message string)
+	logShipMu     sync.RWMutex
+
 	// 日志语言配置
 	logLanguage string = "zh-CN"
 	langMu      sync.RWMutex
@@ -281,6 +285,23 @@ func InitLogStorage(writer func(level, message string)) {
 	logStorageWriter = writer
 }
 
+// InitLogShipper 设置远程日志转发（writer 为 nil 时关闭），writer 会在日志调用方的 goroutine 中同步执行，不能阻塞
+func InitLogShipper(writer func(level, message string)) {
+	logShipMu.Lock()
+	defer logShipMu.Unlock()
+	logShipWriter = writer
+}
+
+// shipLog 转发日志到远程（未设置时忽略）
+func shipLog(level LogLevel, message string) {
+	logShipMu.RLock()
+	writer := logShipWriter
+	logShipMu.RUnlock()
+	if writer != nil {
+		writer(level.String(), message)
+	}
+}
+
 // InitWebLogger 初始化 Web 日志文件
 func InitWebLogger() error {
 	webFileMu.Lock()
@@ -411,7 +432,7 @@ func shouldLog(level LogLevel) bool {
 
 // logf 内部日志输出函数
 func logf(level LogLevel, format string, args ...interface{}) {
-	if !shouldLog(level) {
+	if !allowLog(level, firstRuneOf(format)) {
 		return
 	}
 	
@@ -475,11 +496,19 @@ func logf(level LogLevel, format string, args ...interface{}) {
 			writer(level.String(), message)
 		}()
 	}
+
+	shipLog(level, message)
 }
 
 // logln 内部日志输出函数（无格式）
 func logln(level LogLevel, args ...interface{}) {
-	if !shouldLog(level) {
+	first := rune(0)
+	if len(args) > 0 {
+		if s, ok := args[0].(string); ok {
+			first = firstRuneOf(s)
+		}
+	}
+	if !allowLog(level, first) {
 		return
 	}
 	
@@ -547,6 +576,8 @@ func logln(level LogLevel, args ...interface{}) {
 			writer(level.String(), strings.TrimSuffix(message, "\n"))
 		}()
 	}
+
+	shipLog(level, strings.TrimSuffix(message, "\n"))
 }
 
 // Debug 输出调试日志
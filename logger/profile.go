@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// 日志配置档
+const (
+	ProfileNormal  = "normal"  // 按全局日志级别输出
+	ProfileQuiet   = "quiet"   // 静默：不输出以 emoji 开头的 INFO/DEBUG 日志（周期性状态改为只写指标），WARN 及以上不受影响
+	ProfileVerbose = "verbose" // 详细：所有模块按 DEBUG 输出
+)
+
+// modulePathPrefix 本仓库包路径前缀，模块名为去掉前缀后的包路径（main 包为 main）
+const modulePathPrefix = "quantmesh/"
+
+var (
+	profile atomic.Value // string
+
+	// 按模块（包路径）覆盖日志级别，如 position=DEBUG、exchange=WARN（前缀匹配子包，最长匹配优先）
+	moduleLevels     map[string]LogLevel
+	moduleLevelCount atomic.Int32
+	moduleMu         sync.RWMutex
+)
+
+func init() {
+	profile.Store(ProfileNormal)
+}
+
+// SetProfile 切换日志配置档（normal / quiet / verbose），运行时生效
+func SetProfile(p string) error {
+	p = strings.ToLower(strings.TrimSpace(p))
+	if p == "" {
+		p = ProfileNormal
+	}
+	switch p {
+	case ProfileNormal, ProfileQuiet, ProfileVerbose:
+		profile.Store(p)
+		return nil
+	}
+	return fmt.Errorf("不支持的日志配置档: %s（可选 normal / quiet / verbose）", p)
+}
+
+// GetProfile 当前日志配置档
+func GetProfile() string {
+	return profile.Load().(string)
+}
+
+// IsQuiet 是否为静默配置档（周期性状态输出应改为只写指标）
+func IsQuiet() bool {
+	return GetProfile() == ProfileQuiet
+}
+
+// SetModuleLevel 覆盖单个模块的日志级别，module 为包路径（如 position、exchange/binance、main）
+func SetModuleLevel(module string, level LogLevel) {
+	module = strings.Trim(strings.TrimSpace(module), "/")
+	moduleMu.Lock()
+	defer moduleMu.Unlock()
+	if moduleLevels == nil {
+		moduleLevels = make(map[string]LogLevel)
+	}
+	moduleLevels[module] = level
+	moduleLevelCount.Store(int32(len(moduleLevels)))
+}
+
+// ClearModuleLevel 取消模块的日志级别覆盖，恢复使用全局级别
+func ClearModuleLevel(module string) {
+	module = strings.Trim(strings.TrimSpace(module), "/")
+	moduleMu.Lock()
+	defer moduleMu.Unlock()
+	delete(moduleLevels, module)
+	moduleLevelCount.Store(int32(len(moduleLevels)))
+}
+
+// ModuleLevels 当前的模块日志级别覆盖
+func ModuleLevels() map[string]string {
+	moduleMu.RLock()
+	defer moduleMu.RUnlock()
+	levels := make(map[string]string, len(moduleLevels))
+	for module, level := range moduleLevels {
+		levels[module] = level.String()
+	}
+	return levels
+}
+
+// allowLog 结合配置档、模块级别覆盖和全局级别判断是否输出；firstRune 为消息的首个非空白字符
+func allowLog(level LogLevel, firstRune rune) bool {
+	if level < WARN && GetProfile() == ProfileQuiet && isEmoji(firstRune) {
+		return false
+	}
+	if moduleLevelCount.Load() > 0 {
+		if moduleLevel, ok := callerModuleLevel(); ok {
+			return level >= moduleLevel
+		}
+	}
+	if GetProfile() == ProfileVerbose {
+		return true
+	}
+	return shouldLog(level)
+}
+
+// callerModuleLevel 查找调用方所在模块的级别覆盖
+func callerModuleLevel() (LogLevel, bool) {
+	module := callerModule()
+	moduleMu.RLock()
+	defer moduleMu.RUnlock()
+	return matchModuleLevel(moduleLevels, module)
+}
+
+// matchModuleLevel 按包路径前缀匹配模块级别，最长匹配优先
+func matchModuleLevel(levels map[string]LogLevel, module string) (LogLevel, bool) {
+	best, bestLen, found := INFO, -1, false
+	for name, level := range levels {
+		if (module == name || strings.HasPrefix(module, name+"/")) && len(name) > bestLen {
+			best, bestLen, found = level, len(name), true
+		}
+	}
+	return best, found
+}
+
+// callerModule 日志调用方的模块名（跳过 logger 包自身的栈帧）
+func callerModule() string {
+	var pcs [16]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, modulePathPrefix+"logger.") {
+			return moduleOf(frame.Function)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// moduleOf 由函数全名得到模块名，如 quantmesh/exchange/binance.(*Client).Do -> exchange/binance
+func moduleOf(function string) string {
+	function = strings.TrimPrefix(function, modulePathPrefix)
+	dir := ""
+	if idx := strings.LastIndex(function, "/"); idx >= 0 {
+		dir, function = function[:idx+1], function[idx+1:]
+	}
+	if idx := strings.Index(function, "."); idx >= 0 {
+		function = function[:idx]
+	}
+	return dir + function
+}
+
+// firstRuneOf 消息的首个非空白字符
+func firstRuneOf(s string) rune {
+	s = strings.TrimLeft(s, " \t")
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+// isEmoji 粗略判断是否为 emoji/符号字符（箭头、杂项符号、表情等区段，不含中日韩文字和标点）
+func isEmoji(r rune) bool {
+	return r >= 0x1F000 || (r >= 0x2190 && r <= 0x2BFF)
+}
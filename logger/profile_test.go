@@ -0,0 +1,42 @@
+package logger
+
+import "testing"
+
+func TestModuleLevelsAndProfiles(t *testing.T) {
+	for function, want := range map[string]string{
+		"quantmesh/position.(*SuperPositionManager).AdjustOrders": "position",
+		"quantmesh/exchange/binance.(*Client).PlaceOrder":         "exchange/binance",
+		"main.startSymbolRuntime.func3":                           "main",
+	} {
+		if got := moduleOf(function); got != want {
+			t.Errorf("moduleOf(%q) = %q, want %q", function, got, want)
+		}
+	}
+
+	// 子包继承父模块的级别，更具体的模块优先
+	levels := map[string]LogLevel{"exchange": WARN, "exchange/binance": DEBUG}
+	if level, ok := matchModuleLevel(levels, "exchange/binance"); !ok || level != DEBUG {
+		t.Errorf("exchange/binance = %v/%v, want DEBUG", level, ok)
+	}
+	if level, ok := matchModuleLevel(levels, "exchange/okx"); !ok || level != WARN {
+		t.Errorf("exchange/okx = %v/%v, want WARN", level, ok)
+	}
+	if _, ok := matchModuleLevel(levels, "exchangex"); ok {
+		t.Error("exchangex should not match exchange")
+	}
+
+	// 静默配置档只屏蔽以 emoji 开头的 INFO/DEBUG 日志
+	defer SetProfile(ProfileNormal)
+	if err := SetProfile("quiet"); err != nil {
+		t.Fatal(err)
+	}
+	if allowLog(INFO, firstRuneOf("  🟢 [binance:BTCUSDT] 持仓")) {
+		t.Error("emoji info log should be suppressed in quiet profile")
+	}
+	if !allowLog(INFO, firstRuneOf("[BTCUSDT] 累计买入")) || !allowLog(WARN, firstRuneOf("⚠️ 撤单失败")) {
+		t.Error("plain info and emoji warn logs should pass in quiet profile")
+	}
+	if err := SetProfile("loud"); err == nil {
+		t.Error("unknown profile should be rejected")
+	}
+}
@@ -2,11 +2,13 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
@@ -17,6 +19,7 @@ import (
 	"quantmesh/database"
 	"quantmesh/event"
 	"quantmesh/exchange"
+	"quantmesh/hedge"
 	"quantmesh/i18n"
 	"quantmesh/lock"
 	"quantmesh/logger"
@@ -29,7 +32,12 @@ import (
 	"quantmesh/order"
 	"quantmesh/plugin"
 	"quantmesh/position"
+	"quantmesh/safety"
+	"quantmesh/scheduler"
+	"quantmesh/shipper"
+	"quantmesh/standby"
 	"quantmesh/storage"
+	"quantmesh/strategy"
 	"quantmesh/utils"
 	"quantmesh/web"
 )
@@ -78,9 +86,440 @@ func (a *capitalDataSourceAdapter) GetConfig() *config.Config {
 	return a.cfg
 }
 
+// riskProfileAdapter 风控档位适配器（运行时切换交易对档位）
+type riskProfileAdapter struct {
+	manager *SymbolManager
+	cfg     *config.Config
+}
+
+func (a *riskProfileAdapter) GetSymbolRiskProfiles() []web.SymbolRiskProfile {
+	runtimes := a.manager.List()
+	result := make([]web.SymbolRiskProfile, 0, len(runtimes))
+	for _, rt := range runtimes {
+		result = append(result, web.SymbolRiskProfile{
+			Exchange: rt.Config.Exchange,
+			Symbol:   rt.Config.Symbol,
+			Profile:  rt.Config.RiskProfile,
+		})
+	}
+	return result
+}
+
+func (a *riskProfileAdapter) SwitchRiskProfile(exchangeName, symbol, name string) error {
+	rt, ok := a.manager.Get(exchangeName, symbol)
+	if !ok || rt.RuntimeConfig == nil {
+		return fmt.Errorf("交易对 %s:%s 未运行", exchangeName, symbol)
+	}
+	profile, ok := a.cfg.LookupRiskProfile(name)
+	if !ok {
+		return fmt.Errorf("风控档位 %s 不存在", name)
+	}
+	rt.SwitchRiskProfile(name, profile)
+	return nil
+}
+
+// positionEditorAdapter 槽位库存人工修正适配器（修正后强制对账）
+type positionEditorAdapter struct {
+	manager *SymbolManager
+}
+
+func (a *positionEditorAdapter) AdjustSlot(req web.SlotAdjustRequest, operator string) (*web.SlotAdjustResult, error) {
+	rt, ok := a.manager.Get(req.Exchange, req.Symbol)
+	if !ok || rt.SuperPositionManager == nil {
+		return nil, fmt.Errorf("交易对 %s:%s 未运行", req.Exchange, req.Symbol)
+	}
+	adj, err := rt.SuperPositionManager.ManualAdjustSlot(req.Price, req.Status == position.PositionStatusFilled, req.Quantity, operator, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	result := &web.SlotAdjustResult{
+		Exchange:     adj.Exchange,
+		Symbol:       adj.Symbol,
+		Price:        adj.Price,
+		BeforeStatus: adj.Before.PositionStatus,
+		BeforeQty:    adj.Before.PositionQty,
+		AfterStatus:  adj.After.PositionStatus,
+		AfterQty:     adj.After.PositionQty,
+	}
+	if rt.Reconciler != nil {
+		if err := rt.Reconciler.Reconcile(); err != nil {
+			logger.Warn("⚠️ [人工修正] %s:%s 修正后对账失败: %v", req.Exchange, req.Symbol, err)
+			result.ReconcileError = err.Error()
+		} else {
+			result.Reconciled = true
+		}
+	}
+	return result, nil
+}
+
+func (a *positionEditorAdapter) SlotAction(req web.SlotActionRequest, operator string) (*web.SlotActionResult, error) {
+	rt, ok := a.manager.Get(req.Exchange, req.Symbol)
+	if !ok || rt.SuperPositionManager == nil {
+		return nil, fmt.Errorf("交易对 %s:%s 未运行", req.Exchange, req.Symbol)
+	}
+	res, err := rt.SuperPositionManager.ApplySlotAction(req.Price, req.Action, operator, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	return &web.SlotActionResult{
+		Exchange:        res.Exchange,
+		Symbol:          res.Symbol,
+		Price:           res.Price,
+		Action:          res.Action,
+		Locked:          res.Locked,
+		CanceledOrderID: res.CanceledOrderID,
+		SellOrderID:     res.SellOrderID,
+		SellPrice:       res.SellPrice,
+		SellQty:         res.SellQty,
+		Slot:            web.NewSlotInfo(res.Exchange, res.Symbol, res.Slot),
+	}, nil
+}
+
+// gridRecenterAdapter 网格重新居中适配器
+type gridRecenterAdapter struct {
+	manager *SymbolManager
+}
+
+func (a *gridRecenterAdapter) positionManager(exchangeName, symbol string) (*position.SuperPositionManager, error) {
+	rt, ok := a.manager.Get(exchangeName, symbol)
+	if !ok || rt.SuperPositionManager == nil {
+		return nil, fmt.Errorf("交易对 %s:%s 未运行", exchangeName, symbol)
+	}
+	return rt.SuperPositionManager, nil
+}
+
+func (a *gridRecenterAdapter) PlanRecenter(exchangeName, symbol string) (*position.RecenterPlan, error) {
+	spm, err := a.positionManager(exchangeName, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return spm.PlanRecenter()
+}
+
+func (a *gridRecenterAdapter) Recenter(exchangeName, symbol string) (*position.RecenterPlan, error) {
+	spm, err := a.positionManager(exchangeName, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return spm.Recenter()
+}
+
+// strategyBreakerAdapter 单策略亏损熔断适配器
+type strategyBreakerAdapter struct {
+	manager *SymbolManager
+}
+
+func (a *strategyBreakerAdapter) strategyManager(exchangeName, symbol string) (*strategy.StrategyManager, error) {
+	rt, ok := a.manager.Get(exchangeName, symbol)
+	if !ok {
+		return nil, fmt.Errorf("交易对 %s:%s 未运行", exchangeName, symbol)
+	}
+	if rt.StrategyManager == nil {
+		return nil, fmt.Errorf("交易对 %s:%s 未启用多策略", exchangeName, symbol)
+	}
+	return rt.StrategyManager, nil
+}
+
+func (a *strategyBreakerAdapter) GetLossBreakerStatus(exchangeName, symbol string) ([]strategy.LossBreakerStatus, error) {
+	sm, err := a.strategyManager(exchangeName, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return sm.GetLossBreakerStatus(), nil
+}
+
+func (a *strategyBreakerAdapter) ResumeStrategy(exchangeName, symbol, name string) error {
+	sm, err := a.strategyManager(exchangeName, symbol)
+	if err != nil {
+		return err
+	}
+	return sm.ResumeStrategy(name)
+}
+
+// ocoAdapter OCO 止盈/止损关联订单适配器
+// incomeReconciliationAdapter 盈亏对账适配器
+type incomeReconciliationAdapter struct {
+	manager *SymbolManager
+}
+
+func (a *incomeReconciliationAdapter) GetIncomeReconciliation(exchangeName, symbol string, start, end time.Time) (*monitor.IncomeReconciliationReport, error) {
+	rt, ok := a.manager.Get(exchangeName, symbol)
+	if !ok {
+		return nil, fmt.Errorf("交易对 %s:%s 未运行", exchangeName, symbol)
+	}
+	if rt.IncomeLedger == nil {
+		return nil, fmt.Errorf("交易对 %s:%s 未启用账户流水同步", exchangeName, symbol)
+	}
+	return rt.IncomeLedger.Report(start, end)
+}
+
+// klineHistoryAdapter 本地 K 线与缺口补齐适配器
+type klineHistoryAdapter struct {
+	manager *SymbolManager
+}
+
+func (a *klineHistoryAdapter) backfiller(exchangeName, symbol string) (*monitor.KlineBackfiller, error) {
+	rt, ok := a.manager.Get(exchangeName, symbol)
+	if !ok {
+		return nil, fmt.Errorf("交易对 %s:%s 未运行", exchangeName, symbol)
+	}
+	if rt.KlineBackfiller == nil {
+		return nil, fmt.Errorf("交易对 %s:%s 未启用 K 线缺口补齐", exchangeName, symbol)
+	}
+	return rt.KlineBackfiller, nil
+}
+
+func (a *klineHistoryAdapter) GetKlineCoverage(exchangeName, symbol string) ([]*monitor.KlineCoverage, error) {
+	kb, err := a.backfiller(exchangeName, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return kb.Coverage(), nil
+}
+
+func (a *klineHistoryAdapter) BackfillKlines(ctx context.Context, exchangeName, symbol string) ([]*monitor.KlineCoverage, error) {
+	kb, err := a.backfiller(exchangeName, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return kb.BackfillAll(ctx), nil
+}
+
+func (a *klineHistoryAdapter) GetStoredKlines(exchangeName, symbol, interval string, limit int) ([]*storage.Kline, error) {
+	kb, err := a.backfiller(exchangeName, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return kb.Klines(interval, limit)
+}
+
+// notificationTemplateAdapter 通知模板预览和测试适配器
+type notificationTemplateAdapter struct {
+	notifier *notify.NotificationService
+}
+
+func (a *notificationTemplateAdapter) PreviewNotification(req web.NotificationPreviewRequest) (*notify.TemplatePreview, error) {
+	var draft *config.NotificationTemplateConfig
+	if req.Title != "" || req.Body != "" {
+		draft = &config.NotificationTemplateConfig{Title: req.Title, Body: req.Body}
+	}
+	return a.notifier.Preview(req.Channel, notify.SampleEvent(req.EventType, req.Data), draft)
+}
+
+func (a *notificationTemplateAdapter) SendTestNotification(req web.NotificationPreviewRequest) map[string]string {
+	return a.notifier.SendTest(notify.SampleEvent(req.EventType, req.Data))
+}
+
+// marginModelAdapter 下单前保证金检查模型适配器
+type marginModelAdapter struct {
+	manager *SymbolManager
+}
+
+func (a *marginModelAdapter) GetMarginModels(exchangeName, symbol string) []order.MarginModel {
+	runtimes := a.manager.List()
+	sort.Slice(runtimes, func(i, j int) bool {
+		return runtimeKey(runtimes[i].Config.Exchange, runtimes[i].Config.Symbol) < runtimeKey(runtimes[j].Config.Exchange, runtimes[j].Config.Symbol)
+	})
+	models := make([]order.MarginModel, 0, len(runtimes))
+	for _, rt := range runtimes {
+		if rt.ExchangeExecutor == nil || (symbol != "" && rt.Config.Symbol != symbol) ||
+			(exchangeName != "" && !strings.EqualFold(rt.Config.Exchange, exchangeName)) {
+			continue
+		}
+		if m, ok := rt.ExchangeExecutor.MarginModel(); ok {
+			m.Exchange = rt.Config.Exchange
+			models = append(models, m)
+		}
+	}
+	return models
+}
+
+// hedgeAdapter 库存对冲状态适配器
+type hedgeAdapter struct {
+	manager *SymbolManager
+}
+
+func (a *hedgeAdapter) GetHedgeStatuses(exchangeName, symbol string) []hedge.Status {
+	runtimes := a.manager.List()
+	sort.Slice(runtimes, func(i, j int) bool {
+		return runtimeKey(runtimes[i].Config.Exchange, runtimes[i].Config.Symbol) < runtimeKey(runtimes[j].Config.Exchange, runtimes[j].Config.Symbol)
+	})
+	statuses := make([]hedge.Status, 0, len(runtimes))
+	for _, rt := range runtimes {
+		if rt.Hedger == nil || (symbol != "" && rt.Config.Symbol != symbol) ||
+			(exchangeName != "" && !strings.EqualFold(rt.Config.Exchange, exchangeName)) {
+			continue
+		}
+		statuses = append(statuses, rt.Hedger.Status())
+	}
+	return statuses
+}
+
+type ocoAdapter struct {
+	manager *SymbolManager
+}
+
+func (a *ocoAdapter) runtime(exchangeName, symbol string) (*SymbolRuntime, error) {
+	rt, ok := a.manager.Get(exchangeName, symbol)
+	if !ok || rt.ExchangeExecutor == nil {
+		return nil, fmt.Errorf("交易对 %s:%s 未运行", exchangeName, symbol)
+	}
+	return rt, nil
+}
+
+func (a *ocoAdapter) GetOCOLinks(exchangeName, symbol string) ([]order.OCOLink, error) {
+	rt, err := a.runtime(exchangeName, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return rt.ExchangeExecutor.GetOCOLinks(), nil
+}
+
+func (a *ocoAdapter) PlaceOCO(exchangeName, symbol string, req *order.OCORequest) (*order.OCOLink, error) {
+	rt, err := a.runtime(exchangeName, symbol)
+	if err != nil {
+		return nil, err
+	}
+	req.PriceDecimals = rt.Exchange.GetPriceDecimals()
+	return rt.ExchangeExecutor.PlaceOCO(req)
+}
+
+func (a *ocoAdapter) CancelOCO(exchangeName, symbol, linkID string) error {
+	rt, err := a.runtime(exchangeName, symbol)
+	if err != nil {
+		return err
+	}
+	return rt.ExchangeExecutor.CancelOCO(linkID)
+}
+
+// levelsAdapter 支撑/阻力位检测适配器
+type levelsAdapter struct {
+	manager *SymbolManager
+}
+
+func (a *levelsAdapter) GetLevels(exchangeName, symbol string) (*strategy.LevelSnapshot, []position.PriceZone, error) {
+	rt, ok := a.manager.Get(exchangeName, symbol)
+	if !ok {
+		return nil, nil, fmt.Errorf("交易对 %s:%s 未运行", exchangeName, symbol)
+	}
+	if rt.LevelService == nil {
+		return nil, nil, fmt.Errorf("交易对 %s:%s 未启用支撑/阻力位检测", exchangeName, symbol)
+	}
+	var zones []position.PriceZone
+	if rt.SuperPositionManager != nil {
+		zones = rt.SuperPositionManager.GetDenseZones()
+	}
+	snap, ready := rt.LevelService.Snapshot()
+	if !ready {
+		return nil, zones, nil
+	}
+	return &snap, zones, nil
+}
+
+// orderExpiryAdapter 挂单过期统计适配器
+type orderExpiryAdapter struct {
+	manager *SymbolManager
+}
+
+func (a *orderExpiryAdapter) GetOrderExpiryStats() []web.OrderExpiryStatus {
+	result := make([]web.OrderExpiryStatus, 0)
+	for _, rt := range a.manager.List() {
+		if rt.OrderCleaner == nil {
+			continue
+		}
+		result = append(result, web.OrderExpiryStatus{
+			Exchange:         rt.Config.Exchange,
+			Symbol:           rt.Config.Symbol,
+			MaxAgeMinutes:    int(rt.RuntimeConfig.Trading.OrderExpiry.MaxAge("grid").Minutes()),
+			OrderExpiryStats: rt.OrderCleaner.ExpiryStats(),
+		})
+	}
+	return result
+}
+
+// orderCleanerAdapter 订单清理手动触发适配器
+type orderCleanerAdapter struct {
+	manager *SymbolManager
+}
+
+func (a *orderCleanerAdapter) RunOrderCleanup(exchangeName, symbol string, dryRun bool) (*safety.OrderCleanupReport, error) {
+	rt, ok := a.manager.Get(exchangeName, symbol)
+	if !ok || rt.OrderCleaner == nil {
+		return nil, fmt.Errorf("交易对 %s:%s 未运行", exchangeName, symbol)
+	}
+	return rt.OrderCleaner.Run(safety.CleanupTriggerManual, dryRun), nil
+}
+
+// reconciliationAdapter 手动对账适配器
+type reconciliationAdapter struct {
+	manager *SymbolManager
+}
+
+func (a *reconciliationAdapter) RunReconciliation(exchangeName, symbol string) (*safety.ReconcileReport, error) {
+	rt, ok := a.manager.Get(exchangeName, symbol)
+	if !ok || rt.Reconciler == nil {
+		return nil, fmt.Errorf("交易对 %s:%s 未运行", exchangeName, symbol)
+	}
+	return rt.Reconciler.RunNow()
+}
+
+func (a *reconciliationAdapter) ReconciliationDust(exchangeName, symbol string) (safety.DustStats, bool) {
+	rt, ok := a.manager.Get(exchangeName, symbol)
+	if !ok || rt.Reconciler == nil {
+		return safety.DustStats{}, false
+	}
+	return rt.Reconciler.DustStats(), true
+}
+
+// handleInstanceConflict 处理启动时检测到的重复实例：发布告警，observer 模式下等待另一实例退出后接管
+func handleInstanceConflict(ctx context.Context, cfg *config.Config, symCfg config.SymbolConfig, eventBus *event.EventBus,
+	starter *symbolManagerWebAdapter, conflictErr error) {
+	if eventBus != nil {
+		eventBus.Publish(&event.Event{
+			Type: event.EventTypeInstanceConflict,
+			Data: map[string]interface{}{
+				"exchange": symCfg.Exchange,
+				"symbol":   symCfg.Symbol,
+				"reason":   conflictErr.Error(),
+				"mode":     cfg.InstanceLock.Mode,
+			},
+		})
+	}
+	if cfg.InstanceLock.Mode != "observer" {
+		return
+	}
+
+	logger.Warn("👀 [%s:%s] 进入观察模式，等待另一实例退出后接管", symCfg.Exchange, symCfg.Symbol)
+	go func() {
+		ticker := time.NewTicker(time.Duration(cfg.InstanceLock.StaleSeconds) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := starter.StartSymbol(symCfg.Exchange, symCfg.Symbol)
+				if err == nil {
+					logger.Info("✅ [%s:%s] 另一实例已退出，观察模式结束并接管交易", symCfg.Exchange, symCfg.Symbol)
+					return
+				}
+				if !lock.IsInstanceLocked(err) {
+					logger.Warn("⚠️ [%s:%s] 观察模式接管失败，稍后重试: %v", symCfg.Exchange, symCfg.Symbol, err)
+				}
+			}
+		}
+	}()
+}
+
 // Version 版本号
 var Version = "3.3.3"
 
+// GitCommit 构建时注入的 git 提交（-ldflags "-X main.GitCommit=..."）
+var GitCommit = ""
+
+// BuildTime 构建时注入的构建时间（-ldflags "-X main.BuildTime=..."）
+var BuildTime = ""
+
 // 全局日志存储实例（用于清理任务和 WebSocket 推送）
 var globalLogStorage *storage.LogStorage
 
@@ -103,14 +542,15 @@ func (w *webAuthnLoggerAdapter) Debugf(format string, args ...interface{}) {
 	logger.Debug(format, args...)
 }
 
-// reconciliationStorageAdapter 对账存储适配器
+// reconciliationStorageAdapter 对账存储适配器（对账记录按交易所+交易对隔离）
 type reconciliationStorageAdapter struct {
 	storageService *storage.StorageService
+	exchange       string
 }
 
 func (a *reconciliationStorageAdapter) SaveReconciliationHistory(symbol string, reconcileTime time.Time, localPosition, exchangePosition, positionDiff float64,
 	activeBuyOrders, activeSellOrders int, pendingSellQty, totalBuyQty, totalSellQty, estimatedProfit float64) error {
-	return a.storageService.SaveReconciliationHistoryDirect(symbol, reconcileTime, localPosition, exchangePosition, positionDiff,
+	return a.storageService.SaveReconciliationHistoryDirect(a.exchange, symbol, reconcileTime, localPosition, exchangePosition, positionDiff,
 		activeBuyOrders, activeSellOrders, pendingSellQty, totalBuyQty, totalSellQty, estimatedProfit)
 }
 
@@ -226,21 +666,22 @@ func (a *aiPromptAdapter) UpdatePrompt(module, template, systemPrompt string) er
 
 // reconciliationRestoreAdapter 对账恢复适配器（用于从数据库恢复对账统计）
 type reconciliationRestoreAdapter struct {
-	storage storage.Storage
+	storage  storage.Storage
+	exchange string
 }
 
 func (a *reconciliationRestoreAdapter) GetLatestReconciliationHistory(symbol string) (interface{}, error) {
 	if a.storage == nil {
 		return nil, nil
 	}
-	return a.storage.GetLatestReconciliationHistory(symbol)
+	return a.storage.GetLatestReconciliationHistory(a.exchange, symbol)
 }
 
 func (a *reconciliationRestoreAdapter) GetReconciliationCount(symbol string) (int64, error) {
 	if a.storage == nil {
 		return 0, nil
 	}
-	return a.storage.GetReconciliationCount(symbol)
+	return a.storage.GetReconciliationCount(a.exchange, symbol)
 }
 
 // tradeStorageAdapter 交易存储适配器
@@ -269,6 +710,152 @@ func (a *tradeStorageAdapter) SaveTrade(buyOrderID, sellOrderID int64, exchange,
 	})
 }
 
+// costBasisStorageAdapter 持仓成本存储适配器
+type costBasisStorageAdapter struct {
+	storageService *storage.StorageService
+}
+
+func (a *costBasisStorageAdapter) SaveCostBasis(exchange, symbol string, quantity, totalCost float64) error {
+	st := a.storageService.GetStorage()
+	if st == nil {
+		return nil
+	}
+	avgEntryPrice := 0.0
+	if quantity > 0 {
+		avgEntryPrice = totalCost / quantity
+	}
+	return st.SaveCostBasis(&storage.CostBasis{
+		Exchange:      exchange,
+		Symbol:        symbol,
+		Quantity:      quantity,
+		TotalCost:     totalCost,
+		AvgEntryPrice: avgEntryPrice,
+	})
+}
+
+func (a *costBasisStorageAdapter) GetCostBasis(exchange, symbol string) (float64, float64, bool, error) {
+	st := a.storageService.GetStorage()
+	if st == nil {
+		return 0, 0, false, nil
+	}
+	cb, err := st.GetCostBasis(exchange, symbol)
+	if err != nil || cb == nil {
+		return 0, 0, false, err
+	}
+	return cb.Quantity, cb.TotalCost, true, nil
+}
+
+// anchorStorageAdapter 网格锚点存储适配器（重新居中后保存新锚点）
+type anchorStorageAdapter struct {
+	storageService *storage.StorageService
+}
+
+func (a *anchorStorageAdapter) SaveAnchor(exchange, symbol string, price float64, source string) error {
+	st := a.storageService.GetStorage()
+	if st == nil {
+		return nil
+	}
+	return st.SaveGridAnchor(&storage.GridAnchor{Exchange: exchange, Symbol: symbol, Price: price, Source: source})
+}
+
+// rejectionStorageAdapter 拒单记录存储适配器（异步写入，不阻塞下单）
+type rejectionStorageAdapter struct {
+	storageService *storage.StorageService
+}
+
+func (a *rejectionStorageAdapter) RecordRejection(r *order.OrderRejection) {
+	req := r.Request
+	strategyName := req.StrategyName
+	if strategyName == "" {
+		strategyName = req.StrategyType
+	}
+	if strategyName == "" {
+		// 网格仓位管理器的订单不携带策略名称
+		strategyName = "grid"
+	}
+	payload, _ := json.Marshal(req)
+	a.storageService.Save("order_rejected", &storage.RejectedOrder{
+		Exchange:      r.Exchange,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		ClientOrderID: req.ClientOrderID,
+		Strategy:      strategyName,
+		Category:      r.Category,
+		Reason:        r.Reason,
+		Payload:       string(payload),
+		CreatedAt:     r.Time,
+	})
+}
+
+// orderCleanupStorageAdapter 订单清理记录存储适配器（异步写入，不阻塞清理）
+type orderCleanupStorageAdapter struct {
+	storageService *storage.StorageService
+}
+
+func (a *orderCleanupStorageAdapter) RecordCleanupActions(actions []*storage.OrderCleanupAction) {
+	a.storageService.Save("order_cleanup", actions)
+}
+
+// ocoStorageAdapter OCO 关联订单存储适配器
+type ocoStorageAdapter struct {
+	storageService *storage.StorageService
+}
+
+func (a *ocoStorageAdapter) SaveOCOLink(link *order.OCOLink) error {
+	st := a.storageService.GetStorage()
+	if st == nil {
+		return nil
+	}
+	return st.SaveOCOLink(&storage.OCOLink{
+		LinkID:             link.LinkID,
+		Exchange:           link.Exchange,
+		Symbol:             link.Symbol,
+		Side:               link.Side,
+		Quantity:           link.Quantity,
+		TakeProfitPrice:    link.TakeProfitPrice,
+		StopPrice:          link.StopPrice,
+		TakeProfitOrderID:  link.TakeProfitOrderID,
+		TakeProfitClientID: link.TakeProfitClientID,
+		StopOrderID:        link.StopOrderID,
+		Native:             link.Native,
+		Status:             link.Status,
+		CreatedAt:          link.CreatedAt,
+	})
+}
+
+func (a *ocoStorageAdapter) LoadActiveOCOLinks(exchange, symbol string) ([]*order.OCOLink, error) {
+	st := a.storageService.GetStorage()
+	if st == nil {
+		return nil, nil
+	}
+	links, err := st.QueryActiveOCOLinks(exchange, symbol)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*order.OCOLink, 0, len(links))
+	for _, l := range links {
+		result = append(result, &order.OCOLink{
+			LinkID:             l.LinkID,
+			Exchange:           l.Exchange,
+			Symbol:             l.Symbol,
+			Side:               l.Side,
+			Quantity:           l.Quantity,
+			TakeProfitPrice:    l.TakeProfitPrice,
+			StopPrice:          l.StopPrice,
+			TakeProfitOrderID:  l.TakeProfitOrderID,
+			TakeProfitClientID: l.TakeProfitClientID,
+			StopOrderID:        l.StopOrderID,
+			Native:             l.Native,
+			Status:             l.Status,
+			CreatedAt:          l.CreatedAt,
+			UpdatedAt:          l.UpdatedAt,
+		})
+	}
+	return result, nil
+}
+
 // symbolManagerWebAdapter SymbolManager Web API 适配器
 type symbolManagerWebAdapter struct {
 	manager         *SymbolManager
@@ -325,7 +912,13 @@ func (a *symbolManagerWebAdapter) StartSymbol(exchange, symbol string) error {
 	}
 
 	// 启动 SymbolRuntime（使用最新配置）
-	rt, err := startSymbolRuntime(a.ctx, cfg, *symCfg, a.eventBus, a.storageService, a.distributedLock)
+	return a.launchSymbol(cfg, *symCfg)
+}
+
+// launchSymbol 启动交易对运行时并注册到管理器和 Web API
+func (a *symbolManagerWebAdapter) launchSymbol(cfg *config.Config, symCfg config.SymbolConfig) error {
+	exchange, symbol := symCfg.Exchange, symCfg.Symbol
+	rt, err := startSymbolRuntime(a.ctx, cfg, symCfg, a.eventBus, a.storageService, a.distributedLock)
 	if err != nil {
 		return fmt.Errorf("启动失败: %w", err)
 	}
@@ -419,40 +1012,20 @@ func init() {
 	}
 }
 
-func main() {
-	// 检查版本参数
-	if len(os.Args) > 1 && (os.Args[1] == "-version" || os.Args[1] == "--version") {
-		fmt.Printf("QuantMesh Market Maker\n")
-		fmt.Printf("Version: %s\n", Version)
-		os.Exit(0)
-	}
-
-	// 解析调试参数（-debug / --debug）
-	debugMode := false
-	filteredArgs := []string{os.Args[0]}
-	for _, arg := range os.Args[1:] {
-		switch arg {
-		case "-debug", "--debug":
-			debugMode = true
-		default:
-			filteredArgs = append(filteredArgs, arg)
-		}
-	}
+// runTrading 运行交易系统（run 子命令）
+func runTrading(opts runOptions) {
+	debugMode := opts.debug
+	maintenanceMode := opts.maintenance
 	if debugMode {
 		log.Printf("[INFO] Debug 模式已启用：Gin 将输出全量请求日志")
 	}
-	os.Args = filteredArgs
 
 	// 注意：不再设置 time.Local，避免竞态条件
 	// 时区处理统一使用 utils.GlobalLocation（通过 init() 或 config 设置）
 	// 所有时间操作应使用 utils.ToConfiguredTimezone()、utils.ToUTC()、utils.NowConfiguredTimezone() 等工具函数
 
 	// 1. 最早初始化日志存储（在配置加载之前，使用默认路径）
-	logStoragePath := "./logs.db"
-	if len(os.Args) > 2 && os.Args[1] == "--log-db" {
-		logStoragePath = os.Args[2]
-		os.Args = append(os.Args[:1], os.Args[3:]...)
-	}
+	logStoragePath := opts.logDB
 
 	logStorage, err := storage.NewLogStorage(logStoragePath)
 	if err != nil {
@@ -471,10 +1044,7 @@ func main() {
 	logger.Info("🚀 QuantMesh 做市商系统启动...")
 	logger.Info("📦 版本号: %s", Version)
 
-	configPath := "config.yaml"
-	if len(os.Args) > 1 {
-		configPath = os.Args[1]
-	}
+	configPath := opts.configPath
 
 	// 检查配置文件是否存在
 	var cfg *config.Config
@@ -507,11 +1077,28 @@ func main() {
 			len(cfg.Trading.Symbols) > 0 &&
 			cfg.Trading.Symbols[0].Symbol != ""
 
+		if !configComplete && cfg.Headless.Enabled && !maintenanceMode {
+			logger.Fatalf("❌ headless 模式下没有引导页面，请先在配置文件中补全交易所和交易对配置")
+		}
 		if !configComplete {
 			logger.Info("ℹ️ 配置不完整，仅启动 Web 服务，请通过引导页面完成配置")
 		}
 	}
 
+	lifecycleMode := "trading"
+	switch {
+	case maintenanceMode:
+		lifecycleMode = "maintenance"
+	case !configComplete:
+		lifecycleMode = "setup"
+	case cfg.Standby.Role == "standby":
+		lifecycleMode = "standby"
+	}
+	if maintenanceMode && configComplete {
+		logger.Warn("🔧 维护模式：仅启动 Web 服务，不启动交易")
+		configComplete = false
+	}
+
 	if err := utils.SetLocation(cfg.System.Timezone); err != nil {
 		logger.Warn("⚠️ 加载时区 %s 失败: %v，将使用默认时区 Asia/Shanghai", cfg.System.Timezone, err)
 		utils.SetLocation("Asia/Shanghai")
@@ -527,6 +1114,15 @@ func main() {
 	logLevel := logger.ParseLogLevel(cfg.System.LogLevel)
 	logger.SetLevel(logLevel)
 	logger.Info("日志级别设置为: %s", logLevel.String())
+	if err := logger.SetProfile(cfg.System.LogProfile); err != nil {
+		logger.Warn("⚠️ %v，使用 normal", err)
+	}
+	for _, module := range cfg.System.DebugModules {
+		logger.SetModuleLevel(module, logger.DEBUG)
+	}
+	if logger.GetProfile() != logger.ProfileNormal || len(cfg.System.DebugModules) > 0 {
+		logger.Warn("日志配置档: %s, DEBUG 模块: %v", logger.GetProfile(), cfg.System.DebugModules)
+	}
 
 	// 初始化 i18n 系统
 	logLang := cfg.System.LogLanguage
@@ -549,9 +1145,21 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// 远程日志/指标转发（TLS，端点不可用时缓存到磁盘）
+	if cfg.LogShipping.Enabled {
+		logShipper, err := shipper.New(cfg.LogShipping, cfg.Instance.ID)
+		if err != nil {
+			logger.Error("❌ [日志转发] 初始化失败: %v", err)
+		} else {
+			logger.InitLogShipper(logShipper.Log)
+			logShipper.Start(ctx)
+			logger.Info("✅ [日志转发] 已启用: %s (%s)", cfg.LogShipping.URL, cfg.LogShipping.Type)
+		}
+	}
+
 	// 启动定期日志清理任务（在 ctx 定义之后）
 	if globalLogStorage != nil {
-		go func() {
+		utils.GoSupervised(ctx, "log-cleanup", func(ctx context.Context) {
 			// 每天凌晨2点执行清理
 			ticker := time.NewTicker(24 * time.Hour)
 			defer ticker.Stop()
@@ -611,14 +1219,38 @@ func main() {
 					}
 				}
 			}
-		}()
+		})
 	}
 
 	// 事件总线 & 通知 & 存储
 	logger.Info("🔧 正在初始化事件总线...")
 	eventBus := event.NewEventBus(1000)
+	// 守护协程 panic 时发布事件（协程会自动重启）
+	utils.SetPanicHandler(func(name string, recovered interface{}, stack string) {
+		eventBus.Publish(&event.Event{
+			Type: event.EventTypeGoroutinePanic,
+			Data: map[string]interface{}{
+				"goroutine": name,
+				"panic":     fmt.Sprintf("%v", recovered),
+				"stack":     stack,
+			},
+		})
+	})
+	// 守护协程陷入崩溃循环或停止重启时发布严重事件（可触发告警升级）
+	utils.SetFailureHandler(func(name string, restarts int, stopped bool) {
+		eventBus.Publish(&event.Event{
+			Type:      event.EventTypeWatchdogFailure,
+			Timestamp: time.Now(),
+			Data: map[string]interface{}{
+				"goroutine": name,
+				"restarts":  restarts,
+				"stopped":   stopped,
+			},
+		})
+	})
 	logger.Info("🔧 正在初始化通知服务...")
 	notifier := notify.NewNotificationService(cfg)
+	web.SetNotificationTemplateProvider(&notificationTemplateAdapter{notifier: notifier})
 
 	logger.Info("🔧 正在初始化存储服务...")
 	storageService, err := storage.NewStorageService(cfg, ctx)
@@ -659,7 +1291,9 @@ func main() {
 			ai.GlobalTaskService = taskService
 			
 			// 启动任务处理器
-			go taskProcessor.Start()
+			utils.GoSupervised(ctx, "ai-task-processor", func(ctx context.Context) {
+				taskProcessor.Start()
+			})
 			logger.Info("✅ AI 异步任务系统已启动")
 		}
 	}
@@ -696,8 +1330,7 @@ func main() {
 	// 旧的事件处理器（保留用于存储服务）
 	// 使用 worker pool 模式，限制并发数量，避免 goroutine 泄漏
 	eventWorkerPool := make(chan struct{}, 10) // 最多10个并发 worker
-	go func() {
-		defer close(eventWorkerPool)
+	utils.GoSupervised(ctx, "event-dispatcher", func(ctx context.Context) {
 		for {
 			select {
 			case <-ctx.Done():
@@ -710,13 +1343,14 @@ func main() {
 				eventWorkerPool <- struct{}{}
 				go func(e *event.Event) {
 					defer func() { <-eventWorkerPool }()
+					defer utils.RecoverPanic("event-worker")
 					if storageService != nil {
 						storageService.Save(string(e.Type), e.Data)
 					}
 				}(evt)
 			}
 		}
-	}()
+	})
 
 	// 初始化 Prometheus 系统指标采集器
 	logger.Info("🔧 正在初始化 Prometheus 系统指标采集器...")
@@ -818,7 +1452,7 @@ func main() {
 
 	// Web 服务器
 	var webServer *web.WebServer
-	if cfg.Web.Enabled {
+	if cfg.Web.Enabled || cfg.AdminSocket.Enabled {
 		logger.Info("🌐 开始初始化 Web 服务器...")
 		// 初始化密码管理器
 		passwordManager, err := web.NewPasswordManager("./data")
@@ -830,11 +1464,18 @@ func main() {
 		}
 
 		// 初始化 WebAuthn 管理器
+		// 通过域名或反向代理访问时，rp_id/rp_origin 必须与浏览器地址栏一致，否则注册和登录都会失败
 		rpID := "localhost"
 		rpOrigin := fmt.Sprintf("http://%s:%d", cfg.Web.Host, cfg.Web.Port)
 		if cfg.Web.Host == "0.0.0.0" {
 			rpOrigin = fmt.Sprintf("http://localhost:%d", cfg.Web.Port)
 		}
+		if cfg.Web.WebAuthn.RPID != "" {
+			rpID = cfg.Web.WebAuthn.RPID
+		}
+		if cfg.Web.WebAuthn.RPOrigin != "" {
+			rpOrigin = strings.TrimRight(cfg.Web.WebAuthn.RPOrigin, "/")
+		}
 		webauthnManager, err := web.NewWebAuthnManager(&webAuthnLoggerAdapter{}, "./data", rpID, rpOrigin)
 		if err != nil {
 			logger.Error("❌ 初始化 WebAuthn 管理器失败: %v", err)
@@ -850,7 +1491,8 @@ func main() {
 
 		// 设置版本号
 		web.SetVersion(Version)
-		logger.Info("✅ 版本号已设置: %s", Version)
+		web.SetBuildInfo(GitCommit, BuildTime)
+		logger.Info("✅ 版本号已设置: %s (commit %s)", Version, web.GetBuildInfo().GitCommit)
 
 		// 初始化配置备份管理器
 		backupManager := config.NewBackupManager()
@@ -869,6 +1511,54 @@ func main() {
 			logger.Info("✅ 日志存储提供者已设置")
 		}
 
+		// 设置市场情报数据源（带缓存，后台刷新，快照持久化）
+		dataSources := cfg.AI.Modules.SentimentAnalysis.DataSources
+		dataSourceManager := ai.NewDataSourceManager()
+		if dataSources.Twitter.Enabled {
+			dataSourceManager.SetTwitterCredentials(dataSources.Twitter.APIURL, dataSources.Twitter.BearerToken)
+		}
+		ai.ConfigureOnChain(dataSourceManager, cfg)
+		marketIntel := ai.NewCachedDataSource(dataSourceManager, ai.DataSourceCacheConfig{
+			NewsTTL:         time.Duration(dataSources.News.FetchInterval) * time.Second,
+			FearGreedTTL:    time.Duration(dataSources.FearGreedIndex.FetchInterval) * time.Second,
+			TwitterTTL:      time.Duration(dataSources.Twitter.FetchInterval) * time.Second,
+			TelegramTTL:     time.Duration(dataSources.Telegram.FetchInterval) * time.Second,
+			OnChainTTL:      time.Duration(dataSources.OnChain.FetchInterval) * time.Second,
+			RedditPostLimit: dataSources.SocialMedia.PostLimit,
+			SnapshotPath:    "./data/market_intelligence.json",
+		})
+		marketIntel.Start(ctx)
+		web.SetDataSourceProvider(web.NewDataSourceAdapter(marketIntel,
+			dataSources.News.RSSFeeds, dataSources.FearGreedIndex.APIURL, cfg.AI.Modules.PolymarketSignal.APIURL))
+		logger.Info("✅ 市场情报数据源已设置")
+
+		// AI 模块调度（间隔/cron、启停、并发可通过 /api/ai/modules 或配置热更新调整，无需重启）
+		aiScheduler := ai.NewModuleScheduler(cfg)
+		hotReloader.RegisterCallback(func(oldConfig, newConfig *config.Config, changes []config.ConfigChange) error {
+			return aiScheduler.ApplyConfig(newConfig)
+		})
+		web.SetAIModuleSchedulerProvider(aiScheduler)
+
+		// AI 输出护栏（硬性上限随配置热更新）
+		ai.SetGuardrails(cfg)
+		hotReloader.RegisterCallback(func(oldConfig, newConfig *config.Config, changes []config.ConfigChange) error {
+			ai.SetGuardrails(newConfig)
+			return nil
+		})
+
+		// 情绪分析（新闻、Reddit、X/Twitter、Telegram、恐慌贪婪指数按权重加权）
+		// 始终注册，未启用时不调度，可在运行中启用
+		sentimentAnalyzer := ai.NewSentimentAnalyzer(marketIntel, cfg)
+		aiScheduler.Register(config.AIModuleSentimentAnalysis, sentimentAnalyzer.PerformAnalysis)
+		web.SetAISentimentAnalyzerProvider(sentimentAnalyzer)
+		aiScheduler.Start(ctx)
+		logger.Info("✅ AI 模块调度已启动")
+
+		// 提示词管理（版本历史、回滚、固定版本），需要持久化存储
+		if storageService != nil && storageService.GetStorage() != nil {
+			web.SetAIPromptManagerProvider(ai.NewPromptManager(storageService.GetStorage()))
+		}
+
 		logger.Info("🔧 正在创建 Web 服务器实例...")
 		webServer = web.NewWebServer(cfg)
 		if webServer == nil {
@@ -877,15 +1567,18 @@ func main() {
 			logger.Info("🔧 正在启动 Web 服务器...")
 			if err := webServer.Start(ctx); err != nil {
 				logger.Error("❌ 启动Web服务器失败: %v", err)
-			} else {
+			} else if cfg.Web.Enabled {
 				logger.Info("✅ Web服务器已启动，可通过 http://%s:%d 访问", cfg.Web.Host, cfg.Web.Port)
 				// 等待一下，确保 goroutine 中的日志也能输出
 				time.Sleep(200 * time.Millisecond)
 			}
 		}
-	} else {
+	} else if !cfg.Headless.Enabled {
 		logger.Info("ℹ️ Web 服务未启用（配置中 web.enabled=false）")
 	}
+	if cfg.Headless.Enabled {
+		logger.Info("🖥️ headless 模式：Web 服务已关闭，使用 quantmesh status/pause/resume/cancel/stats 控制实例")
+	}
 
 	symbolManager := NewSymbolManager(cfg)
 
@@ -900,14 +1593,84 @@ func main() {
 	}
 	web.RegisterSymbolManager(symbolManagerAdapter)
 
+	lifecycle := newLifecycleManager(cfg, symbolManager, lifecycleMode)
+	web.SetLifecycleProvider(lifecycle)
+	restarter := newRestartCoordinator(cfg, symbolManager, lifecycle)
+	web.SetRestartProvider(restarter)
+
+	// headless 模式：通过控制目录接收 CLI 命令
+	if cfg.Headless.Enabled {
+		executor := &controlExecutor{cfg: cfg, manager: symbolManager, mode: lifecycleMode}
+		spool, err := newControlSpool(cfg.Headless.ControlDir, time.Duration(cfg.Headless.PollInterval)*time.Millisecond, executor)
+		if err != nil {
+			logger.Error("❌ [控制通道] 启动失败: %v", err)
+		} else {
+			spool.Start(ctx)
+			logger.Info("✅ [控制通道] 已启动: %s", cfg.Headless.ControlDir)
+		}
+	}
+
+	// 多实例总览：hub 展示自身状态，member 定期向 hub 上报
+	if cfg.Fleet.Role != "" {
+		var fleetStore storage.Storage
+		if storageService != nil {
+			fleetStore = storageService.GetStorage()
+		}
+		reporter := newFleetReporter(cfg, symbolManager, fleetStore, lifecycleMode)
+		if cfg.Fleet.Role == "hub" {
+			web.SetFleetProvider(reporter)
+		} else {
+			reporter.Start(ctx)
+			logger.Info("✅ [实例总览] 作为 %s 向 %s 上报", reporter.name, cfg.Fleet.HubURL)
+		}
+	}
+
+	var reportStore storage.Storage
+	if storageService != nil {
+		reportStore = storageService.GetStorage()
+	}
+
+	// 候选交易对适配度评估（借用运行中交易对的交易所客户端）
+	web.SetSymbolScoreProvider(newSymbolScorer(symbolManager, storageService))
+
+	// HODL 基准对比：机器人权益与起算时买入持有的价值对比
+	var benchmark *benchmarkTracker
+	if cfg.Benchmark.Enabled {
+		benchmark = newBenchmarkTracker(cfg, symbolManager, reportStore)
+		benchmark.Start(ctx)
+		web.SetBenchmarkProvider(benchmark)
+	}
+
+	// 每日收盘快照：统计、持仓与槽位状态写入不可覆盖的 JSON 文件
+	if cfg.DailySnapshot.Enabled {
+		snapshotter := newDailySnapshotter(cfg, symbolManager, reportStore, eventBus)
+		snapshotter.benchmark = benchmark
+		snapshotter.Start(ctx)
+	}
+
 	// 只有在配置完整时才启动交易系统
 	var firstRuntime *SymbolRuntime
-	if configComplete {
+	if configComplete && cfg.Standby.Role == "standby" {
+		// 热备模式：不启动交易，镜像主实例状态，主实例失联后逐个交易对接管
+		follower := standby.NewFollower(cfg.Standby)
+		follower.Start(ctx)
+		web.SetStandbyProvider(follower)
+		for _, symCfg := range cfg.Trading.Symbols {
+			go runStandbySymbol(ctx, cfg, symCfg, follower, symbolManagerAdapter)
+		}
+		configComplete = false // 接管前没有运行时，跳过后续数据绑定
+	} else if configComplete {
+		// 协调重启后的恢复令牌（校验通过时各交易对接管保留的挂单）
+		loadResumeToken(cfg)
+
 		// 启动所有交易对
 		for _, symCfg := range cfg.Trading.Symbols {
 			rt, err := startSymbolRuntime(ctx, cfg, symCfg, eventBus, storageService, distributedLock)
 			if err != nil {
 				logger.Error("❌ [%s:%s] 启动失败: %v", symCfg.Exchange, symCfg.Symbol, err)
+				if lock.IsInstanceLocked(err) {
+					handleInstanceConflict(ctx, cfg, symCfg, eventBus, symbolManagerAdapter, err)
+				}
 				continue
 			}
 			symbolManager.Add(rt)
@@ -920,6 +1683,17 @@ func main() {
 			logger.Warn("⚠️ 所有交易对启动失败，但 Web 服务将继续运行")
 			configComplete = false // 标记为不完整，避免后续绑定数据
 		}
+
+		// 热备主实例：向备用实例推送状态快照和事件
+		if cfg.Standby.Role == "primary" {
+			publisher := standby.NewPublisher(cfg.Standby, standbyInstanceID(cfg), &standbySnapshotAdapter{manager: symbolManager})
+			if err := publisher.Start(ctx); err != nil {
+				logger.Error("❌ [热备] 复制通道启动失败: %v", err)
+			} else {
+				eventBus.AddListener(publisher.OnEvent)
+				web.SetStandbyProvider(publisher)
+			}
+		}
 	} else {
 		logger.Info("ℹ️ 配置不完整，跳过交易系统启动，仅运行 Web 服务")
 	}
@@ -953,7 +1727,8 @@ func main() {
 			})
 
 			startTime := time.Now()
-			go func(r *SymbolRuntime, st *web.SystemStatus, started time.Time) {
+			r, st, started := rt, status, startTime
+			utils.GoSupervised(ctx, fmt.Sprintf("status-updater:%s:%s", rt.Config.Exchange, rt.Config.Symbol), func(ctx context.Context) {
 				ticker := time.NewTicker(2 * time.Second)
 				defer ticker.Stop()
 				dbQueryCounter := 0
@@ -989,7 +1764,7 @@ func main() {
 									todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 
 									// 转换为 UTC 时间进行数据库查询，确保时区一致
-									pnlSummary, err := storageService.GetStorage().GetPnLBySymbol(r.Config.Symbol, utils.ToUTC(todayStart), utils.ToUTC(now))
+									pnlSummary, err := storageService.GetStorage().GetPnLBySymbol(strings.ToLower(r.Config.Exchange), r.Config.Symbol, utils.ToUTC(todayStart), utils.ToUTC(now))
 									if err == nil {
 										st.TotalPnL = pnlSummary.TotalPnL
 										st.TotalTrades = pnlSummary.TotalTrades
@@ -1027,7 +1802,7 @@ func main() {
 						}
 					}
 				}
-			}(rt, status, startTime)
+			})
 		}
 
 		if firstRuntime != nil {
@@ -1036,21 +1811,28 @@ func main() {
 			web.SetOrderQuantityConfig(firstRuntime.Config.OrderQuantity)
 		}
 
-		// 资金费率监控（复用旧逻辑，默认主流交易对）
+		// 资金费率监控（配置列表为空时使用默认主流交易对，正在交易的交易对自动纳入）
 		if storageService != nil {
-			symbols := []string{
-				"BTCUSDT", "ETHUSDT", "BNBUSDT", "SOLUSDT", "XRPUSDT",
-				"ADAUSDT", "DOGEUSDT", "DOTUSDT", "MATICUSDT", "AVAXUSDT",
-			}
 			fundingMonitor := monitor.NewFundingMonitor(
 				storageService.GetStorage(),
 				firstRuntime.Exchange,
-				symbols,
-				8,
+				cfg.FundingMonitor.Symbols,
+				cfg.FundingMonitor.IntervalHours,
 			)
+			fundingExchange := firstRuntime.Config.Exchange
+			fundingMonitor.SetActiveSymbolsFunc(func() []string {
+				symbols := make([]string, 0)
+				for _, rt := range symbolManager.List() {
+					if strings.EqualFold(rt.Config.Exchange, fundingExchange) {
+						symbols = append(symbols, rt.Config.Symbol)
+					}
+				}
+				return symbols
+			})
 			fundingMonitor.Start()
 			web.RegisterFundingProvider(firstRuntime.Config.Exchange, firstRuntime.Config.Symbol, fundingMonitor)
 			web.SetFundingMonitorProvider(fundingMonitor)
+			web.SetFundingSymbolsProvider(fundingMonitor)
 
 			// 初始化价差监控
 			if cfg.BasisMonitor.Enabled {
@@ -1065,6 +1847,295 @@ func main() {
 				web.SetBasisMonitorProvider(basisMonitor)
 				logger.Info("✅ 价差监控已启动")
 			}
+
+			// 持仓量与大户多空比监控
+			if cfg.OpenInterestMonitor.Enabled {
+				oiSymbols := cfg.OpenInterestMonitor.Symbols
+				if len(oiSymbols) == 0 {
+					for _, rt := range symbolManager.List() {
+						if strings.EqualFold(rt.Config.Exchange, firstRuntime.Config.Exchange) {
+							oiSymbols = append(oiSymbols, rt.Config.Symbol)
+						}
+					}
+				}
+				oiMonitor, err := monitor.NewOpenInterestMonitor(
+					storageService.GetStorage(),
+					firstRuntime.Exchange,
+					oiSymbols,
+					cfg.OpenInterestMonitor.IntervalMinutes,
+				)
+				if err != nil {
+					logger.Warn("⚠️ 持仓量监控未启动: %v", err)
+				} else {
+					if rule := cfg.OpenInterestMonitor.SpikeRule; rule.Enabled {
+						oiMonitor.SetSpikeRule(monitor.OpenInterestSpikeRule{
+							ChangePercent: rule.ChangePercent,
+							Window:        time.Duration(rule.WindowMinutes) * time.Minute,
+							Cooldown:      time.Duration(rule.CooldownMinutes) * time.Minute,
+						}, eventBus)
+						oiMonitor.SetSpikeHandler(openInterestSpikeHandlers(symbolManager, firstRuntime.Config.Exchange, rule.Action, rule.BuyWindowSize))
+					}
+					oiMonitor.Start(ctx)
+					web.SetOpenInterestProvider(oiMonitor)
+				}
+			}
+
+			// 成交异常检测
+			if cfg.FillAnomaly.Enabled {
+				feeRates := make(map[string]float64, len(cfg.Exchanges))
+				for name, exCfg := range cfg.Exchanges {
+					feeRates[name] = exCfg.FeeRate
+				}
+				fillAnomalyDetector := monitor.NewFillAnomalyDetector(
+					storageService.GetStorage(),
+					eventBus,
+					feeRates,
+					cfg.FillAnomaly.IntervalMinutes,
+					cfg.FillAnomaly.LoopWindowSeconds,
+					cfg.FillAnomaly.LoopCount,
+				)
+				fillAnomalyDetector.Start(ctx)
+				web.SetFillAnomalyProvider(fillAnomalyDetector)
+			}
+
+			// 交易日志：自动记录重要事件与大幅回撤，支持手动笔记
+			if cfg.Journal.Enabled {
+				journal := monitor.NewJournal(
+					storageService.GetStorage(),
+					cfg.Journal.AutoEvents,
+					cfg.Journal.DrawdownAmount,
+					cfg.Journal.CheckInterval,
+				)
+				journal.Start(ctx, eventBus)
+				web.SetJournalProvider(journal)
+			}
+
+			// 盈利目标：达成日/周目标或当日亏损达到上限时通知，可暂停当日新开仓
+			if cfg.Goals.Enabled {
+				goalTracker := monitor.NewGoalTracker(cfg, storageService.GetStorage(), eventBus)
+				goalTracker.SetHaltHandler(func(string) {
+					for _, rt := range symbolManager.List() {
+						if rt.SuperPositionManager == nil {
+							continue
+						}
+						rt.SuperPositionManager.HaltBuying(monitor.GoalBuyHaltSource)
+						rt.SuperPositionManager.CancelAllBuyOrders()
+					}
+				}, func() {
+					for _, rt := range symbolManager.List() {
+						if rt.SuperPositionManager != nil {
+							rt.SuperPositionManager.ResumeBuying(monitor.GoalBuyHaltSource)
+						}
+					}
+				})
+				goalTracker.Start(ctx)
+				web.SetGoalProvider(goalTracker)
+			}
+
+			// 组合优化：记录各策略每日收益，比较不同资金分配方案，供再平衡建议使用
+			if optCfg := cfg.Strategies.CapitalAllocation.Optimizer; optCfg.Enabled {
+				portfolioOptimizer := strategy.NewPortfolioOptimizer(
+					storageService.GetStorage(),
+					func() []strategy.PortfolioSource {
+						var sources []strategy.PortfolioSource
+						for _, rt := range symbolManager.List() {
+							if rt.StrategyManager != nil {
+								sources = append(sources, strategy.PortfolioSource{
+									Exchange: rt.Config.Exchange,
+									Symbol:   rt.Config.Symbol,
+									Manager:  rt.StrategyManager,
+								})
+							}
+						}
+						return sources
+					},
+					time.Duration(optCfg.CheckInterval)*time.Second,
+					optCfg.LookbackDays,
+					optCfg.MinDays,
+				)
+				portfolioOptimizer.Start(ctx)
+				web.SetPortfolioOptimizerProvider(portfolioOptimizer)
+			}
+		}
+
+		// 按时段触发的定时任务（低流动性时段降杠杆等）
+		taskScheduler := scheduler.New(time.Duration(cfg.Scheduler.CheckInterval) * time.Second)
+		if cfg.Deleverage.Enabled {
+			if job, err := deleverageJob(cfg, symbolManager); err != nil {
+				logger.Error("❌ 降杠杆时段配置无效: %v", err)
+			} else {
+				taskScheduler.Add(job)
+			}
+		}
+		if len(taskScheduler.Status()) > 0 {
+			taskScheduler.Start(ctx)
+		}
+		web.SetSchedulerProvider(taskScheduler)
+
+		// 交易对元数据注册表：全量加载支持的交易所并定期刷新，检测新上线交易对
+		registry := exchange.GetSymbolRegistry()
+		registry.OnNewListing(func(meta *exchange.SymbolMetadata) {
+			eventBus.Publish(&event.Event{
+				Type: event.EventTypeSymbolListed,
+				Data: map[string]interface{}{
+					"exchange":      meta.Exchange,
+					"symbol":        meta.Symbol,
+					"base_asset":    meta.BaseAsset,
+					"quote_asset":   meta.QuoteAsset,
+					"contract_type": meta.ContractType,
+					"message":       fmt.Sprintf("%s 新上线交易对 %s", meta.Exchange, meta.Symbol),
+				},
+			})
+		})
+		// 运行中的交易对被下架/改名时暂停交易并告警，由人工通过 /api/symbols/migrate 完成迁移
+		registry.OnStatusChange(func(change *exchange.SymbolStatusChange) {
+			if !change.Delisting() {
+				return
+			}
+			for _, rt := range symbolManager.List() {
+				if !strings.EqualFold(rt.Config.Exchange, change.Exchange) || rt.Config.Symbol != change.Symbol {
+					continue
+				}
+				var heldQty float64
+				if rt.SuperPositionManager != nil {
+					heldQty, _, _ = rt.SuperPositionManager.GetCostBasis()
+					rt.SuperPositionManager.Pause()
+				}
+				logger.Error("🚫 [%s:%s] 交易对下架/变更 (%s, %s -> %s)，已暂停交易，持仓数量: %.6f",
+					rt.Config.Exchange, rt.Config.Symbol, change.Kind, change.OldStatus, change.NewStatus, heldQty)
+				message := fmt.Sprintf("%s %s 状态变更为 %s，已暂停交易，请平仓并迁移配置", change.Exchange, change.Symbol, change.NewStatus)
+				if !change.DeliveryDate.IsZero() && change.Kind == exchange.SymbolChangeDelistScheduled {
+					message = fmt.Sprintf("%s %s 将于 %s 下架，已暂停交易，请平仓并迁移配置",
+						change.Exchange, change.Symbol, change.DeliveryDate.Format("2006-01-02 15:04"))
+				}
+				if change.RenamedTo != "" {
+					message += fmt.Sprintf("（疑似改名为 %s）", change.RenamedTo)
+				}
+				eventBus.Publish(&event.Event{
+					Type: event.EventTypeSymbolDelisting,
+					Data: map[string]interface{}{
+						"exchange":      change.Exchange,
+						"symbol":        change.Symbol,
+						"kind":          change.Kind,
+						"old_status":    change.OldStatus,
+						"new_status":    change.NewStatus,
+						"delivery_date": change.DeliveryDate,
+						"renamed_to":    change.RenamedTo,
+						"held_qty":      heldQty,
+						"message":       message,
+					},
+				})
+			}
+		})
+		// 交易所上调最小名义价值：每单金额不再满足要求时按配置告警 / 自动调大每单金额 / 暂停交易对
+		registry.OnFilterChange(func(change *exchange.SymbolFilterChange) {
+			if !change.MinNotionalRaised() {
+				return
+			}
+			policy := cfg.Trading.MinNotionalChange
+			for _, rt := range symbolManager.List() {
+				if !strings.EqualFold(rt.Config.Exchange, change.Exchange) || rt.Config.Symbol != change.Symbol || rt.RuntimeConfig == nil {
+					continue
+				}
+				orderQty := rt.RuntimeConfig.Trading.OrderQuantity
+				if orderQty >= change.NewMinNotional {
+					continue
+				}
+				message := fmt.Sprintf("%s %s 最小名义价值由 %g 上调至 %g，每单金额 %g 已不满足要求",
+					change.Exchange, change.Symbol, change.OldMinNotional, change.NewMinNotional, orderQty)
+				newQty := orderQty
+				switch policy.Action {
+				case config.MinNotionalActionAdjust:
+					_, newQty = rt.RaiseOrderQuantity(change.NewMinNotional, policy.BufferPercent)
+					message += fmt.Sprintf("，已自动调整为 %g", newQty)
+				case config.MinNotionalActionPause:
+					if rt.SuperPositionManager != nil {
+						rt.SuperPositionManager.Pause()
+					}
+					message += "，已暂停交易，请调整 order_quantity 后恢复"
+				default:
+					message += "，请调整 order_quantity"
+				}
+				logger.Warn("⚠️ [%s:%s] %s", rt.Config.Exchange, rt.Config.Symbol, message)
+				eventBus.Publish(&event.Event{
+					Type: event.EventTypeMinNotionalChanged,
+					Data: map[string]interface{}{
+						"exchange":         change.Exchange,
+						"symbol":           change.Symbol,
+						"old_min_notional": change.OldMinNotional,
+						"new_min_notional": change.NewMinNotional,
+						"order_quantity":   orderQty,
+						"new_quantity":     newQty,
+						"action":           policy.Action,
+						"message":          message,
+					},
+				})
+			}
+		})
+		refreshedExchanges := make(map[string]bool)
+		for _, rt := range symbolManager.List() {
+			if !refreshedExchanges[rt.Config.Exchange] {
+				refreshedExchanges[rt.Config.Exchange] = true
+				registry.StartAutoRefresh(ctx, rt.Exchange, time.Hour)
+			}
+			if meta, ok := registry.Lookup(rt.Exchange.GetName(), rt.Config.Symbol); ok && meta.Status != "" && meta.Status != "TRADING" {
+				logger.Warn("⚠️ [%s:%s] 交易所返回的交易对状态为 %s，可能已暂停交易或即将下架", rt.Config.Exchange, rt.Config.Symbol, meta.Status)
+			}
+			if meta, ok := registry.Lookup(rt.Exchange.GetName(), rt.Config.Symbol); ok && meta.MinNotional > rt.Config.OrderQuantity {
+				logger.Warn("⚠️ [%s:%s] 每单金额 %g 低于交易所最小名义价值 %g，订单可能被拒绝", rt.Config.Exchange, rt.Config.Symbol, rt.Config.OrderQuantity, meta.MinNotional)
+			}
+		}
+
+		// 交易所维护窗口监控（每个交易所一个实例，维护期间暂停该交易所全部交易对）
+		if cfg.Maintenance.Enabled {
+			maintenanceMonitors := make(map[string]*monitor.MaintenanceMonitor)
+			for _, rt := range symbolManager.List() {
+				exName := rt.Config.Exchange
+				mm, ok := maintenanceMonitors[exName]
+				if !ok {
+					var windows []monitor.MaintenanceWindow
+					for _, w := range cfg.Maintenance.Windows {
+						if !strings.EqualFold(w.Exchange, exName) {
+							continue
+						}
+						start, end, _ := w.Parse()
+						windows = append(windows, monitor.MaintenanceWindow{
+							Exchange: exName, Title: w.Title, Start: start, End: end, Source: "config",
+						})
+					}
+					mm = monitor.NewMaintenanceMonitor(exName, windows,
+						time.Duration(cfg.Maintenance.CheckInterval)*time.Second,
+						time.Duration(cfg.Maintenance.PreWindowMinutes)*time.Minute, eventBus)
+					ex, symbol := rt.Exchange, rt.Config.Symbol
+					mm.SetHealthCheck(func(ctx context.Context) error {
+						_, err := ex.GetLatestPrice(ctx, symbol)
+						return err
+					})
+					maintenanceMonitors[exName] = mm
+				}
+				mm.AddPauser(rt.Config.Symbol, rt.SuperPositionManager)
+			}
+			for _, mm := range maintenanceMonitors {
+				mm.Start(ctx)
+			}
+		}
+
+		// 利润金库（每个交易所一个实例，周期盈利超过阈值时划转到现货/资金账户）
+		if cfg.ProfitVault.Enabled && storageService != nil {
+			vaultExchanges := make(map[string]bool)
+			for _, rt := range symbolManager.List() {
+				exName := rt.Exchange.GetName()
+				if vaultExchanges[exName] {
+					continue
+				}
+				vaultExchanges[exName] = true
+				vault := monitor.NewProfitVault(cfg, storageService.GetStorage(), rt.Exchange)
+				if vault == nil {
+					logger.Warn("⚠️ [利润金库] 交易所 %s 不支持账户划转，已跳过", exName)
+					continue
+				}
+				vault.Start(ctx)
+			}
 		}
 
 		// 设置系统监控数据提供者
@@ -1086,6 +2157,27 @@ func main() {
 			cfg:     cfg,
 		})
 		logger.Info("✅ 资金数据源提供者已设置")
+		web.SetRiskProfileProvider(&riskProfileAdapter{manager: symbolManager, cfg: cfg})
+		web.SetPositionEditorProvider(&positionEditorAdapter{manager: symbolManager})
+		web.SetGridRecenterProvider(&gridRecenterAdapter{manager: symbolManager})
+		web.SetLevelsProvider(&levelsAdapter{manager: symbolManager})
+		web.SetOrderExpiryProvider(&orderExpiryAdapter{manager: symbolManager})
+		web.SetOrderCleanerProvider(&orderCleanerAdapter{manager: symbolManager})
+		web.SetReconciliationProvider(&reconciliationAdapter{manager: symbolManager})
+		web.SetStrategyBreakerProvider(&strategyBreakerAdapter{manager: symbolManager})
+		web.SetOCOProvider(&ocoAdapter{manager: symbolManager})
+		if cfg.Trading.MarginCheck.Enabled {
+			web.SetMarginModelProvider(&marginModelAdapter{manager: symbolManager})
+		}
+		if cfg.Trading.Hedge.Enabled {
+			web.SetHedgeProvider(&hedgeAdapter{manager: symbolManager})
+		}
+		if cfg.IncomeSync.Enabled {
+			web.SetIncomeReconciliationProvider(&incomeReconciliationAdapter{manager: symbolManager})
+		}
+		if cfg.KlineBackfill.Enabled {
+			web.SetKlineHistoryProvider(&klineHistoryAdapter{manager: symbolManager})
+		}
 
 		logger.Info("✅ 所有交易对已初始化，进入运行状态")
 	} else if webServer != nil {
@@ -1118,43 +2210,45 @@ func main() {
 	// 等待退出信号（SIGINT 或 SIGTERM）
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	<-sigChan
+	stopReason := "收到退出信号"
+	select {
+	case <-sigChan:
+	case <-restarter.Requested():
+		stopReason = "协调重启"
+	}
 
-	logger.Info("🛑 收到退出信号，开始优雅关闭...")
+	logger.Info("🛑 %s，开始优雅关闭...", stopReason)
 
 	// 发布系统停止事件
 	if eventBus != nil {
 		eventBus.Publish(&event.Event{
 			Type: event.EventTypeSystemStop,
 			Data: map[string]interface{}{
-				"reason": "收到退出信号",
+				"reason": stopReason,
 			},
 		})
 	}
 
-	// 🔥 第一优先级：撤销各交易对的订单（仅在配置完整时）
-	if configComplete {
-		if cfg.System.CancelOnExit {
-			for _, rt := range symbolManager.List() {
-				logger.Info("🔄 [%s:%s] 正在撤销所有订单...", rt.Config.Exchange, rt.Config.Symbol)
-				cancelCtx, cancelTimeout := context.WithTimeout(context.Background(), 10*time.Second)
-				if err := rt.Exchange.CancelAllOrders(cancelCtx, rt.Config.Symbol); err != nil {
-					logger.Error("❌ [%s:%s] 撤销订单失败: %v", rt.Config.Exchange, rt.Config.Symbol, err)
-				} else {
-					logger.Info("✅ [%s:%s] 已撤销所有订单", rt.Config.Exchange, rt.Config.Symbol)
-				}
-				cancelTimeout()
-			}
+	// 🔥 第一优先级：暂停所有交易对并并发撤单（在 fast_cancel_seconds 内完成，适配容器停止宽限期）
+	// 已通过 PreStop 钩子撤单时不会重复执行；热备接管的交易对同样需要撤单
+	lifecycle.FastCancel()
+
+	if configComplete || len(symbolManager.List()) > 0 {
+
+		// 🔥 平仓（退出方式为 flatten 的策略）
+		for _, rt := range symbolManager.List() {
+			closeCtx, closeTimeout := context.WithTimeout(context.Background(), 30*time.Second)
+			flattenForShutdown(closeCtx, cfg, rt)
+			closeTimeout()
 		}
 
-		// 🔥 平仓（可选）
-		if cfg.System.ClosePositionsOnExit {
-			for _, rt := range symbolManager.List() {
-				logger.Info("🔄 [%s:%s] 正在平掉所有持仓...", rt.Config.Exchange, rt.Config.Symbol)
-				closeCtx, closeTimeout := context.WithTimeout(context.Background(), 30*time.Second)
-				closeAllPositions(closeCtx, rt.Exchange, rt.Config.Symbol, rt.PriceMonitor)
-				closeTimeout()
+		// 协调重启：撤单、平仓后保存恢复令牌，新进程据此接管保留的挂单
+		if restarter.isRequested() {
+			tokenCtx, tokenTimeout := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := restarter.saveToken(tokenCtx); err != nil {
+				logger.Error("❌ [协调重启] 保存恢复令牌失败，新进程将按正常流程启动: %v", err)
 			}
+			tokenTimeout()
 		}
 
 		// 🔥 停止所有交易对组件
@@ -1206,6 +2300,9 @@ func main() {
 	}
 
 	logger.Info("✅ 系统已安全退出 QuantMesh")
+	if restarter.isRequested() {
+		restartExitCode = cfg.System.Restart.ExitCode
+	}
 }
 
 // loggerAdapter 适配 logger 到 WebAuthnLogger 接口
@@ -1295,6 +2392,7 @@ func (a *exchangeProviderAdapter) GetHistoricalKlines(ctx context.Context, symbo
 type exchangeExecutorAdapter struct {
 	executor *order.ExchangeOrderExecutor
 	eventBus *event.EventBus
+	exchange string
 	symbol   string
 }
 
@@ -1307,6 +2405,7 @@ func (a *exchangeExecutorAdapter) PlaceOrder(req *position.OrderRequest) (*posit
 		PriceDecimals: req.PriceDecimals,
 		ReduceOnly:    req.ReduceOnly,
 		PostOnly:      req.PostOnly,      // 传递 PostOnly 参数
+		StrictPost:    req.StrictPost,
 		ClientOrderID: req.ClientOrderID, // 传递 ClientOrderID
 	}
 	ord, err := a.executor.PlaceOrder(orderReq)
@@ -1321,6 +2420,7 @@ func (a *exchangeExecutorAdapter) PlaceOrder(req *position.OrderRequest) (*posit
 			Data: map[string]interface{}{
 				"order_id":        ord.OrderID,
 				"client_order_id": ord.ClientOrderID,
+				"exchange":        a.exchange,
 				"symbol":          ord.Symbol,
 				"side":            ord.Side,
 				"price":           ord.Price,
@@ -1359,6 +2459,7 @@ func (a *exchangeExecutorAdapter) BatchPlaceOrdersWithDetails(orders []*position
 			PriceDecimals: req.PriceDecimals,
 			ReduceOnly:    req.ReduceOnly,
 			PostOnly:      req.PostOnly,      // 传递 PostOnly 参数
+			StrictPost:    req.StrictPost,
 			ClientOrderID: req.ClientOrderID, // 传递 ClientOrderID
 		}
 	}
@@ -1389,6 +2490,7 @@ func (a *exchangeExecutorAdapter) BatchPlaceOrdersWithDetails(orders []*position
 				Data: map[string]interface{}{
 					"order_id":        ord.OrderID,
 					"client_order_id": ord.ClientOrderID,
+					"exchange":        a.exchange,
 					"symbol":          ord.Symbol,
 					"side":            ord.Side,
 					"price":           ord.Price,
@@ -152,6 +152,31 @@ var (
 		[]string{"exchange", "symbol", "side"},
 	)
 
+	// 网格状态指标（定期状态输出的同一份数据，quiet 日志配置档下只写指标）
+	gridFilledSlots = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "quantmesh_grid_filled_slots",
+			Help: "Number of grid slots holding a position",
+		},
+		[]string{"exchange", "symbol"},
+	)
+
+	gridCumulativeQty = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "quantmesh_grid_cumulative_qty",
+			Help: "Cumulative filled quantity of grid orders since start",
+		},
+		[]string{"exchange", "symbol", "side"},
+	)
+
+	gridEstimatedProfit = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "quantmesh_grid_estimated_profit",
+			Help: "Estimated grid profit (cumulative sell quantity times price interval)",
+		},
+		[]string{"exchange", "symbol"},
+	)
+
 	// 系统指标
 	goroutineCount = promauto.NewGauge(
 		prometheus.GaugeOpts{
@@ -217,6 +242,14 @@ var (
 		[]string{"exchange", "stream_type"},
 	)
 
+	exchangeMaintenance = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "quantmesh_exchange_maintenance",
+			Help: "Exchange maintenance window status (0=normal, 1=maintenance)",
+		},
+		[]string{"exchange"},
+	)
+
 	websocketReconnectCount = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "quantmesh_websocket_reconnect_count_total",
@@ -225,6 +258,31 @@ var (
 		[]string{"exchange", "stream_type"},
 	)
 
+	// 用户数据流 listenKey 生命周期指标
+	listenKeyKeepAliveTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "quantmesh_listen_key_keepalive_total",
+			Help: "Total number of user data stream listenKey keepalive attempts",
+		},
+		[]string{"exchange", "status"},
+	)
+
+	listenKeyKeepAliveFailures = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "quantmesh_listen_key_keepalive_consecutive_failures",
+			Help: "Consecutive listenKey keepalive failures (resets on success or renewal)",
+		},
+		[]string{"exchange"},
+	)
+
+	listenKeyRenewTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "quantmesh_listen_key_renew_total",
+			Help: "Total number of listenKey renewals followed by a user data stream re-subscribe",
+		},
+		[]string{"exchange", "reason"},
+	)
+
 	apiCallTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "quantmesh_api_call_total",
@@ -267,6 +325,14 @@ var (
 		[]string{"exchange", "symbol"},
 	)
 
+	priceConflatedCount = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "quantmesh_price_conflated_total",
+			Help: "Total number of price ticks superseded by a newer price before being processed",
+		},
+		[]string{"exchange", "symbol", "stage"},
+	)
+
 	// 对账指标
 	reconciliationCount = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -283,6 +349,15 @@ var (
 		},
 		[]string{"exchange", "symbol", "type"},
 	)
+
+	// 订单过期指标
+	orderExpiredTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "quantmesh_order_expired_total",
+			Help: "Total number of resting orders cancelled for exceeding the max order age",
+		},
+		[]string{"exchange", "symbol", "side"},
+	)
 )
 
 // PrometheusMetrics Prometheus 指标收集器
@@ -387,6 +462,14 @@ func (pm *PrometheusMetrics) SetActiveOrdersCount(exchange, symbol, side string,
 	activeOrdersCount.WithLabelValues(exchange, symbol, side).Set(float64(count))
 }
 
+// SetGridStatus 设置网格状态（持仓槽位数、累计买卖数量、预计盈利）
+func (pm *PrometheusMetrics) SetGridStatus(exchange, symbol string, filledSlots int, totalBuyQty, totalSellQty, estimatedProfit float64) {
+	gridFilledSlots.WithLabelValues(exchange, symbol).Set(float64(filledSlots))
+	gridCumulativeQty.WithLabelValues(exchange, symbol, "BUY").Set(totalBuyQty)
+	gridCumulativeQty.WithLabelValues(exchange, symbol, "SELL").Set(totalSellQty)
+	gridEstimatedProfit.WithLabelValues(exchange, symbol).Set(estimatedProfit)
+}
+
 // 系统相关指标记录
 
 // SetGoroutineCount 设置 Goroutine 数量
@@ -420,11 +503,36 @@ func (pm *PrometheusMetrics) SetWebSocketStatus(exchange, streamType string, con
 	websocketConnected.WithLabelValues(exchange, streamType).Set(value)
 }
 
+// SetExchangeMaintenance 设置交易所维护状态
+func (pm *PrometheusMetrics) SetExchangeMaintenance(exchange string, active bool) {
+	value := 0.0
+	if active {
+		value = 1.0
+	}
+	exchangeMaintenance.WithLabelValues(exchange).Set(value)
+}
+
 // RecordWebSocketReconnect 记录 WebSocket 重连
 func (pm *PrometheusMetrics) RecordWebSocketReconnect(exchange, streamType string) {
 	websocketReconnectCount.WithLabelValues(exchange, streamType).Inc()
 }
 
+// RecordListenKeyKeepAlive 记录一次 listenKey 保活及当前连续失败次数
+func (pm *PrometheusMetrics) RecordListenKeyKeepAlive(exchange string, success bool, consecutiveFailures int) {
+	status := "success"
+	if !success {
+		status = "failed"
+	}
+	listenKeyKeepAliveTotal.WithLabelValues(exchange, status).Inc()
+	listenKeyKeepAliveFailures.WithLabelValues(exchange).Set(float64(consecutiveFailures))
+}
+
+// RecordListenKeyRenewal 记录一次 listenKey 更换（reason: expired / keepalive_failed / stale）
+func (pm *PrometheusMetrics) RecordListenKeyRenewal(exchange, reason string) {
+	listenKeyRenewTotal.WithLabelValues(exchange, reason).Inc()
+	listenKeyKeepAliveFailures.WithLabelValues(exchange).Set(0)
+}
+
 // RecordAPICall 记录 API 调用
 func (pm *PrometheusMetrics) RecordAPICall(exchange, endpoint, status string, duration time.Duration) {
 	apiCallTotal.WithLabelValues(exchange, endpoint, status).Inc()
@@ -448,6 +556,15 @@ func (pm *PrometheusMetrics) RecordPriceUpdate(exchange, symbol string) {
 	priceUpdateCount.WithLabelValues(exchange, symbol).Inc()
 }
 
+// RecordPriceConflated 记录被合并（被更新价格覆盖而未处理）的价格数量
+// stage: monitor（价格监控节流）、subscriber（订阅通道积压）、adjust（调单循环合并）
+func (pm *PrometheusMetrics) RecordPriceConflated(exchange, symbol, stage string, count int) {
+	if count <= 0 {
+		return
+	}
+	priceConflatedCount.WithLabelValues(exchange, symbol, stage).Add(float64(count))
+}
+
 // 对账相关指标记录
 
 // RecordReconciliation 记录对账
@@ -460,6 +577,14 @@ func (pm *PrometheusMetrics) RecordReconciliationDiff(exchange, symbol, diffType
 	reconciliationDiffFound.WithLabelValues(exchange, symbol, diffType).Inc()
 }
 
+// RecordOrderExpired 记录因挂单超时被撤销的订单
+func (pm *PrometheusMetrics) RecordOrderExpired(exchange, symbol, side string, count int) {
+	if count <= 0 {
+		return
+	}
+	orderExpiredTotal.WithLabelValues(exchange, symbol, side).Add(float64(count))
+}
+
 // 分布式锁相关指标记录
 
 // RecordLockAcquire 记录锁获取
@@ -0,0 +1,182 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"quantmesh/event"
+	"quantmesh/exchange"
+	"quantmesh/logger"
+	"quantmesh/utils"
+)
+
+const (
+	// apiKeyCheckInterval API 密钥权限巡检间隔
+	apiKeyCheckInterval = 10 * time.Minute
+	// apiKeyExpiryWarning 距过期不足该时长时开始告警
+	apiKeyExpiryWarning = 7 * 24 * time.Hour
+	// apiKeyExpiryNotifyInterval 即将过期告警的重复间隔
+	apiKeyExpiryNotifyInterval = 24 * time.Hour
+)
+
+// TradingPauser 交易暂停控制（由仓位管理器实现）
+type TradingPauser interface {
+	Pause()
+	Resume()
+	IsPaused() bool
+}
+
+// APIKeyWatchdog API 密钥权限巡检
+// 定期调用 CheckAPIPermissions，密钥失去交易权限或过期时主动暂停交易并告警，
+// 避免运行中途被交易所以 -2015 等错误拒绝下单；权限恢复后自动恢复交易
+type APIKeyWatchdog struct {
+	checker      exchange.PermissionChecker
+	exchangeName string
+	symbol       string
+	pauser       TradingPauser
+	eventBus     *event.EventBus
+	now          func() time.Time
+
+	mu               sync.Mutex
+	last             *exchange.APIPermissions
+	permissionLost   bool
+	pausedByWatchdog bool // 仅恢复由巡检触发的暂停，不干预其他原因的暂停
+	lastExpiryNotice time.Time
+}
+
+// NewAPIKeyWatchdog 创建 API 密钥巡检，交易所不支持权限检测时返回 nil
+func NewAPIKeyWatchdog(ex exchange.IExchange, symbol string, pauser TradingPauser, eventBus *event.EventBus) *APIKeyWatchdog {
+	checker, ok := exchange.As[exchange.PermissionChecker](ex)
+	if !ok {
+		return nil
+	}
+	return &APIKeyWatchdog{
+		checker:      checker,
+		exchangeName: ex.GetName(),
+		symbol:       symbol,
+		pauser:       pauser,
+		eventBus:     eventBus,
+		now:          time.Now,
+	}
+}
+
+// Start 启动定期巡检
+func (w *APIKeyWatchdog) Start(ctx context.Context) {
+	logger.Info("🔐 [%s] API 密钥巡检已启动 (间隔: %v)", w.symbol, apiKeyCheckInterval)
+
+	utils.GoSupervised(ctx, fmt.Sprintf("api-key-watchdog:%s:%s", w.exchangeName, w.symbol), func(ctx context.Context) {
+		w.check(ctx)
+
+		ticker := time.NewTicker(apiKeyCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.check(ctx)
+			}
+		}
+	})
+}
+
+// check 执行一次权限检测
+func (w *APIKeyWatchdog) check(ctx context.Context) {
+	reqCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	perms, err := w.checker.CheckAPIPermissions(reqCtx)
+	cancel()
+	if err != nil {
+		// 检测接口本身失败（网络、限流）不代表密钥失效，下次再查
+		logger.Warn("⚠️ [%s] API 密钥巡检失败: %v", w.symbol, err)
+		return
+	}
+	w.evaluate(perms)
+}
+
+// evaluate 与上次检测结果对比，处理权限变化
+func (w *APIKeyWatchdog) evaluate(perms *exchange.APIPermissions) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	prev := w.last
+	w.last = perms
+	now := w.now()
+
+	expired := perms.ExpireTime > 0 && now.Unix() >= perms.ExpireTime
+	usable := perms.CanTrade && !expired
+
+	if !usable {
+		reason := "API 密钥已失去交易权限"
+		if expired {
+			reason = fmt.Sprintf("API 密钥交易权限已于 %s 过期", time.Unix(perms.ExpireTime, 0).Format("2006-01-02 15:04"))
+		} else if !perms.CanRead {
+			reason = "API 密钥被交易所拒绝（密钥失效或 IP 不在白名单）"
+		}
+		if !w.permissionLost {
+			w.permissionLost = true
+			if !w.pauser.IsPaused() {
+				w.pauser.Pause()
+				w.pausedByWatchdog = true
+			}
+			logger.Error("🚨 [%s] %s，已暂停交易，请检查交易所 API 设置", w.symbol, reason)
+			w.publish(event.EventTypeAPIKeyPermissionLost, reason+"，已暂停交易", perms)
+		}
+		return
+	}
+
+	if w.permissionLost {
+		w.permissionLost = false
+		if w.pausedByWatchdog {
+			w.pausedByWatchdog = false
+			w.pauser.Resume()
+		}
+		logger.Info("✅ [%s] API 密钥交易权限已恢复，恢复交易", w.symbol)
+		w.publish(event.EventTypeAPIKeyChanged, "API 密钥交易权限已恢复，已恢复交易", perms)
+	}
+
+	if prev != nil && prev.IPRestricted != perms.IPRestricted {
+		msg := "API 密钥已启用 IP 白名单"
+		if !perms.IPRestricted {
+			msg = "API 密钥的 IP 白名单已被取消"
+		}
+		logger.Warn("⚠️ [%s] %s", w.symbol, msg)
+		w.publish(event.EventTypeAPIKeyChanged, msg, perms)
+	}
+	if prev != nil && !prev.CanWithdraw && perms.CanWithdraw {
+		msg := "API 密钥被开启了提现权限，存在资金安全风险"
+		logger.Error("🚨 [%s] %s", w.symbol, msg)
+		w.publish(event.EventTypeAPIKeyChanged, msg, perms)
+	}
+
+	if perms.ExpireTime > 0 {
+		remaining := time.Unix(perms.ExpireTime, 0).Sub(now)
+		if remaining <= apiKeyExpiryWarning && now.Sub(w.lastExpiryNotice) >= apiKeyExpiryNotifyInterval {
+			w.lastExpiryNotice = now
+			msg := fmt.Sprintf("API 密钥交易权限将在 %.1f 天后过期 (%s)，请及时续期",
+				remaining.Hours()/24, time.Unix(perms.ExpireTime, 0).Format("2006-01-02 15:04"))
+			logger.Warn("⚠️ [%s] %s", w.symbol, msg)
+			w.publish(event.EventTypeAPIKeyExpiring, msg, perms)
+		}
+	}
+}
+
+func (w *APIKeyWatchdog) publish(eventType event.EventType, message string, perms *exchange.APIPermissions) {
+	if w.eventBus == nil {
+		return
+	}
+	w.eventBus.Publish(&event.Event{
+		Type:      eventType,
+		Timestamp: w.now(),
+		Data: map[string]interface{}{
+			"exchange":      w.exchangeName,
+			"symbol":        w.symbol,
+			"message":       message,
+			"can_trade":     perms.CanTrade,
+			"can_withdraw":  perms.CanWithdraw,
+			"ip_restricted": perms.IPRestricted,
+			"expire_time":   perms.ExpireTime,
+		},
+	})
+}
@@ -0,0 +1,148 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"quantmesh/event"
+	"quantmesh/exchange"
+)
+
+type fakePauser struct {
+	paused          bool
+	pauses, resumes int
+}
+
+func (p *fakePauser) Pause()         { p.paused = true; p.pauses++ }
+func (p *fakePauser) Resume()        { p.paused = false; p.resumes++ }
+func (p *fakePauser) IsPaused() bool { return p.paused }
+
+// newTestWatchdog 创建使用固定时钟的巡检，返回收到的事件
+func newTestWatchdog(pauser TradingPauser, now *time.Time) (*APIKeyWatchdog, *[]*event.Event) {
+	bus := event.NewEventBus(100)
+	var events []*event.Event
+	bus.AddListener(func(e *event.Event) { events = append(events, e) })
+	return &APIKeyWatchdog{
+		exchangeName: "stub",
+		symbol:       "BTCUSDT",
+		pauser:       pauser,
+		eventBus:     bus,
+		now:          func() time.Time { return *now },
+	}, &events
+}
+
+func eventTypes(events []*event.Event) []event.EventType {
+	types := make([]event.EventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+func TestAPIKeyWatchdogPauseAndResume(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	pauser := &fakePauser{}
+	w, events := newTestWatchdog(pauser, &now)
+
+	w.evaluate(&exchange.APIPermissions{CanRead: true, CanTrade: true})
+	if pauser.paused || len(*events) != 0 {
+		t.Fatalf("usable key should not pause or alert: paused=%v events=%v", pauser.paused, eventTypes(*events))
+	}
+
+	// 失去交易权限：暂停一次并告警，之后重复检测不再重复暂停和告警
+	w.evaluate(&exchange.APIPermissions{CanRead: true})
+	w.evaluate(&exchange.APIPermissions{CanRead: true})
+	if !pauser.paused || pauser.pauses != 1 {
+		t.Fatalf("paused=%v pauses=%d, want paused once", pauser.paused, pauser.pauses)
+	}
+	if len(*events) != 1 || (*events)[0].Type != event.EventTypeAPIKeyPermissionLost {
+		t.Fatalf("events = %v, want one permission lost", eventTypes(*events))
+	}
+
+	// 权限恢复：恢复由巡检触发的暂停
+	w.evaluate(&exchange.APIPermissions{CanRead: true, CanTrade: true})
+	if pauser.paused || pauser.resumes != 1 {
+		t.Fatalf("paused=%v resumes=%d, want resumed once", pauser.paused, pauser.resumes)
+	}
+	if len(*events) != 2 || (*events)[1].Type != event.EventTypeAPIKeyChanged {
+		t.Fatalf("events = %v, want permission restored", eventTypes(*events))
+	}
+}
+
+func TestAPIKeyWatchdogKeepsForeignPause(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	// 交易已因其它原因暂停（风控、手动），权限恢复时不能替其恢复交易
+	pauser := &fakePauser{paused: true}
+	w, events := newTestWatchdog(pauser, &now)
+
+	w.evaluate(&exchange.APIPermissions{CanRead: false})
+	w.evaluate(&exchange.APIPermissions{CanRead: true, CanTrade: true})
+	if !pauser.paused || pauser.pauses != 0 || pauser.resumes != 0 {
+		t.Fatalf("paused=%v pauses=%d resumes=%d, foreign pause must be left alone", pauser.paused, pauser.pauses, pauser.resumes)
+	}
+	if len(*events) != 2 || !strings.Contains((*events)[0].Data["message"].(string), "IP") {
+		t.Fatalf("events = %v", eventTypes(*events))
+	}
+}
+
+func TestAPIKeyWatchdogExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	pauser := &fakePauser{}
+	w, events := newTestWatchdog(pauser, &now)
+	perms := &exchange.APIPermissions{CanRead: true, CanTrade: true, ExpireTime: now.Add(3 * 24 * time.Hour).Unix()}
+
+	// 距过期不足 7 天：告警一次，24 小时内不重复
+	w.evaluate(perms)
+	now = now.Add(time.Hour)
+	w.evaluate(perms)
+	if len(*events) != 1 || (*events)[0].Type != event.EventTypeAPIKeyExpiring {
+		t.Fatalf("events = %v, want one expiring notice", eventTypes(*events))
+	}
+	now = now.Add(24 * time.Hour)
+	w.evaluate(perms)
+	if len(*events) != 2 || (*events)[1].Type != event.EventTypeAPIKeyExpiring {
+		t.Fatalf("events = %v, want expiring notice repeated after 24h", eventTypes(*events))
+	}
+	if pauser.paused {
+		t.Fatal("expiring key should not pause trading yet")
+	}
+
+	// 到期后按失去交易权限处理
+	now = time.Unix(perms.ExpireTime, 0)
+	w.evaluate(perms)
+	if !pauser.paused {
+		t.Fatal("expired key should pause trading")
+	}
+	last := (*events)[len(*events)-1]
+	if last.Type != event.EventTypeAPIKeyPermissionLost || !strings.Contains(last.Data["message"].(string), "过期") {
+		t.Fatalf("last event = %s %v", last.Type, last.Data["message"])
+	}
+
+	// 续期后恢复
+	perms = &exchange.APIPermissions{CanRead: true, CanTrade: true, ExpireTime: now.Add(90 * 24 * time.Hour).Unix()}
+	w.evaluate(perms)
+	if pauser.paused || pauser.resumes != 1 {
+		t.Fatalf("renewed key should resume trading: paused=%v resumes=%d", pauser.paused, pauser.resumes)
+	}
+}
+
+func TestAPIKeyWatchdogSecurityChanges(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	w, events := newTestWatchdog(&fakePauser{}, &now)
+
+	w.evaluate(&exchange.APIPermissions{CanRead: true, CanTrade: true, IPRestricted: true})
+	w.evaluate(&exchange.APIPermissions{CanRead: true, CanTrade: true})
+	w.evaluate(&exchange.APIPermissions{CanRead: true, CanTrade: true, CanWithdraw: true})
+	if len(*events) != 2 {
+		t.Fatalf("events = %v, want IP whitelist removed and withdraw enabled", eventTypes(*events))
+	}
+	for _, e := range *events {
+		if e.Type != event.EventTypeAPIKeyChanged {
+			t.Errorf("unexpected event %s", e.Type)
+		}
+	}
+	if msg := (*events)[1].Data["message"].(string); !strings.Contains(msg, "提现") {
+		t.Errorf("withdraw event message = %q", msg)
+	}
+}
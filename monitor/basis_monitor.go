@@ -9,6 +9,7 @@ import (
 	"quantmesh/exchange"
 	"quantmesh/logger"
 	"quantmesh/storage"
+	"quantmesh/utils"
 )
 
 // BasisMonitor 价差监控器
@@ -67,7 +68,9 @@ func (bm *BasisMonitor) Start() {
 	logger.Info("📊 启动价差监控服务 (交易所: %s, 交易对: %v, 间隔: %v)",
 		bm.exchangeName, bm.symbols, bm.interval)
 
-	go bm.monitorLoop()
+	utils.GoSupervised(bm.ctx, "basis-monitor", func(ctx context.Context) {
+		bm.monitorLoop()
+	})
 }
 
 // Stop 停止价差监控
@@ -0,0 +1,158 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"quantmesh/exchange"
+	"quantmesh/logger"
+	"quantmesh/utils"
+)
+
+const (
+	// commissionRefreshInterval 手续费率刷新间隔（VIP 等级按日调整，BNB 抵扣可随时开关）
+	commissionRefreshInterval = time.Hour
+	// commissionDivergenceRatio 配置费率与实际费率相对偏差超过该比例时告警
+	commissionDivergenceRatio = 0.1
+)
+
+// CommissionMonitor 账户手续费率监控
+// 定期查询交易所实际的 Maker/Taker 费率和抵扣状态，查询不到时回退到配置费率；
+// 适配器返回 ErrNotImplemented 时停用，之后一直使用配置费率
+type CommissionMonitor struct {
+	provider     exchange.CommissionRateProvider
+	exchangeName string
+	symbol       string
+	configRate   float64
+
+	mu          sync.RWMutex
+	rate        *exchange.CommissionRate
+	unsupported bool // 交易所适配器未实现费率查询，停止后续刷新
+}
+
+// NewCommissionMonitor 创建手续费率监控，交易所不支持费率查询时返回 nil
+func NewCommissionMonitor(ex exchange.IExchange, symbol string, configRate float64) *CommissionMonitor {
+	provider, ok := exchange.As[exchange.CommissionRateProvider](ex)
+	if !ok {
+		return nil
+	}
+	return &CommissionMonitor{
+		provider:     provider,
+		exchangeName: ex.GetName(),
+		symbol:       symbol,
+		configRate:   configRate,
+	}
+}
+
+// Refresh 立即查询一次实际费率（已停用时直接返回）
+func (cm *CommissionMonitor) Refresh(ctx context.Context) error {
+	if cm.Unsupported() {
+		return nil
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	rate, err := cm.provider.GetCommissionRate(reqCtx, cm.symbol)
+	if errors.Is(err, exchange.ErrNotImplemented) {
+		cm.mu.Lock()
+		cm.unsupported = true
+		cm.rate = nil
+		cm.mu.Unlock()
+		logger.Warn("⚠️ [%s] %s 不支持手续费率查询，手续费率监控已停用，使用配置费率", cm.symbol, cm.exchangeName)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	cm.mu.Lock()
+	prev := cm.rate
+	cm.rate = rate
+	cm.mu.Unlock()
+
+	changed := prev == nil || prev.MakerRate != rate.MakerRate || prev.TakerRate != rate.TakerRate || prev.FeeDiscount != rate.FeeDiscount
+	if changed {
+		logger.Info("💳 [%s] 实际手续费率: Maker %.4f%%, Taker %.4f%%, 抵扣: %v (生效 Maker %.4f%%, Taker %.4f%%)",
+			cm.symbol, rate.MakerRate*100, rate.TakerRate*100, rate.FeeDiscount,
+			rate.EffectiveMakerRate()*100, rate.EffectiveTakerRate()*100)
+		if warning := cm.divergenceWarning(rate); warning != "" {
+			logger.Warn("⚠️ [%s] %s", cm.symbol, warning)
+		}
+	}
+	return nil
+}
+
+// divergenceWarning 配置费率既不接近实际 Maker 也不接近实际 Taker 费率时返回告警文本
+func (cm *CommissionMonitor) divergenceWarning(rate *exchange.CommissionRate) string {
+	if cm.configRate <= 0 {
+		return ""
+	}
+	for _, actual := range []float64{rate.EffectiveMakerRate(), rate.EffectiveTakerRate(), rate.MakerRate, rate.TakerRate} {
+		if actual > 0 && math.Abs(cm.configRate-actual)/actual <= commissionDivergenceRatio {
+			return ""
+		}
+	}
+	return fmt.Sprintf("配置手续费率 %.4f%% 与实际费率不一致 (Maker %.4f%%, Taker %.4f%%)，已改用实际费率，请更新配置文件",
+		cm.configRate*100, rate.EffectiveMakerRate()*100, rate.EffectiveTakerRate()*100)
+}
+
+// Start 启动定期刷新（已停用时不启动）
+func (cm *CommissionMonitor) Start(ctx context.Context) {
+	if cm.Unsupported() {
+		return
+	}
+	utils.GoSupervised(ctx, fmt.Sprintf("commission-monitor:%s:%s", cm.exchangeName, cm.symbol), func(ctx context.Context) {
+		ticker := time.NewTicker(commissionRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := cm.Refresh(ctx); err != nil {
+					logger.Warn("⚠️ [%s] 刷新手续费率失败: %v", cm.symbol, err)
+				}
+				if cm.Unsupported() {
+					return
+				}
+			}
+		}
+	})
+}
+
+// Unsupported 交易所是否不支持费率查询（监控已停用）
+func (cm *CommissionMonitor) Unsupported() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.unsupported
+}
+
+// GetCommissionRate 最近一次查询到的费率，尚未查询成功时返回 nil
+func (cm *CommissionMonitor) GetCommissionRate() *exchange.CommissionRate {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	if cm.rate == nil {
+		return nil
+	}
+	copied := *cm.rate
+	return &copied
+}
+
+// MakerFeeRate 生效的 Maker 费率（未查询到时使用配置费率）
+func (cm *CommissionMonitor) MakerFeeRate() float64 {
+	if rate := cm.GetCommissionRate(); rate != nil {
+		return rate.EffectiveMakerRate()
+	}
+	return cm.configRate
+}
+
+// TakerFeeRate 生效的 Taker 费率（未查询到时使用配置费率）
+func (cm *CommissionMonitor) TakerFeeRate() float64 {
+	if rate := cm.GetCommissionRate(); rate != nil {
+		return rate.EffectiveTakerRate()
+	}
+	return cm.configRate
+}
@@ -0,0 +1,108 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"quantmesh/exchange"
+)
+
+func TestCommissionDivergenceWarning(t *testing.T) {
+	tests := []struct {
+		name       string
+		configRate float64
+		rate       exchange.CommissionRate
+		warn       bool
+	}{
+		{"no config rate", 0, exchange.CommissionRate{MakerRate: 0.0002, TakerRate: 0.0005}, false},
+		{"matches maker", 0.0002, exchange.CommissionRate{MakerRate: 0.0002, TakerRate: 0.0005}, false},
+		{"matches taker", 0.0005, exchange.CommissionRate{MakerRate: 0.0002, TakerRate: 0.0005}, false},
+		{"within 10%", 0.00021, exchange.CommissionRate{MakerRate: 0.0002, TakerRate: 0.0005}, false},
+		{"just outside 10%", 0.000225, exchange.CommissionRate{MakerRate: 0.0002, TakerRate: 0.0005}, true},
+		{"between maker and taker", 0.0004, exchange.CommissionRate{MakerRate: 0.0002, TakerRate: 0.0005}, true},
+		// 抵扣后的生效费率与配置一致：BNB 抵扣 10%，0.0005 × 0.9 = 0.00045
+		{"matches discounted taker", 0.00045, exchange.CommissionRate{MakerRate: 0.0002, TakerRate: 0.0005, FeeDiscount: true, DiscountRate: 0.1}, false},
+		// 抵扣关闭时仍可匹配原始费率
+		{"discount disabled", 0.00045, exchange.CommissionRate{MakerRate: 0.0002, TakerRate: 0.0005, DiscountRate: 0.1}, true},
+		{"zero maker rebate tier", 0.0001, exchange.CommissionRate{MakerRate: 0, TakerRate: 0.0003}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := &CommissionMonitor{symbol: "BTCUSDT", configRate: tt.configRate}
+			warning := cm.divergenceWarning(&tt.rate)
+			if (warning != "") != tt.warn {
+				t.Fatalf("warning = %q, want warn=%v", warning, tt.warn)
+			}
+			if tt.warn && !strings.Contains(warning, fmt.Sprintf("%.4f%%", tt.configRate*100)) {
+				t.Errorf("warning should quote the configured rate: %q", warning)
+			}
+		})
+	}
+}
+
+func TestCommissionMonitorRefresh(t *testing.T) {
+	ex := &commissionStub{rate: &exchange.CommissionRate{Symbol: "BTCUSDT", MakerRate: 0.0002, TakerRate: 0.0005, FeeDiscount: true, DiscountRate: 0.1}}
+	cm := NewCommissionMonitor(ex, "BTCUSDT", 0.001)
+	if cm == nil {
+		t.Fatal("NewCommissionMonitor returned nil for an exchange supporting commission queries")
+	}
+
+	// 查询前使用配置费率
+	if cm.MakerFeeRate() != 0.001 || cm.TakerFeeRate() != 0.001 || cm.GetCommissionRate() != nil {
+		t.Fatal("monitor should fall back to the configured rate before the first refresh")
+	}
+	if err := cm.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(cm.MakerFeeRate()-0.00018) > 1e-12 || math.Abs(cm.TakerFeeRate()-0.00045) > 1e-12 {
+		t.Fatalf("effective rates = %v/%v", cm.MakerFeeRate(), cm.TakerFeeRate())
+	}
+
+	// 临时错误保留上次结果，下次继续查询
+	ex.rateErr = errors.New("timeout")
+	if err := cm.Refresh(context.Background()); err == nil {
+		t.Fatal("transient errors should be returned")
+	}
+	if cm.Unsupported() || cm.GetCommissionRate() == nil {
+		t.Fatal("a transient error must not disable the monitor or drop the last rate")
+	}
+	ex.rateErr = nil
+	cm.Refresh(context.Background())
+	if ex.queries != 3 {
+		t.Fatalf("queries = %d, want 3", ex.queries)
+	}
+}
+
+func TestCommissionMonitorDisablesOnNotImplemented(t *testing.T) {
+	ex := &commissionStub{rateErr: fmt.Errorf("okx: %w", exchange.ErrNotImplemented)}
+	cm := NewCommissionMonitor(ex, "BTCUSDT", 0.0005)
+
+	if err := cm.Refresh(context.Background()); err != nil {
+		t.Fatalf("ErrNotImplemented should disable the monitor instead of failing: %v", err)
+	}
+	if !cm.Unsupported() {
+		t.Fatal("monitor should be disabled after ErrNotImplemented")
+	}
+	if cm.GetCommissionRate() != nil || cm.MakerFeeRate() != 0.0005 || cm.TakerFeeRate() != 0.0005 {
+		t.Fatal("disabled monitor should keep using the configured rate")
+	}
+
+	// 停用后不再查询，也不启动定期刷新
+	cm.Refresh(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cm.Start(ctx)
+	if ex.queries != 1 {
+		t.Fatalf("disabled monitor queried the exchange %d times", ex.queries)
+	}
+}
+
+func TestNewCommissionMonitorRequiresProvider(t *testing.T) {
+	if cm := NewCommissionMonitor(&venueStub{}, "BTCUSDT", 0.0005); cm != nil {
+		t.Fatal("NewCommissionMonitor should return nil when the exchange cannot query commission rates")
+	}
+}
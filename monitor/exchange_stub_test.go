@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"context"
+
+	"quantmesh/exchange"
+)
+
+// venueStub 监控测试用交易所：只实现账户查询，其余方法未实现（调用时 panic）
+type venueStub struct {
+	exchange.IExchange
+	available float64
+	accounts  int
+}
+
+func (v *venueStub) GetName() string { return "stub" }
+
+func (v *venueStub) GetAccount(ctx context.Context) (*exchange.Account, error) {
+	v.accounts++
+	return &exchange.Account{AvailableBalance: v.available}, nil
+}
+
+// transferStub 支持账户划转的测试交易所
+type transferStub struct {
+	venueStub
+	transferErr error
+	transfers   []float64
+}
+
+func (v *transferStub) TransferFromFutures(ctx context.Context, asset string, amount float64, toWallet string) (string, error) {
+	if v.transferErr != nil {
+		return "", v.transferErr
+	}
+	v.transfers = append(v.transfers, amount)
+	return "tran-1", nil
+}
+
+// permissionTransferStub 同时支持权限检测和账户划转的测试交易所
+type permissionTransferStub struct {
+	transferStub
+	perms   *exchange.APIPermissions
+	permErr error
+}
+
+func (v *permissionTransferStub) CheckAPIPermissions(ctx context.Context) (*exchange.APIPermissions, error) {
+	return v.perms, v.permErr
+}
+
+// commissionStub 支持手续费率查询的测试交易所
+type commissionStub struct {
+	venueStub
+	rate    *exchange.CommissionRate
+	rateErr error
+	queries int
+}
+
+func (v *commissionStub) GetCommissionRate(ctx context.Context, symbol string) (*exchange.CommissionRate, error) {
+	v.queries++
+	if v.rateErr != nil {
+		return nil, v.rateErr
+	}
+	copied := *v.rate
+	return &copied, nil
+}
+
+// priceStreamStub 支持价格流的测试交易所：保存回调，由测试推送价格
+type priceStreamStub struct {
+	venueStub
+	push func(price float64)
+}
+
+func (v *priceStreamStub) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	v.push = callback
+	return nil
+}
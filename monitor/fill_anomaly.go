@@ -0,0 +1,298 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"quantmesh/event"
+	"quantmesh/logger"
+	"quantmesh/storage"
+	"quantmesh/utils"
+)
+
+const (
+	// FillAnomalyBelowBreakeven 买卖价差不足以覆盖双边手续费的往返成交
+	FillAnomalyBelowBreakeven = "below_breakeven"
+	// FillAnomalySlotLoop 同一槽位在短时间内反复成交，通常是下单逻辑或参数导致的循环
+	FillAnomalySlotLoop = "slot_loop"
+
+	fillAnomalyHistoryLimit = 200
+	fillAnomalyQueryPage    = 1000
+)
+
+// FillAnomaly 成交异常记录
+type FillAnomaly struct {
+	Type        string    `json:"type"`
+	Exchange    string    `json:"exchange"`
+	Symbol      string    `json:"symbol"`
+	SlotPrice   float64   `json:"slot_price,omitempty"`
+	Count       int       `json:"count"`
+	GrossPnL    float64   `json:"gross_pnl"`    // 未扣手续费的价差收益
+	FeeEstimate float64   `json:"fee_estimate"` // 按配置费率估算的双边手续费
+	FirstAt     time.Time `json:"first_at"`
+	LastAt      time.Time `json:"last_at"`
+	DetectedAt  time.Time `json:"detected_at"`
+	Message     string    `json:"message"`
+}
+
+// FillAnomalyDetector 成交异常检测
+// 定期扫描已完成的买卖配对，发现低于手续费保本线的往返和同一槽位循环成交，
+// 这类模式通常来自错误配置（价格间隔过小、费率配置错误、重复下单），会持续消耗手续费
+type FillAnomalyDetector struct {
+	db         storage.Storage
+	eventBus   *event.EventBus
+	feeRates   map[string]float64 // 交易所 -> 单边手续费率
+	interval   time.Duration
+	loopWindow time.Duration
+	loopCount  int
+
+	mu         sync.RWMutex
+	lastScan   time.Time
+	lastLoopAt map[string]time.Time // 槽位 -> 已报告的最近一次循环成交时间
+	anomalies  []*FillAnomaly
+}
+
+// NewFillAnomalyDetector 创建成交异常检测器
+func NewFillAnomalyDetector(db storage.Storage, eventBus *event.EventBus, feeRates map[string]float64,
+	intervalMinutes, loopWindowSeconds, loopCount int) *FillAnomalyDetector {
+	interval := time.Duration(intervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	loopWindow := time.Duration(loopWindowSeconds) * time.Second
+	if loopWindow <= 0 {
+		loopWindow = time.Minute
+	}
+	if loopCount <= 1 {
+		loopCount = 3
+	}
+	return &FillAnomalyDetector{
+		db:         db,
+		eventBus:   eventBus,
+		feeRates:   feeRates,
+		interval:   interval,
+		loopWindow: loopWindow,
+		loopCount:  loopCount,
+		// 首次扫描覆盖最近一个周期，避免启动时对全部历史成交重复告警
+		lastScan:   time.Now().Add(-interval),
+		lastLoopAt: make(map[string]time.Time),
+	}
+}
+
+// Start 启动定期扫描
+func (d *FillAnomalyDetector) Start(ctx context.Context) {
+	logger.Info("🔍 启动成交异常检测 (间隔: %v, 循环判定: %v 内 %d 次)", d.interval, d.loopWindow, d.loopCount)
+
+	utils.GoSupervised(ctx, "fill-anomaly-detector", func(ctx context.Context) {
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := d.Scan(time.Now()); err != nil {
+					logger.Warn("⚠️ 成交异常检测失败: %v", err)
+				}
+			}
+		}
+	})
+}
+
+// Scan 扫描上次扫描之后的成交记录
+func (d *FillAnomalyDetector) Scan(now time.Time) error {
+	d.mu.RLock()
+	since := d.lastScan
+	d.mu.RUnlock()
+
+	// 多查一个循环窗口，使跨越两次扫描的循环成交也能被识别
+	trades, err := d.queryTrades(since.Add(-d.loopWindow), now)
+	if err != nil {
+		return err
+	}
+
+	found := d.detect(trades, since, now)
+
+	d.mu.Lock()
+	d.lastScan = now
+	d.anomalies = append(d.anomalies, found...)
+	if len(d.anomalies) > fillAnomalyHistoryLimit {
+		d.anomalies = d.anomalies[len(d.anomalies)-fillAnomalyHistoryLimit:]
+	}
+	d.mu.Unlock()
+
+	for _, a := range found {
+		logger.Warn("⚠️ [成交异常] %s", a.Message)
+		d.publish(a)
+	}
+	return nil
+}
+
+func (d *FillAnomalyDetector) queryTrades(start, end time.Time) ([]*storage.Trade, error) {
+	var all []*storage.Trade
+	for offset := 0; ; offset += fillAnomalyQueryPage {
+		page, err := d.db.QueryTrades("", "", start, end, fillAnomalyQueryPage, offset)
+		if err != nil {
+			return nil, fmt.Errorf("查询成交记录失败: %w", err)
+		}
+		all = append(all, page...)
+		if len(page) < fillAnomalyQueryPage {
+			return all, nil
+		}
+	}
+}
+
+// detect 识别异常，仅报告 since 之后出现的新成交
+func (d *FillAnomalyDetector) detect(trades []*storage.Trade, since, now time.Time) []*FillAnomaly {
+	var found []*FillAnomaly
+
+	// 1. 低于保本线的往返（按交易对汇总，避免逐笔告警）
+	breakeven := make(map[string]*FillAnomaly)
+	var breakevenKeys []string
+	for _, t := range trades {
+		if !t.CreatedAt.After(since) || t.Quantity <= 0 {
+			continue
+		}
+		gross := (t.SellPrice - t.BuyPrice) * t.Quantity
+		fee := (t.BuyPrice + t.SellPrice) * t.Quantity * d.feeRates[t.Exchange]
+		if gross > fee {
+			continue
+		}
+		key := t.Exchange + ":" + t.Symbol
+		a, ok := breakeven[key]
+		if !ok {
+			a = &FillAnomaly{
+				Type:       FillAnomalyBelowBreakeven,
+				Exchange:   t.Exchange,
+				Symbol:     t.Symbol,
+				FirstAt:    t.CreatedAt,
+				LastAt:     t.CreatedAt,
+				DetectedAt: now,
+			}
+			breakeven[key] = a
+			breakevenKeys = append(breakevenKeys, key)
+		}
+		a.Count++
+		a.GrossPnL += gross
+		a.FeeEstimate += fee
+		if t.CreatedAt.Before(a.FirstAt) {
+			a.FirstAt = t.CreatedAt
+		}
+		if t.CreatedAt.After(a.LastAt) {
+			a.LastAt = t.CreatedAt
+		}
+	}
+	sort.Strings(breakevenKeys)
+	for _, key := range breakevenKeys {
+		a := breakeven[key]
+		a.Message = fmt.Sprintf("[%s:%s] %d 笔往返成交的价差不足以覆盖手续费（价差收益 %.4f，估算手续费 %.4f），请检查价格间隔和费率配置",
+			a.Exchange, a.Symbol, a.Count, a.GrossPnL, a.FeeEstimate)
+		found = append(found, a)
+	}
+
+	// 2. 同一槽位循环成交
+	slots := make(map[string][]*storage.Trade)
+	for _, t := range trades {
+		key := fmt.Sprintf("%s:%s:%g", t.Exchange, t.Symbol, t.BuyPrice)
+		slots[key] = append(slots[key], t)
+	}
+	slotKeys := make([]string, 0, len(slots))
+	for key := range slots {
+		slotKeys = append(slotKeys, key)
+	}
+	sort.Strings(slotKeys)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, key := range slotKeys {
+		group := slots[key]
+		if len(group) < d.loopCount {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool { return group[i].CreatedAt.Before(group[j].CreatedAt) })
+
+		// 找出窗口内成交次数最多的一段
+		bestStart, bestEnd := 0, -1
+		for start, end := 0, 0; end < len(group); end++ {
+			for group[end].CreatedAt.Sub(group[start].CreatedAt) > d.loopWindow {
+				start++
+			}
+			if end-start > bestEnd-bestStart {
+				bestStart, bestEnd = start, end
+			}
+		}
+		count := bestEnd - bestStart + 1
+		if count < d.loopCount {
+			continue
+		}
+		last := group[bestEnd].CreatedAt
+		if !last.After(since) || !last.After(d.lastLoopAt[key]) {
+			continue
+		}
+		d.lastLoopAt[key] = last
+
+		first := group[bestStart]
+		a := &FillAnomaly{
+			Type:       FillAnomalySlotLoop,
+			Exchange:   first.Exchange,
+			Symbol:     first.Symbol,
+			SlotPrice:  first.BuyPrice,
+			Count:      count,
+			FirstAt:    first.CreatedAt,
+			LastAt:     last,
+			DetectedAt: now,
+		}
+		for _, t := range group[bestStart : bestEnd+1] {
+			a.GrossPnL += (t.SellPrice - t.BuyPrice) * t.Quantity
+			a.FeeEstimate += (t.BuyPrice + t.SellPrice) * t.Quantity * d.feeRates[t.Exchange]
+		}
+		a.Message = fmt.Sprintf("[%s:%s] 槽位 %g 在 %v 内成交 %d 次，疑似循环下单",
+			a.Exchange, a.Symbol, a.SlotPrice, last.Sub(first.CreatedAt).Round(time.Second), count)
+		found = append(found, a)
+	}
+	return found
+}
+
+func (d *FillAnomalyDetector) publish(a *FillAnomaly) {
+	if d.eventBus == nil {
+		return
+	}
+	d.eventBus.Publish(&event.Event{
+		Type: event.EventTypeExecutionAnomaly,
+		Data: map[string]interface{}{
+			"anomaly":      a.Type,
+			"exchange":     a.Exchange,
+			"symbol":       a.Symbol,
+			"slot_price":   a.SlotPrice,
+			"count":        a.Count,
+			"gross_pnl":    a.GrossPnL,
+			"fee_estimate": a.FeeEstimate,
+			"message":      a.Message,
+		},
+	})
+}
+
+// GetFillAnomalies 最近检测到的成交异常（按时间倒序）
+func (d *FillAnomalyDetector) GetFillAnomalies(limit int) []*FillAnomaly {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if limit <= 0 || limit > len(d.anomalies) {
+		limit = len(d.anomalies)
+	}
+	result := make([]*FillAnomaly, 0, limit)
+	for i := len(d.anomalies) - 1; i >= 0 && len(result) < limit; i-- {
+		copied := *d.anomalies[i]
+		result = append(result, &copied)
+	}
+	return result
+}
+
+// LastScanTime 最近一次扫描时间
+func (d *FillAnomalyDetector) LastScanTime() time.Time {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.lastScan
+}
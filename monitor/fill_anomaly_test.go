@@ -0,0 +1,173 @@
+package monitor
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"quantmesh/storage"
+	"quantmesh/testutil"
+)
+
+var fillTestBase = time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+func fillTrade(symbol string, buy, sell, qty float64, offset time.Duration) *storage.Trade {
+	return &storage.Trade{Exchange: "binance", Symbol: symbol, BuyPrice: buy, SellPrice: sell, Quantity: qty, CreatedAt: fillTestBase.Add(offset)}
+}
+
+func anomaliesOfType(found []*FillAnomaly, typ string) []*FillAnomaly {
+	var result []*FillAnomaly
+	for _, a := range found {
+		if a.Type == typ {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+func TestFillAnomalyBelowBreakeven(t *testing.T) {
+	type want struct {
+		symbol string
+		count  int
+		gross  float64
+		fee    float64
+	}
+	tests := []struct {
+		name   string
+		trades []*storage.Trade
+		want   []want
+	}{
+		{
+			name:   "spread covers fees",
+			trades: []*storage.Trade{fillTrade("BTCUSDT", 100, 101, 1, time.Second)},
+		},
+		{
+			// 价差 0.05，双边手续费 (100+100.05)×0.0004 = 0.08002
+			name:   "spread below fees",
+			trades: []*storage.Trade{fillTrade("BTCUSDT", 100, 100.05, 1, time.Second)},
+			want:   []want{{"BTCUSDT", 1, 0.05, 0.08002}},
+		},
+		{
+			// 未配置费率的交易所手续费按 0 估算，价差为 0 时恰好保本，没有收益同样报告
+			name:   "spread equals fees",
+			trades: []*storage.Trade{{Exchange: "okx", Symbol: "BTCUSDT", BuyPrice: 100, SellPrice: 100, Quantity: 1, CreatedAt: fillTestBase.Add(time.Second)}},
+			want:   []want{{"BTCUSDT", 1, 0, 0}},
+		},
+		{
+			name: "aggregated per symbol in key order",
+			trades: []*storage.Trade{
+				fillTrade("ETHUSDT", 10, 10, 2, time.Second),
+				fillTrade("BTCUSDT", 100, 100.01, 1, 2*time.Second),
+				fillTrade("BTCUSDT", 200, 199.9, 1, 3*time.Second),
+				fillTrade("BTCUSDT", 300, 310, 1, 4*time.Second),
+			},
+			want: []want{{"BTCUSDT", 2, 0.01 - 0.1, 0.080004 + 0.15996}, {"ETHUSDT", 1, 0, 0.016}},
+		},
+		{
+			name: "trades before the scan window and empty quantities are ignored",
+			trades: []*storage.Trade{
+				fillTrade("BTCUSDT", 100, 100, 1, -time.Second),
+				fillTrade("BTCUSDT", 100, 100, 0, time.Second),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewFillAnomalyDetector(nil, nil, map[string]float64{"binance": 0.0004}, 5, 60, 3)
+			got := anomaliesOfType(d.detect(tt.trades, fillTestBase, fillTestBase.Add(time.Minute)), FillAnomalyBelowBreakeven)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d anomalies, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, w := range tt.want {
+				a := got[i]
+				if a.Symbol != w.symbol || a.Count != w.count || math.Abs(a.GrossPnL-w.gross) > 1e-9 || math.Abs(a.FeeEstimate-w.fee) > 1e-9 {
+					t.Errorf("anomaly %d = %+v, want %+v", i, a, w)
+				}
+			}
+		})
+	}
+}
+
+func TestFillAnomalySlotLoop(t *testing.T) {
+	tests := []struct {
+		name      string
+		offsets   []time.Duration // 同一槽位（买价 100）的成交时间
+		wantCount int             // 0 表示不报告
+	}{
+		{"loop within window", []time.Duration{1 * time.Second, 20 * time.Second, 40 * time.Second}, 3},
+		{"too few fills", []time.Duration{1 * time.Second, 20 * time.Second}, 0},
+		{"fills spread beyond window", []time.Duration{1 * time.Second, 70 * time.Second, 140 * time.Second}, 0},
+		{"densest run is reported", []time.Duration{1 * time.Second, 100 * time.Second, 110 * time.Second, 120 * time.Second, 130 * time.Second}, 4},
+		{"loop finished before scan window", []time.Duration{-50 * time.Second, -40 * time.Second, -30 * time.Second}, 0},
+		{"loop crossing scan boundary", []time.Duration{-40 * time.Second, -20 * time.Second, 10 * time.Second}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var trades []*storage.Trade
+			for _, off := range tt.offsets {
+				trades = append(trades, fillTrade("BTCUSDT", 100, 101, 1, off))
+			}
+			// 其它槽位的零星成交不构成循环
+			trades = append(trades, fillTrade("BTCUSDT", 90, 91, 1, 5*time.Second), fillTrade("BTCUSDT", 90, 91, 1, 6*time.Second))
+
+			d := NewFillAnomalyDetector(nil, nil, map[string]float64{"binance": 0.0004}, 5, 60, 3)
+			got := anomaliesOfType(d.detect(trades, fillTestBase, fillTestBase.Add(5*time.Minute)), FillAnomalySlotLoop)
+			if tt.wantCount == 0 {
+				if len(got) != 0 {
+					t.Fatalf("unexpected loop %+v", got[0])
+				}
+				return
+			}
+			if len(got) != 1 || got[0].SlotPrice != 100 || got[0].Count != tt.wantCount {
+				t.Fatalf("loops = %+v, want one at 100 with %d fills", got, tt.wantCount)
+			}
+			if math.Abs(got[0].GrossPnL-float64(tt.wantCount)) > 1e-9 {
+				t.Errorf("gross pnl = %v, want %d", got[0].GrossPnL, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestFillAnomalySlotLoopReportedOnce(t *testing.T) {
+	d := NewFillAnomalyDetector(nil, nil, nil, 5, 60, 3)
+	trades := []*storage.Trade{
+		fillTrade("BTCUSDT", 100, 101, 1, 1*time.Second),
+		fillTrade("BTCUSDT", 100, 101, 1, 2*time.Second),
+		fillTrade("BTCUSDT", 100, 101, 1, 3*time.Second),
+	}
+	if got := anomaliesOfType(d.detect(trades, fillTestBase, fillTestBase.Add(time.Minute)), FillAnomalySlotLoop); len(got) != 1 {
+		t.Fatalf("first detect: %d loops", len(got))
+	}
+	// 下次扫描多查的窗口中仍包含同一段循环，不重复报告
+	if got := anomaliesOfType(d.detect(trades, fillTestBase, fillTestBase.Add(2*time.Minute)), FillAnomalySlotLoop); len(got) != 0 {
+		t.Fatalf("same loop reported again: %+v", got[0])
+	}
+	// 循环继续时再次报告
+	trades = append(trades, fillTrade("BTCUSDT", 100, 101, 1, 4*time.Second))
+	if got := anomaliesOfType(d.detect(trades, fillTestBase, fillTestBase.Add(3*time.Minute)), FillAnomalySlotLoop); len(got) != 1 || got[0].Count != 4 {
+		t.Fatalf("continued loop: %+v", got)
+	}
+}
+
+func TestFillAnomalyScan(t *testing.T) {
+	st := testutil.NewMockStorage()
+	var starts []time.Time
+	st.QueryTradesFunc = func(exchange, symbol string, startTime, endTime time.Time, limit, offset int) ([]*storage.Trade, error) {
+		starts = append(starts, startTime)
+		return []*storage.Trade{fillTrade("BTCUSDT", 100, 100, 1, time.Second)}, nil
+	}
+	d := NewFillAnomalyDetector(st, nil, map[string]float64{"binance": 0.0004}, 5, 60, 3)
+	d.lastScan = fillTestBase
+
+	if err := d.Scan(fillTestBase.Add(5 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	// 多查一个循环窗口，识别跨越两次扫描的循环
+	if len(starts) != 1 || !starts[0].Equal(fillTestBase.Add(-time.Minute)) {
+		t.Fatalf("query start = %v", starts)
+	}
+	got := d.GetFillAnomalies(10)
+	if len(got) != 1 || got[0].Type != FillAnomalyBelowBreakeven || !d.LastScanTime().Equal(fillTestBase.Add(5*time.Minute)) {
+		t.Fatalf("anomalies = %+v, last scan = %v", got, d.LastScanTime())
+	}
+}
@@ -0,0 +1,121 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"quantmesh/exchange"
+	"quantmesh/logger"
+	"quantmesh/storage"
+	"quantmesh/utils"
+)
+
+const (
+	// fundingLedgerInterval 资金费每 8 小时结算一次，每小时同步足以及时入账
+	fundingLedgerInterval = time.Hour
+	// fundingLedgerLookback 首次同步（本地无记录）时回溯的时间
+	fundingLedgerLookback = 30 * 24 * time.Hour
+	// fundingLedgerPageSize 交易所单次返回的最大条数，满页时继续翻页
+	fundingLedgerPageSize = 1000
+)
+
+// FundingLedger 资金费流水同步服务
+// 定期从交易所账户流水接口拉取实际收付的资金费，写入 funding_payments 表，
+// 供净盈亏、每日统计和按交易对统计使用
+type FundingLedger struct {
+	storage      storage.Storage
+	provider     exchange.IncomeHistoryProvider
+	exchangeName string
+	symbol       string
+}
+
+// NewFundingLedger 创建资金费流水同步服务，交易所不支持账户流水查询时返回 nil
+func NewFundingLedger(st storage.Storage, ex exchange.IExchange, symbol string) *FundingLedger {
+	provider, ok := exchange.As[exchange.IncomeHistoryProvider](ex)
+	if !ok || st == nil {
+		return nil
+	}
+	return &FundingLedger{
+		storage:      st,
+		provider:     provider,
+		exchangeName: ex.GetName(),
+		symbol:       symbol,
+	}
+}
+
+// Start 启动同步
+func (fl *FundingLedger) Start(ctx context.Context) {
+	logger.Info("📒 [资金费流水] 启动同步 (交易所: %s, 交易对: %s, 间隔: %v)", fl.exchangeName, fl.symbol, fundingLedgerInterval)
+
+	utils.GoSupervised(ctx, "funding-ledger:"+fl.exchangeName+":"+fl.symbol, func(ctx context.Context) {
+		fl.sync(ctx)
+
+		ticker := time.NewTicker(fundingLedgerInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				fl.sync(ctx)
+			}
+		}
+	})
+}
+
+// sync 从最近一笔已入账流水之后开始增量拉取
+func (fl *FundingLedger) sync(ctx context.Context) {
+	latest, err := fl.storage.GetLatestFundingPaymentTime(fl.exchangeName, fl.symbol)
+	if err != nil {
+		logger.Warn("⚠️ [资金费流水] %s 查询最近入账时间失败: %v", fl.symbol, err)
+		return
+	}
+	startTime := time.Now().Add(-fundingLedgerLookback)
+	if !latest.IsZero() {
+		startTime = latest.Add(time.Millisecond)
+	}
+
+	total := 0
+	for {
+		reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		records, err := fl.provider.GetIncomeHistory(reqCtx, fl.symbol, exchange.IncomeTypeFundingFee, startTime, time.Time{})
+		cancel()
+		if err != nil {
+			logger.Warn("⚠️ [资金费流水] %s 拉取失败: %v", fl.symbol, err)
+			return
+		}
+		if len(records) == 0 {
+			break
+		}
+
+		payments := make([]*storage.FundingPayment, 0, len(records))
+		for _, r := range records {
+			payments = append(payments, &storage.FundingPayment{
+				Exchange:    fl.exchangeName,
+				Symbol:      r.Symbol,
+				Asset:       r.Asset,
+				Amount:      r.Amount,
+				TranID:      r.TranID,
+				PaymentTime: r.Time,
+			})
+			if r.Time.After(startTime) {
+				startTime = r.Time
+			}
+		}
+		inserted, err := fl.storage.SaveFundingPayments(payments)
+		if err != nil {
+			logger.Warn("⚠️ [资金费流水] %s 保存失败: %v", fl.symbol, err)
+			return
+		}
+		total += inserted
+
+		if len(records) < fundingLedgerPageSize {
+			break
+		}
+		startTime = startTime.Add(time.Millisecond)
+	}
+
+	if total > 0 {
+		logger.Info("📒 [资金费流水] %s 新增 %d 笔资金费记录", fl.symbol, total)
+	}
+}
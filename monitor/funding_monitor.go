@@ -3,36 +3,40 @@ package monitor
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"quantmesh/exchange"
 	"quantmesh/logger"
 	"quantmesh/storage"
+	"quantmesh/utils"
 )
 
+// DefaultFundingSymbols 未配置监控列表时默认监控的主流交易对
+var DefaultFundingSymbols = []string{
+	"BTCUSDT", "ETHUSDT", "BNBUSDT", "SOLUSDT", "XRPUSDT",
+	"ADAUSDT", "DOGEUSDT", "DOTUSDT", "MATICUSDT", "AVAXUSDT",
+}
+
 // FundingMonitor 资金费率监控服务
 type FundingMonitor struct {
 	storage      storage.Storage
 	exchange     exchange.IExchange
 	exchangeName string
-	symbols      []string
 	interval     time.Duration
 	ctx          context.Context
 	cancel       context.CancelFunc
+
+	mu            sync.RWMutex
+	symbols       []string        // 配置的监控列表（可运行时修改）
+	activeSymbols func() []string // 正在交易的交易对，始终自动纳入监控
 }
 
 // NewFundingMonitor 创建资金费率监控服务
 func NewFundingMonitor(storage storage.Storage, ex exchange.IExchange, symbols []string, intervalHours int) *FundingMonitor {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// 默认监控主流交易对
-	if len(symbols) == 0 {
-		symbols = []string{
-			"BTCUSDT", "ETHUSDT", "BNBUSDT", "SOLUSDT", "XRPUSDT",
-			"ADAUSDT", "DOGEUSDT", "DOTUSDT", "MATICUSDT", "AVAXUSDT",
-		}
-	}
-
 	interval := time.Duration(intervalHours) * time.Hour
 	if interval <= 0 {
 		interval = 8 * time.Hour // 默认8小时
@@ -42,7 +46,7 @@ func NewFundingMonitor(storage storage.Storage, ex exchange.IExchange, symbols [
 		storage:      storage,
 		exchange:     ex,
 		exchangeName: ex.GetName(),
-		symbols:      symbols,
+		symbols:      normalizeFundingSymbols(symbols),
 		interval:     interval,
 		ctx:          ctx,
 		cancel:       cancel,
@@ -52,9 +56,11 @@ func NewFundingMonitor(storage storage.Storage, ex exchange.IExchange, symbols [
 // Start 启动资金费率监控
 func (fm *FundingMonitor) Start() {
 	logger.Info("📊 启动资金费率监控服务 (交易所: %s, 交易对: %v, 间隔: %v)",
-		fm.exchangeName, fm.symbols, fm.interval)
+		fm.exchangeName, fm.MonitoredSymbols(), fm.interval)
 
-	go fm.monitorLoop()
+	utils.GoSupervised(fm.ctx, "funding-monitor", func(ctx context.Context) {
+		fm.monitorLoop()
+	})
 }
 
 // Stop 停止资金费率监控
@@ -88,7 +94,7 @@ func (fm *FundingMonitor) monitorLoop() {
 func (fm *FundingMonitor) checkFundingRates() {
 	logger.Info("🔍 开始检查资金费率...")
 
-	for _, symbol := range fm.symbols {
+	for _, symbol := range fm.MonitoredSymbols() {
 		if err := fm.checkSymbolFundingRate(symbol); err != nil {
 			logger.Warn("⚠️ [资金费率] %s 检查失败: %v", symbol, err)
 			// 单个交易对失败不影响其他交易对
@@ -128,7 +134,7 @@ func (fm *FundingMonitor) checkSymbolFundingRate(symbol string) error {
 func (fm *FundingMonitor) GetCurrentFundingRates() (map[string]float64, error) {
 	rates := make(map[string]float64)
 
-	for _, symbol := range fm.symbols {
+	for _, symbol := range fm.MonitoredSymbols() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		rate, err := fm.exchange.GetFundingRate(ctx, symbol)
 		cancel()
@@ -143,3 +149,95 @@ func (fm *FundingMonitor) GetCurrentFundingRates() (map[string]float64, error) {
 
 	return rates, nil
 }
+
+// SetActiveSymbolsFunc 设置正在交易的交易对来源，这些交易对始终纳入监控（无需出现在配置列表中）
+func (fm *FundingMonitor) SetActiveSymbolsFunc(fn func() []string) {
+	fm.mu.Lock()
+	fm.activeSymbols = fn
+	fm.mu.Unlock()
+}
+
+// GetSymbols 获取配置的监控列表
+func (fm *FundingMonitor) GetSymbols() []string {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	return append([]string(nil), fm.symbols...)
+}
+
+// GetActiveSymbols 获取自动纳入监控的正在交易的交易对
+func (fm *FundingMonitor) GetActiveSymbols() []string {
+	fm.mu.RLock()
+	fn := fm.activeSymbols
+	fm.mu.RUnlock()
+	if fn == nil {
+		return nil
+	}
+	return mergeFundingSymbols(fn())
+}
+
+// MonitoredSymbols 实际监控的交易对：配置列表 + 正在交易的交易对（去重）
+func (fm *FundingMonitor) MonitoredSymbols() []string {
+	return mergeFundingSymbols(fm.GetSymbols(), fm.GetActiveSymbols())
+}
+
+// SetSymbols 运行时修改监控列表，为空时恢复默认主流交易对
+// 新增的交易对立即采集一次，无需等待下一个周期
+func (fm *FundingMonitor) SetSymbols(symbols []string) []string {
+	normalized := normalizeFundingSymbols(symbols)
+
+	fm.mu.Lock()
+	previous := make(map[string]bool, len(fm.symbols))
+	for _, s := range fm.symbols {
+		previous[s] = true
+	}
+	fm.symbols = normalized
+	fm.mu.Unlock()
+
+	added := make([]string, 0)
+	for _, s := range normalized {
+		if !previous[s] {
+			added = append(added, s)
+		}
+	}
+	logger.Info("📊 [资金费率] 监控列表已更新: %v (新增: %v)", normalized, added)
+
+	if len(added) > 0 {
+		go func() {
+			for _, symbol := range added {
+				if fm.ctx.Err() != nil {
+					return
+				}
+				if err := fm.checkSymbolFundingRate(symbol); err != nil {
+					logger.Warn("⚠️ [资金费率] %s 检查失败: %v", symbol, err)
+				}
+			}
+		}()
+	}
+	return append([]string(nil), normalized...)
+}
+
+// normalizeFundingSymbols 转为大写并去重，为空时使用默认列表
+func normalizeFundingSymbols(symbols []string) []string {
+	normalized := mergeFundingSymbols(symbols, nil)
+	if len(normalized) == 0 {
+		return append([]string(nil), DefaultFundingSymbols...)
+	}
+	return normalized
+}
+
+// mergeFundingSymbols 合并交易对列表，保持顺序并去重
+func mergeFundingSymbols(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	merged := make([]string, 0)
+	for _, list := range lists {
+		for _, s := range list {
+			s = strings.ToUpper(strings.TrimSpace(s))
+			if s == "" || seen[s] {
+				continue
+			}
+			seen[s] = true
+			merged = append(merged, s)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,289 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/event"
+	"quantmesh/logger"
+	"quantmesh/storage"
+	"quantmesh/utils"
+)
+
+// 盈利目标类型
+const (
+	GoalDailyTarget  = "daily_target"
+	GoalWeeklyTarget = "weekly_target"
+	GoalMaxDailyLoss = "max_daily_loss"
+
+	// GoalBuyHaltSource 暂停新开仓时使用的来源名称（见 SuperPositionManager.HaltBuying）
+	GoalBuyHaltSource = "goals"
+)
+
+// GoalStatus 单个目标的进度
+type GoalStatus struct {
+	Kind        string     `json:"kind"`
+	Limit       float64    `json:"limit"`    // 目标金额或亏损上限（正数）
+	Realized    float64    `json:"realized"` // 周期内已实现盈亏（含资金费）
+	Progress    float64    `json:"progress"` // 完成百分比（亏损上限为已用百分比），最低为 0
+	Reached     bool       `json:"reached"`
+	ReachedAt   *time.Time `json:"reached_at,omitempty"`
+	Action      string     `json:"action"` // notify / stop_buying
+	PeriodStart time.Time  `json:"period_start"`
+}
+
+// GoalProgress 盈利目标整体进度
+type GoalProgress struct {
+	DailyPnL     float64      `json:"daily_pnl"`
+	WeeklyPnL    float64      `json:"weekly_pnl"`
+	Goals        []GoalStatus `json:"goals"`
+	BuyingHalted bool         `json:"buying_halted"`         // 是否已暂停新开仓
+	HaltReason   string       `json:"halt_reason,omitempty"` // 触发暂停的目标类型
+	HaltUntil    *time.Time   `json:"halt_until,omitempty"`  // 暂停到当日结束（UTC）
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// GoalTracker 盈利目标跟踪
+// 定期统计当前 UTC 日/周的已实现盈亏（全部交易所，含资金费），首次达成目标或当日亏损达到上限时发布事件，
+// 动作为 stop_buying 时通过回调暂停新开仓，直到下一个 UTC 日开始
+type GoalTracker struct {
+	cfg      *config.Config
+	storage  storage.Storage
+	eventBus *event.EventBus
+	onHalt   func(reason string)
+	onResume func()
+	now      func() time.Time
+
+	mu         sync.RWMutex
+	progress   *GoalProgress
+	dayStart   time.Time
+	weekStart  time.Time
+	reachedAt  map[string]time.Time // 本周期已达成的目标
+	haltedDay  time.Time            // 暂停新开仓所在的日期（零值表示未暂停）
+	haltReason string
+}
+
+// NewGoalTracker 创建盈利目标跟踪
+func NewGoalTracker(cfg *config.Config, st storage.Storage, eventBus *event.EventBus) *GoalTracker {
+	return &GoalTracker{
+		cfg:       cfg,
+		storage:   st,
+		eventBus:  eventBus,
+		now:       time.Now,
+		reachedAt: make(map[string]time.Time),
+	}
+}
+
+// SetHaltHandler 设置暂停/恢复新开仓的回调（动作为 stop_buying 时使用）
+func (gt *GoalTracker) SetHaltHandler(onHalt func(reason string), onResume func()) {
+	gt.onHalt = onHalt
+	gt.onResume = onResume
+}
+
+// Start 立即检查一次，之后按 check_interval 定期检查
+func (gt *GoalTracker) Start(ctx context.Context) {
+	goals := gt.cfg.Goals
+	interval := time.Duration(goals.CheckInterval) * time.Second
+	logger.Info("🎯 [盈利目标] 启动 (日目标: %.2f, 周目标: %.2f, 日亏损上限: %.2f, 间隔: %v)",
+		goals.DailyTarget, goals.WeeklyTarget, goals.MaxDailyLoss, interval)
+
+	utils.GoSupervised(ctx, "goal-tracker", func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			gt.check()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// GetProgress 获取最近一次检查的进度（尚未检查时返回 nil）
+func (gt *GoalTracker) GetProgress() *GoalProgress {
+	gt.mu.RLock()
+	defer gt.mu.RUnlock()
+	if gt.progress == nil {
+		return nil
+	}
+	p := *gt.progress
+	p.Goals = append([]GoalStatus(nil), gt.progress.Goals...)
+	return &p
+}
+
+// check 统计已实现盈亏并评估各目标
+func (gt *GoalTracker) check() {
+	goals := gt.cfg.Goals
+	now := gt.now().UTC()
+	dayStart := profitPeriodStart(now, "daily")
+	weekStart := profitPeriodStart(now, "weekly")
+
+	gt.rollPeriods(dayStart, weekStart)
+
+	daily, err := gt.realizedPnL(dayStart, now)
+	if err != nil {
+		logger.Warn("⚠️ [盈利目标] 统计当日已实现盈亏失败: %v", err)
+		return
+	}
+	weekly, err := gt.realizedPnL(weekStart, now)
+	if err != nil {
+		logger.Warn("⚠️ [盈利目标] 统计本周已实现盈亏失败: %v", err)
+		return
+	}
+
+	candidates := []struct {
+		kind        string
+		limit       float64
+		realized    float64
+		used        float64 // 用于计算进度和判断是否达成的数值
+		action      string
+		periodStart time.Time
+	}{
+		{GoalDailyTarget, goals.DailyTarget, daily, daily, goals.OnTarget, dayStart},
+		{GoalWeeklyTarget, goals.WeeklyTarget, weekly, weekly, goals.OnTarget, weekStart},
+		{GoalMaxDailyLoss, goals.MaxDailyLoss, daily, -daily, goals.OnMaxLoss, dayStart},
+	}
+
+	progress := &GoalProgress{DailyPnL: daily, WeeklyPnL: weekly, Goals: []GoalStatus{}, UpdatedAt: now}
+	var newlyReached []GoalStatus
+	gt.mu.Lock()
+	for _, c := range candidates {
+		if c.limit <= 0 {
+			continue
+		}
+		status := GoalStatus{
+			Kind:        c.kind,
+			Limit:       c.limit,
+			Realized:    c.realized,
+			Progress:    goalProgressPercent(c.used, c.limit),
+			Action:      c.action,
+			PeriodStart: c.periodStart,
+		}
+		at, reached := gt.reachedAt[c.kind]
+		if !reached && c.used >= c.limit {
+			at, reached = now, true
+			gt.reachedAt[c.kind] = now
+			newlyReached = append(newlyReached, status)
+		}
+		if reached {
+			status.Reached = true
+			status.ReachedAt = &at
+		}
+		progress.Goals = append(progress.Goals, status)
+	}
+	gt.progress = progress
+	gt.mu.Unlock()
+
+	for _, status := range newlyReached {
+		gt.onReached(status, dayStart)
+	}
+
+	gt.mu.Lock()
+	if !gt.haltedDay.IsZero() {
+		until := gt.haltedDay.AddDate(0, 0, 1)
+		gt.progress.BuyingHalted = true
+		gt.progress.HaltReason = gt.haltReason
+		gt.progress.HaltUntil = &until
+	}
+	gt.mu.Unlock()
+}
+
+// rollPeriods 进入新的 UTC 日/周时重置对应目标，并恢复前一日暂停的新开仓
+func (gt *GoalTracker) rollPeriods(dayStart, weekStart time.Time) {
+	gt.mu.Lock()
+	resume := false
+	if !gt.dayStart.Equal(dayStart) {
+		gt.dayStart = dayStart
+		delete(gt.reachedAt, GoalDailyTarget)
+		delete(gt.reachedAt, GoalMaxDailyLoss)
+		if !gt.haltedDay.IsZero() && gt.haltedDay.Before(dayStart) {
+			gt.haltedDay = time.Time{}
+			gt.haltReason = ""
+			resume = true
+		}
+	}
+	if !gt.weekStart.Equal(weekStart) {
+		gt.weekStart = weekStart
+		delete(gt.reachedAt, GoalWeeklyTarget)
+	}
+	gt.mu.Unlock()
+
+	if resume {
+		logger.Info("▶️ [盈利目标] 进入新的交易日，恢复新开仓")
+		if gt.onResume != nil {
+			gt.onResume()
+		}
+	}
+}
+
+// onReached 目标首次达成：发布事件，动作为 stop_buying 时暂停当日剩余时间的新开仓
+func (gt *GoalTracker) onReached(status GoalStatus, dayStart time.Time) {
+	var message string
+	eventType := event.EventTypeProfitGoalReached
+	switch status.Kind {
+	case GoalDailyTarget:
+		message = fmt.Sprintf("今日已实现盈亏 %.2f，达成日盈利目标 %.2f", status.Realized, status.Limit)
+	case GoalWeeklyTarget:
+		message = fmt.Sprintf("本周已实现盈亏 %.2f，达成周盈利目标 %.2f", status.Realized, status.Limit)
+	case GoalMaxDailyLoss:
+		eventType = event.EventTypeDailyLossLimit
+		message = fmt.Sprintf("今日已实现亏损 %.2f，达到日亏损上限 %.2f", -status.Realized, status.Limit)
+	}
+	if status.Action == "stop_buying" {
+		message += "，今日剩余时间暂停新开仓"
+	}
+	logger.Warn("🎯 [盈利目标] %s", message)
+
+	if gt.eventBus != nil {
+		gt.eventBus.Publish(&event.Event{
+			Type: eventType,
+			Data: map[string]interface{}{
+				"goal":     status.Kind,
+				"limit":    status.Limit,
+				"realized": status.Realized,
+				"action":   status.Action,
+				"message":  message,
+			},
+		})
+	}
+
+	if status.Action != "stop_buying" {
+		return
+	}
+	gt.mu.Lock()
+	alreadyHalted := !gt.haltedDay.IsZero()
+	if !alreadyHalted {
+		gt.haltedDay = dayStart
+		gt.haltReason = status.Kind
+	}
+	gt.mu.Unlock()
+	if !alreadyHalted && gt.onHalt != nil {
+		gt.onHalt(status.Kind)
+	}
+}
+
+// realizedPnL 汇总全部交易所在时间区间内的已实现盈亏（成交盈亏 + 资金费）
+func (gt *GoalTracker) realizedPnL(startTime, endTime time.Time) (float64, error) {
+	rows, err := gt.storage.GetPnLByTimeRange(startTime, endTime)
+	if err != nil {
+		return 0, err
+	}
+	var total float64
+	for _, r := range rows {
+		total += r.NetPnL
+	}
+	return total, nil
+}
+
+// goalProgressPercent 完成百分比，负数按 0 计
+func goalProgressPercent(value, limit float64) float64 {
+	if limit <= 0 || value <= 0 {
+		return 0
+	}
+	return value / limit * 100
+}
@@ -0,0 +1,257 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/event"
+	"quantmesh/exchange"
+	"quantmesh/logger"
+	"quantmesh/storage"
+	"quantmesh/utils"
+)
+
+// incomeLedgerTypes 同步的账户流水类型
+var incomeLedgerTypes = []string{
+	exchange.IncomeTypeRealizedPnL,
+	exchange.IncomeTypeCommission,
+	exchange.IncomeTypeFundingFee,
+}
+
+// IncomeReconciliationReport 盈亏对账报告：本地成交记录计算的盈亏与交易所账户流水比对
+type IncomeReconciliationReport struct {
+	Exchange            string    `json:"exchange"`
+	Symbol              string    `json:"symbol"`
+	Start               time.Time `json:"start"`
+	End                 time.Time `json:"end"`
+	ComputedPnL         float64   `json:"computed_pnl"`          // 本地成交记录的盈亏（未扣手续费）
+	TradeCount          int       `json:"trade_count"`           // 本地成交记录笔数
+	ExchangeRealizedPnL float64   `json:"exchange_realized_pnl"` // 交易所 REALIZED_PNL 流水合计
+	Commission          float64   `json:"commission"`            // 交易所 COMMISSION 流水合计（负数为支出）
+	FundingFee          float64   `json:"funding_fee"`           // 交易所 FUNDING_FEE 流水合计
+	ExchangeNetPnL      float64   `json:"exchange_net_pnl"`      // 已实现盈亏 + 手续费 + 资金费
+	Divergence          float64   `json:"divergence"`            // 本地盈亏 - 交易所已实现盈亏
+	DivergencePercent   float64   `json:"divergence_percent"`    // 偏差相对交易所已实现盈亏的百分比
+	ToleranceAbs        float64   `json:"tolerance_abs"`
+	TolerancePercent    float64   `json:"tolerance_percent"`
+	Flagged             bool      `json:"flagged"` // 偏差同时超过绝对和相对容差
+	GeneratedAt         time.Time `json:"generated_at"`
+}
+
+// IncomeLedger 账户流水同步与盈亏对账服务
+// 定期从交易所账户流水接口增量拉取已实现盈亏、手续费、资金费写入 income_records 表（按流水号去重），
+// 每次同步后将最近区间内本地计算的盈亏与交易所已实现盈亏比对，偏差超出容差时发布告警事件
+type IncomeLedger struct {
+	cfg           *config.Config
+	storage       storage.Storage
+	provider      exchange.IncomeHistoryProvider
+	eventBus      *event.EventBus
+	exchangeName  string // 账户流水使用的交易所名称（与资金费流水一致）
+	tradeExchange string // 本地成交记录使用的交易所名称
+	symbol        string
+
+	mu          sync.RWMutex
+	lastReport  *IncomeReconciliationReport
+	lastFlagged bool // 上次对账是否超出容差，仅在状态变化时告警
+}
+
+// NewIncomeLedger 创建账户流水同步服务，交易所不支持账户流水查询或存储不可用时返回 nil
+func NewIncomeLedger(cfg *config.Config, st storage.Storage, ex exchange.IExchange, symbol string, eventBus *event.EventBus) *IncomeLedger {
+	provider, ok := exchange.As[exchange.IncomeHistoryProvider](ex)
+	if !ok || st == nil {
+		return nil
+	}
+	tradeExchange := strings.ToLower(cfg.App.CurrentExchange)
+	if tradeExchange == "" {
+		tradeExchange = "binance"
+	}
+	return &IncomeLedger{
+		cfg:           cfg,
+		storage:       st,
+		provider:      provider,
+		eventBus:      eventBus,
+		exchangeName:  ex.GetName(),
+		tradeExchange: tradeExchange,
+		symbol:        symbol,
+	}
+}
+
+// Start 启动同步
+func (il *IncomeLedger) Start(ctx context.Context) {
+	interval := time.Duration(il.cfg.IncomeSync.IntervalMinutes) * time.Minute
+	logger.Info("📒 [账户流水] 启动同步 (交易所: %s, 交易对: %s, 间隔: %v, 容差: %.2f / %.1f%%)",
+		il.exchangeName, il.symbol, interval, il.cfg.IncomeSync.ToleranceAbs, il.cfg.IncomeSync.TolerancePercent)
+
+	utils.GoSupervised(ctx, "income-ledger:"+il.exchangeName+":"+il.symbol, func(ctx context.Context) {
+		il.syncAndReconcile(ctx)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				il.syncAndReconcile(ctx)
+			}
+		}
+	})
+}
+
+// syncAndReconcile 同步全部类型的流水后生成对账报告
+func (il *IncomeLedger) syncAndReconcile(ctx context.Context) {
+	for _, incomeType := range incomeLedgerTypes {
+		if err := il.sync(ctx, incomeType); err != nil {
+			logger.Warn("⚠️ [账户流水] %s %s 同步失败: %v", il.symbol, incomeType, err)
+			return
+		}
+	}
+
+	end := time.Now()
+	start := end.Add(-time.Duration(il.cfg.IncomeSync.ReportHours) * time.Hour)
+	report, err := il.Report(start, end)
+	if err != nil {
+		logger.Warn("⚠️ [账户流水] %s 生成对账报告失败: %v", il.symbol, err)
+		return
+	}
+
+	il.mu.Lock()
+	il.lastReport = report
+	changed := report.Flagged != il.lastFlagged
+	il.lastFlagged = report.Flagged
+	il.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if !report.Flagged {
+		logger.Info("✅ [账户流水] %s 盈亏对账偏差已恢复到容差内 (偏差: %.4f)", il.symbol, report.Divergence)
+		return
+	}
+
+	message := fmt.Sprintf("%s 最近 %d 小时本地盈亏 %.4f 与交易所已实现盈亏 %.4f 偏差 %.4f (%.2f%%)，超出容差",
+		il.symbol, il.cfg.IncomeSync.ReportHours, report.ComputedPnL, report.ExchangeRealizedPnL,
+		report.Divergence, report.DivergencePercent)
+	logger.Warn("⚠️ [账户流水] %s", message)
+	if il.eventBus != nil {
+		il.eventBus.Publish(&event.Event{
+			Type: event.EventTypePnLDivergence,
+			Data: map[string]interface{}{
+				"exchange":              il.exchangeName,
+				"symbol":                il.symbol,
+				"computed_pnl":          report.ComputedPnL,
+				"exchange_realized_pnl": report.ExchangeRealizedPnL,
+				"divergence":            report.Divergence,
+				"divergence_percent":    report.DivergencePercent,
+				"message":               message,
+			},
+		})
+	}
+}
+
+// sync 从该类型最近一笔已入账流水之后开始增量拉取
+func (il *IncomeLedger) sync(ctx context.Context, incomeType string) error {
+	latest, err := il.storage.GetLatestIncomeTime(il.exchangeName, il.symbol, incomeType)
+	if err != nil {
+		return fmt.Errorf("查询最近入账时间失败: %w", err)
+	}
+	startTime := time.Now().Add(-time.Duration(il.cfg.IncomeSync.LookbackDays) * 24 * time.Hour)
+	if !latest.IsZero() {
+		startTime = latest.Add(time.Millisecond)
+	}
+
+	total := 0
+	for {
+		reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		records, err := il.provider.GetIncomeHistory(reqCtx, il.symbol, incomeType, startTime, time.Time{})
+		cancel()
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			break
+		}
+
+		rows := make([]*storage.IncomeRecord, 0, len(records))
+		for _, r := range records {
+			rows = append(rows, &storage.IncomeRecord{
+				Exchange:   il.exchangeName,
+				Symbol:     r.Symbol,
+				IncomeType: r.IncomeType,
+				Asset:      r.Asset,
+				Amount:     r.Amount,
+				TranID:     r.TranID,
+				IncomeTime: r.Time,
+			})
+			if r.Time.After(startTime) {
+				startTime = r.Time
+			}
+		}
+		inserted, err := il.storage.SaveIncomeRecords(rows)
+		if err != nil {
+			return fmt.Errorf("保存失败: %w", err)
+		}
+		total += inserted
+
+		if len(records) < fundingLedgerPageSize {
+			break
+		}
+		startTime = startTime.Add(time.Millisecond)
+	}
+
+	if total > 0 {
+		logger.Info("📒 [账户流水] %s 新增 %d 笔 %s 记录", il.symbol, total, incomeType)
+	}
+	return nil
+}
+
+// Report 生成 [start, end] 区间的盈亏对账报告
+// 本地成交盈亏不含手续费，对应交易所的 REALIZED_PNL；手续费与资金费单独列出
+func (il *IncomeLedger) Report(start, end time.Time) (*IncomeReconciliationReport, error) {
+	sums, err := il.storage.SumIncomeByType(il.exchangeName, il.symbol, start, end)
+	if err != nil {
+		return nil, err
+	}
+	computed, count, err := il.storage.SumTradePnL(il.tradeExchange, il.symbol, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &IncomeReconciliationReport{
+		Exchange:            il.exchangeName,
+		Symbol:              il.symbol,
+		Start:               start,
+		End:                 end,
+		ComputedPnL:         computed,
+		TradeCount:          count,
+		ExchangeRealizedPnL: sums[exchange.IncomeTypeRealizedPnL],
+		Commission:          sums[exchange.IncomeTypeCommission],
+		FundingFee:          sums[exchange.IncomeTypeFundingFee],
+		ToleranceAbs:        il.cfg.IncomeSync.ToleranceAbs,
+		TolerancePercent:    il.cfg.IncomeSync.TolerancePercent,
+		GeneratedAt:         time.Now(),
+	}
+	report.ExchangeNetPnL = report.ExchangeRealizedPnL + report.Commission + report.FundingFee
+	report.Divergence = report.ComputedPnL - report.ExchangeRealizedPnL
+
+	absDivergence := math.Abs(report.Divergence)
+	if base := math.Abs(report.ExchangeRealizedPnL); base > 0 {
+		report.DivergencePercent = absDivergence / base * 100
+	} else if absDivergence > 0 {
+		report.DivergencePercent = 100
+	}
+	report.Flagged = absDivergence > report.ToleranceAbs && report.DivergencePercent > report.TolerancePercent
+	return report, nil
+}
+
+// LastReport 获取最近一次同步后生成的对账报告，尚未完成同步时返回 nil
+func (il *IncomeLedger) LastReport() *IncomeReconciliationReport {
+	il.mu.RLock()
+	defer il.mu.RUnlock()
+	return il.lastReport
+}
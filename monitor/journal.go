@@ -0,0 +1,217 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"quantmesh/event"
+	"quantmesh/logger"
+	"quantmesh/storage"
+	"quantmesh/utils"
+)
+
+// 交易日志条目来源
+const (
+	JournalSourceAuto   = "auto"
+	JournalSourceManual = "manual"
+
+	// JournalEventDrawdown 累计已实现盈亏大幅回撤（由日志服务自身检测）
+	JournalEventDrawdown = "drawdown"
+
+	journalQueueSize = 256
+)
+
+// Journal 交易日志服务
+// 监听事件总线自动记录重要事件，定期检查累计已实现盈亏回撤，并接收手动笔记；
+// 每条日志都记录当时的累计已实现盈亏，便于对照收益曲线复盘决策
+type Journal struct {
+	db             storage.Storage
+	autoEvents     map[event.EventType]bool
+	drawdownAmount float64
+	interval       time.Duration
+
+	queue chan *storage.JournalEntry
+
+	mu            sync.Mutex
+	peakEquity    float64
+	peakSet       bool
+	drawdownNoted bool // 本轮回撤已记录，权益创新高后重置
+}
+
+// NewJournal 创建交易日志服务
+func NewJournal(db storage.Storage, autoEvents []string, drawdownAmount float64, checkIntervalSeconds int) *Journal {
+	interval := time.Duration(checkIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	events := make(map[event.EventType]bool, len(autoEvents))
+	for _, e := range autoEvents {
+		events[event.EventType(e)] = true
+	}
+	return &Journal{
+		db:             db,
+		autoEvents:     events,
+		drawdownAmount: drawdownAmount,
+		interval:       interval,
+		queue:          make(chan *storage.JournalEntry, journalQueueSize),
+	}
+}
+
+// Start 启动日志写入和回撤检查，并注册事件总线监听器
+func (j *Journal) Start(ctx context.Context, eventBus *event.EventBus) {
+	logger.Info("📓 启动交易日志 (自动记录事件: %d 种, 回撤阈值: %.2f)", len(j.autoEvents), j.drawdownAmount)
+
+	if eventBus != nil {
+		eventBus.AddListener(j.onEvent)
+	}
+
+	utils.GoSupervised(ctx, "journal-writer", func(ctx context.Context) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case entry := <-j.queue:
+				if err := j.save(entry); err != nil {
+					logger.Warn("⚠️ [交易日志] %v", err)
+				}
+			}
+		}
+	})
+
+	if j.drawdownAmount > 0 {
+		utils.GoSupervised(ctx, "journal-drawdown", func(ctx context.Context) {
+			ticker := time.NewTicker(j.interval)
+			defer ticker.Stop()
+			for {
+				j.checkDrawdown()
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		})
+	}
+}
+
+// onEvent 事件总线监听器（同步调用，只入队不阻塞）
+func (j *Journal) onEvent(e *event.Event) {
+	if !j.autoEvents[e.Type] {
+		return
+	}
+	entry := &storage.JournalEntry{
+		Exchange:  stringField(e.Data, "exchange"),
+		Symbol:    stringField(e.Data, "symbol"),
+		Strategy:  stringField(e.Data, "strategy"),
+		Source:    JournalSourceAuto,
+		EventType: string(e.Type),
+		Title:     event.GetEventTitle(e.Type),
+		Content:   stringField(e.Data, "message"),
+		CreatedAt: e.Timestamp,
+	}
+	if entry.Content == "" {
+		entry.Content = formatEventData(e.Data)
+	}
+	j.enqueue(entry)
+}
+
+// Record 记录一条自动日志（如参数变更），异步写入
+func (j *Journal) Record(exchange, symbol, eventType, title, content string) {
+	j.enqueue(&storage.JournalEntry{
+		Exchange:  exchange,
+		Symbol:    symbol,
+		Source:    JournalSourceAuto,
+		EventType: eventType,
+		Title:     title,
+		Content:   content,
+		CreatedAt: time.Now(),
+	})
+}
+
+// AddNote 同步保存一条手动笔记
+func (j *Journal) AddNote(entry *storage.JournalEntry) error {
+	entry.ID = 0
+	entry.Source = JournalSourceManual
+	entry.EventType = ""
+	entry.CreatedAt = time.Now()
+	return j.save(entry)
+}
+
+// Query 查询日志
+func (j *Journal) Query(q storage.JournalQuery) ([]*storage.JournalEntry, error) {
+	return j.db.QueryJournalEntries(q)
+}
+
+func (j *Journal) enqueue(entry *storage.JournalEntry) {
+	select {
+	case j.queue <- entry:
+	default:
+		logger.Warn("⚠️ [交易日志] 写入队列已满，丢弃条目: %s", entry.Title)
+	}
+}
+
+// save 补充记录时的累计已实现盈亏后写入存储
+func (j *Journal) save(entry *storage.JournalEntry) error {
+	equity, err := j.equity(entry.Exchange)
+	if err != nil {
+		logger.Warn("⚠️ [交易日志] 获取累计盈亏失败: %v", err)
+	}
+	entry.Equity = equity
+	return j.db.SaveJournalEntry(entry)
+}
+
+// equity 累计已实现盈亏（exchange 为空时为全部交易所）
+func (j *Journal) equity(exchange string) (float64, error) {
+	stats, err := j.db.GetStatisticsSummaryByExchange(exchange)
+	if err != nil || stats == nil {
+		return 0, err
+	}
+	return stats.TotalPnL, nil
+}
+
+// checkDrawdown 累计已实现盈亏自高点回撤超过阈值时记录一条日志（每轮回撤只记录一次）
+func (j *Journal) checkDrawdown() {
+	equity, err := j.equity("")
+	if err != nil {
+		logger.Warn("⚠️ [交易日志] 回撤检查失败: %v", err)
+		return
+	}
+
+	j.mu.Lock()
+	if !j.peakSet || equity > j.peakEquity {
+		j.peakEquity = equity
+		j.peakSet = true
+		j.drawdownNoted = false
+	}
+	drawdown := j.peakEquity - equity
+	shouldRecord := drawdown >= j.drawdownAmount && !j.drawdownNoted
+	if shouldRecord {
+		j.drawdownNoted = true
+	}
+	peak := j.peakEquity
+	j.mu.Unlock()
+
+	if shouldRecord {
+		j.Record("", "", JournalEventDrawdown, "累计盈亏大幅回撤",
+			fmt.Sprintf("累计已实现盈亏从高点 %.2f 回撤 %.2f 至 %.2f", peak, drawdown, equity))
+	}
+}
+
+func stringField(data map[string]interface{}, key string) string {
+	if v, ok := data[key]; ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// formatEventData 事件没有 message 字段时，将数据拼成 key=value 形式
+func formatEventData(data map[string]interface{}) string {
+	parts := make([]string, 0, len(data))
+	for k, v := range data {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, v))
+	}
+	return strings.Join(parts, ", ")
+}
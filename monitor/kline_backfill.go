@@ -0,0 +1,248 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/exchange"
+	"quantmesh/logger"
+	"quantmesh/storage"
+	"quantmesh/utils"
+)
+
+// KlineCoverage 某个周期最近一次缺口检测的结果
+type KlineCoverage struct {
+	Exchange   string           `json:"exchange"`
+	Symbol     string           `json:"symbol"`
+	Interval   string           `json:"interval"`
+	Start      time.Time        `json:"start"`      // 检测区间起点（首根 K 线的开盘时间）
+	End        time.Time        `json:"end"`        // 检测区间终点（未完结 K 线的开盘时间，不含）
+	Expected   int              `json:"expected"`   // 区间内应有的 K 线根数
+	Stored     int              `json:"stored"`     // 补齐后本地已保存的根数
+	Backfilled int              `json:"backfilled"` // 本次从交易所补齐写入的根数
+	Gaps       []utils.KlineGap `json:"gaps"`       // 补齐后仍缺失的区间（交易所也没有数据或超出单次拉取上限）
+	CheckedAt  time.Time        `json:"checked_at"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// KlineBackfiller K 线缺口补齐服务
+// 本地保存配置周期的已完结 K 线；启动时检测回溯区间内的缺口（如停机期间）并从交易所补齐，
+// 之后定期执行同样的检测，新完结的 K 线作为缺口被增量写入，保证指标和图表使用的历史数据连续
+type KlineBackfiller struct {
+	cfg          *config.Config
+	storage      storage.Storage
+	ex           exchange.IExchange
+	exchangeName string
+	symbol       string
+
+	mu       sync.RWMutex
+	coverage map[string]*KlineCoverage // 周期 -> 最近一次检测结果
+	settled  map[string]time.Time      // 周期 -> 已确认交易所也没有数据的缺口截止时间，之后不再重复拉取
+}
+
+// NewKlineBackfiller 创建 K 线缺口补齐服务，存储不可用时返回 nil
+func NewKlineBackfiller(cfg *config.Config, st storage.Storage, ex exchange.IExchange, symbol string) *KlineBackfiller {
+	if st == nil {
+		return nil
+	}
+	return &KlineBackfiller{
+		cfg:          cfg,
+		storage:      st,
+		ex:           ex,
+		exchangeName: ex.GetName(),
+		symbol:       symbol,
+		coverage:     make(map[string]*KlineCoverage),
+		settled:      make(map[string]time.Time),
+	}
+}
+
+// Start 启动时先补齐一次，之后定期增量同步
+func (kb *KlineBackfiller) Start(ctx context.Context) {
+	interval := time.Duration(kb.cfg.KlineBackfill.PollMinutes) * time.Minute
+	logger.Info("🕯️ [K线补齐] 启动 (交易所: %s, 交易对: %s, 周期: %v, 回溯: %d 小时, 间隔: %v)",
+		kb.exchangeName, kb.symbol, kb.cfg.KlineBackfill.Intervals, kb.cfg.KlineBackfill.LookbackHours, interval)
+
+	utils.GoSupervised(ctx, "kline-backfill:"+kb.exchangeName+":"+kb.symbol, func(ctx context.Context) {
+		kb.BackfillAll(ctx)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				kb.BackfillAll(ctx)
+			}
+		}
+	})
+}
+
+// BackfillAll 依次检测并补齐全部配置周期
+func (kb *KlineBackfiller) BackfillAll(ctx context.Context) []*KlineCoverage {
+	result := make([]*KlineCoverage, 0, len(kb.cfg.KlineBackfill.Intervals))
+	for _, interval := range kb.cfg.KlineBackfill.Intervals {
+		if ctx.Err() != nil {
+			break
+		}
+		result = append(result, kb.Backfill(ctx, interval))
+	}
+	return result
+}
+
+// Backfill 检测单个周期回溯区间内的缺口，从最早的缺口开始向交易所拉取最近的 K 线补齐
+// 交易所 K 线接口只能按根数拉取最近的数据，缺口早于单次拉取上限能覆盖的范围时只补齐上限内的部分
+func (kb *KlineBackfiller) Backfill(ctx context.Context, interval string) *KlineCoverage {
+	cov := &KlineCoverage{Exchange: kb.exchangeName, Symbol: kb.symbol, Interval: interval, CheckedAt: time.Now()}
+	defer kb.record(cov)
+
+	d, ok := utils.KlineIntervalDuration(interval)
+	if !ok {
+		cov.Error = fmt.Sprintf("不支持的 K 线周期 %s", interval)
+		return cov
+	}
+	now := time.Now()
+	cov.End = now.Truncate(d)
+	cov.Start = now.Add(-time.Duration(kb.cfg.KlineBackfill.LookbackHours) * time.Hour).Truncate(d)
+	cov.Expected = int(cov.End.Sub(cov.Start) / d)
+
+	gaps, stored, err := kb.detect(interval, d, cov.Start, cov.End)
+	if err != nil {
+		cov.Error = err.Error()
+		return cov
+	}
+	pending := kb.pendingGaps(interval, gaps)
+	if len(pending) > 0 {
+		// 多拉一根：最近一根通常是未完结的 K 线，写入前会被过滤
+		limit := int(cov.End.Sub(pending[0].Start)/d) + 1
+		if limit > kb.cfg.KlineBackfill.MaxFetch {
+			limit = kb.cfg.KlineBackfill.MaxFetch
+		}
+		written, err := kb.fetch(ctx, interval, d, cov.Start, limit)
+		if err != nil {
+			cov.Error = err.Error()
+			logger.Warn("⚠️ [K线补齐] %s %s 拉取失败: %v", kb.symbol, interval, err)
+		}
+		cov.Backfilled = written
+		if written > 0 {
+			if gaps, stored, err = kb.detect(interval, d, cov.Start, cov.End); err != nil {
+				cov.Error = err.Error()
+			}
+		}
+		if cov.Error == "" && len(gaps) > 0 {
+			kb.mu.Lock()
+			kb.settled[interval] = gaps[len(gaps)-1].End
+			kb.mu.Unlock()
+		}
+	}
+	cov.Stored, cov.Gaps = stored, gaps
+
+	// 首次补齐和停机后的补齐才记录日志，定期同步只写入新完结的一两根
+	if cov.Backfilled > 1 {
+		logger.Info("🕯️ [K线补齐] %s %s 补齐 %d 根 K 线 (区间内 %d/%d)", kb.symbol, interval, cov.Backfilled, cov.Stored, cov.Expected)
+	}
+	if missing := missingCount(cov.Gaps); missing > 0 && cov.Error == "" && len(pending) > 0 {
+		logger.Warn("⚠️ [K线补齐] %s %s 仍有 %d 处缺口共 %d 根 K 线无法补齐（最早 %s）",
+			kb.symbol, interval, len(cov.Gaps), missing, cov.Gaps[0].Start.Format(time.RFC3339))
+	}
+	return cov
+}
+
+// pendingGaps 尚未确认无法补齐的缺口（交易所维护等导致的缺口补齐一次后不再重复拉取）
+func (kb *KlineBackfiller) pendingGaps(interval string, gaps []utils.KlineGap) []utils.KlineGap {
+	kb.mu.RLock()
+	settled := kb.settled[interval]
+	kb.mu.RUnlock()
+	for i, g := range gaps {
+		if g.Start.After(settled) {
+			return gaps[i:]
+		}
+	}
+	return nil
+}
+
+// detect 读取区间内已保存的 K 线并检测缺口
+func (kb *KlineBackfiller) detect(interval string, d time.Duration, start, end time.Time) ([]utils.KlineGap, int, error) {
+	klines, err := kb.storage.QueryKlines(kb.exchangeName, kb.symbol, interval, start, end.Add(-d), 0)
+	if err != nil {
+		return nil, 0, fmt.Errorf("读取本地K线失败: %w", err)
+	}
+	openTimes := make([]time.Time, 0, len(klines))
+	for _, k := range klines {
+		openTimes = append(openTimes, k.OpenTime)
+	}
+	return utils.FindKlineGaps(openTimes, d, start, end), len(klines), nil
+}
+
+// fetch 拉取最近 limit 根 K 线，写入回溯区间内已完结的部分
+func (kb *KlineBackfiller) fetch(ctx context.Context, interval string, d time.Duration, start time.Time, limit int) (int, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	candles, err := kb.ex.GetHistoricalKlines(reqCtx, kb.symbol, interval, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	rows := make([]*storage.Kline, 0, len(candles))
+	for _, c := range candles {
+		openTime := time.UnixMilli(c.Timestamp).UTC()
+		if openTime.Before(start) || openTime.Add(d).After(now) || c.Close <= 0 {
+			continue
+		}
+		rows = append(rows, &storage.Kline{
+			Exchange: kb.exchangeName,
+			Symbol:   kb.symbol,
+			Interval: interval,
+			OpenTime: openTime,
+			Open:     c.Open,
+			High:     c.High,
+			Low:      c.Low,
+			Close:    c.Close,
+			Volume:   c.Volume,
+		})
+	}
+	written, err := kb.storage.SaveKlines(rows)
+	if err != nil {
+		return 0, fmt.Errorf("保存K线失败: %w", err)
+	}
+	return written, nil
+}
+
+// record 保存最近一次检测结果
+func (kb *KlineBackfiller) record(cov *KlineCoverage) {
+	kb.mu.Lock()
+	kb.coverage[cov.Interval] = cov
+	kb.mu.Unlock()
+}
+
+// Coverage 各周期最近一次检测结果（按配置顺序，尚未检测的周期不返回）
+func (kb *KlineBackfiller) Coverage() []*KlineCoverage {
+	kb.mu.RLock()
+	defer kb.mu.RUnlock()
+	result := make([]*KlineCoverage, 0, len(kb.coverage))
+	for _, interval := range kb.cfg.KlineBackfill.Intervals {
+		if cov, ok := kb.coverage[interval]; ok {
+			copied := *cov
+			result = append(result, &copied)
+		}
+	}
+	return result
+}
+
+// Klines 读取本地保存的最近 limit 根 K 线（按时间升序）
+func (kb *KlineBackfiller) Klines(interval string, limit int) ([]*storage.Kline, error) {
+	return kb.storage.QueryKlines(kb.exchangeName, kb.symbol, interval, time.Time{}, time.Now(), limit)
+}
+
+// missingCount 缺失的 K 线总根数
+func missingCount(gaps []utils.KlineGap) int {
+	total := 0
+	for _, g := range gaps {
+		total += g.Missing
+	}
+	return total
+}
@@ -0,0 +1,151 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"quantmesh/event"
+	"quantmesh/exchange"
+	"quantmesh/logger"
+	"quantmesh/utils"
+)
+
+const (
+	// liquidationCheckInterval 强平距离检测间隔
+	liquidationCheckInterval = 30 * time.Second
+	// liquidationRenotifyInterval 持续处于危险区间时重复告警的间隔
+	liquidationRenotifyInterval = 15 * time.Minute
+	// liquidationMaintenanceMarginRate 交易所未返回强平价时估算使用的维持保证金率
+	liquidationMaintenanceMarginRate = 0.005
+)
+
+// LiquidationMonitor 强平距离监控
+// 标记价格距强平价的比例低于阈值时发布严重事件，用于触发电话/短信等告警升级
+type LiquidationMonitor struct {
+	ex        exchange.IExchange
+	symbol    string
+	warnRatio float64
+	eventBus  *event.EventBus
+
+	mu         sync.Mutex
+	inDanger   bool
+	lastNotice time.Time
+}
+
+// NewLiquidationMonitor 创建强平距离监控
+func NewLiquidationMonitor(ex exchange.IExchange, symbol string, warnRatio float64, eventBus *event.EventBus) *LiquidationMonitor {
+	if warnRatio <= 0 {
+		warnRatio = 0.05
+	}
+	return &LiquidationMonitor{
+		ex:        ex,
+		symbol:    symbol,
+		warnRatio: warnRatio,
+		eventBus:  eventBus,
+	}
+}
+
+// Start 启动定期检测
+func (m *LiquidationMonitor) Start(ctx context.Context) {
+	logger.Info("🛡️ [%s] 强平距离监控已启动 (告警阈值: %.1f%%)", m.symbol, m.warnRatio*100)
+
+	utils.GoSupervised(ctx, fmt.Sprintf("liquidation-monitor:%s:%s", m.ex.GetName(), m.symbol), func(ctx context.Context) {
+		ticker := time.NewTicker(liquidationCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.check(ctx)
+			}
+		}
+	})
+}
+
+func (m *LiquidationMonitor) check(ctx context.Context) {
+	reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	positions, err := m.ex.GetPositions(reqCtx, m.symbol)
+	cancel()
+	if err != nil {
+		logger.Debug("⚠️ [%s] 强平距离检测查询持仓失败: %v", m.symbol, err)
+		return
+	}
+
+	// 取距离强平最近的持仓
+	var worst *exchange.Position
+	worstLiq, worstDistance := 0.0, math.MaxFloat64
+	for _, pos := range positions {
+		if pos == nil || pos.Symbol != m.symbol || pos.Size == 0 || pos.MarkPrice <= 0 {
+			continue
+		}
+		liq := pos.LiquidationPrice
+		if liq <= 0 {
+			liq = estimateLiquidationPrice(pos)
+		}
+		if liq <= 0 {
+			continue
+		}
+		distance := math.Abs(pos.MarkPrice-liq) / pos.MarkPrice
+		if distance < worstDistance {
+			worst, worstLiq, worstDistance = pos, liq, distance
+		}
+	}
+
+	m.evaluate(worst, worstLiq, worstDistance, time.Now())
+}
+
+// evaluate 根据最近强平距离更新告警状态
+func (m *LiquidationMonitor) evaluate(pos *exchange.Position, liq, distance float64, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if pos == nil || distance >= m.warnRatio {
+		// 留出回差，避免在阈值附近反复告警
+		if m.inDanger && (pos == nil || distance >= m.warnRatio*1.5) {
+			m.inDanger = false
+			logger.Info("✅ [%s] 已脱离强平危险区间", m.symbol)
+		}
+		return
+	}
+
+	if m.inDanger && now.Sub(m.lastNotice) < liquidationRenotifyInterval {
+		return
+	}
+	m.inDanger = true
+	m.lastNotice = now
+
+	logger.Error("🚨 [%s] 标记价格 %.4f 距强平价 %.4f 仅 %.2f%%，请立即处理", m.symbol, pos.MarkPrice, liq, distance*100)
+	if m.eventBus == nil {
+		return
+	}
+	m.eventBus.Publish(&event.Event{
+		Type:      event.EventTypeLiquidationRisk,
+		Timestamp: now,
+		Data: map[string]interface{}{
+			"exchange":          m.ex.GetName(),
+			"symbol":            m.symbol,
+			"position_size":     pos.Size,
+			"mark_price":        pos.MarkPrice,
+			"liquidation_price": liq,
+			"distance_ratio":    distance,
+			"message":           fmt.Sprintf("%s 标记价格距强平价仅 %.2f%%", m.symbol, distance*100),
+		},
+	})
+}
+
+// estimateLiquidationPrice 交易所未提供强平价时，按逐仓杠杆估算（全仓依赖账户余额，无法估算）
+func estimateLiquidationPrice(pos *exchange.Position) float64 {
+	if pos.EntryPrice <= 0 || pos.Leverage <= 0 || !strings.EqualFold(pos.MarginType, "isolated") {
+		return 0
+	}
+	inv := 1 / float64(pos.Leverage)
+	if pos.Size > 0 {
+		return pos.EntryPrice * (1 - inv + liquidationMaintenanceMarginRate)
+	}
+	return pos.EntryPrice * (1 + inv - liquidationMaintenanceMarginRate)
+}
@@ -0,0 +1,400 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"quantmesh/event"
+	"quantmesh/logger"
+	"quantmesh/metrics"
+	"quantmesh/utils"
+)
+
+// MaintenanceWindow 交易所维护窗口
+type MaintenanceWindow struct {
+	Exchange string    `json:"exchange"`
+	Title    string    `json:"title"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Source   string    `json:"source"` // exchange: 交易所公告, config: 手动配置
+}
+
+// ExchangeSystemStatus 交易所系统状态
+type ExchangeSystemStatus struct {
+	Maintenance bool                // 交易所声明当前处于维护中
+	Message     string              // 状态说明
+	Windows     []MaintenanceWindow // 计划中或进行中的维护
+}
+
+// MaintenanceFeed 交易所系统状态来源（公开接口，无需 API 密钥）
+type MaintenanceFeed interface {
+	FetchStatus(ctx context.Context) (*ExchangeSystemStatus, error)
+}
+
+// NewMaintenanceFeed 按交易所创建系统状态来源，不支持的交易所返回 nil（仅使用手动配置的窗口）
+func NewMaintenanceFeed(exchangeName string) MaintenanceFeed {
+	client := &http.Client{Timeout: 10 * time.Second}
+	switch strings.ToLower(exchangeName) {
+	case "binance":
+		return &publicStatusFeed{client: client, url: "https://api.binance.com/sapi/v1/system/status", parse: parseBinanceStatus}
+	case "okx":
+		return &publicStatusFeed{client: client, url: "https://www.okx.com/api/v5/system/status", parse: parseOKXStatus}
+	case "bybit":
+		return &publicStatusFeed{client: client, url: "https://api.bybit.com/v5/system/status", parse: parseBybitStatus}
+	}
+	return nil
+}
+
+// publicStatusFeed 基于交易所公开状态接口的实现
+type publicStatusFeed struct {
+	client *http.Client
+	url    string
+	parse  func(body []byte) (*ExchangeSystemStatus, error)
+}
+
+func (f *publicStatusFeed) FetchStatus(ctx context.Context) (*ExchangeSystemStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("状态接口返回 HTTP %d", resp.StatusCode)
+	}
+	return f.parse(body)
+}
+
+// parseBinanceStatus 解析币安系统状态：{"status":0,"msg":"normal"}，1 表示维护中
+func parseBinanceStatus(body []byte) (*ExchangeSystemStatus, error) {
+	var r struct {
+		Status int    `json:"status"`
+		Msg    string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, err
+	}
+	return &ExchangeSystemStatus{Maintenance: r.Status == 1, Message: r.Msg}, nil
+}
+
+// okxTradingServices OKX 中影响合约交易的服务类型（0 WebSocket、5 交易服务、8/9 分批维护的交易服务）
+var okxTradingServices = map[string]bool{"0": true, "5": true, "8": true, "9": true}
+
+// parseOKXStatus 解析 OKX 系统状态（包含计划维护公告）
+func parseOKXStatus(body []byte) (*ExchangeSystemStatus, error) {
+	var r struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Title       string `json:"title"`
+			State       string `json:"state"`
+			Begin       string `json:"begin"`
+			End         string `json:"end"`
+			ServiceType string `json:"serviceType"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, err
+	}
+	if r.Code != "0" {
+		return nil, fmt.Errorf("OKX 状态接口错误: %s %s", r.Code, r.Msg)
+	}
+	status := &ExchangeSystemStatus{}
+	for _, d := range r.Data {
+		if !okxTradingServices[d.ServiceType] {
+			continue
+		}
+		if w, ok := parseStatusWindow("okx", d.Title, d.State, d.Begin, d.End); ok {
+			status.Windows = append(status.Windows, w)
+			if d.State == "ongoing" {
+				status.Maintenance = true
+				status.Message = d.Title
+			}
+		}
+	}
+	return status, nil
+}
+
+// parseBybitStatus 解析 Bybit 系统状态（product 1 为合约）
+func parseBybitStatus(body []byte) (*ExchangeSystemStatus, error) {
+	var r struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+		Result  struct {
+			List []struct {
+				Title   string `json:"title"`
+				State   string `json:"state"`
+				Begin   string `json:"begin"`
+				End     string `json:"end"`
+				Product []int  `json:"product"`
+			} `json:"list"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, err
+	}
+	if r.RetCode != 0 {
+		return nil, fmt.Errorf("Bybit 状态接口错误: %d %s", r.RetCode, r.RetMsg)
+	}
+	status := &ExchangeSystemStatus{}
+	for _, d := range r.Result.List {
+		affectsFutures := len(d.Product) == 0
+		for _, p := range d.Product {
+			if p == 1 {
+				affectsFutures = true
+			}
+		}
+		if !affectsFutures {
+			continue
+		}
+		if w, ok := parseStatusWindow("bybit", d.Title, d.State, d.Begin, d.End); ok {
+			status.Windows = append(status.Windows, w)
+			if d.State == "ongoing" {
+				status.Maintenance = true
+				status.Message = d.Title
+			}
+		}
+	}
+	return status, nil
+}
+
+// parseStatusWindow 解析毫秒时间戳形式的维护窗口，已完成或已取消的维护忽略
+func parseStatusWindow(exchangeName, title, state, begin, end string) (MaintenanceWindow, bool) {
+	if state == "completed" || state == "canceled" {
+		return MaintenanceWindow{}, false
+	}
+	beginMs, err1 := strconv.ParseInt(begin, 10, 64)
+	endMs, err2 := strconv.ParseInt(end, 10, 64)
+	if err1 != nil || err2 != nil {
+		return MaintenanceWindow{}, false
+	}
+	return MaintenanceWindow{
+		Exchange: exchangeName,
+		Title:    title,
+		Start:    time.UnixMilli(beginMs),
+		End:      time.UnixMilli(endMs),
+		Source:   "exchange",
+	}, true
+}
+
+// MaintenanceMonitor 交易所维护窗口监控
+// 定期检查交易所系统状态和计划维护公告，维护期间暂停该交易所所有交易对的新订单，
+// 并静默预期内的断线告警；维护结束后先做健康复检，通过后才恢复交易
+type MaintenanceMonitor struct {
+	exchangeName string
+	feed         MaintenanceFeed
+	windows      []MaintenanceWindow // 手动配置的窗口
+	interval     time.Duration
+	preWindow    time.Duration
+	healthCheck  func(ctx context.Context) error
+	eventBus     *event.EventBus
+	now          func() time.Time
+
+	mu          sync.Mutex
+	pausers     map[string]TradingPauser
+	pausedByUs  map[string]bool // 仅恢复由维护监控触发的暂停
+	feedWindows []MaintenanceWindow
+	active      bool
+	reason      string
+}
+
+// NewMaintenanceMonitor 创建维护窗口监控（每个交易所一个实例）
+func NewMaintenanceMonitor(exchangeName string, windows []MaintenanceWindow, interval, preWindow time.Duration, eventBus *event.EventBus) *MaintenanceMonitor {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &MaintenanceMonitor{
+		exchangeName: exchangeName,
+		feed:         NewMaintenanceFeed(exchangeName),
+		windows:      windows,
+		interval:     interval,
+		preWindow:    preWindow,
+		eventBus:     eventBus,
+		now:          time.Now,
+		pausers:      make(map[string]TradingPauser),
+		pausedByUs:   make(map[string]bool),
+	}
+}
+
+// SetHealthCheck 设置维护结束后的健康复检（如查询一次行情），返回错误则继续保持暂停
+func (m *MaintenanceMonitor) SetHealthCheck(fn func(ctx context.Context) error) {
+	m.healthCheck = fn
+}
+
+// AddPauser 注册需要在维护期间暂停的交易对
+func (m *MaintenanceMonitor) AddPauser(symbol string, pauser TradingPauser) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pausers[symbol] = pauser
+}
+
+// Start 启动定期检查
+func (m *MaintenanceMonitor) Start(ctx context.Context) {
+	logger.Info("🛠️ [%s] 维护窗口监控已启动 (间隔: %v, 状态接口: %v, 手动窗口: %d)",
+		m.exchangeName, m.interval, m.feed != nil, len(m.windows))
+
+	utils.GoSupervised(ctx, "maintenance-monitor:"+m.exchangeName, func(ctx context.Context) {
+		m.check(ctx)
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.check(ctx)
+			}
+		}
+	})
+}
+
+// IsActive 当前是否处于维护中
+func (m *MaintenanceMonitor) IsActive() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.active
+}
+
+// Windows 返回已知的维护窗口（交易所公告 + 手动配置）
+func (m *MaintenanceMonitor) Windows() []MaintenanceWindow {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]MaintenanceWindow, 0, len(m.windows)+len(m.feedWindows))
+	result = append(result, m.windows...)
+	return append(result, m.feedWindows...)
+}
+
+// check 执行一次状态检查
+func (m *MaintenanceMonitor) check(ctx context.Context) {
+	var status *ExchangeSystemStatus
+	var feedErr error
+	if m.feed != nil {
+		reqCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		status, feedErr = m.feed.FetchStatus(reqCtx)
+		cancel()
+		if feedErr != nil {
+			// 状态接口本身失败不代表维护，沿用上次的公告窗口
+			logger.Debug("[%s] 获取交易所系统状态失败: %v", m.exchangeName, feedErr)
+		}
+	}
+
+	m.mu.Lock()
+	if status != nil {
+		m.feedWindows = status.Windows
+	}
+	inMaintenance, reason := m.evaluate(m.now(), status)
+	active := m.active
+	m.mu.Unlock()
+
+	switch {
+	case inMaintenance && !active:
+		m.enter(reason)
+	case !inMaintenance && active:
+		// 恢复前复检：状态接口必须可用，且健康检查通过
+		if feedErr != nil {
+			logger.Warn("⚠️ [%s] 维护窗口已结束，但交易所状态接口不可用，继续暂停: %v", m.exchangeName, feedErr)
+			return
+		}
+		if m.healthCheck != nil {
+			hcCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+			err := m.healthCheck(hcCtx)
+			cancel()
+			if err != nil {
+				logger.Warn("⚠️ [%s] 维护窗口已结束，但健康检查未通过，继续暂停: %v", m.exchangeName, err)
+				return
+			}
+		}
+		m.exit()
+	}
+}
+
+// evaluate 判断当前是否处于维护中（调用方需持有锁）
+func (m *MaintenanceMonitor) evaluate(now time.Time, status *ExchangeSystemStatus) (bool, string) {
+	if status != nil && status.Maintenance {
+		if status.Message != "" {
+			return true, status.Message
+		}
+		return true, "交易所系统维护中"
+	}
+	for _, windows := range [][]MaintenanceWindow{m.windows, m.feedWindows} {
+		for _, w := range windows {
+			if !now.Before(w.Start.Add(-m.preWindow)) && now.Before(w.End) {
+				title := w.Title
+				if title == "" {
+					title = "计划维护"
+				}
+				return true, fmt.Sprintf("%s (%s ~ %s)", title,
+					w.Start.Local().Format("01-02 15:04"), w.End.Local().Format("01-02 15:04"))
+			}
+		}
+	}
+	return false, ""
+}
+
+// enter 进入维护：暂停下单、静默断线告警
+func (m *MaintenanceMonitor) enter(reason string) {
+	m.mu.Lock()
+	m.active = true
+	m.reason = reason
+	for symbol, p := range m.pausers {
+		if !p.IsPaused() {
+			p.Pause()
+			m.pausedByUs[symbol] = true
+		}
+	}
+	m.mu.Unlock()
+
+	event.SetExchangeMaintenance(m.exchangeName, true)
+	metrics.GetPrometheusMetrics().SetExchangeMaintenance(m.exchangeName, true)
+	logger.Warn("🛠️ [%s] 交易所进入维护: %s，已暂停新订单", m.exchangeName, reason)
+	m.publish(event.EventTypeExchangeMaintenance, fmt.Sprintf("交易所进入维护：%s，已暂停新订单", reason))
+}
+
+// exit 维护结束：恢复由本监控暂停的交易对
+func (m *MaintenanceMonitor) exit() {
+	m.mu.Lock()
+	reason := m.reason
+	m.active = false
+	m.reason = ""
+	for symbol := range m.pausedByUs {
+		if p, ok := m.pausers[symbol]; ok {
+			p.Resume()
+		}
+		delete(m.pausedByUs, symbol)
+	}
+	m.mu.Unlock()
+
+	event.SetExchangeMaintenance(m.exchangeName, false)
+	metrics.GetPrometheusMetrics().SetExchangeMaintenance(m.exchangeName, false)
+	logger.Info("✅ [%s] 交易所维护结束且健康检查通过，已恢复交易", m.exchangeName)
+	m.publish(event.EventTypeExchangeMaintenanceEnd, fmt.Sprintf("交易所维护结束（%s），健康检查通过，已恢复交易", reason))
+}
+
+func (m *MaintenanceMonitor) publish(eventType event.EventType, message string) {
+	if m.eventBus == nil {
+		return
+	}
+	m.eventBus.Publish(&event.Event{
+		Type:      eventType,
+		Timestamp: m.now(),
+		Data: map[string]interface{}{
+			"exchange": m.exchangeName,
+			"message":  message,
+		},
+	})
+}
@@ -0,0 +1,300 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"quantmesh/event"
+	"quantmesh/exchange"
+	"quantmesh/logger"
+	"quantmesh/storage"
+	"quantmesh/utils"
+)
+
+// OpenInterestSpikeRule 持仓量突增规则：Window 内持仓量变化超过 ChangePercent 时触发，
+// 触发后 Cooldown 内不重复触发，冷却结束且变化回落到阈值以下时视为恢复
+type OpenInterestSpikeRule struct {
+	ChangePercent float64
+	Window        time.Duration
+	Cooldown      time.Duration
+}
+
+// OpenInterestSpike 持仓量突变记录
+type OpenInterestSpike struct {
+	Symbol        string    `json:"symbol"`
+	Exchange      string    `json:"exchange"`
+	FromOI        float64   `json:"from_open_interest"`
+	ToOI          float64   `json:"to_open_interest"`
+	ChangePercent float64   `json:"change_percent"`
+	WindowMinutes int       `json:"window_minutes"`
+	DetectedAt    time.Time `json:"detected_at"`
+}
+
+// OpenInterestMonitor 持仓量与大户多空比监控
+// 定期采集每个交易对的持仓量并入库，与资金费率、价差监控互补；
+// 配置突增规则时，持仓量短时间内大幅变化会发布事件并回调风控处理（缩小窗口/暂停）
+type OpenInterestMonitor struct {
+	db           storage.Storage
+	provider     exchange.OpenInterestProvider
+	exchangeName string
+	symbols      []string
+	interval     time.Duration
+	eventBus     *event.EventBus
+	rule         *OpenInterestSpikeRule
+
+	mu           sync.RWMutex
+	latestData   map[string]*storage.OpenInterestData
+	samples      map[string][]*storage.OpenInterestData // 突增检测窗口内的采样（按时间正序）
+	activeSpikes map[string]*OpenInterestSpike
+
+	onSpike   func(*OpenInterestSpike)
+	onRecover func(symbol string)
+}
+
+// NewOpenInterestMonitor 创建持仓量监控器，交易所不支持持仓量查询时返回错误
+func NewOpenInterestMonitor(db storage.Storage, ex exchange.IExchange, symbols []string, intervalMinutes int) (*OpenInterestMonitor, error) {
+	provider, ok := exchange.As[exchange.OpenInterestProvider](ex)
+	if !ok {
+		return nil, fmt.Errorf("交易所 %s 不支持持仓量查询", ex.GetName())
+	}
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("未指定监控的交易对")
+	}
+
+	interval := time.Duration(intervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	return &OpenInterestMonitor{
+		db:           db,
+		provider:     provider,
+		exchangeName: ex.GetName(),
+		symbols:      symbols,
+		interval:     interval,
+		latestData:   make(map[string]*storage.OpenInterestData),
+		samples:      make(map[string][]*storage.OpenInterestData),
+		activeSpikes: make(map[string]*OpenInterestSpike),
+	}, nil
+}
+
+// SetSpikeRule 启用持仓量突增检测（需在 Start 之前调用）
+func (m *OpenInterestMonitor) SetSpikeRule(rule OpenInterestSpikeRule, eventBus *event.EventBus) {
+	m.rule = &rule
+	m.eventBus = eventBus
+}
+
+// SetSpikeHandler 设置突增触发和恢复回调
+func (m *OpenInterestMonitor) SetSpikeHandler(onSpike func(*OpenInterestSpike), onRecover func(symbol string)) {
+	m.onSpike = onSpike
+	m.onRecover = onRecover
+}
+
+// Start 启动持仓量监控
+func (m *OpenInterestMonitor) Start(ctx context.Context) {
+	logger.Info("📊 启动持仓量监控 (交易所: %s, 交易对: %v, 间隔: %v)", m.exchangeName, m.symbols, m.interval)
+
+	if m.rule != nil {
+		m.loadSamples()
+	}
+
+	utils.GoSupervised(ctx, "open-interest-monitor", func(ctx context.Context) {
+		m.checkAll(ctx)
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.checkAll(ctx)
+			}
+		}
+	})
+}
+
+// loadSamples 从数据库恢复检测窗口内的采样，避免重启后丢失比较基准
+func (m *OpenInterestMonitor) loadSamples() {
+	if m.db == nil {
+		return
+	}
+	now := time.Now()
+	for _, symbol := range m.symbols {
+		records, err := m.db.GetOpenInterestHistory(symbol, m.exchangeName, now.Add(-m.rule.Window), now, 1000)
+		if err != nil {
+			logger.Warn("⚠️ [持仓量] %s 加载历史采样失败: %v", symbol, err)
+			continue
+		}
+		sort.Slice(records, func(i, j int) bool { return records[i].Timestamp.Before(records[j].Timestamp) })
+		m.samples[symbol] = records
+	}
+}
+
+func (m *OpenInterestMonitor) checkAll(ctx context.Context) {
+	for _, symbol := range m.symbols {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := m.check(ctx, symbol); err != nil {
+			logger.Warn("⚠️ [持仓量] %s 采集失败: %v", symbol, err)
+		}
+	}
+}
+
+func (m *OpenInterestMonitor) check(ctx context.Context, symbol string) error {
+	reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	stat, err := m.provider.GetOpenInterestStat(reqCtx, symbol)
+	if err != nil {
+		return err
+	}
+
+	data := &storage.OpenInterestData{
+		Symbol:            symbol,
+		Exchange:          m.exchangeName,
+		OpenInterest:      stat.OpenInterest,
+		OpenInterestValue: stat.OpenInterestValue,
+		LongShortRatio:    stat.LongShortRatio,
+		LongPercent:       stat.LongPercent,
+		ShortPercent:      stat.ShortPercent,
+		Timestamp:         time.Now().UTC(),
+	}
+	if m.db != nil {
+		if err := m.db.SaveOpenInterest(data); err != nil {
+			logger.Warn("⚠️ [持仓量] %s 保存失败: %v", symbol, err)
+		}
+	}
+
+	m.mu.Lock()
+	m.latestData[symbol] = data
+	m.mu.Unlock()
+
+	logger.Debug("📊 [持仓量] %s: OI=%.4f (%.2f), 大户多空比=%.4f", symbol, data.OpenInterest, data.OpenInterestValue, data.LongShortRatio)
+
+	if m.rule != nil {
+		m.evaluate(data)
+	}
+	return nil
+}
+
+// evaluate 将新采样加入窗口，与窗口内最早的采样比较持仓量变化
+func (m *OpenInterestMonitor) evaluate(data *storage.OpenInterestData) {
+	now := data.Timestamp
+	cutoff := now.Add(-m.rule.Window)
+
+	m.mu.Lock()
+	samples := append(m.samples[data.Symbol], data)
+	for len(samples) > 0 && samples[0].Timestamp.Before(cutoff) {
+		samples = samples[1:]
+	}
+	m.samples[data.Symbol] = samples
+
+	var changePercent float64
+	base := samples[0]
+	if base != data && base.OpenInterest > 0 {
+		changePercent = (data.OpenInterest - base.OpenInterest) / base.OpenInterest * 100
+	}
+	breached := math.Abs(changePercent) >= m.rule.ChangePercent
+
+	active := m.activeSpikes[data.Symbol]
+	var spike *OpenInterestSpike
+	recovered := false
+	switch {
+	case active != nil && now.Sub(active.DetectedAt) < m.rule.Cooldown:
+		// 冷却期内不重复触发
+	case breached:
+		spike = &OpenInterestSpike{
+			Symbol:        data.Symbol,
+			Exchange:      m.exchangeName,
+			FromOI:        base.OpenInterest,
+			ToOI:          data.OpenInterest,
+			ChangePercent: changePercent,
+			WindowMinutes: int(m.rule.Window.Minutes()),
+			DetectedAt:    now,
+		}
+		m.activeSpikes[data.Symbol] = spike
+	case active != nil:
+		delete(m.activeSpikes, data.Symbol)
+		recovered = true
+	}
+	m.mu.Unlock()
+
+	if spike != nil {
+		logger.Warn("⚠️ [持仓量] %s %d 分钟内持仓量变化 %.2f%% (%.4f -> %.4f)",
+			spike.Symbol, spike.WindowMinutes, spike.ChangePercent, spike.FromOI, spike.ToOI)
+		m.publish(spike)
+		if m.onSpike != nil {
+			m.onSpike(spike)
+		}
+	}
+	if recovered {
+		logger.Info("✅ [持仓量] %s 持仓量变化已回落 (%.2f%%)", data.Symbol, changePercent)
+		if m.onRecover != nil {
+			m.onRecover(data.Symbol)
+		}
+	}
+}
+
+func (m *OpenInterestMonitor) publish(spike *OpenInterestSpike) {
+	if m.eventBus == nil {
+		return
+	}
+	m.eventBus.Publish(&event.Event{
+		Type: event.EventTypeOpenInterestSpike,
+		Data: map[string]interface{}{
+			"exchange":       spike.Exchange,
+			"symbol":         spike.Symbol,
+			"from":           spike.FromOI,
+			"to":             spike.ToOI,
+			"change_percent": spike.ChangePercent,
+			"window_minutes": spike.WindowMinutes,
+			"message": fmt.Sprintf("%s 持仓量 %d 分钟内变化 %.2f%% (%.4f -> %.4f)",
+				spike.Symbol, spike.WindowMinutes, spike.ChangePercent, spike.FromOI, spike.ToOI),
+		},
+	})
+}
+
+// GetAllCurrentOpenInterest 获取所有交易对的最新持仓量（按交易对排序）
+func (m *OpenInterestMonitor) GetAllCurrentOpenInterest() []*storage.OpenInterestData {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]*storage.OpenInterestData, 0, len(m.latestData))
+	for _, data := range m.latestData {
+		result = append(result, data)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Symbol < result[j].Symbol })
+	return result
+}
+
+// GetOpenInterestHistory 获取最近 hours 小时的持仓量历史（按时间倒序）
+func (m *OpenInterestMonitor) GetOpenInterestHistory(symbol string, hours, limit int) ([]*storage.OpenInterestData, error) {
+	if m.db == nil {
+		return nil, fmt.Errorf("存储服务未启用")
+	}
+	if hours <= 0 {
+		hours = 24
+	}
+	now := time.Now()
+	return m.db.GetOpenInterestHistory(symbol, m.exchangeName, now.Add(-time.Duration(hours)*time.Hour), now, limit)
+}
+
+// GetActiveSpikes 获取仍在冷却/生效中的持仓量突变
+func (m *OpenInterestMonitor) GetActiveSpikes() []*OpenInterestSpike {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]*OpenInterestSpike, 0, len(m.activeSpikes))
+	for _, spike := range m.activeSpikes {
+		copied := *spike
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Symbol < result[j].Symbol })
+	return result
+}
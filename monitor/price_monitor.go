@@ -3,12 +3,14 @@ package monitor
 import (
 	"context"
 	"fmt"
+	"math"
 	"sync/atomic"
 	"time"
 
 	"quantmesh/exchange"
 	"quantmesh/logger"
 	"quantmesh/metrics"
+	"quantmesh/utils"
 )
 
 /*
@@ -54,6 +56,11 @@ type PriceMonitor struct {
 
 	// 时间配置
 	priceSendInterval time.Duration
+
+	// 节流配置：价格相对上次发送的变动达到 minMoveDelta 时立即发送
+	minMoveDelta  float64
+	lastSentPrice atomic.Value  // float64
+	flushCh       chan struct{} // 立即发送信号
 }
 
 // NewPriceMonitor 创建价格监控器
@@ -70,14 +77,26 @@ func NewPriceMonitor(ex exchange.IExchange, symbol string, priceSendInterval int
 		ctx:               ctx,
 		cancel:            cancel,
 		priceSendInterval: time.Duration(priceSendInterval) * time.Millisecond,
+		flushCh:           make(chan struct{}, 1),
 	}
 	pm.lastPrice.Store(0.0)
+	pm.lastSentPrice.Store(0.0)
 	pm.lastPriceStr.Store("")
 	pm.lastPriceTime.Store(time.Time{})
 	pm.latestPriceChange.Store((*PriceChange)(nil))
 	return pm
 }
 
+// SetConflation 设置价格合并阈值
+// 默认每个 priceSendInterval 最多发送一次最新价格；当价格相对上次发送变动 >= minMoveTicks 个 tick 时立即发送
+func (pm *PriceMonitor) SetConflation(minMoveTicks int, tickSize float64) {
+	if minMoveTicks <= 0 || tickSize <= 0 {
+		pm.minMoveDelta = 0
+		return
+	}
+	pm.minMoveDelta = float64(minMoveTicks) * tickSize
+}
+
 // Start 启动价格监控
 func (pm *PriceMonitor) Start() error {
 	if pm.isRunning.Load() {
@@ -98,7 +117,9 @@ func (pm *PriceMonitor) Start() error {
 	}
 
 	logger.Info("✅ 价格监控已启动 (WebSocket 推送)")
-	go pm.periodicPriceSender() // 启动定期发送协程
+	utils.GoSupervised(pm.ctx, "price-sender:"+pm.symbol, func(ctx context.Context) {
+		pm.periodicPriceSender() // 启动定期发送协程
+	})
 
 	return nil
 }
@@ -133,7 +154,20 @@ func (pm *PriceMonitor) updatePrice(newPrice float64) {
 			Change:    change,
 			Timestamp: time.Now(),
 		}
-		pm.latestPriceChange.Store(event)
+		// 上一个价格尚未发送就被覆盖，计为合并
+		if prev, _ := pm.latestPriceChange.Swap(event).(*PriceChange); prev != nil {
+			promMetrics.RecordPriceConflated(pm.exchange.GetName(), pm.symbol, "monitor", 1)
+		}
+
+		// 大幅变动时立即发送，不等待下一个发送周期
+		if pm.minMoveDelta > 0 {
+			if lastSent := pm.lastSentPrice.Load().(float64); lastSent > 0 && math.Abs(newPrice-lastSent) >= pm.minMoveDelta {
+				select {
+				case pm.flushCh <- struct{}{}:
+				default:
+				}
+			}
+		}
 	}
 }
 
@@ -147,24 +181,30 @@ func (pm *PriceMonitor) periodicPriceSender() {
 		case <-pm.ctx.Done():
 			return
 		case <-ticker.C:
-			// 获取最新价格更新
-			if latestVal := pm.latestPriceChange.Load(); latestVal != nil {
-				latestChange := latestVal.(*PriceChange)
-				if latestChange != nil {
-					// 尝试非阻塞发送
-					select {
-					case pm.priceChangeCh <- *latestChange:
-						// 成功发送，清空latestPriceChange
-						pm.latestPriceChange.Store((*PriceChange)(nil))
-					default:
-						// channel已满，保留最新价格等待下次机会
-					}
-				}
-			}
+			pm.sendLatest()
+		case <-pm.flushCh:
+			pm.sendLatest()
 		}
 	}
 }
 
+// sendLatest 发送最新价格更新
+func (pm *PriceMonitor) sendLatest() {
+	latestChange, _ := pm.latestPriceChange.Load().(*PriceChange)
+	if latestChange == nil {
+		return
+	}
+	// 尝试非阻塞发送
+	select {
+	case pm.priceChangeCh <- *latestChange:
+		// 成功发送，清空latestPriceChange（期间若有新价格写入则保留）
+		pm.latestPriceChange.CompareAndSwap(latestChange, (*PriceChange)(nil))
+		pm.lastSentPrice.Store(latestChange.NewPrice)
+	default:
+		// channel已满，保留最新价格等待下次机会
+	}
+}
+
 // Stop 停止价格监控
 func (pm *PriceMonitor) Stop() {
 	pm.cancel()
@@ -236,6 +276,9 @@ func (pm *PriceMonitor) Subscribe() <-chan PriceChange {
 				default:
 					// outCh已满，保存最新的价格更新，丢弃旧数据
 					// 这样确保消费者总是能收到最新的价格，而不是被旧数据阻塞
+					if latestChange != nil {
+						metrics.GetPrometheusMetrics().RecordPriceConflated(pm.exchange.GetName(), pm.symbol, "subscriber", 1)
+					}
 					latestChange = &change
 				}
 			}
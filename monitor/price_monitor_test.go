@@ -0,0 +1,130 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// conflatedTotal 读取价格合并计数器
+func conflatedTotal(t *testing.T, symbol, stage string) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "quantmesh_price_conflated_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["exchange"] == "stub" && labels["symbol"] == symbol && labels["stage"] == stage {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+// expectPrice 等待发送的价格
+func expectPrice(t *testing.T, ch <-chan PriceChange, oldPrice, newPrice float64) {
+	t.Helper()
+	select {
+	case change := <-ch:
+		if change.OldPrice != oldPrice || change.NewPrice != newPrice {
+			t.Fatalf("sent %v -> %v, want %v -> %v", change.OldPrice, change.NewPrice, oldPrice, newPrice)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("price %v not sent", newPrice)
+	}
+}
+
+func expectNoPrice(t *testing.T, ch <-chan PriceChange) {
+	t.Helper()
+	select {
+	case change := <-ch:
+		t.Fatalf("unexpected send %+v", change)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPriceMonitorFlushesLargeMoves(t *testing.T) {
+	tests := []struct {
+		name         string
+		minMoveTicks int
+		wantFlush    bool
+	}{
+		{name: "move reaches threshold", minMoveTicks: 2, wantFlush: true},
+		{name: "conflation disabled", minMoveTicks: 0, wantFlush: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			venue := &priceStreamStub{}
+			// 发送周期足够长，测试期间只有立即发送会生效
+			pm := NewPriceMonitor(venue, "FLUSHUSDT", int(time.Hour/time.Millisecond))
+			pm.SetConflation(tt.minMoveTicks, 0.5)
+			if err := pm.Start(); err != nil {
+				t.Fatal(err)
+			}
+			defer pm.cancel()
+
+			venue.push(100)
+			venue.push(100.5)
+			pm.sendLatest() // 建立上次发送价格
+			expectPrice(t, pm.priceChangeCh, 100, 100.5)
+
+			// 变动不足阈值：等待下一个发送周期
+			venue.push(101)
+			expectNoPrice(t, pm.priceChangeCh)
+
+			// 相对上次发送变动 1.5 >= 2 个 tick：立即发送最新价格
+			venue.push(102)
+			if !tt.wantFlush {
+				expectNoPrice(t, pm.priceChangeCh)
+				return
+			}
+			expectPrice(t, pm.priceChangeCh, 101, 102)
+		})
+	}
+}
+
+func TestPriceMonitorConflationCounter(t *testing.T) {
+	const symbol = "CONFLATEUSDT"
+	pm := NewPriceMonitor(&venueStub{}, symbol, 1000)
+	pm.SetConflation(2, 0.5)
+	base := conflatedTotal(t, symbol, "monitor")
+
+	pm.updatePrice(100)
+	pm.updatePrice(100.5)
+	if got := conflatedTotal(t, symbol, "monitor") - base; got != 0 {
+		t.Fatalf("conflated = %v after first change, want 0", got)
+	}
+	// 未发送的价格被新价格覆盖
+	pm.updatePrice(101)
+	pm.updatePrice(101.5)
+	if got := conflatedTotal(t, symbol, "monitor") - base; got != 2 {
+		t.Fatalf("conflated = %v, want 2", got)
+	}
+
+	pm.sendLatest()
+	expectPrice(t, pm.priceChangeCh, 101, 101.5)
+	// 发送后没有待发送价格，新的变化不计为合并
+	pm.updatePrice(102)
+	if got := conflatedTotal(t, symbol, "monitor") - base; got != 2 {
+		t.Fatalf("conflated = %v after send, want 2", got)
+	}
+
+	// 通道已满时保留最新价格，等待下次发送
+	for len(pm.priceChangeCh) < cap(pm.priceChangeCh) {
+		pm.priceChangeCh <- PriceChange{}
+	}
+	pm.sendLatest()
+	if latest, _ := pm.latestPriceChange.Load().(*PriceChange); latest == nil || latest.NewPrice != 102 {
+		t.Fatalf("latest = %+v after full channel, want 102 kept", latest)
+	}
+}
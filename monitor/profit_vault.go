@@ -0,0 +1,202 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/exchange"
+	"quantmesh/logger"
+	"quantmesh/storage"
+	"quantmesh/utils"
+)
+
+// ProfitVault 利润金库
+// 定期统计当前周期（UTC 日/周/月）的已实现盈亏（含资金费），超过阈值的部分按比例从合约账户划转到
+// 现货/资金账户，已划转金额从后续可划转额度中扣除；每次划转（含失败）写入 profit_transfers 表
+type ProfitVault struct {
+	cfg          *config.Config
+	storage      storage.Storage
+	ex           exchange.IExchange
+	transferer   exchange.WalletTransferer
+	exchangeName string
+	now          func() time.Time
+
+	lastDeniedPeriod time.Time // 已提示无划转权限的周期，避免每次检查重复告警
+	unsupported      bool      // 交易所适配器未实现划转，停止后续检查
+}
+
+// NewProfitVault 创建利润金库，交易所不支持账户划转或存储不可用时返回 nil
+func NewProfitVault(cfg *config.Config, st storage.Storage, ex exchange.IExchange) *ProfitVault {
+	transferer, ok := exchange.As[exchange.WalletTransferer](ex)
+	if !ok || st == nil {
+		return nil
+	}
+	return &ProfitVault{
+		cfg:          cfg,
+		storage:      st,
+		ex:           ex,
+		transferer:   transferer,
+		exchangeName: ex.GetName(),
+		now:          time.Now,
+	}
+}
+
+// Start 启动定期检查
+func (pv *ProfitVault) Start(ctx context.Context) {
+	interval := time.Duration(pv.cfg.ProfitVault.CheckInterval) * time.Second
+	logger.Info("🏦 [利润金库] 启动 (交易所: %s, 周期: %s, 阈值: %.2f, 目标账户: %s, 间隔: %v)",
+		pv.exchangeName, pv.cfg.ProfitVault.Period, pv.cfg.ProfitVault.Threshold, pv.cfg.ProfitVault.ToWallet, interval)
+
+	utils.GoSupervised(ctx, "profit-vault:"+pv.exchangeName, func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				pv.sweep(ctx)
+			}
+		}
+	})
+}
+
+// sweep 检查当前周期的可划转金额并执行划转
+func (pv *ProfitVault) sweep(ctx context.Context) {
+	if pv.unsupported {
+		return
+	}
+	vaultCfg := pv.cfg.ProfitVault
+	now := pv.now().UTC()
+	periodStart := profitPeriodStart(now, vaultCfg.Period)
+
+	realized, err := pv.realizedPnL(periodStart, now)
+	if err != nil {
+		logger.Warn("⚠️ [利润金库] %s 统计已实现盈亏失败: %v", pv.exchangeName, err)
+		return
+	}
+	swept, err := pv.storage.GetProfitTransferTotal(pv.exchangeName, periodStart, now)
+	if err != nil {
+		logger.Warn("⚠️ [利润金库] %s 查询已划转金额失败: %v", pv.exchangeName, err)
+		return
+	}
+	amount := sweepAmount(realized, vaultCfg.Threshold, vaultCfg.SweepRatio, swept)
+	if amount < vaultCfg.MinTransfer {
+		return
+	}
+
+	// 不划转已占用的保证金，最多划走当前可用余额
+	accCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	acc, err := pv.ex.GetAccount(accCtx)
+	cancel()
+	if err != nil {
+		logger.Warn("⚠️ [利润金库] %s 获取账户信息失败: %v", pv.exchangeName, err)
+		return
+	}
+	amount = math.Floor(math.Min(amount, acc.AvailableBalance)*100) / 100
+	if amount < vaultCfg.MinTransfer {
+		logger.Debug("[利润金库] %s 可用余额不足，跳过划转 (可用: %.2f)", pv.exchangeName, acc.AvailableBalance)
+		return
+	}
+
+	if !pv.transferPermitted(ctx, periodStart) {
+		return
+	}
+
+	record := &storage.ProfitTransfer{
+		Exchange:    pv.exchangeName,
+		Asset:       vaultCfg.Asset,
+		Amount:      amount,
+		ToWallet:    vaultCfg.ToWallet,
+		Period:      vaultCfg.Period,
+		PeriodStart: periodStart,
+		RealizedPnL: realized,
+		Status:      "success",
+	}
+	transferCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	record.TranID, err = pv.transferer.TransferFromFutures(transferCtx, vaultCfg.Asset, amount, vaultCfg.ToWallet)
+	cancel()
+	if errors.Is(err, exchange.ErrNotImplemented) {
+		pv.unsupported = true
+		logger.Warn("⚠️ [利润金库] %s 不支持账户划转，利润金库已停用", pv.exchangeName)
+		return
+	}
+	if err != nil {
+		record.Status = "failed"
+		record.Error = err.Error()
+		logger.Error("❌ [利润金库] %s 划转 %.2f %s 到 %s 失败: %v", pv.exchangeName, amount, vaultCfg.Asset, vaultCfg.ToWallet, err)
+	} else {
+		logger.Info("🏦 [利润金库] %s 本周期已实现盈亏 %.2f，已划转 %.2f %s 到 %s (流水号: %s)",
+			pv.exchangeName, realized, amount, vaultCfg.Asset, vaultCfg.ToWallet, record.TranID)
+	}
+	if err := pv.storage.SaveProfitTransfer(record); err != nil {
+		logger.Warn("⚠️ [利润金库] %s 保存划转记录失败: %v", pv.exchangeName, err)
+	}
+}
+
+// realizedPnL 汇总该交易所在时间区间内的已实现盈亏（成交盈亏 + 资金费）
+func (pv *ProfitVault) realizedPnL(startTime, endTime time.Time) (float64, error) {
+	rows, err := pv.storage.GetPnLByTimeRange(startTime, endTime)
+	if err != nil {
+		return 0, err
+	}
+	var total float64
+	for _, r := range rows {
+		if r.Exchange == pv.exchangeName {
+			total += r.NetPnL
+		}
+	}
+	return total, nil
+}
+
+// transferPermitted 检查 API 密钥是否开启划转权限（交易所不支持权限检测时直接尝试划转）
+func (pv *ProfitVault) transferPermitted(ctx context.Context, periodStart time.Time) bool {
+	checker, ok := exchange.As[exchange.PermissionChecker](pv.ex)
+	if !ok {
+		return true
+	}
+	permCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	perms, err := checker.CheckAPIPermissions(permCtx)
+	cancel()
+	if errors.Is(err, exchange.ErrNotImplemented) {
+		return true
+	}
+	if err != nil {
+		logger.Warn("⚠️ [利润金库] %s 检测 API 权限失败，跳过本次划转: %v", pv.exchangeName, err)
+		return false
+	}
+	if perms.CanTransfer {
+		return true
+	}
+	if !pv.lastDeniedPeriod.Equal(periodStart) {
+		pv.lastDeniedPeriod = periodStart
+		logger.Warn("⚠️ [利润金库] %s API 密钥未开启划转权限，利润不会自动划转（请在交易所开启万向划转权限）", pv.exchangeName)
+	}
+	return false
+}
+
+// profitPeriodStart 计算 UTC 统计周期的起始时间（weekly 以周一为起点）
+func profitPeriodStart(now time.Time, period string) time.Time {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case "weekly":
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case "monthly":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+// sweepAmount 计算可划转金额：(已实现盈亏 - 阈值) × 比例 - 本周期已划转金额，不足时返回 0
+func sweepAmount(realized, threshold, ratio, swept float64) float64 {
+	excess := (realized - threshold) * ratio
+	if amount := excess - swept; amount > 0 {
+		return amount
+	}
+	return 0
+}
@@ -0,0 +1,212 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/exchange"
+	"quantmesh/storage"
+	"quantmesh/testutil"
+)
+
+func TestProfitPeriodStart(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 30, 0, 0, time.UTC) // 周三
+	tests := []struct {
+		period string
+		want   time.Time
+	}{
+		{"daily", time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)},
+		{"", time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)},
+		{"weekly", time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)},
+		{"monthly", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := profitPeriodStart(now, tt.period); !got.Equal(tt.want) {
+			t.Errorf("profitPeriodStart(%q) = %v, want %v", tt.period, got, tt.want)
+		}
+	}
+
+	// 周日属于以周一开始的本周；非 UTC 时间先换算为 UTC
+	sunday := time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+	if got := profitPeriodStart(sunday, "weekly"); !got.Equal(time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("weekly on sunday = %v", got)
+	}
+	tokyo := time.FixedZone("UTC+9", 9*3600)
+	if got := profitPeriodStart(time.Date(2026, 10, 15, 5, 0, 0, 0, tokyo), "daily"); !got.Equal(time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("daily in UTC+9 = %v", got)
+	}
+}
+
+func TestProfitVaultSweep(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		period     string
+		threshold  float64
+		ratio      float64
+		realized   float64
+		otherPnL   float64 // 其他交易所的盈亏，不计入
+		swept      float64
+		available  float64
+		perms      *exchange.APIPermissions // nil 表示交易所不支持权限检测
+		permErr    error
+		transfer   error
+		wantAmount float64 // 0 表示不划转
+		wantStatus string  // 写入的划转记录状态，空表示不写入
+		wantStart  time.Time
+	}{
+		{
+			name: "below threshold", period: "daily", threshold: 100, ratio: 1, realized: 80, available: 1000,
+			wantStart: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "excess times ratio", period: "daily", threshold: 100, ratio: 0.5, realized: 300, otherPnL: 1000, available: 1000,
+			wantAmount: 100, wantStatus: "success", wantStart: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "already swept this period", period: "weekly", threshold: 100, ratio: 1, realized: 300, swept: 150, available: 1000,
+			wantAmount: 50, wantStatus: "success", wantStart: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "below min transfer", period: "monthly", threshold: 100, ratio: 1, realized: 300, swept: 195, available: 1000,
+			wantStart: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "capped by available balance", period: "daily", threshold: 0, ratio: 1, realized: 300, available: 42.567,
+			wantAmount: 42.56, wantStatus: "success", wantStart: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "available balance below min transfer", period: "daily", threshold: 0, ratio: 1, realized: 300, available: 5,
+			wantStart: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "transfer permission granted", period: "daily", threshold: 0, ratio: 1, realized: 50, available: 1000,
+			perms: &exchange.APIPermissions{CanTrade: true, CanTransfer: true}, wantAmount: 50, wantStatus: "success",
+			wantStart: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "transfer permission denied", period: "daily", threshold: 0, ratio: 1, realized: 50, available: 1000,
+			perms: &exchange.APIPermissions{CanTrade: true}, wantStart: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "permission check failed", period: "daily", threshold: 0, ratio: 1, realized: 50, available: 1000,
+			perms: &exchange.APIPermissions{}, permErr: errors.New("timeout"), wantStart: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "permission check not implemented", period: "daily", threshold: 0, ratio: 1, realized: 50, available: 1000,
+			perms: &exchange.APIPermissions{}, permErr: exchange.ErrNotImplemented, wantAmount: 50, wantStatus: "success",
+			wantStart: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "transfer failed is recorded", period: "daily", threshold: 0, ratio: 1, realized: 50, available: 1000,
+			transfer: errors.New("insufficient balance"), wantStatus: "failed", wantStart: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.ProfitVault.Period = tt.period
+			cfg.ProfitVault.Threshold = tt.threshold
+			cfg.ProfitVault.SweepRatio = tt.ratio
+			cfg.ProfitVault.MinTransfer = 10
+			cfg.ProfitVault.Asset = "USDT"
+			cfg.ProfitVault.ToWallet = "SPOT"
+
+			var pnlStart, totalStart time.Time
+			var saved []*storage.ProfitTransfer
+			st := testutil.NewMockStorage()
+			st.GetPnLByTimeRangeFunc = func(startTime, endTime time.Time) ([]*storage.PnLBySymbol, error) {
+				pnlStart = startTime
+				return []*storage.PnLBySymbol{
+					{Exchange: "stub", Symbol: "BTCUSDT", NetPnL: tt.realized},
+					{Exchange: "other", Symbol: "BTCUSDT", NetPnL: tt.otherPnL},
+				}, nil
+			}
+			st.GetProfitTransferTotalFunc = func(exchangeName string, startTime, endTime time.Time) (float64, error) {
+				totalStart = startTime
+				return tt.swept, nil
+			}
+			st.SaveProfitTransferFunc = func(transfer *storage.ProfitTransfer) error {
+				saved = append(saved, transfer)
+				return nil
+			}
+
+			var ex exchange.IExchange
+			var transfers func() []float64
+			if tt.perms != nil {
+				stub := &permissionTransferStub{perms: tt.perms, permErr: tt.permErr}
+				stub.available, stub.transferErr = tt.available, tt.transfer
+				ex, transfers = stub, func() []float64 { return stub.transfers }
+			} else {
+				stub := &transferStub{transferErr: tt.transfer}
+				stub.available = tt.available
+				ex, transfers = stub, func() []float64 { return stub.transfers }
+			}
+			pv := NewProfitVault(cfg, st, ex)
+			if pv == nil {
+				t.Fatal("NewProfitVault returned nil for an exchange supporting transfers")
+			}
+			pv.now = func() time.Time { return now }
+
+			pv.sweep(context.Background())
+
+			if !pnlStart.Equal(tt.wantStart) || !totalStart.Equal(tt.wantStart) {
+				t.Errorf("period start: pnl=%v total=%v, want %v", pnlStart, totalStart, tt.wantStart)
+			}
+			got := transfers()
+			if tt.wantAmount == 0 && len(got) != 0 {
+				t.Errorf("unexpected transfers %v", got)
+			}
+			if tt.wantAmount != 0 && (len(got) != 1 || fmt.Sprintf("%.2f", got[0]) != fmt.Sprintf("%.2f", tt.wantAmount)) {
+				t.Errorf("transfers = %v, want [%.2f]", got, tt.wantAmount)
+			}
+			if tt.wantStatus == "" {
+				if len(saved) != 0 {
+					t.Errorf("unexpected transfer records %+v", saved[0])
+				}
+				return
+			}
+			if len(saved) != 1 || saved[0].Status != tt.wantStatus || !saved[0].PeriodStart.Equal(tt.wantStart) || saved[0].RealizedPnL != tt.realized {
+				t.Fatalf("transfer records = %+v", saved)
+			}
+		})
+	}
+}
+
+func TestProfitVaultTransferNotImplemented(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.ProfitVault.Period = "daily"
+	cfg.ProfitVault.SweepRatio = 1
+	cfg.ProfitVault.MinTransfer = 10
+	st := testutil.NewMockStorage()
+	st.GetPnLByTimeRangeFunc = func(startTime, endTime time.Time) ([]*storage.PnLBySymbol, error) {
+		return []*storage.PnLBySymbol{{Exchange: "stub", NetPnL: 100}}, nil
+	}
+	st.GetProfitTransferTotalFunc = func(exchangeName string, startTime, endTime time.Time) (float64, error) {
+		return 0, nil
+	}
+	ex := &transferStub{transferErr: fmt.Errorf("binance: %w", exchange.ErrNotImplemented)}
+	ex.available = 1000
+	pv := NewProfitVault(cfg, st, ex)
+
+	pv.sweep(context.Background())
+	if st.Calls("SaveProfitTransfer") != 0 {
+		t.Fatal("an unsupported transfer must not be recorded as a failed transfer")
+	}
+	// 停用后不再统计盈亏和查询账户
+	pv.sweep(context.Background())
+	if st.Calls("GetPnLByTimeRange") != 1 || ex.accounts != 1 {
+		t.Fatalf("vault kept running after ErrNotImplemented: pnl calls=%d account calls=%d",
+			st.Calls("GetPnLByTimeRange"), ex.accounts)
+	}
+}
+
+func TestNewProfitVaultRequiresTransferSupport(t *testing.T) {
+	if pv := NewProfitVault(&config.Config{}, testutil.NewMockStorage(), &venueStub{}); pv != nil {
+		t.Fatal("NewProfitVault should return nil when the exchange cannot transfer")
+	}
+}
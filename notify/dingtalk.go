@@ -20,6 +20,8 @@ type DingTalkNotifier struct {
 	webhook string
 	secret  string
 	client  *http.Client
+
+	templated // 自定义消息模板
 }
 
 // NewDingTalkNotifier 创建钉钉通知器
@@ -44,7 +46,7 @@ func (dn *DingTalkNotifier) Name() string {
 
 // Send 发送通知
 func (dn *DingTalkNotifier) Send(evt *event.Event) error {
-	message := formatDingTalkMessage(evt)
+	message := dn.renderText("dingtalk", evt, formatDingTalkMessage)
 	
 	// 构建请求 URL（如果配置了签名密钥，需要添加签名参数）
 	requestURL := dn.webhook
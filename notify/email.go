@@ -24,6 +24,8 @@ type EmailNotifier struct {
 	from     string
 	to       string
 	subject  string
+
+	templated // 自定义消息模板
 }
 
 // NewEmailNotifier 创建邮件通知器
@@ -79,6 +81,9 @@ func (en *EmailNotifier) Send(evt *event.Event) error {
 	if subject == "" {
 		subject = fmt.Sprintf("QuantMesh 通知: %s", string(evt.Type))
 	}
+	if title, body, ok := en.render("email", evt); ok {
+		subject, message = title, body
+	}
 
 	switch en.provider {
 	case "smtp":
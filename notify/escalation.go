@@ -0,0 +1,337 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/event"
+	"quantmesh/logger"
+	"quantmesh/utils"
+)
+
+// EscalationProvider 告警升级通道（电话、短信、PagerDuty 等）
+type EscalationProvider interface {
+	Escalate(title, message string, evt *event.Event) error
+	Name() string
+}
+
+// EscalationNotifier 严重告警升级
+// 仅处理配置的严重事件，带独立限流和生效时段，避免半夜被非关键事件叫醒
+type EscalationNotifier struct {
+	provider    EscalationProvider
+	events      map[event.EventType]bool
+	minInterval time.Duration
+	maxPerHour  int
+
+	scheduleStart int // 生效开始（当天分钟数），-1 表示全天
+	scheduleEnd   int
+	weekdays      map[time.Weekday]bool
+
+	now func() time.Time
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+	sent     []time.Time
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// NewEscalationNotifier 创建告警升级通知器
+func NewEscalationNotifier(cfg *config.Config) (*EscalationNotifier, error) {
+	ec := cfg.Notifications.Escalation
+
+	var provider EscalationProvider
+	switch strings.ToLower(ec.Provider) {
+	case "twilio":
+		tc := ec.Twilio
+		if tc.AccountSID == "" || tc.AuthToken == "" || tc.From == "" || tc.To == "" {
+			return nil, fmt.Errorf("Twilio 配置不完整（需要 account_sid、auth_token、from、to）")
+		}
+		provider = &twilioProvider{
+			accountSID: tc.AccountSID,
+			authToken:  tc.AuthToken,
+			from:       tc.From,
+			to:         tc.To,
+			sms:        strings.EqualFold(tc.Mode, "sms"),
+			client:     &http.Client{Timeout: 10 * time.Second},
+		}
+	case "pagerduty":
+		if ec.PagerDuty.RoutingKey == "" {
+			return nil, fmt.Errorf("PagerDuty routing_key 未配置")
+		}
+		provider = &pagerDutyProvider{
+			routingKey: ec.PagerDuty.RoutingKey,
+			client:     &http.Client{Timeout: 10 * time.Second},
+		}
+	default:
+		return nil, fmt.Errorf("不支持的告警升级通道: %s（可选 twilio、pagerduty）", ec.Provider)
+	}
+
+	en := &EscalationNotifier{
+		provider:      provider,
+		events:        make(map[event.EventType]bool),
+		minInterval:   time.Duration(ec.MinInterval) * time.Second,
+		maxPerHour:    ec.MaxPerHour,
+		scheduleStart: -1,
+		scheduleEnd:   -1,
+		weekdays:      make(map[time.Weekday]bool),
+		now:           utils.NowConfiguredTimezone,
+		lastSent:      make(map[string]time.Time),
+	}
+	for _, e := range ec.Events {
+		en.events[event.EventType(strings.TrimSpace(e))] = true
+	}
+
+	if ec.Schedule.Start != "" || ec.Schedule.End != "" {
+		start, err := parseClock(ec.Schedule.Start)
+		if err != nil {
+			return nil, fmt.Errorf("告警升级生效时段 start 格式错误: %w", err)
+		}
+		end, err := parseClock(ec.Schedule.End)
+		if err != nil {
+			return nil, fmt.Errorf("告警升级生效时段 end 格式错误: %w", err)
+		}
+		en.scheduleStart, en.scheduleEnd = start, end
+	}
+	for _, d := range ec.Schedule.Weekdays {
+		name := strings.ToLower(strings.TrimSpace(d))
+		if len(name) > 3 {
+			name = name[:3] // 兼容 monday 等全称
+		}
+		wd, ok := weekdayNames[name]
+		if !ok {
+			return nil, fmt.Errorf("告警升级生效星期格式错误: %s", d)
+		}
+		en.weekdays[wd] = true
+	}
+
+	return en, nil
+}
+
+// Name 返回通知器名称
+func (en *EscalationNotifier) Name() string {
+	return "Escalation/" + en.provider.Name()
+}
+
+// Handles 判断事件是否需要升级（仅限配置的严重事件）
+func (en *EscalationNotifier) Handles(eventType event.EventType) bool {
+	return en.events[eventType] && event.GetEventSeverity(eventType) == event.SeverityCritical
+}
+
+// Send 发送升级告警（不在生效时段或超出限流时静默跳过）
+func (en *EscalationNotifier) Send(evt *event.Event) error {
+	if !en.Handles(evt.Type) {
+		return nil
+	}
+	if !en.allow(evt) {
+		return nil
+	}
+
+	title := "QuantMesh " + event.GetEventTitle(evt.Type)
+	message := escalationMessage(evt)
+	if err := en.provider.Escalate(title, message, evt); err != nil {
+		return err
+	}
+	logger.Warn("📞 [告警升级] 已通过 %s 发送: %s", en.provider.Name(), title)
+	return nil
+}
+
+// allow 检查生效时段与限流，通过时记录发送时间
+func (en *EscalationNotifier) allow(evt *event.Event) bool {
+	now := en.now()
+	if !en.inSchedule(now) {
+		logger.Info("ℹ️ [告警升级] 不在生效时段，跳过: %s", evt.Type)
+		return false
+	}
+
+	en.mu.Lock()
+	defer en.mu.Unlock()
+
+	key := escalationKey(evt)
+	if last, ok := en.lastSent[key]; ok && now.Sub(last) < en.minInterval {
+		return false
+	}
+
+	recent := en.sent[:0]
+	for _, t := range en.sent {
+		if now.Sub(t) < time.Hour {
+			recent = append(recent, t)
+		}
+	}
+	en.sent = recent
+	if en.maxPerHour > 0 && len(en.sent) >= en.maxPerHour {
+		logger.Warn("⚠️ [告警升级] 已达到每小时上限 %d 次，跳过: %s", en.maxPerHour, evt.Type)
+		return false
+	}
+
+	en.lastSent[key] = now
+	en.sent = append(en.sent, now)
+	return true
+}
+
+// inSchedule 判断是否在生效时段内（支持跨午夜，如 22:00-08:00）
+func (en *EscalationNotifier) inSchedule(now time.Time) bool {
+	if len(en.weekdays) > 0 && !en.weekdays[now.Weekday()] {
+		return false
+	}
+	if en.scheduleStart < 0 || en.scheduleStart == en.scheduleEnd {
+		return true
+	}
+	minute := now.Hour()*60 + now.Minute()
+	if en.scheduleStart < en.scheduleEnd {
+		return minute >= en.scheduleStart && minute < en.scheduleEnd
+	}
+	return minute >= en.scheduleStart || minute < en.scheduleEnd
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func escalationKey(evt *event.Event) string {
+	exchange, _ := evt.Data["exchange"].(string)
+	symbol, _ := evt.Data["symbol"].(string)
+	return fmt.Sprintf("%s:%s:%s", evt.Type, exchange, symbol)
+}
+
+// escalationMessage 生成简短的告警内容（语音播报和短信都需要简短）
+func escalationMessage(evt *event.Event) string {
+	if msg, ok := evt.Data["message"].(string); ok && msg != "" {
+		return msg
+	}
+	parts := []string{event.GetEventTitle(evt.Type)}
+	for _, key := range []string{"exchange", "symbol", "goroutine"} {
+		if v, ok := evt.Data[key]; ok && fmt.Sprint(v) != "" {
+			parts = append(parts, fmt.Sprintf("%s: %v", key, v))
+		}
+	}
+	return strings.Join(parts, "，")
+}
+
+// twilioProvider Twilio 语音电话/短信
+type twilioProvider struct {
+	accountSID string
+	authToken  string
+	from       string
+	to         string
+	sms        bool
+	client     *http.Client
+}
+
+func (tp *twilioProvider) Name() string {
+	if tp.sms {
+		return "Twilio SMS"
+	}
+	return "Twilio Call"
+}
+
+func (tp *twilioProvider) Escalate(title, message string, evt *event.Event) error {
+	form := url.Values{}
+	form.Set("To", tp.to)
+	form.Set("From", tp.from)
+
+	endpoint := "Calls.json"
+	if tp.sms {
+		endpoint = "Messages.json"
+		form.Set("Body", title+"\n"+message)
+	} else {
+		var text bytes.Buffer
+		if err := xml.EscapeText(&text, []byte(title+"。"+message)); err != nil {
+			return fmt.Errorf("生成语音内容失败: %w", err)
+		}
+		// 重复播报两次，避免接听时错过开头
+		say := fmt.Sprintf(`<Say language="zh-CN">%s</Say>`, text.String())
+		form.Set("Twiml", "<Response>"+say+`<Pause length="1"/>`+say+"</Response>")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	apiURL := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/%s", tp.accountSID, endpoint)
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(tp.accountSID, tp.authToken)
+
+	resp, err := tp.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Twilio API 返回错误: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// pagerDutyProvider PagerDuty Events API v2
+type pagerDutyProvider struct {
+	routingKey string
+	client     *http.Client
+}
+
+func (pp *pagerDutyProvider) Name() string {
+	return "PagerDuty"
+}
+
+func (pp *pagerDutyProvider) Escalate(title, message string, evt *event.Event) error {
+	payload := map[string]interface{}{
+		"routing_key":  pp.routingKey,
+		"event_action": "trigger",
+		// 同一事件在 PagerDuty 侧合并为一个 incident
+		"dedup_key": "quantmesh:" + escalationKey(evt),
+		"payload": map[string]interface{}{
+			"summary":        title + ": " + message,
+			"source":         "quantmesh",
+			"severity":       "critical",
+			"timestamp":      evt.Timestamp.Format(time.RFC3339),
+			"component":      string(event.GetEventSource(evt.Type)),
+			"class":          string(evt.Type),
+			"custom_details": evt.Data,
+		},
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://events.pagerduty.com/v2/enqueue", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := pp.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("PagerDuty API 返回错误: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -16,6 +16,8 @@ import (
 type FeishuNotifier struct {
 	webhook string
 	client  *http.Client
+
+	templated // 自定义消息模板
 }
 
 // NewFeishuNotifier 创建飞书通知器
@@ -39,7 +41,7 @@ func (fn *FeishuNotifier) Name() string {
 
 // Send 发送通知
 func (fn *FeishuNotifier) Send(evt *event.Event) error {
-	message := formatFeishuMessage(evt)
+	message := fn.renderText("feishu", evt, formatFeishuMessage)
 	
 	payload := map[string]interface{}{
 		"msg_type": "text",
@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/event"
+)
+
+// GotifyNotifier Gotify 推送通知器
+type GotifyNotifier struct {
+	url    string
+	token  string
+	tokens map[string]string
+	client *http.Client
+
+	templated // 自定义消息模板
+}
+
+// NewGotifyNotifier 创建 Gotify 通知器
+func NewGotifyNotifier(cfg *config.Config) (*GotifyNotifier, error) {
+	gc := cfg.Notifications.Gotify
+	if gc.URL == "" {
+		return nil, fmt.Errorf("Gotify 服务器地址未配置")
+	}
+	if gc.Token == "" && len(gc.Tokens) == 0 {
+		return nil, fmt.Errorf("Gotify 应用令牌未配置")
+	}
+
+	return &GotifyNotifier{
+		url:    strings.TrimRight(gc.URL, "/"),
+		token:  gc.Token,
+		tokens: gc.Tokens,
+		client: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}, nil
+}
+
+// Name 返回通知器名称
+func (gn *GotifyNotifier) Name() string {
+	return "Gotify"
+}
+
+// Send 发送通知
+func (gn *GotifyNotifier) Send(evt *event.Event) error {
+	token := resolveEventRoute(gn.tokens, evt.Type, gn.token)
+	if token == "" {
+		// 仅配置了部分事件的令牌，其余事件不推送
+		return nil
+	}
+
+	title, message, ok := gn.render("gotify", evt)
+	if !ok {
+		title, message = event.GetEventTitle(evt.Type), formatPushBody(evt)
+	}
+	payload := map[string]interface{}{
+		"title":    title,
+		"message":  message,
+		"priority": gotifyPriority(event.GetEventSeverity(evt.Type)),
+		"extras": map[string]interface{}{
+			"client::display": map[string]string{"contentType": "text/plain"},
+		},
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", gn.url+"/message", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", token)
+
+	resp, err := gn.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Gotify 返回错误: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// gotifyPriority 事件级别映射为 Gotify 优先级（0-10，8 及以上在 Android 客户端弹出提醒）
+func gotifyPriority(severity event.EventSeverity) int {
+	switch severity {
+	case event.SeverityCritical:
+		return 9
+	case event.SeverityWarning:
+		return 6
+	default:
+		return 3
+	}
+}
@@ -1,11 +1,15 @@
 package notify
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"quantmesh/config"
 	"quantmesh/event"
 	"quantmesh/logger"
+	"quantmesh/utils"
 )
 
 // Notifier 通知接口
@@ -16,8 +20,10 @@ type Notifier interface {
 
 // NotificationService 通知服务
 type NotificationService struct {
-	notifiers []Notifier
-	cfg       *config.Config
+	notifiers  []Notifier
+	escalation *EscalationNotifier // 严重告警升级通道（独立于普通通知开关）
+	templates  *MessageTemplates   // 自定义消息模板
+	cfg        *config.Config
 }
 
 // NewNotificationService 创建通知服务
@@ -26,6 +32,16 @@ func NewNotificationService(cfg *config.Config) *NotificationService {
 		cfg: cfg,
 	}
 
+	if cfg.Notifications.Escalation.Enabled {
+		escalation, err := NewEscalationNotifier(cfg)
+		if err != nil {
+			logger.Warn("⚠️ 初始化告警升级通道失败: %v", err)
+		} else {
+			ns.escalation = escalation
+			logger.Info("✅ 告警升级通道已启用 (%s, 事件: %v)", escalation.provider.Name(), cfg.Notifications.Escalation.Events)
+		}
+	}
+
 	// 初始化启用的通知渠道
 	if cfg.Notifications.Enabled {
 		if cfg.Notifications.Telegram.Enabled && cfg.Notifications.Telegram.BotToken != "" {
@@ -97,6 +113,37 @@ func NewNotificationService(cfg *config.Config) *NotificationService {
 				logger.Info("✅ Slack 通知已启用")
 			}
 		}
+
+		if cfg.Notifications.Ntfy.Enabled && cfg.Notifications.Ntfy.Topic != "" {
+			ntfyNotifier, err := NewNtfyNotifier(cfg)
+			if err != nil {
+				logger.Warn("⚠️ 初始化 ntfy 通知失败: %v", err)
+			} else {
+				ns.notifiers = append(ns.notifiers, ntfyNotifier)
+				logger.Info("✅ ntfy 通知已启用 (服务器: %s)", cfg.Notifications.Ntfy.Server)
+			}
+		}
+
+		if cfg.Notifications.Gotify.Enabled && cfg.Notifications.Gotify.URL != "" {
+			gotifyNotifier, err := NewGotifyNotifier(cfg)
+			if err != nil {
+				logger.Warn("⚠️ 初始化 Gotify 通知失败: %v", err)
+			} else {
+				ns.notifiers = append(ns.notifiers, gotifyNotifier)
+				logger.Info("✅ Gotify 通知已启用")
+			}
+		}
+	}
+
+	templates, err := NewMessageTemplates(cfg.Notifications.Templates)
+	if err != nil {
+		logger.Warn("⚠️ 部分通知模板无效，对应事件使用内置格式: %v", err)
+	}
+	ns.templates = templates
+	for _, n := range ns.notifiers {
+		if t, ok := n.(interface{ setTemplates(*MessageTemplates) }); ok {
+			t.setTemplates(templates)
+		}
 	}
 
 	return ns
@@ -138,6 +185,16 @@ func (ns *NotificationService) Send(evt *event.Event) {
 		return
 	}
 
+	// 严重事件升级（电话/短信/PagerDuty），有独立的限流和生效时段
+	if ns.escalation != nil && ns.escalation.Handles(evt.Type) {
+		go func() {
+			defer utils.RecoverPanic("notify-escalation")
+			if err := ns.escalation.Send(evt); err != nil {
+				logger.Error("❌ [%s] 告警升级发送失败: %v", ns.escalation.Name(), err)
+			}
+		}()
+	}
+
 	// 检查是否需要通知
 	if !ns.shouldNotify(evt.Type) {
 		return
@@ -159,3 +216,32 @@ func (ns *NotificationService) Send(evt *event.Event) {
 		wg.Wait()
 	}()
 }
+
+// resolveEventRoute 按事件类型、事件级别的顺序查找路由（ntfy 主题、Gotify 应用令牌等），未匹配时返回默认值
+func resolveEventRoute(routes map[string]string, eventType event.EventType, fallback string) string {
+	if v, ok := routes[string(eventType)]; ok && v != "" {
+		return v
+	}
+	if v, ok := routes[string(event.GetEventSeverity(eventType))]; ok && v != "" {
+		return v
+	}
+	return fallback
+}
+
+// formatPushBody 格式化手机推送正文（标题单独发送）
+func formatPushBody(evt *event.Event) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("时间: %s", evt.Timestamp.Format("2006-01-02 15:04:05")))
+
+	if len(evt.Data) > 0 {
+		keys := make([]string, 0, len(evt.Data))
+		for key := range evt.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			sb.WriteString(fmt.Sprintf("\n%s: %v", key, evt.Data[key]))
+		}
+	}
+	return sb.String()
+}
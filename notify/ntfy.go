@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/event"
+)
+
+// NtfyNotifier ntfy 推送通知器（支持 ntfy.sh 与自建服务器）
+type NtfyNotifier struct {
+	server   string
+	topic    string
+	topics   map[string]string
+	token    string
+	username string
+	password string
+	client   *http.Client
+
+	templated // 自定义消息模板
+}
+
+// NewNtfyNotifier 创建 ntfy 通知器
+func NewNtfyNotifier(cfg *config.Config) (*NtfyNotifier, error) {
+	nc := cfg.Notifications.Ntfy
+	if nc.Topic == "" {
+		return nil, fmt.Errorf("ntfy 主题未配置")
+	}
+
+	server := strings.TrimRight(nc.Server, "/")
+	if server == "" {
+		server = "https://ntfy.sh"
+	}
+
+	return &NtfyNotifier{
+		server:   server,
+		topic:    nc.Topic,
+		topics:   nc.Topics,
+		token:    nc.Token,
+		username: nc.Username,
+		password: nc.Password,
+		client: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}, nil
+}
+
+// Name 返回通知器名称
+func (nn *NtfyNotifier) Name() string {
+	return "ntfy"
+}
+
+// Send 发送通知
+// 使用 JSON 发布接口，避免中文标题放在请求头中出现编码问题
+func (nn *NtfyNotifier) Send(evt *event.Event) error {
+	severity := event.GetEventSeverity(evt.Type)
+	title, message, ok := nn.render("ntfy", evt)
+	if !ok {
+		title, message = event.GetEventTitle(evt.Type), formatPushBody(evt)
+	}
+	payload := map[string]interface{}{
+		"topic":    resolveEventRoute(nn.topics, evt.Type, nn.topic),
+		"title":    title,
+		"message":  message,
+		"priority": ntfyPriority(severity),
+		"tags":     []string{ntfyTag(severity), string(evt.Type)},
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", nn.server, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if nn.token != "" {
+		req.Header.Set("Authorization", "Bearer "+nn.token)
+	} else if nn.username != "" {
+		req.SetBasicAuth(nn.username, nn.password)
+	}
+
+	resp, err := nn.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ntfy 返回错误: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// ntfyPriority 事件级别映射为 ntfy 优先级（1-5）
+func ntfyPriority(severity event.EventSeverity) int {
+	switch severity {
+	case event.SeverityCritical:
+		return 5
+	case event.SeverityWarning:
+		return 4
+	default:
+		return 3
+	}
+}
+
+// ntfyTag 事件级别映射为 ntfy 标签（显示为表情）
+func ntfyTag(severity event.EventSeverity) string {
+	switch severity {
+	case event.SeverityCritical:
+		return "rotating_light"
+	case event.SeverityWarning:
+		return "warning"
+	default:
+		return "information_source"
+	}
+}
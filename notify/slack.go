@@ -16,6 +16,8 @@ import (
 type SlackNotifier struct {
 	webhook string
 	client  *http.Client
+
+	templated // 自定义消息模板
 }
 
 // NewSlackNotifier 创建 Slack 通知器
@@ -39,7 +41,7 @@ func (sn *SlackNotifier) Name() string {
 
 // Send 发送通知
 func (sn *SlackNotifier) Send(evt *event.Event) error {
-	message := formatSlackMessage(evt)
+	message := sn.renderText("slack", evt, formatSlackMessage)
 	
 	payload := map[string]interface{}{
 		"text": message,
@@ -17,6 +17,8 @@ type TelegramNotifier struct {
 	botToken string
 	chatID   string
 	client   *http.Client
+
+	templated // 自定义消息模板
 }
 
 // NewTelegramNotifier 创建 Telegram 通知器
@@ -41,7 +43,7 @@ func (tn *TelegramNotifier) Name() string {
 
 // Send 发送通知
 func (tn *TelegramNotifier) Send(evt *event.Event) error {
-	message := formatTelegramMessage(evt)
+	message := tn.renderText("telegram", evt, formatTelegramMessage)
 	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", tn.botToken)
 
 	payload := map[string]interface{}{
@@ -0,0 +1,346 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/event"
+	"quantmesh/logger"
+	"quantmesh/utils"
+)
+
+// TemplateData 通知模板可引用的字段
+type TemplateData struct {
+	Type     string                 // 事件类型，如 order_filled
+	Title    string                 // 内置标题
+	Severity string                 // critical / warning / info
+	Channel  string                 // 渠道标识，如 telegram
+	Time     time.Time              // 事件时间（配置的时区）
+	Exchange string                 // 事件数据中的 exchange
+	Symbol   string                 // 事件数据中的 symbol
+	PnL      float64                // 事件数据中的盈亏（依次取 pnl / net_pnl / realized_pnl / profit）
+	HasPnL   bool                   // 事件数据是否带盈亏
+	Data     map[string]interface{} // 全部事件数据，如 {{.Data.price}}
+}
+
+// templatePnLKeys 盈亏字段的查找顺序
+var templatePnLKeys = []string{"pnl", "net_pnl", "realized_pnl", "profit"}
+
+// templateFuncs 模板可用的函数
+var templateFuncs = template.FuncMap{
+	// time 按布局格式化时间：{{time "15:04" .Time}}
+	"time": func(layout string, t time.Time) string { return t.Format(layout) },
+	// round 保留小数位：{{round 2 .PnL}}
+	"round": func(decimals int, v interface{}) string {
+		f, ok := toFloat(v)
+		if !ok {
+			return fmt.Sprint(v)
+		}
+		return strconv.FormatFloat(f, 'f', decimals, 64)
+	},
+	// signed 带正负号：{{signed 2 .PnL}} -> +1.23
+	"signed": func(decimals int, v interface{}) string {
+		f, _ := toFloat(v)
+		return fmt.Sprintf("%+.*f", decimals, f)
+	},
+	// default 值为空时使用默认值：{{default "-" .Data.price}}
+	"default": func(def string, v interface{}) interface{} {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"json": func(v interface{}) string {
+		data, _ := json.Marshal(v)
+		return string(data)
+	},
+}
+
+// messageTemplate 编译后的标题和正文模板（为空表示使用内置内容）
+type messageTemplate struct {
+	title *template.Template
+	body  *template.Template
+}
+
+// MessageTemplates 按渠道和事件类型匹配的自定义通知模板
+type MessageTemplates struct {
+	templates map[string]*messageTemplate
+}
+
+// NewMessageTemplates 编译配置的模板；无效的模板跳过（对应事件使用内置格式）并在返回的错误中列出
+func NewMessageTemplates(defs map[string]config.NotificationTemplateConfig) (*MessageTemplates, error) {
+	mt := &MessageTemplates{templates: make(map[string]*messageTemplate, len(defs))}
+	var errs []error
+	for key, def := range defs {
+		compiled, err := compileTemplate(key, def)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		mt.templates[key] = compiled
+	}
+	return mt, errors.Join(errs...)
+}
+
+// compileTemplate 编译单个模板
+func compileTemplate(key string, def config.NotificationTemplateConfig) (*messageTemplate, error) {
+	compiled := &messageTemplate{}
+	var err error
+	if def.Title != "" {
+		if compiled.title, err = template.New(key + ".title").Funcs(templateFuncs).Parse(def.Title); err != nil {
+			return nil, fmt.Errorf("模板 %s 的标题无效: %w", key, err)
+		}
+	}
+	if def.Body != "" {
+		if compiled.body, err = template.New(key + ".body").Funcs(templateFuncs).Parse(def.Body); err != nil {
+			return nil, fmt.Errorf("模板 %s 的正文无效: %w", key, err)
+		}
+	}
+	return compiled, nil
+}
+
+// lookup 按 渠道.事件类型、事件类型、渠道.事件级别、事件级别、渠道.default、default 的顺序查找模板
+func (mt *MessageTemplates) lookup(channel string, eventType event.EventType) (string, *messageTemplate) {
+	if mt == nil {
+		return "", nil
+	}
+	severity := string(event.GetEventSeverity(eventType))
+	for _, name := range []string{string(eventType), severity, "default"} {
+		for _, key := range []string{channel + "." + name, name} {
+			if t, ok := mt.templates[key]; ok {
+				return key, t
+			}
+		}
+	}
+	return "", nil
+}
+
+// Render 按模板渲染渠道消息，没有匹配的模板时 ok 为 false（使用渠道内置格式）
+func (mt *MessageTemplates) Render(channel string, evt *event.Event) (title, body string, ok bool, err error) {
+	key, t := mt.lookup(channel, evt.Type)
+	if t == nil {
+		return "", "", false, nil
+	}
+	title, body, err = t.execute(NewTemplateData(channel, evt))
+	if err != nil {
+		return "", "", false, fmt.Errorf("模板 %s 渲染失败: %w", key, err)
+	}
+	return title, body, true, nil
+}
+
+// execute 渲染标题和正文，未配置的部分使用内置标题和字段列表
+func (t *messageTemplate) execute(data TemplateData) (string, string, error) {
+	title, body := data.Title, formatPushBody(&event.Event{Type: event.EventType(data.Type), Data: data.Data, Timestamp: data.Time})
+	var sb strings.Builder
+	if t.title != nil {
+		if err := t.title.Execute(&sb, data); err != nil {
+			return "", "", err
+		}
+		title = strings.TrimSpace(sb.String())
+		sb.Reset()
+	}
+	if t.body != nil {
+		if err := t.body.Execute(&sb, data); err != nil {
+			return "", "", err
+		}
+		body = strings.TrimRight(sb.String(), "\n")
+	}
+	return title, body, nil
+}
+
+// RenderTemplate 用临时模板渲染（编辑模板时预览）
+func RenderTemplate(def config.NotificationTemplateConfig, channel string, evt *event.Event) (string, string, error) {
+	t, err := compileTemplate("preview", def)
+	if err != nil {
+		return "", "", err
+	}
+	return t.execute(NewTemplateData(channel, evt))
+}
+
+// NewTemplateData 从事件提取模板字段
+func NewTemplateData(channel string, evt *event.Event) TemplateData {
+	data := TemplateData{
+		Type:     string(evt.Type),
+		Title:    event.GetEventTitle(evt.Type),
+		Severity: string(event.GetEventSeverity(evt.Type)),
+		Channel:  channel,
+		Time:     utils.ToConfiguredTimezone(evt.Timestamp),
+		Data:     evt.Data,
+	}
+	if data.Data == nil {
+		data.Data = map[string]interface{}{}
+	}
+	data.Exchange, _ = data.Data["exchange"].(string)
+	data.Symbol, _ = data.Data["symbol"].(string)
+	for _, key := range templatePnLKeys {
+		if v, ok := toFloat(data.Data[key]); ok {
+			data.PnL, data.HasPnL = v, true
+			break
+		}
+	}
+	return data
+}
+
+// toFloat 把事件数据中的数值（含 JSON 解码得到的 float64 和数字字符串）转为 float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, !math.IsNaN(n)
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// templated 支持自定义模板的渠道嵌入此结构，由 NotificationService 创建渠道后注入模板
+type templated struct {
+	templates *MessageTemplates
+}
+
+func (t *templated) setTemplates(mt *MessageTemplates) {
+	t.templates = mt
+}
+
+// render 渲染自定义模板，渲染失败时记录日志并返回 ok=false（使用内置格式，不丢通知）
+func (t *templated) render(channel string, evt *event.Event) (string, string, bool) {
+	title, body, ok, err := t.templates.Render(channel, evt)
+	if err != nil {
+		logger.Warn("⚠️ [通知模板] %s 渠道 %v，使用内置格式", channel, err)
+		return "", "", false
+	}
+	return title, body, ok
+}
+
+// renderText 单段文本消息：有自定义模板时为"标题 + 空行 + 正文"，否则使用渠道内置格式
+func (t *templated) renderText(channel string, evt *event.Event, builtin func(*event.Event) string) string {
+	title, body, ok := t.render(channel, evt)
+	if !ok {
+		return builtin(evt)
+	}
+	return joinTitleBody(title, body)
+}
+
+// joinTitleBody 拼接标题和正文
+func joinTitleBody(title, body string) string {
+	switch {
+	case title == "":
+		return body
+	case body == "":
+		return title
+	}
+	return title + "\n\n" + body
+}
+
+// builtinFormats 各渠道未配置模板时的内置格式（预览用），单段文本渠道的标题为空
+var builtinFormats = map[string]func(evt *event.Event) (string, string){
+	"telegram":    func(evt *event.Event) (string, string) { return "", formatTelegramMessage(evt) },
+	"feishu":      func(evt *event.Event) (string, string) { return "", formatFeishuMessage(evt) },
+	"dingtalk":    func(evt *event.Event) (string, string) { return "", formatDingTalkMessage(evt) },
+	"wechat_work": func(evt *event.Event) (string, string) { return "", formatWeChatWorkMessage(evt) },
+	"slack":       func(evt *event.Event) (string, string) { return "", formatSlackMessage(evt) },
+	"email": func(evt *event.Event) (string, string) {
+		return fmt.Sprintf("QuantMesh 通知: %s", string(evt.Type)), formatEmailMessage(evt)
+	},
+	"webhook": func(evt *event.Event) (string, string) {
+		data, _ := json.MarshalIndent(map[string]interface{}{
+			"type":      string(evt.Type),
+			"timestamp": evt.Timestamp.Format(time.RFC3339),
+			"data":      evt.Data,
+		}, "", "  ")
+		return "", string(data)
+	},
+}
+
+// TemplatePreview 通知预览结果
+type TemplatePreview struct {
+	Channel  string `json:"channel"`
+	Template string `json:"template"` // 匹配的模板键，使用内置格式时为空
+	Title    string `json:"title"`
+	Body     string `json:"body"`
+}
+
+// Preview 预览事件在某个渠道的通知内容
+// def 不为空时使用临时模板（编辑模板时预览），否则按已配置的模板匹配，未匹配时返回渠道的内置格式
+func (ns *NotificationService) Preview(channel string, evt *event.Event, def *config.NotificationTemplateConfig) (*TemplatePreview, error) {
+	preview := &TemplatePreview{Channel: channel}
+	var err error
+	switch {
+	case def != nil:
+		preview.Template = "preview"
+		preview.Title, preview.Body, err = RenderTemplate(*def, channel, evt)
+	default:
+		var ok bool
+		if preview.Title, preview.Body, ok, err = ns.templates.Render(channel, evt); ok {
+			preview.Template, _ = ns.templates.lookup(channel, evt.Type)
+		} else if err == nil {
+			if format, found := builtinFormats[channel]; found {
+				preview.Title, preview.Body = format(evt)
+			} else {
+				preview.Title, preview.Body = event.GetEventTitle(evt.Type), formatPushBody(evt)
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// SendTest 立即向全部已启用的渠道发送事件（不经过通知规则过滤），返回各渠道的发送结果（成功为空字符串）
+func (ns *NotificationService) SendTest(evt *event.Event) map[string]string {
+	results := make(map[string]string, len(ns.notifiers))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, notifier := range ns.notifiers {
+		wg.Add(1)
+		go func(n Notifier) {
+			defer wg.Done()
+			result := ""
+			if err := n.Send(evt); err != nil {
+				result = err.Error()
+			}
+			mu.Lock()
+			results[n.Name()] = result
+			mu.Unlock()
+		}(notifier)
+	}
+	wg.Wait()
+	return results
+}
+
+// SampleEvent 构造预览和测试用的事件，data 为空时使用示例成交数据
+func SampleEvent(eventType string, data map[string]interface{}) *event.Event {
+	if len(data) == 0 {
+		data = map[string]interface{}{
+			"exchange": "binance",
+			"symbol":   "BTCUSDT",
+			"side":     "SELL",
+			"price":    65000.5,
+			"quantity": 0.01,
+			"pnl":      1.23,
+		}
+	}
+	return &event.Event{Type: event.EventType(eventType), Data: data, Timestamp: time.Now()}
+}
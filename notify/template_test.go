@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/event"
+)
+
+func TestMessageTemplates(t *testing.T) {
+	mt, err := NewMessageTemplates(map[string]config.NotificationTemplateConfig{
+		"order_filled":          {Title: "{{.Symbol}} 成交", Body: "盈亏 {{signed 2 .PnL}} @ {{round 1 .Data.price}}"},
+		"telegram.order_filled": {Body: "[{{upper .Exchange}}] {{.Symbol}} {{default \"-\" .Data.missing}}"},
+		"critical":              {Title: "🚨 {{.Title}}"},
+		"broken":                {Body: "{{.Symbol"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("无效模板应返回错误: %v", err)
+	}
+
+	evt := &event.Event{
+		Type:      event.EventTypeOrderFilled,
+		Timestamp: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC),
+		Data:      map[string]interface{}{"exchange": "binance", "symbol": "BTCUSDT", "price": 65000.54, "pnl": 1.234},
+	}
+	title, body, ok, err := mt.Render("slack", evt)
+	if err != nil || !ok || title != "BTCUSDT 成交" || body != "盈亏 +1.23 @ 65000.5" {
+		t.Errorf("通用模板渲染错误: %q %q ok=%v err=%v", title, body, ok, err)
+	}
+
+	// 渠道模板优先，未配置的标题使用内置标题
+	title, body, ok, _ = mt.Render("telegram", evt)
+	if !ok || title != event.GetEventTitle(event.EventTypeOrderFilled) || body != "[BINANCE] BTCUSDT -" {
+		t.Errorf("渠道模板渲染错误: %q %q", title, body)
+	}
+
+	// 按事件级别匹配，未配置的正文使用字段列表
+	title, body, ok, _ = mt.Render("slack", &event.Event{Type: event.EventTypeStopLoss, Data: map[string]interface{}{"symbol": "ETHUSDT"}})
+	if !ok || !strings.HasPrefix(title, "🚨 ") || !strings.Contains(body, "symbol: ETHUSDT") {
+		t.Errorf("事件级别模板渲染错误: %q %q", title, body)
+	}
+
+	if _, _, ok, _ := mt.Render("slack", &event.Event{Type: event.EventTypeOrderPlaced}); ok {
+		t.Error("没有匹配的模板时应使用内置格式")
+	}
+	var empty *MessageTemplates
+	if _, _, ok, err := empty.Render("slack", evt); ok || err != nil {
+		t.Error("未配置模板时应使用内置格式")
+	}
+}
@@ -17,6 +17,8 @@ type WebhookNotifier struct {
 	url     string
 	timeout time.Duration
 	client  *http.Client
+
+	templated // 自定义消息模板
 }
 
 // NewWebhookNotifier 创建 Webhook 通知器
@@ -51,6 +53,11 @@ func (wn *WebhookNotifier) Send(evt *event.Event) error {
 		"timestamp": evt.Timestamp.Format(time.RFC3339),
 		"data":      evt.Data,
 	}
+	// 配置了模板时附带渲染后的文本，接收方可直接转发
+	if title, message, ok := wn.render("webhook", evt); ok {
+		payload["title"] = title
+		payload["message"] = message
+	}
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
@@ -16,6 +16,8 @@ import (
 type WeChatWorkNotifier struct {
 	webhook string
 	client  *http.Client
+
+	templated // 自定义消息模板
 }
 
 // NewWeChatWorkNotifier 创建企业微信通知器
@@ -39,7 +41,7 @@ func (wn *WeChatWorkNotifier) Name() string {
 
 // Send 发送通知
 func (wn *WeChatWorkNotifier) Send(evt *event.Event) error {
-	message := formatWeChatWorkMessage(evt)
+	message := wn.renderText("wechat_work", evt, formatWeChatWorkMessage)
 	
 	payload := map[string]interface{}{
 		"msgtype": "text",
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"sync"
+
+	"quantmesh/logger"
+	"quantmesh/monitor"
+)
+
+// openInterestSpikeProfile 持仓量突增时覆盖挂单窗口使用的名称
+const openInterestSpikeProfile = "oi_spike"
+
+// openInterestSpikeHandlers 根据配置的动作生成持仓量突增的触发/恢复回调
+// shrink_window：缩小买单窗口，减少新增敞口；pause：暂停交易（只恢复由本规则暂停的交易对）；notify：仅依赖事件告警
+func openInterestSpikeHandlers(symbolManager *SymbolManager, exchangeName, action string, buyWindowSize int) (func(*monitor.OpenInterestSpike), func(string)) {
+	var (
+		mu     sync.Mutex
+		paused = make(map[string]bool)
+	)
+
+	onSpike := func(spike *monitor.OpenInterestSpike) {
+		rt, ok := symbolManager.Get(exchangeName, spike.Symbol)
+		if !ok || rt.SuperPositionManager == nil {
+			return
+		}
+		switch action {
+		case "shrink_window":
+			rt.SuperPositionManager.SetWindowProfile(openInterestSpikeProfile, buyWindowSize, 0)
+		case "pause":
+			mu.Lock()
+			defer mu.Unlock()
+			if !rt.SuperPositionManager.IsPaused() {
+				rt.SuperPositionManager.Pause()
+				paused[spike.Symbol] = true
+			}
+		default:
+			return
+		}
+		logger.Warn("⚠️ [%s] 持仓量 %d 分钟内变化 %.2f%%，执行风控动作: %s",
+			spike.Symbol, spike.WindowMinutes, spike.ChangePercent, action)
+	}
+
+	onRecover := func(symbol string) {
+		rt, ok := symbolManager.Get(exchangeName, symbol)
+		if !ok || rt.SuperPositionManager == nil {
+			return
+		}
+		switch action {
+		case "shrink_window":
+			// 只清除本规则设置的窗口，避免覆盖市场状态分类器等其他来源
+			if name, _, _ := rt.SuperPositionManager.GetWindowProfile(); name == openInterestSpikeProfile {
+				rt.SuperPositionManager.ClearWindowProfile()
+			}
+		case "pause":
+			mu.Lock()
+			defer mu.Unlock()
+			if paused[symbol] {
+				delete(paused, symbol)
+				rt.SuperPositionManager.Resume()
+			}
+		}
+	}
+
+	return onSpike, onRecover
+}
@@ -0,0 +1,217 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+)
+
+// ErrTurnoverBudgetExhausted 策略的交易额度（下单名义价值/订单数）已用尽，在本地拦截，不再提交到交易所
+var ErrTurnoverBudgetExhausted = errors.New("交易额度已用尽")
+
+// defaultBudgetStrategy 未标记策略名称的订单（网格主策略）使用的额度名称
+const defaultBudgetStrategy = "grid"
+
+// TurnoverUsage 单个策略的交易额度使用情况（滚动 1 小时 / 24 小时）
+type TurnoverUsage struct {
+	Strategy        string                `json:"strategy"`
+	HourNotional    float64               `json:"hour_notional"`
+	DayNotional     float64               `json:"day_notional"`
+	HourOrders      int                   `json:"hour_orders"`
+	DayOrders       int                   `json:"day_orders"`
+	Limits          config.TurnoverLimits `json:"limits"`
+	Exhausted       bool                  `json:"exhausted"`
+	ExhaustedReason string                `json:"exhausted_reason,omitempty"`
+}
+
+// BudgetExhausted 额度用尽通知
+type BudgetExhausted struct {
+	Exchange string
+	Symbol   string
+	Strategy string
+	Reason   string
+	Usage    TurnoverUsage
+}
+
+// budgetBucket 按分钟聚合的下单记录
+type budgetBucket struct {
+	minute   int64
+	notional float64
+	orders   int
+}
+
+type budgetState struct {
+	kind      string         // 策略类型（如 grid/dca），按类型配置额度时使用
+	buckets   []budgetBucket // 按时间顺序，只保留 24 小时内
+	exhausted bool
+	reason    string
+}
+
+// turnoverBudget 按策略限制滚动窗口内的下单名义价值和订单数，防止异常循环高频下单消耗手续费
+type turnoverBudget struct {
+	cfg         config.TurnoverBudgetConfig
+	onExhausted func(BudgetExhausted)
+
+	mu     sync.Mutex
+	states map[string]*budgetState
+}
+
+// EnableTurnoverBudget 启用交易额度限制
+// 下单前检查该策略滚动 1 小时 / 24 小时内已提交订单的名义价值和数量，超过上限的订单直接拦截；
+// 每次从可用变为用尽时调用 onExhausted（额度随时间窗口滚动自动恢复）
+func (oe *ExchangeOrderExecutor) EnableTurnoverBudget(cfg config.TurnoverBudgetConfig, onExhausted func(BudgetExhausted)) {
+	oe.budget = &turnoverBudget{
+		cfg:         cfg,
+		onExhausted: onExhausted,
+		states:      make(map[string]*budgetState),
+	}
+}
+
+// checkTurnoverBudget 下单前检查额度，用尽时返回 ErrTurnoverBudgetExhausted
+func (oe *ExchangeOrderExecutor) checkTurnoverBudget(req *OrderRequest) error {
+	if oe.budget == nil {
+		return nil
+	}
+	name := budgetStrategyName(req)
+	reason, notify := oe.budget.check(name, req.StrategyType, req.Price*req.Quantity, time.Now())
+	if reason == "" {
+		return nil
+	}
+	if notify {
+		logger.Warn("⛽ [%s:%s] 策略 %s 交易额度已用尽（%s），暂停下单直至额度恢复",
+			oe.exchange.GetName(), oe.symbol, name, reason)
+		if oe.budget.onExhausted != nil {
+			oe.budget.onExhausted(BudgetExhausted{
+				Exchange: oe.exchange.GetName(),
+				Symbol:   oe.symbol,
+				Strategy: name,
+				Reason:   reason,
+				Usage:    oe.budget.usageOf(name, time.Now()),
+			})
+		}
+	}
+	return fmt.Errorf("%w: 策略 %s %s", ErrTurnoverBudgetExhausted, name, reason)
+}
+
+// recordTurnover 记录一笔已成功提交的订单
+func (oe *ExchangeOrderExecutor) recordTurnover(req *OrderRequest) {
+	if oe.budget == nil {
+		return
+	}
+	oe.budget.record(budgetStrategyName(req), req.Price*req.Quantity, time.Now())
+}
+
+// budgetStrategyName 订单所属的额度名称：策略名称，未标记时为网格主策略
+func budgetStrategyName(req *OrderRequest) string {
+	if req.StrategyName != "" {
+		return req.StrategyName
+	}
+	return defaultBudgetStrategy
+}
+
+// limits 策略的额度上限：优先按策略名称，其次按策略类型，最后使用默认值
+func (b *turnoverBudget) limits(name, kind string) config.TurnoverLimits {
+	if l, ok := b.cfg.Strategies[name]; ok {
+		return l
+	}
+	if l, ok := b.cfg.Strategies[kind]; ok && kind != "" {
+		return l
+	}
+	return b.cfg.Default
+}
+
+// check 判断加上本次订单后是否超过上限；返回超限原因（为空表示允许）和是否为新的用尽（需要通知）
+func (b *turnoverBudget) check(name, kind string, notional float64, now time.Time) (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state(name)
+	state.kind = kind
+	hourNotional, dayNotional, hourOrders, dayOrders := state.totals(now)
+	l := b.limits(name, kind)
+
+	var reason string
+	switch {
+	case l.MaxOrdersPerHour > 0 && hourOrders+1 > l.MaxOrdersPerHour:
+		reason = fmt.Sprintf("1 小时内已下单 %d 笔，上限 %d", hourOrders, l.MaxOrdersPerHour)
+	case l.MaxOrdersPerDay > 0 && dayOrders+1 > l.MaxOrdersPerDay:
+		reason = fmt.Sprintf("24 小时内已下单 %d 笔，上限 %d", dayOrders, l.MaxOrdersPerDay)
+	case l.MaxNotionalPerHour > 0 && hourNotional+notional > l.MaxNotionalPerHour:
+		reason = fmt.Sprintf("1 小时内下单金额 %.2f + %.2f 超过上限 %.2f", hourNotional, notional, l.MaxNotionalPerHour)
+	case l.MaxNotionalPerDay > 0 && dayNotional+notional > l.MaxNotionalPerDay:
+		reason = fmt.Sprintf("24 小时内下单金额 %.2f + %.2f 超过上限 %.2f", dayNotional, notional, l.MaxNotionalPerDay)
+	}
+
+	if reason == "" {
+		if state.exhausted {
+			state.exhausted, state.reason = false, ""
+			logger.Info("⛽ 策略 %s 交易额度已恢复", name)
+		}
+		return "", false
+	}
+	notify := !state.exhausted
+	state.exhausted, state.reason = true, reason
+	return reason, notify
+}
+
+// record 计入一笔订单
+func (b *turnoverBudget) record(name string, notional float64, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state(name)
+	minute := now.Unix() / 60
+	if n := len(state.buckets); n > 0 && state.buckets[n-1].minute == minute {
+		state.buckets[n-1].notional += notional
+		state.buckets[n-1].orders++
+		return
+	}
+	state.buckets = append(state.buckets, budgetBucket{minute: minute, notional: notional, orders: 1})
+}
+
+// usageOf 策略当前的额度使用情况
+func (b *turnoverBudget) usageOf(name string, now time.Time) TurnoverUsage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state := b.state(name)
+	u := TurnoverUsage{
+		Strategy:        name,
+		Limits:          b.limits(name, state.kind),
+		Exhausted:       state.exhausted,
+		ExhaustedReason: state.reason,
+	}
+	u.HourNotional, u.DayNotional, u.HourOrders, u.DayOrders = state.totals(now)
+	return u
+}
+
+func (b *turnoverBudget) state(name string) *budgetState {
+	state, ok := b.states[name]
+	if !ok {
+		state = &budgetState{}
+		b.states[name] = state
+	}
+	return state
+}
+
+// totals 清理 24 小时前的记录并汇总滚动 1 小时 / 24 小时内的下单金额和数量
+func (s *budgetState) totals(now time.Time) (hourNotional, dayNotional float64, hourOrders, dayOrders int) {
+	minute := now.Unix() / 60
+	drop := 0
+	for drop < len(s.buckets) && s.buckets[drop].minute <= minute-24*60 {
+		drop++
+	}
+	s.buckets = s.buckets[drop:]
+	for _, bucket := range s.buckets {
+		dayNotional += bucket.notional
+		dayOrders += bucket.orders
+		if bucket.minute > minute-60 {
+			hourNotional += bucket.notional
+			hourOrders += bucket.orders
+		}
+	}
+	return
+}
@@ -0,0 +1,50 @@
+package order
+
+import (
+	"testing"
+	"time"
+
+	"quantmesh/config"
+)
+
+func TestTurnoverBudget(t *testing.T) {
+	b := &turnoverBudget{
+		cfg: config.TurnoverBudgetConfig{
+			Enabled: true,
+			Default: config.TurnoverLimits{MaxOrdersPerHour: 2},
+			Strategies: map[string]config.TurnoverLimits{
+				"dca": {MaxNotionalPerDay: 1000},
+			},
+		},
+		states: make(map[string]*budgetState),
+	}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 默认额度：每小时 2 笔
+	for i := 0; i < 2; i++ {
+		if reason, _ := b.check("grid", "", 100, start); reason != "" {
+			t.Fatalf("order %d should be allowed: %s", i, reason)
+		}
+		b.record("grid", 100, start)
+	}
+	reason, notify := b.check("grid", "", 100, start.Add(time.Minute))
+	if reason == "" || !notify {
+		t.Fatalf("third order within an hour should be blocked with notification: %q %v", reason, notify)
+	}
+	if _, notify := b.check("grid", "", 100, start.Add(2*time.Minute)); notify {
+		t.Errorf("exhaustion should only be notified once")
+	}
+	// 窗口滚动后恢复
+	if reason, _ := b.check("grid", "", 100, start.Add(61*time.Minute)); reason != "" {
+		t.Errorf("budget should recover after the hour window: %s", reason)
+	}
+
+	// 按策略类型匹配额度：24 小时下单金额上限 1000
+	b.record("DCA-ETHUSDT", 900, start)
+	if reason, _ := b.check("DCA-ETHUSDT", "dca", 200, start.Add(12*time.Hour)); reason == "" {
+		t.Errorf("dca notional over the daily budget should be blocked")
+	}
+	if reason, _ := b.check("DCA-ETHUSDT", "dca", 200, start.Add(24*time.Hour)); reason != "" {
+		t.Errorf("dca budget should recover after 24h: %s", reason)
+	}
+}
@@ -2,6 +2,7 @@ package order
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"quantmesh/exchange"
@@ -9,6 +10,7 @@ import (
 	"quantmesh/logger"
 	"quantmesh/metrics"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -23,6 +25,7 @@ type OrderRequest struct {
 	PriceDecimals int    // 价格小数位数（用于格式化价格字符串）
 	ReduceOnly    bool   // 是否只减仓（平仓单）
 	PostOnly      bool   // 是否只做 Maker（Post Only）
+	StrictPost    bool   // PostOnly 多次被拒后不降级为普通单（高波动保护期间避免吃单）
 	ClientOrderID string // 自定义订单ID
 	StrategyName  string // 策略名称（可选，用于日志追踪）
 	StrategyType  string // 策略类型（可选，如 "grid", "dca", "martingale"）
@@ -50,6 +53,26 @@ type ExchangeOrderExecutor struct {
 	// 时间配置
 	rateLimitRetryDelay time.Duration
 	orderRetryDelay     time.Duration
+
+	// OCO 关联订单（见 oco.go）
+	ocoMu    sync.Mutex
+	ocoLinks map[string]*OCOLink
+	ocoStore OCOStore
+
+	// 只减仓统一检查（见 reduce_only.go，未启用时为 nil）
+	reduceOnly *reduceOnlyGuard
+
+	// 拒单记录（见 rejection.go）
+	rejections RejectionRecorder
+
+	// 按策略的交易额度限制（见 budget.go，未启用时为 nil）
+	budget *turnoverBudget
+
+	// 交易对价格带（见 price_band.go，未启用时为 nil）
+	priceBand *priceBand
+
+	// 下单前保证金检查（见 margin.go，未启用时为 nil）
+	margin *marginGuard
 }
 
 // NewExchangeOrderExecutor 创建基于交易所接口的订单执行器
@@ -78,7 +101,9 @@ func isPostOnlyError(err error) bool {
 		strings.Contains(errStr, "ORDER_POC_IMMEDIATE")
 }
 
-// isReduceOnlyError 检查是否为ReduceOnly错误（无持仓时尝试减仓）
+// isReduceOnlyError 检查是否为交易所返回的ReduceOnly错误（无持仓时尝试减仓）
+// 本地只减仓检查的 ErrReduceOnlyExceedsPosition 不算：其依据的持仓查询有缓存，可能落后于成交推送，
+// 只有交易所的拒单才能证明确实没有持仓（调用方会据此清空槽位）
 func isReduceOnlyError(err error) bool {
 	if err == nil {
 		return false
@@ -91,8 +116,31 @@ func isReduceOnlyError(err error) bool {
 		(strings.Contains(errStr, "reduce only") && !strings.Contains(errStr, "-4164"))
 }
 
-// PlaceOrder 下单（带重试）
+// PlaceOrder 下单（带重试），最终失败时记录拒单
+// 超出价格带、交易额度用尽或超过持仓的只减仓单在本地拦截，不记录为拒单
 func (oe *ExchangeOrderExecutor) PlaceOrder(req *OrderRequest) (*Order, error) {
+	if err := oe.checkPriceBand(req); err != nil {
+		return nil, err
+	}
+	if err := oe.checkTurnoverBudget(req); err != nil {
+		return nil, err
+	}
+	// 只减仓统一检查：超过持仓的只减仓单直接拦截，不占用锁和限流额度
+	if err := oe.applyReduceOnlyGuard(req); err != nil {
+		logger.Warn("⚠️ [%s] %v", oe.exchange.GetName(), err)
+		return nil, err
+	}
+	order, err := oe.placeOrder(req)
+	if err != nil {
+		oe.recordRejection(req, err)
+	} else if order != nil {
+		oe.recordTurnover(req)
+	}
+	return order, err
+}
+
+// placeOrder 下单（带重试）
+func (oe *ExchangeOrderExecutor) placeOrder(req *OrderRequest) (*Order, error) {
 	startTime := time.Now()
 	pm := metrics.GetPrometheusMetrics()
 	exchangeName := oe.exchange.GetName()
@@ -148,6 +196,11 @@ func (oe *ExchangeOrderExecutor) PlaceOrder(req *OrderRequest) (*Order, error) {
 			StrategyType:  req.StrategyType,          // 传递策略类型
 		}
 
+		// 严格 PostOnly 不降级，直接放弃本次下单
+		if postOnlyFailCount >= 3 && req.PostOnly && req.StrictPost {
+			return nil, fmt.Errorf("PostOnly 连续被拒（严格模式不降级）: %w", lastErr)
+		}
+
 		// 🔥 如果PostOnly已失败3次，降级为普通限价单
 		if postOnlyFailCount >= 3 && req.PostOnly && !degraded {
 			degraded = true
@@ -186,6 +239,8 @@ func (oe *ExchangeOrderExecutor) PlaceOrder(req *OrderRequest) (*Order, error) {
 			return order, nil
 		}
 
+		// 统一错误分类（交易所实例未经工厂包装时也能识别）
+		err = exchange.ClassifyError(exchangeName, err)
 		lastErr = err
 
 		// 判断错误类型
@@ -194,7 +249,7 @@ func (oe *ExchangeOrderExecutor) PlaceOrder(req *OrderRequest) (*Order, error) {
 			// 持仓模式不匹配：双向持仓 vs 单向持仓
 			logger.Fatalf("❌ 下单失败，请在交易所将双向持仓改为单向持仓。错误码: -4061")
 			return nil, fmt.Errorf("持仓模式不匹配: %w", err)
-		} else if strings.Contains(errStr, "-1003") || strings.Contains(errStr, "rate limit") {
+		} else if errors.Is(err, exchange.ErrRateLimited) {
 			// 速率限制，等待后重试
 			pm.RecordAPIRateLimitHit(exchangeName)
 			logger.Warn("⚠️ 触发速率限制，等待后重试...")
@@ -217,9 +272,13 @@ func (oe *ExchangeOrderExecutor) PlaceOrder(req *OrderRequest) (*Order, error) {
 		} else if strings.Contains(errStr, "-4061") {
 			// 持仓模式不匹配（已在前面处理，这里保留以防万一）
 			return nil, err
-		} else if strings.Contains(errStr, "-2019") || strings.Contains(errStr, "保证金不足") || strings.Contains(errStr, "insufficient") {
+		} else if errors.Is(err, exchange.ErrInsufficientMargin) {
 			// 保证金不足，不重试
 			return nil, err
+		} else if errors.Is(err, exchange.ErrInvalidFilter) || errors.Is(err, exchange.ErrRestrictedRegion) {
+			// 不符合交易规则（精度/最小名义价值等）或地区受限，重试也不会成功
+			pm.RecordOrderFailure(exchangeName, req.Symbol, req.Side, "rejected")
+			return nil, err
 		} else if strings.Contains(errStr, "-1021") {
 			// 时间戳不同步，不重试
 			return nil, err
@@ -256,6 +315,7 @@ func (oe *ExchangeOrderExecutor) BatchPlaceOrders(orders []*OrderRequest) ([]*Or
 }
 
 // BatchPlaceOrdersWithDetails 批量下单（返回详细结果）
+// 启用保证金检查时先按可用保证金裁剪批次
 func (oe *ExchangeOrderExecutor) BatchPlaceOrdersWithDetails(orders []*OrderRequest) *BatchPlaceOrdersResult {
 	result := &BatchPlaceOrdersResult{
 		PlacedOrders:     make([]*Order, 0, len(orders)),
@@ -263,17 +323,28 @@ func (oe *ExchangeOrderExecutor) BatchPlaceOrdersWithDetails(orders []*OrderRequ
 		ReduceOnlyErrors: make(map[string]bool),
 	}
 
+	// 保证金不足时只提交足够的部分，被裁掉的订单与下单失败一样不出现在结果中
+	orders = oe.applyMarginCheck(orders)
+
 	for _, orderReq := range orders {
 		order, err := oe.PlaceOrder(orderReq)
+		if errors.Is(err, ErrTurnoverBudgetExhausted) || errors.Is(err, ErrPriceOutOfBand) {
+			// 额度用尽只在状态变化时记录日志、价格带越界按间隔记录，避免每轮调整都刷屏
+			continue
+		}
+		if errors.Is(err, ErrReduceOnlyExceedsPosition) {
+			// 本地持仓缓存可能落后于成交，不清空槽位：跳过本次下单，槽位锁释放后下一轮重试
+			continue
+		}
 		if err != nil {
 			logger.Warn("⚠️ [%s] 下单失败 %.2f %s: %v",
 				oe.exchange.GetName(), orderReq.Price, orderReq.Side, err)
 
 			// 检查错误类型
-			errStr := err.Error()
-			if strings.Contains(errStr, "保证金不足") || strings.Contains(errStr, "-2019") || strings.Contains(errStr, "insufficient") {
+			if errors.Is(err, exchange.ErrInsufficientMargin) {
 				result.HasMarginError = true
 				logger.Error("❌ [保证金不足] 订单 %.2f %s 因保证金不足失败", orderReq.Price, orderReq.Side)
+				oe.recordMarginError()
 			} else if isReduceOnlyError(err) {
 				// 记录 ReduceOnly 错误
 				result.ReduceOnlyErrors[orderReq.ClientOrderID] = true
@@ -318,15 +389,14 @@ func (oe *ExchangeOrderExecutor) CancelOrder(orderID int64) error {
 		return fmt.Errorf("速率限制等待失败: %v", err)
 	}
 
-	err = oe.exchange.CancelOrder(context.Background(), oe.symbol, orderID)
+	err = exchange.ClassifyError(exchangeName, oe.exchange.CancelOrder(context.Background(), oe.symbol, orderID))
 	if err != nil {
 		// 如果是"Unknown order"错误，说明订单已经不存在（可能已成交或已取消），不算错误
-		errStr := err.Error()
-		if strings.Contains(errStr, "-2011") || strings.Contains(errStr, "Unknown order") || strings.Contains(errStr, "does not exist") {
+		if errors.Is(err, exchange.ErrOrderNotFound) {
 			logger.Info("ℹ️ [%s] 订单 %d 已不存在（可能已成交或已取消），跳过取消", oe.exchange.GetName(), orderID)
 			return nil
 		}
-		return fmt.Errorf("取消订单失败: %v", err)
+		return fmt.Errorf("取消订单失败: %w", err)
 	}
 
 	logger.Info("✅ [%s] 取消订单成功: %d", oe.exchange.GetName(), orderID)
@@ -0,0 +1,344 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+)
+
+// marginTrimLogInterval 批次裁剪日志的最短间隔（期间的裁剪只计数），保证金持续不足时避免每轮调整都刷屏
+const marginTrimLogInterval = time.Minute
+
+// MarginBatchCheck 最近一次批次检查的结果
+type MarginBatchCheck struct {
+	Time      time.Time `json:"time"`
+	Requested int       `json:"requested"` // 批次中的开仓订单数（只减仓订单不占用保证金，不参与检查）
+	Kept      int       `json:"kept"`
+	Trimmed   int       `json:"trimmed"`
+	Required  float64   `json:"required"` // 批次全部开仓订单所需保证金
+	Budget    float64   `json:"budget"`   // 可用于本批次的保证金（可用保证金扣除安全余量和已预留部分）
+	Verified  bool      `json:"verified"` // 检查前是否向交易所重新核实了可用保证金
+}
+
+// MarginModel 本地维护的保证金模型
+// 可用保证金和持仓以最近一次交易所查询为准，之后提交的订单按估算的保证金累加到 Reserved，下次核实时清零
+type MarginModel struct {
+	Exchange         string                    `json:"exchange"`
+	Symbol           string                    `json:"symbol"`
+	Leverage         int                       `json:"leverage"`
+	LeverageSource   string                    `json:"leverage_source"`   // config / exchange / default
+	AvailableBalance float64                   `json:"available_balance"` // 最近一次从交易所核实的可用保证金
+	Reserved         float64                   `json:"reserved"`          // 核实之后已提交订单预计占用的保证金
+	SafetyBuffer     float64                   `json:"safety_buffer"`
+	PositionSize     float64                   `json:"position_size"` // 正数为多仓，负数为空仓
+	MarkPrice        float64                   `json:"mark_price"`
+	Tiers            []config.MarginTierConfig `json:"tiers"`
+	VerifiedAt       time.Time                 `json:"verified_at"`
+	LastBatch        *MarginBatchCheck         `json:"last_batch,omitempty"`
+	TrimmedOrders    int64                     `json:"trimmed_orders"`  // 累计被裁剪的订单数
+	TrimmedBatches   int64                     `json:"trimmed_batches"` // 累计发生裁剪的批次数
+	MarginErrors     int64                     `json:"margin_errors"`   // 检查通过后交易所仍返回保证金不足的次数
+}
+
+// Budget 可用于新订单的保证金
+func (m *MarginModel) Budget() float64 {
+	return math.Max(m.AvailableBalance*(1-m.SafetyBuffer)-m.Reserved, 0)
+}
+
+// MarginRate 持仓名义价值达到 notional 时新增名义价值所需的保证金率：
+// 取所在档位的初始保证金率（1/杠杆，杠杆不超过档位上限）与维持保证金率的较大者
+func (m *MarginModel) MarginRate(notional float64) float64 {
+	if len(m.Tiers) == 0 {
+		return 1 / float64(max(m.Leverage, 1))
+	}
+	tier := m.Tiers[len(m.Tiers)-1]
+	for _, t := range m.Tiers {
+		if t.NotionalCap == 0 || notional <= t.NotionalCap {
+			tier = t
+			break
+		}
+	}
+	leverage := max(m.Leverage, 1)
+	if tier.MaxLeverage > 0 && tier.MaxLeverage < leverage {
+		leverage = tier.MaxLeverage
+	}
+	return math.Max(1/float64(leverage), tier.MaintenanceRate)
+}
+
+// marginGuard 下单前保证金检查
+type marginGuard struct {
+	cfg config.MarginCheckConfig
+	ttl time.Duration
+
+	mu         sync.Mutex
+	model      MarginModel
+	stale      bool // 成交或保证金不足错误后需要重新核实
+	lastLog    time.Time
+	suppressed int
+}
+
+// EnableMarginCheck 启用下单前保证金检查
+// 每批下单前按杠杆和维持保证金档位估算开仓订单所需保证金，超过可用保证金时先向交易所核实，
+// 仍不足则按离市价由远到近裁掉订单，只提交保证金足够的部分，避免整批订单连续被 -2019 拒绝
+func (oe *ExchangeOrderExecutor) EnableMarginCheck(cfg config.MarginCheckConfig) {
+	refresh := time.Duration(cfg.RefreshSeconds) * time.Second
+	if refresh <= 0 {
+		refresh = 30 * time.Second
+	}
+	oe.margin = &marginGuard{
+		cfg: cfg,
+		ttl: refresh,
+		model: MarginModel{
+			Exchange:     oe.exchange.GetName(),
+			Symbol:       oe.symbol,
+			Leverage:     cfg.Leverage,
+			SafetyBuffer: cfg.SafetyBuffer,
+			Tiers:        cfg.Tiers,
+		},
+		stale: true,
+	}
+}
+
+// MarginModel 当前的保证金模型，未启用时 ok 为 false
+func (oe *ExchangeOrderExecutor) MarginModel() (MarginModel, bool) {
+	if oe.margin == nil {
+		return MarginModel{}, false
+	}
+	oe.margin.mu.Lock()
+	defer oe.margin.mu.Unlock()
+	model := oe.margin.model
+	model.Tiers = append([]config.MarginTierConfig(nil), model.Tiers...)
+	if model.LastBatch != nil {
+		batch := *model.LastBatch
+		model.LastBatch = &batch
+	}
+	return model, true
+}
+
+// invalidateMarginModel 成交后可用保证金和持仓变化，下次下单前重新核实
+func (oe *ExchangeOrderExecutor) invalidateMarginModel() {
+	if oe.margin == nil {
+		return
+	}
+	oe.margin.mu.Lock()
+	oe.margin.stale = true
+	oe.margin.mu.Unlock()
+}
+
+// recordMarginError 检查通过后交易所仍返回保证金不足（估算偏低或其他交易对占用了保证金），下次下单前重新核实
+func (oe *ExchangeOrderExecutor) recordMarginError() {
+	if oe.margin == nil {
+		return
+	}
+	oe.margin.mu.Lock()
+	oe.margin.stale = true
+	oe.margin.model.MarginErrors++
+	oe.margin.mu.Unlock()
+}
+
+// applyMarginCheck 按可用保证金裁剪批次，返回需要提交的订单（保持原有顺序）
+// 查询交易所失败时不做检查，由交易所的保证金不足错误兜底
+func (oe *ExchangeOrderExecutor) applyMarginCheck(orders []*OrderRequest) []*OrderRequest {
+	g := oe.margin
+	if g == nil || !hasOpeningOrder(orders) {
+		return orders
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	verified := false
+	if g.stale || time.Since(g.model.VerifiedAt) >= g.ttl {
+		if err := oe.verifyMargin(g); err != nil {
+			logger.Debug("⚠️ [%s] %v，跳过保证金检查", oe.exchange.GetName(), err)
+			return orders
+		}
+		verified = true
+	}
+
+	plan := planMarginBatch(&g.model, orders, g.model.Budget())
+	if len(plan.trimmed) > 0 && !verified {
+		// 本地估算不足：期间的成交、撤单和其他交易对都会改变可用保证金，先向交易所核实再裁剪
+		if err := oe.verifyMargin(g); err == nil {
+			verified = true
+			plan = planMarginBatch(&g.model, orders, g.model.Budget())
+		}
+	}
+
+	g.model.Reserved += plan.used
+	g.model.LastBatch = &MarginBatchCheck{
+		Time:      time.Now(),
+		Requested: plan.requested,
+		Kept:      plan.requested - len(plan.trimmed),
+		Trimmed:   len(plan.trimmed),
+		Required:  plan.required,
+		Budget:    plan.budget,
+		Verified:  verified,
+	}
+	if len(plan.trimmed) == 0 {
+		return orders
+	}
+
+	g.model.TrimmedOrders += int64(len(plan.trimmed))
+	g.model.TrimmedBatches++
+	if now := time.Now(); now.Sub(g.lastLog) >= marginTrimLogInterval {
+		logger.Warn("💳 [%s:%s] 可用保证金不足：批次需要 %.2f，可用 %.2f（可用余额 %.2f，杠杆 %dx），裁掉 %d/%d 笔开仓订单（上次告警后另裁剪 %d 批）",
+			oe.exchange.GetName(), oe.symbol, plan.required, plan.budget, g.model.AvailableBalance, g.model.Leverage,
+			len(plan.trimmed), plan.requested, g.suppressed)
+		g.lastLog, g.suppressed = now, 0
+	} else {
+		g.suppressed++
+	}
+	return plan.kept
+}
+
+// verifyMargin 从交易所查询可用保证金、持仓和杠杆，清零本地预留（调用方持有 g.mu）
+func (oe *ExchangeOrderExecutor) verifyMargin(g *marginGuard) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	account, err := oe.exchange.GetAccount(ctx)
+	if err != nil {
+		return fmt.Errorf("查询账户保证金失败: %w", err)
+	}
+	positions, err := oe.exchange.GetPositions(ctx, oe.symbol)
+	if err != nil {
+		return fmt.Errorf("查询持仓失败: %w", err)
+	}
+
+	size, markPrice, leverage := 0.0, 0.0, 0
+	for _, pos := range positions {
+		if pos == nil || pos.Symbol != oe.symbol {
+			continue
+		}
+		size += pos.Size
+		if pos.MarkPrice > 0 {
+			markPrice = pos.MarkPrice
+		}
+		if pos.Leverage > 0 {
+			leverage = pos.Leverage
+		}
+	}
+
+	m := &g.model
+	switch {
+	case g.cfg.Leverage > 0:
+		m.Leverage, m.LeverageSource = g.cfg.Leverage, "config"
+	case leverage > 0:
+		m.Leverage, m.LeverageSource = leverage, "exchange"
+	case account.AccountLeverage > 0:
+		m.Leverage, m.LeverageSource = account.AccountLeverage, "exchange"
+	default:
+		m.Leverage, m.LeverageSource = 1, "default"
+	}
+	m.AvailableBalance = account.AvailableBalance
+	m.Reserved = 0
+	m.PositionSize = size
+	m.MarkPrice = markPrice
+	m.VerifiedAt = time.Now()
+	g.stale = false
+	return nil
+}
+
+// marginPlan 批次裁剪结果
+type marginPlan struct {
+	kept      []*OrderRequest
+	trimmed   []*OrderRequest
+	requested int     // 开仓订单数
+	required  float64 // 全部开仓订单所需保证金
+	used      float64 // 保留的订单所需保证金
+	budget    float64
+}
+
+// planMarginBatch 按离参考价由近到远依次累加开仓订单所需保证金，超过 budget 的订单被裁掉
+// 只减仓订单和可由反向持仓抵消的数量不占用保证金；档位按该方向持仓加上已保留订单的名义价值确定
+func planMarginBatch(m *MarginModel, orders []*OrderRequest, budget float64) marginPlan {
+	plan := marginPlan{budget: budget}
+	var candidates []*OrderRequest
+	for _, req := range orders {
+		if !req.ReduceOnly && req.Quantity > 0 {
+			candidates = append(candidates, req)
+		}
+	}
+	plan.requested = len(candidates)
+
+	reference := marginReferencePrice(m, candidates)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return math.Abs(candidates[i].Price-reference) < math.Abs(candidates[j].Price-reference)
+	})
+
+	positionPrice := m.MarkPrice
+	if positionPrice <= 0 {
+		positionPrice = reference
+	}
+	// 反向持仓可抵消的数量（卖单对应多仓，买单对应空仓）与各方向的名义价值
+	closable := map[string]float64{"SELL": math.Max(m.PositionSize, 0), "BUY": math.Max(-m.PositionSize, 0)}
+	exposure := map[string]float64{
+		"BUY":  math.Max(m.PositionSize, 0) * positionPrice,
+		"SELL": math.Max(-m.PositionSize, 0) * positionPrice,
+	}
+
+	const epsilon = 1e-9
+	trimmed := make(map[*OrderRequest]bool)
+	for _, req := range candidates {
+		offset := math.Min(req.Quantity, closable[req.Side])
+		notional := (req.Quantity - offset) * req.Price
+		need := notional * m.MarginRate(exposure[req.Side]+notional)
+		plan.required += need
+		if plan.used+need > budget+epsilon {
+			trimmed[req] = true
+			plan.trimmed = append(plan.trimmed, req)
+			continue
+		}
+		plan.used += need
+		closable[req.Side] -= offset
+		exposure[req.Side] += notional
+	}
+
+	plan.kept = make([]*OrderRequest, 0, len(orders)-len(trimmed))
+	for _, req := range orders {
+		if !trimmed[req] {
+			plan.kept = append(plan.kept, req)
+		}
+	}
+	return plan
+}
+
+// marginReferencePrice 裁剪时判断远近的参考价：优先使用标记价格，否则取批次最高买价与最低卖价的中点
+func marginReferencePrice(m *MarginModel, orders []*OrderRequest) float64 {
+	if m.MarkPrice > 0 {
+		return m.MarkPrice
+	}
+	bestBid, bestAsk := 0.0, 0.0
+	for _, req := range orders {
+		switch req.Side {
+		case "BUY":
+			bestBid = math.Max(bestBid, req.Price)
+		case "SELL":
+			if bestAsk == 0 || req.Price < bestAsk {
+				bestAsk = req.Price
+			}
+		}
+	}
+	switch {
+	case bestBid > 0 && bestAsk > 0:
+		return (bestBid + bestAsk) / 2
+	case bestBid > 0:
+		return bestBid
+	}
+	return bestAsk
+}
+
+// hasOpeningOrder 批次中是否有需要占用保证金的订单
+func hasOpeningOrder(orders []*OrderRequest) bool {
+	for _, req := range orders {
+		if !req.ReduceOnly {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package order
+
+import (
+	"math"
+	"testing"
+
+	"quantmesh/config"
+)
+
+func TestMarginRate(t *testing.T) {
+	m := &MarginModel{
+		Leverage: 20,
+		Tiers: []config.MarginTierConfig{
+			{NotionalCap: 50000, MaxLeverage: 50, MaintenanceRate: 0.004},
+			{NotionalCap: 250000, MaxLeverage: 10, MaintenanceRate: 0.005},
+			{MaxLeverage: 2, MaintenanceRate: 0.6},
+		},
+	}
+	for notional, want := range map[float64]float64{
+		10000:  0.05, // 20 倍杠杆
+		100000: 0.1,  // 档位最大杠杆 10 倍
+		300000: 0.6,  // 维持保证金率高于 1/杠杆
+	} {
+		if got := m.MarginRate(notional); math.Abs(got-want) > 1e-12 {
+			t.Errorf("MarginRate(%v) = %v, want %v", notional, got, want)
+		}
+	}
+}
+
+func TestPlanMarginBatch(t *testing.T) {
+	m := &MarginModel{
+		Leverage:         10,
+		AvailableBalance: 100,
+		SafetyBuffer:     0.1,
+		PositionSize:     0.01, // 多仓 0.01，卖单可抵消
+		MarkPrice:        1000,
+		Tiers:            []config.MarginTierConfig{{MaintenanceRate: 0.005}},
+	}
+	orders := []*OrderRequest{
+		{Side: "BUY", Price: 900, Quantity: 0.5, ClientOrderID: "b900"}, // 45
+		{Side: "BUY", Price: 990, Quantity: 0.5, ClientOrderID: "b990"}, // 49.5
+		{Side: "SELL", Price: 1010, Quantity: 0.01, ClientOrderID: "s1010"},
+		{Side: "SELL", Price: 1020, Quantity: 0.01, ClientOrderID: "s1020", ReduceOnly: true},
+		{Side: "BUY", Price: 950, Quantity: 0.2, ClientOrderID: "b950"}, // 19
+	}
+
+	plan := planMarginBatch(m, orders, m.Budget())
+	if plan.budget != 90 || plan.requested != 4 {
+		t.Fatalf("budget=%v requested=%d", plan.budget, plan.requested)
+	}
+	// 离标记价最近的 b990 和 b950 保留，最远的 b900 被裁掉；s1010 由多仓抵消，不占用保证金
+	if len(plan.trimmed) != 1 || plan.trimmed[0].ClientOrderID != "b900" {
+		t.Fatalf("trimmed = %v", plan.trimmed)
+	}
+	if math.Abs(plan.used-68.5) > 1e-9 || math.Abs(plan.required-113.5) > 1e-9 {
+		t.Errorf("used=%v required=%v", plan.used, plan.required)
+	}
+	var kept []string
+	for _, req := range plan.kept {
+		kept = append(kept, req.ClientOrderID)
+	}
+	if len(kept) != 4 || kept[0] != "b990" || kept[3] != "b950" {
+		t.Errorf("kept = %v, want original order without b900", kept)
+	}
+
+	// 已预留的保证金从可用额度中扣除
+	m.Reserved = 80
+	if plan := planMarginBatch(m, orders, m.Budget()); len(plan.kept) != 2 {
+		t.Errorf("with reserved margin kept %d orders, want only the sell orders", len(plan.kept))
+	}
+}
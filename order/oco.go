@@ -0,0 +1,471 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"quantmesh/exchange"
+	"quantmesh/logger"
+)
+
+// OCO 关联订单状态
+const (
+	OCOStatusActive     = "active"      // 止盈单挂单中，止损未触发
+	OCOStatusTakeProfit = "take_profit" // 止盈单成交
+	OCOStatusStopped    = "stopped"     // 止损触发并平仓
+	OCOStatusCanceled   = "canceled"    // 手动撤销或止盈单被外部撤销
+)
+
+// OCORequest OCO 下单请求：止盈限价单与止损单数量相同，均为只减仓
+type OCORequest struct {
+	Side            string // 平仓方向（多仓止盈止损为 SELL）
+	Quantity        float64
+	TakeProfitPrice float64
+	StopPrice       float64
+	PriceDecimals   int
+}
+
+// OCOLink 止盈/止损关联订单
+// 交易所支持原生 OCO 时两张子订单都挂在交易所；否则止盈单为只减仓限价单，止损由执行器按价格在本地触发市价平仓
+type OCOLink struct {
+	LinkID             string    `json:"link_id"`
+	Exchange           string    `json:"exchange"`
+	Symbol             string    `json:"symbol"`
+	Side               string    `json:"side"`
+	Quantity           float64   `json:"quantity"`
+	TakeProfitPrice    float64   `json:"take_profit_price"`
+	StopPrice          float64   `json:"stop_price"`
+	TakeProfitOrderID  int64     `json:"take_profit_order_id"` // 模拟模式下止盈单已撤销（止损触发中）时为 0
+	TakeProfitClientID string    `json:"take_profit_client_id"`
+	TakeProfitFilled   float64   `json:"take_profit_filled"` // 止盈单已成交数量（不持久化，重启后从交易所查询）
+	StopOrderID        int64     `json:"stop_order_id"`      // 原生 OCO 的止损单，模拟模式下为触发后的市价平仓单
+	Native             bool      `json:"native"`
+	Status             string    `json:"status"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+
+	triggering bool // 止损触发处理中，避免重复平仓
+}
+
+// OCOStore OCO 关联订单持久化接口
+type OCOStore interface {
+	SaveOCOLink(link *OCOLink) error
+	LoadActiveOCOLinks(exchange, symbol string) ([]*OCOLink, error)
+}
+
+// SetOCOStore 设置 OCO 关联订单存储（未设置时仅保存在内存中，重启后无法清理遗留的保护单）
+func (oe *ExchangeOrderExecutor) SetOCOStore(store OCOStore) {
+	oe.ocoMu.Lock()
+	defer oe.ocoMu.Unlock()
+	oe.ocoStore = store
+}
+
+// PlaceOCO 挂出止盈/止损关联订单
+// 优先使用交易所原生 OCO，不支持时挂只减仓止盈限价单并在本地监控止损价
+func (oe *ExchangeOrderExecutor) PlaceOCO(req *OCORequest) (*OCOLink, error) {
+	if req.Quantity <= 0 || req.TakeProfitPrice <= 0 || req.StopPrice <= 0 {
+		return nil, fmt.Errorf("数量、止盈价和止损价必须大于0")
+	}
+	switch req.Side {
+	case string(exchange.SideSell):
+		if req.TakeProfitPrice <= req.StopPrice {
+			return nil, fmt.Errorf("卖出平仓的止盈价必须高于止损价")
+		}
+	case string(exchange.SideBuy):
+		if req.TakeProfitPrice >= req.StopPrice {
+			return nil, fmt.Errorf("买入平仓的止盈价必须低于止损价")
+		}
+	default:
+		return nil, fmt.Errorf("无效的方向: %s", req.Side)
+	}
+
+	now := time.Now()
+	link := &OCOLink{
+		LinkID:          fmt.Sprintf("oco%d", now.UnixNano()),
+		Exchange:        oe.exchange.GetName(),
+		Symbol:          oe.symbol,
+		Side:            req.Side,
+		Quantity:        req.Quantity,
+		TakeProfitPrice: req.TakeProfitPrice,
+		StopPrice:       req.StopPrice,
+		Status:          OCOStatusActive,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	native, err := oe.placeNativeOCO(link, req.PriceDecimals)
+	if err != nil {
+		return nil, err
+	}
+	if !native {
+		// 客户端ID不含下划线，避免被网格仓位管理器误认为网格订单
+		tp, err := oe.PlaceOrder(&OrderRequest{
+			Symbol:        oe.symbol,
+			Side:          req.Side,
+			Price:         req.TakeProfitPrice,
+			Quantity:      req.Quantity,
+			PriceDecimals: req.PriceDecimals,
+			ReduceOnly:    true,
+			ClientOrderID: link.LinkID + "T",
+			StrategyName:  "oco",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("挂止盈单失败: %w", err)
+		}
+		if tp == nil {
+			return nil, fmt.Errorf("挂止盈单失败: 价格位已被其他实例锁定")
+		}
+		link.TakeProfitOrderID = tp.OrderID
+		link.TakeProfitClientID = tp.ClientOrderID
+	}
+
+	oe.ocoMu.Lock()
+	if oe.ocoLinks == nil {
+		oe.ocoLinks = make(map[string]*OCOLink)
+	}
+	oe.ocoLinks[link.LinkID] = link
+	oe.saveOCOLinkLocked(link)
+	snapshot := *link
+	oe.ocoMu.Unlock()
+
+	mode := "本地模拟止损"
+	if native {
+		mode = "交易所原生"
+	}
+	logger.Info("🛡️ [%s] OCO 已挂出(%s): %s %s 数量: %.4f 止盈: %.*f 止损: %.*f",
+		link.Exchange, mode, link.Symbol, link.Side, link.Quantity,
+		req.PriceDecimals, link.TakeProfitPrice, req.PriceDecimals, link.StopPrice)
+	return &snapshot, nil
+}
+
+// placeNativeOCO 尝试交易所原生 OCO；交易所不支持时返回 false
+func (oe *ExchangeOrderExecutor) placeNativeOCO(link *OCOLink, priceDecimals int) (bool, error) {
+	placer, ok := exchange.As[exchange.OCOPlacer](oe.exchange)
+	if !ok {
+		return false, nil
+	}
+	if err := oe.rateLimiter.Wait(context.Background()); err != nil {
+		return false, fmt.Errorf("速率限制等待失败: %v", err)
+	}
+	result, err := placer.PlaceOCO(context.Background(), &exchange.OCORequest{
+		Symbol:          oe.symbol,
+		Side:            exchange.Side(link.Side),
+		Quantity:        link.Quantity,
+		TakeProfitPrice: link.TakeProfitPrice,
+		StopPrice:       link.StopPrice,
+		PriceDecimals:   priceDecimals,
+		ClientOrderID:   link.LinkID,
+	})
+	if errors.Is(err, exchange.ErrNotImplemented) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("原生 OCO 下单失败: %w", err)
+	}
+	link.Native = true
+	if result.TakeProfit != nil {
+		link.TakeProfitOrderID = result.TakeProfit.OrderID
+		link.TakeProfitClientID = result.TakeProfit.ClientOrderID
+	}
+	if result.StopLoss != nil {
+		link.StopOrderID = result.StopLoss.OrderID
+	}
+	return true, nil
+}
+
+// HandleOCOOrderUpdate 处理订单更新：一张子订单成交后撤销另一张；返回该订单是否属于 OCO
+func (oe *ExchangeOrderExecutor) HandleOCOOrderUpdate(orderID int64, status string, executedQty float64) bool {
+	oe.ocoMu.Lock()
+	var link *OCOLink
+	isTakeProfit := false
+	for _, l := range oe.ocoLinks {
+		if l.Status != OCOStatusActive {
+			continue
+		}
+		if l.TakeProfitOrderID != 0 && l.TakeProfitOrderID == orderID {
+			link, isTakeProfit = l, true
+			break
+		}
+		if l.StopOrderID != 0 && l.StopOrderID == orderID {
+			link = l
+			break
+		}
+	}
+	if link == nil {
+		oe.ocoMu.Unlock()
+		return false
+	}
+
+	var cancelID int64
+	switch exchange.OrderStatus(status) {
+	case exchange.OrderStatusPartiallyFilled:
+		if isTakeProfit {
+			link.TakeProfitFilled = executedQty
+		}
+	case exchange.OrderStatusFilled:
+		if isTakeProfit {
+			link.TakeProfitFilled = executedQty
+			link.Status = OCOStatusTakeProfit
+			cancelID = link.StopOrderID
+		} else {
+			link.Status = OCOStatusStopped
+			cancelID = link.TakeProfitOrderID
+		}
+		if !link.Native {
+			// 模拟模式下止损没有挂单
+			cancelID = 0
+		}
+	case exchange.OrderStatusCanceled, exchange.OrderStatusExpired, exchange.OrderStatusRejected:
+		if link.triggering {
+			// 止损触发时主动撤销的止盈单
+			break
+		}
+		link.Status = OCOStatusCanceled
+		if link.Native {
+			if isTakeProfit {
+				cancelID = link.StopOrderID
+			} else {
+				cancelID = link.TakeProfitOrderID
+			}
+		}
+	}
+	link.UpdatedAt = time.Now()
+	oe.saveOCOLinkLocked(link)
+	linkID, linkStatus := link.LinkID, link.Status
+	oe.ocoMu.Unlock()
+
+	if linkStatus != OCOStatusActive {
+		logger.Info("🛡️ [%s] OCO %s 结束: %s (订单 %d %s)", oe.exchange.GetName(), linkID, linkStatus, orderID, status)
+	}
+	if cancelID != 0 {
+		if err := oe.CancelOrder(cancelID); err != nil {
+			logger.Warn("⚠️ [%s] OCO %s 撤销关联订单 %d 失败: %v", oe.exchange.GetName(), linkID, cancelID, err)
+		}
+	}
+	return true
+}
+
+// CheckOCOTriggers 按最新价格检查本地模拟的止损（卖出平仓价格跌破止损价、买入平仓价格涨破止损价时触发）
+// 触发后撤销止盈单，并以只减仓市价单平掉剩余数量
+func (oe *ExchangeOrderExecutor) CheckOCOTriggers(price float64) {
+	if price <= 0 {
+		return
+	}
+	oe.ocoMu.Lock()
+	var triggered []*OCOLink
+	for _, l := range oe.ocoLinks {
+		if l.Status != OCOStatusActive || l.Native || l.triggering {
+			continue
+		}
+		if (l.Side == string(exchange.SideSell) && price <= l.StopPrice) ||
+			(l.Side == string(exchange.SideBuy) && price >= l.StopPrice) {
+			l.triggering = true
+			triggered = append(triggered, l)
+		}
+	}
+	oe.ocoMu.Unlock()
+
+	for _, l := range triggered {
+		oe.triggerStop(l, price)
+	}
+}
+
+// triggerStop 执行本地止损；失败时保留 active 状态，下次价格更新时重试
+func (oe *ExchangeOrderExecutor) triggerStop(link *OCOLink, price float64) {
+	exchangeName := oe.exchange.GetName()
+	logger.Warn("🛑 [%s] OCO %s 止损触发: 价格 %.4f 触及止损价 %.4f", exchangeName, link.LinkID, price, link.StopPrice)
+
+	defer func() {
+		oe.ocoMu.Lock()
+		link.triggering = false
+		oe.ocoMu.Unlock()
+	}()
+
+	oe.ocoMu.Lock()
+	tpID := link.TakeProfitOrderID
+	oe.ocoMu.Unlock()
+
+	filled := 0.0
+	if tpID != 0 {
+		if err := oe.CancelOrder(tpID); err != nil {
+			logger.Error("❌ [%s] OCO %s 撤销止盈单失败，稍后重试: %v", exchangeName, link.LinkID, err)
+			return
+		}
+		// 撤单前可能已有部分成交，以交易所状态为准
+		status, executedQty, err := oe.CheckOrderStatus(tpID)
+		if err != nil {
+			logger.Warn("⚠️ [%s] OCO %s 查询止盈单失败，按已知成交量平仓: %v", exchangeName, link.LinkID, err)
+			oe.ocoMu.Lock()
+			executedQty = link.TakeProfitFilled
+			oe.ocoMu.Unlock()
+		}
+		filled = executedQty
+
+		oe.ocoMu.Lock()
+		link.TakeProfitOrderID = 0
+		link.TakeProfitFilled = filled
+		if exchange.OrderStatus(status) == exchange.OrderStatusFilled || filled >= link.Quantity {
+			link.Status = OCOStatusTakeProfit
+		}
+		link.UpdatedAt = time.Now()
+		oe.saveOCOLinkLocked(link)
+		done := link.Status != OCOStatusActive
+		oe.ocoMu.Unlock()
+		if done {
+			logger.Info("🛡️ [%s] OCO %s 止盈单已全部成交，无需止损", exchangeName, link.LinkID)
+			return
+		}
+	} else {
+		oe.ocoMu.Lock()
+		filled = link.TakeProfitFilled
+		oe.ocoMu.Unlock()
+	}
+
+	remaining := link.Quantity - filled
+	if err := oe.rateLimiter.Wait(context.Background()); err != nil {
+		logger.Error("❌ [%s] OCO %s 速率限制等待失败: %v", exchangeName, link.LinkID, err)
+		return
+	}
+	order, err := oe.exchange.PlaceOrder(context.Background(), &exchange.OrderRequest{
+		Symbol:        oe.symbol,
+		Side:          exchange.Side(link.Side),
+		Type:          exchange.OrderTypeMarket,
+		Quantity:      remaining,
+		ReduceOnly:    true,
+		ClientOrderID: link.LinkID + "S",
+		StrategyName:  "oco",
+	})
+	if err != nil && !isReduceOnlyError(err) {
+		logger.Error("❌ [%s] OCO %s 止损平仓失败，稍后重试: %v", exchangeName, link.LinkID, err)
+		return
+	}
+
+	oe.ocoMu.Lock()
+	link.Status = OCOStatusStopped
+	if order != nil {
+		link.StopOrderID = order.OrderID
+	}
+	link.UpdatedAt = time.Now()
+	oe.saveOCOLinkLocked(link)
+	oe.ocoMu.Unlock()
+
+	if err != nil {
+		// 只减仓被拒说明仓位已不存在
+		logger.Warn("⚠️ [%s] OCO %s 止损平仓被拒（无持仓），结束关联", exchangeName, link.LinkID)
+		return
+	}
+	logger.Info("✅ [%s] OCO %s 止损平仓: %s 数量: %.4f", exchangeName, link.LinkID, link.Side, remaining)
+}
+
+// CancelOCO 撤销 OCO 关联订单（撤销交易所上的子订单，并停止本地止损监控）
+func (oe *ExchangeOrderExecutor) CancelOCO(linkID string) error {
+	oe.ocoMu.Lock()
+	link, ok := oe.ocoLinks[linkID]
+	if !ok || link.Status != OCOStatusActive {
+		oe.ocoMu.Unlock()
+		return fmt.Errorf("OCO %s 不存在或已结束", linkID)
+	}
+	if link.triggering {
+		oe.ocoMu.Unlock()
+		return fmt.Errorf("OCO %s 止损触发中，无法撤销", linkID)
+	}
+	link.Status = OCOStatusCanceled
+	link.UpdatedAt = time.Now()
+	oe.saveOCOLinkLocked(link)
+	ids := []int64{link.TakeProfitOrderID, link.StopOrderID}
+	native := link.Native
+	oe.ocoMu.Unlock()
+
+	for i, id := range ids {
+		if id == 0 || (i == 1 && !native) {
+			continue
+		}
+		if err := oe.CancelOrder(id); err != nil {
+			return fmt.Errorf("撤销订单 %d 失败: %w", id, err)
+		}
+	}
+	logger.Info("🛡️ [%s] OCO %s 已撤销", oe.exchange.GetName(), linkID)
+	return nil
+}
+
+// RestoreOCOLinks 启动时恢复未结束的 OCO 关联订单
+// 按交易所上子订单的实际状态处理停机期间的成交：一张已成交则撤销另一张，避免遗留无主的保护单
+func (oe *ExchangeOrderExecutor) RestoreOCOLinks() error {
+	oe.ocoMu.Lock()
+	store := oe.ocoStore
+	oe.ocoMu.Unlock()
+	if store == nil {
+		return nil
+	}
+
+	links, err := store.LoadActiveOCOLinks(oe.exchange.GetName(), oe.symbol)
+	if err != nil {
+		return fmt.Errorf("加载 OCO 关联订单失败: %w", err)
+	}
+
+	restored := 0
+	for _, link := range links {
+		oe.ocoMu.Lock()
+		if oe.ocoLinks == nil {
+			oe.ocoLinks = make(map[string]*OCOLink)
+		}
+		oe.ocoLinks[link.LinkID] = link
+		oe.ocoMu.Unlock()
+
+		if link.TakeProfitOrderID != 0 {
+			status, executedQty, err := oe.CheckOrderStatus(link.TakeProfitOrderID)
+			if err != nil {
+				logger.Warn("⚠️ [%s] OCO %s 查询止盈单失败，继续监控: %v", oe.exchange.GetName(), link.LinkID, err)
+			} else {
+				oe.HandleOCOOrderUpdate(link.TakeProfitOrderID, status, executedQty)
+			}
+		}
+		oe.ocoMu.Lock()
+		active := link.Status == OCOStatusActive
+		oe.ocoMu.Unlock()
+		if active && link.Native && link.StopOrderID != 0 {
+			if status, executedQty, err := oe.CheckOrderStatus(link.StopOrderID); err == nil {
+				oe.HandleOCOOrderUpdate(link.StopOrderID, status, executedQty)
+			}
+		}
+
+		oe.ocoMu.Lock()
+		if link.Status == OCOStatusActive {
+			restored++
+		}
+		oe.ocoMu.Unlock()
+	}
+	if len(links) > 0 {
+		logger.Info("🛡️ [%s] 恢复 OCO 关联订单: %d 个仍有效, %d 个已在停机期间结束",
+			oe.exchange.GetName(), restored, len(links)-restored)
+	}
+	return nil
+}
+
+// GetOCOLinks 获取当前进程跟踪的 OCO 关联订单（按创建时间倒序）
+func (oe *ExchangeOrderExecutor) GetOCOLinks() []OCOLink {
+	oe.ocoMu.Lock()
+	defer oe.ocoMu.Unlock()
+
+	result := make([]OCOLink, 0, len(oe.ocoLinks))
+	for _, l := range oe.ocoLinks {
+		result = append(result, *l)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result
+}
+
+// saveOCOLinkLocked 持久化关联订单（调用方需持有 ocoMu）
+func (oe *ExchangeOrderExecutor) saveOCOLinkLocked(link *OCOLink) {
+	if oe.ocoStore == nil {
+		return
+	}
+	if err := oe.ocoStore.SaveOCOLink(link); err != nil {
+		logger.Warn("⚠️ [%s] 保存 OCO %s 失败: %v", oe.exchange.GetName(), link.LinkID, err)
+	}
+}
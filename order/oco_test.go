@@ -0,0 +1,520 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"quantmesh/exchange"
+	"quantmesh/lock"
+	"quantmesh/testutil"
+)
+
+// nativeOCOVenue 支持原生 OCO 的假交易所：两张子订单都挂在交易所上
+type nativeOCOVenue struct {
+	*testutil.FakeVenue
+	requests []*exchange.OCORequest
+}
+
+func (v *nativeOCOVenue) PlaceOCO(ctx context.Context, req *exchange.OCORequest) (*exchange.OCOResult, error) {
+	v.requests = append(v.requests, req)
+	tpID := v.AddOpenOrder(exchange.Order{
+		ClientOrderID: req.ClientOrderID + "T", Symbol: req.Symbol, Side: req.Side,
+		Type: exchange.OrderTypeLimit, Price: req.TakeProfitPrice, Quantity: req.Quantity,
+	})
+	stopID := v.AddOpenOrder(exchange.Order{
+		ClientOrderID: req.ClientOrderID + "S", Symbol: req.Symbol, Side: req.Side,
+		Type: exchange.OrderTypeMarket, Price: req.StopPrice, Quantity: req.Quantity,
+	})
+	return &exchange.OCOResult{TakeProfit: v.Order(tpID), StopLoss: v.Order(stopID)}, nil
+}
+
+// cancelHookVenue 撤单时回调，用于模拟撤单推送在止损触发过程中到达
+type cancelHookVenue struct {
+	*testutil.FakeVenue
+	onCancel func(orderID int64)
+}
+
+func (v *cancelHookVenue) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	if err := v.FakeVenue.CancelOrder(ctx, symbol, orderID); err != nil {
+		return err
+	}
+	if v.onCancel != nil {
+		v.onCancel(orderID)
+	}
+	return nil
+}
+
+// memOCOStore 内存 OCO 存储，只返回仍为 active 的关联订单
+type memOCOStore struct {
+	mu    sync.Mutex
+	links map[string]OCOLink
+}
+
+func newMemOCOStore(links ...*OCOLink) *memOCOStore {
+	s := &memOCOStore{links: make(map[string]OCOLink)}
+	for _, l := range links {
+		s.links[l.LinkID] = *l
+	}
+	return s
+}
+
+func (s *memOCOStore) SaveOCOLink(link *OCOLink) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.links[link.LinkID] = *link
+	return nil
+}
+
+func (s *memOCOStore) LoadActiveOCOLinks(exchangeName, symbol string) ([]*OCOLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []*OCOLink
+	for _, l := range s.links {
+		if l.Status == OCOStatusActive && l.Exchange == exchangeName && l.Symbol == symbol {
+			cp := l
+			result = append(result, &cp)
+		}
+	}
+	return result, nil
+}
+
+func (s *memOCOStore) get(linkID string) OCOLink {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.links[linkID]
+}
+
+func newOCOVenue() *testutil.FakeVenue {
+	venue := testutil.NewFakeVenue("fake", 2, 3)
+	venue.SetPrice("BTCUSDT", 100)
+	venue.SetPosition("BTCUSDT", 1)
+	return venue
+}
+
+func newOCOExecutor(ex exchange.IExchange) (*ExchangeOrderExecutor, *memOCOStore) {
+	oe := NewExchangeOrderExecutor(ex, "BTCUSDT", 0, 0, lock.NewNopLock())
+	store := newMemOCOStore()
+	oe.SetOCOStore(store)
+	return oe, store
+}
+
+// 多仓止盈止损：止盈 110，止损 90
+func longOCO() *OCORequest {
+	return &OCORequest{Side: "SELL", Quantity: 1, TakeProfitPrice: 110, StopPrice: 90, PriceDecimals: 2}
+}
+
+func ocoLink(t *testing.T, oe *ExchangeOrderExecutor, linkID string) OCOLink {
+	t.Helper()
+	for _, l := range oe.GetOCOLinks() {
+		if l.LinkID == linkID {
+			return l
+		}
+	}
+	t.Fatalf("OCO %s not tracked", linkID)
+	return OCOLink{}
+}
+
+func TestPlaceOCOValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		req  OCORequest
+	}{
+		{"zero quantity", OCORequest{Side: "SELL", TakeProfitPrice: 110, StopPrice: 90}},
+		{"sell take profit below stop", OCORequest{Side: "SELL", Quantity: 1, TakeProfitPrice: 90, StopPrice: 110}},
+		{"buy take profit above stop", OCORequest{Side: "BUY", Quantity: 1, TakeProfitPrice: 110, StopPrice: 90}},
+		{"invalid side", OCORequest{Side: "HOLD", Quantity: 1, TakeProfitPrice: 110, StopPrice: 90}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			venue := newOCOVenue()
+			oe, _ := newOCOExecutor(venue)
+			if _, err := oe.PlaceOCO(&tt.req); err == nil {
+				t.Fatal("PlaceOCO accepted an invalid request")
+			}
+			if len(venue.PlacedOrders()) != 0 || len(oe.GetOCOLinks()) != 0 {
+				t.Fatal("invalid request placed orders")
+			}
+		})
+	}
+}
+
+func TestOCOSimulatedPlacement(t *testing.T) {
+	venue := newOCOVenue()
+	oe, store := newOCOExecutor(venue)
+
+	link, err := oe.PlaceOCO(longOCO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Native || link.StopOrderID != 0 || link.TakeProfitOrderID == 0 {
+		t.Fatalf("link = %+v, want simulated take-profit only", link)
+	}
+	placed := venue.PlacedOrders()
+	if len(placed) != 1 || !placed[0].ReduceOnly || placed[0].Price != 110 || placed[0].ClientOrderID != link.LinkID+"T" {
+		t.Fatalf("placed = %+v, want one reduce-only take-profit", placed)
+	}
+	if got := store.get(link.LinkID); got.Status != OCOStatusActive {
+		t.Fatalf("stored status = %q, want active", got.Status)
+	}
+
+	// 未触及止损价或价格无效时不触发
+	oe.CheckOCOTriggers(95)
+	oe.CheckOCOTriggers(0)
+	if len(venue.PlacedOrders()) != 1 || ocoLink(t, oe, link.LinkID).Status != OCOStatusActive {
+		t.Fatal("stop triggered above the stop price")
+	}
+}
+
+func TestOCONativeOrderUpdates(t *testing.T) {
+	tests := []struct {
+		name         string
+		updates      func(link *OCOLink) []exchange.Order
+		wantStatus   string
+		wantFilled   float64
+		wantCanceled string // 被撤销的子订单："tp" / "stop" / ""
+	}{
+		{
+			name: "take profit partial then filled cancels stop",
+			updates: func(l *OCOLink) []exchange.Order {
+				return []exchange.Order{
+					{OrderID: l.TakeProfitOrderID, Status: exchange.OrderStatusPartiallyFilled, ExecutedQty: 0.4},
+					{OrderID: l.TakeProfitOrderID, Status: exchange.OrderStatusFilled, ExecutedQty: 1},
+				}
+			},
+			wantStatus:   OCOStatusTakeProfit,
+			wantFilled:   1,
+			wantCanceled: "stop",
+		},
+		{
+			name: "partial fill keeps link active",
+			updates: func(l *OCOLink) []exchange.Order {
+				return []exchange.Order{{OrderID: l.TakeProfitOrderID, Status: exchange.OrderStatusPartiallyFilled, ExecutedQty: 0.4}}
+			},
+			wantStatus: OCOStatusActive,
+			wantFilled: 0.4,
+		},
+		{
+			name: "stop filled cancels take profit",
+			updates: func(l *OCOLink) []exchange.Order {
+				return []exchange.Order{{OrderID: l.StopOrderID, Status: exchange.OrderStatusFilled, ExecutedQty: 1}}
+			},
+			wantStatus:   OCOStatusStopped,
+			wantCanceled: "tp",
+		},
+		{
+			name: "external take profit cancel cancels stop",
+			updates: func(l *OCOLink) []exchange.Order {
+				return []exchange.Order{{OrderID: l.TakeProfitOrderID, Status: exchange.OrderStatusCanceled}}
+			},
+			wantStatus:   OCOStatusCanceled,
+			wantCanceled: "stop",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			venue := &nativeOCOVenue{FakeVenue: newOCOVenue()}
+			oe, store := newOCOExecutor(venue)
+			link, err := oe.PlaceOCO(longOCO())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !link.Native || len(venue.requests) != 1 || len(venue.PlacedOrders()) != 0 {
+				t.Fatalf("link = %+v, want native OCO without local orders", link)
+			}
+
+			for _, u := range tt.updates(link) {
+				if !oe.HandleOCOOrderUpdate(u.OrderID, string(u.Status), u.ExecutedQty) {
+					t.Fatalf("order %d not recognised as OCO", u.OrderID)
+				}
+			}
+
+			got := ocoLink(t, oe, link.LinkID)
+			if got.Status != tt.wantStatus || got.TakeProfitFilled != tt.wantFilled {
+				t.Fatalf("link = %+v, want status %s filled %v", got, tt.wantStatus, tt.wantFilled)
+			}
+			if store.get(link.LinkID).Status != tt.wantStatus {
+				t.Errorf("stored status = %q, want %q", store.get(link.LinkID).Status, tt.wantStatus)
+			}
+			tpCanceled := venue.Order(link.TakeProfitOrderID).Status == exchange.OrderStatusCanceled
+			stopCanceled := venue.Order(link.StopOrderID).Status == exchange.OrderStatusCanceled
+			if tpCanceled != (tt.wantCanceled == "tp") || stopCanceled != (tt.wantCanceled == "stop") {
+				t.Errorf("canceled tp=%v stop=%v, want %q", tpCanceled, stopCanceled, tt.wantCanceled)
+			}
+			if len(venue.PlacedOrders()) != 0 {
+				t.Errorf("native OCO placed local orders: %+v", venue.PlacedOrders())
+			}
+		})
+	}
+}
+
+func TestOCOHandleOrderUpdateIgnoresOtherOrders(t *testing.T) {
+	venue := newOCOVenue()
+	oe, _ := newOCOExecutor(venue)
+	link, err := oe.PlaceOCO(longOCO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oe.HandleOCOOrderUpdate(link.TakeProfitOrderID+100, "FILLED", 1) {
+		t.Fatal("unrelated order treated as OCO")
+	}
+	if !oe.HandleOCOOrderUpdate(link.TakeProfitOrderID, "FILLED", 1) {
+		t.Fatal("take-profit fill not handled")
+	}
+	// 已结束的关联不再匹配
+	if oe.HandleOCOOrderUpdate(link.TakeProfitOrderID, "FILLED", 1) {
+		t.Fatal("finished link still matched")
+	}
+	if got := ocoLink(t, oe, link.LinkID); got.Status != OCOStatusTakeProfit {
+		t.Fatalf("status = %q, want take_profit", got.Status)
+	}
+}
+
+func TestOCOSimulatedStopTrigger(t *testing.T) {
+	tests := []struct {
+		name       string
+		tpFilled   float64
+		wantStatus string
+		wantMarket float64 // 市价平仓数量，0 表示不下市价单
+	}{
+		{"untouched take profit", 0, OCOStatusStopped, 1},
+		{"take profit partially filled then cancelled", 0.4, OCOStatusStopped, 0.6},
+		{"take profit already filled", 1, OCOStatusTakeProfit, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			venue := newOCOVenue()
+			oe, store := newOCOExecutor(venue)
+			link, err := oe.PlaceOCO(longOCO())
+			if err != nil {
+				t.Fatal(err)
+			}
+			tpID := link.TakeProfitOrderID
+			if tt.tpFilled > 0 {
+				venue.Fill(tpID, tt.tpFilled)
+			}
+
+			oe.CheckOCOTriggers(89)
+
+			got := ocoLink(t, oe, link.LinkID)
+			if got.Status != tt.wantStatus || got.TakeProfitFilled != tt.tpFilled || got.TakeProfitOrderID != 0 {
+				t.Fatalf("link = %+v, want status %s filled %v", got, tt.wantStatus, tt.tpFilled)
+			}
+			if store.get(link.LinkID).Status != tt.wantStatus {
+				t.Errorf("stored status = %q, want %q", store.get(link.LinkID).Status, tt.wantStatus)
+			}
+			if tt.tpFilled < 1 && venue.Order(tpID).Status != exchange.OrderStatusCanceled {
+				t.Errorf("take-profit status = %s, want canceled", venue.Order(tpID).Status)
+			}
+
+			placed := venue.PlacedOrders()
+			if tt.wantMarket == 0 {
+				if len(placed) != 1 || got.StopOrderID != 0 {
+					t.Fatalf("placed = %+v, want no stop order", placed)
+				}
+				return
+			}
+			if len(placed) != 2 {
+				t.Fatalf("placed = %+v, want take-profit and stop", placed)
+			}
+			stop := placed[1]
+			if stop.Type != exchange.OrderTypeMarket || !stop.ReduceOnly || stop.ClientOrderID != link.LinkID+"S" {
+				t.Errorf("stop order = %+v, want reduce-only market", stop)
+			}
+			if diff := stop.Quantity - tt.wantMarket; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("stop quantity = %v, want %v", stop.Quantity, tt.wantMarket)
+			}
+			if got.StopOrderID == 0 || venue.Position("BTCUSDT") > 1e-9 {
+				t.Errorf("stop order %d, position %v, want flat", got.StopOrderID, venue.Position("BTCUSDT"))
+			}
+
+			// 触发后不再重复平仓
+			oe.CheckOCOTriggers(80)
+			if len(venue.PlacedOrders()) != 2 {
+				t.Errorf("stop triggered twice")
+			}
+		})
+	}
+}
+
+func TestOCOStopTriggerIgnoresCancelPushDuringTrigger(t *testing.T) {
+	venue := &cancelHookVenue{FakeVenue: newOCOVenue()}
+	oe, _ := newOCOExecutor(venue)
+	link, err := oe.PlaceOCO(longOCO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	venue.Fill(link.TakeProfitOrderID, 0.4)
+	// 撤单推送在止损平仓之前到达，不能把关联标记为已撤销
+	venue.onCancel = func(orderID int64) {
+		oe.HandleOCOOrderUpdate(orderID, string(exchange.OrderStatusCanceled), 0.4)
+	}
+
+	oe.CheckOCOTriggers(90)
+
+	got := ocoLink(t, oe, link.LinkID)
+	if got.Status != OCOStatusStopped || got.StopOrderID == 0 {
+		t.Fatalf("link = %+v, want stopped with a stop order", got)
+	}
+}
+
+func TestOCOStopTriggerRetries(t *testing.T) {
+	venue := newOCOVenue()
+	oe, _ := newOCOExecutor(venue)
+	link, err := oe.PlaceOCO(longOCO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 撤销止盈单失败：保持 active，下次价格更新重试
+	venue.FailOn("CancelOrder", errors.New("timeout"))
+	oe.CheckOCOTriggers(89)
+	if got := ocoLink(t, oe, link.LinkID); got.Status != OCOStatusActive || got.TakeProfitOrderID == 0 {
+		t.Fatalf("link = %+v, want active after cancel failure", got)
+	}
+	venue.FailOn("CancelOrder", nil)
+
+	// 市价平仓失败：止盈单已撤，保持 active 并重试剩余数量
+	venue.FailOn("PlaceOrder", errors.New("timeout"))
+	oe.CheckOCOTriggers(89)
+	if got := ocoLink(t, oe, link.LinkID); got.Status != OCOStatusActive || got.TakeProfitOrderID != 0 {
+		t.Fatalf("link = %+v, want active with take-profit cleared", got)
+	}
+	venue.FailOn("PlaceOrder", nil)
+
+	oe.CheckOCOTriggers(89)
+	got := ocoLink(t, oe, link.LinkID)
+	if got.Status != OCOStatusStopped || got.StopOrderID == 0 {
+		t.Fatalf("link = %+v, want stopped after retry", got)
+	}
+}
+
+func TestOCOStopTriggerReduceOnlyRejected(t *testing.T) {
+	venue := newOCOVenue()
+	oe, _ := newOCOExecutor(venue)
+	link, err := oe.PlaceOCO(longOCO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 仓位已不存在时交易所拒绝只减仓单，结束关联
+	venue.FailOn("PlaceOrder", errors.New("code=-2022, msg=ReduceOnly Order is rejected"))
+	oe.CheckOCOTriggers(89)
+
+	if got := ocoLink(t, oe, link.LinkID); got.Status != OCOStatusStopped || got.StopOrderID != 0 {
+		t.Fatalf("link = %+v, want stopped without stop order", got)
+	}
+}
+
+func TestCancelOCO(t *testing.T) {
+	tests := []struct {
+		name   string
+		native bool
+	}{
+		{"simulated", false},
+		{"native", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newOCOVenue()
+			var ex exchange.IExchange = fake
+			if tt.native {
+				ex = &nativeOCOVenue{FakeVenue: fake}
+			}
+			oe, store := newOCOExecutor(ex)
+			link, err := oe.PlaceOCO(longOCO())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := oe.CancelOCO(link.LinkID); err != nil {
+				t.Fatal(err)
+			}
+			if got := ocoLink(t, oe, link.LinkID); got.Status != OCOStatusCanceled {
+				t.Fatalf("status = %q, want canceled", got.Status)
+			}
+			if store.get(link.LinkID).Status != OCOStatusCanceled {
+				t.Errorf("stored status = %q, want canceled", store.get(link.LinkID).Status)
+			}
+			if fake.Order(link.TakeProfitOrderID).Status != exchange.OrderStatusCanceled {
+				t.Errorf("take-profit not canceled")
+			}
+			if tt.native && fake.Order(link.StopOrderID).Status != exchange.OrderStatusCanceled {
+				t.Errorf("native stop not canceled")
+			}
+
+			// 撤销后不再触发止损，也不能重复撤销
+			oe.CheckOCOTriggers(80)
+			if len(fake.PlacedOrders()) > 1 {
+				t.Errorf("canceled OCO triggered stop: %+v", fake.PlacedOrders())
+			}
+			if err := oe.CancelOCO(link.LinkID); err == nil {
+				t.Error("canceling a finished OCO succeeded")
+			}
+			if err := oe.CancelOCO("missing"); err == nil {
+				t.Error("canceling an unknown OCO succeeded")
+			}
+		})
+	}
+}
+
+func TestRestoreOCOLinks(t *testing.T) {
+	venue := &nativeOCOVenue{FakeVenue: newOCOVenue()}
+	order := func(side exchange.Side, status exchange.OrderStatus, executed float64) int64 {
+		return venue.AddOpenOrder(exchange.Order{Symbol: "BTCUSDT", Side: side, Price: 110, Quantity: 1, Status: status, ExecutedQty: executed})
+	}
+	base := OCOLink{Exchange: "fake", Symbol: "BTCUSDT", Side: "SELL", Quantity: 1, TakeProfitPrice: 110, StopPrice: 90, Status: OCOStatusActive}
+
+	// 模拟模式，停机期间止盈单全部成交
+	tpFilled := base
+	tpFilled.LinkID, tpFilled.TakeProfitOrderID = "ocoFilled", order(exchange.SideSell, exchange.OrderStatusFilled, 1)
+	// 原生模式，停机期间止损单成交：止盈单需要撤销
+	stopped := base
+	stopped.LinkID, stopped.Native = "ocoStopped", true
+	stopped.TakeProfitOrderID = order(exchange.SideSell, exchange.OrderStatusNew, 0)
+	stopped.StopOrderID = order(exchange.SideSell, exchange.OrderStatusFilled, 1)
+	// 模拟模式，止盈单仍在挂单且部分成交：继续监控
+	active := base
+	active.LinkID, active.TakeProfitOrderID = "ocoActive", order(exchange.SideSell, exchange.OrderStatusPartiallyFilled, 0.3)
+	// 其他交易对的关联不恢复
+	other := base
+	other.LinkID, other.Symbol = "ocoOther", "ETHUSDT"
+
+	oe := NewExchangeOrderExecutor(venue, "BTCUSDT", 0, 0, lock.NewNopLock())
+	if err := oe.RestoreOCOLinks(); err != nil {
+		t.Fatalf("restore without store: %v", err)
+	}
+	store := newMemOCOStore(&tpFilled, &stopped, &active, &other)
+	oe.SetOCOStore(store)
+	if err := oe.RestoreOCOLinks(); err != nil {
+		t.Fatal(err)
+	}
+
+	if links := oe.GetOCOLinks(); len(links) != 3 {
+		t.Fatalf("restored %d links, want 3", len(links))
+	}
+	if got := ocoLink(t, oe, "ocoFilled"); got.Status != OCOStatusTakeProfit {
+		t.Errorf("ocoFilled status = %q, want take_profit", got.Status)
+	}
+	if got := ocoLink(t, oe, "ocoStopped"); got.Status != OCOStatusStopped {
+		t.Errorf("ocoStopped status = %q, want stopped", got.Status)
+	}
+	if venue.Order(stopped.TakeProfitOrderID).Status != exchange.OrderStatusCanceled {
+		t.Errorf("orphaned take-profit of a stopped native OCO was not canceled")
+	}
+	got := ocoLink(t, oe, "ocoActive")
+	if got.Status != OCOStatusActive || got.TakeProfitFilled != 0.3 {
+		t.Fatalf("ocoActive = %+v, want active with 0.3 filled", got)
+	}
+
+	// 恢复后的模拟止损继续生效，只平掉剩余数量
+	oe.CheckOCOTriggers(90)
+	got = ocoLink(t, oe, "ocoActive")
+	placed := venue.PlacedOrders()
+	if got.Status != OCOStatusStopped || len(placed) != 1 {
+		t.Fatalf("link = %+v placed = %+v, want stopped with one market order", got, placed)
+	}
+	if diff := placed[0].Quantity - 0.7; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("stop quantity = %v, want 0.7", placed[0].Quantity)
+	}
+}
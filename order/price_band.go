@@ -0,0 +1,156 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+)
+
+// ErrPriceOutOfBand 订单价格超出交易对允许的价格带，在本地拦截，不再提交到交易所
+var ErrPriceOutOfBand = errors.New("价格超出允许的价格带")
+
+// priceBandNotifyInterval 同一方向的越界告警最短间隔（期间的越界只计数），避免每轮调整都刷屏
+const priceBandNotifyInterval = time.Minute
+
+// PriceBandViolation 价格带越界通知
+type PriceBandViolation struct {
+	Exchange   string
+	Symbol     string
+	Strategy   string
+	Side       string
+	Price      float64
+	Lower      float64 // 0 表示无下限
+	Upper      float64 // 0 表示无上限
+	ReduceOnly bool
+	Suppressed int // 上次通知以来被拦截但未通知的次数
+}
+
+// priceBand 交易对价格带：绝对上下限与相对基准价的百分比上下限取较严者
+type priceBand struct {
+	cfg         config.PriceBandConfig
+	onViolation func(PriceBandViolation)
+
+	mu         sync.Mutex
+	reference  float64
+	lastNotify map[string]time.Time // side -> 上次通知时间
+	suppressed map[string]int
+}
+
+// EnablePriceBand 启用价格带限制
+// 价格带之外的订单无论来自哪个策略都在本地拦截；同一方向每分钟最多调用一次 onViolation
+// 百分比价格带需要基准价：配置了 reference_price 时直接使用，否则等待 SetPriceBandReference（网格锚点）
+func (oe *ExchangeOrderExecutor) EnablePriceBand(cfg config.PriceBandConfig, onViolation func(PriceBandViolation)) {
+	oe.priceBand = &priceBand{
+		cfg:         cfg,
+		onViolation: onViolation,
+		reference:   cfg.ReferencePrice,
+		lastNotify:  make(map[string]time.Time),
+		suppressed:  make(map[string]int),
+	}
+}
+
+// SetPriceBandReference 设置百分比价格带的基准价（配置了 reference_price 时忽略）
+func (oe *ExchangeOrderExecutor) SetPriceBandReference(price float64) {
+	if oe.priceBand == nil || oe.priceBand.cfg.ReferencePrice > 0 || price <= 0 {
+		return
+	}
+	oe.priceBand.mu.Lock()
+	oe.priceBand.reference = price
+	oe.priceBand.mu.Unlock()
+	lower, upper := oe.priceBand.limits()
+	logger.Info("📏 [%s:%s] 价格带: %s ~ %s (基准价 %.8g)", oe.exchange.GetName(), oe.symbol,
+		formatBandLimit(lower), formatBandLimit(upper), price)
+}
+
+// PriceBandLimits 当前生效的价格带（0 表示该方向不限制），未启用时 ok 为 false
+func (oe *ExchangeOrderExecutor) PriceBandLimits() (lower, upper float64, ok bool) {
+	if oe.priceBand == nil {
+		return 0, 0, false
+	}
+	lower, upper = oe.priceBand.limits()
+	return lower, upper, true
+}
+
+// checkPriceBand 下单前检查价格带，越界时返回 ErrPriceOutOfBand
+func (oe *ExchangeOrderExecutor) checkPriceBand(req *OrderRequest) error {
+	if oe.priceBand == nil {
+		return nil
+	}
+	if req.ReduceOnly && oe.priceBand.cfg.ExemptReduceOnly {
+		return nil
+	}
+	lower, upper := oe.priceBand.limits()
+	if inBand(req.Price, lower, upper) {
+		return nil
+	}
+
+	if notify, suppressed := oe.priceBand.shouldNotify(req.Side, time.Now()); notify {
+		logger.Warn("📏 [%s:%s] %s 订单价格 %.8g 超出价格带 %s ~ %s，已拦截（上次告警后另拦截 %d 笔）",
+			oe.exchange.GetName(), oe.symbol, req.Side, req.Price,
+			formatBandLimit(lower), formatBandLimit(upper), suppressed)
+		if oe.priceBand.onViolation != nil {
+			oe.priceBand.onViolation(PriceBandViolation{
+				Exchange:   oe.exchange.GetName(),
+				Symbol:     oe.symbol,
+				Strategy:   budgetStrategyName(req),
+				Side:       req.Side,
+				Price:      req.Price,
+				Lower:      lower,
+				Upper:      upper,
+				ReduceOnly: req.ReduceOnly,
+				Suppressed: suppressed,
+			})
+		}
+	}
+	return fmt.Errorf("%w: %s %.8g 不在 %s ~ %s 内", ErrPriceOutOfBand, req.Side, req.Price,
+		formatBandLimit(lower), formatBandLimit(upper))
+}
+
+// limits 计算生效的上下限：绝对值与百分比取较严者，0 表示该方向不限制
+func (b *priceBand) limits() (lower, upper float64) {
+	b.mu.Lock()
+	reference := b.reference
+	b.mu.Unlock()
+
+	lower, upper = b.cfg.MinPrice, b.cfg.MaxPrice
+	if reference > 0 && b.cfg.MaxBelowPercent > 0 {
+		if l := reference * (1 - b.cfg.MaxBelowPercent/100); l > lower {
+			lower = l
+		}
+	}
+	if reference > 0 && b.cfg.MaxAbovePercent > 0 {
+		if u := reference * (1 + b.cfg.MaxAbovePercent/100); upper == 0 || u < upper {
+			upper = u
+		}
+	}
+	return lower, upper
+}
+
+// shouldNotify 同一方向距上次通知超过间隔时返回 true 和期间被拦截的次数
+func (b *priceBand) shouldNotify(side string, now time.Time) (bool, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if last, ok := b.lastNotify[side]; ok && now.Sub(last) < priceBandNotifyInterval {
+		b.suppressed[side]++
+		return false, 0
+	}
+	suppressed := b.suppressed[side]
+	b.lastNotify[side] = now
+	b.suppressed[side] = 0
+	return true, suppressed
+}
+
+func inBand(price, lower, upper float64) bool {
+	return (lower <= 0 || price >= lower) && (upper <= 0 || price <= upper)
+}
+
+func formatBandLimit(v float64) string {
+	if v <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.8g", v)
+}
@@ -0,0 +1,54 @@
+package order
+
+import (
+	"testing"
+	"time"
+
+	"quantmesh/config"
+)
+
+func TestPriceBandLimits(t *testing.T) {
+	b := &priceBand{
+		cfg: config.PriceBandConfig{
+			Enabled:         true,
+			MinPrice:        50000,
+			MaxPrice:        80000,
+			MaxBelowPercent: 10,
+			MaxAbovePercent: 10,
+		},
+		lastNotify: make(map[string]time.Time),
+		suppressed: make(map[string]int),
+	}
+
+	// 未设置基准价时只使用绝对上下限
+	if lower, upper := b.limits(); lower != 50000 || upper != 80000 {
+		t.Fatalf("absolute band = %v ~ %v", lower, upper)
+	}
+
+	// 基准价 60000：百分比下限 54000 比绝对下限严，上限 66000 比绝对上限严
+	b.reference = 60000
+	lower, upper := b.limits()
+	if lower != 54000 || upper != 66000 {
+		t.Fatalf("combined band = %v ~ %v, want the stricter of absolute and percentage", lower, upper)
+	}
+	for price, want := range map[float64]bool{53999: false, 54000: true, 66000: true, 66001: false} {
+		if got := inBand(price, lower, upper); got != want {
+			t.Errorf("inBand(%v) = %v, want %v", price, got, want)
+		}
+	}
+
+	// 同一方向每分钟最多通知一次，期间的越界计数在下次通知时带出
+	now := time.Now()
+	if notify, _ := b.shouldNotify("BUY", now); !notify {
+		t.Fatal("first violation should notify")
+	}
+	if notify, _ := b.shouldNotify("BUY", now.Add(10*time.Second)); notify {
+		t.Error("violation within the interval should be suppressed")
+	}
+	if notify, _ := b.shouldNotify("SELL", now.Add(10*time.Second)); !notify {
+		t.Error("other side should notify independently")
+	}
+	if notify, suppressed := b.shouldNotify("BUY", now.Add(time.Minute)); !notify || suppressed != 1 {
+		t.Errorf("notify=%v suppressed=%d, want true/1", notify, suppressed)
+	}
+}
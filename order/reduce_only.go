@@ -0,0 +1,107 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"quantmesh/logger"
+)
+
+// ErrReduceOnlyExceedsPosition 只减仓单超过当前持仓（或方向与持仓不符），在本地拦截，不再提交到交易所
+// 缓存的持仓可能落后于成交推送，因此调用方只跳过本次下单，不据此清空槽位持仓
+var ErrReduceOnlyExceedsPosition = errors.New("ReduceOnly 订单超过当前持仓")
+
+// reduceOnlyGuard 持仓感知的只减仓检查：缓存交易所持仓，订单成交后失效
+type reduceOnlyGuard struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	size      float64 // 正数为多仓，负数为空仓
+	fetchedAt time.Time
+	valid     bool
+}
+
+// EnableReduceOnlyGuard 启用只减仓统一检查
+// 下单前按当前持仓为只会减少敞口的订单自动加上 ReduceOnly，并拦截超过持仓数量或方向不符的只减仓单
+func (oe *ExchangeOrderExecutor) EnableReduceOnlyGuard(refresh time.Duration) {
+	if refresh <= 0 {
+		refresh = 2 * time.Second
+	}
+	oe.reduceOnly = &reduceOnlyGuard{ttl: refresh}
+}
+
+// InvalidatePositionCache 订单成交后使持仓缓存和保证金模型失效，下次下单前重新查询
+func (oe *ExchangeOrderExecutor) InvalidatePositionCache() {
+	oe.invalidateMarginModel()
+	if oe.reduceOnly == nil {
+		return
+	}
+	oe.reduceOnly.mu.Lock()
+	oe.reduceOnly.valid = false
+	oe.reduceOnly.mu.Unlock()
+}
+
+// currentPosition 获取当前净持仓（缓存有效期内不重复查询）；查询失败时返回 false
+func (oe *ExchangeOrderExecutor) currentPosition() (float64, bool) {
+	g := oe.reduceOnly
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.valid && time.Since(g.fetchedAt) < g.ttl {
+		return g.size, true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	positions, err := oe.exchange.GetPositions(ctx, oe.symbol)
+	if err != nil {
+		logger.Debug("⚠️ [%s] 查询持仓失败，跳过只减仓检查: %v", oe.exchange.GetName(), err)
+		return 0, false
+	}
+	size := 0.0
+	for _, pos := range positions {
+		if pos != nil && pos.Symbol == oe.symbol {
+			size += pos.Size
+		}
+	}
+	g.size, g.fetchedAt, g.valid = size, time.Now(), true
+	return size, true
+}
+
+// applyReduceOnlyGuard 按当前持仓调整或拦截订单
+func (oe *ExchangeOrderExecutor) applyReduceOnlyGuard(req *OrderRequest) error {
+	if oe.reduceOnly == nil || req.Quantity <= 0 {
+		return nil
+	}
+	size, ok := oe.currentPosition()
+	if !ok {
+		return nil
+	}
+
+	// 减仓方向可平掉的数量：卖单对应多仓，买单对应空仓
+	closable := 0.0
+	switch req.Side {
+	case "SELL":
+		closable = math.Max(size, 0)
+	case "BUY":
+		closable = math.Max(-size, 0)
+	}
+	const epsilon = 1e-9
+
+	if req.ReduceOnly {
+		if req.Quantity > closable+epsilon {
+			return fmt.Errorf("%w: %s %.8f > 可平数量 %.8f", ErrReduceOnlyExceedsPosition, req.Side, req.Quantity, closable)
+		}
+		return nil
+	}
+	if closable > 0 && req.Quantity <= closable+epsilon {
+		req.ReduceOnly = true
+		logger.Debug("🔁 [%s] 订单只会减少持仓，自动标记 ReduceOnly: %s %.4f (持仓 %.4f)",
+			oe.exchange.GetName(), req.Side, req.Quantity, size)
+	}
+	return nil
+}
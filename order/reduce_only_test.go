@@ -0,0 +1,160 @@
+package order
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"quantmesh/lock"
+	"quantmesh/testutil"
+)
+
+// newGuardedExecutor 启用只减仓检查的执行器，持仓由假交易所提供
+func newGuardedExecutor(position float64, ttl time.Duration) (*ExchangeOrderExecutor, *testutil.FakeVenue) {
+	ex := testutil.NewFakeVenue("fake", 2, 3)
+	ex.SetPrice("BTCUSDT", 100)
+	ex.SetPosition("BTCUSDT", position)
+	oe := NewExchangeOrderExecutor(ex, "BTCUSDT", 0, 0, lock.NewNopLock())
+	oe.EnableReduceOnlyGuard(ttl)
+	return oe, ex
+}
+
+func limitOrder(side string, qty float64, reduceOnly bool) *OrderRequest {
+	return &OrderRequest{Symbol: "BTCUSDT", Side: side, Price: 100, Quantity: qty, ReduceOnly: reduceOnly, ClientOrderID: side}
+}
+
+func TestReduceOnlyGuardAutoMarks(t *testing.T) {
+	tests := []struct {
+		name     string
+		position float64
+		side     string
+		qty      float64
+		want     bool
+	}{
+		{"sell within long", 0.5, "SELL", 0.3, true},
+		{"sell closes whole long", 0.5, "SELL", 0.5, true},
+		{"sell beyond long opens short", 0.5, "SELL", 0.6, false},
+		{"buy adds to long", 0.5, "BUY", 0.1, false},
+		{"buy within short", -0.5, "BUY", 0.2, true},
+		{"flat", 0, "SELL", 0.1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oe, ex := newGuardedExecutor(tt.position, time.Minute)
+			if _, err := oe.PlaceOrder(limitOrder(tt.side, tt.qty, false)); err != nil {
+				t.Fatal(err)
+			}
+			placed := ex.PlacedOrders()
+			if len(placed) != 1 || placed[0].ReduceOnly != tt.want {
+				t.Fatalf("placed = %+v, want ReduceOnly=%v", placed, tt.want)
+			}
+		})
+	}
+}
+
+func TestReduceOnlyGuardBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		position float64
+		side     string
+		qty      float64
+		blocked  bool
+	}{
+		{"within long", 0.5, "SELL", 0.5, false},
+		{"exceeds long", 0.5, "SELL", 0.6, true},
+		{"wrong direction on long", 0.5, "BUY", 0.1, true},
+		{"no position", 0, "SELL", 0.1, true},
+		{"within short", -0.5, "BUY", 0.4, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oe, ex := newGuardedExecutor(tt.position, time.Minute)
+			_, err := oe.PlaceOrder(limitOrder(tt.side, tt.qty, true))
+			if blocked := errors.Is(err, ErrReduceOnlyExceedsPosition); blocked != tt.blocked {
+				t.Fatalf("err = %v, want blocked=%v", err, tt.blocked)
+			}
+			if tt.blocked && ex.CallCount("PlaceOrder") != 0 {
+				t.Fatal("blocked order reached the exchange")
+			}
+			// 本地拦截不是交易所的 -2022，不能当作无持仓处理
+			if isReduceOnlyError(err) {
+				t.Fatal("local guard error must not be treated as an exchange reduce-only rejection")
+			}
+		})
+	}
+}
+
+func TestReduceOnlyGuardBatchSkipsWithoutClearingSlot(t *testing.T) {
+	oe, ex := newGuardedExecutor(0.1, time.Minute)
+	result := oe.BatchPlaceOrdersWithDetails([]*OrderRequest{
+		limitOrder("SELL", 0.5, true),
+		{Symbol: "BTCUSDT", Side: "BUY", Price: 99, Quantity: 0.1, ClientOrderID: "buy"},
+	})
+	if len(result.ReduceOnlyErrors) != 0 {
+		t.Fatalf("local guard reported as reduce-only error: %v", result.ReduceOnlyErrors)
+	}
+	if len(result.PlacedOrders) != 1 || ex.CallCount("PlaceOrder") != 1 {
+		t.Fatalf("placed %d orders, want only the buy", len(result.PlacedOrders))
+	}
+
+	// 交易所返回 -2022 才计入 ReduceOnlyErrors
+	ex.FailOn("PlaceOrder", errors.New("code=-2022, msg=ReduceOnly Order is rejected"))
+	result = oe.BatchPlaceOrdersWithDetails([]*OrderRequest{limitOrder("SELL", 0.1, true)})
+	if !result.ReduceOnlyErrors["SELL"] {
+		t.Fatalf("exchange -2022 not reported: %v", result.ReduceOnlyErrors)
+	}
+}
+
+func TestReduceOnlyGuardPositionCache(t *testing.T) {
+	oe, ex := newGuardedExecutor(0.5, time.Minute)
+	if _, err := oe.PlaceOrder(limitOrder("SELL", 0.5, true)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 缓存有效期内使用缓存的持仓，不重复查询
+	ex.SetPosition("BTCUSDT", 0)
+	if _, err := oe.PlaceOrder(limitOrder("SELL", 0.5, true)); err != nil {
+		t.Fatalf("cached position should allow the order: %v", err)
+	}
+	if n := ex.CallCount("GetPositions"); n != 1 {
+		t.Fatalf("GetPositions called %d times within the TTL", n)
+	}
+
+	// 成交后失效，下次下单重新查询
+	oe.InvalidatePositionCache()
+	if _, err := oe.PlaceOrder(limitOrder("SELL", 0.5, true)); !errors.Is(err, ErrReduceOnlyExceedsPosition) {
+		t.Fatalf("err = %v, want blocked after invalidation", err)
+	}
+	if n := ex.CallCount("GetPositions"); n != 2 {
+		t.Fatalf("GetPositions called %d times, want 2", n)
+	}
+
+	// 超过有效期后重新查询
+	ex.SetPosition("BTCUSDT", 0.5)
+	oe.reduceOnly.mu.Lock()
+	oe.reduceOnly.fetchedAt = time.Now().Add(-2 * time.Minute)
+	oe.reduceOnly.mu.Unlock()
+	if _, err := oe.PlaceOrder(limitOrder("SELL", 0.5, true)); err != nil {
+		t.Fatalf("expired cache should be refreshed: %v", err)
+	}
+	if n := ex.CallCount("GetPositions"); n != 3 {
+		t.Fatalf("GetPositions called %d times, want 3", n)
+	}
+}
+
+func TestReduceOnlyGuardPositionQueryFails(t *testing.T) {
+	oe, ex := newGuardedExecutor(0, time.Minute)
+	ex.FailOn("GetPositions", errors.New("timeout"))
+
+	// 查询失败时不拦截也不改写订单，交给交易所判断
+	if _, err := oe.PlaceOrder(limitOrder("SELL", 0.5, true)); err != nil {
+		t.Fatalf("order should pass when positions are unknown: %v", err)
+	}
+	if _, err := oe.PlaceOrder(limitOrder("SELL", 0.5, false)); err != nil {
+		t.Fatal(err)
+	}
+	placed := ex.PlacedOrders()
+	if len(placed) != 2 || !placed[0].ReduceOnly || placed[1].ReduceOnly {
+		t.Fatalf("placed = %+v", placed)
+	}
+}
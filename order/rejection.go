@@ -0,0 +1,56 @@
+package order
+
+import (
+	"time"
+
+	"quantmesh/exchange"
+)
+
+// OrderRejection 下单被拒绝或最终失败的记录
+type OrderRejection struct {
+	Exchange string
+	Request  *OrderRequest
+	Category string // 错误分类（见 rejectionCategory）
+	Reason   string
+	Time     time.Time
+}
+
+// RejectionRecorder 拒单记录接口（实现需保证不阻塞下单路径）
+type RejectionRecorder interface {
+	RecordRejection(r *OrderRejection)
+}
+
+// SetRejectionRecorder 设置拒单记录器
+func (oe *ExchangeOrderExecutor) SetRejectionRecorder(recorder RejectionRecorder) {
+	oe.rejections = recorder
+}
+
+// recordRejection 记录一次下单失败
+func (oe *ExchangeOrderExecutor) recordRejection(req *OrderRequest, err error) {
+	if oe.rejections == nil {
+		return
+	}
+	reqCopy := *req
+	oe.rejections.RecordRejection(&OrderRejection{
+		Exchange: oe.exchange.GetName(),
+		Request:  &reqCopy,
+		Category: rejectionCategory(err),
+		Reason:   err.Error(),
+		Time:     time.Now(),
+	})
+}
+
+// rejectionCategory 拒单原因分类：优先使用交易所错误分类，其次识别只减仓/PostOnly 拒单
+func rejectionCategory(err error) string {
+	if name := exchange.ErrorCategoryName(err); name != "" {
+		return name
+	}
+	switch {
+	case isReduceOnlyError(err):
+		return "reduce_only"
+	case isPostOnlyError(err):
+		return "post_only"
+	default:
+		return "other"
+	}
+}
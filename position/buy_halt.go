@@ -0,0 +1,49 @@
+package position
+
+import (
+	"sort"
+
+	"quantmesh/logger"
+)
+
+// HaltBuying 按来源暂停新开仓：AdjustOrders 不再创建买单，卖单（平仓）照常挂出
+// 多个来源可同时暂停，全部来源解除后才恢复买入；已挂出的买单由调用方决定是否撤销
+func (spm *SuperPositionManager) HaltBuying(source string) {
+	if _, loaded := spm.buyHalts.LoadOrStore(source, true); loaded {
+		return
+	}
+	logger.Warn("🛑 [%s] 暂停新开仓 (来源: %s)", spm.config.Trading.Symbol, source)
+}
+
+// ResumeBuying 解除指定来源的新开仓暂停
+func (spm *SuperPositionManager) ResumeBuying(source string) {
+	if _, loaded := spm.buyHalts.LoadAndDelete(source); !loaded {
+		return
+	}
+	if remaining := spm.BuyHaltSources(); len(remaining) > 0 {
+		logger.Info("▶️ [%s] 解除新开仓暂停 (来源: %s)，仍被 %v 暂停", spm.config.Trading.Symbol, source, remaining)
+		return
+	}
+	logger.Info("▶️ [%s] 恢复新开仓 (来源: %s)", spm.config.Trading.Symbol, source)
+}
+
+// BuyHaltSources 当前暂停新开仓的来源（按名称排序，为空表示允许买入）
+func (spm *SuperPositionManager) BuyHaltSources() []string {
+	var sources []string
+	spm.buyHalts.Range(func(key, _ interface{}) bool {
+		sources = append(sources, key.(string))
+		return true
+	})
+	sort.Strings(sources)
+	return sources
+}
+
+// isBuyHalted 是否有来源暂停了新开仓
+func (spm *SuperPositionManager) isBuyHalted() bool {
+	halted := false
+	spm.buyHalts.Range(func(_, _ interface{}) bool {
+		halted = true
+		return false
+	})
+	return halted
+}
@@ -0,0 +1,67 @@
+package position
+
+import "quantmesh/logger"
+
+// PriceZone 价格区间（含边界）
+type PriceZone struct {
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// SetDenseZones 设置买单加密区间：区间内的网格价格下方半格处追加买单槽位，下一次 AdjustOrders 生效
+// 传入空列表等同于 ClearDenseZones
+func (spm *SuperPositionManager) SetDenseZones(zones []PriceZone) {
+	prev, _ := spm.denseZones.Load().([]PriceZone)
+	if equalZones(prev, zones) {
+		return
+	}
+	copied := append([]PriceZone(nil), zones...)
+	spm.denseZones.Store(copied)
+	if len(copied) == 0 {
+		logger.Info("🧱 [%s] 已取消买单加密区间", spm.config.Trading.Symbol)
+		return
+	}
+	logger.Info("🧱 [%s] 买单加密区间更新: %v", spm.config.Trading.Symbol, copied)
+}
+
+// ClearDenseZones 取消买单加密区间
+func (spm *SuperPositionManager) ClearDenseZones() {
+	spm.SetDenseZones(nil)
+}
+
+// GetDenseZones 获取当前生效的买单加密区间
+func (spm *SuperPositionManager) GetDenseZones() []PriceZone {
+	zones, _ := spm.denseZones.Load().([]PriceZone)
+	return append([]PriceZone(nil), zones...)
+}
+
+// denseSlotPrice 网格价格位于加密区间内时，返回其下方半格的槽位价格
+func (spm *SuperPositionManager) denseSlotPrice(gridPrice float64) (float64, bool) {
+	zones, _ := spm.denseZones.Load().([]PriceZone)
+	if len(zones) == 0 {
+		return 0, false
+	}
+	mid := roundPrice(gridPrice-spm.config.Trading.PriceInterval/2, spm.priceDecimals)
+	// 价格精度不足以表示半格时不加密
+	if mid <= 0 || mid == gridPrice || mid == roundPrice(gridPrice-spm.config.Trading.PriceInterval, spm.priceDecimals) {
+		return 0, false
+	}
+	for _, z := range zones {
+		if mid >= z.Low && mid <= z.High {
+			return mid, true
+		}
+	}
+	return 0, false
+}
+
+func equalZones(a, b []PriceZone) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
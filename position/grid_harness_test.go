@@ -0,0 +1,868 @@
+package position_test
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/position"
+	"quantmesh/testutil"
+)
+
+type gridHarness struct {
+	spm   *position.SuperPositionManager
+	exec  *testutil.FakeExecutor
+	ex    *testutil.FakeExchange
+	clock *testutil.FakeClock
+}
+
+func newGridHarness(t *testing.T, existingPosition float64) *gridHarness {
+	t.Helper()
+	return newGridHarnessWith(t, existingPosition, 3, nil)
+}
+
+// newGridHarnessWith 创建测试网格，configure 可在初始化前修改配置
+func newGridHarnessWith(t *testing.T, existingPosition float64, quantityDecimals int, configure func(cfg *config.Config)) *gridHarness {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.App.CurrentExchange = "binance"
+	cfg.Trading.Symbol = "BTCUSDT"
+	cfg.Trading.PriceInterval = 10
+	cfg.Trading.BuyWindowSize = 3
+	cfg.Trading.SellWindowSize = 3
+	cfg.Trading.OrderQuantity = 100
+	cfg.Trading.MinOrderValue = 5
+	cfg.Trading.OrderCleanupThreshold = 100
+	cfg.Trading.MarginLockDurationSec = 30
+	if configure != nil {
+		configure(cfg)
+	}
+
+	h := &gridHarness{
+		exec:  testutil.NewFakeExecutor(),
+		ex:    testutil.NewFakeExchange("binance", 2, quantityDecimals),
+		clock: testutil.NewFakeClock(time.Date(2025, 1, 1, 3, 14, 0, 0, time.UTC)),
+	}
+	if existingPosition > 0 {
+		h.ex.SetPosition("BTCUSDT", existingPosition)
+	}
+	h.spm = position.NewSuperPositionManagerWithDeps(cfg, position.Dependencies{
+		Executor:  h.exec,
+		Exchange:  h.ex,
+		Now:       h.clock.Now,
+		AfterFunc: h.clock.AfterFunc,
+	}, 2, quantityDecimals)
+	h.exec.UpdateHandler = h.spm.OnOrderUpdate
+
+	if err := h.spm.Initialize(1000, "1000.00"); err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	return h
+}
+
+func (h *gridHarness) slot(t *testing.T, price float64) position.DetailedSlotData {
+	t.Helper()
+	for _, s := range h.spm.GetAllSlotsDetailed() {
+		if s.Price == price {
+			return s
+		}
+	}
+	t.Fatalf("未找到价格为 %.2f 的槽位", price)
+	return position.DetailedSlotData{}
+}
+
+func (h *gridHarness) openOrder(t *testing.T, side string, price float64) position.Order {
+	t.Helper()
+	for _, o := range h.exec.OpenOrders(side) {
+		if o.Price == price {
+			return o
+		}
+	}
+	t.Fatalf("未找到 %s 挂单 @ %.2f", side, price)
+	return position.Order{}
+}
+
+func (h *gridHarness) totalSlotQty() float64 {
+	total := 0.0
+	for _, s := range h.spm.GetAllSlotsDetailed() {
+		total += s.PositionQty
+	}
+	return total
+}
+
+func TestGridSlotTransitions(t *testing.T) {
+	tests := []struct {
+		name           string
+		run            func(t *testing.T, h *gridHarness)
+		slotPrice      float64
+		wantPosition   string
+		wantSlotStatus string
+		wantQty        float64
+	}{
+		{
+			name:           "买单挂出后锁定槽位",
+			run:            func(t *testing.T, h *gridHarness) {},
+			slotPrice:      990,
+			wantPosition:   position.PositionStatusEmpty,
+			wantSlotStatus: position.SlotStatusLocked,
+		},
+		{
+			name: "买单全部成交后转为有仓并释放槽位",
+			run: func(t *testing.T, h *gridHarness) {
+				h.exec.Fill(h.openOrder(t, "BUY", 990).ClientOrderID)
+			},
+			slotPrice:      990,
+			wantPosition:   position.PositionStatusFilled,
+			wantSlotStatus: position.SlotStatusFree,
+			wantQty:        0.101,
+		},
+		{
+			name: "买单部分成交后撤销保留持仓",
+			run: func(t *testing.T, h *gridHarness) {
+				oid := h.openOrder(t, "BUY", 990).ClientOrderID
+				h.exec.PartialFill(oid, 0.05)
+				h.exec.Cancel(oid)
+			},
+			slotPrice:      990,
+			wantPosition:   position.PositionStatusFilled,
+			wantSlotStatus: position.SlotStatusFree,
+			wantQty:        0.05,
+		},
+		{
+			name: "买单未成交撤销后重置为空槽位",
+			run: func(t *testing.T, h *gridHarness) {
+				h.exec.Cancel(h.openOrder(t, "BUY", 980).ClientOrderID)
+			},
+			slotPrice:      980,
+			wantPosition:   position.PositionStatusEmpty,
+			wantSlotStatus: position.SlotStatusFree,
+		},
+		{
+			name: "买单成交后挂出卖单，卖单成交后回到空仓",
+			run: func(t *testing.T, h *gridHarness) {
+				h.exec.Fill(h.openOrder(t, "BUY", 990).ClientOrderID)
+				if err := h.spm.AdjustOrders(995); err != nil {
+					t.Fatalf("调整订单失败: %v", err)
+				}
+				h.exec.Fill(h.openOrder(t, "SELL", 1000).ClientOrderID)
+			},
+			slotPrice:      990,
+			wantPosition:   position.PositionStatusEmpty,
+			wantSlotStatus: position.SlotStatusFree,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newGridHarness(t, 0)
+			if err := h.spm.AdjustOrders(1000); err != nil {
+				t.Fatalf("调整订单失败: %v", err)
+			}
+			tt.run(t, h)
+
+			s := h.slot(t, tt.slotPrice)
+			if s.PositionStatus != tt.wantPosition {
+				t.Errorf("持仓状态错误: 期望 %s, 得到 %s", tt.wantPosition, s.PositionStatus)
+			}
+			if s.SlotStatus != tt.wantSlotStatus {
+				t.Errorf("槽位状态错误: 期望 %s, 得到 %s", tt.wantSlotStatus, s.SlotStatus)
+			}
+			if math.Abs(s.PositionQty-tt.wantQty) > 1e-9 {
+				t.Errorf("持仓数量错误: 期望 %.4f, 得到 %.4f", tt.wantQty, s.PositionQty)
+			}
+		})
+	}
+}
+
+func TestGridDuplicateFillIgnored(t *testing.T) {
+	h := newGridHarness(t, 0)
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+
+	oid := h.openOrder(t, "BUY", 990).ClientOrderID
+	h.exec.Fill(oid)
+	// 交易所重复推送同一笔成交
+	h.exec.Replay(oid)
+	h.exec.Replay(oid)
+
+	if got := h.slot(t, 990).PositionQty; math.Abs(got-0.101) > 1e-9 {
+		t.Errorf("重复推送导致持仓重复累加: 期望 0.101, 得到 %.4f", got)
+	}
+	if got := h.spm.GetTotalBuyQty(); math.Abs(got-0.101) > 1e-9 {
+		t.Errorf("重复推送导致累计买入重复累加: 期望 0.101, 得到 %.4f", got)
+	}
+}
+
+func TestGridInstantFillBeforePlaceReturns(t *testing.T) {
+	h := newGridHarness(t, 0)
+	h.exec.FillOnPlace(testutil.ScriptedFill{Side: "BUY", Price: 990})
+
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+
+	s := h.slot(t, 990)
+	if s.PositionStatus != position.PositionStatusFilled {
+		t.Errorf("秒成交后持仓状态错误: 期望 FILLED, 得到 %s", s.PositionStatus)
+	}
+	if s.SlotStatus != position.SlotStatusFree {
+		t.Errorf("秒成交后槽位不应被锁定: 得到 %s", s.SlotStatus)
+	}
+}
+
+func TestGridRejectionReleasesSlot(t *testing.T) {
+	h := newGridHarness(t, 0)
+	h.exec.Reject(testutil.Rejection{Side: "BUY", Price: 990, Times: 1})
+
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+	if s := h.slot(t, 990); s.SlotStatus != position.SlotStatusFree || s.ClientOID != "" {
+		t.Fatalf("下单被拒绝后槽位应释放: SlotStatus=%s, ClientOID=%s", s.SlotStatus, s.ClientOID)
+	}
+
+	// 拒绝脚本只生效一次，下一轮应重新挂单
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+	if s := h.slot(t, 990); s.SlotStatus != position.SlotStatusLocked {
+		t.Errorf("重试后槽位应被锁定: 得到 %s", s.SlotStatus)
+	}
+}
+
+func TestGridMarginErrorPausesUntilLockExpires(t *testing.T) {
+	h := newGridHarness(t, 0)
+	h.exec.Reject(testutil.Rejection{Side: "BUY", Reason: testutil.RejectMargin, Times: 2})
+
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+	placed := len(h.exec.PlacedRequests())
+
+	// 锁定期内不再下单
+	h.clock.Advance(10 * time.Second)
+	h.spm.AdjustOrders(1000)
+	if got := len(h.exec.PlacedRequests()); got != placed {
+		t.Errorf("保证金锁定期内不应下单: 之前 %d, 之后 %d", placed, got)
+	}
+
+	// 锁定期过后恢复下单
+	h.clock.Advance(30 * time.Second)
+	h.spm.AdjustOrders(1000)
+	if got := len(h.exec.PlacedRequests()); got <= placed {
+		t.Errorf("保证金锁定期结束后应恢复下单: 之前 %d, 之后 %d", placed, got)
+	}
+}
+
+type fakeReconciliationStorage struct {
+	history *fakeReconciliationHistory
+	count   int64
+}
+
+type fakeReconciliationHistory struct {
+	ReconcileTime time.Time
+	TotalBuyQty   float64
+	TotalSellQty  float64
+}
+
+func (f *fakeReconciliationStorage) GetLatestReconciliationHistory(symbol string) (interface{}, error) {
+	if f.history == nil {
+		return nil, nil
+	}
+	return f.history, nil
+}
+
+func (f *fakeReconciliationStorage) GetReconciliationCount(symbol string) (int64, error) {
+	return f.count, nil
+}
+
+func TestGridReconciliationRestore(t *testing.T) {
+	// 重启时交易所已有持仓：应恢复为卖单槽位
+	h := newGridHarness(t, 0.303)
+	if got := h.totalSlotQty(); math.Abs(got-0.303) > 1e-9 {
+		t.Errorf("恢复的槽位持仓总量错误: 期望 0.303, 得到 %.4f", got)
+	}
+
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+	if n := len(h.exec.OpenOrders("SELL")); n == 0 {
+		t.Error("恢复持仓后应挂出卖单")
+	}
+
+	reconcileTime := time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)
+	st := &fakeReconciliationStorage{
+		history: &fakeReconciliationHistory{ReconcileTime: reconcileTime, TotalBuyQty: 1.5, TotalSellQty: 1.2},
+		count:   42,
+	}
+	if err := h.spm.RestoreReconciliationStats(st, "BTCUSDT"); err != nil {
+		t.Fatalf("恢复对账统计失败: %v", err)
+	}
+	if h.spm.GetReconcileCount() != 42 {
+		t.Errorf("对账次数错误: 期望 42, 得到 %d", h.spm.GetReconcileCount())
+	}
+	if h.spm.GetTotalBuyQty() != 1.5 || h.spm.GetTotalSellQty() != 1.2 {
+		t.Errorf("累计成交量错误: 买 %.2f 卖 %.2f", h.spm.GetTotalBuyQty(), h.spm.GetTotalSellQty())
+	}
+	if !h.spm.GetLastReconcileTime().Equal(reconcileTime) {
+		t.Errorf("最后对账时间错误: %v", h.spm.GetLastReconcileTime())
+	}
+}
+
+// FuzzGridOrderUpdates 随机的成交/撤单/重复推送/调价序列下，槽位账本应保持一致
+func FuzzGridOrderUpdates(f *testing.F) {
+	f.Add([]byte{0, 1, 2, 3, 4, 5})
+	f.Add([]byte{1, 1, 1, 4, 4, 2, 5, 0})
+	f.Add([]byte{3, 0, 2, 2, 1, 5, 5, 4})
+
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		h := newGridHarness(t, 0)
+		price := 1000.0
+		h.spm.AdjustOrders(price)
+
+		for _, op := range ops {
+			orders := h.exec.OpenOrders("")
+			var target string
+			if len(orders) > 0 {
+				target = orders[int(op>>3)%len(orders)].ClientOrderID
+			}
+			switch op % 6 {
+			case 0:
+				if target != "" {
+					h.exec.Fill(target)
+				}
+			case 1:
+				if target != "" {
+					h.exec.PartialFill(target, 0.01)
+				}
+			case 2:
+				if target != "" {
+					h.exec.Cancel(target)
+				}
+			case 3:
+				if target != "" {
+					h.exec.Replay(target)
+				}
+			case 4:
+				price -= 10
+				h.spm.AdjustOrders(price)
+			case 5:
+				price += 10
+				h.spm.AdjustOrders(price)
+			}
+
+			for _, s := range h.spm.GetAllSlotsDetailed() {
+				if s.PositionQty < 0 {
+					t.Fatalf("槽位 %.2f 持仓为负: %.4f", s.Price, s.PositionQty)
+				}
+				if s.SlotStatus == position.SlotStatusLocked && s.ClientOID == "" && s.OrderID == 0 {
+					t.Fatalf("槽位 %.2f 被锁定但没有关联订单", s.Price)
+				}
+			}
+			net := h.spm.GetTotalBuyQty() - h.spm.GetTotalSellQty()
+			if diff := math.Abs(net - h.totalSlotQty()); diff > 1e-6 {
+				t.Fatalf("累计成交与槽位持仓不一致: 净买入 %.6f, 槽位合计 %.6f", net, h.totalSlotQty())
+			}
+		}
+	})
+}
+
+type memCostBasisStorage struct {
+	mu                  sync.Mutex
+	quantity, totalCost float64
+	saved               bool
+	saves               int
+}
+
+func (m *memCostBasisStorage) SaveCostBasis(exchange, symbol string, quantity, totalCost float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.quantity, m.totalCost, m.saved = quantity, totalCost, true
+	m.saves++
+	return nil
+}
+
+func (m *memCostBasisStorage) GetCostBasis(exchange, symbol string) (float64, float64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.quantity, m.totalCost, m.saved, nil
+}
+
+func (m *memCostBasisStorage) snapshot() (quantity float64, saves int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.quantity, m.saves
+}
+
+func TestGridCostBasisTracksFills(t *testing.T) {
+	h := newGridHarness(t, 0)
+	store := &memCostBasisStorage{}
+	h.spm.SetCostBasisStorage(store)
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+
+	oid := h.openOrder(t, "BUY", 990).ClientOrderID
+	h.exec.PartialFill(oid, 0.05)
+	h.exec.Fill(oid)
+
+	s := h.slot(t, 990)
+	if math.Abs(s.CostBasis-990*0.101) > 1e-6 {
+		t.Errorf("持仓成本错误: 期望 %.4f, 得到 %.4f", 990*0.101, s.CostBasis)
+	}
+	if math.Abs(s.AvgEntryPrice-990) > 1e-6 {
+		t.Errorf("成本价错误: 期望 990, 得到 %.4f", s.AvgEntryPrice)
+	}
+
+	qty, cost, avg := h.spm.GetCostBasis()
+	if math.Abs(qty-0.101) > 1e-9 || math.Abs(cost-990*0.101) > 1e-6 || math.Abs(avg-990) > 1e-6 {
+		t.Errorf("汇总成本错误: qty=%.4f cost=%.4f avg=%.4f", qty, cost, avg)
+	}
+	// 成交时不同步写库（假时钟未推进，延迟持久化尚未执行），合并窗口内的多次变化只写一次
+	if _, saves := store.snapshot(); saves != 0 {
+		t.Errorf("成交推送中同步写入了持仓成本 %d 次", saves)
+	}
+	h.spm.FlushCostBasis()
+	h.spm.FlushCostBasis()
+	if quantity, saves := store.snapshot(); saves != 1 || math.Abs(quantity-0.101) > 1e-9 {
+		t.Errorf("持久化持仓成本错误: 写入 %d 次, 数量 %.4f", saves, quantity)
+	}
+}
+
+func TestGridCostBasisPersistedAfterDelay(t *testing.T) {
+	h := newGridHarness(t, 0)
+	store := &memCostBasisStorage{}
+	h.spm.SetCostBasisStorage(store)
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+	h.exec.Fill(h.openOrder(t, "BUY", 990).ClientOrderID)
+	h.exec.Fill(h.openOrder(t, "BUY", 980).ClientOrderID)
+	want, _, _ := h.spm.GetCostBasis()
+
+	h.clock.Advance(999 * time.Millisecond)
+	if _, saves := store.snapshot(); saves != 0 {
+		t.Fatalf("合并窗口结束前写入了 %d 次", saves)
+	}
+	h.clock.Advance(time.Millisecond)
+	if quantity, saves := store.snapshot(); saves != 1 || math.Abs(quantity-want) > 1e-9 {
+		t.Fatalf("合并窗口内的成交应只写入一次最终成本: 写入 %d 次, 数量 %.4f", saves, quantity)
+	}
+
+	// 已写入后再推进时钟不会重复写入
+	h.clock.Advance(time.Minute)
+	if _, saves := store.snapshot(); saves != 1 {
+		t.Fatalf("无新变化时重复写入: %d 次", saves)
+	}
+}
+
+func TestGridReduceOnlyRejectionClearsCostBasis(t *testing.T) {
+	h := newGridHarness(t, 0)
+	store := &memCostBasisStorage{}
+	h.spm.SetCostBasisStorage(store)
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+	h.exec.Fill(h.openOrder(t, "BUY", 990).ClientOrderID)
+	if s := h.slot(t, 990); s.CostBasis == 0 {
+		t.Fatal("买单成交后应记录持仓成本")
+	}
+	h.clock.Advance(time.Second)
+
+	// 交易所拒绝只减仓卖单（无持仓）：槽位持仓和成本一并清空
+	h.exec.Reject(testutil.Rejection{Side: "SELL", Reason: testutil.RejectReduceOnly, Times: 1})
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+	if s := h.slot(t, 990); s.PositionQty != 0 || s.CostBasis != 0 {
+		t.Fatalf("ReduceOnly 拒单后槽位未清空: 持仓 %.4f, 成本 %.4f", s.PositionQty, s.CostBasis)
+	}
+	if qty, cost, _ := h.spm.GetCostBasis(); qty != 0 || cost != 0 {
+		t.Fatalf("汇总成本未清空: qty=%.4f cost=%.4f", qty, cost)
+	}
+	h.clock.Advance(time.Second)
+	if quantity, saves := store.snapshot(); saves != 2 || quantity != 0 {
+		t.Fatalf("清空后的成本未持久化: 写入 %d 次, 数量 %.4f", saves, quantity)
+	}
+
+	// 再次买入时成本从零开始，不叠加旧成本
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+	h.exec.Fill(h.openOrder(t, "BUY", 990).ClientOrderID)
+	if s := h.slot(t, 990); math.Abs(s.AvgEntryPrice-990) > 1e-6 {
+		t.Fatalf("重新买入后成本价错误: %.4f", s.AvgEntryPrice)
+	}
+}
+
+func TestGridCostBasisRestoredAfterRestart(t *testing.T) {
+	store := &memCostBasisStorage{quantity: 0.3, totalCost: 0.3 * 950, saved: true}
+
+	cfg := &config.Config{}
+	cfg.App.CurrentExchange = "binance"
+	cfg.Trading.Symbol = "BTCUSDT"
+	cfg.Trading.PriceInterval = 10
+	cfg.Trading.BuyWindowSize = 3
+	cfg.Trading.SellWindowSize = 3
+	cfg.Trading.OrderQuantity = 100
+	cfg.Trading.MinOrderValue = 5
+
+	ex := testutil.NewFakeExchange("binance", 2, 3)
+	ex.SetPosition("BTCUSDT", 0.3)
+	exec := testutil.NewFakeExecutor()
+	spm := position.NewSuperPositionManagerWithDeps(cfg, position.Dependencies{Executor: exec, Exchange: ex}, 2, 3)
+	exec.UpdateHandler = spm.OnOrderUpdate
+	spm.SetCostBasisStorage(store)
+	if err := spm.Initialize(1000, "1000.00"); err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+
+	qty, _, avg := spm.GetCostBasis()
+	if math.Abs(qty-0.3) > 1e-9 {
+		t.Fatalf("恢复持仓数量错误: 期望 0.3, 得到 %.4f", qty)
+	}
+	if math.Abs(avg-950) > 1e-6 {
+		t.Errorf("恢复成本价错误: 期望 950, 得到 %.4f", avg)
+	}
+}
+
+func TestGridRecenterCancelsStaleBuys(t *testing.T) {
+	h := newGridHarness(t, 0)
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+	h.exec.Fill(h.openOrder(t, "BUY", 990).ClientOrderID)
+
+	// 价格单边上涨，新窗口的买单已挂出，旧买单停留在远低于价格的位置
+	if err := h.spm.AdjustOrders(1100); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+	stale := h.openOrder(t, "BUY", 980)
+
+	plan, err := h.spm.PlanRecenter()
+	if err != nil {
+		t.Fatalf("PlanRecenter 失败: %v", err)
+	}
+	if plan.NewAnchor != 1100 || plan.ShiftIntervals != 10 {
+		t.Fatalf("新锚点错误: %.2f (移动 %d 格)", plan.NewAnchor, plan.ShiftIntervals)
+	}
+	if len(plan.Cancel) != 1 || plan.Cancel[0].OrderID != stale.OrderID {
+		t.Fatalf("应只撤销 980 的闲置买单, 实际: %+v", plan.Cancel)
+	}
+	if plan.HeldSlots != 1 {
+		t.Errorf("持仓槽位应保留 1 个, 实际 %d", plan.HeldSlots)
+	}
+	if len(h.exec.CanceledOrderIDs()) != 0 {
+		t.Fatalf("预览不应撤单")
+	}
+
+	if _, err := h.spm.Recenter(); err != nil {
+		t.Fatalf("Recenter 失败: %v", err)
+	}
+	if got := h.spm.GetAnchorPrice(); got != 1100 {
+		t.Errorf("锚点应移动到 1100, 实际 %.2f", got)
+	}
+	if ids := h.exec.CanceledOrderIDs(); len(ids) != 1 || ids[0] != stale.OrderID {
+		t.Errorf("撤单列表错误: %v", ids)
+	}
+	if s := h.slot(t, 990); s.PositionStatus != position.PositionStatusFilled {
+		t.Errorf("990 槽位持仓不应受影响, 实际 %s", s.PositionStatus)
+	}
+}
+
+func TestGridDenseZoneAddsHalfIntervalBuys(t *testing.T) {
+	h := newGridHarness(t, 0)
+	h.spm.SetDenseZones([]position.PriceZone{{Low: 981, High: 995}})
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+
+	// 区间内的网格价格下方半格追加买单，区间外不加密
+	h.openOrder(t, "BUY", 995)
+	h.openOrder(t, "BUY", 985)
+	for _, o := range h.exec.OpenOrders("BUY") {
+		if o.Price == 975 {
+			t.Errorf("加密区间外不应挂半格买单: %.2f", o.Price)
+		}
+	}
+
+	// 半格槽位成交后按一个完整价格间隔挂卖单
+	h.exec.Fill(h.openOrder(t, "BUY", 985).ClientOrderID)
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+	h.openOrder(t, "SELL", 995)
+
+	h.spm.ClearDenseZones()
+	if zones := h.spm.GetDenseZones(); len(zones) != 0 {
+		t.Errorf("取消后不应有加密区间: %v", zones)
+	}
+}
+
+func TestGridBuyHaltKeepsSells(t *testing.T) {
+	h := newGridHarness(t, 0)
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+	h.exec.Fill(h.openOrder(t, "BUY", 990).ClientOrderID)
+	buysBefore := len(h.exec.OpenOrders("BUY"))
+
+	// 暂停新开仓：持仓槽位照常挂卖单，价格上移后不追加买单
+	h.spm.HaltBuying("goal")
+	h.spm.HaltBuying("loss_breaker")
+	if err := h.spm.AdjustOrders(1010); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+	h.openOrder(t, "SELL", 1000)
+	if buys := len(h.exec.OpenOrders("BUY")); buys != buysBefore {
+		t.Errorf("暂停新开仓后不应新增买单: 之前 %d 个，现在 %d 个", buysBefore, buys)
+	}
+
+	// 任一来源仍在暂停时不恢复买入
+	h.spm.ResumeBuying("goal")
+	if err := h.spm.AdjustOrders(1010); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+	if buys := len(h.exec.OpenOrders("BUY")); buys != buysBefore {
+		t.Errorf("仍有暂停来源时不应新增买单: 之前 %d 个，现在 %d 个", buysBefore, buys)
+	}
+
+	h.spm.ResumeBuying("loss_breaker")
+	if sources := h.spm.BuyHaltSources(); len(sources) != 0 {
+		t.Errorf("全部解除后不应有暂停来源: %v", sources)
+	}
+	if err := h.spm.AdjustOrders(1010); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+	h.openOrder(t, "BUY", 1000)
+}
+
+func TestGridQuoteGuardWidensAndForcesPostOnly(t *testing.T) {
+	h := newGridHarness(t, 0)
+	h.spm.SetQuoteGuard("volatility", 20)
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+
+	// 保护期间买单距现价至少 20：990 不挂单
+	for _, o := range h.exec.OpenOrders("BUY") {
+		if o.Price > 980 {
+			t.Errorf("挂单保护期间买单过近: %.2f", o.Price)
+		}
+	}
+	h.openOrder(t, "BUY", 980)
+	for _, req := range h.exec.PlacedRequests() {
+		if !req.PostOnly || !req.StrictPost {
+			t.Errorf("挂单保护期间应使用严格 PostOnly: %+v", req)
+		}
+	}
+
+	h.spm.ClearQuoteGuard()
+	if _, _, active := h.spm.GetQuoteGuard(); active {
+		t.Fatalf("解除后不应有挂单保护")
+	}
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+	h.openOrder(t, "BUY", 990)
+}
+
+func TestGridWindowCapLimitsWindowAndLayers(t *testing.T) {
+	h := newGridHarness(t, 0)
+	h.spm.SetWindowCap("deleverage", position.WindowCap{BuyWindow: 2})
+	// 更宽的窗口覆盖不能放宽上限
+	h.spm.SetWindowProfile("trend:up", 5, 5)
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+	if buys := len(h.exec.OpenOrders("BUY")); buys != 1 {
+		t.Fatalf("买单窗口上限为 2（含现价槽位）时应只挂 1 个买单，实际 %d 个", buys)
+	}
+	h.exec.Fill(h.openOrder(t, "BUY", 990).ClientOrderID)
+
+	// 持仓层数达到上限后不再买入，卖单照常
+	h.spm.SetWindowCap("deleverage", position.WindowCap{BuyWindow: 2, MaxLayers: 1})
+	if err := h.spm.AdjustOrders(990); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+	if buys := len(h.exec.OpenOrders("BUY")); buys != 0 {
+		t.Errorf("达到层数上限后不应新增买单，实际 %d 个", buys)
+	}
+	h.openOrder(t, "SELL", 1000)
+
+	h.spm.ClearWindowCap("deleverage")
+	if caps := h.spm.GetWindowCaps(); len(caps) != 0 {
+		t.Errorf("解除后不应有上限: %v", caps)
+	}
+	if err := h.spm.AdjustOrders(990); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+	h.openOrder(t, "BUY", 980)
+}
+
+type memTradeStorage struct {
+	pnls []float64
+}
+
+func (m *memTradeStorage) SaveTrade(buyOrderID, sellOrderID int64, exchange, symbol string, buyPrice, sellPrice, quantity, pnl float64, createdAt time.Time) error {
+	m.pnls = append(m.pnls, pnl)
+	return nil
+}
+
+func TestGridInverseContractMath(t *testing.T) {
+	// 币本位：每单 100 USD、每张 10 USD -> 每单 10 张，盈亏以基础币计
+	h := newGridHarnessWith(t, 0, 0, func(cfg *config.Config) {
+		cfg.Trading.Symbol = "BTCUSD_PERP"
+		cfg.Trading.ContractType = "inverse"
+		cfg.Trading.ContractSize = 10
+	})
+	trades := &memTradeStorage{}
+	h.spm.SetTradeStorage(trades)
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+
+	buy := h.openOrder(t, "BUY", 990)
+	if buy.Quantity != 10 {
+		t.Fatalf("币本位每单应为 10 张，实际 %.4f", buy.Quantity)
+	}
+	h.exec.Fill(buy.ClientOrderID)
+	if err := h.spm.AdjustOrders(995); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+	h.exec.Fill(h.openOrder(t, "SELL", 1000).ClientOrderID)
+
+	want := 10 * 10 * (1.0/990 - 1.0/1000)
+	if len(trades.pnls) != 1 || math.Abs(trades.pnls[0]-want) > 1e-12 {
+		t.Errorf("币本位盈亏错误: 期望 %.8f, 得到 %v", want, trades.pnls)
+	}
+}
+
+func TestGridSlotManualControls(t *testing.T) {
+	h := newGridHarness(t, 0)
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+	h.exec.Fill(h.openOrder(t, "BUY", 990).ClientOrderID)
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+	takeProfit := h.openOrder(t, "SELL", 1000)
+
+	// 锁定并撤销 980 的买单：撤单确认后不再补挂
+	buy980 := h.openOrder(t, "BUY", 980)
+	if _, err := h.spm.ApplySlotAction(980, position.SlotActionLock, "ops", "价位异常"); err != nil {
+		t.Fatalf("锁定失败: %v", err)
+	}
+	res, err := h.spm.ApplySlotAction(980, position.SlotActionCancel, "ops", "价位异常")
+	if err != nil || res.CanceledOrderID != buy980.OrderID || !res.Locked {
+		t.Fatalf("撤单结果错误: %+v, %v", res, err)
+	}
+	h.exec.Cancel(buy980.ClientOrderID)
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+	if s := h.slot(t, 980); s.OrderID != 0 || !s.ManualLocked {
+		t.Errorf("锁定的槽位不应补挂买单: %+v", s)
+	}
+	if _, err := h.spm.ApplySlotAction(980, position.SlotActionCancel, "ops", "重复撤单"); err == nil {
+		t.Error("没有活跃订单时撤单应报错")
+	}
+
+	// 强制卖出 990 的持仓：撤销止盈单，按略低于市价挂出只减仓单
+	res, err = h.spm.ApplySlotAction(990, position.SlotActionSell, "ops", "手动止损")
+	if err != nil {
+		t.Fatalf("强制卖出失败: %v", err)
+	}
+	if res.CanceledOrderID != takeProfit.OrderID || res.SellPrice != 990 || res.SellQty != 0.101 {
+		t.Fatalf("强制卖出结果错误: %+v", res)
+	}
+	h.exec.Cancel(takeProfit.ClientOrderID) // 旧止盈单的撤单推送不影响新订单
+	forced := h.openOrder(t, "SELL", 990)
+	if reqs := h.exec.PlacedRequests(); !reqs[len(reqs)-1].ReduceOnly || reqs[len(reqs)-1].PostOnly {
+		t.Error("强制卖单应为只减仓且不使用 PostOnly")
+	}
+	h.exec.Fill(forced.ClientOrderID)
+	if s := h.slot(t, 990); s.PositionStatus != position.PositionStatusEmpty || s.SlotStatus != position.SlotStatusFree {
+		t.Errorf("强制卖单成交后槽位应清空: %+v", s)
+	}
+
+	// 解锁后恢复挂单
+	if _, err := h.spm.ApplySlotAction(980, position.SlotActionUnlock, "ops", "已恢复"); err != nil {
+		t.Fatalf("解锁失败: %v", err)
+	}
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("AdjustOrders 失败: %v", err)
+	}
+	h.openOrder(t, "BUY", 980)
+	if _, err := h.spm.ApplySlotAction(980, position.SlotActionUnlock, "ops", "重复解锁"); err == nil {
+		t.Error("未锁定的槽位解锁应报错")
+	}
+}
+
+func TestGridResumeAdoptsOrders(t *testing.T) {
+	h := newGridHarness(t, 0)
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+	h.exec.Fill(h.openOrder(t, "BUY", 990).ClientOrderID)
+	h.ex.SetPosition("BTCUSDT", 0.101)
+	if err := h.spm.AdjustOrders(1000); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+	// 快照后按 cancel_buys 撤掉 980 的买单，卖单保留
+	snapshot := h.spm.SnapshotSlots()
+	h.exec.Cancel(h.openOrder(t, "BUY", 980).ClientOrderID)
+
+	open := map[int64]bool{}
+	for _, o := range h.exec.OpenOrders("") {
+		open[o.OrderID] = true
+	}
+	placedBefore := len(h.exec.PlacedRequests())
+
+	cfg := &config.Config{}
+	cfg.App.CurrentExchange = "binance"
+	cfg.Trading.Symbol = "BTCUSDT"
+	cfg.Trading.PriceInterval = 10
+	cfg.Trading.BuyWindowSize = 3
+	cfg.Trading.SellWindowSize = 3
+	cfg.Trading.OrderQuantity = 100
+	cfg.Trading.MinOrderValue = 5
+	cfg.Trading.OrderCleanupThreshold = 100
+	resumed := position.NewSuperPositionManagerWithDeps(cfg, position.Dependencies{
+		Executor: h.exec,
+		Exchange: h.ex,
+		Now:      h.clock.Now,
+	}, 2, 3)
+	h.exec.UpdateHandler = resumed.OnOrderUpdate
+
+	// 重启期间有成交（持仓变化）时拒绝恢复，不修改状态
+	h.ex.SetPosition("BTCUSDT", 0.202)
+	if _, err := resumed.ResumeSlots(1000, snapshot, open, 0.101); err == nil {
+		t.Fatal("持仓变化时应拒绝恢复")
+	}
+	h.ex.SetPosition("BTCUSDT", 0.101)
+
+	result, err := resumed.ResumeSlots(1000, snapshot, open, 0.101)
+	if err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	if result.Slots != 1 || result.Adopted != 1 || result.Dropped != 1 {
+		t.Fatalf("恢复结果错误: %+v", result)
+	}
+	if err := resumed.AdjustOrders(1000); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+
+	// 只补挂被撤销的 980 买单，接管的挂单不重复下单
+	placed := h.exec.PlacedRequests()[placedBefore:]
+	if len(placed) != 1 || placed[0].Side != "BUY" || placed[0].Price != 980 {
+		t.Fatalf("恢复后应只补挂 980 买单, 实际: %+v", placed)
+	}
+	if qty, _, _ := resumed.GetCostBasis(); math.Abs(qty-0.101) > 1e-9 {
+		t.Errorf("恢复持仓数量错误: %.4f", qty)
+	}
+}
@@ -0,0 +1,135 @@
+package position
+
+import (
+	"fmt"
+	"time"
+
+	"quantmesh/event"
+	"quantmesh/logger"
+)
+
+// ManualSlotAdjustment 人工修正槽位库存的结果（修正前后快照）
+type ManualSlotAdjustment struct {
+	Exchange  string           `json:"exchange"`
+	Symbol    string           `json:"symbol"`
+	Price     float64          `json:"price"`
+	Before    DetailedSlotData `json:"before"`
+	After     DetailedSlotData `json:"after"`
+	Operator  string           `json:"operator"`
+	Reason    string           `json:"reason"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// ManualAdjustSlot 人工修正槽位库存（应急手段，用于本地模型与实际持仓不一致且无法自动恢复时）
+// filled=true 时将槽位标记为有仓并设置数量，filled=false 时清空槽位持仓。
+// 槽位存在活跃订单时拒绝修改，需先撤单，避免与订单回调互相覆盖。
+// 修改会发布 manual_position_adjustment 事件，调用方应随后触发一次对账。
+func (spm *SuperPositionManager) ManualAdjustSlot(price float64, filled bool, qty float64, operator, reason string) (*ManualSlotAdjustment, error) {
+	if price <= 0 {
+		return nil, fmt.Errorf("槽位价格必须大于0")
+	}
+	if filled && qty <= 0 {
+		return nil, fmt.Errorf("标记为有仓时数量必须大于0")
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("必须填写修正原因")
+	}
+
+	price = roundPrice(price, spm.priceDecimals)
+	if _, exists := spm.slots.Load(price); !exists && !filled {
+		return nil, fmt.Errorf("槽位 %s 不存在", formatPrice(price, spm.priceDecimals))
+	}
+	slot := spm.getOrCreateSlot(price)
+
+	slot.mu.Lock()
+	if slot.SlotStatus != SlotStatusFree || hasActiveOrder(slot.OrderStatus) {
+		status, orderID := slot.OrderStatus, slot.OrderID
+		slot.mu.Unlock()
+		return nil, fmt.Errorf("槽位 %s 存在活跃订单 %d (%s)，请先撤单后再修正",
+			formatPrice(price, spm.priceDecimals), orderID, status)
+	}
+
+	before := snapshotSlot(price, slot)
+	if filled {
+		slot.PositionStatus = PositionStatusFilled
+		slot.PositionQty = roundPrice(qty, spm.quantityDecimals)
+		slot.CostBasis = price * slot.PositionQty
+		slot.OrderSide = "SELL"
+	} else {
+		slot.PositionStatus = PositionStatusEmpty
+		slot.PositionQty = 0
+		slot.CostBasis = 0
+		slot.OrderSide = ""
+	}
+	slot.OrderID = 0
+	slot.ClientOID = ""
+	slot.OrderStatus = OrderStatusNotPlaced
+	slot.OrderFilledQty = 0
+	slot.SlotStatus = SlotStatusFree
+	after := snapshotSlot(price, slot)
+	slot.mu.Unlock()
+
+	spm.persistCostBasis()
+
+	adj := &ManualSlotAdjustment{
+		Exchange:  spm.exchangeName,
+		Symbol:    spm.config.Trading.Symbol,
+		Price:     price,
+		Before:    before,
+		After:     after,
+		Operator:  operator,
+		Reason:    reason,
+		Timestamp: spm.now(),
+	}
+
+	logger.Warn("🛠️ [%s:%s] [人工修正] 槽位 %s: %s %.4f -> %s %.4f (操作人: %s, 原因: %s)",
+		adj.Exchange, adj.Symbol, formatPrice(price, spm.priceDecimals),
+		before.PositionStatus, before.PositionQty, after.PositionStatus, after.PositionQty, operator, reason)
+
+	if spm.eventBus != nil {
+		spm.eventBus.Publish(&event.Event{
+			Type: event.EventTypeManualPositionAdjust,
+			Data: map[string]interface{}{
+				"exchange":      adj.Exchange,
+				"symbol":        adj.Symbol,
+				"price":         price,
+				"before_status": before.PositionStatus,
+				"before_qty":    before.PositionQty,
+				"after_status":  after.PositionStatus,
+				"after_qty":     after.PositionQty,
+				"operator":      operator,
+				"reason":        reason,
+			},
+		})
+	}
+
+	return adj, nil
+}
+
+// hasActiveOrder 订单是否仍在交易所挂着（或撤单尚未确认）
+func hasActiveOrder(status string) bool {
+	switch status {
+	case OrderStatusPlaced, OrderStatusConfirmed, OrderStatusPartiallyFilled, OrderStatusCancelRequested:
+		return true
+	}
+	return false
+}
+
+// snapshotSlot 生成槽位快照（调用方需持有槽位锁）
+func snapshotSlot(price float64, slot *InventorySlot) DetailedSlotData {
+	return DetailedSlotData{
+		Price:          price,
+		PositionStatus: slot.PositionStatus,
+		PositionQty:    slot.PositionQty,
+		CostBasis:      slot.CostBasis,
+		AvgEntryPrice:  slot.entryPrice(),
+		OrderID:        slot.OrderID,
+		ClientOID:      slot.ClientOID,
+		OrderSide:      slot.OrderSide,
+		OrderStatus:    slot.OrderStatus,
+		OrderPrice:     slot.OrderPrice,
+		OrderFilledQty: slot.OrderFilledQty,
+		OrderCreatedAt: slot.OrderCreatedAt,
+		SlotStatus:     slot.SlotStatus,
+	}
+}
@@ -0,0 +1,316 @@
+package position
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"quantmesh/logger"
+)
+
+// QueueLevel 盘口价位
+type QueueLevel struct {
+	Price float64
+	Qty   float64
+}
+
+// QueueInfo 挂单的排队位置估算
+type QueueInfo struct {
+	Known    bool    // 是否已获得该价位的盘口数据（挂单价位在盘口推送深度之外时未知）
+	Ahead    float64 // 估算的前方排队数量
+	LevelQty float64 // 该价位最近一次的盘口总量（含自身）
+	Ratio    float64 // 前方排队量占该价位总量的比例
+}
+
+// queuedOrder 被跟踪的挂单
+type queuedOrder struct {
+	side     string
+	price    float64
+	qty      float64 // 剩余未成交数量
+	ahead    float64
+	levelQty float64
+	known    bool
+	requeued bool // 已因排队过深撤单，避免重复处理
+}
+
+// restingOrder 当前在交易所挂着的订单（由槽位生成）
+type restingOrder struct {
+	orderID int64
+	side    string
+	price   float64
+	qty     float64
+}
+
+// QueueEstimator 挂单排队位置估算器
+// 挂单出现时以该价位的盘口总量作为前方排队量（保守假设排在最后），
+// 之后同价位的主动成交使前方排队量减少，盘口总量减少时前方排队量不超过（总量 - 自身数量）。
+type QueueEstimator struct {
+	mu     sync.Mutex
+	bids   map[float64]float64
+	asks   map[float64]float64
+	orders map[int64]*queuedOrder
+}
+
+// NewQueueEstimator 创建排队位置估算器
+func NewQueueEstimator() *QueueEstimator {
+	return &QueueEstimator{
+		bids:   make(map[float64]float64),
+		asks:   make(map[float64]float64),
+		orders: make(map[int64]*queuedOrder),
+	}
+}
+
+// sync 同步当前挂单：新增的订单按盘口初始化，已不存在的订单移除
+func (q *QueueEstimator) sync(orders []restingOrder) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	seen := make(map[int64]bool, len(orders))
+	for _, o := range orders {
+		seen[o.orderID] = true
+		existing, ok := q.orders[o.orderID]
+		if ok {
+			existing.qty = o.qty
+			continue
+		}
+		tracked := &queuedOrder{side: o.side, price: o.price, qty: o.qty}
+		q.refresh(tracked)
+		q.orders[o.orderID] = tracked
+	}
+	for id := range q.orders {
+		if !seen[id] {
+			delete(q.orders, id)
+		}
+	}
+}
+
+// onDepth 更新盘口快照（价格需已按精度取整）
+func (q *QueueEstimator) onDepth(bids, asks []QueueLevel) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.bids = make(map[float64]float64, len(bids))
+	for _, l := range bids {
+		q.bids[l.Price] = l.Qty
+	}
+	q.asks = make(map[float64]float64, len(asks))
+	for _, l := range asks {
+		q.asks[l.Price] = l.Qty
+	}
+	for _, o := range q.orders {
+		q.refresh(o)
+	}
+}
+
+// refresh 根据盘口更新单个订单的估算（调用方需持有锁）
+func (q *QueueEstimator) refresh(o *queuedOrder) {
+	levels := q.bids
+	if o.side == "SELL" {
+		levels = q.asks
+	}
+	visible, ok := levels[o.price]
+	if !ok {
+		return
+	}
+	maxAhead := math.Max(0, visible-o.qty)
+	if !o.known || maxAhead < o.ahead {
+		o.ahead = maxAhead
+	}
+	o.levelQty = visible
+	o.known = true
+}
+
+// onTrade 处理逐笔成交，buyerMaker=true 表示卖方主动成交（吃掉买盘）
+func (q *QueueEstimator) onTrade(price, qty float64, buyerMaker bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, o := range q.orders {
+		if !o.known {
+			continue
+		}
+		switch {
+		case o.side == "BUY" && buyerMaker && price == o.price,
+			o.side == "SELL" && !buyerMaker && price == o.price:
+			o.ahead = math.Max(0, o.ahead-qty)
+		case o.side == "BUY" && price < o.price,
+			o.side == "SELL" && price > o.price:
+			// 成交价已穿过挂单价位，前方排队已全部成交
+			o.ahead = 0
+		}
+	}
+}
+
+// get 查询订单的排队位置估算
+func (q *QueueEstimator) get(orderID int64) (QueueInfo, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	o, ok := q.orders[orderID]
+	if !ok {
+		return QueueInfo{}, false
+	}
+	info := QueueInfo{Known: o.known, Ahead: o.ahead, LevelQty: o.levelQty}
+	if o.levelQty > 0 {
+		info.Ratio = o.ahead / o.levelQty
+	}
+	return info, true
+}
+
+// markRequeued 标记订单已因排队过深撤单，返回是否为首次标记
+func (q *QueueEstimator) markRequeued(orderID int64) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	o, ok := q.orders[orderID]
+	if !ok || o.requeued {
+		return false
+	}
+	o.requeued = true
+	return true
+}
+
+// OnDepthUpdate 盘口更新回调：同步挂单、更新排队估算，并按配置检查是否需要重挂
+func (spm *SuperPositionManager) OnDepthUpdate(bids, asks []QueueLevel) {
+	if spm.queueEstimator == nil {
+		return
+	}
+	for i := range bids {
+		bids[i].Price = roundPrice(bids[i].Price, spm.priceDecimals)
+	}
+	for i := range asks {
+		asks[i].Price = roundPrice(asks[i].Price, spm.priceDecimals)
+	}
+	spm.queueEstimator.sync(spm.restingOrders())
+	spm.queueEstimator.onDepth(bids, asks)
+	spm.requeueStuckOrders()
+}
+
+// OnTradePrint 逐笔成交回调
+func (spm *SuperPositionManager) OnTradePrint(price, qty float64, buyerMaker bool) {
+	if spm.queueEstimator == nil {
+		return
+	}
+	spm.queueEstimator.onTrade(roundPrice(price, spm.priceDecimals), qty, buyerMaker)
+}
+
+// QueuePosition 查询订单的排队位置估算（未启用或订单未跟踪时返回 false）
+func (spm *SuperPositionManager) QueuePosition(orderID int64) (QueueInfo, bool) {
+	if spm.queueEstimator == nil || orderID == 0 {
+		return QueueInfo{}, false
+	}
+	return spm.queueEstimator.get(orderID)
+}
+
+// restingOrders 当前挂着的订单（买单数量按每单金额估算，卖单数量为槽位持仓）
+func (spm *SuperPositionManager) restingOrders() []restingOrder {
+	var orders []restingOrder
+	spm.slots.Range(func(key, value interface{}) bool {
+		slot := value.(*InventorySlot)
+		slot.mu.RLock()
+		defer slot.mu.RUnlock()
+		if slot.OrderID == 0 || slot.OrderPrice <= 0 || !hasActiveOrder(slot.OrderStatus) ||
+			slot.OrderStatus == OrderStatusCancelRequested {
+			return true
+		}
+		qty := slot.PositionQty
+		if slot.OrderSide == "BUY" {
+			qty = roundPrice(spm.config.Trading.OrderQuantity/slot.OrderPrice, spm.quantityDecimals)
+		}
+		orders = append(orders, restingOrder{
+			orderID: slot.OrderID,
+			side:    slot.OrderSide,
+			price:   roundPrice(slot.OrderPrice, spm.priceDecimals),
+			qty:     math.Max(0, qty-slot.OrderFilledQty),
+		})
+		return true
+	})
+	return orders
+}
+
+// requeueStuckOrders 撤掉整数关口附近排队过深的挂单，下次挂单时向盘口内侧改善价格
+// 整数关口常堆积大量挂单，排在队尾的订单几乎无法成交
+func (spm *SuperPositionManager) requeueStuckOrders() {
+	cfg := spm.config.Trading.QueuePosition
+	if !cfg.Requeue || spm.IsPaused() {
+		return
+	}
+	now := spm.now()
+	tick := math.Pow10(-spm.priceDecimals)
+	minRest := time.Duration(cfg.MinRestSeconds) * time.Second
+
+	var orderIDs []int64
+	var slots []*InventorySlot
+	spm.slots.Range(func(key, value interface{}) bool {
+		slot := value.(*InventorySlot)
+		slot.mu.Lock()
+		defer slot.mu.Unlock()
+		if slot.OrderID == 0 || slot.SlotStatus != SlotStatusLocked || now.Sub(slot.OrderCreatedAt) < minRest {
+			return true
+		}
+		if slot.OrderStatus != OrderStatusPlaced && slot.OrderStatus != OrderStatusConfirmed {
+			return true
+		}
+		info, ok := spm.queueEstimator.get(slot.OrderID)
+		if !ok || !info.Known || info.Ratio < cfg.MaxAheadRatio {
+			return true
+		}
+		remaining := info.LevelQty - info.Ahead
+		if remaining <= 0 || info.Ahead < cfg.MinAheadMultiple*remaining {
+			return true
+		}
+		if !nearRoundNumber(slot.OrderPrice, cfg.RoundNumberStep, cfg.RoundNumberTicks, tick) {
+			return true
+		}
+		if !spm.queueEstimator.markRequeued(slot.OrderID) {
+			return true
+		}
+		logger.Info("🔁 [%s] [排队过深] %s单 %s 前方排队 %.4f（占该价位 %.0f%%），撤单后改善 %d 个价位重挂",
+			spm.config.Trading.Symbol, slot.OrderSide, formatPrice(slot.OrderPrice, spm.priceDecimals),
+			info.Ahead, info.Ratio*100, cfg.ImproveTicks)
+		slot.QueueImproveTicks = cfg.ImproveTicks
+		orderIDs = append(orderIDs, slot.OrderID)
+		slots = append(slots, slot)
+		return true
+	})
+
+	if len(orderIDs) == 0 {
+		return
+	}
+	if err := spm.executor.BatchCancelOrders(orderIDs); err != nil {
+		logger.Warn("⚠️ [%s] [排队过深] 撤单失败: %v", spm.config.Trading.Symbol, err)
+		for _, slot := range slots {
+			slot.mu.Lock()
+			slot.QueueImproveTicks = 0
+			slot.mu.Unlock()
+		}
+	}
+}
+
+// queueImprovedPrice 消耗槽位的改善价位数，返回改善后的挂单价格（调用方需持有槽位锁）
+// 买单向上、卖单向下改善，不得越过 limit（买单上限 / 卖单下限）
+func (spm *SuperPositionManager) queueImprovedPrice(slot *InventorySlot, side string, price, limit float64) float64 {
+	ticks := slot.QueueImproveTicks
+	if ticks <= 0 {
+		return price
+	}
+	slot.QueueImproveTicks = 0
+	offset := float64(ticks) * math.Pow10(-spm.priceDecimals)
+	improved := price + offset
+	if side == "SELL" {
+		improved = price - offset
+		if improved <= limit {
+			return price
+		}
+	} else if improved >= limit {
+		return price
+	}
+	return roundPrice(improved, spm.priceDecimals)
+}
+
+// nearRoundNumber 价格是否位于整数关口附近（step<=0 时视为满足）
+func nearRoundNumber(price, step float64, ticks int, tick float64) bool {
+	if step <= 0 {
+		return true
+	}
+	nearest := math.Round(price/step) * step
+	return math.Abs(price-nearest) <= float64(ticks)*tick+tick/2
+}
@@ -0,0 +1,39 @@
+package position
+
+import "quantmesh/logger"
+
+// quoteGuard 挂单保护参数（高波动期间生效）
+type quoteGuard struct {
+	name   string
+	offset float64 // 新挂单与当前价格的最小距离
+}
+
+// SetQuoteGuard 启用挂单保护：新挂单强制严格 PostOnly（多次被拒也不降级为吃单），
+// 且买单价格不高于 当前价-offset、卖单价格不低于 当前价+offset，下一次 AdjustOrders 生效
+func (spm *SuperPositionManager) SetQuoteGuard(name string, offset float64) {
+	next := &quoteGuard{name: name, offset: offset}
+	if prev, _ := spm.quoteGuard.Load().(*quoteGuard); prev != nil && *prev == *next {
+		return
+	}
+	spm.quoteGuard.Store(next)
+	logger.Warn("🛡️ [%s] 启用挂单保护 %s: 强制 PostOnly，新挂单距现价至少 %.*f",
+		spm.config.Trading.Symbol, name, spm.priceDecimals, offset)
+}
+
+// ClearQuoteGuard 解除挂单保护，恢复正常挂单
+func (spm *SuperPositionManager) ClearQuoteGuard() {
+	if prev, _ := spm.quoteGuard.Load().(*quoteGuard); prev == nil || prev.name == "" {
+		return
+	}
+	spm.quoteGuard.Store(&quoteGuard{})
+	logger.Info("🛡️ [%s] 挂单保护已解除", spm.config.Trading.Symbol)
+}
+
+// GetQuoteGuard 获取当前生效的挂单保护（active=false 表示未启用）
+func (spm *SuperPositionManager) GetQuoteGuard() (name string, offset float64, active bool) {
+	g, _ := spm.quoteGuard.Load().(*quoteGuard)
+	if g == nil || g.name == "" {
+		return "", 0, false
+	}
+	return g.name, g.offset, true
+}
@@ -0,0 +1,243 @@
+package position
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"quantmesh/event"
+	"quantmesh/logger"
+)
+
+// AnchorStorage 网格锚点存储接口（避免循环导入）
+// 重新居中后保存新锚点，重启时由冷启动锚点逻辑沿用
+type AnchorStorage interface {
+	SaveAnchor(exchange, symbol string, price float64, source string) error
+}
+
+// RecenterOrder 重新居中计划中的单个订单
+type RecenterOrder struct {
+	SlotPrice float64 `json:"slot_price"`
+	OrderID   int64   `json:"order_id,omitempty"`
+	Price     float64 `json:"price"`
+	Quantity  float64 `json:"quantity,omitempty"` // 仅待补买单
+}
+
+// RecenterPlan 网格重新居中计划
+// 锚点沿原网格平移到当前价格附近（网格间隔不变，已有持仓的槽位及其卖单保持不动），
+// Cancel 为新买单窗口之外仍挂着的闲置买单，Place 为新窗口内缺少买单的槽位（执行后由下一次订单调整挂出）
+type RecenterPlan struct {
+	Exchange       string          `json:"exchange"`
+	Symbol         string          `json:"symbol"`
+	Trigger        string          `json:"trigger"` // manual / band / time
+	CurrentPrice   float64         `json:"current_price"`
+	OldAnchor      float64         `json:"old_anchor"`
+	NewAnchor      float64         `json:"new_anchor"`
+	ShiftIntervals int             `json:"shift_intervals"` // 锚点移动的网格数（正数为上移）
+	Cancel         []RecenterOrder `json:"cancel"`
+	Place          []RecenterOrder `json:"place"`
+	HeldSlots      int             `json:"held_slots"` // 保留不动的持仓槽位数
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// SetAnchorStorage 设置网格锚点存储（未设置时重新居中后的锚点不持久化）
+func (spm *SuperPositionManager) SetAnchorStorage(storage AnchorStorage) {
+	spm.anchorStorage = storage
+}
+
+// PlanRecenter 按最新市场价格生成重新居中计划（只预览，不撤单）
+func (spm *SuperPositionManager) PlanRecenter() (*RecenterPlan, error) {
+	spm.mu.RLock()
+	defer spm.mu.RUnlock()
+	currentPrice, _ := spm.lastMarketPrice.Load().(float64)
+	return spm.planRecenter(currentPrice, "manual")
+}
+
+// Recenter 手动执行重新居中：撤销窗口外的闲置买单、移动锚点，并立即按新窗口补挂买单
+func (spm *SuperPositionManager) Recenter() (*RecenterPlan, error) {
+	spm.mu.Lock()
+	currentPrice, _ := spm.lastMarketPrice.Load().(float64)
+	plan, err := spm.planRecenter(currentPrice, "manual")
+	if err == nil {
+		spm.applyRecenter(plan)
+	}
+	spm.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := spm.AdjustOrders(currentPrice); err != nil {
+		logger.Warn("⚠️ [%s:%s] [网格居中] 补挂买单失败: %v", spm.exchangeName, spm.config.Trading.Symbol, err)
+	}
+	return plan, nil
+}
+
+// maybeRecenter 按配置的策略检查是否需要重新居中（由 AdjustOrders 调用，调用方需持有 spm.mu）
+func (spm *SuperPositionManager) maybeRecenter(currentPrice float64) {
+	cfg := spm.config.Trading.Recenter
+	interval := spm.config.Trading.PriceInterval
+	if interval <= 0 || spm.anchorPrice <= 0 {
+		return
+	}
+
+	switch cfg.Policy {
+	case "band":
+		if math.Abs(currentPrice-spm.anchorPrice) <= float64(cfg.BandIntervals)*interval {
+			return
+		}
+	case "time":
+		if spm.now().Sub(spm.lastRecenterAt) < time.Duration(cfg.IntervalMinutes)*time.Minute {
+			return
+		}
+		// 无论是否执行都重新计时，避免价格横盘时每次调整都重复计算
+		spm.lastRecenterAt = spm.now()
+	default:
+		return
+	}
+
+	plan, err := spm.planRecenter(currentPrice, cfg.Policy)
+	if err != nil {
+		logger.Warn("⚠️ [%s:%s] [网格居中] 生成计划失败: %v", spm.exchangeName, spm.config.Trading.Symbol, err)
+		return
+	}
+	if plan.ShiftIntervals < cfg.MinShift && -plan.ShiftIntervals < cfg.MinShift {
+		return
+	}
+	spm.applyRecenter(plan)
+}
+
+// planRecenter 生成重新居中计划（调用方需持有 spm.mu）
+func (spm *SuperPositionManager) planRecenter(currentPrice float64, trigger string) (*RecenterPlan, error) {
+	interval := spm.config.Trading.PriceInterval
+	if currentPrice <= 0 {
+		return nil, fmt.Errorf("尚未收到有效的市场价格")
+	}
+	if interval <= 0 || spm.anchorPrice <= 0 {
+		return nil, fmt.Errorf("网格尚未初始化")
+	}
+
+	newAnchor := spm.findNearestGridPrice(currentPrice)
+	plan := &RecenterPlan{
+		Exchange:       spm.exchangeName,
+		Symbol:         spm.config.Trading.Symbol,
+		Trigger:        trigger,
+		CurrentPrice:   currentPrice,
+		OldAnchor:      spm.anchorPrice,
+		NewAnchor:      newAnchor,
+		ShiftIntervals: int(math.Round((newAnchor - spm.anchorPrice) / interval)),
+		Cancel:         []RecenterOrder{},
+		Place:          []RecenterOrder{},
+		CreatedAt:      spm.now(),
+	}
+
+	// 新买单窗口：从新锚点向下 buy_window_size 个网格
+	buyWindow, _ := spm.windowSizes()
+	windowPrices := spm.calculateSlotPrices(newAnchor, buyWindow, "down")
+	inWindow := make(map[float64]bool, len(windowPrices))
+	for _, price := range windowPrices {
+		inWindow[price] = true
+	}
+
+	spm.slots.Range(func(key, value interface{}) bool {
+		price := key.(float64)
+		slot := value.(*InventorySlot)
+		slot.mu.RLock()
+		defer slot.mu.RUnlock()
+
+		if slot.PositionStatus == PositionStatusFilled {
+			plan.HeldSlots++
+			return true
+		}
+		// 只撤完全未成交的空仓买单，部分成交的订单留给订单回调处理
+		if inWindow[price] || slot.OrderSide != "BUY" || slot.OrderID == 0 || slot.OrderFilledQty > 0 ||
+			(slot.OrderStatus != OrderStatusPlaced && slot.OrderStatus != OrderStatusConfirmed) {
+			return true
+		}
+		plan.Cancel = append(plan.Cancel, RecenterOrder{
+			SlotPrice: price,
+			OrderID:   slot.OrderID,
+			Price:     slot.OrderPrice,
+		})
+		return true
+	})
+
+	safetyBuffer := interval * 0.1
+	for _, price := range windowPrices {
+		if price >= currentPrice-safetyBuffer {
+			continue
+		}
+		if value, ok := spm.slots.Load(price); ok {
+			slot := value.(*InventorySlot)
+			slot.mu.RLock()
+			busy := slot.PositionStatus != PositionStatusEmpty || slot.SlotStatus != SlotStatusFree ||
+				slot.OrderID != 0 || slot.ClientOID != ""
+			slot.mu.RUnlock()
+			if busy {
+				continue
+			}
+		}
+		plan.Place = append(plan.Place, RecenterOrder{
+			SlotPrice: price,
+			Price:     price,
+			Quantity:  roundPrice(spm.config.Trading.OrderQuantity/price, spm.quantityDecimals),
+		})
+	}
+
+	sort.Slice(plan.Cancel, func(i, j int) bool { return plan.Cancel[i].SlotPrice > plan.Cancel[j].SlotPrice })
+	return plan, nil
+}
+
+// applyRecenter 执行重新居中计划：撤销闲置买单、移动锚点、发布事件（调用方需持有 spm.mu）
+// 补挂买单由随后的 AdjustOrders 按新窗口完成
+func (spm *SuperPositionManager) applyRecenter(plan *RecenterPlan) {
+	if len(plan.Cancel) > 0 {
+		orderIDs := make([]int64, 0, len(plan.Cancel))
+		for _, o := range plan.Cancel {
+			orderIDs = append(orderIDs, o.OrderID)
+		}
+		if err := spm.executor.BatchCancelOrders(orderIDs); err != nil {
+			logger.Error("❌ [%s:%s] [网格居中] 撤销闲置买单失败: %v", spm.exchangeName, plan.Symbol, err)
+		}
+		for _, o := range plan.Cancel {
+			slot := spm.getOrCreateSlot(o.SlotPrice)
+			slot.mu.Lock()
+			if slot.OrderID == o.OrderID {
+				slot.OrderStatus = OrderStatusCancelRequested
+			}
+			slot.mu.Unlock()
+		}
+	}
+
+	spm.anchorPrice = plan.NewAnchor
+	spm.lastRecenterAt = plan.CreatedAt
+
+	logger.Warn("🎯 [%s:%s] [网格居中] 触发: %s, 锚点 %s -> %s (移动 %d 格), 撤销闲置买单 %d 个, 待补买单 %d 个, 保留持仓槽位 %d 个",
+		plan.Exchange, plan.Symbol, plan.Trigger,
+		formatPrice(plan.OldAnchor, spm.priceDecimals), formatPrice(plan.NewAnchor, spm.priceDecimals),
+		plan.ShiftIntervals, len(plan.Cancel), len(plan.Place), plan.HeldSlots)
+
+	if spm.anchorStorage != nil {
+		if err := spm.anchorStorage.SaveAnchor(plan.Exchange, plan.Symbol, plan.NewAnchor, "recenter"); err != nil {
+			logger.Warn("⚠️ [%s:%s] [网格居中] 保存锚点失败: %v", plan.Exchange, plan.Symbol, err)
+		}
+	}
+
+	if spm.eventBus != nil {
+		spm.eventBus.Publish(&event.Event{
+			Type: event.EventTypeGridRecentered,
+			Data: map[string]interface{}{
+				"exchange":        plan.Exchange,
+				"symbol":          plan.Symbol,
+				"trigger":         plan.Trigger,
+				"price":           plan.CurrentPrice,
+				"old_anchor":      plan.OldAnchor,
+				"new_anchor":      plan.NewAnchor,
+				"shift_intervals": plan.ShiftIntervals,
+				"canceled_orders": len(plan.Cancel),
+				"orders_to_place": len(plan.Place),
+				"held_slots":      plan.HeldSlots,
+			},
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package position_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/exchange"
+	"quantmesh/position"
+	"quantmesh/testutil"
+)
+
+// replayExecutor 直接在回放交易所上下单的执行器
+type replayExecutor struct {
+	ex exchange.IExchange
+}
+
+func (e *replayExecutor) PlaceOrder(req *position.OrderRequest) (*position.Order, error) {
+	o, err := e.ex.PlaceOrder(context.Background(), &exchange.OrderRequest{
+		Symbol:        req.Symbol,
+		Side:          exchange.Side(req.Side),
+		Type:          exchange.OrderTypeLimit,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		ClientOrderID: req.ClientOrderID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &position.Order{OrderID: o.OrderID, ClientOrderID: o.ClientOrderID, Symbol: o.Symbol, Side: string(o.Side),
+		Price: o.Price, Quantity: o.Quantity, Status: string(o.Status), CreatedAt: o.CreatedAt}, nil
+}
+
+func (e *replayExecutor) BatchPlaceOrders(orders []*position.OrderRequest) ([]*position.Order, bool) {
+	return e.BatchPlaceOrdersWithDetails(orders).PlacedOrders, false
+}
+
+func (e *replayExecutor) BatchPlaceOrdersWithDetails(orders []*position.OrderRequest) *position.BatchPlaceOrdersResult {
+	result := &position.BatchPlaceOrdersResult{ReduceOnlyErrors: make(map[string]bool)}
+	for _, req := range orders {
+		if o, err := e.PlaceOrder(req); err == nil {
+			result.PlacedOrders = append(result.PlacedOrders, o)
+		}
+	}
+	return result
+}
+
+func (e *replayExecutor) BatchCancelOrders(orderIDs []int64) error {
+	return e.ex.BatchCancelOrders(context.Background(), "BTCUSDT", orderIDs)
+}
+
+// TestReplayRecordedFillMovesSlot 录制文件中的成交推送（录制时的订单 ID）映射到回放中同价位的挂单后驱动槽位
+func TestReplayRecordedFillMovesSlot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rec.jsonl")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc := json.NewEncoder(file)
+	enc.Encode(&exchange.MarketDataRecord{Type: exchange.RecordTypePrice, Symbol: "BTCUSDT", Price: 1000})
+	enc.Encode(&exchange.MarketDataRecord{Type: exchange.RecordTypeOrder, Symbol: "BTCUSDT", Order: &exchange.OrderUpdate{
+		OrderID: 880001, ClientOrderID: "recorded-run-990", Symbol: "BTCUSDT", Side: exchange.SideBuy,
+		Status: exchange.OrderStatusFilled, Price: 990, Quantity: 0.101, ExecutedQty: 0.101, AvgPrice: 990,
+	}})
+	file.Close()
+
+	cfg := &config.Config{}
+	cfg.App.CurrentExchange = "binance"
+	cfg.Trading.Symbol = "BTCUSDT"
+	cfg.Trading.PriceInterval = 10
+	cfg.Trading.BuyWindowSize = 3
+	cfg.Trading.SellWindowSize = 3
+	cfg.Trading.OrderQuantity = 100
+	cfg.Trading.MinOrderValue = 5
+	cfg.Trading.OrderCleanupThreshold = 100
+	cfg.MarketData.Replay.File = path
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	venue, err := exchange.NewExchange(cfg, config.ReplayExchangeName, "BTCUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spm := position.NewSuperPositionManagerWithDeps(cfg, position.Dependencies{
+		Executor: &replayExecutor{ex: venue},
+		Exchange: testutil.NewFakeExchange("binance", 2, 3),
+	}, 2, 3)
+	if err := spm.Initialize(1000, "1000.00"); err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+
+	venue.StartOrderStream(ctx, func(u interface{}) {
+		update := u.(exchange.OrderUpdate)
+		spm.OnOrderUpdate(position.OrderUpdate{
+			OrderID: update.OrderID, ClientOrderID: update.ClientOrderID, Symbol: update.Symbol,
+			Status: string(update.Status), ExecutedQty: update.ExecutedQty, Price: update.Price,
+			AvgPrice: update.AvgPrice, Side: string(update.Side), Type: string(update.Type),
+		})
+	})
+	// 与线上一致由价格推送驱动挂单：回放先推送价格，网格在 990 挂出买单后再推送录制的成交
+	venue.StartPriceStream(ctx, "BTCUSDT", func(price float64) {
+		if err := spm.AdjustOrders(price); err != nil {
+			t.Errorf("AdjustOrders: %v", err)
+		}
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, s := range spm.GetAllSlotsDetailed() {
+			if s.Price == 990 && s.PositionStatus == position.PositionStatusFilled {
+				if s.PositionQty != 0.101 {
+					t.Fatalf("slot 990 quantity = %v, want 0.101", s.PositionQty)
+				}
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("recorded fill did not move the 990 slot")
+}
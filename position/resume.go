@@ -0,0 +1,135 @@
+package position
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"quantmesh/logger"
+)
+
+// SlotSnapshot 槽位快照（协调重启时写入恢复令牌，新进程据此恢复槽位并接管保留的挂单）
+type SlotSnapshot struct {
+	Price          float64   `json:"price"`
+	PositionQty    float64   `json:"position_qty,omitempty"`
+	CostBasis      float64   `json:"cost_basis,omitempty"`
+	OrderID        int64     `json:"order_id,omitempty"`
+	ClientOID      string    `json:"client_oid,omitempty"`
+	OrderSide      string    `json:"order_side,omitempty"`
+	OrderPrice     float64   `json:"order_price,omitempty"`
+	OrderCreatedAt time.Time `json:"order_created_at,omitempty"`
+}
+
+// ResumeResult 按快照恢复网格的结果
+type ResumeResult struct {
+	Slots   int `json:"slots"`   // 恢复的持仓槽位数
+	Adopted int `json:"adopted"` // 直接接管的挂单数
+	Dropped int `json:"dropped"` // 重启期间已不在交易所的挂单（按退出方式撤销），由订单调整重新挂出
+}
+
+// SnapshotSlots 有持仓或有活跃挂单的槽位快照（按价格从高到低）
+func (spm *SuperPositionManager) SnapshotSlots() []SlotSnapshot {
+	var snapshots []SlotSnapshot
+	spm.slots.Range(func(key, value interface{}) bool {
+		slot := value.(*InventorySlot)
+		slot.mu.RLock()
+		defer slot.mu.RUnlock()
+
+		s := SlotSnapshot{Price: slot.Price}
+		if slot.PositionStatus == PositionStatusFilled && slot.PositionQty > 0 {
+			s.PositionQty = slot.PositionQty
+			s.CostBasis = slot.CostBasis
+		}
+		if slot.OrderID != 0 && isActiveOrderStatus(slot.OrderStatus) {
+			s.OrderID = slot.OrderID
+			s.ClientOID = slot.ClientOID
+			s.OrderSide = slot.OrderSide
+			s.OrderPrice = slot.OrderPrice
+			s.OrderCreatedAt = slot.OrderCreatedAt
+		}
+		if s.PositionQty > 0 || s.OrderID != 0 {
+			snapshots = append(snapshots, s)
+		}
+		return true
+	})
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Price > snapshots[j].Price })
+	return snapshots
+}
+
+// isActiveOrderStatus 订单是否仍挂在交易所上
+func isActiveOrderStatus(status string) bool {
+	switch status {
+	case OrderStatusPlaced, OrderStatusConfirmed, OrderStatusPartiallyFilled:
+		return true
+	}
+	return false
+}
+
+// ResumeSlots 按重启前的快照恢复网格（代替 Initialize）：沿用原锚点和槽位持仓，仍挂在交易所的订单直接接管，不重新挂单
+// openOrders 为交易所当前挂单的订单ID；expectedPosition 为重启前记录的交易所持仓，
+// 与当前持仓不一致（重启期间有成交）时返回错误且不修改任何状态，由调用方按正常流程初始化
+func (spm *SuperPositionManager) ResumeSlots(anchorPrice float64, slots []SlotSnapshot, openOrders map[int64]bool, expectedPosition float64) (*ResumeResult, error) {
+	spm.mu.Lock()
+	defer spm.mu.Unlock()
+
+	if anchorPrice <= 0 {
+		return nil, fmt.Errorf("锚点价格无效: %.8g", anchorPrice)
+	}
+	step := math.Pow10(-spm.quantityDecimals)
+	if current := spm.getExistingPosition(); math.Abs(current-expectedPosition) >= step/2 {
+		return nil, fmt.Errorf("交易所持仓 %.8g 与重启前记录的 %.8g 不一致（重启期间可能有成交）", current, expectedPosition)
+	}
+
+	spm.anchorPrice = anchorPrice
+	spm.lastRecenterAt = spm.now()
+	spm.lastMarketPrice.Store(anchorPrice)
+
+	initialBuyWindow, _ := spm.windowSizes()
+	for _, price := range spm.calculateSlotPrices(anchorPrice, initialBuyWindow, "down") {
+		spm.getOrCreateSlot(price)
+	}
+
+	result := &ResumeResult{}
+	var usedAmount float64
+	for _, s := range slots {
+		slot := spm.getOrCreateSlot(s.Price)
+		slot.mu.Lock()
+		if s.PositionQty > 0 {
+			slot.PositionStatus = PositionStatusFilled
+			slot.PositionQty = s.PositionQty
+			slot.CostBasis = s.CostBasis
+			usedAmount += spm.contract.SettlementValue(s.PositionQty, s.Price)
+			result.Slots++
+		}
+		if s.OrderID != 0 && openOrders[s.OrderID] {
+			slot.OrderID = s.OrderID
+			slot.ClientOID = s.ClientOID
+			slot.OrderSide = s.OrderSide
+			slot.OrderStatus = OrderStatusConfirmed
+			slot.OrderPrice = s.OrderPrice
+			slot.OrderCreatedAt = s.OrderCreatedAt
+			slot.SlotStatus = SlotStatusLocked
+			if s.OrderSide == "BUY" {
+				usedAmount += spm.contract.NotionalToSettlement(spm.config.Trading.OrderQuantity, s.OrderPrice)
+			}
+			result.Adopted++
+		} else {
+			if s.OrderID != 0 {
+				result.Dropped++
+			}
+			if s.PositionQty > 0 {
+				slot.OrderSide = "SELL"
+			}
+		}
+		slot.mu.Unlock()
+	}
+
+	if usedAmount > 0 {
+		spm.allocationManager.SetUsedAmount(spm.exchangeName, spm.config.Trading.Symbol, usedAmount)
+	}
+	spm.isInitialized.Store(true)
+	logger.Info("♻️ [%s:%s] 按重启前快照恢复网格: 锚点 %s, 持仓槽位 %d, 接管挂单 %d, 待重新挂出 %d",
+		spm.exchangeName, spm.config.Trading.Symbol, formatPrice(anchorPrice, spm.priceDecimals), result.Slots, result.Adopted, result.Dropped)
+	return result, nil
+}
@@ -0,0 +1,212 @@
+package position
+
+import (
+	"fmt"
+	"time"
+
+	"quantmesh/logger"
+)
+
+// 单槽位人工操作
+const (
+	SlotActionLock   = "lock"   // 锁定：AdjustOrders 不再为该槽位挂单（已有订单不受影响）
+	SlotActionUnlock = "unlock" // 解除锁定
+	SlotActionCancel = "cancel" // 撤销槽位当前订单
+	SlotActionSell   = "sell"   // 强制卖出槽位持仓（撤销现有订单后以略低于市价的限价平仓）
+)
+
+// forceSellDiscount 强制卖出的限价相对最新价的比例（与全平仓一致，确保成交）
+const forceSellDiscount = 0.99
+
+// slotLock 槽位人工锁定记录
+type slotLock struct {
+	Operator string
+	Reason   string
+	At       time.Time
+}
+
+// SlotActionResult 单槽位人工操作结果
+type SlotActionResult struct {
+	Exchange        string           `json:"exchange"`
+	Symbol          string           `json:"symbol"`
+	Price           float64          `json:"price"`
+	Action          string           `json:"action"`
+	Locked          bool             `json:"locked"`
+	CanceledOrderID int64            `json:"canceled_order_id,omitempty"`
+	SellOrderID     int64            `json:"sell_order_id,omitempty"`
+	SellPrice       float64          `json:"sell_price,omitempty"`
+	SellQty         float64          `json:"sell_qty,omitempty"`
+	Slot            DetailedSlotData `json:"slot"`
+	Operator        string           `json:"operator"`
+	Reason          string           `json:"reason"`
+	Timestamp       time.Time        `json:"timestamp"`
+}
+
+// ApplySlotAction 对单个槽位执行人工操作（lock/unlock/cancel/sell），用于处理个别有问题的价位而不必暂停整个网格
+func (spm *SuperPositionManager) ApplySlotAction(price float64, action, operator, reason string) (*SlotActionResult, error) {
+	if price <= 0 {
+		return nil, fmt.Errorf("槽位价格必须大于0")
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("必须填写操作原因")
+	}
+	price = roundPrice(price, spm.priceDecimals)
+	value, exists := spm.slots.Load(price)
+	if !exists && action != SlotActionLock && action != SlotActionUnlock {
+		return nil, fmt.Errorf("槽位 %s 不存在", formatPrice(price, spm.priceDecimals))
+	}
+
+	result := &SlotActionResult{
+		Exchange:  spm.exchangeName,
+		Symbol:    spm.config.Trading.Symbol,
+		Price:     price,
+		Action:    action,
+		Operator:  operator,
+		Reason:    reason,
+		Timestamp: spm.now(),
+	}
+
+	var err error
+	switch action {
+	case SlotActionLock:
+		spm.slotLocks.Store(price, slotLock{Operator: operator, Reason: reason, At: result.Timestamp})
+	case SlotActionUnlock:
+		if _, loaded := spm.slotLocks.LoadAndDelete(price); !loaded {
+			return nil, fmt.Errorf("槽位 %s 未被锁定", formatPrice(price, spm.priceDecimals))
+		}
+	case SlotActionCancel:
+		result.CanceledOrderID, err = spm.cancelSlotOrder(price, value.(*InventorySlot))
+	case SlotActionSell:
+		err = spm.forceSellSlot(price, value.(*InventorySlot), result)
+	default:
+		return nil, fmt.Errorf("不支持的槽位操作: %s", action)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Locked = spm.IsSlotLocked(price)
+	if value, ok := spm.slots.Load(price); ok {
+		slot := value.(*InventorySlot)
+		slot.mu.RLock()
+		result.Slot = snapshotSlot(price, slot)
+		slot.mu.RUnlock()
+		result.Slot.ManualLocked = result.Locked
+	}
+
+	logger.Warn("🛠️ [%s:%s] [槽位操作] %s 槽位 %s (锁定: %v, 操作人: %s, 原因: %s)",
+		result.Exchange, result.Symbol, action, formatPrice(price, spm.priceDecimals), result.Locked, operator, reason)
+	return result, nil
+}
+
+// IsSlotLocked 槽位是否被人工锁定
+func (spm *SuperPositionManager) IsSlotLocked(price float64) bool {
+	_, locked := spm.slotLocks.Load(price)
+	return locked
+}
+
+// cancelSlotOrder 撤销槽位当前订单，槽位在收到撤单推送后释放
+func (spm *SuperPositionManager) cancelSlotOrder(price float64, slot *InventorySlot) (int64, error) {
+	slot.mu.RLock()
+	orderID, status := slot.OrderID, slot.OrderStatus
+	slot.mu.RUnlock()
+	if orderID == 0 || !hasActiveOrder(status) {
+		return 0, fmt.Errorf("槽位 %s 没有活跃订单", formatPrice(price, spm.priceDecimals))
+	}
+
+	if err := spm.executor.BatchCancelOrders([]int64{orderID}); err != nil {
+		return 0, fmt.Errorf("撤销订单 %d 失败: %w", orderID, err)
+	}
+	slot.mu.Lock()
+	if slot.OrderID == orderID {
+		slot.OrderStatus = OrderStatusCancelRequested
+	}
+	slot.mu.Unlock()
+	return orderID, nil
+}
+
+// forceSellSlot 强制卖出槽位持仓：撤销现有订单后立即挂出只减仓限价单（不使用 PostOnly）
+func (spm *SuperPositionManager) forceSellSlot(price float64, slot *InventorySlot, result *SlotActionResult) error {
+	lastPrice, _ := spm.lastMarketPrice.Load().(float64)
+	if lastPrice <= 0 {
+		return fmt.Errorf("尚未收到市场价格，无法强制卖出")
+	}
+
+	slot.mu.Lock()
+	if slot.PositionStatus != PositionStatusFilled || slot.PositionQty <= 0 {
+		slot.mu.Unlock()
+		return fmt.Errorf("槽位 %s 没有持仓", formatPrice(price, spm.priceDecimals))
+	}
+	if slot.SlotStatus == SlotStatusPending {
+		slot.mu.Unlock()
+		return fmt.Errorf("槽位 %s 正在下单，请稍后重试", formatPrice(price, spm.priceDecimals))
+	}
+	oldOrderID := int64(0)
+	if slot.OrderID > 0 && hasActiveOrder(slot.OrderStatus) {
+		oldOrderID = slot.OrderID
+	}
+	prevStatus := slot.SlotStatus
+	slot.SlotStatus = SlotStatusPending
+	req := &OrderRequest{
+		Symbol:        spm.config.Trading.Symbol,
+		Side:          "SELL",
+		Price:         roundPrice(lastPrice*forceSellDiscount, spm.priceDecimals),
+		Quantity:      slot.PositionQty,
+		PriceDecimals: spm.priceDecimals,
+		ReduceOnly:    true,
+		PostOnly:      false,
+		ClientOrderID: spm.generateClientOrderID(price, "SELL"),
+	}
+	slot.mu.Unlock()
+
+	if oldOrderID > 0 {
+		if err := spm.executor.BatchCancelOrders([]int64{oldOrderID}); err != nil {
+			slot.mu.Lock()
+			slot.SlotStatus = prevStatus
+			slot.mu.Unlock()
+			return fmt.Errorf("撤销现有订单 %d 失败: %w", oldOrderID, err)
+		}
+		result.CanceledOrderID = oldOrderID
+	}
+
+	// 先把槽位切换到新订单：原订单的撤单推送因 ClientOID 不匹配被忽略，
+	// 新订单在下单返回前就成交的推送也能正常处理
+	slot.mu.Lock()
+	slot.OrderID = 0
+	slot.ClientOID = req.ClientOrderID
+	slot.OrderSide = "SELL"
+	slot.OrderStatus = OrderStatusPlaced
+	slot.OrderPrice = req.Price
+	slot.OrderFilledQty = 0
+	slot.OrderCreatedAt = spm.now()
+	slot.mu.Unlock()
+
+	order, err := spm.executor.PlaceOrder(req)
+
+	slot.mu.Lock()
+	defer slot.mu.Unlock()
+	if slot.ClientOID != req.ClientOrderID {
+		// 已被成交推送终结
+		if err == nil {
+			result.SellOrderID = order.OrderID
+		}
+		result.SellPrice = req.Price
+		result.SellQty = req.Quantity
+		return nil
+	}
+	if err != nil {
+		slot.OrderID = 0
+		slot.ClientOID = ""
+		slot.OrderStatus = OrderStatusNotPlaced
+		slot.SlotStatus = SlotStatusFree
+		return fmt.Errorf("强制卖单下单失败: %w", err)
+	}
+	if slot.OrderID == 0 {
+		slot.OrderID = order.OrderID
+	}
+	slot.SlotStatus = SlotStatusLocked
+	result.SellOrderID = order.OrderID
+	result.SellPrice = req.Price
+	result.SellQty = req.Quantity
+	return nil
+}
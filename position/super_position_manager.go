@@ -55,6 +55,7 @@ type OrderRequest struct {
 	PriceDecimals int    // 价格小数位数（用于格式化价格字符串）
 	ReduceOnly    bool   // 是否只减仓（平仓单）
 	PostOnly      bool   // 是否只做 Maker（Post Only）
+	StrictPost    bool   // PostOnly 多次被拒后不降级为普通单（高波动保护期间避免吃单）
 	ClientOrderID string // 自定义订单ID
 	StrategyName  string // 策略名称（可选，用于日志追踪）
 	StrategyType  string // 策略类型（可选，如 "grid", "dca", "martingale"）
@@ -89,6 +90,9 @@ const (
 	PositionStatusFilled = "FILLED" // 有仓
 )
 
+// costBasisPersistDelay 持仓成本持久化的合并窗口：窗口内的多次成交只写一次数据库
+const costBasisPersistDelay = time.Second
+
 // 槽位锁定状态
 const (
 	SlotStatusFree    = "FREE"    // 空闲，可操作
@@ -103,6 +107,7 @@ type InventorySlot struct {
 	// 持仓信息
 	PositionStatus string  // 持仓状态：空仓/有仓
 	PositionQty    float64 // 持仓数量（支持小数点后3位）
+	CostBasis      float64 // 持仓总成本（成交均价 × 数量 + 手续费），卖出时按比例减少
 
 	// 订单信息 (买卖互斥)
 	OrderID        int64     // 订单ID
@@ -119,9 +124,24 @@ type InventorySlot struct {
 	// PostOnly失败计数（连续失败3次后降级为普通单）
 	PostOnlyFailCount int
 
+	// 最近一个已终结（成交/撤销）订单的 ClientOID，用于过滤重复或乱序的推送
+	FinalizedClientOID string
+
+	// 排队过深被撤单后，下次挂单向盘口内侧改善的价位数（使用一次后清零）
+	QueueImproveTicks int
+
 	mu sync.RWMutex // 槽位级别的锁（细粒度锁）
 }
 
+// entryPrice 槽位持仓的平均成本价（含手续费），没有成本记录时退回槽位价格
+// 调用方需持有 slot.mu
+func (slot *InventorySlot) entryPrice() float64 {
+	if slot.PositionQty > 0 && slot.CostBasis > 0 {
+		return slot.CostBasis / slot.PositionQty
+	}
+	return slot.Price
+}
+
 // PositionInfo 持仓信息（简化版，避免循环导入）
 type PositionInfo struct {
 	Symbol string
@@ -156,6 +176,19 @@ type ReconciliationStorage interface {
 	GetReconciliationCount(symbol string) (int64, error)
 }
 
+// CostBasisStorage 持仓成本存储接口（避免循环导入）
+// 按交易所+交易对保存汇总成本，重启恢复持仓时用于还原各槽位成本
+type CostBasisStorage interface {
+	SaveCostBasis(exchange, symbol string, quantity, totalCost float64) error
+	GetCostBasis(exchange, symbol string) (quantity, totalCost float64, found bool, err error)
+}
+
+// FeeRateProvider 实时手续费率提供者（避免循环导入）
+// 网格挂单为限价单，按 Maker 费率计入持仓成本
+type FeeRateProvider interface {
+	MakerFeeRate() float64
+}
+
 // ITrendDetector 趋势检测器接口（避免循环导入）
 type ITrendDetector interface {
 	GetCurrentTrend() string
@@ -176,6 +209,8 @@ type SuperPositionManager struct {
 	priceDecimals int
 	// 数量精度（从交易所获取）
 	quantityDecimals int
+	// 合约规格（U 本位 / 币本位），统一数量、价值和盈亏换算
+	contract utils.ContractSpec
 
 	// 库存槽位：价格 -> 槽位
 	slots sync.Map // map[float64]*InventorySlot
@@ -189,6 +224,31 @@ type SuperPositionManager struct {
 	peakPnL       float64        // 记录最高未实现盈亏（用于回撤止盈）
 	trendDetector ITrendDetector // 趋势检测器
 
+	// 挂单窗口覆盖（*windowProfile，市场状态切换时设置）
+	windowOverride atomic.Value
+
+	// 加密区间（[]PriceZone，支撑位附近在网格价格之间插入半格买单槽位）
+	denseZones atomic.Value
+
+	// 新开仓暂停来源（source -> true），任一来源存在时不再创建买单
+	buyHalts sync.Map
+
+	// 挂单保护（*quoteGuard，高波动期间强制 PostOnly 并拉开挂单距离）
+	quoteGuard atomic.Value
+
+	// 挂单窗口/持仓层数上限（source -> WindowCap，低流动性时段降杠杆等）
+	windowCaps sync.Map
+
+	// 人工锁定的槽位（price -> slotLock），AdjustOrders 不为其挂单
+	slotLocks sync.Map
+
+	// 网格重新居中：上次居中（或 time 策略上次检查）时间、锚点存储（可选）
+	lastRecenterAt time.Time
+	anchorStorage  AnchorStorage
+
+	// 挂单排队位置估算器（未启用时为 nil）
+	queueEstimator *QueueEstimator
+
 	// 资金分配管理器
 	allocationManager *AllocationManager
 
@@ -204,12 +264,23 @@ type SuperPositionManager struct {
 	// 交易存储（可选，用于保存交易记录）
 	tradeStorage TradeStorage
 
+	// 持仓成本存储（可选，用于重启后恢复成本价）
+	costBasisStorage CostBasisStorage
+	costBasisPending atomic.Bool // 已安排延迟持久化，尚未写入
+
+	// 实时手续费率（可选，未设置时使用配置费率）
+	feeRateProvider FeeRateProvider
+
 	// 初始化标志
 	isInitialized atomic.Bool
 
 	// 暂停标志
 	isPaused atomic.Bool
 
+	// 时钟（默认 time.Now，测试中可注入确定性时钟）
+	now       func() time.Time
+	afterFunc func(d time.Duration, f func())
+
 	mu sync.RWMutex // 全局锁（用于关键操作）
 }
 
@@ -218,8 +289,28 @@ type EventBus interface {
 	Publish(evt *event.Event)
 }
 
+// Dependencies 仓位管理器的外部依赖
+// 所有依赖均为接口，便于在测试中注入 testutil.FakeExchange / testutil.FakeExecutor
+type Dependencies struct {
+	Executor      OrderExecutorInterface          // 订单执行器（必填）
+	Exchange      IExchange                       // 交易所（必填）
+	TradeStorage  TradeStorage                    // 交易存储（可选）
+	EventBus      EventBus                        // 事件总线（可选）
+	TrendDetector ITrendDetector                  // 趋势检测器（可选）
+	Now           func() time.Time                // 时钟（可选，默认 time.Now）
+	AfterFunc     func(d time.Duration, f func()) // 延迟执行（可选，默认 time.AfterFunc；测试中可由假时钟驱动）
+}
+
 // NewSuperPositionManager 创建超级仓位管理器
 func NewSuperPositionManager(cfg *config.Config, executor OrderExecutorInterface, exchange IExchange, priceDecimals, quantityDecimals int) *SuperPositionManager {
+	return NewSuperPositionManagerWithDeps(cfg, Dependencies{
+		Executor: executor,
+		Exchange: exchange,
+	}, priceDecimals, quantityDecimals)
+}
+
+// NewSuperPositionManagerWithDeps 使用完整依赖集合创建超级仓位管理器
+func NewSuperPositionManagerWithDeps(cfg *config.Config, deps Dependencies, priceDecimals, quantityDecimals int) *SuperPositionManager {
 	marginLockSec := cfg.Trading.MarginLockDurationSec
 	if marginLockSec <= 0 {
 		marginLockSec = 10 // 默认10秒
@@ -231,22 +322,39 @@ func NewSuperPositionManager(cfg *config.Config, executor OrderExecutorInterface
 		exchangeName = "binance" // 默认值
 	}
 
+	now := deps.Now
+	if now == nil {
+		now = time.Now
+	}
+	afterFunc := deps.AfterFunc
+	if afterFunc == nil {
+		afterFunc = func(d time.Duration, f func()) { time.AfterFunc(d, f) }
+	}
+
 	spm := &SuperPositionManager{
 		config:             cfg,
-		executor:           executor,
-		exchange:           exchange,
+		executor:           deps.Executor,
+		exchange:           deps.Exchange,
 		exchangeName:       exchangeName,
 		insufficientMargin: false,
 		marginLockDuration: time.Duration(marginLockSec) * time.Second,
 		priceDecimals:      priceDecimals,
 		quantityDecimals:   quantityDecimals,
+		contract:           utils.NewContractSpec(cfg.Trading.ContractType, cfg.Trading.ContractSize),
 		peakPnL:            -math.MaxFloat64, // 初始化为一个极小值
-		tradeStorage:       nil,              // 默认不保存交易记录，可通过 SetTradeStorage 设置
+		tradeStorage:       deps.TradeStorage, // 未设置时不保存交易记录，可通过 SetTradeStorage 设置
+		eventBus:           deps.EventBus,
+		trendDetector:      deps.TrendDetector,
 		allocationManager:  NewAllocationManager(cfg), // 初始化资金分配管理器
+		now:                now,
+		afterFunc:          afterFunc,
+	}
+	if cfg.Trading.QueuePosition.Enabled {
+		spm.queueEstimator = NewQueueEstimator()
 	}
 	spm.totalBuyQty.Store(0.0)
 	spm.totalSellQty.Store(0.0)
-	spm.lastReconcileTime.Store(time.Now())
+	spm.lastReconcileTime.Store(now())
 	spm.lastMarketPrice.Store(0.0)
 	return spm
 }
@@ -278,6 +386,16 @@ func (spm *SuperPositionManager) SetTradeStorage(storage TradeStorage) {
 	spm.tradeStorage = storage
 }
 
+// SetCostBasisStorage 设置持仓成本存储（需在 Initialize 之前调用，才能恢复重启前的成本）
+func (spm *SuperPositionManager) SetCostBasisStorage(storage CostBasisStorage) {
+	spm.costBasisStorage = storage
+}
+
+// SetFeeRateProvider 设置实时手续费率提供者
+func (spm *SuperPositionManager) SetFeeRateProvider(provider FeeRateProvider) {
+	spm.feeRateProvider = provider
+}
+
 // SetTrendDetector 设置趋势检测器
 func (spm *SuperPositionManager) SetTrendDetector(td ITrendDetector) {
 	spm.mu.Lock()
@@ -296,6 +414,7 @@ func (spm *SuperPositionManager) Initialize(initialPrice float64, initialPriceSt
 
 	// 1. 设置价格锚点（精度信息已经在构造函数中设置，从交易所获取）
 	spm.anchorPrice = initialPrice
+	spm.lastRecenterAt = spm.now()
 	spm.lastMarketPrice.Store(initialPrice) // 初始化最后市场价格
 	logger.Info("✅ 价格锚点已设置: %s, 价格精度:%d, 数量精度:%d",
 		formatPrice(initialPrice, spm.priceDecimals), spm.priceDecimals, spm.quantityDecimals)
@@ -305,7 +424,8 @@ func (spm *SuperPositionManager) Initialize(initialPrice float64, initialPriceSt
 	logger.Info("✅ 初始网格价格: %s (使用锚点价格)", formatPrice(initialGridPrice, spm.priceDecimals))
 
 	// 4. 使用统一的槽位价格计算方法创建初始槽位
-	slotPrices := spm.calculateSlotPrices(initialGridPrice, spm.config.Trading.BuyWindowSize, "down")
+	initialBuyWindow, _ := spm.windowSizes()
+	slotPrices := spm.calculateSlotPrices(initialGridPrice, initialBuyWindow, "down")
 	for _, price := range slotPrices {
 		spm.getOrCreateSlot(price)
 	}
@@ -477,19 +597,21 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 
 	// 检查保证金不足状态
 	if spm.insufficientMargin {
-		if time.Since(spm.marginLockTime) >= spm.marginLockDuration {
+		if spm.now().Sub(spm.marginLockTime) >= spm.marginLockDuration {
 			logger.Info("✅ [保证金恢复] 锁定时间已过，恢复下单功能")
 			spm.insufficientMargin = false
 		} else {
-			remainingTime := spm.marginLockDuration - time.Since(spm.marginLockTime)
+			remainingTime := spm.marginLockDuration - spm.now().Sub(spm.marginLockTime)
 			logger.Warn("⏸️ [暂停下单] 保证金不足，暂停下单中... (剩余时间: %.0f秒)", remainingTime.Seconds())
 			return nil
 		}
 	}
 
+	// 价格远离锚点时按配置的策略重新居中（撤销窗口外的闲置买单）
+	spm.maybeRecenter(currentPrice)
+
 	// 计算需要监控的价格范围
-	buyWindowSize := spm.config.Trading.BuyWindowSize
-	sellWindowSize := spm.config.Trading.SellWindowSize
+	buyWindowSize, sellWindowSize := spm.windowSizes()
 	priceInterval := spm.config.Trading.PriceInterval
 
 	// 动态计算网格价格
@@ -536,8 +658,8 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 		remainingOrders = 0
 	}
 
-	// 买单允许的新增数量
-	allowedNewBuyOrders := buyWindowSize
+	// 买单允许的新增数量（加密区间会在窗口内追加槽位）
+	allowedNewBuyOrders := len(slotPrices)
 	if allowedNewBuyOrders > remainingOrders {
 		allowedNewBuyOrders = remainingOrders
 	}
@@ -547,6 +669,10 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 
 	// 趋势过滤与层数限制预检查
 	skipBuying := false
+	if spm.isBuyHalted() {
+		logger.Debug("🛑 [%s] 新开仓已暂停 (来源: %v)，跳过买单", spm.config.Trading.Symbol, spm.BuyHaltSources())
+		skipBuying = true
+	}
 	if spm.config.Trading.GridRiskControl.Enabled {
 		// 趋势过滤
 		if spm.config.Trading.GridRiskControl.TrendFilterEnabled && spm.trendDetector != nil {
@@ -567,11 +693,24 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 			}
 		}
 	}
+	if capLayers := spm.effectiveWindowCap().MaxLayers; capLayers > 0 && !skipBuying {
+		if currentLayers := spm.GetActiveLayers(); currentLayers >= capLayers {
+			logger.Debug("🚫 [层数上限] 当前持仓层数 (%d) 已达到上限 (%d, 来源: %v)，暂停买入",
+				currentLayers, capLayers, spm.GetWindowCaps())
+			skipBuying = true
+		}
+	}
+
+	// 挂单保护：新挂单强制严格 PostOnly，并与当前价保持最小距离
+	_, guardOffset, guarded := spm.GetQuoteGuard()
 
 	for _, price := range slotPrices {
 		if skipBuying {
 			break
 		}
+		if spm.IsSlotLocked(price) {
+			continue
+		}
 		slot := spm.getOrCreateSlot(price)
 		slot.mu.Lock()
 
@@ -611,8 +750,13 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 				slot.mu.Unlock()
 				continue
 			}
+			// 挂单保护：买单与当前价保持最小距离
+			if guarded && price > currentPrice-guardOffset {
+				slot.mu.Unlock()
+				continue
+			}
 
-			quantity := spm.config.Trading.OrderQuantity / price
+			quantity := spm.contract.QuantityForNotional(spm.config.Trading.OrderQuantity, price)
 			// 使用从交易所获取的数量精度
 			quantity = roundPrice(quantity, spm.quantityDecimals)
 
@@ -620,18 +764,18 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 			if quantity <= 0 && spm.quantityDecimals >= 0 {
 				minQty := math.Pow10(-spm.quantityDecimals)
 				logger.Error("🚨 [%s] 下单数量过小 (%.8f)，低于交易所最小精度 (%.8f)，交易已自动暂停！请在配置中调大 order_quantity", 
-					spm.config.Trading.Symbol, spm.config.Trading.OrderQuantity/price, minQty)
+					spm.config.Trading.Symbol, spm.contract.QuantityForNotional(spm.config.Trading.OrderQuantity, price), minQty)
 				
 				// 发布事件
 				if spm.eventBus != nil {
 					spm.eventBus.Publish(&event.Event{
 						Type:      event.EventTypePrecisionAdjustment,
-						Timestamp: time.Now(),
+						Timestamp: spm.now(),
 						Data: map[string]interface{}{
 							"symbol":           spm.config.Trading.Symbol,
 							"exchange":         spm.exchangeName,
 							"order_quantity":   spm.config.Trading.OrderQuantity,
-							"calculated_qty":   spm.config.Trading.OrderQuantity / price,
+							"calculated_qty":   spm.contract.QuantityForNotional(spm.config.Trading.OrderQuantity, price),
 							"min_qty":          minQty,
 							"price":            price,
 							"action":           "pause",
@@ -652,16 +796,17 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 			// 🔥 锁定槽位：标记为PENDING状态，防止并发操作
 			slot.SlotStatus = SlotStatusPending
 
-			// 检查PostOnly失败计数，失败3次后不再使用PostOnly
-			usePostOnly := slot.PostOnlyFailCount < 3
+			// 检查PostOnly失败计数，失败3次后不再使用PostOnly（挂单保护期间始终使用）
+			usePostOnly := slot.PostOnlyFailCount < 3 || guarded
 
 			ordersToPlace = append(ordersToPlace, &OrderRequest{
 				Symbol:        spm.config.Trading.Symbol,
 				Side:          "BUY",
-				Price:         price,
+				Price:         spm.queueImprovedPrice(slot, "BUY", price, currentPrice-safetyBuffer),
 				Quantity:      quantity,
 				PriceDecimals: spm.priceDecimals,
 				PostOnly:      usePostOnly,
+				StrictPost:    guarded,
 				ClientOrderID: clientOID,
 			})
 			buyOrdersToCreate++
@@ -684,6 +829,9 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 
 	spm.slots.Range(func(key, value interface{}) bool {
 		slotPrice := key.(float64) // 槽位Key = 买入价
+		if spm.IsSlotLocked(slotPrice) {
+			return true
+		}
 		slot := value.(*InventorySlot)
 		slot.mu.Lock()
 		defer slot.mu.Unlock()
@@ -702,8 +850,13 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 				return true
 			}
 
+			// 挂单保护：卖单与当前价保持最小距离
+			if guarded && sellPrice < currentPrice+guardOffset {
+				return true
+			}
+
 			// 最小名义价值检查
-			orderValue := sellPrice * slot.PositionQty
+			orderValue := spm.contract.Notional(slot.PositionQty, sellPrice)
 			minValue := spm.config.Trading.MinOrderValue
 			if minValue <= 0 {
 				minValue = 6.0
@@ -767,8 +920,9 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 
 			// 🔥 立即锁定槽位：标记为PENDING状态，防止并发操作
 			slot.SlotStatus = SlotStatusPending
-			// 检查PostOnly失败计数，失败3次后不再使用PostOnly
-			usePostOnly := slot.PostOnlyFailCount < 3
+			// 检查PostOnly失败计数，失败3次后不再使用PostOnly（挂单保护期间始终使用）
+			usePostOnly := slot.PostOnlyFailCount < 3 || guarded
+			candidate.SellPrice = spm.queueImprovedPrice(slot, "SELL", candidate.SellPrice, candidate.SlotPrice)
 			slot.mu.Unlock()
 
 			// 生成 ClientOrderID (注意：使用 SlotPrice 即买入价作为标识)
@@ -785,7 +939,7 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 				if spm.eventBus != nil {
 					spm.eventBus.Publish(&event.Event{
 						Type:      event.EventTypePrecisionAdjustment,
-						Timestamp: time.Now(),
+						Timestamp: spm.now(),
 						Data: map[string]interface{}{
 							"symbol":           spm.config.Trading.Symbol,
 							"exchange":         spm.exchangeName,
@@ -812,6 +966,7 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 				PriceDecimals: spm.priceDecimals,
 				ReduceOnly:    true,
 				PostOnly:      usePostOnly,
+				StrictPost:    guarded,
 				ClientOrderID: clientOID, // 🔥
 			})
 			sellOrdersToCreate++
@@ -848,7 +1003,7 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 		// 过滤掉超出资金分配的订单
 		var validOrders []*OrderRequest
 		for _, req := range ordersToPlace {
-			orderAmount := req.Quantity * req.Price
+			orderAmount := spm.contract.SettlementValue(req.Quantity, req.Price)
 			err := spm.allocationManager.CheckAndReserve(
 				spm.exchangeName,
 				spm.config.Trading.Symbol,
@@ -895,7 +1050,7 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 		if result.HasMarginError {
 			logger.Warn("⚠️ [保证金不足] 检测到保证金不足错误，暂停下单 %d 秒", int(spm.marginLockDuration.Seconds()))
 			spm.insufficientMargin = true
-			spm.marginLockTime = time.Now()
+			spm.marginLockTime = spm.now()
 			spm.CancelAllBuyOrders()
 
 			// 发送保证金不足告警事件
@@ -920,6 +1075,7 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 		}
 
 		// 🔥 处理 ReduceOnly 错误：清空对应槽位的持仓
+		costCleared := false
 		for clientOID := range result.ReduceOnlyErrors {
 			price, side, valid := spm.parseClientOrderID(clientOID)
 			if valid && side == "SELL" {
@@ -928,14 +1084,19 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 				if slot.PositionStatus == PositionStatusFilled {
 					logger.Warn("⚠️ [ReduceOnly错误处理] 清空槽位持仓: 价格=%s, 原持仓=%.4f",
 						formatPrice(price, spm.priceDecimals), slot.PositionQty)
-					// 清空持仓状态
+					// 清空持仓状态（成本一并清零，否则下一次买入会叠加在旧成本上）
 					slot.PositionStatus = PositionStatusEmpty
 					slot.PositionQty = 0
+					slot.CostBasis = 0
 					slot.SlotStatus = SlotStatusFree
+					costCleared = true
 				}
 				slot.mu.Unlock()
 			}
 		}
+		if costCleared {
+			spm.persistCostBasis()
+		}
 
 		// 🔥 释放未成功提交订单的槽位锁和资金
 		for _, req := range ordersToPlace {
@@ -954,7 +1115,7 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 					
 					// 🔥 释放预留的资金（只有买单需要释放，卖单不占用资金）
 					if side == "BUY" {
-						orderAmount := req.Quantity * req.Price
+						orderAmount := spm.contract.SettlementValue(req.Quantity, req.Price)
 						if orderAmount > 0 {
 							spm.allocationManager.Release(spm.exchangeName, spm.config.Trading.Symbol, orderAmount)
 							logger.Debug("💰 [资金释放] 订单提交失败，释放预留资金: %.2f USDT", orderAmount)
@@ -1011,7 +1172,7 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 				slot.OrderSide = side // "BUY" or "SELL"
 				slot.OrderStatus = OrderStatusPlaced
 				slot.OrderPrice = ord.Price
-				slot.OrderCreatedAt = time.Now()
+				slot.OrderCreatedAt = spm.now()
 				// 🔥 订单提交成功，设置为LOCKED状态
 				slot.SlotStatus = SlotStatusLocked
 				// 注意：不在这里重置PostOnlyFailCount，因为订单可能立即被撤销
@@ -1043,9 +1204,24 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 	}
 
 	slot := spm.getOrCreateSlot(price)
+	// 成本变化后在释放槽位锁之后再持久化（持久化需要遍历所有槽位）
+	costChanged := false
+	defer func() {
+		if costChanged {
+			spm.persistCostBasis()
+		}
+	}()
 	slot.mu.Lock()
 	defer slot.mu.Unlock()
 
+	// 🔥 重复/乱序推送过滤：订单已终结（成交或撤销）后再次收到同一 ClientOID 的推送，直接忽略
+	// 否则重复的 FILLED 推送会被当作新订单再次累加持仓
+	if slot.ClientOID == "" && update.ClientOrderID != "" && update.ClientOrderID == slot.FinalizedClientOID {
+		logger.Debug("⏭️ [重复推送] 槽位 %.2f: 订单已终结，忽略推送 (ClientOID: %s, 状态: %s)",
+			price, update.ClientOrderID, update.Status)
+		return
+	}
+
 	// 校验：确保这个更新属于当前的订单 (防止旧订单的延迟推送干扰新订单)
 	// 优先使用 ClientOrderID 匹配 (某些交易所如 Gate.io 的 OrderID 可能略有差异)
 	if slot.ClientOID != "" && slot.ClientOID != update.ClientOrderID {
@@ -1083,9 +1259,23 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 
 		slot.OrderFilledQty = update.ExecutedQty
 
+		// 成交价：优先使用成交均价，其次订单价格，最后槽位价格
+		fillPrice := update.AvgPrice
+		if fillPrice <= 0 {
+			fillPrice = update.Price
+		}
+		if fillPrice <= 0 {
+			fillPrice = slot.OrderPrice
+		}
+		if fillPrice <= 0 {
+			fillPrice = slot.Price
+		}
+
 		// 根据方向更新持仓
 		if side == "BUY" {
 			if deltaQty > 0 {
+				slot.CostBasis += fillPrice * deltaQty * (1 + spm.feeRate())
+				costChanged = true
 				slot.PositionQty += deltaQty
 				// 累加统计
 				oldTotal := spm.totalBuyQty.Load().(float64)
@@ -1095,6 +1285,7 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 			if update.Status == "FILLED" {
 				slot.OrderStatus = OrderStatusNotPlaced // 重置订单状态
 				slot.OrderID = 0
+				slot.FinalizedClientOID = update.ClientOrderID
 				slot.ClientOID = ""
 				slot.OrderSide = "" // 🔥 清除订单方向，避免误判
 				slot.OrderFilledQty = 0
@@ -1106,7 +1297,7 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 				slot.PostOnlyFailCount = 0
 				
 				// 🔥 释放资金：买单成交后，资金已转换为持仓，释放预留的资金
-				orderAmount := slot.OrderPrice * update.ExecutedQty
+				orderAmount := spm.contract.SettlementValue(update.ExecutedQty, slot.OrderPrice)
 				if orderAmount > 0 {
 					spm.allocationManager.Release(spm.exchangeName, spm.config.Trading.Symbol, orderAmount)
 					logger.Debug("💰 [资金释放] 买单成交，释放资金: %.2f USDT", orderAmount)
@@ -1123,6 +1314,13 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 
 		} else { // SELL
 			if deltaQty > 0 {
+				// 按卖出比例扣减成本，剩余持仓的成本价保持不变
+				if slot.PositionQty > deltaQty {
+					slot.CostBasis *= (slot.PositionQty - deltaQty) / slot.PositionQty
+				} else {
+					slot.CostBasis = 0
+				}
+				costChanged = true
 				slot.PositionQty -= deltaQty
 				if slot.PositionQty < 0 {
 					slot.PositionQty = 0
@@ -1151,12 +1349,13 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 					} else {
 						// 计算盈亏：(卖出价格 - 买入价格) * 数量
 						// 注意：对于USDT本位合约（如BTCUSDT），价格是USDT，数量是BTC，盈亏单位是USDT
-						pnl := (sellPrice - buyPrice) * deltaQty
+						// 币本位合约（如BTCUSD）数量为张数，盈亏 = 张数 × 面值 × (1/买入价 - 1/卖出价)，单位是BTC
+						pnl := spm.contract.PnL(buyPrice, sellPrice, deltaQty)
 
 						// 🔥 添加合理性检查：如果盈亏异常大，记录警告
 						// 对于BTCUSDT，如果价格差是100 USDT，数量是0.01 BTC，盈亏应该是1 USDT
 						// 如果盈亏超过订单金额的50%，可能是计算错误
-						orderAmount := buyPrice * deltaQty
+						orderAmount := spm.contract.SettlementValue(deltaQty, buyPrice)
 						if orderAmount > 0 && math.Abs(pnl) > orderAmount*0.5 {
 							logger.Warn("⚠️ [盈亏异常] 买入价: %.2f, 卖出价: %.2f, 数量: %.4f, 盈亏: %.2f, 订单金额: %.2f, 盈亏率: %.2f%%",
 								buyPrice, sellPrice, deltaQty, pnl, orderAmount, (pnl/orderAmount)*100)
@@ -1165,7 +1364,7 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 						// 保存交易记录（买入订单ID设为0，因为无法追溯历史订单）
 						buyOrderID := int64(0)
 						sellOrderID := update.OrderID
-						if err := spm.tradeStorage.SaveTrade(buyOrderID, sellOrderID, spm.exchangeName, update.Symbol, buyPrice, sellPrice, deltaQty, pnl, time.Now()); err != nil {
+						if err := spm.tradeStorage.SaveTrade(buyOrderID, sellOrderID, spm.exchangeName, update.Symbol, buyPrice, sellPrice, deltaQty, pnl, spm.now()); err != nil {
 							logger.Warn("⚠️ 保存交易记录失败: %v", err)
 						} else {
 							logger.Debug("💰 [交易记录已保存] 买入价: %s, 卖出价: %s, 数量: %.4f, 盈亏: %.4f",
@@ -1178,6 +1377,7 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 			if update.Status == "FILLED" {
 				slot.OrderStatus = OrderStatusNotPlaced // 重置订单状态
 				slot.OrderID = 0
+				slot.FinalizedClientOID = update.ClientOrderID
 				slot.ClientOID = ""
 				slot.OrderSide = "" // 🔥 清除订单方向，避免误判
 				slot.OrderFilledQty = 0
@@ -1192,11 +1392,11 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 				
 				// 🔥 释放资金：卖单成交后，资金已收回，释放预留的资金（卖单不需要预留资金，但为了统一处理也释放）
 				// 注意：卖单是平仓，不占用资金，但为了保持一致性，这里也处理
-				orderAmount := slot.OrderPrice * update.ExecutedQty
+				orderAmount := spm.contract.SettlementValue(update.ExecutedQty, slot.OrderPrice)
 				if orderAmount > 0 {
 					// 卖单成交后，持仓减少，对应的买入资金应该被释放
 					// 使用槽位价格（买入价）计算释放金额
-					releaseAmount := price * deltaQty
+					releaseAmount := spm.contract.SettlementValue(deltaQty, price)
 					if releaseAmount > 0 {
 						spm.allocationManager.Release(spm.exchangeName, spm.config.Trading.Symbol, releaseAmount)
 						logger.Debug("💰 [资金释放] 卖单成交，释放资金: %.2f USDT (持仓减少: %.4f)", releaseAmount, deltaQty)
@@ -1220,10 +1420,10 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 		if side == "BUY" && slot.OrderPrice > 0 {
 			// 对于买单，如果未成交或部分成交，释放未成交部分的资金
 			// 使用配置的订单金额作为参考（因为每个槽位的订单金额是固定的）
-			orderAmount := spm.config.Trading.OrderQuantity
+			orderAmount := spm.contract.NotionalToSettlement(spm.config.Trading.OrderQuantity, slot.OrderPrice)
 			if slot.OrderFilledQty > 0 {
 				// 部分成交：释放未成交部分的资金
-				filledAmount := slot.OrderPrice * slot.OrderFilledQty
+				filledAmount := spm.contract.SettlementValue(slot.OrderFilledQty, slot.OrderPrice)
 				unfilledAmount := orderAmount - filledAmount
 				if unfilledAmount > 0 {
 					spm.allocationManager.Release(spm.exchangeName, spm.config.Trading.Symbol, unfilledAmount)
@@ -1275,6 +1475,7 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 		// 清空订单信息
 		slot.OrderStatus = OrderStatusCanceled
 		slot.OrderID = 0
+		slot.FinalizedClientOID = update.ClientOrderID
 		slot.ClientOID = ""
 		slot.OrderFilledQty = 0
 		// 保留 OrderSide 用于日志调试
@@ -1344,6 +1545,13 @@ func (spm *SuperPositionManager) calculateSlotPrices(gridPrice float64, count in
 		}
 		
 		prices = append(prices, price)
+
+		// 买单方向：加密区间内在当前价格下方半格处追加槽位
+		if direction == "down" {
+			if mid, ok := spm.denseSlotPrice(price); ok {
+				prices = append(prices, mid)
+			}
+		}
 	}
 
 	return prices
@@ -1362,6 +1570,7 @@ type SlotData struct {
 	OrderSide      string
 	OrderStatus    string
 	OrderCreatedAt time.Time
+	StrategyType   string // 挂单所属策略类型（网格仓位管理器的槽位均为 grid）
 }
 
 // IterateSlots 遍历所有槽位（封装 sync.Map.Range）
@@ -1383,6 +1592,7 @@ func (spm *SuperPositionManager) IterateSlots(fn func(price float64, slot interf
 			OrderSide:      slot.OrderSide,
 			OrderStatus:    slot.OrderStatus,
 			OrderCreatedAt: slot.OrderCreatedAt,
+			StrategyType:   "grid",
 		}
 
 		// 返回槽位数据
@@ -1395,6 +1605,8 @@ type DetailedSlotData struct {
 	Price          float64
 	PositionStatus string
 	PositionQty    float64
+	CostBasis      float64 // 持仓总成本（含手续费）
+	AvgEntryPrice  float64 // 平均成本价（无成本记录时为槽位价格）
 	OrderID        int64
 	ClientOID      string
 	OrderSide      string
@@ -1403,6 +1615,10 @@ type DetailedSlotData struct {
 	OrderFilledQty float64
 	OrderCreatedAt time.Time
 	SlotStatus     string
+	QueueKnown     bool    // 是否有排队位置估算
+	QueueAhead     float64 // 估算的前方排队数量
+	QueueLevelQty  float64 // 挂单价位的盘口总量
+	ManualLocked   bool    // 是否被人工锁定（不再挂单）
 }
 
 // GetAllSlotsDetailed 获取所有槽位的详细信息
@@ -1422,11 +1638,14 @@ func (spm *SuperPositionManager) GetAllSlotsDetailed() []DetailedSlotData {
 		price := key.(float64)
 		slot := value.(*InventorySlot)
 		slot.mu.RLock()
+		queue, _ := spm.QueuePosition(slot.OrderID)
 
 		slots = append(slots, DetailedSlotData{
 			Price:          price,
 			PositionStatus: slot.PositionStatus,
 			PositionQty:    slot.PositionQty,
+			CostBasis:      slot.CostBasis,
+			AvgEntryPrice:  slot.entryPrice(),
 			OrderID:        slot.OrderID,
 			ClientOID:      slot.ClientOID,
 			OrderSide:      slot.OrderSide,
@@ -1435,6 +1654,10 @@ func (spm *SuperPositionManager) GetAllSlotsDetailed() []DetailedSlotData {
 			OrderFilledQty: slot.OrderFilledQty,
 			OrderCreatedAt: slot.OrderCreatedAt,
 			SlotStatus:     slot.SlotStatus,
+			QueueKnown:     queue.Known,
+			QueueAhead:     queue.Ahead,
+			QueueLevelQty:  queue.LevelQty,
+			ManualLocked:   spm.IsSlotLocked(price),
 		})
 
 		slot.mu.RUnlock()
@@ -1498,6 +1721,11 @@ func (spm *SuperPositionManager) GetExchange() string {
 	return spm.exchangeName
 }
 
+// GetContractSpec 获取合约规格（U 本位 / 币本位）
+func (spm *SuperPositionManager) GetContractSpec() utils.ContractSpec {
+	return spm.contract
+}
+
 // GetPriceInterval 获取价格间隔
 func (spm *SuperPositionManager) GetPriceInterval() float64 {
 	return spm.config.Trading.PriceInterval
@@ -1592,6 +1820,15 @@ func (spm *SuperPositionManager) UpdateSlotOrderStatus(price float64, status str
 	slot.mu.Unlock()
 }
 
+// RefreshOrders 按最新市场价格立即执行一次订单调整（订单清理器撤销过期订单后补挂）
+func (spm *SuperPositionManager) RefreshOrders() error {
+	lastPrice, _ := spm.lastMarketPrice.Load().(float64)
+	if lastPrice <= 0 {
+		return fmt.Errorf("尚未收到有效的市场价格")
+	}
+	return spm.AdjustOrders(lastPrice)
+}
+
 // CancelAllBuyOrders 撤销所有买单（风控触发时使用）
 func (spm *SuperPositionManager) CancelAllBuyOrders() {
 	var buyOrderIDs []int64
@@ -1834,6 +2071,7 @@ func (spm *SuperPositionManager) ForceSyncPositions(exchangePosition float64) {
 					formatPrice(slot.Price, spm.priceDecimals), slot.PositionQty)
 				slot.PositionStatus = PositionStatusEmpty
 				slot.PositionQty = 0
+				slot.CostBasis = 0
 				slot.OrderID = 0
 				slot.OrderStatus = OrderStatusNotPlaced
 				slot.ClientOID = ""
@@ -1844,6 +2082,7 @@ func (spm *SuperPositionManager) ForceSyncPositions(exchangePosition float64) {
 		})
 		
 		if count > 0 {
+			spm.persistCostBasis()
 			logger.Info("✅ [强制同步] 已成功清空 %d 个槽位的持仓数据", count)
 		} else {
 			logger.Debug("ℹ️ [强制同步] 本地本来就没有持仓，无需操作")
@@ -1865,7 +2104,7 @@ func (spm *SuperPositionManager) initializeSellSlotsFromPosition(totalPosition f
 	// 使用锚点价格作为参考价格，使用从交易所获取的数量精度
 
 	// 每单的理论数量 = 目标金额 / 锚点价格
-	theoryQtyPerSlot := spm.config.Trading.OrderQuantity / spm.anchorPrice
+	theoryQtyPerSlot := spm.contract.QuantityForNotional(spm.config.Trading.OrderQuantity, spm.anchorPrice)
 	theoryQtyPerSlot = roundPrice(theoryQtyPerSlot, spm.quantityDecimals)
 
 	// 2. 计算需要创建的总槽位数
@@ -1874,9 +2113,9 @@ func (spm *SuperPositionManager) initializeSellSlotsFromPosition(totalPosition f
 		totalPosition, theoryQtyPerSlot, totalSlotsNeeded)
 
 	// 3. 确定窗口大小（前N个槽位可以立即挂卖单）
-	sellWindowSize := spm.config.Trading.SellWindowSize
+	buyWindowSize, sellWindowSize := spm.windowSizes()
 	if sellWindowSize <= 0 {
-		sellWindowSize = spm.config.Trading.BuyWindowSize // 默认与买单窗口相同
+		sellWindowSize = buyWindowSize // 默认与买单窗口相同
 	}
 
 	// 4. 计算卖单槽位价格（从锚点价格 + 价格间隔开始）
@@ -1891,7 +2130,7 @@ func (spm *SuperPositionManager) initializeSellSlotsFromPosition(totalPosition f
 	var totalTheoryQty float64
 	theoryQtys := make([]float64, len(sellPrices))
 	for i, price := range sellPrices {
-		theoryQty := spm.config.Trading.OrderQuantity / price
+		theoryQty := spm.contract.QuantityForNotional(spm.config.Trading.OrderQuantity, price)
 		theoryQty = roundPrice(theoryQty, spm.quantityDecimals)
 		theoryQtys[i] = theoryQty
 		totalTheoryQty += theoryQty
@@ -1900,6 +2139,9 @@ func (spm *SuperPositionManager) initializeSellSlotsFromPosition(totalPosition f
 	logger.Debug("🔍 [持仓恢复] 理论总数量: %.4f, 实际持仓: %.4f, 比例: %.4f",
 		totalTheoryQty, totalPosition, totalPosition/totalTheoryQty)
 
+	// 恢复重启前保存的平均成本价，没有记录时使用槽位价格作为成本
+	restoredEntryPrice := spm.loadSavedEntryPrice(totalPosition)
+
 	// 6. 按比例分配实际持仓到各个槽位，并累加已用资金
 	var allocatedQty float64
 	var totalUsedAmount float64 // 累加已用资金
@@ -1935,6 +2177,11 @@ func (spm *SuperPositionManager) initializeSellSlotsFromPosition(totalPosition f
 		// 设置为有仓状态
 		slot.PositionStatus = PositionStatusFilled
 		slot.PositionQty = slotQty
+		if restoredEntryPrice > 0 {
+			slot.CostBasis = restoredEntryPrice * slotQty
+		} else {
+			slot.CostBasis = price * slotQty
+		}
 
 		// 清空订单信息，但设置方向为SELL（因为这是恢复的持仓，将来要挂卖单）
 		slot.OrderID = 0
@@ -1947,7 +2194,7 @@ func (spm *SuperPositionManager) initializeSellSlotsFromPosition(totalPosition f
 
 		allocatedQty += slotQty
 		// 累加已用资金：槽位价格 * 持仓数量
-		totalUsedAmount += price * slotQty
+		totalUsedAmount += spm.contract.SettlementValue(slotQty, price)
 
 		// 日志标记：是否在窗口内（只打印前10个和最后10个）
 		if i < 10 || i >= len(sellPrices)-10 {
@@ -2142,7 +2389,7 @@ func (spm *SuperPositionManager) PrintPositions() {
 	logger.Info("[%s] 当前网格价格: %s", spm.config.Trading.Symbol, formatPrice(currentGridPrice, spm.priceDecimals))
 
 	// 计算买单窗口范围（当前网格价格下方的买单窗口）
-	buyWindowSize := spm.config.Trading.BuyWindowSize
+	buyWindowSize, _ := spm.windowSizes()
 	buyWindowPrices := spm.calculateSlotPrices(currentGridPrice, buyWindowSize, "down")
 
 	// 创建价格查找表
@@ -2213,16 +2460,17 @@ func formatPrice(price float64, decimals int) string {
 	return fmt.Sprintf("%.*f", decimals, price)
 }
 
-// calculateUnrealizedPnL 计算未实现盈亏
+// calculateUnrealizedPnL 计算未实现盈亏（按含手续费的持仓成本，网格止损和回撤止盈均基于此）
+// 强平监控和压力测试不使用本地成本：保证金和强平价由交易所按其自身的开仓均价（不含手续费）计算，
+// 这些风控直接读取交易所持仓的 EntryPrice 才能与交易所的强平判断一致
 func (spm *SuperPositionManager) calculateUnrealizedPnL(currentPrice float64) float64 {
 	totalPnL := 0.0
 	spm.slots.Range(func(key, value interface{}) bool {
-		slotPrice := key.(float64)
 		slot := value.(*InventorySlot)
 		slot.mu.RLock()
 		if slot.PositionStatus == PositionStatusFilled && slot.PositionQty > 0 {
-			// 盈亏 = (当前价格 - 买入价格) * 数量
-			totalPnL += (currentPrice - slotPrice) * slot.PositionQty
+			// 盈亏 = 当前价值 - 持仓成本（含手续费），币本位按张数折算为基础币
+			totalPnL += spm.contract.PnL(slot.entryPrice(), currentPrice, slot.PositionQty)
 		}
 		slot.mu.RUnlock()
 		return true
@@ -2230,6 +2478,84 @@ func (spm *SuperPositionManager) calculateUnrealizedPnL(currentPrice float64) fl
 	return totalPnL
 }
 
+// GetCostBasis 获取当前交易对的持仓汇总成本
+// 返回：持仓数量、总成本（含手续费）、加权平均成本价
+func (spm *SuperPositionManager) GetCostBasis() (quantity, totalCost, avgEntryPrice float64) {
+	spm.slots.Range(func(key, value interface{}) bool {
+		slot := value.(*InventorySlot)
+		slot.mu.RLock()
+		if slot.PositionStatus == PositionStatusFilled && slot.PositionQty > 0 {
+			quantity += slot.PositionQty
+			totalCost += slot.entryPrice() * slot.PositionQty
+		}
+		slot.mu.RUnlock()
+		return true
+	})
+	if quantity > 0 {
+		avgEntryPrice = totalCost / quantity
+	}
+	return quantity, totalCost, avgEntryPrice
+}
+
+// feeRate 当前生效的手续费率（优先使用交易所实际费率，其次为配置费率）
+func (spm *SuperPositionManager) feeRate() float64 {
+	if spm.feeRateProvider != nil {
+		if rate := spm.feeRateProvider.MakerFeeRate(); rate > 0 {
+			return rate
+		}
+	}
+	if exCfg, ok := spm.config.Exchanges[spm.exchangeName]; ok && exCfg.FeeRate > 0 {
+		return exCfg.FeeRate
+	}
+	return 0
+}
+
+// persistCostBasis 安排持久化汇总成本（未设置存储时跳过）
+// 成交推送处于热路径上，不同步写库：首次变化时启动定时器，合并窗口内的后续变化由同一次写入覆盖
+func (spm *SuperPositionManager) persistCostBasis() {
+	if spm.costBasisStorage == nil {
+		return
+	}
+	if spm.costBasisPending.CompareAndSwap(false, true) {
+		spm.afterFunc(costBasisPersistDelay, spm.FlushCostBasis)
+	}
+}
+
+// FlushCostBasis 立即写入尚未持久化的汇总成本（停止交易对时调用，避免丢失合并窗口内的成交）
+func (spm *SuperPositionManager) FlushCostBasis() {
+	if spm.costBasisStorage == nil || !spm.costBasisPending.Swap(false) {
+		return
+	}
+	quantity, totalCost, _ := spm.GetCostBasis()
+	if err := spm.costBasisStorage.SaveCostBasis(spm.exchangeName, spm.config.Trading.Symbol, quantity, totalCost); err != nil {
+		logger.Warn("⚠️ [%s] 保存持仓成本失败: %v", spm.config.Trading.Symbol, err)
+	}
+}
+
+// loadSavedEntryPrice 读取重启前保存的平均成本价
+// 保存的数量与当前持仓差异较大时仍使用该成本价，但会给出警告
+func (spm *SuperPositionManager) loadSavedEntryPrice(totalPosition float64) float64 {
+	if spm.costBasisStorage == nil {
+		return 0
+	}
+	quantity, totalCost, found, err := spm.costBasisStorage.GetCostBasis(spm.exchangeName, spm.config.Trading.Symbol)
+	if err != nil {
+		logger.Warn("⚠️ [持仓恢复] 读取持仓成本失败: %v，使用槽位价格作为成本", err)
+		return 0
+	}
+	if !found || quantity <= 0 || totalCost <= 0 {
+		return 0
+	}
+	entryPrice := totalCost / quantity
+	if math.Abs(quantity-totalPosition) > totalPosition*0.05 {
+		logger.Warn("⚠️ [持仓恢复] 保存的持仓数量 %.4f 与交易所持仓 %.4f 不一致，成本价 %s 可能不准确",
+			quantity, totalPosition, formatPrice(entryPrice, spm.priceDecimals))
+	} else {
+		logger.Info("💰 [持仓恢复] 恢复平均成本价: %s (持仓: %.4f)", formatPrice(entryPrice, spm.priceDecimals), quantity)
+	}
+	return entryPrice
+}
+
 // calculateTotalPositionValue 计算当前持仓总价值
 func (spm *SuperPositionManager) calculateTotalPositionValue(currentPrice float64) float64 {
 	totalValue := 0.0
@@ -2237,7 +2563,7 @@ func (spm *SuperPositionManager) calculateTotalPositionValue(currentPrice float6
 		slot := value.(*InventorySlot)
 		slot.mu.RLock()
 		if slot.PositionStatus == PositionStatusFilled && slot.PositionQty > 0 {
-			totalValue += currentPrice * slot.PositionQty
+			totalValue += spm.contract.SettlementValue(slot.PositionQty, currentPrice)
 		}
 		slot.mu.RUnlock()
 		return true
@@ -2260,6 +2586,24 @@ func (spm *SuperPositionManager) GetActiveLayers() int {
 	return layers
 }
 
+// ActiveOrderCounts 统计槽位上仍在交易所挂着的买单、卖单数量
+func (spm *SuperPositionManager) ActiveOrderCounts() (buys, sells int) {
+	spm.slots.Range(func(key, value interface{}) bool {
+		slot := value.(*InventorySlot)
+		slot.mu.RLock()
+		if slot.OrderID > 0 && hasActiveOrder(slot.OrderStatus) {
+			if slot.OrderSide == "BUY" {
+				buys++
+			} else {
+				sells++
+			}
+		}
+		slot.mu.RUnlock()
+		return true
+	})
+	return buys, sells
+}
+
 // CleanupEmptySlots 清理空槽位（定期调用，防止 sync.Map 内存泄漏）
 // 清理条件：空仓、无订单、无订单历史
 func (spm *SuperPositionManager) CleanupEmptySlots() int {
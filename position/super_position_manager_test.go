@@ -60,6 +60,9 @@ func (m *MockExchange) GetOrder(ctx context.Context, symbol string, orderID int6
 }
 func (m *MockExchange) GetBaseAsset() string                                     { return "BTC" }
 func (m *MockExchange) CancelAllOrders(ctx context.Context, symbol string) error { return nil }
+func (m *MockExchange) GetAccount(ctx context.Context) (interface{}, error)      { return nil, nil }
+func (m *MockExchange) GetPriceDecimals() int                                    { return 2 }
+func (m *MockExchange) GetQuantityDecimals() int                                 { return 3 }
 
 func TestSuperPositionManager_Initialize(t *testing.T) {
 	cfg := &config.Config{}
@@ -152,3 +155,137 @@ func TestSuperPositionManager_OnOrderUpdate(t *testing.T) {
 		t.Errorf("槽位持仓状态错误: 期望 FILLED, 得到 %s", testSlot.PositionStatus)
 	}
 }
+
+func TestSuperPositionManager_ManualAdjustSlot(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Trading.Symbol = "BTCUSDT"
+	cfg.Trading.PriceInterval = 100.0
+	cfg.Trading.BuyWindowSize = 2
+	cfg.Trading.OrderQuantity = 100.0
+
+	spm := NewSuperPositionManager(cfg, &MockExecutor{}, &MockExchange{}, 2, 3)
+	spm.Initialize(50000.0, "50000.00")
+
+	// 不存在的槽位不能清空
+	if _, err := spm.ManualAdjustSlot(48000.0, false, 0, "admin", "测试"); err == nil {
+		t.Error("清空不存在的槽位应返回错误")
+	}
+
+	// 必须填写原因
+	if _, err := spm.ManualAdjustSlot(48000.0, true, 0.01, "admin", ""); err == nil {
+		t.Error("未填写原因应返回错误")
+	}
+
+	adj, err := spm.ManualAdjustSlot(48000.0, true, 0.0123, "admin", "交易所已成交但本地未记录")
+	if err != nil {
+		t.Fatalf("标记有仓失败: %v", err)
+	}
+	if adj.Before.PositionStatus != PositionStatusEmpty || adj.After.PositionStatus != PositionStatusFilled {
+		t.Errorf("修正前后状态错误: %s -> %s", adj.Before.PositionStatus, adj.After.PositionStatus)
+	}
+	if adj.After.PositionQty != 0.012 {
+		t.Errorf("数量应按精度取整为 0.012, 得到 %v", adj.After.PositionQty)
+	}
+
+	adj, err = spm.ManualAdjustSlot(48000.0, false, 0, "admin", "已手动平仓")
+	if err != nil {
+		t.Fatalf("清空槽位失败: %v", err)
+	}
+	if adj.After.PositionStatus != PositionStatusEmpty || adj.After.PositionQty != 0 {
+		t.Errorf("清空后状态错误: %s %.4f", adj.After.PositionStatus, adj.After.PositionQty)
+	}
+
+	// 有活跃订单的槽位拒绝修改
+	spm.AdjustOrders(49950.0)
+	var lockedPrice float64
+	spm.slots.Range(func(key, value interface{}) bool {
+		slot := value.(*InventorySlot)
+		if slot.SlotStatus == SlotStatusLocked {
+			lockedPrice = key.(float64)
+			return false
+		}
+		return true
+	})
+	if lockedPrice == 0 {
+		t.Fatal("未找到已锁定的槽位")
+	}
+	if _, err := spm.ManualAdjustSlot(lockedPrice, false, 0, "admin", "测试"); err == nil {
+		t.Error("存在活跃订单时应拒绝修正")
+	}
+}
+
+func TestSuperPositionManager_WindowProfile(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Trading.Symbol = "BTCUSDT"
+	cfg.Trading.BuyWindowSize = 10
+	cfg.Trading.SellWindowSize = 8
+
+	spm := NewSuperPositionManager(cfg, &MockExecutor{}, &MockExchange{}, 2, 3)
+	if name, buy, sell := spm.GetWindowProfile(); name != "" || buy != 10 || sell != 8 {
+		t.Errorf("默认应使用配置窗口, 得到 %q %d/%d", name, buy, sell)
+	}
+
+	// 0 表示沿用配置值
+	spm.SetWindowProfile("trend_up", 4, 0)
+	if name, buy, sell := spm.GetWindowProfile(); name != "trend_up" || buy != 4 || sell != 8 {
+		t.Errorf("窗口覆盖错误, 得到 %q %d/%d", name, buy, sell)
+	}
+	if cfg.Trading.BuyWindowSize != 10 {
+		t.Error("窗口覆盖不应修改配置")
+	}
+
+	spm.ClearWindowProfile()
+	if name, buy, sell := spm.GetWindowProfile(); name != "" || buy != 10 || sell != 8 {
+		t.Errorf("清除后应恢复配置窗口, 得到 %q %d/%d", name, buy, sell)
+	}
+}
+
+func TestQueueEstimator(t *testing.T) {
+	q := NewQueueEstimator()
+	q.onDepth([]QueueLevel{{Price: 100, Qty: 50}, {Price: 99, Qty: 10}}, []QueueLevel{{Price: 101, Qty: 5}})
+
+	// 新挂单以当前盘口为准，排在队尾（盘口总量已包含自身）
+	q.sync([]restingOrder{{orderID: 1, side: "BUY", price: 100, qty: 2}, {orderID: 2, side: "SELL", price: 105, qty: 1}})
+	if info, _ := q.get(1); !info.Known || info.Ahead != 48 {
+		t.Fatalf("初始前方排队应为 48, 得到 %+v", info)
+	}
+	if info, _ := q.get(2); info.Known {
+		t.Error("盘口之外的挂单排队位置应未知")
+	}
+
+	// 同价位卖方主动成交减少前方排队；买方主动成交不影响买单
+	q.onTrade(100, 20, true)
+	q.onTrade(100, 5, false)
+	if info, _ := q.get(1); info.Ahead != 28 {
+		t.Errorf("成交后前方排队应为 28, 得到 %v", info.Ahead)
+	}
+
+	// 盘口总量减少（前方撤单）时前方排队不超过 总量-自身
+	q.onDepth([]QueueLevel{{Price: 100, Qty: 12}}, nil)
+	if info, _ := q.get(1); info.Ahead != 10 || info.LevelQty != 12 {
+		t.Errorf("撤单后前方排队应为 10, 得到 %+v", info)
+	}
+
+	// 成交价穿过挂单价位
+	q.onTrade(99.5, 1, true)
+	if info, _ := q.get(1); info.Ahead != 0 {
+		t.Errorf("成交穿价后前方排队应为 0, 得到 %v", info.Ahead)
+	}
+
+	q.sync(nil)
+	if _, ok := q.get(1); ok {
+		t.Error("撤销的挂单应停止跟踪")
+	}
+}
+
+func TestNearRoundNumber(t *testing.T) {
+	if !nearRoundNumber(50000, 100, 0, 0.1) || nearRoundNumber(50000.5, 100, 0, 0.1) {
+		t.Error("整数关口判断错误")
+	}
+	if !nearRoundNumber(49999.8, 100, 2, 0.1) {
+		t.Error("关口附近 2 个价位以内应视为附近")
+	}
+	if !nearRoundNumber(12345.6, 0, 0, 0.1) {
+		t.Error("未设置步长时应视为满足")
+	}
+}
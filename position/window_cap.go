@@ -0,0 +1,66 @@
+package position
+
+import "quantmesh/logger"
+
+// WindowCap 挂单窗口与持仓层数上限（0 表示不限制）
+// 与 windowProfile 不同，上限按来源叠加且只会收紧：生效值取配置/窗口覆盖与所有上限中的最小值
+type WindowCap struct {
+	BuyWindow  int `json:"buy_window"`
+	SellWindow int `json:"sell_window"`
+	MaxLayers  int `json:"max_layers"` // 持仓层数达到该值后不再买入
+}
+
+// SetWindowCap 按来源设置挂单窗口/持仓层数上限，下一次 AdjustOrders 生效
+func (spm *SuperPositionManager) SetWindowCap(source string, c WindowCap) {
+	if prev, loaded := spm.windowCaps.Load(source); loaded && prev.(WindowCap) == c {
+		return
+	}
+	spm.windowCaps.Store(source, c)
+	logger.Warn("🪟 [%s] 设置挂单上限 (来源: %s): 买单窗口=%d, 卖单窗口=%d, 最大层数=%d（0 表示不限制）",
+		spm.config.Trading.Symbol, source, c.BuyWindow, c.SellWindow, c.MaxLayers)
+}
+
+// ClearWindowCap 解除指定来源的上限
+func (spm *SuperPositionManager) ClearWindowCap(source string) {
+	if _, loaded := spm.windowCaps.LoadAndDelete(source); !loaded {
+		return
+	}
+	logger.Info("🪟 [%s] 解除挂单上限 (来源: %s)", spm.config.Trading.Symbol, source)
+}
+
+// GetWindowCaps 获取当前生效的全部上限（source -> 上限）
+func (spm *SuperPositionManager) GetWindowCaps() map[string]WindowCap {
+	caps := make(map[string]WindowCap)
+	spm.windowCaps.Range(func(key, value interface{}) bool {
+		caps[key.(string)] = value.(WindowCap)
+		return true
+	})
+	return caps
+}
+
+// effectiveWindowCap 合并所有来源的上限（各项取非零最小值）
+func (spm *SuperPositionManager) effectiveWindowCap() WindowCap {
+	var merged WindowCap
+	spm.windowCaps.Range(func(_, value interface{}) bool {
+		c := value.(WindowCap)
+		merged.BuyWindow = minPositive(merged.BuyWindow, c.BuyWindow)
+		merged.SellWindow = minPositive(merged.SellWindow, c.SellWindow)
+		merged.MaxLayers = minPositive(merged.MaxLayers, c.MaxLayers)
+		return true
+	})
+	return merged
+}
+
+// minPositive 返回两者中较小的正数（0 表示不限制）
+func minPositive(a, b int) int {
+	switch {
+	case a <= 0:
+		return b
+	case b <= 0:
+		return a
+	case b < a:
+		return b
+	default:
+		return a
+	}
+}
@@ -0,0 +1,61 @@
+package position
+
+import "quantmesh/logger"
+
+// windowProfile 覆盖配置的挂单窗口（由市场状态分类器或趋势检测器设置）
+type windowProfile struct {
+	name       string
+	buyWindow  int
+	sellWindow int
+}
+
+// SetWindowProfile 切换挂单窗口，name 为来源/市场状态名称（仅用于日志和展示）
+// buyWindow/sellWindow 为 0 时沿用配置值，下一次 AdjustOrders 生效
+func (spm *SuperPositionManager) SetWindowProfile(name string, buyWindow, sellWindow int) {
+	next := &windowProfile{name: name, buyWindow: buyWindow, sellWindow: sellWindow}
+	if prev, _ := spm.windowOverride.Load().(*windowProfile); prev != nil && *prev == *next {
+		return
+	}
+	spm.windowOverride.Store(next)
+
+	buy, sell := spm.windowSizes()
+	logger.Info("🪟 [%s] 挂单窗口切换为 %s: 买单窗口=%d, 卖单窗口=%d",
+		spm.config.Trading.Symbol, name, buy, sell)
+}
+
+// ClearWindowProfile 取消窗口覆盖，恢复配置的挂单窗口
+func (spm *SuperPositionManager) ClearWindowProfile() {
+	if prev, _ := spm.windowOverride.Load().(*windowProfile); prev == nil || prev.name == "" {
+		return
+	}
+	spm.windowOverride.Store(&windowProfile{})
+	logger.Info("🪟 [%s] 挂单窗口恢复为配置值: 买单窗口=%d, 卖单窗口=%d",
+		spm.config.Trading.Symbol, spm.config.Trading.BuyWindowSize, spm.config.Trading.SellWindowSize)
+}
+
+// GetWindowProfile 获取当前生效的窗口（name 为空表示使用配置值）
+func (spm *SuperPositionManager) GetWindowProfile() (name string, buyWindow, sellWindow int) {
+	if p, _ := spm.windowOverride.Load().(*windowProfile); p != nil {
+		name = p.name
+	}
+	buyWindow, sellWindow = spm.windowSizes()
+	return name, buyWindow, sellWindow
+}
+
+// windowSizes 当前生效的买单/卖单窗口大小（已应用 WindowCap 上限）
+func (spm *SuperPositionManager) windowSizes() (buyWindow, sellWindow int) {
+	buyWindow = spm.config.Trading.BuyWindowSize
+	sellWindow = spm.config.Trading.SellWindowSize
+	if p, _ := spm.windowOverride.Load().(*windowProfile); p != nil {
+		if p.buyWindow > 0 {
+			buyWindow = p.buyWindow
+		}
+		if p.sellWindow > 0 {
+			sellWindow = p.sellWindow
+		}
+	}
+	c := spm.effectiveWindowCap()
+	buyWindow = minPositive(buyWindow, c.BuyWindow)
+	sellWindow = minPositive(sellWindow, c.SellWindow)
+	return buyWindow, sellWindow
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/exchange"
+	"quantmesh/logger"
+	"quantmesh/position"
+	"quantmesh/web"
+)
+
+// restartExitCode 协调重启时进程的退出码（runTrading 返回后由 cmdRun 使用，0 表示正常退出）
+var restartExitCode int
+
+// resumeToken 协调重启的恢复令牌：记录退出时各交易对的网格锚点、槽位和交易所持仓，新进程校验后据此接管保留的挂单
+type resumeToken struct {
+	Token      string         `json:"token"`
+	InstanceID string         `json:"instance_id"`
+	Version    string         `json:"version"` // 写入令牌的程序版本
+	CreatedAt  time.Time      `json:"created_at"`
+	ExpiresAt  time.Time      `json:"expires_at"`
+	Symbols    []resumeSymbol `json:"symbols"`
+}
+
+// resumeSymbol 单个交易对的恢复信息
+type resumeSymbol struct {
+	Exchange      string                  `json:"exchange"`
+	Symbol        string                  `json:"symbol"`
+	Anchor        float64                 `json:"anchor"`
+	PriceInterval float64                 `json:"price_interval"`
+	Position      float64                 `json:"position"` // 退出时交易所的持仓，新进程启动时不一致说明重启期间有成交
+	Slots         []position.SlotSnapshot `json:"slots"`
+
+	source string // 日志标签，为空时为协调重启
+}
+
+// restartCoordinator 协调重启：按各策略的退出方式撤单后唤醒主流程退出，退出流程中保存恢复令牌
+type restartCoordinator struct {
+	cfg       *config.Config
+	manager   *SymbolManager
+	lifecycle *lifecycleManager
+	once      sync.Once
+	requested chan struct{}
+	token     string
+}
+
+func newRestartCoordinator(cfg *config.Config, manager *SymbolManager, lifecycle *lifecycleManager) *restartCoordinator {
+	return &restartCoordinator{
+		cfg:       cfg,
+		manager:   manager,
+		lifecycle: lifecycle,
+		requested: make(chan struct{}),
+	}
+}
+
+// Restart 发起协调重启（只执行一次）：暂停并按退出方式撤单，随后主流程按正常退出顺序关闭
+func (r *restartCoordinator) Restart() (web.RestartResult, error) {
+	var result web.RestartResult
+	accepted := false
+	r.once.Do(func() {
+		accepted = true
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			r.token = fmt.Sprintf("%d", time.Now().UnixNano())
+		} else {
+			r.token = hex.EncodeToString(buf)
+		}
+		logger.Warn("🔁 [协调重启] 收到重启请求，令牌 %s，按各策略的退出方式处理挂单", r.token)
+		result = web.RestartResult{
+			Token:    r.token,
+			ExitCode: r.cfg.System.Restart.ExitCode,
+			Cancel:   r.lifecycle.FastCancel(),
+		}
+		close(r.requested)
+	})
+	if !accepted {
+		return result, fmt.Errorf("重启已在进行中")
+	}
+	return result, nil
+}
+
+// Requested 重启请求到达时关闭
+func (r *restartCoordinator) Requested() <-chan struct{} {
+	return r.requested
+}
+
+// isRequested 是否已发起重启
+func (r *restartCoordinator) isRequested() bool {
+	select {
+	case <-r.requested:
+		return true
+	default:
+		return false
+	}
+}
+
+// saveToken 保存恢复令牌（退出流程中撤单、平仓之后，停止交易对组件之前调用）
+// 网格退出方式为 flatten 的交易对持仓已平掉，不写入令牌，新进程按正常流程启动
+func (r *restartCoordinator) saveToken(ctx context.Context) error {
+	now := time.Now()
+	token := resumeToken{
+		Token:      r.token,
+		InstanceID: r.cfg.Instance.ID,
+		Version:    Version,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(time.Duration(r.cfg.System.Restart.TokenTTLSeconds) * time.Second),
+	}
+	gridFlatten := r.cfg.ShutdownPolicy(gridStrategyName) == config.ShutdownPolicyFlatten
+	for _, rt := range r.manager.List() {
+		if rt.SuperPositionManager == nil || gridFlatten {
+			continue
+		}
+		pos, err := exchangePosition(ctx, rt.Exchange, rt.Config.Symbol)
+		if err != nil {
+			logger.Warn("⚠️ [%s:%s] 查询持仓失败，不写入恢复令牌: %v", rt.Config.Exchange, rt.Config.Symbol, err)
+			continue
+		}
+		token.Symbols = append(token.Symbols, resumeSymbol{
+			Exchange:      rt.Config.Exchange,
+			Symbol:        rt.Config.Symbol,
+			Anchor:        rt.SuperPositionManager.GetAnchorPrice(),
+			PriceInterval: rt.SuperPositionManager.GetPriceInterval(),
+			Position:      pos,
+			Slots:         rt.SuperPositionManager.SnapshotSlots(),
+		})
+	}
+
+	path := r.cfg.System.Restart.TokenFile
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := writeJSONAtomic(path, token); err != nil {
+		return err
+	}
+	logger.Info("💾 [协调重启] 恢复令牌已保存: %s (%d 个交易对，有效期至 %s)", path, len(token.Symbols), token.ExpiresAt.Format(time.RFC3339))
+	return nil
+}
+
+// exchangePosition 交易所上该交易对的持仓数量（与仓位管理器恢复持仓时的取值一致）
+func exchangePosition(ctx context.Context, ex exchange.IExchange, symbol string) (float64, error) {
+	positions, err := ex.GetPositions(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range positions {
+		if p != nil && p.Symbol == symbol {
+			return p.Size, nil
+		}
+	}
+	return 0, nil
+}
+
+var (
+	resumeMu      sync.Mutex
+	resumeSymbols map[string]*resumeSymbol
+)
+
+// loadResumeToken 启动时读取并校验恢复令牌（读取后即删除，只使用一次）
+// 实例不一致或已过期时忽略，各交易对按正常流程启动
+func loadResumeToken(cfg *config.Config) {
+	path := cfg.System.Restart.TokenFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("⚠️ [协调重启] 读取恢复令牌失败: %v", err)
+		}
+		return
+	}
+	if err := os.Remove(path); err != nil {
+		logger.Warn("⚠️ [协调重启] 删除恢复令牌失败: %v", err)
+	}
+
+	var token resumeToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		logger.Warn("⚠️ [协调重启] 恢复令牌格式错误，按正常流程启动: %v", err)
+		return
+	}
+	switch {
+	case token.Token == "":
+		logger.Warn("⚠️ [协调重启] 恢复令牌缺少令牌标识，按正常流程启动")
+		return
+	case token.InstanceID != cfg.Instance.ID:
+		logger.Warn("⚠️ [协调重启] 恢复令牌属于实例 %q（当前 %q），按正常流程启动", token.InstanceID, cfg.Instance.ID)
+		return
+	case time.Now().After(token.ExpiresAt):
+		logger.Warn("⚠️ [协调重启] 恢复令牌 %s 已于 %s 过期，按正常流程启动", token.Token, token.ExpiresAt.Format(time.RFC3339))
+		return
+	}
+
+	resumeMu.Lock()
+	defer resumeMu.Unlock()
+	resumeSymbols = make(map[string]*resumeSymbol, len(token.Symbols))
+	for i := range token.Symbols {
+		s := &token.Symbols[i]
+		resumeSymbols[runtimeKey(s.Exchange, s.Symbol)] = s
+	}
+	logger.Info("🔁 [协调重启] 恢复令牌 %s 校验通过（版本 %s -> %s），%d 个交易对将接管保留的挂单",
+		token.Token, token.Version, Version, len(token.Symbols))
+}
+
+// stageResumeSymbol 登记交易对的恢复信息（热备接管时由主实例快照生成），下次启动该交易对时使用
+func stageResumeSymbol(rs *resumeSymbol) {
+	resumeMu.Lock()
+	defer resumeMu.Unlock()
+	if resumeSymbols == nil {
+		resumeSymbols = make(map[string]*resumeSymbol)
+	}
+	resumeSymbols[runtimeKey(rs.Exchange, rs.Symbol)] = rs
+}
+
+// label 日志标签
+func (rs *resumeSymbol) label() string {
+	if rs.source != "" {
+		return rs.source
+	}
+	return "协调重启"
+}
+
+// takeResumeSymbol 取出交易对的恢复信息（只取一次，之后重新启动该交易对按正常流程）
+func takeResumeSymbol(exchangeName, symbol string) *resumeSymbol {
+	resumeMu.Lock()
+	defer resumeMu.Unlock()
+	key := runtimeKey(exchangeName, symbol)
+	s := resumeSymbols[key]
+	delete(resumeSymbols, key)
+	return s
+}
+
+// resumeGrid 按恢复信息（协调重启的恢复令牌或热备接管时主实例的快照）恢复网格并接管挂单，成功时返回沿用的锚点
+// 校验失败时撤销重启前保留的挂单（避免与正常初始化重新挂出的订单重复），由调用方按正常流程初始化
+func resumeGrid(ctx context.Context, rs *resumeSymbol, cfg *config.Config, ex exchange.IExchange,
+	spm *position.SuperPositionManager, currentPrice float64) (float64, bool) {
+	symbol := rs.Symbol
+	err := func() error {
+		if rs.PriceInterval != cfg.Trading.PriceInterval {
+			return fmt.Errorf("价格间隔已从 %.8g 调整为 %.8g", rs.PriceInterval, cfg.Trading.PriceInterval)
+		}
+		if deviation := math.Abs(rs.Anchor-currentPrice) / currentPrice; deviation > cfg.Trading.Anchor.MaxDeviation {
+			return fmt.Errorf("锚点 %.8g 偏离当前价格 %.2f%%（上限 %.0f%%）", rs.Anchor, deviation*100, cfg.Trading.Anchor.MaxDeviation*100)
+		}
+		orders, err := ex.GetOpenOrders(ctx, symbol)
+		if err != nil {
+			return fmt.Errorf("查询挂单失败: %w", err)
+		}
+		open := make(map[int64]bool, len(orders))
+		for _, o := range orders {
+			open[o.OrderID] = true
+		}
+		_, err = spm.ResumeSlots(rs.Anchor, rs.Slots, open, rs.Position)
+		return err
+	}()
+	if err == nil {
+		return rs.Anchor, true
+	}
+
+	logger.Warn("⚠️ [%s:%s] [%s] 无法按快照接管挂单，按正常流程启动: %v", rs.Exchange, symbol, rs.label(), err)
+	var ids []int64
+	for _, s := range rs.Slots {
+		if s.OrderID != 0 {
+			ids = append(ids, s.OrderID)
+		}
+	}
+	if len(ids) > 0 {
+		if err := ex.BatchCancelOrders(ctx, symbol, ids); err != nil {
+			logger.Warn("⚠️ [%s:%s] [%s] 撤销快照中保留的 %d 笔挂单失败: %v", rs.Exchange, symbol, rs.label(), len(ids), err)
+		} else {
+			logger.Info("🧹 [%s:%s] [%s] 已撤销快照中保留的 %d 笔挂单", rs.Exchange, symbol, rs.label(), len(ids))
+		}
+	}
+	return 0, false
+}
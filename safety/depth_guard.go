@@ -0,0 +1,118 @@
+package safety
+
+import (
+	"sync"
+	"time"
+
+	"quantmesh/config"
+)
+
+// DepthGuardStatus 买盘深度保护状态
+type DepthGuardStatus struct {
+	Active      bool      `json:"active"`
+	BidDepth    float64   `json:"bid_depth"`    // 最近一次盘口前 levels 档买盘数量合计
+	Baseline    float64   `json:"baseline"`     // 触发时窗口内的最大买盘深度（未启用时为当前窗口最大值）
+	DropPercent float64   `json:"drop_percent"` // 当前深度相对基准的下降比例（%）
+	ActivatedAt time.Time `json:"activated_at,omitempty"`
+}
+
+type depthSample struct {
+	at    time.Time
+	depth float64
+}
+
+// DepthGuard 买盘深度骤降保护
+// 记录短窗口内盘口前 N 档买盘数量，当前深度相对窗口内最大值下降超过阈值时启用（撤单式拉盘往往先于价格下跌），
+// 深度恢复到触发前基准的 recover_percent 且至少保持 min_active_seconds 后解除；
+// 超过 max_active_seconds 仍未恢复时视为盘口已进入新常态，直接解除；状态变化通过回调通知
+type DepthGuard struct {
+	cfg config.DepthGuardConfig
+
+	mu       sync.Mutex
+	samples  []depthSample
+	status   DepthGuardStatus
+	onChange func(active bool, status DepthGuardStatus)
+}
+
+// NewDepthGuard 创建买盘深度保护
+func NewDepthGuard(cfg config.DepthGuardConfig) *DepthGuard {
+	return &DepthGuard{cfg: cfg}
+}
+
+// OnChange 设置状态变化回调（在 Update 的调用方协程中同步执行）
+func (g *DepthGuard) OnChange(fn func(active bool, status DepthGuardStatus)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onChange = fn
+}
+
+// Update 输入最新买盘各档数量（价格从高到低）并重新评估保护状态
+func (g *DepthGuard) Update(bidQtys []float64, now time.Time) {
+	if len(bidQtys) == 0 {
+		return
+	}
+	var depth float64
+	for i, qty := range bidQtys {
+		if i >= g.cfg.Levels {
+			break
+		}
+		depth += qty
+	}
+
+	g.mu.Lock()
+	cutoff := now.Add(-time.Duration(g.cfg.WindowSeconds) * time.Second)
+	drop := 0
+	for drop < len(g.samples) && g.samples[drop].at.Before(cutoff) {
+		drop++
+	}
+	g.samples = g.samples[drop:]
+
+	var windowMax float64
+	for _, s := range g.samples {
+		if s.depth > windowMax {
+			windowMax = s.depth
+		}
+	}
+	g.samples = append(g.samples, depthSample{at: now, depth: depth})
+
+	g.status.BidDepth = depth
+	if !g.status.Active {
+		g.status.Baseline = windowMax
+	}
+	g.status.DropPercent = 0
+	if g.status.Baseline > 0 && depth < g.status.Baseline {
+		g.status.DropPercent = (1 - depth/g.status.Baseline) * 100
+	}
+
+	changed := false
+	activeFor := now.Sub(g.status.ActivatedAt)
+	switch {
+	case !g.status.Active && g.status.Baseline > 0 && g.status.DropPercent >= g.cfg.DropPercent:
+		g.status.Active = true
+		g.status.ActivatedAt = now
+		changed = true
+	case g.status.Active && activeFor >= time.Duration(g.cfg.MinActiveSeconds)*time.Second &&
+		(depth >= g.status.Baseline*g.cfg.RecoverPercent/100 ||
+			activeFor >= time.Duration(g.cfg.MaxActiveSeconds)*time.Second):
+		g.status.Active = false
+		g.status.ActivatedAt = time.Time{}
+		// 解除后以恢复后的盘口重新建立基准，避免触发前的高深度立即再次触发
+		g.samples = []depthSample{{at: now, depth: depth}}
+		g.status.Baseline = depth
+		g.status.DropPercent = 0
+		changed = true
+	}
+	status, fn := g.status, g.onChange
+	g.mu.Unlock()
+
+	if changed && fn != nil {
+		fn(status.Active, status)
+	}
+}
+
+// Status 获取当前保护状态
+func (g *DepthGuard) Status() DepthGuardStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.status
+}
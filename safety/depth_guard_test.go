@@ -0,0 +1,64 @@
+package safety
+
+import (
+	"testing"
+	"time"
+
+	"quantmesh/config"
+)
+
+func TestDepthGuardActivatesAndRecovers(t *testing.T) {
+	g := NewDepthGuard(config.DepthGuardConfig{
+		Levels:           3,
+		WindowSeconds:    5,
+		DropPercent:      70,
+		RecoverPercent:   60,
+		MinActiveSeconds: 10,
+		MaxActiveSeconds: 60,
+	})
+	var changes []bool
+	g.OnChange(func(active bool, st DepthGuardStatus) { changes = append(changes, active) })
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	// 正常波动不触发；超出 levels 的档位不计入
+	g.Update([]float64{10, 10, 10, 1000}, at(0))
+	g.Update([]float64{8, 9, 10, 1000}, at(500))
+	if g.Status().Active || g.Status().BidDepth != 27 {
+		t.Fatalf("normal book should not activate guard: %+v", g.Status())
+	}
+
+	// 买盘 1 秒内消失 80% 触发
+	g.Update([]float64{2, 2, 2}, at(1500))
+	st := g.Status()
+	if !st.Active || st.Baseline != 30 || st.DropPercent < 79 {
+		t.Fatalf("bid evaporation should activate guard: %+v", st)
+	}
+
+	// 深度恢复但未满最短保持时间，不解除
+	g.Update([]float64{10, 10, 10}, at(5000))
+	if !g.Status().Active {
+		t.Fatalf("guard should stay active for min_active_seconds")
+	}
+	// 满足保持时间但深度未恢复到基准的 60%，不解除
+	g.Update([]float64{5, 5, 5}, at(12000))
+	if !g.Status().Active {
+		t.Fatalf("guard should stay active until depth recovers: %+v", g.Status())
+	}
+	g.Update([]float64{6, 6, 6}, at(13000))
+	if g.Status().Active {
+		t.Fatalf("guard should recover when depth reaches 60%% of baseline: %+v", g.Status())
+	}
+
+	// 长时间未恢复时按 max_active_seconds 解除
+	g.Update([]float64{1, 1, 1}, at(14000))
+	g.Update([]float64{1, 1, 1}, at(80000))
+	if g.Status().Active {
+		t.Fatalf("guard should release after max_active_seconds: %+v", g.Status())
+	}
+
+	if len(changes) != 4 || !changes[0] || changes[1] || !changes[2] || changes[3] {
+		t.Errorf("unexpected change notifications: %v", changes)
+	}
+}
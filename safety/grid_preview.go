@@ -0,0 +1,157 @@
+package safety
+
+import (
+	"fmt"
+	"math"
+
+	"quantmesh/utils"
+)
+
+// DefaultMaintenanceMarginRate 估算强平价时默认使用的维持保证金率
+const DefaultMaintenanceMarginRate = 0.005
+
+// TradeEconomics 单笔网格交易（买入后上涨一个价格间隔卖出）的收益分析
+// 固定金额模式：每笔买入金额固定，数量根据价格动态计算
+type TradeEconomics struct {
+	BuyPrice    float64 `json:"buy_price"`
+	SellPrice   float64 `json:"sell_price"`
+	Quantity    float64 `json:"quantity"`
+	BuyAmount   float64 `json:"buy_amount"`
+	SellAmount  float64 `json:"sell_amount"`
+	GrossProfit float64 `json:"gross_profit"` // 卖出金额 - 买入金额（币本位为按张数计算的基础币盈亏）
+	BuyFee      float64 `json:"buy_fee"`
+	SellFee     float64 `json:"sell_fee"`
+	TotalFee    float64 `json:"total_fee"`
+	NetProfit   float64 `json:"net_profit"`  // 毛利润 - 手续费
+	ProfitRate  float64 `json:"profit_rate"` // 价格间隔 / 买入价
+}
+
+// CalculateTradeEconomics 计算单笔网格交易（U 本位合约）的利润和手续费
+func CalculateTradeEconomics(price, orderAmount, priceInterval, feeRate float64) TradeEconomics {
+	return CalculateContractTradeEconomics(utils.ContractSpec{}, price, orderAmount, priceInterval, feeRate)
+}
+
+// CalculateContractTradeEconomics 按合约规格计算单笔网格交易的利润和手续费
+// orderAmount 为每笔名义价值（计价货币）；币本位合约的数量为合约张数，金额、利润和手续费以基础币计
+func CalculateContractTradeEconomics(spec utils.ContractSpec, price, orderAmount, priceInterval, feeRate float64) TradeEconomics {
+	e := TradeEconomics{
+		BuyPrice:  price,
+		SellPrice: price + priceInterval,
+	}
+	if price > 0 {
+		e.Quantity = spec.QuantityForNotional(orderAmount, price)
+		e.ProfitRate = priceInterval / price
+	}
+	e.BuyAmount = spec.SettlementValue(e.Quantity, e.BuyPrice)
+	e.SellAmount = spec.SettlementValue(e.Quantity, e.SellPrice)
+	e.GrossProfit = spec.PnL(e.BuyPrice, e.SellPrice, e.Quantity)
+	e.BuyFee = e.BuyAmount * feeRate
+	e.SellFee = e.SellAmount * feeRate
+	e.TotalFee = e.BuyFee + e.SellFee
+	e.NetProfit = e.GrossProfit - e.TotalFee
+	return e
+}
+
+// GridPreviewParams 网格试算参数
+type GridPreviewParams struct {
+	Capital               float64 // 投入资金（计价币）
+	Price                 float64 // 当前价格
+	OrderAmount           float64 // 每笔金额（计价币）
+	PriceInterval         float64 // 价格间隔
+	BuyWindow             int     // 买单窗口
+	SellWindow            int     // 卖单窗口
+	Leverage              int     // 杠杆倍数
+	FeeRate               float64 // 单边手续费率
+	MaintenanceMarginRate float64 // 维持保证金率（0 表示使用默认值）
+}
+
+// GridPreview 网格试算结果
+type GridPreview struct {
+	TotalOrders       int            `json:"total_orders"`       // 同时挂出的订单数
+	MaxPositions      float64        `json:"max_positions"`      // 资金最多可持有的仓数
+	MaxFillNotional   float64        `json:"max_fill_notional"`  // 买单窗口全部成交后的持仓名义价值
+	RequiredMargin    float64        `json:"required_margin"`    // 买单窗口全部成交所需保证金
+	MarginUsage       float64        `json:"margin_usage"`       // 所需保证金 / 投入资金
+	AvgEntryPrice     float64        `json:"avg_entry_price"`    // 全部成交后的持仓均价
+	LowestFillPrice   float64        `json:"lowest_fill_price"`  // 最低一档买单价格
+	BreakevenInterval float64        `json:"breakeven_interval"` // 覆盖双边手续费所需的最小价格间隔
+	BreakevenMove     float64        `json:"breakeven_move"`     // 保本所需的价格涨幅（比例）
+	FeeDrag           float64        `json:"fee_drag"`           // 手续费占每笔毛利润的比例
+	MaxFillFee        float64        `json:"max_fill_fee"`       // 买单窗口全部成交支付的手续费
+	LiquidationPrice  float64        `json:"liquidation_price"`  // 全部成交后的估算强平价（0 表示不会强平）
+	LiquidationBuffer float64        `json:"liquidation_buffer"` // 最低买单价到强平价的距离（比例）
+	PerTrade          TradeEconomics `json:"per_trade"`          // 单笔交易收益分析
+	Warnings          []string       `json:"warnings"`
+}
+
+// PreviewGrid 在不下单的前提下试算网格参数的资金占用和风险
+// 假设为做多网格：买单从当前价向下每隔一个价格间隔挂一档
+func PreviewGrid(p GridPreviewParams) (*GridPreview, error) {
+	if p.Capital <= 0 || p.Price <= 0 || p.OrderAmount <= 0 || p.PriceInterval <= 0 {
+		return nil, fmt.Errorf("资金、价格、每笔金额和价格间隔必须大于0")
+	}
+	if p.BuyWindow <= 0 || p.SellWindow < 0 {
+		return nil, fmt.Errorf("买单窗口必须大于0，卖单窗口不能为负")
+	}
+	if p.FeeRate < 0 || p.FeeRate >= 1 {
+		return nil, fmt.Errorf("手续费率必须在 0~1 之间")
+	}
+	lowest := p.Price - float64(p.BuyWindow)*p.PriceInterval
+	if lowest <= 0 {
+		return nil, fmt.Errorf("价格间隔过大：%d 档买单的最低价格 %.8f 不为正", p.BuyWindow, lowest)
+	}
+	leverage := p.Leverage
+	if leverage <= 0 {
+		leverage = 1
+	}
+	mmr := p.MaintenanceMarginRate
+	if mmr <= 0 {
+		mmr = DefaultMaintenanceMarginRate
+	}
+
+	preview := &GridPreview{
+		TotalOrders:     p.BuyWindow + p.SellWindow,
+		MaxPositions:    p.Capital * float64(leverage) / p.OrderAmount,
+		LowestFillPrice: lowest,
+		PerTrade:        CalculateTradeEconomics(p.Price, p.OrderAmount, p.PriceInterval, p.FeeRate),
+		Warnings:        []string{},
+	}
+
+	// 买单窗口全部成交
+	var quantity float64
+	for k := 1; k <= p.BuyWindow; k++ {
+		quantity += p.OrderAmount / (p.Price - float64(k)*p.PriceInterval)
+	}
+	preview.MaxFillNotional = p.OrderAmount * float64(p.BuyWindow)
+	preview.AvgEntryPrice = preview.MaxFillNotional / quantity
+	preview.RequiredMargin = preview.MaxFillNotional / float64(leverage)
+	preview.MarginUsage = preview.RequiredMargin / p.Capital
+	preview.MaxFillFee = preview.MaxFillNotional * p.FeeRate
+
+	// 保本：卖出金额 × (1 - f) = 买入金额 × (1 + f)
+	preview.BreakevenMove = (1+p.FeeRate)/(1-p.FeeRate) - 1
+	preview.BreakevenInterval = p.Price * preview.BreakevenMove
+	if preview.PerTrade.GrossProfit > 0 {
+		preview.FeeDrag = preview.PerTrade.TotalFee / preview.PerTrade.GrossProfit
+	}
+
+	// 强平价：资金 + 数量 × (P - 均价) = 数量 × P × 维持保证金率
+	liq := (quantity*preview.AvgEntryPrice - p.Capital) / (quantity * (1 - mmr))
+	if liq > 0 {
+		preview.LiquidationPrice = liq
+		preview.LiquidationBuffer = (lowest - liq) / lowest
+	} else {
+		preview.LiquidationBuffer = 1
+	}
+
+	if preview.PerTrade.NetProfit <= 0 {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("每笔净利润为负或为零 (%.4f)，价格间隔至少需要 %.8f", preview.PerTrade.NetProfit, preview.BreakevenInterval))
+	}
+	if preview.MaxPositions < float64(p.BuyWindow) {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("资金最多持有 %.0f 仓，少于买单窗口 %d 档", math.Floor(preview.MaxPositions), p.BuyWindow))
+	}
+	if preview.LiquidationPrice > 0 && preview.LiquidationBuffer < 0.1 {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("全部成交后强平价 %.8f 距最低买单价仅 %.2f%%", preview.LiquidationPrice, preview.LiquidationBuffer*100))
+	}
+	return preview, nil
+}
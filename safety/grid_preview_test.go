@@ -0,0 +1,51 @@
+package safety
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPreviewGrid(t *testing.T) {
+	preview, err := PreviewGrid(GridPreviewParams{
+		Capital:       1000,
+		Price:         100,
+		OrderAmount:   10,
+		PriceInterval: 1,
+		BuyWindow:     10,
+		SellWindow:    10,
+		Leverage:      5,
+		FeeRate:       0.0002,
+	})
+	if err != nil {
+		t.Fatalf("试算失败: %v", err)
+	}
+	if preview.TotalOrders != 20 {
+		t.Errorf("订单数 = %d, 期望 20", preview.TotalOrders)
+	}
+	if preview.MaxFillNotional != 100 || preview.RequiredMargin != 20 {
+		t.Errorf("全部成交名义价值/保证金错误: %.2f / %.2f", preview.MaxFillNotional, preview.RequiredMargin)
+	}
+	if preview.LowestFillPrice != 90 {
+		t.Errorf("最低买单价 = %.2f, 期望 90", preview.LowestFillPrice)
+	}
+	if math.Abs(preview.BreakevenInterval-0.04) > 1e-4 {
+		t.Errorf("保本价格间隔 = %.6f, 期望约 0.04", preview.BreakevenInterval)
+	}
+	// 资金远大于持仓，不会强平
+	if preview.LiquidationPrice != 0 || len(preview.Warnings) != 0 {
+		t.Errorf("不应出现强平价或告警: liq=%.4f warnings=%v", preview.LiquidationPrice, preview.Warnings)
+	}
+
+	// 价格间隔小于手续费成本时应给出告警
+	preview, err = PreviewGrid(GridPreviewParams{Capital: 1000, Price: 100, OrderAmount: 10, PriceInterval: 0.01, BuyWindow: 10, FeeRate: 0.0005})
+	if err != nil {
+		t.Fatalf("试算失败: %v", err)
+	}
+	if preview.PerTrade.NetProfit >= 0 || len(preview.Warnings) == 0 {
+		t.Errorf("净利润为负时应告警: %+v", preview)
+	}
+
+	if _, err := PreviewGrid(GridPreviewParams{Capital: 1000, Price: 100, OrderAmount: 10, PriceInterval: 20, BuyWindow: 10}); err == nil {
+		t.Error("最低买单价为负时应返回错误")
+	}
+}
@@ -2,13 +2,26 @@ package safety
 
 import (
 	"context"
+	"fmt"
 	"quantmesh/config"
+	"quantmesh/exchange"
 	"quantmesh/logger"
+	"quantmesh/metrics"
+	"quantmesh/storage"
+	"quantmesh/utils"
 	"reflect"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
+// 订单清理的触发方式
+const (
+	CleanupTriggerScheduled = "scheduled"
+	CleanupTriggerManual    = "manual"
+)
+
 // OrderCleanerSlotInfo 订单清理所需的槽位信息
 type OrderCleanerSlotInfo struct {
 	Price       float64
@@ -30,25 +43,95 @@ type IOrderCleanerPositionManager interface {
 	UpdateSlotOrderStatus(price float64, status string)
 }
 
+// IOrderRefresher 可选：立即按当前价格补挂订单（仓位管理器实现时，过期撤单后可立即重挂）
+type IOrderRefresher interface {
+	RefreshOrders() error
+}
+
+// IOrderCleanerExchange 可选：查询交易所挂单（用于识别本地槽位之外的孤儿挂单）
+type IOrderCleanerExchange interface {
+	GetOpenOrders(ctx context.Context, symbol string) (interface{}, error)
+}
+
+// OrderCleanupRecorder 订单清理动作记录器（可选，用于事后查看订单被撤销的原因）
+type OrderCleanupRecorder interface {
+	RecordCleanupActions(actions []*storage.OrderCleanupAction)
+}
+
+// OrderCleanupReport 一次订单清理的结果
+type OrderCleanupReport struct {
+	Exchange     string                        `json:"exchange"`
+	Symbol       string                        `json:"symbol"`
+	Trigger      string                        `json:"trigger"`
+	DryRun       bool                          `json:"dry_run"`
+	OrphanPolicy string                        `json:"orphan_policy"`
+	TotalOrders  int                           `json:"total_orders"` // 本地槽位中可撤销的挂单数
+	Threshold    int                           `json:"threshold"`
+	Actions      []*storage.OrderCleanupAction `json:"actions"`
+	OrphanError  string                        `json:"orphan_error,omitempty"` // 查询交易所挂单失败时的错误
+	StartedAt    time.Time                     `json:"started_at"`
+}
+
+// cleanupCandidate 待撤销的订单
+type cleanupCandidate struct {
+	Price         float64
+	OrderID       int64
+	ClientOrderID string
+	Side          string
+	Detail        string
+}
+
+// OrderExpiryStats 挂单过期统计
+type OrderExpiryStats struct {
+	ExpiredBuy    int64     `json:"expired_buy"`
+	ExpiredSell   int64     `json:"expired_sell"`
+	Refreshes     int64     `json:"refreshes"` // 过期撤单后立即重挂的次数
+	LastExpiredAt time.Time `json:"last_expired_at"`
+}
+
 // OrderCleaner 订单清理器
 type OrderCleaner struct {
 	cfg      *config.Config
 	executor IOrderExecutor
 	pm       IOrderCleanerPositionManager
+	exchange IOrderCleanerExchange // 可选，孤儿挂单处理需要
+	recorder OrderCleanupRecorder  // 可选
+
+	// 定时清理与手动触发互斥，避免同一批订单被重复撤销
+	runMu sync.Mutex
+
+	// 过期撤单后等待撤单回报再重挂的时间；重挂在 runMu 之外调度，不阻塞手动清理
+	refreshDelay time.Duration
+	afterFunc    func(d time.Duration, f func())
+
+	statsMu     sync.Mutex
+	expiryStats OrderExpiryStats
 }
 
 // NewOrderCleaner 创建订单清理器
 func NewOrderCleaner(cfg *config.Config, executor IOrderExecutor, pm IOrderCleanerPositionManager) *OrderCleaner {
 	return &OrderCleaner{
-		cfg:      cfg,
-		executor: executor,
-		pm:       pm,
+		cfg:          cfg,
+		executor:     executor,
+		pm:           pm,
+		refreshDelay: 2 * time.Second,
+		afterFunc:    func(d time.Duration, f func()) { time.AfterFunc(d, f) },
 	}
 }
 
+// SetExchange 设置交易所（可选，启用孤儿挂单处理时需要）
+func (oc *OrderCleaner) SetExchange(ex IOrderCleanerExchange) {
+	oc.exchange = ex
+}
+
+// SetRecorder 设置清理动作记录器（可选）
+func (oc *OrderCleaner) SetRecorder(recorder OrderCleanupRecorder) {
+	oc.recorder = recorder
+}
+
 // Start 启动订单清理协程
 func (oc *OrderCleaner) Start(ctx context.Context) {
-	go func() {
+	utils.GoSupervised(ctx, "order-cleaner", func(ctx context.Context) {
 		cleanupInterval := time.Duration(oc.cfg.Timing.OrderCleanupInterval) * time.Second
 		ticker := time.NewTicker(cleanupInterval)
 		defer ticker.Stop()
@@ -62,12 +145,193 @@ func (oc *OrderCleaner) Start(ctx context.Context) {
 				oc.CleanupOrders()
 			}
 		}
-	}()
+	})
 	logger.Info("✅ 订单清理协程已启动")
 }
 
-// CleanupOrders 清理订单
+// ExpiryStats 获取挂单过期统计
+func (oc *OrderCleaner) ExpiryStats() OrderExpiryStats {
+	oc.statsMu.Lock()
+	defer oc.statsMu.Unlock()
+	return oc.expiryStats
+}
+
+// CleanupOrders 按配置执行一次定时清理
 func (oc *OrderCleaner) CleanupOrders() {
+	oc.Run(CleanupTriggerScheduled, oc.cfg.Trading.OrderCleaner.DryRun)
+}
+
+// Run 执行一次订单清理（过期挂单、超过数量上限、孤儿挂单），每个撤单动作都会被记录
+// dryRun 为 true 时只记录将被撤销的订单，不实际撤单
+func (oc *OrderCleaner) Run(trigger string, dryRun bool) *OrderCleanupReport {
+	report, refresh := oc.run(trigger, dryRun)
+	if refresh {
+		oc.scheduleRefresh(report.Symbol)
+	}
+	return report
+}
+
+// run 在 runMu 保护下执行清理，返回报告以及过期撤单后是否需要重挂
+func (oc *OrderCleaner) run(trigger string, dryRun bool) (*OrderCleanupReport, bool) {
+	oc.runMu.Lock()
+	defer oc.runMu.Unlock()
+
+	report := &OrderCleanupReport{
+		Exchange:     strings.ToLower(oc.cfg.App.CurrentExchange),
+		Symbol:       oc.cfg.Trading.Symbol,
+		Trigger:      trigger,
+		DryRun:       dryRun,
+		OrphanPolicy: oc.cfg.Trading.OrderCleaner.OrphanPolicy,
+		Actions:      []*storage.OrderCleanupAction{},
+		StartedAt:    time.Now(),
+	}
+
+	refresh := oc.expireOrders(report)
+	oc.cleanupByThreshold(report)
+	oc.cleanupOrphans(report)
+
+	if oc.recorder != nil && len(report.Actions) > 0 {
+		oc.recorder.RecordCleanupActions(report.Actions)
+	}
+	return report, refresh
+}
+
+// scheduleRefresh 等待撤单回报后按当前价格重新挂单
+func (oc *OrderCleaner) scheduleRefresh(symbol string) {
+	refresher, ok := oc.pm.(IOrderRefresher)
+	if !ok {
+		return
+	}
+	oc.afterFunc(oc.refreshDelay, func() {
+		if err := refresher.RefreshOrders(); err != nil {
+			logger.Warn("⚠️ [订单过期] %s 重新挂单失败: %v", symbol, err)
+			return
+		}
+		oc.statsMu.Lock()
+		oc.expiryStats.Refreshes++
+		oc.statsMu.Unlock()
+	})
+}
+
+// cancel 撤销一批订单并把结果追加到报告；演练模式只记录不撤单，返回是否已撤单（或演练中视为已撤单）
+func (oc *OrderCleaner) cancel(report *OrderCleanupReport, reason string, candidates []cleanupCandidate) bool {
+	if len(candidates) == 0 {
+		return false
+	}
+
+	var err error
+	if !report.DryRun {
+		orderIDs := make([]int64, 0, len(candidates))
+		for _, cand := range candidates {
+			orderIDs = append(orderIDs, cand.OrderID)
+		}
+		err = oc.executor.BatchCancelOrders(orderIDs)
+	}
+
+	now := utils.NowUTC()
+	for _, cand := range candidates {
+		action := &storage.OrderCleanupAction{
+			Exchange:      report.Exchange,
+			Symbol:        report.Symbol,
+			OrderID:       cand.OrderID,
+			ClientOrderID: cand.ClientOrderID,
+			Side:          cand.Side,
+			Price:         cand.Price,
+			Reason:        reason,
+			Detail:        cand.Detail,
+			Trigger:       report.Trigger,
+			DryRun:        report.DryRun,
+			Success:       err == nil,
+			CreatedAt:     now,
+		}
+		if err != nil {
+			action.Error = err.Error()
+		}
+		report.Actions = append(report.Actions, action)
+	}
+	if report.DryRun {
+		logger.Info("🧪 [订单清理演练] %s 将撤销 %d 个订单 (原因: %s)", report.Symbol, len(candidates), reason)
+	}
+	return err == nil
+}
+
+// expireOrders 撤销超过所属策略最长存活时间且没有任何成交的挂单，返回是否需要立即重挂
+// 部分成交的订单不撤（与数量清理相同的原因），撤单后的槽位由网格按当前窗口重新挂单
+func (oc *OrderCleaner) expireOrders(report *OrderCleanupReport) bool {
+	expiry := oc.cfg.Trading.OrderExpiry
+	if !expiry.Enabled {
+		return false
+	}
+
+	now := time.Now()
+	var candidates []cleanupCandidate
+	var buys, sells int
+	oc.pm.IterateSlots(func(price float64, slotRaw interface{}) bool {
+		v := reflect.ValueOf(slotRaw)
+		if v.Kind() != reflect.Struct {
+			return true
+		}
+		status, _ := v.FieldByName("OrderStatus").Interface().(string)
+		side, _ := v.FieldByName("OrderSide").Interface().(string)
+		orderID, _ := v.FieldByName("OrderID").Interface().(int64)
+		createdAt, _ := v.FieldByName("OrderCreatedAt").Interface().(time.Time)
+		if orderID == 0 || createdAt.IsZero() || (status != "PLACED" && status != "CONFIRMED") {
+			return true
+		}
+		strategyType := "grid"
+		if field := v.FieldByName("StrategyType"); field.IsValid() && field.Kind() == reflect.String && field.String() != "" {
+			strategyType = field.String()
+		}
+		maxAge := expiry.MaxAge(strategyType)
+		age := now.Sub(createdAt)
+		if maxAge <= 0 || age < maxAge {
+			return true
+		}
+		candidates = append(candidates, cleanupCandidate{
+			Price:   price,
+			OrderID: orderID,
+			Side:    side,
+			Detail:  fmt.Sprintf("%s 挂单 %v 未成交，超过最长存活时间 %v", strategyType, age.Truncate(time.Second), maxAge),
+		})
+		if side == "BUY" {
+			buys++
+		} else {
+			sells++
+		}
+		return true
+	})
+	if len(candidates) == 0 {
+		return false
+	}
+
+	exchangeName, symbol := oc.cfg.App.CurrentExchange, report.Symbol
+	logger.Info("⌛ [订单过期] %s 撤销超过最长存活时间未成交的订单 %d 个 (买单: %d, 卖单: %d)",
+		symbol, len(candidates), buys, sells)
+	if !oc.cancel(report, storage.CleanupReasonExpired, candidates) {
+		logger.Error("❌ [订单过期] 批量撤单失败: %s", report.Actions[len(report.Actions)-1].Error)
+		return false
+	}
+	if report.DryRun {
+		return false
+	}
+	for _, cand := range candidates {
+		oc.pm.UpdateSlotOrderStatus(cand.Price, "CANCEL_REQUESTED")
+	}
+
+	oc.statsMu.Lock()
+	oc.expiryStats.ExpiredBuy += int64(buys)
+	oc.expiryStats.ExpiredSell += int64(sells)
+	oc.expiryStats.LastExpiredAt = now
+	oc.statsMu.Unlock()
+	promMetrics := metrics.GetPrometheusMetrics()
+	promMetrics.RecordOrderExpired(exchangeName, symbol, "BUY", buys)
+	promMetrics.RecordOrderExpired(exchangeName, symbol, "SELL", sells)
+
+	return expiry.Replace
+}
+
+// cleanupByThreshold 挂单总数达到上限时，撤销数量较多一方中离当前价格最远的一批
+func (oc *OrderCleaner) cleanupByThreshold(report *OrderCleanupReport) {
 	// 订单状态常量
 	const (
 		OrderStatusPlaced          = "PLACED"
@@ -77,14 +341,8 @@ func (oc *OrderCleaner) CleanupOrders() {
 
 	// 统计当前订单数
 	totalOrders := 0
-	var buyOrders []struct {
-		Price   float64
-		OrderID int64
-	}
-	var sellOrders []struct {
-		Price   float64
-		OrderID int64
-	}
+	var buyOrders []cleanupCandidate
+	var sellOrders []cleanupCandidate
 
 	oc.pm.IterateSlots(func(price float64, slotRaw interface{}) bool {
 		// 使用反射提取槽位字段
@@ -118,15 +376,9 @@ func (oc *OrderCleaner) CleanupOrders() {
 		if orderStatus == OrderStatusPlaced || orderStatus == OrderStatusConfirmed {
 			totalOrders++
 			if orderSide == "BUY" {
-				buyOrders = append(buyOrders, struct {
-					Price   float64
-					OrderID int64
-				}{Price: price, OrderID: orderID})
+				buyOrders = append(buyOrders, cleanupCandidate{Price: price, OrderID: orderID, Side: orderSide})
 			} else if orderSide == "SELL" {
-				sellOrders = append(sellOrders, struct {
-					Price   float64
-					OrderID int64
-				}{Price: price, OrderID: orderID})
+				sellOrders = append(sellOrders, cleanupCandidate{Price: price, OrderID: orderID, Side: orderSide})
 			}
 		}
 		return true
@@ -136,6 +388,8 @@ func (oc *OrderCleaner) CleanupOrders() {
 	if threshold <= 0 {
 		threshold = 100
 	}
+	report.TotalOrders = totalOrders
+	report.Threshold = threshold
 
 	batchSize := oc.cfg.Trading.CleanupBatchSize
 	if batchSize <= 0 {
@@ -144,104 +398,189 @@ func (oc *OrderCleaner) CleanupOrders() {
 
 	// 🔥 核心策略：达到阈值才清理，不提前
 	// 清理时优先清理数量多的一方（买单或卖单）
-	if totalOrders >= threshold {
-		canceledCount := 0
-
-		logger.Info("🧹 [订单清理] 当前订单数: %d (买单: %d, 卖单: %d), 阈值: %d, 批次大小: %d",
-			totalOrders, len(buyOrders), len(sellOrders), threshold, batchSize)
-
-		// 🔥 新策略：优先清理数量多的一方
-		// 如果买单多，就清理买单；如果卖单多，就清理卖单
-		buyOrdersToCancel := 0
-		sellOrdersToCancel := 0
-
-		if len(buyOrders) > len(sellOrders) {
-			// 买单多，清理买单
-			buyOrdersToCancel = batchSize
-			logger.Info("📊 [清理策略] 买单数量多于卖单，清理 %d 个买单", buyOrdersToCancel)
-		} else if len(sellOrders) > len(buyOrders) {
-			// 卖单多，清理卖单
-			sellOrdersToCancel = batchSize
-			logger.Info("📊 [清理策略] 卖单数量多于买单，清理 %d 个卖单", sellOrdersToCancel)
-		} else {
-			// 数量相等，平均清理
-			buyOrdersToCancel = batchSize / 2
-			sellOrdersToCancel = batchSize - buyOrdersToCancel
-			logger.Info("📊 [清理策略] 买卖单数量相等，平均清理 (买单: %d, 卖单: %d)", buyOrdersToCancel, sellOrdersToCancel)
-		}
-
-		// 清理买单：清理价格最低的（离当前价格最远的）
-		if len(buyOrders) > 0 && buyOrdersToCancel > 0 {
-			// 按价格从低到高排序，清理最低的
-			sort.Slice(buyOrders, func(i, j int) bool {
-				return buyOrders[i].Price < buyOrders[j].Price
-			})
-
-			cancelCount := buyOrdersToCancel
-			if cancelCount > len(buyOrders) {
-				cancelCount = len(buyOrders)
-			}
+	if totalOrders < threshold {
+		logger.Debug("ℹ️ [订单清理] 总订单数: %d (阈值: %d，无需清理)", totalOrders, threshold)
+		return
+	}
 
-			if cancelCount > 0 {
-				orderIDs := make([]int64, 0, cancelCount)
-				prices := make([]float64, 0, cancelCount)
-				for i := 0; i < cancelCount; i++ {
-					orderIDs = append(orderIDs, buyOrders[i].OrderID)
-					prices = append(prices, buyOrders[i].Price)
-				}
+	canceledCount := 0
+	detail := fmt.Sprintf("挂单数 %d (买单 %d, 卖单 %d) 达到上限 %d", totalOrders, len(buyOrders), len(sellOrders), threshold)
+
+	logger.Info("🧹 [订单清理] 当前订单数: %d (买单: %d, 卖单: %d), 阈值: %d, 批次大小: %d",
+		totalOrders, len(buyOrders), len(sellOrders), threshold, batchSize)
+
+	// 🔥 新策略：优先清理数量多的一方
+	// 如果买单多，就清理买单；如果卖单多，就清理卖单
+	buyOrdersToCancel := 0
+	sellOrdersToCancel := 0
+
+	if len(buyOrders) > len(sellOrders) {
+		// 买单多，清理买单
+		buyOrdersToCancel = batchSize
+		logger.Info("📊 [清理策略] 买单数量多于卖单，清理 %d 个买单", buyOrdersToCancel)
+	} else if len(sellOrders) > len(buyOrders) {
+		// 卖单多，清理卖单
+		sellOrdersToCancel = batchSize
+		logger.Info("📊 [清理策略] 卖单数量多于买单，清理 %d 个卖单", sellOrdersToCancel)
+	} else {
+		// 数量相等，平均清理
+		buyOrdersToCancel = batchSize / 2
+		sellOrdersToCancel = batchSize - buyOrdersToCancel
+		logger.Info("📊 [清理策略] 买卖单数量相等，平均清理 (买单: %d, 卖单: %d)", buyOrdersToCancel, sellOrdersToCancel)
+	}
 
-				logger.Info("🧹 [订单清理-买单] 买单数: %d, 取消价格最低的 %d 个 (%.2f ~ %.2f)",
-					len(buyOrders), cancelCount, buyOrders[0].Price, buyOrders[cancelCount-1].Price)
-
-				if err := oc.executor.BatchCancelOrders(orderIDs); err != nil {
-					logger.Error("❌ [订单清理-买单] 批量撤单失败: %v", err)
-				} else {
-					// 更新槽位状态为已申请撤单
-					for _, price := range prices {
-						oc.pm.UpdateSlotOrderStatus(price, OrderStatusCancelRequested)
-					}
-					canceledCount += cancelCount
+	// 清理买单：清理价格最低的（离当前价格最远的）
+	if len(buyOrders) > 0 && buyOrdersToCancel > 0 {
+		// 按价格从低到高排序，清理最低的
+		sort.Slice(buyOrders, func(i, j int) bool {
+			return buyOrders[i].Price < buyOrders[j].Price
+		})
+
+		cancelCount := buyOrdersToCancel
+		if cancelCount > len(buyOrders) {
+			cancelCount = len(buyOrders)
+		}
+
+		batch := buyOrders[:cancelCount]
+		for i := range batch {
+			batch[i].Detail = detail + "，撤销价格最低的买单"
+		}
+		logger.Info("🧹 [订单清理-买单] 买单数: %d, 取消价格最低的 %d 个 (%.2f ~ %.2f)",
+			len(buyOrders), cancelCount, batch[0].Price, batch[cancelCount-1].Price)
+
+		if oc.cancel(report, storage.CleanupReasonThreshold, batch) {
+			if !report.DryRun {
+				// 更新槽位状态为已申请撤单
+				for _, cand := range batch {
+					oc.pm.UpdateSlotOrderStatus(cand.Price, OrderStatusCancelRequested)
 				}
 			}
+			canceledCount += cancelCount
+		} else {
+			logger.Error("❌ [订单清理-买单] 批量撤单失败: %s", report.Actions[len(report.Actions)-1].Error)
 		}
+	}
 
-		// 清理卖单：清理价格最高的（离当前价格最远的）
-		if len(sellOrders) > 0 && sellOrdersToCancel > 0 {
-			// 按价格从高到低排序，清理最高的
-			sort.Slice(sellOrders, func(i, j int) bool {
-				return sellOrders[i].Price > sellOrders[j].Price
-			})
-
-			cancelCount := sellOrdersToCancel
-			if cancelCount > len(sellOrders) {
-				cancelCount = len(sellOrders)
-			}
+	// 清理卖单：清理价格最高的（离当前价格最远的）
+	if len(sellOrders) > 0 && sellOrdersToCancel > 0 {
+		// 按价格从高到低排序，清理最高的
+		sort.Slice(sellOrders, func(i, j int) bool {
+			return sellOrders[i].Price > sellOrders[j].Price
+		})
 
-			if cancelCount > 0 {
-				orderIDs := make([]int64, 0, cancelCount)
-				prices := make([]float64, 0, cancelCount)
-				for i := 0; i < cancelCount; i++ {
-					orderIDs = append(orderIDs, sellOrders[i].OrderID)
-					prices = append(prices, sellOrders[i].Price)
-				}
+		cancelCount := sellOrdersToCancel
+		if cancelCount > len(sellOrders) {
+			cancelCount = len(sellOrders)
+		}
 
-				logger.Info("🧹 [订单清理-卖单] 卖单数: %d, 取消价格最高的 %d 个 (%.2f ~ %.2f)",
-					len(sellOrders), cancelCount, sellOrders[0].Price, sellOrders[cancelCount-1].Price)
-
-				if err := oc.executor.BatchCancelOrders(orderIDs); err != nil {
-					logger.Error("❌ [订单清理-卖单] 批量撤单失败: %v", err)
-				} else {
-					// 更新槽位状态为已申请撤单
-					for _, price := range prices {
-						oc.pm.UpdateSlotOrderStatus(price, OrderStatusCancelRequested)
-					}
-					canceledCount += cancelCount
+		batch := sellOrders[:cancelCount]
+		for i := range batch {
+			batch[i].Detail = detail + "，撤销价格最高的卖单"
+		}
+		logger.Info("🧹 [订单清理-卖单] 卖单数: %d, 取消价格最高的 %d 个 (%.2f ~ %.2f)",
+			len(sellOrders), cancelCount, batch[0].Price, batch[cancelCount-1].Price)
+
+		if oc.cancel(report, storage.CleanupReasonThreshold, batch) {
+			if !report.DryRun {
+				// 更新槽位状态为已申请撤单
+				for _, cand := range batch {
+					oc.pm.UpdateSlotOrderStatus(cand.Price, OrderStatusCancelRequested)
 				}
 			}
+			canceledCount += cancelCount
+		} else {
+			logger.Error("❌ [订单清理-卖单] 批量撤单失败: %s", report.Actions[len(report.Actions)-1].Error)
 		}
+	}
 
-		logger.Info("✅ [订单清理完成] 清理了 %d 个订单，剩余: %d", canceledCount, totalOrders-canceledCount)
-	} else {
-		logger.Debug("ℹ️ [订单清理] 总订单数: %d (阈值: %d，无需清理)", totalOrders, threshold)
+	logger.Info("✅ [订单清理完成] 清理了 %d 个订单，剩余: %d", canceledCount, totalOrders-canceledCount)
+}
+
+// cleanupOrphans 按 orphan_policy 撤销交易所上存在、但不属于任何本地槽位的挂单
+// bot：只撤 ClientOrderID 为网格格式的订单（通常是重启前遗留的订单）；all：连同手动下的订单一起撤
+func (oc *OrderCleaner) cleanupOrphans(report *OrderCleanupReport) {
+	policy := report.OrphanPolicy
+	if policy != "bot" && policy != "all" {
+		return
+	}
+	if oc.exchange == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	raw, err := oc.exchange.GetOpenOrders(ctx, report.Symbol)
+	if err != nil {
+		report.OrphanError = err.Error()
+		logger.Warn("⚠️ [订单清理-孤儿挂单] %s 查询交易所挂单失败: %v", report.Symbol, err)
+		return
+	}
+	openOrders, _ := raw.([]*exchange.Order)
+	if len(openOrders) == 0 {
+		return
+	}
+
+	tracked := make(map[int64]bool)
+	oc.pm.IterateSlots(func(price float64, slotRaw interface{}) bool {
+		v := reflect.ValueOf(slotRaw)
+		if v.Kind() != reflect.Struct {
+			return true
+		}
+		if orderID, _ := v.FieldByName("OrderID").Interface().(int64); orderID != 0 {
+			tracked[orderID] = true
+		}
+		return true
+	})
+
+	minAge := time.Duration(oc.cfg.Trading.OrderCleaner.OrphanMinAgeMinutes) * time.Minute
+	now := time.Now()
+	var candidates []cleanupCandidate
+	for _, o := range openOrders {
+		if o == nil || o.OrderID == 0 || tracked[o.OrderID] {
+			continue
+		}
+		botOrder, createdAt := oc.inspectOrphan(o)
+		if policy == "bot" && !botOrder {
+			continue
+		}
+		// 无法确定挂单时间时不撤，避免误撤刚提交、槽位尚未记录的订单
+		if createdAt.IsZero() || now.Sub(createdAt) < minAge {
+			continue
+		}
+		origin := "手动/其他程序"
+		if botOrder {
+			origin = "网格"
+		}
+		candidates = append(candidates, cleanupCandidate{
+			Price:         o.Price,
+			OrderID:       o.OrderID,
+			ClientOrderID: o.ClientOrderID,
+			Side:          string(o.Side),
+			Detail: fmt.Sprintf("孤儿挂单（来源: %s，挂单 %v，本地槽位无记录，orphan_policy=%s）",
+				origin, now.Sub(createdAt).Truncate(time.Second), policy),
+		})
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	logger.Info("🧹 [订单清理-孤儿挂单] %s 撤销本地槽位之外的挂单 %d 个 (策略: %s)", report.Symbol, len(candidates), policy)
+	if !oc.cancel(report, storage.CleanupReasonOrphan, candidates) {
+		logger.Error("❌ [订单清理-孤儿挂单] 批量撤单失败: %s", report.Actions[len(report.Actions)-1].Error)
+	}
+}
+
+// inspectOrphan 判断挂单是否由网格生成，并返回挂单时间（交易所未返回时从 ClientOrderID 中解析）
+func (oc *OrderCleaner) inspectOrphan(o *exchange.Order) (bool, time.Time) {
+	cleanID := utils.RemoveBrokerPrefix(strings.ToLower(oc.cfg.App.CurrentExchange), o.ClientOrderID)
+	_, _, timestamp, botOrder := utils.ParseOrderID(cleanID, 0)
+
+	createdAt := o.CreatedAt
+	if createdAt.IsZero() && o.UpdateTime > 0 {
+		createdAt = time.UnixMilli(o.UpdateTime)
+	}
+	if createdAt.IsZero() && botOrder {
+		createdAt = time.Unix(timestamp, 0)
 	}
+	return botOrder, createdAt
 }
@@ -0,0 +1,468 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"quantmesh/config"
+	"quantmesh/exchange"
+	"quantmesh/position"
+	"quantmesh/storage"
+	"quantmesh/testutil"
+	"quantmesh/utils"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cleanerPositionManager 订单清理测试用的槽位管理器，记录状态更新与重挂次数
+type cleanerPositionManager struct {
+	mu        sync.Mutex
+	slots     map[float64]position.SlotData
+	updates   map[float64]string
+	refreshes int
+	refreshFn func() error
+}
+
+func newCleanerPositionManager(slots ...position.SlotData) *cleanerPositionManager {
+	pm := &cleanerPositionManager{
+		slots:   make(map[float64]position.SlotData),
+		updates: make(map[float64]string),
+	}
+	for _, slot := range slots {
+		pm.slots[slot.Price] = slot
+	}
+	return pm
+}
+
+func (pm *cleanerPositionManager) IterateSlots(fn func(price float64, slot interface{}) bool) {
+	pm.mu.Lock()
+	slots := make([]position.SlotData, 0, len(pm.slots))
+	for _, slot := range pm.slots {
+		slots = append(slots, slot)
+	}
+	pm.mu.Unlock()
+	for _, slot := range slots {
+		if !fn(slot.Price, slot) {
+			return
+		}
+	}
+}
+
+func (pm *cleanerPositionManager) UpdateSlotOrderStatus(price float64, status string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.updates[price] = status
+	slot := pm.slots[price]
+	slot.OrderStatus = status
+	pm.slots[price] = slot
+}
+
+func (pm *cleanerPositionManager) RefreshOrders() error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.refreshes++
+	if pm.refreshFn != nil {
+		return pm.refreshFn()
+	}
+	return nil
+}
+
+// cleanerExecutor 记录批量撤单请求
+type cleanerExecutor struct {
+	batches [][]int64
+	err     error
+}
+
+func (e *cleanerExecutor) BatchCancelOrders(orderIDs []int64) error {
+	e.batches = append(e.batches, append([]int64(nil), orderIDs...))
+	return e.err
+}
+
+func (e *cleanerExecutor) canceled() []int64 {
+	var ids []int64
+	for _, batch := range e.batches {
+		ids = append(ids, batch...)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// cleanupRecorder 记录清理动作
+type cleanupRecorder struct {
+	actions []*storage.OrderCleanupAction
+}
+
+func (r *cleanupRecorder) RecordCleanupActions(actions []*storage.OrderCleanupAction) {
+	r.actions = append(r.actions, actions...)
+}
+
+func newExpiryConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.App.CurrentExchange = "binance"
+	cfg.Trading.Symbol = "BTCUSDT"
+	cfg.Trading.OrderCleanupThreshold = 100
+	cfg.Trading.OrderExpiry = config.OrderExpiryConfig{
+		Enabled:       true,
+		MaxAgeMinutes: 60,
+		Strategies:    map[string]int{"dca": 10, "martingale": 0},
+	}
+	return cfg
+}
+
+func placedSlot(price float64, orderID int64, side, strategyType string, age time.Duration) position.SlotData {
+	return position.SlotData{
+		Price:          price,
+		OrderID:        orderID,
+		OrderSide:      side,
+		OrderStatus:    "PLACED",
+		OrderCreatedAt: time.Now().Add(-age),
+		StrategyType:   strategyType,
+	}
+}
+
+func TestOrderCleanerExpirySelection(t *testing.T) {
+	partial := placedSlot(96, 6, "BUY", "grid", 2*time.Hour)
+	partial.OrderStatus = "PARTIALLY_FILLED"
+
+	pm := newCleanerPositionManager(
+		placedSlot(100, 1, "BUY", "grid", 61*time.Minute),      // 超过默认 60 分钟
+		placedSlot(99, 2, "BUY", "grid", 30*time.Minute),       // 未到期
+		placedSlot(101, 3, "SELL", "dca", 11*time.Minute),      // dca 覆盖为 10 分钟
+		placedSlot(102, 4, "SELL", "martingale", 24*time.Hour), // martingale 配置为不过期
+		placedSlot(98, 5, "BUY", "", 61*time.Minute),           // 未标注策略时按 grid
+		partial, // 部分成交不撤
+		position.SlotData{Price: 97, OrderStatus: "PLACED", OrderCreatedAt: time.Now().Add(-2 * time.Hour)}, // 无订单ID
+	)
+	executor := &cleanerExecutor{}
+	recorder := &cleanupRecorder{}
+	oc := NewOrderCleaner(newExpiryConfig(), executor, pm)
+	oc.SetRecorder(recorder)
+
+	report := oc.Run(CleanupTriggerManual, false)
+
+	if got, want := executor.canceled(), []int64{1, 3, 5}; !equalIDs(got, want) {
+		t.Fatalf("canceled = %v, want %v", got, want)
+	}
+	if len(report.Actions) != 3 || len(recorder.actions) != 3 {
+		t.Fatalf("actions = %d, recorded = %d, want 3", len(report.Actions), len(recorder.actions))
+	}
+	for _, action := range report.Actions {
+		if action.Reason != storage.CleanupReasonExpired || !action.Success || action.DryRun {
+			t.Errorf("action %d = %+v, want successful expired action", action.OrderID, action)
+		}
+	}
+	for _, price := range []float64{100, 101, 98} {
+		if pm.updates[price] != "CANCEL_REQUESTED" {
+			t.Errorf("slot %.0f status = %q, want CANCEL_REQUESTED", price, pm.updates[price])
+		}
+	}
+	if len(pm.updates) != 3 {
+		t.Errorf("updated slots = %v, want only the expired ones", pm.updates)
+	}
+
+	stats := oc.ExpiryStats()
+	if stats.ExpiredBuy != 2 || stats.ExpiredSell != 1 || stats.LastExpiredAt.IsZero() {
+		t.Errorf("stats = %+v, want 2 buys and 1 sell", stats)
+	}
+	if stats.Refreshes != 0 || pm.refreshes != 0 {
+		t.Errorf("refreshes = %d/%d without replace, want 0", stats.Refreshes, pm.refreshes)
+	}
+}
+
+func TestOrderCleanerExpiryDisabled(t *testing.T) {
+	cfg := newExpiryConfig()
+	cfg.Trading.OrderExpiry.Enabled = false
+	pm := newCleanerPositionManager(placedSlot(100, 1, "BUY", "grid", 24*time.Hour))
+	executor := &cleanerExecutor{}
+
+	report := NewOrderCleaner(cfg, executor, pm).Run(CleanupTriggerManual, false)
+	if len(executor.batches) != 0 || len(report.Actions) != 0 {
+		t.Fatalf("disabled expiry canceled %v", executor.batches)
+	}
+}
+
+func TestOrderCleanerExpiryDryRun(t *testing.T) {
+	cfg := newExpiryConfig()
+	cfg.Trading.OrderExpiry.Replace = true
+	pm := newCleanerPositionManager(
+		placedSlot(100, 1, "BUY", "grid", 2*time.Hour),
+		placedSlot(101, 2, "SELL", "grid", 2*time.Hour),
+	)
+	executor := &cleanerExecutor{}
+	clock := testutil.NewFakeClock(time.Now())
+	oc := NewOrderCleaner(cfg, executor, pm)
+	oc.afterFunc = clock.AfterFunc
+
+	report := oc.Run(CleanupTriggerManual, true)
+	clock.Advance(time.Minute)
+
+	if len(executor.batches) != 0 {
+		t.Fatalf("dry run canceled %v", executor.batches)
+	}
+	if len(report.Actions) != 2 {
+		t.Fatalf("actions = %d, want 2", len(report.Actions))
+	}
+	for _, action := range report.Actions {
+		if !action.DryRun || action.Reason != storage.CleanupReasonExpired {
+			t.Errorf("action = %+v, want dry-run expired action", action)
+		}
+	}
+	if len(pm.updates) != 0 {
+		t.Errorf("dry run updated slots %v", pm.updates)
+	}
+	if stats := oc.ExpiryStats(); stats != (OrderExpiryStats{}) {
+		t.Errorf("dry run stats = %+v, want zero", stats)
+	}
+	if pm.refreshes != 0 {
+		t.Errorf("dry run refreshed %d times", pm.refreshes)
+	}
+}
+
+func TestOrderCleanerExpiryCancelFailure(t *testing.T) {
+	pm := newCleanerPositionManager(placedSlot(100, 1, "BUY", "grid", 2*time.Hour))
+	executor := &cleanerExecutor{err: errors.New("rate limited")}
+
+	oc := NewOrderCleaner(newExpiryConfig(), executor, pm)
+	report := oc.Run(CleanupTriggerManual, false)
+	if len(report.Actions) != 1 || report.Actions[0].Success || report.Actions[0].Error != "rate limited" {
+		t.Fatalf("actions = %+v, want one failed action", report.Actions)
+	}
+	if len(pm.updates) != 0 {
+		t.Errorf("failed cancel updated slots %v", pm.updates)
+	}
+	if stats := oc.ExpiryStats(); stats != (OrderExpiryStats{}) {
+		t.Errorf("failed cancel stats = %+v, want zero", stats)
+	}
+}
+
+func TestOrderCleanerExpiryReplace(t *testing.T) {
+	cfg := newExpiryConfig()
+	cfg.Trading.OrderExpiry.Replace = true
+	pm := newCleanerPositionManager(placedSlot(100, 1, "BUY", "grid", 2*time.Hour))
+	executor := &cleanerExecutor{}
+	clock := testutil.NewFakeClock(time.Now())
+	oc := NewOrderCleaner(cfg, executor, pm)
+	oc.afterFunc = clock.AfterFunc
+
+	// 重挂在 runMu 之外调度：Run 立即返回，且等待期间可以再次执行清理
+	oc.Run(CleanupTriggerScheduled, false)
+	if pm.refreshes != 0 {
+		t.Fatalf("refreshed before the delay elapsed")
+	}
+	done := make(chan struct{})
+	go func() {
+		oc.Run(CleanupTriggerManual, true)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("manual run blocked behind pending refresh")
+	}
+
+	clock.Advance(oc.refreshDelay - time.Millisecond)
+	if pm.refreshes != 0 {
+		t.Fatalf("refreshed before the delay elapsed")
+	}
+	clock.Advance(time.Millisecond)
+	if pm.refreshes != 1 {
+		t.Fatalf("refreshes = %d, want 1", pm.refreshes)
+	}
+	if stats := oc.ExpiryStats(); stats.Refreshes != 1 || stats.ExpiredBuy != 1 {
+		t.Errorf("stats = %+v, want 1 expired buy and 1 refresh", stats)
+	}
+
+	// 重挂失败不计入统计
+	pm.slots[101] = placedSlot(101, 2, "SELL", "grid", 2*time.Hour)
+	pm.refreshFn = func() error { return errors.New("insufficient margin") }
+	oc.Run(CleanupTriggerScheduled, false)
+	clock.Advance(oc.refreshDelay)
+	if stats := oc.ExpiryStats(); stats.Refreshes != 1 || stats.ExpiredSell != 1 {
+		t.Errorf("stats = %+v, want failed refresh not counted", stats)
+	}
+}
+
+func equalIDs(got, want []int64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// venueOrderSource 把 FakeVenue 适配为 IOrderCleanerExchange（与 main.go 的 positionExchangeAdapter 相同）
+type venueOrderSource struct {
+	venue *testutil.FakeVenue
+}
+
+func (s venueOrderSource) GetOpenOrders(ctx context.Context, symbol string) (interface{}, error) {
+	return s.venue.GetOpenOrders(ctx, symbol)
+}
+
+func TestOrderCleanerPolicies(t *testing.T) {
+	old := time.Now().Add(-time.Hour)
+	botID := func(price float64, side string) string {
+		return utils.AddBrokerPrefix("binance", utils.GenerateOrderID(price, side, 0))
+	}
+	// 交易所挂单：12 是本地槽位跟踪的订单，其余均为孤儿挂单
+	venueOrders := []exchange.Order{
+		{OrderID: 12, ClientOrderID: botID(99, "BUY"), Side: exchange.SideBuy, Price: 99, CreatedAt: old},
+		{OrderID: 21, ClientOrderID: botID(95, "BUY"), Side: exchange.SideBuy, Price: 95, CreatedAt: old},
+		{OrderID: 22, ClientOrderID: "manual-sell-1", Side: exchange.SideSell, Price: 120, CreatedAt: old},
+		{OrderID: 23, ClientOrderID: botID(94, "BUY"), Side: exchange.SideBuy, Price: 94, CreatedAt: time.Now()},
+		{OrderID: 24, ClientOrderID: fmt.Sprintf("93_B_%d001", old.Unix()), Side: exchange.SideBuy, Price: 93}, // 交易所未返回时间，从 ClientOrderID 解析
+		{OrderID: 25, ClientOrderID: "", Side: exchange.SideBuy, Price: 92},                                    // 手动挂单且无法确定时间
+	}
+
+	tests := []struct {
+		name      string
+		policy    string
+		dryRun    bool
+		expiry    bool
+		threshold int
+		want      map[int64]string // 订单ID -> 撤单原因
+	}{
+		{
+			name:   "ignore policy leaves orphans",
+			policy: "ignore",
+			want:   map[int64]string{},
+		},
+		{
+			name:   "bot policy leaves manual orders alone",
+			policy: "bot",
+			want:   map[int64]string{21: storage.CleanupReasonOrphan, 24: storage.CleanupReasonOrphan},
+		},
+		{
+			name:   "all policy includes manual orders",
+			policy: "all",
+			want: map[int64]string{
+				21: storage.CleanupReasonOrphan,
+				22: storage.CleanupReasonOrphan,
+				24: storage.CleanupReasonOrphan,
+			},
+		},
+		{
+			name:      "dry run cancels nothing",
+			policy:    "all",
+			dryRun:    true,
+			expiry:    true,
+			threshold: 3,
+			want: map[int64]string{
+				11: storage.CleanupReasonExpired,
+				13: storage.CleanupReasonThreshold,
+				21: storage.CleanupReasonOrphan,
+				22: storage.CleanupReasonOrphan,
+				24: storage.CleanupReasonOrphan,
+			},
+		},
+		{
+			name:      "every cleanup path records its reason",
+			policy:    "bot",
+			expiry:    true,
+			threshold: 3,
+			want: map[int64]string{
+				11: storage.CleanupReasonExpired,
+				13: storage.CleanupReasonThreshold,
+				21: storage.CleanupReasonOrphan,
+				24: storage.CleanupReasonOrphan,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newExpiryConfig()
+			cfg.Trading.OrderExpiry.Enabled = tt.expiry
+			cfg.Trading.OrderCleaner.OrphanPolicy = tt.policy
+			cfg.Trading.OrderCleaner.DryRun = tt.dryRun
+			cfg.Trading.OrderCleaner.OrphanMinAgeMinutes = 10
+			cfg.Trading.CleanupBatchSize = 1
+			if tt.threshold > 0 {
+				cfg.Trading.OrderCleanupThreshold = tt.threshold
+			}
+
+			// 槽位：11 已过期；剩余 3 个挂单达到阈值 3 时撤销价格最低的买单 13
+			pm := newCleanerPositionManager(
+				placedSlot(100, 11, "BUY", "grid", 2*time.Hour),
+				placedSlot(99, 12, "BUY", "grid", time.Minute),
+				placedSlot(98, 13, "BUY", "grid", time.Minute),
+				placedSlot(101, 14, "SELL", "grid", time.Minute),
+			)
+			venue := testutil.NewFakeVenue("binance", 0, 3)
+			for _, o := range venueOrders {
+				o.Symbol = "BTCUSDT"
+				o.Quantity = 0.01
+				venue.AddOpenOrder(o)
+			}
+			executor := testutil.NewFakeExecutor()
+			recorder := &cleanupRecorder{}
+			oc := NewOrderCleaner(cfg, executor, pm)
+			oc.SetExchange(venueOrderSource{venue: venue})
+			oc.SetRecorder(recorder)
+
+			oc.CleanupOrders()
+
+			canceled := executor.CanceledOrderIDs()
+			if tt.dryRun {
+				if len(canceled) != 0 {
+					t.Fatalf("dry run canceled %v", canceled)
+				}
+				if len(pm.updates) != 0 {
+					t.Errorf("dry run updated slots %v", pm.updates)
+				}
+			} else {
+				sort.Slice(canceled, func(i, j int) bool { return canceled[i] < canceled[j] })
+				var want []int64
+				for id := range tt.want {
+					want = append(want, id)
+				}
+				sort.Slice(want, func(i, j int) bool { return want[i] < want[j] })
+				if !equalIDs(canceled, want) {
+					t.Fatalf("canceled = %v, want %v", canceled, want)
+				}
+			}
+
+			if len(recorder.actions) != len(tt.want) {
+				t.Fatalf("recorded %d actions, want %d: %+v", len(recorder.actions), len(tt.want), recorder.actions)
+			}
+			for _, action := range recorder.actions {
+				reason, ok := tt.want[action.OrderID]
+				if !ok {
+					t.Errorf("unexpected action for order %d: %+v", action.OrderID, action)
+					continue
+				}
+				if action.Reason != reason || action.Detail == "" {
+					t.Errorf("order %d reason = %q (detail %q), want %q with detail", action.OrderID, action.Reason, action.Detail, reason)
+				}
+				if action.DryRun != tt.dryRun || action.Trigger != CleanupTriggerScheduled || !action.Success {
+					t.Errorf("order %d action = %+v", action.OrderID, action)
+				}
+				if action.Exchange != "binance" || action.Symbol != "BTCUSDT" {
+					t.Errorf("order %d scope = %s/%s", action.OrderID, action.Exchange, action.Symbol)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderCleanerOrphanQueryFailure(t *testing.T) {
+	cfg := newExpiryConfig()
+	cfg.Trading.OrderExpiry.Enabled = false
+	cfg.Trading.OrderCleaner.OrphanPolicy = "all"
+	venue := testutil.NewFakeVenue("binance", 0, 3)
+	venue.FailOn("GetOpenOrders", errors.New("timeout"))
+	executor := testutil.NewFakeExecutor()
+	oc := NewOrderCleaner(cfg, executor, newCleanerPositionManager())
+	oc.SetExchange(venueOrderSource{venue: venue})
+
+	report := oc.Run(CleanupTriggerManual, false)
+	if report.OrphanError != "timeout" || len(report.Actions) != 0 || len(executor.CanceledOrderIDs()) != 0 {
+		t.Fatalf("report = %+v, want orphan error and no actions", report)
+	}
+}
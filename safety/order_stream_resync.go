@@ -0,0 +1,233 @@
+package safety
+
+import (
+	"context"
+	"fmt"
+	"quantmesh/event"
+	"quantmesh/exchange"
+	"quantmesh/logger"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// IOrderResyncExchange 订单补查所需的交易所接口
+type IOrderResyncExchange interface {
+	GetOrder(ctx context.Context, symbol string, orderID int64) (*exchange.Order, error)
+}
+
+// IOrderResyncPositionManager 订单补查所需的仓位管理器接口
+type IOrderResyncPositionManager interface {
+	IterateSlots(fn func(price float64, slot interface{}) bool)
+}
+
+// OrderResyncReport 一次订单流断线补查的结果
+type OrderResyncReport struct {
+	Exchange       string        `json:"exchange"`
+	Symbol         string        `json:"symbol"`
+	DisconnectedAt time.Time     `json:"disconnected_at"`
+	ReconnectedAt  time.Time     `json:"reconnected_at"`
+	Gap            time.Duration `json:"gap"`      // 断线时长
+	Checked        int           `json:"checked"`  // 补查的本地有效订单数
+	Changed        int           `json:"changed"`  // 状态与本地不一致、已按补查结果更新的订单数
+	Failed         int           `json:"failed"`   // 查询失败的订单数（等待下次对账修正）
+	Skipped        int           `json:"skipped"`  // 补查期间已收到更新推送、无需覆盖的订单数
+	Duration       time.Duration `json:"duration"` // 补查耗时
+	FinishedAt     time.Time     `json:"finished_at"`
+}
+
+// OrderStreamStats 订单流推送与补查统计
+type OrderStreamStats struct {
+	LastUpdateAt time.Time          `json:"last_update_at"` // 最近一次收到本交易对订单推送的时间
+	Updates      int64              `json:"updates"`
+	Gaps         int64              `json:"gaps"` // 检测到的断线次数
+	LastResync   *OrderResyncReport `json:"last_resync,omitempty"`
+}
+
+// OrderStreamResyncer 订单流断线检测与 REST 补查
+// 记录每个订单最近一次推送的更新时间；订单流断线恢复后，逐个查询本地仍认为有效的订单，
+// 把状态有变化的订单按推送的方式交给仓位管理器处理，并发布补查事件
+type OrderStreamResyncer struct {
+	exchangeName string
+	symbol       string
+	ex           IOrderResyncExchange
+	pm           IOrderResyncPositionManager
+	apply        func(order *exchange.Order) // 将补查结果当作订单推送处理
+	eventBus     *event.EventBus
+
+	mu            sync.Mutex
+	orderUpdateAt map[int64]int64 // 订单ID -> 最近一次推送的交易所更新时间（毫秒）
+	stats         OrderStreamStats
+
+	// 同一时间只运行一次补查，断线恢复期间的重复通知合并
+	resyncMu sync.Mutex
+}
+
+// NewOrderStreamResyncer 创建订单流补查器
+func NewOrderStreamResyncer(exchangeName, symbol string, ex IOrderResyncExchange, pm IOrderResyncPositionManager, apply func(order *exchange.Order)) *OrderStreamResyncer {
+	return &OrderStreamResyncer{
+		exchangeName:  exchangeName,
+		symbol:        symbol,
+		ex:            ex,
+		pm:            pm,
+		apply:         apply,
+		orderUpdateAt: make(map[int64]int64),
+	}
+}
+
+// SetEventBus 设置事件总线（可选）
+func (r *OrderStreamResyncer) SetEventBus(eventBus *event.EventBus) {
+	r.eventBus = eventBus
+}
+
+// OnOrderUpdate 记录一次订单推送
+func (r *OrderStreamResyncer) OnOrderUpdate(orderID int64, updateTime int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.LastUpdateAt = time.Now()
+	r.stats.Updates++
+	if orderID != 0 && updateTime > r.orderUpdateAt[orderID] {
+		r.orderUpdateAt[orderID] = updateTime
+	}
+}
+
+// OnReconnect 订单流断线恢复，异步补查断线期间可能丢失推送的订单
+func (r *OrderStreamResyncer) OnReconnect(notice exchange.OrderStreamReconnect) {
+	r.mu.Lock()
+	r.stats.Gaps++
+	r.mu.Unlock()
+	logger.Warn("⚠️ [%s] 订单流断线 %v 后恢复，开始补查本地有效订单",
+		r.symbol, notice.ReconnectedAt.Sub(notice.DisconnectedAt).Round(time.Second))
+	go r.Resync(notice)
+}
+
+// Stats 返回订单流统计
+func (r *OrderStreamResyncer) Stats() OrderStreamStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.stats
+	if stats.LastResync != nil {
+		last := *stats.LastResync
+		stats.LastResync = &last
+	}
+	return stats
+}
+
+// resyncOrder 本地有效订单
+type resyncOrder struct {
+	price   float64
+	orderID int64
+	status  string
+	filled  float64
+}
+
+// activeOrders 收集本地仍认为有效的订单
+func (r *OrderStreamResyncer) activeOrders() []resyncOrder {
+	var orders []resyncOrder
+	r.pm.IterateSlots(func(price float64, slotRaw interface{}) bool {
+		v := reflect.ValueOf(slotRaw)
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return true
+		}
+		var o resyncOrder
+		o.price = price
+		if f := v.FieldByName("OrderID"); f.IsValid() && f.CanInt() {
+			o.orderID = f.Int()
+		}
+		if f := v.FieldByName("OrderStatus"); f.IsValid() && f.Kind() == reflect.String {
+			o.status = f.String()
+		}
+		if f := v.FieldByName("OrderFilledQty"); f.IsValid() && f.CanFloat() {
+			o.filled = f.Float()
+		}
+		switch o.status {
+		case "PLACED", "CONFIRMED", "PARTIALLY_FILLED", "CANCEL_REQUESTED":
+			if o.orderID != 0 {
+				orders = append(orders, o)
+			}
+		}
+		return true
+	})
+	return orders
+}
+
+// Resync 逐个查询本地有效订单，状态有变化的按推送处理
+func (r *OrderStreamResyncer) Resync(notice exchange.OrderStreamReconnect) *OrderResyncReport {
+	r.resyncMu.Lock()
+	defer r.resyncMu.Unlock()
+
+	start := time.Now()
+	report := &OrderResyncReport{
+		Exchange:       r.exchangeName,
+		Symbol:         r.symbol,
+		DisconnectedAt: notice.DisconnectedAt,
+		ReconnectedAt:  notice.ReconnectedAt,
+		Gap:            notice.ReconnectedAt.Sub(notice.DisconnectedAt),
+	}
+
+	for _, local := range r.activeOrders() {
+		report.Checked++
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		order, err := r.ex.GetOrder(ctx, r.symbol, local.orderID)
+		cancel()
+		if err != nil || order == nil {
+			report.Failed++
+			logger.Warn("⚠️ [%s] 补查订单 %d 失败: %v", r.symbol, local.orderID, err)
+			continue
+		}
+
+		// 补查期间已收到更新的推送，以推送为准
+		r.mu.Lock()
+		pushedAt := r.orderUpdateAt[local.orderID]
+		r.mu.Unlock()
+		if order.UpdateTime > 0 && pushedAt >= order.UpdateTime {
+			report.Skipped++
+			continue
+		}
+
+		if string(order.Status) == local.status && order.ExecutedQty == local.filled {
+			continue
+		}
+		if order.Status == exchange.OrderStatusNew && (local.status == "PLACED" || local.status == "CONFIRMED") {
+			continue
+		}
+		report.Changed++
+		logger.Warn("🔄 [%s] 补查订单 %d (价位 %.8f): 本地 %s → 交易所 %s (成交 %.8f)",
+			r.symbol, local.orderID, local.price, local.status, order.Status, order.ExecutedQty)
+		if r.apply != nil {
+			r.apply(order)
+		}
+		r.OnOrderUpdate(order.OrderID, order.UpdateTime)
+	}
+
+	report.FinishedAt = time.Now()
+	report.Duration = report.FinishedAt.Sub(start)
+	r.mu.Lock()
+	r.stats.LastResync = report
+	r.mu.Unlock()
+
+	logger.Info("✅ [%s] 订单流补查完成: 查询 %d 个，更新 %d 个，失败 %d 个，耗时 %v",
+		r.symbol, report.Checked, report.Changed, report.Failed, report.Duration.Round(time.Millisecond))
+
+	if r.eventBus != nil {
+		r.eventBus.Publish(&event.Event{
+			Type:      event.EventTypeOrderStreamResync,
+			Timestamp: time.Now(),
+			Data: map[string]interface{}{
+				"exchange":        r.exchangeName,
+				"symbol":          r.symbol,
+				"disconnected_at": notice.DisconnectedAt,
+				"gap_seconds":     report.Gap.Seconds(),
+				"checked":         report.Checked,
+				"changed":         report.Changed,
+				"failed":          report.Failed,
+				"message": fmt.Sprintf("订单流断线 %v，补查 %d 个订单，%d 个状态已更新，%d 个查询失败",
+					report.Gap.Round(time.Second), report.Checked, report.Changed, report.Failed),
+			},
+		})
+	}
+	return report
+}
@@ -0,0 +1,65 @@
+package safety
+
+import (
+	"context"
+	"fmt"
+	"quantmesh/exchange"
+	"testing"
+	"time"
+)
+
+type resyncSlot struct {
+	OrderID        int64
+	OrderStatus    string
+	OrderFilledQty float64
+}
+
+type mockResyncExchange struct {
+	orders map[int64]*exchange.Order
+	calls  int
+}
+
+func (m *mockResyncExchange) GetOrder(ctx context.Context, symbol string, orderID int64) (*exchange.Order, error) {
+	m.calls++
+	if o, ok := m.orders[orderID]; ok {
+		return o, nil
+	}
+	return nil, fmt.Errorf("订单 %d 不存在", orderID)
+}
+
+func TestOrderStreamResyncer_Resync(t *testing.T) {
+	pm := &MockPositionManager{Slots: map[float64]interface{}{
+		100.0: resyncSlot{OrderID: 1, OrderStatus: "PLACED"},    // 断线期间已成交
+		99.0:  resyncSlot{OrderID: 2, OrderStatus: "PLACED"},    // 仍在挂单
+		98.0:  resyncSlot{OrderID: 3, OrderStatus: "CONFIRMED"}, // 查询失败
+		97.0:  resyncSlot{OrderID: 4, OrderStatus: "PLACED"},    // 补查前已收到更新的推送
+		96.0:  resyncSlot{OrderID: 5, OrderStatus: "FILLED"},    // 已终结，不补查
+		95.0:  resyncSlot{OrderID: 0, OrderStatus: ""},          // 空槽位
+	}}
+	ex := &mockResyncExchange{orders: map[int64]*exchange.Order{
+		1: {OrderID: 1, Status: exchange.OrderStatusFilled, ExecutedQty: 0.1, UpdateTime: 2000},
+		2: {OrderID: 2, Status: exchange.OrderStatusNew, UpdateTime: 1000},
+		4: {OrderID: 4, Status: exchange.OrderStatusCanceled, UpdateTime: 1500},
+	}}
+	var applied []int64
+	r := NewOrderStreamResyncer("binance", "BTCUSDT", ex, pm, func(o *exchange.Order) {
+		applied = append(applied, o.OrderID)
+	})
+	r.OnOrderUpdate(4, 1600)
+
+	now := time.Now()
+	report := r.Resync(exchange.OrderStreamReconnect{DisconnectedAt: now.Add(-time.Minute), ReconnectedAt: now})
+
+	if report.Checked != 4 || report.Changed != 1 || report.Failed != 1 || report.Skipped != 1 {
+		t.Fatalf("补查结果不符: %+v", report)
+	}
+	if len(applied) != 1 || applied[0] != 1 {
+		t.Errorf("只有订单 1 应按补查结果处理, 得到 %v", applied)
+	}
+	if report.Gap != time.Minute {
+		t.Errorf("断线时长应为 1m, 得到 %v", report.Gap)
+	}
+	if stats := r.Stats(); stats.LastResync == nil || stats.LastResync.Changed != 1 {
+		t.Errorf("统计中应记录最近一次补查: %+v", stats)
+	}
+}
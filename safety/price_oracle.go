@@ -0,0 +1,277 @@
+package safety
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+	"quantmesh/metrics"
+)
+
+// PriceOracle 第二价格源（用于与主行情交叉校验）
+type PriceOracle interface {
+	GetPrice(ctx context.Context, symbol string) (float64, error)
+	Name() string
+}
+
+// NewPriceOracle 根据配置创建第二价格源（均为交易所公开行情接口，无需密钥）
+func NewPriceOracle(cfg *config.Config) (PriceOracle, error) {
+	oc := cfg.RiskControl.PriceOracle
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	switch strings.ToLower(oc.Source) {
+	case "", "binance":
+		return &publicTickerOracle{name: "binance", client: client, symbolMap: oc.SymbolMap,
+			buildURL: func(s string) string {
+				return "https://fapi.binance.com/fapi/v1/ticker/price?symbol=" + s
+			},
+			parse: parseBinanceTicker,
+		}, nil
+	case "okx":
+		return &publicTickerOracle{name: "okx", client: client, symbolMap: oc.SymbolMap, convert: okxInstID,
+			buildURL: func(s string) string {
+				return "https://www.okx.com/api/v5/market/ticker?instId=" + s
+			},
+			parse: parseOKXTicker,
+		}, nil
+	case "bybit":
+		return &publicTickerOracle{name: "bybit", client: client, symbolMap: oc.SymbolMap,
+			buildURL: func(s string) string {
+				return "https://api.bybit.com/v5/market/tickers?category=linear&symbol=" + s
+			},
+			parse: parseBybitTicker,
+		}, nil
+	default:
+		return nil, fmt.Errorf("不支持的价格源: %s（可选 binance、okx、bybit）", oc.Source)
+	}
+}
+
+// publicTickerOracle 基于交易所公开 ticker 接口的价格源
+type publicTickerOracle struct {
+	name      string
+	client    *http.Client
+	symbolMap map[string]string
+	convert   func(symbol string) string
+	buildURL  func(symbol string) string
+	parse     func(body []byte) (float64, error)
+}
+
+func (o *publicTickerOracle) Name() string {
+	return o.name
+}
+
+func (o *publicTickerOracle) GetPrice(ctx context.Context, symbol string) (float64, error) {
+	remote := symbol
+	if mapped, ok := o.symbolMap[symbol]; ok && mapped != "" {
+		remote = mapped
+	} else if o.convert != nil {
+		remote = o.convert(symbol)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", o.buildURL(remote), nil)
+	if err != nil {
+		return 0, fmt.Errorf("创建请求失败: %w", err)
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("请求 %s 行情失败: %w", o.name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%s 行情接口返回错误: %d", o.name, resp.StatusCode)
+	}
+
+	var body json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("解析 %s 行情失败: %w", o.name, err)
+	}
+	price, err := o.parse(body)
+	if err != nil {
+		return 0, fmt.Errorf("解析 %s 行情失败: %w", o.name, err)
+	}
+	if price <= 0 {
+		return 0, fmt.Errorf("%s 返回无效价格: %v", o.name, price)
+	}
+	return price, nil
+}
+
+func parseBinanceTicker(body []byte) (float64, error) {
+	var t struct {
+		Price string `json:"price"`
+	}
+	if err := json.Unmarshal(body, &t); err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(t.Price, 64)
+}
+
+func parseOKXTicker(body []byte) (float64, error) {
+	var t struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Last string `json:"last"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &t); err != nil {
+		return 0, err
+	}
+	if t.Code != "0" || len(t.Data) == 0 {
+		return 0, fmt.Errorf("code=%s msg=%s", t.Code, t.Msg)
+	}
+	return strconv.ParseFloat(t.Data[0].Last, 64)
+}
+
+func parseBybitTicker(body []byte) (float64, error) {
+	var t struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+		Result  struct {
+			List []struct {
+				LastPrice string `json:"lastPrice"`
+			} `json:"list"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &t); err != nil {
+		return 0, err
+	}
+	if t.RetCode != 0 || len(t.Result.List) == 0 {
+		return 0, fmt.Errorf("retCode=%d msg=%s", t.RetCode, t.RetMsg)
+	}
+	return strconv.ParseFloat(t.Result.List[0].LastPrice, 64)
+}
+
+// okxInstID BTCUSDT -> BTC-USDT-SWAP
+func okxInstID(symbol string) string {
+	s := strings.ToUpper(strings.NewReplacer("-", "", "_", "", "/", "").Replace(symbol))
+	for _, quote := range []string{"USDT", "USDC", "USD"} {
+		if strings.HasSuffix(s, quote) && len(s) > len(quote) {
+			return s[:len(s)-len(quote)] + "-" + quote + "-SWAP"
+		}
+	}
+	return symbol
+}
+
+// oracleState 价格源交叉校验状态
+type oracleState struct {
+	breaches   int // 连续超限次数
+	recoveries int // 连续恢复次数
+	triggered  bool
+	lastOracle float64
+	lastDiv    float64
+}
+
+// evaluate 根据一次校验结果更新状态，返回状态是否变化
+// 连续 confirm 次超限才触发；恢复要求偏离回到阈值一半以内并同样连续 confirm 次，避免来回抖动
+func (s *oracleState) evaluate(primary, oracle, maxDivergence float64, confirm int) bool {
+	divergence := math.Abs(primary-oracle) / oracle
+	s.lastOracle = oracle
+	s.lastDiv = divergence
+
+	if !s.triggered {
+		if divergence > maxDivergence {
+			s.breaches++
+		} else {
+			s.breaches = 0
+		}
+		if s.breaches >= confirm {
+			s.triggered = true
+			s.breaches = 0
+			s.recoveries = 0
+			return true
+		}
+		return false
+	}
+
+	if divergence <= maxDivergence/2 {
+		s.recoveries++
+	} else {
+		s.recoveries = 0
+	}
+	if s.recoveries >= confirm {
+		s.triggered = false
+		s.recoveries = 0
+		return true
+	}
+	return false
+}
+
+// SetPriceOracle 设置第二价格源及主行情价格获取函数，启动后定期交叉校验
+func (r *RiskMonitor) SetPriceOracle(oracle PriceOracle, symbol string, primaryPrice func() float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.oracle = oracle
+	r.oracleSymbol = symbol
+	r.primaryPrice = primaryPrice
+}
+
+// oracleLoop 定期比较主行情与第二价格源
+func (r *RiskMonitor) oracleLoop(ctx context.Context) {
+	oc := r.cfg.RiskControl.PriceOracle
+	interval := time.Duration(oc.Interval) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	logger.Info("🛡️ [%s] 价格源交叉校验已启动 (价格源: %s, 最大偏离: %.2f%%, 间隔: %v)",
+		r.oracleSymbol, r.oracle.Name(), oc.MaxDivergence*100, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.checkOracle(ctx)
+		}
+	}
+}
+
+func (r *RiskMonitor) checkOracle(ctx context.Context) {
+	primary := r.primaryPrice()
+	if primary <= 0 {
+		return
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	oraclePrice, err := r.oracle.GetPrice(reqCtx, r.oracleSymbol)
+	cancel()
+	if err != nil {
+		// 第二价格源不可用不应影响交易，仅记录
+		logger.Debug("⚠️ [%s] 价格源交叉校验失败: %v", r.oracleSymbol, err)
+		return
+	}
+
+	oc := r.cfg.RiskControl.PriceOracle
+	confirm := oc.ConfirmCount
+	if confirm <= 0 {
+		confirm = 2
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.oracleState.evaluate(primary, oraclePrice, oc.MaxDivergence, confirm) {
+		return
+	}
+	if r.oracleState.triggered {
+		r.triggeredTime = time.Now()
+		r.lastMsg = fmt.Sprintf("主行情 %.8f 与 %s 价格 %.8f 偏离 %.2f%%，暂停交易",
+			primary, r.oracle.Name(), oraclePrice, r.oracleState.lastDiv*100)
+		logger.Error("🚨 [%s] %s", r.oracleSymbol, r.lastMsg)
+		if r.exchange != nil {
+			metrics.GetPrometheusMetrics().RecordRiskControlTrigger(r.exchange.GetName(), r.oracleSymbol, "price_oracle_divergence")
+		}
+	} else {
+		r.recoveredTime = time.Now()
+		r.lastMsg = "价格源交叉校验恢复正常"
+		logger.Info("✅ [%s] 主行情与 %s 价格偏离已恢复 (%.2f%%)，恢复交易",
+			r.oracleSymbol, r.oracle.Name(), r.oracleState.lastDiv*100)
+	}
+}
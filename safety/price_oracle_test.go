@@ -0,0 +1,81 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"quantmesh/config"
+)
+
+type fakeOracle struct {
+	price float64
+	err   error
+}
+
+func (f *fakeOracle) GetPrice(ctx context.Context, symbol string) (float64, error) {
+	return f.price, f.err
+}
+
+func (f *fakeOracle) Name() string { return "fake" }
+
+func TestPriceOracleDivergencePausesAndRecovers(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.RiskControl.PriceOracle.MaxDivergence = 0.01
+	cfg.RiskControl.PriceOracle.ConfirmCount = 2
+
+	primary := 100.0
+	oracle := &fakeOracle{price: 100}
+	rm := NewRiskMonitor(cfg, nil)
+	rm.SetPriceOracle(oracle, "BTCUSDT", func() float64 { return primary })
+	ctx := context.Background()
+
+	// 单次偏离不触发
+	primary = 103
+	rm.checkOracle(ctx)
+	if rm.IsTriggered() {
+		t.Fatalf("single divergence should not pause trading")
+	}
+	rm.checkOracle(ctx)
+	if !rm.IsTriggered() {
+		t.Fatalf("sustained divergence should pause trading")
+	}
+
+	// 价格源故障不改变状态
+	oracle.err = errors.New("timeout")
+	primary = 100
+	rm.checkOracle(ctx)
+	rm.checkOracle(ctx)
+	if !rm.IsTriggered() {
+		t.Fatalf("oracle errors should not resume trading")
+	}
+	oracle.err = nil
+
+	// 偏离仍高于阈值一半时不恢复
+	primary = 100.8
+	rm.checkOracle(ctx)
+	rm.checkOracle(ctx)
+	if !rm.IsTriggered() {
+		t.Fatalf("should stay paused until divergence falls below half the threshold")
+	}
+
+	primary = 100.2
+	rm.checkOracle(ctx)
+	rm.checkOracle(ctx)
+	if rm.IsTriggered() {
+		t.Fatalf("should resume after divergence recovers")
+	}
+}
+
+func TestOKXInstID(t *testing.T) {
+	cases := map[string]string{
+		"BTCUSDT":  "BTC-USDT-SWAP",
+		"ETH-USDC": "ETH-USDC-SWAP",
+		"SOLUSD":   "SOL-USD-SWAP",
+	}
+	for in, want := range cases {
+		if got := okxInstID(in); got != want {
+			t.Errorf("okxInstID(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
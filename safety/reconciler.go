@@ -2,12 +2,17 @@ package safety
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"quantmesh/config"
+	"quantmesh/event"
+	"quantmesh/exchange"
 	"quantmesh/lock"
 	"quantmesh/logger"
+	"quantmesh/utils"
 	"reflect"
+	"sort"
 	"sync"
 	"time"
 )
@@ -56,6 +61,79 @@ type ReconciliationStorage interface {
 		activeBuyOrders, activeSellOrders int, pendingSellQty, totalBuyQty, totalSellQty, estimatedProfit float64) error
 }
 
+// 槽位比对结果
+const (
+	SlotDiffOK                = "ok"
+	SlotDiffMissingOnExchange = "missing_on_exchange" // 本地认为挂单有效，交易所挂单列表中没有（可能已成交或被撤销而未收到推送）
+	SlotDiffSideMismatch      = "side_mismatch"       // 同一订单号在本地与交易所的方向不一致
+	SlotDiffStaleLocal        = "stale_local"         // 交易所仍有挂单，本地槽位已不认为该订单有效
+)
+
+// 持仓差异等级
+const (
+	ReconcileSeverityOK       = "ok"
+	ReconcileSeverityDust     = "dust"     // 差异在容差内：只累计统计，不告警、不同步
+	ReconcileSeverityWarning  = "warning"  // 超过容差：记录警告，交易所持仓清空时强制同步
+	ReconcileSeverityCritical = "critical" // 超过 reconcile_divergence_ratio：发布严重事件，需人工介入
+)
+
+// reconcileEpsilon 浮点误差下限，容差不会小于该值
+const reconcileEpsilon = 0.00000001
+
+// DustStats 容差内粉尘差异的累计统计
+type DustStats struct {
+	Current     float64   `json:"current"`     // 最近一次对账的粉尘差异（本地 - 交易所），超出容差或一致时为 0
+	Max         float64   `json:"max"`         // 出现过的最大粉尘差异（绝对值）
+	Accumulated float64   `json:"accumulated"` // 粉尘差异的累计变化量，持续增长说明存在系统性偏差
+	Count       int64     `json:"count"`       // 差异落在容差内的对账次数
+	LastTime    time.Time `json:"last_time"`   // 最近一次记为粉尘的时间
+}
+
+// ReconcileOrder 交易所挂单
+type ReconcileOrder struct {
+	OrderID       int64   `json:"order_id"`
+	ClientOrderID string  `json:"client_order_id"`
+	Side          string  `json:"side"`
+	Price         float64 `json:"price"`
+	Quantity      float64 `json:"quantity"`
+	ExecutedQty   float64 `json:"executed_qty"`
+	Status        string  `json:"status"`
+}
+
+// SlotReconcileDiff 单个槽位的本地订单与交易所挂单比对结果
+type SlotReconcileDiff struct {
+	Price          float64         `json:"price"`
+	PositionStatus string          `json:"position_status"`
+	PositionQty    float64         `json:"position_qty"`
+	LocalOrderID   int64           `json:"local_order_id"`
+	LocalSide      string          `json:"local_side"`
+	LocalStatus    string          `json:"local_status"`
+	ExchangeOrder  *ReconcileOrder `json:"exchange_order,omitempty"`
+	Status         string          `json:"status"` // ok / missing_on_exchange / side_mismatch / stale_local
+}
+
+// ReconcileReport 一次对账的详细差异报告
+type ReconcileReport struct {
+	Exchange           string               `json:"exchange"`
+	Symbol             string               `json:"symbol"`
+	Time               time.Time            `json:"time"`
+	InSync             bool                 `json:"in_sync"`
+	LocalPosition      float64              `json:"local_position"`
+	ExchangePosition   float64              `json:"exchange_position"`
+	PositionDelta      float64              `json:"position_delta"` // 本地 - 交易所
+	Tolerance          float64              `json:"tolerance"`      // 本次对账使用的容差
+	Severity           string               `json:"severity"`       // ok / dust / warning / critical
+	Dust               DustStats            `json:"dust"`
+	ForcedSync         bool                 `json:"forced_sync"` // 交易所持仓已清空，本地已强制同步
+	ActiveBuyOrders    int                  `json:"active_buy_orders"`
+	ActiveSellOrders   int                  `json:"active_sell_orders"`
+	PendingSellQty     float64              `json:"pending_sell_qty"`
+	ExchangeOpenOrders int                  `json:"exchange_open_orders"`
+	MismatchedSlots    int                  `json:"mismatched_slots"`
+	Slots              []*SlotReconcileDiff `json:"slots"`           // 有挂单或持仓的槽位
+	OrphanedOrders     []*ReconcileOrder    `json:"orphaned_orders"` // 交易所有、本地槽位没有的挂单
+}
+
 // Reconciler 持仓对账器
 type Reconciler struct {
 	cfg                  *config.Config
@@ -63,10 +141,13 @@ type Reconciler struct {
 	pm                   IPositionManager
 	pauseChecker         func() bool
 	storage              ReconciliationStorage // 可选的存储服务
+	eventBus             *event.EventBus       // 可选，持仓严重偏差时发布事件
 	lock                 lock.DistributedLock  // 分布式锁
 	lastReconcileTime    time.Time             // 上次对账时间
 	reconcileMu          sync.Mutex            // 对账互斥锁
 	minReconcileInterval time.Duration         // 最小对账间隔（防止频繁调用）
+	dust                 DustStats             // 粉尘差异累计统计
+	dustMu               sync.Mutex
 }
 
 // NewReconciler 创建对账器
@@ -92,6 +173,11 @@ func (r *Reconciler) SetStorage(storage ReconciliationStorage) {
 	r.storage = storage
 }
 
+// SetEventBus 设置事件总线（可选）
+func (r *Reconciler) SetEventBus(eventBus *event.EventBus) {
+	r.eventBus = eventBus
+}
+
 // SetPauseChecker 设置暂停检查函数（用于风控暂停）
 func (r *Reconciler) SetPauseChecker(checker func() bool) {
 	r.pauseChecker = checker
@@ -99,7 +185,7 @@ func (r *Reconciler) SetPauseChecker(checker func() bool) {
 
 // Start 启动对账协程
 func (r *Reconciler) Start(ctx context.Context) {
-	go func() {
+	utils.GoSupervised(ctx, "reconciler", func(ctx context.Context) {
 		interval := time.Duration(r.cfg.Trading.ReconcileInterval) * time.Second
 		if interval <= 0 {
 			interval = 30 * time.Second
@@ -114,25 +200,44 @@ func (r *Reconciler) Start(ctx context.Context) {
 				return
 			case <-ticker.C:
 				if err := r.Reconcile(); err != nil {
-					logger.Error("❌ [对账失败] %v", err)
+					switch {
+					case errors.Is(err, exchange.ErrRateLimited):
+						// 限流时跳过本轮，下个周期再对账，避免加重限流
+						logger.Warn("⚠️ [对账跳过] 交易所限流: %v", err)
+					case errors.Is(err, exchange.ErrRestrictedRegion):
+						logger.Error("❌ [对账失败] 当前网络所在地区被交易所限制，请检查网络或代理: %v", err)
+					default:
+						logger.Error("❌ [对账失败] %v", err)
+					}
 				}
 			}
 		}
-	}()
+	})
 	logger.Info("✅ 持仓对账已启动 (间隔: %d秒)", r.cfg.Trading.ReconcileInterval)
 }
 
 // Reconcile 执行对账（通用实现，支持所有交易所）
 func (r *Reconciler) Reconcile() error {
+	_, err := r.reconcile(false)
+	return err
+}
+
+// RunNow 立即执行一次对账并返回详细差异报告（不等待最小对账间隔，风控暂停时也执行）
+func (r *Reconciler) RunNow() (*ReconcileReport, error) {
+	return r.reconcile(true)
+}
+
+// reconcile 执行对账；force 为 true 时跳过暂停检查和最小间隔等待，获取锁失败时返回错误
+func (r *Reconciler) reconcile(force bool) (*ReconcileReport, error) {
 	// 检查是否暂停（风控触发时不输出日志）
-	if r.pauseChecker != nil && r.pauseChecker() {
-		return nil
+	if !force && r.pauseChecker != nil && r.pauseChecker() {
+		return nil, nil
 	}
 
 	// 速率限制：确保最小对账间隔
 	r.reconcileMu.Lock()
 	elapsed := time.Since(r.lastReconcileTime)
-	if elapsed < r.minReconcileInterval {
+	if !force && elapsed < r.minReconcileInterval {
 		waitTime := r.minReconcileInterval - elapsed
 		r.reconcileMu.Unlock()
 		logger.Debug("⏳ [对账] 等待 %v 后执行（最小间隔限制）", waitTime)
@@ -161,7 +266,10 @@ func (r *Reconciler) Reconcile() error {
 	err := r.lock.Lock(ctx, lockKey, 30*time.Second)
 	if err != nil {
 		logger.Warn("⚠️ [%s] 获取对账锁失败: %v，跳过本次对账", exchangeName, err)
-		return nil // 锁获取失败不返回错误，只是跳过
+		if force {
+			return nil, fmt.Errorf("获取对账锁失败: %w", err)
+		}
+		return nil, nil // 锁获取失败不返回错误，只是跳过
 	}
 	defer func() {
 		if unlockErr := r.lock.Unlock(ctx, lockKey); unlockErr != nil {
@@ -174,13 +282,13 @@ func (r *Reconciler) Reconcile() error {
 	// 1. 查询交易所持仓信息（使用通用接口）
 	positionsRaw, err := r.exchange.GetPositions(context.Background(), symbol)
 	if err != nil {
-		return fmt.Errorf("查询持仓失败: %w", err)
+		return nil, fmt.Errorf("查询持仓失败: %w", err)
 	}
 
 	// 2. 查询所有挂单（使用通用接口）
 	openOrdersRaw, err := r.exchange.GetOpenOrders(context.Background(), symbol)
 	if err != nil {
-		return fmt.Errorf("查询挂单失败: %w", err)
+		return nil, fmt.Errorf("查询挂单失败: %w", err)
 	}
 
 	// 3. 解析持仓和挂单信息（通用处理）
@@ -209,12 +317,39 @@ func (r *Reconciler) Reconcile() error {
 		}
 	}
 
+	// 3b. 按订单号索引交易所挂单，用于逐槽位比对
+	exchangeOrders := make(map[int64]*ReconcileOrder)
+	openOrders, _ := openOrdersRaw.([]*exchange.Order)
+	for _, o := range openOrders {
+		if o == nil || o.OrderID == 0 {
+			continue
+		}
+		exchangeOrders[o.OrderID] = &ReconcileOrder{
+			OrderID:       o.OrderID,
+			ClientOrderID: o.ClientOrderID,
+			Side:          string(o.Side),
+			Price:         o.Price,
+			Quantity:      o.Quantity,
+			ExecutedQty:   o.ExecutedQty,
+			Status:        string(o.Status),
+		}
+	}
+
+	report := &ReconcileReport{
+		Exchange:       exchangeName,
+		Symbol:         symbol,
+		Time:           time.Now(),
+		Slots:          []*SlotReconcileDiff{},
+		OrphanedOrders: []*ReconcileOrder{},
+	}
+
 	// 4. 计算本地持仓统计
 	var localTotal float64
 	var localPendingSellQty float64
 	var localFilledPosition float64
 	var activeBuyOrders int
 	var activeSellOrders int
+	matched := make(map[int64]bool)
 
 	// 订单状态常量（与 position 包保持一致）
 	const (
@@ -249,8 +384,17 @@ func (r *Reconciler) Reconcile() error {
 			return 0.0
 		}
 
+		getInt64Field := func(name string) int64 {
+			field := v.FieldByName(name)
+			if field.IsValid() && field.CanInt() {
+				return field.Int()
+			}
+			return 0
+		}
+
 		positionStatus := getStringField("PositionStatus")
 		positionQty := getFloat64Field("PositionQty")
+		orderID := getInt64Field("OrderID")
 		orderSide := getStringField("OrderSide")
 		orderStatus := getStringField("OrderStatus")
 
@@ -268,11 +412,53 @@ func (r *Reconciler) Reconcile() error {
 			activeBuyOrders++
 		}
 
+		// 逐槽位比对本地订单与交易所挂单
+		localActive := orderID != 0 && (orderStatus == OrderStatusPlaced || orderStatus == OrderStatusConfirmed ||
+			orderStatus == OrderStatusPartiallyFilled)
+		exOrder := exchangeOrders[orderID]
+		if orderID != 0 && exOrder != nil {
+			matched[orderID] = true
+		}
+		if !localActive && exOrder == nil && positionStatus != PositionStatusFilled {
+			return true
+		}
+		slotDiff := &SlotReconcileDiff{
+			Price:          price,
+			PositionStatus: positionStatus,
+			PositionQty:    positionQty,
+			LocalOrderID:   orderID,
+			LocalSide:      orderSide,
+			LocalStatus:    orderStatus,
+			ExchangeOrder:  exOrder,
+			Status:         SlotDiffOK,
+		}
+		switch {
+		case localActive && exOrder == nil:
+			slotDiff.Status = SlotDiffMissingOnExchange
+		case exOrder != nil && orderSide != "" && exOrder.Side != orderSide:
+			slotDiff.Status = SlotDiffSideMismatch
+		case exOrder != nil && !localActive && orderStatus != OrderStatusCancelRequested:
+			slotDiff.Status = SlotDiffStaleLocal
+		}
+		report.Slots = append(report.Slots, slotDiff)
 		return true
 	})
 
 	localTotal = localFilledPosition
 
+	for orderID, o := range exchangeOrders {
+		if !matched[orderID] {
+			report.OrphanedOrders = append(report.OrphanedOrders, o)
+		}
+	}
+	sort.Slice(report.Slots, func(i, j int) bool { return report.Slots[i].Price > report.Slots[j].Price })
+	sort.Slice(report.OrphanedOrders, func(i, j int) bool { return report.OrphanedOrders[i].Price > report.OrphanedOrders[j].Price })
+	for _, slotDiff := range report.Slots {
+		if slotDiff.Status != SlotDiffOK {
+			report.MismatchedSlots++
+		}
+	}
+
 	logger.Debug("📊 [对账统计] 本地持仓: %.4f, 挂单卖单: %d 个 (%.4f), 挂单买单: %d 个",
 		localTotal, activeSellOrders, localPendingSellQty, activeBuyOrders)
 
@@ -292,6 +478,14 @@ func (r *Reconciler) Reconcile() error {
 	logger.Info("📊 [统计] 对账次数: %d, 累计买入: %.2f, 累计卖出: %.2f, 预计盈利: %.2f U",
 		r.pm.GetReconcileCount(), totalBuyQty, totalSellQty, estimatedProfit)
 
+	report.LocalPosition = localTotal
+	report.ExchangePosition = exchangePosition
+	report.PositionDelta = localTotal - exchangePosition
+	report.ActiveBuyOrders = activeBuyOrders
+	report.ActiveSellOrders = activeSellOrders
+	report.PendingSellQty = localPendingSellQty
+	report.ExchangeOpenOrders = len(exchangeOrders)
+
 	// 6. 保存对账历史到数据库（如果存储服务可用）
 	if r.storage != nil {
 		reconcileTime := time.Now()
@@ -303,25 +497,110 @@ func (r *Reconciler) Reconcile() error {
 		}
 	}
 
-	// 7. 检查持仓差异并执行同步
+	// 7. 按容差判定差异等级，只有超过容差才告警和同步
 	diff := math.Abs(localTotal - exchangePosition)
-	// 使用相对较小的阈值，但要考虑到浮点数精度
-	if diff > 0.00000001 {
+	base := math.Max(math.Abs(localTotal), math.Abs(exchangePosition))
+	report.Tolerance = math.Max(r.cfg.RiskControl.ReconcileTolerance.For(symbol).Band(base), reconcileEpsilon)
+	report.Severity = r.classify(diff, base, report.Tolerance)
+	report.Dust = r.trackDust(report.Severity, localTotal-exchangePosition)
+	if report.Severity == ReconcileSeverityDust {
+		logger.Debug("🧹 [对账] 持仓差异 %.8f 在容差 %.8f 内，记为粉尘（累计变化 %.8f）",
+			localTotal-exchangePosition, report.Tolerance, report.Dust.Accumulated)
+	}
+	if report.Severity == ReconcileSeverityWarning || report.Severity == ReconcileSeverityCritical {
 		logger.Warn("🚨 [对账预警] 持仓不一致! 本地: %.6f, 交易所: %.6f, 差异: %.6f",
 			localTotal, exchangePosition, localTotal-exchangePosition)
 
 		// 🔥 自动同步逻辑：如果交易所持仓为0，但本地认为有持仓
 		// 这种情况通常发生在手动平仓、重启程序或订单流丢失时
-		if math.Abs(exchangePosition) < 0.00000001 && math.Abs(localTotal) > 0.00000001 {
+		if math.Abs(exchangePosition) < reconcileEpsilon && math.Abs(localTotal) > reconcileEpsilon {
 			logger.Warn("⚠️ [对账同步] 交易所持仓已清空，正在强制同步本地状态...")
 			r.pm.ForceSyncPositions(0)
+			report.ForcedSync = true
 		} else {
 			// 如果交易所仍有持仓但与本地不符，目前仅记录警告
 			// 自动同步非零持仓较为危险，需要更复杂的槽位重新分配逻辑
 			logger.Warn("💡 [对账建议] 建议检查交易所挂单或重启程序以触发完整持仓恢复")
+			r.checkDivergence(exchangeName, symbol, localTotal, exchangePosition)
 		}
 	}
+	report.InSync = (report.Severity == ReconcileSeverityOK || report.Severity == ReconcileSeverityDust) &&
+		report.MismatchedSlots == 0 && len(report.OrphanedOrders) == 0
 
 	logger.Debugln("🔍 ===== 对账完成 =====")
-	return nil
+	return report, nil
+}
+
+// classify 按容差和严重偏差阈值判定持仓差异等级
+func (r *Reconciler) classify(diff, base, tolerance float64) string {
+	switch {
+	case diff <= reconcileEpsilon:
+		return ReconcileSeverityOK
+	case diff <= tolerance:
+		return ReconcileSeverityDust
+	}
+	threshold := r.cfg.RiskControl.ReconcileDivergenceRatio
+	if threshold <= 0 {
+		threshold = 0.1
+	}
+	if base > 0 && diff/base >= threshold {
+		return ReconcileSeverityCritical
+	}
+	return ReconcileSeverityWarning
+}
+
+// trackDust 累计容差内的粉尘差异，返回当前统计快照
+func (r *Reconciler) trackDust(severity string, signedDiff float64) DustStats {
+	r.dustMu.Lock()
+	defer r.dustMu.Unlock()
+	if severity != ReconcileSeverityDust {
+		r.dust.Current = 0
+		return r.dust
+	}
+	r.dust.Accumulated += math.Abs(signedDiff - r.dust.Current)
+	r.dust.Current = signedDiff
+	r.dust.Max = math.Max(r.dust.Max, math.Abs(signedDiff))
+	r.dust.Count++
+	r.dust.LastTime = time.Now()
+	return r.dust
+}
+
+// DustStats 返回粉尘差异累计统计
+func (r *Reconciler) DustStats() DustStats {
+	r.dustMu.Lock()
+	defer r.dustMu.Unlock()
+	return r.dust
+}
+
+// checkDivergence 偏差占持仓比例超过阈值时发布严重事件（无法自动修复，需人工介入）
+func (r *Reconciler) checkDivergence(exchangeName, symbol string, localTotal, exchangePosition float64) {
+	if r.eventBus == nil {
+		return
+	}
+	threshold := r.cfg.RiskControl.ReconcileDivergenceRatio
+	if threshold <= 0 {
+		threshold = 0.1
+	}
+	base := math.Max(math.Abs(localTotal), math.Abs(exchangePosition))
+	if base == 0 {
+		return
+	}
+	ratio := math.Abs(localTotal-exchangePosition) / base
+	if ratio < threshold {
+		return
+	}
+
+	logger.Error("🚨 [对账告警] 持仓偏差 %.2f%% 超过阈值 %.2f%%", ratio*100, threshold*100)
+	r.eventBus.Publish(&event.Event{
+		Type:      event.EventTypeReconcileDivergence,
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"exchange":          exchangeName,
+			"symbol":            symbol,
+			"local_position":    localTotal,
+			"exchange_position": exchangePosition,
+			"divergence_ratio":  ratio,
+			"message":           fmt.Sprintf("本地持仓 %.6f 与交易所持仓 %.6f 偏差 %.2f%%，请人工核对", localTotal, exchangePosition, ratio*100),
+		},
+	})
 }
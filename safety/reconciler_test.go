@@ -3,6 +3,7 @@ package safety
 import (
 	"context"
 	"quantmesh/config"
+	"quantmesh/exchange"
 	"quantmesh/lock"
 	"testing"
 	"time"
@@ -32,23 +33,28 @@ func (m *MockPositionManager) IncrementReconcileCount()            { m.Reconcile
 func (m *MockPositionManager) UpdateLastReconcileTime(t time.Time) {}
 func (m *MockPositionManager) GetSymbol() string                   { return m.Symbol }
 func (m *MockPositionManager) GetPriceInterval() float64           { return m.PriceInterval }
+func (m *MockPositionManager) ForceSyncPositions(qty float64)      {}
 
 // TestSlot 用于对账反射
 type TestSlot struct {
 	PositionStatus string
 	PositionQty    float64
+	OrderID        int64
 	OrderSide      string
 	OrderStatus    string
 }
 
 // MockReconcileExchange 专门用于对账测试的 Mock
-type MockReconcileExchange struct{}
+type MockReconcileExchange struct {
+	Positions  []*exchange.Position
+	OpenOrders []*exchange.Order
+}
 
 func (m *MockReconcileExchange) GetPositions(ctx context.Context, symbol string) (interface{}, error) {
-	return nil, nil
+	return m.Positions, nil
 }
 func (m *MockReconcileExchange) GetOpenOrders(ctx context.Context, symbol string) (interface{}, error) {
-	return nil, nil
+	return m.OpenOrders, nil
 }
 func (m *MockReconcileExchange) GetBaseAsset() string { return "BTC" }
 
@@ -95,3 +101,104 @@ func TestReconciler_Reconcile(t *testing.T) {
 		t.Errorf("对账次数应为 1, 得到 %d", pm.ReconcileCount)
 	}
 }
+
+func TestReconciler_RunNowReport(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Trading.ReconcileInterval = 30
+
+	ex := &MockReconcileExchange{
+		Positions: []*exchange.Position{{Symbol: "BTCUSDT", Size: 0.1}},
+		OpenOrders: []*exchange.Order{
+			{OrderID: 1, Symbol: "BTCUSDT", Side: exchange.SideSell, Price: 50100, Quantity: 0.1, Status: exchange.OrderStatusNew},
+			{OrderID: 9, Symbol: "BTCUSDT", Side: exchange.SideBuy, Price: 48000, Quantity: 0.1, Status: exchange.OrderStatusNew},
+		},
+	}
+	pm := &MockPositionManager{
+		Symbol:        "BTCUSDT",
+		PriceInterval: 100.0,
+		Slots: map[float64]interface{}{
+			// 已成交持仓，卖单在交易所存在
+			50000.0: TestSlot{PositionStatus: "FILLED", PositionQty: 0.2, OrderID: 1, OrderSide: "SELL", OrderStatus: "PLACED"},
+			// 本地买单有效，交易所没有
+			49900.0: TestSlot{PositionStatus: "EMPTY", OrderID: 2, OrderSide: "BUY", OrderStatus: "PLACED"},
+		},
+	}
+
+	r := NewReconciler(cfg, ex, pm, lock.NewNopLock())
+	report, err := r.RunNow()
+	if err != nil {
+		t.Fatalf("对账执行失败: %v", err)
+	}
+	if report.InSync {
+		t.Error("存在差异时 InSync 应为 false")
+	}
+	if report.PositionDelta < 0.0999 || report.PositionDelta > 0.1001 {
+		t.Errorf("持仓差异应为 0.1, 得到 %f", report.PositionDelta)
+	}
+	if len(report.Slots) != 2 || report.MismatchedSlots != 1 {
+		t.Fatalf("应有 2 个槽位、1 个不一致, 得到 %d/%d", len(report.Slots), report.MismatchedSlots)
+	}
+	if report.Slots[0].Status != SlotDiffOK || report.Slots[0].ExchangeOrder == nil {
+		t.Errorf("50000 槽位应与交易所挂单匹配, 得到 %+v", report.Slots[0])
+	}
+	if report.Slots[1].Status != SlotDiffMissingOnExchange {
+		t.Errorf("49900 槽位应为 %s, 得到 %s", SlotDiffMissingOnExchange, report.Slots[1].Status)
+	}
+	if len(report.OrphanedOrders) != 1 || report.OrphanedOrders[0].OrderID != 9 {
+		t.Errorf("应识别出孤儿挂单 9, 得到 %+v", report.OrphanedOrders)
+	}
+}
+
+func TestReconciler_DustTolerance(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.RiskControl.ReconcileDivergenceRatio = 0.1
+	cfg.RiskControl.ReconcileTolerance.Absolute = 0.001
+	cfg.RiskControl.ReconcileTolerance.Symbols = map[string]config.ReconcileTolerance{
+		"ETHUSDT": {Absolute: 0.00001},
+	}
+
+	ex := &MockReconcileExchange{Positions: []*exchange.Position{{Symbol: "BTCUSDT", Size: 0.9995}}}
+	pm := &MockPositionManager{
+		Symbol: "BTCUSDT",
+		Slots: map[float64]interface{}{
+			50000.0: TestSlot{PositionStatus: "FILLED", PositionQty: 1.0},
+		},
+	}
+	r := NewReconciler(cfg, ex, pm, lock.NewNopLock())
+
+	report, err := r.RunNow()
+	if err != nil {
+		t.Fatalf("对账执行失败: %v", err)
+	}
+	if report.Severity != ReconcileSeverityDust || !report.InSync {
+		t.Fatalf("差异在容差内应为 dust 且视为一致, 得到 %s/%v", report.Severity, report.InSync)
+	}
+
+	// 粉尘变化累计统计
+	ex.Positions[0].Size = 0.9992
+	report, _ = r.RunNow()
+	dust := r.DustStats()
+	if dust.Count != 2 || dust.Accumulated < 0.00079 || dust.Accumulated > 0.00081 {
+		t.Errorf("粉尘应累计 2 次、变化量 0.0008, 得到 %d/%f", dust.Count, dust.Accumulated)
+	}
+
+	// 超过容差
+	ex.Positions[0].Size = 0.95
+	report, _ = r.RunNow()
+	if report.Severity != ReconcileSeverityWarning {
+		t.Errorf("超过容差应为 warning, 得到 %s", report.Severity)
+	}
+	ex.Positions[0].Size = 0.5
+	report, _ = r.RunNow()
+	if report.Severity != ReconcileSeverityCritical {
+		t.Errorf("超过严重偏差阈值应为 critical, 得到 %s", report.Severity)
+	}
+	if r.DustStats().Current != 0 {
+		t.Error("超出容差后当前粉尘应清零")
+	}
+
+	// 按交易对覆盖
+	if got := cfg.RiskControl.ReconcileTolerance.For("ethusdt").Band(1); got != 0.00001 {
+		t.Errorf("ETHUSDT 容差应为 0.00001, 得到 %f", got)
+	}
+}
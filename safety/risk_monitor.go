@@ -8,6 +8,8 @@ import (
 	"quantmesh/logger"
 	"quantmesh/metrics"
 	"quantmesh/storage"
+	"quantmesh/utils"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -31,6 +33,17 @@ type RiskMonitor struct {
 	triggeredTime    time.Time
 	recoveredTime    time.Time
 	lastMsg          string
+
+	// 监控篮子：配置的币种，或启用自动发现时按24小时成交额选出的币种 + 正在交易的币种
+	symbols       []string
+	tradedSymbols []string
+	lastDiscovery time.Time
+
+	// 外部价格源交叉校验
+	oracle       PriceOracle
+	oracleSymbol string
+	primaryPrice func() float64
+	oracleState  oracleState
 }
 
 // NewRiskMonitor 创建风控监视器
@@ -47,7 +60,39 @@ func NewRiskMonitor(cfg *config.Config, ex exchange.IExchange) *RiskMonitor {
 		exchange:         ex,
 		symbolDataMap:    symbolDataMap,
 		lastHealthStatus: make(map[string]bool),
+		symbols:          append([]string(nil), cfg.RiskControl.MonitorSymbols...),
+	}
+}
+
+// SetTradedSymbols 设置正在交易的币种，启用自动发现时始终纳入监控篮子
+func (r *RiskMonitor) SetTradedSymbols(symbols []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tradedSymbols = append([]string(nil), symbols...)
+}
+
+// monitorSymbols 当前监控篮子
+func (r *RiskMonitor) monitorSymbols() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.symbols...)
+}
+
+// symbolData 获取币种K线缓存
+func (r *RiskMonitor) symbolData(symbol string) (*SymbolData, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	data, ok := r.symbolDataMap[symbol]
+	return data, ok
+}
+
+// recoveryThreshold 恢复阈值，不超过篮子中的币种数量（自动发现可能缩小篮子）
+func (r *RiskMonitor) recoveryThreshold(symbolCount int) int {
+	threshold := r.cfg.RiskControl.RecoveryThreshold
+	if threshold > symbolCount {
+		threshold = symbolCount
 	}
+	return threshold
 }
 
 // SetStorage 设置存储服务（用于保存检查历史）
@@ -59,6 +104,11 @@ func (r *RiskMonitor) SetStorage(storage storage.Storage) {
 
 // Start 启动监控
 func (r *RiskMonitor) Start(ctx context.Context) {
+	// 价格源交叉校验独立于K线成交量风控
+	if r.oracle != nil && r.primaryPrice != nil {
+		utils.GoSupervised(ctx, "price-oracle-"+r.oracleSymbol, r.oracleLoop)
+	}
+
 	if !r.cfg.RiskControl.Enabled {
 		logger.Info("⚠️ 主动安全风控未启用")
 		return
@@ -66,12 +116,36 @@ func (r *RiskMonitor) Start(ctx context.Context) {
 
 	logger.Info("🛡️ 启动主动安全风控监控 (周期: %s, 倍数: %.1f, 窗口: %d)",
 		r.cfg.RiskControl.Interval, r.cfg.RiskControl.VolumeMultiplier, r.cfg.RiskControl.AverageWindow)
-	logger.Info("🛡️ 监控币种: %v (恢复阈值: %d/%d)", r.cfg.RiskControl.MonitorSymbols,
-		r.cfg.RiskControl.RecoveryThreshold, len(r.cfg.RiskControl.MonitorSymbols))
+	discovery := r.cfg.RiskControl.AutoDiscovery.Enabled
+	if discovery {
+		r.refreshBasket(ctx, false)
+	}
+	symbols := r.monitorSymbols()
+	logger.Info("🛡️ 监控币种: %v (恢复阈值: %d/%d)", symbols,
+		r.recoveryThreshold(len(symbols)), len(symbols))
 
 	// 预加载历史K线数据
 	logger.Info("📊 正在加载历史K线数据...")
-	for _, symbol := range r.cfg.RiskControl.MonitorSymbols {
+	r.loadHistory(ctx, symbols)
+	logger.Info("✅ 历史K线数据加载完成，风控系统已就绪")
+
+	// 启动K线流
+	if err := r.exchange.StartKlineStream(ctx, symbols, r.cfg.RiskControl.Interval, r.onCandleUpdate); err != nil {
+		logger.Error("❌ 启动K线流失败: %v", err)
+		return
+	}
+
+	// 启动定期报告协程（每60秒）
+	go r.reportLoop(ctx)
+
+	if discovery {
+		utils.GoSupervised(ctx, "risk-basket-discovery", r.discoveryLoop)
+	}
+}
+
+// loadHistory 预加载币种的历史K线
+func (r *RiskMonitor) loadHistory(ctx context.Context, symbols []string) {
+	for _, symbol := range symbols {
 		candles, err := r.exchange.GetHistoricalKlines(ctx, symbol, r.cfg.RiskControl.Interval, r.cfg.RiskControl.AverageWindow+1)
 		if err != nil {
 			logger.Warn("⚠️ 加载 %s 历史K线失败: %v", symbol, err)
@@ -79,11 +153,7 @@ func (r *RiskMonitor) Start(ctx context.Context) {
 		}
 
 		if len(candles) > 0 {
-			r.mu.Lock()
-			symbolData, exists := r.symbolDataMap[symbol]
-			r.mu.Unlock()
-
-			if exists {
+			if symbolData, exists := r.symbolData(symbol); exists {
 				symbolData.mu.Lock()
 				symbolData.candles = candles
 				symbolData.mu.Unlock()
@@ -91,16 +161,139 @@ func (r *RiskMonitor) Start(ctx context.Context) {
 			}
 		}
 	}
-	logger.Info("✅ 历史K线数据加载完成，风控系统已就绪")
+}
 
-	// 启动K线流
-	if err := r.exchange.StartKlineStream(ctx, r.cfg.RiskControl.MonitorSymbols, r.cfg.RiskControl.Interval, r.onCandleUpdate); err != nil {
-		logger.Error("❌ 启动K线流失败: %v", err)
+// discoveryLoop 定期刷新监控篮子
+func (r *RiskMonitor) discoveryLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(r.cfg.RiskControl.AutoDiscovery.RefreshHours) * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.refreshBasket(ctx, true)
+		}
+	}
+}
+
+// refreshBasket 按交易所24小时成交额重新选出监控篮子，失败时保留当前篮子
+// restartStream 为 true 时为新增币种加载历史K线并按新篮子重新订阅K线流
+func (r *RiskMonitor) refreshBasket(ctx context.Context, restartStream bool) {
+	provider, ok := exchange.As[exchange.TickerStatsProvider](r.exchange)
+	if !ok {
+		logger.Warn("⚠️ [风控篮子] 交易所 %s 不支持24小时行情查询，使用配置的监控币种", r.exchange.GetName())
+		return
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	tickers, err := provider.GetTickers24h(reqCtx)
+	cancel()
+	if err != nil {
+		logger.Warn("⚠️ [风控篮子] 获取24小时行情失败，保留当前监控币种: %v", err)
 		return
 	}
 
-	// 启动定期报告协程（每60秒）
-	go r.reportLoop(ctx)
+	discovery := r.cfg.RiskControl.AutoDiscovery
+	r.mu.RLock()
+	traded := r.tradedSymbols
+	r.mu.RUnlock()
+	basket := SelectRiskBasket(tickers, discovery.QuoteAsset, discovery.TopN, traded)
+	if len(basket) == 0 {
+		logger.Warn("⚠️ [风控篮子] 未选出任何 %s 币种，保留当前监控币种", discovery.QuoteAsset)
+		return
+	}
+
+	current := r.monitorSymbols()
+	existing := make(map[string]bool, len(current))
+	for _, symbol := range current {
+		existing[symbol] = true
+	}
+	added := make([]string, 0)
+	for _, symbol := range basket {
+		if !existing[symbol] {
+			added = append(added, symbol)
+		}
+	}
+
+	r.mu.Lock()
+	r.lastDiscovery = time.Now()
+	if len(added) == 0 && len(basket) == len(current) {
+		r.mu.Unlock()
+		logger.Debug("🔄 [风控篮子] 监控币种未变化: %v", basket)
+		return
+	}
+	dataMap := make(map[string]*SymbolData, len(basket))
+	for _, symbol := range basket {
+		if data, ok := r.symbolDataMap[symbol]; ok {
+			dataMap[symbol] = data
+		} else {
+			dataMap[symbol] = &SymbolData{
+				candles: make([]*exchange.Candle, 0, r.cfg.RiskControl.AverageWindow+1),
+			}
+		}
+	}
+	r.symbolDataMap = dataMap
+	r.symbols = basket
+	r.mu.Unlock()
+
+	logger.Info("🔄 [风控篮子] 按24小时成交额更新监控币种: %v -> %v", current, basket)
+	if !restartStream {
+		return
+	}
+
+	r.loadHistory(ctx, added)
+	r.exchange.StopKlineStream()
+	if err := r.exchange.StartKlineStream(ctx, basket, r.cfg.RiskControl.Interval, r.onCandleUpdate); err != nil {
+		logger.Error("❌ [风控篮子] 重新订阅K线流失败: %v", err)
+	}
+}
+
+// SelectRiskBasket 选出风控监控篮子：以 quoteAsset 计价、24小时成交额最高的 topN 个币种 + 正在交易的币种（去重，保持顺序）
+func SelectRiskBasket(tickers []*exchange.Ticker24h, quoteAsset string, topN int, traded []string) []string {
+	quoteAsset = strings.ToUpper(quoteAsset)
+	candidates := make([]*exchange.Ticker24h, 0, len(tickers))
+	for _, t := range tickers {
+		if t == nil || t.QuoteVolume <= 0 {
+			continue
+		}
+		// 排除交割合约（如 BTCUSDT_250328）
+		if strings.Contains(t.Symbol, "_") || !strings.HasSuffix(strings.ToUpper(t.Symbol), quoteAsset) {
+			continue
+		}
+		candidates = append(candidates, t)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].QuoteVolume > candidates[j].QuoteVolume
+	})
+
+	seen := make(map[string]bool)
+	basket := make([]string, 0, topN+len(traded))
+	for _, t := range candidates {
+		if len(basket) >= topN {
+			break
+		}
+		symbol := strings.ToUpper(t.Symbol)
+		if !seen[symbol] {
+			seen[symbol] = true
+			basket = append(basket, symbol)
+		}
+	}
+	for _, symbol := range traded {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol != "" && !seen[symbol] {
+			seen[symbol] = true
+			basket = append(basket, symbol)
+		}
+	}
+	return basket
+}
+
+// GetLastDiscovery 获取最近一次自动发现的时间，未启用时为零值
+func (r *RiskMonitor) GetLastDiscovery() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.lastDiscovery
 }
 
 // onCandleUpdate K线更新回调（实时检测）
@@ -112,9 +305,7 @@ func (r *RiskMonitor) onCandleUpdate(candle *exchange.Candle) {
 	c := candle
 
 	// 更新缓存
-	r.mu.RLock()
-	symbolData, exists := r.symbolDataMap[c.Symbol]
-	r.mu.RUnlock()
+	symbolData, exists := r.symbolData(c.Symbol)
 
 	if !exists {
 		logger.Warn("⚠️ 收到未监控的币种K线: %s", c.Symbol)
@@ -185,6 +376,7 @@ func (r *RiskMonitor) onCandleUpdate(candle *exchange.Candle) {
 func (r *RiskMonitor) checkMarket() {
 	checkTime := time.Now()
 	var checkRecords []*storage.RiskCheckRecord
+	symbols := r.monitorSymbols()
 
 	// 先检查当前状态（不持有锁）
 	r.mu.RLock()
@@ -193,10 +385,10 @@ func (r *RiskMonitor) checkMarket() {
 
 	if triggered {
 		// 已触发状态：检查是否可以解除
-		canRecover, details := r.checkRecovery()
+		canRecover, details := r.checkRecovery(symbols)
 
 		// 收集恢复检查结果
-		for _, symbol := range r.cfg.RiskControl.MonitorSymbols {
+		for _, symbol := range symbols {
 			isRecovered, reason := r.checkSymbolRecovery(symbol)
 			record := &storage.RiskCheckRecord{
 				CheckTime: checkTime,
@@ -205,7 +397,7 @@ func (r *RiskMonitor) checkMarket() {
 				Reason:    reason,
 			}
 			// 获取价格偏离和成交量比率
-			if symbolData, exists := r.symbolDataMap[symbol]; exists {
+			if symbolData, exists := r.symbolData(symbol); exists {
 				symbolData.mu.RLock()
 				candles := symbolData.candles
 				candleCount := len(candles)
@@ -246,7 +438,7 @@ func (r *RiskMonitor) checkMarket() {
 				}
 			}
 			logger.Info("✅ 市场风险信号消失，解除风控限制。(%d/%d 币种已恢复正常，达到恢复阈值 %d)",
-				recoveredCount, len(r.cfg.RiskControl.MonitorSymbols), r.cfg.RiskControl.RecoveryThreshold)
+				recoveredCount, len(symbols), r.recoveryThreshold(len(symbols)))
 			logger.Info("详情: %s", strings.Join(details, ", "))
 			r.triggered = false
 			r.recoveredTime = time.Now()
@@ -260,7 +452,7 @@ func (r *RiskMonitor) checkMarket() {
 		panicCount := 0
 		details := []string{}
 
-		for _, symbol := range r.cfg.RiskControl.MonitorSymbols {
+		for _, symbol := range symbols {
 			isPanic, reason := r.checkSymbol(symbol)
 
 			// 收集检查结果
@@ -271,7 +463,7 @@ func (r *RiskMonitor) checkMarket() {
 				Reason:    reason,
 			}
 			// 获取价格偏离和成交量比率
-			if symbolData, exists := r.symbolDataMap[symbol]; exists {
+			if symbolData, exists := r.symbolData(symbol); exists {
 				symbolData.mu.RLock()
 				candles := symbolData.candles
 				candleCount := len(candles)
@@ -310,22 +502,22 @@ func (r *RiskMonitor) checkMarket() {
 		// 全部币种都出现异常时才触发
 		r.mu.Lock()
 		pm := metrics.GetPrometheusMetrics()
-		if panicCount > 0 && panicCount >= len(r.cfg.RiskControl.MonitorSymbols) {
+		if panicCount > 0 && panicCount >= len(symbols) {
 			logger.Warn("🚨🚨🚨 触发主动安全风控！市场出现集体异动！🚨🚨🚨")
 			logger.Warn("详情: %s", strings.Join(details, ", "))
 			r.triggered = true
 			r.triggeredTime = time.Now()
-			r.lastMsg = fmt.Sprintf("触发风控: %d/%d 币种异常 (%s)", panicCount, len(r.cfg.RiskControl.MonitorSymbols), strings.Join(details, ","))
+			r.lastMsg = fmt.Sprintf("触发风控: %d/%d 币种异常 (%s)", panicCount, len(symbols), strings.Join(details, ","))
 
 			// 记录风控触发指标
-			for _, symbol := range r.cfg.RiskControl.MonitorSymbols {
+			for _, symbol := range symbols {
 				pm.SetRiskControlStatus(r.exchange.GetName(), symbol, true)
 				pm.RecordRiskControlTrigger(r.exchange.GetName(), symbol, "market_anomaly")
 			}
 		} else {
 			r.lastMsg = "监控正常"
 			// 记录风控正常状态
-			for _, symbol := range r.cfg.RiskControl.MonitorSymbols {
+			for _, symbol := range symbols {
 				pm.SetRiskControlStatus(r.exchange.GetName(), symbol, false)
 			}
 		}
@@ -367,11 +559,11 @@ func (r *RiskMonitor) checkMarket() {
 }
 
 // checkRecovery 检查是否可以解除风控（价格回到均线上方 + 成交量恢复正常）
-func (r *RiskMonitor) checkRecovery() (bool, []string) {
+func (r *RiskMonitor) checkRecovery(symbols []string) (bool, []string) {
 	recoveredCount := 0
 	details := []string{}
 
-	for _, symbol := range r.cfg.RiskControl.MonitorSymbols {
+	for _, symbol := range symbols {
 		isRecovered, reason := r.checkSymbolRecovery(symbol)
 		if isRecovered {
 			recoveredCount++
@@ -382,14 +574,14 @@ func (r *RiskMonitor) checkRecovery() (bool, []string) {
 	}
 
 	// 达到恢复阈值即可解除风控
-	threshold := r.cfg.RiskControl.RecoveryThreshold
+	threshold := r.recoveryThreshold(len(symbols))
 	return recoveredCount >= threshold, details
 }
 
 // checkSymbolRecovery 检查单个币种是否恢复（价格>均价 且 成交量<均值×倍数）
 // 解除风控必须使用完结的K线数据
 func (r *RiskMonitor) checkSymbolRecovery(symbol string) (bool, string) {
-	symbolData, exists := r.symbolDataMap[symbol]
+	symbolData, exists := r.symbolData(symbol)
 	if !exists {
 		return false, "无数据"
 	}
@@ -458,9 +650,7 @@ func (r *RiskMonitor) checkSymbolRecovery(symbol string) (bool, string) {
 // checkSymbol 检查单个币种（基于移动平均线）
 // 触发风控可以使用最新K线数据（包括未完结的K线），以便及时检测到异常
 func (r *RiskMonitor) checkSymbol(symbol string) (bool, string) {
-	r.mu.RLock()
-	symbolData, exists := r.symbolDataMap[symbol]
-	r.mu.RUnlock()
+	symbolData, exists := r.symbolData(symbol)
 
 	if !exists {
 		return false, ""
@@ -517,7 +707,7 @@ func (r *RiskMonitor) checkSymbol(symbol string) (bool, string) {
 func (r *RiskMonitor) IsTriggered() bool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	return r.triggered
+	return r.triggered || r.oracleState.triggered
 }
 
 // GetTriggeredTime 获取触发时间
@@ -536,14 +726,12 @@ func (r *RiskMonitor) GetRecoveredTime() time.Time {
 
 // GetMonitorSymbols 获取监控币种列表
 func (r *RiskMonitor) GetMonitorSymbols() []string {
-	return r.cfg.RiskControl.MonitorSymbols
+	return r.monitorSymbols()
 }
 
 // GetSymbolData 获取币种数据（返回最新K线和统计信息）
 func (r *RiskMonitor) GetSymbolData(symbol string) interface{} {
-	r.mu.RLock()
-	symbolData, exists := r.symbolDataMap[symbol]
-	r.mu.RUnlock()
+	symbolData, exists := r.symbolData(symbol)
 
 	if !exists {
 		return nil
@@ -633,10 +821,8 @@ func (r *RiskMonitor) printMovingAverages(inRiskControl bool) {
 	// 检查K线数据是否过期
 	hasStaleData := false
 
-	for _, symbol := range r.cfg.RiskControl.MonitorSymbols {
-		r.mu.RLock()
-		symbolData, exists := r.symbolDataMap[symbol]
-		r.mu.RUnlock()
+	for _, symbol := range r.monitorSymbols() {
+		symbolData, exists := r.symbolData(symbol)
 
 		if !exists {
 			logger.Info("  %s: 无数据", symbol)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"quantmesh/config"
 	"quantmesh/exchange"
+	"reflect"
 	"testing"
 )
 
@@ -82,3 +83,69 @@ func TestRiskMonitor_IsTriggered(t *testing.T) {
 		t.Error("行情恢复后应解除风控")
 	}
 }
+
+// mockTickerExchange 支持24小时行情查询的模拟交易所
+type mockTickerExchange struct {
+	MockRiskExchange
+	tickers []*exchange.Ticker24h
+}
+
+func (m *mockTickerExchange) GetName() string { return "binance" }
+
+func (m *mockTickerExchange) GetTickers24h(ctx context.Context) ([]*exchange.Ticker24h, error) {
+	return m.tickers, nil
+}
+
+func TestSelectRiskBasket(t *testing.T) {
+	tickers := []*exchange.Ticker24h{
+		{Symbol: "ETHUSDT", QuoteVolume: 8e9},
+		{Symbol: "BTCUSDT", QuoteVolume: 1e10},
+		{Symbol: "BTCUSDT_250328", QuoteVolume: 9e9}, // 交割合约
+		{Symbol: "ETHBTC", QuoteVolume: 7e9},         // 非 USDT 计价
+		{Symbol: "SOLUSDT", QuoteVolume: 3e9},
+		{Symbol: "DOGEUSDT", QuoteVolume: 0},
+	}
+
+	basket := SelectRiskBasket(tickers, "usdt", 2, []string{"xrpusdt", "ETHUSDT"})
+	if want := []string{"BTCUSDT", "ETHUSDT", "XRPUSDT"}; !reflect.DeepEqual(basket, want) {
+		t.Errorf("篮子 = %v, 期望 %v", basket, want)
+	}
+
+	if basket := SelectRiskBasket(nil, "USDT", 5, nil); len(basket) != 0 {
+		t.Errorf("无行情且无交易币种时应为空: %v", basket)
+	}
+}
+
+func TestRiskMonitor_RefreshBasket(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.RiskControl.MonitorSymbols = []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}
+	cfg.RiskControl.RecoveryThreshold = 3
+	cfg.RiskControl.AverageWindow = 5
+	cfg.RiskControl.AutoDiscovery.TopN = 1
+	cfg.RiskControl.AutoDiscovery.QuoteAsset = "USDT"
+
+	ex := &mockTickerExchange{tickers: []*exchange.Ticker24h{
+		{Symbol: "BTCUSDT", QuoteVolume: 1e10},
+		{Symbol: "ETHUSDT", QuoteVolume: 5e9},
+	}}
+	rm := NewRiskMonitor(cfg, ex)
+	rm.SetTradedSymbols([]string{"DOGEUSDT"})
+	rm.refreshBasket(context.Background(), false)
+
+	if want := []string{"BTCUSDT", "DOGEUSDT"}; !reflect.DeepEqual(rm.GetMonitorSymbols(), want) {
+		t.Fatalf("篮子 = %v, 期望 %v", rm.GetMonitorSymbols(), want)
+	}
+	if _, ok := rm.symbolData("DOGEUSDT"); !ok {
+		t.Error("新增币种应创建K线缓存")
+	}
+	if _, ok := rm.symbolData("SOLUSDT"); ok {
+		t.Error("移出篮子的币种应删除K线缓存")
+	}
+	if rm.GetLastDiscovery().IsZero() {
+		t.Error("应记录发现时间")
+	}
+	// 篮子缩小后恢复阈值不超过币种数量
+	if got := rm.recoveryThreshold(len(rm.GetMonitorSymbols())); got != 2 {
+		t.Errorf("恢复阈值 = %d, 期望 2", got)
+	}
+}
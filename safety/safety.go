@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"quantmesh/exchange"
 	"quantmesh/logger"
+	"quantmesh/utils"
 )
 
 const (
@@ -23,10 +24,21 @@ const (
 //   - priceDecimals: 价格小数位数（用于格式化显示）
 //   - maxLeverage: 最大允许杠杆倍数（0表示不限制，默认使用DefaultMaxLeverage）
 func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orderAmount, priceInterval, feeRate float64, requiredPositions, priceDecimals int, maxLeverage int) error {
+	return CheckContractAccountSafety(ex, symbol, utils.ContractSpec{}, currentPrice, orderAmount, priceInterval, feeRate, requiredPositions, priceDecimals, maxLeverage)
+}
+
+// CheckContractAccountSafety 按合约规格检查账户安全性
+// 币本位合约的保证金和盈亏以基础币结算：每仓成本 = 每笔名义价值 / 当前价，每笔数量为合约张数
+func CheckContractAccountSafety(ex exchange.IExchange, symbol string, spec utils.ContractSpec, currentPrice, orderAmount, priceInterval, feeRate float64, requiredPositions, priceDecimals int, maxLeverage int) error {
 	logger.Info("🔒 ===== 开始持仓安全性检查 =====")
 
 	// 从交易所接口获取计价币种（支持U本位和币本位合约）
 	quoteCurrency := ex.GetQuoteAsset()
+	// 结算币种：U 本位为计价币，币本位为基础币
+	settleCurrency, amountDecimals, profitDecimals, qtyUnit := quoteCurrency, 2, 4, ""
+	if spec.Inverse {
+		settleCurrency, amountDecimals, profitDecimals, qtyUnit = ex.GetBaseAsset(), 8, 8, " 张"
+	}
 
 	// 1. 获取账户信息
 	ctx := context.Background()
@@ -67,9 +79,9 @@ func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orde
 	}
 	accountBalance := account.AvailableBalance
 	if accountBalance <= 0 {
-		return fmt.Errorf("账户余额不足，当前余额: %.2f %s", accountBalance, quoteCurrency)
+		return fmt.Errorf("账户余额不足，当前余额: %.*f %s", amountDecimals, accountBalance, settleCurrency)
 	}
-	logger.Info("💰 账户余额: %.2f %s (交易对: %s)", accountBalance, quoteCurrency, symbol)
+	logger.Info("💰 账户余额: %.*f %s (交易对: %s, 合约类型: %s)", amountDecimals, accountBalance, settleCurrency, symbol, spec.Type())
 	// 如果是币安交易所，尝试获取更准确的杠杆信息
 	exchangeName := ex.GetName()
 	if leverage == 1 && exchangeName == "Binance" {
@@ -97,8 +109,12 @@ func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orde
 	// 公式：最大可持有仓位 = (账户余额 * 杠杆倍数) / 每笔金额
 	// 例如：余额3000，杠杆10倍，每笔投入30U
 	// 最大可持有 = (3000 * 10) / 30 = 1000仓
+	// 币本位：余额为基础币，每仓成本按当前价换算，如每笔 100 USD、价格 50000 时为 0.002 BTC
 	maxAvailableMargin := accountBalance * float64(leverage)
-	costPerPosition := orderAmount // 每仓成本就是配置的金额
+	costPerPosition := spec.NotionalToSettlement(orderAmount, currentPrice)
+	if costPerPosition <= 0 {
+		return fmt.Errorf("每仓成本无效（每笔金额 %.2f，当前价 %v）", orderAmount, currentPrice)
+	}
 	maxPositions := maxAvailableMargin / costPerPosition
 
 	// 如果未设置小数位数，使用默认值2
@@ -107,11 +123,11 @@ func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orde
 	}
 
 	// 根据当前价格计算实际购买数量（用于显示）
-	orderQuantity := orderAmount / currentPrice
+	orderQuantity := spec.QuantityForNotional(orderAmount, currentPrice)
 
-	logger.Info("📈 当前币价: %.*f, 每笔金额: %.2f %s, 每笔数量: %.4f", priceDecimals, currentPrice, orderAmount, quoteCurrency, orderQuantity)
-	logger.Info("💵 最大可用保证金: %.2f %s (余额 %.2f × 杠杆 %dx)", maxAvailableMargin, quoteCurrency, accountBalance, leverage)
-	logger.Info("📦 每仓成本: %.2f %s (固定金额模式)", costPerPosition, quoteCurrency)
+	logger.Info("📈 当前币价: %.*f, 每笔金额: %.2f %s, 每笔数量: %.4f%s", priceDecimals, currentPrice, orderAmount, quoteCurrency, orderQuantity, qtyUnit)
+	logger.Info("💵 最大可用保证金: %.*f %s (余额 %.*f × 杠杆 %dx)", amountDecimals, maxAvailableMargin, settleCurrency, amountDecimals, accountBalance, leverage)
+	logger.Info("📦 每仓成本: %.*f %s (固定金额模式)", amountDecimals, costPerPosition, settleCurrency)
 	logger.Info("🎯 最大可持有仓位: %.0f 仓", maxPositions)
 	logger.Info("✅ 要求最少持有: %d 仓", requiredPositions)
 
@@ -131,52 +147,39 @@ func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orde
 
 	// 计算每笔交易的利润和手续费
 	// 🔥 固定金额模式：每笔买入金额固定，数量根据价格动态计算
-	buyPrice := currentPrice
-	sellPrice := currentPrice + priceInterval
-
-	// 买入时：投入固定金额，买到的数量 = orderAmount / buyPrice
-	buyQuantity := orderAmount / buyPrice
-	// 卖出时：卖出价 = buyPrice + priceInterval
-	sellQuantity := buyQuantity // 卖出数量等于买入数量
-
-	// 利润 = 卖出金额 - 买入金额
-	buyAmount := orderAmount               // 买入金额固定
-	sellAmount := sellPrice * sellQuantity // 卖出金额
-	profitPerTrade := sellAmount - buyAmount
-
-	// 手续费 = 买入手续费 + 卖出手续费
-	buyFee := buyAmount * buyFeeRate
-	sellFee := sellAmount * sellFeeRate
-	totalFee := buyFee + sellFee
+	trade := CalculateContractTradeEconomics(spec, currentPrice, orderAmount, priceInterval, feeRate)
+	buyPrice, sellPrice := trade.BuyPrice, trade.SellPrice
+	buyQuantity, sellQuantity := trade.Quantity, trade.Quantity
+	buyAmount, sellAmount := trade.BuyAmount, trade.SellAmount
+	profitPerTrade := trade.GrossProfit
+	buyFee, sellFee, totalFee := trade.BuyFee, trade.SellFee, trade.TotalFee
+	profitRate := trade.ProfitRate
 
 	// 计算总手续费率（买入费率 + 卖出费率）
 	totalFeeRate := buyFeeRate + sellFeeRate
 
-	// 计算利润占买入价的比例（利润率）
-	profitRate := priceInterval / buyPrice
-
 	logger.Info("💰 每笔交易分析 (固定金额模式):")
 	logger.Info("   买入价: %.*f, 卖出价: %.*f, 价格差: %.*f", priceDecimals, buyPrice, priceDecimals, sellPrice, priceDecimals, priceInterval)
-	logger.Info("   买入金额: %.2f %s, 买入数量: %.4f", buyAmount, quoteCurrency, buyQuantity)
-	logger.Info("   卖出金额: %.2f %s, 卖出数量: %.4f", sellAmount, quoteCurrency, sellQuantity)
-	logger.Info("   每笔利润: %.4f %s (卖出 %.2f - 买入 %.2f)", profitPerTrade, quoteCurrency, sellAmount, buyAmount)
+	logger.Info("   买入金额: %.*f %s, 买入数量: %.4f%s", amountDecimals, buyAmount, settleCurrency, buyQuantity, qtyUnit)
+	logger.Info("   卖出金额: %.*f %s, 卖出数量: %.4f%s", amountDecimals, sellAmount, settleCurrency, sellQuantity, qtyUnit)
+	logger.Info("   每笔利润: %.*f %s", profitDecimals, profitPerTrade, settleCurrency)
 	logger.Info("   利润率: %.4f%% (价格差 %.*f / 买入价 %.*f)", profitRate*100, priceDecimals, priceInterval, priceDecimals, buyPrice)
-	logger.Info("   买入手续费: %.4f %s (金额 %.2f × 费率 %.4f%%)", buyFee, quoteCurrency, buyAmount, buyFeeRate*100)
-	logger.Info("   卖出手续费: %.4f %s (金额 %.2f × 费率 %.4f%%)", sellFee, quoteCurrency, sellAmount, sellFeeRate*100)
-	logger.Info("   总手续费: %.4f %s (费率: %.4f%%)", totalFee, quoteCurrency, totalFeeRate*100)
+	logger.Info("   买入手续费: %.*f %s (金额 %.*f × 费率 %.4f%%)", profitDecimals, buyFee, settleCurrency, amountDecimals, buyAmount, buyFeeRate*100)
+	logger.Info("   卖出手续费: %.*f %s (金额 %.*f × 费率 %.4f%%)", profitDecimals, sellFee, settleCurrency, amountDecimals, sellAmount, sellFeeRate*100)
+	logger.Info("   总手续费: %.*f %s (费率: %.4f%%)", profitDecimals, totalFee, settleCurrency, totalFeeRate*100)
 
-	netProfit := profitPerTrade - totalFee
-	logger.Info("   净利润: %.4f %s (利润 %.4f - 手续费 %.4f)", netProfit, quoteCurrency, profitPerTrade, totalFee)
+	netProfit := trade.NetProfit
+	logger.Info("   净利润: %.*f %s (利润 %.*f - 手续费 %.*f)", profitDecimals, netProfit, settleCurrency, profitDecimals, profitPerTrade, profitDecimals, totalFee)
 
 	// 验证利润是否足够支付手续费（净利润必须为正）
 	if netProfit <= 0 {
-		logger.Error("❌ 错误：每笔净利润为负或为零 (%.4f %s)，无法盈利！", netProfit, quoteCurrency)
+		logger.Error("❌ 错误：每笔净利润为负或为零 (%.*f %s)，无法盈利！", profitDecimals, netProfit, settleCurrency)
 		logger.Error("   建议：增加价格间隔或降低手续费率")
 		logger.Error("   当前价格间隔: %.*f, 手续费率: %.4f%%", priceDecimals, priceInterval, totalFeeRate*100)
-		return fmt.Errorf("每笔净利润为负或为零 (%.4f %s)，系统拒绝启动", netProfit, quoteCurrency)
+		return fmt.Errorf("每笔净利润为负或为零 (%.*f %s)，系统拒绝启动", profitDecimals, netProfit, settleCurrency)
 	}
 
-	logger.Info("✅ 手续费率安全检查通过：每笔净利润 %.4f %s", netProfit, quoteCurrency)
+	logger.Info("✅ 手续费率安全检查通过：每笔净利润 %.*f %s", profitDecimals, netProfit, settleCurrency)
 
 	logger.Info("🔒 ===== 持仓安全性检查完成 =====")
 
@@ -0,0 +1,211 @@
+package safety
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// varZScore 单日 99% 置信度对应的正态分位数
+const varZScore = 2.33
+
+// StressScenario 压力情景
+// PriceShock 不为 0 时所有持仓按同一比例冲击价格（如 -0.05 表示整体下跌 5%）；
+// 否则按 VolMultiplier 倍日波动率对每个持仓施加 99% 置信度的单日不利波动
+type StressScenario struct {
+	Name          string  `json:"name"`
+	PriceShock    float64 `json:"price_shock,omitempty"`
+	VolMultiplier float64 `json:"vol_multiplier,omitempty"`
+}
+
+// StressPosition 压力测试输入：实时持仓
+type StressPosition struct {
+	Exchange         string  `json:"exchange"`
+	Symbol           string  `json:"symbol"`
+	Size             float64 `json:"size"` // 正数为多仓，负数为空仓
+	EntryPrice       float64 `json:"entry_price"`
+	MarkPrice        float64 `json:"mark_price"`
+	Leverage         int     `json:"leverage"`
+	LiquidationPrice float64 `json:"liquidation_price"` // 交易所返回的强平价（0 表示未知）
+	DailyVolatility  float64 `json:"daily_volatility"`  // 日收益率标准差（0 表示未知）
+}
+
+// StressAccount 压力测试输入：交易所账户权益
+type StressAccount struct {
+	Exchange string  `json:"exchange"`
+	Equity   float64 `json:"equity"` // 保证金余额（含未实现盈亏）
+}
+
+// StressSymbolResult 单个持仓在某情景下的结果
+type StressSymbolResult struct {
+	Exchange         string  `json:"exchange"`
+	Symbol           string  `json:"symbol"`
+	Size             float64 `json:"size"`
+	MarkPrice        float64 `json:"mark_price"`
+	ShockedPrice     float64 `json:"shocked_price"`
+	Move             float64 `json:"move"` // 价格变动比例
+	PnL              float64 `json:"pnl"`
+	MarginBefore     float64 `json:"margin_before"` // 名义价值 / 杠杆
+	MarginAfter      float64 `json:"margin_after"`
+	LiquidationPrice float64 `json:"liquidation_price,omitempty"`
+	LiqDistance      float64 `json:"liq_distance"`       // 冲击前标记价格距强平价的比例（强平价未知时为 1）
+	LiqDistanceAfter float64 `json:"liq_distance_after"` // 冲击后价格距强平价的比例，<=0 表示已被强平
+	Liquidated       bool    `json:"liquidated"`
+}
+
+// StressExchangeResult 单个交易所账户在某情景下的结果
+type StressExchangeResult struct {
+	Exchange       string  `json:"exchange"`
+	EquityBefore   float64 `json:"equity_before"`
+	EquityAfter    float64 `json:"equity_after"`
+	MarginAfter    float64 `json:"margin_after"`
+	MarginUsage    float64 `json:"margin_usage"`    // 冲击后保证金占用 / 冲击后权益（权益为负时为 +Inf 的替代值 999）
+	AvailableAfter float64 `json:"available_after"` // 冲击后权益 - 保证金占用
+}
+
+// StressScenarioResult 单个情景的结果
+type StressScenarioResult struct {
+	Scenario  StressScenario         `json:"scenario"`
+	TotalPnL  float64                `json:"total_pnl"`
+	Symbols   []StressSymbolResult   `json:"symbols"`
+	Exchanges []StressExchangeResult `json:"exchanges"`
+}
+
+// StressReport 压力测试报告
+type StressReport struct {
+	Positions   int                    `json:"positions"`
+	VaR99       float64                `json:"var_99"` // 单日 99% VaR（按持仓完全相关保守估计，未知波动率的持仓不计入）
+	Scenarios   []StressScenarioResult `json:"scenarios"`
+	Warnings    []string               `json:"warnings"`
+	GeneratedAt time.Time              `json:"generated_at"`
+}
+
+// DefaultStressScenarios 根据价格冲击和波动率倍数生成情景列表
+func DefaultStressScenarios(priceShocks, volMultipliers []float64) []StressScenario {
+	scenarios := make([]StressScenario, 0, len(priceShocks)+len(volMultipliers))
+	for _, shock := range priceShocks {
+		scenarios = append(scenarios, StressScenario{Name: fmt.Sprintf("price %+.1f%%", shock*100), PriceShock: shock})
+	}
+	for _, mult := range volMultipliers {
+		scenarios = append(scenarios, StressScenario{Name: fmt.Sprintf("vol x%g", mult), VolMultiplier: mult})
+	}
+	return scenarios
+}
+
+// RunStressTest 在各情景下重新估值当前持仓，计算盈亏、保证金占用和强平距离
+func RunStressTest(accounts []StressAccount, positions []StressPosition, scenarios []StressScenario) *StressReport {
+	report := &StressReport{
+		Positions:   len(positions),
+		Scenarios:   make([]StressScenarioResult, 0, len(scenarios)),
+		Warnings:    []string{},
+		GeneratedAt: time.Now(),
+	}
+
+	equity := make(map[string]float64, len(accounts))
+	exchanges := make([]string, 0, len(accounts))
+	for _, acc := range accounts {
+		if _, ok := equity[acc.Exchange]; !ok {
+			exchanges = append(exchanges, acc.Exchange)
+		}
+		equity[acc.Exchange] += acc.Equity
+	}
+	for _, p := range positions {
+		if _, ok := equity[p.Exchange]; !ok {
+			equity[p.Exchange] = 0
+			exchanges = append(exchanges, p.Exchange)
+		}
+		if p.DailyVolatility > 0 {
+			report.VaR99 += math.Abs(p.Size) * p.MarkPrice * p.DailyVolatility * varZScore
+		} else {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s:%s 缺少波动率数据，未计入 VaR 和波动率情景", p.Exchange, p.Symbol))
+		}
+	}
+	sort.Strings(exchanges)
+
+	for _, sc := range scenarios {
+		result := StressScenarioResult{
+			Scenario:  sc,
+			Symbols:   make([]StressSymbolResult, 0, len(positions)),
+			Exchanges: make([]StressExchangeResult, 0, len(exchanges)),
+		}
+		pnlByExchange := make(map[string]float64)
+		marginByExchange := make(map[string]float64)
+
+		for _, p := range positions {
+			r := stressPosition(p, sc)
+			result.Symbols = append(result.Symbols, r)
+			result.TotalPnL += r.PnL
+			pnlByExchange[p.Exchange] += r.PnL
+			marginByExchange[p.Exchange] += r.MarginAfter
+		}
+
+		for _, ex := range exchanges {
+			er := StressExchangeResult{
+				Exchange:     ex,
+				EquityBefore: equity[ex],
+				EquityAfter:  equity[ex] + pnlByExchange[ex],
+				MarginAfter:  marginByExchange[ex],
+			}
+			er.AvailableAfter = er.EquityAfter - er.MarginAfter
+			switch {
+			case er.EquityAfter > 0:
+				er.MarginUsage = er.MarginAfter / er.EquityAfter
+			case er.MarginAfter > 0:
+				er.MarginUsage = 999
+			}
+			result.Exchanges = append(result.Exchanges, er)
+		}
+		report.Scenarios = append(report.Scenarios, result)
+	}
+	return report
+}
+
+// stressPosition 计算单个持仓在某情景下的结果
+func stressPosition(p StressPosition, sc StressScenario) StressSymbolResult {
+	leverage := p.Leverage
+	if leverage <= 0 {
+		leverage = 1
+	}
+	r := StressSymbolResult{
+		Exchange:         p.Exchange,
+		Symbol:           p.Symbol,
+		Size:             p.Size,
+		MarkPrice:        p.MarkPrice,
+		LiquidationPrice: p.LiquidationPrice,
+		LiqDistance:      liquidationDistance(p.Size, p.MarkPrice, p.LiquidationPrice),
+	}
+
+	switch {
+	case sc.PriceShock != 0:
+		r.Move = sc.PriceShock
+	case sc.VolMultiplier > 0:
+		// 不利方向：多仓下跌、空仓上涨
+		r.Move = -varZScore * p.DailyVolatility * sc.VolMultiplier
+		if p.Size < 0 {
+			r.Move = -r.Move
+		}
+	}
+	if r.Move < -1 {
+		r.Move = -1
+	}
+
+	r.ShockedPrice = p.MarkPrice * (1 + r.Move)
+	r.PnL = p.Size * (r.ShockedPrice - p.MarkPrice)
+	r.MarginBefore = math.Abs(p.Size) * p.MarkPrice / float64(leverage)
+	r.MarginAfter = math.Abs(p.Size) * r.ShockedPrice / float64(leverage)
+	r.LiqDistanceAfter = liquidationDistance(p.Size, r.ShockedPrice, p.LiquidationPrice)
+	r.Liquidated = p.LiquidationPrice > 0 && r.LiqDistanceAfter <= 0
+	return r
+}
+
+// liquidationDistance 价格距强平价的比例（多仓为价格高出强平价的比例，空仓相反），强平价未知时返回 1
+func liquidationDistance(size, price, liqPrice float64) float64 {
+	if liqPrice <= 0 || price <= 0 || size == 0 {
+		return 1
+	}
+	if size > 0 {
+		return (price - liqPrice) / price
+	}
+	return (liqPrice - price) / price
+}
@@ -0,0 +1,56 @@
+package safety
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRunStressTest(t *testing.T) {
+	accounts := []StressAccount{{Exchange: "binance", Equity: 1000}}
+	positions := []StressPosition{
+		{Exchange: "binance", Symbol: "BTCUSDT", Size: 0.1, MarkPrice: 50000, Leverage: 10, LiquidationPrice: 42000, DailyVolatility: 0.02},
+		{Exchange: "binance", Symbol: "ETHUSDT", Size: -1, MarkPrice: 3000, Leverage: 5},
+	}
+	report := RunStressTest(accounts, positions, DefaultStressScenarios([]float64{-0.1, -0.2}, []float64{2}))
+
+	if len(report.Scenarios) != 3 {
+		t.Fatalf("情景数量错误: %d", len(report.Scenarios))
+	}
+	if want := 0.1 * 50000 * 0.02 * varZScore; math.Abs(report.VaR99-want) > 1e-9 {
+		t.Errorf("VaR 错误: 期望 %.4f, 得到 %.4f", want, report.VaR99)
+	}
+	if len(report.Warnings) != 1 {
+		t.Errorf("缺少波动率的持仓应产生 1 条警告, 实际 %v", report.Warnings)
+	}
+
+	// -10%：BTC 多仓亏 500，ETH 空仓赚 300
+	down10 := report.Scenarios[0]
+	if math.Abs(down10.TotalPnL-(-200)) > 1e-9 {
+		t.Errorf("-10%% 总盈亏错误: %.4f", down10.TotalPnL)
+	}
+	btc := down10.Symbols[0]
+	if btc.ShockedPrice != 45000 || btc.Liquidated {
+		t.Errorf("-10%% BTC 结果错误: %+v", btc)
+	}
+	if math.Abs(btc.LiqDistanceAfter-(45000-42000)/45000.0) > 1e-9 {
+		t.Errorf("-10%% BTC 强平距离错误: %.4f", btc.LiqDistanceAfter)
+	}
+	ex := down10.Exchanges[0]
+	if ex.EquityAfter != 800 || math.Abs(ex.MarginAfter-(450+540)) > 1e-9 {
+		t.Errorf("-10%% 账户结果错误: %+v", ex)
+	}
+
+	// -20%：BTC 跌破强平价
+	if !report.Scenarios[1].Symbols[0].Liquidated {
+		t.Errorf("-20%% 时 BTC 应被强平")
+	}
+
+	// 波动率情景只对有波动率数据的持仓施加不利波动
+	vol := report.Scenarios[2]
+	if want := -varZScore * 0.02 * 2; math.Abs(vol.Symbols[0].Move-want) > 1e-9 {
+		t.Errorf("波动率情景 BTC 变动错误: %.4f", vol.Symbols[0].Move)
+	}
+	if vol.Symbols[1].Move != 0 {
+		t.Errorf("缺少波动率的持仓不应变动: %.4f", vol.Symbols[1].Move)
+	}
+}
@@ -0,0 +1,141 @@
+package safety
+
+import (
+	"fmt"
+	"math"
+
+	"quantmesh/indicators"
+)
+
+// DefaultMinQuoteVolume 评估交易对时默认的 24 小时成交额门槛（计价币）
+const DefaultMinQuoteVolume = 10_000_000
+
+// SymbolScoreParams 交易对网格做市适配度评估参数
+type SymbolScoreParams struct {
+	Price                float64   // 当前价格
+	PriceInterval        float64   // 价格间隔（0 表示按小时波动率建议）
+	FeeRate              float64   // 单边挂单手续费率
+	BuyWindow            int       // 买单窗口（估算占用资金）
+	Closes               []float64 // 小时 K 线收盘价（按时间升序）
+	FundingRates         []float64 // 历史资金费率（每期）
+	FundingIntervalHours float64   // 资金费结算间隔（小时，默认 8）
+	Spread               float64   // 盘口相对价差 (ask-bid)/mid（0 表示未知）
+	QuoteVolume24h       float64   // 24 小时成交额（0 表示未知）
+	MinQuoteVolume       float64   // 成交额门槛（0 表示使用默认值）
+}
+
+// SymbolScore 交易对适配度评估结果
+// 收益和成本均按买单窗口占用的资金（买单窗口 × 每笔金额）折算，与每笔金额无关
+type SymbolScore struct {
+	HourlyVolatility  float64  `json:"hourly_volatility"`   // 小时对数收益率标准差
+	DailyVolatility   float64  `json:"daily_volatility"`    // 按 √24 折算的日波动率
+	PriceInterval     float64  `json:"price_interval"`      // 评估使用的价格间隔
+	SuggestedInterval float64  `json:"suggested_interval"`  // 建议价格间隔：一个小时波动率，且至少为双边手续费的 2 倍
+	IntervalRatio     float64  `json:"interval_ratio"`      // 价格间隔 / 价格
+	RoundTripsPerDay  float64  `json:"round_trips_per_day"` // 预计每天完成的买卖轮次：(日波动率 / 间隔比例)² / 2
+	NetEdge           float64  `json:"net_edge"`            // 每轮净收益率：间隔比例 - 双边手续费
+	GridYieldDaily    float64  `json:"grid_yield_daily"`    // 预计网格日收益率
+	AvgFundingRate    float64  `json:"avg_funding_rate"`    // 平均每期资金费率
+	FundingDragDaily  float64  `json:"funding_drag_daily"`  // 多头库存（平均半个买单窗口）的资金费日成本，负数表示收取资金费
+	NetYieldDaily     float64  `json:"net_yield_daily"`     // 网格日收益 - 资金费日成本
+	NetYieldAnnual    float64  `json:"net_yield_annual"`    // 按 365 天折算（单利）
+	SpreadRatio       float64  `json:"spread_ratio"`        // 盘口价差 / 间隔比例（0 表示未知）
+	Score             float64  `json:"score"`               // 0-100：净年化收益（50% 为满分）× 流动性系数 × 价差系数
+	Rating            string   `json:"rating"`              // good（≥60）/ fair（≥30）/ poor
+	Warnings          []string `json:"warnings"`
+}
+
+// ScoreSymbol 根据历史波动率、资金费率、价差和成交额估算交易对做多网格的收益与资金费拖累，并给出适配度评分
+// 波动率按随机游走估计成交频率：价格每移动一个间隔需要 (间隔比例 / 波动率)² 个时间单位，其中一半为上行（卖单成交完成一轮）
+func ScoreSymbol(p SymbolScoreParams) (*SymbolScore, error) {
+	if p.Price <= 0 {
+		return nil, fmt.Errorf("价格必须大于0")
+	}
+	if p.FeeRate < 0 || p.FeeRate >= 1 {
+		return nil, fmt.Errorf("手续费率必须在 0~1 之间")
+	}
+	returns := indicators.LogReturns(p.Closes)
+	if len(returns) < 2 {
+		return nil, fmt.Errorf("K 线数据不足，至少需要 3 根小时 K 线")
+	}
+	buyWindow := p.BuyWindow
+	if buyWindow <= 0 {
+		buyWindow = 10
+	}
+	fundingInterval := p.FundingIntervalHours
+	if fundingInterval <= 0 {
+		fundingInterval = 8
+	}
+	minVolume := p.MinQuoteVolume
+	if minVolume <= 0 {
+		minVolume = DefaultMinQuoteVolume
+	}
+
+	s := &SymbolScore{Warnings: []string{}}
+	s.HourlyVolatility = indicators.SampleStdDev(returns)
+	s.DailyVolatility = s.HourlyVolatility * math.Sqrt(24)
+	s.SuggestedInterval = p.Price * math.Max(s.HourlyVolatility, 4*p.FeeRate)
+	s.PriceInterval = p.PriceInterval
+	if s.PriceInterval <= 0 {
+		s.PriceInterval = s.SuggestedInterval
+	}
+	s.IntervalRatio = s.PriceInterval / p.Price
+	if s.IntervalRatio > 0 {
+		s.RoundTripsPerDay = math.Pow(s.DailyVolatility/s.IntervalRatio, 2) / 2
+	}
+	s.NetEdge = s.IntervalRatio - 2*p.FeeRate
+	s.GridYieldDaily = s.RoundTripsPerDay * s.NetEdge / float64(buyWindow)
+
+	if len(p.FundingRates) > 0 {
+		sum := 0.0
+		for _, r := range p.FundingRates {
+			sum += r
+		}
+		s.AvgFundingRate = sum / float64(len(p.FundingRates))
+		s.FundingDragDaily = s.AvgFundingRate * 24 / fundingInterval * 0.5
+	}
+	s.NetYieldDaily = s.GridYieldDaily - s.FundingDragDaily
+	s.NetYieldAnnual = s.NetYieldDaily * 365
+
+	liquidity := 1.0
+	if p.QuoteVolume24h > 0 && p.QuoteVolume24h < minVolume {
+		liquidity = p.QuoteVolume24h / minVolume
+	}
+	spreadFactor := 1.0
+	if p.Spread > 0 && s.IntervalRatio > 0 {
+		s.SpreadRatio = p.Spread / s.IntervalRatio
+		// 价差不超过间隔的 1/4 时不扣分，达到一个间隔时为 0
+		spreadFactor = math.Max(0, math.Min(1, 1-(s.SpreadRatio-0.25)/0.75))
+	}
+	s.Score = math.Max(0, math.Min(1, s.NetYieldAnnual/0.5)) * liquidity * spreadFactor * 100
+	switch {
+	case s.Score >= 60:
+		s.Rating = "good"
+	case s.Score >= 30:
+		s.Rating = "fair"
+	default:
+		s.Rating = "poor"
+	}
+
+	if len(returns) < 24 {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("只有 %d 根小时 K 线，波动率估计不可靠", len(p.Closes)))
+	}
+	if s.NetEdge <= 0 {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("价格间隔 %.8g 不足以覆盖双边手续费", s.PriceInterval))
+	}
+	if s.RoundTripsPerDay < 1 {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("价格间隔相对波动率过大，预计每天成交 %.2f 轮", s.RoundTripsPerDay))
+	}
+	if len(p.FundingRates) == 0 {
+		s.Warnings = append(s.Warnings, "没有资金费率数据，未计入资金费成本")
+	} else if s.FundingDragDaily > 0 && s.FundingDragDaily >= s.GridYieldDaily {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("资金费日成本 %.4f%% 超过网格日收益 %.4f%%", s.FundingDragDaily*100, s.GridYieldDaily*100))
+	}
+	if s.SpreadRatio > 0.5 {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("盘口价差占价格间隔 %.0f%%，挂单容易被不利成交", s.SpreadRatio*100))
+	}
+	if p.QuoteVolume24h > 0 && p.QuoteVolume24h < minVolume {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("24 小时成交额 %.0f 低于 %.0f", p.QuoteVolume24h, minVolume))
+	}
+	return s, nil
+}
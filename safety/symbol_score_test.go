@@ -0,0 +1,61 @@
+package safety
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScoreSymbol(t *testing.T) {
+	// 每小时在 100 和 101 之间来回，小时波动率约 1%
+	closes := make([]float64, 49)
+	for i := range closes {
+		closes[i] = 100
+		if i%2 == 1 {
+			closes[i] = 101
+		}
+	}
+	params := SymbolScoreParams{
+		Price:          100,
+		PriceInterval:  1,
+		FeeRate:        0.0002,
+		BuyWindow:      10,
+		Closes:         closes,
+		FundingRates:   []float64{0.0001, 0.0001, 0.0001},
+		Spread:         0.0001,
+		QuoteVolume24h: 50_000_000,
+	}
+	score, err := ScoreSymbol(params)
+	if err != nil {
+		t.Fatalf("评估失败: %v", err)
+	}
+	if math.Abs(score.HourlyVolatility-0.01) > 0.0005 {
+		t.Errorf("小时波动率 = %.5f, 期望约 0.01", score.HourlyVolatility)
+	}
+	// 日波动率约 4.9%，间隔 1%：(4.9)^2 / 2 ≈ 12 轮
+	if score.RoundTripsPerDay < 11 || score.RoundTripsPerDay > 13 {
+		t.Errorf("每天轮次 = %.2f, 期望约 12", score.RoundTripsPerDay)
+	}
+	wantDrag := 0.0001 * 3 * 0.5
+	if math.Abs(score.FundingDragDaily-wantDrag) > 1e-12 {
+		t.Errorf("资金费日成本 = %.8f, 期望 %.8f", score.FundingDragDaily, wantDrag)
+	}
+	if score.Rating != "good" || len(score.Warnings) != 0 {
+		t.Errorf("评级 = %s (%.1f), 告警 %v", score.Rating, score.Score, score.Warnings)
+	}
+
+	// 高资金费、宽价差、低成交额时评分下降并给出告警
+	params.FundingRates = []float64{0.01}
+	params.Spread = 0.008
+	params.QuoteVolume24h = 1_000_000
+	score, err = ScoreSymbol(params)
+	if err != nil {
+		t.Fatalf("评估失败: %v", err)
+	}
+	if score.Rating != "poor" || len(score.Warnings) != 3 {
+		t.Errorf("评级 = %s (%.1f), 告警 %v", score.Rating, score.Score, score.Warnings)
+	}
+
+	if _, err := ScoreSymbol(SymbolScoreParams{Price: 100, Closes: []float64{100, 101}}); err == nil {
+		t.Error("K 线不足时应报错")
+	}
+}
@@ -0,0 +1,104 @@
+package safety
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+)
+
+// VolatilityGuardStatus 高波动保护状态
+type VolatilityGuardStatus struct {
+	Active      bool      `json:"active"`
+	Volatility  float64   `json:"volatility"` // 最近一次计算的窗口已实现波动率（%）
+	ActivatedAt time.Time `json:"activated_at,omitempty"`
+}
+
+type priceSample struct {
+	second int64
+	price  float64
+}
+
+// VolatilityGuard 短时高波动保护
+// 按秒采样行情价格，计算窗口内对数收益率的已实现波动率（√Σr²），超过阈值时启用，
+// 回落到恢复阈值以下且至少保持 min_active_seconds 后解除；状态变化通过回调通知
+type VolatilityGuard struct {
+	cfg config.VolatilityGuardConfig
+
+	mu       sync.Mutex
+	samples  []priceSample
+	status   VolatilityGuardStatus
+	onChange func(active bool, volatility float64)
+}
+
+// NewVolatilityGuard 创建高波动保护
+func NewVolatilityGuard(cfg config.VolatilityGuardConfig) *VolatilityGuard {
+	return &VolatilityGuard{cfg: cfg}
+}
+
+// OnChange 设置状态变化回调（在 Update 的调用方协程中同步执行）
+func (g *VolatilityGuard) OnChange(fn func(active bool, volatility float64)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onChange = fn
+}
+
+// Update 输入最新价格并重新评估保护状态
+func (g *VolatilityGuard) Update(price float64, now time.Time) {
+	if price <= 0 {
+		return
+	}
+	g.mu.Lock()
+	sec := now.Unix()
+	if n := len(g.samples); n > 0 && g.samples[n-1].second == sec {
+		g.samples[n-1].price = price
+	} else {
+		g.samples = append(g.samples, priceSample{second: sec, price: price})
+	}
+	cutoff := sec - int64(g.cfg.WindowSeconds)
+	drop := 0
+	for drop < len(g.samples) && g.samples[drop].second < cutoff {
+		drop++
+	}
+	g.samples = g.samples[drop:]
+
+	vol := realizedVolatility(g.samples)
+	g.status.Volatility = vol
+
+	changed := false
+	switch {
+	case !g.status.Active && vol >= g.cfg.ThresholdPercent:
+		g.status.Active = true
+		g.status.ActivatedAt = now
+		changed = true
+	case g.status.Active && vol < g.cfg.RecoverPercent &&
+		now.Sub(g.status.ActivatedAt) >= time.Duration(g.cfg.MinActiveSeconds)*time.Second:
+		g.status.Active = false
+		g.status.ActivatedAt = time.Time{}
+		changed = true
+	}
+	active, fn := g.status.Active, g.onChange
+	g.mu.Unlock()
+
+	if changed && fn != nil {
+		fn(active, vol)
+	}
+}
+
+// Status 获取当前保护状态
+func (g *VolatilityGuard) Status() VolatilityGuardStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.status
+}
+
+// realizedVolatility 按采样计算已实现波动率（%）
+func realizedVolatility(samples []priceSample) float64 {
+	var sumSq float64
+	for i := 1; i < len(samples); i++ {
+		r := math.Log(samples[i].price / samples[i-1].price)
+		sumSq += r * r
+	}
+	return math.Sqrt(sumSq) * 100
+}
@@ -0,0 +1,52 @@
+package safety
+
+import (
+	"testing"
+	"time"
+
+	"quantmesh/config"
+)
+
+func TestVolatilityGuardActivatesAndRecovers(t *testing.T) {
+	g := NewVolatilityGuard(config.VolatilityGuardConfig{
+		WindowSeconds:    10,
+		ThresholdPercent: 1.0,
+		RecoverPercent:   0.5,
+		MinActiveSeconds: 30,
+	})
+	var changes []bool
+	g.OnChange(func(active bool, vol float64) { changes = append(changes, active) })
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(sec int) time.Time { return start.Add(time.Duration(sec) * time.Second) }
+
+	// 平稳行情不触发
+	for i := 0; i < 10; i++ {
+		g.Update(100+float64(i%2)*0.01, at(i))
+	}
+	if g.Status().Active {
+		t.Fatalf("calm market should not activate guard (vol=%.4f)", g.Status().Volatility)
+	}
+
+	// 急跌触发
+	g.Update(97.5, at(11))
+	if !g.Status().Active {
+		t.Fatalf("2.5%% drop should activate guard (vol=%.4f)", g.Status().Volatility)
+	}
+
+	// 波动率回落但未满最短保持时间，不解除
+	for i := 12; i < 30; i++ {
+		g.Update(97.5, at(i))
+	}
+	if !g.Status().Active {
+		t.Fatalf("guard should stay active for min_active_seconds")
+	}
+	g.Update(97.5, at(42))
+	if g.Status().Active {
+		t.Fatalf("guard should recover after volatility falls (vol=%.4f)", g.Status().Volatility)
+	}
+
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("unexpected change notifications: %v", changes)
+	}
+}
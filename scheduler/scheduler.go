@@ -0,0 +1,215 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"quantmesh/logger"
+	"quantmesh/utils"
+)
+
+// Window 计划时段
+type Window interface {
+	Contains(t time.Time) bool
+	String() string
+}
+
+// WeeklyWindow 按周循环的时段（结束早于开始时表示跨周，如周五 20:00 至周一 00:00）
+type WeeklyWindow struct {
+	StartDay    time.Weekday
+	StartMinute int // 开始日当天的分钟数
+	EndDay      time.Weekday
+	EndMinute   int
+	Location    *time.Location
+}
+
+// Contains 时间是否落在时段内（含开始，不含结束）
+func (w WeeklyWindow) Contains(t time.Time) bool {
+	if w.Location != nil {
+		t = t.In(w.Location)
+	}
+	m := int(t.Weekday())*24*60 + t.Hour()*60 + t.Minute()
+	start := int(w.StartDay)*24*60 + w.StartMinute
+	end := int(w.EndDay)*24*60 + w.EndMinute
+	switch {
+	case start < end:
+		return m >= start && m < end
+	case start > end:
+		return m >= start || m < end
+	default:
+		return false
+	}
+}
+
+func (w WeeklyWindow) String() string {
+	loc := "UTC"
+	if w.Location != nil {
+		loc = w.Location.String()
+	}
+	return fmt.Sprintf("每周%s %02d:%02d - %s %02d:%02d (%s)", w.StartDay, w.StartMinute/60, w.StartMinute%60,
+		w.EndDay, w.EndMinute/60, w.EndMinute%60, loc)
+}
+
+// FixedWindow 固定起止时间的一次性时段（如节假日）
+type FixedWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains 时间是否落在时段内（含开始，不含结束）
+func (w FixedWindow) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+func (w FixedWindow) String() string {
+	return fmt.Sprintf("%s - %s", w.Start.Format(time.RFC3339), w.End.Format(time.RFC3339))
+}
+
+// Job 按时段触发的任务：进入任一时段（可提前 Lead）时调用 OnEnter，离开全部时段时调用 OnExit
+type Job struct {
+	Name    string
+	Windows []Window
+	Lead    time.Duration
+	OnEnter func()
+	OnExit  func()
+}
+
+// JobStatus 任务状态
+type JobStatus struct {
+	Name        string     `json:"name"`
+	Windows     []string   `json:"windows"`
+	LeadMinutes int        `json:"lead_minutes"`
+	Active      bool       `json:"active"`
+	Since       *time.Time `json:"since,omitempty"` // 最近一次状态变化时间
+	CheckedAt   time.Time  `json:"checked_at"`
+}
+
+type jobState struct {
+	job       Job
+	active    bool
+	since     time.Time
+	checkedAt time.Time
+}
+
+// Scheduler 定时任务调度器
+// 按固定间隔检查各任务的时段，状态变化时在调度协程中依次执行 OnEnter/OnExit；
+// 启动后的第一次检查如果已处于时段内，同样会触发 OnEnter
+type Scheduler struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu   sync.RWMutex
+	jobs []*jobState
+}
+
+// New 创建调度器
+func New(interval time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &Scheduler{interval: interval, now: time.Now}
+}
+
+// Add 注册任务（需在 Start 之前调用）
+func (s *Scheduler) Add(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, &jobState{job: job})
+}
+
+// Start 立即检查一次，之后按间隔定期检查
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.RLock()
+	count := len(s.jobs)
+	s.mu.RUnlock()
+	logger.Info("⏰ [调度器] 启动 (任务数: %d, 检查间隔: %v)", count, s.interval)
+
+	utils.GoSupervised(ctx, "scheduler", func(ctx context.Context) {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			s.tick(s.now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Status 获取全部任务的状态
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]JobStatus, 0, len(s.jobs))
+	for _, js := range s.jobs {
+		status := JobStatus{
+			Name:        js.job.Name,
+			Windows:     make([]string, 0, len(js.job.Windows)),
+			LeadMinutes: int(js.job.Lead.Minutes()),
+			Active:      js.active,
+			CheckedAt:   js.checkedAt,
+		}
+		for _, w := range js.job.Windows {
+			status.Windows = append(status.Windows, w.String())
+		}
+		if !js.since.IsZero() {
+			since := js.since
+			status.Since = &since
+		}
+		result = append(result, status)
+	}
+	return result
+}
+
+// tick 检查全部任务，状态变化时执行回调（回调在锁外执行）
+func (s *Scheduler) tick(now time.Time) {
+	type transition struct {
+		name  string
+		enter bool
+		fn    func()
+	}
+	var transitions []transition
+
+	s.mu.Lock()
+	for _, js := range s.jobs {
+		js.checkedAt = now
+		active := inWindows(js.job, now)
+		if active == js.active {
+			continue
+		}
+		js.active = active
+		js.since = now
+		fn := js.job.OnExit
+		if active {
+			fn = js.job.OnEnter
+		}
+		transitions = append(transitions, transition{name: js.job.Name, enter: active, fn: fn})
+	}
+	s.mu.Unlock()
+
+	for _, t := range transitions {
+		if t.enter {
+			logger.Info("⏰ [调度器] 任务 %s 进入计划时段", t.name)
+		} else {
+			logger.Info("⏰ [调度器] 任务 %s 离开计划时段", t.name)
+		}
+		if t.fn != nil {
+			t.fn()
+		}
+	}
+}
+
+// inWindows 是否处于任一时段内，或距时段开始不足 Lead
+func inWindows(job Job, now time.Time) bool {
+	for _, w := range job.Windows {
+		if w.Contains(now) || (job.Lead > 0 && w.Contains(now.Add(job.Lead))) {
+			return true
+		}
+	}
+	return false
+}
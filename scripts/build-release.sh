@@ -5,6 +5,7 @@
 set -e
 
 VERSION=${1:-"dev"}
+GIT_COMMIT=$(git rev-parse HEAD 2>/dev/null || echo "")
 PROJECT_NAME="quantmesh"
 PLUGIN_DIR="../quantmesh-premium/plugins"
 
@@ -56,14 +57,14 @@ build_platform() {
     echo "  → 编译主程序..."
     cd "${PROJECT_ROOT}"
     GOOS=${GOOS} GOARCH=${GOARCH} go build \
-        -ldflags "-X main.Version=${VERSION}" \
+        -ldflags "-X main.Version=${VERSION} -X main.GitCommit=${GIT_COMMIT}" \
         -o "${OUTPUT_DIR}/${PROJECT_NAME}" \
         ./cmd/main.go || ./main.go 2>/dev/null || {
         echo -e "${RED}  ❌ 主程序编译失败（尝试其他入口）${NC}"
         # 如果没有 cmd/main.go，尝试根目录的 main.go
         if [ -f "main.go" ]; then
             GOOS=${GOOS} GOARCH=${GOARCH} go build \
-                -ldflags "-X main.Version=${VERSION}" \
+                -ldflags "-X main.Version=${VERSION} -X main.GitCommit=${GIT_COMMIT}" \
                 -o "${OUTPUT_DIR}/${PROJECT_NAME}" \
                 .
         else
@@ -91,7 +91,9 @@ fi
 log_info "📌 版本号: ${VERSION}"
 
 cd "${SCRIPT_DIR}"
-go build -ldflags="-s -w -X main.Version=${VERSION}" -o quantmesh .
+GIT_COMMIT=$(git rev-parse HEAD 2>/dev/null || echo "")
+BUILD_TIME=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+go build -ldflags="-s -w -X main.Version=${VERSION} -X main.GitCommit=${GIT_COMMIT} -X main.BuildTime=${BUILD_TIME}" -o quantmesh .
 
 echo ""
 echo -e "${GREEN}========================================${NC}"
@@ -0,0 +1,96 @@
+package shipper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// diskBuffer 发送失败时的磁盘缓存
+// 每个批次一个文件，文件名为写入时间（纳秒），按文件名顺序补发；超出容量上限时丢弃最旧的批次
+type diskBuffer struct {
+	dir      string
+	maxBytes int64
+}
+
+func newDiskBuffer(dir string, maxBytes int64) (*diskBuffer, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("创建缓存目录失败: %w", err)
+	}
+	return &diskBuffer{dir: dir, maxBytes: maxBytes}, nil
+}
+
+// push 写入一个批次，返回因超出容量而丢弃的批次数
+func (b *diskBuffer) push(batch []Entry) (int, error) {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return 0, err
+	}
+	path := filepath.Join(b.dir, fmt.Sprintf("%020d.json", time.Now().UnixNano()))
+	if err := os.WriteFile(path+".tmp", data, 0600); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return 0, err
+	}
+	return b.trim()
+}
+
+// files 按写入顺序列出缓存的批次文件
+func (b *diskBuffer) files() ([]string, error) {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, filepath.Join(b.dir, e.Name()))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// load 读取一个批次
+func (b *diskBuffer) load(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var batch []Entry
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// trim 从最旧的批次开始删除，直到总大小不超过上限
+func (b *diskBuffer) trim() (int, error) {
+	names, err := b.files()
+	if err != nil {
+		return 0, err
+	}
+	sizes := make([]int64, len(names))
+	var total int64
+	for i, name := range names {
+		if info, err := os.Stat(name); err == nil {
+			sizes[i] = info.Size()
+			total += sizes[i]
+		}
+	}
+	dropped := 0
+	// 至少保留最新的一个批次
+	for i := 0; i < len(names)-1 && total > b.maxBytes; i++ {
+		if err := os.Remove(names[i]); err != nil {
+			return dropped, err
+		}
+		total -= sizes[i]
+		dropped++
+	}
+	return dropped, nil
+}
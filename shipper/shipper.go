@@ -0,0 +1,350 @@
+package shipper
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"quantmesh/config"
+	"quantmesh/logger"
+	"quantmesh/utils"
+)
+
+// 记录类型
+const (
+	KindLog    = "log"
+	KindMetric = "metric"
+)
+
+const (
+	queueSize       = 10000 // 内存队列容量，队列满时丢弃新日志并计数
+	replayPerFlush  = 10    // 每次发送前最多补发的缓存批次数，避免长时间阻塞队列
+	shutdownTimeout = 5 * time.Second
+)
+
+// Entry 一条转发记录
+// 指标以 logfmt 文本行的形式发送（metric=名称 value=值 标签=值），Loki 中可用 | logfmt 解析
+type Entry struct {
+	Time    time.Time `json:"ts"`
+	Kind    string    `json:"kind"`
+	Level   string    `json:"level,omitempty"`
+	Message string    `json:"message"`
+}
+
+// Shipper 把结构化日志和关键指标通过 TLS 转发到远程端点
+// 日志经 logger.InitLogShipper 非阻塞写入内存队列，后台按批次发送；
+// 发送失败的批次缓存到磁盘，端点恢复后按顺序补发
+type Shipper struct {
+	cfg      config.LogShippingConfig
+	labels   map[string]string
+	minLevel logger.LogLevel
+	metrics  map[string]bool
+	client   *http.Client
+	gatherer prometheus.Gatherer
+	buffer   *diskBuffer
+	queue    chan Entry
+	dropped  atomic.Int64
+	down     bool // 端点不可用（只在发送 goroutine 中读写）
+}
+
+// New 创建转发器，instance 为实例标识（写入 instance 标签）
+func New(cfg config.LogShippingConfig, instance string) (*Shipper, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLS.CAFile != "" {
+		pem, err := os.ReadFile(cfg.TLS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书失败: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA 证书 %s 无效", cfg.TLS.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.TLS.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	buffer, err := newDiskBuffer(cfg.BufferDir, int64(cfg.MaxBufferMB)*1024*1024)
+	if err != nil {
+		return nil, err
+	}
+
+	host, _ := os.Hostname()
+	if instance == "" {
+		instance = host
+	}
+	labels := map[string]string{"app": "quantmesh", "instance": instance, "host": host}
+	for k, v := range cfg.Labels {
+		labels[k] = v
+	}
+	metrics := make(map[string]bool, len(cfg.Metrics))
+	for _, name := range cfg.Metrics {
+		metrics[name] = true
+	}
+
+	return &Shipper{
+		cfg:      cfg,
+		labels:   labels,
+		minLevel: logger.ParseLogLevel(cfg.MinLevel),
+		metrics:  metrics,
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+		},
+		gatherer: prometheus.DefaultGatherer,
+		buffer:   buffer,
+		queue:    make(chan Entry, queueSize),
+	}, nil
+}
+
+// Log 日志钩子（由 logger 同步调用，不能阻塞）
+func (s *Shipper) Log(level, message string) {
+	if logger.ParseLogLevel(level) < s.minLevel {
+		return
+	}
+	s.enqueue(Entry{
+		Time:    time.Now(),
+		Kind:    KindLog,
+		Level:   strings.ToLower(level),
+		Message: strings.TrimPrefix(message, "["+level+"] "),
+	})
+}
+
+// enqueue 写入队列，队列满时丢弃并计数
+func (s *Shipper) enqueue(e Entry) {
+	select {
+	case s.queue <- e:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// Start 后台发送
+func (s *Shipper) Start(ctx context.Context) {
+	utils.GoSupervised(ctx, "log-shipper", s.run)
+}
+
+func (s *Shipper) run(ctx context.Context) {
+	flushTicker := time.NewTicker(time.Duration(s.cfg.FlushInterval) * time.Second)
+	defer flushTicker.Stop()
+	metricsTicker := time.NewTicker(time.Duration(s.cfg.MetricsInterval) * time.Second)
+	defer metricsTicker.Stop()
+
+	var batch []Entry
+	for {
+		select {
+		case <-ctx.Done():
+			// 退出前发送剩余日志，发不出去的写入磁盘缓存，下次启动时补发
+			for len(s.queue) > 0 {
+				batch = append(batch, <-s.queue)
+			}
+			flushCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			s.flush(flushCtx, batch)
+			cancel()
+			return
+		case e := <-s.queue:
+			batch = append(batch, e)
+			if len(batch) >= s.cfg.BatchSize {
+				s.flush(ctx, batch)
+				batch = nil
+			}
+		case <-flushTicker.C:
+			s.flush(ctx, batch)
+			batch = nil
+		case <-metricsTicker.C:
+			batch = append(batch, s.collectMetrics(time.Now())...)
+		}
+	}
+}
+
+// flush 先按顺序补发磁盘缓存，再发送当前批次
+// 缓存未补发完时当前批次直接写入缓存，保证端点收到的顺序与产生顺序一致
+func (s *Shipper) flush(ctx context.Context, batch []Entry) {
+	if n := s.dropped.Swap(0); n > 0 {
+		batch = append(batch, Entry{Time: time.Now(), Kind: KindLog, Level: "warn",
+			Message: fmt.Sprintf("⚠️ [日志转发] 队列已满，丢弃了 %d 条日志", n)})
+	}
+
+	err := s.replay(ctx)
+	if err == nil && len(batch) > 0 {
+		err = s.send(ctx, batch)
+	}
+	if err != nil {
+		if len(batch) > 0 {
+			if dropped, bufErr := s.buffer.push(batch); bufErr != nil {
+				logger.Error("❌ [日志转发] 写入磁盘缓存失败: %v", bufErr)
+			} else if dropped > 0 {
+				logger.Warn("⚠️ [日志转发] 磁盘缓存超过 %d MB，丢弃了 %d 个最旧的批次", s.cfg.MaxBufferMB, dropped)
+			}
+		}
+		if !s.down {
+			s.down = true
+			logger.Warn("⚠️ [日志转发] 发送到 %s 失败，日志将缓存到磁盘: %v", s.cfg.URL, err)
+		}
+		return
+	}
+	if s.down {
+		s.down = false
+		logger.Info("✅ [日志转发] 端点已恢复")
+	}
+}
+
+// replay 补发磁盘缓存中最旧的若干批次
+func (s *Shipper) replay(ctx context.Context) error {
+	files, err := s.buffer.files()
+	if err != nil {
+		return err
+	}
+	if len(files) > replayPerFlush {
+		files = files[:replayPerFlush]
+	}
+	for _, path := range files {
+		batch, err := s.buffer.load(path)
+		if err != nil {
+			// 损坏的缓存文件无法补发，直接删除
+			logger.Warn("⚠️ [日志转发] 丢弃无法读取的缓存 %s: %v", path, err)
+			os.Remove(path)
+			continue
+		}
+		if err := s.send(ctx, batch); err != nil {
+			return err
+		}
+		os.Remove(path)
+	}
+	return nil
+}
+
+// send 按目标类型编码并发送一个批次
+func (s *Shipper) send(ctx context.Context, batch []Entry) error {
+	var body []byte
+	var err error
+	if s.cfg.Type == config.LogShippingLoki {
+		body, err = encodeLoki(s.labels, batch)
+	} else {
+		body, err = json.Marshal(map[string]interface{}{"labels": s.labels, "entries": batch})
+	}
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.cfg.Username != "" || s.cfg.Password != "" {
+		req.SetBasicAuth(s.cfg.Username, s.cfg.Password)
+	}
+	for k, v := range s.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// encodeLoki 编码为 Loki push API 请求体：按 kind/level 分组为 stream，组内按时间排序
+func encodeLoki(labels map[string]string, batch []Entry) ([]byte, error) {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	streams := make(map[string]*stream)
+	var keys []string
+	sorted := append([]Entry(nil), batch...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+	for _, e := range sorted {
+		key := e.Kind + "/" + e.Level
+		st, ok := streams[key]
+		if !ok {
+			streamLabels := make(map[string]string, len(labels)+2)
+			for k, v := range labels {
+				streamLabels[k] = v
+			}
+			streamLabels["kind"] = e.Kind
+			if e.Level != "" {
+				streamLabels["level"] = e.Level
+			}
+			st = &stream{Stream: streamLabels}
+			streams[key] = st
+			keys = append(keys, key)
+		}
+		st.Values = append(st.Values, [2]string{strconv.FormatInt(e.Time.UnixNano(), 10), e.Message})
+	}
+
+	payload := struct {
+		Streams []*stream `json:"streams"`
+	}{}
+	for _, key := range keys {
+		payload.Streams = append(payload.Streams, streams[key])
+	}
+	return json.Marshal(payload)
+}
+
+// collectMetrics 从 Prometheus 注册表采样配置的指标（只处理 gauge/counter）
+func (s *Shipper) collectMetrics(now time.Time) []Entry {
+	families, err := s.gatherer.Gather()
+	if err != nil {
+		logger.Warn("⚠️ [日志转发] 采集指标失败: %v", err)
+	}
+	var entries []Entry
+	for _, mf := range families {
+		if !s.metrics[mf.GetName()] {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			var value float64
+			switch mf.GetType() {
+			case dto.MetricType_GAUGE:
+				value = m.GetGauge().GetValue()
+			case dto.MetricType_COUNTER:
+				value = m.GetCounter().GetValue()
+			case dto.MetricType_UNTYPED:
+				value = m.GetUntyped().GetValue()
+			default:
+				continue
+			}
+			var line strings.Builder
+			fmt.Fprintf(&line, "metric=%s value=%s", mf.GetName(), strconv.FormatFloat(value, 'f', -1, 64))
+			for _, lp := range m.GetLabel() {
+				fmt.Fprintf(&line, " %s=%s", lp.GetName(), logfmtValue(lp.GetValue()))
+			}
+			entries = append(entries, Entry{Time: now, Kind: KindMetric, Message: line.String()})
+		}
+	}
+	return entries
+}
+
+// logfmtValue 含空格、引号或等号的值加引号
+func logfmtValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " \"=") {
+		return strconv.Quote(v)
+	}
+	return v
+}
@@ -0,0 +1,138 @@
+package shipper
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"quantmesh/config"
+)
+
+// lokiSink 记录收到的 Loki push 请求，available 为 false 时返回 503
+type lokiSink struct {
+	mu        sync.Mutex
+	available bool
+	lines     []string
+	streams   []map[string]string
+}
+
+func (s *lokiSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.available {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	var payload struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	for _, st := range payload.Streams {
+		s.streams = append(s.streams, st.Stream)
+		for _, v := range st.Values {
+			s.lines = append(s.lines, v[1])
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newTestShipper(t *testing.T, url string) *Shipper {
+	t.Helper()
+	cfg := config.LogShippingConfig{
+		Enabled:     true,
+		Type:        config.LogShippingLoki,
+		URL:         url,
+		MinLevel:    "info",
+		Metrics:     []string{"test_position_size"},
+		BufferDir:   t.TempDir(),
+		MaxBufferMB: 1,
+		Labels:      map[string]string{"env": "test"},
+	}
+	s, err := New(cfg, "bot-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestShipperBuffersDuringOutage(t *testing.T) {
+	sink := &lokiSink{}
+	server := httptest.NewTLSServer(sink)
+	defer server.Close()
+	s := newTestShipper(t, server.URL)
+	s.client = server.Client()
+	ctx := context.Background()
+
+	s.Log("DEBUG", "[DEBUG] below min level")
+	s.Log("INFO", "[INFO] first")
+	s.flush(ctx, []Entry{<-s.queue})
+	if files, _ := s.buffer.files(); len(files) != 1 || !s.down {
+		t.Fatalf("batch should be buffered while endpoint is down: files=%v down=%v", files, s.down)
+	}
+
+	sink.mu.Lock()
+	sink.available = true
+	sink.mu.Unlock()
+	s.Log("ERROR", "[ERROR] second")
+	s.flush(ctx, []Entry{<-s.queue})
+	if files, _ := s.buffer.files(); len(files) != 0 || s.down {
+		t.Fatalf("buffer should be drained after recovery: files=%v down=%v", files, s.down)
+	}
+	if strings.Join(sink.lines, ",") != "first,second" {
+		t.Fatalf("lines = %v, want buffered batch replayed first", sink.lines)
+	}
+	st := sink.streams[1]
+	if st["instance"] != "bot-1" || st["env"] != "test" || st["level"] != "error" || st["kind"] != KindLog {
+		t.Errorf("unexpected stream labels: %v", st)
+	}
+}
+
+func TestShipperCollectMetrics(t *testing.T) {
+	s := newTestShipper(t, "https://example.invalid")
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_position_size"}, []string{"exchange", "symbol"})
+	other := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_ignored"})
+	registry.MustRegister(gauge, other)
+	gauge.WithLabelValues("binance", "BTCUSDT").Set(0.25)
+	s.gatherer = registry
+
+	entries := s.collectMetrics(time.Now())
+	if len(entries) != 1 {
+		t.Fatalf("entries = %+v, want only the configured metric", entries)
+	}
+	want := "metric=test_position_size value=0.25 exchange=binance symbol=BTCUSDT"
+	if entries[0].Kind != KindMetric || entries[0].Message != want {
+		t.Errorf("entry = %+v, want %q", entries[0], want)
+	}
+}
+
+func TestDiskBufferTrim(t *testing.T) {
+	b, err := newDiskBuffer(t.TempDir(), 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := []Entry{{Time: time.Now(), Kind: KindLog, Message: strings.Repeat("x", 100)}}
+	for i := 0; i < 3; i++ {
+		if _, err := b.push(batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if files, _ := b.files(); len(files) != 1 {
+		t.Fatalf("only the newest batch should remain under the size cap, got %d", len(files))
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"quantmesh/config"
+	"quantmesh/exchange"
+	"quantmesh/logger"
+)
+
+// gridStrategyName 网格主策略（仓位管理器直接下单，订单不带策略标记）在退出策略配置中的名称
+const gridStrategyName = "grid"
+
+// runtimeStrategies 交易对上运行的策略：网格主策略和多策略模式下注册的其它策略
+func runtimeStrategies(rt *SymbolRuntime) []string {
+	names := []string{gridStrategyName}
+	if rt.StrategyManager != nil {
+		for name := range rt.StrategyManager.GetAllStrategies() {
+			if name != gridStrategyName {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// orderStrategy 订单所属策略：多策略执行器下的订单按下单时记录的策略名称，其余（包括重启前遗留的订单）归网格主策略
+func orderStrategy(rt *SymbolRuntime, orderID int64) string {
+	if rt.MultiExecutor != nil {
+		if name := rt.MultiExecutor.GetStrategyByOrderID(orderID); name != "" {
+			return name
+		}
+	}
+	return gridStrategyName
+}
+
+// cancelOnShutdown 按退出方式判断订单是否需要撤销
+func cancelOnShutdown(policy string, side exchange.Side) bool {
+	switch policy {
+	case config.ShutdownPolicyCancelAll, config.ShutdownPolicyFlatten:
+		return true
+	case config.ShutdownPolicyCancelBuys:
+		return side == exchange.SideBuy
+	}
+	return false
+}
+
+// shutdownCancelsAny 是否有交易对的策略需要在退出时撤单
+func shutdownCancelsAny(cfg *config.Config, runtimes []*SymbolRuntime) bool {
+	for _, rt := range runtimes {
+		for _, name := range runtimeStrategies(rt) {
+			if cfg.ShutdownPolicy(name) != config.ShutdownPolicyLeave {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cancelForShutdown 按各策略的退出方式撤单，返回保留在交易所的挂单数
+// 所有策略都需要撤销全部挂单时直接整体撤单；否则查询挂单后逐笔按所属策略筛选
+func cancelForShutdown(ctx context.Context, cfg *config.Config, rt *SymbolRuntime) (int, error) {
+	symbol := rt.Config.Symbol
+	cancelAll, cancelNone := true, true
+	for _, name := range runtimeStrategies(rt) {
+		switch cfg.ShutdownPolicy(name) {
+		case config.ShutdownPolicyCancelAll, config.ShutdownPolicyFlatten:
+			cancelNone = false
+		case config.ShutdownPolicyCancelBuys:
+			cancelAll, cancelNone = false, false
+		default:
+			cancelAll = false
+		}
+	}
+	if cancelNone {
+		logger.Info("ℹ️ [%s:%s] 按退出策略保留所有挂单", rt.Config.Exchange, symbol)
+		return 0, nil
+	}
+	if cancelAll {
+		return 0, rt.Exchange.CancelAllOrders(ctx, symbol)
+	}
+
+	orders, err := rt.Exchange.GetOpenOrders(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int64
+	kept := 0
+	for _, o := range orders {
+		if cancelOnShutdown(cfg.ShutdownPolicy(orderStrategy(rt, o.OrderID)), o.Side) {
+			ids = append(ids, o.OrderID)
+		} else {
+			kept++
+		}
+	}
+	if len(ids) > 0 {
+		if err := rt.Exchange.BatchCancelOrders(ctx, symbol, ids); err != nil {
+			return kept, err
+		}
+	}
+	logger.Info("🛑 [%s:%s] 按退出策略撤销 %d 笔挂单，保留 %d 笔", rt.Config.Exchange, symbol, len(ids), kept)
+	return kept, nil
+}
+
+// flattenForShutdown 平掉退出方式为 flatten 的策略持仓
+// 全部策略都平仓时平掉交易所上的整个持仓；否则只卖出这些策略自己的库存，其余策略的持仓保留
+func flattenForShutdown(ctx context.Context, cfg *config.Config, rt *SymbolRuntime) {
+	var flatten []string
+	all := true
+	for _, name := range runtimeStrategies(rt) {
+		if cfg.ShutdownPolicy(name) == config.ShutdownPolicyFlatten {
+			flatten = append(flatten, name)
+		} else {
+			all = false
+		}
+	}
+	if len(flatten) == 0 {
+		return
+	}
+	if all {
+		logger.Info("🔄 [%s:%s] 正在平掉所有持仓...", rt.Config.Exchange, rt.Config.Symbol)
+		closeAllPositions(ctx, rt.Exchange, rt.Config.Symbol, rt.PriceMonitor)
+		return
+	}
+
+	qty := 0.0
+	for _, name := range flatten {
+		qty += strategyInventory(rt, name)
+	}
+	decimals := math.Pow10(rt.Exchange.GetQuantityDecimals())
+	qty = math.Floor(qty*decimals) / decimals
+	if qty <= 0 {
+		return
+	}
+	price := 0.0
+	if rt.PriceMonitor != nil {
+		price = rt.PriceMonitor.GetLastPrice()
+	}
+	if price <= 0 {
+		if p, err := rt.Exchange.GetLatestPrice(ctx, rt.Config.Symbol); err == nil {
+			price = p
+		}
+	}
+	if price <= 0 {
+		logger.Error("❌ [%s:%s] 无法获取价格，跳过策略 %v 的平仓", rt.Config.Exchange, rt.Config.Symbol, flatten)
+		return
+	}
+
+	logger.Info("🔄 [%s:%s] 平掉策略 %v 的持仓: SELL %.6f @ %.8g (ReduceOnly)", rt.Config.Exchange, rt.Config.Symbol, flatten, qty, price)
+	if _, err := rt.Exchange.PlaceOrder(ctx, &exchange.OrderRequest{
+		Symbol:        rt.Config.Symbol,
+		Side:          exchange.SideSell,
+		Type:          exchange.OrderTypeLimit,
+		TimeInForce:   exchange.TimeInForceGTC,
+		Quantity:      qty,
+		Price:         price,
+		ReduceOnly:    true,
+		PriceDecimals: rt.Exchange.GetPriceDecimals(),
+	}); err != nil {
+		logger.Error("❌ [%s:%s] 策略 %v 平仓下单失败: %v", rt.Config.Exchange, rt.Config.Symbol, flatten, err)
+	}
+}
+
+// strategyInventory 策略自己的持仓数量：网格主策略为槽位持仓，其它策略为策略记录的持仓
+func strategyInventory(rt *SymbolRuntime, name string) float64 {
+	if name == gridStrategyName {
+		if rt.SuperPositionManager == nil {
+			return 0
+		}
+		qty, _, _ := rt.SuperPositionManager.GetCostBasis()
+		return qty
+	}
+	if rt.StrategyManager == nil {
+		return 0
+	}
+	s := rt.StrategyManager.GetStrategy(name)
+	if s == nil {
+		return 0
+	}
+	qty := 0.0
+	for _, p := range s.GetPositions() {
+		if p != nil && p.Size > 0 {
+			qty += p.Size
+		}
+	}
+	return qty
+}
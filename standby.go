@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/event"
+	"quantmesh/exchange"
+	"quantmesh/lock"
+	"quantmesh/logger"
+	"quantmesh/monitor"
+	"quantmesh/position"
+	"quantmesh/standby"
+)
+
+// standbySnapshotAdapter 主实例状态快照适配器
+type standbySnapshotAdapter struct {
+	manager *SymbolManager
+}
+
+func (a *standbySnapshotAdapter) Snapshots() []standby.SymbolSnapshot {
+	runtimes := a.manager.List()
+	result := make([]standby.SymbolSnapshot, 0, len(runtimes))
+	for _, rt := range runtimes {
+		if rt == nil || rt.SuperPositionManager == nil {
+			continue
+		}
+		snap := standby.SymbolSnapshot{
+			Exchange:      rt.Config.Exchange,
+			Symbol:        rt.Config.Symbol,
+			AnchorPrice:   rt.SuperPositionManager.GetAnchorPrice(),
+			PriceInterval: rt.SuperPositionManager.GetPriceInterval(),
+			Paused:        rt.SuperPositionManager.IsPaused(),
+			Slots:         rt.SuperPositionManager.SnapshotSlots(),
+			TakenAt:       time.Now(),
+		}
+		if snap.Slots == nil {
+			snap.Slots = []position.SlotSnapshot{}
+		}
+		if rt.PriceMonitor != nil {
+			snap.LastPrice = rt.PriceMonitor.GetLastPrice()
+		}
+		result = append(result, snap)
+	}
+	return result
+}
+
+// standbyInstanceID 复制通道中使用的实例标识
+func standbyInstanceID(cfg *config.Config) string {
+	if cfg.Instance.ID != "" {
+		return cfg.Instance.ID
+	}
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// runStandbySymbol 备用实例：只读订阅行情，主实例复制通道静默超过 takeover_after 后尝试接管
+// 接管时按主实例最后一次快照恢复网格（与协调重启相同的 ResumeSlots 流程）：沿用锚点和槽位持仓，
+// 仍挂在交易所的订单直接接管；快照之后有成交导致持仓不一致时退回按镜像锚点正常初始化。
+// 实例锁（租约）仍被持有时继续等待
+func runStandbySymbol(ctx context.Context, cfg *config.Config, symCfg config.SymbolConfig, follower *standby.Follower,
+	launcher *symbolManagerWebAdapter) {
+	key := fmt.Sprintf("%s:%s", symCfg.Exchange, symCfg.Symbol)
+
+	feedCfg := *cfg
+	feedCfg.App.CurrentExchange = symCfg.Exchange
+	feedCfg.Trading.Symbol = symCfg.Symbol
+	var feed *monitor.PriceMonitor
+	if ex, err := exchange.NewExchange(&feedCfg, symCfg.Exchange, symCfg.Symbol); err != nil {
+		logger.Warn("⚠️ [热备] [%s] 创建只读行情失败: %v", key, err)
+	} else {
+		feed = monitor.NewPriceMonitor(ex, symCfg.Symbol, feedCfg.Timing.PriceSendInterval)
+		if err := feed.Start(); err != nil {
+			logger.Warn("⚠️ [热备] [%s] 启动只读行情失败: %v", key, err)
+			feed = nil
+		}
+	}
+	logger.Info("👀 [热备] [%s] 备用模式运行中，主实例静默 %ds 后尝试接管", key, cfg.Standby.TakeoverAfter)
+
+	takeoverAfter := time.Duration(cfg.Standby.TakeoverAfter) * time.Second
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if feed != nil {
+				feed.Stop()
+			}
+			return
+		case <-ticker.C:
+		}
+		if follower.PrimaryAlive(takeoverAfter) {
+			continue
+		}
+
+		takeoverCfg := *cfg
+		snap, mirrored := follower.Snapshot(symCfg.Exchange, symCfg.Symbol)
+		if mirrored && snap.AnchorPrice > 0 {
+			takeoverCfg.Trading.Anchor.Mode = "manual"
+			takeoverCfg.Trading.Anchor.Price = snap.AnchorPrice
+			stageResumeSymbol(standbyResumeSymbol(snap))
+		}
+		err := launcher.launchSymbol(&takeoverCfg, symCfg)
+		if err != nil {
+			takeResumeSymbol(symCfg.Exchange, symCfg.Symbol)
+			if !lock.IsInstanceLocked(err) {
+				logger.Warn("⚠️ [热备] [%s] 接管失败，稍后重试: %v", key, err)
+			}
+			continue
+		}
+
+		follower.MarkTakenOver(symCfg.Exchange, symCfg.Symbol)
+		if feed != nil {
+			feed.Stop()
+		}
+		logger.Warn("🔀 [热备] [%s] 主实例已失联，备用实例接管交易 (镜像锚点: %.8g, 镜像持仓: %.8g)",
+			key, snap.AnchorPrice, snap.TotalPosition())
+		if launcher.eventBus != nil {
+			launcher.eventBus.Publish(&event.Event{
+				Type: event.EventTypeStandbyTakeover,
+				Data: map[string]interface{}{
+					"exchange":        symCfg.Exchange,
+					"symbol":          symCfg.Symbol,
+					"mirrored":        mirrored,
+					"anchor_price":    snap.AnchorPrice,
+					"mirrored_qty":    snap.TotalPosition(),
+					"primary_last_at": snap.TakenAt,
+				},
+			})
+		}
+		return
+	}
+}
+
+// standbyResumeSymbol 把主实例的快照转换为恢复信息，交给 launchSymbol 按 ResumeSlots 恢复网格
+// 快照中的槽位持仓合计作为预期持仓，与交易所当前持仓不一致说明快照之后主实例还有成交
+func standbyResumeSymbol(snap standby.SymbolSnapshot) *resumeSymbol {
+	return &resumeSymbol{
+		Exchange:      snap.Exchange,
+		Symbol:        snap.Symbol,
+		Anchor:        snap.AnchorPrice,
+		PriceInterval: snap.PriceInterval,
+		Position:      snap.TotalPosition(),
+		Slots:         snap.Slots,
+		source:        "热备接管",
+	}
+}
@@ -0,0 +1,196 @@
+package standby
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"quantmesh/config"
+	"quantmesh/logger"
+)
+
+// Status 备用实例状态
+type Status struct {
+	Role           string           `json:"role"`
+	Clients        int              `json:"clients,omitempty"` // primary：已连接的备用实例数
+	PrimaryURL     string           `json:"primary_url"`
+	PrimaryID      string           `json:"primary_id"`
+	Connected      bool             `json:"connected"`
+	LastMessageAt  time.Time        `json:"last_message_at"`
+	LastSeq        uint64           `json:"last_seq"`
+	Gaps           int64            `json:"gaps"` // 序号不连续次数（重连或推送丢弃）
+	EventsReceived int64            `json:"events_received"`
+	TakenOver      []string         `json:"taken_over"` // 已接管的 exchange:symbol
+	Snapshots      []SymbolSnapshot `json:"snapshots"`
+}
+
+// Follower 备用实例：订阅主实例复制通道，镜像其状态
+type Follower struct {
+	cfg    config.StandbyConfig
+	dialer *websocket.Dialer
+
+	mu             sync.RWMutex
+	connected      bool
+	primaryID      string
+	lastMessageAt  time.Time
+	lastSeq        uint64
+	gaps           int64
+	eventsReceived int64
+	snapshots      map[string]SymbolSnapshot
+	takenOver      map[string]bool
+	onEvent        func(*EventMessage)
+}
+
+// NewFollower 创建复制通道订阅者
+func NewFollower(cfg config.StandbyConfig) *Follower {
+	return &Follower{
+		cfg:       cfg,
+		dialer:    &websocket.Dialer{HandshakeTimeout: 10 * time.Second},
+		snapshots: make(map[string]SymbolSnapshot),
+		takenOver: make(map[string]bool),
+	}
+}
+
+// snapshotKey exchange:symbol
+func snapshotKey(exchange, symbol string) string {
+	return strings.ToLower(exchange) + ":" + strings.ToUpper(symbol)
+}
+
+// SetOnEvent 设置复制事件回调（用于日志或镜像展示）
+func (f *Follower) SetOnEvent(fn func(*EventMessage)) {
+	f.mu.Lock()
+	f.onEvent = fn
+	f.mu.Unlock()
+}
+
+// Start 启动订阅（断开后自动重连）
+func (f *Follower) Start(ctx context.Context) {
+	go func() {
+		backoff := time.Second
+		for {
+			if err := f.follow(ctx); err != nil && ctx.Err() == nil {
+				logger.Warn("⚠️ [热备] 复制通道断开: %v，%v 后重连", err, backoff)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff < 10*time.Second {
+				backoff *= 2
+			}
+		}
+	}()
+}
+
+// follow 建立一次连接并持续读取消息
+func (f *Follower) follow(ctx context.Context) error {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+f.cfg.Token)
+	conn, _, err := f.dialer.DialContext(ctx, f.cfg.PrimaryURL, header)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	f.mu.Lock()
+	f.connected = true
+	f.mu.Unlock()
+	logger.Info("🔁 [热备] 已连接主实例复制通道: %s", f.cfg.PrimaryURL)
+	defer func() {
+		f.mu.Lock()
+		f.connected = false
+		f.mu.Unlock()
+	}()
+
+	for {
+		var msg Message
+		if err := conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+		f.apply(&msg)
+	}
+}
+
+// apply 应用一条复制消息
+func (f *Follower) apply(msg *Message) {
+	f.mu.Lock()
+	if f.lastSeq != 0 && msg.Seq != f.lastSeq+1 {
+		f.gaps++
+	}
+	f.lastSeq = msg.Seq
+	f.primaryID = msg.Instance
+	f.lastMessageAt = time.Now()
+
+	var onEvent func(*EventMessage)
+	switch msg.Type {
+	case MessageSnapshot:
+		for _, snap := range msg.Snapshots {
+			f.snapshots[snapshotKey(snap.Exchange, snap.Symbol)] = snap
+		}
+	case MessageEvent:
+		if msg.Event != nil {
+			f.eventsReceived++
+			onEvent = f.onEvent
+		}
+	}
+	f.mu.Unlock()
+
+	if onEvent != nil {
+		onEvent(msg.Event)
+	}
+}
+
+// PrimaryAlive 主实例在 within 时间内是否推送过消息
+func (f *Follower) PrimaryAlive(within time.Duration) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return !f.lastMessageAt.IsZero() && time.Since(f.lastMessageAt) < within
+}
+
+// Snapshot 获取镜像的交易对快照
+func (f *Follower) Snapshot(exchange, symbol string) (SymbolSnapshot, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	snap, ok := f.snapshots[snapshotKey(exchange, symbol)]
+	return snap, ok
+}
+
+// MarkTakenOver 记录已接管的交易对
+func (f *Follower) MarkTakenOver(exchange, symbol string) {
+	f.mu.Lock()
+	f.takenOver[snapshotKey(exchange, symbol)] = true
+	f.mu.Unlock()
+}
+
+// Status 获取备用实例状态
+func (f *Follower) Status() *Status {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	status := &Status{
+		Role:           "standby",
+		PrimaryURL:     f.cfg.PrimaryURL,
+		PrimaryID:      f.primaryID,
+		Connected:      f.connected,
+		LastMessageAt:  f.lastMessageAt,
+		LastSeq:        f.lastSeq,
+		Gaps:           f.gaps,
+		EventsReceived: f.eventsReceived,
+		TakenOver:      make([]string, 0, len(f.takenOver)),
+		Snapshots:      make([]SymbolSnapshot, 0, len(f.snapshots)),
+	}
+	for key := range f.takenOver {
+		status.TakenOver = append(status.TakenOver, key)
+	}
+	for _, snap := range f.snapshots {
+		status.Snapshots = append(status.Snapshots, snap)
+	}
+	return status
+}
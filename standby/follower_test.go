@@ -0,0 +1,77 @@
+package standby
+
+import (
+	"testing"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/event"
+	"quantmesh/position"
+)
+
+func TestFollowerApply(t *testing.T) {
+	f := NewFollower(config.StandbyConfig{PrimaryURL: "ws://primary/replication"})
+	var events []*EventMessage
+	f.SetOnEvent(func(e *EventMessage) { events = append(events, e) })
+
+	if f.PrimaryAlive(time.Minute) {
+		t.Fatal("primary should not be alive before any message")
+	}
+
+	f.apply(&Message{Seq: 1, Type: MessageSnapshot, Instance: "primary-1", Snapshots: []SymbolSnapshot{{
+		Exchange:    "Binance",
+		Symbol:      "btcusdt",
+		AnchorPrice: 50000,
+		Slots:       []position.SlotSnapshot{{Price: 49900, PositionQty: 0.01}, {Price: 49800, PositionQty: 0.02}},
+	}}})
+	f.apply(&Message{Seq: 2, Type: MessageEvent, Instance: "primary-1", Event: &EventMessage{Type: event.EventTypeOrderFilled}})
+
+	// 交易对按 exchange:symbol 归一化匹配
+	snap, ok := f.Snapshot("binance", "BTCUSDT")
+	if !ok || snap.AnchorPrice != 50000 || snap.TotalPosition() != 0.03 {
+		t.Fatalf("mirrored snapshot = %+v, %v", snap, ok)
+	}
+	if len(events) != 1 || events[0].Type != event.EventTypeOrderFilled {
+		t.Fatalf("onEvent received %v", events)
+	}
+
+	// 序号跳过 3 记为一次缺口，之后的快照整体替换旧状态
+	f.apply(&Message{Seq: 4, Type: MessageSnapshot, Instance: "primary-1", Snapshots: []SymbolSnapshot{{
+		Exchange: "binance", Symbol: "BTCUSDT", AnchorPrice: 51000,
+	}}})
+	status := f.Status()
+	if status.Gaps != 1 || status.LastSeq != 4 || status.EventsReceived != 1 || status.PrimaryID != "primary-1" {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if snap, _ := f.Snapshot("binance", "BTCUSDT"); snap.AnchorPrice != 51000 || snap.TotalPosition() != 0 {
+		t.Fatalf("snapshot not replaced: %+v", snap)
+	}
+
+	// 主实例重启后序号从 1 重新开始，同样记为缺口
+	f.apply(&Message{Seq: 1, Type: MessageSnapshot, Instance: "primary-2"})
+	if status := f.Status(); status.Gaps != 2 || status.PrimaryID != "primary-2" {
+		t.Fatalf("restart not counted as gap: %+v", status)
+	}
+}
+
+func TestFollowerTakeoverState(t *testing.T) {
+	f := NewFollower(config.StandbyConfig{})
+	f.apply(&Message{Seq: 1, Type: MessageSnapshot})
+	if !f.PrimaryAlive(time.Minute) {
+		t.Fatal("primary should be alive right after a message")
+	}
+
+	// 复制通道静默超过接管阈值
+	f.mu.Lock()
+	f.lastMessageAt = time.Now().Add(-15 * time.Second)
+	f.mu.Unlock()
+	if f.PrimaryAlive(10 * time.Second) {
+		t.Fatal("primary should be considered dead after takeover_after of silence")
+	}
+
+	f.MarkTakenOver("Binance", "btcusdt")
+	status := f.Status()
+	if len(status.TakenOver) != 1 || status.TakenOver[0] != "binance:BTCUSDT" {
+		t.Fatalf("taken over = %v", status.TakenOver)
+	}
+}
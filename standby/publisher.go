@@ -0,0 +1,203 @@
+package standby
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"quantmesh/config"
+	"quantmesh/event"
+	"quantmesh/logger"
+)
+
+// Publisher 主实例复制通道：向已连接的备用实例推送状态快照和事件
+type Publisher struct {
+	cfg      config.StandbyConfig
+	instance string
+	provider SnapshotProvider
+	upgrader websocket.Upgrader
+
+	seq    atomic.Uint64
+	events chan *event.Event
+
+	mu      sync.Mutex
+	clients map[*publisherClient]struct{}
+}
+
+// publisherClient 单个备用实例连接
+type publisherClient struct {
+	conn *websocket.Conn
+	send chan *Message
+}
+
+// NewPublisher 创建复制通道发布者
+func NewPublisher(cfg config.StandbyConfig, instance string, provider SnapshotProvider) *Publisher {
+	return &Publisher{
+		cfg:      cfg,
+		instance: instance,
+		provider: provider,
+		upgrader: websocket.Upgrader{
+			// 复制通道不经过浏览器，使用共享密钥认证
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		events:  make(chan *event.Event, 1000),
+		clients: make(map[*publisherClient]struct{}),
+	}
+}
+
+// OnEvent 事件总线监听器（非阻塞，复制队列满时丢弃，备用实例以下一次快照为准）
+func (p *Publisher) OnEvent(e *event.Event) {
+	select {
+	case p.events <- e:
+	default:
+	}
+}
+
+// Start 启动复制通道监听和推送
+func (p *Publisher) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/replication", p.handleReplication)
+	server := &http.Server{Addr: p.cfg.Listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+	go p.run(ctx)
+
+	logger.Info("🔁 [热备] 复制通道已启动: %s/replication", p.cfg.Listen)
+	return nil
+}
+
+// run 转发事件并定期推送快照
+func (p *Publisher) run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(p.cfg.SnapshotInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			p.mu.Lock()
+			for client := range p.clients {
+				client.conn.Close()
+			}
+			p.mu.Unlock()
+			return
+		case e := <-p.events:
+			p.broadcast(&Message{
+				Type:  MessageEvent,
+				Event: &EventMessage{Type: e.Type, Timestamp: e.Timestamp, Data: e.Data},
+			})
+		case <-ticker.C:
+			p.broadcast(p.snapshotMessage())
+		}
+	}
+}
+
+// snapshotMessage 生成快照消息
+func (p *Publisher) snapshotMessage() *Message {
+	return &Message{Type: MessageSnapshot, Snapshots: p.provider.Snapshots()}
+}
+
+// broadcast 推送消息给所有备用实例，发送队列满的连接直接断开（重连后从快照恢复）
+func (p *Publisher) broadcast(msg *Message) {
+	msg.Seq = p.seq.Add(1)
+	msg.Instance = p.instance
+	msg.SentAt = time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for client := range p.clients {
+		select {
+		case client.send <- msg:
+		default:
+			logger.Warn("⚠️ [热备] 备用实例 %s 接收过慢，断开连接", client.conn.RemoteAddr())
+			delete(p.clients, client)
+			close(client.send)
+		}
+	}
+}
+
+// handleReplication 备用实例连接入口
+func (p *Publisher) handleReplication(w http.ResponseWriter, r *http.Request) {
+	token := r.Header.Get("Authorization")
+	if subtle.ConstantTimeCompare([]byte(token), []byte("Bearer "+p.cfg.Token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	conn, err := p.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Warn("⚠️ [热备] 复制通道握手失败: %v", err)
+		return
+	}
+
+	client := &publisherClient{conn: conn, send: make(chan *Message, 256)}
+	// 新连接先收到一份全量快照
+	first := p.snapshotMessage()
+	first.Seq = p.seq.Add(1)
+	first.Instance = p.instance
+	first.SentAt = time.Now()
+	client.send <- first
+
+	p.mu.Lock()
+	p.clients[client] = struct{}{}
+	p.mu.Unlock()
+	logger.Info("🔁 [热备] 备用实例已连接: %s", conn.RemoteAddr())
+
+	// 读取循环只用于感知断开
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				p.mu.Lock()
+				if _, ok := p.clients[client]; ok {
+					delete(p.clients, client)
+					close(client.send)
+				}
+				p.mu.Unlock()
+				return
+			}
+		}
+	}()
+
+	for msg := range client.send {
+		conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		if err := conn.WriteJSON(msg); err != nil {
+			break
+		}
+	}
+	conn.Close()
+	logger.Info("🔁 [热备] 备用实例已断开: %s", conn.RemoteAddr())
+}
+
+// Status 获取主实例复制通道状态
+func (p *Publisher) Status() *Status {
+	p.mu.Lock()
+	clients := len(p.clients)
+	p.mu.Unlock()
+	return &Status{
+		Role:      "primary",
+		PrimaryID: p.instance,
+		Connected: clients > 0,
+		LastSeq:   p.seq.Load(),
+		Clients:   clients,
+		TakenOver: []string{},
+		Snapshots: p.provider.Snapshots(),
+	}
+}
@@ -0,0 +1,132 @@
+package standby
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/event"
+	"quantmesh/position"
+)
+
+// staticSnapshots 返回固定快照的提供者
+type staticSnapshots struct {
+	mu    sync.Mutex
+	snaps []SymbolSnapshot
+}
+
+func (s *staticSnapshots) Snapshots() []SymbolSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SymbolSnapshot(nil), s.snaps...)
+}
+
+func (s *staticSnapshots) set(snaps []SymbolSnapshot) {
+	s.mu.Lock()
+	s.snaps = snaps
+	s.mu.Unlock()
+}
+
+// waitFor 轮询直到条件成立
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestPublisherRejectsWrongToken(t *testing.T) {
+	p := NewPublisher(config.StandbyConfig{Token: "secret", SnapshotInterval: 1}, "primary", &staticSnapshots{})
+	server := httptest.NewServer(http.HandlerFunc(p.handleReplication))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Authorization", "Bearer guess")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestPublisherReplicatesToFollower(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	provider := &staticSnapshots{}
+	provider.set([]SymbolSnapshot{{
+		Exchange:      "binance",
+		Symbol:        "BTCUSDT",
+		AnchorPrice:   50000,
+		PriceInterval: 100,
+		Slots:         []position.SlotSnapshot{{Price: 49900, PositionQty: 0.01, OrderID: 7, OrderSide: "SELL", OrderPrice: 50000}},
+	}})
+	p := NewPublisher(config.StandbyConfig{Token: "secret", SnapshotInterval: 1}, "primary-1", provider)
+	server := httptest.NewServer(http.HandlerFunc(p.handleReplication))
+	defer server.Close()
+	go p.run(ctx)
+
+	f := NewFollower(config.StandbyConfig{Token: "secret", PrimaryURL: "ws" + strings.TrimPrefix(server.URL, "http")})
+	var mu sync.Mutex
+	var received []*EventMessage
+	f.SetOnEvent(func(e *EventMessage) {
+		mu.Lock()
+		received = append(received, e)
+		mu.Unlock()
+	})
+	f.Start(ctx)
+
+	// 连接后立即收到一份全量快照，槽位挂单信息完整复制
+	waitFor(t, "initial snapshot", func() bool {
+		_, ok := f.Snapshot("binance", "BTCUSDT")
+		return ok
+	})
+	snap, _ := f.Snapshot("binance", "BTCUSDT")
+	if snap.PriceInterval != 100 || len(snap.Slots) != 1 || snap.Slots[0].OrderID != 7 || snap.Slots[0].OrderPrice != 50000 {
+		t.Fatalf("mirrored snapshot = %+v", snap)
+	}
+	waitFor(t, "publisher client registered", func() bool { return p.Status().Clients == 1 })
+
+	p.OnEvent(&event.Event{Type: event.EventTypeOrderFilled, Timestamp: time.Now(), Data: map[string]interface{}{"order_id": 7}})
+	waitFor(t, "replicated event", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 1
+	})
+
+	// 定期快照同时作为心跳，状态变化随下一次快照同步
+	provider.set([]SymbolSnapshot{{Exchange: "binance", Symbol: "BTCUSDT", AnchorPrice: 51000}})
+	waitFor(t, "periodic snapshot", func() bool {
+		snap, _ := f.Snapshot("binance", "BTCUSDT")
+		return snap.AnchorPrice == 51000
+	})
+
+	status := f.Status()
+	if !status.Connected || status.PrimaryID != "primary-1" || status.Gaps != 0 || status.EventsReceived != 1 {
+		t.Fatalf("unexpected follower status %+v", status)
+	}
+	if !f.PrimaryAlive(5 * time.Second) {
+		t.Fatal("primary should be alive while replicating")
+	}
+
+	// 主实例退出后复制通道静默，备用实例据此判断可以尝试接管
+	cancel()
+	server.CloseClientConnections()
+	waitFor(t, "follower disconnect", func() bool { return !f.Status().Connected })
+	if f.PrimaryAlive(0) {
+		t.Fatal("primary should not be alive with a zero takeover window")
+	}
+}
@@ -0,0 +1,58 @@
+package standby
+
+import (
+	"time"
+
+	"quantmesh/event"
+	"quantmesh/position"
+)
+
+// 复制通道消息类型
+const (
+	MessageSnapshot = "snapshot" // 全量槽位快照（定期推送，同时作为主实例心跳）
+	MessageEvent    = "event"    // 主实例事件（成交、撤单、风控等）
+)
+
+// SymbolSnapshot 单个交易对的运行状态快照
+// Slots 与协调重启的恢复令牌格式相同，接管时据此恢复槽位持仓并接管主实例留下的挂单
+type SymbolSnapshot struct {
+	Exchange      string                  `json:"exchange"`
+	Symbol        string                  `json:"symbol"`
+	AnchorPrice   float64                 `json:"anchor_price"`
+	PriceInterval float64                 `json:"price_interval"`
+	LastPrice     float64                 `json:"last_price"`
+	Paused        bool                    `json:"paused"`
+	Slots         []position.SlotSnapshot `json:"slots"`
+	TakenAt       time.Time               `json:"taken_at"`
+}
+
+// TotalPosition 快照中的持仓总量
+func (s SymbolSnapshot) TotalPosition() float64 {
+	total := 0.0
+	for _, slot := range s.Slots {
+		total += slot.PositionQty
+	}
+	return total
+}
+
+// EventMessage 复制的事件
+type EventMessage struct {
+	Type      event.EventType        `json:"type"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// Message 复制通道消息
+type Message struct {
+	Seq       uint64           `json:"seq"`
+	Type      string           `json:"type"`
+	Instance  string           `json:"instance"`
+	Snapshots []SymbolSnapshot `json:"snapshots,omitempty"`
+	Event     *EventMessage    `json:"event,omitempty"`
+	SentAt    time.Time        `json:"sent_at"`
+}
+
+// SnapshotProvider 主实例状态快照提供者（需要从 main.go 注入）
+type SnapshotProvider interface {
+	Snapshots() []SymbolSnapshot
+}
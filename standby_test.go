@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"quantmesh/position"
+	"quantmesh/standby"
+)
+
+func TestStandbyTakeoverStagesResume(t *testing.T) {
+	snap := standby.SymbolSnapshot{
+		Exchange:      "binance",
+		Symbol:        "BTCUSDT",
+		AnchorPrice:   50000,
+		PriceInterval: 100,
+		Slots: []position.SlotSnapshot{
+			{Price: 49900, PositionQty: 0.01, CostBasis: 499.1, OrderID: 11, OrderSide: "SELL", OrderPrice: 50000},
+			{Price: 49800, OrderID: 12, OrderSide: "BUY", OrderPrice: 49800},
+		},
+	}
+	stageResumeSymbol(standbyResumeSymbol(snap))
+
+	rs := takeResumeSymbol("binance", "BTCUSDT")
+	if rs == nil {
+		t.Fatal("takeover snapshot was not staged for launchSymbol")
+	}
+	if rs.Anchor != 50000 || rs.PriceInterval != 100 || rs.Position != 0.01 || len(rs.Slots) != 2 {
+		t.Fatalf("unexpected resume info %+v", rs)
+	}
+	if rs.Slots[0].OrderID != 11 || rs.Slots[0].CostBasis != 499.1 {
+		t.Fatalf("slot snapshot not carried over: %+v", rs.Slots[0])
+	}
+	if rs.label() != "热备接管" {
+		t.Errorf("label = %q", rs.label())
+	}
+
+	// 只使用一次，之后重新启动该交易对按正常流程初始化
+	if takeResumeSymbol("binance", "BTCUSDT") != nil {
+		t.Fatal("resume info should be consumed by the first launch")
+	}
+}
@@ -0,0 +1,1342 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"quantmesh/utils"
+)
+
+// MemoryStorage 内存存储实现（数据不落盘，进程退出即丢失）
+// 用于单元测试和 storage.type: memory 的临时试验，查询语义与 SQLiteStorage 保持一致
+type MemoryStorage struct {
+	mu sync.RWMutex
+
+	nextID          int64
+	orders          []*Order
+	positions       []*Position
+	trades          []*Trade
+	events          []*MemoryEvent
+	statistics      []*Statistics
+	systemMetrics   []*SystemMetrics
+	dailyMetrics    []*DailySystemMetrics
+	reconciliations []*ReconciliationHistory
+	riskChecks      []*RiskCheckRecord
+	fundingRates    []*FundingRate
+	aiPrompts       map[string]*AIPromptTemplate
+	aiPromptHistory []*AIPromptVersion
+	basis           []*BasisData
+	costBasis       map[[2]string]*CostBasis
+	gridAnchors     map[[2]string]*GridAnchor
+	fundingPayments []*FundingPayment
+	profitTransfers []*ProfitTransfer
+	openInterest    []*OpenInterestData
+	journal         []*JournalEntry
+	rejectedOrders  []*RejectedOrder
+	cleanupActions  []*OrderCleanupAction
+	ocoLinks        []*OCOLink
+	incomeRecords   []*IncomeRecord
+	strategyReturns []*StrategyReturn
+	klines          map[[3]string]map[int64]*Kline // 交易所+交易对+周期 -> 开盘时间（毫秒）-> K 线
+}
+
+var _ Storage = (*MemoryStorage)(nil)
+
+// MemoryEvent 内存存储中保存的通用事件
+type MemoryEvent struct {
+	ID        int64
+	EventType string
+	Data      map[string]interface{}
+	CreatedAt time.Time
+}
+
+// NewMemoryStorage 创建内存存储
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		aiPrompts:   make(map[string]*AIPromptTemplate),
+		costBasis:   make(map[[2]string]*CostBasis),
+		gridAnchors: make(map[[2]string]*GridAnchor),
+		klines:      make(map[[3]string]map[int64]*Kline),
+	}
+}
+
+// newID 生成自增 ID（调用方需持有写锁）
+func (m *MemoryStorage) newID() int64 {
+	m.nextID++
+	return m.nextID
+}
+
+// SaveOrder 保存订单（同一交易所+订单号覆盖）
+func (m *MemoryStorage) SaveOrder(order *Order) error {
+	o := *order
+	o.Exchange = exchangeOrDefault(o.Exchange)
+	o.CreatedAt = utils.ToUTC(o.CreatedAt)
+	o.UpdatedAt = utils.ToUTC(o.UpdatedAt)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, existing := range m.orders {
+		if existing.Exchange == o.Exchange && existing.OrderID == o.OrderID {
+			m.orders[i] = &o
+			return nil
+		}
+	}
+	m.orders = append(m.orders, &o)
+	return nil
+}
+
+// SavePosition 保存持仓
+func (m *MemoryStorage) SavePosition(position *Position) error {
+	p := *position
+	p.Exchange = exchangeOrDefault(p.Exchange)
+	p.OpenedAt = utils.ToUTC(p.OpenedAt)
+	if p.ClosedAt != nil {
+		closedAt := utils.ToUTC(*p.ClosedAt)
+		p.ClosedAt = &closedAt
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.positions = append(m.positions, &p)
+	return nil
+}
+
+// SaveTrade 保存交易
+func (m *MemoryStorage) SaveTrade(trade *Trade) error {
+	t := *trade
+	t.Exchange = exchangeOrDefault(t.Exchange)
+	t.CreatedAt = utils.ToUTC(t.CreatedAt)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.trades = append(m.trades, &t)
+	return nil
+}
+
+// SaveEvent 保存事件（system_metrics 事件写入系统监控数据）
+func (m *MemoryStorage) SaveEvent(eventType string, data map[string]interface{}) error {
+	if eventType == "system_metrics" {
+		return m.SaveSystemMetrics(systemMetricsFromMap(data))
+	}
+
+	copied := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		copied[k] = v
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, &MemoryEvent{ID: m.newID(), EventType: eventType, Data: copied, CreatedAt: utils.NowUTC()})
+	return nil
+}
+
+// Events 返回已保存的通用事件（eventType 为空表示全部，按保存顺序）
+func (m *MemoryStorage) Events(eventType string) []*MemoryEvent {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*MemoryEvent
+	for _, e := range m.events {
+		if eventType == "" || e.EventType == eventType {
+			copied := *e
+			result = append(result, &copied)
+		}
+	}
+	return result
+}
+
+// Positions 返回已保存的持仓记录（按保存顺序）
+func (m *MemoryStorage) Positions() []*Position {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return copyAll(m.positions)
+}
+
+// SaveStatistics 保存统计（同一交易所+交易对+日期覆盖）
+func (m *MemoryStorage) SaveStatistics(stats *Statistics) error {
+	st := *stats
+	st.Exchange = exchangeOrDefault(st.Exchange)
+	st.Date = utils.ToUTC(st.Date)
+	st.CreatedAt = utils.ToUTC(st.CreatedAt)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, existing := range m.statistics {
+		if existing.Exchange == st.Exchange && existing.Symbol == st.Symbol && existing.Date.Equal(st.Date) {
+			m.statistics[i] = &st
+			return nil
+		}
+	}
+	m.statistics = append(m.statistics, &st)
+	return nil
+}
+
+// SaveSystemMetrics 保存系统监控细粒度数据
+func (m *MemoryStorage) SaveSystemMetrics(metrics *SystemMetrics) error {
+	sm := *metrics
+	sm.Timestamp = utils.ToUTC(sm.Timestamp)
+	sm.CreatedAt = utils.NowUTC()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sm.ID = m.newID()
+	m.systemMetrics = append(m.systemMetrics, &sm)
+	return nil
+}
+
+// SaveDailySystemMetrics 保存系统监控每日汇总数据（同一日期覆盖）
+func (m *MemoryStorage) SaveDailySystemMetrics(metrics *DailySystemMetrics) error {
+	dm := *metrics
+	dm.Date = utils.ToUTC(dm.Date)
+	dm.CreatedAt = utils.NowUTC()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	dm.ID = m.newID()
+	for i, existing := range m.dailyMetrics {
+		if existing.Date.Equal(dm.Date) {
+			m.dailyMetrics[i] = &dm
+			return nil
+		}
+	}
+	m.dailyMetrics = append(m.dailyMetrics, &dm)
+	return nil
+}
+
+// QuerySystemMetrics 查询系统监控细粒度数据（按时间升序）
+func (m *MemoryStorage) QuerySystemMetrics(startTime, endTime time.Time) ([]*SystemMetrics, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.systemMetrics, func(sm *SystemMetrics) bool { return inTimeRange(sm.Timestamp, startTime, endTime) })
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Timestamp.Before(result[j].Timestamp) })
+	return result, nil
+}
+
+// QueryDailySystemMetrics 查询最近 days 天的每日汇总数据（按日期升序）
+func (m *MemoryStorage) QueryDailySystemMetrics(days int) ([]*DailySystemMetrics, error) {
+	startDate := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	startDate = time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.dailyMetrics, func(dm *DailySystemMetrics) bool { return !dm.Date.Before(startDate) })
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Date.Before(result[j].Date) })
+	return result, nil
+}
+
+// GetLatestSystemMetrics 获取最新的系统监控数据，没有数据时返回 nil
+func (m *MemoryStorage) GetLatestSystemMetrics() (*SystemMetrics, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var latest *SystemMetrics
+	for _, sm := range m.systemMetrics {
+		if latest == nil || sm.Timestamp.After(latest.Timestamp) {
+			latest = sm
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	copied := *latest
+	return &copied, nil
+}
+
+// CleanupSystemMetrics 清理过期的细粒度数据
+func (m *MemoryStorage) CleanupSystemMetrics(beforeTime time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.systemMetrics = removeWhere(m.systemMetrics, func(sm *SystemMetrics) bool { return sm.Timestamp.Before(beforeTime) })
+	return nil
+}
+
+// CleanupDailySystemMetrics 清理过期的每日汇总数据
+func (m *MemoryStorage) CleanupDailySystemMetrics(beforeDate time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dailyMetrics = removeWhere(m.dailyMetrics, func(dm *DailySystemMetrics) bool { return dm.Date.Before(beforeDate) })
+	return nil
+}
+
+// QueryOrders 查询订单（exchange/symbol 为空表示不过滤，按创建时间倒序）
+func (m *MemoryStorage) QueryOrders(exchange, symbol string, limit, offset int, status string) ([]*Order, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.orders, func(o *Order) bool {
+		return inScope(o.Exchange, o.Symbol, exchange, symbol) && (status == "" || o.Status == status)
+	})
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return paginate(result, clampLimit(limit, 100, 10000), offset), nil
+}
+
+// QueryTrades 查询交易（exchange/symbol 为空表示不过滤，按创建时间倒序）
+func (m *MemoryStorage) QueryTrades(exchange, symbol string, startTime, endTime time.Time, limit, offset int) ([]*Trade, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := m.tradesInScope(exchange, symbol, startTime, endTime)
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return paginate(result, clampLimit(limit, 100, 10000), offset), nil
+}
+
+// tradesInScope 过滤交易所+交易对+时间区间内的交易（调用方需持有读锁）
+func (m *MemoryStorage) tradesInScope(exchange, symbol string, startTime, endTime time.Time) []*Trade {
+	return filterCopy(m.trades, func(t *Trade) bool {
+		return inScope(t.Exchange, t.Symbol, exchange, symbol) && inTimeRange(t.CreatedAt, startTime, endTime)
+	})
+}
+
+// QueryStatistics 查询统计数据（exchange/symbol 为空表示不过滤，按日期倒序）
+func (m *MemoryStorage) QueryStatistics(exchange, symbol string, startDate, endDate time.Time) ([]*Statistics, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.statistics, func(st *Statistics) bool {
+		return inScope(st.Exchange, st.Symbol, exchange, symbol) && inTimeRange(st.Date, startDate, endDate)
+	})
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Date.After(result[j].Date) })
+	return paginate(result, 10000, 0), nil
+}
+
+// GetStatisticsSummary 获取统计汇总（从交易记录实时计算）
+func (m *MemoryStorage) GetStatisticsSummary() (*Statistics, error) {
+	return m.GetStatisticsSummaryByExchange("")
+}
+
+// GetStatisticsSummaryByExchange 获取指定交易所的统计汇总
+func (m *MemoryStorage) GetStatisticsSummaryByExchange(exchange string) (*Statistics, error) {
+	return m.GetStatisticsSummaryBySymbol(exchange, "")
+}
+
+// GetStatisticsSummaryBySymbol 获取指定交易所+交易对的统计汇总（exchange/symbol 为空表示不过滤）
+func (m *MemoryStorage) GetStatisticsSummaryBySymbol(exchange, symbol string) (*Statistics, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stat := &Statistics{Exchange: exchange, Symbol: symbol}
+	winning := 0
+	for _, t := range m.trades {
+		if !inScope(t.Exchange, t.Symbol, exchange, symbol) {
+			continue
+		}
+		stat.TotalTrades++
+		stat.TotalVolume += t.Quantity
+		stat.TotalPnL += t.PnL
+		if t.PnL > 0 {
+			winning++
+		}
+	}
+	if stat.TotalTrades > 0 {
+		stat.WinRate = float64(winning) / float64(stat.TotalTrades)
+	}
+	return stat, nil
+}
+
+// QueryDailyStatisticsFromTrades 从交易记录查询每日统计
+func (m *MemoryStorage) QueryDailyStatisticsFromTrades(startDate, endDate time.Time) ([]*DailyStatisticsWithTradeCount, error) {
+	return m.QueryDailyStatisticsByExchange("", startDate, endDate)
+}
+
+// QueryDailyStatisticsByExchange 从交易记录查询指定交易所的每日统计
+func (m *MemoryStorage) QueryDailyStatisticsByExchange(exchange string, startDate, endDate time.Time) ([]*DailyStatisticsWithTradeCount, error) {
+	return m.QueryDailyStatisticsBySymbol(exchange, "", startDate, endDate)
+}
+
+// QueryDailyStatisticsBySymbol 从交易记录查询指定交易所+交易对的每日统计，并合并当日资金费（按日期倒序）
+func (m *MemoryStorage) QueryDailyStatisticsBySymbol(exchange, symbol string, startDate, endDate time.Time) ([]*DailyStatisticsWithTradeCount, error) {
+	// 与 SQLite 的 date(created_at) 一致：按 UTC 日期分组，日期边界按字符串比较
+	startDateStr := startDate.Format("2006-01-02")
+	endDateStr := endDate.Format("2006-01-02")
+	inDays := func(t time.Time) (string, bool) {
+		day := t.UTC().Format("2006-01-02")
+		return day, day >= startDateStr && day <= endDateStr
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	byDate := make(map[string]*DailyStatisticsWithTradeCount)
+	dayStat := func(day string) *DailyStatisticsWithTradeCount {
+		stat, ok := byDate[day]
+		if !ok {
+			date, _ := time.Parse("2006-01-02", day)
+			stat = &DailyStatisticsWithTradeCount{Date: date}
+			byDate[day] = stat
+		}
+		return stat
+	}
+	for _, t := range m.trades {
+		day, ok := inDays(t.CreatedAt)
+		if !ok || !inScope(t.Exchange, t.Symbol, exchange, symbol) {
+			continue
+		}
+		stat := dayStat(day)
+		stat.TotalTrades++
+		stat.TotalVolume += t.Quantity
+		stat.TotalPnL += t.PnL
+		if t.PnL > 0 {
+			stat.WinningTrades++
+		} else if t.PnL < 0 {
+			stat.LosingTrades++
+		}
+	}
+	for _, p := range m.fundingPayments {
+		day, ok := inDays(p.PaymentTime)
+		if !ok || !inScope(p.Exchange, p.Symbol, exchange, symbol) {
+			continue
+		}
+		dayStat(day).FundingFee += p.Amount
+	}
+
+	stats := make([]*DailyStatisticsWithTradeCount, 0, len(byDate))
+	for _, stat := range byDate {
+		if stat.TotalTrades > 0 {
+			stat.WinRate = float64(stat.WinningTrades) / float64(stat.TotalTrades)
+		}
+		stat.NetPnL = stat.TotalPnL + stat.FundingFee
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Date.After(stats[j].Date) })
+	return paginate(stats, 3650, 0), nil
+}
+
+// SaveReconciliationHistory 保存对账历史
+func (m *MemoryStorage) SaveReconciliationHistory(history *ReconciliationHistory) error {
+	h := *history
+	h.Exchange = exchangeOrDefault(h.Exchange)
+	h.ReconcileTime = utils.ToUTC(h.ReconcileTime)
+	h.CreatedAt = utils.ToUTC(h.CreatedAt)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h.ID = m.newID()
+	m.reconciliations = append(m.reconciliations, &h)
+	return nil
+}
+
+// QueryReconciliationHistory 查询对账历史（exchange/symbol 为空表示不过滤，按对账时间倒序）
+func (m *MemoryStorage) QueryReconciliationHistory(exchange, symbol string, startTime, endTime time.Time, limit, offset int) ([]*ReconciliationHistory, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.reconciliations, func(h *ReconciliationHistory) bool {
+		return inScope(h.Exchange, h.Symbol, exchange, symbol) && inTimeRange(h.ReconcileTime, startTime, endTime)
+	})
+	sort.SliceStable(result, func(i, j int) bool { return result[i].ReconcileTime.After(result[j].ReconcileTime) })
+	return paginate(result, clampLimit(limit, 100, 10000), offset), nil
+}
+
+// GetLatestReconciliationHistory 获取指定交易所+币种的最新对账记录，没有记录时返回 nil
+func (m *MemoryStorage) GetLatestReconciliationHistory(exchange, symbol string) (*ReconciliationHistory, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var latest *ReconciliationHistory
+	for _, h := range m.reconciliations {
+		if h.Symbol == symbol && inScope(h.Exchange, "", exchange, "") &&
+			(latest == nil || h.ReconcileTime.After(latest.ReconcileTime)) {
+			latest = h
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	copied := *latest
+	return &copied, nil
+}
+
+// GetReconciliationCount 获取指定交易所+币种的对账次数
+func (m *MemoryStorage) GetReconciliationCount(exchange, symbol string) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var count int64
+	for _, h := range m.reconciliations {
+		if h.Symbol == symbol && inScope(h.Exchange, "", exchange, "") {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// GetPnLBySymbol 按币种对查询盈亏数据（exchange 为空表示汇总所有交易所）
+func (m *MemoryStorage) GetPnLBySymbol(exchange, symbol string, startTime, endTime time.Time) (*PnLSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	summary := &PnLSummary{Symbol: symbol}
+	for _, t := range m.tradesInScope(exchange, symbol, startTime, endTime) {
+		summary.TotalTrades++
+		summary.TotalPnL += t.PnL
+		summary.TotalVolume += t.Quantity
+		if t.PnL > 0 {
+			summary.WinningTrades++
+		} else if t.PnL < 0 {
+			summary.LosingTrades++
+		}
+	}
+	if summary.TotalTrades > 0 {
+		summary.WinRate = float64(summary.WinningTrades) / float64(summary.TotalTrades)
+	}
+	for _, p := range m.fundingPayments {
+		if inScope(p.Exchange, p.Symbol, exchange, symbol) && inTimeRange(p.PaymentTime, startTime, endTime) {
+			summary.FundingFee += p.Amount
+		}
+	}
+	summary.NetPnL = summary.TotalPnL + summary.FundingFee
+	return summary, nil
+}
+
+// GetPnLByTimeRange 按时间区间查询盈亏数据（按交易所+币种对分组，按盈亏倒序）
+func (m *MemoryStorage) GetPnLByTimeRange(startTime, endTime time.Time) ([]*PnLBySymbol, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	byKey := make(map[[2]string]*PnLBySymbol)
+	winning := make(map[[2]string]int)
+	var results []*PnLBySymbol
+	for _, t := range m.tradesInScope("", "", startTime, endTime) {
+		key := [2]string{t.Exchange, t.Symbol}
+		r, ok := byKey[key]
+		if !ok {
+			r = &PnLBySymbol{Exchange: t.Exchange, Symbol: t.Symbol}
+			byKey[key] = r
+			results = append(results, r)
+		}
+		r.TotalTrades++
+		r.TotalPnL += t.PnL
+		r.TotalVolume += t.Quantity
+		if t.PnL > 0 {
+			winning[key]++
+		}
+	}
+	for key, r := range byKey {
+		r.WinRate = float64(winning[key]) / float64(r.TotalTrades)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].TotalPnL > results[j].TotalPnL })
+	results = paginate(results, 1000, 0)
+
+	// 资金费计入净盈亏；只有资金费没有成交的交易对也需要列出
+	fees := make(map[[2]string]float64)
+	var feeKeys [][2]string
+	for _, p := range m.fundingPayments {
+		if !inTimeRange(p.PaymentTime, startTime, endTime) {
+			continue
+		}
+		key := [2]string{p.Exchange, p.Symbol}
+		if _, ok := fees[key]; !ok {
+			feeKeys = append(feeKeys, key)
+		}
+		fees[key] += p.Amount
+	}
+	for _, r := range results {
+		key := [2]string{r.Exchange, r.Symbol}
+		r.FundingFee = fees[key]
+		r.NetPnL = r.TotalPnL + r.FundingFee
+		delete(fees, key)
+	}
+	for _, key := range feeKeys {
+		if fee, ok := fees[key]; ok {
+			results = append(results, &PnLBySymbol{Exchange: key[0], Symbol: key[1], FundingFee: fee, NetPnL: fee})
+		}
+	}
+	return results, nil
+}
+
+// GetActualProfitBySymbol 计算指定交易所+币种在指定时间之前的累计实际盈利
+func (m *MemoryStorage) GetActualProfitBySymbol(exchange, symbol string, beforeTime time.Time) (float64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	total := 0.0
+	for _, t := range m.trades {
+		if t.Symbol == symbol && inScope(t.Exchange, "", exchange, "") && !t.CreatedAt.After(beforeTime) {
+			total += t.PnL
+		}
+	}
+	return total, nil
+}
+
+// SaveRiskCheck 保存风控检查记录
+func (m *MemoryStorage) SaveRiskCheck(record *RiskCheckRecord) error {
+	r := *record
+	r.CheckTime = utils.ToUTC(r.CheckTime)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.riskChecks = append(m.riskChecks, &r)
+	return nil
+}
+
+// QueryRiskCheckHistory 查询风控检查历史（按时间范围决定聚合粒度，与 SQLite 实现一致）
+func (m *MemoryStorage) QueryRiskCheckHistory(startTime, endTime time.Time, limit int) ([]*RiskCheckHistory, error) {
+	if limit <= 0 {
+		limit = 200
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	timeRange := endTime.Sub(startTime)
+	truncateDuration := time.Minute
+	if timeRange > 30*24*time.Hour {
+		truncateDuration = time.Hour
+	} else if timeRange > 7*24*time.Hour {
+		truncateDuration = 30 * time.Minute
+	} else if timeRange > 24*time.Hour {
+		truncateDuration = 10 * time.Minute
+	}
+
+	m.mu.RLock()
+	records := filterCopy(m.riskChecks, func(r *RiskCheckRecord) bool { return inTimeRange(r.CheckTime, startTime, endTime) })
+	m.mu.RUnlock()
+	sort.SliceStable(records, func(i, j int) bool { return records[i].CheckTime.After(records[j].CheckTime) })
+	records = paginate(records, limit*4, 0)
+
+	historyMap := make(map[time.Time]*RiskCheckHistory)
+	for _, r := range records {
+		checkTime := r.CheckTime.Truncate(truncateDuration)
+		history, ok := historyMap[checkTime]
+		if !ok {
+			history = &RiskCheckHistory{CheckTime: checkTime, Symbols: []*RiskCheckSymbol{}}
+			historyMap[checkTime] = history
+		}
+		history.Symbols = append(history.Symbols, &RiskCheckSymbol{
+			Symbol:         r.Symbol,
+			IsHealthy:      r.IsHealthy,
+			PriceDeviation: r.PriceDeviation,
+			VolumeRatio:    r.VolumeRatio,
+			Reason:         r.Reason,
+		})
+		if r.IsHealthy {
+			history.HealthyCount++
+		}
+		history.TotalCount++
+	}
+
+	result := make([]*RiskCheckHistory, 0, len(historyMap))
+	for _, history := range historyMap {
+		result = append(result, history)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CheckTime.Before(result[j].CheckTime) })
+	if len(result) > limit {
+		result = result[len(result)-limit:]
+	}
+	return result, nil
+}
+
+// CleanupRiskCheckHistory 清理指定时间之前的风控检查历史
+func (m *MemoryStorage) CleanupRiskCheckHistory(beforeTime time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.riskChecks = removeWhere(m.riskChecks, func(r *RiskCheckRecord) bool { return r.CheckTime.Before(beforeTime) })
+	return nil
+}
+
+// SaveFundingRate 保存资金费率（仅在变动时存储）
+func (m *MemoryStorage) SaveFundingRate(symbol, exchange string, rate float64, timestamp time.Time) error {
+	if latestRate, err := m.GetLatestFundingRate(symbol, exchange); err == nil {
+		const epsilon = 0.0000001
+		if abs(latestRate-rate) < epsilon {
+			return nil
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fundingRates = append(m.fundingRates, &FundingRate{
+		ID:        m.newID(),
+		Symbol:    symbol,
+		Exchange:  exchange,
+		Rate:      rate,
+		Timestamp: timestamp,
+		CreatedAt: time.Now(),
+	})
+	return nil
+}
+
+// GetLatestFundingRate 获取最新的资金费率
+func (m *MemoryStorage) GetLatestFundingRate(symbol, exchange string) (float64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var latest *FundingRate
+	for _, fr := range m.fundingRates {
+		if fr.Symbol == symbol && fr.Exchange == exchange && (latest == nil || fr.Timestamp.After(latest.Timestamp)) {
+			latest = fr
+		}
+	}
+	if latest == nil {
+		return 0, fmt.Errorf("未找到资金费率记录")
+	}
+	return latest.Rate, nil
+}
+
+// GetFundingRateHistory 获取资金费率历史（symbol/exchange 为空表示不过滤，按时间倒序）
+func (m *MemoryStorage) GetFundingRateHistory(symbol, exchange string, limit int) ([]*FundingRate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.fundingRates, func(fr *FundingRate) bool { return inScope(fr.Exchange, fr.Symbol, exchange, symbol) })
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Timestamp.After(result[j].Timestamp) })
+	return paginate(result, clampLimit(limit, 100, 10000), 0), nil
+}
+
+// GetAIPromptTemplate 获取AI提示词模板，不存在时返回 nil
+func (m *MemoryStorage) GetAIPromptTemplate(module string) (*AIPromptTemplate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	template, ok := m.aiPrompts[module]
+	if !ok {
+		return nil, nil
+	}
+	copied := *template
+	return &copied, nil
+}
+
+// SetAIPromptTemplate 设置AI提示词模板（同一模块覆盖）
+func (m *MemoryStorage) SetAIPromptTemplate(template *AIPromptTemplate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := *template
+	if existing, ok := m.aiPrompts[t.Module]; ok {
+		t.ID = existing.ID
+		t.Version, t.PinnedVersion = existing.Version, existing.PinnedVersion
+	} else {
+		t.ID = m.newID()
+		t.Version, t.PinnedVersion = 0, 0
+	}
+	t.UpdatedAt = time.Now()
+	m.aiPrompts[t.Module] = &t
+	return nil
+}
+
+// GetAllAIPromptTemplates 获取所有AI提示词模板（按模块名排序）
+func (m *MemoryStorage) GetAllAIPromptTemplates() ([]*AIPromptTemplate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var templates []*AIPromptTemplate
+	for _, t := range m.aiPrompts {
+		copied := *t
+		templates = append(templates, &copied)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Module < templates[j].Module })
+	return templates, nil
+}
+
+// SaveAIPromptVersion 追加提示词版本（版本号自动递增）并设为模块的当前模板
+func (m *MemoryStorage) SaveAIPromptVersion(version *AIPromptVersion) error {
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = utils.NowUTC()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var latest int
+	for _, v := range m.aiPromptHistory {
+		if v.Module == version.Module && v.Version > latest {
+			latest = v.Version
+		}
+	}
+	version.Version = latest + 1
+	version.ID = m.newID()
+	v := *version
+	m.aiPromptHistory = append(m.aiPromptHistory, &v)
+
+	current, ok := m.aiPrompts[v.Module]
+	if !ok {
+		current = &AIPromptTemplate{ID: m.newID(), Module: v.Module}
+		m.aiPrompts[v.Module] = current
+	}
+	current.Template, current.SystemPrompt, current.Version, current.UpdatedAt = v.Template, v.SystemPrompt, v.Version, v.CreatedAt
+	return nil
+}
+
+// GetAIPromptVersion 获取提示词的指定版本，不存在时返回 nil
+func (m *MemoryStorage) GetAIPromptVersion(module string, version int) (*AIPromptVersion, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, v := range m.aiPromptHistory {
+		if v.Module == module && v.Version == version {
+			copied := *v
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+// QueryAIPromptVersions 查询提示词版本历史（按版本号倒序）
+func (m *MemoryStorage) QueryAIPromptVersions(module string, limit int) ([]*AIPromptVersion, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.aiPromptHistory, func(v *AIPromptVersion) bool { return v.Module == module })
+	sort.Slice(result, func(i, j int) bool { return result[i].Version > result[j].Version })
+	return paginate(result, clampLimit(limit, 100, 10000), 0), nil
+}
+
+// SetAIPromptPin 固定模块使用的提示词版本，version 为 0 时取消固定
+func (m *MemoryStorage) SetAIPromptPin(module string, version int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.aiPrompts[module]
+	if !ok {
+		return fmt.Errorf("提示词模块不存在: %s", module)
+	}
+	current.PinnedVersion = version
+	return nil
+}
+
+// SaveBasisData 保存价差数据
+func (m *MemoryStorage) SaveBasisData(data *BasisData) error {
+	d := *data
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.basis = append(m.basis, &d)
+	return nil
+}
+
+// GetLatestBasis 获取最新价差数据，不存在时返回 nil
+func (m *MemoryStorage) GetLatestBasis(symbol, exchange string) (*BasisData, error) {
+	history, err := m.GetBasisHistory(symbol, exchange, 1)
+	if err != nil || len(history) == 0 {
+		return nil, err
+	}
+	return history[0], nil
+}
+
+// GetBasisHistory 获取价差历史数据（按时间倒序）
+func (m *MemoryStorage) GetBasisHistory(symbol, exchange string, limit int) ([]*BasisData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.basis, func(d *BasisData) bool { return d.Symbol == symbol && d.Exchange == exchange })
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Timestamp.After(result[j].Timestamp) })
+	return paginate(result, clampLimit(limit, 100, 10000), 0), nil
+}
+
+// GetBasisStatistics 获取最近 hours 小时的价差统计数据
+func (m *MemoryStorage) GetBasisStatistics(symbol, exchange string, hours int) (*BasisStats, error) {
+	if hours <= 0 {
+		hours = 24
+	}
+	cutoffTime := time.Now().UTC().Add(-time.Duration(hours) * time.Hour)
+
+	m.mu.RLock()
+	var values []float64
+	for _, d := range m.basis {
+		if d.Symbol == symbol && d.Exchange == exchange && !d.Timestamp.Before(cutoffTime) {
+			values = append(values, d.BasisPercent)
+		}
+	}
+	m.mu.RUnlock()
+	return basisStatsFromValues(symbol, exchange, hours, values)
+}
+
+// SaveCostBasis 保存持仓成本（同一交易所+交易对覆盖更新）
+func (m *MemoryStorage) SaveCostBasis(costBasis *CostBasis) error {
+	cb := *costBasis
+	cb.UpdatedAt = utils.NowUTC()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.costBasis[[2]string{cb.Exchange, cb.Symbol}] = &cb
+	return nil
+}
+
+// GetCostBasis 获取持仓成本，不存在时返回 nil
+func (m *MemoryStorage) GetCostBasis(exchange, symbol string) (*CostBasis, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cb, ok := m.costBasis[[2]string{exchange, symbol}]
+	if !ok {
+		return nil, nil
+	}
+	copied := *cb
+	return &copied, nil
+}
+
+// SaveGridAnchor 保存网格锚点（同一交易所+交易对覆盖）
+func (m *MemoryStorage) SaveGridAnchor(anchor *GridAnchor) error {
+	a := *anchor
+	a.UpdatedAt = utils.NowUTC()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gridAnchors[[2]string{a.Exchange, a.Symbol}] = &a
+	return nil
+}
+
+// GetGridAnchor 获取网格锚点，不存在时返回 nil
+func (m *MemoryStorage) GetGridAnchor(exchange, symbol string) (*GridAnchor, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	a, ok := m.gridAnchors[[2]string{exchange, symbol}]
+	if !ok {
+		return nil, nil
+	}
+	copied := *a
+	return &copied, nil
+}
+
+// SaveFundingPayments 批量保存资金费流水，同一交易所已存在的流水号会被忽略，返回新增条数
+func (m *MemoryStorage) SaveFundingPayments(payments []*FundingPayment) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inserted := 0
+	now := utils.NowUTC()
+	for _, p := range payments {
+		duplicate := false
+		for _, existing := range m.fundingPayments {
+			if existing.Exchange == p.Exchange && existing.TranID == p.TranID {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		fp := *p
+		fp.ID = m.newID()
+		fp.PaymentTime = utils.ToUTC(fp.PaymentTime)
+		fp.CreatedAt = now
+		m.fundingPayments = append(m.fundingPayments, &fp)
+		inserted++
+	}
+	return inserted, nil
+}
+
+// GetLatestFundingPaymentTime 获取最近一笔资金费流水的时间，没有记录时返回零值
+func (m *MemoryStorage) GetLatestFundingPaymentTime(exchange, symbol string) (time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var latest time.Time
+	for _, p := range m.fundingPayments {
+		if p.Exchange == exchange && p.Symbol == symbol && p.PaymentTime.After(latest) {
+			latest = p.PaymentTime
+		}
+	}
+	return latest, nil
+}
+
+// QueryFundingPayments 查询资金费流水（exchange/symbol 为空表示不过滤，按时间倒序）
+func (m *MemoryStorage) QueryFundingPayments(exchange, symbol string, startTime, endTime time.Time, limit int) ([]*FundingPayment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.fundingPayments, func(p *FundingPayment) bool {
+		return inScope(p.Exchange, p.Symbol, exchange, symbol) && inTimeRange(p.PaymentTime, startTime, endTime)
+	})
+	sort.SliceStable(result, func(i, j int) bool { return result[i].PaymentTime.After(result[j].PaymentTime) })
+	return paginate(result, clampLimit(limit, 100, 10000), 0), nil
+}
+
+// SaveProfitTransfer 保存利润金库划转记录（保存后回填 ID）
+func (m *MemoryStorage) SaveProfitTransfer(transfer *ProfitTransfer) error {
+	if transfer.CreatedAt.IsZero() {
+		transfer.CreatedAt = utils.NowUTC()
+	}
+	t := *transfer
+	t.CreatedAt = utils.ToUTC(t.CreatedAt)
+	t.PeriodStart = utils.ToUTC(t.PeriodStart)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t.ID = m.newID()
+	transfer.ID = t.ID
+	m.profitTransfers = append(m.profitTransfers, &t)
+	return nil
+}
+
+// QueryProfitTransfers 查询利润金库划转记录（exchange 为空表示不过滤，按时间倒序）
+func (m *MemoryStorage) QueryProfitTransfers(exchange string, startTime, endTime time.Time, limit int) ([]*ProfitTransfer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.profitTransfers, func(t *ProfitTransfer) bool {
+		return inScope(t.Exchange, "", exchange, "") && inTimeRange(t.CreatedAt, startTime, endTime)
+	})
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return paginate(result, clampLimit(limit, 100, 10000), 0), nil
+}
+
+// GetProfitTransferTotal 汇总时间区间内成功划转的金额（exchange 为空表示全部交易所）
+func (m *MemoryStorage) GetProfitTransferTotal(exchange string, startTime, endTime time.Time) (float64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	total := 0.0
+	for _, t := range m.profitTransfers {
+		if t.Status == "success" && inScope(t.Exchange, "", exchange, "") && inTimeRange(t.CreatedAt, startTime, endTime) {
+			total += t.Amount
+		}
+	}
+	return total, nil
+}
+
+// SaveOpenInterest 保存持仓量快照
+func (m *MemoryStorage) SaveOpenInterest(data *OpenInterestData) error {
+	d := *data
+	if d.Timestamp.IsZero() {
+		d.Timestamp = utils.NowUTC()
+	}
+	d.Timestamp = utils.ToUTC(d.Timestamp)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.openInterest = append(m.openInterest, &d)
+	return nil
+}
+
+// GetOpenInterestHistory 查询时间区间内的持仓量快照（按时间倒序）
+func (m *MemoryStorage) GetOpenInterestHistory(symbol, exchange string, startTime, endTime time.Time, limit int) ([]*OpenInterestData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.openInterest, func(d *OpenInterestData) bool {
+		return d.Symbol == symbol && d.Exchange == exchange && inTimeRange(d.Timestamp, startTime, endTime)
+	})
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Timestamp.After(result[j].Timestamp) })
+	return paginate(result, clampLimit(limit, 100, 10000), 0), nil
+}
+
+// SaveJournalEntry 保存交易日志条目（保存后回填 ID）
+func (m *MemoryStorage) SaveJournalEntry(entry *JournalEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = utils.NowUTC()
+	}
+	e := *entry
+	e.CreatedAt = utils.ToUTC(e.CreatedAt)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e.ID = m.newID()
+	entry.ID = e.ID
+	m.journal = append(m.journal, &e)
+	return nil
+}
+
+// QueryJournalEntries 查询交易日志（按时间倒序）
+func (m *MemoryStorage) QueryJournalEntries(q JournalQuery) ([]*JournalEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.journal, func(e *JournalEntry) bool {
+		return matchFields([][2]string{{e.Exchange, q.Exchange}, {e.Symbol, q.Symbol}, {e.Strategy, q.Strategy}, {e.Source, q.Source}}) &&
+			inOptionalRange(e.CreatedAt, q.StartTime, q.EndTime)
+	})
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].ID > result[j].ID
+	})
+	return paginate(result, clampLimit(q.Limit, 100, 10000), 0), nil
+}
+
+// SaveRejectedOrder 保存拒单记录（保存后回填 ID）
+func (m *MemoryStorage) SaveRejectedOrder(order *RejectedOrder) error {
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = utils.NowUTC()
+	}
+	o := *order
+	o.CreatedAt = utils.ToUTC(o.CreatedAt)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o.ID = m.newID()
+	order.ID = o.ID
+	m.rejectedOrders = append(m.rejectedOrders, &o)
+	return nil
+}
+
+// QueryRejectedOrders 查询拒单记录（按时间倒序）
+func (m *MemoryStorage) QueryRejectedOrders(q RejectedOrderQuery) ([]*RejectedOrder, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.rejectedOrders, func(o *RejectedOrder) bool {
+		return matchFields([][2]string{{o.Exchange, q.Exchange}, {o.Symbol, q.Symbol}, {o.Side, q.Side}, {o.Category, q.Category}}) &&
+			inOptionalRange(o.CreatedAt, q.StartTime, q.EndTime)
+	})
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].ID > result[j].ID
+	})
+	return paginate(result, clampLimit(q.Limit, 100, 10000), 0), nil
+}
+
+// SaveOrderCleanupActions 批量保存订单清理动作（保存后回填 ID）
+func (m *MemoryStorage) SaveOrderCleanupActions(actions []*OrderCleanupAction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, action := range actions {
+		if action.CreatedAt.IsZero() {
+			action.CreatedAt = utils.NowUTC()
+		}
+		a := *action
+		a.CreatedAt = utils.ToUTC(a.CreatedAt)
+		a.ID = m.newID()
+		action.ID = a.ID
+		m.cleanupActions = append(m.cleanupActions, &a)
+	}
+	return nil
+}
+
+// QueryOrderCleanupActions 查询订单清理记录（按时间倒序）
+func (m *MemoryStorage) QueryOrderCleanupActions(q OrderCleanupQuery) ([]*OrderCleanupAction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.cleanupActions, func(a *OrderCleanupAction) bool {
+		return matchFields([][2]string{{a.Exchange, q.Exchange}, {a.Symbol, q.Symbol}, {a.Reason, q.Reason}}) &&
+			(q.OrderID == 0 || a.OrderID == q.OrderID) &&
+			inOptionalRange(a.CreatedAt, q.StartTime, q.EndTime)
+	})
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].ID > result[j].ID
+	})
+	return paginate(result, clampLimit(q.Limit, 100, 10000), 0), nil
+}
+
+// SaveOCOLink 保存 OCO 关联订单（同一 link_id 只更新可变字段）
+func (m *MemoryStorage) SaveOCOLink(link *OCOLink) error {
+	now := utils.NowUTC()
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = now
+	}
+	link.UpdatedAt = now
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.ocoLinks {
+		if existing.LinkID == link.LinkID {
+			existing.Quantity = link.Quantity
+			existing.TakeProfitOrderID = link.TakeProfitOrderID
+			existing.TakeProfitClientID = link.TakeProfitClientID
+			existing.StopOrderID = link.StopOrderID
+			existing.Status = link.Status
+			existing.UpdatedAt = now
+			return nil
+		}
+	}
+	l := *link
+	l.CreatedAt = utils.ToUTC(l.CreatedAt)
+	m.ocoLinks = append(m.ocoLinks, &l)
+	return nil
+}
+
+// QueryActiveOCOLinks 查询交易对下仍处于 active 状态的 OCO 关联订单（按创建时间升序）
+func (m *MemoryStorage) QueryActiveOCOLinks(exchange, symbol string) ([]*OCOLink, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.ocoLinks, func(l *OCOLink) bool {
+		return l.Exchange == exchange && l.Symbol == symbol && l.Status == "active"
+	})
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+// SaveIncomeRecords 批量保存账户收支流水，已存在的流水号会被忽略，返回新增条数
+func (m *MemoryStorage) SaveIncomeRecords(records []*IncomeRecord) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inserted := 0
+	now := utils.NowUTC()
+	for _, r := range records {
+		duplicate := false
+		for _, existing := range m.incomeRecords {
+			if existing.Exchange == r.Exchange && existing.IncomeType == r.IncomeType && existing.TranID == r.TranID {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		ir := *r
+		ir.ID = m.newID()
+		ir.IncomeTime = utils.ToUTC(ir.IncomeTime)
+		ir.CreatedAt = now
+		m.incomeRecords = append(m.incomeRecords, &ir)
+		inserted++
+	}
+	return inserted, nil
+}
+
+// GetLatestIncomeTime 获取指定类型最近一笔账户流水的时间，没有记录时返回零值
+func (m *MemoryStorage) GetLatestIncomeTime(exchange, symbol, incomeType string) (time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var latest time.Time
+	for _, r := range m.incomeRecords {
+		if r.Exchange == exchange && r.Symbol == symbol && r.IncomeType == incomeType && r.IncomeTime.After(latest) {
+			latest = r.IncomeTime
+		}
+	}
+	return latest, nil
+}
+
+// SumIncomeByType 按流水类型汇总 [startTime, endTime] 内的账户流水金额
+func (m *MemoryStorage) SumIncomeByType(exchange, symbol string, startTime, endTime time.Time) (map[string]float64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make(map[string]float64)
+	for _, r := range m.incomeRecords {
+		if r.Exchange == exchange && r.Symbol == symbol && inTimeRange(r.IncomeTime, startTime, endTime) {
+			result[r.IncomeType] += r.Amount
+		}
+	}
+	return result, nil
+}
+
+// SumTradePnL 汇总 [startTime, endTime] 内本地记录的交易盈亏（未扣手续费）
+func (m *MemoryStorage) SumTradePnL(exchange, symbol string, startTime, endTime time.Time) (float64, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	pnl, count := 0.0, 0
+	for _, t := range m.trades {
+		if t.Exchange == exchange && t.Symbol == symbol && inTimeRange(t.CreatedAt, startTime, endTime) {
+			pnl += t.PnL
+			count++
+		}
+	}
+	return pnl, count, nil
+}
+
+// SaveStrategyReturn 保存策略每日收益（同一日期、交易所、交易对、策略覆盖）
+func (m *MemoryStorage) SaveStrategyReturn(r *StrategyReturn) error {
+	r.UpdatedAt = utils.NowUTC()
+	sr := *r
+	sr.Date = utils.ToUTC(sr.Date)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, existing := range m.strategyReturns {
+		if existing.Date.Equal(sr.Date) && existing.Exchange == sr.Exchange && existing.Symbol == sr.Symbol && existing.Strategy == sr.Strategy {
+			sr.ID = existing.ID
+			m.strategyReturns[i] = &sr
+			return nil
+		}
+	}
+	sr.ID = m.newID()
+	m.strategyReturns = append(m.strategyReturns, &sr)
+	return nil
+}
+
+// QueryStrategyReturns 查询 [startDate, endDate] 内的策略每日收益（按日期升序）
+func (m *MemoryStorage) QueryStrategyReturns(startDate, endDate time.Time) ([]*StrategyReturn, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := filterCopy(m.strategyReturns, func(r *StrategyReturn) bool { return inTimeRange(r.Date, startDate, endDate) })
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Date.Before(result[j].Date) })
+	return result, nil
+}
+
+// SaveKlines 批量保存 K 线（同一交易所、交易对、周期、开盘时间覆盖），返回写入的条数
+func (m *MemoryStorage) SaveKlines(klines []*Kline) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := utils.NowUTC()
+	for _, kline := range klines {
+		k := *kline
+		k.OpenTime = utils.ToUTC(k.OpenTime)
+		k.UpdatedAt = now
+		key := [3]string{k.Exchange, k.Symbol, k.Interval}
+		series := m.klines[key]
+		if series == nil {
+			series = make(map[int64]*Kline)
+			m.klines[key] = series
+		}
+		if existing, ok := series[k.OpenTime.UnixMilli()]; ok {
+			k.ID = existing.ID
+		} else {
+			k.ID = m.newID()
+		}
+		series[k.OpenTime.UnixMilli()] = &k
+	}
+	return len(klines), nil
+}
+
+// QueryKlines 查询 [startTime, endTime] 内的 K 线（按开盘时间升序），limit > 0 时只返回最近的 limit 根
+func (m *MemoryStorage) QueryKlines(exchange, symbol, interval string, startTime, endTime time.Time, limit int) ([]*Kline, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*Kline
+	for _, k := range m.klines[[3]string{exchange, symbol, interval}] {
+		if inTimeRange(k.OpenTime, startTime, endTime) {
+			copied := *k
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].OpenTime.Before(result[j].OpenTime) })
+	if limit > 0 && len(result) > limit {
+		result = result[len(result)-limit:]
+	}
+	return result, nil
+}
+
+// Close 内存存储无需释放资源
+func (m *MemoryStorage) Close() error {
+	return nil
+}
+
+// inScope 判断记录是否属于交易所+交易对范围（为空的条件不过滤）
+func inScope(recordExchange, recordSymbol, exchange, symbol string) bool {
+	return (exchange == "" || recordExchange == exchange) && (symbol == "" || recordSymbol == symbol)
+}
+
+// matchFields 逐个比较 {记录值, 条件值}，条件值为空表示不过滤
+func matchFields(fields [][2]string) bool {
+	for _, f := range fields {
+		if f[1] != "" && f[0] != f[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// inTimeRange 判断时间是否在闭区间 [start, end] 内
+func inTimeRange(t, start, end time.Time) bool {
+	return !t.Before(start) && !t.After(end)
+}
+
+// inOptionalRange 判断时间是否在区间内，零值边界表示不限制
+func inOptionalRange(t, start, end time.Time) bool {
+	return (start.IsZero() || !t.Before(start)) && (end.IsZero() || !t.After(end))
+}
+
+// clampLimit 规范查询条数：<= 0 时使用默认值，超过上限时截断
+func clampLimit(limit, defaultLimit, maxLimit int) int {
+	if limit <= 0 {
+		return defaultLimit
+	}
+	if limit > maxLimit {
+		return maxLimit
+	}
+	return limit
+}
+
+// filterCopy 返回满足条件的记录副本，避免调用方修改内部数据
+func filterCopy[T any](items []*T, keep func(*T) bool) []*T {
+	var result []*T
+	for _, item := range items {
+		if keep(item) {
+			copied := *item
+			result = append(result, &copied)
+		}
+	}
+	return result
+}
+
+// copyAll 返回所有记录的副本
+func copyAll[T any](items []*T) []*T {
+	return filterCopy(items, func(*T) bool { return true })
+}
+
+// removeWhere 原地删除满足条件的记录
+func removeWhere[T any](items []*T, remove func(*T) bool) []*T {
+	kept := items[:0]
+	for _, item := range items {
+		if !remove(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// paginate 按 offset/limit 截取
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
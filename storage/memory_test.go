@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// TestMemoryStorageMatchesSQLite 同一组数据写入内存存储和 SQLite，查询结果应一致
+func TestMemoryStorageMatchesSQLite(t *testing.T) {
+	sqlite, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "parity.db"))
+	if err != nil {
+		t.Fatalf("创建 SQLite 存储失败: %v", err)
+	}
+	defer sqlite.Close()
+	memory := NewMemoryStorage()
+
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	for name, st := range map[string]Storage{"sqlite": sqlite, "memory": memory} {
+		for i, o := range []*Order{
+			{OrderID: 1, Exchange: "binance", Symbol: "BTCUSDT", Side: "BUY", Status: "FILLED", CreatedAt: base},
+			{OrderID: 2, Exchange: "binance", Symbol: "BTCUSDT", Side: "SELL", Status: "NEW", CreatedAt: base.Add(time.Hour)},
+			{OrderID: 1, Symbol: "BTCUSDT", Side: "BUY", Status: "CANCELED", CreatedAt: base}, // 覆盖 binance 的 1 号订单
+			{OrderID: 1, Exchange: "bybit", Symbol: "ETHUSDT", Side: "BUY", Status: "FILLED", CreatedAt: base.Add(2 * time.Hour)},
+		} {
+			if err := st.SaveOrder(o); err != nil {
+				t.Fatalf("%s: 保存订单 %d 失败: %v", name, i, err)
+			}
+		}
+		for _, tr := range []*Trade{
+			{Exchange: "binance", Symbol: "BTCUSDT", Quantity: 1, PnL: 10, CreatedAt: base},
+			{Exchange: "binance", Symbol: "BTCUSDT", Quantity: 2, PnL: -4, CreatedAt: base.Add(24 * time.Hour)},
+			{Exchange: "bybit", Symbol: "ETHUSDT", Quantity: 3, PnL: 6, CreatedAt: base.Add(time.Hour)},
+		} {
+			if err := st.SaveTrade(tr); err != nil {
+				t.Fatalf("%s: 保存交易失败: %v", name, err)
+			}
+		}
+		payments := []*FundingPayment{
+			{Exchange: "binance", Symbol: "BTCUSDT", Amount: -1, TranID: "f1", PaymentTime: base.Add(2 * time.Hour)},
+			{Exchange: "binance", Symbol: "BTCUSDT", Amount: -1, TranID: "f1", PaymentTime: base.Add(2 * time.Hour)},
+			{Exchange: "okx", Symbol: "SOLUSDT", Amount: 0.5, TranID: "f2", PaymentTime: base.Add(48 * time.Hour)},
+		}
+		if n, err := st.SaveFundingPayments(payments); err != nil || n != 2 {
+			t.Fatalf("%s: 资金费去重后应新增 2 条, got %d, err=%v", name, n, err)
+		}
+		for _, link := range []*OCOLink{
+			{LinkID: "a", Exchange: "binance", Symbol: "BTCUSDT", Status: "active", CreatedAt: base},
+			{LinkID: "b", Exchange: "binance", Symbol: "BTCUSDT", Status: "active", CreatedAt: base.Add(time.Minute)},
+			{LinkID: "a", Exchange: "binance", Symbol: "BTCUSDT", Status: "take_profit"},
+		} {
+			if err := st.SaveOCOLink(link); err != nil {
+				t.Fatalf("%s: 保存 OCO 失败: %v", name, err)
+			}
+		}
+	}
+
+	start, end := base.Add(-time.Hour), base.Add(72*time.Hour)
+	queries := map[string]func(st Storage) (interface{}, error){
+		"orders": func(st Storage) (interface{}, error) {
+			orders, err := st.QueryOrders("binance", "", 10, 0, "")
+			var got []string
+			for _, o := range orders {
+				got = append(got, o.Exchange+"/"+o.Status)
+			}
+			return got, err
+		},
+		"summary": func(st Storage) (interface{}, error) { return st.GetStatisticsSummaryBySymbol("binance", "BTCUSDT") },
+		"daily": func(st Storage) (interface{}, error) {
+			return st.QueryDailyStatisticsBySymbol("binance", "BTCUSDT", start, end)
+		},
+		"pnl": func(st Storage) (interface{}, error) { return st.GetPnLBySymbol("", "BTCUSDT", start, end) },
+		"pnl_by_range": func(st Storage) (interface{}, error) {
+			results, err := st.GetPnLByTimeRange(start, end)
+			sort.Slice(results, func(i, j int) bool { return results[i].Symbol < results[j].Symbol })
+			return results, err
+		},
+		"actual_profit": func(st Storage) (interface{}, error) {
+			return st.GetActualProfitBySymbol("binance", "BTCUSDT", base.Add(time.Hour))
+		},
+		"oco": func(st Storage) (interface{}, error) {
+			links, err := st.QueryActiveOCOLinks("binance", "BTCUSDT")
+			var got []string
+			for _, l := range links {
+				got = append(got, l.LinkID)
+			}
+			return got, err
+		},
+	}
+	for name, query := range queries {
+		want, err := query(sqlite)
+		if err != nil {
+			t.Fatalf("%s: SQLite 查询失败: %v", name, err)
+		}
+		got, err := query(memory)
+		if err != nil {
+			t.Fatalf("%s: 内存查询失败: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: 内存存储结果 %+v 与 SQLite %+v 不一致", name, got, want)
+		}
+	}
+}
+
+func TestMemoryStorageReturnsCopies(t *testing.T) {
+	st := NewMemoryStorage()
+	if err := st.SaveCostBasis(&CostBasis{Exchange: "binance", Symbol: "BTCUSDT", Quantity: 1}); err != nil {
+		t.Fatal(err)
+	}
+	cb, _ := st.GetCostBasis("binance", "BTCUSDT")
+	cb.Quantity = 99
+	if again, _ := st.GetCostBasis("binance", "BTCUSDT"); again.Quantity != 1 {
+		t.Fatalf("修改返回值不应影响存储内容, got %v", again.Quantity)
+	}
+	if missing, err := st.GetGridAnchor("binance", "BTCUSDT"); missing != nil || err != nil {
+		t.Fatalf("不存在的锚点应返回 nil, got %+v, %v", missing, err)
+	}
+}
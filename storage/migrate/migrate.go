@@ -0,0 +1,327 @@
+// Package migrate 版本化的数据库结构迁移
+//
+// 迁移脚本命名为 <版本号>_<名称>.up.sql 和可选的 <版本号>_<名称>.down.sql，版本号从 1 开始连续递增，
+// 已执行的版本记录在 schema_version 表中。每个版本在独立事务中执行并写入版本记录，失败时整体回滚。
+// SQLite 与 Postgres 共用同一套流程，方言只影响占位符。
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"quantmesh/logger"
+)
+
+// Dialect 数据库方言
+type Dialect struct {
+	Name        string
+	Placeholder func(n int) string // 第 n 个参数的占位符（从 1 开始）
+}
+
+var (
+	// SQLite 使用 ? 占位符
+	SQLite = Dialect{Name: "sqlite", Placeholder: func(int) string { return "?" }}
+	// Postgres 使用 $n 占位符
+	Postgres = Dialect{Name: "postgres", Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) }}
+)
+
+// Migration 单个版本的迁移脚本
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string // 为空表示该版本不支持回滚
+}
+
+// Status 迁移版本的执行状态
+type Status struct {
+	Version    int        `json:"version"`
+	Name       string     `json:"name"`
+	Applied    bool       `json:"applied"`
+	AppliedAt  *time.Time `json:"applied_at,omitempty"`
+	Reversible bool       `json:"reversible"`
+}
+
+// BackupFunc 执行迁移前的备份，fromVersion 为迁移前的版本，返回备份位置
+type BackupFunc func(fromVersion int) (string, error)
+
+// Load 从目录中读取迁移脚本（通常为 embed.FS），校验版本号连续且每个版本都有 up 脚本
+func Load(fsys fs.FS, dir string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移目录失败: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(fileName, ".sql") {
+			continue
+		}
+		base := strings.TrimSuffix(fileName, ".sql")
+		direction := path.Ext(base)
+		if direction != ".up" && direction != ".down" {
+			return nil, fmt.Errorf("迁移文件名应以 .up.sql 或 .down.sql 结尾: %s", fileName)
+		}
+		base = strings.TrimSuffix(base, direction)
+		idx := strings.Index(base, "_")
+		if idx <= 0 {
+			return nil, fmt.Errorf("迁移文件名应为 <版本号>_<名称>: %s", fileName)
+		}
+		version, err := strconv.Atoi(base[:idx])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("迁移文件版本号无效: %s", fileName)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, fileName))
+		if err != nil {
+			return nil, fmt.Errorf("读取迁移文件失败: %w", err)
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: base[idx+1:]}
+			byVersion[version] = m
+		} else if m.Name != base[idx+1:] {
+			return nil, fmt.Errorf("版本 %d 存在多个名称: %s / %s", version, m.Name, base[idx+1:])
+		}
+		if direction == ".up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("迁移版本不连续: 期望 %d, 得到 %d", i+1, m.Version)
+		}
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("迁移版本 %d 缺少 up 脚本", m.Version)
+		}
+	}
+	return migrations, nil
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []*Migration
+	backup     BackupFunc
+}
+
+// New 创建迁移执行器，migrations 需按版本号升序（Load 的返回值）
+func New(db *sql.DB, dialect Dialect, migrations []*Migration) *Migrator {
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}
+}
+
+// SetBackup 设置迁移前的备份函数（只在已有版本的数据库上执行变更时调用）
+func (m *Migrator) SetBackup(fn BackupFunc) {
+	m.backup = fn
+}
+
+// Latest 返回最新的迁移版本
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// ensureVersionTable 创建 schema_version 表
+func (m *Migrator) ensureVersionTable() error {
+	_, err := m.db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)`)
+	if err != nil {
+		return fmt.Errorf("创建 schema_version 表失败: %w", err)
+	}
+	return nil
+}
+
+// applied 读取已执行的版本及执行时间
+func (m *Migrator) applied() (map[int]time.Time, error) {
+	if err := m.ensureVersionTable(); err != nil {
+		return nil, err
+	}
+	rows, err := m.db.Query(`SELECT version, applied_at FROM schema_version`)
+	if err != nil {
+		return nil, fmt.Errorf("查询 schema_version 失败: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("读取 schema_version 失败: %w", err)
+		}
+		result[version] = appliedAt
+	}
+	return result, rows.Err()
+}
+
+// Current 返回当前数据库版本（0 表示尚未执行任何迁移）
+func (m *Migrator) Current() (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+	current := 0
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+	return current, nil
+}
+
+// Status 返回所有迁移版本的执行状态
+func (m *Migrator) Status() ([]*Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		st := &Status{Version: mig.Version, Name: mig.Name, Reversible: strings.TrimSpace(mig.Down) != ""}
+		if t, ok := applied[mig.Version]; ok {
+			appliedAt := t
+			st.Applied = true
+			st.AppliedAt = &appliedAt
+		}
+		result = append(result, st)
+	}
+	return result, nil
+}
+
+// Up 执行所有未执行的迁移，返回执行的版本数
+func (m *Migrator) Up() (int, error) {
+	current, err := m.Current()
+	if err != nil {
+		return 0, err
+	}
+	if current > m.Latest() {
+		return 0, fmt.Errorf("数据库版本 %d 高于程序支持的最新版本 %d，请升级程序", current, m.Latest())
+	}
+	if current == m.Latest() {
+		return 0, nil
+	}
+	if err := m.runBackup(current); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, mig := range m.migrations {
+		if mig.Version <= current {
+			continue
+		}
+		logger.Info("🔄 [数据库迁移] 执行 %04d_%s", mig.Version, mig.Name)
+		if err := m.exec(mig.Up, func(tx *sql.Tx) error {
+			_, err := tx.Exec(fmt.Sprintf(`INSERT INTO schema_version (version, name, applied_at) VALUES (%s, %s, %s)`,
+				m.dialect.Placeholder(1), m.dialect.Placeholder(2), m.dialect.Placeholder(3)),
+				mig.Version, mig.Name, time.Now().UTC())
+			return err
+		}); err != nil {
+			return count, fmt.Errorf("迁移 %04d_%s 失败: %w", mig.Version, mig.Name, err)
+		}
+		count++
+	}
+	logger.Info("✅ [数据库迁移] 已升级到版本 %d（执行 %d 个迁移）", m.Latest(), count)
+	return count, nil
+}
+
+// Down 回滚到指定版本（回滚所有大于 target 的版本），返回回滚的版本数
+// 任一待回滚的版本没有 down 脚本时不做任何变更
+func (m *Migrator) Down(target int) (int, error) {
+	if target < 0 {
+		return 0, fmt.Errorf("目标版本不能为负数")
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	var pending []*Migration
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if mig.Version <= target {
+			break
+		}
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		if strings.TrimSpace(mig.Down) == "" {
+			return 0, fmt.Errorf("迁移 %04d_%s 不支持回滚", mig.Version, mig.Name)
+		}
+		pending = append(pending, mig)
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+	if err := m.runBackup(pending[0].Version); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, mig := range pending {
+		logger.Info("🔄 [数据库迁移] 回滚 %04d_%s", mig.Version, mig.Name)
+		if err := m.exec(mig.Down, func(tx *sql.Tx) error {
+			_, err := tx.Exec(fmt.Sprintf(`DELETE FROM schema_version WHERE version = %s`, m.dialect.Placeholder(1)), mig.Version)
+			return err
+		}); err != nil {
+			return count, fmt.Errorf("回滚 %04d_%s 失败: %w", mig.Version, mig.Name, err)
+		}
+		count++
+	}
+	logger.Info("✅ [数据库迁移] 已回滚到版本 %d（回滚 %d 个迁移）", target, count)
+	return count, nil
+}
+
+// exec 在事务中执行脚本并更新版本记录
+func (m *Migrator) exec(script string, record func(tx *sql.Tx) error) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(script); err != nil {
+		return err
+	}
+	if err := record(tx); err != nil {
+		return fmt.Errorf("更新 schema_version 失败: %w", err)
+	}
+	return tx.Commit()
+}
+
+// runBackup 变更已有版本的数据库前执行备份，备份失败时中止迁移
+func (m *Migrator) runBackup(fromVersion int) error {
+	if m.backup == nil || fromVersion == 0 {
+		return nil
+	}
+	location, err := m.backup(fromVersion)
+	if err != nil {
+		return fmt.Errorf("迁移前备份失败，已中止迁移: %w", err)
+	}
+	if location == "" {
+		return nil
+	}
+	logger.Info("💾 [数据库迁移] 迁移前已备份版本 %d 到 %s", fromVersion, location)
+	return nil
+}
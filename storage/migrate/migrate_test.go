@@ -0,0 +1,142 @@
+package migrate
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&count); err != nil {
+		t.Fatalf("查询表失败: %v", err)
+	}
+	return count > 0
+}
+
+var testMigrations = fstest.MapFS{
+	"m/0001_init.up.sql":    {Data: []byte(`CREATE TABLE a (id INTEGER);`)},
+	"m/0002_add_b.up.sql":   {Data: []byte(`CREATE TABLE b (id INTEGER); INSERT INTO b VALUES (1);`)},
+	"m/0002_add_b.down.sql": {Data: []byte(`DROP TABLE b;`)},
+	"m/0003_add_c.up.sql":   {Data: []byte(`CREATE TABLE c (id INTEGER);`)},
+	"m/0003_add_c.down.sql": {Data: []byte(`DROP TABLE c;`)},
+	"m/README.md":           {Data: []byte(`ignored`)},
+}
+
+func TestUpDownAndBackup(t *testing.T) {
+	migrations, err := Load(testMigrations, "m")
+	if err != nil {
+		t.Fatalf("加载迁移失败: %v", err)
+	}
+	db := openTestDB(t)
+	m := New(db, SQLite, migrations)
+	var backups []int
+	m.SetBackup(func(from int) (string, error) {
+		backups = append(backups, from)
+		return "backup", nil
+	})
+
+	if n, err := m.Up(); err != nil || n != 3 {
+		t.Fatalf("首次迁移应执行 3 个版本: n=%d, err=%v", n, err)
+	}
+	if len(backups) != 0 {
+		t.Errorf("空库首次迁移不应备份: %v", backups)
+	}
+	if n, _ := m.Up(); n != 0 {
+		t.Errorf("重复执行不应再迁移: %d", n)
+	}
+
+	if n, err := m.Down(1); err != nil || n != 2 {
+		t.Fatalf("应回滚 2 个版本: n=%d, err=%v", n, err)
+	}
+	if tableExists(t, db, "b") || tableExists(t, db, "c") || !tableExists(t, db, "a") {
+		t.Errorf("回滚后表结构不正确")
+	}
+	if current, _ := m.Current(); current != 1 {
+		t.Errorf("回滚后版本应为 1, 得到 %d", current)
+	}
+	if len(backups) != 1 || backups[0] != 3 {
+		t.Errorf("回滚前应备份版本 3: %v", backups)
+	}
+
+	// 版本 1 没有 down 脚本，不能回滚
+	if _, err := m.Down(0); err == nil {
+		t.Errorf("不可回滚的版本应返回错误")
+	}
+
+	if n, err := m.Up(); err != nil || n != 2 {
+		t.Fatalf("再次升级应执行 2 个版本: n=%d, err=%v", n, err)
+	}
+	if len(backups) != 2 || backups[1] != 1 {
+		t.Errorf("升级已有版本的数据库前应备份: %v", backups)
+	}
+
+	status, err := m.Status()
+	if err != nil || len(status) != 3 {
+		t.Fatalf("查询状态失败: %v, err=%v", status, err)
+	}
+	for _, st := range status {
+		if !st.Applied || st.AppliedAt == nil {
+			t.Errorf("版本 %d 应为已执行", st.Version)
+		}
+	}
+	if status[0].Reversible || !status[1].Reversible {
+		t.Errorf("可回滚标记错误: %+v %+v", status[0], status[1])
+	}
+}
+
+func TestFailedMigrationRollsBack(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0001_init.up.sql":   {Data: []byte(`CREATE TABLE a (id INTEGER);`)},
+		"m/0002_broken.up.sql": {Data: []byte(`CREATE TABLE b (id INTEGER); INSERT INTO missing VALUES (1);`)},
+	}
+	migrations, err := Load(fsys, "m")
+	if err != nil {
+		t.Fatalf("加载迁移失败: %v", err)
+	}
+	db := openTestDB(t)
+	m := New(db, SQLite, migrations)
+
+	if n, err := m.Up(); err == nil || n != 1 {
+		t.Fatalf("第二个版本应失败: n=%d, err=%v", n, err)
+	}
+	if tableExists(t, db, "b") {
+		t.Errorf("失败的迁移应整体回滚")
+	}
+	if current, _ := m.Current(); current != 1 {
+		t.Errorf("失败后版本应停留在 1, 得到 %d", current)
+	}
+}
+
+func TestLoadValidation(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"版本不连续": {
+			"m/0001_a.up.sql": {Data: []byte(`SELECT 1;`)},
+			"m/0003_c.up.sql": {Data: []byte(`SELECT 1;`)},
+		},
+		"缺少 up": {
+			"m/0001_a.down.sql": {Data: []byte(`SELECT 1;`)},
+		},
+		"文件名无效": {
+			"m/init.up.sql": {Data: []byte(`SELECT 1;`)},
+		},
+	}
+	for name, fsys := range cases {
+		if _, err := Load(fsys, "m"); err == nil {
+			t.Errorf("%s: 应返回错误", name)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+-- 基线结构：引入版本化迁移时的完整表结构
+-- 在此之前创建的数据库会先由旧的补列逻辑升级，再执行本脚本补齐缺失的表和索引
+
+-- 订单表（订单号只在同一交易所内唯一）
+CREATE TABLE IF NOT EXISTS orders (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	order_id BIGINT,
+	client_order_id TEXT,
+	exchange TEXT,
+	symbol TEXT,
+	side TEXT,
+	price DECIMAL(20,8),
+	quantity DECIMAL(20,8),
+	status TEXT,
+	created_at TIMESTAMP,
+	updated_at TIMESTAMP,
+	UNIQUE(exchange, order_id)
+);
+
+-- 持仓表
+CREATE TABLE IF NOT EXISTS positions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	slot_price DECIMAL(20,8),
+	exchange TEXT,
+	symbol TEXT,
+	size DECIMAL(20,8),
+	entry_price DECIMAL(20,8),
+	current_price DECIMAL(20,8),
+	pnl DECIMAL(20,8),
+	opened_at TIMESTAMP,
+	closed_at TIMESTAMP
+);
+
+-- 交易表（买卖配对）
+CREATE TABLE IF NOT EXISTS trades (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	buy_order_id BIGINT,
+	sell_order_id BIGINT,
+	exchange TEXT,
+	symbol TEXT,
+	buy_price DECIMAL(20,8),
+	sell_price DECIMAL(20,8),
+	quantity DECIMAL(20,8),
+	pnl DECIMAL(20,8),
+	created_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_trades_exchange_symbol ON trades(exchange, symbol);
+CREATE INDEX IF NOT EXISTS idx_trades_created_at ON trades(created_at);
+
+-- 事件表
+CREATE TABLE IF NOT EXISTS events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_type TEXT,
+	data TEXT,
+	created_at TIMESTAMP
+);
+
+-- 系统监控细粒度数据表
+CREATE TABLE IF NOT EXISTS system_metrics (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp DATETIME NOT NULL,
+	cpu_percent REAL NOT NULL,
+	memory_mb REAL NOT NULL,
+	memory_percent REAL,
+	process_id INTEGER,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_system_metrics_timestamp ON system_metrics(timestamp);
+
+-- 系统监控每日汇总数据表
+CREATE TABLE IF NOT EXISTS daily_system_metrics (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	date DATE NOT NULL UNIQUE,
+	avg_cpu_percent REAL NOT NULL,
+	max_cpu_percent REAL NOT NULL,
+	min_cpu_percent REAL NOT NULL,
+	avg_memory_mb REAL NOT NULL,
+	max_memory_mb REAL NOT NULL,
+	min_memory_mb REAL NOT NULL,
+	sample_count INTEGER NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_daily_system_metrics_date ON daily_system_metrics(date);
+
+-- 统计表（按交易所+交易对+日期唯一）
+CREATE TABLE IF NOT EXISTS statistics (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	exchange TEXT,
+	symbol TEXT,
+	date DATE,
+	total_trades INTEGER,
+	total_volume DECIMAL(20,8),
+	total_pnl DECIMAL(20,8),
+	win_rate DECIMAL(5,2),
+	created_at TIMESTAMP,
+	UNIQUE(exchange, symbol, date)
+);
+
+-- 对账历史表
+CREATE TABLE IF NOT EXISTS reconciliation_history (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	exchange TEXT,
+	symbol TEXT,
+	reconcile_time TIMESTAMP,
+	local_position DECIMAL(20,8),
+	exchange_position DECIMAL(20,8),
+	position_diff DECIMAL(20,8),
+	active_buy_orders INTEGER,
+	active_sell_orders INTEGER,
+	pending_sell_qty DECIMAL(20,8),
+	total_buy_qty DECIMAL(20,8),
+	total_sell_qty DECIMAL(20,8),
+	estimated_profit DECIMAL(20,8),
+	actual_profit DECIMAL(20,8) DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_history_symbol ON reconciliation_history(symbol);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_history_time ON reconciliation_history(reconcile_time);
+
+-- 风控检查历史表
+CREATE TABLE IF NOT EXISTS risk_check_history (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	check_time TIMESTAMP NOT NULL,
+	symbol TEXT NOT NULL,
+	is_healthy INTEGER NOT NULL,
+	price_deviation REAL,
+	volume_ratio REAL,
+	reason TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_risk_check_history_time ON risk_check_history(check_time);
+CREATE INDEX IF NOT EXISTS idx_risk_check_history_symbol ON risk_check_history(symbol);
+CREATE INDEX IF NOT EXISTS idx_risk_check_history_time_symbol ON risk_check_history(check_time, symbol);
+
+-- 资金费率表
+CREATE TABLE IF NOT EXISTS funding_rates (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	symbol TEXT NOT NULL,
+	exchange TEXT NOT NULL,
+	rate REAL NOT NULL,
+	timestamp TIMESTAMP NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_funding_rates_symbol ON funding_rates(symbol);
+CREATE INDEX IF NOT EXISTS idx_funding_rates_timestamp ON funding_rates(timestamp);
+CREATE INDEX IF NOT EXISTS idx_funding_rates_symbol_timestamp ON funding_rates(symbol, timestamp);
+
+-- AI提示词模板表
+CREATE TABLE IF NOT EXISTS ai_prompts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	module TEXT UNIQUE NOT NULL,
+	template TEXT NOT NULL,
+	system_prompt TEXT,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_ai_prompts_module ON ai_prompts(module);
+
+-- 价差数据表
+CREATE TABLE IF NOT EXISTS basis_data (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	symbol TEXT NOT NULL,
+	exchange TEXT NOT NULL,
+	spot_price REAL NOT NULL,
+	futures_price REAL NOT NULL,
+	basis REAL NOT NULL,
+	basis_percent REAL NOT NULL,
+	funding_rate REAL,
+	timestamp DATETIME NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_basis_symbol_time ON basis_data(symbol, timestamp);
+CREATE INDEX IF NOT EXISTS idx_basis_exchange ON basis_data(exchange);
+
+-- 持仓成本表（每个交易所+交易对一行）
+CREATE TABLE IF NOT EXISTS cost_basis (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	exchange TEXT NOT NULL,
+	symbol TEXT NOT NULL,
+	quantity REAL NOT NULL,
+	total_cost REAL NOT NULL,
+	avg_entry_price REAL NOT NULL,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(exchange, symbol)
+);
+
+-- 网格锚点表（每个交易所+交易对一行）
+CREATE TABLE IF NOT EXISTS grid_anchors (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	exchange TEXT NOT NULL,
+	symbol TEXT NOT NULL,
+	price REAL NOT NULL,
+	source TEXT,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(exchange, symbol)
+);
+
+-- 资金费流水表（按交易所流水号去重）
+CREATE TABLE IF NOT EXISTS funding_payments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	exchange TEXT NOT NULL,
+	symbol TEXT NOT NULL,
+	asset TEXT,
+	amount REAL NOT NULL,
+	tran_id TEXT NOT NULL,
+	payment_time DATETIME NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(exchange, tran_id)
+);
+CREATE INDEX IF NOT EXISTS idx_funding_payments_symbol_time ON funding_payments(symbol, payment_time);
+
+-- 利润金库划转记录表
+CREATE TABLE IF NOT EXISTS profit_transfers (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	exchange TEXT NOT NULL,
+	asset TEXT NOT NULL,
+	amount REAL NOT NULL,
+	to_wallet TEXT NOT NULL,
+	tran_id TEXT,
+	period TEXT,
+	period_start DATETIME NOT NULL,
+	realized_pnl REAL,
+	status TEXT NOT NULL,
+	error TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_profit_transfers_exchange_time ON profit_transfers(exchange, created_at);
+
+-- 持仓量与大户多空比表
+CREATE TABLE IF NOT EXISTS open_interest (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	symbol TEXT NOT NULL,
+	exchange TEXT NOT NULL,
+	open_interest REAL NOT NULL,
+	open_interest_value REAL,
+	long_short_ratio REAL,
+	long_percent REAL,
+	short_percent REAL,
+	timestamp DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_open_interest_symbol_time ON open_interest(exchange, symbol, timestamp);
+
+-- 交易日志表
+CREATE TABLE IF NOT EXISTS journal_entries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	exchange TEXT,
+	symbol TEXT,
+	strategy TEXT,
+	source TEXT NOT NULL,
+	event_type TEXT,
+	title TEXT NOT NULL,
+	content TEXT,
+	tags TEXT,
+	equity REAL,
+	created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_created_at ON journal_entries(created_at);
+
+-- 拒单记录表
+CREATE TABLE IF NOT EXISTS rejected_orders (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	exchange TEXT NOT NULL,
+	symbol TEXT NOT NULL,
+	side TEXT NOT NULL,
+	price REAL,
+	quantity REAL,
+	client_order_id TEXT,
+	strategy TEXT,
+	category TEXT NOT NULL,
+	reason TEXT,
+	payload TEXT,
+	created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_rejected_orders_symbol_time ON rejected_orders(exchange, symbol, created_at);
+
+-- OCO 关联订单表（按 link_id 覆盖更新）
+CREATE TABLE IF NOT EXISTS oco_links (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	link_id TEXT NOT NULL UNIQUE,
+	exchange TEXT NOT NULL,
+	symbol TEXT NOT NULL,
+	side TEXT NOT NULL,
+	quantity REAL NOT NULL,
+	take_profit_price REAL NOT NULL,
+	stop_price REAL NOT NULL,
+	take_profit_order_id INTEGER,
+	take_profit_client_id TEXT,
+	stop_order_id INTEGER,
+	native INTEGER NOT NULL DEFAULT 0,
+	status TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_oco_links_status ON oco_links(exchange, symbol, status);
+
+-- 账户收支流水表（按交易所流水号去重；同一流水号在不同类型下可能重复出现）
+CREATE TABLE IF NOT EXISTS income_records (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	exchange TEXT NOT NULL,
+	symbol TEXT NOT NULL,
+	income_type TEXT NOT NULL,
+	asset TEXT,
+	amount REAL NOT NULL,
+	tran_id TEXT NOT NULL,
+	income_time DATETIME NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(exchange, income_type, tran_id)
+);
+CREATE INDEX IF NOT EXISTS idx_income_records_symbol_time ON income_records(exchange, symbol, income_time);
+
+-- 索引
+CREATE INDEX IF NOT EXISTS idx_orders_order_id ON orders(order_id);
+CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders(created_at);
+CREATE INDEX IF NOT EXISTS idx_positions_slot_price ON positions(slot_price);
+CREATE INDEX IF NOT EXISTS idx_trades_created_at ON trades(created_at);
+CREATE INDEX IF NOT EXISTS idx_trades_symbol ON trades(symbol);
+CREATE INDEX IF NOT EXISTS idx_events_created_at ON events(created_at);
+CREATE INDEX IF NOT EXISTS idx_orders_exchange_symbol_time ON orders(exchange, symbol, created_at);
+CREATE INDEX IF NOT EXISTS idx_positions_exchange_symbol ON positions(exchange, symbol);
+CREATE INDEX IF NOT EXISTS idx_trades_exchange_symbol_time ON trades(exchange, symbol, created_at);
+CREATE INDEX IF NOT EXISTS idx_statistics_exchange_symbol_date ON statistics(exchange, symbol, date);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_history_exchange_symbol_time ON reconciliation_history(exchange, symbol, reconcile_time);
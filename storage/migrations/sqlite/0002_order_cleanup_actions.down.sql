@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_order_cleanup_actions_order_id;
+DROP INDEX IF EXISTS idx_order_cleanup_actions_symbol_time;
+DROP TABLE IF EXISTS order_cleanup_actions;
@@ -0,0 +1,19 @@
+-- 订单清理动作记录（每个被撤销或演练中将被撤销的订单一行）
+CREATE TABLE IF NOT EXISTS order_cleanup_actions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	exchange TEXT NOT NULL,
+	symbol TEXT NOT NULL,
+	order_id INTEGER NOT NULL,
+	client_order_id TEXT,
+	side TEXT,
+	price REAL,
+	reason TEXT NOT NULL,
+	detail TEXT,
+	trigger_source TEXT NOT NULL,
+	dry_run INTEGER NOT NULL DEFAULT 0,
+	success INTEGER NOT NULL DEFAULT 1,
+	error TEXT,
+	created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_order_cleanup_actions_symbol_time ON order_cleanup_actions(exchange, symbol, created_at);
+CREATE INDEX IF NOT EXISTS idx_order_cleanup_actions_order_id ON order_cleanup_actions(order_id);
@@ -0,0 +1,3 @@
+ALTER TABLE ai_prompts DROP COLUMN pinned_version;
+ALTER TABLE ai_prompts DROP COLUMN version;
+DROP TABLE IF EXISTS ai_prompt_versions;
@@ -0,0 +1,19 @@
+-- AI提示词版本历史（每次修改或回滚追加一个版本，ai_prompts 保存当前版本）
+CREATE TABLE IF NOT EXISTS ai_prompt_versions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	module TEXT NOT NULL,
+	version INTEGER NOT NULL,
+	template TEXT NOT NULL,
+	system_prompt TEXT,
+	author TEXT,
+	comment TEXT,
+	created_at DATETIME NOT NULL,
+	UNIQUE(module, version)
+);
+ALTER TABLE ai_prompts ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE ai_prompts ADD COLUMN pinned_version INTEGER NOT NULL DEFAULT 0;
+
+-- 已有模板记为版本 1
+INSERT INTO ai_prompt_versions (module, version, template, system_prompt, author, comment, created_at)
+SELECT module, 1, template, system_prompt, 'system', '启用版本历史前的模板', COALESCE(updated_at, CURRENT_TIMESTAMP) FROM ai_prompts;
+UPDATE ai_prompts SET version = 1;
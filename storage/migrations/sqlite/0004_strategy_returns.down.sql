@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_strategy_returns_date;
+DROP TABLE IF EXISTS strategy_returns;
@@ -0,0 +1,13 @@
+-- 各策略的每日收益记录（组合优化器按日期汇总计算收益率）
+CREATE TABLE IF NOT EXISTS strategy_returns (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	date DATETIME NOT NULL,
+	exchange TEXT NOT NULL,
+	symbol TEXT NOT NULL,
+	strategy TEXT NOT NULL,
+	pnl REAL NOT NULL DEFAULT 0,
+	allocated REAL NOT NULL DEFAULT 0,
+	updated_at DATETIME NOT NULL,
+	UNIQUE(date, exchange, symbol, strategy)
+);
+CREATE INDEX IF NOT EXISTS idx_strategy_returns_date ON strategy_returns(date);
@@ -0,0 +1,15 @@
+-- 本地保存的 K 线（停机期间的缺口在启动时从交易所补齐，指标和图表不出现断档）
+CREATE TABLE IF NOT EXISTS klines (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	exchange TEXT NOT NULL,
+	symbol TEXT NOT NULL,
+	interval TEXT NOT NULL,
+	open_time DATETIME NOT NULL,
+	open REAL NOT NULL,
+	high REAL NOT NULL,
+	low REAL NOT NULL,
+	close REAL NOT NULL,
+	volume REAL NOT NULL DEFAULT 0,
+	updated_at DATETIME NOT NULL,
+	UNIQUE(exchange, symbol, interval, open_time)
+);
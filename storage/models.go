@@ -6,6 +6,7 @@ import "time"
 type Order struct {
 	OrderID       int64
 	ClientOrderID string
+	Exchange      string
 	Symbol        string
 	Side          string
 	Price         float64
@@ -18,6 +19,7 @@ type Order struct {
 // Position 持仓模型
 type Position struct {
 	SlotPrice    float64
+	Exchange     string
 	Symbol       string
 	Size         float64
 	EntryPrice   float64
@@ -42,6 +44,8 @@ type Trade struct {
 
 // Statistics 统计模型
 type Statistics struct {
+	Exchange    string
+	Symbol      string
 	Date        time.Time
 	TotalTrades int
 	TotalVolume float64
@@ -59,6 +63,8 @@ type DailyStatisticsWithTradeCount struct {
 	WinRate       float64
 	WinningTrades int
 	LosingTrades  int
+	FundingFee    float64 // 当日资金费收支（正数为收入）
+	NetPnL        float64 // 交易盈亏 + 资金费
 }
 
 // SystemMetrics 系统监控细粒度数据模型
@@ -89,6 +95,7 @@ type DailySystemMetrics struct {
 // ReconciliationHistory 对账历史记录
 type ReconciliationHistory struct {
 	ID               int64
+	Exchange         string
 	Symbol           string
 	ReconcileTime    time.Time
 	LocalPosition    float64
@@ -113,6 +120,8 @@ type PnLSummary struct {
 	WinRate       float64
 	WinningTrades int
 	LosingTrades  int
+	FundingFee    float64 // 资金费收支（正数为收入）
+	NetPnL        float64 // 交易盈亏 + 资金费
 }
 
 // PnLBySymbol 按币种对的盈亏数据
@@ -123,6 +132,8 @@ type PnLBySymbol struct {
 	TotalTrades int
 	TotalVolume float64
 	WinRate     float64
+	FundingFee  float64 // 资金费收支（正数为收入）
+	NetPnL      float64 // 交易盈亏 + 资金费
 }
 
 // RiskCheckRecord 风控检查记录（单条）
@@ -164,11 +175,25 @@ type FundingRate struct {
 
 // AIPromptTemplate AI提示词模板模型
 type AIPromptTemplate struct {
-	ID           int64
-	Module       string // 模块名: market_analysis, parameter_optimization, risk_analysis, sentiment_analysis
-	Template     string // 提示词模板（支持占位符）
-	SystemPrompt string // 系统提示词（可选）
-	UpdatedAt    time.Time
+	ID            int64
+	Module        string // 模块名: market_analysis, parameter_optimization, risk_analysis, sentiment_analysis
+	Template      string // 提示词模板（支持占位符）
+	SystemPrompt  string // 系统提示词（可选）
+	Version       int    // 当前版本号（0 表示尚无版本记录）
+	PinnedVersion int    // 固定使用的版本，0 表示使用当前版本
+	UpdatedAt     time.Time
+}
+
+// AIPromptVersion AI提示词的历史版本（每次修改或回滚追加一条，不可修改）
+type AIPromptVersion struct {
+	ID           int64     `json:"id"`
+	Module       string    `json:"module"`
+	Version      int       `json:"version"` // 同一模块内从 1 递增
+	Template     string    `json:"template"`
+	SystemPrompt string    `json:"system_prompt"`
+	Author       string    `json:"author"`
+	Comment      string    `json:"comment,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 // BasisData 价差数据
@@ -194,3 +219,209 @@ type BasisStats struct {
 	DataPoints int     `json:"data_points"` // 数据点数量
 	Hours      int     `json:"hours"`       // 统计时间范围（小时）
 }
+
+// CostBasis 持仓成本（按交易所+交易对汇总，重启后用于恢复槽位成本）
+type CostBasis struct {
+	Exchange      string    `json:"exchange"`
+	Symbol        string    `json:"symbol"`
+	Quantity      float64   `json:"quantity"`        // 持仓数量
+	TotalCost     float64   `json:"total_cost"`      // 持仓总成本（含手续费）
+	AvgEntryPrice float64   `json:"avg_entry_price"` // 加权平均开仓价
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// GridAnchor 网格价格锚点（按交易所+交易对持久化，重启后沿用，避免网格被意外重新定位）
+type GridAnchor struct {
+	Exchange  string    `json:"exchange"`
+	Symbol    string    `json:"symbol"`
+	Price     float64   `json:"price"`
+	Source    string    `json:"source"` // 锚点来源：first_tick / vwap / candle_close / manual
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FundingPayment 实际发生的资金费流水（来自交易所账户流水接口）
+type FundingPayment struct {
+	ID          int64     `json:"id"`
+	Exchange    string    `json:"exchange"`
+	Symbol      string    `json:"symbol"`
+	Asset       string    `json:"asset"`
+	Amount      float64   `json:"amount"`  // 正数为收入，负数为支出
+	TranID      string    `json:"tran_id"` // 交易所流水号（同一交易所内唯一）
+	PaymentTime time.Time `json:"payment_time"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// IncomeRecord 账户收支流水（已实现盈亏、手续费、资金费，来自交易所账户流水接口）
+type IncomeRecord struct {
+	ID         int64     `json:"id"`
+	Exchange   string    `json:"exchange"`
+	Symbol     string    `json:"symbol"`
+	IncomeType string    `json:"income_type"` // REALIZED_PNL / COMMISSION / FUNDING_FEE
+	Asset      string    `json:"asset"`
+	Amount     float64   `json:"amount"`  // 正数为收入，负数为支出
+	TranID     string    `json:"tran_id"` // 交易所流水号
+	IncomeTime time.Time `json:"income_time"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// StrategyReturn 单个策略在某交易对上的每日收益（同一日期、交易所、交易对、策略覆盖）
+type StrategyReturn struct {
+	ID        int64     `json:"id"`
+	Date      time.Time `json:"date"` // UTC 零点
+	Exchange  string    `json:"exchange"`
+	Symbol    string    `json:"symbol"`
+	Strategy  string    `json:"strategy"`
+	PnL       float64   `json:"pnl"`       // 当日盈亏
+	Allocated float64   `json:"allocated"` // 当日分配给该策略的资金
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Kline 本地保存的 K 线（同一交易所、交易对、周期、开盘时间覆盖）
+type Kline struct {
+	ID        int64     `json:"id"`
+	Exchange  string    `json:"exchange"`
+	Symbol    string    `json:"symbol"`
+	Interval  string    `json:"interval"`  // 1m / 1h 等
+	OpenTime  time.Time `json:"open_time"` // 开盘时间（UTC）
+	Open      float64   `json:"open"`
+	High      float64   `json:"high"`
+	Low       float64   `json:"low"`
+	Close     float64   `json:"close"`
+	Volume    float64   `json:"volume"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ProfitTransfer 利润金库划转记录（合约账户 -> 现货/资金账户）
+type ProfitTransfer struct {
+	ID          int64     `json:"id"`
+	Exchange    string    `json:"exchange"`
+	Asset       string    `json:"asset"`
+	Amount      float64   `json:"amount"`
+	ToWallet    string    `json:"to_wallet"`
+	TranID      string    `json:"tran_id"`      // 交易所划转流水号（失败时为空）
+	Period      string    `json:"period"`       // 统计周期 daily / weekly / monthly
+	PeriodStart time.Time `json:"period_start"` // 所属统计周期的起始时间
+	RealizedPnL float64   `json:"realized_pnl"` // 划转时该周期的已实现盈亏
+	Status      string    `json:"status"`       // success / failed
+	Error       string    `json:"error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// OpenInterestData 合约持仓量与大户多空比快照
+type OpenInterestData struct {
+	Symbol            string    `json:"symbol"`
+	Exchange          string    `json:"exchange"`
+	OpenInterest      float64   `json:"open_interest"`       // 持仓量（币）
+	OpenInterestValue float64   `json:"open_interest_value"` // 持仓价值（计价币，按标记价格折算）
+	LongShortRatio    float64   `json:"long_short_ratio"`    // 大户持仓多空比
+	LongPercent       float64   `json:"long_percent"`        // 大户多头占比（0-1）
+	ShortPercent      float64   `json:"short_percent"`       // 大户空头占比（0-1）
+	Timestamp         time.Time `json:"timestamp"`
+}
+
+// JournalEntry 交易日志条目（自动记录的重要事件或手动笔记）
+type JournalEntry struct {
+	ID        int64     `json:"id"`
+	Exchange  string    `json:"exchange"`   // 为空表示全局
+	Symbol    string    `json:"symbol"`     // 为空表示全局
+	Strategy  string    `json:"strategy"`   // 为空表示不针对具体策略
+	Source    string    `json:"source"`     // auto / manual
+	EventType string    `json:"event_type"` // 自动条目的事件类型（如 risk_triggered、drawdown、config_change）
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Tags      string    `json:"tags"`   // 逗号分隔的标签
+	Equity    float64   `json:"equity"` // 记录时的累计已实现盈亏，对应收益曲线上的位置
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RejectedOrder 被拒绝/失败的下单记录（单侧挂单长期静默失败是网格停止报价的常见原因）
+type RejectedOrder struct {
+	ID            int64     `json:"id"`
+	Exchange      string    `json:"exchange"`
+	Symbol        string    `json:"symbol"`
+	Side          string    `json:"side"`
+	Price         float64   `json:"price"`
+	Quantity      float64   `json:"quantity"`
+	ClientOrderID string    `json:"client_order_id"`
+	Strategy      string    `json:"strategy"`
+	Category      string    `json:"category"` // 错误分类：rate_limited / insufficient_margin / invalid_filter / reduce_only / post_only 等
+	Reason        string    `json:"reason"`
+	Payload       string    `json:"payload"` // 下单请求（JSON）
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// RejectedOrderQuery 拒单记录查询条件（字段为空表示不过滤）
+type RejectedOrderQuery struct {
+	Exchange  string
+	Symbol    string
+	Side      string
+	Category  string
+	StartTime time.Time
+	EndTime   time.Time
+	Limit     int
+}
+
+// 订单清理原因
+const (
+	CleanupReasonThreshold = "threshold" // 挂单总数超过上限，撤销离当前价格最远的一批
+	CleanupReasonExpired   = "expired"   // 挂单超过最长存活时间仍未成交
+	CleanupReasonOrphan    = "orphan"    // 交易所有、本地槽位没有的孤儿挂单
+)
+
+// OrderCleanupAction 订单清理器的一次撤单动作（演练模式下记录将被撤销的订单）
+type OrderCleanupAction struct {
+	ID            int64     `json:"id"`
+	Exchange      string    `json:"exchange"`
+	Symbol        string    `json:"symbol"`
+	OrderID       int64     `json:"order_id"`
+	ClientOrderID string    `json:"client_order_id"`
+	Side          string    `json:"side"`
+	Price         float64   `json:"price"`
+	Reason        string    `json:"reason"`  // threshold / expired / orphan
+	Detail        string    `json:"detail"`  // 触发时的具体条件，如挂单数与阈值、挂单时长
+	Trigger       string    `json:"trigger"` // scheduled / manual
+	DryRun        bool      `json:"dry_run"`
+	Success       bool      `json:"success"`
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// OrderCleanupQuery 订单清理记录查询条件（字段为空表示不过滤）
+type OrderCleanupQuery struct {
+	Exchange  string
+	Symbol    string
+	Reason    string
+	OrderID   int64
+	StartTime time.Time
+	EndTime   time.Time
+	Limit     int
+}
+
+// OCOLink 止盈/止损关联订单（一单成交后撤销另一单），持久化后重启可清理遗留的保护单
+type OCOLink struct {
+	LinkID             string    `json:"link_id"`
+	Exchange           string    `json:"exchange"`
+	Symbol             string    `json:"symbol"`
+	Side               string    `json:"side"` // 平仓方向（多仓止盈止损为 SELL）
+	Quantity           float64   `json:"quantity"`
+	TakeProfitPrice    float64   `json:"take_profit_price"`
+	StopPrice          float64   `json:"stop_price"`
+	TakeProfitOrderID  int64     `json:"take_profit_order_id"`
+	TakeProfitClientID string    `json:"take_profit_client_id"`
+	StopOrderID        int64     `json:"stop_order_id"` // 原生 OCO 的止损单，模拟模式下为触发后的市价平仓单
+	Native             bool      `json:"native"`        // 是否为交易所原生 OCO
+	Status             string    `json:"status"`        // active / take_profit / stopped / canceled
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// JournalQuery 交易日志查询条件（字段为空表示不过滤）
+type JournalQuery struct {
+	Exchange  string
+	Symbol    string
+	Strategy  string
+	Source    string
+	StartTime time.Time
+	EndTime   time.Time
+	Limit     int
+}
@@ -0,0 +1,377 @@
+package storage
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"time"
+
+	"quantmesh/logger"
+	"quantmesh/storage/migrate"
+)
+
+// sqliteMigrations SQLite 结构迁移脚本（新增结构变更时在目录中追加 <版本号>_<名称>.up.sql / .down.sql）
+//
+//go:embed migrations/sqlite/*.sql
+var sqliteMigrations embed.FS
+
+// OpenSQLite 打开 SQLite 数据库（不执行迁移）
+func OpenSQLite(path string) (*sql.DB, error) {
+	// 使用 WAL 模式提高并发性能
+	db, err := sql.Open("sqlite3", path+"?_journal_mode=WAL&_synchronous=NORMAL")
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+
+	// 设置连接池
+	db.SetMaxOpenConns(1) // SQLite 并发限制
+	db.SetMaxIdleConns(1)
+	return db, nil
+}
+
+// NewSQLiteMigrator 创建 SQLite 迁移执行器，迁移前把数据库备份到 path 同目录
+func NewSQLiteMigrator(db *sql.DB, path string) (*migrate.Migrator, error) {
+	migrations, err := migrate.Load(sqliteMigrations, "migrations/sqlite")
+	if err != nil {
+		return nil, err
+	}
+	m := migrate.New(db, migrate.SQLite, migrations)
+	m.SetBackup(func(fromVersion int) (string, error) {
+		return backupSQLite(db, path, fromVersion)
+	})
+	return m, nil
+}
+
+// MigrateSQLite 把数据库升级到最新结构
+// 引入版本化迁移之前创建的数据库（有业务表但没有 schema_version）先备份，再用旧的补列逻辑升级到基线结构
+func MigrateSQLite(db *sql.DB, path string) error {
+	m, err := NewSQLiteMigrator(db, path)
+	if err != nil {
+		return err
+	}
+
+	tables, err := existingTables(db)
+	if err != nil {
+		return err
+	}
+	if !tables["schema_version"] && tables["orders"] {
+		location, err := backupSQLite(db, path, 0)
+		if err != nil {
+			return fmt.Errorf("升级旧版数据库前备份失败: %w", err)
+		}
+		logger.Info("💾 [数据库迁移] 检测到旧版数据库，已备份到 %s", location)
+		if err := upgradeLegacySchema(db, tables); err != nil {
+			return fmt.Errorf("升级旧版数据库失败: %w", err)
+		}
+	}
+
+	_, err = m.Up()
+	return err
+}
+
+// backupSQLite 使用 VACUUM INTO 生成一致的数据库快照（内存数据库不备份）
+func backupSQLite(db *sql.DB, path string, fromVersion int) (string, error) {
+	if path == "" || path == ":memory:" {
+		return "", nil
+	}
+	backupPath := fmt.Sprintf("%s.v%d-%s.bak", path, fromVersion, time.Now().Format("20060102-150405"))
+	if _, err := db.Exec(`VACUUM INTO ?`, backupPath); err != nil {
+		return "", err
+	}
+	return backupPath, nil
+}
+
+// existingTables 列出数据库中已有的表
+func existingTables(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type = 'table'`)
+	if err != nil {
+		return nil, fmt.Errorf("查询表列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables[name] = true
+	}
+	return tables, rows.Err()
+}
+
+// upgradeLegacySchema 为旧版数据库中已存在的表补齐基线结构需要的列，缺失的表和索引由基线迁移创建
+func upgradeLegacySchema(db *sql.DB, tables map[string]bool) error {
+	if tables["reconciliation_history"] {
+		// 为已存在的表添加 actual_profit 字段（如果不存在）
+		if err := migrateReconciliationHistory(db); err != nil {
+			return fmt.Errorf("迁移对账历史表失败: %w", err)
+		}
+	}
+	if tables["events"] {
+		// 为 events 表添加 event_type 字段（如果不存在）
+		if err := migrateEventsTable(db); err != nil {
+			return fmt.Errorf("迁移事件表失败: %w", err)
+		}
+	}
+	if tables["trades"] {
+		// 添加 exchange 字段（如果不存在）
+		if err := migrateTradesTable(db); err != nil {
+			return fmt.Errorf("迁移 trades 表失败: %w", err)
+		}
+	}
+	// 订单/持仓/统计/对账历史按交易所+交易对隔离
+	if err := migrateSymbolIsolation(db, tables); err != nil {
+		return fmt.Errorf("迁移交易所/交易对字段失败: %w", err)
+	}
+	return nil
+}
+
+// ordersTableSQL 订单表（订单号只在同一交易所内唯一），旧库重建 orders 表时使用，需与基线迁移保持一致
+const ordersTableSQL = `
+	CREATE TABLE IF NOT EXISTS orders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		order_id BIGINT,
+		client_order_id TEXT,
+		exchange TEXT,
+		symbol TEXT,
+		side TEXT,
+		price DECIMAL(20,8),
+		quantity DECIMAL(20,8),
+		status TEXT,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP,
+		UNIQUE(exchange, order_id)
+	);`
+
+// statisticsTableSQL 统计表（按交易所+交易对+日期唯一），旧库重建 statistics 表时使用，需与基线迁移保持一致
+const statisticsTableSQL = `
+	CREATE TABLE IF NOT EXISTS statistics (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		exchange TEXT,
+		symbol TEXT,
+		date DATE,
+		total_trades INTEGER,
+		total_volume DECIMAL(20,8),
+		total_pnl DECIMAL(20,8),
+		win_rate DECIMAL(5,2),
+		created_at TIMESTAMP,
+		UNIQUE(exchange, symbol, date)
+	);`
+
+// migrateEventsTable 迁移 events 表，添加 event_type 字段
+func migrateEventsTable(db *sql.DB) error {
+	row := db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('events') 
+		WHERE name='event_type'
+	`)
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return err
+	}
+
+	if count == 0 {
+		logger.Info("🔄 [数据库] 为 events 表添加 event_type 列...")
+		_, err := db.Exec(`ALTER TABLE events ADD COLUMN event_type TEXT`)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateReconciliationHistory 迁移对账历史表，添加 actual_profit 和 created_at 字段（如果不存在）
+func migrateReconciliationHistory(db *sql.DB) error {
+	// 检查 actual_profit 字段是否存在
+	row := db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('reconciliation_history') 
+		WHERE name='actual_profit'
+	`)
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return err
+	}
+
+	// 如果字段不存在，添加它
+	if count == 0 {
+		_, err := db.Exec(`
+			ALTER TABLE reconciliation_history 
+			ADD COLUMN actual_profit DECIMAL(20,8) DEFAULT 0
+		`)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 检查 created_at 字段是否存在
+	row = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('reconciliation_history') 
+		WHERE name='created_at'
+	`)
+	if err := row.Scan(&count); err != nil {
+		return err
+	}
+
+	// 如果字段不存在，添加它
+	if count == 0 {
+		_, err := db.Exec(`
+			ALTER TABLE reconciliation_history 
+			ADD COLUMN created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		`)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateTradesTable 迁移 trades 表，添加 exchange 字段
+func migrateTradesTable(db *sql.DB) error {
+	logger.Info("🔧 开始检查 trades 表结构...")
+
+	// 检查 exchange 列是否存在
+	rows, err := db.Query(`PRAGMA table_info(trades)`)
+	if err != nil {
+		return fmt.Errorf("检查表结构失败: %w", err)
+	}
+	defer rows.Close()
+
+	hasExchangeColumn := false
+	for rows.Next() {
+		var cid int
+		var name string
+		var dataType string
+		var notNull int
+		var defaultValue interface{}
+		var pk int
+
+		if err := rows.Scan(&cid, &name, &dataType, &notNull, &defaultValue, &pk); err != nil {
+			continue
+		}
+		if name == "exchange" {
+			hasExchangeColumn = true
+			break
+		}
+	}
+
+	if !hasExchangeColumn {
+		logger.Info("🔄 开始迁移 trades 表：添加 exchange 字段")
+
+		// 添加 exchange 列
+		logger.Info("🔧 添加 exchange 列...")
+		_, err := db.Exec(`ALTER TABLE trades ADD COLUMN exchange TEXT`)
+		if err != nil {
+			return fmt.Errorf("添加 exchange 列失败: %w", err)
+		}
+		logger.Info("✅ exchange 列添加成功")
+
+		// 更新现有数据
+		logger.Info("🔧 更新历史数据...")
+		result, err := db.Exec(`UPDATE trades SET exchange = 'binance' WHERE exchange IS NULL`)
+		if err != nil {
+			return fmt.Errorf("更新现有数据失败: %w", err)
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		logger.Info("✅ 迁移完成：已更新 %d 条历史交易记录的 exchange 字段为 binance", rowsAffected)
+
+		// 创建索引
+		logger.Info("🔧 创建索引...")
+		_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_trades_exchange_symbol ON trades(exchange, symbol)`)
+		if err != nil {
+			logger.Warn("⚠️ 创建索引失败: %v", err)
+		} else {
+			logger.Info("✅ 索引创建成功")
+		}
+	} else {
+		logger.Info("✅ exchange 列已存在，跳过迁移")
+	}
+
+	logger.Info("✅ trades 表迁移检查完成")
+	return nil
+}
+
+// migrateSymbolIsolation 为订单、持仓、统计和对账历史补齐 exchange/symbol 字段
+// 旧数据没有交易所信息，与 trades 表迁移保持一致，默认记为 binance
+func migrateSymbolIsolation(db *sql.DB, tables map[string]bool) error {
+	// orders 的唯一约束从 order_id 改为 (exchange, order_id)，SQLite 不支持修改约束，需要重建表
+	hasExchange, err := columnExists(db, "orders", "exchange")
+	if err != nil {
+		return err
+	}
+	if tables["orders"] && !hasExchange {
+		logger.Info("🔄 [数据库] 重建 orders 表：添加 exchange 字段")
+		if err := rebuildTable(db, "orders", ordersTableSQL,
+			`INSERT INTO orders (order_id, client_order_id, exchange, symbol, side, price, quantity, status, created_at, updated_at)
+			 SELECT order_id, client_order_id, 'binance', symbol, side, price, quantity, status, created_at, updated_at FROM orders_old`); err != nil {
+			return fmt.Errorf("重建 orders 表失败: %w", err)
+		}
+	}
+
+	// statistics 的唯一约束从 date 改为 (exchange, symbol, date)
+	// 迁移时拿不到配置的交易对，旧行 symbol 留空，由 QueryStatistics 在按交易对查询时一并返回
+	hasExchange, err = columnExists(db, "statistics", "exchange")
+	if err != nil {
+		return err
+	}
+	if tables["statistics"] && !hasExchange {
+		logger.Info("🔄 [数据库] 重建 statistics 表：添加 exchange、symbol 字段")
+		if err := rebuildTable(db, "statistics", statisticsTableSQL,
+			`INSERT INTO statistics (exchange, symbol, date, total_trades, total_volume, total_pnl, win_rate, created_at)
+			 SELECT 'binance', '', date, total_trades, total_volume, total_pnl, win_rate, created_at FROM statistics_old`); err != nil {
+			return fmt.Errorf("重建 statistics 表失败: %w", err)
+		}
+	}
+
+	for _, table := range []string{"positions", "reconciliation_history"} {
+		hasExchange, err := columnExists(db, table, "exchange")
+		if err != nil {
+			return err
+		}
+		if !tables[table] || hasExchange {
+			continue
+		}
+		logger.Info("🔄 [数据库] 为 %s 表添加 exchange 列...", table)
+		if _, err := db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN exchange TEXT`, table)); err != nil {
+			return fmt.Errorf("为 %s 添加 exchange 列失败: %w", table, err)
+		}
+		if _, err := db.Exec(fmt.Sprintf(`UPDATE %s SET exchange = 'binance' WHERE exchange IS NULL`, table)); err != nil {
+			return fmt.Errorf("更新 %s 历史数据失败: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// columnExists 检查表中是否存在指定列
+func columnExists(db *sql.DB, table, column string) (bool, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("检查 %s 表结构失败: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+// rebuildTable 在事务中重建表：旧表改名为 <table>_old，按新结构建表后用 copySQL 复制数据，再删除旧表
+func rebuildTable(db *sql.DB, table, createSQL, copySQL string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmts := []string{
+		fmt.Sprintf(`ALTER TABLE %s RENAME TO %s_old`, table, table),
+		createSQL,
+		copySQL,
+		fmt.Sprintf(`DROP TABLE %s_old`, table),
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// snapshotStepPages 在线备份每步复制的页数，步与步之间检查是否已取消
+const snapshotStepPages = 1024
+
+// Snapshotter 支持导出一致性快照的存储（SQLite）
+type Snapshotter interface {
+	// Snapshot 把当前数据库完整复制到 destPath（文件应不存在或为空）
+	Snapshot(ctx context.Context, destPath string) error
+}
+
+// Snapshot 使用 SQLite 在线备份 API 把数据库复制到 destPath
+// 直接复制 WAL 模式下正在写入的数据库文件会丢失未检查点的数据甚至得到损坏的文件；
+// 备份期间独占唯一的连接，其他读写排队等待，得到的快照是某一时刻的一致状态，
+// 快照改为 DELETE 日志模式，单个文件即可离线打开
+func (s *SQLiteStorage) Snapshot(ctx context.Context, destPath string) error {
+	if s.closed {
+		return fmt.Errorf("数据库已关闭")
+	}
+	destDB, err := sql.Open("sqlite3", destPath)
+	if err != nil {
+		return fmt.Errorf("创建快照文件失败: %w", err)
+	}
+	defer destDB.Close()
+
+	destConn, err := destDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("创建快照文件失败: %w", err)
+	}
+	defer destConn.Close()
+	srcConn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("获取数据库连接失败: %w", err)
+	}
+	defer srcConn.Close()
+
+	err = destConn.Raw(func(destRaw interface{}) error {
+		return srcConn.Raw(func(srcRaw interface{}) error {
+			dest, ok := destRaw.(*sqlite3.SQLiteConn)
+			src, ok2 := srcRaw.(*sqlite3.SQLiteConn)
+			if !ok || !ok2 {
+				return fmt.Errorf("不支持的数据库驱动")
+			}
+			backup, err := dest.Backup("main", src, "main")
+			if err != nil {
+				return err
+			}
+			for {
+				if err := ctx.Err(); err != nil {
+					backup.Close()
+					return err
+				}
+				done, err := backup.Step(snapshotStepPages)
+				if err != nil {
+					backup.Close()
+					return err
+				}
+				if done {
+					return backup.Finish()
+				}
+			}
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("备份数据库失败: %w", err)
+	}
+
+	if _, err := destConn.ExecContext(ctx, `PRAGMA journal_mode = DELETE`); err != nil {
+		return fmt.Errorf("设置快照日志模式失败: %w", err)
+	}
+	return nil
+}
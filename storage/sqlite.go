@@ -18,388 +18,21 @@ type SQLiteStorage struct {
 	closed bool
 }
 
-// NewSQLiteStorage 创建 SQLite 存储
+// NewSQLiteStorage 创建 SQLite 存储，打开时自动把表结构迁移到最新版本
 func NewSQLiteStorage(path string) (*SQLiteStorage, error) {
-	// 使用 WAL 模式提高并发性能
-	db, err := sql.Open("sqlite3", path+"?_journal_mode=WAL&_synchronous=NORMAL")
+	db, err := OpenSQLite(path)
 	if err != nil {
-		return nil, fmt.Errorf("打开数据库失败: %w", err)
-	}
-
-	// 设置连接池
-	db.SetMaxOpenConns(1) // SQLite 并发限制
-	db.SetMaxIdleConns(1)
-
-	// 创建表
-	if err := createTables(db); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("创建表失败: %w", err)
+		return nil, err
 	}
 
-	// 迁移：添加 exchange 字段（如果不存在）
-	if err := migrateTradesTable(db); err != nil {
+	if err := MigrateSQLite(db, path); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("迁移 trades 表失败: %w", err)
+		return nil, fmt.Errorf("迁移数据库结构失败: %w", err)
 	}
 
 	return &SQLiteStorage{db: db}, nil
 }
 
-// createTables 创建表
-func createTables(db *sql.DB) error {
-	// 订单表
-	ordersSQL := `
-	CREATE TABLE IF NOT EXISTS orders (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		order_id BIGINT UNIQUE,
-		client_order_id TEXT,
-		symbol TEXT,
-		side TEXT,
-		price DECIMAL(20,8),
-		quantity DECIMAL(20,8),
-		status TEXT,
-		created_at TIMESTAMP,
-		updated_at TIMESTAMP
-	);`
-
-	// 持仓表
-	positionsSQL := `
-	CREATE TABLE IF NOT EXISTS positions (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		slot_price DECIMAL(20,8),
-		symbol TEXT,
-		size DECIMAL(20,8),
-		entry_price DECIMAL(20,8),
-		current_price DECIMAL(20,8),
-		pnl DECIMAL(20,8),
-		opened_at TIMESTAMP,
-		closed_at TIMESTAMP
-	);`
-
-	// 交易表（买卖配对）
-	tradesSQL := `
-	CREATE TABLE IF NOT EXISTS trades (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		buy_order_id BIGINT,
-		sell_order_id BIGINT,
-		exchange TEXT,
-		symbol TEXT,
-		buy_price DECIMAL(20,8),
-		sell_price DECIMAL(20,8),
-		quantity DECIMAL(20,8),
-		pnl DECIMAL(20,8),
-		created_at TIMESTAMP
-	);
-	CREATE INDEX IF NOT EXISTS idx_trades_exchange_symbol ON trades(exchange, symbol);
-	CREATE INDEX IF NOT EXISTS idx_trades_created_at ON trades(created_at);`
-
-	// 事件表
-	eventsSQL := `
-	CREATE TABLE IF NOT EXISTS events (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		event_type TEXT,
-		data TEXT,
-		created_at TIMESTAMP
-	);`
-
-	// 系统监控细粒度数据表
-	systemMetricsSQL := `
-	CREATE TABLE IF NOT EXISTS system_metrics (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		timestamp DATETIME NOT NULL,
-		cpu_percent REAL NOT NULL,
-		memory_mb REAL NOT NULL,
-		memory_percent REAL,
-		process_id INTEGER,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	);
-	CREATE INDEX IF NOT EXISTS idx_system_metrics_timestamp ON system_metrics(timestamp);`
-
-	// 系统监控每日汇总数据表
-	dailySystemMetricsSQL := `
-	CREATE TABLE IF NOT EXISTS daily_system_metrics (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		date DATE NOT NULL UNIQUE,
-		avg_cpu_percent REAL NOT NULL,
-		max_cpu_percent REAL NOT NULL,
-		min_cpu_percent REAL NOT NULL,
-		avg_memory_mb REAL NOT NULL,
-		max_memory_mb REAL NOT NULL,
-		min_memory_mb REAL NOT NULL,
-		sample_count INTEGER NOT NULL,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	);
-	CREATE INDEX IF NOT EXISTS idx_daily_system_metrics_date ON daily_system_metrics(date);`
-
-	// 统计表
-	statisticsSQL := `
-	CREATE TABLE IF NOT EXISTS statistics (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		date DATE UNIQUE,
-		total_trades INTEGER,
-		total_volume DECIMAL(20,8),
-		total_pnl DECIMAL(20,8),
-		win_rate DECIMAL(5,2),
-		created_at TIMESTAMP
-	);`
-
-	// 对账历史表
-	reconciliationHistorySQL := `
-	CREATE TABLE IF NOT EXISTS reconciliation_history (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		symbol TEXT,
-		reconcile_time TIMESTAMP,
-		local_position DECIMAL(20,8),
-		exchange_position DECIMAL(20,8),
-		position_diff DECIMAL(20,8),
-		active_buy_orders INTEGER,
-		active_sell_orders INTEGER,
-		pending_sell_qty DECIMAL(20,8),
-		total_buy_qty DECIMAL(20,8),
-		total_sell_qty DECIMAL(20,8),
-		estimated_profit DECIMAL(20,8),
-		actual_profit DECIMAL(20,8) DEFAULT 0,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	);
-	CREATE INDEX IF NOT EXISTS idx_reconciliation_history_symbol ON reconciliation_history(symbol);
-	CREATE INDEX IF NOT EXISTS idx_reconciliation_history_time ON reconciliation_history(reconcile_time);`
-
-	// 风控检查历史表
-	riskCheckHistorySQL := `
-	CREATE TABLE IF NOT EXISTS risk_check_history (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		check_time TIMESTAMP NOT NULL,
-		symbol TEXT NOT NULL,
-		is_healthy INTEGER NOT NULL,
-		price_deviation REAL,
-		volume_ratio REAL,
-		reason TEXT,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	);
-	CREATE INDEX IF NOT EXISTS idx_risk_check_history_time ON risk_check_history(check_time);
-	CREATE INDEX IF NOT EXISTS idx_risk_check_history_symbol ON risk_check_history(symbol);
-	CREATE INDEX IF NOT EXISTS idx_risk_check_history_time_symbol ON risk_check_history(check_time, symbol);`
-
-	// 资金费率表
-	fundingRatesSQL := `
-	CREATE TABLE IF NOT EXISTS funding_rates (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		symbol TEXT NOT NULL,
-		exchange TEXT NOT NULL,
-		rate REAL NOT NULL,
-		timestamp TIMESTAMP NOT NULL,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	);
-	CREATE INDEX IF NOT EXISTS idx_funding_rates_symbol ON funding_rates(symbol);
-	CREATE INDEX IF NOT EXISTS idx_funding_rates_timestamp ON funding_rates(timestamp);
-	CREATE INDEX IF NOT EXISTS idx_funding_rates_symbol_timestamp ON funding_rates(symbol, timestamp);`
-
-	// AI提示词模板表
-	aiPromptsSQL := `
-	CREATE TABLE IF NOT EXISTS ai_prompts (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		module TEXT UNIQUE NOT NULL,
-		template TEXT NOT NULL,
-		system_prompt TEXT,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	);
-	CREATE INDEX IF NOT EXISTS idx_ai_prompts_module ON ai_prompts(module);`
-
-	// 价差数据表
-	basisDataSQL := `
-	CREATE TABLE IF NOT EXISTS basis_data (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		symbol TEXT NOT NULL,
-		exchange TEXT NOT NULL,
-		spot_price REAL NOT NULL,
-		futures_price REAL NOT NULL,
-		basis REAL NOT NULL,
-		basis_percent REAL NOT NULL,
-		funding_rate REAL,
-		timestamp DATETIME NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-	CREATE INDEX IF NOT EXISTS idx_basis_symbol_time ON basis_data(symbol, timestamp);
-	CREATE INDEX IF NOT EXISTS idx_basis_exchange ON basis_data(exchange);`
-
-	// 创建索引
-	indexesSQL := `
-	CREATE INDEX IF NOT EXISTS idx_orders_order_id ON orders(order_id);
-	CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders(created_at);
-	CREATE INDEX IF NOT EXISTS idx_positions_slot_price ON positions(slot_price);
-	CREATE INDEX IF NOT EXISTS idx_trades_created_at ON trades(created_at);
-	CREATE INDEX IF NOT EXISTS idx_trades_symbol ON trades(symbol);
-	CREATE INDEX IF NOT EXISTS idx_events_created_at ON events(created_at);
-	`
-
-	// 执行创建语句
-	sqls := []string{
-		ordersSQL,
-		positionsSQL,
-		tradesSQL,
-		eventsSQL,
-		systemMetricsSQL,
-		dailySystemMetricsSQL,
-		statisticsSQL,
-		reconciliationHistorySQL,
-		riskCheckHistorySQL,
-		fundingRatesSQL,
-		aiPromptsSQL,
-		basisDataSQL,
-		indexesSQL,
-	}
-	for _, sql := range sqls {
-		if _, err := db.Exec(sql); err != nil {
-			return fmt.Errorf("执行 SQL 失败: %w", err)
-		}
-	}
-
-	// 迁移：为已存在的表添加 actual_profit 字段（如果不存在）
-	if err := migrateReconciliationHistory(db); err != nil {
-		return fmt.Errorf("迁移对账历史表失败: %w", err)
-	}
-
-	// 迁移：为 events 表添加 event_type 字段（如果不存在）
-	if err := migrateEventsTable(db); err != nil {
-		return fmt.Errorf("迁移事件表失败: %w", err)
-	}
-
-	return nil
-}
-
-// migrateEventsTable 迁移 events 表，添加 event_type 字段
-func migrateEventsTable(db *sql.DB) error {
-	row := db.QueryRow(`
-		SELECT COUNT(*) FROM pragma_table_info('events') 
-		WHERE name='event_type'
-	`)
-	var count int
-	if err := row.Scan(&count); err != nil {
-		return err
-	}
-
-	if count == 0 {
-		logger.Info("🔄 [数据库] 为 events 表添加 event_type 列...")
-		_, err := db.Exec(`ALTER TABLE events ADD COLUMN event_type TEXT`)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-// migrateReconciliationHistory 迁移对账历史表，添加 actual_profit 和 created_at 字段（如果不存在）
-func migrateReconciliationHistory(db *sql.DB) error {
-	// 检查 actual_profit 字段是否存在
-	row := db.QueryRow(`
-		SELECT COUNT(*) FROM pragma_table_info('reconciliation_history') 
-		WHERE name='actual_profit'
-	`)
-	var count int
-	if err := row.Scan(&count); err != nil {
-		return err
-	}
-
-	// 如果字段不存在，添加它
-	if count == 0 {
-		_, err := db.Exec(`
-			ALTER TABLE reconciliation_history 
-			ADD COLUMN actual_profit DECIMAL(20,8) DEFAULT 0
-		`)
-		if err != nil {
-			return err
-		}
-	}
-
-	// 检查 created_at 字段是否存在
-	row = db.QueryRow(`
-		SELECT COUNT(*) FROM pragma_table_info('reconciliation_history') 
-		WHERE name='created_at'
-	`)
-	if err := row.Scan(&count); err != nil {
-		return err
-	}
-
-	// 如果字段不存在，添加它
-	if count == 0 {
-		_, err := db.Exec(`
-			ALTER TABLE reconciliation_history 
-			ADD COLUMN created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		`)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-// migrateTradesTable 迁移 trades 表，添加 exchange 字段
-func migrateTradesTable(db *sql.DB) error {
-	logger.Info("🔧 开始检查 trades 表结构...")
-	
-	// 检查 exchange 列是否存在
-	rows, err := db.Query(`PRAGMA table_info(trades)`)
-	if err != nil {
-		return fmt.Errorf("检查表结构失败: %w", err)
-	}
-	defer rows.Close()
-
-	hasExchangeColumn := false
-	for rows.Next() {
-		var cid int
-		var name string
-		var dataType string
-		var notNull int
-		var defaultValue interface{}
-		var pk int
-
-		if err := rows.Scan(&cid, &name, &dataType, &notNull, &defaultValue, &pk); err != nil {
-			continue
-		}
-		if name == "exchange" {
-			hasExchangeColumn = true
-			break
-		}
-	}
-
-	if !hasExchangeColumn {
-		logger.Info("🔄 开始迁移 trades 表：添加 exchange 字段")
-
-		// 添加 exchange 列
-		logger.Info("🔧 添加 exchange 列...")
-		_, err := db.Exec(`ALTER TABLE trades ADD COLUMN exchange TEXT`)
-		if err != nil {
-			return fmt.Errorf("添加 exchange 列失败: %w", err)
-		}
-		logger.Info("✅ exchange 列添加成功")
-
-		// 更新现有数据
-		logger.Info("🔧 更新历史数据...")
-		result, err := db.Exec(`UPDATE trades SET exchange = 'binance' WHERE exchange IS NULL`)
-		if err != nil {
-			return fmt.Errorf("更新现有数据失败: %w", err)
-		}
-
-		rowsAffected, _ := result.RowsAffected()
-		logger.Info("✅ 迁移完成：已更新 %d 条历史交易记录的 exchange 字段为 binance", rowsAffected)
-
-		// 创建索引
-		logger.Info("🔧 创建索引...")
-		_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_trades_exchange_symbol ON trades(exchange, symbol)`)
-		if err != nil {
-			logger.Warn("⚠️ 创建索引失败: %v", err)
-		} else {
-			logger.Info("✅ 索引创建成功")
-		}
-	} else {
-		logger.Info("✅ exchange 列已存在，跳过迁移")
-	}
-
-	logger.Info("✅ trades 表迁移检查完成")
-	return nil
-}
-
 // SaveOrder 保存订单
 func (s *SQLiteStorage) SaveOrder(order *Order) error {
 	// 转换为UTC时间存储
@@ -407,9 +40,9 @@ func (s *SQLiteStorage) SaveOrder(order *Order) error {
 	updatedAt := utils.ToUTC(order.UpdatedAt)
 	_, err := s.db.Exec(`
 		INSERT OR REPLACE INTO orders 
-		(order_id, client_order_id, symbol, side, price, quantity, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, order.OrderID, order.ClientOrderID, order.Symbol, order.Side,
+		(order_id, client_order_id, exchange, symbol, side, price, quantity, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, order.OrderID, order.ClientOrderID, exchangeOrDefault(order.Exchange), order.Symbol, order.Side,
 		order.Price, order.Quantity, order.Status, createdAt, updatedAt)
 	return err
 }
@@ -426,9 +59,9 @@ func (s *SQLiteStorage) SavePosition(position *Position) error {
 
 	_, err := s.db.Exec(`
 		INSERT INTO positions 
-		(slot_price, symbol, size, entry_price, current_price, pnl, opened_at, closed_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, position.SlotPrice, position.Symbol, position.Size,
+		(slot_price, exchange, symbol, size, entry_price, current_price, pnl, opened_at, closed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, position.SlotPrice, exchangeOrDefault(position.Exchange), position.Symbol, position.Size,
 		position.EntryPrice, position.CurrentPrice, position.PnL,
 		openedAt, closedAt)
 	return err
@@ -438,16 +71,11 @@ func (s *SQLiteStorage) SavePosition(position *Position) error {
 func (s *SQLiteStorage) SaveTrade(trade *Trade) error {
 	// 转换为UTC时间存储
 	createdAt := utils.ToUTC(trade.CreatedAt)
-	// 确保 exchange 不为空，默认为 binance（兼容旧数据）
-	exchange := trade.Exchange
-	if exchange == "" {
-		exchange = "binance"
-	}
 	_, err := s.db.Exec(`
 		INSERT INTO trades 
 		(buy_order_id, sell_order_id, exchange, symbol, buy_price, sell_price, quantity, pnl, created_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, trade.BuyOrderID, trade.SellOrderID, exchange, trade.Symbol,
+	`, trade.BuyOrderID, trade.SellOrderID, exchangeOrDefault(trade.Exchange), trade.Symbol,
 		trade.BuyPrice, trade.SellPrice, trade.Quantity, trade.PnL, createdAt)
 	return err
 }
@@ -505,6 +133,11 @@ func (s *SQLiteStorage) SaveEvent(eventType string, data map[string]interface{})
 
 // saveSystemMetricsFromMap 从 map 保存系统监控数据
 func (s *SQLiteStorage) saveSystemMetricsFromMap(data map[string]interface{}) error {
+	return s.SaveSystemMetrics(systemMetricsFromMap(data))
+}
+
+// systemMetricsFromMap 从事件数据解析系统监控数据
+func systemMetricsFromMap(data map[string]interface{}) *SystemMetrics {
 	metrics := &SystemMetrics{}
 
 	if timestamp, ok := data["timestamp"].(time.Time); ok {
@@ -536,7 +169,7 @@ func (s *SQLiteStorage) saveSystemMetricsFromMap(data map[string]interface{}) er
 		metrics.ProcessID = int(processID)
 	}
 
-	return s.SaveSystemMetrics(metrics)
+	return metrics
 }
 
 // SaveStatistics 保存统计
@@ -546,15 +179,15 @@ func (s *SQLiteStorage) SaveStatistics(stats *Statistics) error {
 	createdAt := utils.ToUTC(stats.CreatedAt)
 	_, err := s.db.Exec(`
 		INSERT OR REPLACE INTO statistics 
-		(date, total_trades, total_volume, total_pnl, win_rate, created_at)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, date, stats.TotalTrades, stats.TotalVolume,
+		(exchange, symbol, date, total_trades, total_volume, total_pnl, win_rate, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, exchangeOrDefault(stats.Exchange), stats.Symbol, date, stats.TotalTrades, stats.TotalVolume,
 		stats.TotalPnL, stats.WinRate, createdAt)
 	return err
 }
 
-// QueryOrders 查询订单
-func (s *SQLiteStorage) QueryOrders(limit, offset int, status string) ([]*Order, error) {
+// QueryOrders 查询订单（exchange/symbol 为空表示不过滤）
+func (s *SQLiteStorage) QueryOrders(exchange, symbol string, limit, offset int, status string) ([]*Order, error) {
 	// 限制最大返回数量，防止内存占用过大
 	maxLimit := 10000 // 最多返回1万条订单
 	if limit <= 0 {
@@ -566,11 +199,12 @@ func (s *SQLiteStorage) QueryOrders(limit, offset int, status string) ([]*Order,
 	}
 	
 	query := `
-		SELECT order_id, client_order_id, symbol, side, price, quantity, status, created_at, updated_at
+		SELECT order_id, client_order_id, exchange, symbol, side, price, quantity, status, created_at, updated_at
 		FROM orders
 		WHERE 1=1
 	`
-	args := []interface{}{}
+	scope, args := scopeFilter(exchange, symbol)
+	query += scope
 
 	if status != "" {
 		query += " AND status = ?"
@@ -592,6 +226,7 @@ func (s *SQLiteStorage) QueryOrders(limit, offset int, status string) ([]*Order,
 		err := rows.Scan(
 			&order.OrderID,
 			&order.ClientOrderID,
+			&order.Exchange,
 			&order.Symbol,
 			&order.Side,
 			&order.Price,
@@ -609,8 +244,8 @@ func (s *SQLiteStorage) QueryOrders(limit, offset int, status string) ([]*Order,
 	return orders, nil
 }
 
-// QueryTrades 查询交易
-func (s *SQLiteStorage) QueryTrades(startTime, endTime time.Time, limit, offset int) ([]*Trade, error) {
+// QueryTrades 查询交易（exchange/symbol 为空表示不过滤）
+func (s *SQLiteStorage) QueryTrades(exchange, symbol string, startTime, endTime time.Time, limit, offset int) ([]*Trade, error) {
 	// 限制最大返回数量，防止内存占用过大
 	maxLimit := 10000 // 最多返回1万条交易
 	if limit <= 0 {
@@ -621,13 +256,16 @@ func (s *SQLiteStorage) QueryTrades(startTime, endTime time.Time, limit, offset
 		logger.Warn("⚠️ 交易查询 limit 超过限制 (%d)，已限制为 %d", limit, maxLimit)
 	}
 	
+	scope, scopeArgs := scopeFilter(exchange, symbol)
+	args := append([]interface{}{startTime, endTime}, scopeArgs...)
+	args = append(args, limit, offset)
 	rows, err := s.db.Query(`
 		SELECT buy_order_id, sell_order_id, exchange, symbol, buy_price, sell_price, quantity, pnl, created_at
 		FROM trades
-		WHERE created_at >= ? AND created_at <= ?
+		WHERE created_at >= ? AND created_at <= ?`+scope+`
 		ORDER BY created_at DESC
 		LIMIT ? OFFSET ?
-	`, startTime, endTime, limit, offset)
+	`, args...)
 	if err != nil {
 		return nil, fmt.Errorf("查询交易失败: %w", err)
 	}
@@ -660,18 +298,22 @@ func (s *SQLiteStorage) QueryTrades(startTime, endTime time.Time, limit, offset
 	return trades, nil
 }
 
-// QueryStatistics 查询统计数据
-func (s *SQLiteStorage) QueryStatistics(startDate, endDate time.Time) ([]*Statistics, error) {
+// QueryStatistics 查询统计数据（exchange/symbol 为空表示不过滤）
+// 按交易对查询时包含迁移前的旧统计（symbol 为空），旧版本只运行单个交易对，这些数据不能因为缺少交易对而消失
+func (s *SQLiteStorage) QueryStatistics(exchange, symbol string, startDate, endDate time.Time) ([]*Statistics, error) {
 	// 限制最大返回数量，防止内存占用过大
 	maxStats := 10000 // 最多返回1万条统计数据
 	
+	scope, scopeArgs := statisticsScopeFilter(exchange, symbol)
+	args := append([]interface{}{startDate, endDate}, scopeArgs...)
+	args = append(args, maxStats)
 	rows, err := s.db.Query(`
-		SELECT date, total_trades, total_volume, total_pnl, win_rate, created_at
+		SELECT exchange, symbol, date, total_trades, total_volume, total_pnl, win_rate, created_at
 		FROM statistics
-		WHERE date >= ? AND date <= ?
+		WHERE date >= ? AND date <= ?`+scope+`
 		ORDER BY date DESC
 		LIMIT ?
-	`, startDate, endDate, maxStats)
+	`, args...)
 	if err != nil {
 		return nil, fmt.Errorf("查询统计数据失败: %w", err)
 	}
@@ -680,7 +322,10 @@ func (s *SQLiteStorage) QueryStatistics(startDate, endDate time.Time) ([]*Statis
 	var stats []*Statistics
 	for rows.Next() {
 		stat := &Statistics{}
+		var exchangeName, symbolName sql.NullString
 		err := rows.Scan(
+			&exchangeName,
+			&symbolName,
 			&stat.Date,
 			&stat.TotalTrades,
 			&stat.TotalVolume,
@@ -691,6 +336,8 @@ func (s *SQLiteStorage) QueryStatistics(startDate, endDate time.Time) ([]*Statis
 		if err != nil {
 			continue
 		}
+		stat.Exchange = exchangeName.String
+		stat.Symbol = symbolName.String
 		stats = append(stats, stat)
 	}
 
@@ -704,6 +351,11 @@ func (s *SQLiteStorage) GetStatisticsSummary() (*Statistics, error) {
 
 // GetStatisticsSummaryByExchange 获取指定交易所的统计汇总
 func (s *SQLiteStorage) GetStatisticsSummaryByExchange(exchange string) (*Statistics, error) {
+	return s.GetStatisticsSummaryBySymbol(exchange, "")
+}
+
+// GetStatisticsSummaryBySymbol 获取指定交易所+交易对的统计汇总（exchange/symbol 为空表示不过滤）
+func (s *SQLiteStorage) GetStatisticsSummaryBySymbol(exchange, symbol string) (*Statistics, error) {
 	query := `
 		SELECT 
 			COUNT(*) as total_trades,
@@ -715,16 +367,12 @@ func (s *SQLiteStorage) GetStatisticsSummaryByExchange(exchange string) (*Statis
 				ELSE 0
 			END as win_rate
 		FROM trades
+		WHERE 1=1
 	`
-	var row *sql.Row
-	if exchange != "" {
-		query += " WHERE exchange = ?"
-		row = s.db.QueryRow(query, exchange)
-	} else {
-		row = s.db.QueryRow(query)
-	}
+	scope, args := scopeFilter(exchange, symbol)
+	row := s.db.QueryRow(query+scope, args...)
 
-	stat := &Statistics{}
+	stat := &Statistics{Exchange: exchange, Symbol: symbol}
 	var totalTrades sql.NullInt64
 	var totalVolume sql.NullFloat64
 	var totalPnL sql.NullFloat64
@@ -761,6 +409,11 @@ func (s *SQLiteStorage) QueryDailyStatisticsFromTrades(startDate, endDate time.T
 
 // QueryDailyStatisticsByExchange 从 trades 表查询指定交易所的每日统计
 func (s *SQLiteStorage) QueryDailyStatisticsByExchange(exchange string, startDate, endDate time.Time) ([]*DailyStatisticsWithTradeCount, error) {
+	return s.QueryDailyStatisticsBySymbol(exchange, "", startDate, endDate)
+}
+
+// QueryDailyStatisticsBySymbol 从 trades 表查询指定交易所+交易对的每日统计（exchange/symbol 为空表示不过滤）
+func (s *SQLiteStorage) QueryDailyStatisticsBySymbol(exchange, symbol string, startDate, endDate time.Time) ([]*DailyStatisticsWithTradeCount, error) {
 	// 限制最大返回数量，防止内存占用过大（分组后的结果通常不会太多，但还是要限制）
 	maxLimit := 3650 // 最多返回10年的每日统计（3650天）
 	
@@ -784,11 +437,9 @@ func (s *SQLiteStorage) QueryDailyStatisticsByExchange(exchange string, startDat
 		FROM trades
 		WHERE date(created_at) >= ? AND date(created_at) <= ?
 	`
-	args := []interface{}{startDateStr, endDateStr}
-	if exchange != "" {
-		query += " AND exchange = ?"
-		args = append(args, exchange)
-	}
+	scope, scopeArgs := scopeFilter(exchange, symbol)
+	query += scope
+	args := append([]interface{}{startDateStr, endDateStr}, scopeArgs...)
 	query += " GROUP BY date(created_at) ORDER BY date DESC LIMIT ?"
 	args = append(args, maxLimit)
 
@@ -839,10 +490,56 @@ func (s *SQLiteStorage) QueryDailyStatisticsByExchange(exchange string, startDat
 		if losingTrades.Valid {
 			stat.LosingTrades = int(losingTrades.Int64)
 		}
+		stat.NetPnL = stat.TotalPnL
 
 		stats = append(stats, stat)
 	}
 
+	// 合并每日资金费；只有资金费没有成交的日期也需要列出
+	fundingQuery := `
+		SELECT date(payment_time) as date, COALESCE(SUM(amount), 0)
+		FROM funding_payments
+		WHERE date(payment_time) >= ? AND date(payment_time) <= ?
+	`
+	fundingQuery += scope
+	fundingArgs := append([]interface{}{startDateStr, endDateStr}, scopeArgs...)
+	fundingQuery += " GROUP BY date(payment_time)"
+
+	fundingRows, err := s.db.Query(fundingQuery, fundingArgs...)
+	if err != nil {
+		logger.Warn("⚠️ 查询每日资金费失败: %v", err)
+		return stats, nil
+	}
+	defer fundingRows.Close()
+
+	statByDate := make(map[string]*DailyStatisticsWithTradeCount, len(stats))
+	for _, stat := range stats {
+		statByDate[stat.Date.Format("2006-01-02")] = stat
+	}
+	appended := false
+	for fundingRows.Next() {
+		var dateStr string
+		var fee float64
+		if err := fundingRows.Scan(&dateStr, &fee); err != nil {
+			continue
+		}
+		stat, ok := statByDate[dateStr]
+		if !ok {
+			date, err := time.Parse("2006-01-02", dateStr)
+			if err != nil {
+				continue
+			}
+			stat = &DailyStatisticsWithTradeCount{Date: date}
+			stats = append(stats, stat)
+			appended = true
+		}
+		stat.FundingFee = fee
+		stat.NetPnL = stat.TotalPnL + fee
+	}
+	if appended {
+		sort.Slice(stats, func(i, j int) bool { return stats[i].Date.After(stats[j].Date) })
+	}
+
 	return stats, nil
 }
 
@@ -853,18 +550,18 @@ func (s *SQLiteStorage) SaveReconciliationHistory(history *ReconciliationHistory
 	createdAt := utils.ToUTC(history.CreatedAt)
 	_, err := s.db.Exec(`
 		INSERT INTO reconciliation_history 
-		(symbol, reconcile_time, local_position, exchange_position, position_diff,
+		(exchange, symbol, reconcile_time, local_position, exchange_position, position_diff,
 		 active_buy_orders, active_sell_orders, pending_sell_qty,
 		 total_buy_qty, total_sell_qty, estimated_profit, actual_profit, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, history.Symbol, reconcileTime, history.LocalPosition, history.ExchangePosition,
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, exchangeOrDefault(history.Exchange), history.Symbol, reconcileTime, history.LocalPosition, history.ExchangePosition,
 		history.PositionDiff, history.ActiveBuyOrders, history.ActiveSellOrders,
 		history.PendingSellQty, history.TotalBuyQty, history.TotalSellQty, history.EstimatedProfit, history.ActualProfit, createdAt)
 	return err
 }
 
-// QueryReconciliationHistory 查询对账历史
-func (s *SQLiteStorage) QueryReconciliationHistory(symbol string, startTime, endTime time.Time, limit, offset int) ([]*ReconciliationHistory, error) {
+// QueryReconciliationHistory 查询对账历史（exchange/symbol 为空表示不过滤）
+func (s *SQLiteStorage) QueryReconciliationHistory(exchange, symbol string, startTime, endTime time.Time, limit, offset int) ([]*ReconciliationHistory, error) {
 	// 限制最大返回数量，防止内存占用过大
 	maxLimit := 10000 // 最多返回1万条对账记录
 	if limit <= 0 {
@@ -876,18 +573,15 @@ func (s *SQLiteStorage) QueryReconciliationHistory(symbol string, startTime, end
 	}
 	
 	query := `
-		SELECT id, symbol, reconcile_time, local_position, exchange_position, position_diff,
+		SELECT id, exchange, symbol, reconcile_time, local_position, exchange_position, position_diff,
 		       active_buy_orders, active_sell_orders, pending_sell_qty,
 		       total_buy_qty, total_sell_qty, estimated_profit, actual_profit, created_at
 		FROM reconciliation_history
 		WHERE reconcile_time >= ? AND reconcile_time <= ?
 	`
-	args := []interface{}{startTime, endTime}
-
-	if symbol != "" {
-		query += " AND symbol = ?"
-		args = append(args, symbol)
-	}
+	scope, scopeArgs := scopeFilter(exchange, symbol)
+	query += scope
+	args := append([]interface{}{startTime, endTime}, scopeArgs...)
 
 	query += " ORDER BY reconcile_time DESC LIMIT ? OFFSET ?"
 	args = append(args, limit, offset)
@@ -901,8 +595,10 @@ func (s *SQLiteStorage) QueryReconciliationHistory(symbol string, startTime, end
 	var histories []*ReconciliationHistory
 	for rows.Next() {
 		h := &ReconciliationHistory{}
+		var exchangeName sql.NullString
 		err := rows.Scan(
 			&h.ID,
+			&exchangeName,
 			&h.Symbol,
 			&h.ReconcileTime,
 			&h.LocalPosition,
@@ -920,29 +616,32 @@ func (s *SQLiteStorage) QueryReconciliationHistory(symbol string, startTime, end
 		if err != nil {
 			continue
 		}
+		h.Exchange = exchangeName.String
 		histories = append(histories, h)
 	}
 
 	return histories, nil
 }
 
-// GetLatestReconciliationHistory 获取指定币种的最新对账记录
-func (s *SQLiteStorage) GetLatestReconciliationHistory(symbol string) (*ReconciliationHistory, error) {
+// GetLatestReconciliationHistory 获取指定交易所+币种的最新对账记录（exchange 为空表示不区分交易所）
+func (s *SQLiteStorage) GetLatestReconciliationHistory(exchange, symbol string) (*ReconciliationHistory, error) {
 	query := `
-		SELECT id, symbol, reconcile_time, local_position, exchange_position, position_diff,
+		SELECT id, exchange, symbol, reconcile_time, local_position, exchange_position, position_diff,
 		       active_buy_orders, active_sell_orders, pending_sell_qty,
 		       total_buy_qty, total_sell_qty, estimated_profit, actual_profit, created_at
 		FROM reconciliation_history
 		WHERE symbol = ?
-		ORDER BY reconcile_time DESC
-		LIMIT 1
 	`
+	scope, scopeArgs := scopeFilter(exchange, "")
+	query += scope + " ORDER BY reconcile_time DESC LIMIT 1"
 
-	row := s.db.QueryRow(query, symbol)
+	row := s.db.QueryRow(query, append([]interface{}{symbol}, scopeArgs...)...)
 	h := &ReconciliationHistory{}
+	var exchangeName sql.NullString
 
 	err := row.Scan(
 		&h.ID,
+		&exchangeName,
 		&h.Symbol,
 		&h.ReconcileTime,
 		&h.LocalPosition,
@@ -964,16 +663,18 @@ func (s *SQLiteStorage) GetLatestReconciliationHistory(symbol string) (*Reconcil
 		}
 		return nil, fmt.Errorf("查询最新对账记录失败: %w", err)
 	}
+	h.Exchange = exchangeName.String
 
 	return h, nil
 }
 
-// GetReconciliationCount 获取指定币种的对账次数（统计历史记录数量）
-func (s *SQLiteStorage) GetReconciliationCount(symbol string) (int64, error) {
-	query := `SELECT COUNT(*) FROM reconciliation_history WHERE symbol = ?`
+// GetReconciliationCount 获取指定交易所+币种的对账次数（统计历史记录数量，exchange 为空表示不区分交易所）
+func (s *SQLiteStorage) GetReconciliationCount(exchange, symbol string) (int64, error) {
+	scope, scopeArgs := scopeFilter(exchange, "")
+	query := `SELECT COUNT(*) FROM reconciliation_history WHERE symbol = ?` + scope
 
 	var count int64
-	err := s.db.QueryRow(query, symbol).Scan(&count)
+	err := s.db.QueryRow(query, append([]interface{}{symbol}, scopeArgs...)...).Scan(&count)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, nil // 没有记录，返回 0
@@ -984,8 +685,9 @@ func (s *SQLiteStorage) GetReconciliationCount(symbol string) (int64, error) {
 	return count, nil
 }
 
-// GetPnLBySymbol 按币种对查询盈亏数据
-func (s *SQLiteStorage) GetPnLBySymbol(symbol string, startTime, endTime time.Time) (*PnLSummary, error) {
+// GetPnLBySymbol 按币种对查询盈亏数据（exchange 为空表示汇总所有交易所）
+func (s *SQLiteStorage) GetPnLBySymbol(exchange, symbol string, startTime, endTime time.Time) (*PnLSummary, error) {
+	scope, scopeArgs := scopeFilter(exchange, "")
 	row := s.db.QueryRow(`
 		SELECT 
 			COUNT(*) as total_trades,
@@ -994,8 +696,8 @@ func (s *SQLiteStorage) GetPnLBySymbol(symbol string, startTime, endTime time.Ti
 			SUM(CASE WHEN pnl > 0 THEN 1 ELSE 0 END) as winning_trades,
 			SUM(CASE WHEN pnl < 0 THEN 1 ELSE 0 END) as losing_trades
 		FROM trades
-		WHERE symbol = ? AND created_at >= ? AND created_at <= ?
-	`, symbol, startTime, endTime)
+		WHERE symbol = ? AND created_at >= ? AND created_at <= ?`+scope,
+		append([]interface{}{symbol, startTime, endTime}, scopeArgs...)...)
 
 	summary := &PnLSummary{
 		Symbol: symbol,
@@ -1035,6 +737,16 @@ func (s *SQLiteStorage) GetPnLBySymbol(symbol string, startTime, endTime time.Ti
 		summary.WinRate = float64(summary.WinningTrades) / float64(summary.TotalTrades)
 	}
 
+	// 资金费计入净盈亏
+	var fundingFee sql.NullFloat64
+	if err := s.db.QueryRow(`
+		SELECT SUM(amount) FROM funding_payments
+		WHERE symbol = ? AND payment_time >= ? AND payment_time <= ?`+scope,
+		append([]interface{}{symbol, startTime, endTime}, scopeArgs...)...).Scan(&fundingFee); err == nil && fundingFee.Valid {
+		summary.FundingFee = fundingFee.Float64
+	}
+	summary.NetPnL = summary.TotalPnL + summary.FundingFee
+
 	return summary, nil
 }
 
@@ -1090,16 +802,37 @@ func (s *SQLiteStorage) GetPnLByTimeRange(startTime, endTime time.Time) ([]*PnLB
 		results = append(results, r)
 	}
 
+	// 资金费计入净盈亏；只有资金费没有成交的交易对也需要列出
+	fees, err := s.fundingFeeByExchangeSymbol(startTime, endTime)
+	if err != nil {
+		logger.Warn("⚠️ 查询资金费汇总失败: %v", err)
+	}
+	for _, r := range results {
+		key := [2]string{r.Exchange, r.Symbol}
+		r.FundingFee = fees[key]
+		r.NetPnL = r.TotalPnL + r.FundingFee
+		delete(fees, key)
+	}
+	for key, fee := range fees {
+		results = append(results, &PnLBySymbol{
+			Exchange:   key[0],
+			Symbol:     key[1],
+			FundingFee: fee,
+			NetPnL:     fee,
+		})
+	}
+
 	return results, nil
 }
 
-// GetActualProfitBySymbol 计算指定币种在指定时间之前的累计实际盈利
-func (s *SQLiteStorage) GetActualProfitBySymbol(symbol string, beforeTime time.Time) (float64, error) {
+// GetActualProfitBySymbol 计算指定交易所+币种在指定时间之前的累计实际盈利（exchange 为空表示不区分交易所）
+func (s *SQLiteStorage) GetActualProfitBySymbol(exchange, symbol string, beforeTime time.Time) (float64, error) {
+	scope, scopeArgs := scopeFilter(exchange, "")
 	row := s.db.QueryRow(`
 		SELECT COALESCE(SUM(pnl), 0) as total_pnl
 		FROM trades
-		WHERE symbol = ? AND created_at <= ?
-	`, symbol, beforeTime)
+		WHERE symbol = ? AND created_at <= ?`+scope,
+		append([]interface{}{symbol, beforeTime}, scopeArgs...)...)
 
 	var totalPnL sql.NullFloat64
 	err := row.Scan(&totalPnL)
@@ -1333,6 +1066,39 @@ func (s *SQLiteStorage) GetFundingRateHistory(symbol, exchange string, limit int
 	return rates, rows.Err()
 }
 
+// exchangeOrDefault 写入时交易所为空则记为 binance（兼容旧数据）
+func exchangeOrDefault(exchange string) string {
+	if exchange == "" {
+		return "binance"
+	}
+	return exchange
+}
+
+// scopeFilter 生成按交易所+交易对过滤的 SQL 片段（以 AND 开头），为空的字段不过滤
+func scopeFilter(exchange, symbol string) (string, []interface{}) {
+	var clause string
+	var args []interface{}
+	if exchange != "" {
+		clause += " AND exchange = ?"
+		args = append(args, exchange)
+	}
+	if symbol != "" {
+		clause += " AND symbol = ?"
+		args = append(args, symbol)
+	}
+	return clause, args
+}
+
+// statisticsScopeFilter 与 scopeFilter 相同，但按交易对过滤时保留 symbol 为空的旧统计行
+func statisticsScopeFilter(exchange, symbol string) (string, []interface{}) {
+	clause, args := scopeFilter(exchange, "")
+	if symbol != "" {
+		clause += " AND (symbol = ? OR symbol = '')"
+		args = append(args, symbol)
+	}
+	return clause, args
+}
+
 // abs 计算绝对值（用于浮点数比较）
 func abs(x float64) float64 {
 	if x < 0 {
@@ -1345,9 +1111,9 @@ func abs(x float64) float64 {
 func (s *SQLiteStorage) GetAIPromptTemplate(module string) (*AIPromptTemplate, error) {
 	var template AIPromptTemplate
 	err := s.db.QueryRow(
-		"SELECT id, module, template, system_prompt, updated_at FROM ai_prompts WHERE module = ?",
+		"SELECT id, module, template, COALESCE(system_prompt, ''), version, pinned_version, updated_at FROM ai_prompts WHERE module = ?",
 		module,
-	).Scan(&template.ID, &template.Module, &template.Template, &template.SystemPrompt, &template.UpdatedAt)
+	).Scan(&template.ID, &template.Module, &template.Template, &template.SystemPrompt, &template.Version, &template.PinnedVersion, &template.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil // 不存在，返回nil
@@ -1375,7 +1141,7 @@ func (s *SQLiteStorage) SetAIPromptTemplate(template *AIPromptTemplate) error {
 // GetAllAIPromptTemplates 获取所有AI提示词模板
 func (s *SQLiteStorage) GetAllAIPromptTemplates() ([]*AIPromptTemplate, error) {
 	rows, err := s.db.Query(
-		"SELECT id, module, template, system_prompt, updated_at FROM ai_prompts ORDER BY module",
+		"SELECT id, module, template, COALESCE(system_prompt, ''), version, pinned_version, updated_at FROM ai_prompts ORDER BY module",
 	)
 	if err != nil {
 		return nil, err
@@ -1385,7 +1151,7 @@ func (s *SQLiteStorage) GetAllAIPromptTemplates() ([]*AIPromptTemplate, error) {
 	var templates []*AIPromptTemplate
 	for rows.Next() {
 		var template AIPromptTemplate
-		err := rows.Scan(&template.ID, &template.Module, &template.Template, &template.SystemPrompt, &template.UpdatedAt)
+		err := rows.Scan(&template.ID, &template.Module, &template.Template, &template.SystemPrompt, &template.Version, &template.PinnedVersion, &template.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -1395,6 +1161,103 @@ func (s *SQLiteStorage) GetAllAIPromptTemplates() ([]*AIPromptTemplate, error) {
 	return templates, rows.Err()
 }
 
+// SaveAIPromptVersion 追加提示词版本（版本号自动递增）并设为模块的当前模板
+func (s *SQLiteStorage) SaveAIPromptVersion(version *AIPromptVersion) error {
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = utils.NowUTC()
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var latest int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(version), 0) FROM ai_prompt_versions WHERE module = ?", version.Module).Scan(&latest); err != nil {
+		return err
+	}
+	version.Version = latest + 1
+	result, err := tx.Exec(`
+		INSERT INTO ai_prompt_versions (module, version, template, system_prompt, author, comment, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, version.Module, version.Version, version.Template, version.SystemPrompt, version.Author, version.Comment, utils.ToUTC(version.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("保存提示词版本失败: %w", err)
+	}
+	version.ID, _ = result.LastInsertId()
+
+	if _, err := tx.Exec(`
+		INSERT INTO ai_prompts (module, template, system_prompt, version, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(module) DO UPDATE SET
+		template = excluded.template,
+		system_prompt = excluded.system_prompt,
+		version = excluded.version,
+		updated_at = excluded.updated_at
+	`, version.Module, version.Template, version.SystemPrompt, version.Version, utils.ToUTC(version.CreatedAt)); err != nil {
+		return fmt.Errorf("更新当前提示词失败: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetAIPromptVersion 获取提示词的指定版本，不存在时返回 nil
+func (s *SQLiteStorage) GetAIPromptVersion(module string, version int) (*AIPromptVersion, error) {
+	var v AIPromptVersion
+	err := s.db.QueryRow(`
+		SELECT id, module, version, template, COALESCE(system_prompt, ''), COALESCE(author, ''), COALESCE(comment, ''), created_at
+		FROM ai_prompt_versions WHERE module = ? AND version = ?
+	`, module, version).Scan(&v.ID, &v.Module, &v.Version, &v.Template, &v.SystemPrompt, &v.Author, &v.Comment, &v.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// QueryAIPromptVersions 查询提示词版本历史（按版本号倒序）
+func (s *SQLiteStorage) QueryAIPromptVersions(module string, limit int) ([]*AIPromptVersion, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 10000 {
+		limit = 10000
+	}
+	rows, err := s.db.Query(`
+		SELECT id, module, version, template, COALESCE(system_prompt, ''), COALESCE(author, ''), COALESCE(comment, ''), created_at
+		FROM ai_prompt_versions WHERE module = ?
+		ORDER BY version DESC
+		LIMIT ?
+	`, module, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*AIPromptVersion
+	for rows.Next() {
+		var v AIPromptVersion
+		if err := rows.Scan(&v.ID, &v.Module, &v.Version, &v.Template, &v.SystemPrompt, &v.Author, &v.Comment, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, &v)
+	}
+	return versions, rows.Err()
+}
+
+// SetAIPromptPin 固定模块使用的提示词版本，version 为 0 时取消固定
+func (s *SQLiteStorage) SetAIPromptPin(module string, version int) error {
+	result, err := s.db.Exec("UPDATE ai_prompts SET pinned_version = ? WHERE module = ?", version, module)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("提示词模块不存在: %s", module)
+	}
+	return nil
+}
+
 // SaveBasisData 保存价差数据
 func (s *SQLiteStorage) SaveBasisData(data *BasisData) error {
 	_, err := s.db.Exec(`
@@ -1497,6 +1360,11 @@ func (s *SQLiteStorage) GetBasisStatistics(symbol, exchange string, hours int) (
 		values = append(values, value)
 	}
 
+	return basisStatsFromValues(symbol, exchange, hours, values)
+}
+
+// basisStatsFromValues 根据价差百分比序列计算统计数据
+func basisStatsFromValues(symbol, exchange string, hours int, values []float64) (*BasisStats, error) {
 	if len(values) == 0 {
 		return nil, fmt.Errorf("没有找到数据")
 	}
@@ -1546,6 +1414,816 @@ func (s *SQLiteStorage) GetBasisStatistics(symbol, exchange string, hours int) (
 	}, nil
 }
 
+// SaveCostBasis 保存持仓成本（同一交易所+交易对覆盖更新）
+func (s *SQLiteStorage) SaveCostBasis(costBasis *CostBasis) error {
+	_, err := s.db.Exec(
+		`INSERT INTO cost_basis (exchange, symbol, quantity, total_cost, avg_entry_price, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(exchange, symbol) DO UPDATE SET
+		 quantity = excluded.quantity,
+		 total_cost = excluded.total_cost,
+		 avg_entry_price = excluded.avg_entry_price,
+		 updated_at = excluded.updated_at`,
+		costBasis.Exchange, costBasis.Symbol, costBasis.Quantity, costBasis.TotalCost, costBasis.AvgEntryPrice, utils.NowUTC(),
+	)
+	return err
+}
+
+// GetCostBasis 获取持仓成本，不存在时返回 nil
+func (s *SQLiteStorage) GetCostBasis(exchange, symbol string) (*CostBasis, error) {
+	var cb CostBasis
+	err := s.db.QueryRow(
+		"SELECT exchange, symbol, quantity, total_cost, avg_entry_price, updated_at FROM cost_basis WHERE exchange = ? AND symbol = ?",
+		exchange, symbol,
+	).Scan(&cb.Exchange, &cb.Symbol, &cb.Quantity, &cb.TotalCost, &cb.AvgEntryPrice, &cb.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cb, nil
+}
+
+// SaveGridAnchor 保存网格锚点（同一交易所+交易对覆盖）
+func (s *SQLiteStorage) SaveGridAnchor(anchor *GridAnchor) error {
+	_, err := s.db.Exec(
+		`INSERT INTO grid_anchors (exchange, symbol, price, source, updated_at)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(exchange, symbol) DO UPDATE SET
+		 price = excluded.price,
+		 source = excluded.source,
+		 updated_at = excluded.updated_at`,
+		anchor.Exchange, anchor.Symbol, anchor.Price, anchor.Source, utils.NowUTC(),
+	)
+	return err
+}
+
+// GetGridAnchor 获取网格锚点，不存在时返回 nil
+func (s *SQLiteStorage) GetGridAnchor(exchange, symbol string) (*GridAnchor, error) {
+	var a GridAnchor
+	err := s.db.QueryRow(
+		"SELECT exchange, symbol, price, COALESCE(source, ''), updated_at FROM grid_anchors WHERE exchange = ? AND symbol = ?",
+		exchange, symbol,
+	).Scan(&a.Exchange, &a.Symbol, &a.Price, &a.Source, &a.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// SaveFundingPayments 批量保存资金费流水，已存在的流水号会被忽略，返回新增条数
+func (s *SQLiteStorage) SaveFundingPayments(payments []*FundingPayment) (int, error) {
+	if len(payments) == 0 {
+		return 0, nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	stmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO funding_payments (exchange, symbol, asset, amount, tran_id, payment_time, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	defer stmt.Close()
+
+	inserted := 0
+	now := utils.NowUTC()
+	for _, p := range payments {
+		res, err := stmt.Exec(p.Exchange, p.Symbol, p.Asset, p.Amount, p.TranID, utils.ToUTC(p.PaymentTime), now)
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("保存资金费流水失败: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			inserted++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
+
+// GetLatestFundingPaymentTime 获取最近一笔资金费流水的时间，没有记录时返回零值
+func (s *SQLiteStorage) GetLatestFundingPaymentTime(exchange, symbol string) (time.Time, error) {
+	var latest sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT payment_time FROM funding_payments
+		WHERE exchange = ? AND symbol = ?
+		ORDER BY payment_time DESC
+		LIMIT 1
+	`, exchange, symbol).Scan(&latest)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return latest.Time, nil
+}
+
+// QueryFundingPayments 查询资金费流水（exchange/symbol 为空表示不过滤）
+func (s *SQLiteStorage) QueryFundingPayments(exchange, symbol string, startTime, endTime time.Time, limit int) ([]*FundingPayment, error) {
+	// 限制最大返回数量，防止内存占用过大
+	maxLimit := 10000
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	query := `
+		SELECT id, exchange, symbol, COALESCE(asset, ''), amount, tran_id, payment_time, created_at
+		FROM funding_payments
+		WHERE payment_time >= ? AND payment_time <= ?
+	`
+	args := []interface{}{startTime, endTime}
+	if exchange != "" {
+		query += " AND exchange = ?"
+		args = append(args, exchange)
+	}
+	if symbol != "" {
+		query += " AND symbol = ?"
+		args = append(args, symbol)
+	}
+	query += " ORDER BY payment_time DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询资金费流水失败: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []*FundingPayment
+	for rows.Next() {
+		var p FundingPayment
+		if err := rows.Scan(&p.ID, &p.Exchange, &p.Symbol, &p.Asset, &p.Amount, &p.TranID, &p.PaymentTime, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		payments = append(payments, &p)
+	}
+	return payments, rows.Err()
+}
+
+// SaveProfitTransfer 保存利润金库划转记录（成功和失败都记录）
+func (s *SQLiteStorage) SaveProfitTransfer(t *ProfitTransfer) error {
+	createdAt := t.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = utils.NowUTC()
+	}
+	res, err := s.db.Exec(`
+		INSERT INTO profit_transfers (exchange, asset, amount, to_wallet, tran_id, period, period_start, realized_pnl, status, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, t.Exchange, t.Asset, t.Amount, t.ToWallet, t.TranID, t.Period, utils.ToUTC(t.PeriodStart), t.RealizedPnL, t.Status, t.Error, utils.ToUTC(createdAt))
+	if err != nil {
+		return fmt.Errorf("保存利润划转记录失败: %w", err)
+	}
+	t.ID, _ = res.LastInsertId()
+	return nil
+}
+
+// QueryProfitTransfers 查询利润金库划转记录（exchange 为空表示不过滤）
+func (s *SQLiteStorage) QueryProfitTransfers(exchange string, startTime, endTime time.Time, limit int) ([]*ProfitTransfer, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 10000 {
+		limit = 10000
+	}
+
+	query := `
+		SELECT id, exchange, asset, amount, to_wallet, COALESCE(tran_id, ''), COALESCE(period, ''), period_start,
+			COALESCE(realized_pnl, 0), status, COALESCE(error, ''), created_at
+		FROM profit_transfers
+		WHERE created_at >= ? AND created_at <= ?
+	`
+	args := []interface{}{utils.ToUTC(startTime), utils.ToUTC(endTime)}
+	if exchange != "" {
+		query += " AND exchange = ?"
+		args = append(args, exchange)
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询利润划转记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var transfers []*ProfitTransfer
+	for rows.Next() {
+		var t ProfitTransfer
+		if err := rows.Scan(&t.ID, &t.Exchange, &t.Asset, &t.Amount, &t.ToWallet, &t.TranID, &t.Period, &t.PeriodStart,
+			&t.RealizedPnL, &t.Status, &t.Error, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, &t)
+	}
+	return transfers, rows.Err()
+}
+
+// GetProfitTransferTotal 汇总时间区间内成功划转的金额（exchange 为空表示全部交易所）
+func (s *SQLiteStorage) GetProfitTransferTotal(exchange string, startTime, endTime time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0) FROM profit_transfers
+		WHERE status = 'success' AND created_at >= ? AND created_at <= ?
+	`
+	args := []interface{}{utils.ToUTC(startTime), utils.ToUTC(endTime)}
+	if exchange != "" {
+		query += " AND exchange = ?"
+		args = append(args, exchange)
+	}
+	var total float64
+	if err := s.db.QueryRow(query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("汇总利润划转金额失败: %w", err)
+	}
+	return total, nil
+}
+
+// SaveOpenInterest 保存持仓量快照
+func (s *SQLiteStorage) SaveOpenInterest(data *OpenInterestData) error {
+	ts := data.Timestamp
+	if ts.IsZero() {
+		ts = utils.NowUTC()
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO open_interest (symbol, exchange, open_interest, open_interest_value, long_short_ratio, long_percent, short_percent, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, data.Symbol, data.Exchange, data.OpenInterest, data.OpenInterestValue, data.LongShortRatio,
+		data.LongPercent, data.ShortPercent, utils.ToUTC(ts))
+	if err != nil {
+		return fmt.Errorf("保存持仓量数据失败: %w", err)
+	}
+	return nil
+}
+
+// GetOpenInterestHistory 查询时间区间内的持仓量快照（按时间倒序）
+func (s *SQLiteStorage) GetOpenInterestHistory(symbol, exchange string, startTime, endTime time.Time, limit int) ([]*OpenInterestData, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 10000 {
+		limit = 10000
+	}
+
+	rows, err := s.db.Query(`
+		SELECT symbol, exchange, open_interest, COALESCE(open_interest_value, 0), COALESCE(long_short_ratio, 0),
+			COALESCE(long_percent, 0), COALESCE(short_percent, 0), timestamp
+		FROM open_interest
+		WHERE symbol = ? AND exchange = ? AND timestamp >= ? AND timestamp <= ?
+		ORDER BY timestamp DESC
+		LIMIT ?
+	`, symbol, exchange, utils.ToUTC(startTime), utils.ToUTC(endTime), limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询持仓量历史失败: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*OpenInterestData
+	for rows.Next() {
+		var d OpenInterestData
+		if err := rows.Scan(&d.Symbol, &d.Exchange, &d.OpenInterest, &d.OpenInterestValue, &d.LongShortRatio,
+			&d.LongPercent, &d.ShortPercent, &d.Timestamp); err != nil {
+			return nil, err
+		}
+		result = append(result, &d)
+	}
+	return result, rows.Err()
+}
+
+// SaveJournalEntry 保存交易日志条目（保存后回填 ID）
+func (s *SQLiteStorage) SaveJournalEntry(entry *JournalEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = utils.NowUTC()
+	}
+	result, err := s.db.Exec(`
+		INSERT INTO journal_entries (exchange, symbol, strategy, source, event_type, title, content, tags, equity, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, entry.Exchange, entry.Symbol, entry.Strategy, entry.Source, entry.EventType, entry.Title, entry.Content,
+		entry.Tags, entry.Equity, utils.ToUTC(entry.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("保存交易日志失败: %w", err)
+	}
+	entry.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// QueryJournalEntries 查询交易日志（按时间倒序）
+func (s *SQLiteStorage) QueryJournalEntries(q JournalQuery) ([]*JournalEntry, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 10000 {
+		limit = 10000
+	}
+
+	query := `
+		SELECT id, COALESCE(exchange, ''), COALESCE(symbol, ''), COALESCE(strategy, ''), source, COALESCE(event_type, ''),
+			title, COALESCE(content, ''), COALESCE(tags, ''), COALESCE(equity, 0), created_at
+		FROM journal_entries
+		WHERE 1 = 1
+	`
+	var args []interface{}
+	for _, f := range []struct{ column, value string }{
+		{"exchange", q.Exchange}, {"symbol", q.Symbol}, {"strategy", q.Strategy}, {"source", q.Source},
+	} {
+		if f.value != "" {
+			query += " AND " + f.column + " = ?"
+			args = append(args, f.value)
+		}
+	}
+	if !q.StartTime.IsZero() {
+		query += " AND created_at >= ?"
+		args = append(args, utils.ToUTC(q.StartTime))
+	}
+	if !q.EndTime.IsZero() {
+		query += " AND created_at <= ?"
+		args = append(args, utils.ToUTC(q.EndTime))
+	}
+	query += " ORDER BY created_at DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易日志失败: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*JournalEntry
+	for rows.Next() {
+		var e JournalEntry
+		if err := rows.Scan(&e.ID, &e.Exchange, &e.Symbol, &e.Strategy, &e.Source, &e.EventType,
+			&e.Title, &e.Content, &e.Tags, &e.Equity, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+// SaveRejectedOrder 保存拒单记录
+func (s *SQLiteStorage) SaveRejectedOrder(order *RejectedOrder) error {
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = utils.NowUTC()
+	}
+	result, err := s.db.Exec(`
+		INSERT INTO rejected_orders (exchange, symbol, side, price, quantity, client_order_id, strategy, category, reason, payload, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, order.Exchange, order.Symbol, order.Side, order.Price, order.Quantity, order.ClientOrderID, order.Strategy,
+		order.Category, order.Reason, order.Payload, utils.ToUTC(order.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("保存拒单记录失败: %w", err)
+	}
+	order.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// QueryRejectedOrders 查询拒单记录（按时间倒序）
+func (s *SQLiteStorage) QueryRejectedOrders(q RejectedOrderQuery) ([]*RejectedOrder, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 10000 {
+		limit = 10000
+	}
+
+	query := `
+		SELECT id, exchange, symbol, side, COALESCE(price, 0), COALESCE(quantity, 0), COALESCE(client_order_id, ''),
+			COALESCE(strategy, ''), category, COALESCE(reason, ''), COALESCE(payload, ''), created_at
+		FROM rejected_orders
+		WHERE 1 = 1
+	`
+	var args []interface{}
+	for _, f := range []struct{ column, value string }{
+		{"exchange", q.Exchange}, {"symbol", q.Symbol}, {"side", q.Side}, {"category", q.Category},
+	} {
+		if f.value != "" {
+			query += " AND " + f.column + " = ?"
+			args = append(args, f.value)
+		}
+	}
+	if !q.StartTime.IsZero() {
+		query += " AND created_at >= ?"
+		args = append(args, utils.ToUTC(q.StartTime))
+	}
+	if !q.EndTime.IsZero() {
+		query += " AND created_at <= ?"
+		args = append(args, utils.ToUTC(q.EndTime))
+	}
+	query += " ORDER BY created_at DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询拒单记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*RejectedOrder
+	for rows.Next() {
+		var o RejectedOrder
+		if err := rows.Scan(&o.ID, &o.Exchange, &o.Symbol, &o.Side, &o.Price, &o.Quantity, &o.ClientOrderID,
+			&o.Strategy, &o.Category, &o.Reason, &o.Payload, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		orders = append(orders, &o)
+	}
+	return orders, rows.Err()
+}
+
+// SaveOrderCleanupActions 批量保存订单清理动作
+func (s *SQLiteStorage) SaveOrderCleanupActions(actions []*OrderCleanupAction) error {
+	if len(actions) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO order_cleanup_actions (exchange, symbol, order_id, client_order_id, side, price, reason, detail,
+			trigger_source, dry_run, success, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, a := range actions {
+		if a.CreatedAt.IsZero() {
+			a.CreatedAt = utils.NowUTC()
+		}
+		result, err := stmt.Exec(a.Exchange, a.Symbol, a.OrderID, a.ClientOrderID, a.Side, a.Price, a.Reason, a.Detail,
+			a.Trigger, a.DryRun, a.Success, a.Error, utils.ToUTC(a.CreatedAt))
+		if err != nil {
+			return fmt.Errorf("保存订单清理记录失败: %w", err)
+		}
+		a.ID, _ = result.LastInsertId()
+	}
+	return tx.Commit()
+}
+
+// QueryOrderCleanupActions 查询订单清理记录（按时间倒序）
+func (s *SQLiteStorage) QueryOrderCleanupActions(q OrderCleanupQuery) ([]*OrderCleanupAction, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 10000 {
+		limit = 10000
+	}
+
+	query := `
+		SELECT id, exchange, symbol, order_id, COALESCE(client_order_id, ''), COALESCE(side, ''), COALESCE(price, 0),
+			reason, COALESCE(detail, ''), trigger_source, dry_run, success, COALESCE(error, ''), created_at
+		FROM order_cleanup_actions
+		WHERE 1 = 1
+	`
+	var args []interface{}
+	for _, f := range []struct{ column, value string }{
+		{"exchange", q.Exchange}, {"symbol", q.Symbol}, {"reason", q.Reason},
+	} {
+		if f.value != "" {
+			query += " AND " + f.column + " = ?"
+			args = append(args, f.value)
+		}
+	}
+	if q.OrderID != 0 {
+		query += " AND order_id = ?"
+		args = append(args, q.OrderID)
+	}
+	if !q.StartTime.IsZero() {
+		query += " AND created_at >= ?"
+		args = append(args, utils.ToUTC(q.StartTime))
+	}
+	if !q.EndTime.IsZero() {
+		query += " AND created_at <= ?"
+		args = append(args, utils.ToUTC(q.EndTime))
+	}
+	query += " ORDER BY created_at DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询订单清理记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var actions []*OrderCleanupAction
+	for rows.Next() {
+		var a OrderCleanupAction
+		if err := rows.Scan(&a.ID, &a.Exchange, &a.Symbol, &a.OrderID, &a.ClientOrderID, &a.Side, &a.Price,
+			&a.Reason, &a.Detail, &a.Trigger, &a.DryRun, &a.Success, &a.Error, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		actions = append(actions, &a)
+	}
+	return actions, rows.Err()
+}
+
+// SaveOCOLink 保存 OCO 关联订单（同一 link_id 覆盖）
+func (s *SQLiteStorage) SaveOCOLink(link *OCOLink) error {
+	now := utils.NowUTC()
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = now
+	}
+	link.UpdatedAt = now
+	_, err := s.db.Exec(`
+		INSERT INTO oco_links (link_id, exchange, symbol, side, quantity, take_profit_price, stop_price,
+			take_profit_order_id, take_profit_client_id, stop_order_id, native, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(link_id) DO UPDATE SET
+			quantity = excluded.quantity,
+			take_profit_order_id = excluded.take_profit_order_id,
+			take_profit_client_id = excluded.take_profit_client_id,
+			stop_order_id = excluded.stop_order_id,
+			status = excluded.status,
+			updated_at = excluded.updated_at
+	`, link.LinkID, link.Exchange, link.Symbol, link.Side, link.Quantity, link.TakeProfitPrice, link.StopPrice,
+		link.TakeProfitOrderID, link.TakeProfitClientID, link.StopOrderID, link.Native, link.Status,
+		utils.ToUTC(link.CreatedAt), link.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("保存 OCO 关联订单失败: %w", err)
+	}
+	return nil
+}
+
+// QueryActiveOCOLinks 查询交易对下仍处于 active 状态的 OCO 关联订单（按创建时间升序）
+func (s *SQLiteStorage) QueryActiveOCOLinks(exchange, symbol string) ([]*OCOLink, error) {
+	rows, err := s.db.Query(`
+		SELECT link_id, exchange, symbol, side, quantity, take_profit_price, stop_price,
+			COALESCE(take_profit_order_id, 0), COALESCE(take_profit_client_id, ''), COALESCE(stop_order_id, 0),
+			native, status, created_at, updated_at
+		FROM oco_links
+		WHERE exchange = ? AND symbol = ? AND status = 'active'
+		ORDER BY created_at ASC, id ASC
+	`, exchange, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("查询 OCO 关联订单失败: %w", err)
+	}
+	defer rows.Close()
+
+	var links []*OCOLink
+	for rows.Next() {
+		var l OCOLink
+		if err := rows.Scan(&l.LinkID, &l.Exchange, &l.Symbol, &l.Side, &l.Quantity, &l.TakeProfitPrice, &l.StopPrice,
+			&l.TakeProfitOrderID, &l.TakeProfitClientID, &l.StopOrderID, &l.Native, &l.Status,
+			&l.CreatedAt, &l.UpdatedAt); err != nil {
+			return nil, err
+		}
+		links = append(links, &l)
+	}
+	return links, rows.Err()
+}
+
+// SaveIncomeRecords 批量保存账户收支流水，已存在的流水号会被忽略，返回新增条数
+func (s *SQLiteStorage) SaveIncomeRecords(records []*IncomeRecord) (int, error) {
+	if len(records) == 0 {
+		return 0, nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	stmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO income_records (exchange, symbol, income_type, asset, amount, tran_id, income_time, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	defer stmt.Close()
+
+	inserted := 0
+	now := utils.NowUTC()
+	for _, r := range records {
+		res, err := stmt.Exec(r.Exchange, r.Symbol, r.IncomeType, r.Asset, r.Amount, r.TranID, utils.ToUTC(r.IncomeTime), now)
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("保存账户流水失败: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			inserted++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
+
+// GetLatestIncomeTime 获取指定类型最近一笔账户流水的时间，没有记录时返回零值
+func (s *SQLiteStorage) GetLatestIncomeTime(exchange, symbol, incomeType string) (time.Time, error) {
+	var latest sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT income_time FROM income_records
+		WHERE exchange = ? AND symbol = ? AND income_type = ?
+		ORDER BY income_time DESC
+		LIMIT 1
+	`, exchange, symbol, incomeType).Scan(&latest)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return latest.Time, nil
+}
+
+// SumIncomeByType 按流水类型汇总 [startTime, endTime] 内的账户流水金额
+func (s *SQLiteStorage) SumIncomeByType(exchange, symbol string, startTime, endTime time.Time) (map[string]float64, error) {
+	rows, err := s.db.Query(`
+		SELECT income_type, SUM(amount) FROM income_records
+		WHERE exchange = ? AND symbol = ? AND income_time >= ? AND income_time <= ?
+		GROUP BY income_type
+	`, exchange, symbol, utils.ToUTC(startTime), utils.ToUTC(endTime))
+	if err != nil {
+		return nil, fmt.Errorf("汇总账户流水失败: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]float64)
+	for rows.Next() {
+		var incomeType string
+		var amount sql.NullFloat64
+		if err := rows.Scan(&incomeType, &amount); err != nil {
+			return nil, err
+		}
+		result[incomeType] = amount.Float64
+	}
+	return result, rows.Err()
+}
+
+// SumTradePnL 汇总 [startTime, endTime] 内本地记录的交易盈亏（未扣手续费）
+func (s *SQLiteStorage) SumTradePnL(exchange, symbol string, startTime, endTime time.Time) (float64, int, error) {
+	var pnl sql.NullFloat64
+	var count int
+	err := s.db.QueryRow(`
+		SELECT SUM(pnl), COUNT(*) FROM trades
+		WHERE exchange = ? AND symbol = ? AND created_at >= ? AND created_at <= ?
+	`, exchange, symbol, utils.ToUTC(startTime), utils.ToUTC(endTime)).Scan(&pnl, &count)
+	if err != nil {
+		return 0, 0, fmt.Errorf("汇总交易盈亏失败: %w", err)
+	}
+	return pnl.Float64, count, nil
+}
+
+// fundingFeeByExchangeSymbol 按交易所+交易对汇总时间区间内的资金费
+func (s *SQLiteStorage) fundingFeeByExchangeSymbol(startTime, endTime time.Time) (map[[2]string]float64, error) {
+	rows, err := s.db.Query(`
+		SELECT exchange, symbol, COALESCE(SUM(amount), 0)
+		FROM funding_payments
+		WHERE payment_time >= ? AND payment_time <= ?
+		GROUP BY exchange, symbol
+	`, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fees := make(map[[2]string]float64)
+	for rows.Next() {
+		var exchange, symbol string
+		var amount float64
+		if err := rows.Scan(&exchange, &symbol, &amount); err != nil {
+			continue
+		}
+		fees[[2]string{exchange, symbol}] = amount
+	}
+	return fees, rows.Err()
+}
+
+// SaveStrategyReturn 保存策略每日收益（同一日期、交易所、交易对、策略覆盖）
+func (s *SQLiteStorage) SaveStrategyReturn(r *StrategyReturn) error {
+	r.UpdatedAt = utils.NowUTC()
+	_, err := s.db.Exec(`
+		INSERT INTO strategy_returns (date, exchange, symbol, strategy, pnl, allocated, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(date, exchange, symbol, strategy) DO UPDATE SET
+		pnl = excluded.pnl,
+		allocated = excluded.allocated,
+		updated_at = excluded.updated_at
+	`, utils.ToUTC(r.Date), r.Exchange, r.Symbol, r.Strategy, r.PnL, r.Allocated, r.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("保存策略收益失败: %w", err)
+	}
+	return nil
+}
+
+// QueryStrategyReturns 查询 [startDate, endDate] 内的策略每日收益（按日期升序）
+func (s *SQLiteStorage) QueryStrategyReturns(startDate, endDate time.Time) ([]*StrategyReturn, error) {
+	rows, err := s.db.Query(`
+		SELECT id, date, exchange, symbol, strategy, pnl, allocated, updated_at
+		FROM strategy_returns
+		WHERE date >= ? AND date <= ?
+		ORDER BY date ASC, id ASC
+	`, utils.ToUTC(startDate), utils.ToUTC(endDate))
+	if err != nil {
+		return nil, fmt.Errorf("查询策略收益失败: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*StrategyReturn
+	for rows.Next() {
+		r := &StrategyReturn{}
+		if err := rows.Scan(&r.ID, &r.Date, &r.Exchange, &r.Symbol, &r.Strategy, &r.PnL, &r.Allocated, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// SaveKlines 批量保存 K 线（同一交易所、交易对、周期、开盘时间覆盖），返回写入的条数
+func (s *SQLiteStorage) SaveKlines(klines []*Kline) (int, error) {
+	if len(klines) == 0 {
+		return 0, nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	stmt, err := tx.Prepare(`
+		INSERT INTO klines (exchange, symbol, interval, open_time, open, high, low, close, volume, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(exchange, symbol, interval, open_time) DO UPDATE SET
+		open = excluded.open,
+		high = excluded.high,
+		low = excluded.low,
+		close = excluded.close,
+		volume = excluded.volume,
+		updated_at = excluded.updated_at
+	`)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	defer stmt.Close()
+
+	written := 0
+	now := utils.NowUTC()
+	for _, k := range klines {
+		res, err := stmt.Exec(k.Exchange, k.Symbol, k.Interval, utils.ToUTC(k.OpenTime), k.Open, k.High, k.Low, k.Close, k.Volume, now)
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("保存K线失败: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			written++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return written, nil
+}
+
+// QueryKlines 查询 [startTime, endTime] 内的 K 线（按开盘时间升序），limit > 0 时只返回最近的 limit 根
+func (s *SQLiteStorage) QueryKlines(exchange, symbol, interval string, startTime, endTime time.Time, limit int) ([]*Kline, error) {
+	if limit <= 0 {
+		limit = -1 // SQLite 中 LIMIT -1 表示不限制
+	}
+	rows, err := s.db.Query(`
+		SELECT id, exchange, symbol, interval, open_time, open, high, low, close, volume, updated_at FROM (
+			SELECT * FROM klines
+			WHERE exchange = ? AND symbol = ? AND interval = ? AND open_time >= ? AND open_time <= ?
+			ORDER BY open_time DESC
+			LIMIT ?
+		) ORDER BY open_time ASC
+	`, exchange, symbol, interval, utils.ToUTC(startTime), utils.ToUTC(endTime), limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询K线失败: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*Kline
+	for rows.Next() {
+		k := &Kline{}
+		if err := rows.Scan(&k.ID, &k.Exchange, &k.Symbol, &k.Interval, &k.OpenTime, &k.Open, &k.High, &k.Low, &k.Close, &k.Volume, &k.UpdatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, k)
+	}
+	return result, rows.Err()
+}
+
 // Close 关闭数据库连接
 func (s *SQLiteStorage) Close() error {
 	if s.closed {
@@ -1,7 +1,10 @@
 package storage
 
 import (
+	"context"
+	"database/sql"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -35,7 +38,7 @@ func TestSQLiteStorage(t *testing.T) {
 		t.Errorf("保存订单失败: %v", err)
 	}
 
-	orders, err := storage.QueryOrders(10, 0, "FILLED")
+	orders, err := storage.QueryOrders("", "", 10, 0, "FILLED")
 	if err != nil {
 		t.Errorf("查询订单失败: %v", err)
 	}
@@ -84,7 +87,7 @@ func TestSQLiteStorage(t *testing.T) {
 	}
 	storage.SaveTrade(trade)
 
-	summary, err := storage.GetPnLBySymbol("BTCUSDT", time.Now().UTC().Add(-time.Hour), time.Now().UTC().Add(time.Hour))
+	summary, err := storage.GetPnLBySymbol("", "BTCUSDT", time.Now().UTC().Add(-time.Hour), time.Now().UTC().Add(time.Hour))
 	if err != nil {
 		t.Errorf("获取盈亏汇总失败: %v", err)
 	}
@@ -92,3 +95,639 @@ func TestSQLiteStorage(t *testing.T) {
 		t.Errorf("盈亏汇总计算错误: 期望 100.0, 得到 %.2f", summary.TotalPnL)
 	}
 }
+
+func TestFundingPayments(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "funding.db"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	defer storage.Close()
+
+	now := time.Now().UTC()
+	payments := []*FundingPayment{
+		{Exchange: "binance", Symbol: "BTCUSDT", Asset: "USDT", Amount: 1.5, TranID: "1001", PaymentTime: now.Add(-2 * time.Hour)},
+		{Exchange: "binance", Symbol: "BTCUSDT", Asset: "USDT", Amount: -0.5, TranID: "1002", PaymentTime: now.Add(-time.Hour)},
+		{Exchange: "binance", Symbol: "ETHUSDT", Asset: "USDT", Amount: 0.2, TranID: "1003", PaymentTime: now.Add(-time.Hour)},
+	}
+	inserted, err := storage.SaveFundingPayments(payments)
+	if err != nil || inserted != 3 {
+		t.Fatalf("保存资金费流水失败: inserted=%d, err=%v", inserted, err)
+	}
+
+	// 重复流水号应被忽略
+	inserted, err = storage.SaveFundingPayments(payments[:1])
+	if err != nil || inserted != 0 {
+		t.Errorf("重复流水不应新增: inserted=%d, err=%v", inserted, err)
+	}
+
+	latest, err := storage.GetLatestFundingPaymentTime("binance", "BTCUSDT")
+	if err != nil || !latest.Equal(payments[1].PaymentTime) {
+		t.Errorf("最近入账时间错误: %v, err=%v", latest, err)
+	}
+
+	storage.SaveTrade(&Trade{Exchange: "binance", Symbol: "BTCUSDT", BuyPrice: 50000, SellPrice: 50100, Quantity: 0.1, PnL: 10, CreatedAt: now})
+
+	start, end := now.Add(-3*time.Hour), now.Add(time.Hour)
+	summary, err := storage.GetPnLBySymbol("", "BTCUSDT", start, end)
+	if err != nil {
+		t.Fatalf("获取盈亏汇总失败: %v", err)
+	}
+	if summary.FundingFee != 1.0 || summary.NetPnL != 11.0 {
+		t.Errorf("资金费未计入净盈亏: funding=%.2f, net=%.2f", summary.FundingFee, summary.NetPnL)
+	}
+
+	// 只有资金费没有成交的交易对也应出现在按交易对统计中
+	bySymbol, err := storage.GetPnLByTimeRange(start, end)
+	if err != nil {
+		t.Fatalf("按时间区间查询盈亏失败: %v", err)
+	}
+	found := false
+	for _, r := range bySymbol {
+		if r.Symbol == "ETHUSDT" {
+			found = true
+			if r.TotalTrades != 0 || r.NetPnL != 0.2 {
+				t.Errorf("ETHUSDT 资金费统计错误: %+v", r)
+			}
+		}
+	}
+	if !found {
+		t.Error("只有资金费的交易对未出现在统计结果中")
+	}
+}
+
+func TestProfitTransfers(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "vault.db"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	defer storage.Close()
+
+	now := time.Now().UTC()
+	transfers := []*ProfitTransfer{
+		{Exchange: "binance", Asset: "USDT", Amount: 50, ToWallet: "SPOT", TranID: "t1", Period: "daily", PeriodStart: now.Add(-time.Hour), Status: "success", CreatedAt: now.Add(-30 * time.Minute)},
+		{Exchange: "binance", Asset: "USDT", Amount: 20, ToWallet: "SPOT", Period: "daily", PeriodStart: now.Add(-time.Hour), Status: "failed", Error: "no permission", CreatedAt: now.Add(-20 * time.Minute)},
+		{Exchange: "gate", Asset: "USDT", Amount: 5, ToWallet: "FUNDING", TranID: "t2", Period: "daily", PeriodStart: now.Add(-time.Hour), Status: "success", CreatedAt: now.Add(-10 * time.Minute)},
+	}
+	for _, tr := range transfers {
+		if err := storage.SaveProfitTransfer(tr); err != nil {
+			t.Fatalf("保存划转记录失败: %v", err)
+		}
+	}
+
+	start, end := now.Add(-time.Hour), now.Add(time.Minute)
+	total, err := storage.GetProfitTransferTotal("binance", start, end)
+	if err != nil || total != 50 {
+		t.Errorf("失败的划转不应计入总额: total=%.2f, err=%v", total, err)
+	}
+	total, err = storage.GetProfitTransferTotal("", start, end)
+	if err != nil || total != 55 {
+		t.Errorf("全部交易所划转总额错误: total=%.2f, err=%v", total, err)
+	}
+
+	records, err := storage.QueryProfitTransfers("binance", start, end, 10)
+	if err != nil {
+		t.Fatalf("查询划转记录失败: %v", err)
+	}
+	if len(records) != 2 || records[0].Status != "failed" || records[0].Error != "no permission" {
+		t.Errorf("划转记录查询结果错误: %+v", records)
+	}
+}
+
+func TestGridAnchor(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "anchor.db"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	defer storage.Close()
+
+	anchor, err := storage.GetGridAnchor("binance", "BTCUSDT")
+	if err != nil || anchor != nil {
+		t.Fatalf("未保存时应返回 nil: %+v, err=%v", anchor, err)
+	}
+
+	if err := storage.SaveGridAnchor(&GridAnchor{Exchange: "binance", Symbol: "BTCUSDT", Price: 50000, Source: "vwap"}); err != nil {
+		t.Fatalf("保存锚点失败: %v", err)
+	}
+	if err := storage.SaveGridAnchor(&GridAnchor{Exchange: "binance", Symbol: "BTCUSDT", Price: 51000, Source: "manual"}); err != nil {
+		t.Fatalf("覆盖锚点失败: %v", err)
+	}
+	anchor, err = storage.GetGridAnchor("binance", "BTCUSDT")
+	if err != nil || anchor == nil || anchor.Price != 51000 || anchor.Source != "manual" {
+		t.Errorf("锚点应被覆盖为手动设置的值: %+v, err=%v", anchor, err)
+	}
+}
+
+func TestOpenInterestHistory(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "oi.db"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	defer storage.Close()
+
+	now := time.Now().UTC()
+	for i, oi := range []float64{1000, 1100, 1300} {
+		data := &OpenInterestData{
+			Symbol: "BTCUSDT", Exchange: "binance", OpenInterest: oi, OpenInterestValue: oi * 50000,
+			LongShortRatio: 1.5, LongPercent: 0.6, ShortPercent: 0.4,
+			Timestamp: now.Add(time.Duration(i-2) * 30 * time.Minute),
+		}
+		if err := storage.SaveOpenInterest(data); err != nil {
+			t.Fatalf("保存持仓量失败: %v", err)
+		}
+	}
+	if err := storage.SaveOpenInterest(&OpenInterestData{Symbol: "ETHUSDT", Exchange: "binance", OpenInterest: 1, Timestamp: now}); err != nil {
+		t.Fatalf("保存持仓量失败: %v", err)
+	}
+
+	records, err := storage.GetOpenInterestHistory("BTCUSDT", "binance", now.Add(-2*time.Hour), now.Add(time.Minute), 10)
+	if err != nil {
+		t.Fatalf("查询持仓量历史失败: %v", err)
+	}
+	if len(records) != 3 || records[0].OpenInterest != 1300 || records[2].OpenInterest != 1000 {
+		t.Fatalf("持仓量历史应按时间倒序返回: %+v", records)
+	}
+	if records[0].LongShortRatio != 1.5 || records[0].LongPercent != 0.6 {
+		t.Errorf("多空比字段未正确保存: %+v", records[0])
+	}
+
+	records, err = storage.GetOpenInterestHistory("BTCUSDT", "binance", now.Add(-45*time.Minute), now.Add(time.Minute), 10)
+	if err != nil || len(records) != 2 {
+		t.Errorf("时间区间过滤错误: %d 条, err=%v", len(records), err)
+	}
+}
+
+func TestJournalEntries(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "journal.db"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	defer storage.Close()
+
+	now := time.Now().UTC()
+	entries := []*JournalEntry{
+		{Source: "manual", Title: "全局笔记", Content: "降低整体仓位", Equity: 120, CreatedAt: now.Add(-2 * time.Hour)},
+		{Exchange: "binance", Symbol: "BTCUSDT", Source: "auto", EventType: "risk_triggered", Title: "风控触发", Equity: 100, CreatedAt: now.Add(-time.Hour)},
+		{Exchange: "binance", Symbol: "BTCUSDT", Strategy: "grid", Source: "manual", Title: "调整间隔", Tags: "params,grid", CreatedAt: now},
+	}
+	for _, e := range entries {
+		if err := storage.SaveJournalEntry(e); err != nil {
+			t.Fatalf("保存日志失败: %v", err)
+		}
+		if e.ID == 0 {
+			t.Fatalf("保存后应回填 ID")
+		}
+	}
+
+	all, err := storage.QueryJournalEntries(JournalQuery{})
+	if err != nil || len(all) != 3 || all[0].Title != "调整间隔" {
+		t.Fatalf("应按时间倒序返回全部日志: %+v, err=%v", all, err)
+	}
+
+	btc, err := storage.QueryJournalEntries(JournalQuery{Symbol: "BTCUSDT", Source: "auto"})
+	if err != nil || len(btc) != 1 || btc[0].EventType != "risk_triggered" || btc[0].Equity != 100 {
+		t.Errorf("按交易对和来源过滤错误: %+v, err=%v", btc, err)
+	}
+
+	recent, err := storage.QueryJournalEntries(JournalQuery{StartTime: now.Add(-90 * time.Minute), Limit: 10})
+	if err != nil || len(recent) != 2 {
+		t.Errorf("时间过滤错误: %d 条, err=%v", len(recent), err)
+	}
+}
+
+func TestOCOLinks(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "oco.db"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	defer storage.Close()
+
+	link := &OCOLink{
+		LinkID: "oco1", Exchange: "binance", Symbol: "BTCUSDT", Side: "SELL", Quantity: 0.01,
+		TakeProfitPrice: 110, StopPrice: 90, TakeProfitOrderID: 1001, TakeProfitClientID: "oco1T", Status: "active",
+	}
+	if err := storage.SaveOCOLink(link); err != nil {
+		t.Fatalf("保存 OCO 失败: %v", err)
+	}
+	other := &OCOLink{LinkID: "oco2", Exchange: "binance", Symbol: "ETHUSDT", Side: "BUY", Quantity: 1, TakeProfitPrice: 90, StopPrice: 110, Status: "active"}
+	if err := storage.SaveOCOLink(other); err != nil {
+		t.Fatalf("保存 OCO 失败: %v", err)
+	}
+
+	active, err := storage.QueryActiveOCOLinks("binance", "BTCUSDT")
+	if err != nil || len(active) != 1 || active[0].TakeProfitOrderID != 1001 || active[0].StopPrice != 90 {
+		t.Fatalf("查询活跃 OCO 错误: %+v, err=%v", active, err)
+	}
+
+	// 同一 link_id 覆盖更新，非 active 状态不再返回
+	link.Status = "take_profit"
+	if err := storage.SaveOCOLink(link); err != nil {
+		t.Fatalf("更新 OCO 失败: %v", err)
+	}
+	active, err = storage.QueryActiveOCOLinks("binance", "BTCUSDT")
+	if err != nil || len(active) != 0 {
+		t.Errorf("已完成的 OCO 不应返回: %+v, err=%v", active, err)
+	}
+}
+
+func TestRejectedOrders(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "rejected.db"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	defer storage.Close()
+
+	now := time.Now().UTC()
+	orders := []*RejectedOrder{
+		{Exchange: "binance", Symbol: "BTCUSDT", Side: "BUY", Price: 100, Quantity: 0.01, Category: "insufficient_margin", Reason: "Margin is insufficient", CreatedAt: now.Add(-time.Hour)},
+		{Exchange: "binance", Symbol: "BTCUSDT", Side: "SELL", Price: 110, Quantity: 0.01, Category: "reduce_only", Payload: `{"side":"SELL"}`, CreatedAt: now},
+		{Exchange: "binance", Symbol: "ETHUSDT", Side: "BUY", Price: 10, Quantity: 1, Category: "invalid_filter", CreatedAt: now},
+	}
+	for _, o := range orders {
+		if err := storage.SaveRejectedOrder(o); err != nil {
+			t.Fatalf("保存拒单记录失败: %v", err)
+		}
+	}
+
+	btc, err := storage.QueryRejectedOrders(RejectedOrderQuery{Exchange: "binance", Symbol: "BTCUSDT"})
+	if err != nil || len(btc) != 2 || btc[0].Side != "SELL" || btc[0].Payload != `{"side":"SELL"}` {
+		t.Fatalf("应按时间倒序返回交易对的拒单: %+v, err=%v", btc, err)
+	}
+
+	buys, err := storage.QueryRejectedOrders(RejectedOrderQuery{Side: "BUY", StartTime: now.Add(-30 * time.Minute)})
+	if err != nil || len(buys) != 1 || buys[0].Symbol != "ETHUSDT" {
+		t.Errorf("按方向和时间过滤错误: %+v, err=%v", buys, err)
+	}
+}
+
+func TestOrderCleanupActions(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "cleanup.db"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	defer storage.Close()
+
+	now := time.Now().UTC()
+	actions := []*OrderCleanupAction{
+		{Exchange: "binance", Symbol: "BTCUSDT", OrderID: 1, Side: "BUY", Price: 100, Reason: CleanupReasonThreshold, Trigger: "scheduled", Success: true, CreatedAt: now.Add(-time.Hour)},
+		{Exchange: "binance", Symbol: "BTCUSDT", OrderID: 2, ClientOrderID: "manual-1", Side: "SELL", Price: 120, Reason: CleanupReasonOrphan, Trigger: "manual", DryRun: true, Success: true, CreatedAt: now},
+		{Exchange: "binance", Symbol: "ETHUSDT", OrderID: 3, Side: "BUY", Price: 10, Reason: CleanupReasonExpired, Trigger: "scheduled", Error: "rate limited", CreatedAt: now},
+	}
+	if err := storage.SaveOrderCleanupActions(actions); err != nil {
+		t.Fatalf("保存订单清理记录失败: %v", err)
+	}
+	if actions[0].ID == 0 {
+		t.Errorf("保存后应回填 ID")
+	}
+
+	btc, err := storage.QueryOrderCleanupActions(OrderCleanupQuery{Exchange: "binance", Symbol: "BTCUSDT"})
+	if err != nil || len(btc) != 2 || btc[0].OrderID != 2 || !btc[0].DryRun || btc[0].ClientOrderID != "manual-1" {
+		t.Fatalf("应按时间倒序返回交易对的清理记录: %+v, err=%v", btc, err)
+	}
+
+	byOrder, err := storage.QueryOrderCleanupActions(OrderCleanupQuery{OrderID: 3})
+	if err != nil || len(byOrder) != 1 || byOrder[0].Success || byOrder[0].Error != "rate limited" {
+		t.Errorf("按订单号查询错误: %+v, err=%v", byOrder, err)
+	}
+}
+
+func TestIncomeRecords(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "income.db"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	defer storage.Close()
+
+	now := time.Now().UTC()
+	records := []*IncomeRecord{
+		{Exchange: "Binance", Symbol: "BTCUSDT", IncomeType: "REALIZED_PNL", Asset: "USDT", Amount: 12, TranID: "2001", IncomeTime: now.Add(-2 * time.Hour)},
+		{Exchange: "Binance", Symbol: "BTCUSDT", IncomeType: "COMMISSION", Asset: "USDT", Amount: -1.5, TranID: "2001", IncomeTime: now.Add(-2 * time.Hour)},
+		{Exchange: "Binance", Symbol: "BTCUSDT", IncomeType: "FUNDING_FEE", Asset: "USDT", Amount: 0.3, TranID: "2002", IncomeTime: now.Add(-time.Hour)},
+		{Exchange: "Binance", Symbol: "ETHUSDT", IncomeType: "REALIZED_PNL", Asset: "USDT", Amount: 5, TranID: "2003", IncomeTime: now.Add(-time.Hour)},
+	}
+	inserted, err := storage.SaveIncomeRecords(records)
+	if err != nil || inserted != 4 {
+		t.Fatalf("保存账户流水失败: inserted=%d, err=%v", inserted, err)
+	}
+
+	// 同一类型的重复流水号应被忽略
+	inserted, err = storage.SaveIncomeRecords(records[:2])
+	if err != nil || inserted != 0 {
+		t.Errorf("重复流水不应新增: inserted=%d, err=%v", inserted, err)
+	}
+
+	latest, err := storage.GetLatestIncomeTime("Binance", "BTCUSDT", "FUNDING_FEE")
+	if err != nil || !latest.Equal(records[2].IncomeTime) {
+		t.Errorf("最近入账时间错误: %v, err=%v", latest, err)
+	}
+	latest, err = storage.GetLatestIncomeTime("Binance", "BTCUSDT", "INSURANCE_CLEAR")
+	if err != nil || !latest.IsZero() {
+		t.Errorf("无记录时应返回零值: %v, err=%v", latest, err)
+	}
+
+	start, end := now.Add(-3*time.Hour), now.Add(time.Hour)
+	sums, err := storage.SumIncomeByType("Binance", "BTCUSDT", start, end)
+	if err != nil {
+		t.Fatalf("汇总账户流水失败: %v", err)
+	}
+	if sums["REALIZED_PNL"] != 12 || sums["COMMISSION"] != -1.5 || sums["FUNDING_FEE"] != 0.3 {
+		t.Errorf("按类型汇总错误: %+v", sums)
+	}
+
+	storage.SaveTrade(&Trade{Exchange: "binance", Symbol: "BTCUSDT", BuyPrice: 50000, SellPrice: 50100, Quantity: 0.1, PnL: 10, CreatedAt: now.Add(-90 * time.Minute)})
+	storage.SaveTrade(&Trade{Exchange: "binance", Symbol: "BTCUSDT", BuyPrice: 50000, SellPrice: 50020, Quantity: 0.1, PnL: 2, CreatedAt: now.Add(-30 * time.Minute)})
+	pnl, count, err := storage.SumTradePnL("binance", "BTCUSDT", start, end)
+	if err != nil || pnl != 12 || count != 2 {
+		t.Errorf("汇总交易盈亏错误: pnl=%.2f, count=%d, err=%v", pnl, count, err)
+	}
+}
+
+func TestSymbolIsolation(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "isolation.db")
+
+	// 旧版表结构：orders.order_id 全局唯一，statistics 只按日期唯一，没有 exchange 列
+	legacy, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("打开旧库失败: %v", err)
+	}
+	_, err = legacy.Exec(`
+	CREATE TABLE orders (id INTEGER PRIMARY KEY AUTOINCREMENT, order_id BIGINT UNIQUE, client_order_id TEXT, symbol TEXT,
+		side TEXT, price DECIMAL(20,8), quantity DECIMAL(20,8), status TEXT, created_at TIMESTAMP, updated_at TIMESTAMP);
+	CREATE TABLE statistics (id INTEGER PRIMARY KEY AUTOINCREMENT, date DATE UNIQUE, total_trades INTEGER,
+		total_volume DECIMAL(20,8), total_pnl DECIMAL(20,8), win_rate DECIMAL(5,2), created_at TIMESTAMP);
+	INSERT INTO orders (order_id, client_order_id, symbol, side, price, quantity, status, created_at, updated_at)
+		VALUES (1, 'c1', 'BTCUSDT', 'BUY', 50000, 0.1, 'FILLED', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+	INSERT INTO statistics (date, total_trades, total_volume, total_pnl, win_rate, created_at)
+		VALUES ('2024-01-01', 3, 0.3, 12, 66.67, CURRENT_TIMESTAMP);`)
+	legacy.Close()
+	if err != nil {
+		t.Fatalf("创建旧表失败: %v", err)
+	}
+
+	storage, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("迁移旧库失败: %v", err)
+	}
+	defer storage.Close()
+
+	orders, err := storage.QueryOrders("binance", "BTCUSDT", 10, 0, "")
+	if err != nil || len(orders) != 1 || orders[0].Exchange != "binance" {
+		t.Fatalf("旧订单应迁移为 binance: %v, err=%v", orders, err)
+	}
+
+	// 不同交易所的订单号可以相同
+	now := time.Now().UTC()
+	if err := storage.SaveOrder(&Order{OrderID: 1, Exchange: "okx", Symbol: "BTCUSDT", Status: "FILLED", CreatedAt: now}); err != nil {
+		t.Fatalf("保存订单失败: %v", err)
+	}
+	storage.SaveOrder(&Order{OrderID: 2, Exchange: "binance", Symbol: "ETHUSDT", Status: "FILLED", CreatedAt: now})
+	if all, _ := storage.QueryOrders("", "", 10, 0, ""); len(all) != 3 {
+		t.Errorf("期望 3 条订单, 得到 %d", len(all))
+	}
+	if okx, _ := storage.QueryOrders("okx", "BTCUSDT", 10, 0, ""); len(okx) != 1 || okx[0].Exchange != "okx" {
+		t.Errorf("按交易所过滤订单错误: %v", okx)
+	}
+
+	storage.SaveTrade(&Trade{Exchange: "binance", Symbol: "BTCUSDT", Quantity: 1, PnL: 10, CreatedAt: now})
+	storage.SaveTrade(&Trade{Exchange: "binance", Symbol: "ETHUSDT", Quantity: 1, PnL: -4, CreatedAt: now})
+	storage.SaveTrade(&Trade{Exchange: "okx", Symbol: "BTCUSDT", Quantity: 1, PnL: 7, CreatedAt: now})
+
+	start, end := now.Add(-time.Hour), now.Add(time.Hour)
+	if trades, _ := storage.QueryTrades("binance", "BTCUSDT", start, end, 10, 0); len(trades) != 1 || trades[0].PnL != 10 {
+		t.Errorf("按交易对过滤成交错误: %v", trades)
+	}
+	summary, err := storage.GetStatisticsSummaryBySymbol("binance", "BTCUSDT")
+	if err != nil || summary.TotalTrades != 1 || summary.TotalPnL != 10 {
+		t.Errorf("交易对统计汇总不应混入其他交易对: %+v, err=%v", summary, err)
+	}
+	if pnl, _ := storage.GetPnLBySymbol("okx", "BTCUSDT", start, end); pnl.TotalPnL != 7 {
+		t.Errorf("按交易所过滤盈亏错误: %+v", pnl)
+	}
+	daily, err := storage.QueryDailyStatisticsBySymbol("binance", "ETHUSDT", start, end)
+	if err != nil || len(daily) != 1 || daily[0].TotalPnL != -4 {
+		t.Errorf("交易对每日统计错误: %v, err=%v", daily, err)
+	}
+
+	// 同一天不同交易对的统计各自保存
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	storage.SaveStatistics(&Statistics{Exchange: "binance", Symbol: "BTCUSDT", Date: day, TotalTrades: 5})
+	storage.SaveStatistics(&Statistics{Exchange: "binance", Symbol: "ETHUSDT", Date: day, TotalTrades: 2})
+	if stats, _ := storage.QueryStatistics("binance", "ETHUSDT", day, day); len(stats) != 1 || stats[0].TotalTrades != 2 {
+		t.Errorf("按交易对查询统计错误: %v", stats)
+	}
+	// 迁移前的旧统计没有交易对，按交易对查询时仍然可见
+	legacyStart := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
+	stats, err := storage.QueryStatistics("binance", "BTCUSDT", legacyStart, day)
+	if err != nil || len(stats) != 2 || stats[1].Symbol != "" || stats[1].TotalTrades != 3 {
+		t.Errorf("按交易对查询应包含旧统计: %v, err=%v", stats, err)
+	}
+	if stats, _ := storage.QueryStatistics("okx", "BTCUSDT", legacyStart, day); len(stats) != 0 {
+		t.Errorf("旧统计属于 binance，不应出现在其他交易所: %v", stats)
+	}
+
+	storage.SaveReconciliationHistory(&ReconciliationHistory{Exchange: "binance", Symbol: "BTCUSDT", ReconcileTime: now, LocalPosition: 1})
+	storage.SaveReconciliationHistory(&ReconciliationHistory{Exchange: "okx", Symbol: "BTCUSDT", ReconcileTime: now, LocalPosition: 2})
+	if count, _ := storage.GetReconciliationCount("okx", "BTCUSDT"); count != 1 {
+		t.Errorf("对账次数应按交易所区分: %d", count)
+	}
+	latest, err := storage.GetLatestReconciliationHistory("okx", "BTCUSDT")
+	if err != nil || latest == nil || latest.LocalPosition != 2 || latest.Exchange != "okx" {
+		t.Errorf("最新对账记录错误: %+v, err=%v", latest, err)
+	}
+}
+
+func TestSchemaMigration(t *testing.T) {
+	dir := t.TempDir()
+
+	// 新库直接升级到最新版本，不产生备份
+	storage, err := NewSQLiteStorage(filepath.Join(dir, "fresh.db"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	m, err := NewSQLiteMigrator(storage.db, filepath.Join(dir, "fresh.db"))
+	if err != nil {
+		t.Fatalf("创建迁移执行器失败: %v", err)
+	}
+	if current, err := m.Current(); err != nil || current != m.Latest() {
+		t.Errorf("新库应位于最新版本 %d, 得到 %d, err=%v", m.Latest(), current, err)
+	}
+	storage.Close()
+
+	// 旧版数据库（没有 schema_version）升级前先备份
+	legacyPath := filepath.Join(dir, "legacy.db")
+	legacy, err := sql.Open("sqlite3", legacyPath)
+	if err != nil {
+		t.Fatalf("打开旧库失败: %v", err)
+	}
+	_, err = legacy.Exec(`CREATE TABLE orders (id INTEGER PRIMARY KEY AUTOINCREMENT, order_id BIGINT UNIQUE, client_order_id TEXT,
+		symbol TEXT, side TEXT, price DECIMAL(20,8), quantity DECIMAL(20,8), status TEXT, created_at TIMESTAMP, updated_at TIMESTAMP)`)
+	legacy.Close()
+	if err != nil {
+		t.Fatalf("创建旧表失败: %v", err)
+	}
+	storage, err = NewSQLiteStorage(legacyPath)
+	if err != nil {
+		t.Fatalf("升级旧库失败: %v", err)
+	}
+	defer storage.Close()
+
+	backups, _ := filepath.Glob(legacyPath + ".v0-*.bak")
+	if len(backups) != 1 {
+		t.Errorf("升级旧库前应生成备份, 得到 %v", backups)
+	}
+	if err := storage.SaveRejectedOrder(&RejectedOrder{Exchange: "binance", Symbol: "BTCUSDT", CreatedAt: time.Now()}); err != nil {
+		t.Errorf("基线迁移应补齐旧库缺失的表: %v", err)
+	}
+}
+
+func TestAIPromptVersions(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "prompts.db"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	defer storage.Close()
+
+	for _, text := range []string{"v1", "v2"} {
+		v := &AIPromptVersion{Module: "market_analysis", Template: text, Author: "alice"}
+		if err := storage.SaveAIPromptVersion(v); err != nil {
+			t.Fatalf("保存提示词版本失败: %v", err)
+		}
+	}
+	current, err := storage.GetAIPromptTemplate("market_analysis")
+	if err != nil || current == nil || current.Template != "v2" || current.Version != 2 {
+		t.Fatalf("当前提示词错误: %+v, err=%v", current, err)
+	}
+
+	if err := storage.SetAIPromptPin("market_analysis", 1); err != nil {
+		t.Fatalf("固定版本失败: %v", err)
+	}
+	// 直接覆盖模板不影响版本号和固定版本
+	if err := storage.SetAIPromptTemplate(&AIPromptTemplate{Module: "market_analysis", Template: "manual"}); err != nil {
+		t.Fatal(err)
+	}
+	current, _ = storage.GetAIPromptTemplate("market_analysis")
+	if current.PinnedVersion != 1 || current.Version != 2 {
+		t.Errorf("固定版本丢失: %+v", current)
+	}
+	if err := storage.SetAIPromptPin("unknown", 1); err == nil {
+		t.Error("不存在的模块应返回错误")
+	}
+
+	versions, err := storage.QueryAIPromptVersions("market_analysis", 10)
+	if err != nil || len(versions) != 2 || versions[0].Version != 2 || versions[1].Author != "alice" {
+		t.Fatalf("版本历史错误: %+v, err=%v", versions, err)
+	}
+	v1, err := storage.GetAIPromptVersion("market_analysis", 1)
+	if err != nil || v1 == nil || v1.Template != "v1" {
+		t.Errorf("获取 v1 错误: %+v, err=%v", v1, err)
+	}
+	if missing, err := storage.GetAIPromptVersion("market_analysis", 3); err != nil || missing != nil {
+		t.Errorf("不存在的版本应返回 nil: %+v, err=%v", missing, err)
+	}
+}
+
+func TestStrategyReturns(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "returns.db"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	defer storage.Close()
+
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, r := range []*StrategyReturn{
+		{Date: day, Exchange: "binance", Symbol: "BTCUSDT", Strategy: "grid", PnL: 5, Allocated: 1000},
+		{Date: day, Exchange: "binance", Symbol: "BTCUSDT", Strategy: "grid", PnL: 12, Allocated: 1000}, // 同日覆盖
+		{Date: day.AddDate(0, 0, 1), Exchange: "binance", Symbol: "BTCUSDT", Strategy: "grid", PnL: -3, Allocated: 900},
+		{Date: day.AddDate(0, 0, 5), Exchange: "binance", Symbol: "BTCUSDT", Strategy: "grid", PnL: 1, Allocated: 900},
+	} {
+		if err := storage.SaveStrategyReturn(r); err != nil {
+			t.Fatalf("保存策略收益失败: %v", err)
+		}
+	}
+
+	records, err := storage.QueryStrategyReturns(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("查询策略收益失败: %v", err)
+	}
+	if len(records) != 2 || records[0].PnL != 12 || records[1].PnL != -3 || !records[0].Date.Equal(day) {
+		t.Fatalf("策略收益记录错误: %+v", records)
+	}
+}
+
+func TestKlines(t *testing.T) {
+	sqlite, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "klines.db"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	defer sqlite.Close()
+
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	for name, st := range map[string]Storage{"sqlite": sqlite, "memory": NewMemoryStorage()} {
+		var klines []*Kline
+		for i := 0; i < 5; i++ {
+			klines = append(klines, &Kline{Exchange: "binance", Symbol: "BTCUSDT", Interval: "1m",
+				OpenTime: base.Add(time.Duration(i) * time.Minute), Open: 100, High: 101, Low: 99, Close: float64(100 + i)})
+		}
+		klines = append(klines,
+			&Kline{Exchange: "binance", Symbol: "BTCUSDT", Interval: "1m", OpenTime: base.Add(4 * time.Minute), Close: 200}, // 覆盖最后一根
+			&Kline{Exchange: "binance", Symbol: "BTCUSDT", Interval: "1h", OpenTime: base, Close: 1},
+			&Kline{Exchange: "bybit", Symbol: "BTCUSDT", Interval: "1m", OpenTime: base, Close: 2},
+		)
+		if n, err := st.SaveKlines(klines); err != nil || n != len(klines) {
+			t.Fatalf("%s: 保存K线失败: n=%d, err=%v", name, n, err)
+		}
+
+		got, err := st.QueryKlines("binance", "BTCUSDT", "1m", base.Add(time.Minute), base.Add(time.Hour), 0)
+		if err != nil {
+			t.Fatalf("%s: 查询K线失败: %v", name, err)
+		}
+		if len(got) != 4 || got[0].Close != 101 || got[3].Close != 200 || !got[0].OpenTime.Equal(base.Add(time.Minute)) {
+			t.Fatalf("%s: K线查询结果错误: %+v", name, got)
+		}
+		// limit 返回最近的 N 根，仍按时间升序
+		got, _ = st.QueryKlines("binance", "BTCUSDT", "1m", base, base.Add(time.Hour), 2)
+		if len(got) != 2 || got[0].Close != 103 || got[1].Close != 200 {
+			t.Errorf("%s: limit 查询结果错误: %+v", name, got)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	st, err := NewSQLiteStorage(filepath.Join(dir, "live.db"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	defer st.Close()
+	if err := st.SaveOrder(&Order{OrderID: 1, ClientOrderID: "snap_1", Symbol: "BTCUSDT", Status: "FILLED", CreatedAt: time.Now(), UpdatedAt: time.Now()}); err != nil {
+		t.Fatalf("保存订单失败: %v", err)
+	}
+
+	dest := filepath.Join(dir, "snapshot.db")
+	if err := st.Snapshot(context.Background(), dest); err != nil {
+		t.Fatalf("导出快照失败: %v", err)
+	}
+	// 快照写入后源库继续可用，快照不含之后的写入
+	if err := st.SaveOrder(&Order{OrderID: 2, ClientOrderID: "snap_2", Symbol: "BTCUSDT", Status: "FILLED", CreatedAt: time.Now(), UpdatedAt: time.Now()}); err != nil {
+		t.Fatalf("快照后保存订单失败: %v", err)
+	}
+
+	db, err := sql.Open("sqlite3", dest)
+	if err != nil {
+		t.Fatalf("打开快照失败: %v", err)
+	}
+	defer db.Close()
+	var count int
+	var mode string
+	if err := db.QueryRow(`SELECT COUNT(*) FROM orders`).Scan(&count); err != nil || count != 1 {
+		t.Errorf("快照订单数错误: count=%d, err=%v", count, err)
+	}
+	if err := db.QueryRow(`PRAGMA journal_mode`).Scan(&mode); err != nil || mode != "delete" {
+		t.Errorf("快照日志模式错误: %q, err=%v", mode, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := st.Snapshot(ctx, filepath.Join(dir, "cancelled.db")); err == nil {
+		t.Error("已取消的快照应返回错误")
+	}
+}
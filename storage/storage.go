@@ -14,7 +14,9 @@ import (
 	"quantmesh/utils"
 )
 
-// Storage 存储接口
+//go:generate go run ../tools/storagemock -src storage.go -out ../testutil/mock_storage.go
+
+// Storage 存储接口（修改后执行 go generate ./storage 重新生成 testutil.MockStorage）
 type Storage interface {
 	SaveOrder(order *Order) error
 	SavePosition(position *Position) error
@@ -28,20 +30,22 @@ type Storage interface {
 	GetLatestSystemMetrics() (*SystemMetrics, error)
 	CleanupSystemMetrics(beforeTime time.Time) error
 	CleanupDailySystemMetrics(beforeDate time.Time) error
-	QueryOrders(limit, offset int, status string) ([]*Order, error)
-	QueryTrades(startTime, endTime time.Time, limit, offset int) ([]*Trade, error)
-	QueryStatistics(startDate, endDate time.Time) ([]*Statistics, error)
+	QueryOrders(exchange, symbol string, limit, offset int, status string) ([]*Order, error)
+	QueryTrades(exchange, symbol string, startTime, endTime time.Time, limit, offset int) ([]*Trade, error)
+	QueryStatistics(exchange, symbol string, startDate, endDate time.Time) ([]*Statistics, error)
 	GetStatisticsSummary() (*Statistics, error)
 	GetStatisticsSummaryByExchange(exchange string) (*Statistics, error)
+	GetStatisticsSummaryBySymbol(exchange, symbol string) (*Statistics, error)
 	QueryDailyStatisticsFromTrades(startDate, endDate time.Time) ([]*DailyStatisticsWithTradeCount, error)
 	QueryDailyStatisticsByExchange(exchange string, startDate, endDate time.Time) ([]*DailyStatisticsWithTradeCount, error)
+	QueryDailyStatisticsBySymbol(exchange, symbol string, startDate, endDate time.Time) ([]*DailyStatisticsWithTradeCount, error)
 	SaveReconciliationHistory(history *ReconciliationHistory) error
-	QueryReconciliationHistory(symbol string, startTime, endTime time.Time, limit, offset int) ([]*ReconciliationHistory, error)
-	GetLatestReconciliationHistory(symbol string) (*ReconciliationHistory, error)
-	GetReconciliationCount(symbol string) (int64, error)
-	GetPnLBySymbol(symbol string, startTime, endTime time.Time) (*PnLSummary, error)
+	QueryReconciliationHistory(exchange, symbol string, startTime, endTime time.Time, limit, offset int) ([]*ReconciliationHistory, error)
+	GetLatestReconciliationHistory(exchange, symbol string) (*ReconciliationHistory, error)
+	GetReconciliationCount(exchange, symbol string) (int64, error)
+	GetPnLBySymbol(exchange, symbol string, startTime, endTime time.Time) (*PnLSummary, error)
 	GetPnLByTimeRange(startTime, endTime time.Time) ([]*PnLBySymbol, error)
-	GetActualProfitBySymbol(symbol string, beforeTime time.Time) (float64, error)
+	GetActualProfitBySymbol(exchange, symbol string, beforeTime time.Time) (float64, error)
 	SaveRiskCheck(record *RiskCheckRecord) error
 	QueryRiskCheckHistory(startTime, endTime time.Time, limit int) ([]*RiskCheckHistory, error)
 	CleanupRiskCheckHistory(beforeTime time.Time) error
@@ -51,10 +55,42 @@ type Storage interface {
 	GetAIPromptTemplate(module string) (*AIPromptTemplate, error)
 	SetAIPromptTemplate(template *AIPromptTemplate) error
 	GetAllAIPromptTemplates() ([]*AIPromptTemplate, error)
+	SaveAIPromptVersion(version *AIPromptVersion) error
+	GetAIPromptVersion(module string, version int) (*AIPromptVersion, error)
+	QueryAIPromptVersions(module string, limit int) ([]*AIPromptVersion, error)
+	SetAIPromptPin(module string, version int) error
 	SaveBasisData(data *BasisData) error
 	GetLatestBasis(symbol, exchange string) (*BasisData, error)
 	GetBasisHistory(symbol, exchange string, limit int) ([]*BasisData, error)
 	GetBasisStatistics(symbol, exchange string, hours int) (*BasisStats, error)
+	SaveCostBasis(costBasis *CostBasis) error
+	GetCostBasis(exchange, symbol string) (*CostBasis, error)
+	SaveGridAnchor(anchor *GridAnchor) error
+	GetGridAnchor(exchange, symbol string) (*GridAnchor, error)
+	SaveFundingPayments(payments []*FundingPayment) (int, error)
+	GetLatestFundingPaymentTime(exchange, symbol string) (time.Time, error)
+	QueryFundingPayments(exchange, symbol string, startTime, endTime time.Time, limit int) ([]*FundingPayment, error)
+	SaveProfitTransfer(transfer *ProfitTransfer) error
+	QueryProfitTransfers(exchange string, startTime, endTime time.Time, limit int) ([]*ProfitTransfer, error)
+	GetProfitTransferTotal(exchange string, startTime, endTime time.Time) (float64, error)
+	SaveOpenInterest(data *OpenInterestData) error
+	GetOpenInterestHistory(symbol, exchange string, startTime, endTime time.Time, limit int) ([]*OpenInterestData, error)
+	SaveJournalEntry(entry *JournalEntry) error
+	QueryJournalEntries(q JournalQuery) ([]*JournalEntry, error)
+	SaveRejectedOrder(order *RejectedOrder) error
+	QueryRejectedOrders(q RejectedOrderQuery) ([]*RejectedOrder, error)
+	SaveOrderCleanupActions(actions []*OrderCleanupAction) error
+	QueryOrderCleanupActions(q OrderCleanupQuery) ([]*OrderCleanupAction, error)
+	SaveOCOLink(link *OCOLink) error
+	QueryActiveOCOLinks(exchange, symbol string) ([]*OCOLink, error)
+	SaveIncomeRecords(records []*IncomeRecord) (int, error)
+	GetLatestIncomeTime(exchange, symbol, incomeType string) (time.Time, error)
+	SumIncomeByType(exchange, symbol string, startTime, endTime time.Time) (map[string]float64, error)
+	SumTradePnL(exchange, symbol string, startTime, endTime time.Time) (float64, int, error)
+	SaveStrategyReturn(r *StrategyReturn) error
+	QueryStrategyReturns(startDate, endDate time.Time) ([]*StrategyReturn, error)
+	SaveKlines(klines []*Kline) (int, error)
+	QueryKlines(exchange, symbol, interval string, startTime, endTime time.Time, limit int) ([]*Kline, error)
 	Close() error
 }
 
@@ -95,20 +131,23 @@ func NewStorageService(cfg *config.Config, ctx context.Context) (*StorageService
 		fallbackPath: "./data/storage_fallback.log",
 	}
 
-	// 创建数据目录
-	dataDir := filepath.Dir(cfg.Storage.Path)
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		return nil, fmt.Errorf("创建数据目录失败: %w", err)
-	}
-
 	// 初始化存储实现
 	switch cfg.Storage.Type {
 	case "sqlite":
+		// 创建数据目录
+		dataDir := filepath.Dir(cfg.Storage.Path)
+		if err := os.MkdirAll(dataDir, 0755); err != nil {
+			return nil, fmt.Errorf("创建数据目录失败: %w", err)
+		}
 		sqliteStorage, err := NewSQLiteStorage(cfg.Storage.Path)
 		if err != nil {
 			return nil, fmt.Errorf("初始化 SQLite 存储失败: %w", err)
 		}
 		ss.storage = sqliteStorage
+	case "memory":
+		// 内存存储：数据不落盘，进程退出即丢失，仅用于临时试验
+		logger.Warn("⚠️ 使用内存存储，订单、交易和统计数据在退出后将丢失")
+		ss.storage = NewMemoryStorage()
 	default:
 		return nil, fmt.Errorf("不支持的存储类型: %s", cfg.Storage.Type)
 	}
@@ -122,7 +161,7 @@ func (ss *StorageService) GetStorage() Storage {
 }
 
 // SaveReconciliationHistoryDirect 直接保存对账历史（用于 Reconciler）
-func (ss *StorageService) SaveReconciliationHistoryDirect(symbol string, reconcileTime time.Time, localPosition, exchangePosition, positionDiff float64,
+func (ss *StorageService) SaveReconciliationHistoryDirect(exchange, symbol string, reconcileTime time.Time, localPosition, exchangePosition, positionDiff float64,
 	activeBuyOrders, activeSellOrders int, pendingSellQty, totalBuyQty, totalSellQty, estimatedProfit float64) error {
 	if ss.storage == nil {
 		return nil
@@ -131,13 +170,14 @@ func (ss *StorageService) SaveReconciliationHistoryDirect(symbol string, reconci
 	// 计算实际盈利（从 trades 表统计截止到对账时间的累计盈亏）
 	// 🔥 重要：先将 reconcileTime 转换为 UTC，因为数据库中的 created_at 是 UTC 时间
 	reconcileTimeUTC := utils.ToUTC(reconcileTime)
-	actualProfit, err := ss.storage.GetActualProfitBySymbol(symbol, reconcileTimeUTC)
+	actualProfit, err := ss.storage.GetActualProfitBySymbol(exchange, symbol, reconcileTimeUTC)
 	if err != nil {
 		logger.Warn("⚠️ 计算实际盈利失败: %v，使用 0 作为默认值", err)
 		actualProfit = 0
 	}
 
 	history := &ReconciliationHistory{
+		Exchange:         exchange,
 		Symbol:           symbol,
 		ReconcileTime:    utils.ToUTC(reconcileTime),
 		LocalPosition:    localPosition,
@@ -298,6 +338,14 @@ func (ss *StorageService) batchSave(events []*storageEvent) error {
 			if position, ok := event.data.(map[string]interface{}); ok {
 				err = ss.savePositionFromMap(position)
 			}
+		case "order_rejected":
+			if rejected, ok := event.data.(*RejectedOrder); ok {
+				err = ss.storage.SaveRejectedOrder(rejected)
+			}
+		case "order_cleanup":
+			if actions, ok := event.data.([]*OrderCleanupAction); ok {
+				err = ss.storage.SaveOrderCleanupActions(actions)
+			}
 		case "system_metrics":
 			// 系统监控数据直接通过SaveEvent处理（已在sqlite中实现）
 			if data, ok := event.data.(map[string]interface{}); ok {
@@ -331,6 +379,9 @@ func (ss *StorageService) saveOrderFromMap(data map[string]interface{}) error {
 	if clientOID, ok := data["client_order_id"].(string); ok {
 		order.ClientOrderID = clientOID
 	}
+	if exchange, ok := data["exchange"].(string); ok {
+		order.Exchange = exchange
+	}
 	if symbol, ok := data["symbol"].(string); ok {
 		order.Symbol = symbol
 	}
@@ -362,6 +413,9 @@ func (ss *StorageService) savePositionFromMap(data map[string]interface{}) error
 	if slotPrice, ok := data["slot_price"].(float64); ok {
 		position.SlotPrice = slotPrice
 	}
+	if exchange, ok := data["exchange"].(string); ok {
+		position.Exchange = exchange
+	}
 	if symbol, ok := data["symbol"].(string); ok {
 		position.Symbol = symbol
 	}
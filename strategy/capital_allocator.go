@@ -2,12 +2,16 @@ package strategy
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"sync"
 	"time"
 
 	"quantmesh/config"
+	"quantmesh/event"
+	"quantmesh/indicators"
 	"quantmesh/logger"
+	"quantmesh/utils"
 )
 
 // StrategyCapital 策略资金
@@ -90,6 +94,32 @@ func (ca *CapitalAllocator) Allocate() {
 	}
 }
 
+// ApplyRiskParity 按波动率倒数重新设定按权重分配的策略权重（风险平价），并重新分配资金
+// vols 为各策略的已实现波动率；固定资金池策略和缺少波动率的策略保持原权重
+func (ca *CapitalAllocator) ApplyRiskParity(vols map[string]float64) map[string]float64 {
+	ca.mu.Lock()
+	eligible := make(map[string]float64)
+	weightTotal := 0.0
+	for name, capital := range ca.strategies {
+		if vol, ok := vols[name]; ok && vol > 0 && capital.FixedPool <= 0 {
+			eligible[name] = vol
+			weightTotal += capital.Weight
+		}
+	}
+	weights := indicators.InverseVolWeights(eligible)
+	// 参与风险平价的策略共享它们原有的权重总额，不影响其余策略的占比
+	for name, weight := range weights {
+		weights[name] = weight * weightTotal
+		ca.strategies[name].Weight = weights[name]
+	}
+	ca.mu.Unlock()
+
+	if len(weights) > 0 {
+		ca.Allocate()
+	}
+	return weights
+}
+
 // CheckAvailable 检查策略可用资金
 func (ca *CapitalAllocator) CheckAvailable(strategyName string, amount float64) bool {
 	ca.mu.RLock()
@@ -212,17 +242,57 @@ type StrategyPerformance struct {
 	TotalTrades   int
 	WinningTrades int
 	LosingTrades  int
+	Returns       []float64 // 最近各再平衡区间的收益率（区间盈亏 / 分配资金）
+	lastPnL       float64
+	hasSnapshot   bool
 	mu            sync.RWMutex
 }
 
+// 资金分配模式
+const (
+	AllocationModeRiskParity = "risk_parity" // 按区间收益波动率倒数分配
+	AllocationModeKelly      = "kelly"       // 按分数凯利比例分配
+)
+
+// UsesDynamicWeights 是否需要启动动态分配器（启用 dynamic 或选择了风险平价/凯利模式）
+func UsesDynamicWeights(cfg *config.Config) bool {
+	mode := cfg.Strategies.CapitalAllocation.Mode
+	return cfg.Strategies.CapitalAllocation.DynamicAllocation.Enabled ||
+		mode == AllocationModeRiskParity || mode == AllocationModeKelly
+}
+
+// WeightChange 一次再平衡中单个策略的权重调整记录（审计用）
+type WeightChange struct {
+	Time         time.Time `json:"time"`
+	Strategy     string    `json:"strategy"`
+	Mode         string    `json:"mode"`
+	OldWeight    float64   `json:"old_weight"`
+	NewWeight    float64   `json:"new_weight"`
+	TargetWeight float64   `json:"target_weight"`
+	Reason       string    `json:"reason"` // 计算目标权重的依据（波动率、凯利比例、样本数等）
+}
+
+// maxWeightChanges 内存中保留的权重调整记录数量
+const maxWeightChanges = 500
+
 // DynamicAllocator 动态分配器
 type DynamicAllocator struct {
 	strategies            map[string]*StrategyPerformance
+	mode                  string
 	rebalanceInterval     time.Duration
 	maxChangePerRebalance float64
 	minWeight             float64
 	maxWeight             float64
 	performanceWeights    map[string]float64
+	returnLookback        int
+	minReturnSamples      int
+	kellyFraction         float64
+	kellyMaxFraction      float64
+	exchange              string
+	symbol                string
+	eventBus              EventBus
+	reasons               map[string]string // 最近一次计算目标权重的依据
+	changes               []WeightChange
 	ctx                   context.Context
 	cancel                context.CancelFunc
 	mu                    sync.RWMutex
@@ -234,11 +304,19 @@ func NewDynamicAllocator(cfg *config.Config) *DynamicAllocator {
 
 	da := &DynamicAllocator{
 		strategies:            make(map[string]*StrategyPerformance),
+		mode:                  cfg.Strategies.CapitalAllocation.Mode,
 		rebalanceInterval:     time.Duration(cfg.Strategies.CapitalAllocation.DynamicAllocation.RebalanceInterval) * time.Second,
 		maxChangePerRebalance: cfg.Strategies.CapitalAllocation.DynamicAllocation.MaxChangePerRebalance,
 		minWeight:             cfg.Strategies.CapitalAllocation.DynamicAllocation.MinWeight,
 		maxWeight:             cfg.Strategies.CapitalAllocation.DynamicAllocation.MaxWeight,
 		performanceWeights:    cfg.Strategies.CapitalAllocation.DynamicAllocation.PerformanceWeights,
+		returnLookback:        cfg.Strategies.CapitalAllocation.DynamicAllocation.ReturnLookback,
+		minReturnSamples:      cfg.Strategies.CapitalAllocation.DynamicAllocation.MinReturnSamples,
+		kellyFraction:         cfg.Strategies.CapitalAllocation.Kelly.Fraction,
+		kellyMaxFraction:      cfg.Strategies.CapitalAllocation.Kelly.MaxFraction,
+		exchange:              cfg.App.CurrentExchange,
+		symbol:                cfg.Trading.Symbol,
+		reasons:               make(map[string]string),
 		ctx:                   ctx,
 		cancel:                cancel,
 	}
@@ -255,6 +333,18 @@ func NewDynamicAllocator(cfg *config.Config) *DynamicAllocator {
 	if da.maxWeight <= 0 {
 		da.maxWeight = 0.7 // 默认70%
 	}
+	if da.returnLookback <= 0 {
+		da.returnLookback = 30
+	}
+	if da.minReturnSamples < 2 {
+		da.minReturnSamples = 5
+	}
+	if da.kellyFraction <= 0 {
+		da.kellyFraction = 0.5
+	}
+	if da.kellyMaxFraction <= 0 {
+		da.kellyMaxFraction = 0.25
+	}
 
 	// 设置默认性能权重
 	if da.performanceWeights == nil {
@@ -316,8 +406,46 @@ func (da *DynamicAllocator) UpdatePerformance(strategyName string, pnl float64,
 	// TODO: 计算夏普比率和最大回撤
 }
 
+// SetEventBus 设置事件总线（权重调整时发布审计事件）
+func (da *DynamicAllocator) SetEventBus(eb EventBus) {
+	da.mu.Lock()
+	defer da.mu.Unlock()
+	da.eventBus = eb
+}
+
+// RecordPeriod 记录一个再平衡区间的收益率：区间盈亏 / 区间开始时的分配资金
+// 首次调用只记录盈亏快照；分配资金为 0 的策略本区间不产生样本
+func (da *DynamicAllocator) RecordPeriod(stats map[string]*StrategyStatistics, allocated map[string]float64) {
+	da.mu.Lock()
+	defer da.mu.Unlock()
+
+	for name, perf := range da.strategies {
+		st, ok := stats[name]
+		if !ok || st == nil {
+			continue
+		}
+		perf.mu.Lock()
+		if perf.hasSnapshot && allocated[name] > 0 {
+			perf.Returns = append(perf.Returns, (st.TotalPnL-perf.lastPnL)/allocated[name])
+			if len(perf.Returns) > da.returnLookback {
+				perf.Returns = perf.Returns[len(perf.Returns)-da.returnLookback:]
+			}
+		}
+		perf.lastPnL = st.TotalPnL
+		perf.hasSnapshot = true
+		perf.mu.Unlock()
+	}
+}
+
 // CalculateTargetWeights 计算目标权重
 func (da *DynamicAllocator) CalculateTargetWeights() map[string]float64 {
+	switch da.mode {
+	case AllocationModeRiskParity:
+		return da.calculateRiskParityWeights()
+	case AllocationModeKelly:
+		return da.calculateKellyWeights()
+	}
+
 	da.mu.RLock()
 	defer da.mu.RUnlock()
 
@@ -381,6 +509,80 @@ func (da *DynamicAllocator) CalculateTargetWeights() map[string]float64 {
 	return result
 }
 
+// calculateRiskParityWeights 风险平价目标权重：样本充足的策略按区间收益波动率倒数分配它们当前的权重总额
+func (da *DynamicAllocator) calculateRiskParityWeights() map[string]float64 {
+	return da.distributeByReturns(func(name string, returns []float64) (float64, string) {
+		vol := indicators.SampleStdDev(returns)
+		if vol <= 0 {
+			return 0, fmt.Sprintf("波动率为0 (样本%d)", len(returns))
+		}
+		return 1 / vol, fmt.Sprintf("波动率=%.4f (样本%d)", vol, len(returns))
+	})
+}
+
+// calculateKellyWeights 凯利目标权重：fraction × 区间收益均值 / 方差，限制在 [0, max_fraction]，
+// 样本充足的策略按凯利比例分配它们当前的权重总额，无正期望的策略降到最小权重
+func (da *DynamicAllocator) calculateKellyWeights() map[string]float64 {
+	return da.distributeByReturns(func(name string, returns []float64) (float64, string) {
+		mean := 0.0
+		for _, r := range returns {
+			mean += r
+		}
+		mean /= float64(len(returns))
+		vol := indicators.SampleStdDev(returns)
+		if vol <= 0 {
+			return 0, fmt.Sprintf("波动率为0 (样本%d)", len(returns))
+		}
+		kelly := da.kellyFraction * mean / (vol * vol)
+		capped := math.Max(0, math.Min(da.kellyMaxFraction, kelly))
+		return capped, fmt.Sprintf("均值=%.4f 波动率=%.4f 凯利=%.4f 上限后=%.4f (样本%d)", mean, vol, kelly, capped, len(returns))
+	})
+}
+
+// distributeByReturns 按区间收益样本计算的得分重新分配权重
+// 样本不足 min_return_samples 的策略保持当前权重；其余策略按得分比例瓜分它们当前的权重总额，并限制在最小/最大权重之间
+// 所有策略得分都为 0 时保持当前权重
+func (da *DynamicAllocator) distributeByReturns(score func(name string, returns []float64) (float64, string)) map[string]float64 {
+	da.mu.Lock()
+	defer da.mu.Unlock()
+
+	result := make(map[string]float64, len(da.strategies))
+	scores := make(map[string]float64)
+	budget, totalScore := 0.0, 0.0
+	for name, perf := range da.strategies {
+		perf.mu.RLock()
+		current := perf.CurrentWeight
+		returns := append([]float64(nil), perf.Returns...)
+		perf.mu.RUnlock()
+
+		result[name] = current
+		if len(returns) < da.minReturnSamples {
+			da.reasons[name] = fmt.Sprintf("样本不足 (%d/%d)，保持当前权重", len(returns), da.minReturnSamples)
+			continue
+		}
+		s, reason := score(name, returns)
+		da.reasons[name] = reason
+		scores[name] = s
+		budget += current
+		totalScore += s
+	}
+	if totalScore <= 0 {
+		return result
+	}
+
+	for name, s := range scores {
+		weight := budget * s / totalScore
+		if weight < da.minWeight {
+			weight = da.minWeight
+		}
+		if weight > da.maxWeight {
+			weight = da.maxWeight
+		}
+		result[name] = weight
+	}
+	return result
+}
+
 // calculateScore 计算策略得分
 func (da *DynamicAllocator) calculateScore(perf *StrategyPerformance) float64 {
 	score := 0.0
@@ -420,6 +622,12 @@ func (da *DynamicAllocator) Rebalance(targetWeights map[string]float64) map[stri
 	defer da.mu.Unlock()
 
 	adjustedWeights := make(map[string]float64)
+	now := time.Now()
+	mode := da.mode
+	if mode == "" || mode == "fixed" || mode == "both" {
+		mode = "dynamic"
+	}
+	var changes []WeightChange
 
 	for name, targetWeight := range targetWeights {
 		perf, exists := da.strategies[name]
@@ -428,7 +636,8 @@ func (da *DynamicAllocator) Rebalance(targetWeights map[string]float64) map[stri
 		}
 
 		perf.mu.Lock()
-		currentWeight := perf.CurrentWeight
+		oldWeight := perf.CurrentWeight
+		currentWeight := oldWeight
 		diff := targetWeight - currentWeight
 
 		// 平滑调整：每次调整不超过 maxChangePerRebalance
@@ -455,22 +664,71 @@ func (da *DynamicAllocator) Rebalance(targetWeights map[string]float64) map[stri
 		adjustedWeights[name] = currentWeight
 		perf.mu.Unlock()
 
-		if math.Abs(diff) > 0.001 {
-			logger.Info("📊 [动态分配] 策略 %s: 权重 %.2f%% -> %.2f%% (目标: %.2f%%)",
-				name, perf.CurrentWeight*100, currentWeight*100, targetWeight*100)
+		if math.Abs(currentWeight-oldWeight) > 0.001 {
+			reason := da.reasons[name]
+			if reason == "" {
+				reason = "综合表现得分"
+			}
+			logger.Info("📊 [动态分配] 策略 %s: 权重 %.2f%% -> %.2f%% (目标: %.2f%%, 模式: %s, %s)",
+				name, oldWeight*100, currentWeight*100, targetWeight*100, mode, reason)
+			changes = append(changes, WeightChange{
+				Time:         now,
+				Strategy:     name,
+				Mode:         mode,
+				OldWeight:    oldWeight,
+				NewWeight:    currentWeight,
+				TargetWeight: targetWeight,
+				Reason:       reason,
+			})
+		}
+	}
+
+	if len(changes) > 0 {
+		da.changes = append(da.changes, changes...)
+		if len(da.changes) > maxWeightChanges {
+			da.changes = da.changes[len(da.changes)-maxWeightChanges:]
+		}
+		if da.eventBus != nil {
+			details := make([]map[string]interface{}, len(changes))
+			for i, ch := range changes {
+				details[i] = map[string]interface{}{
+					"strategy":      ch.Strategy,
+					"old_weight":    ch.OldWeight,
+					"new_weight":    ch.NewWeight,
+					"target_weight": ch.TargetWeight,
+					"reason":        ch.Reason,
+				}
+			}
+			da.eventBus.Publish(&event.Event{
+				Type: event.EventTypeCapitalRebalanced,
+				Data: map[string]interface{}{
+					"exchange": da.exchange,
+					"symbol":   da.symbol,
+					"mode":     mode,
+					"changes":  details,
+					"message":  fmt.Sprintf("策略资金权重已调整（%s 模式，%d 个策略）", mode, len(changes)),
+				},
+			})
 		}
 	}
 
 	return adjustedWeights
 }
 
-// Start 启动动态分配器
-func (da *DynamicAllocator) Start(allocator *CapitalAllocator) {
+// GetWeightChanges 获取最近的权重调整记录（按时间先后）
+func (da *DynamicAllocator) GetWeightChanges() []WeightChange {
+	da.mu.RLock()
+	defer da.mu.RUnlock()
+	return append([]WeightChange(nil), da.changes...)
+}
+
+// Start 启动动态分配器，stats 提供各策略当前的交易统计（用于记录区间收益率）
+func (da *DynamicAllocator) Start(allocator *CapitalAllocator, stats func() map[string]*StrategyStatistics) {
 	if da.rebalanceInterval <= 0 {
 		return
 	}
 
-	go func() {
+	utils.GoSupervised(da.ctx, "dynamic-allocator", func(ctx context.Context) {
 		ticker := time.NewTicker(da.rebalanceInterval)
 		defer ticker.Stop()
 
@@ -479,6 +737,15 @@ func (da *DynamicAllocator) Start(allocator *CapitalAllocator) {
 			case <-da.ctx.Done():
 				return
 			case <-ticker.C:
+				// 记录本区间收益率（按区间开始时的分配资金计算）
+				if stats != nil {
+					allocated := make(map[string]float64)
+					for name, capital := range allocator.GetAllStrategiesCapital() {
+						allocated[name] = capital.Allocated
+					}
+					da.RecordPeriod(stats(), allocated)
+				}
+
 				// 计算目标权重
 				targetWeights := da.CalculateTargetWeights()
 
@@ -498,7 +765,7 @@ func (da *DynamicAllocator) Start(allocator *CapitalAllocator) {
 				allocator.Allocate()
 			}
 		}
-	}()
+	})
 }
 
 // Stop 停止动态分配器
@@ -531,5 +798,6 @@ func (da *DynamicAllocator) GetPerformance(strategyName string) *StrategyPerform
 		TotalTrades:   perf.TotalTrades,
 		WinningTrades: perf.WinningTrades,
 		LosingTrades:  perf.LosingTrades,
+		Returns:       append([]float64(nil), perf.Returns...),
 	}
 }
@@ -0,0 +1,108 @@
+package strategy
+
+import (
+	"math"
+	"testing"
+
+	"quantmesh/config"
+	"quantmesh/event"
+)
+
+type recordingEventBus struct {
+	events []*event.Event
+}
+
+func (b *recordingEventBus) Publish(evt *event.Event) { b.events = append(b.events, evt) }
+
+func newModeAllocator(mode string) *DynamicAllocator {
+	cfg := &config.Config{}
+	cfg.Strategies.CapitalAllocation.Mode = mode
+	cfg.Strategies.CapitalAllocation.DynamicAllocation.MinWeight = 0.05
+	cfg.Strategies.CapitalAllocation.DynamicAllocation.MaxWeight = 0.9
+	cfg.Strategies.CapitalAllocation.DynamicAllocation.MaxChangePerRebalance = 1
+	cfg.Strategies.CapitalAllocation.DynamicAllocation.MinReturnSamples = 3
+	return NewDynamicAllocator(cfg)
+}
+
+// feedReturns 以 1000 的分配资金逐个区间喂入收益率
+func feedReturns(da *DynamicAllocator, returns map[string][]float64) {
+	pnl := make(map[string]float64)
+	allocated := make(map[string]float64)
+	stats := make(map[string]*StrategyStatistics)
+	for name := range returns {
+		allocated[name] = 1000
+		stats[name] = &StrategyStatistics{}
+	}
+	da.RecordPeriod(stats, allocated) // 初始快照
+	for i := 0; ; i++ {
+		// 样本已用完的策略不再出现在统计中，不产生新的区间收益
+		period := make(map[string]*StrategyStatistics)
+		for name, rs := range returns {
+			if i < len(rs) {
+				pnl[name] += rs[i] * 1000
+				period[name] = &StrategyStatistics{TotalPnL: pnl[name]}
+			}
+		}
+		if len(period) == 0 {
+			return
+		}
+		da.RecordPeriod(period, allocated)
+	}
+}
+
+func TestDynamicAllocatorRiskParity(t *testing.T) {
+	da := newModeAllocator(AllocationModeRiskParity)
+	da.RegisterStrategy("grid", 0.5)
+	da.RegisterStrategy("trend", 0.5)
+	da.RegisterStrategy("momentum", 0.2)
+
+	feedReturns(da, map[string][]float64{
+		"grid":     {0.01, -0.01, 0.01, -0.01},
+		"trend":    {0.02, -0.02, 0.02, -0.02},
+		"momentum": {0.05, -0.05}, // 样本不足
+	})
+
+	weights := da.CalculateTargetWeights()
+	// grid 波动率是 trend 的一半，两者瓜分原有的 1.0 权重：2/3 与 1/3
+	if math.Abs(weights["grid"]-2.0/3) > 1e-9 || math.Abs(weights["trend"]-1.0/3) > 1e-9 {
+		t.Fatalf("unexpected risk parity weights: %v", weights)
+	}
+	if weights["momentum"] != 0.2 {
+		t.Fatalf("strategy without enough samples should keep its weight, got %v", weights["momentum"])
+	}
+}
+
+func TestDynamicAllocatorKellyAndAudit(t *testing.T) {
+	da := newModeAllocator(AllocationModeKelly)
+	bus := &recordingEventBus{}
+	da.SetEventBus(bus)
+	da.RegisterStrategy("grid", 0.5)
+	da.RegisterStrategy("trend", 0.5)
+
+	feedReturns(da, map[string][]float64{
+		"grid":  {0.02, 0.0, 0.02, 0.0},   // 正期望
+		"trend": {-0.02, 0.0, -0.02, 0.0}, // 负期望
+	})
+
+	weights := da.CalculateTargetWeights()
+	if weights["trend"] != 0.05 {
+		t.Fatalf("negative edge should fall to min weight, got %v", weights["trend"])
+	}
+	if weights["grid"] != 0.9 {
+		t.Fatalf("positive edge should take the budget up to max weight, got %v", weights["grid"])
+	}
+
+	da.Rebalance(weights)
+	changes := da.GetWeightChanges()
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 audited weight changes, got %d", len(changes))
+	}
+	for _, ch := range changes {
+		if ch.Mode != AllocationModeKelly || ch.Reason == "" || ch.OldWeight != 0.5 {
+			t.Fatalf("incomplete audit record: %+v", ch)
+		}
+	}
+	if len(bus.events) != 1 || bus.events[0].Type != event.EventTypeCapitalRebalanced {
+		t.Fatalf("expected one capital_rebalanced event, got %v", bus.events)
+	}
+}
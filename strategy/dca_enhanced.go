@@ -62,6 +62,9 @@ type DCAEnhancedStrategy struct {
 
 	// 事件总线
 	eventBus EventBus
+
+	// 共享的多周期趋势服务（可选，设置后趋势过滤不再自行计算均线）
+	trendService *TrendService
 }
 
 // DCAEnhancedConfig 增强型 DCA 配置
@@ -768,8 +771,16 @@ func (s *DCAEnhancedStrategy) closeAllPositions(price float64, reason string) er
 	return nil
 }
 
+// SetTrendService 设置共享的多周期趋势服务
+func (s *DCAEnhancedStrategy) SetTrendService(ts *TrendService) {
+	s.trendService = ts
+}
+
 // isTrendUp 判断趋势是否向上
 func (s *DCAEnhancedStrategy) isTrendUp() bool {
+	if s.trendService != nil {
+		return s.trendService.Consensus() != TrendDown
+	}
 	if len(s.priceHistory) < s.strategyCfg.TrendPeriod*2 {
 		return true // 数据不足，默认允许开仓
 	}
@@ -0,0 +1,154 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/indicators"
+	"quantmesh/logger"
+	"quantmesh/utils"
+)
+
+// LevelSnapshot 支撑/阻力位与K线形态检测结果
+type LevelSnapshot struct {
+	Symbol      string                     `json:"symbol"`
+	Interval    string                     `json:"interval"`
+	Close       float64                    `json:"close"`
+	Supports    []indicators.Level         `json:"supports"`    // 按离收盘价由近到远
+	Resistances []indicators.Level         `json:"resistances"` // 按离收盘价由近到远
+	Patterns    []indicators.CandlePattern `json:"patterns"`
+	UpdatedAt   time.Time                  `json:"updated_at"`
+}
+
+// LevelService 支撑/阻力位与K线形态检测服务
+// 每个交易对一个实例，定期拉取已收盘K线计算关键价位和常见形态，结果供 API 展示和网格偏置使用
+type LevelService struct {
+	symbol string
+	source KlineSource
+	cfg    config.LevelsConfig
+
+	mu        sync.RWMutex
+	snapshot  *LevelSnapshot
+	listeners []func(LevelSnapshot)
+	cancel    context.CancelFunc
+}
+
+// NewLevelService 创建支撑/阻力位检测服务
+func NewLevelService(symbol string, source KlineSource, cfg config.LevelsConfig) *LevelService {
+	return &LevelService{symbol: symbol, source: source, cfg: cfg}
+}
+
+// OnUpdate 注册检测结果回调（每次刷新成功后触发）
+func (ls *LevelService) OnUpdate(fn func(LevelSnapshot)) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.listeners = append(ls.listeners, fn)
+}
+
+// Snapshot 获取最近一次检测结果（尚未计算出结果时返回 false）
+func (ls *LevelService) Snapshot() (LevelSnapshot, bool) {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	if ls.snapshot == nil {
+		return LevelSnapshot{}, false
+	}
+	return *ls.snapshot, true
+}
+
+// Start 立即检测一次，之后按 check_interval 刷新
+func (ls *LevelService) Start(ctx context.Context) {
+	interval := time.Duration(ls.cfg.CheckInterval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	ls.mu.Lock()
+	ls.cancel = cancel
+	ls.mu.Unlock()
+	utils.GoSupervised(ctx, fmt.Sprintf("level-service:%s", ls.symbol), func(ctx context.Context) {
+		ls.update(ctx)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ls.update(ctx)
+			}
+		}
+	})
+}
+
+// Stop 停止检测服务
+func (ls *LevelService) Stop() {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if ls.cancel != nil {
+		ls.cancel()
+	}
+}
+
+func (ls *LevelService) update(ctx context.Context) {
+	reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	klines, err := ls.source.GetHistoricalKlines(reqCtx, ls.symbol, ls.cfg.Interval, ls.cfg.Lookback)
+	if err != nil {
+		logger.Warn("⚠️ [%s] 支撑/阻力位检测获取K线失败: %v", ls.symbol, err)
+		return
+	}
+
+	// 只使用已收盘的K线（最后一根可能仍在变化）
+	candles := make([]indicators.Candle, 0, len(klines))
+	for i, k := range klines {
+		if i == len(klines)-1 && !k.IsClosed {
+			continue
+		}
+		candles = append(candles, indicators.Candle{
+			Time: k.Timestamp, Open: k.Open, High: k.High, Low: k.Low, Close: k.Close, Volume: k.Volume,
+		})
+	}
+	snap, ok := ComputeLevels(candles, ls.cfg)
+	if !ok {
+		return
+	}
+	snap.Symbol = ls.symbol
+	snap.UpdatedAt = time.Now()
+
+	ls.mu.Lock()
+	ls.snapshot = &snap
+	listeners := append([]func(LevelSnapshot){}, ls.listeners...)
+	ls.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(snap)
+	}
+}
+
+// ComputeLevels 根据已收盘K线计算支撑/阻力位与最近的K线形态
+func ComputeLevels(candles []indicators.Candle, cfg config.LevelsConfig) (LevelSnapshot, bool) {
+	if len(candles) < cfg.PivotWindow*2+1 {
+		return LevelSnapshot{}, false
+	}
+	snap := LevelSnapshot{
+		Interval:    cfg.Interval,
+		Close:       candles[len(candles)-1].Close,
+		Supports:    []indicators.Level{},
+		Resistances: []indicators.Level{},
+		Patterns:    indicators.DetectPatterns(candles, cfg.PatternBars),
+	}
+	for _, level := range indicators.DetectLevels(candles, cfg.PivotWindow, cfg.Tolerance/100, cfg.MaxLevels) {
+		if level.Kind == indicators.LevelSupport {
+			snap.Supports = append(snap.Supports, level)
+		} else {
+			snap.Resistances = append(snap.Resistances, level)
+		}
+	}
+	if snap.Patterns == nil {
+		snap.Patterns = []indicators.CandlePattern{}
+	}
+	return snap, true
+}
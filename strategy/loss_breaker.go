@@ -0,0 +1,218 @@
+package strategy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+)
+
+// LossBreakerStatus 单个策略的亏损熔断状态
+type LossBreakerStatus struct {
+	Strategy          string     `json:"strategy"`
+	ConsecutiveLosses int        `json:"consecutive_losses"`
+	WindowPnL         float64    `json:"window_pnl"` // 滚动窗口内已完成交易的累计盈亏
+	Paused            bool       `json:"paused"`
+	Reason            string     `json:"reason,omitempty"`
+	PausedAt          *time.Time `json:"paused_at,omitempty"`
+	ResumeAt          *time.Time `json:"resume_at,omitempty"` // 自动恢复时间（仅手动恢复时为空）
+}
+
+type roundTrip struct {
+	at  time.Time
+	pnl float64
+}
+
+type lossBreakerState struct {
+	lastTrades  int     // 上次检查时的累计交易次数
+	lastPnL     float64 // 上次检查时的累计盈亏
+	consecutive int
+	trips       []roundTrip
+	paused      bool
+	reason      string
+	pausedAt    time.Time
+	resumeAt    time.Time
+}
+
+// LossBreaker 单策略连续亏损熔断
+// 通过策略的累计统计识别新完成的交易（开仓到平仓），连续亏损次数或滚动窗口内亏损占分配资金的比例超限时
+// 标记该策略暂停，冷却结束后自动恢复（manual_resume 时只能手动恢复）
+type LossBreaker struct {
+	cfg config.StrategyLossBreakerConfig
+
+	mu     sync.Mutex
+	states map[string]*lossBreakerState
+}
+
+// NewLossBreaker 创建策略亏损熔断
+func NewLossBreaker(cfg config.StrategyLossBreakerConfig) *LossBreaker {
+	return &LossBreaker{
+		cfg:    cfg,
+		states: make(map[string]*lossBreakerState),
+	}
+}
+
+// Observe 对比策略累计统计，将新完成的交易计入熔断判断；返回本次是否触发熔断及原因。
+// 两次检查之间完成多笔交易时按平均盈亏逐笔计入
+func (lb *LossBreaker) Observe(name string, stats StrategyStatistics, capital float64, now time.Time) (bool, string) {
+	lb.mu.Lock()
+	state := lb.state(name)
+	if stats.TotalTrades < state.lastTrades {
+		// 统计被重置（策略重建），重新建立基准
+		state.lastTrades, state.lastPnL = stats.TotalTrades, stats.TotalPnL
+	}
+	count := stats.TotalTrades - state.lastTrades
+	delta := stats.TotalPnL - state.lastPnL
+	state.lastTrades, state.lastPnL = stats.TotalTrades, stats.TotalPnL
+	lb.mu.Unlock()
+
+	tripped, reason := false, ""
+	for i := 0; i < count; i++ {
+		if t, r := lb.RecordRoundTrip(name, delta/float64(count), capital, now); t {
+			tripped, reason = true, r
+		}
+	}
+	return tripped, reason
+}
+
+// RecordRoundTrip 记录一笔已完成交易的盈亏；返回本次是否触发熔断及原因
+func (lb *LossBreaker) RecordRoundTrip(name string, pnl, capital float64, now time.Time) (bool, string) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	state := lb.state(name)
+	if pnl < 0 {
+		state.consecutive++
+	} else {
+		state.consecutive = 0
+	}
+	state.trips = append(state.trips, roundTrip{at: now, pnl: pnl})
+	windowPnL := lb.pruneWindow(state, now)
+
+	if state.paused {
+		return false, ""
+	}
+
+	var reason string
+	switch {
+	case state.consecutive >= lb.cfg.MaxConsecutiveLosses:
+		reason = fmt.Sprintf("连续亏损 %d 次", state.consecutive)
+	case lb.cfg.MaxLossPercent > 0 && capital > 0 && windowPnL <= -capital*lb.cfg.MaxLossPercent/100:
+		reason = fmt.Sprintf("%d 分钟内亏损 %.2f，超过分配资金 %.2f 的 %.1f%%",
+			lb.cfg.WindowMinutes, -windowPnL, capital, lb.cfg.MaxLossPercent)
+	default:
+		return false, ""
+	}
+
+	state.paused = true
+	state.reason = reason
+	state.pausedAt = now
+	state.resumeAt = time.Time{}
+	if !lb.cfg.ManualResume {
+		state.resumeAt = now.Add(time.Duration(lb.cfg.CooldownMinutes) * time.Minute)
+	}
+	return true, reason
+}
+
+// IsPaused 策略是否处于熔断暂停中
+func (lb *LossBreaker) IsPaused(name string) bool {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	state, ok := lb.states[name]
+	return ok && state.paused
+}
+
+// Resume 解除策略的熔断暂停并清空亏损计数；策略未暂停时返回 false
+func (lb *LossBreaker) Resume(name string) bool {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	state, ok := lb.states[name]
+	if !ok || !state.paused {
+		return false
+	}
+	lb.reset(state)
+	return true
+}
+
+// ResumeExpired 解除冷却时间已到的熔断暂停，返回恢复的策略名称
+func (lb *LossBreaker) ResumeExpired(now time.Time) []string {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	var resumed []string
+	for name, state := range lb.states {
+		if state.paused && !state.resumeAt.IsZero() && !now.Before(state.resumeAt) {
+			lb.reset(state)
+			resumed = append(resumed, name)
+		}
+	}
+	sort.Strings(resumed)
+	return resumed
+}
+
+// Status 获取指定策略的熔断状态（按名称排序）
+func (lb *LossBreaker) Status(names []string, now time.Time) []LossBreakerStatus {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	result := make([]LossBreakerStatus, 0, len(sorted))
+	for _, name := range sorted {
+		state := lb.state(name)
+		status := LossBreakerStatus{
+			Strategy:          name,
+			ConsecutiveLosses: state.consecutive,
+			WindowPnL:         lb.pruneWindow(state, now),
+			Paused:            state.paused,
+			Reason:            state.reason,
+		}
+		if state.paused {
+			pausedAt := state.pausedAt
+			status.PausedAt = &pausedAt
+			if !state.resumeAt.IsZero() {
+				resumeAt := state.resumeAt
+				status.ResumeAt = &resumeAt
+			}
+		}
+		result = append(result, status)
+	}
+	return result
+}
+
+func (lb *LossBreaker) state(name string) *lossBreakerState {
+	state, ok := lb.states[name]
+	if !ok {
+		state = &lossBreakerState{}
+		lb.states[name] = state
+	}
+	return state
+}
+
+// pruneWindow 移除滚动窗口之外的交易，返回窗口内累计盈亏
+func (lb *LossBreaker) pruneWindow(state *lossBreakerState, now time.Time) float64 {
+	cutoff := now.Add(-time.Duration(lb.cfg.WindowMinutes) * time.Minute)
+	drop := 0
+	for drop < len(state.trips) && state.trips[drop].at.Before(cutoff) {
+		drop++
+	}
+	state.trips = state.trips[drop:]
+
+	var total float64
+	for _, t := range state.trips {
+		total += t.pnl
+	}
+	return total
+}
+
+// reset 解除暂停，恢复后重新开始统计连续亏损和窗口亏损
+func (lb *LossBreaker) reset(state *lossBreakerState) {
+	state.paused = false
+	state.reason = ""
+	state.pausedAt = time.Time{}
+	state.resumeAt = time.Time{}
+	state.consecutive = 0
+	state.trips = nil
+}
@@ -0,0 +1,133 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/position"
+)
+
+func TestLossBreakerConsecutiveLosses(t *testing.T) {
+	lb := NewLossBreaker(config.StrategyLossBreakerConfig{
+		MaxConsecutiveLosses: 3,
+		WindowMinutes:        60,
+		CooldownMinutes:      30,
+	})
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 盈利交易会重置连续亏损计数
+	for _, pnl := range []float64{-1, -1, 2, -1, -1} {
+		if tripped, _ := lb.RecordRoundTrip("dca", pnl, 1000, now); tripped {
+			t.Fatalf("should not trip before %d consecutive losses", 3)
+		}
+	}
+	if tripped, _ := lb.RecordRoundTrip("dca", -1, 1000, now); !tripped {
+		t.Fatal("third consecutive loss should trip the breaker")
+	}
+	if !lb.IsPaused("dca") || lb.IsPaused("martingale") {
+		t.Fatal("only the losing strategy should be paused")
+	}
+
+	if resumed := lb.ResumeExpired(now.Add(29 * time.Minute)); len(resumed) != 0 {
+		t.Fatalf("resumed before cooldown: %v", resumed)
+	}
+	if resumed := lb.ResumeExpired(now.Add(30 * time.Minute)); len(resumed) != 1 || resumed[0] != "dca" {
+		t.Fatalf("expected dca to resume after cooldown, got %v", resumed)
+	}
+	status := lb.Status([]string{"dca"}, now.Add(30*time.Minute))
+	if status[0].Paused || status[0].ConsecutiveLosses != 0 {
+		t.Errorf("resume should clear loss streak: %+v", status[0])
+	}
+}
+
+func TestLossBreakerWindowLossAndManualResume(t *testing.T) {
+	lb := NewLossBreaker(config.StrategyLossBreakerConfig{
+		MaxConsecutiveLosses: 10,
+		MaxLossPercent:       5,
+		WindowMinutes:        60,
+		CooldownMinutes:      30,
+		ManualResume:         true,
+	})
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 窗口外的亏损不计入
+	lb.RecordRoundTrip("martingale", -40, 1000, now)
+	lb.RecordRoundTrip("martingale", 5, 1000, now.Add(61*time.Minute))
+	if tripped, _ := lb.RecordRoundTrip("martingale", -30, 1000, now.Add(62*time.Minute)); tripped {
+		t.Fatal("loss outside the rolling window should be ignored")
+	}
+
+	// 累计统计中两次检查间完成两笔交易，共亏损 30
+	stats := StrategyStatistics{TotalTrades: 2, TotalPnL: -30}
+	tripped, reason := lb.Observe("martingale", stats, 1000, now.Add(63*time.Minute))
+	if !tripped || reason == "" {
+		t.Fatal("window loss above 5% of capital should trip the breaker")
+	}
+
+	if resumed := lb.ResumeExpired(now.Add(24 * time.Hour)); len(resumed) != 0 {
+		t.Fatalf("manual_resume should never auto resume, got %v", resumed)
+	}
+	if !lb.Resume("martingale") || lb.IsPaused("martingale") {
+		t.Fatal("manual resume failed")
+	}
+	if lb.Resume("martingale") {
+		t.Error("resuming a running strategy should report false")
+	}
+}
+
+type breakerStubStrategy struct {
+	prices chan float64
+}
+
+func (s *breakerStubStrategy) Name() string { return "stub" }
+func (s *breakerStubStrategy) Initialize(*config.Config, position.OrderExecutorInterface, position.IExchange) error {
+	return nil
+}
+func (s *breakerStubStrategy) OnPriceChange(price float64) error         { s.prices <- price; return nil }
+func (s *breakerStubStrategy) OnOrderUpdate(*position.OrderUpdate) error { return nil }
+func (s *breakerStubStrategy) GetPositions() []*Position                 { return nil }
+func (s *breakerStubStrategy) GetOrders() []*Order                       { return nil }
+func (s *breakerStubStrategy) GetStatistics() *StrategyStatistics        { return &StrategyStatistics{} }
+func (s *breakerStubStrategy) Start(context.Context) error               { return nil }
+func (s *breakerStubStrategy) Stop() error                               { return nil }
+func (s *breakerStubStrategy) SetEventBus(EventBus)                      {}
+
+func TestStrategyManagerSkipsPausedStrategy(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Strategies.Configs = map[string]config.StrategyConfig{"dca": {Enabled: true}}
+	cfg.Strategies.LossBreaker = config.StrategyLossBreakerConfig{
+		Enabled:              true,
+		MaxConsecutiveLosses: 1,
+		WindowMinutes:        60,
+		CooldownMinutes:      30,
+	}
+	sm := NewStrategyManager(cfg, 1000)
+	stub := &breakerStubStrategy{prices: make(chan float64, 1)}
+	sm.RegisterStrategy("dca", stub, 1, 0)
+
+	sm.lossBreaker.RecordRoundTrip("dca", -1, 1000, time.Now())
+	if !sm.IsStrategyPaused("dca") {
+		t.Fatal("strategy should be paused after a loss")
+	}
+	sm.OnPriceChange(100)
+	select {
+	case <-stub.prices:
+		t.Fatal("paused strategy should not receive price updates")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := sm.ResumeStrategy("missing"); err == nil {
+		t.Error("resuming an unknown strategy should fail")
+	}
+	if err := sm.ResumeStrategy("dca"); err != nil {
+		t.Fatalf("manual resume failed: %v", err)
+	}
+	sm.OnPriceChange(101)
+	select {
+	case <-stub.prices:
+	case <-time.After(time.Second):
+		t.Fatal("resumed strategy should receive price updates")
+	}
+}
@@ -92,6 +92,7 @@ func (mse *MultiStrategyExecutor) PlaceOrder(strategyName string, req *position.
 		PriceDecimals: req.PriceDecimals,
 		ReduceOnly:    req.ReduceOnly,
 		PostOnly:      req.PostOnly,
+		StrictPost:    req.StrictPost,
 		ClientOrderID: req.ClientOrderID,
 		StrategyName:  strategyName,
 		StrategyType:  extractStrategyType(strategyName),
@@ -161,6 +162,7 @@ func (mse *MultiStrategyExecutor) BatchPlaceOrdersWithDetails(strategyName strin
 			PriceDecimals: req.PriceDecimals,
 			ReduceOnly:    req.ReduceOnly,
 			PostOnly:      req.PostOnly,
+			StrictPost:    req.StrictPost,
 			ClientOrderID: req.ClientOrderID,
 			StrategyName:  strategyName,
 			StrategyType:  extractStrategyType(strategyName),
@@ -0,0 +1,396 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"quantmesh/indicators"
+	"quantmesh/logger"
+	"quantmesh/storage"
+	"quantmesh/utils"
+)
+
+// 组合优化器比较的资金分配方案
+const (
+	PortfolioMixCurrent     = "current"      // 最近一天的实际分配比例
+	PortfolioMixEqual       = "equal"        // 等权
+	PortfolioMixVolWeighted = "vol_weighted" // 按日收益波动率倒数分配
+)
+
+// annualizationDays 年化夏普比率使用的天数（加密市场全年交易）
+const annualizationDays = 365
+
+// PortfolioSource 一个交易对的策略管理器（组合优化器从中读取各策略累计盈亏与分配资金）
+type PortfolioSource struct {
+	Exchange string
+	Symbol   string
+	Manager  *StrategyManager
+}
+
+// StrategyReturnStats 单个策略在回看期内的日收益统计
+type StrategyReturnStats struct {
+	Strategy    string  `json:"strategy"`
+	PnL         float64 `json:"pnl"`
+	MeanReturn  float64 `json:"mean_return"`
+	Volatility  float64 `json:"volatility"`
+	TotalReturn float64 `json:"total_return"`
+}
+
+// PortfolioMix 一种资金分配方案在回看期内的假设表现
+type PortfolioMix struct {
+	Name        string             `json:"name"`
+	Weights     map[string]float64 `json:"weights"`
+	MeanReturn  float64            `json:"mean_return"`  // 日均收益率
+	Volatility  float64            `json:"volatility"`   // 日收益率标准差
+	Sharpe      float64            `json:"sharpe"`       // 年化夏普比率（无风险利率按 0 计）
+	TotalReturn float64            `json:"total_return"` // 回看期复利总收益率
+	MaxDrawdown float64            `json:"max_drawdown"`
+}
+
+// PortfolioReport 组合优化分析结果
+type PortfolioReport struct {
+	GeneratedAt  time.Time             `json:"generated_at"`
+	LookbackDays int                   `json:"lookback_days"`
+	Days         int                   `json:"days"` // 回看期内有收益记录的天数
+	Strategies   []StrategyReturnStats `json:"strategies"`
+	Mixes        []PortfolioMix        `json:"mixes"`
+	Best         string                `json:"best,omitempty"` // 风险调整后收益最高的方案，样本不足时为空
+	Reason       string                `json:"reason"`
+}
+
+// Recommended 返回最优方案的权重，没有结论时返回 nil
+func (r *PortfolioReport) Recommended() map[string]float64 {
+	if r == nil || r.Best == "" {
+		return nil
+	}
+	for _, mix := range r.Mixes {
+		if mix.Name == r.Best {
+			return mix.Weights
+		}
+	}
+	return nil
+}
+
+// AnalyzePortfolio 用每日策略收益记录比较当前分配、等权和波动率倒数加权三种方案，
+// 选出年化夏普比率最高的方案；有记录的天数少于 minDays 时只给出各方案表现，不给出结论
+// 同一日期同一策略在多个交易对上的记录合并计算：日收益率 = 合计盈亏 / 合计分配资金
+func AnalyzePortfolio(records []*storage.StrategyReturn, lookbackDays, minDays int) *PortfolioReport {
+	report := &PortfolioReport{GeneratedAt: time.Now(), LookbackDays: lookbackDays}
+
+	type daily struct{ pnl, allocated float64 }
+	byDate := make(map[time.Time]map[string]*daily)
+	names := make(map[string]bool)
+	for _, r := range records {
+		date := utils.ToUTC(r.Date)
+		if byDate[date] == nil {
+			byDate[date] = make(map[string]*daily)
+		}
+		d := byDate[date][r.Strategy]
+		if d == nil {
+			d = &daily{}
+			byDate[date][r.Strategy] = d
+		}
+		d.pnl += r.PnL
+		d.allocated += r.Allocated
+		names[r.Strategy] = true
+	}
+
+	dates := make([]time.Time, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	strategies := make([]string, 0, len(names))
+	for name := range names {
+		strategies = append(strategies, name)
+	}
+	sort.Strings(strategies)
+	report.Days = len(dates)
+	if len(strategies) == 0 {
+		report.Reason = "没有策略收益记录"
+		return report
+	}
+
+	returns := make(map[string][]float64, len(strategies))
+	for _, name := range strategies {
+		series := make([]float64, len(dates))
+		stats := StrategyReturnStats{Strategy: name}
+		for i, date := range dates {
+			if d := byDate[date][name]; d != nil {
+				stats.PnL += d.pnl
+				if d.allocated > 0 {
+					series[i] = d.pnl / d.allocated
+				}
+			}
+		}
+		stats.MeanReturn = average(series)
+		stats.Volatility = indicators.SampleStdDev(series)
+		stats.TotalReturn = compound(series)
+		returns[name] = series
+		report.Strategies = append(report.Strategies, stats)
+	}
+
+	// 当前方案：最近一天各策略分配资金的占比
+	current := make(map[string]float64, len(strategies))
+	for name, d := range byDate[dates[len(dates)-1]] {
+		current[name] = d.allocated
+	}
+	equal := make(map[string]float64, len(strategies))
+	for _, name := range strategies {
+		equal[name] = 1
+	}
+	// 波动率倒数加权：波动率为 0（区间内没有盈亏）的策略不参与分配
+	volWeighted := make(map[string]float64, len(strategies))
+	for _, stats := range report.Strategies {
+		if stats.Volatility > 0 {
+			volWeighted[stats.Strategy] = 1 / stats.Volatility
+		}
+	}
+
+	for _, candidate := range []struct {
+		name    string
+		weights map[string]float64
+	}{
+		{PortfolioMixCurrent, current},
+		{PortfolioMixEqual, equal},
+		{PortfolioMixVolWeighted, volWeighted},
+	} {
+		weights := normalizeWeights(candidate.weights, strategies)
+		report.Mixes = append(report.Mixes, evaluateMix(candidate.name, weights, strategies, returns, len(dates)))
+	}
+
+	if report.Days < minDays {
+		report.Reason = fmt.Sprintf("收益记录不足 (%d/%d 天)，暂不给出建议", report.Days, minDays)
+		return report
+	}
+	best := report.Mixes[0]
+	for _, mix := range report.Mixes[1:] {
+		if mix.Sharpe > best.Sharpe {
+			best = mix
+		}
+	}
+	report.Best = best.Name
+	report.Reason = fmt.Sprintf("近 %d 天 %s 方案年化夏普比率最高 (%.2f)", report.Days, best.Name, best.Sharpe)
+	return report
+}
+
+// normalizeWeights 权重归一化，全部为 0 时退化为等权
+func normalizeWeights(raw map[string]float64, strategies []string) map[string]float64 {
+	total := 0.0
+	for _, name := range strategies {
+		if raw[name] > 0 {
+			total += raw[name]
+		}
+	}
+	weights := make(map[string]float64, len(strategies))
+	for _, name := range strategies {
+		switch {
+		case total <= 0:
+			weights[name] = 1 / float64(len(strategies))
+		case raw[name] > 0:
+			weights[name] = raw[name] / total
+		default:
+			weights[name] = 0
+		}
+	}
+	return weights
+}
+
+// evaluateMix 按固定权重回放每日组合收益
+func evaluateMix(name string, weights map[string]float64, strategies []string, returns map[string][]float64, days int) PortfolioMix {
+	series := make([]float64, days)
+	for i := range series {
+		for _, s := range strategies {
+			series[i] += weights[s] * returns[s][i]
+		}
+	}
+	mix := PortfolioMix{
+		Name:        name,
+		Weights:     weights,
+		MeanReturn:  average(series),
+		Volatility:  indicators.SampleStdDev(series),
+		TotalReturn: compound(series),
+	}
+	if mix.Volatility > 0 {
+		mix.Sharpe = mix.MeanReturn / mix.Volatility * math.Sqrt(annualizationDays)
+	}
+	equity, peak := 1.0, 1.0
+	for _, r := range series {
+		equity *= 1 + r
+		peak = math.Max(peak, equity)
+		mix.MaxDrawdown = math.Max(mix.MaxDrawdown, (peak-equity)/peak)
+	}
+	return mix
+}
+
+// average 算术平均
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// compound 日收益率复利得到的总收益率
+func compound(values []float64) float64 {
+	equity := 1.0
+	for _, v := range values {
+		equity *= 1 + v
+	}
+	return equity - 1
+}
+
+// PortfolioOptimizer 组合优化任务：定期把各策略当日盈亏与分配资金写入存储，
+// 并用最近 lookback_days 天的记录比较不同资金分配方案，结果供再平衡建议接口使用
+type PortfolioOptimizer struct {
+	store        storage.Storage
+	sources      func() []PortfolioSource
+	interval     time.Duration
+	lookbackDays int
+	minDays      int
+
+	mu        sync.RWMutex
+	day       time.Time
+	baselines map[string]float64 // 当日起点的策略累计盈亏
+	offsets   map[string]float64 // 重启前当日已记录的盈亏
+	last      map[string]float64 // 最近一次观察到的策略累计盈亏
+	report    *PortfolioReport
+}
+
+// NewPortfolioOptimizer 创建组合优化任务，interval/lookbackDays/minDays 为 0 时使用默认值（1小时/30天/7天）
+func NewPortfolioOptimizer(store storage.Storage, sources func() []PortfolioSource, interval time.Duration, lookbackDays, minDays int) *PortfolioOptimizer {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	if lookbackDays <= 0 {
+		lookbackDays = 30
+	}
+	if minDays <= 0 {
+		minDays = 7
+	}
+	return &PortfolioOptimizer{
+		store:        store,
+		sources:      sources,
+		interval:     interval,
+		lookbackDays: lookbackDays,
+		minDays:      minDays,
+		baselines:    make(map[string]float64),
+		offsets:      make(map[string]float64),
+		last:         make(map[string]float64),
+	}
+}
+
+// Start 启动后立即记录并分析一次，之后按间隔执行
+func (po *PortfolioOptimizer) Start(ctx context.Context) {
+	utils.GoSupervised(ctx, "portfolio-optimizer", func(ctx context.Context) {
+		ticker := time.NewTicker(po.interval)
+		defer ticker.Stop()
+		for {
+			now := time.Now()
+			if err := po.Record(now); err != nil {
+				logger.Warn("⚠️ [组合优化] 记录策略收益失败: %v", err)
+			}
+			if _, err := po.Analyze(now); err != nil {
+				logger.Warn("⚠️ [组合优化] 分析失败: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Record 记录各策略当日盈亏（当日起点之后的累计盈亏变化）与当前分配资金
+func (po *PortfolioOptimizer) Record(now time.Time) error {
+	day := utils.ToUTC(now).Truncate(24 * time.Hour)
+
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	if po.day.IsZero() {
+		// 首次记录：当日已有的记录作为偏移，避免重启后覆盖当日盈亏
+		existing, err := po.store.QueryStrategyReturns(day, day)
+		if err != nil {
+			return err
+		}
+		for _, r := range existing {
+			po.offsets[returnKey(r.Exchange, r.Symbol, r.Strategy)] = r.PnL
+		}
+		po.day = day
+	} else if day.After(po.day) {
+		// 跨日：上次观察值作为新一天的起点
+		po.day = day
+		po.offsets = make(map[string]float64)
+		po.baselines = po.last
+		po.last = make(map[string]float64, len(po.baselines))
+		for key, pnl := range po.baselines {
+			po.last[key] = pnl
+		}
+	}
+
+	var firstErr error
+	for _, src := range po.sources() {
+		if src.Manager == nil {
+			continue
+		}
+		capitals := src.Manager.GetCapitalAllocator().GetAllStrategiesCapital()
+		for name, stats := range src.Manager.collectStatistics() {
+			key := returnKey(src.Exchange, src.Symbol, name)
+			if _, ok := po.baselines[key]; !ok {
+				po.baselines[key] = stats.TotalPnL
+			}
+			po.last[key] = stats.TotalPnL
+			r := &storage.StrategyReturn{
+				Date:     day,
+				Exchange: src.Exchange,
+				Symbol:   src.Symbol,
+				Strategy: name,
+				PnL:      po.offsets[key] + stats.TotalPnL - po.baselines[key],
+			}
+			if capital, ok := capitals[name]; ok {
+				r.Allocated = capital.Allocated
+			}
+			if err := po.store.SaveStrategyReturn(r); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// Analyze 用最近 lookback_days 天的记录重新计算组合建议
+func (po *PortfolioOptimizer) Analyze(now time.Time) (*PortfolioReport, error) {
+	end := utils.ToUTC(now).Truncate(24 * time.Hour)
+	start := end.AddDate(0, 0, -(po.lookbackDays - 1))
+	records, err := po.store.QueryStrategyReturns(start, end)
+	if err != nil {
+		return nil, err
+	}
+	report := AnalyzePortfolio(records, po.lookbackDays, po.minDays)
+	report.GeneratedAt = now
+
+	po.mu.Lock()
+	po.report = report
+	po.mu.Unlock()
+	return report, nil
+}
+
+// GetReport 获取最近一次分析结果，尚未分析时返回 nil
+func (po *PortfolioOptimizer) GetReport() *PortfolioReport {
+	po.mu.RLock()
+	defer po.mu.RUnlock()
+	return po.report
+}
+
+func returnKey(exchange, symbol, strategy string) string {
+	return exchange + ":" + symbol + ":" + strategy
+}
@@ -0,0 +1,119 @@
+package strategy
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/storage"
+)
+
+func TestAnalyzePortfolio(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var records []*storage.StrategyReturn
+	for i := 0; i < 10; i++ {
+		gridAlloc, trendAlloc := 1000.0, 1000.0
+		if i == 9 {
+			gridAlloc, trendAlloc = 250, 750
+		}
+		gridRet, trendRet := 0.01, 0.05
+		if i%2 == 1 {
+			gridRet, trendRet = 0.012, -0.04
+		}
+		date := start.AddDate(0, 0, i)
+		records = append(records,
+			&storage.StrategyReturn{Date: date, Exchange: "binance", Symbol: "BTCUSDT", Strategy: "grid", PnL: gridRet * gridAlloc, Allocated: gridAlloc},
+			&storage.StrategyReturn{Date: date, Exchange: "binance", Symbol: "BTCUSDT", Strategy: "trend", PnL: trendRet * trendAlloc / 2, Allocated: trendAlloc / 2},
+			// 同一策略在另一个交易对上的记录合并计算
+			&storage.StrategyReturn{Date: date, Exchange: "binance", Symbol: "ETHUSDT", Strategy: "trend", PnL: trendRet * trendAlloc / 2, Allocated: trendAlloc / 2},
+		)
+	}
+
+	report := AnalyzePortfolio(records, 30, 7)
+	if report.Days != 10 || len(report.Mixes) != 3 || len(report.Strategies) != 2 {
+		t.Fatalf("unexpected report shape: %+v", report)
+	}
+	current := report.Mixes[0]
+	if current.Name != PortfolioMixCurrent || math.Abs(current.Weights["grid"]-0.25) > 1e-9 || math.Abs(current.Weights["trend"]-0.75) > 1e-9 {
+		t.Errorf("current weights = %v, want grid 0.25 / trend 0.75", current.Weights)
+	}
+	if report.Best != PortfolioMixVolWeighted {
+		t.Errorf("best = %s, want %s (mixes %+v)", report.Best, PortfolioMixVolWeighted, report.Mixes)
+	}
+	if w := report.Recommended(); w["grid"] <= w["trend"] {
+		t.Errorf("vol-weighted mix should favour the low-volatility strategy: %v", w)
+	}
+
+	report = AnalyzePortfolio(records[:9], 30, 7)
+	if report.Best != "" || report.Recommended() != nil {
+		t.Errorf("3 days of records should not produce a recommendation: %+v", report)
+	}
+}
+
+type pnlStubStrategy struct {
+	breakerStubStrategy
+	pnl float64
+}
+
+func (s *pnlStubStrategy) GetStatistics() *StrategyStatistics {
+	return &StrategyStatistics{TotalPnL: s.pnl}
+}
+
+func TestPortfolioOptimizerRecord(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Strategies.Configs = map[string]config.StrategyConfig{"grid": {Enabled: true}}
+	store := storage.NewMemoryStorage()
+	newOptimizer := func(pnl float64) (*PortfolioOptimizer, *pnlStubStrategy) {
+		sm := NewStrategyManager(cfg, 1000)
+		stub := &pnlStubStrategy{pnl: pnl}
+		sm.RegisterStrategy("grid", stub, 1, 0)
+		sm.GetCapitalAllocator().Allocate()
+		sources := func() []PortfolioSource {
+			return []PortfolioSource{{Exchange: "binance", Symbol: "BTCUSDT", Manager: sm}}
+		}
+		return NewPortfolioOptimizer(store, sources, time.Hour, 30, 2), stub
+	}
+	pnlOn := func(day time.Time) float64 {
+		records, err := store.QueryStrategyReturns(day, day)
+		if err != nil || len(records) != 1 {
+			t.Fatalf("records on %s: %+v (%v)", day, records, err)
+		}
+		return records[0].PnL
+	}
+
+	day1 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	po, stub := newOptimizer(100)
+	if err := po.Record(day1.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	stub.pnl = 110
+	po.Record(day1.Add(2 * time.Hour))
+	if got := pnlOn(day1); got != 10 {
+		t.Fatalf("day1 pnl = %v, want 10", got)
+	}
+
+	// 重启后策略累计盈亏从 0 开始，当日已记录的盈亏不丢失
+	po, stub = newOptimizer(0)
+	po.Record(day1.Add(3 * time.Hour))
+	stub.pnl = 5
+	po.Record(day1.Add(4 * time.Hour))
+	if got := pnlOn(day1); got != 15 {
+		t.Fatalf("day1 pnl after restart = %v, want 15", got)
+	}
+
+	// 跨日后以上次观察值为起点
+	stub.pnl = 8
+	po.Record(day1.Add(25 * time.Hour))
+	if got := pnlOn(day1.AddDate(0, 0, 1)); got != 3 {
+		t.Fatalf("day2 pnl = %v, want 3", got)
+	}
+
+	report, err := po.Analyze(day1.Add(25 * time.Hour))
+	if err != nil || report.Days != 2 {
+		t.Fatalf("analyze: %+v (%v)", report, err)
+	}
+	if po.GetReport() != report {
+		t.Error("GetReport should return the latest analysis")
+	}
+}
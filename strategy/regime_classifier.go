@@ -0,0 +1,222 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/exchange"
+	"quantmesh/indicators"
+	"quantmesh/logger"
+	"quantmesh/utils"
+)
+
+// Regime 市场状态
+type Regime string
+
+const (
+	RegimeUnknown   Regime = "unknown"    // 数据不足
+	RegimeTrendUp   Regime = "trend_up"   // 上涨趋势
+	RegimeTrendDown Regime = "trend_down" // 下跌趋势
+	RegimeRange     Regime = "range"      // 震荡
+	RegimeHighVol   Regime = "high_vol"   // 高波动
+)
+
+// RegimeMetrics 分类依据
+type RegimeMetrics struct {
+	ADX         float64   `json:"adx"`
+	PlusDI      float64   `json:"plus_di"`
+	MinusDI     float64   `json:"minus_di"`
+	RealizedVol float64   `json:"realized_vol"` // 单根K线对数收益率标准差（%）
+	Candles     int       `json:"candles"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// KlineSource 历史K线数据源（exchange.IExchange 已实现）
+type KlineSource interface {
+	GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*exchange.Candle, error)
+}
+
+// RegimeClassifier 市场状态分类器
+// 定期拉取K线，按 ADX 判断趋势强度、按已实现波动率判断是否高波动，状态变化时通知回调
+type RegimeClassifier struct {
+	symbol string
+	source KlineSource
+	cfg    config.RegimeConfig
+
+	mu        sync.RWMutex
+	current   Regime
+	metrics   RegimeMetrics
+	listeners []func(prev, cur Regime, metrics RegimeMetrics)
+	cancel    context.CancelFunc
+}
+
+// NewRegimeClassifier 创建市场状态分类器
+func NewRegimeClassifier(symbol string, source KlineSource, cfg config.RegimeConfig) *RegimeClassifier {
+	return &RegimeClassifier{
+		symbol:  symbol,
+		source:  source,
+		cfg:     cfg,
+		current: RegimeUnknown,
+	}
+}
+
+// OnChange 注册状态变化回调（首次得出分类结果时也会触发）
+func (rc *RegimeClassifier) OnChange(fn func(prev, cur Regime, metrics RegimeMetrics)) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.listeners = append(rc.listeners, fn)
+}
+
+// Current 获取当前市场状态及分类依据
+func (rc *RegimeClassifier) Current() (Regime, RegimeMetrics) {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.current, rc.metrics
+}
+
+// Start 立即分类一次，之后按 check_interval 定期更新
+func (rc *RegimeClassifier) Start(ctx context.Context) {
+	interval := time.Duration(rc.cfg.CheckInterval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	rc.mu.Lock()
+	rc.cancel = cancel
+	rc.mu.Unlock()
+	utils.GoSupervised(ctx, fmt.Sprintf("regime-classifier:%s", rc.symbol), func(ctx context.Context) {
+		rc.update(ctx)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rc.update(ctx)
+			}
+		}
+	})
+}
+
+// Stop 停止分类器
+func (rc *RegimeClassifier) Stop() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.cancel != nil {
+		rc.cancel()
+	}
+}
+
+// update 拉取K线并更新分类结果
+func (rc *RegimeClassifier) update(ctx context.Context) {
+	reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	klines, err := rc.source.GetHistoricalKlines(reqCtx, rc.symbol, rc.cfg.Interval, rc.cfg.Lookback)
+	if err != nil {
+		logger.Warn("⚠️ [%s] 市场状态分类获取K线失败: %v", rc.symbol, err)
+		return
+	}
+
+	// 只使用已收盘的K线（最后一根可能仍在变化）
+	candles := make([]indicators.Candle, 0, len(klines))
+	for i, k := range klines {
+		if i == len(klines)-1 && !k.IsClosed {
+			continue
+		}
+		candles = append(candles, indicators.Candle{
+			Time: k.Timestamp, Open: k.Open, High: k.High, Low: k.Low, Close: k.Close, Volume: k.Volume,
+		})
+	}
+
+	regime, metrics := ClassifyRegime(candles, rc.cfg)
+	metrics.UpdatedAt = time.Now()
+
+	rc.mu.Lock()
+	prev := rc.current
+	rc.current = regime
+	rc.metrics = metrics
+	listeners := append([]func(prev, cur Regime, metrics RegimeMetrics){}, rc.listeners...)
+	rc.mu.Unlock()
+
+	if regime == prev {
+		return
+	}
+	logger.Info("🧭 [%s] 市场状态: %s -> %s (ADX=%.1f, +DI=%.1f, -DI=%.1f, 波动率=%.3f%%)",
+		rc.symbol, prev, regime, metrics.ADX, metrics.PlusDI, metrics.MinusDI, metrics.RealizedVol)
+	for _, fn := range listeners {
+		fn(prev, regime, metrics)
+	}
+}
+
+// ClassifyRegime 根据K线分类市场状态
+// 高波动优先；否则 ADX 高于阈值为趋势（方向由 +DI/-DI 决定），其余为震荡
+func ClassifyRegime(candles []indicators.Candle, cfg config.RegimeConfig) (Regime, RegimeMetrics) {
+	metrics := RegimeMetrics{Candles: len(candles)}
+	adx := indicators.NewADX(cfg.ADXPeriod).CalculateMulti(candles)
+	if adx == nil || len(adx["adx"]) == 0 {
+		return RegimeUnknown, metrics
+	}
+	last := len(adx["adx"]) - 1
+	metrics.ADX = adx["adx"][last]
+	metrics.PlusDI = adx["plus_di"][last]
+	metrics.MinusDI = adx["minus_di"][last]
+	metrics.RealizedVol = realizedVolatility(candles)
+
+	switch {
+	case metrics.RealizedVol >= cfg.HighVolThreshold:
+		return RegimeHighVol, metrics
+	case metrics.ADX >= cfg.ADXTrendThreshold && metrics.PlusDI >= metrics.MinusDI:
+		return RegimeTrendUp, metrics
+	case metrics.ADX >= cfg.ADXTrendThreshold:
+		return RegimeTrendDown, metrics
+	default:
+		return RegimeRange, metrics
+	}
+}
+
+// realizedVolatility 收盘价对数收益率的标准差（%）
+func realizedVolatility(candles []indicators.Candle) float64 {
+	if len(candles) < 3 {
+		return 0
+	}
+	returns := make([]float64, 0, len(candles)-1)
+	for i := 1; i < len(candles); i++ {
+		if candles[i-1].Close > 0 && candles[i].Close > 0 {
+			returns = append(returns, math.Log(candles[i].Close/candles[i-1].Close))
+		}
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+	return math.Sqrt(variance) * 100
+}
+
+// WindowProfile 市场状态对应的挂单窗口配置
+func (r Regime) WindowProfile(cfg config.RegimeConfig) config.RegimeWindowProfile {
+	switch r {
+	case RegimeTrendUp:
+		return cfg.Profiles.TrendUp
+	case RegimeTrendDown:
+		return cfg.Profiles.TrendDown
+	case RegimeRange:
+		return cfg.Profiles.Range
+	case RegimeHighVol:
+		return cfg.Profiles.HighVol
+	}
+	return config.RegimeWindowProfile{}
+}
@@ -2,12 +2,15 @@ package strategy
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"quantmesh/config"
 	"quantmesh/event"
 	"quantmesh/logger"
 	"quantmesh/position"
+	"quantmesh/utils"
 )
 
 // Strategy 策略接口
@@ -66,6 +69,7 @@ type StrategyManager struct {
 	ctx              context.Context
 	cancel           context.CancelFunc
 	eventBus         EventBus // 新增
+	lossBreaker      *LossBreaker
 }
 
 // NewStrategyManager 创建策略管理器
@@ -80,11 +84,16 @@ func NewStrategyManager(cfg *config.Config, totalCapital float64) *StrategyManag
 		cancel:     cancel,
 	}
 
-	// 如果启用动态分配，创建动态分配器
-	if cfg.Strategies.CapitalAllocation.DynamicAllocation.Enabled {
+	// 如果启用动态分配（或风险平价/凯利模式），创建动态分配器
+	if UsesDynamicWeights(cfg) {
 		sm.dynamicAllocator = NewDynamicAllocator(cfg)
 	}
 
+	// 如果启用单策略亏损熔断，创建熔断器
+	if cfg.Strategies.LossBreaker.Enabled {
+		sm.lossBreaker = NewLossBreaker(cfg.Strategies.LossBreaker)
+	}
+
 	return sm
 }
 
@@ -98,6 +107,9 @@ func (sm *StrategyManager) SetEventBus(eb EventBus) {
 	for _, s := range sm.strategies {
 		s.SetEventBus(eb)
 	}
+	if sm.dynamicAllocator != nil {
+		sm.dynamicAllocator.SetEventBus(eb)
+	}
 }
 
 // RegisterStrategy 注册策略
@@ -173,9 +185,14 @@ func (sm *StrategyManager) StartAll() error {
 	sm.mu.RUnlock()
 
 	// 3. 启动动态分配（如果启用）
-	if sm.dynamicAllocator != nil && sm.cfg.Strategies.CapitalAllocation.DynamicAllocation.Enabled {
-		sm.dynamicAllocator.Start(sm.allocator)
-		logger.Info("✅ 动态资金分配已启动")
+	if sm.dynamicAllocator != nil {
+		sm.dynamicAllocator.Start(sm.allocator, sm.collectStatistics)
+		logger.Info("✅ 动态资金分配已启动 (模式: %s)", sm.cfg.Strategies.CapitalAllocation.Mode)
+	}
+
+	// 4. 启动单策略亏损熔断（如果启用）
+	if sm.lossBreaker != nil {
+		sm.startLossBreaker()
 	}
 
 	return nil
@@ -200,13 +217,13 @@ func (sm *StrategyManager) StopAll() {
 	}
 }
 
-// OnPriceChange 价格变化时通知所有策略
+// OnPriceChange 价格变化时通知所有策略（熔断暂停中的策略不再根据行情开新单）
 func (sm *StrategyManager) OnPriceChange(price float64) {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
 	for name, strategy := range sm.strategies {
-		if sm.IsStrategyEnabled(name) {
+		if sm.IsStrategyEnabled(name) && !sm.IsStrategyPaused(name) {
 			go func(n string, s Strategy) {
 				if err := s.OnPriceChange(price); err != nil {
 					logger.Warn("⚠️ 策略 %s 处理价格变化失败: %v", n, err)
@@ -237,7 +254,109 @@ func (sm *StrategyManager) GetCapitalAllocator() *CapitalAllocator {
 	return sm.allocator
 }
 
+// collectStatistics 收集各策略当前的交易统计
+func (sm *StrategyManager) collectStatistics() map[string]*StrategyStatistics {
+	result := make(map[string]*StrategyStatistics)
+	for name, s := range sm.GetAllStrategies() {
+		if stats := s.GetStatistics(); stats != nil {
+			result[name] = stats
+		}
+	}
+	return result
+}
+
 // GetDynamicAllocator 获取动态分配器
 func (sm *StrategyManager) GetDynamicAllocator() *DynamicAllocator {
 	return sm.dynamicAllocator
 }
+
+// IsStrategyPaused 检查策略是否因亏损熔断而暂停
+func (sm *StrategyManager) IsStrategyPaused(name string) bool {
+	return sm.lossBreaker != nil && sm.lossBreaker.IsPaused(name)
+}
+
+// GetLossBreakerStatus 获取各策略的亏损熔断状态（未启用熔断时返回 nil）
+func (sm *StrategyManager) GetLossBreakerStatus() []LossBreakerStatus {
+	if sm.lossBreaker == nil {
+		return nil
+	}
+	sm.mu.RLock()
+	names := make([]string, 0, len(sm.strategies))
+	for name := range sm.strategies {
+		names = append(names, name)
+	}
+	sm.mu.RUnlock()
+	return sm.lossBreaker.Status(names, time.Now())
+}
+
+// ResumeStrategy 手动恢复因亏损熔断暂停的策略
+func (sm *StrategyManager) ResumeStrategy(name string) error {
+	if sm.lossBreaker == nil {
+		return fmt.Errorf("策略亏损熔断未启用")
+	}
+	if sm.GetStrategy(name) == nil {
+		return fmt.Errorf("策略 %s 不存在", name)
+	}
+	if !sm.lossBreaker.Resume(name) {
+		return fmt.Errorf("策略 %s 未处于熔断暂停状态", name)
+	}
+	logger.Info("▶️ [%s] 策略 %s 已手动解除亏损熔断", sm.cfg.Trading.Symbol, name)
+	return nil
+}
+
+// startLossBreaker 定期检查各策略的交易统计，触发熔断时暂停该策略，冷却结束后自动恢复
+func (sm *StrategyManager) startLossBreaker() {
+	interval := time.Duration(sm.cfg.Strategies.LossBreaker.CheckInterval) * time.Second
+	utils.GoSupervised(sm.ctx, "strategy-loss-breaker:"+sm.cfg.Trading.Symbol, func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sm.checkLossBreaker(time.Now())
+			}
+		}
+	})
+}
+
+// checkLossBreaker 执行一次熔断检查
+func (sm *StrategyManager) checkLossBreaker(now time.Time) {
+	capitals := sm.allocator.GetAllStrategiesCapital()
+	for name, s := range sm.GetAllStrategies() {
+		stats := s.GetStatistics()
+		if stats == nil {
+			continue
+		}
+		var capital float64
+		if c, ok := capitals[name]; ok {
+			capital = c.Allocated
+		}
+		tripped, reason := sm.lossBreaker.Observe(name, *stats, capital, now)
+		if !tripped {
+			continue
+		}
+
+		logger.Warn("⛔ [%s] 策略 %s 触发亏损熔断（%s），暂停该策略", sm.cfg.Trading.Symbol, name, reason)
+		sm.mu.RLock()
+		eb := sm.eventBus
+		sm.mu.RUnlock()
+		if eb != nil {
+			eb.Publish(&event.Event{
+				Type: event.EventTypeStrategyLossBreaker,
+				Data: map[string]interface{}{
+					"exchange": sm.cfg.App.CurrentExchange,
+					"symbol":   sm.cfg.Trading.Symbol,
+					"strategy": name,
+					"reason":   reason,
+					"message":  fmt.Sprintf("策略 %s %s，已暂停", name, reason),
+				},
+			})
+		}
+	}
+
+	for _, name := range sm.lossBreaker.ResumeExpired(now) {
+		logger.Info("▶️ [%s] 策略 %s 亏损熔断冷却结束，已恢复", sm.cfg.Trading.Symbol, name)
+	}
+}
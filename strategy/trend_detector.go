@@ -29,6 +29,10 @@ type TrendDetector struct {
 	cancel       context.CancelFunc
 	priceMonitor *monitor.PriceMonitor
 	currentTrend Trend
+
+	// 设置后趋势取自共享的多周期趋势服务，不再自行维护价格历史
+	trendService *TrendService
+	unsubscribe  func()
 }
 
 // NewTrendDetector 创建趋势检测器
@@ -46,7 +50,7 @@ func NewTrendDetector(cfg *config.Config, priceMonitor *monitor.PriceMonitor) *T
 
 // Start 启动趋势检测器
 func (td *TrendDetector) Start() {
-	if !td.cfg.Trading.SmartPosition.Enabled {
+	if !td.cfg.Trading.SmartPosition.Enabled || td.usingTrendService() {
 		return
 	}
 
@@ -66,6 +70,40 @@ func (td *TrendDetector) Stop() {
 	if td.cancel != nil {
 		td.cancel()
 	}
+	td.mu.Lock()
+	unsubscribe := td.unsubscribe
+	td.unsubscribe = nil
+	td.mu.Unlock()
+	if unsubscribe != nil {
+		unsubscribe()
+	}
+}
+
+// SetTrendService 改用共享的多周期趋势服务（需在 Start 之前调用），当前趋势跟随其综合趋势
+func (td *TrendDetector) SetTrendService(ts *TrendService) {
+	td.mu.Lock()
+	td.trendService = ts
+	td.mu.Unlock()
+
+	unsubscribe := ts.Subscribe(func(snap TrendSnapshot) {
+		td.mu.Lock()
+		prev := td.currentTrend
+		td.currentTrend = snap.Consensus
+		td.mu.Unlock()
+		if prev != snap.Consensus {
+			logger.Info("📊 [趋势变化] %s -> %s（多周期趋势服务）", prev, snap.Consensus)
+		}
+	})
+	td.mu.Lock()
+	td.unsubscribe = unsubscribe
+	td.mu.Unlock()
+}
+
+// usingTrendService 是否使用共享的多周期趋势服务
+func (td *TrendDetector) usingTrendService() bool {
+	td.mu.RLock()
+	defer td.mu.RUnlock()
+	return td.trendService != nil
 }
 
 // watchPriceChanges 监听价格变化
@@ -154,11 +192,15 @@ func (td *TrendDetector) calculateEMA(period int) float64 {
 	return ema
 }
 
-// DetectTrend 检测趋势
+// DetectTrend 检测趋势（使用共享趋势服务时直接返回其综合趋势）
 func (td *TrendDetector) DetectTrend() Trend {
 	td.mu.RLock()
 	defer td.mu.RUnlock()
 
+	if td.trendService != nil {
+		return td.trendService.Consensus()
+	}
+
 	longPeriod := td.cfg.Trading.SmartPosition.TrendDetection.LongPeriod
 	if longPeriod <= 0 {
 		longPeriod = 30
@@ -40,6 +40,9 @@ type TrendFollowingStrategy struct {
 	isPaused bool
 	eventBus EventBus
 
+	// 共享的多周期趋势服务（可选，设置后不再自行计算均线）
+	trendService *TrendService
+
 	ctx    context.Context
 	cancel context.CancelFunc
 }
@@ -207,8 +210,17 @@ func (tfs *TrendFollowingStrategy) calculateEMA(period int) float64 {
 	return ema
 }
 
+// SetTrendService 设置共享的多周期趋势服务
+func (tfs *TrendFollowingStrategy) SetTrendService(ts *TrendService) {
+	tfs.trendService = ts
+}
+
 // detectTrend 检测趋势
 func (tfs *TrendFollowingStrategy) detectTrend() Trend {
+	if tfs.trendService != nil {
+		return tfs.trendService.Consensus()
+	}
+
 	var shortMA, longMA float64
 
 	if tfs.method == "ema" {
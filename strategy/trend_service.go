@@ -0,0 +1,294 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"quantmesh/config"
+	"quantmesh/indicators"
+	"quantmesh/logger"
+	"quantmesh/utils"
+)
+
+// TimeframeTrend 单个周期的趋势状态
+type TimeframeTrend struct {
+	Timeframe string    `json:"timeframe"`
+	Trend     Trend     `json:"trend"`
+	Close     float64   `json:"close"`
+	FastEMA   float64   `json:"fast_ema"`
+	SlowEMA   float64   `json:"slow_ema"`
+	MACD      float64   `json:"macd"`
+	Signal    float64   `json:"signal"`
+	Histogram float64   `json:"histogram"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TrendSnapshot 多周期趋势快照
+type TrendSnapshot struct {
+	Symbol     string                    `json:"symbol"`
+	Consensus  Trend                     `json:"consensus"`
+	Timeframes map[string]TimeframeTrend `json:"timeframes"`
+}
+
+// TrendService 多周期趋势服务
+// 每个交易对一个实例，按周期定期拉取K线计算 EMA/MACD，趋势检测器与各策略订阅同一结果，
+// 避免各自维护价格历史、重复计算且结论不一致
+type TrendService struct {
+	symbol string
+	source KlineSource
+	cfg    config.TrendServiceConfig
+
+	mu          sync.RWMutex
+	states      map[string]TimeframeTrend
+	consensus   Trend
+	subscribers map[int]func(TrendSnapshot)
+	nextSubID   int
+	cancel      context.CancelFunc
+}
+
+// NewTrendService 创建多周期趋势服务
+func NewTrendService(symbol string, source KlineSource, cfg config.TrendServiceConfig) *TrendService {
+	return &TrendService{
+		symbol:      symbol,
+		source:      source,
+		cfg:         cfg,
+		states:      make(map[string]TimeframeTrend),
+		consensus:   TrendSide,
+		subscribers: make(map[int]func(TrendSnapshot)),
+	}
+}
+
+// Subscribe 订阅综合趋势变化（订阅时若已有结果会立即回调一次），返回取消订阅函数
+func (ts *TrendService) Subscribe(fn func(TrendSnapshot)) func() {
+	ts.mu.Lock()
+	id := ts.nextSubID
+	ts.nextSubID++
+	ts.subscribers[id] = fn
+	ready := len(ts.states) > 0
+	ts.mu.Unlock()
+
+	if ready {
+		fn(ts.Snapshot())
+	}
+	return func() {
+		ts.mu.Lock()
+		delete(ts.subscribers, id)
+		ts.mu.Unlock()
+	}
+}
+
+// Consensus 获取综合趋势
+func (ts *TrendService) Consensus() Trend {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.consensus
+}
+
+// Get 获取指定周期的趋势状态（尚未计算出结果时返回 false）
+func (ts *TrendService) Get(timeframe string) (TimeframeTrend, bool) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	state, ok := ts.states[timeframe]
+	return state, ok
+}
+
+// Snapshot 获取多周期趋势快照
+func (ts *TrendService) Snapshot() TrendSnapshot {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	snap := TrendSnapshot{
+		Symbol:     ts.symbol,
+		Consensus:  ts.consensus,
+		Timeframes: make(map[string]TimeframeTrend, len(ts.states)),
+	}
+	for tf, state := range ts.states {
+		snap.Timeframes[tf] = state
+	}
+	return snap
+}
+
+// Start 立即计算所有周期，之后每个周期按 max(check_interval, K线时长/4) 刷新
+func (ts *TrendService) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	ts.mu.Lock()
+	ts.cancel = cancel
+	ts.mu.Unlock()
+
+	minInterval := time.Duration(ts.cfg.CheckInterval) * time.Second
+	if minInterval <= 0 {
+		minInterval = time.Minute
+	}
+	utils.GoSupervised(ctx, fmt.Sprintf("trend-service:%s", ts.symbol), func(ctx context.Context) {
+		nextRefresh := make(map[string]time.Time, len(ts.cfg.Timeframes))
+		ticker := time.NewTicker(minInterval)
+		defer ticker.Stop()
+		for {
+			now := time.Now()
+			for _, tf := range ts.cfg.Timeframes {
+				if now.Before(nextRefresh[tf]) {
+					continue
+				}
+				ts.update(ctx, tf)
+				refresh := timeframeDuration(tf) / 4
+				if refresh < minInterval {
+					refresh = minInterval
+				}
+				nextRefresh[tf] = now.Add(refresh)
+			}
+			ts.publish()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Stop 停止趋势服务
+func (ts *TrendService) Stop() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.cancel != nil {
+		ts.cancel()
+	}
+}
+
+// update 拉取单个周期的K线并更新其趋势状态
+func (ts *TrendService) update(ctx context.Context, timeframe string) {
+	reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	klines, err := ts.source.GetHistoricalKlines(reqCtx, ts.symbol, timeframe, ts.cfg.Lookback)
+	if err != nil {
+		logger.Warn("⚠️ [%s] 趋势服务获取 %s K线失败: %v", ts.symbol, timeframe, err)
+		return
+	}
+
+	// 只使用已收盘的K线（最后一根可能仍在变化）
+	candles := make([]indicators.Candle, 0, len(klines))
+	for i, k := range klines {
+		if i == len(klines)-1 && !k.IsClosed {
+			continue
+		}
+		candles = append(candles, indicators.Candle{
+			Time: k.Timestamp, Open: k.Open, High: k.High, Low: k.Low, Close: k.Close, Volume: k.Volume,
+		})
+	}
+
+	state, ok := ComputeTimeframeTrend(candles, ts.cfg)
+	if !ok {
+		return
+	}
+	state.Timeframe = timeframe
+	state.UpdatedAt = time.Now()
+
+	ts.mu.Lock()
+	ts.states[timeframe] = state
+	ts.mu.Unlock()
+}
+
+// publish 重新计算综合趋势，变化时通知订阅者
+func (ts *TrendService) publish() {
+	ts.mu.Lock()
+	if len(ts.states) == 0 {
+		ts.mu.Unlock()
+		return
+	}
+	trends := make([]Trend, 0, len(ts.states))
+	for _, state := range ts.states {
+		trends = append(trends, state.Trend)
+	}
+	prev := ts.consensus
+	ts.consensus = ConsensusTrend(trends)
+	changed := ts.consensus != prev
+	subscribers := make([]func(TrendSnapshot), 0, len(ts.subscribers))
+	for _, fn := range ts.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	ts.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	snap := ts.Snapshot()
+	logger.Info("📊 [%s] 多周期趋势: %s -> %s", ts.symbol, prev, snap.Consensus)
+	for _, fn := range subscribers {
+		fn(snap)
+	}
+}
+
+// ComputeTimeframeTrend 根据K线计算单个周期的 EMA/MACD 状态
+// 快线在慢线之上且 MACD 柱为正为上涨，反之为下跌，其余为震荡
+func ComputeTimeframeTrend(candles []indicators.Candle, cfg config.TrendServiceConfig) (TimeframeTrend, bool) {
+	macd := indicators.NewMACD(cfg.FastPeriod, cfg.SlowPeriod, cfg.SignalPeriod).CalculateMulti(candles)
+	if macd == nil || len(macd["histogram"]) == 0 {
+		return TimeframeTrend{}, false
+	}
+	closes := indicators.ClosePrices(candles)
+	fast := indicators.EMA(closes, cfg.FastPeriod)
+	slow := indicators.EMA(closes, cfg.SlowPeriod)
+
+	last := len(macd["histogram"]) - 1
+	state := TimeframeTrend{
+		Trend:     TrendSide,
+		Close:     closes[len(closes)-1],
+		FastEMA:   fast[len(fast)-1],
+		SlowEMA:   slow[len(slow)-1],
+		MACD:      macd["macd"][last],
+		Signal:    macd["signal"][last],
+		Histogram: macd["histogram"][last],
+	}
+	switch {
+	case state.FastEMA > state.SlowEMA && state.Histogram > 0:
+		state.Trend = TrendUp
+	case state.FastEMA < state.SlowEMA && state.Histogram < 0:
+		state.Trend = TrendDown
+	}
+	return state, true
+}
+
+// ConsensusTrend 综合多个周期的趋势：超过半数同向且没有反向周期时取该方向，否则为震荡
+func ConsensusTrend(trends []Trend) Trend {
+	var up, down int
+	for _, t := range trends {
+		switch t {
+		case TrendUp:
+			up++
+		case TrendDown:
+			down++
+		}
+	}
+	switch {
+	case up*2 > len(trends) && down == 0:
+		return TrendUp
+	case down*2 > len(trends) && up == 0:
+		return TrendDown
+	}
+	return TrendSide
+}
+
+// timeframeDuration 解析 1m/15m/1h/4h/1d 格式的K线周期，无法解析时返回 0
+func timeframeDuration(timeframe string) time.Duration {
+	if len(timeframe) < 2 {
+		return 0
+	}
+	n, err := strconv.Atoi(timeframe[:len(timeframe)-1])
+	if err != nil || n <= 0 {
+		return 0
+	}
+	switch timeframe[len(timeframe)-1] {
+	case 'm':
+		return time.Duration(n) * time.Minute
+	case 'h':
+		return time.Duration(n) * time.Hour
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour
+	case 'w':
+		return time.Duration(n) * 7 * 24 * time.Hour
+	}
+	return 0
+}
@@ -0,0 +1,221 @@
+package strategy
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"quantmesh/config"
+	"quantmesh/exchange"
+	"quantmesh/indicators"
+)
+
+// testTrendCfg 小周期参数，便于手工计算
+var testTrendCfg = config.TrendServiceConfig{FastPeriod: 2, SlowPeriod: 4, SignalPeriod: 2, Lookback: 50}
+
+func closeCandles(closes ...float64) []indicators.Candle {
+	candles := make([]indicators.Candle, len(closes))
+	for i, c := range closes {
+		candles[i] = indicators.Candle{Time: int64(i), Open: c, High: c, Low: c, Close: c}
+	}
+	return candles
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestComputeTimeframeTrendKnownValues(t *testing.T) {
+	tests := []struct {
+		name                                     string
+		closes                                   []float64
+		wantFast, wantSlow, wantMACD, wantSignal float64
+		wantTrend                                Trend
+	}{
+		{
+			// EMA2 = 16×2/3 + 10×1/3 = 14，EMA4 = 16×0.4 + 10×0.6 = 12.4，MACD 序列 [0 0 0 1.6]，
+			// 信号线 EMA2 = 1.6×2/3，柱 = 1.6 - 1.0667 > 0
+			name: "breakout up", closes: []float64{10, 10, 10, 10, 10, 10, 16},
+			wantFast: 14, wantSlow: 12.4, wantMACD: 1.6, wantSignal: 1.6 * 2 / 3, wantTrend: TrendUp,
+		},
+		{
+			name: "breakdown", closes: []float64{10, 10, 10, 10, 10, 10, 4},
+			wantFast: 6, wantSlow: 7.6, wantMACD: -1.6, wantSignal: -1.6 * 2 / 3, wantTrend: TrendDown,
+		},
+		{
+			name: "flat", closes: []float64{10, 10, 10, 10, 10, 10, 10},
+			wantFast: 10, wantSlow: 10, wantTrend: TrendSide,
+		},
+		{
+			// 线性序列上以 SMA 起算的 EMA 恰好滞后 (n-1)/2 个步长：EMA2 = 39.5，EMA4 = 38.5，MACD 恒为 1
+			name: "linear ramp", closes: []float64{31, 32, 33, 34, 35, 36, 37, 38, 39, 40},
+			wantFast: 39.5, wantSlow: 38.5, wantMACD: 1, wantSignal: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, ok := ComputeTimeframeTrend(closeCandles(tt.closes...), testTrendCfg)
+			if !ok {
+				t.Fatal("expected a result")
+			}
+			if !approx(state.FastEMA, tt.wantFast) || !approx(state.SlowEMA, tt.wantSlow) ||
+				!approx(state.MACD, tt.wantMACD) || !approx(state.Signal, tt.wantSignal) ||
+				!approx(state.Histogram, tt.wantMACD-tt.wantSignal) {
+				t.Fatalf("state = %+v", state)
+			}
+			if state.Close != tt.closes[len(tt.closes)-1] {
+				t.Errorf("close = %v", state.Close)
+			}
+			if tt.wantTrend != "" && state.Trend != tt.wantTrend {
+				t.Errorf("trend = %s, want %s", state.Trend, tt.wantTrend)
+			}
+		})
+	}
+
+	// 少于 慢线+信号线 根K线时没有结果
+	if _, ok := ComputeTimeframeTrend(closeCandles(10, 10, 10, 10, 10), testTrendCfg); ok {
+		t.Error("insufficient candles should not produce a trend")
+	}
+}
+
+func TestConsensusTrend(t *testing.T) {
+	tests := []struct {
+		trends []Trend
+		want   Trend
+	}{
+		{nil, TrendSide},
+		{[]Trend{TrendUp}, TrendUp},
+		{[]Trend{TrendUp, TrendUp, TrendSide}, TrendUp},
+		{[]Trend{TrendUp, TrendSide, TrendSide}, TrendSide},
+		{[]Trend{TrendUp, TrendUp, TrendDown}, TrendSide}, // 存在反向周期
+		{[]Trend{TrendDown, TrendDown, TrendSide}, TrendDown},
+		{[]Trend{TrendUp, TrendSide}, TrendSide}, // 恰好一半不算多数
+	}
+	for _, tt := range tests {
+		if got := ConsensusTrend(tt.trends); got != tt.want {
+			t.Errorf("ConsensusTrend(%v) = %s, want %s", tt.trends, got, tt.want)
+		}
+	}
+}
+
+// scriptedKlines 按周期返回预设收盘价的K线源
+type scriptedKlines struct {
+	closes map[string][]float64
+	open   map[string]bool // 最后一根K线是否未收盘
+}
+
+func (s *scriptedKlines) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*exchange.Candle, error) {
+	closes := s.closes[interval]
+	klines := make([]*exchange.Candle, len(closes))
+	for i, c := range closes {
+		klines[i] = &exchange.Candle{Symbol: symbol, Close: c, Timestamp: int64(i), IsClosed: i < len(closes)-1 || !s.open[interval]}
+	}
+	return klines, nil
+}
+
+var (
+	upCloses   = []float64{10, 10, 10, 10, 10, 10, 16}
+	downCloses = []float64{10, 10, 10, 10, 10, 10, 4}
+	flatCloses = []float64{10, 10, 10, 10, 10, 10, 10}
+)
+
+func TestTrendServiceSubscribeAndConsensus(t *testing.T) {
+	source := &scriptedKlines{closes: map[string][]float64{"1m": upCloses, "15m": upCloses, "1h": flatCloses}}
+	cfg := testTrendCfg
+	cfg.Timeframes = []string{"1m", "15m", "1h"}
+	ts := NewTrendService("BTCUSDT", source, cfg)
+	ctx := context.Background()
+
+	if ts.Consensus() != TrendSide {
+		t.Fatalf("initial consensus = %s", ts.Consensus())
+	}
+	var first []TrendSnapshot
+	unsubscribe := ts.Subscribe(func(snap TrendSnapshot) { first = append(first, snap) })
+	if len(first) != 0 {
+		t.Fatal("subscriber should not be called before any result")
+	}
+
+	refresh := func() {
+		for _, tf := range cfg.Timeframes {
+			ts.update(ctx, tf)
+		}
+		ts.publish()
+	}
+
+	// 两个周期上涨、一个震荡：综合为上涨
+	refresh()
+	if ts.Consensus() != TrendUp || len(first) != 1 {
+		t.Fatalf("consensus = %s, notifications = %d", ts.Consensus(), len(first))
+	}
+	if snap := first[0]; snap.Symbol != "BTCUSDT" || snap.Consensus != TrendUp || len(snap.Timeframes) != 3 || snap.Timeframes["1h"].Trend != TrendSide {
+		t.Fatalf("snapshot = %+v", snap)
+	}
+
+	// 综合趋势不变时不通知
+	refresh()
+	if len(first) != 1 {
+		t.Fatalf("unchanged consensus notified %d times", len(first))
+	}
+
+	// 已有结果时订阅立即收到当前快照
+	var second []TrendSnapshot
+	ts.Subscribe(func(snap TrendSnapshot) { second = append(second, snap) })
+	if len(second) != 1 || second[0].Consensus != TrendUp {
+		t.Fatalf("late subscriber got %+v", second)
+	}
+
+	// 取消订阅后不再收到；出现反向周期时综合为震荡
+	unsubscribe()
+	source.closes["1h"] = downCloses
+	refresh()
+	if ts.Consensus() != TrendSide || len(first) != 1 || len(second) != 2 || second[1].Consensus != TrendSide {
+		t.Fatalf("consensus = %s, first = %d, second = %d", ts.Consensus(), len(first), len(second))
+	}
+}
+
+func TestTrendServiceIgnoresOpenCandle(t *testing.T) {
+	// 最后一根未收盘的K线暴跌，不应影响已收盘K线的结论
+	source := &scriptedKlines{
+		closes: map[string][]float64{"1m": append(append([]float64{}, upCloses...), 1)},
+		open:   map[string]bool{"1m": true},
+	}
+	ts := NewTrendService("BTCUSDT", source, testTrendCfg)
+	ts.update(context.Background(), "1m")
+	state, ok := ts.Get("1m")
+	if !ok || state.Close != 16 || state.Trend != TrendUp || state.Timeframe != "1m" {
+		t.Fatalf("state = %+v, ok = %v", state, ok)
+	}
+}
+
+func TestTrendConsumersDelegateToService(t *testing.T) {
+	source := &scriptedKlines{closes: map[string][]float64{"1m": downCloses}}
+	cfg := testTrendCfg
+	cfg.Timeframes = []string{"1m"}
+	ts := NewTrendService("BTCUSDT", source, cfg)
+
+	appCfg := &config.Config{}
+	appCfg.Trading.SmartPosition.Enabled = true
+	td := NewTrendDetector(appCfg, nil)
+	td.SetTrendService(ts)
+	td.Start() // 使用趋势服务时不再订阅价格、自行计算均线
+	defer td.Stop()
+
+	// 本地价格历史给出相反结论，结果仍以趋势服务为准
+	td.priceHistory = []float64{100, 101, 102, 103, 104, 105, 106, 107, 108, 109, 110, 111, 112, 113, 114, 115, 116, 117, 118, 119,
+		120, 121, 122, 123, 124, 125, 126, 127, 128, 129, 130}
+	dca := &DCAEnhancedStrategy{strategyCfg: &DCAEnhancedConfig{TrendPeriod: 2}, priceHistory: []float64{1, 2, 3, 4, 5}}
+	dca.SetTrendService(ts)
+
+	ts.update(context.Background(), "1m")
+	ts.publish()
+
+	if got := td.GetCurrentTrend(); got != string(TrendDown) {
+		t.Errorf("detector current trend = %s, want down", got)
+	}
+	if got := td.DetectTrend(); got != TrendDown {
+		t.Errorf("detector DetectTrend = %s, want down", got)
+	}
+	if dca.isTrendUp() {
+		t.Error("DCA trend filter should follow the shared service")
+	}
+}
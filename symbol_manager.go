@@ -3,34 +3,51 @@ package main
 import (
 	"context"
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"quantmesh/config"
 	"quantmesh/event"
 	"quantmesh/exchange"
+	"quantmesh/hedge"
 	"quantmesh/lock"
 	"quantmesh/logger"
+	"quantmesh/metrics"
 	"quantmesh/monitor"
 	"quantmesh/order"
 	"quantmesh/position"
 	"quantmesh/safety"
 	"quantmesh/storage"
 	"quantmesh/strategy"
+	"quantmesh/utils"
 )
 
 // SymbolRuntime 代表单个交易所/交易对的运行时组件集合
 type SymbolRuntime struct {
 	Config               config.SymbolConfig
+	RuntimeConfig        *config.Config // 该交易对的局部运行配置（组件共享，运行时调整直接生效）
 	Exchange             exchange.IExchange
 	PriceMonitor         *monitor.PriceMonitor
 	RiskMonitor          *safety.RiskMonitor
 	SuperPositionManager *position.SuperPositionManager
 	OrderCleaner         *safety.OrderCleaner
 	Reconciler           *safety.Reconciler
+	OrderStreamResyncer  *safety.OrderStreamResyncer
 	TrendDetector        *strategy.TrendDetector
+	TrendService         *strategy.TrendService
+	RegimeClassifier     *strategy.RegimeClassifier
+	LevelService         *strategy.LevelService
+	VolatilityGuard      *safety.VolatilityGuard
+	DepthGuard           *safety.DepthGuard
+	Hedger               *hedge.Hedger
+	IncomeLedger         *monitor.IncomeLedger
+	KlineBackfiller      *monitor.KlineBackfiller
 	DynamicAdjuster      *strategy.DynamicAdjuster
 	StrategyManager      *strategy.StrategyManager
+	MultiExecutor        *strategy.MultiStrategyExecutor // 未启用多策略时为 nil
 	ExchangeExecutor     *order.ExchangeOrderExecutor
 	ExecutorAdapter      *exchangeExecutorAdapter
 	ExchangeAdapter      *positionExchangeAdapter
@@ -39,6 +56,9 @@ type SymbolRuntime struct {
 	Stop                 func()
 }
 
+// depthGuardSource 买盘深度保护暂停新开仓时使用的来源名称
+const depthGuardSource = "depth_guard"
+
 // SymbolManager 管理多个 SymbolRuntime
 type SymbolManager struct {
 	cfg      *config.Config
@@ -112,6 +132,36 @@ func startSymbolRuntime(
 	localCfg.Trading.CleanupBatchSize = symCfg.CleanupBatchSize
 	localCfg.Trading.MarginLockDurationSec = symCfg.MarginLockDurationSec
 	localCfg.Trading.PositionSafetyCheck = symCfg.PositionSafetyCheck
+	localCfg.Trading.GridRiskControl = symCfg.GridRiskControl
+	localCfg.Trading.ContractType = symCfg.ContractType
+	localCfg.Trading.ContractSize = symCfg.ContractSize
+	localCfg.Trading.PriceBand = symCfg.PriceBand
+	if profile, ok := baseCfg.LookupRiskProfile(symCfg.RiskProfile); ok {
+		config.ApplyRiskProfile(&localCfg, profile)
+		logger.Info("🛡️ [%s:%s] 使用风控档位: %s (杠杆上限 %d, 窗口 %d/%d)", symCfg.Exchange, symCfg.Symbol,
+			symCfg.RiskProfile, localCfg.RiskControl.MaxLeverage, localCfg.Trading.BuyWindowSize, localCfg.Trading.SellWindowSize)
+	}
+
+	// 重复实例检测：另一个存活实例正在操作同一交易所+交易对时拒绝启动
+	var instanceLock *lock.InstanceLock
+	started := false
+	if baseCfg.InstanceLock.Mode != "off" {
+		var err error
+		instanceLock, err = lock.AcquireInstanceLock(lock.InstanceLockConfig{
+			InstanceID: baseCfg.Instance.ID,
+			Dir:        baseCfg.InstanceLock.Dir,
+			StaleAfter: time.Duration(baseCfg.InstanceLock.StaleSeconds) * time.Second,
+		}, distributedLock, symCfg.Exchange, symCfg.Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("实例锁检查失败(%s:%s): %w", symCfg.Exchange, symCfg.Symbol, err)
+		}
+		defer func() {
+			if !started {
+				instanceLock.Release()
+			}
+		}()
+		instanceLock.Start(ctx)
+	}
 
 	// 创建交易所实例
 	ex, err := exchange.NewExchange(&localCfg, symCfg.Exchange, symCfg.Symbol)
@@ -120,12 +170,15 @@ func startSymbolRuntime(
 	}
 	logger.Info("✅ [%s] 交易所实例已创建 (symbol=%s)", ex.GetName(), symCfg.Symbol)
 
+	// 登记交易对元数据，供策略、Web 和校验逻辑统一查询
+	exchange.GetSymbolRegistry().Register(ex, symCfg.Symbol)
+
 	// API 权限安全检测
 	logger.Info("🔐 [%s:%s] 开始检测 API 权限...", symCfg.Exchange, symCfg.Symbol)
 	permCheckCtx, permCheckCancel := context.WithTimeout(ctx, 10*time.Second)
 	defer permCheckCancel()
 
-	if checker, ok := ex.(exchange.PermissionChecker); ok {
+	if checker, ok := exchange.As[exchange.PermissionChecker](ex); ok {
 		permissions, err := checker.CheckAPIPermissions(permCheckCtx)
 		if err != nil {
 			logger.Warn("⚠️ [%s:%s] API 权限检测失败: %v (将继续启动)", symCfg.Exchange, symCfg.Symbol, err)
@@ -162,6 +215,7 @@ func startSymbolRuntime(
 		symCfg.Symbol,
 		localCfg.Timing.PriceSendInterval,
 	)
+	priceMonitor.SetConflation(localCfg.Timing.PriceConflateMinTicks, math.Pow10(-ex.GetPriceDecimals()))
 
 	logger.Info("🔗 [%s] 启动 WebSocket 价格流...", symCfg.Symbol)
 	if err := priceMonitor.Start(); err != nil {
@@ -190,13 +244,25 @@ func startSymbolRuntime(
 	logger.Info("ℹ️ [%s] 精度 - 价格:%d 数量:%d", symCfg.Symbol, priceDecimals, quantityDecimals)
 
 	// 获取交易手续费率
-	// 币安期货API不提供获取手续费率的接口，因此使用以下策略：
-	// 1. 如果配置文件中设置了费率且不为0，使用配置值
-	// 2. 否则使用币安期货默认Taker费率（0.04%）作为保守估计
+	// 1. 交易所支持费率查询时，使用账户实际费率（含 VIP 等级和 BNB 抵扣），并定期刷新
+	// 2. 否则如果配置文件中设置了费率且不为0，使用配置值
+	// 3. 币安未配置时使用默认Taker费率（0.04%）作为保守估计
 	configFeeRate := baseCfg.Exchanges[symCfg.Exchange].FeeRate
 	feeRate := configFeeRate
 
-	if symCfg.Exchange == "binance" {
+	commissionMonitor := monitor.NewCommissionMonitor(ex, symCfg.Symbol, configFeeRate)
+	if commissionMonitor != nil {
+		if err := commissionMonitor.Refresh(ctx); err != nil {
+			logger.Warn("⚠️ [%s] 查询实际手续费率失败，使用配置费率: %v", symCfg.Symbol, err)
+		} else if commissionMonitor.GetCommissionRate() != nil {
+			// 安全检查为保守起见使用 Taker 费率
+			feeRate = commissionMonitor.TakerFeeRate()
+		}
+	}
+
+	if commissionMonitor != nil && commissionMonitor.GetCommissionRate() != nil {
+		logger.Info("💳 [%s] 使用交易所实际手续费率: %.4f%%", symCfg.Symbol, feeRate*100)
+	} else if symCfg.Exchange == "binance" {
 		// 币安期货默认费率：Maker 0.02%, Taker 0.04%
 		// 网格策略使用限价单，通常作为Maker成交，但为保守起见使用Taker费率
 		defaultBinanceTakerFee := 0.0004 // 0.04%
@@ -209,16 +275,16 @@ func startSymbolRuntime(
 			// 使用配置文件中的费率
 			logger.Info("💳 [%s] 使用配置文件中的手续费率: %.4f%%", symCfg.Symbol, feeRate*100)
 		}
-		logger.Info("ℹ️ [%s] 提示：币安期货实际费率取决于您的VIP等级，请在配置文件中设置准确的费率", symCfg.Symbol)
 	} else {
 		logger.Info("💳 [%s] 使用配置文件中的手续费率: %.4f%%", symCfg.Symbol, feeRate*100)
 	}
 
 	// 持仓安全性检查
 	maxLeverage := baseCfg.RiskControl.MaxLeverage
-	if err := safety.CheckAccountSafety(
+	if err := safety.CheckContractAccountSafety(
 		ex,
 		symCfg.Symbol,
+		utils.NewContractSpec(symCfg.ContractType, symCfg.ContractSize),
 		currentPrice,
 		symCfg.OrderQuantity,
 		symCfg.PriceInterval,
@@ -239,9 +305,63 @@ func startSymbolRuntime(
 		localCfg.Timing.OrderRetryDelay,
 		distributedLock,
 	)
+	if localCfg.Trading.ReduceOnlyGuard.Enabled {
+		exchangeExecutor.EnableReduceOnlyGuard(time.Duration(localCfg.Trading.ReduceOnlyGuard.RefreshMillis) * time.Millisecond)
+	}
+	if localCfg.Trading.TurnoverBudget.Enabled {
+		exchangeExecutor.EnableTurnoverBudget(localCfg.Trading.TurnoverBudget, func(b order.BudgetExhausted) {
+			if eventBus == nil {
+				return
+			}
+			eventBus.Publish(&event.Event{
+				Type: event.EventTypeTurnoverBudgetExhausted,
+				Data: map[string]interface{}{
+					"exchange":      symCfg.Exchange,
+					"symbol":        b.Symbol,
+					"strategy":      b.Strategy,
+					"reason":        b.Reason,
+					"hour_notional": b.Usage.HourNotional,
+					"day_notional":  b.Usage.DayNotional,
+					"hour_orders":   b.Usage.HourOrders,
+					"day_orders":    b.Usage.DayOrders,
+					"message":       fmt.Sprintf("策略 %s 交易额度已用尽（%s），暂停下单直至额度恢复", b.Strategy, b.Reason),
+				},
+			})
+		})
+	}
+	if localCfg.Trading.PriceBand.Enabled {
+		exchangeExecutor.EnablePriceBand(localCfg.Trading.PriceBand, func(v order.PriceBandViolation) {
+			if eventBus == nil {
+				return
+			}
+			eventBus.Publish(&event.Event{
+				Type: event.EventTypePriceBandViolation,
+				Data: map[string]interface{}{
+					"exchange":    symCfg.Exchange,
+					"symbol":      v.Symbol,
+					"strategy":    v.Strategy,
+					"side":        v.Side,
+					"price":       v.Price,
+					"lower":       v.Lower,
+					"upper":       v.Upper,
+					"reduce_only": v.ReduceOnly,
+					"suppressed":  v.Suppressed,
+					"message":     fmt.Sprintf("%s 订单价格 %.8g 超出价格带，已拦截", v.Side, v.Price),
+				},
+			})
+		})
+	}
+	if localCfg.Trading.MarginCheck.Enabled {
+		if utils.NewContractSpec(symCfg.ContractType, symCfg.ContractSize).Inverse {
+			logger.Warn("⚠️ [%s:%s] 保证金检查仅支持 U 本位合约，币本位合约不启用", symCfg.Exchange, symCfg.Symbol)
+		} else {
+			exchangeExecutor.EnableMarginCheck(localCfg.Trading.MarginCheck)
+		}
+	}
 	executorAdapter := &exchangeExecutorAdapter{
 		executor: exchangeExecutor,
 		eventBus: eventBus,
+		exchange: strings.ToLower(symCfg.Exchange),
 		symbol:   symCfg.Symbol,
 	}
 	exchangeAdapter := &positionExchangeAdapter{exchange: ex}
@@ -250,41 +370,73 @@ func startSymbolRuntime(
 	if storageService != nil {
 		tradeStorageAdapter := &tradeStorageAdapter{storageService: storageService}
 		superPositionManager.SetTradeStorage(tradeStorageAdapter)
+		superPositionManager.SetCostBasisStorage(&costBasisStorageAdapter{storageService: storageService})
+		if localCfg.Trading.Anchor.Persist {
+			superPositionManager.SetAnchorStorage(&anchorStorageAdapter{storageService: storageService})
+		}
+		exchangeExecutor.SetOCOStore(&ocoStorageAdapter{storageService: storageService})
+		exchangeExecutor.SetRejectionRecorder(&rejectionStorageAdapter{storageService: storageService})
+	}
+	if commissionMonitor != nil {
+		superPositionManager.SetFeeRateProvider(commissionMonitor)
+		commissionMonitor.Start(ctx)
 	}
 	// 设置事件总线（用于发送告警）
 	if eventBus != nil {
 		superPositionManager.SetEventBus(eventBus)
 	}
+	// 心跳时发现锁被其它实例接管：立即暂停交易，避免两个实例同时下单
+	if instanceLock != nil {
+		instanceLock.SetOnLost(func(reason string) {
+			logger.Error("🚨 [%s:%s] 实例锁丢失，已暂停交易: %s", symCfg.Exchange, symCfg.Symbol, reason)
+			superPositionManager.Pause()
+			if eventBus != nil {
+				eventBus.Publish(&event.Event{
+					Type: event.EventTypeInstanceConflict,
+					Data: map[string]interface{}{
+						"exchange": symCfg.Exchange,
+						"symbol":   symCfg.Symbol,
+						"reason":   reason,
+					},
+				})
+			}
+		})
+	}
 
 	riskMonitor := safety.NewRiskMonitor(&localCfg, ex)
+	if localCfg.RiskControl.AutoDiscovery.Enabled {
+		traded := make([]string, 0, len(baseCfg.Trading.Symbols))
+		for _, sym := range baseCfg.Trading.Symbols {
+			if strings.EqualFold(sym.Exchange, symCfg.Exchange) {
+				traded = append(traded, sym.Symbol)
+			}
+		}
+		riskMonitor.SetTradedSymbols(traded)
+	}
 	if storageService != nil {
 		riskMonitor.SetStorage(storageService.GetStorage())
 	}
+	if localCfg.RiskControl.PriceOracle.Enabled {
+		if oracle, err := safety.NewPriceOracle(&localCfg); err != nil {
+			logger.Warn("⚠️ [%s] 价格源交叉校验未启用: %v", symCfg.Symbol, err)
+		} else {
+			riskMonitor.SetPriceOracle(oracle, symCfg.Symbol, priceMonitor.GetLastPrice)
+		}
+	}
 
 	reconciler := safety.NewReconciler(&localCfg, exchangeAdapter, superPositionManager, distributedLock)
 	reconciler.SetPauseChecker(func() bool {
 		return riskMonitor.IsTriggered()
 	})
 	if storageService != nil {
-		reconciler.SetStorage(&reconciliationStorageAdapter{storageService: storageService})
+		reconciler.SetStorage(&reconciliationStorageAdapter{storageService: storageService, exchange: strings.ToLower(symCfg.Exchange)})
+	}
+	if eventBus != nil {
+		reconciler.SetEventBus(eventBus)
 	}
 
 	// 订单流
-	if err := ex.StartOrderStream(ctx, func(updateInterface interface{}) {
-		posUpdate := toPositionOrderUpdate(updateInterface)
-		if posUpdate == nil {
-			return
-		}
-
-		// 🔥 关键修复：过滤掉不属于当前交易对的订单更新
-		// 币安的 WebSocket 订单流是全局的，会推送所有交易对的订单
-		// 必须检查 Symbol 是否匹配，避免不同交易对的订单互相干扰
-		if posUpdate.Symbol != symCfg.Symbol {
-			logger.Debug("⏭️ [订单过滤] 跳过其他交易对的订单: Symbol=%s (当前交易对: %s), ClientOID=%s",
-				posUpdate.Symbol, symCfg.Symbol, posUpdate.ClientOrderID)
-			return
-		}
-
+	handleOrderUpdate := func(posUpdate *position.OrderUpdate) {
 		// 发布订单事件
 		if eventBus != nil && posUpdate.Symbol != "" {
 			var eventType event.EventType
@@ -310,13 +462,101 @@ func startSymbolRuntime(
 			}
 		}
 
+		// 成交后持仓变化，只减仓检查和保证金检查需要重新查询持仓
+		if posUpdate.Status == "FILLED" || posUpdate.Status == "PARTIALLY_FILLED" {
+			exchangeExecutor.InvalidatePositionCache()
+		}
+
+		// OCO 止盈/止损子订单由执行器处理，不进入网格仓位管理
+		if exchangeExecutor.HandleOCOOrderUpdate(posUpdate.OrderID, posUpdate.Status, posUpdate.ExecutedQty) {
+			return
+		}
+
 		superPositionManager.OnOrderUpdate(*posUpdate)
+	}
+
+	// 订单流断线恢复后通过 REST 补查本地有效订单，补查结果按推送处理
+	orderResyncer := safety.NewOrderStreamResyncer(strings.ToLower(symCfg.Exchange), symCfg.Symbol, ex, superPositionManager,
+		func(o *exchange.Order) {
+			handleOrderUpdate(&position.OrderUpdate{
+				OrderID:       o.OrderID,
+				ClientOrderID: o.ClientOrderID,
+				Symbol:        symCfg.Symbol,
+				Status:        string(o.Status),
+				ExecutedQty:   o.ExecutedQty,
+				Price:         o.Price,
+				AvgPrice:      o.AvgPrice,
+				Side:          string(o.Side),
+				Type:          string(o.Type),
+				UpdateTime:    o.UpdateTime,
+			})
+		})
+	if eventBus != nil {
+		orderResyncer.SetEventBus(eventBus)
+	}
+
+	if err := ex.StartOrderStream(ctx, func(updateInterface interface{}) {
+		switch notice := updateInterface.(type) {
+		case exchange.OrderStreamReconnect:
+			orderResyncer.OnReconnect(notice)
+			return
+		case exchange.UserStreamAlert:
+			publishUserStreamAlert(eventBus, symCfg.Symbol, notice)
+			return
+		}
+
+		posUpdate := toPositionOrderUpdate(updateInterface)
+		if posUpdate == nil {
+			return
+		}
+
+		// 🔥 关键修复：过滤掉不属于当前交易对的订单更新
+		// 币安的 WebSocket 订单流是全局的，会推送所有交易对的订单
+		// 必须检查 Symbol 是否匹配，避免不同交易对的订单互相干扰
+		if posUpdate.Symbol != symCfg.Symbol {
+			logger.Debug("⏭️ [订单过滤] 跳过其他交易对的订单: Symbol=%s (当前交易对: %s), ClientOID=%s",
+				posUpdate.Symbol, symCfg.Symbol, posUpdate.ClientOrderID)
+			return
+		}
+
+		orderResyncer.OnOrderUpdate(posUpdate.OrderID, posUpdate.UpdateTime)
+		handleOrderUpdate(posUpdate)
 	}); err != nil {
 		logger.Warn("⚠️ [%s] 启动订单流失败: %v", symCfg.Symbol, err)
 	}
 
-	if err := superPositionManager.Initialize(currentPrice, currentPriceStr); err != nil {
-		return nil, fmt.Errorf("初始化仓位管理器失败(%s:%s): %w", symCfg.Exchange, symCfg.Symbol, err)
+	// 恢复未结束的 OCO 关联订单（需在订单流启动后，确保撤单回报能被处理）
+	if err := exchangeExecutor.RestoreOCOLinks(); err != nil {
+		logger.Warn("⚠️ [%s] %v", symCfg.Symbol, err)
+	}
+
+	// 协调重启后按恢复令牌沿用原网格并接管保留的挂单，不重新定位锚点；校验失败时按正常流程初始化
+	var anchorPrice float64
+	resumed := false
+	if rs := takeResumeSymbol(symCfg.Exchange, symCfg.Symbol); rs != nil {
+		anchorPrice, resumed = resumeGrid(ctx, rs, &localCfg, ex, superPositionManager, currentPrice)
+	}
+
+	// 网格锚点：按配置的模式（VWAP / 已收盘K线 / 手动）确定，启用持久化时重启沿用上次的锚点
+	if !resumed {
+		var anchorStorage storage.Storage
+		if storageService != nil {
+			anchorStorage = storageService.GetStorage()
+		}
+		anchorPrice = resolveGridAnchor(ctx, localCfg.Trading.Anchor, ex, priceMonitor, anchorStorage, symCfg.Symbol, currentPrice)
+	}
+
+	// 百分比价格带未配置基准价时以网格锚点为基准
+	exchangeExecutor.SetPriceBandReference(anchorPrice)
+
+	if !resumed {
+		anchorPriceStr := currentPriceStr
+		if anchorPrice != currentPrice {
+			anchorPriceStr = strconv.FormatFloat(anchorPrice, 'f', priceDecimals, 64)
+		}
+		if err := superPositionManager.Initialize(anchorPrice, anchorPriceStr); err != nil {
+			return nil, fmt.Errorf("初始化仓位管理器失败(%s:%s): %w", symCfg.Exchange, symCfg.Symbol, err)
+		}
 	}
 
 	// 🔥 如果启动时已有持仓（满仓或接近满仓），立即调用 AdjustOrders 初始化卖单
@@ -329,7 +569,7 @@ func startSymbolRuntime(
 
 	if storageService != nil {
 		if st := storageService.GetStorage(); st != nil {
-			restoreAdapter := &reconciliationRestoreAdapter{storage: st}
+			restoreAdapter := &reconciliationRestoreAdapter{storage: st, exchange: strings.ToLower(symCfg.Exchange)}
 			if err := superPositionManager.RestoreReconciliationStats(restoreAdapter, symCfg.Symbol); err != nil {
 				logger.Warn("⚠️ [%s] 恢复对账统计失败: %v", symCfg.Symbol, err)
 			}
@@ -339,10 +579,58 @@ func startSymbolRuntime(
 	reconciler.Start(ctx)
 
 	orderCleaner := safety.NewOrderCleaner(&localCfg, exchangeExecutor, superPositionManager)
+	orderCleaner.SetExchange(exchangeAdapter)
+	if storageService != nil {
+		orderCleaner.SetRecorder(&orderCleanupStorageAdapter{storageService: storageService})
+	}
 	orderCleaner.Start(ctx)
 
 	go riskMonitor.Start(ctx)
 
+	// API 密钥巡检：失去交易权限或过期时暂停交易，避免下单被拒
+	if apiKeyWatchdog := monitor.NewAPIKeyWatchdog(ex, symCfg.Symbol, superPositionManager, eventBus); apiKeyWatchdog != nil {
+		apiKeyWatchdog.Start(ctx)
+	}
+
+	// 强平距离监控：接近强平价时发布严重事件（可触发电话/短信告警升级）
+	monitor.NewLiquidationMonitor(ex, symCfg.Symbol, localCfg.RiskControl.LiquidationWarningRatio, eventBus).Start(ctx)
+
+	// 资金费流水同步（交易所支持账户流水查询时）
+	if storageService != nil {
+		if ledger := monitor.NewFundingLedger(storageService.GetStorage(), ex, symCfg.Symbol); ledger != nil {
+			ledger.Start(ctx)
+		}
+	}
+
+	// 账户流水同步与盈亏对账（已实现盈亏、手续费、资金费）
+	var incomeLedger *monitor.IncomeLedger
+	if localCfg.IncomeSync.Enabled && storageService != nil {
+		if incomeLedger = monitor.NewIncomeLedger(&localCfg, storageService.GetStorage(), ex, symCfg.Symbol, eventBus); incomeLedger != nil {
+			incomeLedger.Start(ctx)
+		} else {
+			logger.Warn("⚠️ [%s] 交易所 %s 不支持账户流水查询，跳过盈亏对账", symCfg.Symbol, ex.GetName())
+		}
+	}
+
+	// K 线缺口补齐：启动时补齐停机期间缺失的 K 线，之后定期增量同步
+	var klineBackfiller *monitor.KlineBackfiller
+	if localCfg.KlineBackfill.Enabled && storageService != nil {
+		if klineBackfiller = monitor.NewKlineBackfiller(&localCfg, storageService.GetStorage(), ex, symCfg.Symbol); klineBackfiller != nil {
+			klineBackfiller.Start(ctx)
+		}
+	}
+
+	// 库存对冲：在另一账户或相关合约上做空，限制网格累积库存的方向性敞口
+	var hedger *hedge.Hedger
+	if localCfg.Trading.Hedge.Enabled {
+		if hedger, err = hedge.NewHedger(&localCfg, ex, symCfg.Symbol, superPositionManager); err != nil {
+			logger.Warn("⚠️ [%s] 库存对冲未启动: %v", symCfg.Symbol, err)
+			hedger = nil
+		} else {
+			hedger.Start(ctx)
+		}
+	}
+
 	// 可选组件
 	var dynamicAdjuster *strategy.DynamicAdjuster
 	if localCfg.Trading.DynamicAdjustment.Enabled {
@@ -350,14 +638,131 @@ func startSymbolRuntime(
 		dynamicAdjuster.Start()
 	}
 
+	// 多周期趋势服务：趋势检测器与各策略共享同一份 EMA/MACD 计算结果
+	var trendService *strategy.TrendService
+	if localCfg.Trading.TrendService.Enabled {
+		trendService = strategy.NewTrendService(symCfg.Symbol, ex, localCfg.Trading.TrendService)
+		trendService.Start(ctx)
+	}
+
 	var trendDetector *strategy.TrendDetector
 	if localCfg.Trading.SmartPosition.Enabled || localCfg.Trading.GridRiskControl.TrendFilterEnabled {
 		trendDetector = strategy.NewTrendDetector(&localCfg, priceMonitor)
+		if trendService != nil {
+			trendDetector.SetTrendService(trendService)
+		}
 		trendDetector.Start()
 		// 将趋势检测器注入 SuperPositionManager
 		superPositionManager.SetTrendDetector(trendDetector)
 	}
 
+	// 市场状态分类：按状态切换挂单窗口（启用后取代趋势检测器的窗口调整）
+	var regimeClassifier *strategy.RegimeClassifier
+	if localCfg.Trading.Regime.Enabled {
+		regimeCfg := localCfg.Trading.Regime
+		regimeClassifier = strategy.NewRegimeClassifier(symCfg.Symbol, ex, regimeCfg)
+		regimeClassifier.OnChange(func(prev, cur strategy.Regime, metrics strategy.RegimeMetrics) {
+			if cur == strategy.RegimeUnknown {
+				superPositionManager.ClearWindowProfile()
+				return
+			}
+			profile := cur.WindowProfile(regimeCfg)
+			superPositionManager.SetWindowProfile(string(cur), profile.BuyWindowSize, profile.SellWindowSize)
+		})
+		regimeClassifier.Start(ctx)
+	}
+
+	// 支撑/阻力位与K线形态检测（可选：支撑位附近加密买单槽位）
+	var levelService *strategy.LevelService
+	if localCfg.Trading.Levels.Enabled {
+		levelsCfg := localCfg.Trading.Levels
+		levelService = strategy.NewLevelService(symCfg.Symbol, ex, levelsCfg)
+		if levelsCfg.GridBias.Enabled {
+			levelService.OnUpdate(func(snap strategy.LevelSnapshot) {
+				superPositionManager.SetDenseZones(supportZones(snap, levelsCfg.GridBias.ZonePercent, levelsCfg.GridBias.MinTouches))
+			})
+		}
+		levelService.Start(ctx)
+	}
+
+	// 短时高波动保护：波动率超过阈值时新挂单强制 PostOnly 并拉开挂单距离，回落后自动恢复
+	var volatilityGuard *safety.VolatilityGuard
+	if localCfg.Trading.VolatilityGuard.Enabled {
+		guardCfg := localCfg.Trading.VolatilityGuard
+		volatilityGuard = safety.NewVolatilityGuard(guardCfg)
+		volatilityGuard.OnChange(func(active bool, volatility float64) {
+			if active {
+				logger.Warn("🌪️ [%s] %d 秒已实现波动率 %.2f%% 超过阈值 %.2f%%，启用高波动保护",
+					symCfg.Symbol, guardCfg.WindowSeconds, volatility, guardCfg.ThresholdPercent)
+				superPositionManager.SetQuoteGuard("volatility", guardCfg.OffsetIntervals*localCfg.Trading.PriceInterval)
+				return
+			}
+			logger.Info("🌤️ [%s] 已实现波动率回落至 %.2f%%，解除高波动保护", symCfg.Symbol, volatility)
+			superPositionManager.ClearQuoteGuard()
+		})
+	}
+
+	// 买盘深度骤降保护：买盘深度短时大幅消失时暂停新开仓并撤销买单，深度恢复后自动恢复
+	var depthGuard *safety.DepthGuard
+	if localCfg.Trading.DepthGuard.Enabled {
+		guardCfg := localCfg.Trading.DepthGuard
+		depthGuard = safety.NewDepthGuard(guardCfg)
+		depthGuard.OnChange(func(active bool, st safety.DepthGuardStatus) {
+			if active {
+				logger.Warn("🕳️ [%s] 前 %d 档买盘深度 %d 秒内下降 %.1f%% (%.4f → %.4f)，暂停买入并撤销买单",
+					symCfg.Symbol, guardCfg.Levels, guardCfg.WindowSeconds, st.DropPercent, st.Baseline, st.BidDepth)
+				superPositionManager.HaltBuying(depthGuardSource)
+				superPositionManager.CancelAllBuyOrders()
+				return
+			}
+			logger.Info("🧱 [%s] 买盘深度恢复至 %.4f，解除深度保护", symCfg.Symbol, st.BidDepth)
+			superPositionManager.ResumeBuying(depthGuardSource)
+		})
+	}
+
+	// 盘口推送：挂单排队位置估算（盘口+逐笔成交）与买盘深度保护共用（交易所不支持时仅记录警告）
+	var queueStreamCancel context.CancelFunc
+	queueEnabled := localCfg.Trading.QueuePosition.Enabled
+	if queueEnabled || depthGuard != nil {
+		if streamer, ok := exchange.As[exchange.MarketDepthStreamer](ex); ok {
+			var queueCtx context.Context
+			queueCtx, queueStreamCancel = context.WithCancel(ctx)
+			depthErr := streamer.StartDepthStream(queueCtx, symCfg.Symbol, func(d *exchange.DepthUpdate) {
+				if depthGuard != nil {
+					bidQtys := make([]float64, len(d.Bids))
+					for i, l := range d.Bids {
+						bidQtys[i] = l.Qty
+					}
+					depthGuard.Update(bidQtys, time.Now())
+				}
+				if !queueEnabled {
+					return
+				}
+				bids := make([]position.QueueLevel, len(d.Bids))
+				for i, l := range d.Bids {
+					bids[i] = position.QueueLevel{Price: l.Price, Qty: l.Qty}
+				}
+				asks := make([]position.QueueLevel, len(d.Asks))
+				for i, l := range d.Asks {
+					asks[i] = position.QueueLevel{Price: l.Price, Qty: l.Qty}
+				}
+				superPositionManager.OnDepthUpdate(bids, asks)
+			})
+			var tradeErr error
+			if queueEnabled {
+				tradeErr = streamer.StartTradeStream(queueCtx, symCfg.Symbol, func(t *exchange.TradePrint) {
+					superPositionManager.OnTradePrint(t.Price, t.Qty, t.BuyerMaker)
+				})
+			}
+			if depthErr != nil || tradeErr != nil {
+				logger.Warn("⚠️ [%s:%s] 排队位置估算/买盘深度保护不可用（交易所不支持盘口/成交推送）: %v %v",
+					symCfg.Exchange, symCfg.Symbol, depthErr, tradeErr)
+			}
+		} else {
+			logger.Warn("⚠️ [%s:%s] 交易所不支持盘口/成交推送，排队位置估算/买盘深度保护未启用", symCfg.Exchange, symCfg.Symbol)
+		}
+	}
+
 	var strategyManager *strategy.StrategyManager
 	var multiExecutor *strategy.MultiStrategyExecutor
 	if localCfg.Strategies.Enabled {
@@ -393,6 +798,9 @@ func startSymbolRuntime(
 		if trendCfg, exists := localCfg.Strategies.Configs["trend"]; exists && trendCfg.Enabled {
 			trendExecutor := strategy.NewMultiStrategyExecutorAdapter(multiExecutor, "trend")
 			trendStrategy := strategy.NewTrendFollowingStrategy("trend", &localCfg, trendExecutor, exchangeAdapter, trendCfg.Config)
+			if trendService != nil {
+				trendStrategy.SetTrendService(trendService)
+			}
 			fixedPool := 0.0
 			if pool, ok := trendCfg.Config["capital_pool"].(float64); ok {
 				fixedPool = pool
@@ -437,6 +845,9 @@ func startSymbolRuntime(
 		if dcaEnhancedCfg, exists := localCfg.Strategies.Configs["dca_enhanced"]; exists && dcaEnhancedCfg.Enabled {
 			dcaEnhancedExecutor := strategy.NewMultiStrategyExecutorAdapter(multiExecutor, "dca_enhanced")
 			dcaEnhancedStrategy := strategy.NewDCAEnhancedStrategy("dca_enhanced", symCfg.Symbol, &localCfg, dcaEnhancedExecutor, exchangeAdapter, dcaEnhancedCfg.Config)
+			if trendService != nil {
+				dcaEnhancedStrategy.SetTrendService(trendService)
+			}
 			fixedPool := 0.0
 			if pool, ok := dcaEnhancedCfg.Config["capital_pool"].(float64); ok {
 				fixedPool = pool
@@ -463,17 +874,11 @@ func startSymbolRuntime(
 		}
 	}
 
-	// 价格变动处理
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				logger.Error("❌ [%s] 价格变化处理协程 panic: %v", symCfg.Symbol, r)
-			}
-		}()
-		
-		priceCh := priceMonitor.Subscribe()
+	// 价格变动处理（守护协程：panic 后自动重启，订阅 channel 跨重启复用）
+	priceCh := priceMonitor.Subscribe()
+	utils.GoSupervised(ctx, fmt.Sprintf("price-handler:%s:%s", symCfg.Exchange, symCfg.Symbol), func(ctx context.Context) {
 		var lastTriggered bool
-		
+
 		for {
 			select {
 			case <-ctx.Done():
@@ -485,7 +890,31 @@ func startSymbolRuntime(
 					logger.Debug("⏹️ [%s] 价格变化 channel 已关闭", symCfg.Symbol)
 					return
 				}
-				
+
+				// 合并积压的价格更新，只处理最新价格，避免快速行情下调单请求堆积
+				coalesced := 0
+			drain:
+				for {
+					select {
+					case next, ok := <-priceCh:
+						if !ok {
+							break drain
+						}
+						priceChange = next
+						coalesced++
+					default:
+						break drain
+					}
+				}
+				metrics.GetPrometheusMetrics().RecordPriceConflated(symCfg.Exchange, symCfg.Symbol, "adjust", coalesced)
+
+				if volatilityGuard != nil {
+					volatilityGuard.Update(priceChange.NewPrice, time.Now())
+				}
+
+				// 本地模拟的 OCO 止损不受风控暂停影响
+				exchangeExecutor.CheckOCOTriggers(priceChange.NewPrice)
+
 				isTriggered := riskMonitor.IsTriggered()
 				if isTriggered {
 					if !lastTriggered {
@@ -523,16 +952,17 @@ func startSymbolRuntime(
 					strategyManager.OnPriceChange(priceChange.NewPrice)
 				}
 
-				if trendDetector != nil && localCfg.Trading.SmartPosition.WindowAdjustment.Enabled {
+				if regimeClassifier != nil {
+					// 窗口由市场状态分类器通过 SetWindowProfile 切换
+					if err := superPositionManager.AdjustOrders(priceChange.NewPrice); err != nil {
+						logger.Error("❌ [%s] 调整订单失败: %v", symCfg.Symbol, err)
+					}
+				} else if trendDetector != nil && localCfg.Trading.SmartPosition.WindowAdjustment.Enabled {
 					buyWindow, sellWindow := trendDetector.AdjustWindows()
-					origBuy, origSell := localCfg.Trading.BuyWindowSize, localCfg.Trading.SellWindowSize
-					localCfg.Trading.BuyWindowSize = buyWindow
-					localCfg.Trading.SellWindowSize = sellWindow
+					superPositionManager.SetWindowProfile("trend:"+trendDetector.GetCurrentTrend(), buyWindow, sellWindow)
 					if err := superPositionManager.AdjustOrders(priceChange.NewPrice); err != nil {
 						logger.Error("❌ [%s] 调整订单失败: %v", symCfg.Symbol, err)
 					}
-					localCfg.Trading.BuyWindowSize = origBuy
-					localCfg.Trading.SellWindowSize = origSell
 				} else {
 					if strategyManager == nil || !localCfg.Strategies.Enabled {
 						if err := superPositionManager.AdjustOrders(priceChange.NewPrice); err != nil {
@@ -542,24 +972,24 @@ func startSymbolRuntime(
 				}
 			}
 		}
-	}()
+	})
 
-	// 定期打印持仓
-	go func() {
-		statusInterval := time.Duration(localCfg.Timing.StatusPrintInterval) * time.Minute
-		ticker := time.NewTicker(statusInterval)
+	// 定期状态：总是写入指标，quiet 日志配置档下不打印持仓
+	utils.GoSupervised(ctx, fmt.Sprintf("status-printer:%s:%s", symCfg.Exchange, symCfg.Symbol), func(ctx context.Context) {
+		ticker := time.NewTicker(localCfg.StatusPrintPeriod())
 		defer ticker.Stop()
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				if !riskMonitor.IsTriggered() {
+				recordStatusMetrics(ex.GetName(), symCfg.Symbol, superPositionManager, priceMonitor)
+				if !riskMonitor.IsTriggered() && !logger.IsQuiet() {
 					superPositionManager.PrintPositions()
 				}
 			}
 		}
-	}()
+	})
 
 	stopFn := func() {
 		logger.Info("⏹️ [%s] 停止价格监控...", symCfg.Symbol)
@@ -578,22 +1008,50 @@ func startSymbolRuntime(
 		if trendDetector != nil {
 			trendDetector.Stop()
 		}
+		if trendService != nil {
+			trendService.Stop()
+		}
+		if regimeClassifier != nil {
+			regimeClassifier.Stop()
+		}
+		if levelService != nil {
+			levelService.Stop()
+		}
+		if queueStreamCancel != nil {
+			queueStreamCancel()
+		}
 		if strategyManager != nil {
 			strategyManager.StopAll()
 		}
+		superPositionManager.FlushCostBasis()
+		if instanceLock != nil {
+			instanceLock.Release()
+		}
 	}
 
+	started = true
 	return &SymbolRuntime{
 		Config:               symCfg,
+		RuntimeConfig:        &localCfg,
 		Exchange:             ex,
 		PriceMonitor:         priceMonitor,
 		RiskMonitor:          riskMonitor,
 		SuperPositionManager: superPositionManager,
 		OrderCleaner:         orderCleaner,
 		Reconciler:           reconciler,
+		OrderStreamResyncer:  orderResyncer,
 		TrendDetector:        trendDetector,
+		TrendService:         trendService,
+		RegimeClassifier:     regimeClassifier,
+		LevelService:         levelService,
+		VolatilityGuard:      volatilityGuard,
+		DepthGuard:           depthGuard,
+		Hedger:               hedger,
+		IncomeLedger:         incomeLedger,
+		KlineBackfiller:      klineBackfiller,
 		DynamicAdjuster:      dynamicAdjuster,
 		StrategyManager:      strategyManager,
+		MultiExecutor:        multiExecutor,
 		ExchangeExecutor:     exchangeExecutor,
 		ExecutorAdapter:      executorAdapter,
 		ExchangeAdapter:      exchangeAdapter,
@@ -603,6 +1061,37 @@ func startSymbolRuntime(
 	}, nil
 }
 
+// SwitchRiskProfile 运行时切换风控档位
+// 窗口、网格层数、止损比例在下一次订单调整时生效；杠杆上限仅用于安全检查，不会修改交易所杠杆
+func (rt *SymbolRuntime) SwitchRiskProfile(name string, profile config.RiskProfile) {
+	config.ApplyRiskProfile(rt.RuntimeConfig, profile)
+	rt.Config.RiskProfile = name
+	logger.Info("🛡️ [%s:%s] 风控档位已切换为 %s (杠杆上限 %d, 窗口 %d/%d, 最大层数 %d, 止损 %.1f%%)",
+		rt.Config.Exchange, rt.Config.Symbol, name, rt.RuntimeConfig.RiskControl.MaxLeverage,
+		rt.RuntimeConfig.Trading.BuyWindowSize, rt.RuntimeConfig.Trading.SellWindowSize,
+		rt.RuntimeConfig.Trading.GridRiskControl.MaxGridLayers, rt.RuntimeConfig.Trading.GridRiskControl.StopLossRatio*100)
+}
+
+// RaiseOrderQuantity 交易所上调最小名义价值后调大每单金额（留出 bufferPercent 余量），最小订单价值同步提高
+// 返回调整前后的每单金额；已满足要求时不调整
+func (rt *SymbolRuntime) RaiseOrderQuantity(minNotional, bufferPercent float64) (oldQty, newQty float64) {
+	trading := &rt.RuntimeConfig.Trading
+	oldQty = trading.OrderQuantity
+	if oldQty >= minNotional {
+		return oldQty, oldQty
+	}
+	newQty = math.Ceil(minNotional*(1+bufferPercent/100)*100) / 100
+	trading.OrderQuantity = newQty
+	if trading.MinOrderValue < minNotional {
+		trading.MinOrderValue = minNotional
+	}
+	rt.Config.OrderQuantity = trading.OrderQuantity
+	rt.Config.MinOrderValue = trading.MinOrderValue
+	logger.Warn("📐 [%s:%s] 最小名义价值上调至 %g，每单金额 %g -> %g，最小订单价值 %g",
+		rt.Config.Exchange, rt.Config.Symbol, minNotional, oldQty, newQty, trading.MinOrderValue)
+	return oldQty, newQty
+}
+
 // toPositionOrderUpdate 提取订单更新为 position.OrderUpdate
 func toPositionOrderUpdate(updateInterface interface{}) *position.OrderUpdate {
 	v := reflect.ValueOf(updateInterface)
@@ -648,3 +1137,62 @@ func toPositionOrderUpdate(updateInterface interface{}) *position.OrderUpdate {
 		UpdateTime:    getInt64Field("UpdateTime"),
 	}
 }
+
+// publishUserStreamAlert 将用户数据流告警发布为事件（listenKey 更换为 warning，其余为 critical）
+// 单次保活失败通常是网络抖动，连续失败才发布事件
+func publishUserStreamAlert(eventBus *event.EventBus, symbol string, alert exchange.UserStreamAlert) {
+	logger.Warn("⚠️ [%s] 用户数据流告警 (%s): %s", symbol, alert.Kind, alert.Message)
+	if eventBus == nil {
+		return
+	}
+	if alert.Kind == exchange.UserStreamAlertKeepAliveFailed && alert.ConsecutiveFailures < 2 {
+		return
+	}
+	eventType := event.EventTypeUserStreamUnhealthy
+	if alert.Kind == exchange.UserStreamAlertListenKeyRenewed {
+		eventType = event.EventTypeListenKeyRenewed
+	}
+	eventBus.Publish(&event.Event{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"exchange":             alert.Exchange,
+			"symbol":               symbol,
+			"kind":                 alert.Kind,
+			"consecutive_failures": alert.ConsecutiveFailures,
+			"message":              alert.Message,
+		},
+	})
+}
+
+// supportZones 将触及次数足够的支撑位转换为买单加密区间（支撑位上下 zonePercent%）
+func supportZones(snap strategy.LevelSnapshot, zonePercent float64, minTouches int) []position.PriceZone {
+	var zones []position.PriceZone
+	for _, level := range snap.Supports {
+		if level.Touches < minTouches {
+			continue
+		}
+		zones = append(zones, position.PriceZone{
+			Low:  level.Price * (1 - zonePercent/100),
+			High: level.Price * (1 + zonePercent/100),
+		})
+	}
+	return zones
+}
+
+// recordStatusMetrics 把定期状态（持仓、挂单、累计买卖与预计盈利）写入 Prometheus 指标
+func recordStatusMetrics(exchangeName, symbol string, spm *position.SuperPositionManager, priceMonitor *monitor.PriceMonitor) {
+	pm := metrics.GetPrometheusMetrics()
+	qty, _, _ := spm.GetCostBasis()
+	pm.SetPositionSize(exchangeName, symbol, qty)
+	if priceMonitor != nil {
+		if price := priceMonitor.GetLastPrice(); price > 0 {
+			pm.SetPositionValue(exchangeName, symbol, qty*price)
+		}
+	}
+	buys, sells := spm.ActiveOrderCounts()
+	pm.SetActiveOrdersCount(exchangeName, symbol, "BUY", buys)
+	pm.SetActiveOrdersCount(exchangeName, symbol, "SELL", sells)
+	totalSellQty := spm.GetTotalSellQty()
+	pm.SetGridStatus(exchangeName, symbol, spm.GetActiveLayers(), spm.GetTotalBuyQty(), totalSellQty, totalSellQty*spm.GetPriceInterval())
+}
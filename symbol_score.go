@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"quantmesh/exchange"
+	"quantmesh/logger"
+	"quantmesh/safety"
+	"quantmesh/storage"
+	"quantmesh/web"
+)
+
+// symbolScorer 候选交易对适配度评估：借用同一交易所运行中交易对的客户端查询行情，资金费率历史来自资金费率监控的记录
+type symbolScorer struct {
+	manager        *SymbolManager
+	storageService *storage.StorageService
+}
+
+func newSymbolScorer(manager *SymbolManager, storageService *storage.StorageService) *symbolScorer {
+	return &symbolScorer{manager: manager, storageService: storageService}
+}
+
+// ScoreSymbol 拉取 K 线、资金费率、盘口价差和成交额后评估
+func (s *symbolScorer) ScoreSymbol(ctx context.Context, req web.SymbolScoreRequest) (*web.SymbolScoreReport, error) {
+	ex := s.exchangeFor(req.Exchange)
+	if ex == nil {
+		return nil, fmt.Errorf("交易所 %s 没有运行中的交易对，无法查询行情", req.Exchange)
+	}
+	exName := ex.GetName()
+	report := &web.SymbolScoreReport{
+		Exchange:      exName,
+		Symbol:        req.Symbol,
+		FundingSource: "none",
+		SpreadSource:  "none",
+		VolumeSource:  "klines",
+		GeneratedAt:   time.Now(),
+	}
+
+	candles, err := ex.GetHistoricalKlines(ctx, req.Symbol, "1h", req.LookbackHours+1)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s K 线失败: %w", req.Symbol, err)
+	}
+	closes := make([]float64, 0, len(candles))
+	for _, c := range candles {
+		if c.Close > 0 {
+			closes = append(closes, c.Close)
+		}
+	}
+	report.Candles = len(closes)
+	if len(closes) == 0 {
+		return nil, fmt.Errorf("交易对 %s 没有 K 线数据", req.Symbol)
+	}
+	report.Price = closes[len(closes)-1]
+	if price, err := ex.GetLatestPrice(ctx, req.Symbol); err == nil && price > 0 {
+		report.Price = price
+	}
+
+	// 成交额：优先使用 24 小时行情，不支持时按最近 24 根小时 K 线估算
+	if provider, ok := exchange.As[exchange.TickerStatsProvider](ex); ok {
+		if tickers, err := provider.GetTickers24h(ctx); err == nil {
+			for _, t := range tickers {
+				if t.Symbol == req.Symbol {
+					report.QuoteVolume24h, report.VolumeSource = t.QuoteVolume, "ticker"
+					break
+				}
+			}
+		}
+	}
+	if report.VolumeSource == "klines" {
+		start := len(candles) - 24
+		if start < 0 {
+			start = 0
+		}
+		for _, c := range candles[start:] {
+			report.QuoteVolume24h += c.Volume * c.Close
+		}
+	}
+
+	funding := s.fundingHistory(exName, req.Symbol, req.FundingSamples)
+	if len(funding) > 0 {
+		report.FundingSource = "history"
+	} else if rate, err := ex.GetFundingRate(ctx, req.Symbol); err == nil {
+		funding, report.FundingSource = []float64{rate}, "current"
+	}
+	report.FundingSamples = len(funding)
+
+	if spread, ok := sampleSpread(ctx, ex, req.Symbol, time.Duration(req.SpreadSampleSeconds)*time.Second); ok {
+		report.Spread, report.SpreadSource = spread, "depth_stream"
+	}
+
+	report.Score, err = safety.ScoreSymbol(safety.SymbolScoreParams{
+		Price:          report.Price,
+		PriceInterval:  req.PriceInterval,
+		FeeRate:        req.FeeRate,
+		BuyWindow:      req.BuyWindowSize,
+		Closes:         closes,
+		FundingRates:   funding,
+		Spread:         report.Spread,
+		QuoteVolume24h: report.QuoteVolume24h,
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("📐 [交易对评估] %s:%s 评分 %.0f (%s)，预计年化 %.1f%%，资金费日成本 %.4f%%",
+		exName, req.Symbol, report.Score.Score, report.Score.Rating, report.Score.NetYieldAnnual*100, report.Score.FundingDragDaily*100)
+	return report, nil
+}
+
+// exchangeFor 同一交易所任意运行中交易对的客户端（查询接口均按交易对传参）
+func (s *symbolScorer) exchangeFor(name string) exchange.IExchange {
+	for _, rt := range s.manager.List() {
+		if name == "" || strings.EqualFold(rt.Config.Exchange, name) {
+			return rt.Exchange
+		}
+	}
+	return nil
+}
+
+// fundingHistory 资金费率监控记录的历史费率（交易对不在监控列表时为空）
+func (s *symbolScorer) fundingHistory(exName, symbol string, limit int) []float64 {
+	if s.storageService == nil || s.storageService.GetStorage() == nil {
+		return nil
+	}
+	history, err := s.storageService.GetStorage().GetFundingRateHistory(symbol, exName, limit)
+	if err != nil {
+		logger.Warn("⚠️ [交易对评估] 读取 %s 资金费率历史失败: %v", symbol, err)
+		return nil
+	}
+	rates := make([]float64, 0, len(history))
+	for _, h := range history {
+		rates = append(rates, h.Rate)
+	}
+	return rates
+}
+
+// sampleSpread 订阅一段时间盘口快照，返回平均相对价差 (ask-bid)/mid；交易所不支持盘口推送时返回 false
+func sampleSpread(ctx context.Context, ex exchange.IExchange, symbol string, window time.Duration) (float64, bool) {
+	streamer, ok := exchange.As[exchange.MarketDepthStreamer](ex)
+	if !ok || window <= 0 {
+		return 0, false
+	}
+	streamCtx, cancel := context.WithTimeout(ctx, window)
+	defer cancel()
+
+	var mu sync.Mutex
+	var sum float64
+	var samples int
+	err := streamer.StartDepthStream(streamCtx, symbol, func(d *exchange.DepthUpdate) {
+		if len(d.Bids) == 0 || len(d.Asks) == 0 {
+			return
+		}
+		bid, ask := d.Bids[0].Price, d.Asks[0].Price
+		if bid <= 0 || ask < bid {
+			return
+		}
+		mu.Lock()
+		sum += (ask - bid) / ((ask + bid) / 2)
+		samples++
+		mu.Unlock()
+	})
+	if err != nil {
+		return 0, false
+	}
+	<-streamCtx.Done()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if samples == 0 {
+		return 0, false
+	}
+	return sum / float64(samples), true
+}
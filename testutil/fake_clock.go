@@ -0,0 +1,59 @@
+package testutil
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock 可手动推进的确定性时钟
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+}
+
+// fakeTimer 到期后由 Advance 执行的回调
+type fakeTimer struct {
+	at time.Time
+	f  func()
+}
+
+// NewFakeClock 创建假时钟
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now 返回当前时间（可作为 position.Dependencies.Now 注入）
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc 在时钟推进 d 后执行 f（可作为 position.Dependencies.AfterFunc 注入）
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), f: f})
+}
+
+// Advance 推进时钟，并在调用方协程中依次执行到期的回调
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var due []func()
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.at.After(c.now) {
+			pending = append(pending, timer)
+		} else {
+			due = append(due, timer.f)
+		}
+	}
+	c.timers = pending
+	c.mu.Unlock()
+
+	for _, f := range due {
+		f()
+	}
+}
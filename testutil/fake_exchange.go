@@ -0,0 +1,211 @@
+package testutil
+
+import (
+	"context"
+	"sync"
+
+	"quantmesh/position"
+)
+
+// FakeAccount 假账户信息
+// 字段名与 exchange.Account 保持一致，SuperPositionManager 通过反射读取 AvailableBalance
+type FakeAccount struct {
+	TotalWalletBalance float64
+	TotalMarginBalance float64
+	AvailableBalance   float64
+}
+
+// FakeExchange 确定性的假交易所（实现 position.IExchange）
+// 持仓、挂单、账户均由测试脚本设置；可针对单个方法注入错误
+type FakeExchange struct {
+	mu sync.Mutex
+
+	name             string
+	baseAsset        string
+	priceDecimals    int
+	quantityDecimals int
+
+	positions  []*position.PositionInfo
+	openOrders []*position.Order
+	orders     map[int64]*position.Order
+	account    *FakeAccount
+
+	errors map[string]error // 方法名 -> 注入的错误
+	calls  map[string]int   // 方法名 -> 调用次数
+}
+
+// NewFakeExchange 创建假交易所
+func NewFakeExchange(name string, priceDecimals, quantityDecimals int) *FakeExchange {
+	if name == "" {
+		name = "fake"
+	}
+	return &FakeExchange{
+		name:             name,
+		baseAsset:        "BTC",
+		priceDecimals:    priceDecimals,
+		quantityDecimals: quantityDecimals,
+		orders:           make(map[int64]*position.Order),
+		account:          &FakeAccount{AvailableBalance: 10000},
+		errors:           make(map[string]error),
+		calls:            make(map[string]int),
+	}
+}
+
+// SetPosition 设置某交易对的持仓数量（正数多仓，负数空仓）
+func (f *FakeExchange) SetPosition(symbol string, size float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, p := range f.positions {
+		if p.Symbol == symbol {
+			p.Size = size
+			return
+		}
+	}
+	f.positions = append(f.positions, &position.PositionInfo{Symbol: symbol, Size: size})
+}
+
+// SetOpenOrders 设置当前挂单
+func (f *FakeExchange) SetOpenOrders(orders []*position.Order) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.openOrders = orders
+	for _, o := range orders {
+		f.orders[o.OrderID] = o
+	}
+}
+
+// SetAvailableBalance 设置可用余额
+func (f *FakeExchange) SetAvailableBalance(balance float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.account.AvailableBalance = balance
+}
+
+// SetBaseAsset 设置基础资产
+func (f *FakeExchange) SetBaseAsset(asset string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.baseAsset = asset
+}
+
+// FailOn 为指定方法注入错误（err 为 nil 时清除）
+func (f *FakeExchange) FailOn(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.errors, method)
+		return
+	}
+	f.errors[method] = err
+}
+
+// CallCount 获取方法调用次数
+func (f *FakeExchange) CallCount(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[method]
+}
+
+// record 记录调用并返回注入的错误（调用方需持有锁）
+func (f *FakeExchange) record(method string) error {
+	f.calls[method]++
+	return f.errors[method]
+}
+
+// GetName 获取交易所名称
+func (f *FakeExchange) GetName() string {
+	return f.name
+}
+
+// GetPositions 获取持仓（返回 []*position.PositionInfo）
+func (f *FakeExchange) GetPositions(ctx context.Context, symbol string) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetPositions"); err != nil {
+		return nil, err
+	}
+	result := make([]*position.PositionInfo, 0, len(f.positions))
+	for _, p := range f.positions {
+		if symbol == "" || p.Symbol == symbol {
+			cp := *p
+			result = append(result, &cp)
+		}
+	}
+	return result, nil
+}
+
+// GetOpenOrders 获取挂单（返回 []*position.Order）
+func (f *FakeExchange) GetOpenOrders(ctx context.Context, symbol string) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetOpenOrders"); err != nil {
+		return nil, err
+	}
+	result := make([]*position.Order, 0, len(f.openOrders))
+	for _, o := range f.openOrders {
+		if symbol == "" || o.Symbol == symbol {
+			cp := *o
+			result = append(result, &cp)
+		}
+	}
+	return result, nil
+}
+
+// GetOrder 查询订单
+func (f *FakeExchange) GetOrder(ctx context.Context, symbol string, orderID int64) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetOrder"); err != nil {
+		return nil, err
+	}
+	if o, ok := f.orders[orderID]; ok {
+		cp := *o
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+// GetBaseAsset 获取基础资产
+func (f *FakeExchange) GetBaseAsset() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.baseAsset
+}
+
+// CancelAllOrders 撤销所有订单
+func (f *FakeExchange) CancelAllOrders(ctx context.Context, symbol string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("CancelAllOrders"); err != nil {
+		return err
+	}
+	remaining := f.openOrders[:0]
+	for _, o := range f.openOrders {
+		if symbol != "" && o.Symbol != symbol {
+			remaining = append(remaining, o)
+		}
+	}
+	f.openOrders = remaining
+	return nil
+}
+
+// GetAccount 获取账户信息（返回 *FakeAccount）
+func (f *FakeExchange) GetAccount(ctx context.Context) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetAccount"); err != nil {
+		return nil, err
+	}
+	cp := *f.account
+	return &cp, nil
+}
+
+// GetPriceDecimals 获取价格精度
+func (f *FakeExchange) GetPriceDecimals() int {
+	return f.priceDecimals
+}
+
+// GetQuantityDecimals 获取数量精度
+func (f *FakeExchange) GetQuantityDecimals() int {
+	return f.quantityDecimals
+}
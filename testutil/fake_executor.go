@@ -0,0 +1,355 @@
+package testutil
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"quantmesh/position"
+)
+
+// RejectReason 下单拒绝原因
+type RejectReason string
+
+const (
+	RejectGeneric    RejectReason = "generic"     // 普通拒绝（订单未提交）
+	RejectMargin     RejectReason = "margin"      // 保证金不足
+	RejectReduceOnly RejectReason = "reduce_only" // ReduceOnly 被拒绝（无持仓可平）
+)
+
+// Rejection 下单拒绝脚本
+// Side/Price 为空值时匹配任意订单；Times 为 0 时永久生效
+type Rejection struct {
+	Side   string
+	Price  float64
+	Reason RejectReason
+	Times  int
+}
+
+// ScriptedFill 自动成交脚本：匹配的订单提交后立即推送成交（模拟秒成交）
+// Ratio 为成交比例（0 或 1 表示全部成交，0~1 表示部分成交）
+type ScriptedFill struct {
+	Side  string
+	Price float64
+	Ratio float64
+}
+
+// FakeExecutor 确定性的假订单执行器（实现 position.OrderExecutorInterface）
+// 订单 ID 按提交顺序递增；通过 Fill/Cancel 等方法生成订单推送，并交给 UpdateHandler 处理
+type FakeExecutor struct {
+	mu sync.Mutex
+
+	nextOrderID int64
+	latency     time.Duration
+
+	orders   map[string]*fakeOrder // ClientOrderID -> 订单
+	placed   []*position.OrderRequest
+	canceled []int64
+
+	rejections    []*Rejection
+	scriptedFills []ScriptedFill
+
+	// UpdateHandler 订单推送处理函数（通常为 SuperPositionManager.OnOrderUpdate）
+	UpdateHandler func(update position.OrderUpdate)
+}
+
+type fakeOrder struct {
+	order       position.Order
+	executedQty float64
+	final       bool
+}
+
+// NewFakeExecutor 创建假订单执行器
+func NewFakeExecutor() *FakeExecutor {
+	return &FakeExecutor{
+		nextOrderID: 1000,
+		orders:      make(map[string]*fakeOrder),
+	}
+}
+
+// SetLatency 设置每次下单的模拟延迟
+func (f *FakeExecutor) SetLatency(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency = d
+}
+
+// Reject 添加下单拒绝脚本
+func (f *FakeExecutor) Reject(r Rejection) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rc := r
+	f.rejections = append(f.rejections, &rc)
+}
+
+// FillOnPlace 添加自动成交脚本
+func (f *FakeExecutor) FillOnPlace(fill ScriptedFill) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scriptedFills = append(f.scriptedFills, fill)
+}
+
+// PlaceOrder 下单
+func (f *FakeExecutor) PlaceOrder(req *position.OrderRequest) (*position.Order, error) {
+	result := f.BatchPlaceOrdersWithDetails([]*position.OrderRequest{req})
+	if len(result.PlacedOrders) == 0 {
+		return nil, fmt.Errorf("order rejected: %s %s @ %.8f", req.Side, req.ClientOrderID, req.Price)
+	}
+	return result.PlacedOrders[0], nil
+}
+
+// BatchPlaceOrders 批量下单
+func (f *FakeExecutor) BatchPlaceOrders(orders []*position.OrderRequest) ([]*position.Order, bool) {
+	result := f.BatchPlaceOrdersWithDetails(orders)
+	return result.PlacedOrders, result.HasMarginError
+}
+
+// BatchPlaceOrdersWithDetails 批量下单（返回详细结果）
+func (f *FakeExecutor) BatchPlaceOrdersWithDetails(orders []*position.OrderRequest) *position.BatchPlaceOrdersResult {
+	f.mu.Lock()
+	latency := f.latency
+	f.mu.Unlock()
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	result := &position.BatchPlaceOrdersResult{
+		PlacedOrders:     make([]*position.Order, 0, len(orders)),
+		ReduceOnlyErrors: make(map[string]bool),
+	}
+	var instantFills []position.OrderUpdate
+
+	f.mu.Lock()
+	for _, req := range orders {
+		cp := *req
+		f.placed = append(f.placed, &cp)
+
+		if reason, rejected := f.matchRejection(req); rejected {
+			switch reason {
+			case RejectMargin:
+				result.HasMarginError = true
+			case RejectReduceOnly:
+				result.ReduceOnlyErrors[req.ClientOrderID] = true
+			}
+			continue
+		}
+
+		f.nextOrderID++
+		ord := position.Order{
+			OrderID:       f.nextOrderID,
+			ClientOrderID: req.ClientOrderID,
+			Symbol:        req.Symbol,
+			Side:          req.Side,
+			Price:         req.Price,
+			Quantity:      req.Quantity,
+			Status:        "NEW",
+			CreatedAt:     time.Unix(0, 0),
+		}
+		fo := &fakeOrder{order: ord}
+		f.orders[req.ClientOrderID] = fo
+		out := ord
+		result.PlacedOrders = append(result.PlacedOrders, &out)
+
+		if fill, ok := f.matchScriptedFill(req); ok {
+			qty := req.Quantity
+			if fill.Ratio > 0 && fill.Ratio < 1 {
+				qty = req.Quantity * fill.Ratio
+			}
+			instantFills = append(instantFills, f.fillLocked(fo, qty))
+		}
+	}
+	handler := f.UpdateHandler
+	f.mu.Unlock()
+
+	// 秒成交推送在下单返回之前送达（与真实 WebSocket 先于 REST 返回的场景一致）
+	if handler != nil {
+		for _, u := range instantFills {
+			handler(u)
+		}
+	}
+	return result
+}
+
+// BatchCancelOrders 批量撤单（只记录，撤单推送需通过 Cancel 生成）
+func (f *FakeExecutor) BatchCancelOrders(orderIDs []int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.canceled = append(f.canceled, orderIDs...)
+	return nil
+}
+
+// matchRejection 匹配拒绝脚本（调用方需持有锁）
+func (f *FakeExecutor) matchRejection(req *position.OrderRequest) (RejectReason, bool) {
+	for i, r := range f.rejections {
+		if r.Side != "" && r.Side != req.Side {
+			continue
+		}
+		if r.Price != 0 && r.Price != req.Price {
+			continue
+		}
+		reason := r.Reason
+		if reason == "" {
+			reason = RejectGeneric
+		}
+		if r.Times > 0 {
+			r.Times--
+			if r.Times == 0 {
+				f.rejections = append(f.rejections[:i], f.rejections[i+1:]...)
+			}
+		}
+		return reason, true
+	}
+	return "", false
+}
+
+// matchScriptedFill 匹配自动成交脚本（调用方需持有锁）
+func (f *FakeExecutor) matchScriptedFill(req *position.OrderRequest) (ScriptedFill, bool) {
+	for _, s := range f.scriptedFills {
+		if s.Side != "" && s.Side != req.Side {
+			continue
+		}
+		if s.Price != 0 && s.Price != req.Price {
+			continue
+		}
+		return s, true
+	}
+	return ScriptedFill{}, false
+}
+
+// fillLocked 为订单增加成交量并生成推送（调用方需持有锁）
+func (f *FakeExecutor) fillLocked(fo *fakeOrder, qty float64) position.OrderUpdate {
+	fo.executedQty += qty
+	status := "PARTIALLY_FILLED"
+	if fo.executedQty >= fo.order.Quantity-1e-12 {
+		fo.executedQty = fo.order.Quantity
+		status = "FILLED"
+		fo.final = true
+	}
+	fo.order.Status = status
+	return f.updateFor(fo, status)
+}
+
+func (f *FakeExecutor) updateFor(fo *fakeOrder, status string) position.OrderUpdate {
+	return position.OrderUpdate{
+		OrderID:       fo.order.OrderID,
+		ClientOrderID: fo.order.ClientOrderID,
+		Symbol:        fo.order.Symbol,
+		Status:        status,
+		ExecutedQty:   fo.executedQty,
+		Price:         fo.order.Price,
+		AvgPrice:      fo.order.Price,
+		Side:          fo.order.Side,
+		Type:          "LIMIT",
+	}
+}
+
+// Fill 将订单全部成交并推送
+func (f *FakeExecutor) Fill(clientOrderID string) (position.OrderUpdate, error) {
+	f.mu.Lock()
+	fo, ok := f.orders[clientOrderID]
+	if !ok {
+		f.mu.Unlock()
+		return position.OrderUpdate{}, fmt.Errorf("unknown order: %s", clientOrderID)
+	}
+	u := f.fillLocked(fo, fo.order.Quantity-fo.executedQty)
+	handler := f.UpdateHandler
+	f.mu.Unlock()
+
+	if handler != nil {
+		handler(u)
+	}
+	return u, nil
+}
+
+// PartialFill 将订单部分成交（qty 为本次成交增量）并推送
+func (f *FakeExecutor) PartialFill(clientOrderID string, qty float64) (position.OrderUpdate, error) {
+	f.mu.Lock()
+	fo, ok := f.orders[clientOrderID]
+	if !ok {
+		f.mu.Unlock()
+		return position.OrderUpdate{}, fmt.Errorf("unknown order: %s", clientOrderID)
+	}
+	u := f.fillLocked(fo, qty)
+	handler := f.UpdateHandler
+	f.mu.Unlock()
+
+	if handler != nil {
+		handler(u)
+	}
+	return u, nil
+}
+
+// Cancel 撤销订单并推送 CANCELED
+func (f *FakeExecutor) Cancel(clientOrderID string) (position.OrderUpdate, error) {
+	f.mu.Lock()
+	fo, ok := f.orders[clientOrderID]
+	if !ok {
+		f.mu.Unlock()
+		return position.OrderUpdate{}, fmt.Errorf("unknown order: %s", clientOrderID)
+	}
+	fo.final = true
+	fo.order.Status = "CANCELED"
+	u := f.updateFor(fo, "CANCELED")
+	handler := f.UpdateHandler
+	f.mu.Unlock()
+
+	if handler != nil {
+		handler(u)
+	}
+	return u, nil
+}
+
+// Replay 重新推送订单的最近一次状态（用于模拟重复推送）
+func (f *FakeExecutor) Replay(clientOrderID string) (position.OrderUpdate, error) {
+	f.mu.Lock()
+	fo, ok := f.orders[clientOrderID]
+	if !ok {
+		f.mu.Unlock()
+		return position.OrderUpdate{}, fmt.Errorf("unknown order: %s", clientOrderID)
+	}
+	u := f.updateFor(fo, fo.order.Status)
+	handler := f.UpdateHandler
+	f.mu.Unlock()
+
+	if handler != nil {
+		handler(u)
+	}
+	return u, nil
+}
+
+// OpenOrders 返回尚未终结的订单（按订单 ID 排序）
+func (f *FakeExecutor) OpenOrders(side string) []position.Order {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result []position.Order
+	for _, fo := range f.orders {
+		if fo.final {
+			continue
+		}
+		if side != "" && fo.order.Side != side {
+			continue
+		}
+		result = append(result, fo.order)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].OrderID < result[j].OrderID })
+	return result
+}
+
+// PlacedRequests 返回所有下单请求（包括被拒绝的）
+func (f *FakeExecutor) PlacedRequests() []*position.OrderRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := make([]*position.OrderRequest, len(f.placed))
+	copy(result, f.placed)
+	return result
+}
+
+// CanceledOrderIDs 返回所有撤单请求的订单 ID
+func (f *FakeExecutor) CanceledOrderIDs() []int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := make([]int64, len(f.canceled))
+	copy(result, f.canceled)
+	return result
+}
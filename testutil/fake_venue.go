@@ -0,0 +1,415 @@
+package testutil
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"quantmesh/exchange"
+)
+
+var _ exchange.IExchange = (*FakeVenue)(nil)
+
+// FakeVenue 确定性的假交易所（实现 exchange.IExchange）
+// 与 FakeExchange 对应，供直接使用交易所接口的组件（对冲、监控等）测试；
+// 价格、持仓、账户由测试脚本设置，市价单按当前价格立即成交并更新持仓，限价单保留为挂单直到被撤销或由 Fill 成交，
+// 可针对单个方法注入错误。
+// 只实现 IExchange，不实现任何可选接口（PermissionChecker 等），需要时在测试中嵌入后补充
+type FakeVenue struct {
+	mu sync.Mutex
+
+	name             string
+	baseAsset        string
+	quoteAsset       string
+	priceDecimals    int
+	quantityDecimals int
+
+	prices    map[string]float64
+	positions map[string]float64
+	account   exchange.Account
+	placed    []*exchange.OrderRequest
+	orders    map[int64]*exchange.Order
+	nextID    int64
+
+	errors map[string]error // 方法名 -> 注入的错误
+	calls  map[string]int   // 方法名 -> 调用次数
+}
+
+// NewFakeVenue 创建假交易所
+func NewFakeVenue(name string, priceDecimals, quantityDecimals int) *FakeVenue {
+	if name == "" {
+		name = "fake"
+	}
+	return &FakeVenue{
+		name:             name,
+		baseAsset:        "BTC",
+		quoteAsset:       "USDT",
+		priceDecimals:    priceDecimals,
+		quantityDecimals: quantityDecimals,
+		prices:           make(map[string]float64),
+		positions:        make(map[string]float64),
+		orders:           make(map[int64]*exchange.Order),
+		account:          exchange.Account{AvailableBalance: 10000, TotalWalletBalance: 10000, TotalMarginBalance: 10000},
+		nextID:           1,
+		errors:           make(map[string]error),
+		calls:            make(map[string]int),
+	}
+}
+
+// SetPrice 设置交易对最新价格
+func (f *FakeVenue) SetPrice(symbol string, price float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prices[symbol] = price
+}
+
+// SetPosition 设置某交易对的持仓数量（正数多仓，负数空仓）
+func (f *FakeVenue) SetPosition(symbol string, size float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.positions[symbol] = size
+}
+
+// Position 获取某交易对的持仓数量
+func (f *FakeVenue) Position(symbol string) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.positions[symbol]
+}
+
+// SetAvailableBalance 设置可用余额
+func (f *FakeVenue) SetAvailableBalance(balance float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.account.AvailableBalance = balance
+}
+
+// FailOn 为指定方法注入错误（err 为 nil 时清除）
+func (f *FakeVenue) FailOn(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.errors, method)
+		return
+	}
+	f.errors[method] = err
+}
+
+// CallCount 获取方法调用次数
+func (f *FakeVenue) CallCount(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[method]
+}
+
+// PlacedOrders 获取已提交的下单请求（副本）
+func (f *FakeVenue) PlacedOrders() []exchange.OrderRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := make([]exchange.OrderRequest, len(f.placed))
+	for i, req := range f.placed {
+		result[i] = *req
+	}
+	return result
+}
+
+// AddOpenOrder 放入一笔不经 PlaceOrder 提交的挂单（如手动下的订单），OrderID 为 0 时自动分配
+func (f *FakeVenue) AddOpenOrder(order exchange.Order) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if order.OrderID == 0 {
+		order.OrderID = f.nextID
+		f.nextID++
+	}
+	if order.Status == "" {
+		order.Status = exchange.OrderStatusNew
+	}
+	f.orders[order.OrderID] = &order
+	return order.OrderID
+}
+
+// Fill 按数量成交一笔挂单（qty 达到剩余数量时完全成交），返回成交后的订单副本
+func (f *FakeVenue) Fill(orderID int64, qty float64) *exchange.Order {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil
+	}
+	if remaining := order.Quantity - order.ExecutedQty; qty > remaining {
+		qty = remaining
+	}
+	order.ExecutedQty += qty
+	order.AvgPrice = order.Price
+	order.Status = exchange.OrderStatusPartiallyFilled
+	if order.ExecutedQty >= order.Quantity {
+		order.Status = exchange.OrderStatusFilled
+	}
+	if order.Side == exchange.SideSell {
+		qty = -qty
+	}
+	f.positions[order.Symbol] += qty
+	cp := *order
+	return &cp
+}
+
+// Order 获取订单副本（不计入调用次数）
+func (f *FakeVenue) Order(orderID int64) *exchange.Order {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil
+	}
+	cp := *order
+	return &cp
+}
+
+// cancelLocked 撤销仍在挂单中的订单（调用方需持有锁）
+func (f *FakeVenue) cancelLocked(orderID int64) {
+	if order, ok := f.orders[orderID]; ok && isOpenStatus(order.Status) {
+		order.Status = exchange.OrderStatusCanceled
+	}
+}
+
+func isOpenStatus(status exchange.OrderStatus) bool {
+	return status == exchange.OrderStatusNew || status == exchange.OrderStatusPartiallyFilled
+}
+
+// record 记录调用并返回注入的错误（调用方需持有锁）
+func (f *FakeVenue) record(method string) error {
+	f.calls[method]++
+	return f.errors[method]
+}
+
+// GetName 获取交易所名称
+func (f *FakeVenue) GetName() string {
+	return f.name
+}
+
+// PlaceOrder 下单：市价单按当前价格立即成交并更新持仓，限价单保持挂单状态
+func (f *FakeVenue) PlaceOrder(ctx context.Context, req *exchange.OrderRequest) (*exchange.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("PlaceOrder"); err != nil {
+		return nil, err
+	}
+	cp := *req
+	f.placed = append(f.placed, &cp)
+	order := &exchange.Order{
+		OrderID:       f.nextID,
+		ClientOrderID: req.ClientOrderID,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		Status:        exchange.OrderStatusNew,
+		CreatedAt:     time.Now(),
+	}
+	f.nextID++
+	if req.Type == exchange.OrderTypeMarket {
+		qty := req.Quantity
+		if req.Side == exchange.SideSell {
+			qty = -qty
+		}
+		f.positions[req.Symbol] += qty
+		order.Status = exchange.OrderStatusFilled
+		order.ExecutedQty = req.Quantity
+		order.AvgPrice = f.prices[req.Symbol]
+	}
+	stored := *order
+	f.orders[order.OrderID] = &stored
+	return order, nil
+}
+
+// BatchPlaceOrders 批量下单（逐笔调用 PlaceOrder）
+func (f *FakeVenue) BatchPlaceOrders(ctx context.Context, orders []*exchange.OrderRequest) ([]*exchange.Order, bool) {
+	var placed []*exchange.Order
+	for _, req := range orders {
+		if order, err := f.PlaceOrder(ctx, req); err == nil {
+			placed = append(placed, order)
+		}
+	}
+	return placed, false
+}
+
+// CancelOrder 撤单
+func (f *FakeVenue) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("CancelOrder"); err != nil {
+		return err
+	}
+	f.cancelLocked(orderID)
+	return nil
+}
+
+// BatchCancelOrders 批量撤单
+func (f *FakeVenue) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("BatchCancelOrders"); err != nil {
+		return err
+	}
+	for _, orderID := range orderIDs {
+		f.cancelLocked(orderID)
+	}
+	return nil
+}
+
+// CancelAllOrders 撤销所有订单
+func (f *FakeVenue) CancelAllOrders(ctx context.Context, symbol string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("CancelAllOrders"); err != nil {
+		return err
+	}
+	for _, order := range f.orders {
+		if symbol == "" || order.Symbol == symbol {
+			f.cancelLocked(order.OrderID)
+		}
+	}
+	return nil
+}
+
+// GetOrder 查询订单（订单不存在时返回 nil）
+func (f *FakeVenue) GetOrder(ctx context.Context, symbol string, orderID int64) (*exchange.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetOrder"); err != nil {
+		return nil, err
+	}
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil, nil
+	}
+	cp := *order
+	return &cp, nil
+}
+
+// GetOpenOrders 查询挂单（按 OrderID 排序）
+func (f *FakeVenue) GetOpenOrders(ctx context.Context, symbol string) ([]*exchange.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetOpenOrders"); err != nil {
+		return nil, err
+	}
+	result := []*exchange.Order{}
+	for _, order := range f.orders {
+		if isOpenStatus(order.Status) && (symbol == "" || order.Symbol == symbol) {
+			cp := *order
+			result = append(result, &cp)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].OrderID < result[j].OrderID })
+	return result, nil
+}
+
+// GetAccount 获取账户信息
+func (f *FakeVenue) GetAccount(ctx context.Context) (*exchange.Account, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetAccount"); err != nil {
+		return nil, err
+	}
+	cp := f.account
+	return &cp, nil
+}
+
+// GetPositions 获取持仓（symbol 为空时返回全部）
+func (f *FakeVenue) GetPositions(ctx context.Context, symbol string) ([]*exchange.Position, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetPositions"); err != nil {
+		return nil, err
+	}
+	result := make([]*exchange.Position, 0, len(f.positions))
+	for s, size := range f.positions {
+		if (symbol == "" || s == symbol) && size != 0 {
+			result = append(result, &exchange.Position{Symbol: s, Size: size, MarkPrice: f.prices[s]})
+		}
+	}
+	return result, nil
+}
+
+// GetBalance 获取余额
+func (f *FakeVenue) GetBalance(ctx context.Context, asset string) (float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetBalance"); err != nil {
+		return 0, err
+	}
+	return f.account.AvailableBalance, nil
+}
+
+// StartOrderStream 启动订单流（假交易所不推送）
+func (f *FakeVenue) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	return nil
+}
+
+// StopOrderStream 停止订单流
+func (f *FakeVenue) StopOrderStream() error {
+	return nil
+}
+
+// GetLatestPrice 获取最新价格
+func (f *FakeVenue) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetLatestPrice"); err != nil {
+		return 0, err
+	}
+	return f.prices[symbol], nil
+}
+
+// StartPriceStream 启动价格流（假交易所不推送）
+func (f *FakeVenue) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	return nil
+}
+
+// StartKlineStream 启动K线流（假交易所不推送）
+func (f *FakeVenue) StartKlineStream(ctx context.Context, symbols []string, interval string, callback exchange.CandleUpdateCallback) error {
+	return nil
+}
+
+// StopKlineStream 停止K线流
+func (f *FakeVenue) StopKlineStream() error {
+	return nil
+}
+
+// GetHistoricalKlines 获取历史K线（假交易所没有历史数据）
+func (f *FakeVenue) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*exchange.Candle, error) {
+	return nil, nil
+}
+
+// GetPriceDecimals 获取价格精度
+func (f *FakeVenue) GetPriceDecimals() int {
+	return f.priceDecimals
+}
+
+// GetQuantityDecimals 获取数量精度
+func (f *FakeVenue) GetQuantityDecimals() int {
+	return f.quantityDecimals
+}
+
+// GetBaseAsset 获取基础资产
+func (f *FakeVenue) GetBaseAsset() string {
+	return f.baseAsset
+}
+
+// GetQuoteAsset 获取计价资产
+func (f *FakeVenue) GetQuoteAsset() string {
+	return f.quoteAsset
+}
+
+// GetFundingRate 获取资金费率
+func (f *FakeVenue) GetFundingRate(ctx context.Context, symbol string) (float64, error) {
+	return 0, nil
+}
+
+// GetSpotPrice 获取现货价格（与合约价格相同）
+func (f *FakeVenue) GetSpotPrice(ctx context.Context, symbol string) (float64, error) {
+	return f.GetLatestPrice(ctx, symbol)
+}
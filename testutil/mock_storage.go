@@ -0,0 +1,783 @@
+// Code generated by tools/storagemock; DO NOT EDIT.
+
+package testutil
+
+import (
+	"sync"
+	"time"
+
+	"quantmesh/storage"
+)
+
+var _ storage.Storage = (*MockStorage)(nil)
+
+// MockStorage 可编程的 storage.Storage mock
+// 设置了 XxxFunc 的方法调用该函数，否则委托给 Base（默认为内存存储）；所有调用都会记录次数
+type MockStorage struct {
+	Base storage.Storage
+
+	mu    sync.Mutex
+	calls map[string]int
+
+	SaveOrderFunc                      func(order *storage.Order) error
+	SavePositionFunc                   func(position *storage.Position) error
+	SaveTradeFunc                      func(trade *storage.Trade) error
+	SaveEventFunc                      func(eventType string, data map[string]interface{}) error
+	SaveStatisticsFunc                 func(stats *storage.Statistics) error
+	SaveSystemMetricsFunc              func(metrics *storage.SystemMetrics) error
+	SaveDailySystemMetricsFunc         func(metrics *storage.DailySystemMetrics) error
+	QuerySystemMetricsFunc             func(startTime time.Time, endTime time.Time) ([]*storage.SystemMetrics, error)
+	QueryDailySystemMetricsFunc        func(days int) ([]*storage.DailySystemMetrics, error)
+	GetLatestSystemMetricsFunc         func() (*storage.SystemMetrics, error)
+	CleanupSystemMetricsFunc           func(beforeTime time.Time) error
+	CleanupDailySystemMetricsFunc      func(beforeDate time.Time) error
+	QueryOrdersFunc                    func(exchange string, symbol string, limit int, offset int, status string) ([]*storage.Order, error)
+	QueryTradesFunc                    func(exchange string, symbol string, startTime time.Time, endTime time.Time, limit int, offset int) ([]*storage.Trade, error)
+	QueryStatisticsFunc                func(exchange string, symbol string, startDate time.Time, endDate time.Time) ([]*storage.Statistics, error)
+	GetStatisticsSummaryFunc           func() (*storage.Statistics, error)
+	GetStatisticsSummaryByExchangeFunc func(exchange string) (*storage.Statistics, error)
+	GetStatisticsSummaryBySymbolFunc   func(exchange string, symbol string) (*storage.Statistics, error)
+	QueryDailyStatisticsFromTradesFunc func(startDate time.Time, endDate time.Time) ([]*storage.DailyStatisticsWithTradeCount, error)
+	QueryDailyStatisticsByExchangeFunc func(exchange string, startDate time.Time, endDate time.Time) ([]*storage.DailyStatisticsWithTradeCount, error)
+	QueryDailyStatisticsBySymbolFunc   func(exchange string, symbol string, startDate time.Time, endDate time.Time) ([]*storage.DailyStatisticsWithTradeCount, error)
+	SaveReconciliationHistoryFunc      func(history *storage.ReconciliationHistory) error
+	QueryReconciliationHistoryFunc     func(exchange string, symbol string, startTime time.Time, endTime time.Time, limit int, offset int) ([]*storage.ReconciliationHistory, error)
+	GetLatestReconciliationHistoryFunc func(exchange string, symbol string) (*storage.ReconciliationHistory, error)
+	GetReconciliationCountFunc         func(exchange string, symbol string) (int64, error)
+	GetPnLBySymbolFunc                 func(exchange string, symbol string, startTime time.Time, endTime time.Time) (*storage.PnLSummary, error)
+	GetPnLByTimeRangeFunc              func(startTime time.Time, endTime time.Time) ([]*storage.PnLBySymbol, error)
+	GetActualProfitBySymbolFunc        func(exchange string, symbol string, beforeTime time.Time) (float64, error)
+	SaveRiskCheckFunc                  func(record *storage.RiskCheckRecord) error
+	QueryRiskCheckHistoryFunc          func(startTime time.Time, endTime time.Time, limit int) ([]*storage.RiskCheckHistory, error)
+	CleanupRiskCheckHistoryFunc        func(beforeTime time.Time) error
+	SaveFundingRateFunc                func(symbol string, exchange string, rate float64, timestamp time.Time) error
+	GetLatestFundingRateFunc           func(symbol string, exchange string) (float64, error)
+	GetFundingRateHistoryFunc          func(symbol string, exchange string, limit int) ([]*storage.FundingRate, error)
+	GetAIPromptTemplateFunc            func(module string) (*storage.AIPromptTemplate, error)
+	SetAIPromptTemplateFunc            func(template *storage.AIPromptTemplate) error
+	GetAllAIPromptTemplatesFunc        func() ([]*storage.AIPromptTemplate, error)
+	SaveAIPromptVersionFunc            func(version *storage.AIPromptVersion) error
+	GetAIPromptVersionFunc             func(module string, version int) (*storage.AIPromptVersion, error)
+	QueryAIPromptVersionsFunc          func(module string, limit int) ([]*storage.AIPromptVersion, error)
+	SetAIPromptPinFunc                 func(module string, version int) error
+	SaveBasisDataFunc                  func(data *storage.BasisData) error
+	GetLatestBasisFunc                 func(symbol string, exchange string) (*storage.BasisData, error)
+	GetBasisHistoryFunc                func(symbol string, exchange string, limit int) ([]*storage.BasisData, error)
+	GetBasisStatisticsFunc             func(symbol string, exchange string, hours int) (*storage.BasisStats, error)
+	SaveCostBasisFunc                  func(costBasis *storage.CostBasis) error
+	GetCostBasisFunc                   func(exchange string, symbol string) (*storage.CostBasis, error)
+	SaveGridAnchorFunc                 func(anchor *storage.GridAnchor) error
+	GetGridAnchorFunc                  func(exchange string, symbol string) (*storage.GridAnchor, error)
+	SaveFundingPaymentsFunc            func(payments []*storage.FundingPayment) (int, error)
+	GetLatestFundingPaymentTimeFunc    func(exchange string, symbol string) (time.Time, error)
+	QueryFundingPaymentsFunc           func(exchange string, symbol string, startTime time.Time, endTime time.Time, limit int) ([]*storage.FundingPayment, error)
+	SaveProfitTransferFunc             func(transfer *storage.ProfitTransfer) error
+	QueryProfitTransfersFunc           func(exchange string, startTime time.Time, endTime time.Time, limit int) ([]*storage.ProfitTransfer, error)
+	GetProfitTransferTotalFunc         func(exchange string, startTime time.Time, endTime time.Time) (float64, error)
+	SaveOpenInterestFunc               func(data *storage.OpenInterestData) error
+	GetOpenInterestHistoryFunc         func(symbol string, exchange string, startTime time.Time, endTime time.Time, limit int) ([]*storage.OpenInterestData, error)
+	SaveJournalEntryFunc               func(entry *storage.JournalEntry) error
+	QueryJournalEntriesFunc            func(q storage.JournalQuery) ([]*storage.JournalEntry, error)
+	SaveRejectedOrderFunc              func(order *storage.RejectedOrder) error
+	QueryRejectedOrdersFunc            func(q storage.RejectedOrderQuery) ([]*storage.RejectedOrder, error)
+	SaveOrderCleanupActionsFunc        func(actions []*storage.OrderCleanupAction) error
+	QueryOrderCleanupActionsFunc       func(q storage.OrderCleanupQuery) ([]*storage.OrderCleanupAction, error)
+	SaveOCOLinkFunc                    func(link *storage.OCOLink) error
+	QueryActiveOCOLinksFunc            func(exchange string, symbol string) ([]*storage.OCOLink, error)
+	SaveIncomeRecordsFunc              func(records []*storage.IncomeRecord) (int, error)
+	GetLatestIncomeTimeFunc            func(exchange string, symbol string, incomeType string) (time.Time, error)
+	SumIncomeByTypeFunc                func(exchange string, symbol string, startTime time.Time, endTime time.Time) (map[string]float64, error)
+	SumTradePnLFunc                    func(exchange string, symbol string, startTime time.Time, endTime time.Time) (float64, int, error)
+	SaveStrategyReturnFunc             func(r *storage.StrategyReturn) error
+	QueryStrategyReturnsFunc           func(startDate time.Time, endDate time.Time) ([]*storage.StrategyReturn, error)
+	SaveKlinesFunc                     func(klines []*storage.Kline) (int, error)
+	QueryKlinesFunc                    func(exchange string, symbol string, interval string, startTime time.Time, endTime time.Time, limit int) ([]*storage.Kline, error)
+	CloseFunc                          func() error
+}
+
+// NewMockStorage 创建以内存存储为底层实现的 MockStorage
+func NewMockStorage() *MockStorage {
+	return &MockStorage{Base: storage.NewMemoryStorage()}
+}
+
+// Calls 返回方法被调用的次数
+func (m *MockStorage) Calls(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[method]
+}
+
+func (m *MockStorage) record(method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.calls == nil {
+		m.calls = make(map[string]int)
+	}
+	m.calls[method]++
+}
+
+// SaveOrder 实现 storage.Storage
+func (m *MockStorage) SaveOrder(order *storage.Order) error {
+	m.record("SaveOrder")
+	if m.SaveOrderFunc != nil {
+		return m.SaveOrderFunc(order)
+	}
+	return m.Base.SaveOrder(order)
+}
+
+// SavePosition 实现 storage.Storage
+func (m *MockStorage) SavePosition(position *storage.Position) error {
+	m.record("SavePosition")
+	if m.SavePositionFunc != nil {
+		return m.SavePositionFunc(position)
+	}
+	return m.Base.SavePosition(position)
+}
+
+// SaveTrade 实现 storage.Storage
+func (m *MockStorage) SaveTrade(trade *storage.Trade) error {
+	m.record("SaveTrade")
+	if m.SaveTradeFunc != nil {
+		return m.SaveTradeFunc(trade)
+	}
+	return m.Base.SaveTrade(trade)
+}
+
+// SaveEvent 实现 storage.Storage
+func (m *MockStorage) SaveEvent(eventType string, data map[string]interface{}) error {
+	m.record("SaveEvent")
+	if m.SaveEventFunc != nil {
+		return m.SaveEventFunc(eventType, data)
+	}
+	return m.Base.SaveEvent(eventType, data)
+}
+
+// SaveStatistics 实现 storage.Storage
+func (m *MockStorage) SaveStatistics(stats *storage.Statistics) error {
+	m.record("SaveStatistics")
+	if m.SaveStatisticsFunc != nil {
+		return m.SaveStatisticsFunc(stats)
+	}
+	return m.Base.SaveStatistics(stats)
+}
+
+// SaveSystemMetrics 实现 storage.Storage
+func (m *MockStorage) SaveSystemMetrics(metrics *storage.SystemMetrics) error {
+	m.record("SaveSystemMetrics")
+	if m.SaveSystemMetricsFunc != nil {
+		return m.SaveSystemMetricsFunc(metrics)
+	}
+	return m.Base.SaveSystemMetrics(metrics)
+}
+
+// SaveDailySystemMetrics 实现 storage.Storage
+func (m *MockStorage) SaveDailySystemMetrics(metrics *storage.DailySystemMetrics) error {
+	m.record("SaveDailySystemMetrics")
+	if m.SaveDailySystemMetricsFunc != nil {
+		return m.SaveDailySystemMetricsFunc(metrics)
+	}
+	return m.Base.SaveDailySystemMetrics(metrics)
+}
+
+// QuerySystemMetrics 实现 storage.Storage
+func (m *MockStorage) QuerySystemMetrics(startTime time.Time, endTime time.Time) ([]*storage.SystemMetrics, error) {
+	m.record("QuerySystemMetrics")
+	if m.QuerySystemMetricsFunc != nil {
+		return m.QuerySystemMetricsFunc(startTime, endTime)
+	}
+	return m.Base.QuerySystemMetrics(startTime, endTime)
+}
+
+// QueryDailySystemMetrics 实现 storage.Storage
+func (m *MockStorage) QueryDailySystemMetrics(days int) ([]*storage.DailySystemMetrics, error) {
+	m.record("QueryDailySystemMetrics")
+	if m.QueryDailySystemMetricsFunc != nil {
+		return m.QueryDailySystemMetricsFunc(days)
+	}
+	return m.Base.QueryDailySystemMetrics(days)
+}
+
+// GetLatestSystemMetrics 实现 storage.Storage
+func (m *MockStorage) GetLatestSystemMetrics() (*storage.SystemMetrics, error) {
+	m.record("GetLatestSystemMetrics")
+	if m.GetLatestSystemMetricsFunc != nil {
+		return m.GetLatestSystemMetricsFunc()
+	}
+	return m.Base.GetLatestSystemMetrics()
+}
+
+// CleanupSystemMetrics 实现 storage.Storage
+func (m *MockStorage) CleanupSystemMetrics(beforeTime time.Time) error {
+	m.record("CleanupSystemMetrics")
+	if m.CleanupSystemMetricsFunc != nil {
+		return m.CleanupSystemMetricsFunc(beforeTime)
+	}
+	return m.Base.CleanupSystemMetrics(beforeTime)
+}
+
+// CleanupDailySystemMetrics 实现 storage.Storage
+func (m *MockStorage) CleanupDailySystemMetrics(beforeDate time.Time) error {
+	m.record("CleanupDailySystemMetrics")
+	if m.CleanupDailySystemMetricsFunc != nil {
+		return m.CleanupDailySystemMetricsFunc(beforeDate)
+	}
+	return m.Base.CleanupDailySystemMetrics(beforeDate)
+}
+
+// QueryOrders 实现 storage.Storage
+func (m *MockStorage) QueryOrders(exchange string, symbol string, limit int, offset int, status string) ([]*storage.Order, error) {
+	m.record("QueryOrders")
+	if m.QueryOrdersFunc != nil {
+		return m.QueryOrdersFunc(exchange, symbol, limit, offset, status)
+	}
+	return m.Base.QueryOrders(exchange, symbol, limit, offset, status)
+}
+
+// QueryTrades 实现 storage.Storage
+func (m *MockStorage) QueryTrades(exchange string, symbol string, startTime time.Time, endTime time.Time, limit int, offset int) ([]*storage.Trade, error) {
+	m.record("QueryTrades")
+	if m.QueryTradesFunc != nil {
+		return m.QueryTradesFunc(exchange, symbol, startTime, endTime, limit, offset)
+	}
+	return m.Base.QueryTrades(exchange, symbol, startTime, endTime, limit, offset)
+}
+
+// QueryStatistics 实现 storage.Storage
+func (m *MockStorage) QueryStatistics(exchange string, symbol string, startDate time.Time, endDate time.Time) ([]*storage.Statistics, error) {
+	m.record("QueryStatistics")
+	if m.QueryStatisticsFunc != nil {
+		return m.QueryStatisticsFunc(exchange, symbol, startDate, endDate)
+	}
+	return m.Base.QueryStatistics(exchange, symbol, startDate, endDate)
+}
+
+// GetStatisticsSummary 实现 storage.Storage
+func (m *MockStorage) GetStatisticsSummary() (*storage.Statistics, error) {
+	m.record("GetStatisticsSummary")
+	if m.GetStatisticsSummaryFunc != nil {
+		return m.GetStatisticsSummaryFunc()
+	}
+	return m.Base.GetStatisticsSummary()
+}
+
+// GetStatisticsSummaryByExchange 实现 storage.Storage
+func (m *MockStorage) GetStatisticsSummaryByExchange(exchange string) (*storage.Statistics, error) {
+	m.record("GetStatisticsSummaryByExchange")
+	if m.GetStatisticsSummaryByExchangeFunc != nil {
+		return m.GetStatisticsSummaryByExchangeFunc(exchange)
+	}
+	return m.Base.GetStatisticsSummaryByExchange(exchange)
+}
+
+// GetStatisticsSummaryBySymbol 实现 storage.Storage
+func (m *MockStorage) GetStatisticsSummaryBySymbol(exchange string, symbol string) (*storage.Statistics, error) {
+	m.record("GetStatisticsSummaryBySymbol")
+	if m.GetStatisticsSummaryBySymbolFunc != nil {
+		return m.GetStatisticsSummaryBySymbolFunc(exchange, symbol)
+	}
+	return m.Base.GetStatisticsSummaryBySymbol(exchange, symbol)
+}
+
+// QueryDailyStatisticsFromTrades 实现 storage.Storage
+func (m *MockStorage) QueryDailyStatisticsFromTrades(startDate time.Time, endDate time.Time) ([]*storage.DailyStatisticsWithTradeCount, error) {
+	m.record("QueryDailyStatisticsFromTrades")
+	if m.QueryDailyStatisticsFromTradesFunc != nil {
+		return m.QueryDailyStatisticsFromTradesFunc(startDate, endDate)
+	}
+	return m.Base.QueryDailyStatisticsFromTrades(startDate, endDate)
+}
+
+// QueryDailyStatisticsByExchange 实现 storage.Storage
+func (m *MockStorage) QueryDailyStatisticsByExchange(exchange string, startDate time.Time, endDate time.Time) ([]*storage.DailyStatisticsWithTradeCount, error) {
+	m.record("QueryDailyStatisticsByExchange")
+	if m.QueryDailyStatisticsByExchangeFunc != nil {
+		return m.QueryDailyStatisticsByExchangeFunc(exchange, startDate, endDate)
+	}
+	return m.Base.QueryDailyStatisticsByExchange(exchange, startDate, endDate)
+}
+
+// QueryDailyStatisticsBySymbol 实现 storage.Storage
+func (m *MockStorage) QueryDailyStatisticsBySymbol(exchange string, symbol string, startDate time.Time, endDate time.Time) ([]*storage.DailyStatisticsWithTradeCount, error) {
+	m.record("QueryDailyStatisticsBySymbol")
+	if m.QueryDailyStatisticsBySymbolFunc != nil {
+		return m.QueryDailyStatisticsBySymbolFunc(exchange, symbol, startDate, endDate)
+	}
+	return m.Base.QueryDailyStatisticsBySymbol(exchange, symbol, startDate, endDate)
+}
+
+// SaveReconciliationHistory 实现 storage.Storage
+func (m *MockStorage) SaveReconciliationHistory(history *storage.ReconciliationHistory) error {
+	m.record("SaveReconciliationHistory")
+	if m.SaveReconciliationHistoryFunc != nil {
+		return m.SaveReconciliationHistoryFunc(history)
+	}
+	return m.Base.SaveReconciliationHistory(history)
+}
+
+// QueryReconciliationHistory 实现 storage.Storage
+func (m *MockStorage) QueryReconciliationHistory(exchange string, symbol string, startTime time.Time, endTime time.Time, limit int, offset int) ([]*storage.ReconciliationHistory, error) {
+	m.record("QueryReconciliationHistory")
+	if m.QueryReconciliationHistoryFunc != nil {
+		return m.QueryReconciliationHistoryFunc(exchange, symbol, startTime, endTime, limit, offset)
+	}
+	return m.Base.QueryReconciliationHistory(exchange, symbol, startTime, endTime, limit, offset)
+}
+
+// GetLatestReconciliationHistory 实现 storage.Storage
+func (m *MockStorage) GetLatestReconciliationHistory(exchange string, symbol string) (*storage.ReconciliationHistory, error) {
+	m.record("GetLatestReconciliationHistory")
+	if m.GetLatestReconciliationHistoryFunc != nil {
+		return m.GetLatestReconciliationHistoryFunc(exchange, symbol)
+	}
+	return m.Base.GetLatestReconciliationHistory(exchange, symbol)
+}
+
+// GetReconciliationCount 实现 storage.Storage
+func (m *MockStorage) GetReconciliationCount(exchange string, symbol string) (int64, error) {
+	m.record("GetReconciliationCount")
+	if m.GetReconciliationCountFunc != nil {
+		return m.GetReconciliationCountFunc(exchange, symbol)
+	}
+	return m.Base.GetReconciliationCount(exchange, symbol)
+}
+
+// GetPnLBySymbol 实现 storage.Storage
+func (m *MockStorage) GetPnLBySymbol(exchange string, symbol string, startTime time.Time, endTime time.Time) (*storage.PnLSummary, error) {
+	m.record("GetPnLBySymbol")
+	if m.GetPnLBySymbolFunc != nil {
+		return m.GetPnLBySymbolFunc(exchange, symbol, startTime, endTime)
+	}
+	return m.Base.GetPnLBySymbol(exchange, symbol, startTime, endTime)
+}
+
+// GetPnLByTimeRange 实现 storage.Storage
+func (m *MockStorage) GetPnLByTimeRange(startTime time.Time, endTime time.Time) ([]*storage.PnLBySymbol, error) {
+	m.record("GetPnLByTimeRange")
+	if m.GetPnLByTimeRangeFunc != nil {
+		return m.GetPnLByTimeRangeFunc(startTime, endTime)
+	}
+	return m.Base.GetPnLByTimeRange(startTime, endTime)
+}
+
+// GetActualProfitBySymbol 实现 storage.Storage
+func (m *MockStorage) GetActualProfitBySymbol(exchange string, symbol string, beforeTime time.Time) (float64, error) {
+	m.record("GetActualProfitBySymbol")
+	if m.GetActualProfitBySymbolFunc != nil {
+		return m.GetActualProfitBySymbolFunc(exchange, symbol, beforeTime)
+	}
+	return m.Base.GetActualProfitBySymbol(exchange, symbol, beforeTime)
+}
+
+// SaveRiskCheck 实现 storage.Storage
+func (m *MockStorage) SaveRiskCheck(record *storage.RiskCheckRecord) error {
+	m.record("SaveRiskCheck")
+	if m.SaveRiskCheckFunc != nil {
+		return m.SaveRiskCheckFunc(record)
+	}
+	return m.Base.SaveRiskCheck(record)
+}
+
+// QueryRiskCheckHistory 实现 storage.Storage
+func (m *MockStorage) QueryRiskCheckHistory(startTime time.Time, endTime time.Time, limit int) ([]*storage.RiskCheckHistory, error) {
+	m.record("QueryRiskCheckHistory")
+	if m.QueryRiskCheckHistoryFunc != nil {
+		return m.QueryRiskCheckHistoryFunc(startTime, endTime, limit)
+	}
+	return m.Base.QueryRiskCheckHistory(startTime, endTime, limit)
+}
+
+// CleanupRiskCheckHistory 实现 storage.Storage
+func (m *MockStorage) CleanupRiskCheckHistory(beforeTime time.Time) error {
+	m.record("CleanupRiskCheckHistory")
+	if m.CleanupRiskCheckHistoryFunc != nil {
+		return m.CleanupRiskCheckHistoryFunc(beforeTime)
+	}
+	return m.Base.CleanupRiskCheckHistory(beforeTime)
+}
+
+// SaveFundingRate 实现 storage.Storage
+func (m *MockStorage) SaveFundingRate(symbol string, exchange string, rate float64, timestamp time.Time) error {
+	m.record("SaveFundingRate")
+	if m.SaveFundingRateFunc != nil {
+		return m.SaveFundingRateFunc(symbol, exchange, rate, timestamp)
+	}
+	return m.Base.SaveFundingRate(symbol, exchange, rate, timestamp)
+}
+
+// GetLatestFundingRate 实现 storage.Storage
+func (m *MockStorage) GetLatestFundingRate(symbol string, exchange string) (float64, error) {
+	m.record("GetLatestFundingRate")
+	if m.GetLatestFundingRateFunc != nil {
+		return m.GetLatestFundingRateFunc(symbol, exchange)
+	}
+	return m.Base.GetLatestFundingRate(symbol, exchange)
+}
+
+// GetFundingRateHistory 实现 storage.Storage
+func (m *MockStorage) GetFundingRateHistory(symbol string, exchange string, limit int) ([]*storage.FundingRate, error) {
+	m.record("GetFundingRateHistory")
+	if m.GetFundingRateHistoryFunc != nil {
+		return m.GetFundingRateHistoryFunc(symbol, exchange, limit)
+	}
+	return m.Base.GetFundingRateHistory(symbol, exchange, limit)
+}
+
+// GetAIPromptTemplate 实现 storage.Storage
+func (m *MockStorage) GetAIPromptTemplate(module string) (*storage.AIPromptTemplate, error) {
+	m.record("GetAIPromptTemplate")
+	if m.GetAIPromptTemplateFunc != nil {
+		return m.GetAIPromptTemplateFunc(module)
+	}
+	return m.Base.GetAIPromptTemplate(module)
+}
+
+// SetAIPromptTemplate 实现 storage.Storage
+func (m *MockStorage) SetAIPromptTemplate(template *storage.AIPromptTemplate) error {
+	m.record("SetAIPromptTemplate")
+	if m.SetAIPromptTemplateFunc != nil {
+		return m.SetAIPromptTemplateFunc(template)
+	}
+	return m.Base.SetAIPromptTemplate(template)
+}
+
+// GetAllAIPromptTemplates 实现 storage.Storage
+func (m *MockStorage) GetAllAIPromptTemplates() ([]*storage.AIPromptTemplate, error) {
+	m.record("GetAllAIPromptTemplates")
+	if m.GetAllAIPromptTemplatesFunc != nil {
+		return m.GetAllAIPromptTemplatesFunc()
+	}
+	return m.Base.GetAllAIPromptTemplates()
+}
+
+// SaveAIPromptVersion 实现 storage.Storage
+func (m *MockStorage) SaveAIPromptVersion(version *storage.AIPromptVersion) error {
+	m.record("SaveAIPromptVersion")
+	if m.SaveAIPromptVersionFunc != nil {
+		return m.SaveAIPromptVersionFunc(version)
+	}
+	return m.Base.SaveAIPromptVersion(version)
+}
+
+// GetAIPromptVersion 实现 storage.Storage
+func (m *MockStorage) GetAIPromptVersion(module string, version int) (*storage.AIPromptVersion, error) {
+	m.record("GetAIPromptVersion")
+	if m.GetAIPromptVersionFunc != nil {
+		return m.GetAIPromptVersionFunc(module, version)
+	}
+	return m.Base.GetAIPromptVersion(module, version)
+}
+
+// QueryAIPromptVersions 实现 storage.Storage
+func (m *MockStorage) QueryAIPromptVersions(module string, limit int) ([]*storage.AIPromptVersion, error) {
+	m.record("QueryAIPromptVersions")
+	if m.QueryAIPromptVersionsFunc != nil {
+		return m.QueryAIPromptVersionsFunc(module, limit)
+	}
+	return m.Base.QueryAIPromptVersions(module, limit)
+}
+
+// SetAIPromptPin 实现 storage.Storage
+func (m *MockStorage) SetAIPromptPin(module string, version int) error {
+	m.record("SetAIPromptPin")
+	if m.SetAIPromptPinFunc != nil {
+		return m.SetAIPromptPinFunc(module, version)
+	}
+	return m.Base.SetAIPromptPin(module, version)
+}
+
+// SaveBasisData 实现 storage.Storage
+func (m *MockStorage) SaveBasisData(data *storage.BasisData) error {
+	m.record("SaveBasisData")
+	if m.SaveBasisDataFunc != nil {
+		return m.SaveBasisDataFunc(data)
+	}
+	return m.Base.SaveBasisData(data)
+}
+
+// GetLatestBasis 实现 storage.Storage
+func (m *MockStorage) GetLatestBasis(symbol string, exchange string) (*storage.BasisData, error) {
+	m.record("GetLatestBasis")
+	if m.GetLatestBasisFunc != nil {
+		return m.GetLatestBasisFunc(symbol, exchange)
+	}
+	return m.Base.GetLatestBasis(symbol, exchange)
+}
+
+// GetBasisHistory 实现 storage.Storage
+func (m *MockStorage) GetBasisHistory(symbol string, exchange string, limit int) ([]*storage.BasisData, error) {
+	m.record("GetBasisHistory")
+	if m.GetBasisHistoryFunc != nil {
+		return m.GetBasisHistoryFunc(symbol, exchange, limit)
+	}
+	return m.Base.GetBasisHistory(symbol, exchange, limit)
+}
+
+// GetBasisStatistics 实现 storage.Storage
+func (m *MockStorage) GetBasisStatistics(symbol string, exchange string, hours int) (*storage.BasisStats, error) {
+	m.record("GetBasisStatistics")
+	if m.GetBasisStatisticsFunc != nil {
+		return m.GetBasisStatisticsFunc(symbol, exchange, hours)
+	}
+	return m.Base.GetBasisStatistics(symbol, exchange, hours)
+}
+
+// SaveCostBasis 实现 storage.Storage
+func (m *MockStorage) SaveCostBasis(costBasis *storage.CostBasis) error {
+	m.record("SaveCostBasis")
+	if m.SaveCostBasisFunc != nil {
+		return m.SaveCostBasisFunc(costBasis)
+	}
+	return m.Base.SaveCostBasis(costBasis)
+}
+
+// GetCostBasis 实现 storage.Storage
+func (m *MockStorage) GetCostBasis(exchange string, symbol string) (*storage.CostBasis, error) {
+	m.record("GetCostBasis")
+	if m.GetCostBasisFunc != nil {
+		return m.GetCostBasisFunc(exchange, symbol)
+	}
+	return m.Base.GetCostBasis(exchange, symbol)
+}
+
+// SaveGridAnchor 实现 storage.Storage
+func (m *MockStorage) SaveGridAnchor(anchor *storage.GridAnchor) error {
+	m.record("SaveGridAnchor")
+	if m.SaveGridAnchorFunc != nil {
+		return m.SaveGridAnchorFunc(anchor)
+	}
+	return m.Base.SaveGridAnchor(anchor)
+}
+
+// GetGridAnchor 实现 storage.Storage
+func (m *MockStorage) GetGridAnchor(exchange string, symbol string) (*storage.GridAnchor, error) {
+	m.record("GetGridAnchor")
+	if m.GetGridAnchorFunc != nil {
+		return m.GetGridAnchorFunc(exchange, symbol)
+	}
+	return m.Base.GetGridAnchor(exchange, symbol)
+}
+
+// SaveFundingPayments 实现 storage.Storage
+func (m *MockStorage) SaveFundingPayments(payments []*storage.FundingPayment) (int, error) {
+	m.record("SaveFundingPayments")
+	if m.SaveFundingPaymentsFunc != nil {
+		return m.SaveFundingPaymentsFunc(payments)
+	}
+	return m.Base.SaveFundingPayments(payments)
+}
+
+// GetLatestFundingPaymentTime 实现 storage.Storage
+func (m *MockStorage) GetLatestFundingPaymentTime(exchange string, symbol string) (time.Time, error) {
+	m.record("GetLatestFundingPaymentTime")
+	if m.GetLatestFundingPaymentTimeFunc != nil {
+		return m.GetLatestFundingPaymentTimeFunc(exchange, symbol)
+	}
+	return m.Base.GetLatestFundingPaymentTime(exchange, symbol)
+}
+
+// QueryFundingPayments 实现 storage.Storage
+func (m *MockStorage) QueryFundingPayments(exchange string, symbol string, startTime time.Time, endTime time.Time, limit int) ([]*storage.FundingPayment, error) {
+	m.record("QueryFundingPayments")
+	if m.QueryFundingPaymentsFunc != nil {
+		return m.QueryFundingPaymentsFunc(exchange, symbol, startTime, endTime, limit)
+	}
+	return m.Base.QueryFundingPayments(exchange, symbol, startTime, endTime, limit)
+}
+
+// SaveProfitTransfer 实现 storage.Storage
+func (m *MockStorage) SaveProfitTransfer(transfer *storage.ProfitTransfer) error {
+	m.record("SaveProfitTransfer")
+	if m.SaveProfitTransferFunc != nil {
+		return m.SaveProfitTransferFunc(transfer)
+	}
+	return m.Base.SaveProfitTransfer(transfer)
+}
+
+// QueryProfitTransfers 实现 storage.Storage
+func (m *MockStorage) QueryProfitTransfers(exchange string, startTime time.Time, endTime time.Time, limit int) ([]*storage.ProfitTransfer, error) {
+	m.record("QueryProfitTransfers")
+	if m.QueryProfitTransfersFunc != nil {
+		return m.QueryProfitTransfersFunc(exchange, startTime, endTime, limit)
+	}
+	return m.Base.QueryProfitTransfers(exchange, startTime, endTime, limit)
+}
+
+// GetProfitTransferTotal 实现 storage.Storage
+func (m *MockStorage) GetProfitTransferTotal(exchange string, startTime time.Time, endTime time.Time) (float64, error) {
+	m.record("GetProfitTransferTotal")
+	if m.GetProfitTransferTotalFunc != nil {
+		return m.GetProfitTransferTotalFunc(exchange, startTime, endTime)
+	}
+	return m.Base.GetProfitTransferTotal(exchange, startTime, endTime)
+}
+
+// SaveOpenInterest 实现 storage.Storage
+func (m *MockStorage) SaveOpenInterest(data *storage.OpenInterestData) error {
+	m.record("SaveOpenInterest")
+	if m.SaveOpenInterestFunc != nil {
+		return m.SaveOpenInterestFunc(data)
+	}
+	return m.Base.SaveOpenInterest(data)
+}
+
+// GetOpenInterestHistory 实现 storage.Storage
+func (m *MockStorage) GetOpenInterestHistory(symbol string, exchange string, startTime time.Time, endTime time.Time, limit int) ([]*storage.OpenInterestData, error) {
+	m.record("GetOpenInterestHistory")
+	if m.GetOpenInterestHistoryFunc != nil {
+		return m.GetOpenInterestHistoryFunc(symbol, exchange, startTime, endTime, limit)
+	}
+	return m.Base.GetOpenInterestHistory(symbol, exchange, startTime, endTime, limit)
+}
+
+// SaveJournalEntry 实现 storage.Storage
+func (m *MockStorage) SaveJournalEntry(entry *storage.JournalEntry) error {
+	m.record("SaveJournalEntry")
+	if m.SaveJournalEntryFunc != nil {
+		return m.SaveJournalEntryFunc(entry)
+	}
+	return m.Base.SaveJournalEntry(entry)
+}
+
+// QueryJournalEntries 实现 storage.Storage
+func (m *MockStorage) QueryJournalEntries(q storage.JournalQuery) ([]*storage.JournalEntry, error) {
+	m.record("QueryJournalEntries")
+	if m.QueryJournalEntriesFunc != nil {
+		return m.QueryJournalEntriesFunc(q)
+	}
+	return m.Base.QueryJournalEntries(q)
+}
+
+// SaveRejectedOrder 实现 storage.Storage
+func (m *MockStorage) SaveRejectedOrder(order *storage.RejectedOrder) error {
+	m.record("SaveRejectedOrder")
+	if m.SaveRejectedOrderFunc != nil {
+		return m.SaveRejectedOrderFunc(order)
+	}
+	return m.Base.SaveRejectedOrder(order)
+}
+
+// QueryRejectedOrders 实现 storage.Storage
+func (m *MockStorage) QueryRejectedOrders(q storage.RejectedOrderQuery) ([]*storage.RejectedOrder, error) {
+	m.record("QueryRejectedOrders")
+	if m.QueryRejectedOrdersFunc != nil {
+		return m.QueryRejectedOrdersFunc(q)
+	}
+	return m.Base.QueryRejectedOrders(q)
+}
+
+// SaveOrderCleanupActions 实现 storage.Storage
+func (m *MockStorage) SaveOrderCleanupActions(actions []*storage.OrderCleanupAction) error {
+	m.record("SaveOrderCleanupActions")
+	if m.SaveOrderCleanupActionsFunc != nil {
+		return m.SaveOrderCleanupActionsFunc(actions)
+	}
+	return m.Base.SaveOrderCleanupActions(actions)
+}
+
+// QueryOrderCleanupActions 实现 storage.Storage
+func (m *MockStorage) QueryOrderCleanupActions(q storage.OrderCleanupQuery) ([]*storage.OrderCleanupAction, error) {
+	m.record("QueryOrderCleanupActions")
+	if m.QueryOrderCleanupActionsFunc != nil {
+		return m.QueryOrderCleanupActionsFunc(q)
+	}
+	return m.Base.QueryOrderCleanupActions(q)
+}
+
+// SaveOCOLink 实现 storage.Storage
+func (m *MockStorage) SaveOCOLink(link *storage.OCOLink) error {
+	m.record("SaveOCOLink")
+	if m.SaveOCOLinkFunc != nil {
+		return m.SaveOCOLinkFunc(link)
+	}
+	return m.Base.SaveOCOLink(link)
+}
+
+// QueryActiveOCOLinks 实现 storage.Storage
+func (m *MockStorage) QueryActiveOCOLinks(exchange string, symbol string) ([]*storage.OCOLink, error) {
+	m.record("QueryActiveOCOLinks")
+	if m.QueryActiveOCOLinksFunc != nil {
+		return m.QueryActiveOCOLinksFunc(exchange, symbol)
+	}
+	return m.Base.QueryActiveOCOLinks(exchange, symbol)
+}
+
+// SaveIncomeRecords 实现 storage.Storage
+func (m *MockStorage) SaveIncomeRecords(records []*storage.IncomeRecord) (int, error) {
+	m.record("SaveIncomeRecords")
+	if m.SaveIncomeRecordsFunc != nil {
+		return m.SaveIncomeRecordsFunc(records)
+	}
+	return m.Base.SaveIncomeRecords(records)
+}
+
+// GetLatestIncomeTime 实现 storage.Storage
+func (m *MockStorage) GetLatestIncomeTime(exchange string, symbol string, incomeType string) (time.Time, error) {
+	m.record("GetLatestIncomeTime")
+	if m.GetLatestIncomeTimeFunc != nil {
+		return m.GetLatestIncomeTimeFunc(exchange, symbol, incomeType)
+	}
+	return m.Base.GetLatestIncomeTime(exchange, symbol, incomeType)
+}
+
+// SumIncomeByType 实现 storage.Storage
+func (m *MockStorage) SumIncomeByType(exchange string, symbol string, startTime time.Time, endTime time.Time) (map[string]float64, error) {
+	m.record("SumIncomeByType")
+	if m.SumIncomeByTypeFunc != nil {
+		return m.SumIncomeByTypeFunc(exchange, symbol, startTime, endTime)
+	}
+	return m.Base.SumIncomeByType(exchange, symbol, startTime, endTime)
+}
+
+// SumTradePnL 实现 storage.Storage
+func (m *MockStorage) SumTradePnL(exchange string, symbol string, startTime time.Time, endTime time.Time) (float64, int, error) {
+	m.record("SumTradePnL")
+	if m.SumTradePnLFunc != nil {
+		return m.SumTradePnLFunc(exchange, symbol, startTime, endTime)
+	}
+	return m.Base.SumTradePnL(exchange, symbol, startTime, endTime)
+}
+
+// SaveStrategyReturn 实现 storage.Storage
+func (m *MockStorage) SaveStrategyReturn(r *storage.StrategyReturn) error {
+	m.record("SaveStrategyReturn")
+	if m.SaveStrategyReturnFunc != nil {
+		return m.SaveStrategyReturnFunc(r)
+	}
+	return m.Base.SaveStrategyReturn(r)
+}
+
+// QueryStrategyReturns 实现 storage.Storage
+func (m *MockStorage) QueryStrategyReturns(startDate time.Time, endDate time.Time) ([]*storage.StrategyReturn, error) {
+	m.record("QueryStrategyReturns")
+	if m.QueryStrategyReturnsFunc != nil {
+		return m.QueryStrategyReturnsFunc(startDate, endDate)
+	}
+	return m.Base.QueryStrategyReturns(startDate, endDate)
+}
+
+// SaveKlines 实现 storage.Storage
+func (m *MockStorage) SaveKlines(klines []*storage.Kline) (int, error) {
+	m.record("SaveKlines")
+	if m.SaveKlinesFunc != nil {
+		return m.SaveKlinesFunc(klines)
+	}
+	return m.Base.SaveKlines(klines)
+}
+
+// QueryKlines 实现 storage.Storage
+func (m *MockStorage) QueryKlines(exchange string, symbol string, interval string, startTime time.Time, endTime time.Time, limit int) ([]*storage.Kline, error) {
+	m.record("QueryKlines")
+	if m.QueryKlinesFunc != nil {
+		return m.QueryKlinesFunc(exchange, symbol, interval, startTime, endTime, limit)
+	}
+	return m.Base.QueryKlines(exchange, symbol, interval, startTime, endTime, limit)
+}
+
+// Close 实现 storage.Storage
+func (m *MockStorage) Close() error {
+	m.record("Close")
+	if m.CloseFunc != nil {
+		return m.CloseFunc()
+	}
+	return m.Base.Close()
+}
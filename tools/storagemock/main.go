@@ -0,0 +1,213 @@
+// storagemock 根据 storage.Storage 接口生成 testutil.MockStorage
+//
+// 用法（在 storage 目录下执行 go generate）:
+//
+//	go run ../tools/storagemock -src storage.go -out ../testutil/mock_storage.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"strings"
+	"unicode"
+)
+
+func main() {
+	src := flag.String("src", "storage.go", "声明 Storage 接口的源文件")
+	out := flag.String("out", "../testutil/mock_storage.go", "生成文件路径")
+	flag.Parse()
+
+	code, err := generate(*src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "生成 MockStorage 失败: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, code, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "写入 %s 失败: %v\n", *out, err)
+		os.Exit(1)
+	}
+}
+
+// method 接口方法的签名信息
+type method struct {
+	name    string
+	params  []string // "name type"
+	args    []string // 调用时的参数（变参带 ...）
+	results []string
+}
+
+func generate(src string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, src, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var iface *ast.InterfaceType
+	ast.Inspect(file, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok && ts.Name.Name == "Storage" {
+			iface, _ = ts.Type.(*ast.InterfaceType)
+			return false
+		}
+		return true
+	})
+	if iface == nil {
+		return nil, fmt.Errorf("%s 中未找到 Storage 接口", src)
+	}
+
+	var methods []method
+	for _, field := range iface.Methods.List {
+		fn, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			return nil, fmt.Errorf("Storage 接口不支持嵌入接口")
+		}
+		m := method{name: field.Names[0].Name}
+		argIndex := 0
+		for _, p := range fn.Params.List {
+			typ := typeString(fset, p.Type)
+			names := p.Names
+			if len(names) == 0 {
+				names = []*ast.Ident{ast.NewIdent(fmt.Sprintf("arg%d", argIndex))}
+			}
+			for _, name := range names {
+				argIndex++
+				m.params = append(m.params, name.Name+" "+typ)
+				if _, variadic := p.Type.(*ast.Ellipsis); variadic {
+					m.args = append(m.args, name.Name+"...")
+				} else {
+					m.args = append(m.args, name.Name)
+				}
+			}
+		}
+		if fn.Results != nil {
+			for _, r := range fn.Results.List {
+				typ := typeString(fset, r.Type)
+				for range max(1, len(r.Names)) {
+					m.results = append(m.results, typ)
+				}
+			}
+		}
+		methods = append(methods, m)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`// Code generated by tools/storagemock; DO NOT EDIT.
+
+package testutil
+
+import (
+	"sync"
+	"time"
+
+	"quantmesh/storage"
+)
+
+var _ storage.Storage = (*MockStorage)(nil)
+
+// MockStorage 可编程的 storage.Storage mock
+// 设置了 XxxFunc 的方法调用该函数，否则委托给 Base（默认为内存存储）；所有调用都会记录次数
+type MockStorage struct {
+	Base storage.Storage
+
+	mu    sync.Mutex
+	calls map[string]int
+
+`)
+	for _, m := range methods {
+		fmt.Fprintf(&buf, "\t%sFunc func(%s)%s\n", m.name, strings.Join(m.params, ", "), resultList(m.results))
+	}
+	buf.WriteString(`}
+
+// NewMockStorage 创建以内存存储为底层实现的 MockStorage
+func NewMockStorage() *MockStorage {
+	return &MockStorage{Base: storage.NewMemoryStorage()}
+}
+
+// Calls 返回方法被调用的次数
+func (m *MockStorage) Calls(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[method]
+}
+
+func (m *MockStorage) record(method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.calls == nil {
+		m.calls = make(map[string]int)
+	}
+	m.calls[method]++
+}
+`)
+	for _, m := range methods {
+		call := fmt.Sprintf("(%s)", strings.Join(m.args, ", "))
+		override, fallback := "return m."+m.name+"Func"+call, "return m.Base."+m.name+call
+		if len(m.results) == 0 {
+			override, fallback = "m."+m.name+"Func"+call+"\n\t\treturn", "m.Base."+m.name+call
+		}
+		fmt.Fprintf(&buf, `
+// %[1]s 实现 storage.Storage
+func (m *MockStorage) %[1]s(%[2]s)%[3]s {
+	m.record(%[1]q)
+	if m.%[1]sFunc != nil {
+		%[4]s
+	}
+	%[5]s
+}
+`, m.name, strings.Join(m.params, ", "), resultList(m.results), override, fallback)
+	}
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("格式化生成代码失败: %w", err)
+	}
+	return formatted, nil
+}
+
+// resultList 生成返回值列表
+func resultList(results []string) string {
+	switch len(results) {
+	case 0:
+		return ""
+	case 1:
+		return " " + results[0]
+	default:
+		return " (" + strings.Join(results, ", ") + ")"
+	}
+}
+
+// typeString 输出类型表达式，storage 包内声明的导出类型加上 storage. 前缀
+func typeString(fset *token.FileSet, expr ast.Expr) string {
+	qualified := qualify(expr)
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, qualified)
+	return buf.String()
+}
+
+// qualify 复制类型表达式，把未限定的导出标识符替换为 storage.X
+func qualify(expr ast.Expr) ast.Expr {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if unicode.IsUpper(rune(t.Name[0])) {
+			return &ast.SelectorExpr{X: ast.NewIdent("storage"), Sel: ast.NewIdent(t.Name)}
+		}
+		return t
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: qualify(t.X)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: t.Len, Elt: qualify(t.Elt)}
+	case *ast.MapType:
+		return &ast.MapType{Key: qualify(t.Key), Value: qualify(t.Value)}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: qualify(t.Elt)}
+	default:
+		// 已限定的类型（如 time.Time）和其他表达式原样输出
+		return expr
+	}
+}
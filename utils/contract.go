@@ -0,0 +1,85 @@
+package utils
+
+import "strings"
+
+// 合约类型
+const (
+	ContractLinear  = "linear"  // U 本位：数量以币计，价值与盈亏以计价货币（USDT/USDC）结算
+	ContractInverse = "inverse" // 币本位（COIN-M）：数量为合约张数，每张面值固定（以计价货币计），盈亏以基础币结算
+)
+
+// ContractSpec 合约规格，统一 U 本位与币本位合约的数量、价值和盈亏换算
+// 零值表示 U 本位合约，与原有计算保持一致
+type ContractSpec struct {
+	Inverse      bool
+	ContractSize float64 // 币本位合约每张面值（计价货币），如币安 BTCUSD 为 100 USD、其余多为 10 USD
+}
+
+// NewContractSpec 按配置创建合约规格，未知类型按 U 本位处理
+func NewContractSpec(contractType string, contractSize float64) ContractSpec {
+	if strings.EqualFold(contractType, ContractInverse) && contractSize > 0 {
+		return ContractSpec{Inverse: true, ContractSize: contractSize}
+	}
+	return ContractSpec{}
+}
+
+// Type 合约类型名称
+func (s ContractSpec) Type() string {
+	if s.Inverse {
+		return ContractInverse
+	}
+	return ContractLinear
+}
+
+// QuantityForNotional 按名义价值（计价货币）计算下单数量：U 本位为币数量，币本位为合约张数（未取整）
+func (s ContractSpec) QuantityForNotional(notional, price float64) float64 {
+	if s.Inverse {
+		return notional / s.ContractSize
+	}
+	if price <= 0 {
+		return 0
+	}
+	return notional / price
+}
+
+// Notional 持仓/订单的名义价值（计价货币）
+func (s ContractSpec) Notional(quantity, price float64) float64 {
+	if s.Inverse {
+		return quantity * s.ContractSize
+	}
+	return quantity * price
+}
+
+// SettlementValue 持仓/订单价值（结算资产）：U 本位为计价货币，币本位为基础币
+func (s ContractSpec) SettlementValue(quantity, price float64) float64 {
+	if s.Inverse {
+		if price <= 0 {
+			return 0
+		}
+		return quantity * s.ContractSize / price
+	}
+	return quantity * price
+}
+
+// NotionalToSettlement 将名义价值（计价货币）换算为结算资产
+func (s ContractSpec) NotionalToSettlement(notional, price float64) float64 {
+	if s.Inverse {
+		if price <= 0 {
+			return 0
+		}
+		return notional / price
+	}
+	return notional
+}
+
+// PnL 多头持仓从 entryPrice 到 exitPrice 的盈亏（结算资产）
+// 币本位：张数 × 面值 × (1/开仓价 - 1/平仓价)
+func (s ContractSpec) PnL(entryPrice, exitPrice, quantity float64) float64 {
+	if s.Inverse {
+		if entryPrice <= 0 || exitPrice <= 0 {
+			return 0
+		}
+		return quantity * s.ContractSize * (1/entryPrice - 1/exitPrice)
+	}
+	return (exitPrice - entryPrice) * quantity
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestContractSpecLinear(t *testing.T) {
+	spec := NewContractSpec("", 0)
+	if spec.Inverse || spec.Type() != ContractLinear {
+		t.Fatalf("默认应为 U 本位: %+v", spec)
+	}
+	if q := spec.QuantityForNotional(100, 50000); math.Abs(q-0.002) > 1e-12 {
+		t.Errorf("数量错误: %v", q)
+	}
+	if v := spec.SettlementValue(0.002, 50000); v != 100 {
+		t.Errorf("价值错误: %v", v)
+	}
+	if pnl := spec.PnL(50000, 50100, 0.1); math.Abs(pnl-10) > 1e-9 {
+		t.Errorf("盈亏错误: %v", pnl)
+	}
+}
+
+func TestContractSpecInverse(t *testing.T) {
+	spec := NewContractSpec("INVERSE", 100)
+	if !spec.Inverse || spec.Type() != ContractInverse {
+		t.Fatalf("应为币本位: %+v", spec)
+	}
+
+	// 每单 1000 USD、每张 100 USD -> 10 张，与价格无关
+	if q := spec.QuantityForNotional(1000, 50000); q != 10 {
+		t.Errorf("张数错误: %v", q)
+	}
+	if n := spec.Notional(10, 50000); n != 1000 {
+		t.Errorf("名义价值错误: %v", n)
+	}
+	if v := spec.SettlementValue(10, 50000); math.Abs(v-0.02) > 1e-12 {
+		t.Errorf("结算价值错误: %v BTC", v)
+	}
+	if v := spec.NotionalToSettlement(1000, 50000); math.Abs(v-0.02) > 1e-12 {
+		t.Errorf("名义价值换算错误: %v BTC", v)
+	}
+
+	// 10 张从 50000 涨到 50500：1000 × (1/50000 - 1/50500) ≈ 0.000198 BTC
+	pnl := spec.PnL(50000, 50500, 10)
+	if math.Abs(pnl-1000*(1.0/50000-1.0/50500)) > 1e-15 || pnl <= 0 {
+		t.Errorf("盈亏错误: %v", pnl)
+	}
+	if spec.PnL(50500, 50000, 10) >= 0 {
+		t.Error("价格下跌多头应亏损")
+	}
+
+	// 未配置面值时退回 U 本位，避免除以零
+	if NewContractSpec("inverse", 0).Inverse {
+		t.Error("面值为 0 不应视为币本位")
+	}
+}
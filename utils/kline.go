@@ -0,0 +1,64 @@
+package utils
+
+import "time"
+
+// klineIntervals 支持缺口检测的 K 线周期（按 UTC 对齐的固定时长，不含 1w/1M）
+var klineIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"6h":  6 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+// KlineIntervalDuration K 线周期对应的时长，不支持的周期返回 false
+func KlineIntervalDuration(interval string) (time.Duration, bool) {
+	d, ok := klineIntervals[interval]
+	return d, ok
+}
+
+// KlineGap 连续缺失的 K 线区间，Start/End 为首尾两根缺失 K 线的开盘时间
+type KlineGap struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Missing int       `json:"missing"` // 缺失的 K 线根数
+}
+
+// FindKlineGaps 检查 [start, end) 内按周期对齐的每个开盘时间，返回缺失的连续区间（按时间升序）
+// openTimes 为已存储 K 线的开盘时间，顺序不限，不在区间内或未对齐的时间忽略
+func FindKlineGaps(openTimes []time.Time, interval time.Duration, start, end time.Time) []KlineGap {
+	if interval <= 0 {
+		return nil
+	}
+	stored := make(map[int64]bool, len(openTimes))
+	for _, t := range openTimes {
+		stored[t.UnixMilli()] = true
+	}
+
+	first := start.Truncate(interval)
+	if first.Before(start) {
+		first = first.Add(interval)
+	}
+	var gaps []KlineGap
+	var current *KlineGap
+	for t := first; t.Before(end); t = t.Add(interval) {
+		if stored[t.UnixMilli()] {
+			current = nil
+			continue
+		}
+		if current == nil {
+			gaps = append(gaps, KlineGap{Start: t})
+			current = &gaps[len(gaps)-1]
+		}
+		current.End = t
+		current.Missing++
+	}
+	return gaps
+}
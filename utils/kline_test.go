@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindKlineGaps(t *testing.T) {
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	var stored []time.Time
+	for _, m := range []int{0, 1, 2, 5, 6, 9} {
+		stored = append(stored, base.Add(time.Duration(m)*time.Minute))
+	}
+	stored = append(stored, base.Add(90*time.Second)) // 未对齐的时间忽略
+
+	// 起点未对齐时从下一根开始检查，终点不含
+	gaps := FindKlineGaps(stored, time.Minute, base.Add(-30*time.Second), base.Add(11*time.Minute))
+	want := []KlineGap{
+		{Start: base.Add(3 * time.Minute), End: base.Add(4 * time.Minute), Missing: 2},
+		{Start: base.Add(7 * time.Minute), End: base.Add(8 * time.Minute), Missing: 2},
+		{Start: base.Add(10 * time.Minute), End: base.Add(10 * time.Minute), Missing: 1},
+	}
+	if len(gaps) != len(want) {
+		t.Fatalf("缺口数量错误: %+v", gaps)
+	}
+	for i := range want {
+		if !gaps[i].Start.Equal(want[i].Start) || !gaps[i].End.Equal(want[i].End) || gaps[i].Missing != want[i].Missing {
+			t.Errorf("缺口 %d = %+v, want %+v", i, gaps[i], want[i])
+		}
+	}
+
+	if gaps := FindKlineGaps(stored, time.Minute, base, base.Add(3*time.Minute)); len(gaps) != 0 {
+		t.Errorf("连续区间不应有缺口: %+v", gaps)
+	}
+	if gaps := FindKlineGaps(nil, time.Hour, base, base.Add(24*time.Hour)); len(gaps) != 1 || gaps[0].Missing != 24 {
+		t.Errorf("没有数据时整个区间为一个缺口: %+v", gaps)
+	}
+}
+
+func TestKlineIntervalDuration(t *testing.T) {
+	if d, ok := KlineIntervalDuration("4h"); !ok || d != 4*time.Hour {
+		t.Errorf("4h = %v, %v", d, ok)
+	}
+	for _, interval := range []string{"1w", "1M", "", "7m"} {
+		if _, ok := KlineIntervalDuration(interval); ok {
+			t.Errorf("%q 不应支持缺口检测", interval)
+		}
+	}
+}
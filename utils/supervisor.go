@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"quantmesh/logger"
+)
+
+const (
+	// defaultSupervisorInitialBackoff 首次重启前的等待时间
+	defaultSupervisorInitialBackoff = 1 * time.Second
+	// defaultSupervisorMaxBackoff 重启等待时间上限
+	defaultSupervisorMaxBackoff = 1 * time.Minute
+	// supervisorStableRunTime 协程稳定运行超过该时长后重置退避时间
+	supervisorStableRunTime = 5 * time.Minute
+	// supervisorCrashLoopThreshold 连续崩溃（中间未稳定运行）达到该次数视为守护失效
+	supervisorCrashLoopThreshold = 5
+)
+
+// PanicHandler 协程 panic 回调（用于发布事件、记录指标等）
+type PanicHandler func(name string, recovered interface{}, stack string)
+
+// FailureHandler 守护失效回调：协程陷入崩溃循环（stopped=false）或达到最大重启次数停止重启（stopped=true）
+type FailureHandler func(name string, restarts int, stopped bool)
+
+var (
+	panicHandlerMu sync.RWMutex
+	panicHandler   PanicHandler
+	failureHandler FailureHandler
+)
+
+// SetPanicHandler 设置全局 panic 回调
+// 由 main 在事件总线初始化后注入，避免 utils 依赖 event 包
+func SetPanicHandler(handler PanicHandler) {
+	panicHandlerMu.Lock()
+	defer panicHandlerMu.Unlock()
+	panicHandler = handler
+}
+
+// SetFailureHandler 设置全局守护失效回调（用于关键告警升级）
+func SetFailureHandler(handler FailureHandler) {
+	panicHandlerMu.Lock()
+	defer panicHandlerMu.Unlock()
+	failureHandler = handler
+}
+
+func notifyFailure(name string, restarts int, stopped bool) {
+	panicHandlerMu.RLock()
+	handler := failureHandler
+	panicHandlerMu.RUnlock()
+	if handler != nil {
+		handler(name, restarts, stopped)
+	}
+}
+
+func getPanicHandler() PanicHandler {
+	panicHandlerMu.RLock()
+	defer panicHandlerMu.RUnlock()
+	return panicHandler
+}
+
+// SuperviseOptions 协程守护选项
+type SuperviseOptions struct {
+	InitialBackoff time.Duration // 首次重启等待时间（默认 1 秒）
+	MaxBackoff     time.Duration // 最大重启等待时间（默认 1 分钟）
+	MaxRestarts    int           // 最大重启次数（0 表示不限制）
+}
+
+// GoSupervised 以守护模式启动长期运行的协程
+// fn 正常返回时守护结束；fn panic 时记录堆栈、触发 panic 回调，并按指数退避重启，
+// 直到 ctx 被取消或达到最大重启次数
+func GoSupervised(ctx context.Context, name string, fn func(ctx context.Context)) {
+	GoSupervisedWithOptions(ctx, name, SuperviseOptions{}, fn)
+}
+
+// GoSupervisedWithOptions 以自定义选项启动守护协程
+func GoSupervisedWithOptions(ctx context.Context, name string, opts SuperviseOptions, fn func(ctx context.Context)) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	go Supervise(ctx, name, opts, fn)
+}
+
+// Supervise 在当前协程中守护运行 fn（阻塞直到 fn 正常返回或守护结束）
+func Supervise(ctx context.Context, name string, opts SuperviseOptions, fn func(ctx context.Context)) {
+	initialBackoff := opts.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = defaultSupervisorInitialBackoff
+	}
+	maxBackoff := opts.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultSupervisorMaxBackoff
+	}
+	if maxBackoff < initialBackoff {
+		maxBackoff = initialBackoff
+	}
+
+	backoff := initialBackoff
+	restarts := 0
+	consecutive := 0 // 未经稳定运行的连续崩溃次数
+	for {
+		startedAt := time.Now()
+		if !runRecovered(ctx, name, fn) {
+			// 正常返回，不再重启
+			return
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		if opts.MaxRestarts > 0 && restarts >= opts.MaxRestarts {
+			logger.Error("❌ [守护协程:%s] 已达到最大重启次数 %d，停止重启", name, opts.MaxRestarts)
+			notifyFailure(name, restarts, true)
+			return
+		}
+
+		// 稳定运行一段时间后再次崩溃，从初始退避重新计算
+		if time.Since(startedAt) >= supervisorStableRunTime {
+			backoff = initialBackoff
+			consecutive = 0
+		}
+		consecutive++
+		if consecutive == supervisorCrashLoopThreshold {
+			logger.Error("❌ [守护协程:%s] 连续崩溃 %d 次，可能已无法正常工作", name, consecutive)
+			notifyFailure(name, restarts, false)
+		}
+
+		restarts++
+		logger.Warn("🔄 [守护协程:%s] 将在 %v 后重启 (第 %d 次)", name, backoff, restarts)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// runRecovered 执行 fn 并捕获 panic，返回是否发生 panic
+func runRecovered(ctx context.Context, name string, fn func(ctx context.Context)) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			stack := string(debug.Stack())
+			logger.Error("❌ [守护协程:%s] panic: %v\n%s", name, r, stack)
+			if handler := getPanicHandler(); handler != nil {
+				handler(name, r, stack)
+			}
+		}
+	}()
+	fn(ctx)
+	return false
+}
+
+// RecoverPanic 捕获短生命周期协程的 panic（不重启），需通过 defer 调用
+// 用法: defer utils.RecoverPanic("event-worker")
+func RecoverPanic(name string) {
+	if r := recover(); r != nil {
+		stack := string(debug.Stack())
+		logger.Error("❌ [协程:%s] panic: %v\n%s", name, r, stack)
+		if handler := getPanicHandler(); handler != nil {
+			handler(name, r, stack)
+		}
+	}
+}
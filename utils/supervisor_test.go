@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSuperviseRestartsAfterPanic(t *testing.T) {
+	var runs int32
+	var panics int32
+	SetPanicHandler(func(name string, recovered interface{}, stack string) {
+		atomic.AddInt32(&panics, 1)
+		if name != "test-loop" {
+			t.Errorf("协程名称错误: %s", name)
+		}
+	})
+	defer SetPanicHandler(nil)
+
+	opts := SuperviseOptions{InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	Supervise(context.Background(), "test-loop", opts, func(ctx context.Context) {
+		if atomic.AddInt32(&runs, 1) < 3 {
+			panic("boom")
+		}
+	})
+
+	if runs != 3 {
+		t.Errorf("期望运行 3 次（2 次 panic 后正常返回），实际 %d", runs)
+	}
+	if panics != 2 {
+		t.Errorf("期望 panic 回调 2 次，实际 %d", panics)
+	}
+}
+
+func TestSuperviseMaxRestarts(t *testing.T) {
+	var runs int32
+	opts := SuperviseOptions{InitialBackoff: time.Millisecond, MaxRestarts: 2}
+	Supervise(context.Background(), "always-panic", opts, func(ctx context.Context) {
+		atomic.AddInt32(&runs, 1)
+		panic("boom")
+	})
+
+	if runs != 3 {
+		t.Errorf("期望首次运行 + 2 次重启，实际运行 %d 次", runs)
+	}
+}
+
+func TestSuperviseStopsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		Supervise(ctx, "cancelled", SuperviseOptions{InitialBackoff: time.Hour}, func(ctx context.Context) {
+			panic("boom")
+		})
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("context 取消后守护协程未退出")
+	}
+}
+
+func TestSuperviseFailureHandler(t *testing.T) {
+	var crashLoops, stops int32
+	SetFailureHandler(func(name string, restarts int, stopped bool) {
+		if stopped {
+			atomic.AddInt32(&stops, 1)
+		} else {
+			atomic.AddInt32(&crashLoops, 1)
+		}
+	})
+	defer SetFailureHandler(nil)
+
+	opts := SuperviseOptions{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRestarts: 6}
+	Supervise(context.Background(), "crash-loop", opts, func(ctx context.Context) {
+		panic("boom")
+	})
+
+	if crashLoops != 1 || stops != 1 {
+		t.Errorf("期望崩溃循环回调 1 次、停止回调 1 次，实际 %d / %d", crashLoops, stops)
+	}
+}
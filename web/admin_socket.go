@@ -0,0 +1,73 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 本地管理 socket：与 HTTP 服务共用同一套路由，只监听 Unix domain socket
+// 能连接 socket 即视为管理员（访问控制交给文件权限 0600），请求不经过会话认证和 CSRF 校验
+
+// localAdminUsername 通过管理 socket 发起的请求在审计日志中记录的用户名
+const localAdminUsername = "local-admin"
+
+type localAdminKey struct{}
+
+// isLocalAdmin 请求是否来自本地管理 socket
+func isLocalAdmin(r *http.Request) bool {
+	local, _ := r.Context().Value(localAdminKey{}).(bool)
+	return local
+}
+
+// localAdminHandler 标记经由管理 socket 到达的请求
+func localAdminHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), localAdminKey{}, true)))
+	})
+}
+
+// listenAdminSocket 监听管理 socket，清理上次异常退出留下的 socket 文件
+// socket 仍可连接说明有其他实例在使用，此时返回错误而不是抢占
+// socket 所在目录在监听前收紧为 0700：Listen 按 umask 创建的 socket 在 Chmod 之前其他用户也无法访问
+func listenAdminSocket(path string) (net.Listener, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("创建管理 socket 目录失败: %w", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取管理 socket 目录失败: %w", err)
+	}
+	// /tmp 这类共享目录不能收紧权限
+	if info.Mode()&os.ModeSticky != 0 {
+		return nil, fmt.Errorf("管理 socket 目录 %s 是共享目录，请使用专用目录", dir)
+	}
+	// 目录已存在时 MkdirAll 不会修改权限
+	if err := os.Chmod(dir, 0700); err != nil {
+		return nil, fmt.Errorf("设置管理 socket 目录权限失败: %w", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("管理 socket %s 正在被其他实例使用", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("清理旧的管理 socket 失败: %w", err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("设置管理 socket 权限失败: %w", err)
+	}
+	return ln, nil
+}
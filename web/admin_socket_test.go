@@ -0,0 +1,94 @@
+package web
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminSocketBypassesSessionAndCSRF(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(csrfMiddleware(true))
+	r.POST("/api/trading/stop", authMiddleware(), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("username"))
+	})
+
+	path := filepath.Join(t.TempDir(), "admin.sock")
+	ln, err := listenAdminSocket(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: localAdminHandler(r)}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("socket permissions: %v (%v)", info.Mode(), err)
+	}
+	if _, err := listenAdminSocket(path); err == nil {
+		t.Fatal("second listener on a live socket should fail")
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Post("http://localhost/api/trading/stop", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != localAdminUsername {
+		t.Fatalf("socket request: %d %s", resp.StatusCode, body)
+	}
+
+	// 同一路由经 HTTP 访问仍需要 CSRF 令牌和会话
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/trading/stop", nil))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("HTTP request without CSRF token: got %d, want 403", w.Code)
+	}
+}
+
+func TestAdminSocketDirectoryPermissions(t *testing.T) {
+	// 已存在的宽松权限目录在监听前收紧
+	dir := filepath.Join(t.TempDir(), "run")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := listenAdminSocket(filepath.Join(dir, "admin.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.Close()
+	if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0700 {
+		t.Fatalf("socket directory permissions: %v (%v)", info.Mode(), err)
+	}
+
+	// 共享目录（sticky bit）拒绝使用，且不修改其权限
+	shared := filepath.Join(t.TempDir(), "shared")
+	if err := os.Mkdir(shared, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(shared, 0777|os.ModeSticky); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listenAdminSocket(filepath.Join(shared, "admin.sock")); err == nil {
+		t.Fatal("socket in a shared directory should be rejected")
+	}
+	if info, err := os.Stat(shared); err != nil || info.Mode().Perm() != 0777 {
+		t.Fatalf("shared directory permissions changed: %v (%v)", info.Mode(), err)
+	}
+}
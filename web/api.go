@@ -2,6 +2,9 @@ package web
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
@@ -19,6 +22,7 @@ import (
 	qmi18n "quantmesh/i18n"
 	"quantmesh/logger"
 	"quantmesh/position"
+	"quantmesh/safety"
 	"quantmesh/storage"
 	"quantmesh/utils"
 )
@@ -69,6 +73,9 @@ var (
 	// 多交易对状态（key: exchange:symbol）
 	statusBySymbol   = make(map[string]*SystemStatus)
 	defaultSymbolKey string
+	// 默认交易对的存储查询范围（交易所小写、交易对大写，与入库格式一致）
+	defaultScopeExchange string
+	defaultScopeSymbol   string
 	// 保护 statusBySymbol 的读写锁
 	statusMu sync.RWMutex
 	// 版本号（需要从 main.go 注入）
@@ -94,6 +101,8 @@ type AITask struct {
 	Result    *ai.GenerateConfigResponse `json:"result,omitempty"`
 	Error     string                 `json:"error,omitempty"`
 	Progress  int                    `json:"progress"` // 0-100
+	// 审核模式下生成结果对应的待审批提案
+	ProposalID string `json:"proposal_id,omitempty"`
 }
 
 // AITaskManager AI 任务管理器
@@ -102,8 +111,20 @@ type AITaskManager struct {
 	mu    sync.RWMutex
 }
 
-var aiTaskManager = &AITaskManager{
-	tasks: make(map[string]*AITask),
+var aiTaskManager = newAITaskManager()
+
+// newAITaskManager 创建 AI 任务管理器
+func newAITaskManager() *AITaskManager {
+	return &AITaskManager{tasks: make(map[string]*AITask)}
+}
+
+// generateTaskID 生成随机任务ID（任务结果可凭ID直接查询，不能使用可预测的时间戳）
+func generateTaskID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("生成任务ID失败: %v", err))
+	}
+	return "task_" + hex.EncodeToString(b)
 }
 
 // CreateTask 创建新任务
@@ -111,7 +132,7 @@ func (m *AITaskManager) CreateTask() *AITask {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	
-	taskID := fmt.Sprintf("task_%d", time.Now().UnixNano())
+	taskID := generateTaskID()
 	task := &AITask{
 		TaskID:    taskID,
 		Status:    TaskStatusPending,
@@ -152,6 +173,15 @@ func (m *AITaskManager) UpdateTask(taskID string, status AITaskStatus, result *a
 	}
 }
 
+// SetProposal 记录任务生成结果对应的配置提案
+func (m *AITaskManager) SetProposal(taskID, proposalID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if task, ok := m.tasks[taskID]; ok {
+		task.ProposalID = proposalID
+	}
+}
+
 // CleanupOldTasks 清理旧任务（超过1小时）
 func (m *AITaskManager) CleanupOldTasks() {
 	m.mu.Lock()
@@ -245,6 +275,8 @@ func RegisterFundingProvider(exchange, symbol string, provider FundingMonitorPro
 // SetDefaultSymbolKey 设置默认交易对（兼容旧接口）
 func SetDefaultSymbolKey(exchange, symbol string) {
 	defaultSymbolKey = makeSymbolKey(exchange, symbol)
+	defaultScopeExchange = strings.ToLower(exchange)
+	defaultScopeSymbol = strings.ToUpper(symbol)
 }
 
 // resolveSymbolKey 根据查询参数获取 key
@@ -260,6 +292,19 @@ func resolveSymbolKey(c *gin.Context) string {
 	return defaultSymbolKey
 }
 
+// resolveSymbolScope 根据查询参数获取存储查询范围（交易所, 交易对），与 resolveSymbolKey 使用相同的参数
+// 未传 symbol 时使用默认交易对；只传 symbol 时不区分交易所；scope=all 时不过滤，返回所有交易对的汇总数据
+func resolveSymbolScope(c *gin.Context) (string, string) {
+	if c.Query("scope") == "all" {
+		return "", ""
+	}
+	sym := c.Query("symbol")
+	if sym == "" {
+		return defaultScopeExchange, defaultScopeSymbol
+	}
+	return strings.ToLower(c.Query("exchange")), strings.ToUpper(sym)
+}
+
 // === Provider 映射 ===
 var (
 	priceProviders    = make(map[string]PriceProvider)
@@ -285,6 +330,7 @@ func pickStatus(c *gin.Context) *SystemStatus {
 }
 
 func PickPriceProvider(c *gin.Context) PriceProvider {
+	priceProvider := providersOf(c).Price
 	if key := resolveSymbolKey(c); key != "" {
 		providersMu.RLock()
 		p, ok := priceProviders[key]
@@ -300,6 +346,7 @@ func PickPriceProvider(c *gin.Context) PriceProvider {
 }
 
 func pickExchangeProvider(c *gin.Context) ExchangeProvider {
+	exchangeProvider := providersOf(c).Exchange
 	if key := resolveSymbolKey(c); key != "" {
 		providersMu.RLock()
 		p, ok := exchangeProviders[key]
@@ -312,6 +359,7 @@ func pickExchangeProvider(c *gin.Context) ExchangeProvider {
 }
 
 func PickPositionProvider(c *gin.Context) PositionManagerProvider {
+	positionManagerProvider := providersOf(c).PositionManager
 	key := resolveSymbolKey(c)
 	logger.Info("[DEBUG] PickPositionProvider - resolvedKey=%s", key)
 
@@ -332,6 +380,7 @@ func PickPositionProvider(c *gin.Context) PositionManagerProvider {
 }
 
 func PickRiskProvider(c *gin.Context) RiskMonitorProvider {
+	riskMonitorProvider := providersOf(c).RiskMonitor
 	if key := resolveSymbolKey(c); key != "" {
 		providersMu.RLock()
 		p, ok := riskProviders[key]
@@ -344,6 +393,7 @@ func PickRiskProvider(c *gin.Context) RiskMonitorProvider {
 }
 
 func PickStorageProvider(c *gin.Context) StorageServiceProvider {
+	storageServiceProvider := providersOf(c).Storage
 	if key := resolveSymbolKey(c); key != "" {
 		providersMu.RLock()
 		p, ok := storageProviders[key]
@@ -356,6 +406,7 @@ func PickStorageProvider(c *gin.Context) StorageServiceProvider {
 }
 
 func PickFundingProvider(c *gin.Context) FundingMonitorProvider {
+	fundingMonitorProvider := providersOf(c).FundingMonitor
 	if key := resolveSymbolKey(c); key != "" {
 		providersMu.RLock()
 		p, ok := fundingProviders[key]
@@ -544,13 +595,9 @@ func getSymbols(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"symbols": list})
 }
 
-// getVersion 返回版本号（不需要认证）
+// getVersion 返回版本号、git 提交和嵌入的前端版本（不需要认证）
 func getVersion(c *gin.Context) {
-	version := appVersion
-	if version == "" {
-		version = "unknown"
-	}
-	c.JSON(http.StatusOK, gin.H{"version": version})
+	c.JSON(http.StatusOK, GetBuildInfo())
 }
 
 // getExchanges 返回所有配置的交易所列表
@@ -624,17 +671,14 @@ type PositionSummary struct {
 
 // PositionInfo 单个持仓信息
 type PositionInfo struct {
-	Price         float64 `json:"price"`          // 持仓价格
+	Price         float64 `json:"price"`          // 持仓价格（槽位价格）
+	EntryPrice    float64 `json:"entry_price"`    // 平均成本价（含手续费）
+	CostBasis     float64 `json:"cost_basis"`     // 持仓成本
 	Quantity      float64 `json:"quantity"`       // 持仓数量
 	Value         float64 `json:"value"`          // 持仓价值
 	UnrealizedPnL float64 `json:"unrealized_pnl"` // 未实现盈亏
 }
 
-var (
-	// 价格提供者（需要从main.go注入）
-	priceProvider PriceProvider
-)
-
 // PriceProvider 价格提供者接口
 type PriceProvider interface {
 	GetLastPrice() float64
@@ -642,14 +686,9 @@ type PriceProvider interface {
 
 // SetPriceProvider 设置价格提供者
 func SetPriceProvider(provider PriceProvider) {
-	priceProvider = provider
+	defaultProviders.Price = provider
 }
 
-var (
-	// 交易所提供者（需要从main.go注入）
-	exchangeProvider ExchangeProvider
-)
-
 // ExchangeProvider 交易所提供者接口
 type ExchangeProvider interface {
 	GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*exchange.Candle, error)
@@ -657,7 +696,7 @@ type ExchangeProvider interface {
 
 // SetExchangeProvider 设置交易所提供者
 func SetExchangeProvider(provider ExchangeProvider) {
-	exchangeProvider = provider
+	defaultProviders.Exchange = provider
 }
 
 // getPositions 获取持仓列表（从槽位数据筛选）
@@ -711,6 +750,12 @@ func getPositions(c *gin.Context) {
 			positionCount++
 			totalQuantity += slot.PositionQty
 
+			// 成本价：使用含手续费的真实成本，旧数据没有成本记录时退回槽位价格
+			entryPrice := slot.AvgEntryPrice
+			if entryPrice <= 0 {
+				entryPrice = slot.Price
+			}
+
 			// 计算持仓价值（使用当前价格）
 			value := slot.PositionQty * currentPrice
 			if currentPrice == 0 {
@@ -752,14 +797,16 @@ func getPositions(c *gin.Context) {
 					// 价格偏差仍然过大，使用持仓价格（未实现盈亏为0）
 					logger.Warn("⚠️ [getPositions] [%s:%s] 价格偏差过大，使用持仓价格计算（未实现盈亏设为0）: currentPrice=%.2f, slotPrice=%.2f, 偏差=%.2f%%, resolvedKey=%s",
 						exchange, symbol, adjustedCurrentPrice, slot.Price, priceDeviation*100, resolvedKey)
-					adjustedCurrentPrice = slot.Price
+					adjustedCurrentPrice = entryPrice
 				}
 
-				unrealizedPnL = (adjustedCurrentPrice - slot.Price) * slot.PositionQty
+				unrealizedPnL = (adjustedCurrentPrice - entryPrice) * slot.PositionQty
 			}
 
 			positions = append(positions, PositionInfo{
 				Price:         slot.Price,
+				EntryPrice:    entryPrice,
+				CostBasis:     entryPrice * slot.PositionQty,
 				Quantity:      slot.PositionQty,
 				Value:         value,
 				UnrealizedPnL: unrealizedPnL,
@@ -767,12 +814,12 @@ func getPositions(c *gin.Context) {
 		}
 	}
 
-	// 计算平均持仓价格
+	// 计算平均持仓价格（加权平均成本价）
 	averagePrice := 0.0
 	if totalQuantity > 0 {
 		totalCost := 0.0
 		for _, pos := range positions {
-			totalCost += pos.Price * pos.Quantity
+			totalCost += pos.CostBasis
 		}
 		averagePrice = totalCost / totalQuantity
 	}
@@ -788,7 +835,7 @@ func getPositions(c *gin.Context) {
 	// 计算总持仓成本
 	totalCost := 0.0
 	for _, pos := range positions {
-		totalCost += pos.Price * pos.Quantity
+		totalCost += pos.CostBasis
 	}
 
 	// 计算亏损率（相对于持仓成本的百分比）
@@ -844,6 +891,11 @@ func getPositionsSummary(c *gin.Context) {
 	if priceProv != nil {
 		currentPrice = priceProv.GetLastPrice()
 	}
+	// 币本位合约的数量为张数，价值与盈亏以基础币计
+	var spec utils.ContractSpec
+	if sp, ok := pmProvider.(ContractSpecProvider); ok {
+		spec = sp.GetContractSpec()
+	}
 
 	totalQuantity := 0.0
 	totalValue := 0.0
@@ -856,14 +908,18 @@ func getPositionsSummary(c *gin.Context) {
 		if slot.PositionStatus == "FILLED" && slot.PositionQty > 0.000001 && slot.Price > 0.000001 {
 			positionCount++
 			totalQuantity += slot.PositionQty
-			totalCost += slot.Price * slot.PositionQty
+			if slot.AvgEntryPrice > 0 {
+				totalCost += slot.AvgEntryPrice * slot.PositionQty
+			} else {
+				totalCost += slot.Price * slot.PositionQty
+			}
 
 			// 计算持仓价值（使用当前价格）
 			if currentPrice > 0 {
-				totalValue += slot.PositionQty * currentPrice
+				totalValue += spec.SettlementValue(slot.PositionQty, currentPrice)
 			} else {
 				// 如果当前价格不可用，使用持仓价格
-				totalValue += slot.PositionQty * slot.Price
+				totalValue += spec.SettlementValue(slot.PositionQty, slot.Price)
 			}
 		}
 	}
@@ -873,6 +929,8 @@ func getPositionsSummary(c *gin.Context) {
 	if totalQuantity > 0 {
 		averagePrice = totalCost / totalQuantity
 	}
+	// 持仓成本（结算资产），U 本位时等于 totalCost
+	costValue := spec.SettlementValue(totalQuantity, averagePrice)
 
 	// 计算总未实现盈亏
 	unrealizedPnL := 0.0
@@ -913,22 +971,22 @@ func getPositionsSummary(c *gin.Context) {
 			currentPrice = averagePrice // 使用平均价格，使未实现盈亏为0
 		}
 
-		unrealizedPnL = (currentPrice - averagePrice) * totalQuantity
+		unrealizedPnL = spec.PnL(averagePrice, currentPrice, totalQuantity)
 
 		// 🔥 添加未实现盈亏合理性检查：如果未实现盈亏相对于持仓成本过大（超过100%），记录警告
-		if totalCost > 0 {
-			pnlRatio := unrealizedPnL / totalCost
+		if costValue > 0 {
+			pnlRatio := unrealizedPnL / costValue
 			if pnlRatio > 1.0 || pnlRatio < -1.0 {
 				logger.Warn("⚠️ [getPositionsSummary] 未实现盈亏异常: unrealizedPnL=%.2f, totalCost=%.2f, 比例=%.2f%%, currentPrice=%.2f, averagePrice=%.2f",
-					unrealizedPnL, totalCost, pnlRatio*100, currentPrice, averagePrice)
+					unrealizedPnL, costValue, pnlRatio*100, currentPrice, averagePrice)
 			}
 		}
 	}
 
 	// 计算亏损率（相对于持仓成本的百分比）
 	pnlPercentage := 0.0
-	if totalCost > 0 {
-		pnlPercentage = (unrealizedPnL / totalCost) * 100.0
+	if costValue > 0 {
+		pnlPercentage = (unrealizedPnL / costValue) * 100.0
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -939,6 +997,7 @@ func getPositionsSummary(c *gin.Context) {
 		"current_price":  currentPrice,
 		"unrealized_pnl": unrealizedPnL,
 		"pnl_percentage": pnlPercentage,
+		"contract_type":  spec.Type(),
 	})
 }
 
@@ -971,7 +1030,8 @@ func getOrders(c *gin.Context) {
 		offset = o
 	}
 
-	orders, err := storage.QueryOrders(limit, offset, status)
+	exchangeName, symbol := resolveSymbolScope(c)
+	orders, err := storage.QueryOrders(exchangeName, symbol, limit, offset, status)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "error.query_orders_failed", err)
 		return
@@ -983,6 +1043,7 @@ func getOrders(c *gin.Context) {
 		ordersResponse[i] = map[string]interface{}{
 			"order_id":        order.OrderID,
 			"client_order_id": order.ClientOrderID,
+			"exchange":        order.Exchange,
 			"symbol":          order.Symbol,
 			"side":            order.Side,
 			"price":           order.Price,
@@ -1025,10 +1086,11 @@ func getOrderHistory(c *gin.Context) {
 	}
 
 	// 只查询已完成或已取消的订单
-	orders, err := storage.QueryOrders(limit, offset, "FILLED")
+	exchangeName, symbol := resolveSymbolScope(c)
+	orders, err := storage.QueryOrders(exchangeName, symbol, limit, offset, "FILLED")
 	if err != nil {
 		// 如果查询失败，尝试查询所有状态的订单
-		orders, err = storage.QueryOrders(limit, offset, "")
+		orders, err = storage.QueryOrders(exchangeName, symbol, limit, offset, "")
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -1036,7 +1098,7 @@ func getOrderHistory(c *gin.Context) {
 	}
 
 	// 也查询已取消的订单
-	canceledOrders, err := storage.QueryOrders(limit, offset, "CANCELED")
+	canceledOrders, err := storage.QueryOrders(exchangeName, symbol, limit, offset, "CANCELED")
 	if err == nil {
 		orders = append(orders, canceledOrders...)
 	}
@@ -1047,6 +1109,7 @@ func getOrderHistory(c *gin.Context) {
 		ordersResponse[i] = map[string]interface{}{
 			"order_id":        order.OrderID,
 			"client_order_id": order.ClientOrderID,
+			"exchange":        order.Exchange,
 			"symbol":          order.Symbol,
 			"side":            order.Side,
 			"price":           order.Price,
@@ -1060,11 +1123,6 @@ func getOrderHistory(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"orders": ordersResponse})
 }
 
-var (
-	// 存储服务提供者（需要从main.go注入）
-	storageServiceProvider StorageServiceProvider
-)
-
 // StorageServiceProvider 存储服务提供者接口
 type StorageServiceProvider interface {
 	GetStorage() storage.Storage
@@ -1072,7 +1130,7 @@ type StorageServiceProvider interface {
 
 // SetStorageServiceProvider 设置存储服务提供者
 func SetStorageServiceProvider(provider StorageServiceProvider) {
-	storageServiceProvider = provider
+	defaultProviders.Storage = provider
 }
 
 // storageServiceAdapter 存储服务适配器
@@ -1118,8 +1176,9 @@ func getStatistics(c *gin.Context) {
 		return
 	}
 
-	// 从数据库获取统计汇总
-	summary, err := storage.GetStatisticsSummary()
+	// 从数据库获取当前交易对的统计汇总
+	exchangeName, symbol := resolveSymbolScope(c)
+	summary, err := storage.GetStatisticsSummaryBySymbol(exchangeName, symbol)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -1182,7 +1241,8 @@ func getDailyStatistics(c *gin.Context) {
 	endDate := utils.NowConfiguredTimezone()
 
 	// 1. 先从 statistics 表查询
-	statsFromTable, err := st.QueryStatistics(startDate, endDate)
+	exchangeName, symbol := resolveSymbolScope(c)
+	statsFromTable, err := st.QueryStatistics(exchangeName, symbol, startDate, endDate)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -1197,7 +1257,7 @@ func getDailyStatistics(c *gin.Context) {
 
 	// 3. 从 trades 表查询所有日期（包含缺失的日期和盈利/亏损交易数）
 	tradesStatsMap := make(map[string]*storage.DailyStatisticsWithTradeCount)
-	tradesStats, err2 := st.QueryDailyStatisticsFromTrades(startDate, endDate)
+	tradesStats, err2 := st.QueryDailyStatisticsBySymbol(exchangeName, symbol, startDate, endDate)
 	if err2 == nil {
 		for _, tradeStat := range tradesStats {
 			dateKey := tradeStat.Date.Format("2006-01-02")
@@ -1268,6 +1328,15 @@ func getDailyStatistics(c *gin.Context) {
 			}
 		}
 
+		// 资金费来自资金费流水表，净盈亏 = 交易盈亏 + 资金费
+		fundingFee := 0.0
+		if tradeStat, exists := tradesStatsMap[dateKey]; exists {
+			fundingFee = tradeStat.FundingFee
+		}
+		totalPnL, _ := item["total_pnl"].(float64)
+		item["funding_fee"] = fundingFee
+		item["net_pnl"] = totalPnL + fundingFee
+
 		result = append(result, item)
 	}
 
@@ -1327,7 +1396,8 @@ func getTradeStatistics(c *gin.Context) {
 		endTime = utils.NowConfiguredTimezone()
 	}
 
-	trades, err := storage.QueryTrades(startTime, endTime, limit, offset)
+	exchangeName, symbol := resolveSymbolScope(c)
+	trades, err := storage.QueryTrades(exchangeName, symbol, startTime, endTime, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -1351,6 +1421,70 @@ func getTradeStatistics(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"trades": tradesResponse})
 }
 
+// getFundingPayments 获取资金费流水
+// GET /api/statistics/funding-payments
+func getFundingPayments(c *gin.Context) {
+	storageProv := PickStorageProvider(c)
+	if storageProv == nil {
+		c.JSON(http.StatusOK, gin.H{"payments": []interface{}{}})
+		return
+	}
+
+	st := storageProv.GetStorage()
+	if st == nil {
+		c.JSON(http.StatusOK, gin.H{"payments": []interface{}{}})
+		return
+	}
+
+	limit := 100
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "100")); err == nil && l > 0 {
+		limit = l
+	}
+
+	var startTime, endTime time.Time
+	var err error
+	if startTimeStr := c.Query("start_time"); startTimeStr != "" {
+		startTime, err = time.Parse(time.RFC3339, startTimeStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_start_time")
+			return
+		}
+	} else {
+		startTime = time.Now().AddDate(0, 0, -30) // 默认最近30天
+	}
+	if endTimeStr := c.Query("end_time"); endTimeStr != "" {
+		endTime, err = time.Parse(time.RFC3339, endTimeStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_end_time")
+			return
+		}
+	} else {
+		endTime = time.Now()
+	}
+
+	payments, err := st.QueryFundingPayments(c.Query("exchange"), c.Query("symbol"), startTime, endTime, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	total := 0.0
+	response := make([]map[string]interface{}, len(payments))
+	for i, p := range payments {
+		total += p.Amount
+		response[i] = map[string]interface{}{
+			"exchange":     p.Exchange,
+			"symbol":       p.Symbol,
+			"asset":        p.Asset,
+			"amount":       p.Amount,
+			"tran_id":      p.TranID,
+			"payment_time": utils.ToUTC8(p.PaymentTime),
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payments": response, "total": total})
+}
+
 // 这些函数已移动到 web/api_config.go
 // 保留这些存根函数以保持向后兼容（如果其他地方有引用）
 func getConfig(c *gin.Context) {
@@ -1362,6 +1496,7 @@ func updateConfig(c *gin.Context) {
 }
 
 func startTrading(c *gin.Context) {
+	symbolManagerProvider := providersOf(c).SymbolManager
 	exchange := c.Query("exchange")
 	symbol := c.Query("symbol")
 
@@ -1401,6 +1536,7 @@ func startTrading(c *gin.Context) {
 }
 
 func stopTrading(c *gin.Context) {
+	symbolManagerProvider := providersOf(c).SymbolManager
 	exchange := c.Query("exchange")
 	symbol := c.Query("symbol")
 
@@ -1441,6 +1577,7 @@ type ClosePositionsResponse struct {
 }
 
 func closeAllPositions(c *gin.Context) {
+	symbolManagerProvider := providersOf(c).SymbolManager
 	exchange := c.Query("exchange")
 	symbol := c.Query("symbol")
 
@@ -1466,7 +1603,7 @@ func closeAllPositions(c *gin.Context) {
 	result, err := adapter.ClosePositions(exchange, symbol)
 	if err != nil {
 		logger.Error("❌ [%s:%s] 平仓失败: %v", exchange, symbol, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondExchangeError(c, http.StatusInternalServerError, "error.close_positions_failed", err)
 		return
 	}
 
@@ -1476,11 +1613,6 @@ func closeAllPositions(c *gin.Context) {
 
 // ========== 交易控制相关API ==========
 
-var (
-	// SymbolManager 提供者（需要从main.go注入）
-	symbolManagerProvider SymbolManagerProvider
-)
-
 // SymbolManagerProvider SymbolManager 提供者接口
 type SymbolManagerProvider interface {
 	Get(exchange, symbol string) (interface{}, bool) // 返回 SymbolRuntime（使用 interface{} 避免循环依赖）
@@ -1491,16 +1623,11 @@ type SymbolManagerProvider interface {
 
 // RegisterSymbolManager 注册 SymbolManager
 func RegisterSymbolManager(provider SymbolManagerProvider) {
-	symbolManagerProvider = provider
+	defaultProviders.SymbolManager = provider
 }
 
 // ========== 系统监控相关API ==========
 
-var (
-	// 系统监控数据提供者（需要从main.go注入）
-	systemMetricsProvider SystemMetricsProvider
-)
-
 // SystemMetricsProvider 系统监控数据提供者接口
 type SystemMetricsProvider interface {
 	GetCurrentMetrics() (*SystemMetricsResponse, error)
@@ -1531,7 +1658,7 @@ type DailySystemMetricsResponse struct {
 
 // SetSystemMetricsProvider 设置系统监控数据提供者
 func SetSystemMetricsProvider(provider SystemMetricsProvider) {
-	systemMetricsProvider = provider
+	defaultProviders.SystemMetrics = provider
 }
 
 // getSystemMetrics 获取系统监控数据
@@ -1541,6 +1668,7 @@ func SetSystemMetricsProvider(provider SystemMetricsProvider) {
 //   - end_time: 结束时间（可选，ISO 8601格式，默认当前时间）
 //   - granularity: 粒度（detail/daily，默认detail）
 func getSystemMetrics(c *gin.Context) {
+	systemMetricsProvider := providersOf(c).SystemMetrics
 	if systemMetricsProvider == nil {
 		c.JSON(http.StatusOK, gin.H{"metrics": []interface{}{}})
 		return
@@ -1605,6 +1733,7 @@ func getSystemMetrics(c *gin.Context) {
 // getCurrentSystemMetrics 获取当前系统状态
 // GET /api/system/metrics/current
 func getCurrentSystemMetrics(c *gin.Context) {
+	systemMetricsProvider := providersOf(c).SystemMetrics
 	if systemMetricsProvider == nil {
 		// 返回完整的对象结构，避免前端访问 undefined 字段
 		c.JSON(http.StatusOK, &SystemMetricsResponse{
@@ -1649,6 +1778,7 @@ func getCurrentSystemMetrics(c *gin.Context) {
 // 参数：
 //   - days: 查询天数（默认30天）
 func getDailySystemMetrics(c *gin.Context) {
+	systemMetricsProvider := providersOf(c).SystemMetrics
 	if systemMetricsProvider == nil {
 		c.JSON(http.StatusOK, gin.H{"metrics": []interface{}{}})
 		return
@@ -1672,8 +1802,6 @@ func getDailySystemMetrics(c *gin.Context) {
 // ========== 槽位数据相关API ==========
 
 var (
-	// 槽位数据提供者（需要从main.go注入）
-	positionManagerProvider PositionManagerProvider
 	// 订单金额配置（用于计算订单数量）
 	orderQuantityConfig float64
 )
@@ -1694,6 +1822,11 @@ type PositionManagerProvider interface {
 	GetPriceInterval() float64
 }
 
+// ContractSpecProvider 合约规格提供者（可选接口，币本位交易对的价值与盈亏换算）
+type ContractSpecProvider interface {
+	GetContractSpec() utils.ContractSpec
+}
+
 // SlotInfo 槽位信息
 type SlotInfo struct {
 	Exchange       string    `json:"exchange"`
@@ -1701,6 +1834,8 @@ type SlotInfo struct {
 	Price          float64   `json:"price"`
 	PositionStatus string    `json:"position_status"` // EMPTY/FILLED
 	PositionQty    float64   `json:"position_qty"`
+	CostBasis      float64   `json:"cost_basis"`      // 持仓总成本（含手续费）
+	AvgEntryPrice  float64   `json:"avg_entry_price"` // 平均成本价
 	OrderID        int64     `json:"order_id"`
 	ClientOID      string    `json:"client_order_id"`
 	OrderSide      string    `json:"order_side"`   // BUY/SELL
@@ -1709,11 +1844,15 @@ type SlotInfo struct {
 	OrderFilledQty float64   `json:"order_filled_qty"`
 	OrderCreatedAt time.Time `json:"order_created_at"`
 	SlotStatus     string    `json:"slot_status"` // FREE/PENDING/LOCKED
+	QueueKnown     bool      `json:"queue_known"`     // 是否有排队位置估算（需启用 trading.queue_position）
+	QueueAhead     float64   `json:"queue_ahead"`     // 估算的前方排队数量
+	QueueLevelQty  float64   `json:"queue_level_qty"` // 挂单价位的盘口总量
+	ManualLocked   bool      `json:"manual_locked"`   // 是否被人工锁定（不再挂单）
 }
 
 // SetPositionManagerProvider 设置槽位数据提供者
 func SetPositionManagerProvider(provider PositionManagerProvider) {
-	positionManagerProvider = provider
+	defaultProviders.PositionManager = provider
 }
 
 // positionManagerAdapter 槽位管理器适配器
@@ -1739,25 +1878,36 @@ func (a *positionManagerAdapter) GetAllSlots() []SlotInfo {
 
 	slots := make([]SlotInfo, len(detailedSlots))
 	for i, ds := range detailedSlots {
-		slots[i] = SlotInfo{
-			Exchange:       exchange,
-			Symbol:         symbol,
-			Price:          ds.Price,
-			PositionStatus: ds.PositionStatus,
-			PositionQty:    ds.PositionQty,
-			OrderID:        ds.OrderID,
-			ClientOID:      ds.ClientOID,
-			OrderSide:      ds.OrderSide,
-			OrderStatus:    ds.OrderStatus,
-			OrderPrice:     ds.OrderPrice,
-			OrderFilledQty: ds.OrderFilledQty,
-			OrderCreatedAt: utils.ToUTC8(ds.OrderCreatedAt),
-			SlotStatus:     ds.SlotStatus,
-		}
+		slots[i] = NewSlotInfo(exchange, symbol, ds)
 	}
 	return slots
 }
 
+// NewSlotInfo 把槽位详细信息转换为 API 返回结构
+func NewSlotInfo(exchange, symbol string, ds position.DetailedSlotData) SlotInfo {
+	return SlotInfo{
+		Exchange:       exchange,
+		Symbol:         symbol,
+		Price:          ds.Price,
+		PositionStatus: ds.PositionStatus,
+		PositionQty:    ds.PositionQty,
+		CostBasis:      ds.CostBasis,
+		AvgEntryPrice:  ds.AvgEntryPrice,
+		OrderID:        ds.OrderID,
+		ClientOID:      ds.ClientOID,
+		OrderSide:      ds.OrderSide,
+		OrderStatus:    ds.OrderStatus,
+		OrderPrice:     ds.OrderPrice,
+		OrderFilledQty: ds.OrderFilledQty,
+		OrderCreatedAt: utils.ToUTC8(ds.OrderCreatedAt),
+		SlotStatus:     ds.SlotStatus,
+		QueueKnown:     ds.QueueKnown,
+		QueueAhead:     ds.QueueAhead,
+		QueueLevelQty:  ds.QueueLevelQty,
+		ManualLocked:   ds.ManualLocked,
+	}
+}
+
 // GetSlotCount 获取槽位总数
 func (a *positionManagerAdapter) GetSlotCount() int {
 	return a.manager.GetSlotCount()
@@ -1788,6 +1938,16 @@ func (a *positionManagerAdapter) GetPriceInterval() float64 {
 	return a.manager.GetPriceInterval()
 }
 
+// GetContractSpec 获取合约规格
+func (a *positionManagerAdapter) GetContractSpec() utils.ContractSpec {
+	return a.manager.GetContractSpec()
+}
+
+// GetAnchorPrice 获取网格锚点价格
+func (a *positionManagerAdapter) GetAnchorPrice() float64 {
+	return a.manager.GetAnchorPrice()
+}
+
 // getSlots 获取所有槽位信息
 // GET /api/slots
 func getSlots(c *gin.Context) {
@@ -1820,11 +1980,6 @@ func getSlots(c *gin.Context) {
 
 // ========== 策略资金分配相关API ==========
 
-var (
-	// 策略数据提供者（需要从main.go注入）
-	strategyProvider StrategyProvider
-)
-
 // StrategyProvider 策略资金分配提供者接口
 type StrategyProvider interface {
 	GetCapitalAllocation() map[string]StrategyCapitalInfo
@@ -1841,7 +1996,7 @@ type StrategyCapitalInfo struct {
 
 // SetStrategyProvider 设置策略数据提供者
 func SetStrategyProvider(provider StrategyProvider) {
-	strategyProvider = provider
+	defaultProviders.Strategy = provider
 }
 
 // strategyProviderAdapter 策略提供者适配器
@@ -1862,6 +2017,7 @@ func (a *strategyProviderAdapter) GetCapitalAllocation() map[string]StrategyCapi
 // getStrategyAllocation 获取策略资金分配信息
 // GET /api/strategies/allocation
 func getStrategyAllocation(c *gin.Context) {
+	strategyProvider := providersOf(c).Strategy
 	if strategyProvider == nil {
 		c.JSON(http.StatusOK, gin.H{"allocation": map[string]interface{}{}})
 		return
@@ -1929,11 +2085,6 @@ type PendingOrderInfo struct {
 
 // ========== 日志相关API ==========
 
-var (
-	// 日志存储提供者（需要从main.go注入）
-	logStorageProvider LogStorageProvider
-)
-
 // LogStorageProvider 日志存储提供者接口
 type LogStorageProvider interface {
 	GetLogs(startTime, endTime time.Time, level, keyword string, limit, offset int) ([]*LogRecordResponse, int, error)
@@ -2007,7 +2158,7 @@ type LogRecordResponse struct {
 
 // SetLogStorageProvider 设置日志存储提供者
 func SetLogStorageProvider(provider LogStorageProvider) {
-	logStorageProvider = provider
+	defaultProviders.LogStorage = provider
 }
 
 // getLogs 获取日志
@@ -2020,6 +2171,7 @@ func SetLogStorageProvider(provider LogStorageProvider) {
 //   - limit: 每页数量（可选，默认100，最大1000）
 //   - offset: 偏移量（可选，默认0）
 func getLogs(c *gin.Context) {
+	logStorageProvider := providersOf(c).LogStorage
 	if logStorageProvider == nil {
 		c.JSON(http.StatusOK, gin.H{"logs": []interface{}{}, "total": 0})
 		return
@@ -2093,6 +2245,7 @@ func getLogs(c *gin.Context) {
 //   - days: 保留天数（默认7天）
 //   - levels: 要清理的日志级别列表，如 ["INFO", "WARN"]（可选，默认清理所有级别）
 func cleanLogs(c *gin.Context) {
+	logStorageProvider := providersOf(c).LogStorage
 	if logStorageProvider == nil {
 		respondError(c, http.StatusServiceUnavailable, "日志存储未初始化")
 		return
@@ -2138,6 +2291,7 @@ func cleanLogs(c *gin.Context) {
 // getLogStats 获取日志统计信息
 // GET /api/logs/stats
 func getLogStats(c *gin.Context) {
+	logStorageProvider := providersOf(c).LogStorage
 	if logStorageProvider == nil {
 		respondError(c, http.StatusServiceUnavailable, "日志存储未初始化")
 		return
@@ -2155,6 +2309,7 @@ func getLogStats(c *gin.Context) {
 // vacuumLogs 优化日志数据库
 // POST /api/logs/vacuum
 func vacuumLogs(c *gin.Context) {
+	logStorageProvider := providersOf(c).LogStorage
 	if logStorageProvider == nil {
 		respondError(c, http.StatusServiceUnavailable, "日志存储未初始化")
 		return
@@ -2173,13 +2328,14 @@ func vacuumLogs(c *gin.Context) {
 
 // ReconciliationStatus 对账状态
 type ReconciliationStatus struct {
-	ReconcileCount    int64     `json:"reconcile_count"`     // 对账次数
-	LastReconcileTime time.Time `json:"last_reconcile_time"` // 最后对账时间
-	LocalPosition     float64   `json:"local_position"`      // 本地持仓
-	TotalBuyQty       float64   `json:"total_buy_qty"`       // 累计买入
-	TotalSellQty      float64   `json:"total_sell_qty"`      // 累计卖出
-	EstimatedProfit   float64   `json:"estimated_profit"`    // 预计盈利
-	ActualProfit      float64   `json:"actual_profit"`       // 实际盈利（来自 trades 表）
+	ReconcileCount    int64             `json:"reconcile_count"`     // 对账次数
+	LastReconcileTime time.Time         `json:"last_reconcile_time"` // 最后对账时间
+	LocalPosition     float64           `json:"local_position"`      // 本地持仓
+	TotalBuyQty       float64           `json:"total_buy_qty"`       // 累计买入
+	TotalSellQty      float64           `json:"total_sell_qty"`      // 累计卖出
+	EstimatedProfit   float64           `json:"estimated_profit"`    // 预计盈利
+	ActualProfit      float64           `json:"actual_profit"`       // 实际盈利（来自 trades 表）
+	Dust              *safety.DustStats `json:"dust,omitempty"`      // 容差内粉尘差异累计统计（单独统计，不计入持仓偏差告警）
 }
 
 // ReconciliationHistoryInfo 对账历史信息
@@ -2236,7 +2392,7 @@ func getReconciliationStatus(c *gin.Context) {
 
 	// 获取实际盈利
 	actualProfit := 0.0
-	symbol := c.Query("symbol")
+	exchangeName, symbol := resolveSymbolScope(c)
 	if symbol == "" {
 		if st := pickStatus(c); st != nil {
 			symbol = st.Symbol
@@ -2246,7 +2402,7 @@ func getReconciliationStatus(c *gin.Context) {
 	storageProv := PickStorageProvider(c)
 	if symbol != "" && storageProv != nil && storageProv.GetStorage() != nil {
 		// 查询截止到现在的累计实际盈利
-		actualProfit, _ = storageProv.GetStorage().GetActualProfitBySymbol(symbol, time.Now().UTC())
+		actualProfit, _ = storageProv.GetStorage().GetActualProfitBySymbol(exchangeName, symbol, time.Now().UTC())
 	}
 
 	status := ReconciliationStatus{
@@ -2258,6 +2414,11 @@ func getReconciliationStatus(c *gin.Context) {
 		EstimatedProfit:   estimatedProfit,
 		ActualProfit:      actualProfit,
 	}
+	if reconciliationProvider := providersOf(c).Reconciliation; reconciliationProvider != nil && symbol != "" {
+		if dust, ok := reconciliationProvider.ReconciliationDust(exchangeName, symbol); ok {
+			status.Dust = &dust
+		}
+	}
 
 	c.JSON(http.StatusOK, status)
 }
@@ -2278,7 +2439,7 @@ func getReconciliationHistory(c *gin.Context) {
 	}
 
 	// 解析参数
-	symbol := c.Query("symbol")
+	exchangeName, symbol := resolveSymbolScope(c)
 	startTimeStr := c.Query("start_time")
 	endTimeStr := c.Query("end_time")
 	limitStr := c.DefaultQuery("limit", "100")
@@ -2319,7 +2480,7 @@ func getReconciliationHistory(c *gin.Context) {
 	}
 
 	// 查询对账历史
-	histories, err := storage.QueryReconciliationHistory(symbol, startTime, endTime, limit, offset)
+	histories, err := storage.QueryReconciliationHistory(exchangeName, symbol, startTime, endTime, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -2358,6 +2519,8 @@ type PnLSummaryResponse struct {
 	WinRate       float64 `json:"win_rate"`
 	WinningTrades int     `json:"winning_trades"`
 	LosingTrades  int     `json:"losing_trades"`
+	FundingFee    float64 `json:"funding_fee"` // 资金费收支（正数为收入）
+	NetPnL        float64 `json:"net_pnl"`     // 交易盈亏 + 资金费
 }
 
 // getPnLBySymbol 按币种对查询盈亏数据
@@ -2409,7 +2572,8 @@ func getPnLBySymbol(c *gin.Context) {
 	}
 
 	// 查询盈亏数据
-	summary, err := storage.GetPnLBySymbol(symbol, startTime, endTime)
+	exchangeName, _ := resolveSymbolScope(c)
+	summary, err := storage.GetPnLBySymbol(exchangeName, strings.ToUpper(symbol), startTime, endTime)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -2423,6 +2587,8 @@ func getPnLBySymbol(c *gin.Context) {
 		WinRate:       summary.WinRate,
 		WinningTrades: summary.WinningTrades,
 		LosingTrades:  summary.LosingTrades,
+		FundingFee:    summary.FundingFee,
+		NetPnL:        summary.NetPnL,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -2435,6 +2601,8 @@ type PnLBySymbolResponse struct {
 	TotalTrades int     `json:"total_trades"`
 	TotalVolume float64 `json:"total_volume"`
 	WinRate     float64 `json:"win_rate"`
+	FundingFee  float64 `json:"funding_fee"`
+	NetPnL      float64 `json:"net_pnl"`
 }
 
 // getPnLByTimeRange 按时间区间查询盈亏数据（按币种对分组）
@@ -2495,6 +2663,8 @@ func getPnLByTimeRange(c *gin.Context) {
 			TotalTrades: r.TotalTrades,
 			TotalVolume: r.TotalVolume,
 			WinRate:     r.WinRate,
+			FundingFee:  r.FundingFee,
+			NetPnL:      r.NetPnL,
 		}
 	}
 
@@ -2508,6 +2678,8 @@ type ExchangePnLResponse struct {
 	TotalTrades int                 `json:"total_trades"`
 	TotalVolume float64             `json:"total_volume"`
 	WinRate     float64             `json:"win_rate"`
+	FundingFee  float64             `json:"funding_fee"`
+	NetPnL      float64             `json:"net_pnl"`
 	Symbols     []SymbolPnLInfo     `json:"symbols"`
 }
 
@@ -2518,6 +2690,8 @@ type SymbolPnLInfo struct {
 	TotalTrades int     `json:"total_trades"`
 	TotalVolume float64 `json:"total_volume"`
 	WinRate     float64 `json:"win_rate"`
+	FundingFee  float64 `json:"funding_fee"`
+	NetPnL      float64 `json:"net_pnl"`
 }
 
 // getPnLByExchange 按交易所分组查询盈亏数据
@@ -2593,6 +2767,8 @@ func getPnLByExchange(c *gin.Context) {
 		exData.TotalPnL += r.TotalPnL
 		exData.TotalTrades += r.TotalTrades
 		exData.TotalVolume += r.TotalVolume
+		exData.FundingFee += r.FundingFee
+		exData.NetPnL += r.NetPnL
 
 		// 添加币种信息
 		exData.Symbols = append(exData.Symbols, SymbolPnLInfo{
@@ -2601,6 +2777,8 @@ func getPnLByExchange(c *gin.Context) {
 			TotalTrades: r.TotalTrades,
 			TotalVolume: r.TotalVolume,
 			WinRate:     r.WinRate,
+			FundingFee:  r.FundingFee,
+			NetPnL:      r.NetPnL,
 		})
 	}
 
@@ -2650,8 +2828,9 @@ func getAnomalousTrades(c *gin.Context) {
 		return
 	}
 
-	// 查询所有交易记录
-	trades, err := st.QueryTrades(time.Time{}, time.Now(), 1000, 0)
+	// 查询该交易对的交易记录
+	exchangeName, scopeSymbol := resolveSymbolScope(c)
+	trades, err := st.QueryTrades(exchangeName, scopeSymbol, time.Time{}, time.Now(), 1000, 0)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -2659,10 +2838,6 @@ func getAnomalousTrades(c *gin.Context) {
 
 	var anomalousTrades []map[string]interface{}
 	for _, trade := range trades {
-		if trade.Symbol != symbol {
-			continue
-		}
-
 		// 计算订单金额
 		orderAmount := trade.BuyPrice * trade.Quantity
 
@@ -2698,13 +2873,9 @@ type RiskMonitorProvider interface {
 	GetSymbolData(symbol string) interface{}
 }
 
-var (
-	riskMonitorProvider RiskMonitorProvider
-)
-
 // SetRiskMonitorProvider 设置风控监控提供者
 func SetRiskMonitorProvider(provider RiskMonitorProvider) {
-	riskMonitorProvider = provider
+	defaultProviders.RiskMonitor = provider
 }
 
 // RiskStatusResponse 风控状态响应
@@ -2966,6 +3137,12 @@ func getKlines(c *gin.Context) {
 		}
 	}
 
+	// 本地保存的 K 线（K 线缺口补齐启用时）
+	if c.Query("source") == "local" {
+		getStoredKlines(c, symbol, interval, limit)
+		return
+	}
+
 	// 调用交易所接口获取K线数据
 	ctx := c.Request.Context()
 	candles, err := prov.GetHistoricalKlines(ctx, symbol, interval, limit)
@@ -2993,11 +3170,6 @@ func getKlines(c *gin.Context) {
 
 // ========== 资金费率相关API ==========
 
-var (
-	// 资金费率监控提供者（需要从main.go注入）
-	fundingMonitorProvider FundingMonitorProvider
-)
-
 // FundingMonitorProvider 资金费率监控提供者接口
 type FundingMonitorProvider interface {
 	GetCurrentFundingRates() (map[string]float64, error)
@@ -3005,7 +3177,7 @@ type FundingMonitorProvider interface {
 
 // SetFundingMonitorProvider 设置资金费率监控提供者
 func SetFundingMonitorProvider(provider FundingMonitorProvider) {
-	fundingMonitorProvider = provider
+	defaultProviders.FundingMonitor = provider
 }
 
 // getFundingRate 获取当前资金费率
@@ -3122,17 +3294,13 @@ func getFundingRateHistory(c *gin.Context) {
 
 // ========== 市场情报数据源相关API ==========
 
-var (
-	// 数据源管理器提供者（需要从main.go注入）
-	dataSourceProvider DataSourceProvider
-)
-
 // DataSourceProvider 数据源提供者接口
 type DataSourceProvider interface {
 	GetRSSFeeds() ([]RSSFeedInfo, error)
 	GetFearGreedIndex() (*FearGreedIndexInfo, error)
 	GetRedditPosts(subreddits []string, limit int) ([]RedditPostInfo, error)
 	GetPolymarketMarkets(keywords []string) ([]PolymarketMarketInfo, error)
+	GetOnChain() (*ai.OnChainSnapshot, error)
 }
 
 // RSSFeedInfo RSS源信息
@@ -3185,21 +3353,19 @@ type PolymarketMarketInfo struct {
 
 // SetDataSourceProvider 设置数据源提供者
 func SetDataSourceProvider(provider DataSourceProvider) {
-	dataSourceProvider = provider
+	defaultProviders.DataSource = provider
 }
 
-// dataSourceAdapter 数据源适配器
-// 注意：这个适配器使用反射来调用方法，避免循环依赖
+// dataSourceAdapter 数据源适配器，将 ai 包的数据源转换为 Web API 的响应结构
 type dataSourceAdapter struct {
-	dsm              interface{}
+	dsm              ai.DataSourceAPI
 	rssFeeds         []string
 	fearGreedAPIURL  string
 	polymarketAPIURL string
 }
 
 // NewDataSourceAdapter 创建数据源适配器
-// dsm 应该是 *ai.DataSourceManager 类型，但使用 interface{} 避免循环依赖
-func NewDataSourceAdapter(dsm interface{}, rssFeeds []string, fearGreedAPIURL, polymarketAPIURL string) DataSourceProvider {
+func NewDataSourceAdapter(dsm ai.DataSourceAPI, rssFeeds []string, fearGreedAPIURL, polymarketAPIURL string) DataSourceProvider {
 	return &dataSourceAdapter{
 		dsm:              dsm,
 		rssFeeds:         rssFeeds,
@@ -3214,72 +3380,29 @@ func (a *dataSourceAdapter) GetRSSFeeds() ([]RSSFeedInfo, error) {
 		return nil, fmt.Errorf("数据源管理器未初始化")
 	}
 
-	// 使用反射调用方法（避免循环依赖）
-	dsmValue := reflect.ValueOf(a.dsm)
-	if !dsmValue.IsValid() {
-		return nil, fmt.Errorf("无效的数据源管理器")
-	}
-
 	feeds := make([]RSSFeedInfo, 0)
 
 	// 如果没有配置RSS源，使用默认源
 	rssFeeds := a.rssFeeds
 	if len(rssFeeds) == 0 {
-		rssFeeds = []string{
-			"https://www.coindesk.com/arc/outboundfeeds/rss/",
-			"https://cointelegraph.com/rss",
-			"https://cryptonews.com/news/feed/",
-		}
+		rssFeeds = ai.DefaultRSSFeeds
 	}
 
 	for _, feedURL := range rssFeeds {
-		method := dsmValue.MethodByName("FetchRSSFeed")
-		if !method.IsValid() {
-			continue
-		}
-
-		results := method.Call([]reflect.Value{reflect.ValueOf(feedURL)})
-		if len(results) != 2 {
-			continue
-		}
-
-		if !results[1].IsNil() {
+		items, err := a.dsm.FetchRSSFeed(feedURL)
+		if err != nil {
 			// 错误，跳过这个源
 			continue
 		}
 
-		itemsValue := results[0]
-		if itemsValue.IsNil() {
-			continue
-		}
-
-		// 转换为[]NewsItem（ai包中的类型）
-		items := itemsValue.Interface()
-		itemsSlice := reflect.ValueOf(items)
-		if itemsSlice.Kind() != reflect.Slice {
-			continue
-		}
-
-		rssItems := make([]RSSItemInfo, 0)
-		for i := 0; i < itemsSlice.Len(); i++ {
-			item := itemsSlice.Index(i)
-			if !item.IsValid() {
-				continue
-			}
-
-			// 提取字段
-			title := getFieldString(item, "Title")
-			description := getFieldString(item, "Description")
-			url := getFieldString(item, "URL")
-			source := getFieldString(item, "Source")
-			pubDate := getFieldTime(item, "PublishedAt")
-
+		rssItems := make([]RSSItemInfo, 0, len(items))
+		for _, item := range items {
 			rssItems = append(rssItems, RSSItemInfo{
-				Title:       title,
-				Description: description,
-				Link:        url,
-				PubDate:     pubDate,
-				Source:      source,
+				Title:       item.Title,
+				Description: item.Description,
+				Link:        item.URL,
+				PubDate:     item.PublishedAt,
+				Source:      item.Source,
 			})
 		}
 
@@ -3307,38 +3430,21 @@ func (a *dataSourceAdapter) GetFearGreedIndex() (*FearGreedIndexInfo, error) {
 
 	apiURL := a.fearGreedAPIURL
 	if apiURL == "" {
-		apiURL = "https://api.alternative.me/fng/"
-	}
-
-	dsmValue := reflect.ValueOf(a.dsm)
-	method := dsmValue.MethodByName("FetchFearGreedIndex")
-	if !method.IsValid() {
-		return nil, fmt.Errorf("方法不存在")
-	}
-
-	results := method.Call([]reflect.Value{reflect.ValueOf(apiURL)})
-	if len(results) != 2 {
-		return nil, fmt.Errorf("返回值数量错误")
+		apiURL = ai.DefaultFearGreedAPIURL
 	}
 
-	if !results[1].IsNil() {
-		return nil, results[1].Interface().(error)
+	index, err := a.dsm.FetchFearGreedIndex(apiURL)
+	if err != nil {
+		return nil, err
 	}
-
-	indexValue := results[0]
-	if indexValue.IsNil() {
+	if index == nil {
 		return nil, fmt.Errorf("返回值为空")
 	}
 
-	index := indexValue.Elem()
-	value := int(getFieldInt(index, "Value"))
-	classification := getFieldString(index, "Classification")
-	timestamp := getFieldTime(index, "Timestamp")
-
 	return &FearGreedIndexInfo{
-		Value:          value,
-		Classification: classification,
-		Timestamp:      timestamp,
+		Value:          index.Value,
+		Classification: index.Classification,
+		Timestamp:      index.Timestamp,
 	}, nil
 }
 
@@ -3349,54 +3455,25 @@ func (a *dataSourceAdapter) GetRedditPosts(subreddits []string, limit int) ([]Re
 	}
 
 	if len(subreddits) == 0 {
-		subreddits = []string{"Bitcoin", "ethereum", "CryptoCurrency", "CryptoMarkets"}
-	}
-
-	dsmValue := reflect.ValueOf(a.dsm)
-	method := dsmValue.MethodByName("FetchRedditPosts")
-	if !method.IsValid() {
-		return nil, fmt.Errorf("方法不存在")
-	}
-
-	results := method.Call([]reflect.Value{
-		reflect.ValueOf(subreddits),
-		reflect.ValueOf(limit),
-	})
-
-	if len(results) != 2 {
-		return nil, fmt.Errorf("返回值数量错误")
-	}
-
-	if !results[1].IsNil() {
-		return nil, results[1].Interface().(error)
-	}
-
-	postsValue := results[0]
-	if postsValue.IsNil() {
-		return []RedditPostInfo{}, nil
+		subreddits = ai.DefaultSubreddits
 	}
 
-	postsSlice := reflect.ValueOf(postsValue.Interface())
-	if postsSlice.Kind() != reflect.Slice {
-		return []RedditPostInfo{}, nil
+	result, err := a.dsm.FetchRedditPosts(subreddits, limit)
+	if err != nil {
+		return nil, err
 	}
 
-	posts := make([]RedditPostInfo, 0)
-	for i := 0; i < postsSlice.Len(); i++ {
-		post := postsSlice.Index(i)
-		if !post.IsValid() {
-			continue
-		}
-
+	posts := make([]RedditPostInfo, 0, len(result))
+	for _, post := range result {
 		posts = append(posts, RedditPostInfo{
-			Title:       getFieldString(post, "Title"),
-			Content:     getFieldString(post, "Content"),
-			URL:         getFieldString(post, "URL"),
-			Subreddit:   getFieldString(post, "Subreddit"),
-			Score:       int(getFieldInt(post, "Score")),
-			UpvoteRatio: getFieldFloat(post, "UpvoteRatio"),
-			CreatedAt:   getFieldTime(post, "CreatedAt"),
-			Author:      getFieldString(post, "Author"),
+			Title:       post.Title,
+			Content:     post.Content,
+			URL:         post.URL,
+			Subreddit:   post.Subreddit,
+			Score:       post.Score,
+			UpvoteRatio: post.UpvoteRatio,
+			CreatedAt:   post.CreatedAt,
+			Author:      post.Author,
 		})
 	}
 
@@ -3411,109 +3488,40 @@ func (a *dataSourceAdapter) GetPolymarketMarkets(keywords []string) ([]Polymarke
 
 	apiURL := a.polymarketAPIURL
 	if apiURL == "" {
-		apiURL = "https://api.polymarket.com/graphql"
-	}
-
-	dsmValue := reflect.ValueOf(a.dsm)
-	method := dsmValue.MethodByName("FetchPolymarketMarkets")
-	if !method.IsValid() {
-		return nil, fmt.Errorf("方法不存在")
-	}
-
-	results := method.Call([]reflect.Value{
-		reflect.ValueOf(apiURL),
-		reflect.ValueOf(keywords),
-	})
-
-	if len(results) != 2 {
-		return nil, fmt.Errorf("返回值数量错误")
-	}
-
-	if !results[1].IsNil() {
-		return nil, results[1].Interface().(error)
+		apiURL = ai.DefaultPolymarketAPIURL
 	}
 
-	marketsValue := results[0]
-	if marketsValue.IsNil() {
-		return []PolymarketMarketInfo{}, nil
-	}
-
-	marketsSlice := reflect.ValueOf(marketsValue.Interface())
-	if marketsSlice.Kind() != reflect.Slice {
-		return []PolymarketMarketInfo{}, nil
+	result, err := a.dsm.FetchPolymarketMarkets(apiURL, keywords)
+	if err != nil {
+		return nil, err
 	}
 
-	markets := make([]PolymarketMarketInfo, 0)
-	for i := 0; i < marketsSlice.Len(); i++ {
-		market := marketsSlice.Index(i)
-		if !market.IsValid() {
-			continue
-		}
-
-		// 处理指针类型
-		if market.Kind() == reflect.Ptr {
-			market = market.Elem()
+	markets := make([]PolymarketMarketInfo, 0, len(result))
+	for _, market := range result {
+		outcomes := market.Outcomes
+		if outcomes == nil {
+			outcomes = []string{}
 		}
-
-		outcomesValue := market.FieldByName("Outcomes")
-		outcomes := []string{}
-		if outcomesValue.IsValid() && outcomesValue.Kind() == reflect.Slice {
-			for j := 0; j < outcomesValue.Len(); j++ {
-				outcomes = append(outcomes, outcomesValue.Index(j).String())
-			}
-		}
-
 		markets = append(markets, PolymarketMarketInfo{
-			ID:          getFieldString(market, "ID"),
-			Question:    getFieldString(market, "Question"),
-			Description: getFieldString(market, "Description"),
-			EndDate:     getFieldTime(market, "EndDate"),
+			ID:          market.ID,
+			Question:    market.Question,
+			Description: market.Description,
+			EndDate:     market.EndDate,
 			Outcomes:    outcomes,
-			Volume:      getFieldFloat(market, "Volume"),
-			Liquidity:   getFieldFloat(market, "Liquidity"),
+			Volume:      market.Volume,
+			Liquidity:   market.Liquidity,
 		})
 	}
 
 	return markets, nil
 }
 
-// 辅助函数：从反射值获取字符串字段
-func getFieldString(v reflect.Value, fieldName string) string {
-	field := v.FieldByName(fieldName)
-	if !field.IsValid() {
-		return ""
-	}
-	return field.String()
-}
-
-// 辅助函数：从反射值获取整数字段
-func getFieldInt(v reflect.Value, fieldName string) int64 {
-	field := v.FieldByName(fieldName)
-	if !field.IsValid() {
-		return 0
-	}
-	return field.Int()
-}
-
-// 辅助函数：从反射值获取浮点数字段
-func getFieldFloat(v reflect.Value, fieldName string) float64 {
-	field := v.FieldByName(fieldName)
-	if !field.IsValid() {
-		return 0
-	}
-	return field.Float()
-}
-
-// 辅助函数：从反射值获取时间字段
-func getFieldTime(v reflect.Value, fieldName string) time.Time {
-	field := v.FieldByName(fieldName)
-	if !field.IsValid() {
-		return time.Now()
-	}
-	if t, ok := field.Interface().(time.Time); ok {
-		return t
+// GetOnChain 获取链上数据快照
+func (a *dataSourceAdapter) GetOnChain() (*ai.OnChainSnapshot, error) {
+	if a.dsm == nil {
+		return nil, fmt.Errorf("数据源管理器未初始化")
 	}
-	return time.Now()
+	return a.dsm.FetchOnChain()
 }
 
 // 辅助函数：从URL提取源名称
@@ -3530,16 +3538,18 @@ func extractSourceName(url string) string {
 // getMarketIntelligence 获取市场情报数据
 // GET /api/market-intelligence
 // 查询参数：
-//   - source: 数据源类型（rss, fear_greed, reddit, polymarket，默认全部）
+//   - source: 数据源类型（rss, fear_greed, reddit, polymarket, on_chain，默认全部）
 //   - keyword: 搜索关键词（可选）
 //   - limit: 返回数量限制（默认50）
 func getMarketIntelligence(c *gin.Context) {
+	dataSourceProvider := providersOf(c).DataSource
 	if dataSourceProvider == nil {
 		c.JSON(http.StatusOK, gin.H{
 			"rss_feeds":    []interface{}{},
 			"fear_greed":   nil,
 			"reddit_posts": []interface{}{},
 			"polymarket":   []interface{}{},
+			"on_chain":     nil,
 		})
 		return
 	}
@@ -3606,9 +3616,8 @@ func getMarketIntelligence(c *gin.Context) {
 
 	// 获取Reddit帖子
 	if source == "" || source == "reddit" {
-		// 默认子版块
-		subreddits := []string{"Bitcoin", "ethereum", "CryptoCurrency", "CryptoMarkets"}
-		redditPosts, err := dataSourceProvider.GetRedditPosts(subreddits, limit)
+		// 使用默认子版块
+		redditPosts, err := dataSourceProvider.GetRedditPosts(nil, limit)
 		if err == nil {
 			// 如果有关键词，进行筛选
 			if keyword != "" {
@@ -3648,6 +3657,16 @@ func getMarketIntelligence(c *gin.Context) {
 		}
 	}
 
+	// 获取链上数据（未启用时为 null）
+	if source == "" || source == "on_chain" {
+		onChain, err := dataSourceProvider.GetOnChain()
+		if err == nil {
+			result["on_chain"] = onChain
+		} else {
+			result["on_chain"] = nil
+		}
+	}
+
 	c.JSON(http.StatusOK, result)
 }
 
@@ -3661,16 +3680,6 @@ func min(a, b int) int {
 
 // ========== AI分析相关API ==========
 
-var (
-	// AI模块提供者（需要从main.go注入）
-	aiMarketAnalyzerProvider     AIMarketAnalyzerProvider
-	aiParameterOptimizerProvider AIParameterOptimizerProvider
-	aiRiskAnalyzerProvider       AIRiskAnalyzerProvider
-	aiSentimentAnalyzerProvider  AISentimentAnalyzerProvider
-	aiPolymarketSignalProvider   AIPolymarketSignalProvider
-	aiPromptManagerProvider      AIPromptManagerProvider
-)
-
 // AI提供者接口
 type AIMarketAnalyzerProvider interface {
 	GetLastAnalysis() interface{}
@@ -3702,39 +3711,49 @@ type AIPolymarketSignalProvider interface {
 	PerformAnalysis() error
 }
 
+// AIPromptManagerProvider 提示词管理提供者接口（带版本历史，见 ai.PromptManager）
 type AIPromptManagerProvider interface {
-	GetAllPrompts() (map[string]interface{}, error)
-	UpdatePrompt(module, template, systemPrompt string) error
+	GetAllPrompts() (map[string]*ai.PromptInfo, error)
+	UpdatePrompt(module, template, systemPrompt, author, comment string) (*storage.AIPromptVersion, error)
+	History(module string, limit int) ([]*storage.AIPromptVersion, error)
+	Diff(module string, from, to int) (*ai.PromptDiff, error)
+	Rollback(module string, version int, author string) (*storage.AIPromptVersion, error)
+	Pin(module string, version int) error
 }
 
 // SetAIProviders 设置AI提供者
 func SetAIMarketAnalyzerProvider(provider AIMarketAnalyzerProvider) {
-	aiMarketAnalyzerProvider = provider
+	defaultProviders.AIMarketAnalyzer = provider
 }
 
 func SetAIParameterOptimizerProvider(provider AIParameterOptimizerProvider) {
-	aiParameterOptimizerProvider = provider
+	defaultProviders.AIParameterOptimizer = provider
 }
 
 func SetAIRiskAnalyzerProvider(provider AIRiskAnalyzerProvider) {
-	aiRiskAnalyzerProvider = provider
+	defaultProviders.AIRiskAnalyzer = provider
 }
 
 func SetAISentimentAnalyzerProvider(provider AISentimentAnalyzerProvider) {
-	aiSentimentAnalyzerProvider = provider
+	defaultProviders.AISentimentAnalyzer = provider
 }
 
 func SetAIPolymarketSignalProvider(provider AIPolymarketSignalProvider) {
-	aiPolymarketSignalProvider = provider
+	defaultProviders.AIPolymarketSignal = provider
 }
 
 func SetAIPromptManagerProvider(provider AIPromptManagerProvider) {
-	aiPromptManagerProvider = provider
+	defaultProviders.AIPromptManager = provider
 }
 
 // getAIAnalysisStatus 获取AI系统状态
 // GET /api/ai/status
 func getAIAnalysisStatus(c *gin.Context) {
+	aiMarketAnalyzerProvider := providersOf(c).AIMarketAnalyzer
+	aiParameterOptimizerProvider := providersOf(c).AIParameterOptimizer
+	aiRiskAnalyzerProvider := providersOf(c).AIRiskAnalyzer
+	aiSentimentAnalyzerProvider := providersOf(c).AISentimentAnalyzer
+	aiPolymarketSignalProvider := providersOf(c).AIPolymarketSignal
 	status := map[string]interface{}{
 		"enabled": true,
 		"modules": map[string]interface{}{
@@ -3808,6 +3827,7 @@ func getAIAnalysisStatus(c *gin.Context) {
 // getAIMarketAnalysis 获取市场分析结果
 // GET /api/ai/analysis/market
 func getAIMarketAnalysis(c *gin.Context) {
+	aiMarketAnalyzerProvider := providersOf(c).AIMarketAnalyzer
 	if aiMarketAnalyzerProvider == nil {
 		c.JSON(http.StatusOK, gin.H{"error": "市场分析模块未启用"})
 		return
@@ -3825,6 +3845,7 @@ func getAIMarketAnalysis(c *gin.Context) {
 // getAIParameterOptimization 获取参数优化结果
 // GET /api/ai/analysis/parameter
 func getAIParameterOptimization(c *gin.Context) {
+	aiParameterOptimizerProvider := providersOf(c).AIParameterOptimizer
 	if aiParameterOptimizerProvider == nil {
 		c.JSON(http.StatusOK, gin.H{"error": "参数优化模块未启用"})
 		return
@@ -3842,6 +3863,7 @@ func getAIParameterOptimization(c *gin.Context) {
 // getAIRiskAnalysis 获取风险分析结果
 // GET /api/ai/analysis/risk
 func getAIRiskAnalysis(c *gin.Context) {
+	aiRiskAnalyzerProvider := providersOf(c).AIRiskAnalyzer
 	if aiRiskAnalyzerProvider == nil {
 		c.JSON(http.StatusOK, gin.H{"error": "风险分析模块未启用"})
 		return
@@ -3859,6 +3881,7 @@ func getAIRiskAnalysis(c *gin.Context) {
 // getAISentimentAnalysis 获取情绪分析结果
 // GET /api/ai/analysis/sentiment
 func getAISentimentAnalysis(c *gin.Context) {
+	aiSentimentAnalyzerProvider := providersOf(c).AISentimentAnalyzer
 	if aiSentimentAnalyzerProvider == nil {
 		c.JSON(http.StatusOK, gin.H{"error": "情绪分析模块未启用"})
 		return
@@ -3876,6 +3899,7 @@ func getAISentimentAnalysis(c *gin.Context) {
 // getAIPolymarketSignal 获取Polymarket信号分析结果
 // GET /api/ai/analysis/polymarket
 func getAIPolymarketSignal(c *gin.Context) {
+	aiPolymarketSignalProvider := providersOf(c).AIPolymarketSignal
 	if aiPolymarketSignalProvider == nil {
 		c.JSON(http.StatusOK, gin.H{"error": "Polymarket信号模块未启用"})
 		return
@@ -3893,6 +3917,11 @@ func getAIPolymarketSignal(c *gin.Context) {
 // triggerAIAnalysis 手动触发AI分析
 // POST /api/ai/analysis/trigger/:module
 func triggerAIAnalysis(c *gin.Context) {
+	aiMarketAnalyzerProvider := providersOf(c).AIMarketAnalyzer
+	aiParameterOptimizerProvider := providersOf(c).AIParameterOptimizer
+	aiRiskAnalyzerProvider := providersOf(c).AIRiskAnalyzer
+	aiSentimentAnalyzerProvider := providersOf(c).AISentimentAnalyzer
+	aiPolymarketSignalProvider := providersOf(c).AIPolymarketSignal
 	module := c.Param("module")
 	var err error
 
@@ -3948,8 +3977,9 @@ func triggerAIAnalysis(c *gin.Context) {
 // getAIPrompts 获取所有提示词模板
 // GET /api/ai/prompts
 func getAIPrompts(c *gin.Context) {
+	aiPromptManagerProvider := providersOf(c).AIPromptManager
 	if aiPromptManagerProvider == nil {
-		c.JSON(http.StatusOK, gin.H{"prompts": map[string]interface{}{}})
+		c.JSON(http.StatusOK, gin.H{"prompts": map[string]*ai.PromptInfo{}})
 		return
 	}
 
@@ -3962,9 +3992,10 @@ func getAIPrompts(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"prompts": prompts})
 }
 
-// updateAIPrompt 更新提示词模板
+// updateAIPrompt 更新提示词模板（追加新版本，记录作者）
 // POST /api/ai/prompts
 func updateAIPrompt(c *gin.Context) {
+	aiPromptManagerProvider := providersOf(c).AIPromptManager
 	if aiPromptManagerProvider == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "提示词管理器未启用"})
 		return
@@ -3974,6 +4005,7 @@ func updateAIPrompt(c *gin.Context) {
 		Module       string `json:"module"`
 		Template     string `json:"template"`
 		SystemPrompt string `json:"system_prompt"`
+		Comment      string `json:"comment"` // 修改说明
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -3991,12 +4023,15 @@ func updateAIPrompt(c *gin.Context) {
 		return
 	}
 
-	if err := aiPromptManagerProvider.UpdatePrompt(req.Module, req.Template, req.SystemPrompt); err != nil {
+	version, err := aiPromptManagerProvider.UpdatePrompt(req.Module, req.Template, req.SystemPrompt, c.GetString("username"), req.Comment)
+	if err != nil {
+		LogAction(c, "ai_prompt_update", req.Module, gin.H{"comment": req.Comment}, "failed", err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	LogAction(c, "ai_prompt_update", req.Module, gin.H{"version": version.Version, "comment": req.Comment}, "success", "")
 
-	c.JSON(http.StatusOK, gin.H{"message": "提示词已更新"})
+	c.JSON(http.StatusOK, gin.H{"message": "提示词已更新", "version": version})
 }
 
 // AI模块适配器
@@ -4100,21 +4135,6 @@ func (a *aiPolymarketSignalAdapter) PerformAnalysis() error {
 	return a.analyzer.PerformAnalysis()
 }
 
-type aiPromptManagerAdapter struct {
-	manager interface {
-		GetAllPrompts() (map[string]interface{}, error)
-		UpdatePrompt(module, template, systemPrompt string) error
-	}
-}
-
-func (a *aiPromptManagerAdapter) GetAllPrompts() (map[string]interface{}, error) {
-	return a.manager.GetAllPrompts()
-}
-
-func (a *aiPromptManagerAdapter) UpdatePrompt(module, template, systemPrompt string) error {
-	return a.manager.UpdatePrompt(module, template, systemPrompt)
-}
-
 // ==================== 价差监控 API ====================
 
 // BasisMonitorProvider 价差监控提供者接口
@@ -4125,29 +4145,26 @@ type BasisMonitorProvider interface {
 	GetBasisStatistics(symbol string, hours int) (*storage.BasisStats, error)
 }
 
-var (
-	basisMonitorProvider BasisMonitorProvider
-	basisMonitorMu       sync.RWMutex
-)
+var basisMonitorMu sync.RWMutex
 
 // SetBasisMonitorProvider 设置价差监控提供者
 func SetBasisMonitorProvider(provider BasisMonitorProvider) {
 	basisMonitorMu.Lock()
 	defer basisMonitorMu.Unlock()
-	basisMonitorProvider = provider
+	defaultProviders.BasisMonitor = provider
 }
 
 // getBasisMonitorProvider 获取价差监控提供者
-func getBasisMonitorProvider() BasisMonitorProvider {
+func getBasisMonitorProvider(c *gin.Context) BasisMonitorProvider {
 	basisMonitorMu.RLock()
 	defer basisMonitorMu.RUnlock()
-	return basisMonitorProvider
+	return providersOf(c).BasisMonitor
 }
 
 // getBasisCurrent 获取当前价差数据
 // GET /api/basis/current?symbol=BTCUSDT
 func getBasisCurrent(c *gin.Context) {
-	provider := getBasisMonitorProvider()
+	provider := getBasisMonitorProvider(c)
 	if provider == nil {
 		respondError(c, http.StatusServiceUnavailable, "errors.service_unavailable")
 		return
@@ -4177,7 +4194,7 @@ func getBasisCurrent(c *gin.Context) {
 // getBasisHistory 获取价差历史数据
 // GET /api/basis/history?symbol=BTCUSDT&limit=100
 func getBasisHistory(c *gin.Context) {
-	provider := getBasisMonitorProvider()
+	provider := getBasisMonitorProvider(c)
 	if provider == nil {
 		respondError(c, http.StatusServiceUnavailable, "errors.service_unavailable")
 		return
@@ -4212,7 +4229,7 @@ func getBasisHistory(c *gin.Context) {
 // getBasisStatistics 获取价差统计数据
 // GET /api/basis/statistics?symbol=BTCUSDT&hours=24
 func getBasisStatistics(c *gin.Context) {
-	provider := getBasisMonitorProvider()
+	provider := getBasisMonitorProvider(c)
 	if provider == nil {
 		respondError(c, http.StatusServiceUnavailable, "errors.service_unavailable")
 		return
@@ -4244,6 +4261,7 @@ func getBasisStatistics(c *gin.Context) {
 // getAllocationStatus 获取资金分配状态
 // GET /api/allocation/status
 func getAllocationStatus(c *gin.Context) {
+	symbolManagerProvider := providersOf(c).SymbolManager
 	if symbolManagerProvider == nil {
 		respondError(c, http.StatusServiceUnavailable, "error.symbol_manager_unavailable")
 		return
@@ -4313,6 +4331,7 @@ func getAllocationStatus(c *gin.Context) {
 // getAllocationStatusBySymbol 获取指定交易对的资金分配状态
 // GET /api/allocation/status/:exchange/:symbol
 func getAllocationStatusBySymbol(c *gin.Context) {
+	symbolManagerProvider := providersOf(c).SymbolManager
 	exchange := c.Param("exchange")
 	symbol := c.Param("symbol")
 	
@@ -4397,6 +4416,7 @@ type SymbolCapitalRequest struct {
 // generateAIConfig 生成 AI 配置建议
 // POST /api/ai/generate-config
 func generateAIConfig(c *gin.Context) {
+	symbolManagerProvider := providersOf(c).SymbolManager
 	var req struct {
 		Exchange       string                 `json:"exchange"`
 		Symbols        []string               `json:"symbols"`
@@ -4574,22 +4594,48 @@ func generateAIConfig(c *gin.Context) {
 			return
 		}
 
+		// 审核模式：生成结果保存为待审批提案
+		if aiConfigReviewEnabled() {
+			proposal, err := configService.ProposeAIConfig(aiProposalStore(), "generate-config", task.TaskID, aiConfig)
+			if err != nil {
+				logger.Error("❌ [AI任务] %s 创建配置提案失败: %v", task.TaskID, err)
+				aiTaskManager.UpdateTask(task.TaskID, TaskStatusFailed, nil, err)
+				return
+			}
+			aiTaskManager.SetProposal(task.TaskID, proposal.ID)
+			logger.Info("📝 [AI任务] %s 已创建待审批配置提案 %s", task.TaskID, proposal.ID)
+		}
+
 		// 更新任务状态为完成
 		logger.Info("✅ [AI任务] %s 配置生成完成，更新任务状态为 completed", task.TaskID)
 		aiTaskManager.UpdateTask(task.TaskID, TaskStatusCompleted, aiConfig, nil)
 	}()
 }
 
+// aiConfigErrorStatus 配置未通过校验或超出护栏时返回 400，其他错误返回 500
+func aiConfigErrorStatus(err error) int {
+	var validationErr *ai.ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
 // getAITaskStatus 获取 AI 任务状态
 // GET /api/ai/task/:task_id
 func getAITaskStatus(c *gin.Context) {
+	respondAITaskStatus(c, aiTaskManager)
+}
+
+// respondAITaskStatus 从指定任务管理器中查询任务并返回状态
+func respondAITaskStatus(c *gin.Context, manager *AITaskManager) {
 	taskID := c.Param("task_id")
 	if taskID == "" {
 		respondError(c, http.StatusBadRequest, "error.missing_task_id")
 		return
 	}
 
-	task, ok := aiTaskManager.GetTask(taskID)
+	task, ok := manager.GetTask(taskID)
 	if !ok {
 		respondError(c, http.StatusNotFound, "error.task_not_found")
 		return
@@ -4614,6 +4660,9 @@ func getAITaskStatus(c *gin.Context) {
 	if task.Status == TaskStatusFailed && task.Error != "" {
 		response["error"] = task.Error
 	}
+	if task.ProposalID != "" {
+		response["proposal_id"] = task.ProposalID
+	}
 
 	c.JSON(http.StatusOK, response)
 }
@@ -4641,11 +4690,28 @@ func applyAIConfig(c *gin.Context) {
 
 	configPath := configManager.GetConfigPath()
 	configService := ai.NewConfigService(configPath)
+
+	// 审核模式：不直接写入，保存为待审批提案
+	if aiConfigReviewEnabled() {
+		proposal, err := configService.ProposeAIConfig(aiProposalStore(), "apply-config", "", &req)
+		if err != nil {
+			respondError(c, aiConfigErrorStatus(err), "error.ai_proposal_failed", err)
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":  "审核模式已开启，配置已保存为待审批提案，批准后才会写入配置文件",
+			"proposal": proposal,
+		})
+		return
+	}
+
 	if err := configService.ApplyAIConfig(&req, cfg); err != nil {
 		logger.Error("❌ 应用 AI 配置失败: %v", err)
-		respondError(c, http.StatusInternalServerError, "error.apply_config_failed", err)
+		respondError(c, aiConfigErrorStatus(err), "error.apply_config_failed", err)
 		return
 	}
+	recordJournal(c, cfg.App.CurrentExchange, "", "应用 AI 生成配置",
+		fmt.Sprintf("已应用 %d 个交易对的网格参数（重启后生效）：%s", len(req.GridConfig), req.Explanation))
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "配置已成功应用，请重启服务使配置生效",
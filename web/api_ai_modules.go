@@ -0,0 +1,134 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/ai"
+	"quantmesh/config"
+)
+
+// AIModuleSchedulerProvider AI 模块调度提供者接口（需要从 main.go 注入）
+type AIModuleSchedulerProvider interface {
+	Status() []ai.ModuleStatus
+	UpdateSchedule(module string, schedule config.AIModuleSchedule) (ai.ModuleStatus, error)
+}
+
+// SetAIModuleSchedulerProvider 设置 AI 模块调度提供者
+func SetAIModuleSchedulerProvider(provider AIModuleSchedulerProvider) {
+	defaultProviders.AIModuleScheduler = provider
+}
+
+// AIModuleUpdateRequest 更新 AI 模块调度请求，未提供的字段保持不变
+type AIModuleUpdateRequest struct {
+	Enabled        *bool   `json:"enabled"`
+	Interval       *int    `json:"interval"`
+	Cron           *string `json:"cron"`
+	MaxConcurrency *int    `json:"max_concurrency"`
+}
+
+// getAIModules 获取各 AI 模块的调度设置与运行状态
+// GET /api/ai/modules
+func getAIModules(c *gin.Context) {
+	provider := providersOf(c).AIModuleScheduler
+	if provider == nil {
+		c.JSON(http.StatusOK, gin.H{"modules": []ai.ModuleStatus{}})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"modules": provider.Status()})
+}
+
+// updateAIModule 更新 AI 模块的调度（间隔/cron、启停、并发），写入 config.yaml 后立即生效，无需重启
+// PUT /api/ai/modules/:module
+func updateAIModule(c *gin.Context) {
+	provider := providersOf(c).AIModuleScheduler
+	module := c.Param("module")
+	if !config.IsAIModule(module) {
+		respondError(c, http.StatusNotFound, "error.ai_module_schedule_failed", fmt.Errorf("未知的 AI 模块: %s", module))
+		return
+	}
+	if provider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.ai_module_schedule_failed", fmt.Errorf("AI 调度器未初始化"))
+		return
+	}
+
+	var req AIModuleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+
+	var schedule config.AIModuleSchedule
+	for _, st := range provider.Status() {
+		if st.Module == module {
+			schedule = st.Schedule
+		}
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+	if req.Interval != nil {
+		schedule.Interval = *req.Interval
+	}
+	if req.Cron != nil {
+		schedule.Cron = *req.Cron
+	}
+	if req.MaxConcurrency != nil {
+		schedule.MaxConcurrency = *req.MaxConcurrency
+	}
+	if err := schedule.Validate(); err != nil {
+		respondError(c, http.StatusBadRequest, "error.ai_module_schedule_failed", err)
+		return
+	}
+
+	// 先持久化，保证重启后设置不丢失
+	if configManager != nil {
+		if err := persistAIModuleSchedule(module, schedule); err != nil {
+			LogAction(c, "ai_module_update", module, schedule, "failed", err.Error())
+			respondError(c, http.StatusInternalServerError, "error.ai_module_schedule_failed", err)
+			return
+		}
+	}
+
+	status, err := provider.UpdateSchedule(module, schedule)
+	if err != nil {
+		LogAction(c, "ai_module_update", module, schedule, "failed", err.Error())
+		respondError(c, http.StatusBadRequest, "error.ai_module_schedule_failed", err)
+		return
+	}
+	LogAction(c, "ai_module_update", module, schedule, "success", "")
+	exchange := ""
+	if globalConfig != nil {
+		exchange = globalConfig.App.CurrentExchange
+	}
+	recordJournal(c, exchange, "", "AI 模块调度更新", fmt.Sprintf("%s: enabled=%v interval=%ds cron=%q max_concurrency=%d",
+		module, schedule.Enabled, schedule.Interval, schedule.Cron, schedule.MaxConcurrency))
+
+	c.JSON(http.StatusOK, status)
+}
+
+// persistAIModuleSchedule 写入 ai.scheduling 并备份原配置
+func persistAIModuleSchedule(module string, schedule config.AIModuleSchedule) error {
+	cfg, err := configManager.GetConfig()
+	if err != nil {
+		return fmt.Errorf("读取配置失败: %w", err)
+	}
+	if cfg.AI.Scheduling == nil {
+		cfg.AI.Scheduling = make(map[string]config.AIModuleSchedule)
+	}
+	cfg.AI.Scheduling[module] = schedule
+
+	if configBackupMgr != nil {
+		if _, err := configBackupMgr.CreateBackup(configManager.GetConfigPath(), "更新 AI 模块调度: "+module); err != nil {
+			return fmt.Errorf("创建备份失败: %w", err)
+		}
+	}
+	if err := configManager.UpdateConfig(cfg); err != nil {
+		return fmt.Errorf("保存配置失败: %w", err)
+	}
+	if configHotReloader != nil {
+		configHotReloader.UpdateConfig(cfg)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AIPromptVersionRequest 回滚/固定提示词版本请求
+type AIPromptVersionRequest struct {
+	Version int `json:"version"` // 固定时 0 表示取消固定
+}
+
+// getAIPromptVersions 获取提示词版本历史（新版本在前）
+// GET /api/ai/prompts/:module/versions?limit=50
+func getAIPromptVersions(c *gin.Context) {
+	provider := providersOf(c).AIPromptManager
+	if provider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.ai_prompt_failed", fmt.Errorf("提示词管理器未启用"))
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	versions, err := provider.History(c.Param("module"), limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "error.ai_prompt_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"module": c.Param("module"), "versions": versions})
+}
+
+// diffAIPromptVersions 比较两个提示词版本，to 省略时与当前版本比较
+// GET /api/ai/prompts/:module/diff?from=3&to=5
+func diffAIPromptVersions(c *gin.Context) {
+	provider := providersOf(c).AIPromptManager
+	if provider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.ai_prompt_failed", fmt.Errorf("提示词管理器未启用"))
+		return
+	}
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil || from <= 0 {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("from 必须为正整数版本号"))
+		return
+	}
+	to := 0
+	if v := c.Query("to"); v != "" {
+		if to, err = strconv.Atoi(v); err != nil || to <= 0 {
+			respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("to 必须为正整数版本号"))
+			return
+		}
+	}
+	diff, err := provider.Diff(c.Param("module"), from, to)
+	if err != nil {
+		respondError(c, http.StatusNotFound, "error.ai_prompt_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+// rollbackAIPrompt 回滚提示词到历史版本（以该版本内容追加新版本，历史保留）
+// POST /api/ai/prompts/:module/rollback
+func rollbackAIPrompt(c *gin.Context) {
+	provider := providersOf(c).AIPromptManager
+	if provider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.ai_prompt_failed", fmt.Errorf("提示词管理器未启用"))
+		return
+	}
+	var req AIPromptVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Version <= 0 {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("version 必须为正整数版本号"))
+		return
+	}
+	module := c.Param("module")
+	version, err := provider.Rollback(module, req.Version, c.GetString("username"))
+	if err != nil {
+		LogAction(c, "ai_prompt_rollback", module, req, "failed", err.Error())
+		respondError(c, http.StatusBadRequest, "error.ai_prompt_failed", err)
+		return
+	}
+	LogAction(c, "ai_prompt_rollback", module, gin.H{"from_version": req.Version, "new_version": version.Version}, "success", "")
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("已回滚到 v%d（新版本 v%d）", req.Version, version.Version), "version": version})
+}
+
+// pinAIPrompt 将分析器固定到指定提示词版本，version 为 0 时取消固定
+// PUT /api/ai/prompts/:module/pin
+func pinAIPrompt(c *gin.Context) {
+	provider := providersOf(c).AIPromptManager
+	if provider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.ai_prompt_failed", fmt.Errorf("提示词管理器未启用"))
+		return
+	}
+	var req AIPromptVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	module := c.Param("module")
+	if err := provider.Pin(module, req.Version); err != nil {
+		LogAction(c, "ai_prompt_pin", module, req, "failed", err.Error())
+		respondError(c, http.StatusBadRequest, "error.ai_prompt_failed", err)
+		return
+	}
+	LogAction(c, "ai_prompt_pin", module, req, "success", "")
+	c.JSON(http.StatusOK, gin.H{"module": module, "pinned_version": req.Version})
+}
@@ -0,0 +1,131 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/ai"
+)
+
+// aiProposalsPath AI 配置提案持久化文件
+const aiProposalsPath = "./data/ai_config_proposals.json"
+
+var (
+	aiProposalsOnce sync.Once
+	aiProposals     *ai.ProposalStore
+)
+
+// aiProposalStore 返回 AI 配置提案存储（首次使用时从文件加载）
+func aiProposalStore() *ai.ProposalStore {
+	aiProposalsOnce.Do(func() {
+		aiProposals = ai.NewProposalStore(aiProposalsPath)
+	})
+	return aiProposals
+}
+
+// aiConfigReviewEnabled 是否开启 AI 配置审核模式（读取当前配置文件，修改后立即生效）
+func aiConfigReviewEnabled() bool {
+	if configManager != nil {
+		if cfg, err := configManager.GetConfig(); err == nil {
+			return cfg.AI.ConfigReview
+		}
+	}
+	return globalConfig != nil && globalConfig.AI.ConfigReview
+}
+
+// AIProposalReviewRequest 审批提案请求
+type AIProposalReviewRequest struct {
+	Comment string `json:"comment"`
+}
+
+// listAIProposals 列出 AI 配置提案
+// GET /api/ai/proposals?status=pending|approved|rejected
+func listAIProposals(c *gin.Context) {
+	status := ai.ProposalStatus(strings.ToLower(c.Query("status")))
+	switch status {
+	case "", ai.ProposalPending, ai.ProposalApproved, ai.ProposalRejected:
+	default:
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("未知的提案状态: %s", status))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"review_mode": aiConfigReviewEnabled(),
+		"proposals":   aiProposalStore().List(status),
+	})
+}
+
+// getAIProposal 获取提案详情（说明、AI 生成的配置、与当前配置的差异）
+// GET /api/ai/proposals/:id
+func getAIProposal(c *gin.Context) {
+	proposal, ok := aiProposalStore().Get(c.Param("id"))
+	if !ok {
+		respondError(c, http.StatusNotFound, "error.ai_proposal_failed", fmt.Errorf("提案不存在: %s", c.Param("id")))
+		return
+	}
+	c.JSON(http.StatusOK, proposal)
+}
+
+// approveAIProposal 批准提案并写入 config.yaml（按当前配置重新合并，重启后生效）
+// POST /api/ai/proposals/:id/approve
+func approveAIProposal(c *gin.Context) {
+	if configManager == nil {
+		respondError(c, http.StatusInternalServerError, "error.config_manager_unavailable")
+		return
+	}
+	var req AIProposalReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	id := c.Param("id")
+
+	if configBackupMgr != nil {
+		if _, err := configBackupMgr.CreateBackup(configManager.GetConfigPath(), "批准 AI 配置提案: "+id); err != nil {
+			respondError(c, http.StatusInternalServerError, "error.ai_proposal_failed", fmt.Errorf("创建备份失败: %w", err))
+			return
+		}
+	}
+
+	configService := ai.NewConfigService(configManager.GetConfigPath())
+	proposal, err := configService.ApproveProposal(aiProposalStore(), id, c.GetString("username"), req.Comment)
+	if err != nil {
+		LogAction(c, "ai_proposal_approve", id, req, "failed", err.Error())
+		respondError(c, http.StatusBadRequest, "error.ai_proposal_failed", err)
+		return
+	}
+	LogAction(c, "ai_proposal_approve", id, gin.H{"changes": len(proposal.AppliedDiff), "comment": req.Comment}, "success", "")
+
+	exchange := ""
+	if globalConfig != nil {
+		exchange = globalConfig.App.CurrentExchange
+	}
+	recordJournal(c, exchange, "", "批准 AI 配置提案",
+		fmt.Sprintf("提案 %s：%d 项变更（重启后生效）：%s", id, len(proposal.AppliedDiff), proposal.Explanation))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "提案已批准并写入配置，请重启服务使配置生效",
+		"proposal": proposal,
+	})
+}
+
+// rejectAIProposal 拒绝提案，不修改配置
+// POST /api/ai/proposals/:id/reject
+func rejectAIProposal(c *gin.Context) {
+	var req AIProposalReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	id := c.Param("id")
+	proposal, err := aiProposalStore().Reject(id, c.GetString("username"), req.Comment)
+	if err != nil {
+		LogAction(c, "ai_proposal_reject", id, req, "failed", err.Error())
+		respondError(c, http.StatusBadRequest, "error.ai_proposal_failed", err)
+		return
+	}
+	LogAction(c, "ai_proposal_reject", id, req, "success", "")
+	c.JSON(http.StatusOK, gin.H{"proposal": proposal})
+}
@@ -0,0 +1,54 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/position"
+	"quantmesh/strategy"
+)
+
+// LevelsProvider 支撑/阻力位检测提供者接口（需要从 main.go 注入）
+type LevelsProvider interface {
+	// GetLevels 返回检测结果（尚未计算出结果时为 nil）和当前生效的买单加密区间；交易对未启用检测时返回错误
+	GetLevels(exchange, symbol string) (*strategy.LevelSnapshot, []position.PriceZone, error)
+}
+
+// SetLevelsProvider 设置支撑/阻力位检测提供者
+func SetLevelsProvider(provider LevelsProvider) {
+	defaultProviders.Levels = provider
+}
+
+// getAnalysisLevels 获取支撑/阻力位、最近的K线形态和网格加密区间
+// GET /api/analysis/levels?exchange=binance&symbol=BTCUSDT
+func getAnalysisLevels(c *gin.Context) {
+	levelsProvider := providersOf(c).Levels
+	exchangeName, symbol := c.Query("exchange"), strings.ToUpper(c.Query("symbol"))
+	if exchangeName == "" && globalConfig != nil {
+		exchangeName = globalConfig.App.CurrentExchange
+	}
+	if symbol == "" {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("需要 symbol"))
+		return
+	}
+	if levelsProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "errors.service_unavailable")
+		return
+	}
+
+	snap, zones, err := levelsProvider.GetLevels(exchangeName, symbol)
+	if err != nil {
+		respondError(c, http.StatusNotFound, "errors.not_found", err)
+		return
+	}
+	if zones == nil {
+		zones = []position.PriceZone{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ready":       snap != nil,
+		"levels":      snap,
+		"dense_zones": zones,
+	})
+}
@@ -0,0 +1,332 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/exchange"
+	"quantmesh/indicators"
+	"quantmesh/logger"
+	"quantmesh/storage"
+	"quantmesh/utils"
+)
+
+// getAnalyticsCorrelation 计算交易对的滚动波动率和两两相关系数
+// GET /api/analytics/correlation?symbols=BTCUSDT,ETHUSDT&interval=1d&lookback=30&window=7
+// symbols 可选（默认所有配置的交易对，可写作 exchange:symbol），lookback 为K线根数，window 为滚动波动率窗口
+func getAnalyticsCorrelation(c *gin.Context) {
+	capitalDataSource := providersOf(c).CapitalDataSource
+	if capitalDataSource == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.correlation_failed", fmt.Errorf("交易服务未就绪"))
+		return
+	}
+	cfg := capitalDataSource.GetConfig()
+
+	interval := c.DefaultQuery("interval", "1d")
+	lookback, err := strconv.Atoi(c.DefaultQuery("lookback", "30"))
+	if err != nil || lookback < 3 || lookback > 1000 {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("lookback 必须在 3-1000 之间"))
+		return
+	}
+	window, err := strconv.Atoi(c.DefaultQuery("window", "7"))
+	if err != nil || window < 2 || window >= lookback {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("window 必须不小于 2 且小于 lookback"))
+		return
+	}
+
+	// 交易对 -> 交易所名称
+	defaultExchange := ""
+	if cfg != nil {
+		defaultExchange = cfg.App.CurrentExchange
+	}
+	targets := make(map[string]string)
+	if raw := c.Query("symbols"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			exchangeName, symbol := defaultExchange, part
+			if idx := strings.Index(part, ":"); idx >= 0 {
+				exchangeName, symbol = part[:idx], part[idx+1:]
+			}
+			targets[strings.ToUpper(symbol)] = exchangeName
+		}
+	} else if cfg != nil {
+		for _, sym := range cfg.Trading.Symbols {
+			exchangeName := sym.Exchange
+			if exchangeName == "" {
+				exchangeName = defaultExchange
+			}
+			targets[strings.ToUpper(sym.Symbol)] = exchangeName
+		}
+	}
+	if len(targets) < 2 {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("至少需要 2 个交易对"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 20*time.Second)
+	defer cancel()
+
+	exchanges := capitalDataSource.GetExchanges()
+	series := make(map[string][]indicators.Candle, len(targets))
+	var skipped []string
+	for symbol, exchangeName := range targets {
+		var ex exchange.IExchange
+		for _, candidate := range exchanges {
+			if strings.EqualFold(candidate.GetName(), exchangeName) {
+				ex = candidate
+				break
+			}
+		}
+		if ex == nil {
+			skipped = append(skipped, fmt.Sprintf("%s:%s（交易所未连接）", exchangeName, symbol))
+			continue
+		}
+		klines, err := ex.GetHistoricalKlines(ctx, symbol, interval, lookback)
+		if err != nil {
+			logger.Warn("⚠️ [相关性分析] 获取 %s:%s K线失败: %v", exchangeName, symbol, err)
+			skipped = append(skipped, fmt.Sprintf("%s:%s（%v）", exchangeName, symbol, err))
+			continue
+		}
+		candles := make([]indicators.Candle, len(klines))
+		for i, k := range klines {
+			candles[i] = indicators.Candle{Time: k.Timestamp, Open: k.Open, High: k.High, Low: k.Low, Close: k.Close, Volume: k.Volume}
+		}
+		series[symbol] = candles
+	}
+	if len(series) < 2 {
+		respondError(c, http.StatusBadGateway, "error.correlation_failed", fmt.Errorf("可用K线数据的交易对不足 2 个: %s", strings.Join(skipped, ", ")))
+		return
+	}
+
+	report := indicators.ComputeCorrelation(series, window)
+	if skipped == nil {
+		skipped = []string{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"interval": interval,
+		"lookback": lookback,
+		"window":   window,
+		"report":   report,
+		"skipped":  skipped,
+	})
+}
+
+const (
+	attributionPageSize  = 1000
+	attributionMaxTrades = 100000 // 单次分析最多读取的交易记录数
+)
+
+// AttributionBucket 盈亏归因的一个分组
+type AttributionBucket struct {
+	Label     string  `json:"label,omitempty"`      // 时段分组名称（如 09:00、Monday）
+	PriceLow  float64 `json:"price_low,omitempty"`  // 价格分组下界（含）
+	PriceHigh float64 `json:"price_high,omitempty"` // 价格分组上界（不含，最后一组含）
+	Trades    int     `json:"trades"`
+	Wins      int     `json:"wins"`
+	Losses    int     `json:"losses"`
+	PnL       float64 `json:"pnl"`
+	AvgPnL    float64 `json:"avg_pnl"`
+	WinRate   float64 `json:"win_rate"`
+}
+
+func (b *AttributionBucket) add(pnl float64) {
+	b.Trades++
+	b.PnL += pnl
+	if pnl > 0 {
+		b.Wins++
+	} else if pnl < 0 {
+		b.Losses++
+	}
+}
+
+func (b *AttributionBucket) finish() {
+	if b.Trades > 0 {
+		b.AvgPnL = b.PnL / float64(b.Trades)
+		b.WinRate = float64(b.Wins) / float64(b.Trades)
+	}
+}
+
+// PnLAttribution 已实现盈亏按槽位价格、小时、星期分组的归因结果
+type PnLAttribution struct {
+	TotalTrades int                 `json:"total_trades"`
+	TotalPnL    float64             `json:"total_pnl"`
+	BucketSize  float64             `json:"bucket_size"`  // 价格分组宽度
+	ByPrice     []AttributionBucket `json:"by_price"`     // 按买入（槽位）价格，仅包含有交易的分组
+	ByHour      []AttributionBucket `json:"by_hour"`      // 按平仓时间的小时（0-23）
+	ByWeekday   []AttributionBucket `json:"by_weekday"`   // 按平仓时间的星期（周一到周日）
+	LosingHours []int               `json:"losing_hours"` // 交易数不少于 min_trades 且合计亏损的小时
+	LosingDays  []string            `json:"losing_days"`  // 交易数不少于 min_trades 且合计亏损的星期
+}
+
+// computePnLAttribution 计算盈亏归因
+// bucketSize>0 时按固定宽度分组（边界为宽度的整数倍），否则将买入价格范围等分为 buckets 组；时段按 loc 时区统计
+func computePnLAttribution(trades []*storage.Trade, bucketSize float64, buckets, minTrades int, loc *time.Location) PnLAttribution {
+	result := PnLAttribution{
+		ByPrice:     []AttributionBucket{},
+		ByHour:      make([]AttributionBucket, 24),
+		ByWeekday:   make([]AttributionBucket, 7),
+		LosingHours: []int{},
+		LosingDays:  []string{},
+	}
+	for h := range result.ByHour {
+		result.ByHour[h].Label = fmt.Sprintf("%02d:00", h)
+	}
+	for d := range result.ByWeekday {
+		result.ByWeekday[d].Label = time.Weekday((d + 1) % 7).String()
+	}
+	if len(trades) == 0 {
+		return result
+	}
+
+	minPrice, maxPrice := math.Inf(1), math.Inf(-1)
+	for _, t := range trades {
+		minPrice = math.Min(minPrice, t.BuyPrice)
+		maxPrice = math.Max(maxPrice, t.BuyPrice)
+	}
+	origin, equalWidth := 0.0, bucketSize <= 0
+	if equalWidth {
+		origin = minPrice
+		bucketSize = (maxPrice - minPrice) / float64(buckets)
+		if bucketSize <= 0 {
+			bucketSize = math.Max(minPrice, 1) // 所有交易价格相同，只有一组
+		}
+	}
+	result.BucketSize = bucketSize
+
+	byPrice := make(map[int]*AttributionBucket)
+	for _, t := range trades {
+		result.TotalTrades++
+		result.TotalPnL += t.PnL
+
+		idx := int(math.Floor((t.BuyPrice - origin) / bucketSize))
+		if equalWidth && idx >= buckets {
+			idx = buckets - 1 // 等分模式下最高价落在最后一组
+		}
+		b, ok := byPrice[idx]
+		if !ok {
+			low := origin + float64(idx)*bucketSize
+			b = &AttributionBucket{PriceLow: low, PriceHigh: low + bucketSize}
+			byPrice[idx] = b
+		}
+		b.add(t.PnL)
+
+		closed := t.CreatedAt.In(loc)
+		result.ByHour[closed.Hour()].add(t.PnL)
+		result.ByWeekday[(int(closed.Weekday())+6)%7].add(t.PnL)
+	}
+
+	keys := make([]int, 0, len(byPrice))
+	for k := range byPrice {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	for _, k := range keys {
+		byPrice[k].finish()
+		result.ByPrice = append(result.ByPrice, *byPrice[k])
+	}
+	for h := range result.ByHour {
+		b := &result.ByHour[h]
+		b.finish()
+		if b.Trades >= minTrades && b.PnL < 0 {
+			result.LosingHours = append(result.LosingHours, h)
+		}
+	}
+	for d := range result.ByWeekday {
+		b := &result.ByWeekday[d]
+		b.finish()
+		if b.Trades >= minTrades && b.PnL < 0 {
+			result.LosingDays = append(result.LosingDays, b.Label)
+		}
+	}
+	return result
+}
+
+// getAnalyticsAttribution 已实现盈亏归因：按槽位价格区间、小时、星期分组
+// GET /api/analytics/attribution?symbol=BTCUSDT&days=30&buckets=10&bucket_size=&min_trades=5
+// 也可用 start_time/end_time（RFC3339）指定时间范围；scope=all 时统计所有交易对（价格分组仅在单个交易对时有意义）
+func getAnalyticsAttribution(c *gin.Context) {
+	storageProv := PickStorageProvider(c)
+	if storageProv == nil || storageProv.GetStorage() == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.storage_unavailable")
+		return
+	}
+	st := storageProv.GetStorage()
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 || days > 365 {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("days 必须在 1-365 之间"))
+		return
+	}
+	buckets, err := strconv.Atoi(c.DefaultQuery("buckets", "10"))
+	if err != nil || buckets < 1 || buckets > 100 {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("buckets 必须在 1-100 之间"))
+		return
+	}
+	var bucketSize float64
+	if raw := c.Query("bucket_size"); raw != "" {
+		bucketSize, err = strconv.ParseFloat(raw, 64)
+		if err != nil || bucketSize <= 0 {
+			respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("bucket_size 必须为正数"))
+			return
+		}
+	}
+	minTrades, err := strconv.Atoi(c.DefaultQuery("min_trades", "5"))
+	if err != nil || minTrades < 1 {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("min_trades 必须为正整数"))
+		return
+	}
+
+	endTime := time.Now()
+	startTime := endTime.AddDate(0, 0, -days)
+	if raw := c.Query("start_time"); raw != "" {
+		if startTime, err = time.Parse(time.RFC3339, raw); err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_start_time")
+			return
+		}
+	}
+	if raw := c.Query("end_time"); raw != "" {
+		if endTime, err = time.Parse(time.RFC3339, raw); err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_end_time")
+			return
+		}
+	}
+
+	exchangeName, symbol := resolveSymbolScope(c)
+	var trades []*storage.Trade
+	truncated := false
+	for offset := 0; ; offset += attributionPageSize {
+		page, err := st.QueryTrades(exchangeName, symbol, startTime, endTime, attributionPageSize, offset)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "error.query_trades_failed", err)
+			return
+		}
+		trades = append(trades, page...)
+		if len(page) < attributionPageSize {
+			break
+		}
+		if len(trades) >= attributionMaxTrades {
+			truncated = true
+			break
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"exchange":    exchangeName,
+		"symbol":      symbol,
+		"start_time":  utils.ToConfiguredTimezone(startTime),
+		"end_time":    utils.ToConfiguredTimezone(endTime),
+		"timezone":    utils.GlobalLocation.String(),
+		"truncated":   truncated,
+		"attribution": computePnLAttribution(trades, bucketSize, buckets, minTrades, utils.GlobalLocation),
+	})
+}
@@ -0,0 +1,49 @@
+package web
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"quantmesh/storage"
+)
+
+func TestComputePnLAttribution(t *testing.T) {
+	// 2025-01-06 为周一
+	at := func(day, hour int) time.Time { return time.Date(2025, 1, 6+day, hour, 30, 0, 0, time.UTC) }
+	trades := []*storage.Trade{
+		{BuyPrice: 100, PnL: 2, CreatedAt: at(0, 9)},
+		{BuyPrice: 104, PnL: 1, CreatedAt: at(0, 9)},
+		{BuyPrice: 112, PnL: -3, CreatedAt: at(1, 3)},
+		{BuyPrice: 119, PnL: -1, CreatedAt: at(1, 3)},
+		{BuyPrice: 120, PnL: 4, CreatedAt: at(6, 9)},
+	}
+
+	// 固定宽度分组：边界为 10 的整数倍
+	ov := computePnLAttribution(trades, 10, 10, 2, time.UTC)
+	if ov.TotalTrades != 5 || math.Abs(ov.TotalPnL-3) > 1e-9 {
+		t.Fatalf("totals = %d %.2f", ov.TotalTrades, ov.TotalPnL)
+	}
+	if len(ov.ByPrice) != 3 || ov.ByPrice[0].PriceLow != 100 || ov.ByPrice[0].Trades != 2 ||
+		ov.ByPrice[1].PriceLow != 110 || ov.ByPrice[1].PnL != -4 || ov.ByPrice[2].PriceHigh != 130 {
+		t.Fatalf("unexpected price buckets: %+v", ov.ByPrice)
+	}
+	if h := ov.ByHour[9]; h.Trades != 3 || h.PnL != 7 || h.WinRate != 1 {
+		t.Errorf("unexpected 09:00 bucket: %+v", h)
+	}
+	if len(ov.LosingHours) != 1 || ov.LosingHours[0] != 3 {
+		t.Errorf("losing hours = %v, want [3]", ov.LosingHours)
+	}
+	if ov.ByWeekday[0].Label != "Monday" || ov.ByWeekday[6].Label != "Sunday" || ov.ByWeekday[6].Trades != 1 {
+		t.Errorf("unexpected weekday buckets: %+v", ov.ByWeekday)
+	}
+	if len(ov.LosingDays) != 1 || ov.LosingDays[0] != "Tuesday" {
+		t.Errorf("losing days = %v, want [Tuesday]", ov.LosingDays)
+	}
+
+	// 等分模式：最高价落在最后一组
+	ov = computePnLAttribution(trades, 0, 2, 2, time.UTC)
+	if ov.BucketSize != 10 || len(ov.ByPrice) != 2 || ov.ByPrice[1].Trades != 3 || ov.ByPrice[1].PriceHigh != 120 {
+		t.Errorf("unexpected equal-width buckets: size=%.2f %+v", ov.BucketSize, ov.ByPrice)
+	}
+}
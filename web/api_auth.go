@@ -115,18 +115,8 @@ func setPassword(c *gin.Context) {
 
 	logger.WriteWebLog(fmt.Sprintf("[AUTH] 会话已创建，SessionID: %s...", session.SessionID[:20]))
 
-	// 使用 Gin 的 SetCookie 方法设置会话Cookie
-	// MaxAge: 24小时 = 86400秒
-	c.SetCookie(
-		"session_id",      // name
-		session.SessionID, // value
-		86400,             // maxAge (24小时)
-		"/",               // path
-		"",                // domain (空字符串表示当前域)
-		false,             // secure (HTTP 环境设为 false)
-		true,              // httpOnly
-	)
-	logger.WriteWebLog("[AUTH] Cookie 已通过 Gin 设置: Name=session_id, Path=/, MaxAge=86400, HttpOnly=true, Secure=false")
+	// 设置会话和刷新令牌Cookie
+	sm.SetLoginCookies(c.Writer, session, c.Request.TLS != nil)
 
 	c.JSON(http.StatusOK, gin.H{"success": true, "message": "密码设置成功"})
 }
@@ -168,9 +158,9 @@ func verifyPassword(c *gin.Context) {
 		userAgent := c.GetHeader("User-Agent")
 		session, err := sm.CreateSession(username, "admin", ip, userAgent)
 		if err == nil {
-			// 设置会话Cookie
+			// 设置会话和刷新令牌Cookie
 			secure := c.Request.TLS != nil
-			sm.SetSessionCookie(c.Writer, session.SessionID, secure)
+			sm.SetLoginCookies(c.Writer, session, secure)
 		}
 	}
 
@@ -226,7 +216,10 @@ func changePassword(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true, "message": "密码修改成功"})
+	// 修改密码后注销其他设备上的会话
+	revoked := sm.RevokeUserSessions(session.Username, session.SessionID, "修改密码")
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "密码修改成功", "revoked_sessions": revoked})
 }
 
 // logout 退出登录
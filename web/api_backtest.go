@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"quantmesh/backtest"
+	"quantmesh/exchange"
 	"quantmesh/logger"
 
 	"github.com/gin-gonic/gin"
@@ -19,6 +20,7 @@ type BacktestRequest struct {
 	StartTime      time.Time `json:"start_time" binding:"required"`      // 开始时间
 	EndTime        time.Time `json:"end_time" binding:"required"`        // 结束时间
 	InitialCapital float64   `json:"initial_capital" binding:"required"` // 初始资金
+	Exchange       string    `json:"exchange"`                           // 模拟执行参数使用的交易所（对应 simulation.exchanges，默认 binance）
 }
 
 // BacktestResponse 回测响应
@@ -114,6 +116,14 @@ func runBacktest(c *gin.Context) {
 		strategy,
 		req.InitialCapital,
 	)
+	if globalConfig != nil && globalConfig.Simulation.Enabled {
+		venue := req.Exchange
+		if venue == "" {
+			venue = "binance"
+		}
+		backtester.SetExecutionSimulator(exchange.NewExecutionSimulator(globalConfig.Simulation.For(venue), globalConfig.Simulation.Seed))
+		logger.Info("⏱️ 回测使用 %s 的模拟执行参数（延迟与滑点）", venue)
+	}
 
 	// 4. 运行回测
 	result, err := backtester.Run()
@@ -0,0 +1,128 @@
+package web
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/indicators"
+)
+
+// BenchmarkSample 基准对比的一次采样
+type BenchmarkSample struct {
+	Time   time.Time `json:"time"`
+	Price  float64   `json:"price"`
+	Equity float64   `json:"equity"` // 机器人权益（初始资金 + 已实现盈亏 + 未实现盈亏）
+}
+
+// BenchmarkSymbol 单个交易对的机器人权益与持币不动（HODL）对比
+// 收益率、alpha 与波动率均为百分比，波动率按采样间隔年化
+type BenchmarkSymbol struct {
+	Exchange       string    `json:"exchange"`
+	Symbol         string    `json:"symbol"`
+	StartedAt      time.Time `json:"started_at"`
+	StartPrice     float64   `json:"start_price"`
+	InitialCapital float64   `json:"initial_capital"`
+	CurrentPrice   float64   `json:"current_price"`
+	RealizedPnL    float64   `json:"realized_pnl"` // 起算以来的已实现盈亏
+	UnrealizedPnL  float64   `json:"unrealized_pnl"`
+	BotEquity      float64   `json:"bot_equity"`
+	HODLValue      float64   `json:"hodl_value"` // 起算时用初始资金买入并持有至今的价值
+	BotReturn      float64   `json:"bot_return"`
+	HODLReturn     float64   `json:"hodl_return"`
+	Alpha          float64   `json:"alpha"` // 机器人收益率 - HODL 收益率
+	BotVolatility  float64   `json:"bot_volatility"`
+	HODLVolatility float64   `json:"hodl_volatility"`
+	Samples        int       `json:"samples"`
+}
+
+// BenchmarkReport 各交易对及合计的 HODL 对比
+type BenchmarkReport struct {
+	GeneratedAt    time.Time         `json:"generated_at"`
+	SampleInterval int               `json:"sample_interval"` // 采样间隔（秒）
+	Symbols        []BenchmarkSymbol `json:"symbols"`
+	InitialCapital float64           `json:"initial_capital"`
+	BotEquity      float64           `json:"bot_equity"`
+	HODLValue      float64           `json:"hodl_value"`
+	BotReturn      float64           `json:"bot_return"`
+	HODLReturn     float64           `json:"hodl_return"`
+	Alpha          float64           `json:"alpha"`
+}
+
+// BenchmarkProvider HODL 基准对比提供者（需要从 main.go 注入）
+type BenchmarkProvider interface {
+	BenchmarkReport() BenchmarkReport
+}
+
+// SetBenchmarkProvider 设置 HODL 基准对比提供者
+func SetBenchmarkProvider(provider BenchmarkProvider) {
+	defaultProviders.Benchmark = provider
+}
+
+// ComputeBenchmarkSymbol 根据起算点、最新价格与盈亏计算权益和 HODL 价值，并用采样序列计算两者的年化波动率
+// sym 需填好起算点（StartPrice、InitialCapital）、CurrentPrice、RealizedPnL 和 UnrealizedPnL
+func ComputeBenchmarkSymbol(sym BenchmarkSymbol, samples []BenchmarkSample, interval time.Duration) BenchmarkSymbol {
+	sym.BotEquity = sym.InitialCapital + sym.RealizedPnL + sym.UnrealizedPnL
+	if sym.StartPrice > 0 && sym.CurrentPrice > 0 {
+		sym.HODLValue = sym.InitialCapital * sym.CurrentPrice / sym.StartPrice
+	}
+	if sym.InitialCapital > 0 {
+		sym.BotReturn = (sym.BotEquity/sym.InitialCapital - 1) * 100
+		if sym.HODLValue > 0 {
+			sym.HODLReturn = (sym.HODLValue/sym.InitialCapital - 1) * 100
+		}
+		sym.Alpha = sym.BotReturn - sym.HODLReturn
+	}
+
+	sym.Samples = len(samples)
+	sym.BotVolatility, sym.HODLVolatility = 0, 0
+	if len(samples) >= 3 && interval > 0 {
+		prices := make([]float64, len(samples))
+		equities := make([]float64, len(samples))
+		for i, s := range samples {
+			prices[i], equities[i] = s.Price, s.Equity
+		}
+		annualize := math.Sqrt(float64(365*24*time.Hour) / float64(interval))
+		sym.BotVolatility = indicators.SampleStdDev(indicators.LogReturns(equities)) * annualize * 100
+		sym.HODLVolatility = indicators.SampleStdDev(indicators.LogReturns(prices)) * annualize * 100
+	}
+	return sym
+}
+
+// NewBenchmarkReport 汇总各交易对的对比结果
+func NewBenchmarkReport(symbols []BenchmarkSymbol, interval time.Duration) BenchmarkReport {
+	report := BenchmarkReport{
+		GeneratedAt:    time.Now(),
+		SampleInterval: int(interval / time.Second),
+		Symbols:        symbols,
+	}
+	if report.Symbols == nil {
+		report.Symbols = []BenchmarkSymbol{}
+	}
+	for _, sym := range symbols {
+		report.InitialCapital += sym.InitialCapital
+		report.BotEquity += sym.BotEquity
+		report.HODLValue += sym.HODLValue
+	}
+	if report.InitialCapital > 0 {
+		report.BotReturn = (report.BotEquity/report.InitialCapital - 1) * 100
+		report.HODLReturn = (report.HODLValue/report.InitialCapital - 1) * 100
+		report.Alpha = report.BotReturn - report.HODLReturn
+	}
+	return report
+}
+
+// getStatisticsBenchmark 机器人权益与持币不动（HODL）的收益、alpha 与波动率对比
+// GET /api/statistics/benchmark
+func getStatisticsBenchmark(c *gin.Context) {
+	benchmarkProvider := providersOf(c).Benchmark
+	if benchmarkProvider == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":   true,
+		"benchmark": benchmarkProvider.BenchmarkReport(),
+	})
+}
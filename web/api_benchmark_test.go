@@ -0,0 +1,45 @@
+package web
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestComputeBenchmarkSymbol(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := []BenchmarkSample{
+		{Time: start, Price: 100, Equity: 1000},
+		{Time: start.Add(time.Hour), Price: 110, Equity: 1010},
+		{Time: start.Add(2 * time.Hour), Price: 90, Equity: 1005},
+		{Time: start.Add(3 * time.Hour), Price: 120, Equity: 1030},
+	}
+
+	// 价格涨 20%，机器人盈利 30（已实现 20 + 未实现 10）：跑输 HODL 17 个百分点
+	sym := ComputeBenchmarkSymbol(BenchmarkSymbol{
+		StartPrice:     100,
+		InitialCapital: 1000,
+		CurrentPrice:   120,
+		RealizedPnL:    20,
+		UnrealizedPnL:  10,
+	}, samples, time.Hour)
+	if sym.BotEquity != 1030 || sym.HODLValue != 1200 {
+		t.Fatalf("equity=%v hodl=%v, want 1030/1200", sym.BotEquity, sym.HODLValue)
+	}
+	if math.Abs(sym.BotReturn-3) > 1e-9 || math.Abs(sym.HODLReturn-20) > 1e-9 || math.Abs(sym.Alpha+17) > 1e-9 {
+		t.Fatalf("returns bot=%v hodl=%v alpha=%v", sym.BotReturn, sym.HODLReturn, sym.Alpha)
+	}
+	if sym.Samples != 4 || sym.BotVolatility <= 0 || sym.HODLVolatility <= sym.BotVolatility {
+		t.Errorf("volatility bot=%v hodl=%v, want grid equity smoother than price", sym.BotVolatility, sym.HODLVolatility)
+	}
+
+	// 样本不足时不计算波动率
+	if short := ComputeBenchmarkSymbol(sym, samples[:2], time.Hour); short.BotVolatility != 0 || short.HODLVolatility != 0 {
+		t.Errorf("volatility with 2 samples = %v/%v, want 0", short.BotVolatility, short.HODLVolatility)
+	}
+
+	report := NewBenchmarkReport([]BenchmarkSymbol{sym, {InitialCapital: 1000, BotEquity: 1100, HODLValue: 800}}, 5*time.Minute)
+	if report.SampleInterval != 300 || report.InitialCapital != 2000 || math.Abs(report.BotReturn-6.5) > 1e-9 || math.Abs(report.HODLReturn) > 1e-9 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
@@ -30,11 +30,22 @@ type PositionManagerInfo struct {
 	Manager  *position.SuperPositionManager
 }
 
-var capitalDataSource CapitalDataSource
+// usedCapital 仓位管理器实际占用的资金（计价货币）
+// 币本位合约的数量为张数，按持仓张数 × 每张面值折算，避免张数与价格间隔相乘得到无意义的数值
+func usedCapital(pm PositionManagerInfo) float64 {
+	if pm.Manager == nil {
+		return 0
+	}
+	if spec := pm.Manager.GetContractSpec(); spec.Inverse {
+		quantity, _, _ := pm.Manager.GetCostBasis()
+		return spec.Notional(quantity, 0)
+	}
+	return pm.Manager.GetTotalBuyQty() * pm.Manager.GetPriceInterval()
+}
 
 // SetCapitalDataSource 设置资金数据源
 func SetCapitalDataSource(ds CapitalDataSource) {
-	capitalDataSource = ds
+	defaultProviders.CapitalDataSource = ds
 }
 
 // CapitalOverview 资金概览（汇总或分交易所）
@@ -46,6 +57,7 @@ type CapitalOverview struct {
 	ReservedCapital  float64                  `json:"reservedCapital"`  // 用户预留资金（不可用于策略）
 	UnrealizedPnL    float64                  `json:"unrealizedPnL"`    // 未实现盈亏
 	MarginRatio      float64                  `json:"marginRatio"`      // 保证金占用率
+	VaultedProfit    float64                  `json:"vaultedProfit"`    // 利润金库累计划出金额（已不在合约账户中）
 	Exchanges        []ExchangeCapitalSummary `json:"exchanges,omitempty"`
 	LastUpdated      string                   `json:"lastUpdated"`
 }
@@ -58,7 +70,8 @@ type ExchangeCapitalSummary struct {
 	Available    float64 `json:"available"`
 	Used         float64 `json:"used"`
 	PnL          float64 `json:"pnl"`
-	Status       string  `json:"status"` // online, offline, error
+	Vaulted      float64 `json:"vaulted"`   // 利润金库累计划出金额
+	Status       string  `json:"status"`    // online, offline, error
 	IsTestnet    bool    `json:"isTestnet"` // 是否使用测试网
 }
 
@@ -148,6 +161,8 @@ type CapitalHistoryPoint struct {
 
 // 获取资金概览
 func getCapitalOverviewHandler(c *gin.Context) {
+	capitalDataSource := providersOf(c).CapitalDataSource
+	storageServiceProvider := providersOf(c).Storage
 	if capitalDataSource == nil {
 		c.JSON(http.StatusOK, gin.H{
 			"success": false,
@@ -234,19 +249,35 @@ func getCapitalOverviewHandler(c *gin.Context) {
 
 	// 3. 汇总实际占用资金
 	for _, pm := range posManagers {
-		overview.UsedCapital += pm.Manager.GetTotalBuyQty() * pm.Manager.GetPriceInterval()
+		overview.UsedCapital += usedCapital(pm)
 	}
 
 	if overview.TotalBalance > 0 {
 		overview.MarginRatio = overview.UsedCapital / overview.TotalBalance
 	}
 
+	// 4. 利润金库累计划出金额
+	if storageServiceProvider != nil {
+		if st := storageServiceProvider.GetStorage(); st != nil {
+			for i := range overview.Exchanges {
+				vaulted, err := st.GetProfitTransferTotal(overview.Exchanges[i].ExchangeID, time.Time{}, time.Now())
+				if err != nil {
+					logger.Warn("⚠️ [资金概览] 查询交易所 %s 利润划转金额失败: %v", overview.Exchanges[i].ExchangeID, err)
+					continue
+				}
+				overview.Exchanges[i].Vaulted = math.Round(vaulted*100) / 100
+				overview.VaultedProfit += vaulted
+			}
+		}
+	}
+
 	// 四舍五入
 	overview.TotalBalance = math.Round(overview.TotalBalance*100) / 100
 	overview.AllocatedCapital = math.Round(overview.AllocatedCapital*100) / 100
 	overview.UsedCapital = math.Round(overview.UsedCapital*100) / 100
 	overview.AvailableCapital = math.Round(overview.AvailableCapital*100) / 100
 	overview.UnrealizedPnL = math.Round(overview.UnrealizedPnL*100) / 100
+	overview.VaultedProfit = math.Round(overview.VaultedProfit*100) / 100
 
 	c.JSON(http.StatusOK, gin.H{
 		"success":  true,
@@ -256,6 +287,7 @@ func getCapitalOverviewHandler(c *gin.Context) {
 
 // 获取资金分配配置
 func getCapitalAllocationHandler(c *gin.Context) {
+	capitalDataSource := providersOf(c).CapitalDataSource
 	if capitalDataSource == nil {
 		c.JSON(http.StatusOK, gin.H{
 			"success": false, 
@@ -355,7 +387,7 @@ func getCapitalAllocationHandler(c *gin.Context) {
 					if pm.Exchange == details[i].ExchangeID {
 						// 这里需要判断该 PM 是否属于该策略
 						// TODO: 完善策略与交易对的关联逻辑
-						strategy.Used += pm.Manager.GetTotalBuyQty() * pm.Manager.GetPriceInterval()
+						strategy.Used += usedCapital(pm)
 					}
 				}
 				
@@ -388,6 +420,7 @@ func getCapitalAllocationHandler(c *gin.Context) {
 
 // 更新资金分配
 func updateCapitalAllocationHandler(c *gin.Context) {
+	capitalDataSource := providersOf(c).CapitalDataSource
 	var req struct {
 		Allocations []CapitalAllocationConfig `json:"allocations"`
 	}
@@ -483,6 +516,7 @@ func updateStrategyCapitalHandler(c *gin.Context) {
 
 // 获取单个策略的资金详情
 func getStrategyCapitalDetailHandler(c *gin.Context) {
+	capitalDataSource := providersOf(c).CapitalDataSource
 	strategyID := c.Param("id")
 
 	if capitalDataSource == nil {
@@ -513,7 +547,7 @@ func getStrategyCapitalDetailHandler(c *gin.Context) {
 
 	for _, pm := range posManagers {
 		// 简化逻辑：这里应该判断 PM 是否属于该策略
-		totalUsed += pm.Manager.GetTotalBuyQty() * pm.Manager.GetPriceInterval()
+		totalUsed += usedCapital(pm)
 	}
 
 	maxCap := 0.0
@@ -546,8 +580,9 @@ func getStrategyCapitalDetailHandler(c *gin.Context) {
 
 // 触发资金再平衡
 func rebalanceCapitalHandler(c *gin.Context) {
+	capitalDataSource := providersOf(c).CapitalDataSource
 	var req struct {
-		Mode   string `json:"mode"` // equal, weighted, priority
+		Mode   string `json:"mode"` // equal, weighted, priority, optimal
 		Force  bool   `json:"force"`
 		DryRun bool   `json:"dryRun"`
 	}
@@ -602,12 +637,24 @@ func rebalanceCapitalHandler(c *gin.Context) {
 		totalWeight += stratConfigs[id].Weight
 	}
 
+	// optimal：按组合优化器的最优方案分配
+	var optimal *optimalAllocation
+	if req.Mode == "optimal" {
+		var err error
+		if optimal, err = planOptimalAllocation(providersOf(c).PortfolioOptimizer, stratConfigs, enabledStrategies, totalBalance); err != nil {
+			c.JSON(http.StatusOK, gin.H{"success": false, "message": err.Error()})
+			return
+		}
+	}
+
 	for _, id := range enabledStrategies {
 		cfg := stratConfigs[id]
 		
 		// 计算目标分配
 		var targetAllocation float64
 		switch req.Mode {
+		case "optimal":
+			targetAllocation = optimal.target(id, configuredMaxCapital(cfg))
 		case "equal":
 			targetAllocation = totalBalance / count
 		case "weighted":
@@ -624,12 +671,7 @@ func rebalanceCapitalHandler(c *gin.Context) {
 		}
 
 		// 获取当前分配（从配置读取）
-		prevAllocation := 0.0
-		if val, ok := cfg.Config["max_capital"].(float64); ok {
-			prevAllocation = val
-		} else if val, ok := cfg.Config["max_capital"].(int); ok {
-			prevAllocation = float64(val)
-		}
+		prevAllocation := configuredMaxCapital(cfg)
 
 		diff := targetAllocation - prevAllocation
 		
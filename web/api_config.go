@@ -3,6 +3,7 @@ package web
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 
 	"github.com/gin-gonic/gin"
@@ -260,6 +261,19 @@ func updateConfigHandler(c *gin.Context) {
 		}
 	}
 
+	if len(diff.Changes) > 0 {
+		paths := make([]string, 0, len(diff.Changes))
+		for _, change := range diff.Changes {
+			if isSensitiveConfigPath(change.Path) {
+				// 密钥类字段只记录路径，不写入取值
+				paths = append(paths, change.Path+": ***")
+				continue
+			}
+			paths = append(paths, fmt.Sprintf("%s: %v → %v", change.Path, change.OldValue, change.NewValue))
+		}
+		recordJournal(c, newConfig.App.CurrentExchange, "", "配置更新", strings.Join(paths, "\n"))
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":          "配置更新成功",
 		"backup_id":        backupInfo.ID,
@@ -19,11 +19,9 @@ type EventProvider interface {
 	GetEventStats(ctx context.Context) (*database.EventStats, error)
 }
 
-var eventProvider EventProvider
-
 // SetEventProvider 设置事件提供者
 func SetEventProvider(provider EventProvider) {
-	eventProvider = provider
+	defaultProviders.Event = provider
 }
 
 // handleGetEvents 获取事件列表
@@ -44,6 +42,7 @@ func SetEventProvider(provider EventProvider) {
 // @Success 200 {object} map[string]interface{}
 // @Router /api/events [get]
 func handleGetEvents(c *gin.Context) {
+	eventProvider := providersOf(c).Event
 	if eventProvider == nil {
 		respondError(c, http.StatusServiceUnavailable, "errors.event_service_unavailable")
 		return
@@ -103,6 +102,7 @@ func handleGetEvents(c *gin.Context) {
 // @Success 200 {object} database.EventRecord
 // @Router /api/events/{id} [get]
 func handleGetEventDetail(c *gin.Context) {
+	eventProvider := providersOf(c).Event
 	if eventProvider == nil {
 		respondError(c, http.StatusServiceUnavailable, "errors.event_service_unavailable")
 		return
@@ -139,6 +139,7 @@ func handleGetEventDetail(c *gin.Context) {
 // @Success 200 {object} database.EventStats
 // @Router /api/events/stats [get]
 func handleGetEventStats(c *gin.Context) {
+	eventProvider := providersOf(c).Event
 	if eventProvider == nil {
 		respondError(c, http.StatusServiceUnavailable, "errors.event_service_unavailable")
 		return
@@ -0,0 +1,69 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/storage"
+)
+
+// databaseExportTimeout 单次导出快照的最长时间
+const databaseExportTimeout = 10 * time.Minute
+
+// databaseExportRunning 同一时刻只允许一个导出（备份期间数据库的其他读写需要排队）
+var databaseExportRunning atomic.Bool
+
+// exportDatabase 导出数据库的一致性快照（SQLite 在线备份），供离线分析
+// 不要直接复制 WAL 模式下运行中的数据库文件：未检查点的数据在 -wal 文件中，单独复制主文件会丢数据或损坏
+// GET /api/export/database
+func exportDatabase(c *gin.Context) {
+	var st storage.Storage
+	if storageProv := providersOf(c).Storage; storageProv != nil {
+		st = storageProv.GetStorage()
+	}
+	snapshotter, ok := st.(storage.Snapshotter)
+	if !ok {
+		respondError(c, http.StatusBadRequest, "error.database_export_unavailable")
+		return
+	}
+	if !databaseExportRunning.CompareAndSwap(false, true) {
+		respondError(c, http.StatusTooManyRequests, "error.database_export_in_progress")
+		return
+	}
+	defer databaseExportRunning.Store(false)
+
+	tmp, err := os.CreateTemp("", "quantmesh-export-*.db")
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "error.database_export_failed", err)
+		return
+	}
+	path := tmp.Name()
+	tmp.Close()
+	defer os.Remove(path)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), databaseExportTimeout)
+	defer cancel()
+	start := time.Now()
+	if err := snapshotter.Snapshot(ctx, path); err != nil {
+		LogAction(c, "database_export", "database", nil, "failed", err.Error())
+		respondError(c, http.StatusInternalServerError, "error.database_export_failed", err)
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "error.database_export_failed", err)
+		return
+	}
+	LogAction(c, "database_export", "database", gin.H{
+		"size":        info.Size(),
+		"duration_ms": time.Since(start).Milliseconds(),
+	}, "success", "")
+
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(path, fmt.Sprintf("quantmesh-%s.db", time.Now().Format("20060102-150405")))
+}
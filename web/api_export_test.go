@@ -0,0 +1,72 @@
+package web
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/storage"
+	"quantmesh/testutil"
+)
+
+func TestExportDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	st, err := storage.NewSQLiteStorage(filepath.Join(dir, "live.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	if err := st.SaveTrade(&storage.Trade{Exchange: "binance", Symbol: "BTCUSDT", Quantity: 1, PnL: 2, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	SetStorageServiceProvider(&mockStorageProvider{st: st})
+	defer SetStorageServiceProvider(nil)
+
+	role := "admin"
+	r := gin.New()
+	r.GET("/api/export/database", func(c *gin.Context) {
+		c.Set("session", &Session{Username: "u", Role: role})
+	}, adminOnlyMiddleware(), exportDatabase)
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/export/database", nil))
+		return w
+	}
+
+	w := get()
+	if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Disposition"), "quantmesh-") {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Header().Get("Content-Disposition"))
+	}
+	snapshot := filepath.Join(dir, "export.db")
+	if err := os.WriteFile(snapshot, w.Body.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM trades`).Scan(&count); err != nil || count != 1 {
+		t.Fatalf("snapshot trades: count=%d err=%v", count, err)
+	}
+
+	role = "viewer"
+	if w := get(); w.Code != http.StatusForbidden {
+		t.Fatalf("non-admin: got status %d, want 403", w.Code)
+	}
+
+	// 内存存储不支持导出
+	role = "admin"
+	SetStorageServiceProvider(&mockStorageProvider{st: testutil.NewMockStorage()})
+	if w := get(); w.Code != http.StatusBadRequest {
+		t.Fatalf("unsupported storage: got status %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,60 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/monitor"
+)
+
+// FillAnomalyProvider 成交异常检测提供者接口
+type FillAnomalyProvider interface {
+	GetFillAnomalies(limit int) []*monitor.FillAnomaly
+	LastScanTime() time.Time
+}
+
+// SetFillAnomalyProvider 设置成交异常检测提供者
+func SetFillAnomalyProvider(provider FillAnomalyProvider) {
+	defaultProviders.FillAnomaly = provider
+}
+
+// getExecutionAnomalies 获取自身成交中检测到的异常模式
+// GET /api/statistics/execution-anomalies?symbol=BTCUSDT&limit=50
+func getExecutionAnomalies(c *gin.Context) {
+	fillAnomalyProvider := providersOf(c).FillAnomaly
+	if fillAnomalyProvider == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"enabled":   false,
+			"anomalies": []*monitor.FillAnomaly{},
+			"count":     0,
+		})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	symbol := c.Query("symbol")
+
+	anomalies := fillAnomalyProvider.GetFillAnomalies(0)
+	result := make([]*monitor.FillAnomaly, 0, limit)
+	for _, a := range anomalies {
+		if symbol != "" && a.Symbol != symbol {
+			continue
+		}
+		result = append(result, a)
+		if len(result) >= limit {
+			break
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":   true,
+		"anomalies": result,
+		"count":     len(result),
+		"last_scan": fillAnomalyProvider.LastScanTime(),
+	})
+}
@@ -0,0 +1,185 @@
+package web
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/config"
+	"quantmesh/logger"
+)
+
+// fleetMaxReportBytes 单次上报的请求体上限
+const fleetMaxReportBytes = 1 << 20
+
+// FleetSymbolStatus 成员上报的单个交易对状态
+type FleetSymbolStatus struct {
+	Exchange     string  `json:"exchange"`
+	Symbol       string  `json:"symbol"`
+	Paused       bool    `json:"paused"`
+	CurrentPrice float64 `json:"current_price"`
+	Position     float64 `json:"position"`
+	TotalPnL     float64 `json:"total_pnl"`
+	TotalTrades  int     `json:"total_trades"`
+}
+
+// FleetReport 成员上报的状态与盈亏
+type FleetReport struct {
+	Name        string              `json:"name"`
+	Version     string              `json:"version"`
+	Mode        string              `json:"mode"` // trading / maintenance / setup / standby
+	StartedAt   time.Time           `json:"started_at"`
+	ReportedAt  time.Time           `json:"reported_at"`
+	Symbols     []FleetSymbolStatus `json:"symbols"`
+	TotalPnL    float64             `json:"total_pnl"`
+	TodayPnL    float64             `json:"today_pnl"`
+	TotalTrades int                 `json:"total_trades"`
+}
+
+// FleetMember 总览中的一个实例
+type FleetMember struct {
+	FleetReport
+	Self       bool      `json:"self"` // hub 自身
+	Online     bool      `json:"online"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// FleetOverview 多实例总览
+type FleetOverview struct {
+	GeneratedAt    time.Time     `json:"generated_at"`
+	Members        []FleetMember `json:"members"`
+	Online         int           `json:"online"`
+	RunningSymbols int           `json:"running_symbols"`
+	TotalPnL       float64       `json:"total_pnl"`
+	TodayPnL       float64       `json:"today_pnl"`
+	TotalTrades    int           `json:"total_trades"`
+}
+
+// FleetProvider 本实例的状态报告（需要从 main.go 注入）
+type FleetProvider interface {
+	LocalReport() FleetReport
+}
+
+// SetFleetProvider 设置多实例总览提供者
+func SetFleetProvider(provider FleetProvider) {
+	defaultProviders.Fleet = provider
+}
+
+// fleetHub 接收成员上报并汇总（只保存在内存中，hub 重启后等待成员下次上报）
+type fleetHub struct {
+	token      string
+	staleAfter time.Duration
+
+	mu      sync.RWMutex
+	members map[string]FleetMember
+}
+
+func newFleetHub(cfg config.FleetConfig) *fleetHub {
+	return &fleetHub{
+		token:      cfg.Token,
+		staleAfter: time.Duration(cfg.StaleAfter) * time.Second,
+		members:    make(map[string]FleetMember),
+	}
+}
+
+// hasToken 请求是否携带有效的实例间令牌
+func (h *fleetHub) hasToken(c *gin.Context) bool {
+	header := c.GetHeader("Authorization")
+	return subtle.ConstantTimeCompare([]byte(header), []byte("Bearer "+h.token)) == 1
+}
+
+// tokenMiddleware 成员上报只接受实例间令牌
+func (h *fleetHub) tokenMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !h.hasToken(c) {
+			respondError(c, http.StatusUnauthorized, "error.fleet_token_invalid")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// readMiddleware 读取总览：实例间令牌（只读）或已登录会话
+func (h *fleetHub) readMiddleware() gin.HandlerFunc {
+	auth := authMiddleware()
+	return func(c *gin.Context) {
+		if h.hasToken(c) {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
+
+// receiveReport 接收成员上报
+// POST /api/fleet/report
+func (h *fleetHub) receiveReport(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, fleetMaxReportBytes)
+	var report FleetReport
+	if err := c.ShouldBindJSON(&report); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	report.Name = strings.TrimSpace(report.Name)
+	if report.Name == "" {
+		respondError(c, http.StatusBadRequest, "error.invalid_request")
+		return
+	}
+
+	h.mu.Lock()
+	if _, known := h.members[report.Name]; !known {
+		logger.Info("🛰️ [实例总览] 新成员加入: %s (%s)", report.Name, c.ClientIP())
+	}
+	h.members[report.Name] = FleetMember{FleetReport: report, ReceivedAt: time.Now()}
+	h.mu.Unlock()
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// overview 汇总 hub 自身与所有成员，离线成员保留最后一次上报但不计入合计
+func (h *fleetHub) overview(self FleetProvider, now time.Time) FleetOverview {
+	var members []FleetMember
+	if self != nil {
+		report := self.LocalReport()
+		members = append(members, FleetMember{FleetReport: report, Self: true, Online: true, ReceivedAt: report.ReportedAt})
+	}
+	h.mu.RLock()
+	for _, m := range h.members {
+		m.Online = now.Sub(m.ReceivedAt) <= h.staleAfter
+		members = append(members, m)
+	}
+	h.mu.RUnlock()
+	sort.SliceStable(members, func(i, j int) bool {
+		if members[i].Self != members[j].Self {
+			return members[i].Self
+		}
+		return members[i].Name < members[j].Name
+	})
+
+	ov := FleetOverview{GeneratedAt: now, Members: members}
+	for _, m := range members {
+		if !m.Online {
+			continue
+		}
+		ov.Online++
+		ov.TotalPnL += m.TotalPnL
+		ov.TodayPnL += m.TodayPnL
+		ov.TotalTrades += m.TotalTrades
+		for _, s := range m.Symbols {
+			if !s.Paused {
+				ov.RunningSymbols++
+			}
+		}
+	}
+	return ov
+}
+
+// getFleet 多实例总览
+// GET /api/fleet
+func (h *fleetHub) getFleet(c *gin.Context) {
+	c.JSON(http.StatusOK, h.overview(providersOf(c).Fleet, time.Now()))
+}
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/config"
+)
+
+type stubFleetProvider struct{ report FleetReport }
+
+func (p stubFleetProvider) LocalReport() FleetReport { return p.report }
+
+func TestFleetHub(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hub := newFleetHub(config.FleetConfig{Token: "secret", StaleAfter: 60})
+	providers := &Providers{Fleet: stubFleetProvider{FleetReport{
+		Name: "hub", TotalPnL: 5, Symbols: []FleetSymbolStatus{{Exchange: "binance", Symbol: "BTCUSDT"}},
+	}}}
+	r := gin.New()
+	r.Use(providersMiddleware(providers))
+	r.POST("/api/fleet/report", hub.tokenMiddleware(), hub.receiveReport)
+	r.GET("/api/fleet", hub.readMiddleware(), hub.getFleet)
+
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	report := `{"name":"okx-bot","total_pnl":7,"today_pnl":1.5,"total_trades":3,"symbols":[{"exchange":"okx","symbol":"ETHUSDT","paused":true}]}`
+	if w := do(http.MethodPost, "/api/fleet/report", "wrong", report); w.Code != http.StatusUnauthorized {
+		t.Fatalf("report with wrong token: got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/fleet/report", "secret", report); w.Code != http.StatusOK {
+		t.Fatalf("report: got %d %s", w.Code, w.Body.String())
+	}
+
+	w := do(http.MethodGet, "/api/fleet", "secret", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("overview: got %d", w.Code)
+	}
+	var ov FleetOverview
+	if err := json.Unmarshal(w.Body.Bytes(), &ov); err != nil {
+		t.Fatal(err)
+	}
+	if len(ov.Members) != 2 || !ov.Members[0].Self || ov.Online != 2 || ov.TotalPnL != 12 || ov.RunningSymbols != 1 || ov.TotalTrades != 3 {
+		t.Fatalf("unexpected overview: %+v", ov)
+	}
+
+	// 超过 stale_after 未上报的成员不计入合计
+	ov = hub.overview(providers.Fleet, time.Now().Add(2*time.Minute))
+	if ov.Online != 1 || ov.TotalPnL != 5 || ov.Members[1].Online {
+		t.Errorf("stale member should be offline: %+v", ov)
+	}
+}
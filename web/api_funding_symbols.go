@@ -0,0 +1,122 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/logger"
+)
+
+// maxFundingSymbols 监控列表上限，避免单个周期内请求过多
+const maxFundingSymbols = 100
+
+var fundingSymbolPattern = regexp.MustCompile(`^[A-Z0-9]{2,30}$`)
+
+// FundingSymbolsProvider 资金费率监控列表提供者接口（需要从 main.go 注入）
+type FundingSymbolsProvider interface {
+	GetSymbols() []string       // 配置的监控列表
+	GetActiveSymbols() []string // 自动纳入的正在交易的交易对
+	MonitoredSymbols() []string // 实际监控的交易对
+	SetSymbols(symbols []string) []string
+}
+
+// SetFundingSymbolsProvider 设置资金费率监控列表提供者
+func SetFundingSymbolsProvider(provider FundingSymbolsProvider) {
+	defaultProviders.FundingSymbols = provider
+}
+
+// UpdateFundingSymbolsRequest 修改资金费率监控列表请求
+type UpdateFundingSymbolsRequest struct {
+	Symbols []string `json:"symbols"` // 为空时恢复默认主流交易对
+}
+
+// getFundingSymbols 查看资金费率监控列表
+// GET /api/funding/symbols
+func getFundingSymbols(c *gin.Context) {
+	fundingSymbolsProvider := providersOf(c).FundingSymbols
+	if fundingSymbolsProvider == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	c.JSON(http.StatusOK, fundingSymbolsResponse(fundingSymbolsProvider, false))
+}
+
+// updateFundingSymbols 修改资金费率监控列表，写入配置文件后立即生效
+// PUT /api/funding/symbols
+func updateFundingSymbols(c *gin.Context) {
+	fundingSymbolsProvider := providersOf(c).FundingSymbols
+	if fundingSymbolsProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.invalid_request", fmt.Errorf("资金费率监控未启用"))
+		return
+	}
+	var req UpdateFundingSymbolsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	symbols, err := normalizeFundingSymbolsRequest(req.Symbols)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+
+	// 先持久化再生效，保存失败时运行中的列表保持不变
+	persisted := false
+	if configManager != nil {
+		cfg, err := configManager.GetConfig()
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "error.save_config_failed", err)
+			return
+		}
+		newCfg := *cfg
+		newCfg.FundingMonitor.Symbols = symbols
+		if err := configManager.UpdateConfig(&newCfg); err != nil {
+			respondError(c, http.StatusInternalServerError, "error.save_config_failed", err)
+			return
+		}
+		persisted = true
+	}
+
+	fundingSymbolsProvider.SetSymbols(symbols)
+	logger.Info("📊 [资金费率] 通过 API 修改监控列表: %v (已保存: %v)", symbols, persisted)
+	c.JSON(http.StatusOK, fundingSymbolsResponse(fundingSymbolsProvider, persisted))
+}
+
+// fundingSymbolsResponse 监控列表响应
+func fundingSymbolsResponse(fundingSymbolsProvider FundingSymbolsProvider, persisted bool) gin.H {
+	active := fundingSymbolsProvider.GetActiveSymbols()
+	if active == nil {
+		active = []string{}
+	}
+	return gin.H{
+		"enabled":   true,
+		"symbols":   fundingSymbolsProvider.GetSymbols(),
+		"active":    active,
+		"monitored": fundingSymbolsProvider.MonitoredSymbols(),
+		"persisted": persisted,
+	}
+}
+
+// normalizeFundingSymbolsRequest 校验并规范化监控列表（大写、去重），空列表返回 nil
+func normalizeFundingSymbolsRequest(symbols []string) ([]string, error) {
+	seen := make(map[string]bool, len(symbols))
+	var normalized []string
+	for _, s := range symbols {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		if s == "" || seen[s] {
+			continue
+		}
+		if !fundingSymbolPattern.MatchString(s) {
+			return nil, fmt.Errorf("交易对格式无效: %s", s)
+		}
+		seen[s] = true
+		normalized = append(normalized, s)
+	}
+	if len(normalized) > maxFundingSymbols {
+		return nil, fmt.Errorf("监控列表最多 %d 个交易对", maxFundingSymbols)
+	}
+	return normalized, nil
+}
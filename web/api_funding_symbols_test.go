@@ -0,0 +1,33 @@
+package web
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeFundingSymbolsRequest(t *testing.T) {
+	symbols, err := normalizeFundingSymbolsRequest([]string{" btcusdt", "ETHUSDT", "BTCUSDT", ""})
+	if err != nil {
+		t.Fatalf("校验失败: %v", err)
+	}
+	if !reflect.DeepEqual(symbols, []string{"BTCUSDT", "ETHUSDT"}) {
+		t.Errorf("规范化结果错误: %v", symbols)
+	}
+
+	// 空列表表示恢复默认
+	if symbols, err := normalizeFundingSymbolsRequest(nil); err != nil || symbols != nil {
+		t.Errorf("空列表应返回 nil: %v, %v", symbols, err)
+	}
+
+	if _, err := normalizeFundingSymbolsRequest([]string{"BTC/USDT"}); err == nil {
+		t.Error("非法交易对应返回错误")
+	}
+
+	tooMany := make([]string, 0, maxFundingSymbols+1)
+	for i := 0; i <= maxFundingSymbols; i++ {
+		tooMany = append(tooMany, "SYM"+string(rune('A'+i/26%26))+string(rune('A'+i%26))+"USDT")
+	}
+	if _, err := normalizeFundingSymbolsRequest(tooMany); err == nil {
+		t.Error("超过上限应返回错误")
+	}
+}
@@ -0,0 +1,40 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/monitor"
+)
+
+// GoalProvider 盈利目标提供者接口
+type GoalProvider interface {
+	GetProgress() *monitor.GoalProgress
+}
+
+// SetGoalProvider 设置盈利目标提供者
+func SetGoalProvider(provider GoalProvider) {
+	defaultProviders.Goal = provider
+}
+
+// getGoalProgress 获取日/周盈利目标与日亏损上限的完成进度
+// GET /api/goals
+func getGoalProgress(c *gin.Context) {
+	goalProvider := providersOf(c).Goal
+	if goalProvider == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+
+	progress := goalProvider.GetProgress()
+	if progress == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": true, "ready": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":  true,
+		"ready":    true,
+		"progress": progress,
+	})
+}
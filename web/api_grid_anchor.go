@@ -0,0 +1,91 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/storage"
+)
+
+// GridAnchorRequest 手动设置网格锚点请求
+type GridAnchorRequest struct {
+	Exchange string  `json:"exchange"`
+	Symbol   string  `json:"symbol"`
+	Price    float64 `json:"price"`
+}
+
+// getGridAnchor 查询网格锚点：运行中的锚点与已保存（重启后沿用）的锚点
+// GET /api/symbols/anchor?exchange=binance&symbol=BTCUSDT
+func getGridAnchor(c *gin.Context) {
+	storageServiceProvider := providersOf(c).Storage
+	exchangeName, symbol := c.Query("exchange"), strings.ToUpper(c.Query("symbol"))
+	if exchangeName == "" && globalConfig != nil {
+		exchangeName = globalConfig.App.CurrentExchange
+	}
+	if symbol == "" {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("需要 symbol"))
+		return
+	}
+
+	resp := gin.H{"exchange": exchangeName, "symbol": symbol}
+	providersMu.RLock()
+	pm, ok := positionProviders[makeSymbolKey(exchangeName, symbol)]
+	providersMu.RUnlock()
+	if ok {
+		if anchored, ok := pm.(interface{ GetAnchorPrice() float64 }); ok {
+			resp["current"] = anchored.GetAnchorPrice()
+		}
+	}
+
+	if storageServiceProvider != nil {
+		if st := storageServiceProvider.GetStorage(); st != nil {
+			saved, err := st.GetGridAnchor(exchangeName, symbol)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			resp["saved"] = saved
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// setGridAnchor 手动设置网格锚点（保存后在该交易对下次启动时生效）
+// PUT /api/symbols/anchor
+func setGridAnchor(c *gin.Context) {
+	storageServiceProvider := providersOf(c).Storage
+	var req GridAnchorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if req.Exchange == "" && globalConfig != nil {
+		req.Exchange = globalConfig.App.CurrentExchange
+	}
+	if req.Symbol == "" || req.Price <= 0 {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("需要 symbol，且 price 必须大于 0"))
+		return
+	}
+	if storageServiceProvider == nil || storageServiceProvider.GetStorage() == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.storage_unavailable")
+		return
+	}
+
+	resource := fmt.Sprintf("%s:%s", req.Exchange, req.Symbol)
+	anchor := &storage.GridAnchor{Exchange: req.Exchange, Symbol: req.Symbol, Price: req.Price, Source: "manual"}
+	if err := storageServiceProvider.GetStorage().SaveGridAnchor(anchor); err != nil {
+		LogAction(c, "grid_anchor_set", resource, req, "failed", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	LogAction(c, "grid_anchor_set", resource, req, "success", "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"anchor":  anchor,
+		"message": "锚点已保存，重启该交易对后生效",
+	})
+}
@@ -0,0 +1,115 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/position"
+)
+
+// GridRecenterProvider 网格重新居中提供者接口（需要从 main.go 注入）
+type GridRecenterProvider interface {
+	PlanRecenter(exchange, symbol string) (*position.RecenterPlan, error)
+	Recenter(exchange, symbol string) (*position.RecenterPlan, error)
+}
+
+// SetGridRecenterProvider 设置网格重新居中提供者
+func SetGridRecenterProvider(provider GridRecenterProvider) {
+	defaultProviders.GridRecenter = provider
+}
+
+// GridRecenterRequest 手动重新居中请求
+// confirm=false 时只返回撤单/补单计划；confirm=true 时 expected_anchor 必须与预览计划的 new_anchor 一致
+type GridRecenterRequest struct {
+	Exchange       string  `json:"exchange"`
+	Symbol         string  `json:"symbol"`
+	Confirm        bool    `json:"confirm"`
+	ExpectedAnchor float64 `json:"expected_anchor"`
+}
+
+// previewGridRecenter 预览重新居中计划（不撤单）
+// GET /api/grid/recenter?exchange=binance&symbol=BTCUSDT
+func previewGridRecenter(c *gin.Context) {
+	gridRecenterProvider := providersOf(c).GridRecenter
+	exchangeName, symbol := c.Query("exchange"), strings.ToUpper(c.Query("symbol"))
+	if exchangeName == "" && globalConfig != nil {
+		exchangeName = globalConfig.App.CurrentExchange
+	}
+	if symbol == "" {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("需要 symbol"))
+		return
+	}
+	if gridRecenterProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.grid_recenter_failed", fmt.Errorf("交易服务未就绪"))
+		return
+	}
+
+	plan, err := gridRecenterProvider.PlanRecenter(exchangeName, symbol)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "error.grid_recenter_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"plan": plan})
+}
+
+// recenterGrid 手动重新居中：先以 confirm=false 获取计划，确认后带上 expected_anchor 执行
+// POST /api/grid/recenter
+func recenterGrid(c *gin.Context) {
+	gridRecenterProvider := providersOf(c).GridRecenter
+	var req GridRecenterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if req.Exchange == "" && globalConfig != nil {
+		req.Exchange = globalConfig.App.CurrentExchange
+	}
+	if req.Symbol == "" {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("需要 symbol"))
+		return
+	}
+	if gridRecenterProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.grid_recenter_failed", fmt.Errorf("交易服务未就绪"))
+		return
+	}
+
+	plan, err := gridRecenterProvider.PlanRecenter(req.Exchange, req.Symbol)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "error.grid_recenter_failed", err)
+		return
+	}
+	if !req.Confirm {
+		c.JSON(http.StatusOK, gin.H{
+			"executed": false,
+			"plan":     plan,
+			"message":  "请确认计划后以 confirm=true 和 expected_anchor 重新提交",
+		})
+		return
+	}
+	// 价格在预览后移动到了其它网格，要求重新确认
+	if req.ExpectedAnchor != plan.NewAnchor {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    T(c, "error.grid_recenter_plan_changed"),
+			"executed": false,
+			"plan":     plan,
+		})
+		return
+	}
+
+	resource := fmt.Sprintf("%s:%s", req.Exchange, req.Symbol)
+	plan, err = gridRecenterProvider.Recenter(req.Exchange, req.Symbol)
+	if err != nil {
+		LogAction(c, "grid_recenter", resource, req, "failed", err.Error())
+		respondError(c, http.StatusBadRequest, "error.grid_recenter_failed", err)
+		return
+	}
+	LogAction(c, "grid_recenter", resource, plan, "success", "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"executed": true,
+		"plan":     plan,
+	})
+}
@@ -0,0 +1,57 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/monitor"
+)
+
+// IncomeReconciliationProvider 盈亏对账提供者接口（需要从 main.go 注入）
+type IncomeReconciliationProvider interface {
+	GetIncomeReconciliation(exchange, symbol string, start, end time.Time) (*monitor.IncomeReconciliationReport, error)
+}
+
+// SetIncomeReconciliationProvider 设置盈亏对账提供者
+func SetIncomeReconciliationProvider(provider IncomeReconciliationProvider) {
+	defaultProviders.IncomeReconciliation = provider
+}
+
+// getIncomeReconciliation 比对本地计算的盈亏与交易所账户流水（已实现盈亏、手续费、资金费）
+// GET /api/income/reconciliation?exchange=binance&symbol=BTCUSDT&hours=24
+func getIncomeReconciliation(c *gin.Context) {
+	incomeReconciliationProvider := providersOf(c).IncomeReconciliation
+	exchangeName, symbol := c.Query("exchange"), strings.ToUpper(c.Query("symbol"))
+	if exchangeName == "" && globalConfig != nil {
+		exchangeName = globalConfig.App.CurrentExchange
+	}
+	if symbol == "" {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("需要 symbol"))
+		return
+	}
+	if incomeReconciliationProvider == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+
+	hours := 24
+	if globalConfig != nil && globalConfig.IncomeSync.ReportHours > 0 {
+		hours = globalConfig.IncomeSync.ReportHours
+	}
+	if h, err := strconv.Atoi(c.Query("hours")); err == nil && h > 0 {
+		hours = h
+	}
+	end := time.Now()
+	start := end.Add(-time.Duration(hours) * time.Hour)
+
+	report, err := incomeReconciliationProvider.GetIncomeReconciliation(exchangeName, symbol, start, end)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "error.income_reconciliation_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "report": report})
+}
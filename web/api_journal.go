@@ -0,0 +1,158 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/storage"
+)
+
+// JournalProvider 交易日志提供者接口
+type JournalProvider interface {
+	Query(q storage.JournalQuery) ([]*storage.JournalEntry, error)
+	AddNote(entry *storage.JournalEntry) error
+	Record(exchange, symbol, eventType, title, content string)
+}
+
+// SetJournalProvider 设置交易日志提供者
+func SetJournalProvider(provider JournalProvider) {
+	defaultProviders.Journal = provider
+}
+
+// journalEventConfigChange 参数变更日志的事件类型
+const journalEventConfigChange = "config_change"
+
+// recordJournal 记录一条参数变更日志（交易日志未启用时忽略）
+func recordJournal(c *gin.Context, exchange, symbol, title, content string) {
+	journalProvider := providersOf(c).Journal
+	if journalProvider == nil {
+		return
+	}
+	journalProvider.Record(exchange, symbol, journalEventConfigChange, title, content)
+}
+
+// getJournalEntries 查询交易日志（按时间倒序）
+// GET /api/journal?exchange=&symbol=&strategy=&source=auto|manual&start_time=&end_time=&limit=
+func getJournalEntries(c *gin.Context) {
+	journalProvider := providersOf(c).Journal
+	if journalProvider == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false, "entries": []*storage.JournalEntry{}, "count": 0})
+		return
+	}
+
+	q := storage.JournalQuery{
+		Exchange: c.Query("exchange"),
+		Symbol:   c.Query("symbol"),
+		Strategy: c.Query("strategy"),
+		Source:   c.Query("source"),
+	}
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "100")); err == nil && l > 0 {
+		q.Limit = l
+	}
+	if startTimeStr := c.Query("start_time"); startTimeStr != "" {
+		t, err := time.Parse(time.RFC3339, startTimeStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_start_time")
+			return
+		}
+		q.StartTime = t
+	}
+	if endTimeStr := c.Query("end_time"); endTimeStr != "" {
+		t, err := time.Parse(time.RFC3339, endTimeStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_end_time")
+			return
+		}
+		q.EndTime = t
+	}
+
+	entries, err := journalProvider.Query(q)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "error.query_journal_failed", err)
+		return
+	}
+	if entries == nil {
+		entries = []*storage.JournalEntry{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": true,
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// JournalNoteRequest 手动笔记请求
+type JournalNoteRequest struct {
+	Exchange string   `json:"exchange"`
+	Symbol   string   `json:"symbol"`
+	Strategy string   `json:"strategy"`
+	Title    string   `json:"title"`
+	Content  string   `json:"content"`
+	Tags     []string `json:"tags"`
+}
+
+// addJournalNote 添加手动笔记（记录时的累计已实现盈亏由服务端补充）
+// POST /api/journal
+func addJournalNote(c *gin.Context) {
+	journalProvider := providersOf(c).Journal
+	if journalProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.journal_not_enabled")
+		return
+	}
+
+	var req JournalNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	req.Content = strings.TrimSpace(req.Content)
+	if req.Title == "" && req.Content == "" {
+		respondError(c, http.StatusBadRequest, "error.journal_note_empty")
+		return
+	}
+	if req.Title == "" {
+		// 标题缺省取内容的第一行
+		req.Title = strings.SplitN(req.Content, "\n", 2)[0]
+	}
+
+	tags := make([]string, 0, len(req.Tags))
+	for _, t := range req.Tags {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+
+	entry := &storage.JournalEntry{
+		Exchange: req.Exchange,
+		Symbol:   req.Symbol,
+		Strategy: req.Strategy,
+		Title:    req.Title,
+		Content:  req.Content,
+		Tags:     strings.Join(tags, ","),
+	}
+	if err := journalProvider.AddNote(entry); err != nil {
+		respondError(c, http.StatusInternalServerError, "error.save_journal_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"entry":   entry,
+	})
+}
+
+// isSensitiveConfigPath 判断配置路径是否为密钥、口令等敏感字段
+func isSensitiveConfigPath(path string) bool {
+	p := strings.ToLower(path)
+	for _, kw := range []string{"key", "secret", "password", "passphrase", "token"} {
+		if strings.Contains(p, kw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package web
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/storage"
+)
+
+type fakeJournalProvider struct {
+	notes []*storage.JournalEntry
+}
+
+func (f *fakeJournalProvider) Query(q storage.JournalQuery) ([]*storage.JournalEntry, error) {
+	return f.notes, nil
+}
+
+func (f *fakeJournalProvider) AddNote(entry *storage.JournalEntry) error {
+	f.notes = append(f.notes, entry)
+	return nil
+}
+
+func (f *fakeJournalProvider) Record(exchange, symbol, eventType, title, content string) {}
+
+func TestAddJournalNote(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fake := &fakeJournalProvider{}
+	SetJournalProvider(fake)
+	defer SetJournalProvider(nil)
+
+	r := gin.New()
+	r.POST("/api/journal", addJournalNote)
+
+	post := func(body string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/journal", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := post(`{"title":"  ","content":""}`); code != http.StatusBadRequest {
+		t.Fatalf("empty note: got status %d, want 400", code)
+	}
+	if code := post(`{"symbol":"BTCUSDT","content":"加仓理由\n第二行","tags":["review"," ",  "btc"]}`); code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", code)
+	}
+	if len(fake.notes) != 1 {
+		t.Fatalf("got %d notes, want 1", len(fake.notes))
+	}
+	note := fake.notes[0]
+	if note.Title != "加仓理由" || note.Tags != "review,btc" || note.Symbol != "BTCUSDT" {
+		t.Errorf("unexpected note: %+v", note)
+	}
+}
+
+func TestIsSensitiveConfigPath(t *testing.T) {
+	for path, want := range map[string]bool{
+		"exchanges.binance.api_key":    true,
+		"exchanges.binance.secret_key": true,
+		"web.password":                 true,
+		"trading.price_interval":       false,
+	} {
+		if got := isSensitiveConfigPath(path); got != want {
+			t.Errorf("%s: got %v, want %v", path, got, want)
+		}
+	}
+}
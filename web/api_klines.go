@@ -0,0 +1,110 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/monitor"
+	"quantmesh/storage"
+)
+
+// KlineHistoryProvider 本地 K 线与缺口补齐提供者接口（需要从 main.go 注入）
+type KlineHistoryProvider interface {
+	GetKlineCoverage(exchange, symbol string) ([]*monitor.KlineCoverage, error)
+	BackfillKlines(ctx context.Context, exchange, symbol string) ([]*monitor.KlineCoverage, error)
+	GetStoredKlines(exchange, symbol, interval string, limit int) ([]*storage.Kline, error)
+}
+
+// SetKlineHistoryProvider 设置本地 K 线提供者
+func SetKlineHistoryProvider(provider KlineHistoryProvider) {
+	defaultProviders.KlineHistory = provider
+}
+
+// klineTarget 解析请求的交易所和交易对（交易所默认当前交易所）
+func klineTarget(c *gin.Context) (string, string, error) {
+	exchangeName, symbol := c.Query("exchange"), strings.ToUpper(c.Query("symbol"))
+	if exchangeName == "" && globalConfig != nil {
+		exchangeName = globalConfig.App.CurrentExchange
+	}
+	if symbol == "" {
+		return "", "", fmt.Errorf("需要 symbol")
+	}
+	return exchangeName, symbol, nil
+}
+
+// getKlineGaps 各周期最近一次缺口检测的结果（检测区间、已保存根数、仍缺失的区间）
+// GET /api/klines/gaps?exchange=binance&symbol=BTCUSDT
+func getKlineGaps(c *gin.Context) {
+	klineHistoryProvider := providersOf(c).KlineHistory
+	exchangeName, symbol, err := klineTarget(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	if klineHistoryProvider == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	coverage, err := klineHistoryProvider.GetKlineCoverage(exchangeName, symbol)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "error.kline_backfill_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "coverage": coverage})
+}
+
+// backfillKlines 立即检测并补齐该交易对全部周期的缺口
+// POST /api/klines/backfill?exchange=binance&symbol=BTCUSDT
+func backfillKlines(c *gin.Context) {
+	klineHistoryProvider := providersOf(c).KlineHistory
+	exchangeName, symbol, err := klineTarget(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	if klineHistoryProvider == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	coverage, err := klineHistoryProvider.BackfillKlines(c.Request.Context(), exchangeName, symbol)
+	if err != nil {
+		LogAction(c, "kline_backfill", symbol, gin.H{"exchange": exchangeName}, "failed", err.Error())
+		respondError(c, http.StatusBadRequest, "error.kline_backfill_failed", err)
+		return
+	}
+	LogAction(c, "kline_backfill", symbol, gin.H{"exchange": exchangeName}, "success", "")
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "coverage": coverage})
+}
+
+// getStoredKlines 本地保存的 K 线（/api/klines?source=local），返回格式与交易所实时查询一致
+func getStoredKlines(c *gin.Context, symbol, interval string, limit int) {
+	klineHistoryProvider := providersOf(c).KlineHistory
+	if klineHistoryProvider == nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("未启用 K 线缺口补齐，没有本地 K 线"))
+		return
+	}
+	exchangeName := c.Query("exchange")
+	if exchangeName == "" && globalConfig != nil {
+		exchangeName = globalConfig.App.CurrentExchange
+	}
+	stored, err := klineHistoryProvider.GetStoredKlines(exchangeName, symbol, interval, limit)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "error.kline_backfill_failed", err)
+		return
+	}
+	klines := make([]KlineData, len(stored))
+	for i, k := range stored {
+		klines[i] = KlineData{
+			Time:   k.OpenTime.Unix(),
+			Open:   k.Open,
+			High:   k.High,
+			Low:    k.Low,
+			Close:  k.Close,
+			Volume: k.Volume,
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"klines": klines, "symbol": symbol, "interval": interval, "source": "local"})
+}
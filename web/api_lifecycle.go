@@ -0,0 +1,135 @@
+package web
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LifecycleHealth 存活检查结果
+type LifecycleHealth struct {
+	Status        string `json:"status"`
+	Mode          string `json:"mode"` // trading / maintenance / setup / standby
+	Draining      bool   `json:"draining"`
+	Symbols       int    `json:"symbols"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+}
+
+// FastCancelResult 快速撤单结果
+type FastCancelResult struct {
+	Symbols   int      `json:"symbols"`
+	Canceled  int      `json:"canceled"`
+	Failed    []string `json:"failed"`
+	Kept      int      `json:"kept"`    // 按策略退出方式保留在交易所的挂单数
+	Skipped   bool     `json:"skipped"` // 所有策略的退出方式均为 leave，只暂停不撤单
+	ElapsedMs int64    `json:"elapsed_ms"`
+}
+
+// LifecycleProvider 容器生命周期提供者接口（需要从 main.go 注入）
+type LifecycleProvider interface {
+	Health() LifecycleHealth
+	Ready() (bool, string)
+	PreStop() FastCancelResult
+}
+
+// RestartResult 协调重启请求结果
+type RestartResult struct {
+	Token    string           `json:"token"`     // 恢复令牌（新进程启动时校验）
+	ExitCode int              `json:"exit_code"` // 退出码，进程管理器据此立即拉起新进程
+	Cancel   FastCancelResult `json:"cancel"`    // 按各策略退出方式的撤单结果
+}
+
+// RestartProvider 协调重启提供者接口（需要从 main.go 注入）
+type RestartProvider interface {
+	Restart() (RestartResult, error)
+}
+
+// SetRestartProvider 设置协调重启提供者
+func SetRestartProvider(provider RestartProvider) {
+	defaultProviders.Restart = provider
+}
+
+// SetLifecycleProvider 设置容器生命周期提供者
+func SetLifecycleProvider(provider LifecycleProvider) {
+	defaultProviders.Lifecycle = provider
+}
+
+// getHealth 存活检查（不需要认证，供 Docker HEALTHCHECK / livenessProbe 使用）
+// GET /api/health
+func getHealth(c *gin.Context) {
+	lifecycleProvider := providersOf(c).Lifecycle
+	if lifecycleProvider == nil {
+		c.JSON(http.StatusOK, LifecycleHealth{Status: "ok", Mode: "starting"})
+		return
+	}
+	c.JSON(http.StatusOK, lifecycleProvider.Health())
+}
+
+// getReady 就绪检查（不需要认证，供 readinessProbe 使用），排空中或交易对未启动时返回 503
+// GET /api/ready
+func getReady(c *gin.Context) {
+	lifecycleProvider := providersOf(c).Lifecycle
+	if lifecycleProvider == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"ready": false, "reason": "starting"})
+		return
+	}
+	ready, reason := lifecycleProvider.Ready()
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, gin.H{"ready": ready, "reason": reason})
+}
+
+// preStopHook 容器 PreStop 钩子：暂停交易并快速撤单，返回后编排器再发送 SIGTERM
+// 需经由本地管理 socket 调用，或携带与 system.prestop_token 一致的 X-PreStop-Token 头
+// POST /api/lifecycle/prestop
+func preStopHook(c *gin.Context) {
+	lifecycleProvider := providersOf(c).Lifecycle
+	if !preStopAllowed(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+	if lifecycleProvider == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "not ready"})
+		return
+	}
+	c.JSON(http.StatusOK, lifecycleProvider.PreStop())
+}
+
+// restartSystem 协调重启：按各策略的退出方式撤单、保存恢复令牌后以 system.restart.exit_code 退出，
+// 新进程校验令牌后沿用原网格并接管保留的挂单（需由 systemd / Docker restart 策略拉起）
+// POST /api/system/restart
+func restartSystem(c *gin.Context) {
+	restartProvider := providersOf(c).Restart
+	if restartProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.restart_unavailable")
+		return
+	}
+	result, err := restartProvider.Restart()
+	if err != nil {
+		LogAction(c, "restart", "system", nil, "failed", err.Error())
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	LogAction(c, "restart", "system", result, "success", "")
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"restart": result,
+	})
+}
+
+// preStopAllowed PreStop 调用方校验：经由本地管理 socket 的请求直接放行，
+// 否则必须配置 system.prestop_token 并在 X-PreStop-Token 头中携带
+// 不按来源地址放行：部署在反向代理之后时所有请求的来源都是本机
+func preStopAllowed(c *gin.Context) bool {
+	if isLocalAdmin(c.Request) {
+		return true
+	}
+	if globalConfig == nil || globalConfig.System.PreStopToken == "" {
+		return false
+	}
+	token := c.GetHeader("X-PreStop-Token")
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(globalConfig.System.PreStopToken)) == 1
+}
@@ -0,0 +1,154 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/config"
+)
+
+type fakeLifecycleProvider struct {
+	mode     string
+	ready    bool
+	reason   string
+	preStops int
+}
+
+func (f *fakeLifecycleProvider) Health() LifecycleHealth {
+	return LifecycleHealth{Status: "ok", Mode: f.mode, Symbols: 2}
+}
+
+func (f *fakeLifecycleProvider) Ready() (bool, string) {
+	return f.ready, f.reason
+}
+
+func (f *fakeLifecycleProvider) PreStop() FastCancelResult {
+	f.preStops++
+	return FastCancelResult{Symbols: 2, Canceled: 2, Failed: []string{}}
+}
+
+func newLifecycleTestRouter(provider LifecycleProvider) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(providersMiddleware(&Providers{Lifecycle: provider}))
+	r.GET("/api/health", getHealth)
+	r.GET("/api/ready", getReady)
+	r.POST("/api/lifecycle/prestop", preStopHook)
+	return r
+}
+
+func TestHealthAndReady(t *testing.T) {
+	do := func(r *gin.Engine, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	// 尚未注入提供者：存活但未就绪
+	r := newLifecycleTestRouter(nil)
+	if w := do(r, "/api/health"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"starting"`) {
+		t.Fatalf("health without provider: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(r, "/api/ready"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("ready without provider: got %d, want 503", w.Code)
+	}
+
+	provider := &fakeLifecycleProvider{ready: true, reason: "trading"}
+	r = newLifecycleTestRouter(provider)
+	if w := do(r, "/api/health"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"symbols":2`) {
+		t.Fatalf("health: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(r, "/api/ready"); w.Code != http.StatusOK {
+		t.Fatalf("ready: got %d, want 200", w.Code)
+	}
+	provider.ready, provider.reason = false, "draining"
+	if w := do(r, "/api/ready"); w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "draining") {
+		t.Fatalf("draining: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestPreStopHookAuthorization(t *testing.T) {
+	saved := globalConfig
+	defer func() { globalConfig = saved }()
+
+	tests := []struct {
+		name       string
+		token      string // 配置的 prestop_token
+		header     string
+		query      string
+		localAdmin bool
+		want       int
+	}{
+		{name: "no token configured, loopback", want: http.StatusForbidden},
+		{name: "no token configured, admin socket", localAdmin: true, want: http.StatusOK},
+		{name: "token missing", token: "secret", want: http.StatusForbidden},
+		{name: "token wrong", token: "secret", header: "guess", want: http.StatusForbidden},
+		{name: "token in query ignored", token: "secret", query: "secret", want: http.StatusForbidden},
+		{name: "token matches", token: "secret", header: "secret", want: http.StatusOK},
+		{name: "admin socket without token", token: "secret", localAdmin: true, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.System.PreStopToken = tt.token
+			globalConfig = cfg
+
+			provider := &fakeLifecycleProvider{}
+			r := newLifecycleTestRouter(provider)
+			path := "/api/lifecycle/prestop"
+			if tt.query != "" {
+				path += "?token=" + tt.query
+			}
+			req := httptest.NewRequest(http.MethodPost, path, nil)
+			req.RemoteAddr = "127.0.0.1:34567"
+			if tt.header != "" {
+				req.Header.Set("X-PreStop-Token", tt.header)
+			}
+			if tt.localAdmin {
+				req = req.WithContext(context.WithValue(req.Context(), localAdminKey{}, true))
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("got status %d, want %d (%s)", w.Code, tt.want, w.Body.String())
+			}
+			wantCalls := 0
+			if tt.want == http.StatusOK {
+				wantCalls = 1
+			}
+			if provider.preStops != wantCalls {
+				t.Fatalf("PreStop called %d times, want %d", provider.preStops, wantCalls)
+			}
+		})
+	}
+}
+
+func TestPreStopRouteIsPostOnly(t *testing.T) {
+	saved := globalConfig
+	defer func() { globalConfig = saved }()
+
+	gin.SetMode(gin.TestMode)
+	provider := &fakeLifecycleProvider{}
+	r := gin.New()
+	SetupRoutesWithProviders(r, &config.Config{}, &Providers{Lifecycle: provider})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/lifecycle/prestop", nil)
+	req = req.WithContext(context.WithValue(req.Context(), localAdminKey{}, true))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code == http.StatusOK || provider.preStops != 0 {
+		t.Fatalf("GET prestop must not trigger PreStop: status %d, calls %d", w.Code, provider.preStops)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/lifecycle/prestop", nil)
+	req = req.WithContext(context.WithValue(req.Context(), localAdminKey{}, true))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || provider.preStops != 1 {
+		t.Fatalf("POST prestop via admin socket: status %d, calls %d (%s)", w.Code, provider.preStops, w.Body.String())
+	}
+}
@@ -0,0 +1,86 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/logger"
+)
+
+// LoggingSettings 当前日志设置
+type LoggingSettings struct {
+	Profile string            `json:"profile"` // normal / quiet / verbose
+	Level   string            `json:"level"`   // 全局日志级别
+	Modules map[string]string `json:"modules"` // 模块（包路径）-> 覆盖的日志级别
+}
+
+// LoggingUpdateRequest 运行时调整日志设置（不写回配置文件，重启后恢复配置）
+// modules 中级别为空字符串表示取消该模块的覆盖
+type LoggingUpdateRequest struct {
+	Profile string            `json:"profile"`
+	Modules map[string]string `json:"modules"`
+}
+
+func currentLoggingSettings() LoggingSettings {
+	return LoggingSettings{
+		Profile: logger.GetProfile(),
+		Level:   logger.GetLevel().String(),
+		Modules: logger.ModuleLevels(),
+	}
+}
+
+// getLoggingSettings 获取日志配置档与模块级别
+// GET /api/system/logging
+func getLoggingSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, currentLoggingSettings())
+}
+
+// updateLoggingSettings 运行时切换日志配置档，或按模块开关 DEBUG（如 {"modules":{"position":"debug","exchange":""}}）
+// PUT /api/system/logging
+func updateLoggingSettings(c *gin.Context) {
+	var req LoggingUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	levels := make(map[string]logger.LogLevel, len(req.Modules))
+	for module, level := range req.Modules {
+		if strings.Trim(strings.TrimSpace(module), "/") == "" {
+			respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("模块名不能为空"))
+			return
+		}
+		if level == "" {
+			continue
+		}
+		switch strings.ToUpper(strings.TrimSpace(level)) {
+		case "DEBUG", "INFO", "WARN", "WARNING", "ERROR", "FATAL":
+			levels[module] = logger.ParseLogLevel(level)
+		default:
+			respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("无效的日志级别: %s", level))
+			return
+		}
+	}
+	if req.Profile != "" {
+		if err := logger.SetProfile(req.Profile); err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+			return
+		}
+	}
+	for module, level := range req.Modules {
+		if level == "" {
+			logger.ClearModuleLevel(module)
+		} else {
+			logger.SetModuleLevel(module, levels[module])
+		}
+	}
+
+	settings := currentLoggingSettings()
+	LogAction(c, "update_logging", "system", req, "success", "")
+	logger.Warn("📝 日志设置已更新: 配置档 %s, 模块级别 %v", settings.Profile, settings.Modules)
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"settings": settings,
+	})
+}
@@ -0,0 +1,49 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/logger"
+)
+
+func TestUpdateLoggingSettings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer func() {
+		logger.SetProfile(logger.ProfileNormal)
+		logger.ClearModuleLevel("position")
+	}()
+	r := gin.New()
+	r.PUT("/api/system/logging", updateLoggingSettings)
+
+	put := func(body string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/system/logging", strings.NewReader(body)))
+		return w.Code
+	}
+
+	if code := put(`{"profile":"quiet","modules":{"position":"debug"}}`); code != http.StatusOK {
+		t.Fatalf("update: got %d", code)
+	}
+	if !logger.IsQuiet() || logger.ModuleLevels()["position"] != "DEBUG" {
+		t.Fatalf("settings not applied: %+v", currentLoggingSettings())
+	}
+
+	// 无效级别整体拒绝，不部分生效
+	if code := put(`{"profile":"verbose","modules":{"order":"loud"}}`); code != http.StatusBadRequest {
+		t.Fatalf("invalid level: got %d", code)
+	}
+	if !logger.IsQuiet() {
+		t.Error("profile should be unchanged after a rejected request")
+	}
+
+	if code := put(`{"modules":{"position":""}}`); code != http.StatusOK {
+		t.Fatalf("clear: got %d", code)
+	}
+	if _, ok := logger.ModuleLevels()["position"]; ok {
+		t.Error("module override should be cleared")
+	}
+}
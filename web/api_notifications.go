@@ -0,0 +1,74 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"quantmesh/notify"
+)
+
+// NotificationPreviewRequest 通知模板预览/测试请求
+type NotificationPreviewRequest struct {
+	EventType string                 `json:"event_type" binding:"required"`
+	Channel   string                 `json:"channel"` // 渠道标识（telegram / email / ntfy 等），为空时按通用模板预览
+	Title     string                 `json:"title"`   // 临时模板（编辑时预览），与 body 都为空时使用已配置的模板
+	Body      string                 `json:"body"`
+	Data      map[string]interface{} `json:"data"` // 示例事件数据，为空时使用内置的成交示例
+}
+
+// NotificationTemplateProvider 通知模板预览和测试提供者接口（需要从 main.go 注入）
+type NotificationTemplateProvider interface {
+	PreviewNotification(req NotificationPreviewRequest) (*notify.TemplatePreview, error)
+	SendTestNotification(req NotificationPreviewRequest) map[string]string
+}
+
+// SetNotificationTemplateProvider 设置通知模板提供者
+func SetNotificationTemplateProvider(provider NotificationTemplateProvider) {
+	defaultProviders.NotificationTemplate = provider
+}
+
+// previewNotification 按模板渲染示例事件（不发送），模板语法或字段错误时返回具体原因
+// POST /api/notifications/preview
+func previewNotification(c *gin.Context) {
+	notificationTemplateProvider := providersOf(c).NotificationTemplate
+	var req NotificationPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	if notificationTemplateProvider == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	preview, err := notificationTemplateProvider.PreviewNotification(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "preview": preview})
+}
+
+// testNotification 通过全部已启用的渠道立即发送示例事件（按已配置的模板，不经过通知规则过滤）
+// POST /api/notifications/test
+func testNotification(c *gin.Context) {
+	notificationTemplateProvider := providersOf(c).NotificationTemplate
+	var req NotificationPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	if notificationTemplateProvider == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	results := notificationTemplateProvider.SendTestNotification(req)
+	status := "success"
+	for _, result := range results {
+		if result != "" {
+			status = "failed"
+		}
+	}
+	LogAction(c, "notification_test", req.EventType, results, status, "")
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "results": results})
+}
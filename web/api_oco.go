@@ -0,0 +1,124 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/order"
+)
+
+// OCOProvider OCO 止盈/止损关联订单提供者接口（需要从 main.go 注入）
+type OCOProvider interface {
+	GetOCOLinks(exchange, symbol string) ([]order.OCOLink, error)
+	PlaceOCO(exchange, symbol string, req *order.OCORequest) (*order.OCOLink, error)
+	CancelOCO(exchange, symbol, linkID string) error
+}
+
+// SetOCOProvider 设置 OCO 提供者
+func SetOCOProvider(provider OCOProvider) {
+	defaultProviders.OCO = provider
+}
+
+// PlaceOCORequest 挂出 OCO 请求
+type PlaceOCORequest struct {
+	Exchange        string  `json:"exchange"`
+	Symbol          string  `json:"symbol" binding:"required"`
+	Side            string  `json:"side" binding:"required"` // 平仓方向：多仓为 SELL，空仓为 BUY
+	Quantity        float64 `json:"quantity" binding:"required"`
+	TakeProfitPrice float64 `json:"take_profit_price" binding:"required"`
+	StopPrice       float64 `json:"stop_price" binding:"required"`
+}
+
+// getOCOLinks 获取交易对的 OCO 关联订单
+// GET /api/oco?exchange=binance&symbol=BTCUSDT
+func getOCOLinks(c *gin.Context) {
+	ocoProvider := providersOf(c).OCO
+	exchangeName, symbol := c.Query("exchange"), strings.ToUpper(c.Query("symbol"))
+	if exchangeName == "" && globalConfig != nil {
+		exchangeName = globalConfig.App.CurrentExchange
+	}
+	if symbol == "" {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("需要 symbol"))
+		return
+	}
+	if ocoProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.oco_failed", fmt.Errorf("交易服务未就绪"))
+		return
+	}
+
+	links, err := ocoProvider.GetOCOLinks(exchangeName, symbol)
+	if err != nil {
+		respondExchangeError(c, http.StatusBadRequest, "error.oco_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"links": links})
+}
+
+// placeOCO 挂出止盈/止损关联订单
+// POST /api/oco
+func placeOCO(c *gin.Context) {
+	ocoProvider := providersOf(c).OCO
+	var req PlaceOCORequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	req.Side = strings.ToUpper(strings.TrimSpace(req.Side))
+	if req.Exchange == "" && globalConfig != nil {
+		req.Exchange = globalConfig.App.CurrentExchange
+	}
+	if ocoProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.oco_failed", fmt.Errorf("交易服务未就绪"))
+		return
+	}
+
+	resource := fmt.Sprintf("%s:%s", req.Exchange, req.Symbol)
+	link, err := ocoProvider.PlaceOCO(req.Exchange, req.Symbol, &order.OCORequest{
+		Side:            req.Side,
+		Quantity:        req.Quantity,
+		TakeProfitPrice: req.TakeProfitPrice,
+		StopPrice:       req.StopPrice,
+	})
+	if err != nil {
+		LogAction(c, "oco_place", resource, req, "failed", err.Error())
+		respondExchangeError(c, http.StatusBadRequest, "error.oco_failed", err)
+		return
+	}
+	LogAction(c, "oco_place", resource, req, "success", "")
+	recordJournal(c, req.Exchange, req.Symbol, "挂出 OCO 止盈止损",
+		fmt.Sprintf("%s 数量 %g，止盈 %g，止损 %g", req.Side, req.Quantity, req.TakeProfitPrice, req.StopPrice))
+
+	c.JSON(http.StatusOK, gin.H{"link": link})
+}
+
+// cancelOCO 撤销 OCO 关联订单
+// DELETE /api/oco/:link_id?exchange=binance&symbol=BTCUSDT
+func cancelOCO(c *gin.Context) {
+	ocoProvider := providersOf(c).OCO
+	linkID := c.Param("link_id")
+	exchangeName, symbol := c.Query("exchange"), strings.ToUpper(c.Query("symbol"))
+	if exchangeName == "" && globalConfig != nil {
+		exchangeName = globalConfig.App.CurrentExchange
+	}
+	if symbol == "" {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("需要 symbol"))
+		return
+	}
+	if ocoProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.oco_failed", fmt.Errorf("交易服务未就绪"))
+		return
+	}
+
+	resource := fmt.Sprintf("%s:%s:%s", exchangeName, symbol, linkID)
+	if err := ocoProvider.CancelOCO(exchangeName, symbol, linkID); err != nil {
+		LogAction(c, "oco_cancel", resource, nil, "failed", err.Error())
+		respondExchangeError(c, http.StatusBadRequest, "error.oco_failed", err)
+		return
+	}
+	LogAction(c, "oco_cancel", resource, nil, "success", "")
+
+	c.JSON(http.StatusOK, gin.H{"canceled": true, "link_id": linkID})
+}
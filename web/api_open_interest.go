@@ -0,0 +1,91 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/monitor"
+	"quantmesh/storage"
+)
+
+// OpenInterestProvider 持仓量监控提供者接口
+type OpenInterestProvider interface {
+	GetAllCurrentOpenInterest() []*storage.OpenInterestData
+	GetOpenInterestHistory(symbol string, hours, limit int) ([]*storage.OpenInterestData, error)
+	GetActiveSpikes() []*monitor.OpenInterestSpike
+}
+
+// SetOpenInterestProvider 设置持仓量监控提供者
+func SetOpenInterestProvider(provider OpenInterestProvider) {
+	defaultProviders.OpenInterest = provider
+}
+
+// getOpenInterestCurrent 获取各交易对最新的持仓量与大户多空比，以及生效中的持仓量突变
+// GET /api/open-interest/current?symbol=BTCUSDT
+func getOpenInterestCurrent(c *gin.Context) {
+	openInterestProvider := providersOf(c).OpenInterest
+	if openInterestProvider == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"enabled": false,
+			"data":    []*storage.OpenInterestData{},
+			"spikes":  []*monitor.OpenInterestSpike{},
+			"count":   0,
+		})
+		return
+	}
+
+	symbol := c.Query("symbol")
+	data := make([]*storage.OpenInterestData, 0)
+	for _, d := range openInterestProvider.GetAllCurrentOpenInterest() {
+		if symbol == "" || d.Symbol == symbol {
+			data = append(data, d)
+		}
+	}
+	spikes := make([]*monitor.OpenInterestSpike, 0)
+	for _, s := range openInterestProvider.GetActiveSpikes() {
+		if symbol == "" || s.Symbol == symbol {
+			spikes = append(spikes, s)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": true,
+		"data":    data,
+		"spikes":  spikes,
+		"count":   len(data),
+	})
+}
+
+// getOpenInterestHistory 获取持仓量历史
+// GET /api/open-interest/history?symbol=BTCUSDT&hours=24&limit=500
+func getOpenInterestHistory(c *gin.Context) {
+	openInterestProvider := providersOf(c).OpenInterest
+	if openInterestProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "errors.service_unavailable")
+		return
+	}
+
+	symbol := c.Query("symbol")
+	if symbol == "" {
+		respondError(c, http.StatusBadRequest, "errors.missing_parameter",
+			map[string]interface{}{"param": "symbol"})
+		return
+	}
+	hours, _ := strconv.Atoi(c.DefaultQuery("hours", "24"))
+	if hours <= 0 || hours > 24*30 {
+		hours = 24
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "500"))
+
+	history, err := openInterestProvider.GetOpenInterestHistory(symbol, hours, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "errors.internal_error", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  history,
+		"count": len(history),
+	})
+}
@@ -0,0 +1,140 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/safety"
+	"quantmesh/storage"
+)
+
+// OrderCleanerProvider 订单清理手动触发提供者接口（需要从 main.go 注入）
+type OrderCleanerProvider interface {
+	RunOrderCleanup(exchange, symbol string, dryRun bool) (*safety.OrderCleanupReport, error)
+}
+
+// SetOrderCleanerProvider 设置订单清理提供者
+func SetOrderCleanerProvider(provider OrderCleanerProvider) {
+	defaultProviders.OrderCleaner = provider
+}
+
+// OrderCleanupRunRequest 手动触发订单清理请求
+type OrderCleanupRunRequest struct {
+	Exchange string `json:"exchange"`
+	Symbol   string `json:"symbol"`
+	DryRun   *bool  `json:"dry_run"` // 为空时使用配置中的 dry_run
+}
+
+// getOrderCleanupHistory 查询订单清理记录（按时间倒序），可按订单号查找某个订单被撤销的原因
+// GET /api/safety/cleaner/history?exchange=&symbol=&reason=threshold|expired|orphan&order_id=&start_time=&end_time=&limit=
+func getOrderCleanupHistory(c *gin.Context) {
+	empty := gin.H{"actions": []*storage.OrderCleanupAction{}, "count": 0}
+	storageProv := PickStorageProvider(c)
+	if storageProv == nil {
+		c.JSON(http.StatusOK, empty)
+		return
+	}
+	st := storageProv.GetStorage()
+	if st == nil {
+		c.JSON(http.StatusOK, empty)
+		return
+	}
+
+	q := storage.OrderCleanupQuery{
+		Exchange: strings.ToLower(c.Query("exchange")),
+		Symbol:   strings.ToUpper(c.Query("symbol")),
+		Reason:   c.Query("reason"),
+	}
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "100")); err == nil && l > 0 {
+		q.Limit = l
+	}
+	if orderIDStr := c.Query("order_id"); orderIDStr != "" {
+		orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("order_id 无效"))
+			return
+		}
+		q.OrderID = orderID
+	}
+	if startTimeStr := c.Query("start_time"); startTimeStr != "" {
+		t, err := time.Parse(time.RFC3339, startTimeStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_start_time")
+			return
+		}
+		q.StartTime = t
+	}
+	if endTimeStr := c.Query("end_time"); endTimeStr != "" {
+		t, err := time.Parse(time.RFC3339, endTimeStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_end_time")
+			return
+		}
+		q.EndTime = t
+	}
+
+	actions, err := st.QueryOrderCleanupActions(q)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "error.order_cleanup_failed", err)
+		return
+	}
+	if actions == nil {
+		actions = []*storage.OrderCleanupAction{}
+	}
+
+	byReason := make(map[string]int)
+	for _, a := range actions {
+		byReason[a.Reason]++
+	}
+	resp := gin.H{
+		"actions":   actions,
+		"count":     len(actions),
+		"by_reason": byReason,
+	}
+	if globalConfig != nil {
+		resp["policy"] = globalConfig.Trading.OrderCleaner
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// runOrderCleanup 立即执行一次订单清理并返回本次的撤单动作（dry_run 时只返回将被撤销的订单）
+// POST /api/safety/cleaner/run
+func runOrderCleanup(c *gin.Context) {
+	orderCleanerProvider := providersOf(c).OrderCleaner
+	var req OrderCleanupRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if req.Exchange == "" && globalConfig != nil {
+		req.Exchange = globalConfig.App.CurrentExchange
+	}
+	if req.Symbol == "" {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("需要 symbol"))
+		return
+	}
+	if orderCleanerProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.order_cleanup_failed", fmt.Errorf("交易服务未就绪"))
+		return
+	}
+	dryRun := globalConfig != nil && globalConfig.Trading.OrderCleaner.DryRun
+	if req.DryRun != nil {
+		dryRun = *req.DryRun
+	}
+
+	resource := fmt.Sprintf("%s:%s", req.Exchange, req.Symbol)
+	report, err := orderCleanerProvider.RunOrderCleanup(req.Exchange, req.Symbol, dryRun)
+	if err != nil {
+		LogAction(c, "order_cleanup_run", resource, req, "failed", err.Error())
+		respondError(c, http.StatusBadRequest, "error.order_cleanup_failed", err)
+		return
+	}
+	LogAction(c, "order_cleanup_run", resource, gin.H{"dry_run": dryRun, "actions": len(report.Actions)}, "success", "")
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,37 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/safety"
+)
+
+// OrderExpiryStatus 单个交易对的挂单过期统计
+type OrderExpiryStatus struct {
+	Exchange      string `json:"exchange"`
+	Symbol        string `json:"symbol"`
+	MaxAgeMinutes int    `json:"max_age_minutes"` // 0 表示未启用
+	safety.OrderExpiryStats
+}
+
+// OrderExpiryProvider 挂单过期统计提供者接口（需要从 main.go 注入）
+type OrderExpiryProvider interface {
+	GetOrderExpiryStats() []OrderExpiryStatus
+}
+
+// SetOrderExpiryProvider 设置挂单过期统计提供者
+func SetOrderExpiryProvider(provider OrderExpiryProvider) {
+	defaultProviders.OrderExpiry = provider
+}
+
+// getOrderExpiryStats 获取各交易对的挂单过期撤单统计
+// GET /api/orders/expiry
+func getOrderExpiryStats(c *gin.Context) {
+	orderExpiryProvider := providersOf(c).OrderExpiry
+	stats := []OrderExpiryStatus{}
+	if orderExpiryProvider != nil {
+		stats = orderExpiryProvider.GetOrderExpiryStats()
+	}
+	c.JSON(http.StatusOK, gin.H{"symbols": stats})
+}
@@ -32,7 +32,7 @@ func CheckExchangePermissions(ctx context.Context, ex exchange.IExchange, exchan
 	}
 
 	// 检查交易所是否实现了 PermissionChecker 接口
-	checker, ok := ex.(exchange.PermissionChecker)
+	checker, ok := exchange.As[exchange.PermissionChecker](ex)
 	if !ok {
 		result.ErrorMessage = "该交易所暂不支持权限检测"
 		result.IsSecure = true // 假设安全，不阻止启动
@@ -0,0 +1,103 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/config"
+	"quantmesh/strategy"
+)
+
+// PortfolioOptimizerProvider 组合优化提供者接口（需要从 main.go 注入）
+type PortfolioOptimizerProvider interface {
+	GetReport() *strategy.PortfolioReport
+	Analyze(now time.Time) (*strategy.PortfolioReport, error)
+}
+
+// SetPortfolioOptimizerProvider 设置组合优化提供者
+func SetPortfolioOptimizerProvider(provider PortfolioOptimizerProvider) {
+	defaultProviders.PortfolioOptimizer = provider
+}
+
+// configuredMaxCapital 策略配置中的资金上限（max_capital），未配置时为 0
+func configuredMaxCapital(cfg config.StrategyConfig) float64 {
+	if val, ok := cfg.Config["max_capital"].(float64); ok {
+		return val
+	} else if val, ok := cfg.Config["max_capital"].(int); ok {
+		return float64(val)
+	}
+	return 0
+}
+
+// optimalAllocation 按组合优化最优方案计算的再平衡目标
+// 没有收益记录的已启用策略保持当前分配，其余资金按最优方案的权重分给有记录的策略
+type optimalAllocation struct {
+	weights     map[string]float64
+	weightTotal float64
+	budget      float64
+}
+
+// planOptimalAllocation 根据最近一次组合优化结果准备 optimal 模式的再平衡目标
+func planOptimalAllocation(provider PortfolioOptimizerProvider, configs map[string]config.StrategyConfig, enabled []string, totalBalance float64) (*optimalAllocation, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("组合优化未启用")
+	}
+	report := provider.GetReport()
+	if report == nil {
+		return nil, fmt.Errorf("组合优化尚未完成首次分析")
+	}
+	weights := report.Recommended()
+	if weights == nil {
+		return nil, fmt.Errorf("组合优化暂无建议: %s", report.Reason)
+	}
+
+	plan := &optimalAllocation{weights: weights, budget: totalBalance}
+	for _, id := range enabled {
+		if w, ok := weights[id]; ok {
+			plan.weightTotal += w
+		} else {
+			plan.budget -= configuredMaxCapital(configs[id])
+		}
+	}
+	if plan.weightTotal <= 0 {
+		return nil, fmt.Errorf("最优方案 %s 未给任何已启用策略分配资金", report.Best)
+	}
+	if plan.budget < 0 {
+		plan.budget = 0
+	}
+	return plan, nil
+}
+
+// target 策略的目标分配，prev 为当前分配
+func (p *optimalAllocation) target(id string, prev float64) float64 {
+	w, ok := p.weights[id]
+	if !ok {
+		return prev
+	}
+	return w / p.weightTotal * p.budget
+}
+
+// getRebalanceRecommendation 获取组合优化结果：各分配方案在回看期内的表现与推荐方案
+// GET /api/capital/rebalance/recommendation?refresh=true
+func getRebalanceRecommendation(c *gin.Context) {
+	provider := providersOf(c).PortfolioOptimizer
+	if provider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.portfolio_optimizer_failed", fmt.Errorf("组合优化未启用"))
+		return
+	}
+
+	report := provider.GetReport()
+	if report == nil || c.Query("refresh") == "true" {
+		var err error
+		if report, err = provider.Analyze(time.Now()); err != nil {
+			respondError(c, http.StatusInternalServerError, "error.portfolio_optimizer_failed", err)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"report":      report,
+		"recommended": report.Recommended(),
+	})
+}
@@ -0,0 +1,162 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SlotAdjustRequest 人工修正槽位库存请求
+type SlotAdjustRequest struct {
+	Exchange string  `json:"exchange"`
+	Symbol   string  `json:"symbol"`
+	Price    float64 `json:"price"`
+	Status   string  `json:"status"`   // FILLED/EMPTY
+	Quantity float64 `json:"quantity"` // 仅 FILLED 时使用
+	Reason   string  `json:"reason"`
+}
+
+// SlotAdjustResult 人工修正结果
+type SlotAdjustResult struct {
+	Exchange       string  `json:"exchange"`
+	Symbol         string  `json:"symbol"`
+	Price          float64 `json:"price"`
+	BeforeStatus   string  `json:"before_status"`
+	BeforeQty      float64 `json:"before_qty"`
+	AfterStatus    string  `json:"after_status"`
+	AfterQty       float64 `json:"after_qty"`
+	Reconciled     bool    `json:"reconciled"`
+	ReconcileError string  `json:"reconcile_error,omitempty"`
+}
+
+// SlotActionRequest 单槽位人工操作请求（价格取自路径参数）
+type SlotActionRequest struct {
+	Exchange string  `json:"exchange"`
+	Symbol   string  `json:"symbol"`
+	Price    float64 `json:"-"`
+	Action   string  `json:"action"` // lock/unlock/cancel/sell
+	Reason   string  `json:"reason"`
+}
+
+// SlotActionResult 单槽位人工操作结果
+type SlotActionResult struct {
+	Exchange        string   `json:"exchange"`
+	Symbol          string   `json:"symbol"`
+	Price           float64  `json:"price"`
+	Action          string   `json:"action"`
+	Locked          bool     `json:"locked"`
+	CanceledOrderID int64    `json:"canceled_order_id,omitempty"`
+	SellOrderID     int64    `json:"sell_order_id,omitempty"`
+	SellPrice       float64  `json:"sell_price,omitempty"`
+	SellQty         float64  `json:"sell_qty,omitempty"`
+	Slot            SlotInfo `json:"slot"`
+}
+
+// slotActions 支持的单槽位操作
+var slotActions = map[string]bool{"lock": true, "unlock": true, "cancel": true, "sell": true}
+
+// PositionEditorProvider 槽位库存人工修正提供者接口（需要从 main.go 注入）
+type PositionEditorProvider interface {
+	AdjustSlot(req SlotAdjustRequest, operator string) (*SlotAdjustResult, error)
+	SlotAction(req SlotActionRequest, operator string) (*SlotActionResult, error)
+}
+
+// SetPositionEditorProvider 设置槽位库存人工修正提供者
+func SetPositionEditorProvider(provider PositionEditorProvider) {
+	defaultProviders.PositionEditor = provider
+}
+
+// adjustSlot 人工修正槽位库存（应急操作，修正后立即触发对账）
+// POST /api/positions/slots/adjust
+func adjustSlot(c *gin.Context) {
+	positionEditorProvider := providersOf(c).PositionEditor
+	var req SlotAdjustRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	req.Status = strings.ToUpper(req.Status)
+	if req.Symbol == "" || req.Price <= 0 || (req.Status != "FILLED" && req.Status != "EMPTY") || strings.TrimSpace(req.Reason) == "" {
+		respondError(c, http.StatusBadRequest, "error.invalid_request",
+			fmt.Errorf("需要 symbol、price、status(FILLED/EMPTY) 和 reason"))
+		return
+	}
+	if positionEditorProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.adjust_slot_failed", fmt.Errorf("交易服务未就绪"))
+		return
+	}
+	if req.Exchange == "" && globalConfig != nil {
+		req.Exchange = globalConfig.App.CurrentExchange
+	}
+
+	operator := "admin"
+	if user, exists := c.Get("username"); exists {
+		operator = user.(string)
+	}
+
+	resource := fmt.Sprintf("%s:%s@%g", req.Exchange, req.Symbol, req.Price)
+	result, err := positionEditorProvider.AdjustSlot(req, operator)
+	if err != nil {
+		LogAction(c, "position_slot_adjust", resource, req, "failed", err.Error())
+		respondError(c, http.StatusBadRequest, "error.adjust_slot_failed", err)
+		return
+	}
+	LogAction(c, "position_slot_adjust", resource, result, "success", "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"result":  result,
+	})
+}
+
+// slotAction 单槽位人工操作：锁定（不再挂单）/解锁/撤销订单/强制卖出持仓，无需暂停整个网格
+// POST /api/slots/:price/action
+func slotAction(c *gin.Context) {
+	positionEditorProvider := providersOf(c).PositionEditor
+	var req SlotActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	price, err := strconv.ParseFloat(c.Param("price"), 64)
+	if err != nil || price <= 0 {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("无效的槽位价格: %s", c.Param("price")))
+		return
+	}
+	req.Price = price
+	req.Action = strings.ToLower(req.Action)
+	if req.Symbol == "" || !slotActions[req.Action] || strings.TrimSpace(req.Reason) == "" {
+		respondError(c, http.StatusBadRequest, "error.invalid_request",
+			fmt.Errorf("需要 symbol、action(lock/unlock/cancel/sell) 和 reason"))
+		return
+	}
+	if positionEditorProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.slot_action_failed", fmt.Errorf("交易服务未就绪"))
+		return
+	}
+	if req.Exchange == "" && globalConfig != nil {
+		req.Exchange = globalConfig.App.CurrentExchange
+	}
+
+	operator := "admin"
+	if user, exists := c.Get("username"); exists {
+		operator = user.(string)
+	}
+
+	resource := fmt.Sprintf("%s:%s@%g", req.Exchange, req.Symbol, req.Price)
+	result, err := positionEditorProvider.SlotAction(req, operator)
+	if err != nil {
+		LogAction(c, "position_slot_"+req.Action, resource, req, "failed", err.Error())
+		respondError(c, http.StatusBadRequest, "error.slot_action_failed", err)
+		return
+	}
+	LogAction(c, "position_slot_"+req.Action, resource, result, "success", "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"result":  result,
+	})
+}
@@ -133,13 +133,14 @@ func getProfitSummaryHandler(c *gin.Context) {
 	weekProfit := 0.0
 	monthProfit := 0.0
 
+	// 使用净盈亏（含资金费）
 	for _, s := range dailyStats {
-		monthProfit += s.TotalPnL
+		monthProfit += s.NetPnL
 		if s.Date.After(todayStart) || s.Date.Equal(todayStart) {
-			todayProfit += s.TotalPnL
+			todayProfit += s.NetPnL
 		}
 		if s.Date.After(weekStart) || s.Date.Equal(weekStart) {
-			weekProfit += s.TotalPnL
+			weekProfit += s.NetPnL
 		}
 	}
 
@@ -303,7 +304,7 @@ func getStrategyProfitDetailHandler(c *gin.Context) {
 	// 查询该币种的所有盈亏
 	startTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
 	now := time.Now()
-	summary, err := st.GetPnLBySymbol(strategyID, startTime, now)
+	summary, err := st.GetPnLBySymbol(strings.ToLower(c.Query("exchange")), strategyID, startTime, now)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "查询策略盈亏详情失败: " + err.Error()})
 		return
@@ -553,13 +554,13 @@ func getProfitTrendHandler(c *gin.Context) {
 	allStatsBefore, _ := st.QueryDailyStatisticsByExchange(exchangeID, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), startDate.AddDate(0, 0, -1))
 	baseProfit := 0.0
 	for _, s := range allStatsBefore {
-		baseProfit += s.TotalPnL
+		baseProfit += s.NetPnL
 	}
 
 	// 将结果按日期填充，缺失的日期补0
 	trendMap := make(map[string]float64)
 	for _, s := range dailyStats {
-		trendMap[s.Date.Format("2006-01-02")] = s.TotalPnL
+		trendMap[s.Date.Format("2006-01-02")] = s.NetPnL
 	}
 
 	trend := make([]ProfitTrendPoint, days+1)
@@ -0,0 +1,65 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/storage"
+)
+
+// getProfitVaultTransfers 获取利润金库划转记录
+// GET /api/capital/vault/transfers?exchange=&start_time=&end_time=&limit=
+func getProfitVaultTransfers(c *gin.Context) {
+	storageProv := PickStorageProvider(c)
+	if storageProv == nil || storageProv.GetStorage() == nil {
+		c.JSON(http.StatusOK, gin.H{"transfers": []interface{}{}, "total": 0})
+		return
+	}
+	st := storageProv.GetStorage()
+
+	limit := 100
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "100")); err == nil && l > 0 {
+		limit = l
+	}
+
+	startTime := time.Now().AddDate(0, 0, -30) // 默认最近30天
+	endTime := time.Now()
+	if startTimeStr := c.Query("start_time"); startTimeStr != "" {
+		t, err := time.Parse(time.RFC3339, startTimeStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_start_time")
+			return
+		}
+		startTime = t
+	}
+	if endTimeStr := c.Query("end_time"); endTimeStr != "" {
+		t, err := time.Parse(time.RFC3339, endTimeStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_end_time")
+			return
+		}
+		endTime = t
+	}
+
+	exchangeName := c.Query("exchange")
+	transfers, err := st.QueryProfitTransfers(exchangeName, startTime, endTime, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if transfers == nil {
+		transfers = []*storage.ProfitTransfer{}
+	}
+	total, err := st.GetProfitTransferTotal(exchangeName, startTime, endTime)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transfers": transfers,
+		"total":     total, // 区间内成功划转的总额
+	})
+}
@@ -0,0 +1,394 @@
+package web
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+	"quantmesh/config"
+	"quantmesh/logger"
+	"quantmesh/storage"
+	"quantmesh/utils"
+)
+
+// 公开状态页可暴露的指标
+const (
+	publicMetricEquityCurve   = "equity_curve"
+	publicMetricUptime        = "uptime"
+	publicMetricLastTradeTime = "last_trade_time"
+	publicMetricRunning       = "running"
+	publicMetricTotalPnL      = "total_pnl"
+	publicMetricWinRate       = "win_rate"
+	publicMetricTradeCount    = "trade_count"
+
+	// publicStatusCacheTTL 响应缓存时长，避免公开接口频繁查询数据库
+	publicStatusCacheTTL = 30 * time.Second
+)
+
+var knownPublicMetrics = map[string]bool{
+	publicMetricEquityCurve:   true,
+	publicMetricUptime:        true,
+	publicMetricLastTradeTime: true,
+	publicMetricRunning:       true,
+	publicMetricTotalPnL:      true,
+	publicMetricWinRate:       true,
+	publicMetricTradeCount:    true,
+}
+
+// EquityPoint 收益曲线数据点（累计已实现盈亏，不含账户余额）
+type EquityPoint struct {
+	Date  string  `json:"date"`
+	Value float64 `json:"value"`
+}
+
+// PublicStatusResponse 公开状态页响应，字段按配置的指标选择性返回
+type PublicStatusResponse struct {
+	Title         string        `json:"title"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	Running       *bool         `json:"running,omitempty"`
+	Uptime        *int64        `json:"uptime,omitempty"` // 秒
+	LastTradeTime *time.Time    `json:"last_trade_time,omitempty"`
+	TotalPnL      *float64      `json:"total_pnl,omitempty"`
+	WinRate       *float64      `json:"win_rate,omitempty"`
+	TradeCount    *int          `json:"trade_count,omitempty"`
+	EquityCurve   []EquityPoint `json:"equity_curve,omitempty"`
+}
+
+// publicStatusService 公开状态页服务
+type publicStatusService struct {
+	title      string
+	metrics    map[string]bool
+	equityDays int
+
+	limiterMu sync.Mutex
+	limiters  map[string]*ipLimiter
+	rateLimit rate.Limit
+	burst     int
+
+	cacheMu  sync.Mutex
+	cached   *PublicStatusResponse
+	cachedAt time.Time
+}
+
+type ipLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newPublicStatusService(cfg *config.Config) *publicStatusService {
+	ps := cfg.Web.PublicStatus
+	metrics := make(map[string]bool)
+	for _, m := range ps.Metrics {
+		m = strings.ToLower(strings.TrimSpace(m))
+		if !knownPublicMetrics[m] {
+			logger.Warn("⚠️ 公开状态页忽略未知指标: %s", m)
+			continue
+		}
+		metrics[m] = true
+	}
+
+	perMinute := ps.RateLimit
+	if perMinute <= 0 {
+		perMinute = 30
+	}
+	burst := perMinute / 6
+	if burst < 3 {
+		burst = 3
+	}
+
+	return &publicStatusService{
+		title:      ps.Title,
+		metrics:    metrics,
+		equityDays: ps.EquityDays,
+		limiters:   make(map[string]*ipLimiter),
+		rateLimit:  rate.Limit(float64(perMinute) / 60),
+		burst:      burst,
+	}
+}
+
+// allow 按客户端 IP 限流，顺带清理长时间未访问的限流器
+func (s *publicStatusService) allow(ip string) bool {
+	s.limiterMu.Lock()
+	defer s.limiterMu.Unlock()
+
+	now := time.Now()
+	l, ok := s.limiters[ip]
+	if !ok {
+		if len(s.limiters) > 10000 {
+			for k, v := range s.limiters {
+				if now.Sub(v.lastSeen) > 10*time.Minute {
+					delete(s.limiters, k)
+				}
+			}
+		}
+		l = &ipLimiter{limiter: rate.NewLimiter(s.rateLimit, s.burst)}
+		s.limiters[ip] = l
+	}
+	l.lastSeen = now
+	return l.limiter.Allow()
+}
+
+func (s *publicStatusService) rateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.allow(c.ClientIP()) {
+			c.Header("Retry-After", "60")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "请求过于频繁，请稍后再试"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// snapshot 获取状态快照（带缓存）
+func (s *publicStatusService) snapshot(providers *Providers) *PublicStatusResponse {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	if s.cached != nil && time.Since(s.cachedAt) < publicStatusCacheTTL {
+		return s.cached
+	}
+	s.cached = s.build(providers)
+	s.cachedAt = time.Now()
+	return s.cached
+}
+
+// build 汇总公开指标，只读取运行状态与已实现盈亏，不涉及余额、持仓和密钥
+func (s *publicStatusService) build(providers *Providers) *PublicStatusResponse {
+	resp := &PublicStatusResponse{
+		Title:     s.title,
+		UpdatedAt: time.Now(),
+	}
+
+	if s.metrics[publicMetricRunning] || s.metrics[publicMetricUptime] {
+		running := false
+		var uptime int64
+		statusMu.RLock()
+		for _, st := range statusBySymbol {
+			if st == nil || !st.Running {
+				continue
+			}
+			running = true
+			if st.Uptime > uptime {
+				uptime = st.Uptime
+			}
+		}
+		if len(statusBySymbol) == 0 && currentStatus != nil && currentStatus.Running {
+			running = true
+			uptime = currentStatus.Uptime
+		}
+		statusMu.RUnlock()
+		if s.metrics[publicMetricRunning] {
+			resp.Running = &running
+		}
+		if s.metrics[publicMetricUptime] {
+			resp.Uptime = &uptime
+		}
+	}
+
+	if providers.Storage == nil {
+		return resp
+	}
+	st := providers.Storage.GetStorage()
+	if st == nil {
+		return resp
+	}
+
+	if s.metrics[publicMetricLastTradeTime] {
+		trades, err := st.QueryTrades("", "", time.Time{}, time.Now(), 1, 0)
+		if err != nil {
+			logger.Warn("⚠️ 公开状态页查询最近成交失败: %v", err)
+		} else if len(trades) > 0 {
+			t := trades[0].CreatedAt
+			resp.LastTradeTime = &t
+		}
+	}
+
+	if s.metrics[publicMetricTotalPnL] || s.metrics[publicMetricWinRate] || s.metrics[publicMetricTradeCount] {
+		summary, err := st.GetStatisticsSummary()
+		if err != nil {
+			logger.Warn("⚠️ 公开状态页查询统计汇总失败: %v", err)
+		} else {
+			if s.metrics[publicMetricTotalPnL] {
+				resp.TotalPnL = &summary.TotalPnL
+			}
+			if s.metrics[publicMetricWinRate] {
+				resp.WinRate = &summary.WinRate
+			}
+			if s.metrics[publicMetricTradeCount] {
+				resp.TradeCount = &summary.TotalTrades
+			}
+		}
+	}
+
+	if s.metrics[publicMetricEquityCurve] {
+		endDate := utils.NowConfiguredTimezone()
+		startDate := endDate.AddDate(0, 0, -s.equityDays)
+		daily, err := st.QueryDailyStatisticsFromTrades(startDate, endDate)
+		if err != nil {
+			logger.Warn("⚠️ 公开状态页查询收益曲线失败: %v", err)
+		} else {
+			resp.EquityCurve = buildEquityCurve(daily)
+		}
+	}
+
+	return resp
+}
+
+// getPublicStatus 公开状态（无需认证）
+// GET /api/public/status
+func (s *publicStatusService) getPublicStatus(c *gin.Context) {
+	// 允许任意站点嵌入读取（不携带凭证），已在 CORS 白名单中的来源保持原有响应头
+	if c.Writer.Header().Get("Access-Control-Allow-Origin") == "" {
+		c.Header("Access-Control-Allow-Origin", "*")
+	}
+	c.Header("Cache-Control", "public, max-age=30")
+	c.JSON(http.StatusOK, s.snapshot(providersOf(c)))
+}
+
+// getPublicStatusPage 公开状态页 HTML（可直接分享或通过 iframe 嵌入）
+// GET /public/status
+func (s *publicStatusService) getPublicStatusPage(c *gin.Context) {
+	data := s.snapshot(providersOf(c))
+	view := publicStatusView{PublicStatusResponse: data}
+	if data.Running != nil {
+		view.RunningText = "⚪ Stopped"
+		if *data.Running {
+			view.RunningText = "🟢 Running"
+		}
+	}
+	if data.TradeCount != nil {
+		view.TradeCountText = fmt.Sprintf("%d", *data.TradeCount)
+	}
+	if data.WinRate != nil {
+		view.WinRateText = fmt.Sprintf("%.1f%%", *data.WinRate*100)
+	}
+	if data.TotalPnL != nil {
+		view.PnLText = fmt.Sprintf("%.2f", *data.TotalPnL)
+	}
+	if data.Uptime != nil {
+		view.UptimeText = formatPublicUptime(time.Duration(*data.Uptime) * time.Second)
+	}
+	if data.LastTradeTime != nil {
+		view.LastTradeText = data.LastTradeTime.Format("2006-01-02 15:04:05")
+	}
+	view.CurvePoints = equityCurveSVGPoints(data.EquityCurve, 600, 160)
+
+	c.Header("Cache-Control", "public, max-age=30")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := publicStatusTemplate.Execute(c.Writer, view); err != nil {
+		logger.Warn("⚠️ 渲染公开状态页失败: %v", err)
+	}
+}
+
+// buildEquityCurve 将每日净盈亏累加为收益曲线（按日期升序）
+func buildEquityCurve(daily []*storage.DailyStatisticsWithTradeCount) []EquityPoint {
+	sorted := make([]*storage.DailyStatisticsWithTradeCount, 0, len(daily))
+	for _, d := range daily {
+		if d != nil {
+			sorted = append(sorted, d)
+		}
+	}
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && sorted[j].Date.Before(sorted[j-1].Date); j-- {
+			sorted[j], sorted[j-1] = sorted[j-1], sorted[j]
+		}
+	}
+
+	curve := make([]EquityPoint, 0, len(sorted))
+	cumulative := 0.0
+	for _, d := range sorted {
+		pnl := d.NetPnL
+		if pnl == 0 {
+			pnl = d.TotalPnL
+		}
+		cumulative += pnl
+		curve = append(curve, EquityPoint{
+			Date:  d.Date.Format("2006-01-02"),
+			Value: math.Round(cumulative*100) / 100,
+		})
+	}
+	return curve
+}
+
+// equityCurveSVGPoints 生成 SVG polyline 坐标
+func equityCurveSVGPoints(curve []EquityPoint, width, height float64) string {
+	if len(curve) < 2 {
+		return ""
+	}
+	minV, maxV := curve[0].Value, curve[0].Value
+	for _, p := range curve {
+		minV = math.Min(minV, p.Value)
+		maxV = math.Max(maxV, p.Value)
+	}
+	span := maxV - minV
+	if span == 0 {
+		span = 1
+	}
+
+	points := make([]string, 0, len(curve))
+	for i, p := range curve {
+		x := float64(i) / float64(len(curve)-1) * width
+		y := height - (p.Value-minV)/span*height
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	return strings.Join(points, " ")
+}
+
+func formatPublicUptime(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+type publicStatusView struct {
+	*PublicStatusResponse
+	RunningText    string
+	UptimeText     string
+	LastTradeText  string
+	TradeCountText string
+	WinRateText    string
+	PnLText        string
+	CurvePoints    string
+}
+
+var publicStatusTemplate = template.Must(template.New("public_status").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="60">
+<title>{{.Title}}</title>
+<style>
+body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",sans-serif;background:#0f172a;color:#e2e8f0;margin:0;padding:24px}
+.card{max-width:640px;margin:0 auto;background:#1e293b;border-radius:8px;padding:20px}
+h1{font-size:20px;margin:0 0 16px}
+.row{display:flex;justify-content:space-between;padding:6px 0;border-bottom:1px solid #334155}
+.muted{color:#94a3b8;font-size:12px;margin-top:12px}
+svg{width:100%;height:160px;margin-top:16px}
+</style>
+</head>
+<body>
+<div class="card">
+<h1>{{.Title}}</h1>
+{{if .RunningText}}<div class="row"><span>Status</span><span>{{.RunningText}}</span></div>{{end}}
+{{if .UptimeText}}<div class="row"><span>Uptime</span><span>{{.UptimeText}}</span></div>{{end}}
+{{if .LastTradeText}}<div class="row"><span>Last trade</span><span>{{.LastTradeText}}</span></div>{{end}}
+{{if .TradeCountText}}<div class="row"><span>Trades</span><span>{{.TradeCountText}}</span></div>{{end}}
+{{if .WinRateText}}<div class="row"><span>Win rate</span><span>{{.WinRateText}}</span></div>{{end}}
+{{if .PnLText}}<div class="row"><span>Realized PnL</span><span>{{.PnLText}}</span></div>{{end}}
+{{if .CurvePoints}}<svg viewBox="0 0 600 160" preserveAspectRatio="none"><polyline fill="none" stroke="#22c55e" stroke-width="2" points="{{.CurvePoints}}"/></svg>{{end}}
+<div class="muted">Updated {{.UpdatedAt.Format "2006-01-02 15:04:05"}}</div>
+</div>
+</body>
+</html>`))
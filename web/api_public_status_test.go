@@ -0,0 +1,51 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"quantmesh/storage"
+)
+
+func TestBuildEquityCurveCumulative(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	daily := []*storage.DailyStatisticsWithTradeCount{
+		{Date: day(3), TotalPnL: 5, NetPnL: 4},
+		{Date: day(1), TotalPnL: 10},
+		{Date: day(2), TotalPnL: -3, NetPnL: -3.5},
+	}
+
+	curve := buildEquityCurve(daily)
+	want := []EquityPoint{
+		{Date: "2025-01-01", Value: 10},
+		{Date: "2025-01-02", Value: 6.5},
+		{Date: "2025-01-03", Value: 10.5},
+	}
+	if len(curve) != len(want) {
+		t.Fatalf("got %d points, want %d", len(curve), len(want))
+	}
+	for i := range want {
+		if curve[i] != want[i] {
+			t.Errorf("point %d: got %+v, want %+v", i, curve[i], want[i])
+		}
+	}
+}
+
+func TestPublicStatusRateLimit(t *testing.T) {
+	s := &publicStatusService{
+		limiters:  make(map[string]*ipLimiter),
+		rateLimit: 1.0 / 60,
+		burst:     3,
+	}
+	for i := 0; i < 3; i++ {
+		if !s.allow("1.1.1.1") {
+			t.Fatalf("request %d should be allowed", i)
+		}
+	}
+	if s.allow("1.1.1.1") {
+		t.Fatalf("request over burst should be limited")
+	}
+	if !s.allow("2.2.2.2") {
+		t.Fatalf("other IPs should not share the limit")
+	}
+}
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/safety"
+)
+
+// ReconciliationProvider 手动对账提供者接口（需要从 main.go 注入）
+type ReconciliationProvider interface {
+	RunReconciliation(exchange, symbol string) (*safety.ReconcileReport, error)
+	ReconciliationDust(exchange, symbol string) (safety.DustStats, bool)
+}
+
+// SetReconciliationProvider 设置手动对账提供者
+func SetReconciliationProvider(provider ReconciliationProvider) {
+	defaultProviders.Reconciliation = provider
+}
+
+// ReconciliationRunRequest 手动触发对账请求
+type ReconciliationRunRequest struct {
+	Exchange string `json:"exchange"`
+	Symbol   string `json:"symbol"`
+}
+
+// runReconciliation 立即执行一次对账并返回详细差异（逐槽位本地订单与交易所挂单、持仓差异、孤儿挂单）
+// POST /api/reconciliation/run
+func runReconciliation(c *gin.Context) {
+	reconciliationProvider := providersOf(c).Reconciliation
+	var req ReconciliationRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if req.Exchange == "" && globalConfig != nil {
+		req.Exchange = globalConfig.App.CurrentExchange
+	}
+	if req.Symbol == "" {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("需要 symbol"))
+		return
+	}
+	if reconciliationProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.reconciliation_failed", fmt.Errorf("交易服务未就绪"))
+		return
+	}
+
+	resource := fmt.Sprintf("%s:%s", req.Exchange, req.Symbol)
+	report, err := reconciliationProvider.RunReconciliation(req.Exchange, req.Symbol)
+	if err != nil {
+		LogAction(c, "reconciliation_run", resource, req, "failed", err.Error())
+		respondError(c, http.StatusBadRequest, "error.reconciliation_failed", err)
+		return
+	}
+	LogAction(c, "reconciliation_run", resource, gin.H{
+		"in_sync":          report.InSync,
+		"position_delta":   report.PositionDelta,
+		"mismatched_slots": report.MismatchedSlots,
+		"orphaned_orders":  len(report.OrphanedOrders),
+	}, "success", "")
+
+	c.JSON(http.StatusOK, report)
+}
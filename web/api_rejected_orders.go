@@ -0,0 +1,77 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/storage"
+)
+
+// getRejectedOrders 查询被拒绝/失败的下单记录（按时间倒序），附按方向与原因的汇总
+// 某一方向持续被拒通常意味着网格已静默停止该侧报价
+// GET /api/orders/rejected?exchange=&symbol=&side=&category=&start_time=&end_time=&limit=
+func getRejectedOrders(c *gin.Context) {
+	empty := gin.H{"orders": []*storage.RejectedOrder{}, "count": 0}
+	storageProv := PickStorageProvider(c)
+	if storageProv == nil {
+		c.JSON(http.StatusOK, empty)
+		return
+	}
+	st := storageProv.GetStorage()
+	if st == nil {
+		c.JSON(http.StatusOK, empty)
+		return
+	}
+
+	q := storage.RejectedOrderQuery{
+		Exchange: c.Query("exchange"),
+		Symbol:   strings.ToUpper(c.Query("symbol")),
+		Side:     strings.ToUpper(c.Query("side")),
+		Category: c.Query("category"),
+	}
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "100")); err == nil && l > 0 {
+		q.Limit = l
+	}
+	if startTimeStr := c.Query("start_time"); startTimeStr != "" {
+		t, err := time.Parse(time.RFC3339, startTimeStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_start_time")
+			return
+		}
+		q.StartTime = t
+	}
+	if endTimeStr := c.Query("end_time"); endTimeStr != "" {
+		t, err := time.Parse(time.RFC3339, endTimeStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_end_time")
+			return
+		}
+		q.EndTime = t
+	}
+
+	orders, err := st.QueryRejectedOrders(q)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "error.query_rejected_orders_failed", err)
+		return
+	}
+	if orders == nil {
+		orders = []*storage.RejectedOrder{}
+	}
+
+	bySide := make(map[string]int)
+	byCategory := make(map[string]int)
+	for _, o := range orders {
+		bySide[o.Side]++
+		byCategory[o.Category]++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"orders":      orders,
+		"count":       len(orders),
+		"by_side":     bySide,
+		"by_category": byCategory,
+	})
+}
@@ -0,0 +1,40 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/hedge"
+)
+
+// HedgeProvider 库存对冲状态提供者接口（需要从 main.go 注入）
+type HedgeProvider interface {
+	// GetHedgeStatuses 返回启用了库存对冲的交易对的状态，symbol 为空时返回全部交易对
+	GetHedgeStatuses(exchange, symbol string) []hedge.Status
+}
+
+// SetHedgeProvider 设置库存对冲状态提供者
+func SetHedgeProvider(provider HedgeProvider) {
+	defaultProviders.Hedge = provider
+}
+
+// getRiskHedge 库存对冲状态：网格库存、目标与当前对冲仓位、最近一次再平衡时间和错误
+// GET /api/risk/hedge?exchange=binance&symbol=BTCUSDT（不指定 symbol 时返回全部交易对）
+func getRiskHedge(c *gin.Context) {
+	hedgeProvider := providersOf(c).Hedge
+	if hedgeProvider == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	exchangeName, symbol := c.Query("exchange"), strings.ToUpper(c.Query("symbol"))
+	if exchangeName == "" && symbol != "" && globalConfig != nil {
+		exchangeName = globalConfig.App.CurrentExchange
+	}
+
+	statuses := hedgeProvider.GetHedgeStatuses(exchangeName, symbol)
+	if statuses == nil {
+		statuses = []hedge.Status{}
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "symbols": statuses})
+}
@@ -0,0 +1,57 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/hedge"
+)
+
+type fakeHedgeProvider struct {
+	exchange, symbol string
+}
+
+func (f *fakeHedgeProvider) GetHedgeStatuses(exchange, symbol string) []hedge.Status {
+	f.exchange, f.symbol = exchange, symbol
+	return []hedge.Status{{Exchange: "binance", GridSymbol: "BTCUSDT", HedgeSymbol: "BTCUSDT", TargetQty: -0.5, HedgeQty: -0.5}}
+}
+
+func TestGetRiskHedge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	do := func(providers *Providers, path string) map[string]interface{} {
+		r := gin.New()
+		r.Use(providersMiddleware(providers))
+		r.GET("/api/risk/hedge", getRiskHedge)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", path, w.Code)
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	// 未启用库存对冲
+	if body := do(&Providers{}, "/api/risk/hedge"); body["enabled"] != false {
+		t.Fatalf("without provider: %v", body)
+	}
+
+	provider := &fakeHedgeProvider{}
+	body := do(&Providers{Hedge: provider}, "/api/risk/hedge?exchange=binance&symbol=btcusdt")
+	if provider.exchange != "binance" || provider.symbol != "BTCUSDT" {
+		t.Fatalf("provider queried with %q %q", provider.exchange, provider.symbol)
+	}
+	symbols, _ := body["symbols"].([]interface{})
+	if body["enabled"] != true || len(symbols) != 1 {
+		t.Fatalf("body = %v", body)
+	}
+	if st := symbols[0].(map[string]interface{}); st["target_qty"] != -0.5 || st["exchange"] != "binance" {
+		t.Fatalf("status = %v", st)
+	}
+}
@@ -0,0 +1,46 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/order"
+)
+
+// MarginModelProvider 下单前保证金检查的保证金模型提供者接口（需要从 main.go 注入）
+type MarginModelProvider interface {
+	// GetMarginModels 返回启用了保证金检查的交易对的模型，symbol 为空时返回全部交易对
+	GetMarginModels(exchange, symbol string) []order.MarginModel
+}
+
+// SetMarginModelProvider 设置保证金模型提供者
+func SetMarginModelProvider(provider MarginModelProvider) {
+	defaultProviders.MarginModel = provider
+}
+
+// getRiskMargin 下单前保证金检查维护的模型：最近一次从交易所核实的可用保证金、杠杆、持仓、
+// 维持保证金档位、核实后已预留的保证金，以及最近一次批次检查和累计裁剪情况
+// GET /api/risk/margin?exchange=binance&symbol=BTCUSDT（不指定 symbol 时返回全部交易对）
+func getRiskMargin(c *gin.Context) {
+	marginModelProvider := providersOf(c).MarginModel
+	if marginModelProvider == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	exchangeName, symbol := c.Query("exchange"), strings.ToUpper(c.Query("symbol"))
+	if exchangeName == "" && symbol != "" && globalConfig != nil {
+		exchangeName = globalConfig.App.CurrentExchange
+	}
+
+	models := marginModelProvider.GetMarginModels(exchangeName, symbol)
+	result := make([]gin.H, 0, len(models))
+	for i := range models {
+		m := &models[i]
+		result = append(result, gin.H{
+			"model":  m,
+			"budget": m.Budget(),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "symbols": result})
+}
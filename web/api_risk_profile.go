@@ -0,0 +1,93 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/config"
+)
+
+// SymbolRiskProfile 交易对当前使用的风控档位
+type SymbolRiskProfile struct {
+	Exchange string `json:"exchange"`
+	Symbol   string `json:"symbol"`
+	Profile  string `json:"profile"`
+}
+
+// RiskProfileProvider 风控档位提供者接口（需要从 main.go 注入）
+type RiskProfileProvider interface {
+	GetSymbolRiskProfiles() []SymbolRiskProfile
+	SwitchRiskProfile(exchange, symbol, profile string) error
+}
+
+// SetRiskProfileProvider 设置风控档位提供者
+func SetRiskProfileProvider(provider RiskProfileProvider) {
+	defaultProviders.RiskProfile = provider
+}
+
+// getRiskProfiles 获取可用风控档位及各交易对当前档位
+// GET /api/risk/profiles
+func getRiskProfiles(c *gin.Context) {
+	riskProfileProvider := providersOf(c).RiskProfile
+	profiles := config.BuiltinRiskProfiles()
+	if globalConfig != nil {
+		profiles = globalConfig.AllRiskProfiles()
+	}
+	symbols := []SymbolRiskProfile{}
+	if riskProfileProvider != nil {
+		symbols = riskProfileProvider.GetSymbolRiskProfiles()
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"profiles": profiles,
+		"symbols":  symbols,
+	})
+}
+
+// switchRiskProfile 运行时切换交易对的风控档位（仅对本次运行生效，持久化请修改配置文件中的 risk_profile）
+// POST /api/risk/profiles/switch
+func switchRiskProfile(c *gin.Context) {
+	riskProfileProvider := providersOf(c).RiskProfile
+	var req SymbolRiskProfile
+	if err := c.ShouldBindJSON(&req); err != nil || req.Symbol == "" || req.Profile == "" {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	if globalConfig == nil || riskProfileProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.switch_risk_profile_failed", fmt.Errorf("交易服务未就绪"))
+		return
+	}
+	if req.Exchange == "" {
+		req.Exchange = globalConfig.App.CurrentExchange
+	}
+	if _, ok := globalConfig.LookupRiskProfile(req.Profile); !ok {
+		respondError(c, http.StatusBadRequest, "error.risk_profile_not_found")
+		return
+	}
+
+	previous := ""
+	for _, s := range riskProfileProvider.GetSymbolRiskProfiles() {
+		if s.Exchange == req.Exchange && s.Symbol == req.Symbol {
+			previous = s.Profile
+			break
+		}
+	}
+
+	resource := req.Exchange + ":" + req.Symbol
+	details := map[string]string{"from": previous, "to": req.Profile}
+	if err := riskProfileProvider.SwitchRiskProfile(req.Exchange, req.Symbol, req.Profile); err != nil {
+		LogAction(c, "risk_profile_switch", resource, details, "failed", err.Error())
+		respondError(c, http.StatusBadRequest, "error.switch_risk_profile_failed", err)
+		return
+	}
+	LogAction(c, "risk_profile_switch", resource, details, "success", "")
+	recordJournal(c, req.Exchange, req.Symbol, "切换风控档位", fmt.Sprintf("风控档位 %s → %s", previous, req.Profile))
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"exchange": req.Exchange,
+		"symbol":   req.Symbol,
+		"profile":  req.Profile,
+		"previous": previous,
+	})
+}
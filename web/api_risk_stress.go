@@ -0,0 +1,153 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/exchange"
+	"quantmesh/logger"
+	"quantmesh/safety"
+)
+
+// getRiskStress 压力测试：按价格冲击和波动率放大情景重新估值实时持仓，
+// 返回每个交易对的盈亏、保证金变化和强平距离，以及各交易所账户冲击后的权益和保证金占用
+// GET /api/risk/stress?shocks=-0.05,-0.1&vol_multipliers=2（参数可选，默认使用 risk_control.stress_test 配置）
+func getRiskStress(c *gin.Context) {
+	capitalDataSource := providersOf(c).CapitalDataSource
+	if capitalDataSource == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.stress_test_failed", fmt.Errorf("交易服务未就绪"))
+		return
+	}
+	cfg := capitalDataSource.GetConfig()
+
+	priceShocks, volMultipliers, volLookback := []float64{-0.05, -0.10}, []float64{2}, 30
+	if cfg != nil {
+		priceShocks = cfg.RiskControl.StressTest.PriceShocks
+		volMultipliers = cfg.RiskControl.StressTest.VolMultipliers
+		volLookback = cfg.RiskControl.StressTest.VolLookback
+	}
+	var err error
+	if raw := c.Query("shocks"); raw != "" {
+		if priceShocks, err = parseFloatList(raw); err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+			return
+		}
+	}
+	if raw := c.Query("vol_multipliers"); raw != "" {
+		if volMultipliers, err = parseFloatList(raw); err != nil {
+			respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+			return
+		}
+	}
+	for _, shock := range priceShocks {
+		if shock <= -1 || shock == 0 {
+			respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("价格冲击必须大于 -1 且不为 0"))
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 20*time.Second)
+	defer cancel()
+
+	var accounts []safety.StressAccount
+	var positions []safety.StressPosition
+	posManagers := capitalDataSource.GetPositionManagers()
+	for _, ex := range capitalDataSource.GetExchanges() {
+		acc, err := ex.GetAccount(ctx)
+		if err != nil {
+			respondError(c, http.StatusBadGateway, "error.stress_test_failed", fmt.Errorf("获取 %s 账户信息失败: %w", ex.GetName(), err))
+			return
+		}
+
+		// 交易所名称使用配置中的名称（与交易对运行时一致）
+		exchangeName := ex.GetName()
+		seen := make(map[string]bool)
+		for _, pm := range posManagers {
+			if !strings.EqualFold(pm.Exchange, ex.GetName()) || seen[pm.Symbol] {
+				continue
+			}
+			seen[pm.Symbol] = true
+			exchangeName = pm.Exchange
+
+			exPositions, err := ex.GetPositions(ctx, pm.Symbol)
+			if err != nil {
+				respondError(c, http.StatusBadGateway, "error.stress_test_failed", fmt.Errorf("获取 %s:%s 持仓失败: %w", pm.Exchange, pm.Symbol, err))
+				return
+			}
+			for _, p := range exPositions {
+				if p.Size == 0 || p.Symbol != pm.Symbol {
+					continue
+				}
+				markPrice := p.MarkPrice
+				if markPrice <= 0 {
+					markPrice = p.EntryPrice
+				}
+				positions = append(positions, safety.StressPosition{
+					Exchange:         pm.Exchange,
+					Symbol:           p.Symbol,
+					Size:             p.Size,
+					EntryPrice:       p.EntryPrice,
+					MarkPrice:        markPrice,
+					Leverage:         p.Leverage,
+					LiquidationPrice: p.LiquidationPrice,
+					DailyVolatility:  dailyVolatility(ctx, ex, p.Symbol, volLookback),
+				})
+			}
+		}
+		accounts = append(accounts, safety.StressAccount{Exchange: exchangeName, Equity: acc.TotalMarginBalance})
+	}
+
+	report := safety.RunStressTest(accounts, positions, safety.DefaultStressScenarios(priceShocks, volMultipliers))
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// dailyVolatility 最近 lookback 根日K的对数收益率标准差，获取失败时返回 0
+func dailyVolatility(ctx context.Context, ex exchange.IExchange, symbol string, lookback int) float64 {
+	klines, err := ex.GetHistoricalKlines(ctx, symbol, "1d", lookback+1)
+	if err != nil {
+		logger.Warn("⚠️ [压力测试] 获取 %s 日K失败: %v", symbol, err)
+		return 0
+	}
+	var returns []float64
+	for i := 1; i < len(klines); i++ {
+		if klines[i-1].Close > 0 && klines[i].Close > 0 {
+			returns = append(returns, math.Log(klines[i].Close/klines[i-1].Close))
+		}
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	return math.Sqrt(variance / float64(len(returns)-1))
+}
+
+// parseFloatList 解析逗号分隔的数字列表
+func parseFloatList(raw string) ([]float64, error) {
+	var values []float64
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, fmt.Errorf("无效的数字: %s", part)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
@@ -0,0 +1,29 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/scheduler"
+)
+
+// SchedulerProvider 定时任务调度器提供者接口
+type SchedulerProvider interface {
+	Status() []scheduler.JobStatus
+}
+
+// SetSchedulerProvider 设置定时任务调度器提供者
+func SetSchedulerProvider(provider SchedulerProvider) {
+	defaultProviders.Scheduler = provider
+}
+
+// getSchedulerJobs 获取按时段触发的定时任务（如低流动性时段降杠杆）及其当前状态
+// GET /api/scheduler/jobs
+func getSchedulerJobs(c *gin.Context) {
+	schedulerProvider := providersOf(c).Scheduler
+	if schedulerProvider == nil {
+		c.JSON(http.StatusOK, gin.H{"jobs": []scheduler.JobStatus{}})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": schedulerProvider.Status()})
+}
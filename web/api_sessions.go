@@ -0,0 +1,130 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/logger"
+)
+
+// SessionInfo 会话列表项
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// refreshSession 用刷新令牌换取新会话
+// POST /api/auth/refresh
+func refreshSession(c *gin.Context) {
+	sm := GetSessionManager()
+	if sm == nil {
+		respondError(c, http.StatusInternalServerError, "error.session_manager_not_initialized")
+		return
+	}
+
+	refreshToken, err := c.Cookie(refreshTokenCookieName)
+	if err != nil || refreshToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "缺少刷新令牌，请重新登录"})
+		return
+	}
+
+	session, err := sm.RefreshSession(refreshToken, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		logger.WriteWebLog(fmt.Sprintf("[SESSION] 刷新会话失败: IP=%s, 原因: %v", c.ClientIP(), err))
+		sm.ClearSessionCookie(c.Writer)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sm.SetLoginCookies(c.Writer, session, c.Request.TLS != nil)
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"expires_at": session.ExpiresAt,
+	})
+}
+
+// listSessions 列出当前用户的活跃会话
+// GET /api/sessions
+func listSessions(c *gin.Context) {
+	current, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	sessions := GetSessionManager().ListUserSessions(current.Username)
+	items := make([]SessionInfo, 0, len(sessions))
+	for i := range sessions {
+		s := &sessions[i]
+		items = append(items, SessionInfo{
+			ID:         s.PublicID(),
+			IP:         s.IP,
+			UserAgent:  s.UserAgent,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    s.SessionID == current.SessionID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": items})
+}
+
+// revokeSessionHandler 注销指定会话
+// DELETE /api/sessions/:id
+func revokeSessionHandler(c *gin.Context) {
+	current, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if !GetSessionManager().RevokeSessionByPublicID(current.Username, id, "用户注销") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "会话不存在"})
+		return
+	}
+	if id == current.PublicID() {
+		GetSessionManager().ClearSessionCookie(c.Writer)
+	}
+
+	logger.WriteWebLog(fmt.Sprintf("[SESSION] 用户 %s 注销了会话 %s (IP=%s)", current.Username, id, c.ClientIP()))
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// revokeAllSessions 退出所有设备
+// POST /api/sessions/revoke-all?keep_current=true
+func revokeAllSessions(c *gin.Context) {
+	current, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	sm := GetSessionManager()
+	except := ""
+	if c.Query("keep_current") == "true" {
+		except = current.SessionID
+	}
+	revoked := sm.RevokeUserSessions(current.Username, except, "退出所有设备")
+	if except == "" {
+		sm.ClearSessionCookie(c.Writer)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "revoked": revoked})
+}
+
+// currentSession 获取认证中间件写入的会话
+func currentSession(c *gin.Context) (*Session, bool) {
+	value, exists := c.Get("session")
+	session, ok := value.(*Session)
+	if !exists || !ok || session == nil {
+		respondError(c, http.StatusUnauthorized, "error.not_logged_in")
+		return nil, false
+	}
+	return session, true
+}
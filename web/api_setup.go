@@ -19,6 +19,7 @@ import (
 // SetupStatusResponse 配置状态响应
 type SetupStatusResponse struct {
 	NeedsSetup bool                            `json:"needs_setup"`
+	Missing    []string                        `json:"missing,omitempty"` // 缺失的配置项
 	ConfigPath string                          `json:"config_path"`
 	Exchanges  map[string]config.ExchangeConfig `json:"exchanges,omitempty"`
 	Symbols    []config.SymbolConfig           `json:"symbols,omitempty"`
@@ -37,6 +38,7 @@ func getSetupStatusHandler(c *gin.Context) {
 	if os.IsNotExist(err) {
 		c.JSON(http.StatusOK, SetupStatusResponse{
 			NeedsSetup: true,
+			Missing:    []string{"config_file"},
 			ConfigPath: configPath,
 		})
 		return
@@ -47,27 +49,48 @@ func getSetupStatusHandler(c *gin.Context) {
 	if err != nil {
 		c.JSON(http.StatusOK, SetupStatusResponse{
 			NeedsSetup: true,
+			Missing:    []string{"valid_config"},
 			ConfigPath: configPath,
 		})
 		return
 	}
 
 	// 检查配置是否完整
-	needsSetup := cfg.App.CurrentExchange == "" ||
-		len(cfg.Exchanges) == 0 ||
-		cfg.Exchanges[cfg.App.CurrentExchange].APIKey == "" ||
-		cfg.Exchanges[cfg.App.CurrentExchange].SecretKey == "" ||
-		len(cfg.Trading.Symbols) == 0 ||
-		cfg.Trading.Symbols[0].Symbol == ""
+	missing := missingSetupItems(cfg)
 
 	c.JSON(http.StatusOK, SetupStatusResponse{
-		NeedsSetup: needsSetup,
+		NeedsSetup: len(missing) > 0,
+		Missing:    missing,
 		ConfigPath: configPath,
 		Exchanges:  cfg.Exchanges,
 		Symbols:    cfg.Trading.Symbols,
 	})
 }
 
+// missingSetupItems 列出首次运行前必须补齐的配置项，返回空表示配置完整
+func missingSetupItems(cfg *config.Config) []string {
+	var missing []string
+	if cfg.App.CurrentExchange == "" {
+		missing = append(missing, "app.current_exchange")
+	} else {
+		exCfg, ok := cfg.Exchanges[cfg.App.CurrentExchange]
+		if !ok {
+			missing = append(missing, "exchanges."+cfg.App.CurrentExchange)
+		} else {
+			if exCfg.APIKey == "" {
+				missing = append(missing, "exchanges."+cfg.App.CurrentExchange+".api_key")
+			}
+			if exCfg.SecretKey == "" {
+				missing = append(missing, "exchanges."+cfg.App.CurrentExchange+".secret_key")
+			}
+		}
+	}
+	if len(cfg.Trading.Symbols) == 0 || cfg.Trading.Symbols[0].Symbol == "" {
+		missing = append(missing, "trading.symbols")
+	}
+	return missing
+}
+
 // SetupInitRequest 配置初始化请求
 type SetupInitRequest struct {
 	Exchange       string   `json:"exchange" binding:"required"`
@@ -209,37 +232,8 @@ func initSetupHandler(c *gin.Context) {
 		cfg.Trading.Symbol = cfg.Trading.Symbols[0].Symbol
 	}
 
-	// 检查配置文件是否已存在，如果存在则先备份
-	var backupPath string
-	_, err := os.Stat(configPath)
-	if err == nil {
-		// 配置文件存在，先创建备份
-		backupManager := config.NewBackupManager()
-		backupInfo, backupErr := backupManager.CreateBackup(configPath, "首次设置向导覆盖前自动备份")
-		if backupErr != nil {
-			logger.Warn("⚠️ 创建配置备份失败: %v，但继续保存配置", backupErr)
-		} else {
-			backupPath = backupInfo.FilePath
-			logger.Info("✅ 已创建配置备份: %s", backupPath)
-		}
-
-		// 检查配置是否完整（用于日志记录，但不阻止覆盖）
-		existingCfg, loadErr := config.LoadConfig(configPath)
-		if loadErr == nil {
-			isComplete := existingCfg.App.CurrentExchange != "" &&
-				len(existingCfg.Exchanges) > 0 &&
-				existingCfg.Exchanges[existingCfg.App.CurrentExchange].APIKey != "" &&
-				existingCfg.Exchanges[existingCfg.App.CurrentExchange].SecretKey != "" &&
-				len(existingCfg.Trading.Symbols) > 0 &&
-				existingCfg.Trading.Symbols[0].Symbol != ""
-
-			if isComplete {
-				logger.Info("ℹ️ 检测到完整配置，已备份到: %s", backupPath)
-			}
-		}
-	}
-
-	// 保存配置
+	// 配置文件已存在时先备份，再验证并保存
+	backupPath := backupSetupConfig(configPath)
 	if err := config.SaveConfig(cfg, configPath); err != nil {
 		logger.Error("❌ 保存配置失败: %v", err)
 		c.JSON(http.StatusInternalServerError, SetupInitResponse{
@@ -248,13 +242,7 @@ func initSetupHandler(c *gin.Context) {
 		})
 		return
 	}
-
-	// 更新配置管理器中的配置
-	if configManager != nil {
-		configManager.mu.Lock()
-		configManager.currentConfig = cfg
-		configManager.mu.Unlock()
-	}
+	updateSetupConfig(cfg)
 
 	symbolsStr := ""
 	if len(symbols) > 0 {
@@ -278,6 +266,37 @@ func initSetupHandler(c *gin.Context) {
 	})
 }
 
+// backupSetupConfig 覆盖前备份已存在的配置文件，返回备份路径（无需备份或备份失败时为空）
+func backupSetupConfig(configPath string) string {
+	if _, err := os.Stat(configPath); err != nil {
+		return ""
+	}
+
+	backupManager := config.NewBackupManager()
+	backupInfo, err := backupManager.CreateBackup(configPath, "首次设置向导覆盖前自动备份")
+	if err != nil {
+		logger.Warn("⚠️ 创建配置备份失败: %v，但继续保存配置", err)
+		return ""
+	}
+	logger.Info("✅ 已创建配置备份: %s", backupInfo.FilePath)
+
+	// 检查配置是否完整（用于日志记录，但不阻止覆盖）
+	if existingCfg, err := config.LoadConfig(configPath); err == nil && len(missingSetupItems(existingCfg)) == 0 {
+		logger.Info("ℹ️ 检测到完整配置，已备份到: %s", backupInfo.FilePath)
+	}
+	return backupInfo.FilePath
+}
+
+// updateSetupConfig 将向导保存的配置同步到配置管理器
+func updateSetupConfig(cfg *config.Config) {
+	if configManager == nil {
+		return
+	}
+	configManager.mu.Lock()
+	configManager.currentConfig = cfg
+	configManager.mu.Unlock()
+}
+
 // ExchangeSymbolsRequest 获取交易所交易对请求
 type ExchangeSymbolsRequest struct {
 	Exchange   string `json:"exchange" binding:"required"`
@@ -0,0 +1,397 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"quantmesh/ai"
+	"quantmesh/config"
+	"quantmesh/exchange"
+	"quantmesh/logger"
+)
+
+const (
+	// setupTestOrderPriceRatio 测试单挂在当前价下方，避免成交（需在交易所价格保护范围内）
+	setupTestOrderPriceRatio = 0.97
+	// setupTestOrderDefaultValue 测试单默认金额（USDT）
+	setupTestOrderDefaultValue = 20.0
+)
+
+// setupTaskManager 设置向导的 AI 生成任务（与登录后的 AI 任务分开存放，未认证的向导接口只能查到向导自己的任务）
+var setupTaskManager = newAITaskManager()
+
+// setupConfigPath 设置向导读写的配置文件路径
+func setupConfigPath() string {
+	if configManager != nil {
+		return configManager.GetConfigPath()
+	}
+	return "config.yaml"
+}
+
+// setupPendingOnly 设置向导的密钥验证、测试下单、生成配置（及查询生成结果）和写入配置不需要登录，只在首次配置完成前开放：
+// 配置文件已存在且 missingSetupItems 为空时拒绝，之后修改配置须登录后通过配置接口进行
+func setupPendingOnly(c *gin.Context) {
+	if cfg, err := config.LoadConfig(setupConfigPath()); err == nil && len(missingSetupItems(cfg)) == 0 {
+		respondError(c, http.StatusForbidden, "error.setup_already_completed")
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// SetupCredentials 设置向导中用户填写的交易所凭证
+type SetupCredentials struct {
+	Exchange   string `json:"exchange" binding:"required"`
+	APIKey     string `json:"api_key" binding:"required"`
+	SecretKey  string `json:"secret_key" binding:"required"`
+	Passphrase string `json:"passphrase,omitempty"`
+	Testnet    bool   `json:"testnet,omitempty"`
+	Symbol     string `json:"symbol,omitempty"`
+}
+
+// exchangeConfig 转换为交易所配置
+func (sc *SetupCredentials) exchangeConfig() config.ExchangeConfig {
+	return config.ExchangeConfig{
+		APIKey:     sc.APIKey,
+		SecretKey:  sc.SecretKey,
+		Passphrase: sc.Passphrase,
+		Testnet:    sc.Testnet,
+		FeeRate:    0.0002,
+	}
+}
+
+// newSetupExchange 用向导中填写的凭证创建临时交易所实例（不读取、不修改现有配置）
+func newSetupExchange(creds *SetupCredentials) (exchange.IExchange, error) {
+	cfg := config.CreateMinimalConfig()
+	cfg.App.CurrentExchange = creds.Exchange
+	cfg.Exchanges[creds.Exchange] = creds.exchangeConfig()
+	cfg.Trading.Symbol = creds.Symbol
+	return exchange.NewExchange(cfg, creds.Exchange, creds.Symbol)
+}
+
+// SetupValidateKeysResponse API 密钥验证响应
+type SetupValidateKeysResponse struct {
+	Success     bool                   `json:"success"`
+	Message     string                 `json:"message"`
+	Permissions *PermissionCheckResult `json:"permissions,omitempty"`
+}
+
+// validateSetupKeysHandler 验证 API 密钥可用性和权限
+// POST /api/setup/validate-keys
+func validateSetupKeysHandler(c *gin.Context) {
+	var req SetupCredentials
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, SetupValidateKeysResponse{
+			Success: false,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	ex, err := newSetupExchange(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, SetupValidateKeysResponse{
+			Success: false,
+			Message: "创建交易所连接失败: " + err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	// 先查询账户，确认密钥有效（权限检测失败时不阻止启动，不能用来判断密钥是否有效）
+	if _, err := ex.GetAccount(ctx); err != nil {
+		c.JSON(http.StatusOK, SetupValidateKeysResponse{
+			Success: false,
+			Message: "API 密钥无效或网络不可达: " + err.Error(),
+		})
+		return
+	}
+
+	result := CheckExchangePermissions(ctx, ex, req.Exchange, req.Symbol)
+	resp := SetupValidateKeysResponse{
+		Success:     true,
+		Message:     "API 密钥验证通过",
+		Permissions: result,
+	}
+	if result.Permissions != nil && !result.Permissions.CanTrade {
+		resp.Success = false
+		resp.Message = "API 密钥未开启交易权限"
+	} else if !result.IsSecure {
+		resp.Message = "API 密钥可用，但存在安全风险，建议关闭提现权限并设置 IP 白名单"
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetupTestOrderRequest 测试网下单验证请求
+type SetupTestOrderRequest struct {
+	SetupCredentials
+	OrderValue float64 `json:"order_value,omitempty"` // 测试单金额（USDT），默认 20
+}
+
+// SetupTestOrderResponse 测试网下单验证响应
+type SetupTestOrderResponse struct {
+	Success   bool    `json:"success"`
+	Message   string  `json:"message"`
+	OrderID   int64   `json:"order_id,omitempty"`
+	Price     float64 `json:"price,omitempty"`
+	Quantity  float64 `json:"quantity,omitempty"`
+	Cancelled bool    `json:"cancelled"`
+}
+
+// testSetupOrderHandler 在测试网挂一笔远离市价的限价买单并立即撤销，验证下单链路
+// POST /api/setup/test-order
+func testSetupOrderHandler(c *gin.Context) {
+	var req SetupTestOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, SetupTestOrderResponse{
+			Success: false,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	if !req.Testnet {
+		c.JSON(http.StatusBadRequest, SetupTestOrderResponse{
+			Success: false,
+			Message: "测试下单仅允许在测试网进行",
+		})
+		return
+	}
+	if req.Symbol == "" {
+		c.JSON(http.StatusBadRequest, SetupTestOrderResponse{
+			Success: false,
+			Message: "请指定交易对",
+		})
+		return
+	}
+	orderValue := req.OrderValue
+	if orderValue <= 0 {
+		orderValue = setupTestOrderDefaultValue
+	}
+
+	ex, err := newSetupExchange(&req.SetupCredentials)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, SetupTestOrderResponse{
+			Success: false,
+			Message: "创建交易所连接失败: " + err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	lastPrice, err := ex.GetLatestPrice(ctx, req.Symbol)
+	if err != nil || lastPrice <= 0 {
+		c.JSON(http.StatusOK, SetupTestOrderResponse{
+			Success: false,
+			Message: fmt.Sprintf("获取 %s 当前价格失败: %v", req.Symbol, err),
+		})
+		return
+	}
+
+	priceScale := math.Pow10(ex.GetPriceDecimals())
+	quantityScale := math.Pow10(ex.GetQuantityDecimals())
+	price := math.Floor(lastPrice*setupTestOrderPriceRatio*priceScale) / priceScale
+	quantity := math.Ceil(orderValue/price*quantityScale) / quantityScale
+
+	order, err := ex.PlaceOrder(ctx, &exchange.OrderRequest{
+		Symbol:        req.Symbol,
+		Side:          exchange.SideBuy,
+		Type:          exchange.OrderTypeLimit,
+		TimeInForce:   exchange.TimeInForceGTC,
+		Quantity:      quantity,
+		Price:         price,
+		PriceDecimals: ex.GetPriceDecimals(),
+	})
+	if err != nil {
+		c.JSON(http.StatusOK, SetupTestOrderResponse{
+			Success:  false,
+			Message:  "测试下单失败: " + err.Error(),
+			Price:    price,
+			Quantity: quantity,
+		})
+		return
+	}
+
+	resp := SetupTestOrderResponse{
+		Success:   true,
+		Message:   "测试下单和撤单成功",
+		OrderID:   order.OrderID,
+		Price:     price,
+		Quantity:  quantity,
+		Cancelled: true,
+	}
+	if err := ex.CancelOrder(ctx, req.Symbol, order.OrderID); err != nil {
+		logger.Warn("⚠️ [设置向导] 测试单 %d 撤销失败: %v", order.OrderID, err)
+		resp.Success = false
+		resp.Cancelled = false
+		resp.Message = fmt.Sprintf("测试下单成功，但撤单失败，请到交易所手动撤销订单 %d: %v", order.OrderID, err)
+	} else {
+		logger.Info("✅ [设置向导] %s 测试网下单撤单验证通过 (订单 %d)", req.Symbol, order.OrderID)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetupGenerateConfigRequest 设置向导 AI 配置生成请求
+type SetupGenerateConfigRequest struct {
+	SetupCredentials
+	Symbols      []string `json:"symbols" binding:"required,min=1"`
+	TotalCapital float64  `json:"total_capital" binding:"required,gt=0"`
+	RiskProfile  string   `json:"risk_profile"`
+	GeminiAPIKey string   `json:"gemini_api_key" binding:"required"`
+}
+
+// generateSetupConfigHandler 调用 Gemini 生成初始配置（异步任务）
+// 任务状态通过 GET /api/setup/generate-config/:task_id 查询，确认后调用 POST /api/setup/apply-config 写入
+// POST /api/setup/generate-config
+func generateSetupConfigHandler(c *gin.Context) {
+	var req SetupGenerateConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	if req.RiskProfile == "" {
+		req.RiskProfile = "conservative"
+	}
+
+	// 使用填写的凭证获取当前价格，失败时交由 AI 使用默认值
+	currentPrices := make(map[string]float64)
+	if ex, err := newSetupExchange(&req.SetupCredentials); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		for _, symbol := range req.Symbols {
+			if price, err := ex.GetLatestPrice(ctx, symbol); err == nil && price > 0 {
+				currentPrices[symbol] = price
+			}
+		}
+		cancel()
+	}
+	if len(currentPrices) < len(req.Symbols) {
+		logger.Warn("⚠️ [设置向导] 部分币种未能获取到价格，将使用默认值")
+	}
+
+	task := setupTaskManager.CreateTask()
+	c.JSON(http.StatusAccepted, gin.H{
+		"task_id": task.TaskID,
+		"status":  "pending",
+		"message": "任务已创建，正在处理中...",
+	})
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+		defer cancel()
+
+		setupTaskManager.UpdateTask(task.TaskID, TaskStatusRunning, nil, nil)
+		logger.Info("🔄 [设置向导] AI 任务 %s 开始生成初始配置", task.TaskID)
+
+		aiConfig, err := ai.NewGeminiClient(req.GeminiAPIKey).GenerateConfig(ctx, &ai.GenerateConfigRequest{
+			Exchange:      req.Exchange,
+			Symbols:       req.Symbols,
+			TotalCapital:  req.TotalCapital,
+			CapitalMode:   "total",
+			RiskProfile:   req.RiskProfile,
+			CurrentPrices: currentPrices,
+		})
+		if err != nil {
+			logger.Error("❌ [设置向导] AI 任务 %s 配置生成失败: %v", task.TaskID, err)
+			setupTaskManager.UpdateTask(task.TaskID, TaskStatusFailed, nil, err)
+			return
+		}
+
+		if err := ai.NewConfigService("").ValidateAIConfig(aiConfig, req.TotalCapital); err != nil {
+			logger.Error("❌ [设置向导] AI 任务 %s 配置验证失败: %v", task.TaskID, err)
+			setupTaskManager.UpdateTask(task.TaskID, TaskStatusFailed, nil, err)
+			return
+		}
+
+		logger.Info("✅ [设置向导] AI 任务 %s 配置生成完成", task.TaskID)
+		setupTaskManager.UpdateTask(task.TaskID, TaskStatusCompleted, aiConfig, nil)
+	}()
+}
+
+// getSetupTaskStatus 查询设置向导的 AI 生成任务（只能查询向导自己创建的任务）
+// GET /api/setup/generate-config/:task_id
+func getSetupTaskStatus(c *gin.Context) {
+	respondAITaskStatus(c, setupTaskManager)
+}
+
+// SetupApplyConfigRequest 设置向导写入 AI 配置请求
+type SetupApplyConfigRequest struct {
+	SetupCredentials
+	Config ai.GenerateConfigResponse `json:"config" binding:"required"`
+}
+
+// applySetupConfigHandler 将 AI 生成的配置与交易所凭证合并，验证后写入 config.yaml
+// POST /api/setup/apply-config
+func applySetupConfigHandler(c *gin.Context) {
+	var req SetupApplyConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, SetupInitResponse{
+			Success: false,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	if len(req.Config.SymbolsConfig) == 0 && len(req.Config.GridConfig) == 0 {
+		c.JSON(http.StatusBadRequest, SetupInitResponse{
+			Success: false,
+			Message: "AI 配置中没有任何交易对",
+		})
+		return
+	}
+
+	configPath := setupConfigPath()
+	cfg := config.CreateMinimalConfig()
+	cfg.App.CurrentExchange = req.Exchange
+	cfg.Exchanges[req.Exchange] = req.exchangeConfig()
+
+	// AI 返回的交易对可能未填写交易所，统一归到向导选择的交易所
+	for i := range req.Config.SymbolsConfig {
+		if req.Config.SymbolsConfig[i].Exchange == "" {
+			req.Config.SymbolsConfig[i].Exchange = req.Exchange
+		}
+	}
+	for i := range req.Config.GridConfig {
+		if req.Config.GridConfig[i].Exchange == "" {
+			req.Config.GridConfig[i].Exchange = req.Exchange
+		}
+	}
+
+	// ApplyAIConfig 会验证并保存配置，先备份旧文件
+	backupPath := backupSetupConfig(configPath)
+	if err := ai.NewConfigService(configPath).ApplyAIConfig(&req.Config, cfg); err != nil {
+		logger.Error("❌ [设置向导] 写入 AI 配置失败: %v", err)
+		c.JSON(http.StatusBadRequest, SetupInitResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+	updateSetupConfig(cfg)
+
+	symbols := make([]string, 0, len(cfg.Trading.Symbols))
+	for _, sc := range cfg.Trading.Symbols {
+		symbols = append(symbols, sc.Symbol)
+	}
+	logger.Info("✅ [设置向导] AI 配置已写入: 交易所=%s, 交易对=%s", req.Exchange, strings.Join(symbols, ","))
+
+	message := "配置已保存，请重启系统以应用配置"
+	if backupPath != "" {
+		message = fmt.Sprintf("配置已保存（原配置已备份到: %s），请重启系统以应用配置", backupPath)
+	}
+	c.JSON(http.StatusOK, SetupInitResponse{
+		Success:         true,
+		Message:         message,
+		RequiresRestart: true,
+		BackupPath:      backupPath,
+	})
+}
@@ -0,0 +1,186 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/config"
+)
+
+// newSetupTestRouter 使用临时配置文件的设置向导路由
+func newSetupTestRouter(t *testing.T) (*gin.Engine, string) {
+	t.Helper()
+	saved := configManager
+	t.Cleanup(func() { configManager = saved })
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	configManager = NewConfigManager(path)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	setup := r.Group("/api/setup")
+	setup.POST("/validate-keys", setupPendingOnly, validateSetupKeysHandler)
+	setup.POST("/test-order", setupPendingOnly, testSetupOrderHandler)
+	setup.POST("/generate-config", setupPendingOnly, generateSetupConfigHandler)
+	setup.GET("/generate-config/:task_id", setupPendingOnly, getSetupTaskStatus)
+	setup.POST("/apply-config", setupPendingOnly, applySetupConfigHandler)
+	return r, path
+}
+
+func postSetup(r *gin.Engine, path string, body interface{}) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func getSetup(r *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestMissingSetupItems(t *testing.T) {
+	complete := func() *config.Config {
+		cfg := &config.Config{Exchanges: map[string]config.ExchangeConfig{"binance": {APIKey: "k", SecretKey: "s"}}}
+		cfg.App.CurrentExchange = "binance"
+		cfg.Trading.Symbols = []config.SymbolConfig{{Symbol: "BTCUSDT"}}
+		return cfg
+	}
+	tests := []struct {
+		name   string
+		modify func(cfg *config.Config)
+		want   []string
+	}{
+		{"complete", func(cfg *config.Config) {}, nil},
+		{"no exchange", func(cfg *config.Config) { cfg.App.CurrentExchange = "" }, []string{"app.current_exchange"}},
+		{"exchange not configured", func(cfg *config.Config) { cfg.App.CurrentExchange = "okx" }, []string{"exchanges.okx"}},
+		{"missing keys", func(cfg *config.Config) { cfg.Exchanges["binance"] = config.ExchangeConfig{} },
+			[]string{"exchanges.binance.api_key", "exchanges.binance.secret_key"}},
+		{"no symbols", func(cfg *config.Config) { cfg.Trading.Symbols = nil }, []string{"trading.symbols"}},
+	}
+	for _, tt := range tests {
+		cfg := complete()
+		tt.modify(cfg)
+		if got := missingSetupItems(cfg); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: missingSetupItems = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSetupWizardRequestValidation(t *testing.T) {
+	r, _ := newSetupTestRouter(t)
+	creds := map[string]interface{}{"exchange": "binance", "api_key": "k", "secret_key": "s", "symbol": "BTCUSDT"}
+
+	tests := []struct {
+		name string
+		path string
+		body interface{}
+	}{
+		{"validate-keys without credentials", "/api/setup/validate-keys", map[string]interface{}{"exchange": "binance"}},
+		{"test order on mainnet", "/api/setup/test-order", creds},
+		{"test order without symbol", "/api/setup/test-order", map[string]interface{}{"exchange": "binance", "api_key": "k", "secret_key": "s", "testnet": true}},
+		{"generate-config without symbols", "/api/setup/generate-config", creds},
+		{"apply-config without symbols", "/api/setup/apply-config", map[string]interface{}{"exchange": "binance", "api_key": "k", "secret_key": "s", "config": map[string]interface{}{}}},
+	}
+	for _, tt := range tests {
+		if w := postSetup(r, tt.path, tt.body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400 (%s)", tt.name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestSetupWizardClosedAfterSetup(t *testing.T) {
+	r, path := newSetupTestRouter(t)
+	apply := map[string]interface{}{
+		"exchange": "binance", "api_key": "key", "secret_key": "secret",
+		"config": map[string]interface{}{
+			"symbols_config": []map[string]interface{}{{
+				"symbol": "BTCUSDT", "price_interval": 10, "order_quantity": 30, "min_order_value": 20,
+				"buy_window_size": 10, "sell_window_size": 10,
+			}},
+		},
+	}
+
+	// 首次配置：配置文件不存在时写入
+	w := postSetup(r, "/api/setup/apply-config", apply)
+	if w.Code != http.StatusOK {
+		t.Fatalf("first apply: %d %s", w.Code, w.Body.String())
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("config file not written: %v", err)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil || len(missingSetupItems(cfg)) != 0 {
+		t.Fatalf("written config incomplete: %v %v", err, missingSetupItems(cfg))
+	}
+	before, _ := os.ReadFile(path)
+
+	// 配置完成后，未登录的向导接口不能再覆盖配置或使用密钥下单
+	apply["api_key"] = "attacker"
+	testOrder := map[string]interface{}{"exchange": "binance", "api_key": "k", "secret_key": "s", "symbol": "BTCUSDT", "testnet": true}
+	for _, req := range []struct {
+		path string
+		body interface{}
+	}{
+		{"/api/setup/apply-config", apply},
+		{"/api/setup/test-order", testOrder},
+		{"/api/setup/validate-keys", testOrder},
+		{"/api/setup/generate-config", testOrder},
+	} {
+		if w := postSetup(r, req.path, req.body); w.Code != http.StatusForbidden {
+			t.Errorf("%s after setup: got %d, want 403", req.path, w.Code)
+		}
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(before, after) {
+		t.Fatal("config.yaml was modified after setup completed")
+	}
+}
+
+func TestSetupTaskStatusScope(t *testing.T) {
+	r, _ := newSetupTestRouter(t)
+
+	// 登录后创建的 AI 任务不能通过未认证的向导接口查询
+	private := aiTaskManager.CreateTask()
+	if w := getSetup(r, "/api/setup/generate-config/"+private.TaskID); w.Code != http.StatusNotFound {
+		t.Fatalf("non-wizard task via setup endpoint: got %d, want 404", w.Code)
+	}
+
+	task := setupTaskManager.CreateTask()
+	if w := getSetup(r, "/api/setup/generate-config/"+task.TaskID); w.Code != http.StatusOK {
+		t.Fatalf("wizard task before setup: got %d %s", w.Code, w.Body.String())
+	}
+
+	// 配置完成后向导任务也不再开放
+	apply := map[string]interface{}{
+		"exchange": "binance", "api_key": "key", "secret_key": "secret",
+		"config": map[string]interface{}{
+			"symbols_config": []map[string]interface{}{{
+				"symbol": "BTCUSDT", "price_interval": 10, "order_quantity": 30, "min_order_value": 20,
+				"buy_window_size": 10, "sell_window_size": 10,
+			}},
+		},
+	}
+	if w := postSetup(r, "/api/setup/apply-config", apply); w.Code != http.StatusOK {
+		t.Fatalf("apply: %d %s", w.Code, w.Body.String())
+	}
+	if w := getSetup(r, "/api/setup/generate-config/"+task.TaskID); w.Code != http.StatusForbidden {
+		t.Fatalf("wizard task after setup: got %d, want 403", w.Code)
+	}
+}
+
+func TestAITaskIDsAreRandom(t *testing.T) {
+	m := newAITaskManager()
+	first, second := m.CreateTask(), m.CreateTask()
+	if first.TaskID == second.TaskID || len(first.TaskID) != len("task_")+32 {
+		t.Fatalf("task IDs = %q, %q", first.TaskID, second.TaskID)
+	}
+}
@@ -0,0 +1,29 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/standby"
+)
+
+// StandbyProvider 热备状态提供者接口（需要从 main.go 注入，主实例为复制通道发布者，备用实例为订阅者）
+type StandbyProvider interface {
+	Status() *standby.Status
+}
+
+// SetStandbyProvider 设置热备状态提供者
+func SetStandbyProvider(provider StandbyProvider) {
+	defaultProviders.Standby = provider
+}
+
+// getStandbyStatus 获取热备状态（复制通道连接、最近消息、镜像快照、已接管交易对）
+// GET /api/standby/status
+func getStandbyStatus(c *gin.Context) {
+	standbyProvider := providersOf(c).Standby
+	if standbyProvider == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "status": standbyProvider.Status()})
+}
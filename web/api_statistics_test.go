@@ -0,0 +1,63 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/storage"
+	"quantmesh/testutil"
+)
+
+type mockStorageProvider struct {
+	st storage.Storage
+}
+
+func (p *mockStorageProvider) GetStorage() storage.Storage { return p.st }
+
+func TestGetStatisticsWithMockStorage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock := testutil.NewMockStorage()
+	SetStorageServiceProvider(&mockStorageProvider{st: mock})
+	defer SetStorageServiceProvider(nil)
+
+	now := time.Now()
+	for _, tr := range []*storage.Trade{
+		{Exchange: "binance", Symbol: "BTCUSDT", Quantity: 1, PnL: 5, CreatedAt: now},
+		{Exchange: "binance", Symbol: "BTCUSDT", Quantity: 1, PnL: -1, CreatedAt: now},
+		{Exchange: "binance", Symbol: "ETHUSDT", Quantity: 2, PnL: 3, CreatedAt: now},
+	} {
+		if err := mock.SaveTrade(tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := gin.New()
+	r.GET("/api/statistics", getStatistics)
+	get := func() (int, map[string]float64) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/statistics?exchange=binance&symbol=BTCUSDT", nil))
+		var body map[string]float64
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body
+	}
+
+	code, body := get()
+	if code != http.StatusOK || body["total_trades"] != 2 || body["total_pnl"] != 4 || body["win_rate"] != 0.5 {
+		t.Fatalf("unexpected response %d %v", code, body)
+	}
+
+	mock.GetStatisticsSummaryBySymbolFunc = func(exchange, symbol string) (*storage.Statistics, error) {
+		return nil, errors.New("disk full")
+	}
+	if code, _ := get(); code != http.StatusInternalServerError {
+		t.Fatalf("storage error: got status %d, want 500", code)
+	}
+	if n := mock.Calls("GetStatisticsSummaryBySymbol"); n != 2 {
+		t.Fatalf("got %d calls, want 2", n)
+	}
+}
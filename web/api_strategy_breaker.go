@@ -0,0 +1,94 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/strategy"
+)
+
+// StrategyBreakerProvider 单策略亏损熔断提供者接口（需要从 main.go 注入）
+type StrategyBreakerProvider interface {
+	GetLossBreakerStatus(exchange, symbol string) ([]strategy.LossBreakerStatus, error)
+	ResumeStrategy(exchange, symbol, name string) error
+}
+
+// SetStrategyBreakerProvider 设置单策略亏损熔断提供者
+func SetStrategyBreakerProvider(provider StrategyBreakerProvider) {
+	defaultProviders.StrategyBreaker = provider
+}
+
+// StrategyResumeRequest 手动恢复熔断策略请求
+type StrategyResumeRequest struct {
+	Exchange string `json:"exchange"`
+	Symbol   string `json:"symbol"`
+	Strategy string `json:"strategy" binding:"required"`
+}
+
+// getStrategyBreakerStatus 获取交易对下各策略的连续亏损与熔断暂停状态
+// GET /api/strategy-breaker?exchange=binance&symbol=BTCUSDT
+func getStrategyBreakerStatus(c *gin.Context) {
+	strategyBreakerProvider := providersOf(c).StrategyBreaker
+	exchangeName, symbol := c.Query("exchange"), strings.ToUpper(c.Query("symbol"))
+	if exchangeName == "" && globalConfig != nil {
+		exchangeName = globalConfig.App.CurrentExchange
+	}
+	if symbol == "" {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("需要 symbol"))
+		return
+	}
+	if strategyBreakerProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.strategy_breaker_failed", fmt.Errorf("交易服务未就绪"))
+		return
+	}
+
+	statuses, err := strategyBreakerProvider.GetLossBreakerStatus(exchangeName, symbol)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "error.strategy_breaker_failed", err)
+		return
+	}
+	if statuses == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":    true,
+		"strategies": statuses,
+	})
+}
+
+// resumeStrategyBreaker 手动恢复因亏损熔断暂停的策略
+// POST /api/strategy-breaker/resume
+func resumeStrategyBreaker(c *gin.Context) {
+	strategyBreakerProvider := providersOf(c).StrategyBreaker
+	var req StrategyResumeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if req.Exchange == "" && globalConfig != nil {
+		req.Exchange = globalConfig.App.CurrentExchange
+	}
+	if req.Symbol == "" {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", fmt.Errorf("需要 symbol"))
+		return
+	}
+	if strategyBreakerProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.strategy_breaker_failed", fmt.Errorf("交易服务未就绪"))
+		return
+	}
+
+	resource := fmt.Sprintf("%s:%s:%s", req.Exchange, req.Symbol, req.Strategy)
+	if err := strategyBreakerProvider.ResumeStrategy(req.Exchange, req.Symbol, req.Strategy); err != nil {
+		LogAction(c, "strategy_breaker_resume", resource, req, "failed", err.Error())
+		respondError(c, http.StatusBadRequest, "error.strategy_breaker_failed", err)
+		return
+	}
+	LogAction(c, "strategy_breaker_resume", resource, req, "success", "")
+	recordJournal(c, req.Exchange, req.Symbol, "手动恢复熔断策略", fmt.Sprintf("策略 %s 已手动解除亏损熔断", req.Strategy))
+
+	c.JSON(http.StatusOK, gin.H{"resumed": true, "strategy": req.Strategy})
+}
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/exchange"
+)
+
+// getSymbolMetadata 查询交易对元数据（精度、币种、合约类型、下单过滤条件）
+// GET /api/symbols/metadata?exchange=binance&symbol=BTCUSDT
+// 指定 symbol 时返回单个交易对，否则返回列表；listed_within_hours 仅返回该时间内新上线的交易对
+func getSymbolMetadata(c *gin.Context) {
+	registry := exchange.GetSymbolRegistry()
+	exchangeName := c.Query("exchange")
+
+	if symbol := c.Query("symbol"); symbol != "" {
+		if exchangeName == "" && globalConfig != nil {
+			exchangeName = globalConfig.App.CurrentExchange
+		}
+		meta, ok := registry.Lookup(exchangeName, symbol)
+		if !ok {
+			respondError(c, http.StatusNotFound, "error.symbol_not_found")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"symbol": meta})
+		return
+	}
+
+	var symbols []*exchange.SymbolMetadata
+	if hours, err := strconv.Atoi(c.Query("listed_within_hours")); err == nil && hours > 0 {
+		for _, meta := range registry.RecentListings(time.Now().Add(-time.Duration(hours) * time.Hour)) {
+			if exchangeName == "" || strings.EqualFold(meta.Exchange, exchangeName) {
+				symbols = append(symbols, meta)
+			}
+		}
+	} else {
+		symbols = registry.List(exchangeName)
+	}
+	if symbols == nil {
+		symbols = []*exchange.SymbolMetadata{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"symbols": symbols,
+		"count":   len(symbols),
+	})
+}
@@ -0,0 +1,214 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/config"
+	"quantmesh/exchange"
+	"quantmesh/logger"
+)
+
+// SymbolMigrationRequest 下架/改名交易对的迁移请求
+type SymbolMigrationRequest struct {
+	Exchange       string `json:"exchange"`
+	Symbol         string `json:"symbol"`
+	NewSymbol      string `json:"new_symbol"`      // 改名后的交易对，为空时从配置中移除该交易对
+	ClosePositions bool   `json:"close_positions"` // 迁移前市价平掉旧交易对的持仓
+	StartNew       bool   `json:"start_new"`       // 配置更新后立即启动新交易对
+}
+
+// SymbolMigrationResult 迁移结果
+type SymbolMigrationResult struct {
+	Exchange  string                  `json:"exchange"`
+	Symbol    string                  `json:"symbol"`
+	NewSymbol string                  `json:"new_symbol,omitempty"`
+	Stopped   bool                    `json:"stopped"`
+	Closed    *ClosePositionsResponse `json:"closed,omitempty"`
+	BackupID  string                  `json:"backup_id,omitempty"`
+	Started   bool                    `json:"started"`
+	Steps     []string                `json:"steps"`
+}
+
+// symbolMigrationItem 待迁移交易对（附带是否在配置中）
+type symbolMigrationItem struct {
+	*exchange.SymbolStatusChange
+	Configured bool `json:"configured"`
+}
+
+// getSymbolMigrations 列出检测到下架/改名、尚未迁移的交易对
+// GET /api/symbols/migrations
+func getSymbolMigrations(c *gin.Context) {
+	cfg := globalConfig
+	if latest, err := GetLatestConfig(); err == nil {
+		cfg = latest
+	}
+
+	items := make([]symbolMigrationItem, 0)
+	for _, change := range exchange.GetSymbolRegistry().PendingChanges() {
+		items = append(items, symbolMigrationItem{
+			SymbolStatusChange: change,
+			Configured:         cfg != nil && findSymbolConfig(cfg, change.Exchange, change.Symbol) >= 0,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"migrations": items,
+		"count":      len(items),
+	})
+}
+
+// migrateSymbol 引导式迁移：停止旧交易对 -> （可选）平仓 -> 更新配置（改名或移除）-> （可选）启动新交易对
+// 平仓失败时中止，配置保持不变
+// POST /api/symbols/migrate
+func migrateSymbol(c *gin.Context) {
+	symbolManagerProvider := providersOf(c).SymbolManager
+	var req SymbolMigrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	req.NewSymbol = strings.ToUpper(strings.TrimSpace(req.NewSymbol))
+	if req.Symbol == "" || req.NewSymbol == req.Symbol {
+		respondError(c, http.StatusBadRequest, "error.invalid_request",
+			fmt.Errorf("需要 symbol，且 new_symbol 不能与 symbol 相同"))
+		return
+	}
+	if req.Exchange == "" && globalConfig != nil {
+		req.Exchange = globalConfig.App.CurrentExchange
+	}
+	if symbolManagerProvider == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.symbol_manager_unavailable")
+		return
+	}
+	if configManager == nil {
+		respondError(c, http.StatusServiceUnavailable, "error.symbol_migration_failed", fmt.Errorf("配置管理器未初始化"))
+		return
+	}
+
+	resource := fmt.Sprintf("%s:%s", req.Exchange, req.Symbol)
+	result, err := runSymbolMigration(symbolManagerProvider, req)
+	if err != nil {
+		logger.Error("❌ [%s] 交易对迁移失败: %v", resource, err)
+		LogAction(c, "symbol_migrate", resource, gin.H{"request": req, "result": result}, "failed", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "result": result})
+		return
+	}
+	LogAction(c, "symbol_migrate", resource, result, "success", "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"result":  result,
+	})
+}
+
+// runSymbolMigration 按步骤执行迁移，返回已完成的步骤（失败时也返回，便于人工接手）
+func runSymbolMigration(symbolManagerProvider SymbolManagerProvider, req SymbolMigrationRequest) (*SymbolMigrationResult, error) {
+	result := &SymbolMigrationResult{Exchange: req.Exchange, Symbol: req.Symbol, NewSymbol: req.NewSymbol}
+
+	// 1. 先停止旧交易对，避免平仓过程中网格继续挂单
+	if _, running := symbolManagerProvider.Get(req.Exchange, req.Symbol); running {
+		if err := symbolManagerProvider.StopSymbol(req.Exchange, req.Symbol); err != nil {
+			return result, fmt.Errorf("停止交易对失败: %w", err)
+		}
+		result.Stopped = true
+		result.Steps = append(result.Steps, "已停止交易")
+	}
+
+	// 2. 平仓（撤销挂单并市价平掉全部持仓）
+	if req.ClosePositions {
+		closer, ok := symbolManagerProvider.(interface {
+			ClosePositions(exchange, symbol string) (*ClosePositionsResponse, error)
+		})
+		if !ok {
+			return result, fmt.Errorf("当前运行模式不支持平仓")
+		}
+		closed, err := closer.ClosePositions(req.Exchange, req.Symbol)
+		if err != nil {
+			return result, fmt.Errorf("平仓失败: %w", err)
+		}
+		result.Closed = closed
+		result.Steps = append(result.Steps, closed.Message)
+		if closed.FailCount > 0 {
+			return result, fmt.Errorf("有 %d 个持仓平仓失败，配置未修改", closed.FailCount)
+		}
+	}
+
+	// 3. 更新配置（先备份）
+	oldCfg, err := configManager.GetConfig()
+	if err != nil {
+		return result, fmt.Errorf("获取当前配置失败: %w", err)
+	}
+	newCfg, err := migrateSymbolConfig(oldCfg, req.Exchange, req.Symbol, req.NewSymbol)
+	if err != nil {
+		return result, err
+	}
+	if configBackupMgr != nil {
+		backup, err := configBackupMgr.CreateBackup(configManager.GetConfigPath(),
+			fmt.Sprintf("迁移交易对 %s:%s", req.Exchange, req.Symbol))
+		if err != nil {
+			return result, fmt.Errorf("创建备份失败: %w", err)
+		}
+		result.BackupID = backup.ID
+	}
+	if err := configManager.UpdateConfig(newCfg); err != nil {
+		return result, fmt.Errorf("保存配置失败: %w", err)
+	}
+	if req.NewSymbol != "" {
+		result.Steps = append(result.Steps, fmt.Sprintf("配置已改为 %s", req.NewSymbol))
+	} else {
+		result.Steps = append(result.Steps, "已从配置中移除")
+	}
+	exchange.GetSymbolRegistry().ResolveChange(req.Exchange, req.Symbol)
+
+	// 4. 启动新交易对（槽位库存从空开始，旧持仓已在第 2 步处理）
+	if req.StartNew && req.NewSymbol != "" {
+		if err := symbolManagerProvider.StartSymbol(req.Exchange, req.NewSymbol); err != nil {
+			return result, fmt.Errorf("配置已更新，但启动 %s 失败: %w", req.NewSymbol, err)
+		}
+		result.Started = true
+		result.Steps = append(result.Steps, fmt.Sprintf("已启动 %s", req.NewSymbol))
+	}
+
+	logger.Info("✅ [%s:%s] 交易对迁移完成: %s", req.Exchange, req.Symbol, strings.Join(result.Steps, " -> "))
+	return result, nil
+}
+
+// findSymbolConfig 查找交易对在配置中的位置，未找到返回 -1
+func findSymbolConfig(cfg *config.Config, exchangeName, symbol string) int {
+	for i, sym := range cfg.Trading.Symbols {
+		exName := sym.Exchange
+		if exName == "" {
+			exName = cfg.App.CurrentExchange
+		}
+		if strings.EqualFold(exName, exchangeName) && strings.EqualFold(sym.Symbol, symbol) {
+			return i
+		}
+	}
+	return -1
+}
+
+// migrateSymbolConfig 生成迁移后的配置（不修改原配置）
+// newSymbol 非空时改名（保留原有参数），新交易对已在配置中时直接移除旧交易对
+func migrateSymbolConfig(cfg *config.Config, exchangeName, symbol, newSymbol string) (*config.Config, error) {
+	idx := findSymbolConfig(cfg, exchangeName, symbol)
+	if idx < 0 {
+		return nil, fmt.Errorf("配置中未找到交易对 %s:%s", exchangeName, symbol)
+	}
+
+	newCfg := *cfg
+	symbols := make([]config.SymbolConfig, 0, len(cfg.Trading.Symbols))
+	for i, sym := range cfg.Trading.Symbols {
+		if i != idx {
+			symbols = append(symbols, sym)
+		}
+	}
+	if newSymbol != "" && findSymbolConfig(cfg, exchangeName, newSymbol) < 0 {
+		renamed := cfg.Trading.Symbols[idx]
+		renamed.Symbol = newSymbol
+		symbols = append(symbols[:idx], append([]config.SymbolConfig{renamed}, symbols[idx:]...)...)
+	}
+	newCfg.Trading.Symbols = symbols
+	return &newCfg, nil
+}
@@ -0,0 +1,49 @@
+package web
+
+import (
+	"testing"
+
+	"quantmesh/config"
+)
+
+func TestMigrateSymbolConfig(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.App.CurrentExchange = "binance"
+	cfg.Trading.Symbols = []config.SymbolConfig{
+		{Symbol: "BTCUSDT", PriceInterval: 10},
+		{Exchange: "binance", Symbol: "SHIBUSDT", PriceInterval: 0.0000001},
+		{Exchange: "binance", Symbol: "ETHUSDT", PriceInterval: 1},
+	}
+
+	// 改名：保留原有参数和位置
+	renamed, err := migrateSymbolConfig(cfg, "binance", "SHIBUSDT", "1000SHIBUSDT")
+	if err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	if len(renamed.Trading.Symbols) != 3 || renamed.Trading.Symbols[1].Symbol != "1000SHIBUSDT" ||
+		renamed.Trading.Symbols[1].PriceInterval != 0.0000001 {
+		t.Errorf("改名结果错误: %+v", renamed.Trading.Symbols)
+	}
+	if cfg.Trading.Symbols[1].Symbol != "SHIBUSDT" {
+		t.Error("不应修改原配置")
+	}
+
+	// 移除：未填写 exchange 的交易对按 current_exchange 匹配
+	removed, err := migrateSymbolConfig(cfg, "binance", "BTCUSDT", "")
+	if err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	if len(removed.Trading.Symbols) != 2 || removed.Trading.Symbols[0].Symbol != "SHIBUSDT" {
+		t.Errorf("移除结果错误: %+v", removed.Trading.Symbols)
+	}
+
+	// 新交易对已在配置中时只移除旧交易对
+	merged, _ := migrateSymbolConfig(cfg, "binance", "SHIBUSDT", "ETHUSDT")
+	if len(merged.Trading.Symbols) != 2 {
+		t.Errorf("新交易对已存在时不应重复添加: %+v", merged.Trading.Symbols)
+	}
+
+	if _, err := migrateSymbolConfig(cfg, "okx", "BTCUSDT", ""); err == nil {
+		t.Error("配置中不存在的交易对应返回错误")
+	}
+}
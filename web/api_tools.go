@@ -0,0 +1,226 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"quantmesh/safety"
+)
+
+// GridPreviewRequest 网格试算请求
+// 未填写的字段按配置文件中该交易对的设置补齐
+type GridPreviewRequest struct {
+	Exchange              string  `json:"exchange"`
+	Symbol                string  `json:"symbol"`
+	Capital               float64 `json:"capital"`
+	Price                 float64 `json:"price"` // 不填时使用当前行情价格
+	OrderQuantity         float64 `json:"order_quantity"`
+	PriceInterval         float64 `json:"price_interval"`
+	BuyWindowSize         int     `json:"buy_window_size"`
+	SellWindowSize        int     `json:"sell_window_size"`
+	Leverage              int     `json:"leverage"`
+	FeeRate               float64 `json:"fee_rate"`
+	MaintenanceMarginRate float64 `json:"maintenance_margin_rate"`
+}
+
+// previewGridHandler 网格参数试算（不下单）
+// POST /api/tools/grid-preview
+func previewGridHandler(c *gin.Context) {
+	priceProvider := providersOf(c).Price
+	var req GridPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+
+	fillGridPreviewDefaults(&req)
+
+	if req.Price <= 0 {
+		providersMu.RLock()
+		provider := priceProviders[makeSymbolKey(req.Exchange, req.Symbol)]
+		providersMu.RUnlock()
+		if provider == nil && req.Symbol == "" {
+			provider = priceProvider
+		}
+		if provider != nil {
+			req.Price = provider.GetLastPrice()
+		}
+	}
+	if req.Price <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无法获取当前价格，请在请求中指定 price"})
+		return
+	}
+
+	preview, err := safety.PreviewGrid(safety.GridPreviewParams{
+		Capital:               req.Capital,
+		Price:                 req.Price,
+		OrderAmount:           req.OrderQuantity,
+		PriceInterval:         req.PriceInterval,
+		BuyWindow:             req.BuyWindowSize,
+		SellWindow:            req.SellWindowSize,
+		Leverage:              req.Leverage,
+		FeeRate:               req.FeeRate,
+		MaintenanceMarginRate: req.MaintenanceMarginRate,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"params":  req,
+		"preview": preview,
+	})
+}
+
+// fillGridPreviewDefaults 用配置文件中的交易对设置补齐未填写的试算参数
+func fillGridPreviewDefaults(req *GridPreviewRequest) {
+	if configManager == nil {
+		return
+	}
+	cfg, err := configManager.GetConfig()
+	if err != nil || cfg == nil {
+		return
+	}
+
+	if req.Exchange == "" {
+		req.Exchange = cfg.App.CurrentExchange
+	}
+	if req.FeeRate <= 0 {
+		if exCfg, ok := cfg.Exchanges[req.Exchange]; ok {
+			req.FeeRate = exCfg.FeeRate
+		}
+	}
+	if req.Leverage <= 0 {
+		req.Leverage = 1
+	}
+
+	for _, symCfg := range cfg.Trading.Symbols {
+		if !strings.EqualFold(symCfg.Exchange, req.Exchange) || !strings.EqualFold(symCfg.Symbol, req.Symbol) {
+			continue
+		}
+		if req.Capital <= 0 {
+			req.Capital = symCfg.TotalAllocatedCapital
+		}
+		if req.OrderQuantity <= 0 {
+			req.OrderQuantity = symCfg.OrderQuantity
+		}
+		if req.PriceInterval <= 0 {
+			req.PriceInterval = symCfg.PriceInterval
+		}
+		if req.BuyWindowSize <= 0 {
+			req.BuyWindowSize = symCfg.BuyWindowSize
+		}
+		if req.SellWindowSize <= 0 {
+			req.SellWindowSize = symCfg.SellWindowSize
+		}
+		break
+	}
+}
+
+// SymbolScoreRequest 交易对适配度评估请求（候选交易对无需在配置文件中）
+// 未填写的价格间隔、买单窗口和手续费率按配置补齐，仍为空时价格间隔按小时波动率建议
+type SymbolScoreRequest struct {
+	Exchange            string  `json:"exchange"`
+	Symbol              string  `json:"symbol"`
+	PriceInterval       float64 `json:"price_interval"`
+	BuyWindowSize       int     `json:"buy_window_size"`
+	FeeRate             float64 `json:"fee_rate"`
+	LookbackHours       int     `json:"lookback_hours"`        // 波动率回看小时数（默认 168，最多 1000）
+	FundingSamples      int     `json:"funding_samples"`       // 资金费率历史期数（默认 90，约 30 天）
+	SpreadSampleSeconds int     `json:"spread_sample_seconds"` // 盘口价差采样时长（秒，默认 3，最多 10，0 表示默认）
+}
+
+// SymbolScoreReport 交易对适配度评估报告
+type SymbolScoreReport struct {
+	Exchange       string              `json:"exchange"`
+	Symbol         string              `json:"symbol"`
+	Price          float64             `json:"price"`
+	Candles        int                 `json:"candles"`         // 参与计算的小时 K 线数
+	FundingSamples int                 `json:"funding_samples"` // 参与计算的资金费率期数
+	FundingSource  string              `json:"funding_source"`  // history（资金费率监控记录）/ current（仅当前费率）/ none
+	Spread         float64             `json:"spread"`          // 盘口相对价差（0 表示未采样到）
+	SpreadSource   string              `json:"spread_source"`   // depth_stream / none
+	QuoteVolume24h float64             `json:"quote_volume_24h"`
+	VolumeSource   string              `json:"volume_source"` // ticker / klines
+	Score          *safety.SymbolScore `json:"score"`
+	GeneratedAt    time.Time           `json:"generated_at"`
+}
+
+// SymbolScoreProvider 交易对适配度评估提供者接口（需要从 main.go 注入）
+type SymbolScoreProvider interface {
+	ScoreSymbol(ctx context.Context, req SymbolScoreRequest) (*SymbolScoreReport, error)
+}
+
+// SetSymbolScoreProvider 设置交易对适配度评估提供者
+func SetSymbolScoreProvider(provider SymbolScoreProvider) {
+	defaultProviders.SymbolScore = provider
+}
+
+// scoreSymbolHandler 评估候选交易对是否适合网格做市：按历史波动率、资金费率、价差和成交额估算网格收益与资金费拖累（不下单）
+// POST /api/tools/symbol-score
+func scoreSymbolHandler(c *gin.Context) {
+	symbolScoreProvider := providersOf(c).SymbolScore
+	if symbolScoreProvider == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	var req SymbolScoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "error.invalid_request", err)
+		return
+	}
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if !fundingSymbolPattern.MatchString(req.Symbol) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "交易对格式无效"})
+		return
+	}
+	if req.LookbackHours > 1000 || req.FundingSamples > 1000 || req.SpreadSampleSeconds > 10 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lookback_hours、funding_samples 最多 1000，spread_sample_seconds 最多 10"})
+		return
+	}
+	fillSymbolScoreDefaults(&req)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+	report, err := symbolScoreProvider.ScoreSymbol(ctx, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"params":  req,
+		"report":  report,
+	})
+}
+
+// fillSymbolScoreDefaults 补齐评估参数：交易对已配置时沿用其价格间隔和买单窗口
+func fillSymbolScoreDefaults(req *SymbolScoreRequest) {
+	if req.LookbackHours <= 0 {
+		req.LookbackHours = 168
+	}
+	if req.FundingSamples <= 0 {
+		req.FundingSamples = 90
+	}
+	if req.SpreadSampleSeconds <= 0 {
+		req.SpreadSampleSeconds = 3
+	}
+	preview := GridPreviewRequest{
+		Exchange:      req.Exchange,
+		Symbol:        req.Symbol,
+		PriceInterval: req.PriceInterval,
+		BuyWindowSize: req.BuyWindowSize,
+		FeeRate:       req.FeeRate,
+	}
+	fillGridPreviewDefaults(&preview)
+	req.Exchange = preview.Exchange
+	req.PriceInterval = preview.PriceInterval
+	req.BuyWindowSize = preview.BuyWindowSize
+	req.FeeRate = preview.FeeRate
+}
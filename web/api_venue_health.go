@@ -0,0 +1,18 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/exchange"
+)
+
+// getExchangesHealth 获取各交易所的健康评分（REST 延迟、错误率、行情推送停滞时间）
+// GET /api/exchanges/health
+func getExchangesHealth(c *gin.Context) {
+	venues := exchange.AllVenueHealth()
+	c.JSON(http.StatusOK, gin.H{
+		"venues": venues,
+		"count":  len(venues),
+	})
+}
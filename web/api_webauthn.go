@@ -612,7 +612,7 @@ func finishWebAuthnLogin(c *gin.Context) {
 		session, err := sm.CreateSession(req.Username, "admin", ip, userAgent)
 		if err == nil {
 			secure := c.Request.TLS != nil
-			sm.SetSessionCookie(c.Writer, session.SessionID, secure)
+			sm.SetLoginCookies(c.Writer, session, secure)
 		}
 	}
 
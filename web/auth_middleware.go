@@ -11,6 +11,13 @@ import (
 // authMiddleware 认证中间件
 func authMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// 本地管理 socket 的请求由文件权限控制访问
+		if isLocalAdmin(c.Request) {
+			c.Set("username", localAdminUsername)
+			c.Next()
+			return
+		}
+
 		// 获取会话管理器
 		sm := GetSessionManager()
 		if sm == nil {
@@ -45,6 +52,9 @@ func authMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 滑动续期
+		sm.TouchSession(session)
+
 		// 将会话信息存储到上下文中，供后续处理使用
 		c.Set("session", session)
 		c.Set("username", session.Username)
@@ -67,3 +77,20 @@ func optionalAuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// adminOnlyMiddleware 仅允许管理员角色的会话（及本地管理 socket）访问，须放在 authMiddleware 之后
+func adminOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isLocalAdmin(c.Request) {
+			c.Next()
+			return
+		}
+		value, _ := c.Get("session")
+		if session, ok := value.(*Session); !ok || session == nil || session.Role != "admin" {
+			respondError(c, http.StatusForbidden, "error.admin_required")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
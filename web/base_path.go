@@ -0,0 +1,98 @@
+package web
+
+import (
+	"bytes"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// basePath 对外访问的子路径（web.base_path，如 /quantmesh），为空表示部署在根路径
+// 路由仍注册在根路径下，由 basePathHandler 在进入 gin 之前去掉前缀；
+// 反向代理已自行去掉前缀（nginx proxy_pass 末尾带 /）时请求不带前缀，同样可以处理
+var basePath string
+
+// BasePath 获取对外访问的子路径
+func BasePath() string {
+	return basePath
+}
+
+// withBasePath 为站内绝对路径加上子路径前缀（Cookie Path、重定向等）
+func withBasePath(p string) string {
+	return basePath + p
+}
+
+// basePathHandler 去掉请求路径中的子路径前缀
+func basePathHandler(prefix string, next http.Handler) http.Handler {
+	if prefix == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if path == prefix {
+			// /quantmesh -> /quantmesh/，保证页面中的相对路径正确
+			target := prefix + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			return
+		}
+		if strings.HasPrefix(path, prefix+"/") {
+			r2 := r.Clone(r.Context())
+			r2.URL.Path = strings.TrimPrefix(path, prefix)
+			if r.URL.RawPath != "" {
+				r2.URL.RawPath = strings.TrimPrefix(r.URL.RawPath, prefix)
+			}
+			r2.RequestURI = r2.URL.RequestURI()
+			next.ServeHTTP(w, r2)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// absoluteAttrPattern 以 / 开头（不含 // 协议相对地址）的 src/href/content 属性
+var absoluteAttrPattern = regexp.MustCompile(`(src|href|content)="/([^/])`)
+
+// setBasePath 设置子路径，并让嵌入文件按新的子路径重新生成
+func setBasePath(p string) {
+	if p == basePath {
+		return
+	}
+	basePath = p
+	assetIndexOnce = sync.Once{}
+}
+
+// rewriteForBasePath 改写前端入口页面和 Service Worker 注册脚本中的站内绝对路径
+// Vite 构建产物以 / 开头引用资源，部署在子路径下时需要加上前缀；
+// 同时注入 window.__QUANTMESH_BASE__，并让以 / 开头的 fetch 请求自动带上前缀
+func rewriteForBasePath(name string, data []byte) []byte {
+	if basePath == "" {
+		return data
+	}
+	switch {
+	case strings.HasSuffix(name, ".html"):
+		data = absoluteAttrPattern.ReplaceAll(data, []byte(`${1}="`+basePath+`/${2}`))
+		script := `<script>window.__QUANTMESH_BASE__=` + jsString(basePath) + `;(function(b){var f=window.fetch;` +
+			`window.fetch=function(i,o){if(typeof i==="string"&&i.charAt(0)==="/"&&i.indexOf(b+"/")!==0){i=b+i}` +
+			`return f.call(this,i,o)}})(window.__QUANTMESH_BASE__);</script>`
+		if idx := bytes.Index(data, []byte("<head>")); idx >= 0 {
+			idx += len("<head>")
+			data = append(data[:idx:idx], append([]byte(script), data[idx:]...)...)
+		} else {
+			data = append([]byte(script), data...)
+		}
+	case strings.HasSuffix(name, "registerSW.js"):
+		data = bytes.ReplaceAll(data, []byte(`'/sw.js'`), []byte(`'`+basePath+`/sw.js'`))
+		data = bytes.ReplaceAll(data, []byte(`scope: '/'`), []byte(`scope: '`+basePath+`/'`))
+		data = bytes.ReplaceAll(data, []byte(`scope:'/'`), []byte(`scope:'`+basePath+`/'`))
+	}
+	return data
+}
+
+// jsString 生成 JS 字符串字面量（base_path 已校验，不含引号和反斜杠）
+func jsString(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,63 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBasePathHandler(t *testing.T) {
+	var gotPath string
+	h := basePathHandler("/quantmesh", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	}))
+
+	cases := map[string]string{
+		"/quantmesh/api/status": "/api/status",
+		"/quantmesh/":           "/",
+		// 代理已去掉前缀
+		"/api/status": "/api/status",
+		// 前缀只匹配完整的路径段
+		"/quantmeshx/api": "/quantmeshx/api",
+	}
+	for in, want := range cases {
+		gotPath = ""
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, in, nil))
+		if gotPath != want {
+			t.Errorf("%s -> %q, want %q", in, gotPath, want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/quantmesh?x=1", nil))
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/quantmesh/?x=1" {
+		t.Errorf("redirect = %d %q", w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestRewriteForBasePath(t *testing.T) {
+	setBasePath("/quantmesh")
+	defer setBasePath("")
+
+	html := `<html><head><script src="/assets/index-abc.js"></script>` +
+		`<link href="/assets/index.css"><link href="//cdn.example.com/x.css"></head></html>`
+	got := string(rewriteForBasePath("dist/index.html", []byte(html)))
+	if !strings.Contains(got, `src="/quantmesh/assets/index-abc.js"`) || !strings.Contains(got, `href="/quantmesh/assets/index.css"`) {
+		t.Errorf("asset paths not rewritten: %s", got)
+	}
+	if !strings.Contains(got, `href="//cdn.example.com/x.css"`) {
+		t.Errorf("protocol-relative URL should be kept: %s", got)
+	}
+	if !strings.Contains(got, `window.__QUANTMESH_BASE__="/quantmesh"`) {
+		t.Errorf("base path not injected: %s", got)
+	}
+
+	sw := string(rewriteForBasePath("dist/registerSW.js", []byte(`navigator.serviceWorker.register('/sw.js', { scope: '/' })`)))
+	if sw != `navigator.serviceWorker.register('/quantmesh/sw.js', { scope: '/quantmesh/' })` {
+		t.Errorf("registerSW not rewritten: %s", sw)
+	}
+	if withBasePath(refreshTokenCookiePath) != "/quantmesh/api/auth/refresh" {
+		t.Errorf("cookie path = %s", withBasePath(refreshTokenCookiePath))
+	}
+}
@@ -0,0 +1,185 @@
+package web
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+	"quantmesh/config"
+)
+
+// endpointLimiter 单个接口的限流器与响应缓存
+// 限流按客户端 IP 计算；缓存按用户 + 完整查询串区分，只缓存 200 响应，
+// 同一时刻多个仪表盘轮询同一份数据时只会有一次请求真正打到交易所或日志库
+type endpointLimiter struct {
+	rateLimit rate.Limit
+	burst     int
+	cacheTTL  time.Duration
+
+	limiterMu sync.Mutex
+	limiters  map[string]*ipLimiter
+
+	cacheMu sync.Mutex
+	cache   map[string]*cachedResponse
+}
+
+// cachedResponse 缓存的响应
+type cachedResponse struct {
+	status      int
+	contentType string
+	body        []byte
+	expiresAt   time.Time
+}
+
+// endpointLimitMiddleware 按 web.endpoint_limits 为高开销接口限流并缓存响应
+// 未配置的接口直接放行
+func endpointLimitMiddleware(cfg *config.Config) gin.HandlerFunc {
+	limits := config.DefaultEndpointLimits()
+	if cfg != nil && cfg.Web.EndpointLimits != nil {
+		limits = cfg.Web.EndpointLimits
+	}
+	limiters := make(map[string]*endpointLimiter, len(limits))
+	for path, l := range limits {
+		if l.RatePerMinute <= 0 && l.CacheTTL <= 0 {
+			continue
+		}
+		limiters[path] = newEndpointLimiter(l)
+	}
+
+	return func(c *gin.Context) {
+		l, ok := limiters[c.FullPath()]
+		if !ok {
+			c.Next()
+			return
+		}
+		l.handle(c)
+	}
+}
+
+func newEndpointLimiter(l config.EndpointLimitConfig) *endpointLimiter {
+	burst := l.Burst
+	if burst <= 0 {
+		burst = 3
+	}
+	return &endpointLimiter{
+		rateLimit: rate.Limit(float64(l.RatePerMinute) / 60),
+		burst:     burst,
+		cacheTTL:  time.Duration(l.CacheTTL) * time.Second,
+		limiters:  make(map[string]*ipLimiter),
+		cache:     make(map[string]*cachedResponse),
+	}
+}
+
+// handle 先查缓存（命中不消耗限流额度），再限流，最后执行并缓存响应
+func (l *endpointLimiter) handle(c *gin.Context) {
+	key := l.cacheKey(c)
+	if l.cacheTTL > 0 {
+		if resp := l.lookup(key); resp != nil {
+			c.Header("X-Cache", "HIT")
+			c.Data(resp.status, resp.contentType, resp.body)
+			c.Abort()
+			return
+		}
+	}
+
+	if l.rateLimit > 0 && !l.allow(c.ClientIP()) {
+		c.Header("Retry-After", strconv.Itoa(int(1/float64(l.rateLimit))+1))
+		respondError(c, http.StatusTooManyRequests, "error.too_many_requests")
+		c.Abort()
+		return
+	}
+
+	if l.cacheTTL <= 0 {
+		c.Next()
+		return
+	}
+
+	c.Header("X-Cache", "MISS")
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Next()
+	if recorder.Status() == http.StatusOK {
+		l.store(key, &cachedResponse{
+			status:      http.StatusOK,
+			contentType: recorder.Header().Get("Content-Type"),
+			body:        recorder.body.Bytes(),
+			expiresAt:   time.Now().Add(l.cacheTTL),
+		})
+	}
+}
+
+// cacheKey 用户名 + 语言 + 请求 URI（响应中的错误信息随语言变化）
+func (l *endpointLimiter) cacheKey(c *gin.Context) string {
+	return c.GetString("username") + "|" + c.GetHeader("Accept-Language") + "|" + c.Request.URL.RequestURI()
+}
+
+// allow 按客户端 IP 限流，顺带清理长时间未访问的限流器
+func (l *endpointLimiter) allow(ip string) bool {
+	l.limiterMu.Lock()
+	defer l.limiterMu.Unlock()
+
+	now := time.Now()
+	lim, ok := l.limiters[ip]
+	if !ok {
+		if len(l.limiters) > 10000 {
+			for k, v := range l.limiters {
+				if now.Sub(v.lastSeen) > 10*time.Minute {
+					delete(l.limiters, k)
+				}
+			}
+		}
+		lim = &ipLimiter{limiter: rate.NewLimiter(l.rateLimit, l.burst)}
+		l.limiters[ip] = lim
+	}
+	lim.lastSeen = now
+	return lim.limiter.Allow()
+}
+
+func (l *endpointLimiter) lookup(key string) *cachedResponse {
+	l.cacheMu.Lock()
+	defer l.cacheMu.Unlock()
+	resp, ok := l.cache[key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(resp.expiresAt) {
+		delete(l.cache, key)
+		return nil
+	}
+	return resp
+}
+
+// store 写入缓存，条目过多时清理已过期的条目
+func (l *endpointLimiter) store(key string, resp *cachedResponse) {
+	l.cacheMu.Lock()
+	defer l.cacheMu.Unlock()
+	if len(l.cache) > 1000 {
+		now := time.Now()
+		for k, v := range l.cache {
+			if now.After(v.expiresAt) {
+				delete(l.cache, k)
+			}
+		}
+	}
+	l.cache[key] = resp
+}
+
+// responseRecorder 在写出响应的同时保留一份副本
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,84 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/config"
+)
+
+func newEndpointLimitRouter(limits map[string]config.EndpointLimitConfig, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Web.EndpointLimits = limits
+	r := gin.New()
+	r.Use(endpointLimitMiddleware(cfg))
+	handler := func(c *gin.Context) {
+		*calls++
+		c.JSON(http.StatusOK, gin.H{"calls": *calls})
+	}
+	r.GET("/api/klines", handler)
+	r.GET("/api/logs", handler)
+	r.GET("/api/status", handler)
+	return r
+}
+
+func TestEndpointLimitCache(t *testing.T) {
+	calls := 0
+	r := newEndpointLimitRouter(map[string]config.EndpointLimitConfig{
+		"/api/klines": {CacheTTL: 60},
+	}, &calls)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	first := get("/api/klines?symbol=BTCUSDT")
+	second := get("/api/klines?symbol=BTCUSDT")
+	if calls != 1 {
+		t.Fatalf("handler called %d times, want 1", calls)
+	}
+	if first.Header().Get("X-Cache") != "MISS" || second.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("X-Cache = %q, %q", first.Header().Get("X-Cache"), second.Header().Get("X-Cache"))
+	}
+	if first.Body.String() != second.Body.String() {
+		t.Errorf("cached body mismatch: %q vs %q", first.Body.String(), second.Body.String())
+	}
+
+	// 不同查询参数分开缓存
+	get("/api/klines?symbol=ETHUSDT")
+	if calls != 2 {
+		t.Errorf("different query should miss cache, calls = %d", calls)
+	}
+
+	// 未配置的接口不缓存
+	get("/api/status")
+	get("/api/status")
+	if calls != 4 {
+		t.Errorf("unconfigured endpoint should pass through, calls = %d", calls)
+	}
+}
+
+func TestEndpointLimitRate(t *testing.T) {
+	calls := 0
+	r := newEndpointLimitRouter(map[string]config.EndpointLimitConfig{
+		"/api/logs": {RatePerMinute: 1, Burst: 2},
+	}, &calls)
+
+	codes := make([]int, 0, 3)
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/logs", nil))
+		codes = append(codes, w.Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("codes = %v, want [200 200 429]", codes)
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2", calls)
+	}
+}
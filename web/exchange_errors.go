@@ -0,0 +1,33 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/exchange"
+)
+
+// exchangeErrorResponses 交易所错误分类对应的 HTTP 状态码与提示
+var exchangeErrorResponses = []struct {
+	category   error
+	status     int
+	messageKey string
+}{
+	{exchange.ErrRateLimited, http.StatusTooManyRequests, "error.exchange_rate_limited"},
+	{exchange.ErrInsufficientMargin, http.StatusBadRequest, "error.exchange_insufficient_margin"},
+	{exchange.ErrOrderNotFound, http.StatusNotFound, "error.exchange_order_not_found"},
+	{exchange.ErrInvalidFilter, http.StatusBadRequest, "error.exchange_invalid_filter"},
+	{exchange.ErrRestrictedRegion, http.StatusUnavailableForLegalReasons, "error.exchange_restricted_region"},
+}
+
+// respondExchangeError 按交易所错误分类返回统一的状态码与提示，未分类的错误使用 status/messageKey
+func respondExchangeError(c *gin.Context, status int, messageKey string, err error) {
+	for _, r := range exchangeErrorResponses {
+		if errors.Is(err, r.category) {
+			c.JSON(r.status, gin.H{"error": T(c, r.messageKey), "detail": err.Error()})
+			return
+		}
+	}
+	respondError(c, status, messageKey, err)
+}
@@ -0,0 +1,92 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Providers Web 层依赖的全部服务提供者
+// 通过 NewServer 注入后按请求解析，同一进程内的多个 WebServer（或测试）互不影响；
+// 旧的 SetXxxProvider 写入默认集合，未注入 Providers 的路由（如直接调用 SetupRoutes）使用默认集合
+type Providers struct {
+	Price           PriceProvider
+	Exchange        ExchangeProvider
+	Storage         StorageServiceProvider
+	SymbolManager   SymbolManagerProvider
+	SystemMetrics   SystemMetricsProvider
+	PositionManager PositionManagerProvider
+	Strategy        StrategyProvider
+	LogStorage      LogStorageProvider
+	RiskMonitor     RiskMonitorProvider
+	FundingMonitor  FundingMonitorProvider
+	DataSource      DataSourceProvider
+	BasisMonitor    BasisMonitorProvider
+
+	AIMarketAnalyzer     AIMarketAnalyzerProvider
+	AIParameterOptimizer AIParameterOptimizerProvider
+	AIRiskAnalyzer       AIRiskAnalyzerProvider
+	AISentimentAnalyzer  AISentimentAnalyzerProvider
+	AIPolymarketSignal   AIPolymarketSignalProvider
+	AIPromptManager      AIPromptManagerProvider
+	AIModuleScheduler    AIModuleSchedulerProvider
+
+	Benchmark            BenchmarkProvider
+	Levels               LevelsProvider
+	CapitalDataSource    CapitalDataSource
+	Event                EventProvider
+	FillAnomaly          FillAnomalyProvider
+	Fleet                FleetProvider
+	FundingSymbols       FundingSymbolsProvider
+	Goal                 GoalProvider
+	GridRecenter         GridRecenterProvider
+	Hedge                HedgeProvider
+	IncomeReconciliation IncomeReconciliationProvider
+	Journal              JournalProvider
+	KlineHistory         KlineHistoryProvider
+	Lifecycle            LifecycleProvider
+	MarginModel          MarginModelProvider
+	NotificationTemplate NotificationTemplateProvider
+	OCO                  OCOProvider
+	OrderCleaner         OrderCleanerProvider
+	OpenInterest         OpenInterestProvider
+	OrderExpiry          OrderExpiryProvider
+	PositionEditor       PositionEditorProvider
+	PortfolioOptimizer   PortfolioOptimizerProvider
+	Reconciliation       ReconciliationProvider
+	Restart              RestartProvider
+	RiskProfile          RiskProfileProvider
+	Scheduler            SchedulerProvider
+	Standby              StandbyProvider
+	StrategyBreaker      StrategyBreakerProvider
+	SymbolScore          SymbolScoreProvider
+}
+
+// providersContextKey 请求上下文中 Providers 的键
+const providersContextKey = "web.providers"
+
+// defaultProviders 旧的 SetXxxProvider 写入的默认集合
+var defaultProviders = &Providers{}
+
+// DefaultProviders 返回默认的提供者集合（SetXxxProvider 写入的同一实例）
+func DefaultProviders() *Providers {
+	return defaultProviders
+}
+
+// providersMiddleware 把 WebServer 持有的 Providers 绑定到请求上下文
+func providersMiddleware(providers *Providers) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(providersContextKey, providers)
+		c.Next()
+	}
+}
+
+// providersOf 返回处理当前请求的 Providers，未绑定时使用默认集合
+func providersOf(c *gin.Context) *Providers {
+	if c != nil {
+		if v, ok := c.Get(providersContextKey); ok {
+			if providers, ok := v.(*Providers); ok && providers != nil {
+				return providers
+			}
+		}
+	}
+	return defaultProviders
+}
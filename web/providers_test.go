@@ -0,0 +1,48 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestProvidersAreScopedPerServer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetLifecycleProvider(&fakeLifecycleProvider{mode: "default"})
+	defer SetLifecycleProvider(nil)
+
+	newEngine := func(providers *Providers) *gin.Engine {
+		r := gin.New()
+		r.Use(providersMiddleware(providers))
+		r.GET("/api/health", getHealth)
+		return r
+	}
+	mode := func(r *gin.Engine) string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+		var health LifecycleHealth
+		if err := json.Unmarshal(w.Body.Bytes(), &health); err != nil {
+			t.Fatalf("decode health: %v", err)
+		}
+		return health.Mode
+	}
+
+	a := newEngine(&Providers{Lifecycle: &fakeLifecycleProvider{mode: "trading"}})
+	b := newEngine(&Providers{Lifecycle: &fakeLifecycleProvider{mode: "standby"}})
+	if got := mode(a); got != "trading" {
+		t.Errorf("server a: got mode %q, want trading", got)
+	}
+	if got := mode(b); got != "standby" {
+		t.Errorf("server b: got mode %q, want standby", got)
+	}
+
+	// 未绑定 Providers 的路由回退到 SetXxxProvider 写入的默认集合
+	legacy := gin.New()
+	legacy.GET("/api/health", getHealth)
+	if got := mode(legacy); got != "default" {
+		t.Errorf("legacy route: got mode %q, want default", got)
+	}
+}
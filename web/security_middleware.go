@@ -0,0 +1,197 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"quantmesh/config"
+	"quantmesh/logger"
+)
+
+const (
+	csrfCookieName = "csrf_token"
+	csrfHeaderName = "X-CSRF-Token"
+)
+
+// csrfExemptPaths 不校验 CSRF 的路径（第三方服务端回调、容器编排钩子、实例间上报，不携带浏览器 Cookie）
+var csrfExemptPaths = map[string]bool{
+	"/api/billing/webhook/stripe":          true,
+	"/api/payment/crypto/webhook/coinbase": true,
+	"/api/lifecycle/prestop":               true,
+	"/api/fleet/report":                    true,
+}
+
+// originPolicy 跨域来源白名单（HTTP 与 WebSocket 共用）
+type originPolicy struct {
+	mu      sync.RWMutex
+	allowed map[string]bool
+}
+
+var corsPolicy = &originPolicy{allowed: make(map[string]bool)}
+
+// setAllowedOrigins 更新跨域来源白名单
+func (p *originPolicy) setAllowedOrigins(origins []string) {
+	allowed := make(map[string]bool, len(origins))
+	for _, o := range origins {
+		if o = normalizeOrigin(o); o != "" {
+			allowed[o] = true
+		}
+	}
+	p.mu.Lock()
+	p.allowed = allowed
+	p.mu.Unlock()
+}
+
+// isAllowed 判断请求来源是否允许：同源请求始终允许，跨域来源需在白名单中
+func (p *originPolicy) isAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// 非浏览器客户端或同源导航请求不带 Origin
+		return true
+	}
+	if isSameOrigin(origin, r.Host) {
+		return true
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.allowed[normalizeOrigin(origin)]
+}
+
+// normalizeOrigin 统一为 scheme://host[:port] 的小写形式
+func normalizeOrigin(origin string) string {
+	origin = strings.TrimSpace(origin)
+	if origin == "" {
+		return ""
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+// isSameOrigin 比较 Origin 与请求 Host（反向代理需保留原始 Host 头）
+func isSameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return strings.EqualFold(u.Host, host)
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// corsMiddleware 严格 CORS 策略
+// 默认仅允许同源访问；web.cors.allowed_origins 中的来源可携带凭证跨域访问，其余跨域请求一律拒绝
+func corsMiddleware(policy *originPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" || isSameOrigin(origin, c.Request.Host) {
+			c.Next()
+			return
+		}
+
+		if !policy.isAllowed(c.Request) {
+			// 浏览器会拦截跨域读取，但写操作仍会到达服务端，必须在此拒绝
+			if c.Request.Method == http.MethodOptions || !isSafeMethod(c.Request.Method) {
+				logger.WriteWebLog(fmt.Sprintf("[CORS] 拒绝跨域请求: Origin=%s, %s %s, IP=%s",
+					origin, c.Request.Method, c.Request.URL.Path, c.ClientIP()))
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "跨域请求被拒绝"})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Set("Access-Control-Allow-Credentials", "true")
+		h.Add("Vary", "Origin")
+
+		if c.Request.Method == http.MethodOptions {
+			h.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			h.Set("Access-Control-Allow-Headers", "Content-Type, Accept-Language, "+csrfHeaderName)
+			h.Set("Access-Control-Max-Age", "600")
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// csrfMiddleware CSRF 防护（双重提交 Cookie）
+// 服务端下发非 HttpOnly 的 csrf_token Cookie，前端在修改状态的请求中通过 X-CSRF-Token 请求头回传，
+// 恶意页面无法读取该 Cookie，因此无法伪造请求头；本地管理 socket 的请求不来自浏览器，不做校验
+func csrfMiddleware(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, err := c.Cookie(csrfCookieName)
+		if err != nil || token == "" {
+			token = generateCSRFToken()
+			http.SetCookie(c.Writer, &http.Cookie{
+				Name:     csrfCookieName,
+				Value:    token,
+				Path:     "/",
+				Secure:   c.Request.TLS != nil,
+				SameSite: http.SameSiteStrictMode,
+			})
+			// 首次请求没有 Cookie，无法校验
+			token = ""
+		}
+
+		if !enabled || isSafeMethod(c.Request.Method) || isLocalAdmin(c.Request) ||
+			!strings.HasPrefix(c.Request.URL.Path, "/api/") || csrfExemptPaths[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		header := c.GetHeader(csrfHeaderName)
+		if token == "" || header == "" || subtle.ConstantTimeCompare([]byte(token), []byte(header)) != 1 {
+			logger.WriteWebLog(fmt.Sprintf("[CSRF] 拒绝请求: %s %s, IP=%s, Origin=%s",
+				c.Request.Method, c.Request.URL.Path, c.ClientIP(), c.GetHeader("Origin")))
+			respondError(c, http.StatusForbidden, "error.csrf_token_invalid")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func generateCSRFToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("生成 CSRF 令牌失败: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// setupSecurityMiddleware 根据配置注册 CORS 与 CSRF 中间件
+func setupSecurityMiddleware(r *gin.Engine, cfg *config.Config) {
+	csrfEnabled := true
+	var origins []string
+	if cfg != nil {
+		origins = cfg.Web.CORS.AllowedOrigins
+		csrfEnabled = !cfg.Web.CSRF.Disabled
+	}
+	corsPolicy.setAllowedOrigins(origins)
+	if len(origins) > 0 {
+		logger.Info("✅ CORS 跨域白名单: %v", origins)
+	}
+	if !csrfEnabled {
+		logger.Warn("⚠️ CSRF 防护已关闭，仅建议在调试环境使用")
+	}
+
+	r.Use(corsMiddleware(corsPolicy), csrfMiddleware(csrfEnabled))
+}
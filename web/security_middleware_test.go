@@ -0,0 +1,99 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newSecurityTestRouter(origins []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	policy := &originPolicy{}
+	policy.setAllowedOrigins(origins)
+
+	r := gin.New()
+	r.Use(corsMiddleware(policy), csrfMiddleware(true))
+	r.GET("/api/status", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/api/start", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/api/billing/webhook/stripe", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func TestCSRFDoubleSubmit(t *testing.T) {
+	r := newSecurityTestRouter(nil)
+
+	// GET 请求下发令牌
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/status", nil))
+	var token string
+	for _, ck := range w.Result().Cookies() {
+		if ck.Name == csrfCookieName {
+			token = ck.Value
+		}
+	}
+	if token == "" {
+		t.Fatalf("csrf cookie not issued")
+	}
+
+	cases := []struct {
+		name   string
+		path   string
+		header string
+		want   int
+	}{
+		{"missing header", "/api/start", "", http.StatusForbidden},
+		{"wrong header", "/api/start", "bad", http.StatusForbidden},
+		{"valid token", "/api/start", token, http.StatusOK},
+		{"exempt webhook", "/api/billing/webhook/stripe", "", http.StatusOK},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodPost, tc.path, nil)
+		req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: token})
+		if tc.header != "" {
+			req.Header.Set(csrfHeaderName, tc.header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.name, w.Code, tc.want)
+		}
+	}
+}
+
+func TestCORSAllowList(t *testing.T) {
+	r := newSecurityTestRouter([]string{"https://dash.example.com"})
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/api/start", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := preflight("https://dash.example.com")
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://dash.example.com" {
+		t.Fatalf("allowed origin preflight: code=%d headers=%v", w.Code, w.Header())
+	}
+	if w := preflight("https://evil.example.com"); w.Code != http.StatusForbidden {
+		t.Fatalf("disallowed origin preflight: got %d", w.Code)
+	}
+
+	// 未在白名单中的来源直接发起写请求
+	req := httptest.NewRequest(http.MethodPost, "/api/start", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("cross-origin post: got %d", w.Code)
+	}
+
+	// 同源请求不受白名单限制
+	req = httptest.NewRequest(http.MethodGet, "http://bot.local/api/status", nil)
+	req.Header.Set("Origin", "http://bot.local")
+	if !(&originPolicy{}).isAllowed(req) {
+		t.Fatalf("same-origin request should be allowed")
+	}
+}
@@ -43,20 +43,27 @@ func SetupRoutes(r *gin.Engine) {
 	SetupRoutesWithConfig(r, nil)
 }
 
-// SetupRoutesWithConfig 设置路由（带配置）
+// SetupRoutesWithConfig 设置路由（带配置），处理器使用 SetXxxProvider 写入的默认提供者
 func SetupRoutesWithConfig(r *gin.Engine, cfg *config.Config) {
+	SetupRoutesWithProviders(r, cfg, defaultProviders)
+}
+
+// SetupRoutesWithProviders 设置路由，处理器从 providers 读取依赖（nil 时使用默认提供者）
+func SetupRoutesWithProviders(r *gin.Engine, cfg *config.Config, providers *Providers) {
 	globalConfig = cfg
+	if cfg != nil {
+		setBasePath(cfg.Web.BasePath)
+	}
+	if providers == nil {
+		providers = defaultProviders
+	}
+	// 绑定提供者集合（必须在注册路由之前）
+	r.Use(providersMiddleware(providers))
+	// CORS 与 CSRF 防护（必须在注册路由之前）
+	setupSecurityMiddleware(r, cfg)
+
 	// 首先处理根路径，返回 index.html（必须在其他路由之前）
-	r.GET("/", func(c *gin.Context) {
-		index, err := staticFiles.ReadFile("dist/index.html")
-		if err != nil {
-			// 如果找不到文件，返回404
-			c.Status(http.StatusNotFound)
-			c.String(http.StatusNotFound, "Frontend not found. Please rebuild the project.")
-			return
-		}
-		c.Data(http.StatusOK, "text/html; charset=utf-8", index)
-	})
+	r.GET("/", serveIndex)
 
 	// Prometheus metrics 端点（不需要认证，供 Prometheus 抓取）
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
@@ -114,19 +121,47 @@ func SetupRoutesWithConfig(r *gin.Engine, cfg *config.Config) {
 			auth.POST("/password/set", setPassword)
 			auth.POST("/password/verify", verifyPassword)
 			auth.POST("/logout", logout)
+			auth.POST("/refresh", refreshSession)
 		}
 
-		// 配置引导路由（不需要认证，在配置完成前使用）
+		// 配置引导路由（不需要认证，在配置完成前使用；会写入配置或使用密钥下单的接口在配置完成后关闭）
 		setup := api.Group("/setup")
 		{
 			setup.GET("/status", getSetupStatusHandler)
 			setup.POST("/init", initSetupHandler)
 			setup.POST("/exchange-symbols", getExchangeSymbolsHandler)
+			setup.POST("/validate-keys", setupPendingOnly, validateSetupKeysHandler)
+			setup.POST("/test-order", setupPendingOnly, testSetupOrderHandler)
+			setup.POST("/generate-config", setupPendingOnly, generateSetupConfigHandler)
+			setup.GET("/generate-config/:task_id", setupPendingOnly, getSetupTaskStatus)
+			setup.POST("/apply-config", setupPendingOnly, applySetupConfigHandler)
 		}
 
 		// 版本号API（不需要认证）
 		api.GET("/version", getVersion)
 
+		// 容器生命周期（不需要认证）：存活/就绪检查和 PreStop 钩子
+		api.GET("/health", getHealth)
+		api.GET("/ready", getReady)
+		api.POST("/lifecycle/prestop", preStopHook)
+
+		// 公开状态页（不需要认证，默认关闭，带 IP 限流）
+		if cfg != nil && cfg.Web.PublicStatus.Enabled {
+			publicStatus := newPublicStatusService(cfg)
+			limit := publicStatus.rateLimitMiddleware()
+			api.GET("/public/status", limit, publicStatus.getPublicStatus)
+			r.GET("/public/status", limit, publicStatus.getPublicStatusPage)
+			logger.Info("✅ 公开状态页已启用: /public/status (指标: %v)", cfg.Web.PublicStatus.Metrics)
+		}
+
+		// 多实例总览（hub）：成员使用实例间令牌上报，读取接受令牌或登录会话
+		if cfg != nil && cfg.Fleet.Role == "hub" {
+			fleet := newFleetHub(cfg.Fleet)
+			api.POST("/fleet/report", fleet.tokenMiddleware(), fleet.receiveReport)
+			api.GET("/fleet", fleet.readMiddleware(), fleet.getFleet)
+			logger.Info("✅ 实例总览 hub 已启用: /api/fleet")
+		}
+
 		// 需要认证的认证路由
 		authProtected := api.Group("/auth")
 		authProtected.Use(authMiddleware())
@@ -134,6 +169,15 @@ func SetupRoutesWithConfig(r *gin.Engine, cfg *config.Config) {
 			authProtected.POST("/password/change", changePassword)
 		}
 
+		// 会话管理API
+		sessions := api.Group("/sessions")
+		sessions.Use(authMiddleware())
+		{
+			sessions.GET("", listSessions)
+			sessions.DELETE("/:id", revokeSessionHandler)
+			sessions.POST("/revoke-all", revokeAllSessions)
+		}
+
 		// WebAuthn API（部分需要认证，部分不需要）
 		webauthn := api.Group("/webauthn")
 		{
@@ -148,22 +192,47 @@ func SetupRoutesWithConfig(r *gin.Engine, cfg *config.Config) {
 		// 需要认证的业务API
 		protected := api.Group("")
 		protected.Use(authMiddleware())
+		// 高开销接口限流与响应缓存（在认证之后，缓存按用户区分）
+		protected.Use(endpointLimitMiddleware(cfg))
 		{
 			protected.GET("/status", getStatus)
 			protected.GET("/symbols", getSymbols)
+			protected.GET("/symbols/metadata", getSymbolMetadata)
+			protected.GET("/symbols/migrations", getSymbolMigrations)
+			protected.POST("/symbols/migrate", migrateSymbol)
+			protected.GET("/symbols/anchor", getGridAnchor)
+			protected.PUT("/symbols/anchor", setGridAnchor)
+			protected.GET("/grid/recenter", previewGridRecenter)
+			protected.POST("/grid/recenter", recenterGrid)
+			protected.GET("/analysis/levels", getAnalysisLevels)
+			protected.GET("/analytics/correlation", getAnalyticsCorrelation)
+			protected.GET("/analytics/attribution", getAnalyticsAttribution)
+			protected.GET("/orders/expiry", getOrderExpiryStats)
+			protected.GET("/safety/cleaner/history", getOrderCleanupHistory)
+			protected.POST("/safety/cleaner/run", runOrderCleanup)
+			protected.GET("/standby/status", getStandbyStatus)
 			protected.GET("/exchanges", getExchanges)
+			protected.GET("/exchanges/health", getExchangesHealth)
 			protected.GET("/positions", getPositions)
 			protected.GET("/positions/summary", getPositionsSummary)
+			protected.POST("/positions/slots/adjust", adjustSlot)
+			protected.POST("/slots/:price/action", slotAction)
 			protected.GET("/orders", getOrders)
 			protected.GET("/orders/history", getOrderHistory)
+			protected.GET("/orders/rejected", getRejectedOrders)
+			protected.GET("/income/reconciliation", getIncomeReconciliation)
 			protected.GET("/statistics", getStatistics)
 			protected.GET("/statistics/daily", getDailyStatistics)
 			protected.GET("/statistics/trades", getTradeStatistics)
+			protected.GET("/statistics/funding-payments", getFundingPayments)
 			protected.GET("/statistics/pnl/symbol", getPnLBySymbol)
 			protected.GET("/statistics/pnl/time-range", getPnLByTimeRange)
 			protected.GET("/statistics/pnl/exchange", getPnLByExchange)
 			protected.GET("/statistics/anomalous-trades", getAnomalousTrades)
+			protected.GET("/statistics/execution-anomalies", getExecutionAnomalies)
+			protected.GET("/statistics/benchmark", getStatisticsBenchmark)
 			protected.GET("/reconciliation/status", getReconciliationStatus)
+			protected.POST("/reconciliation/run", runReconciliation)
 
 			// 资金分配管理 API
 			protected.GET("/allocation/status", getAllocationStatus)
@@ -219,9 +288,18 @@ func SetupRoutesWithConfig(r *gin.Engine, cfg *config.Config) {
 			protected.GET("/reconciliation/history", getReconciliationHistory)
 			protected.GET("/risk/status", getRiskStatus)
 			protected.GET("/risk/monitor", getRiskMonitorData)
+			protected.GET("/risk/stress", getRiskStress)
+			protected.GET("/risk/margin", getRiskMargin)
+			protected.GET("/risk/hedge", getRiskHedge)
 			protected.GET("/risk/history", getRiskCheckHistory)
 			protected.GET("/risk/newbie-check", getNewbieRiskCheck)
 			protected.POST("/risk/newbie-check/apply", applyNewbieSecurityConfig)
+			protected.GET("/risk/profiles", getRiskProfiles)
+			protected.POST("/risk/profiles/switch", switchRiskProfile)
+
+			// 工具API
+			protected.POST("/tools/grid-preview", previewGridHandler)
+			protected.POST("/tools/symbol-score", scoreSymbolHandler)
 
 			// 配置管理API
 			protected.GET("/config", getConfigHandler)
@@ -241,6 +319,13 @@ func SetupRoutesWithConfig(r *gin.Engine, cfg *config.Config) {
 			protected.GET("/system/metrics", getSystemMetrics)
 			protected.GET("/system/metrics/current", getCurrentSystemMetrics)
 			protected.GET("/system/metrics/daily", getDailySystemMetrics)
+			protected.GET("/system/logging", getLoggingSettings)
+			protected.PUT("/system/logging", updateLoggingSettings)
+			protected.POST("/system/restart", restartSystem)
+
+			// 通知模板预览与测试
+			protected.POST("/notifications/preview", previewNotification)
+			protected.POST("/notifications/test", testNotification)
 
 			// 日志API
 			protected.GET("/logs", getLogs)
@@ -253,15 +338,28 @@ func SetupRoutesWithConfig(r *gin.Engine, cfg *config.Config) {
 
 			// 策略资金分配API
 			protected.GET("/strategies/allocation", getStrategyAllocation)
+			protected.GET("/strategy-breaker", getStrategyBreakerStatus)
+			protected.POST("/strategy-breaker/resume", resumeStrategyBreaker)
+			// OCO 止盈/止损关联订单
+			protected.GET("/oco", getOCOLinks)
+			protected.POST("/oco", placeOCO)
+			protected.DELETE("/oco/:link_id", cancelOCO)
 
 			// 待成交订单API
 			protected.GET("/orders/pending", getPendingOrders)
 
 			// K线数据API
 			protected.GET("/klines", getKlines)
+			protected.GET("/klines/gaps", getKlineGaps)
+			protected.POST("/klines/backfill", backfillKlines)
+
+			// 数据库快照导出（仅管理员）
+			protected.GET("/export/database", adminOnlyMiddleware(), exportDatabase)
 
 			// 资金费率API
 			protected.GET("/funding/current", getFundingRate)
+			protected.GET("/funding/symbols", getFundingSymbols)
+			protected.PUT("/funding/symbols", updateFundingSymbols)
 
 			// AI分析API
 			protected.GET("/ai/status", getAIAnalysisStatus)
@@ -271,13 +369,23 @@ func SetupRoutesWithConfig(r *gin.Engine, cfg *config.Config) {
 			protected.GET("/ai/analysis/sentiment", getAISentimentAnalysis)
 			protected.GET("/ai/analysis/polymarket", getAIPolymarketSignal)
 			protected.POST("/ai/analysis/trigger/:module", triggerAIAnalysis)
+			protected.GET("/ai/modules", getAIModules)
+			protected.PUT("/ai/modules/:module", updateAIModule)
 			protected.GET("/ai/prompts", getAIPrompts)
 			protected.POST("/ai/prompts", updateAIPrompt)
+			protected.GET("/ai/prompts/:module/versions", getAIPromptVersions)
+			protected.GET("/ai/prompts/:module/diff", diffAIPromptVersions)
+			protected.POST("/ai/prompts/:module/rollback", rollbackAIPrompt)
+			protected.PUT("/ai/prompts/:module/pin", pinAIPrompt)
 
 			// AI 配置助手 API
 			protected.POST("/ai/generate-config", generateAIConfig)
 			protected.GET("/ai/task/:task_id", getAITaskStatus)
 			protected.POST("/ai/apply-config", applyAIConfig)
+			protected.GET("/ai/proposals", listAIProposals)
+			protected.GET("/ai/proposals/:id", getAIProposal)
+			protected.POST("/ai/proposals/:id/approve", approveAIProposal)
+			protected.POST("/ai/proposals/:id/reject", rejectAIProposal)
 
 			protected.GET("/funding/history", getFundingRateHistory)
 
@@ -285,6 +393,16 @@ func SetupRoutesWithConfig(r *gin.Engine, cfg *config.Config) {
 			protected.GET("/basis/current", getBasisCurrent)
 			protected.GET("/basis/history", getBasisHistory)
 			protected.GET("/basis/statistics", getBasisStatistics)
+			protected.GET("/open-interest/current", getOpenInterestCurrent)
+			protected.GET("/open-interest/history", getOpenInterestHistory)
+
+			// 交易日志
+			protected.GET("/journal", getJournalEntries)
+			protected.POST("/journal", addJournalNote)
+
+			// 盈利目标
+			protected.GET("/goals", getGoalProgress)
+			protected.GET("/scheduler/jobs", getSchedulerJobs)
 
 			// 市场情报API
 			protected.GET("/market-intelligence", getMarketIntelligence)
@@ -339,8 +457,10 @@ func SetupRoutesWithConfig(r *gin.Engine, cfg *config.Config) {
 				capital.PUT("/allocation/:id", updateStrategyCapitalHandler)
 				capital.POST("/allocation/:id/lock", lockStrategyCapitalHandler)
 				capital.POST("/rebalance", rebalanceCapitalHandler)
+				capital.GET("/rebalance/recommendation", getRebalanceRecommendation)
 				capital.GET("/history", getCapitalHistoryHandler)
 				capital.PUT("/reserve", setReserveCapitalHandler)
+				capital.GET("/vault/transfers", getProfitVaultTransfers)
 			}
 		}
 
@@ -357,16 +477,17 @@ func SetupRoutesWithConfig(r *gin.Engine, cfg *config.Config) {
 
 	// 静态资源文件（CSS、JS、图片等）
 	// 注意：Vite 构建后的资源在 dist/assets 目录下
+	// 文件名带内容哈希，可以永久缓存
 	assetsFS := GetAssetsFS()
 	if assetsFS != nil {
 		// 使用文件系统提供 /assets 路径下的文件
-		r.StaticFS("/assets", assetsFS)
+		r.Group("/assets", cacheControl(cacheControlImmutable)).StaticFS("/", assetsFS)
 	}
 
 	// 图标目录
 	iconsFS := GetIconsFS()
 	if iconsFS != nil {
-		r.StaticFS("/icons", iconsFS)
+		r.Group("/icons", cacheControl(cacheControlIcons)).StaticFS("/", iconsFS)
 	}
 
 	// PWA 相关静态文件（Service Worker、Manifest 等）
@@ -380,17 +501,13 @@ func SetupRoutesWithConfig(r *gin.Engine, cfg *config.Config) {
 	for urlPath, filePath := range pwaFiles {
 		fp := filePath // 捕获变量
 		r.GET(urlPath, func(c *gin.Context) {
-			data, err := staticFiles.ReadFile(fp)
-			if err != nil {
-				c.Status(http.StatusNotFound)
-				return
-			}
 			// 根据文件类型设置正确的 Content-Type
 			contentType := "application/javascript"
 			if strings.HasSuffix(fp, ".json") || strings.HasSuffix(fp, ".webmanifest") {
 				contentType = "application/json"
 			}
-			c.Data(http.StatusOK, contentType, data)
+			// Service Worker 决定前端何时更新，必须每次校验
+			serveEmbedded(c, fp, contentType, cacheControlRevalidate)
 		})
 	}
 
@@ -410,22 +527,12 @@ func SetupRoutesWithConfig(r *gin.Engine, cfg *config.Config) {
 
 		// 处理 workbox 文件（如 /workbox-3ade98c4.js）
 		if strings.HasPrefix(path, "/workbox-") && strings.HasSuffix(path, ".js") {
-			filePath := "dist" + path
-			data, err := staticFiles.ReadFile(filePath)
-			if err != nil {
-				c.Status(http.StatusNotFound)
-				return
-			}
-			c.Data(http.StatusOK, "application/javascript", data)
+			// workbox 文件名带哈希
+			serveEmbedded(c, "dist"+path, "application/javascript", cacheControlImmutable)
 			return
 		}
 
 		// 其他路径都返回 index.html（SPA 路由）
-		index, err := staticFiles.ReadFile("dist/index.html")
-		if err != nil {
-			c.Status(http.StatusNotFound)
-			return
-		}
-		c.Data(http.StatusOK, "text/html; charset=utf-8", index)
+		serveIndex(c)
 	})
 }
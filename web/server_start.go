@@ -13,13 +13,20 @@ import (
 
 // WebServer Web服务器
 type WebServer struct {
-	server *http.Server
-	cfg    *config.Config
+	server      *http.Server
+	adminServer *http.Server // 本地管理 socket（未启用时为 nil）
+	cfg         *config.Config
+	providers   *Providers
 }
 
-// NewWebServer 创建Web服务器
+// NewWebServer 创建Web服务器，使用 SetXxxProvider 写入的默认提供者
 func NewWebServer(cfg *config.Config) *WebServer {
-	if !cfg.Web.Enabled {
+	return NewServer(cfg, defaultProviders)
+}
+
+// NewServer 创建使用指定提供者集合的Web服务器（providers 为 nil 时使用默认提供者）
+func NewServer(cfg *config.Config, providers *Providers) *WebServer {
+	if !cfg.Web.Enabled && !cfg.AdminSocket.Enabled {
 		return nil
 	}
 
@@ -37,6 +44,15 @@ func NewWebServer(cfg *config.Config) *WebServer {
 
 	// 使用 gin.New() 代替 gin.Default()，手动添加中间件
 	r := gin.New()
+
+	// 只信任来自可信代理的 X-Forwarded-For，审计日志、登录锁定和限流使用的客户端 IP 才不会被伪造
+	if err := r.SetTrustedProxies(cfg.Web.TrustedProxies); err != nil {
+		logger.Warn("⚠️ 可信代理配置无效: %v，将不信任任何代理", err)
+		r.SetTrustedProxies(nil)
+	}
+	if len(cfg.Web.RemoteIPHeaders) > 0 {
+		r.RemoteIPHeaders = cfg.Web.RemoteIPHeaders
+	}
 	
 	// 添加 Recovery 中间件（panic 恢复）
 	r.Use(gin.Recovery())
@@ -48,24 +64,39 @@ func NewWebServer(cfg *config.Config) *WebServer {
 	// 添加 i18n 中间件
 	r.Use(I18nMiddleware())
 
+	if providers == nil {
+		providers = defaultProviders
+	}
+
 	// 设置路由（传入配置以便 pprof 可以读取配置）
-	SetupRoutesWithConfig(r, cfg)
+	SetupRoutesWithProviders(r, cfg, providers)
 
 	// 配置服务器
 	// 注意：AI 生成配置等长时间操作需要较长的超时时间
 	addr := fmt.Sprintf("%s:%d", cfg.Web.Host, cfg.Web.Port)
 	server := &http.Server{
 		Addr:         addr,
-		Handler:      r,
+		Handler:      basePathHandler(cfg.Web.BasePath, r),
 		ReadTimeout:  120 * time.Second, // 2 分钟读取超时
 		WriteTimeout: 180 * time.Second, // 3 分钟写入超时（AI 请求可能需要较长时间）
 		IdleTimeout:  120 * time.Second,
 	}
 
-	return &WebServer{
-		server: server,
-		cfg:    cfg,
+	ws := &WebServer{
+		server:    server,
+		cfg:       cfg,
+		providers: providers,
+	}
+	if cfg.AdminSocket.Enabled {
+		// 管理 socket 不经过反向代理，直接使用 /api/... 路径
+		ws.adminServer = &http.Server{
+			Handler:      localAdminHandler(r),
+			ReadTimeout:  server.ReadTimeout,
+			WriteTimeout: server.WriteTimeout,
+			IdleTimeout:  server.IdleTimeout,
+		}
 	}
+	return ws
 }
 
 // Start 启动Web服务器
@@ -74,25 +105,35 @@ func (ws *WebServer) Start(ctx context.Context) error {
 		return nil
 	}
 
-	go func() {
-		logger.Info("🌐 Web服务器正在启动，监听地址: http://%s:%d", ws.cfg.Web.Host, ws.cfg.Web.Port)
-		if err := ws.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error("❌ Web服务器启动失败: %v", err)
+	if ws.adminServer != nil {
+		ln, err := listenAdminSocket(ws.cfg.AdminSocket.Path)
+		if err != nil {
+			return fmt.Errorf("启动管理 socket 失败: %w", err)
 		}
-	}()
-	// 给 goroutine 一点时间启动，确保日志能输出
-	time.Sleep(100 * time.Millisecond)
+		logger.Info("🔌 管理 socket 已启动: %s", ws.cfg.AdminSocket.Path)
+		go func() {
+			if err := ws.adminServer.Serve(ln); err != nil && err != http.ErrServerClosed {
+				logger.Error("❌ 管理 socket 服务异常退出: %v", err)
+			}
+		}()
+	}
+
+	if ws.cfg.Web.Enabled {
+		go func() {
+			logger.Info("🌐 Web服务器正在启动，监听地址: http://%s:%d%s/", ws.cfg.Web.Host, ws.cfg.Web.Port, ws.cfg.Web.BasePath)
+			if err := ws.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("❌ Web服务器启动失败: %v", err)
+			}
+		}()
+		// 给 goroutine 一点时间启动，确保日志能输出
+		time.Sleep(100 * time.Millisecond)
+	}
 
 	// 等待context取消
 	go func() {
 		<-ctx.Done()
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		if err := ws.server.Shutdown(shutdownCtx); err != nil {
-			logger.Error("❌ Web服务器关闭失败: %v", err)
-		} else {
-			logger.Info("✅ Web服务器已关闭")
-		}
+		ws.Stop()
+		logger.Info("✅ Web服务器已关闭")
 	}()
 
 	return nil
@@ -110,4 +151,17 @@ func (ws *WebServer) Stop() {
 	if err := ws.server.Shutdown(ctx); err != nil {
 		logger.Error("❌ Web服务器关闭失败: %v", err)
 	}
+	if ws.adminServer != nil {
+		if err := ws.adminServer.Shutdown(ctx); err != nil {
+			logger.Error("❌ 管理 socket 关闭失败: %v", err)
+		}
+	}
+}
+
+// Providers 返回该服务器使用的提供者集合
+func (ws *WebServer) Providers() *Providers {
+	if ws == nil {
+		return nil
+	}
+	return ws.providers
 }
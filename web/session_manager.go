@@ -2,12 +2,15 @@ package web
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -15,38 +18,89 @@ import (
 	"quantmesh/logger"
 )
 
+const (
+	// defaultSessionTimeout 会话空闲超时，每次请求滑动续期
+	defaultSessionTimeout = 24 * time.Hour
+	// defaultSessionMaxLifetime 会话绝对有效期，滑动续期不超过该时长
+	defaultSessionMaxLifetime = 7 * 24 * time.Hour
+	// defaultRefreshTokenTTL 刷新令牌有效期
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+	// defaultMaxSessionsPerUser 每个用户的并发会话上限，超出时淘汰最久未活动的会话
+	defaultMaxSessionsPerUser = 5
+	// sessionPersistInterval 滑动续期写库的最小间隔，避免每个请求都写数据库
+	sessionPersistInterval = 5 * time.Minute
+
+	refreshTokenCookieName = "refresh_token"
+	refreshTokenCookiePath = "/api/auth/refresh"
+)
+
 // Session 会话信息
 type Session struct {
-	SessionID string
-	Username  string
-	Role      string
-	IP        string
-	UserAgent string
-	CreatedAt time.Time
-	ExpiresAt time.Time
+	SessionID        string
+	Username         string
+	Role             string
+	IP               string
+	UserAgent        string
+	CreatedAt        time.Time
+	ExpiresAt        time.Time
+	LastSeenAt       time.Time
+	RefreshTokenHash string
+	RefreshExpiresAt time.Time
+
+	// RefreshToken 刷新令牌明文，仅在创建会话时返回用于写入 Cookie，不持久化
+	RefreshToken string
+
+	persistedAt time.Time
+}
+
+// revokedToken 已吊销的刷新令牌
+type revokedToken struct {
+	username  string
+	expiresAt time.Time // 令牌本身的过期时间，之后重放也无法换取会话，无需再保留
+}
+
+// PublicID 会话的对外标识（会话 ID 的摘要），用于会话列表和远程注销，避免泄露会话 ID
+func (s *Session) PublicID() string {
+	return hashToken(s.SessionID)[:16]
 }
 
 // SessionManager 会话管理器
 type SessionManager struct {
 	sessions map[string]*Session
 	mu       sync.RWMutex
-	// 会话过期时间（默认24小时）
+	// 会话空闲超时（默认24小时，请求时滑动续期）
 	sessionTimeout time.Duration
+	// 会话绝对有效期
+	maxLifetime time.Duration
+	// 刷新令牌有效期
+	refreshTTL time.Duration
+	// 每个用户的并发会话上限（0 表示不限制）
+	maxSessionsPerUser int
+	// 已吊销的刷新令牌（摘要 -> 所属用户），用于识别令牌被盗后的重放；令牌过期后清理
+	revokedRefresh map[string]revokedToken
 	// 数据库连接（用于持久化会话）
 	db *sql.DB
 }
 
+// newMemorySessionManager 创建不持久化的会话管理器
+func newMemorySessionManager() *SessionManager {
+	return &SessionManager{
+		sessions:           make(map[string]*Session),
+		sessionTimeout:     defaultSessionTimeout,
+		maxLifetime:        defaultSessionMaxLifetime,
+		refreshTTL:         defaultRefreshTokenTTL,
+		maxSessionsPerUser: defaultMaxSessionsPerUser,
+		revokedRefresh:     make(map[string]revokedToken),
+	}
+}
+
 // NewSessionManager 创建会话管理器
 func NewSessionManager() *SessionManager {
 	dataDir := "./data"
 	// 确保数据目录存在
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		logger.Warn("⚠️ 创建数据目录失败: %v，会话将不会持久化", err)
-		return &SessionManager{
-			sessions:       make(map[string]*Session),
-			sessionTimeout: 24 * time.Hour,
-			db:             nil,
-		}
+		return newMemorySessionManager()
 	}
 
 	// 使用与 PasswordManager 相同的数据库文件
@@ -55,22 +109,15 @@ func NewSessionManager() *SessionManager {
 	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		logger.Warn("⚠️ 打开会话数据库失败: %v，会话将不会持久化", err)
-		return &SessionManager{
-			sessions:       make(map[string]*Session),
-			sessionTimeout: 24 * time.Hour,
-			db:             nil,
-		}
+		return newMemorySessionManager()
 	}
 
 	// 配置连接池
 	db.SetMaxOpenConns(10)
 	db.SetMaxIdleConns(5)
 
-	sm := &SessionManager{
-		sessions:       make(map[string]*Session),
-		sessionTimeout: 24 * time.Hour,
-		db:             db,
-	}
+	sm := newMemorySessionManager()
+	sm.db = db
 
 	// 初始化数据库表
 	if err := sm.initDatabase(); err != nil {
@@ -78,12 +125,15 @@ func NewSessionManager() *SessionManager {
 		db.Close()
 		sm.db = nil
 	} else {
-		// 从数据库加载有效的会话
+		// 从数据库加载有效的会话和吊销列表
 		if err := sm.loadSessionsFromDB(); err != nil {
 			logger.Warn("⚠️ 从数据库加载会话失败: %v", err)
 		} else {
 			logger.Info("✅ 会话管理器已初始化，已从数据库加载有效会话")
 		}
+		if err := sm.loadRevocationsFromDB(); err != nil {
+			logger.Warn("⚠️ 从数据库加载会话吊销列表失败: %v", err)
+		}
 	}
 
 	// 启动清理过期会话的协程
@@ -123,10 +173,75 @@ func (sm *SessionManager) initDatabase() error {
 		return fmt.Errorf("创建索引失败: %v", err)
 	}
 
+	// 旧版本的会话表没有活动时间和刷新令牌字段
+	for _, column := range []struct{ name, ddl string }{
+		{"last_seen_at", "ALTER TABLE sessions ADD COLUMN last_seen_at DATETIME"},
+		{"refresh_token_hash", "ALTER TABLE sessions ADD COLUMN refresh_token_hash TEXT"},
+		{"refresh_expires_at", "ALTER TABLE sessions ADD COLUMN refresh_expires_at DATETIME"},
+	} {
+		var count int
+		if err := sm.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('sessions') WHERE name = ?`, column.name).Scan(&count); err != nil {
+			return fmt.Errorf("检查会话表字段失败: %v", err)
+		}
+		if count == 0 {
+			if _, err := sm.db.Exec(column.ddl); err != nil {
+				return fmt.Errorf("添加会话表字段 %s 失败: %v", column.name, err)
+			}
+		}
+	}
+
+	revocationSQL := `
+	CREATE TABLE IF NOT EXISTS session_revocations (
+		token_hash TEXT PRIMARY KEY,
+		username TEXT NOT NULL,
+		reason TEXT,
+		revoked_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL
+	);
+	`
+	if _, err := sm.db.Exec(revocationSQL); err != nil {
+		return fmt.Errorf("创建会话吊销表失败: %v", err)
+	}
+
 	return nil
 }
 
-// loadSessionsFromDB 从数据库加载有效的会话
+// sessionColumns 会话表查询字段，与 scanSession 对应
+const sessionColumns = `session_id, username, role, ip, user_agent, created_at, expires_at, last_seen_at, refresh_token_hash, refresh_expires_at`
+
+// scanSession 扫描一行会话记录
+func scanSession(scan func(dest ...interface{}) error) (*Session, error) {
+	var session Session
+	var lastSeenAt, refreshExpiresAt sql.NullTime
+	var refreshHash sql.NullString
+	err := scan(
+		&session.SessionID,
+		&session.Username,
+		&session.Role,
+		&session.IP,
+		&session.UserAgent,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+		&lastSeenAt,
+		&refreshHash,
+		&refreshExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	session.LastSeenAt = session.CreatedAt
+	if lastSeenAt.Valid {
+		session.LastSeenAt = lastSeenAt.Time
+	}
+	session.RefreshTokenHash = refreshHash.String
+	if refreshExpiresAt.Valid {
+		session.RefreshExpiresAt = refreshExpiresAt.Time
+	}
+	session.persistedAt = time.Now()
+	return &session, nil
+}
+
+// loadSessionsFromDB 从数据库加载有效的会话（含会话已过期但刷新令牌仍有效的记录）
 func (sm *SessionManager) loadSessionsFromDB() error {
 	if sm.db == nil {
 		return nil // 数据库未初始化，跳过加载
@@ -134,10 +249,10 @@ func (sm *SessionManager) loadSessionsFromDB() error {
 
 	now := time.Now()
 	rows, err := sm.db.Query(`
-		SELECT session_id, username, role, ip, user_agent, created_at, expires_at 
+		SELECT `+sessionColumns+`
 		FROM sessions 
-		WHERE expires_at > ?
-	`, now)
+		WHERE expires_at > ? OR refresh_expires_at > ?
+	`, now, now)
 	if err != nil {
 		return fmt.Errorf("查询会话失败: %v", err)
 	}
@@ -148,26 +263,13 @@ func (sm *SessionManager) loadSessionsFromDB() error {
 
 	loadedCount := 0
 	for rows.Next() {
-		var session Session
-		err := rows.Scan(
-			&session.SessionID,
-			&session.Username,
-			&session.Role,
-			&session.IP,
-			&session.UserAgent,
-			&session.CreatedAt,
-			&session.ExpiresAt,
-		)
+		session, err := scanSession(rows.Scan)
 		if err != nil {
 			logger.Warn("⚠️ 加载会话失败: %v", err)
 			continue
 		}
-
-		// 再次检查是否过期（防止时间差问题）
-		if now.Before(session.ExpiresAt) {
-			sm.sessions[session.SessionID] = &session
-			loadedCount++
-		}
+		sm.sessions[session.SessionID] = session
+		loadedCount++
 	}
 
 	if loadedCount > 0 {
@@ -177,21 +279,40 @@ func (sm *SessionManager) loadSessionsFromDB() error {
 	return rows.Err()
 }
 
+// loadRevocationsFromDB 从数据库加载未过期的刷新令牌吊销记录
+func (sm *SessionManager) loadRevocationsFromDB() error {
+	if sm.db == nil {
+		return nil
+	}
+
+	rows, err := sm.db.Query(`SELECT token_hash, username, expires_at FROM session_revocations WHERE expires_at > ?`, time.Now())
+	if err != nil {
+		return fmt.Errorf("查询会话吊销列表失败: %v", err)
+	}
+	defer rows.Close()
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	for rows.Next() {
+		var hash, username string
+		var expiresAt time.Time
+		if err := rows.Scan(&hash, &username, &expiresAt); err != nil {
+			return err
+		}
+		sm.revokedRefresh[hash] = revokedToken{username: username, expiresAt: expiresAt}
+	}
+	return rows.Err()
+}
+
 // cleanupExpiredSessions 清理过期会话
 func (sm *SessionManager) cleanupExpiredSessions() {
 	ticker := time.NewTicker(1 * time.Hour)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		sm.mu.Lock()
 		now := time.Now()
-		var expiredSessionIDs []string
-		for sessionID, session := range sm.sessions {
-			if now.After(session.ExpiresAt) {
-				expiredSessionIDs = append(expiredSessionIDs, sessionID)
-				delete(sm.sessions, sessionID)
-			}
-		}
+		sm.mu.Lock()
+		expiredSessionIDs := sm.pruneLocked(now)
 		sm.mu.Unlock()
 
 		// 从数据库删除过期会话
@@ -201,14 +322,43 @@ func (sm *SessionManager) cleanupExpiredSessions() {
 
 		// 同时清理数据库中所有过期的会话（防止遗漏）
 		if sm.db != nil {
-			_, err := sm.db.Exec("DELETE FROM sessions WHERE expires_at <= ?", now)
+			_, err := sm.db.Exec(`
+				DELETE FROM sessions 
+				WHERE expires_at <= ? AND (refresh_expires_at IS NULL OR refresh_expires_at <= ?)
+			`, now, now)
 			if err != nil {
 				logger.Warn("⚠️ 清理数据库过期会话失败: %v", err)
 			}
+			// 吊销记录在对应刷新令牌过期后即无意义
+			if _, err := sm.db.Exec("DELETE FROM session_revocations WHERE expires_at <= ?", now); err != nil {
+				logger.Warn("⚠️ 清理过期会话吊销记录失败: %v", err)
+			}
 		}
 	}
 }
 
+// pruneLocked 移除已失效的会话和已过期的吊销记录（调用方需持有写锁），返回被移除的会话 ID
+func (sm *SessionManager) pruneLocked(now time.Time) []string {
+	var expiredSessionIDs []string
+	for sessionID, session := range sm.sessions {
+		if sm.isDead(session, now) {
+			expiredSessionIDs = append(expiredSessionIDs, sessionID)
+			delete(sm.sessions, sessionID)
+		}
+	}
+	for hash, revoked := range sm.revokedRefresh {
+		if !now.Before(revoked.expiresAt) {
+			delete(sm.revokedRefresh, hash)
+		}
+	}
+	return expiredSessionIDs
+}
+
+// isDead 会话和刷新令牌均已过期（调用方需持有锁）
+func (sm *SessionManager) isDead(session *Session, now time.Time) bool {
+	return !now.Before(session.ExpiresAt) && !now.Before(session.RefreshExpiresAt)
+}
+
 // generateSessionID 生成会话ID
 func (sm *SessionManager) generateSessionID() (string, error) {
 	b := make([]byte, 32)
@@ -220,26 +370,62 @@ func (sm *SessionManager) generateSessionID() (string, error) {
 }
 
 // CreateSession 创建会话
+// 同时签发刷新令牌（明文仅通过返回值的 RefreshToken 字段传出），超出并发会话上限时淘汰最久未活动的会话
 func (sm *SessionManager) CreateSession(username, role, ip, userAgent string) (*Session, error) {
+	now := time.Now()
+	session, err := sm.newSession(username, role, ip, userAgent, now)
+	if err != nil {
+		return nil, err
+	}
+
+	sm.mu.Lock()
+	evicted := sm.addSessionLocked(session, now)
+	sm.mu.Unlock()
+
+	sm.persistCreated(session, evicted)
+	return session, nil
+}
+
+// newSession 生成会话 ID 和刷新令牌（尚未加入会话表）
+func (sm *SessionManager) newSession(username, role, ip, userAgent string, now time.Time) (*Session, error) {
 	sessionID, err := sm.generateSessionID()
 	if err != nil {
 		return nil, fmt.Errorf("生成会话ID失败: %v", err)
 	}
-
-	now := time.Now()
-	session := &Session{
-		SessionID: sessionID,
-		Username:  username,
-		Role:      role,
-		IP:        ip,
-		UserAgent: userAgent,
-		CreatedAt: now,
-		ExpiresAt: now.Add(sm.sessionTimeout),
+	refreshToken, err := sm.generateSessionID()
+	if err != nil {
+		return nil, fmt.Errorf("生成刷新令牌失败: %v", err)
 	}
 
-	sm.mu.Lock()
-	sm.sessions[sessionID] = session
-	sm.mu.Unlock()
+	return &Session{
+		SessionID:        sessionID,
+		Username:         username,
+		Role:             role,
+		IP:               ip,
+		UserAgent:        userAgent,
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(sm.sessionTimeout),
+		LastSeenAt:       now,
+		RefreshTokenHash: hashToken(refreshToken),
+		RefreshExpiresAt: now.Add(sm.refreshTTL),
+		RefreshToken:     refreshToken,
+		persistedAt:      now,
+	}, nil
+}
+
+// addSessionLocked 将会话加入会话表（调用方需持有写锁），返回因超出并发上限被淘汰的会话
+func (sm *SessionManager) addSessionLocked(session *Session, now time.Time) []*Session {
+	sm.sessions[session.SessionID] = session
+	return sm.evictOverLimit(session.Username, now)
+}
+
+// persistCreated 记录被淘汰的会话并保存新会话
+func (sm *SessionManager) persistCreated(session *Session, evicted []*Session) {
+	for _, old := range evicted {
+		logger.WriteWebLog(fmt.Sprintf("[SESSION] 用户 %s 会话数超过上限 %d，已注销最久未活动的会话 %s (IP=%s)",
+			session.Username, sm.maxSessionsPerUser, old.PublicID(), old.IP))
+		sm.persistRevocation(old, "超出并发会话上限")
+	}
 
 	// 保存到数据库
 	if sm.db != nil {
@@ -248,8 +434,37 @@ func (sm *SessionManager) CreateSession(username, role, ip, userAgent string) (*
 			// 不返回错误，因为内存中已经创建了会话
 		}
 	}
+}
 
-	return session, nil
+// evictOverLimit 淘汰超出并发上限的会话（调用方需持有写锁），返回被淘汰的会话
+func (sm *SessionManager) evictOverLimit(username string, now time.Time) []*Session {
+	if sm.maxSessionsPerUser <= 0 {
+		return nil
+	}
+	var active []*Session
+	for _, session := range sm.sessions {
+		if session.Username == username && !sm.isDead(session, now) {
+			active = append(active, session)
+		}
+	}
+	if len(active) <= sm.maxSessionsPerUser {
+		return nil
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].LastSeenAt.Before(active[j].LastSeenAt)
+	})
+	evicted := active[:len(active)-sm.maxSessionsPerUser]
+	for _, session := range evicted {
+		sm.revokeLocked(session)
+	}
+	return evicted
+}
+
+// hashToken 令牌摘要（数据库和吊销列表只保存摘要）
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 // saveSessionToDB 保存会话到数据库
@@ -260,8 +475,8 @@ func (sm *SessionManager) saveSessionToDB(session *Session) error {
 
 	_, err := sm.db.Exec(`
 		INSERT OR REPLACE INTO sessions 
-		(session_id, username, role, ip, user_agent, created_at, expires_at) 
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		(`+sessionColumns+`) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		session.SessionID,
 		session.Username,
@@ -270,44 +485,73 @@ func (sm *SessionManager) saveSessionToDB(session *Session) error {
 		session.UserAgent,
 		session.CreatedAt,
 		session.ExpiresAt,
+		session.LastSeenAt,
+		session.RefreshTokenHash,
+		session.RefreshExpiresAt,
 	)
 	return err
 }
 
-// GetSession 获取会话
+// GetSession 获取会话（只返回未过期的会话，不续期）
 func (sm *SessionManager) GetSession(sessionID string) (*Session, bool) {
 	sm.mu.RLock()
 	session, exists := sm.sessions[sessionID]
 	sm.mu.RUnlock()
 
-	// 如果内存中存在，检查是否过期
-	if exists {
-		if time.Now().After(session.ExpiresAt) {
-			// 过期了，从内存和数据库删除
-			sm.DeleteSession(sessionID)
-			return nil, false
-		}
-		return session, true
-	}
-
 	// 内存中不存在，尝试从数据库加载（防止启动时遗漏）
-	if sm.db != nil {
+	if !exists && sm.db != nil {
 		session = sm.loadSessionFromDB(sessionID)
 		if session != nil {
-			// 检查是否过期
-			if time.Now().After(session.ExpiresAt) {
-				sm.DeleteSession(sessionID)
-				return nil, false
-			}
-			// 加载到内存中
 			sm.mu.Lock()
 			sm.sessions[sessionID] = session
 			sm.mu.Unlock()
-			return session, true
+			exists = true
 		}
 	}
+	if !exists {
+		return nil, false
+	}
+
+	now := time.Now()
+	sm.mu.RLock()
+	expired := !now.Before(session.ExpiresAt)
+	dead := sm.isDead(session, now)
+	sm.mu.RUnlock()
+	if expired {
+		// 刷新令牌仍有效时保留记录，供 /api/auth/refresh 换取新会话
+		if dead {
+			sm.DeleteSession(sessionID)
+		}
+		return nil, false
+	}
+	return session, true
+}
+
+// TouchSession 滑动续期：记录活动时间并将过期时间顺延一个空闲超时，但不超过会话绝对有效期
+func (sm *SessionManager) TouchSession(session *Session) {
+	now := time.Now()
+
+	sm.mu.Lock()
+	session.LastSeenAt = now
+	expiresAt := now.Add(sm.sessionTimeout)
+	if limit := session.CreatedAt.Add(sm.maxLifetime); sm.maxLifetime > 0 && expiresAt.After(limit) {
+		expiresAt = limit
+	}
+	if expiresAt.After(session.ExpiresAt) {
+		session.ExpiresAt = expiresAt
+	}
+	persist := now.Sub(session.persistedAt) >= sessionPersistInterval
+	if persist {
+		session.persistedAt = now
+	}
+	snapshot := *session
+	sm.mu.Unlock()
 
-	return nil, false
+	if persist && sm.db != nil {
+		if err := sm.saveSessionToDB(&snapshot); err != nil {
+			logger.Warn("⚠️ 保存会话续期失败: %v", err)
+		}
+	}
 }
 
 // loadSessionFromDB 从数据库加载单个会话
@@ -316,39 +560,184 @@ func (sm *SessionManager) loadSessionFromDB(sessionID string) *Session {
 		return nil
 	}
 
-	var session Session
-	err := sm.db.QueryRow(`
-		SELECT session_id, username, role, ip, user_agent, created_at, expires_at 
+	now := time.Now()
+	row := sm.db.QueryRow(`
+		SELECT `+sessionColumns+`
 		FROM sessions 
-		WHERE session_id = ? AND expires_at > ?
-	`, sessionID, time.Now()).Scan(
-		&session.SessionID,
-		&session.Username,
-		&session.Role,
-		&session.IP,
-		&session.UserAgent,
-		&session.CreatedAt,
-		&session.ExpiresAt,
-	)
+		WHERE session_id = ? AND (expires_at > ? OR refresh_expires_at > ?)
+	`, sessionID, now, now)
+	session, err := scanSession(row.Scan)
 	if err != nil {
 		return nil
 	}
 
-	return &session
+	return session
 }
 
-// DeleteSession 删除会话
-func (sm *SessionManager) DeleteSession(sessionID string) {
+// RefreshSession 用刷新令牌换取新会话（令牌轮换：旧会话和旧令牌同时作废）
+// 已吊销的令牌被再次使用说明令牌可能泄露，此时注销该用户的全部会话
+// 查找、吊销旧会话和创建新会话在同一次写锁内完成，同一令牌的并发刷新只有一个能成功
+func (sm *SessionManager) RefreshSession(refreshToken, ip, userAgent string) (*Session, error) {
+	if refreshToken == "" {
+		return nil, fmt.Errorf("缺少刷新令牌")
+	}
+	hash := hashToken(refreshToken)
+	now := time.Now()
+	// 会话 ID 和令牌在加锁前生成，用户和角色在确认旧会话后填入
+	session, err := sm.newSession("", "", ip, userAgent, now)
+	if err != nil {
+		return nil, err
+	}
+
 	sm.mu.Lock()
-	delete(sm.sessions, sessionID)
+	if revoked, ok := sm.revokedRefresh[hash]; ok && now.Before(revoked.expiresAt) {
+		targets := sm.revokeUserLocked(revoked.username, "")
+		sm.mu.Unlock()
+		logger.Warn("🚨 检测到已吊销的刷新令牌被重复使用，注销用户 %s 的全部会话 (IP=%s)", revoked.username, ip)
+		sm.persistUserRevocations(revoked.username, targets, "刷新令牌重放")
+		return nil, fmt.Errorf("刷新令牌已失效")
+	}
+	var old *Session
+	for _, s := range sm.sessions {
+		if s.RefreshTokenHash == hash {
+			old = s
+			break
+		}
+	}
+	if old == nil || !now.Before(old.RefreshExpiresAt) {
+		sm.mu.Unlock()
+		return nil, fmt.Errorf("刷新令牌无效或已过期")
+	}
+	sm.revokeLocked(old)
+	session.Username, session.Role = old.Username, old.Role
+	evicted := sm.addSessionLocked(session, now)
 	sm.mu.Unlock()
 
-	// 从数据库删除
-	if sm.db != nil {
+	sm.persistRevocation(old, "刷新令牌轮换")
+	sm.persistCreated(session, evicted)
+	return session, nil
+}
+
+// ListUserSessions 列出用户的活跃会话（按最近活动时间倒序）
+func (sm *SessionManager) ListUserSessions(username string) []Session {
+	now := time.Now()
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	sessions := make([]Session, 0)
+	for _, session := range sm.sessions {
+		if session.Username == username && now.Before(session.ExpiresAt) {
+			sessions = append(sessions, *session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions
+}
+
+// RevokeSessionByPublicID 按对外标识注销用户的某个会话
+func (sm *SessionManager) RevokeSessionByPublicID(username, publicID, reason string) bool {
+	sm.mu.RLock()
+	var target string
+	for sessionID, session := range sm.sessions {
+		if session.Username == username && session.PublicID() == publicID {
+			target = sessionID
+			break
+		}
+	}
+	sm.mu.RUnlock()
+
+	if target == "" {
+		return false
+	}
+	sm.RevokeSession(target, reason)
+	return true
+}
+
+// RevokeUserSessions 注销用户的全部会话（“退出所有设备”），exceptSessionID 非空时保留该会话，返回注销数量
+func (sm *SessionManager) RevokeUserSessions(username, exceptSessionID, reason string) int {
+	sm.mu.Lock()
+	targets := sm.revokeUserLocked(username, exceptSessionID)
+	sm.mu.Unlock()
+
+	sm.persistUserRevocations(username, targets, reason)
+	return len(targets)
+}
+
+// revokeUserLocked 注销用户除 exceptSessionID 外的全部会话（调用方需持有写锁），返回被注销的会话
+func (sm *SessionManager) revokeUserLocked(username, exceptSessionID string) []*Session {
+	var targets []*Session
+	for sessionID, session := range sm.sessions {
+		if session.Username == username && sessionID != exceptSessionID {
+			targets = append(targets, session)
+		}
+	}
+	for _, session := range targets {
+		sm.revokeLocked(session)
+	}
+	return targets
+}
+
+// persistUserRevocations 持久化批量注销的会话并记录日志
+func (sm *SessionManager) persistUserRevocations(username string, targets []*Session, reason string) {
+	for _, session := range targets {
+		sm.persistRevocation(session, reason)
+	}
+	if len(targets) > 0 {
+		logger.WriteWebLog(fmt.Sprintf("[SESSION] 已注销用户 %s 的 %d 个会话，原因: %s", username, len(targets), reason))
+	}
+}
+
+// RevokeSession 注销会话，并将其刷新令牌加入吊销列表
+func (sm *SessionManager) RevokeSession(sessionID, reason string) {
+	sm.mu.Lock()
+	session, exists := sm.sessions[sessionID]
+	if exists {
+		sm.revokeLocked(session)
+	}
+	sm.mu.Unlock()
+
+	if exists {
+		sm.persistRevocation(session, reason)
+	} else if sm.db != nil {
 		sm.deleteSessionsFromDB([]string{sessionID})
 	}
 }
 
+// revokeLocked 从内存中移除会话并登记刷新令牌（调用方需持有写锁）
+func (sm *SessionManager) revokeLocked(session *Session) {
+	delete(sm.sessions, session.SessionID)
+	if session.RefreshTokenHash != "" {
+		sm.revokedRefresh[session.RefreshTokenHash] = revokedToken{username: session.Username, expiresAt: session.RefreshExpiresAt}
+	}
+}
+
+// persistRevocation 将会话删除和吊销记录写入数据库
+func (sm *SessionManager) persistRevocation(session *Session, reason string) {
+	if sm.db == nil {
+		return
+	}
+	if err := sm.deleteSessionsFromDB([]string{session.SessionID}); err != nil {
+		logger.Warn("⚠️ 从数据库删除会话失败: %v", err)
+	}
+	if session.RefreshTokenHash == "" {
+		return
+	}
+	_, err := sm.db.Exec(`
+		INSERT OR REPLACE INTO session_revocations (token_hash, username, reason, revoked_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, session.RefreshTokenHash, session.Username, reason, time.Now(), session.RefreshExpiresAt)
+	if err != nil {
+		logger.Warn("⚠️ 保存会话吊销记录失败: %v", err)
+	}
+}
+
+// DeleteSession 删除会话（退出登录），其刷新令牌同时作废
+func (sm *SessionManager) DeleteSession(sessionID string) {
+	sm.RevokeSession(sessionID, "退出登录")
+}
+
 // deleteSessionsFromDB 从数据库删除会话
 func (sm *SessionManager) deleteSessionsFromDB(sessionIDs []string) error {
 	if sm.db == nil || len(sessionIDs) == 0 {
@@ -389,9 +778,9 @@ func (sm *SessionManager) SetSessionCookie(w http.ResponseWriter, sessionID stri
 		Value:    sessionID,
 		Path:     "/",
 		HttpOnly: true,
-		Secure:   false,                // 本地开发环境使用 HTTP，不需要 Secure 标志
-		SameSite: http.SameSiteLaxMode, // 使用 Lax 模式，确保同站请求能正常携带 Cookie
-		MaxAge:   int(sm.sessionTimeout.Seconds()),
+		Secure:   false,                         // 本地开发环境使用 HTTP，不需要 Secure 标志
+		SameSite: http.SameSiteLaxMode,          // 使用 Lax 模式，确保同站请求能正常携带 Cookie
+		MaxAge:   int(sm.maxLifetime.Seconds()), // 空闲超时由服务端滑动续期控制
 	}
 	http.SetCookie(w, cookie)
 
@@ -400,6 +789,28 @@ func (sm *SessionManager) SetSessionCookie(w http.ResponseWriter, sessionID stri
 		cookie.Name, sessionID[:20], cookie.Path, cookie.MaxAge, cookie.HttpOnly, cookie.Secure, cookie.SameSite))
 }
 
+// SetRefreshCookie 设置刷新令牌Cookie（仅发送给刷新接口）
+func (sm *SessionManager) SetRefreshCookie(w http.ResponseWriter, refreshToken string, secure bool) {
+	if refreshToken == "" {
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshTokenCookieName,
+		Value:    refreshToken,
+		Path:     withBasePath(refreshTokenCookiePath),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   int(sm.refreshTTL.Seconds()),
+	})
+}
+
+// SetLoginCookies 登录成功后设置会话和刷新令牌Cookie
+func (sm *SessionManager) SetLoginCookies(w http.ResponseWriter, session *Session, secure bool) {
+	sm.SetSessionCookie(w, session.SessionID, secure)
+	sm.SetRefreshCookie(w, session.RefreshToken, secure)
+}
+
 // ClearSessionCookie 清除会话Cookie
 func (sm *SessionManager) ClearSessionCookie(w http.ResponseWriter) {
 	cookie := &http.Cookie{
@@ -410,6 +821,13 @@ func (sm *SessionManager) ClearSessionCookie(w http.ResponseWriter) {
 		MaxAge:   -1,
 	}
 	http.SetCookie(w, cookie)
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshTokenCookieName,
+		Value:    "",
+		Path:     withBasePath(refreshTokenCookiePath),
+		HttpOnly: true,
+		MaxAge:   -1,
+	})
 }
 
 // 全局会话管理器
@@ -0,0 +1,154 @@
+package web
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSessionRefreshRotationAndReuse(t *testing.T) {
+	sm := newMemorySessionManager()
+
+	first, err := sm.CreateSession("admin", "admin", "1.1.1.1", "ua")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if first.RefreshToken == "" || first.RefreshTokenHash != hashToken(first.RefreshToken) {
+		t.Fatalf("refresh token not issued")
+	}
+
+	second, err := sm.RefreshSession(first.RefreshToken, "2.2.2.2", "ua")
+	if err != nil {
+		t.Fatalf("RefreshSession: %v", err)
+	}
+	if _, ok := sm.GetSession(first.SessionID); ok {
+		t.Fatalf("rotated session should be revoked")
+	}
+	if _, ok := sm.GetSession(second.SessionID); !ok {
+		t.Fatalf("new session should be valid")
+	}
+
+	// 旧令牌重放：注销该用户全部会话
+	if _, err := sm.RefreshSession(first.RefreshToken, "3.3.3.3", "ua"); err == nil {
+		t.Fatalf("reused refresh token should be rejected")
+	}
+	if _, ok := sm.GetSession(second.SessionID); ok {
+		t.Fatalf("reuse should revoke all sessions of the user")
+	}
+}
+
+func TestSessionSlidingExpirationAndLimit(t *testing.T) {
+	sm := newMemorySessionManager()
+	sm.maxSessionsPerUser = 2
+
+	s1, _ := sm.CreateSession("admin", "admin", "ip1", "ua")
+	s2, _ := sm.CreateSession("admin", "admin", "ip2", "ua")
+	sm.TouchSession(s1) // s2 成为最久未活动的会话
+	s3, _ := sm.CreateSession("admin", "admin", "ip3", "ua")
+
+	if _, ok := sm.GetSession(s2.SessionID); ok {
+		t.Fatalf("least recently used session should be evicted")
+	}
+	for _, s := range []*Session{s1, s3} {
+		if _, ok := sm.GetSession(s.SessionID); !ok {
+			t.Fatalf("session %s should remain", s.PublicID())
+		}
+	}
+
+	// 已过期会话：续期前不可用，刷新令牌仍可换取新会话
+	s1.ExpiresAt = time.Now().Add(-time.Minute)
+	if _, ok := sm.GetSession(s1.SessionID); ok {
+		t.Fatalf("expired session should be rejected")
+	}
+	if _, err := sm.RefreshSession(s1.RefreshToken, "ip1", "ua"); err != nil {
+		t.Fatalf("refresh of expired session: %v", err)
+	}
+
+	// 滑动续期不超过绝对有效期
+	s3.CreatedAt = time.Now().Add(-sm.maxLifetime + time.Hour)
+	s3.ExpiresAt = time.Now().Add(time.Minute)
+	sm.TouchSession(s3)
+	if s3.ExpiresAt.After(s3.CreatedAt.Add(sm.maxLifetime)) {
+		t.Fatalf("sliding expiration exceeded max lifetime")
+	}
+
+	if n := sm.RevokeUserSessions("admin", s3.SessionID, "test"); n != 1 {
+		t.Fatalf("expected 1 revoked session, got %d", n)
+	}
+	if got := sm.ListUserSessions("admin"); len(got) != 1 || got[0].SessionID != s3.SessionID {
+		t.Fatalf("unexpected sessions after revoke-all: %+v", got)
+	}
+}
+
+func TestSessionConcurrentRefresh(t *testing.T) {
+	sm := newMemorySessionManager()
+	first, err := sm.CreateSession("admin", "admin", "1.1.1.1", "ua")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	// 同一令牌并发刷新：只能换出一个新会话
+	const workers = 8
+	var wg sync.WaitGroup
+	results := make(chan *Session, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if session, err := sm.RefreshSession(first.RefreshToken, "2.2.2.2", "ua"); err == nil {
+				results <- session
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	var issued []*Session
+	for session := range results {
+		issued = append(issued, session)
+	}
+	if len(issued) > 1 {
+		t.Fatalf("refresh token exchanged %d times", len(issued))
+	}
+	// 其余请求视为重放，连同唯一的新会话一起注销
+	if got := sm.ListUserSessions("admin"); len(got) != 0 {
+		t.Fatalf("replayed refresh left %d sessions", len(got))
+	}
+}
+
+func TestSessionPruneRevokedRefresh(t *testing.T) {
+	sm := newMemorySessionManager()
+	first, _ := sm.CreateSession("admin", "admin", "ip1", "ua")
+	if _, err := sm.RefreshSession(first.RefreshToken, "ip1", "ua"); err != nil {
+		t.Fatalf("RefreshSession: %v", err)
+	}
+	if len(sm.revokedRefresh) != 1 {
+		t.Fatalf("revoked tokens = %d, want 1", len(sm.revokedRefresh))
+	}
+
+	// 刷新令牌过期前保留吊销记录
+	sm.mu.Lock()
+	sm.pruneLocked(first.RefreshExpiresAt.Add(-time.Second))
+	kept := len(sm.revokedRefresh)
+	sm.mu.Unlock()
+	if kept != 1 {
+		t.Fatalf("revocation pruned before the token expired")
+	}
+
+	// 过期后移除；过期令牌的重放只是无效，不再注销用户的会话
+	sm.mu.Lock()
+	sm.pruneLocked(first.RefreshExpiresAt)
+	kept = len(sm.revokedRefresh)
+	sm.mu.Unlock()
+	if kept != 0 {
+		t.Fatalf("expired revocation kept")
+	}
+	live, _ := sm.CreateSession("admin", "admin", "ip2", "ua")
+	sm.revokedRefresh[first.RefreshTokenHash] = revokedToken{username: "admin", expiresAt: time.Now().Add(-time.Second)}
+	if _, err := sm.RefreshSession(first.RefreshToken, "ip2", "ua"); err == nil {
+		t.Fatalf("expired refresh token accepted")
+	}
+	if _, ok := sm.GetSession(live.SessionID); !ok {
+		t.Fatalf("expired token replay revoked live sessions")
+	}
+}
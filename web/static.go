@@ -1,9 +1,15 @@
 package web
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"io/fs"
 	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
 )
 
 //go:embed dist/*
@@ -41,3 +47,93 @@ func GetIconsFS() http.FileSystem {
 	}
 	return http.FS(iconsFS)
 }
+
+// 静态资源缓存策略
+// Vite 构建的 /assets 文件名带内容哈希，可以永久缓存；index.html、Service Worker 和 Manifest
+// 引用这些哈希文件名，必须每次向服务器确认（配合 ETag 返回 304），否则升级后浏览器会继续使用旧界面
+const (
+	cacheControlImmutable  = "public, max-age=31536000, immutable"
+	cacheControlRevalidate = "no-cache"
+	cacheControlIcons      = "public, max-age=86400"
+)
+
+// embeddedAsset 嵌入的静态文件及其 ETag（内容哈希）
+type embeddedAsset struct {
+	data []byte
+	etag string
+}
+
+var (
+	assetIndexOnce sync.Once
+	assetIndex     map[string]*embeddedAsset
+	uiHash         string
+)
+
+// loadAssetIndex 计算所有嵌入文件的内容哈希（只计算一次）
+// 界面版本取 index.html 的哈希：它引用了全部带哈希的资源文件名，前端任何改动都会改变它
+func loadAssetIndex() {
+	assetIndexOnce.Do(func() {
+		assetIndex = make(map[string]*embeddedAsset)
+		fs.WalkDir(staticFiles, "dist", func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			data, err := staticFiles.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+			data = rewriteForBasePath(path, data)
+			sum := sha256.Sum256(data)
+			assetIndex[path] = &embeddedAsset{data: data, etag: `"` + hex.EncodeToString(sum[:8]) + `"`}
+			return nil
+		})
+		if index, ok := assetIndex["dist/index.html"]; ok {
+			uiHash = strings.Trim(index.etag, `"`)
+		}
+	})
+}
+
+// UIHash 嵌入的前端构建哈希（未嵌入前端时为空）
+func UIHash() string {
+	loadAssetIndex()
+	return uiHash
+}
+
+// serveEmbedded 返回嵌入的文件，支持 If-None-Match 协商缓存
+func serveEmbedded(c *gin.Context, name, contentType, cacheControl string) {
+	loadAssetIndex()
+	asset, ok := assetIndex[name]
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	c.Header("Cache-Control", cacheControl)
+	c.Header("ETag", asset.etag)
+	if match := c.GetHeader("If-None-Match"); match != "" && strings.Contains(match, asset.etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, contentType, asset.data)
+}
+
+// serveIndex 返回前端入口页面，并通过 X-UI-Version 头标识界面版本（便于排查浏览器缓存的旧界面）
+func serveIndex(c *gin.Context) {
+	loadAssetIndex()
+	if _, ok := assetIndex["dist/index.html"]; !ok {
+		c.String(http.StatusNotFound, "Frontend not found. Please rebuild the project.")
+		return
+	}
+	c.Header("X-UI-Version", uiHash)
+	serveEmbedded(c, "dist/index.html", "text/html; charset=utf-8", cacheControlRevalidate)
+}
+
+// cacheControl 为静态资源路由设置 Cache-Control（只对存在的文件设置，避免 404 被长期缓存）
+func cacheControl(value string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		loadAssetIndex()
+		if _, ok := assetIndex["dist"+c.Request.URL.Path]; ok {
+			c.Header("Cache-Control", value)
+		}
+		c.Next()
+	}
+}
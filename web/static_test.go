@@ -0,0 +1,70 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestServeIndexETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", serveIndex)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+	if got := w.Header().Get("Cache-Control"); got != cacheControlRevalidate {
+		t.Errorf("Cache-Control = %q, want %q", got, cacheControlRevalidate)
+	}
+	if got := w.Header().Get("X-UI-Version"); got == "" || got != UIHash() {
+		t.Errorf("X-UI-Version = %q, want %q", got, UIHash())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("revalidation status = %d, want 304", w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("304 response should have no body")
+	}
+}
+
+func TestServeEmbeddedMissing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/sw.js", func(c *gin.Context) {
+		serveEmbedded(c, "dist/does-not-exist.js", "application/javascript", cacheControlRevalidate)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sw.js", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestGetBuildInfoDefaults(t *testing.T) {
+	SetVersion("1.2.3")
+	defer SetVersion("")
+	SetBuildInfo("abc123", "2026-01-01T00:00:00Z")
+
+	info := GetBuildInfo()
+	if info.Version != "1.2.3" || info.GitCommit != "abc123" || info.BuildTime != "2026-01-01T00:00:00Z" {
+		t.Errorf("unexpected build info: %+v", info)
+	}
+	if !info.UIEmbedded || info.UIHash == "" {
+		t.Errorf("embedded UI should be reported: %+v", info)
+	}
+}
@@ -0,0 +1,75 @@
+package web
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// BuildInfo 构建信息
+// 前端加载后可对比 ui_hash 与页面响应头 X-UI-Version，判断浏览器是否仍在使用旧界面
+type BuildInfo struct {
+	Version    string `json:"version"`
+	GitCommit  string `json:"git_commit"`
+	GitDirty   bool   `json:"git_dirty"`
+	BuildTime  string `json:"build_time"`
+	GoVersion  string `json:"go_version"`
+	UIHash     string `json:"ui_hash"`
+	UIEmbedded bool   `json:"ui_embedded"`
+}
+
+var (
+	buildInfoMu sync.RWMutex
+	buildCommit string
+	buildTime   string
+	buildDirty  bool
+)
+
+// SetBuildInfo 设置构建时注入的 git 提交和构建时间
+// 未注入（如直接 go build）时回退到 Go 工具链记录的 VCS 信息
+func SetBuildInfo(commit, builtAt string) {
+	buildInfoMu.Lock()
+	defer buildInfoMu.Unlock()
+	buildCommit, buildTime = commit, builtAt
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if buildCommit == "" {
+				buildCommit = setting.Value
+			}
+		case "vcs.time":
+			if buildTime == "" {
+				buildTime = setting.Value
+			}
+		case "vcs.modified":
+			buildDirty = setting.Value == "true"
+		}
+	}
+}
+
+// GetBuildInfo 获取构建信息
+func GetBuildInfo() BuildInfo {
+	buildInfoMu.RLock()
+	defer buildInfoMu.RUnlock()
+	info := BuildInfo{
+		Version:   appVersion,
+		GitCommit: buildCommit,
+		GitDirty:  buildDirty,
+		BuildTime: buildTime,
+		GoVersion: runtime.Version(),
+		UIHash:    UIHash(),
+	}
+	if info.Version == "" {
+		info.Version = "unknown"
+	}
+	if info.GitCommit == "" {
+		info.GitCommit = "unknown"
+	}
+	info.UIEmbedded = info.UIHash != ""
+	return info
+}
@@ -2,7 +2,6 @@ package web
 
 import (
 	"encoding/json"
-	"net/http"
 	"sync"
 
 	"github.com/gin-gonic/gin"
@@ -11,9 +10,8 @@ import (
 )
 
 var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true // 允许所有来源（生产环境应该限制）
-	},
+	// 与 HTTP 接口共用来源白名单，防止恶意页面借用户 Cookie 建立连接
+	CheckOrigin: corsPolicy.isAllowed,
 }
 
 // WebSocketHub WebSocket 中心
@@ -61,6 +61,7 @@ import MobileNav from './components/MobileNav'
 import LanguageSelector from './components/LanguageSelector'
 import ConnectionStatusBanner from './components/ConnectionStatusBanner'
 import { logout } from './services/auth'
+import { BASE_PATH } from './services/api'
 import { useTranslation } from 'react-i18next'
 import { useResponsive } from './hooks/useResponsive'
 import './App.css'
@@ -394,7 +395,7 @@ const ThemedApp: React.FC = () => {
 
 function App() {
   return (
-    <BrowserRouter>
+    <BrowserRouter basename={BASE_PATH}>
       <AuthProvider>
         <SymbolProvider>
           <ThemedApp />
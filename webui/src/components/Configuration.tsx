@@ -632,6 +632,45 @@ const Configuration: React.FC = () => {
                           />
                         </FormControl>
                       </ConfigCard>
+                      <ConfigCard title={t('configuration.ntfy')}>
+                        <FormControl mb={4}>
+                          <FormLabel fontSize="xs" fontWeight="bold">{t('configuration.ntfyServer')}</FormLabel>
+                          <Input
+                            value={config.notifications?.ntfy?.server || ''}
+                            onChange={(e) => updateConfigField('notifications.ntfy.server', e.target.value)}
+                            placeholder="https://ntfy.sh"
+                            borderRadius="xl"
+                          />
+                        </FormControl>
+                        <FormControl mb={4}>
+                          <FormLabel fontSize="xs" fontWeight="bold">{t('configuration.ntfyTopic')}</FormLabel>
+                          <Input
+                            value={config.notifications?.ntfy?.topic || ''}
+                            onChange={(e) => updateConfigField('notifications.ntfy.topic', e.target.value)}
+                            placeholder="quantmesh-alerts"
+                            borderRadius="xl"
+                          />
+                        </FormControl>
+                        <FormControl>
+                          <FormLabel fontSize="xs" fontWeight="bold">{t('configuration.ntfyToken')}</FormLabel>
+                          {renderPasswordInput('notifications.ntfy.token')}
+                        </FormControl>
+                      </ConfigCard>
+                      <ConfigCard title={t('configuration.gotify')}>
+                        <FormControl mb={4}>
+                          <FormLabel fontSize="xs" fontWeight="bold">{t('configuration.gotifyUrl')}</FormLabel>
+                          <Input
+                            value={config.notifications?.gotify?.url || ''}
+                            onChange={(e) => updateConfigField('notifications.gotify.url', e.target.value)}
+                            placeholder="https://gotify.example.com"
+                            borderRadius="xl"
+                          />
+                        </FormControl>
+                        <FormControl>
+                          <FormLabel fontSize="xs" fontWeight="bold">{t('configuration.gotifyToken')}</FormLabel>
+                          {renderPasswordInput('notifications.gotify.token')}
+                        </FormControl>
+                      </ConfigCard>
                     </SimpleGrid>
                   </VStack>
                 )}
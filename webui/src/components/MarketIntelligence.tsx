@@ -7,12 +7,13 @@ const MarketIntelligence: React.FC = () => {
     fear_greed: null,
     reddit_posts: [],
     polymarket: [],
+    on_chain: null,
   })
   const [loading, setLoading] = useState(true)
   const [error, setError] = useState<string | null>(null)
   const [searchKeyword, setSearchKeyword] = useState('')
   const [selectedSource, setSelectedSource] = useState<string>('')
-  const [activeTab, setActiveTab] = useState<'rss' | 'fear_greed' | 'reddit' | 'polymarket' | 'all'>('all')
+  const [activeTab, setActiveTab] = useState<'rss' | 'fear_greed' | 'reddit' | 'polymarket' | 'on_chain' | 'all'>('all')
 
   // 获取市场情报数据
   const fetchData = async () => {
@@ -36,7 +37,8 @@ const MarketIntelligence: React.FC = () => {
         response.rss_feeds.length === 0 &&
         response.fear_greed === null &&
         response.reddit_posts.length === 0 &&
-        response.polymarket.length === 0
+        response.polymarket.length === 0 &&
+        !response.on_chain
       )
       
       if (isEmpty) {
@@ -125,6 +127,7 @@ const MarketIntelligence: React.FC = () => {
           <option value="fear_greed">恐慌贪婪指数</option>
           <option value="reddit">Reddit</option>
           <option value="polymarket">Polymarket</option>
+          <option value="on_chain">链上数据</option>
         </select>
         <button
           onClick={fetchData}
@@ -173,7 +176,7 @@ const MarketIntelligence: React.FC = () => {
         <>
           {/* 标签页 */}
           <div style={{ marginBottom: '20px', display: 'flex', gap: '8px', borderBottom: '2px solid #e5e7eb' }}>
-            {(['all', 'rss', 'fear_greed', 'reddit', 'polymarket'] as const).map((tab) => (
+            {(['all', 'rss', 'fear_greed', 'reddit', 'polymarket', 'on_chain'] as const).map((tab) => (
               <button
                 key={tab}
                 onClick={() => setActiveTab(tab)}
@@ -189,7 +192,7 @@ const MarketIntelligence: React.FC = () => {
                   fontWeight: activeTab === tab ? '600' : '400',
                 }}
               >
-                {tab === 'all' ? '全部' : tab === 'rss' ? 'RSS新闻' : tab === 'fear_greed' ? '恐慌贪婪' : tab === 'reddit' ? 'Reddit' : 'Polymarket'}
+                {tab === 'all' ? '全部' : tab === 'rss' ? 'RSS新闻' : tab === 'fear_greed' ? '恐慌贪婪' : tab === 'reddit' ? 'Reddit' : tab === 'polymarket' ? 'Polymarket' : '链上数据'}
               </button>
             ))}
           </div>
@@ -401,6 +404,46 @@ const MarketIntelligence: React.FC = () => {
               )}
             </div>
           )}
+
+          {/* 链上数据 */}
+          {(activeTab === 'all' || activeTab === 'on_chain') && (
+            <div style={{ marginBottom: '40px' }}>
+              <h3>链上数据</h3>
+              {!data.on_chain ? (
+                <p style={{ color: '#6b7280', padding: '20px', textAlign: 'center' }}>未启用链上数据源</p>
+              ) : (
+                <div style={{ backgroundColor: '#fff', borderRadius: '8px', overflow: 'hidden' }}>
+                  <div style={{ padding: '12px', fontSize: '14px', color: '#6b7280' }}>
+                    稳定币供应量: ${data.on_chain.stablecoin_supply.toLocaleString('en-US', { maximumFractionDigits: 0 })}
+                    {' '}({(data.on_chain.stablecoin_supply_change * 100).toFixed(2)}% / 24h)
+                    <span style={{ float: 'right', fontSize: '12px', color: '#9ca3af' }}>
+                      {data.on_chain.provider} · {formatDate(data.on_chain.updated_at)}
+                    </span>
+                  </div>
+                  <table style={{ width: '100%', borderCollapse: 'collapse' }}>
+                    <thead>
+                      <tr style={{ backgroundColor: '#f3f4f6' }}>
+                        <th style={{ padding: '12px', textAlign: 'left', borderBottom: '2px solid #e5e7eb' }}>币种</th>
+                        <th style={{ padding: '12px', textAlign: 'right', borderBottom: '2px solid #e5e7eb' }}>交易所净流入 (24h)</th>
+                        <th style={{ padding: '12px', textAlign: 'right', borderBottom: '2px solid #e5e7eb' }}>大额转账笔数 (24h)</th>
+                      </tr>
+                    </thead>
+                    <tbody>
+                      {Object.values(data.on_chain.assets).map((m) => (
+                        <tr key={m.asset} style={{ borderBottom: '1px solid #e5e7eb' }}>
+                          <td style={{ padding: '12px', fontWeight: '500' }}>{m.asset}</td>
+                          <td style={{ padding: '12px', textAlign: 'right', color: m.exchange_netflow > 0 ? '#ef4444' : '#10b981' }}>
+                            {m.exchange_netflow.toLocaleString('en-US', { maximumFractionDigits: 2 })}
+                          </td>
+                          <td style={{ padding: '12px', textAlign: 'right', color: '#6b7280' }}>{m.large_transfers}</td>
+                        </tr>
+                      ))}
+                    </tbody>
+                  </table>
+                </div>
+              )}
+            </div>
+          )}
         </>
       )}
     </div>
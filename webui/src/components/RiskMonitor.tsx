@@ -3,9 +3,11 @@ import {
   getRiskStatus, 
   getRiskMonitorData, 
   getRiskCheckHistory,
+  getCorrelation,
   RiskStatusResponse, 
   SymbolMonitorData,
-  RiskCheckHistoryItem 
+  RiskCheckHistoryItem,
+  CorrelationResponse
 } from '../services/api'
 import { BarChart, Bar, XAxis, YAxis, Tooltip, Legend, ResponsiveContainer, Cell } from 'recharts'
 import './RiskMonitor.css'
@@ -20,6 +22,8 @@ const RiskMonitor: React.FC = () => {
   const [errorStatus, setErrorStatus] = useState<string | null>(null)
   const [errorData, setErrorData] = useState<string | null>(null)
   const [errorHistory, setErrorHistory] = useState<string | null>(null)
+  const [correlation, setCorrelation] = useState<CorrelationResponse | null>(null)
+  const [errorCorrelation, setErrorCorrelation] = useState<string | null>(null)
 
   // Fetch Risk Status
   useEffect(() => {
@@ -42,6 +46,24 @@ const RiskMonitor: React.FC = () => {
     return () => clearInterval(interval)
   }, [])
 
+  // Fetch Volatility & Correlation (K线按日计算，无需频繁刷新)
+  useEffect(() => {
+    const fetchCorrelation = async () => {
+      try {
+        const data = await getCorrelation()
+        setCorrelation(data)
+        setErrorCorrelation(null)
+      } catch (err) {
+        setErrorCorrelation(err instanceof Error ? err.message : 'Failed to fetch correlation')
+        console.error('Failed to fetch correlation:', err)
+      }
+    }
+
+    fetchCorrelation()
+    const interval = setInterval(fetchCorrelation, 10 * 60 * 1000)
+    return () => clearInterval(interval)
+  }, [])
+
   // Fetch Monitor Data
   useEffect(() => {
     const fetchData = async () => {
@@ -189,6 +211,54 @@ const RiskMonitor: React.FC = () => {
         </div>
       )}
 
+      {/* Volatility & Correlation */}
+      <h3 style={{ marginTop: '32px' }}>波动率与相关性</h3>
+      {errorCorrelation ? (
+        <p style={{ color: '#999' }}>暂无相关性数据: {errorCorrelation}</p>
+      ) : !correlation ? (
+        <p>加载相关性数据...</p>
+      ) : (
+        <div style={{ overflowX: 'auto' }}>
+          <p style={{ fontSize: '13px', color: '#666' }}>
+            周期 {correlation.interval}，最近 {correlation.report.samples} 个收益率样本
+            {correlation.skipped.length > 0 && `（已跳过: ${correlation.skipped.join(', ')}）`}
+          </p>
+          <table className="risk-monitor-table">
+            <thead>
+              <tr>
+                <th>币种</th>
+                <th>波动率</th>
+                <th>滚动波动率({correlation.window})</th>
+                <th>风险平价权重</th>
+                {correlation.report.symbols.map((s) => <th key={s}>{s}</th>)}
+              </tr>
+            </thead>
+            <tbody>
+              {correlation.report.symbols.map((symbol, i) => {
+                const rolling = correlation.report.rolling_vol[symbol]
+                const latest = rolling && rolling.length > 0 ? rolling[rolling.length - 1] : 0
+                return (
+                  <tr key={symbol}>
+                    <td><strong>{symbol}</strong></td>
+                    <td>{(correlation.report.volatility[symbol] * 100).toFixed(2)}%</td>
+                    <td>{(latest * 100).toFixed(2)}%</td>
+                    <td>{((correlation.report.risk_parity_weight[symbol] || 0) * 100).toFixed(1)}%</td>
+                    {correlation.report.matrix[i].map((v, j) => (
+                      <td
+                        key={j}
+                        style={{ backgroundColor: i === j ? '#fafafa' : v >= 0 ? `rgba(255, 77, 79, ${Math.abs(v) * 0.5})` : `rgba(82, 196, 26, ${Math.abs(v) * 0.5})` }}
+                      >
+                        {v.toFixed(2)}
+                      </td>
+                    ))}
+                  </tr>
+                )
+              })}
+            </tbody>
+          </table>
+        </div>
+      )}
+
       {/* History Health Chart */}
       <div style={{ marginTop: '32px', display: 'flex', justifyContent: 'space-between', alignItems: 'center' }}>
         <h3 style={{ margin: 0 }}>历史健康度</h3>
@@ -4,6 +4,9 @@ import App from './App'
 import './index.css'
 import './i18n/config'
 import i18n from 'i18next'
+import { installCSRFFetch } from './services/csrf'
+
+installCSRFFetch()
 
 // PWA Service Worker 注册
 if ('serviceWorker' in navigator) {
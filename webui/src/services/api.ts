@@ -1,5 +1,8 @@
+// 反向代理子路径（服务端根据 web.base_path 注入，根路径部署时为空）
+export const BASE_PATH: string = (window as any).__QUANTMESH_BASE__ || ''
+
 // 使用页面同源，避免相对路径被代理/扩展劫持
-const API_BASE_URL = `${window.location.origin}/api`
+const API_BASE_URL = `${window.location.origin}${BASE_PATH}/api`
 
 // Helper function to make authenticated requests
 export async function fetchWithAuth(url: string, options: RequestInit = {}) {
@@ -440,7 +443,7 @@ export type LogSubscribeErrorHandler = (event: Event) => void
 export function subscribeLogs(onLog: LogSubscribeHandler, onError?: LogSubscribeErrorHandler) {
   const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
   const host = window.location.host
-  const wsUrl = `${protocol}//${host}/ws?subscribe_logs=true`
+  const wsUrl = `${protocol}//${host}${BASE_PATH}/ws?subscribe_logs=true`
   const socket = new WebSocket(wsUrl)
 
   const handleMessage = (event: MessageEvent) => {
@@ -660,6 +663,42 @@ export async function getRiskMonitorData(): Promise<RiskMonitorDataResponse> {
   return fetchWithAuth(`${API_BASE_URL}/risk/monitor`)
 }
 
+export interface CorrelationReport {
+  symbols: string[]
+  samples: number
+  volatility: Record<string, number>
+  rolling_vol: Record<string, number[]>
+  rolling_times: number[] | null
+  matrix: number[][]
+  risk_parity_weight: Record<string, number>
+}
+
+export interface CorrelationResponse {
+  interval: string
+  lookback: number
+  window: number
+  report: CorrelationReport
+  skipped: string[]
+}
+
+export interface CorrelationParams {
+  symbols?: string
+  interval?: string
+  lookback?: number
+  window?: number
+}
+
+export async function getCorrelation(params?: CorrelationParams): Promise<CorrelationResponse> {
+  const queryParams = new URLSearchParams()
+  if (params?.symbols) queryParams.append('symbols', params.symbols)
+  if (params?.interval) queryParams.append('interval', params.interval)
+  if (params?.lookback) queryParams.append('lookback', String(params.lookback))
+  if (params?.window) queryParams.append('window', String(params.window))
+
+  const url = `${API_BASE_URL}/analytics/correlation${queryParams.toString() ? '?' + queryParams.toString() : ''}`
+  return fetchWithAuth(url)
+}
+
 export interface RiskCheckSymbolInfo {
   symbol: string
   is_healthy: boolean
@@ -1042,15 +1081,31 @@ export interface PolymarketMarketInfo {
   liquidity: number
 }
 
+export interface OnChainMetrics {
+  asset: string
+  exchange_netflow: number
+  large_transfers: number
+}
+
+export interface OnChainSnapshot {
+  provider: string
+  assets: Record<string, OnChainMetrics>
+  stablecoin_supply: number
+  stablecoin_supply_change: number
+  updated_at: string
+  errors?: string[]
+}
+
 export interface MarketIntelligenceResponse {
   rss_feeds: RSSFeedInfo[]
   fear_greed: FearGreedIndexInfo | null
   reddit_posts: RedditPostInfo[]
   polymarket: PolymarketMarketInfo[]
+  on_chain?: OnChainSnapshot | null
 }
 
 export interface MarketIntelligenceParams {
-  source?: 'rss' | 'fear_greed' | 'reddit' | 'polymarket'
+  source?: 'rss' | 'fear_greed' | 'reddit' | 'polymarket' | 'on_chain'
   keyword?: string
   limit?: number
 }
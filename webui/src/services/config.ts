@@ -285,6 +285,21 @@ export interface Config {
       enabled: boolean
       webhook: string
     }
+    ntfy: {
+      enabled: boolean
+      server: string
+      topic: string
+      token: string
+      username: string
+      password: string
+      topics: Record<string, string>
+    }
+    gotify: {
+      enabled: boolean
+      url: string
+      token: string
+      tokens: Record<string, string>
+    }
     rules: {
       order_placed: boolean
       order_filled: boolean